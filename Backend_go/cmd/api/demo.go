@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/routes"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/presence"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workflow"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workitems"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/background"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/config"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+const (
+	demoEmail    = "demo@compass.local"
+	demoTokenTTL = 24 * time.Hour
+)
+
+// demoPermissions are granted to the demo user in the demo organization
+var demoPermissions = []string{
+	"tasks:create", "tasks:read", "tasks:update", "tasks:delete",
+	"workflows:create", "workflows:read", "workflows:update", "workflows:delete", "workflows:execute",
+}
+
+// runDemo serves the task, todo, habit, calendar and workflow APIs from in-memory
+// repositories, for trying the API without Postgres. Redis is still needed for caching
+// and dashboard events. There is a single demo user, who belongs to a single demo
+// organization and whose token is logged at startup. Unless activityInterval is zero, the
+// demo organization keeps changing on its own. Everything is lost on exit.
+func runDemo(cfg *config.Config, log *logger.Logger, router *gin.Engine, activityInterval time.Duration) {
+	redisClient, err := cache.NewRedisClient(cache.NewConfigFromEnv(cfg))
+	if err != nil {
+		log.Fatal("Failed to connect to Redis", zap.Error(err))
+	}
+	defer redisClient.Close()
+
+	workflowLogger := logger.Named("workflow").Logger
+
+	userID, orgID, projectID := uuid.New(), uuid.New(), uuid.New()
+	orgContext := middleware.NewOrganizationContext(demoMembership{userID: userID, orgID: orgID})
+	cacheMiddleware := middleware.NewCacheMiddleware(redisClient, "compass-demo", 5*time.Minute)
+
+	// Notifications, activity, webhooks, roles, plugins, domain events, business-hours
+	// clocks and organization default settings are left out of the demo
+	taskService := task.NewService(task.NewMemoryRepository(), redisClient, nil, nil, nil, nil, cacheMiddleware, nil, nil, nil, log.Logger)
+	habitsService := habits.NewService(habits.NewMemoryRepository(), nil, redisClient, nil, nil, log.Logger)
+	calendarService := calendar.NewService(calendar.NewMemoryRepository(), nil, nil, redisClient, nil, log.Logger)
+	todosService := todos.NewService(todos.NewMemoryRepository(), redisClient, nil, nil, cacheMiddleware, log.Logger)
+	workflowRepo := workflow.NewMemoryRepository()
+	backgroundWork := background.NewGroup(log.Logger)
+	workflowService := workflow.NewService(workflow.ServiceConfig{
+		Repository: workflowRepo,
+		Logger:     workflowLogger,
+		Executor: workflow.NewDefaultExecutor(workflowRepo, workflowLogger, nil, nil).
+			WithWorkItems(workitems.NewService(taskService, todosService, calendarService)).
+			WithBackground(backgroundWork),
+		Background: backgroundWork,
+	})
+	presenceService := presence.NewService(redisClient, log.Logger)
+
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "healthy",
+			"mode":      "demo",
+			"timestamp": time.Now().Unix(),
+		})
+	})
+
+	routes.NewTaskRoutes(handlers.NewTaskHandler(taskService, presenceService), cfg.Auth.JWTSecret).
+		RegisterRoutes(router, cacheMiddleware, orgContext)
+	routes.NewHabitsRoutes(handlers.NewHabitsHandler(habitsService), cfg.Auth.JWTSecret).
+		RegisterRoutes(router, cacheMiddleware)
+	routes.NewCalendarRoutes(handlers.NewCalendarHandler(calendarService), cfg.Auth.JWTSecret).
+		RegisterRoutes(router)
+	routes.NewWorkflowRoutes(handlers.NewWorkflowHandler(workflowService), cfg.Auth.JWTSecret).
+		RegisterRoutes(router, orgContext, demoPlan{})
+	routes.NewTodosRoutes(handlers.NewTodoHandler(todosService), cfg.Auth.JWTSecret).
+		RegisterRoutes(router, cacheMiddleware)
+
+	token, err := auth.GenerateTokenWithExpiry(userID, demoEmail, []string{"user"}, orgID, demoPermissions,
+		cfg.Auth.JWTSecret, demoTokenTTL)
+	if err != nil {
+		log.Fatal("Failed to issue demo token", zap.Error(err))
+	}
+	auth.GetSessionStore().CreateSession(userID, "demo", "127.0.0.1", token, demoTokenTTL)
+
+	if err := seedDemo(context.Background(), userID, orgID, projectID, taskService, habitsService, calendarService,
+		todosService, workflowService); err != nil {
+		log.Fatal("Failed to seed demo data", zap.Error(err))
+	}
+	if activityInterval > 0 {
+		activity := newDemoActivity(userID, orgID, projectID, taskService, todosService, calendarService,
+			activityInterval, logger.Named("demo").Logger)
+		activity.Start()
+		defer activity.Stop()
+	}
+
+	for _, route := range router.Routes() {
+		log.Info("Route registered",
+			zap.String("method", route.Method),
+			zap.String("path", route.Path),
+		)
+	}
+	log.Info("Demo mode: data is kept in memory and lost on exit",
+		zap.String("user_id", userID.String()),
+		zap.String("organization_id", orgID.String()),
+		zap.String("token", token))
+	fmt.Printf("\nDemo token (valid for %s):\n\n  Authorization: Bearer %s\n  %s: %s\n\n",
+		demoTokenTTL, token, middleware.OrganizationHeader, orgID)
+
+	serve(router, cfg.Server, log, backgroundWork)
+}
+
+// seedDemo gives the demo user something to look at in every area
+func seedDemo(ctx context.Context, userID, orgID, projectID uuid.UUID, taskService task.Service, habitsService habits.Service,
+	calendarService calendar.Service, todosService todos.Service, workflowService workflow.Service) error {
+	now := time.Now()
+	tomorrow := now.AddDate(0, 0, 1)
+
+	for _, input := range []task.CreateTaskInput{
+		{Title: "Draft the launch plan", Status: task.TaskStatusInProgress, Priority: task.TaskPriorityHigh, DueDate: &tomorrow},
+		{Title: "Review the landing page copy", Status: task.TaskStatusUpcoming, Priority: task.TaskPriorityMedium},
+		{Title: "Set up the project board", Status: task.TaskStatusCompleted, Priority: task.TaskPriorityLow},
+	} {
+		input.CreatorID = userID
+		input.AssigneeID = &userID
+		input.ProjectID = projectID
+		input.OrganizationID = orgID
+		input.StartDate = now
+		if _, err := taskService.CreateTask(ctx, input); err != nil {
+			return fmt.Errorf("task %q: %w", input.Title, err)
+		}
+	}
+
+	list, err := todosService.GetOrCreateDefaultList(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, title := range []string{"Buy groceries", "Call the dentist", "Read one chapter"} {
+		if _, err := todosService.CreateTodo(ctx, todos.CreateTodoInput{
+			Title:  title,
+			UserID: userID,
+			ListID: list.ID,
+		}); err != nil {
+			return fmt.Errorf("todo %q: %w", title, err)
+		}
+	}
+
+	for _, title := range []string{"Morning walk", "Drink water", "Journal"} {
+		if _, err := habitsService.CreateHabit(ctx, habits.CreateHabitInput{
+			Title:    title,
+			UserID:   userID,
+			StartDay: now.AddDate(0, 0, -7),
+		}); err != nil {
+			return fmt.Errorf("habit %q: %w", title, err)
+		}
+	}
+
+	standup := now.Truncate(time.Hour).Add(time.Hour)
+	if _, err := calendarService.CreateEvent(ctx, calendar.CreateCalendarEventRequest{
+		Title:     "Team standup",
+		EventType: calendar.EventTypeMeeting,
+		StartTime: standup,
+		EndTime:   standup.Add(15 * time.Minute),
+		RecurrenceRule: &calendar.CreateRecurrenceRuleRequest{
+			Freq:     calendar.RecurrenceTypeDaily,
+			Interval: 1,
+		},
+	}, userID); err != nil {
+		return fmt.Errorf("calendar event: %w", err)
+	}
+
+	flow, err := workflowService.CreateWorkflow(ctx, workflow.CreateWorkflowRequest{
+		Name:           "Publish a blog post",
+		WorkflowType:   workflow.WorkflowTypeSequential,
+		OrganizationID: orgID,
+	}, userID)
+	if err != nil {
+		return fmt.Errorf("workflow: %w", err)
+	}
+	for i, step := range []workflow.CreateWorkflowStepRequest{
+		{Name: "Write draft", StepType: workflow.StepTypeAutomated, Config: datatypes.JSON(`{"output":{"title":"$.input.title"}}`)},
+		{Name: "Editor review", StepType: workflow.StepTypeApproval, AssignedTo: &userID},
+	} {
+		step.StepOrder = i + 1
+		if _, err := workflowService.AddWorkflowStep(ctx, flow.Workflow.ID, step); err != nil {
+			return fmt.Errorf("workflow step %q: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// demoMembership makes the demo user a member of the demo organization only
+type demoMembership struct {
+	userID, orgID uuid.UUID
+}
+
+func (m demoMembership) ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*organization.Membership, error) {
+	if orgID != m.orgID {
+		return nil, organization.ErrOrganizationNotFound
+	}
+	if userID != m.userID {
+		return nil, organization.ErrNotMember
+	}
+	return &organization.Membership{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Role:           "admin",
+		Permissions:    demoPermissions,
+	}, nil
+}
+
+// demoPlan allows every feature without limits, as when billing is not configured
+type demoPlan struct{}
+
+func (demoPlan) CheckFeature(ctx context.Context, orgID uuid.UUID, feature billing.Feature) error {
+	return nil
+}
+
+func (demoPlan) CheckQuota(ctx context.Context, orgID uuid.UUID, quota billing.Quota) error {
+	return nil
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// demoOpenTasks and demoOpenTodos are how much unfinished work the generator keeps
+	// around; it creates work below them and finishes work above them
+	demoOpenTasks = 6
+	demoOpenTodos = 5
+	// demoKeepDone is how many finished tasks and todos are kept before the oldest go
+	demoKeepDone = 25
+	// demoUpcomingEvents is how many upcoming events the generator keeps scheduled
+	demoUpcomingEvents = 8
+)
+
+var (
+	demoTaskTitles = []string{
+		"Prepare the quarterly review", "Fix the signup email typo", "Update the pricing page",
+		"Interview a design candidate", "Write release notes", "Plan the team offsite",
+		"Triage support tickets", "Refresh the onboarding checklist", "Review the analytics dashboard",
+		"Draft the customer newsletter", "Clean up stale feature flags", "Sync with the sales team",
+	}
+	demoTodoTitles = []string{
+		"Reply to Sam's email", "Book a meeting room", "Renew the domain", "Order new headphones",
+		"Send the invoice", "Water the plants", "Read the design doc", "Submit expenses",
+	}
+	demoEventTitles = []string{
+		"Design review", "1:1 with manager", "Customer call", "Sprint planning",
+		"Lunch and learn", "Roadmap sync", "Demo prep",
+	}
+	demoPriorities = []task.TaskPriority{task.TaskPriorityLow, task.TaskPriorityMedium, task.TaskPriorityHigh}
+)
+
+// demoActivity keeps the demo organization lively: every interval it makes one change a
+// member could have made, such as starting or finishing a task, ticking off a todo or
+// scheduling a meeting, so the UI has moving data to show. It only touches the in-memory
+// demo repositories.
+type demoActivity struct {
+	userID, orgID, projectID uuid.UUID
+	tasks                    task.Service
+	todos                    todos.Service
+	calendar                 calendar.Service
+	interval                 time.Duration
+	logger                   *zap.Logger
+	rand                     *rand.Rand
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newDemoActivity(userID, orgID, projectID uuid.UUID, taskService task.Service, todosService todos.Service,
+	calendarService calendar.Service, interval time.Duration, logger *zap.Logger) *demoActivity {
+	return &demoActivity{
+		userID:    userID,
+		orgID:     orgID,
+		projectID: projectID,
+		tasks:     taskService,
+		todos:     todosService,
+		calendar:  calendarService,
+		interval:  interval,
+		logger:    logger,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Start makes a change every interval until Stop is called
+func (a *demoActivity) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := a.step(ctx); err != nil && ctx.Err() == nil {
+					a.logger.Warn("Failed to generate demo activity", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop waits for the current change and stops the generator
+func (a *demoActivity) Stop() {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.wg.Wait()
+}
+
+// step makes one change, mostly to tasks
+func (a *demoActivity) step(ctx context.Context) error {
+	switch n := a.rand.Intn(10); {
+	case n < 5:
+		if err := a.taskActivity(ctx); err != nil {
+			return err
+		}
+		// The demo runs no scheduler, so task priority scores are refreshed here
+		_, err := a.tasks.ScorePriorities(ctx)
+		return err
+	case n < 8:
+		return a.todoActivity(ctx)
+	default:
+		return a.eventActivity(ctx)
+	}
+}
+
+// taskActivity moves a task of the demo project a column along the board, or creates one
+// when there is little left to do
+func (a *demoActivity) taskActivity(ctx context.Context) error {
+	all, _, err := a.tasks.ListTasks(ctx, task.TaskFilter{OrganizationID: &a.orgID, ProjectID: &a.projectID})
+	if err != nil {
+		return err
+	}
+	var open, done []task.Task
+	for _, t := range all {
+		switch t.Status {
+		case task.TaskStatusUpcoming, task.TaskStatusInProgress:
+			open = append(open, t)
+		case task.TaskStatusCompleted:
+			done = append(done, t)
+		}
+	}
+
+	if len(done) > demoKeepDone {
+		oldest := done[0]
+		for _, t := range done[1:] {
+			if t.CreatedAt.Before(oldest.CreatedAt) {
+				oldest = t
+			}
+		}
+		if err := a.tasks.DeleteTask(ctx, oldest.ID); err != nil {
+			return err
+		}
+	}
+	if len(open) < demoOpenTasks && (len(open) == 0 || a.rand.Intn(3) == 0) {
+		return a.createTask(ctx)
+	}
+
+	t := open[a.rand.Intn(len(open))]
+	if t.Status == task.TaskStatusUpcoming {
+		_, err = a.tasks.UpdateTaskStatus(ctx, t.ID, task.TaskStatusInProgress)
+		return err
+	}
+	// Work is logged a few times before a task is done
+	if a.rand.Intn(3) > 0 {
+		_, err = a.tasks.LogWork(ctx, t.ID, a.userID, float64(1+a.rand.Intn(4))/2, "")
+		return err
+	}
+	_, err = a.tasks.UpdateTaskStatus(ctx, t.ID, task.TaskStatusCompleted)
+	return err
+}
+
+func (a *demoActivity) createTask(ctx context.Context) error {
+	now := time.Now()
+	due := now.AddDate(0, 0, 1+a.rand.Intn(10))
+	_, err := a.tasks.CreateTask(ctx, task.CreateTaskInput{
+		Title:          demoTaskTitles[a.rand.Intn(len(demoTaskTitles))],
+		Status:         task.TaskStatusUpcoming,
+		Priority:       demoPriorities[a.rand.Intn(len(demoPriorities))],
+		CreatorID:      a.userID,
+		AssigneeID:     &a.userID,
+		ProjectID:      a.projectID,
+		OrganizationID: a.orgID,
+		StartDate:      now,
+		DueDate:        &due,
+	})
+	return err
+}
+
+// todoActivity ticks off a todo of the demo user, or adds one when few are left
+func (a *demoActivity) todoActivity(ctx context.Context) error {
+	all, err := a.todos.FindByUserID(ctx, a.userID)
+	if err != nil {
+		return err
+	}
+	var open, done []todos.Todo
+	for _, t := range all {
+		if t.IsCompleted {
+			done = append(done, t)
+		} else {
+			open = append(open, t)
+		}
+	}
+
+	if len(done) > demoKeepDone {
+		oldest := done[0]
+		for _, t := range done[1:] {
+			if t.CreatedAt.Before(oldest.CreatedAt) {
+				oldest = t
+			}
+		}
+		if err := a.todos.DeleteTodo(ctx, oldest.ID); err != nil {
+			return err
+		}
+	}
+	if len(open) < demoOpenTodos && (len(open) == 0 || a.rand.Intn(2) == 0) {
+		list, err := a.todos.GetOrCreateDefaultList(ctx, a.userID)
+		if err != nil {
+			return err
+		}
+		_, err = a.todos.CreateTodo(ctx, todos.CreateTodoInput{
+			Title:  demoTodoTitles[a.rand.Intn(len(demoTodoTitles))],
+			UserID: a.userID,
+			ListID: list.ID,
+		})
+		return err
+	}
+	_, err = a.todos.CompleteTodo(ctx, open[a.rand.Intn(len(open))].ID)
+	return err
+}
+
+// eventActivity schedules a meeting in the next few working hours or days, unless the
+// calendar is already full enough
+func (a *demoActivity) eventActivity(ctx context.Context) error {
+	events, err := a.calendar.GetUpcomingEvents(ctx, a.userID, 0)
+	if err != nil {
+		return err
+	}
+	// The seeded standup repeats forever and does not count
+	upcoming := 0
+	for _, event := range events {
+		if len(event.RecurrenceRules) == 0 && event.StartTime.After(time.Now()) {
+			upcoming++
+		}
+	}
+	if upcoming >= demoUpcomingEvents {
+		return nil
+	}
+
+	start := time.Now().Truncate(time.Hour).Add(time.Duration(1+a.rand.Intn(72)) * time.Hour)
+	if start.Hour() < 9 || start.Hour() > 17 {
+		start = time.Date(start.Year(), start.Month(), start.Day()+1, 9+a.rand.Intn(8), 0, 0, 0, start.Location())
+	}
+	length := time.Duration(1+a.rand.Intn(2)) * 30 * time.Minute
+	title := demoEventTitles[a.rand.Intn(len(demoEventTitles))]
+	if _, err := a.calendar.CreateEvent(ctx, calendar.CreateCalendarEventRequest{
+		Title:     title,
+		EventType: calendar.EventTypeMeeting,
+		StartTime: start,
+		EndTime:   start.Add(length),
+	}, a.userID); err != nil {
+		return fmt.Errorf("event %q: %w", title, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,26 +15,74 @@ import (
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/routes"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/accountmerge"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/activity"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/agenda"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/announcements"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/apikeys"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/attachments"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/automation"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/avatars"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/badges"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/baselines"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/chat"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/commands"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/devices"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/focus"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habitlinks"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/inbound"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/integrations"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/legal"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/meetingnotes"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/memberimport"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/onboarding"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/orgunits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/presence"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/projectcopy"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/projections"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/quickadd"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/retention"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/settings"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/tags"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/timetracking"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/timezone"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/trash"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/usage"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/vcs"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workflow"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workitems"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/email"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/plugins"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/realtime"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/migrations"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/providers"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/scheduler"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/storage"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/background"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/broker"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/config"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
@@ -80,7 +129,116 @@ func RequestLoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
 	}
 }
 
+// chatProviderConfig converts the config file settings of a chat app to the provider configuration
+func chatProviderConfig(c config.ChatProviderConfig) chat.ProviderConfig {
+	return chat.ProviderConfig{
+		ClientID:      c.ClientID,
+		ClientSecret:  c.ClientSecret,
+		RedirectURL:   c.RedirectURL,
+		SigningSecret: c.SigningSecret,
+	}
+}
+
+func mailerConfig(c config.EmailConfig) email.Config {
+	return email.Config{
+		Provider: c.Provider,
+		From:     email.Address{Name: c.FromName, Email: c.FromAddress},
+		AppURL:   c.AppURL,
+		SMTP: email.SMTPConfig{
+			Host:     c.SMTPHost,
+			Port:     c.SMTPPort,
+			Username: c.SMTPUsername,
+			Password: c.SMTPPassword,
+		},
+		APIKey: c.APIKey,
+	}
+}
+
+func storageConfig(c config.StorageConfig) storage.Config {
+	return storage.Config{
+		Driver:   c.Driver,
+		LocalDir: c.LocalDir,
+		S3: storage.S3Config{
+			Endpoint:     c.S3.Endpoint,
+			Region:       c.S3.Region,
+			Bucket:       c.S3.Bucket,
+			AccessKey:    c.S3.AccessKey,
+			SecretKey:    c.S3.SecretKey,
+			UsePathStyle: c.S3.UsePathStyle,
+		},
+	}
+}
+
+// attachmentScanner returns the configured malware scanner, or nil when uploads are not
+// scanned
+func attachmentScanner(c config.ScanConfig) (attachments.Scanner, error) {
+	switch c.Driver {
+	case "":
+		return nil, nil
+	case "clamav":
+		if c.ClamAVAddress == "" {
+			return nil, fmt.Errorf("scan driver clamav needs a clamav_address")
+		}
+		return attachments.NewClamAVScanner(c.ClamAVAddress), nil
+	case "http":
+		if c.URL == "" {
+			return nil, fmt.Errorf("scan driver http needs a url")
+		}
+		return attachments.NewHTTPScanner(c.URL, c.APIKey), nil
+	default:
+		return nil, fmt.Errorf("unknown scan driver %q", c.Driver)
+	}
+}
+
+// llmProviders returns the configurations of the LLM providers that have credentials
+func llmProviders(c config.AIConfig) map[string]providers.Config {
+	configs := make(map[string]providers.Config)
+	convert := func(p config.AIProviderConfig) providers.Config {
+		return providers.Config{APIKey: p.APIKey, BaseURL: p.BaseURL, Model: p.Model, EmbeddingModel: p.EmbeddingModel}
+	}
+	if c.OpenAI.APIKey != "" {
+		configs[providers.OpenAI] = convert(c.OpenAI)
+	}
+	if c.Anthropic.APIKey != "" {
+		configs[providers.Anthropic] = convert(c.Anthropic)
+	}
+	if c.Ollama.BaseURL != "" {
+		configs[providers.Ollama] = convert(c.Ollama)
+	}
+	return configs
+}
+
+func billingConfig(c config.BillingConfig) billing.Config {
+	prices := make(map[billing.PlanName]string, len(c.Prices))
+	for plan, price := range c.Prices {
+		prices[billing.PlanName(plan)] = price
+	}
+	return billing.Config{
+		Prices:          prices,
+		PortalReturnURL: c.PortalReturnURL,
+	}
+}
+
+func legalDocuments(c config.LegalConfig) []legal.Document {
+	document := func(t legal.DocumentType, d config.LegalDocumentConfig) legal.Document {
+		doc := legal.Document{Type: t, Version: d.Version, URL: d.URL}
+		if effective, err := time.Parse("2006-01-02", d.EffectiveDate); err == nil {
+			doc.EffectiveDate = &effective
+		}
+		return doc
+	}
+	return []legal.Document{
+		document(legal.DocumentTerms, c.Terms),
+		document(legal.DocumentPrivacy, c.Privacy),
+	}
+}
+
 func main() {
+	migrate := flag.Bool("migrate", false, "apply pending database migrations and exit")
+	demo := flag.Bool("demo", false, "serve tasks, todos, habits, calendar and workflows from memory, without Postgres")
+	demoActivity := flag.Duration("demo-activity", 15*time.Second, "how often the demo generates task, todo and event activity; 0 turns it off")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.LoadConfig("") // Empty string will make it search in default locations
 	if err != nil {
@@ -88,6 +246,13 @@ func main() {
 	}
 
 	// Initialize logger
+	if err := logger.Setup(logger.Config{
+		Level:   cfg.Logging.Level,
+		Format:  cfg.Logging.Format,
+		Modules: cfg.Logging.Modules,
+	}); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
 	log := logger.NewLogger()
 	defer log.Sync()
 
@@ -123,6 +288,15 @@ func main() {
 	// Add middleware
 	router.Use(gin.Recovery())
 	router.Use(RequestLoggerMiddleware(log))
+	// Attach request/trace IDs so they follow async work such as workflow execution
+	router.Use(middleware.NewTracingMiddleware().TraceRequest())
+	// Give every error response a machine-readable code and the request ID
+	router.Use(middleware.ErrorEnvelope())
+	// Record where requests come from in the audit entries they produce
+	router.Use(middleware.AuditClient())
+	// Request metrics, checked against the latency objectives of their route group
+	slos := middleware.NewSLOs(cfg.SLO, logger.Named("slo").Logger)
+	router.Use(middleware.NewMetricsMiddleware().WithSLOs(slos).CollectMetrics())
 	// Configure gin to use proper content type for JSON
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -137,9 +311,14 @@ func main() {
 			"Content-Type",
 			"Authorization",
 			"X-Organization-ID",
+			middleware.APIKeyHeader,
 			"x-organization-id",
 			"X-Forwarded-For",
 			"X-Real-IP",
+			middleware.RequestIDHeader,
+			middleware.TraceIDHeader,
+			middleware.SpanIDHeader,
+			middleware.ParentSpanIDHeader,
 		),
 		ExposeHeaders: []string{
 			"Content-Length",
@@ -147,8 +326,16 @@ func main() {
 			"Content-Type",
 			"X-RateLimit-Remaining",
 			"X-RateLimit-Reset",
+			middleware.RateLimitLimitHeader,
+			middleware.RateLimitRemainingHeader,
+			middleware.RateLimitResetHeader,
+			middleware.RateLimitPolicyHeader,
+			"Retry-After",
 			"Vary",
 			"X-Organization-ID",
+			middleware.RequestIDHeader,
+			middleware.TraceIDHeader,
+			middleware.SpanIDHeader,
 		},
 		AllowCredentials: cfg.CORS.AllowCredentials,
 		MaxAge:           12 * time.Hour,
@@ -157,26 +344,40 @@ func main() {
 	// Add Prometheus metrics endpoint
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// The demo server keeps its data in memory and never touches the database
+	if *demo {
+		slos.Start()
+		defer slos.Stop()
+		runDemo(cfg, log, router, *demoActivity)
+		return
+	}
+
 	// Connect to database
 	db, err := connection.NewDatabase(cfg)
 	if err != nil {
 		log.Fatal("Failed to connect to database", zap.Error(err))
 	}
 
-	// Run database migrations
-	if err := migrations.AutoMigrate(db, log.Logger); err != nil {
-		log.Fatal("Failed to run database migrations", zap.Error(err))
+	// Apply migrations explicitly when requested, then exit
+	if *migrate {
+		if err := migrations.AutoMigrate(db, log.Logger); err != nil {
+			log.Fatal("Failed to run database migrations", zap.Error(err))
+		}
+		log.Info("Database migrations applied")
+		return
 	}
 
-	// Initialize logrus logger for workflow service
-	workflowLogger := logrus.New()
-	workflowLogger.SetFormatter(&logrus.JSONFormatter{})
-	if cfg.Server.Mode == "production" {
-		workflowLogger.SetLevel(logrus.InfoLevel)
-	} else {
-		workflowLogger.SetLevel(logrus.DebugLevel)
+	// Run database migrations, or refuse to start on a stale schema in strict mode
+	if cfg.Database.MigrationMode == migrations.ModeStrict {
+		if err := migrations.EnsureUpToDate(db, log.Logger); err != nil {
+			log.Fatal("Refusing to start with an out-of-date database schema", zap.Error(err))
+		}
+	} else if err := migrations.AutoMigrate(db, log.Logger); err != nil {
+		log.Fatal("Failed to run database migrations", zap.Error(err))
 	}
 
+	workflowLogger := logger.Named("workflow").Logger
+
 	// Initialize repositories
 	taskRepo := task.NewRepository(db)
 	userRepo := user.NewRepository(db)
@@ -187,6 +388,20 @@ func main() {
 	calendarRepo := calendar.NewRepository(db.DB)
 	workflowRepo := workflow.NewRepository(db.DB, workflowLogger)
 	todosRepo := todos.NewTodoRepository(db)
+	onboardingRepo := onboarding.NewRepository(db)
+	activityRepo := activity.NewRepository(db)
+	webhookRepo := webhooks.NewRepository(db)
+	announcementRepo := announcements.NewRepository(db)
+	inboundRepo := inbound.NewRepository(db)
+	chatRepo := chat.NewRepository(db)
+	searchRepo := search.NewRepository(db)
+	vcsRepo := vcs.NewRepository(db)
+	automationRepo := automation.NewRepository(db)
+	timezoneRepo := timezone.NewRepository(db)
+	billingRepo := billing.NewRepository(db)
+	meteringRepo := metering.NewRepository(db)
+	legalRepo := legal.NewRepository(db)
+	integrationRepo := integrations.NewRepository(db)
 
 	// Initialize Redis
 	redisConfig := cache.NewConfigFromEnv(cfg)
@@ -198,6 +413,14 @@ func main() {
 
 	// Initialize rate limiter with Redis client
 	rateLimiter := auth.NewRedisRateLimiter(redisClient.GetClient(), 1*time.Minute, 1000)
+	// Tiered token-bucket limits per caller, route group and organization
+	rateLimits := middleware.NewRateLimits(redisClient, cfg.Auth.JWTSecret, cfg.RateLimit)
+
+	// Reject changes while an admin has switched the API, or part of it, to read-only.
+	// Admin routes and sign-in stay writable so the switch can always be turned off.
+	maintenanceMode := middleware.NewMaintenanceMode(redisClient,
+		"/api/admin/", "/api/users/login", "/api/users/refresh", "/api/users/logout", "/api/auth/")
+	router.Use(maintenanceMode.Guard())
 
 	// Create cache middleware instances
 	cacheMiddleware := middleware.NewCacheMiddleware(redisClient, "compass", 5*time.Minute)
@@ -206,72 +429,370 @@ func main() {
 	// Initialize routes
 	authMiddleware := middleware.NewAuthMiddleware(cfg.Auth.JWTSecret)
 
+	// Outgoing email, used by notifications and account emails
+	emailConfig := mailerConfig(cfg.Email)
+	emailSender, err := email.NewSender(emailConfig, log.Logger)
+	if err != nil {
+		log.Fatal("Failed to configure email", zap.Error(err))
+	}
+	mailer, err := email.NewMailer(emailSender, emailConfig)
+	if err != nil {
+		log.Fatal("Failed to load email templates", zap.Error(err))
+	}
+
 	// Initialize notification system
 	notificationSystem, err := SetupNotificationSystem(
 		db,
 		log,
-		cfg.Server.Mode != "production",
+		mailer,
+		user.NewEmailRecipients(userRepo),
 	)
 	if err != nil {
 		log.Fatal("Failed to initialize notification system", zap.Error(err))
 	}
 	defer notificationSystem.Shutdown()
 
+	// Operators configured for it are notified when a route group burns its latency budget
+	sloAlerter, err := newSLOAlertNotifier(notificationSystem.DomainNotifier, cfg.SLO)
+	if err != nil {
+		log.Fatal("Failed to configure latency SLO alerts", zap.Error(err))
+	}
+	slos.WithAlerter(sloAlerter).Start()
+	defer slos.Stop()
+
 	// Initialize habit notification service using the notification service from our system
 	habitNotifySvc := habits.NewHabitNotificationService(notificationSystem.Service)
 	// Add domain notifier for enhanced capabilities
 	habitNotifySvc.WithDomainNotifier(notificationSystem.DomainNotifier)
 
 	// Initialize services
+	// Sign-ins, role changes, task deletions and workflow executions go to the audit log
+	auditService := audit.NewService(audit.NewRepository(db), log.Logger)
+	auditService.Start()
+	defer auditService.Stop()
 	rolesService := roles.NewService(rolesRepo)
-	userService := user.NewService(userRepo, rolesService, redisClient)
-	taskService := task.NewService(taskRepo, redisClient, log.Logger)
-	projectService := project.NewService(projectRepo)
-	organizationService := organization.NewService(organizationRepo)
-	habitsService := habits.NewService(habitsRepo, habitNotifySvc, redisClient, log.Logger)
-	calendarService := calendar.NewService(calendarRepo, notificationSystem.DomainNotifier, redisClient, log.Logger)
-	workflowExecutor := workflow.NewDefaultExecutor(workflowRepo, workflowLogger, notificationSystem.DomainNotifier, rolesService)
+	refreshTokenService := auth.NewRefreshTokenService(auth.NewRefreshTokenStore(db.DB), cfg.Auth.JWTSecret,
+		cfg.Auth.AccessTokenTTL(), cfg.Auth.RefreshTokenTTL())
+	userService := user.NewService(userRepo, rolesService, redisClient, refreshTokenService, auditService)
+	organizationService := organization.NewService(organizationRepo, rolesService, auditService)
+	orgContext := middleware.NewOrganizationContext(organizationService)
+	invitationService := organization.NewInvitationService(organization.NewInvitationRepository(db),
+		organizationService, rolesService, organization.NewEmailInvitationSender(mailer), log.Logger)
+	activityService := activity.NewService(activityRepo, organizationService, log.Logger)
+	webhookDispatcher := webhooks.NewDispatcher(webhookRepo, webhooks.DefaultDispatcherConfig(), log.Logger)
+	webhookDispatcher.Start()
+	defer webhookDispatcher.Stop()
+
+	// Metered usage is aggregated in memory and written periodically
+	meteringPipeline := metering.NewPipeline(meteringRepo, metering.DefaultPipelineConfig(), log.Logger)
+	meteringPipeline.Start()
+	defer meteringPipeline.Stop()
+	meteringService := metering.NewService(meteringRepo, meteringPipeline)
+	rateLimits.WithRecorder(meteringPipeline)
+
+	// Users who have not accepted the current terms and privacy policy can only reach
+	// the legal endpoints, sign out, and view or delete their account
+	legalService := legal.NewService(legalRepo, legalDocuments(cfg.Legal))
+	middleware.UseConsentGate(middleware.NewConsentGate(legalService,
+		"/api/legal/", "/api/users/logout", "/api/users/profile", "/api/users/sessions"))
+	webhookService := webhooks.NewService(webhookRepo)
+
+	// Chat apps receive the same domain events as outbound webhooks
+	var chatProviders []chat.Provider
+	if slack := chatProviderConfig(cfg.Chat.Slack); slack.Enabled() {
+		chatProviders = append(chatProviders, chat.NewSlackProvider(slack))
+	}
+	if teams := chatProviderConfig(cfg.Chat.Teams); teams.Enabled() {
+		chatProviders = append(chatProviders, chat.NewTeamsProvider(teams))
+	}
+	integrationHealth := integrations.NewRecorder(integrationRepo, log.Logger)
+	chatNotifier := chat.NewNotifier(chatRepo, chatProviders, cfg.Chat.AppURL, integrationHealth, log.Logger)
+
+	// Stream entity changes to connected clients on every API instance
+	realtimeHub := realtime.NewHub(redisClient, organizationService, projectRepo, log.Logger)
+	realtimeHub.Start()
+	defer realtimeHub.Stop()
+
+	// Badge counts are pushed to users as the events and notifications affecting them arrive
+	badgeService := badges.NewService(badges.NewRepository(db), userService, redisClient, realtimeHub, log.Logger)
+	badgeService.Watch(notificationSystem.SignalRepository)
+	badgeService.Start()
+	defer badgeService.Stop()
+
+	// Tasks and todos are embedded for semantic search as they change
+	llmResolver := providers.NewResolver(cfg.AI.Provider, llmProviders(cfg.AI), organization.NewProviderSettings(organizationService))
+	var searchEmbedder search.Embedder
+	if llmResolver.Enabled() {
+		searchEmbedder = search.NewProviderEmbedder(llmResolver)
+	}
+	searchIndexer := search.NewIndexer(searchRepo, searchEmbedder, search.DefaultIndexerConfig(), log.Logger)
+	searchIndexer.Start()
+	defer searchIndexer.Stop()
+
+	eventPublisher := webhooks.Publishers{webhookDispatcher, chatNotifier, realtimeHub, searchIndexer, badgeService}
+
+	// Domain events connect the services that produce them to the features reacting to them
+	eventBus := events.NewBus(events.DefaultBusConfig(), log.Logger)
+	habitNotifySvc.Subscribe(eventBus)
+	calendar.SubscribeRescheduleNotifications(eventBus, calendarRepo, notificationSystem.DomainNotifier)
+	task.SubscribeAssignmentNotifications(eventBus, notificationSystem.DomainNotifier)
+	task.SubscribeRiskNotifications(eventBus, notificationSystem.DomainNotifier)
+	todos.SubscribeGeofenceNotifications(eventBus, notificationSystem.DomainNotifier)
+	attachments.SubscribeInfectionNotifications(eventBus, organizationService, notificationSystem.DomainNotifier)
+	// Dashboard read models are projected from task, todo and habit events
+	projectionService := projections.NewService(projections.NewRepository(db), log.Logger)
+	projectionService.Subscribe(eventBus)
+	eventBus.Start()
+	defer eventBus.Stop()
+
+	// Plugins extend the core services through lifecycle hooks and their own routes
+	pluginRegistry := plugins.NewRegistry(log.Logger)
+	if err := pluginRegistry.Load(cfg.Plugins.Dir); err != nil {
+		log.Fatal("Failed to load plugins", zap.Error(err))
+	}
+
+	// Projects and workflows inherit the organization's default settings unless they override them
+	settingsService := settings.NewService(settings.NewRepository(db))
+	slaService := sla.NewService(sla.NewRepository(db), settingsService)
+	taskService := task.NewService(taskRepo, redisClient, activityService, eventPublisher, pluginRegistry, eventBus, cacheMiddleware, slaService, settingsService, auditService, log.Logger)
+	projectService := project.NewService(projectRepo, activityService, cacheMiddleware)
+	orgUnitService := orgunits.NewService(orgunits.NewRepository(db), projectService, rolesService, organizationService)
+	habitsService := habits.NewService(habitsRepo, habitNotifySvc, redisClient, eventPublisher, eventBus, logger.Named("habits").Logger)
+	calendarService := calendar.NewService(calendarRepo, userRepo, notificationSystem.DomainNotifier, redisClient, eventBus, log.Logger)
+	// Work requests leave running, like workflow steps, is drained on shutdown
+	backgroundWork := background.NewGroup(log.Logger)
+	workflowRecovery := workflow.DefaultRecoveryConfig()
+	workflowExecutor := workflow.NewDefaultExecutor(workflowRepo, workflowLogger, notificationSystem.DomainNotifier, rolesService).
+		WithWebhooks(eventPublisher).
+		WithHooks(pluginRegistry).
+		WithBackground(backgroundWork).
+		WithRecovery(workflowRecovery)
+	if llmResolver.Enabled() {
+		workflowExecutor.WithAI(workflow.NewLLMRunner(llmResolver))
+	}
 	workflowService := workflow.NewService(workflow.ServiceConfig{
 		Repository:   workflowRepo,
 		Logger:       workflowLogger,
 		Executor:     workflowExecutor,
 		RolesService: rolesService,
 		Notifier:     notificationSystem.DomainNotifier,
+		Activity:     activityService,
+		Webhooks:     eventPublisher,
+		Usage:        meteringPipeline,
+		Audit:        auditService,
+		Defaults:     settingsService,
+		Background:   backgroundWork,
 	})
-	todosService := todos.NewService(todosRepo, redisClient, log.Logger)
+	todosService := todos.NewService(todosRepo, redisClient, eventPublisher, eventBus, cacheMiddleware, log.Logger)
+	deviceService := devices.NewService(devices.NewRepository(db))
+	// Machine clients may authenticate with an X-API-Key header instead of a bearer token
+	apiKeyService := apikeys.NewService(apikeys.NewRepository(db), userService, log.Logger)
+	middleware.UseAPIKeys(apiKeyService)
+	usageService := usage.NewService(meteringService, rateLimits, auth.GetSessionStore(), refreshTokenService, apiKeyService, webhookService)
+	// Files attached to tasks, todos and comments
+	attachmentStore, err := storage.NewStore(storageConfig(cfg.Storage))
+	if err != nil {
+		log.Fatal("Failed to configure attachment storage", zap.Error(err))
+	}
+	scanner, err := attachmentScanner(cfg.Storage.Scan)
+	if err != nil {
+		log.Fatal("Failed to configure attachment scanning", zap.Error(err))
+	}
+	var pdfRenderer attachments.PDFRenderer
+	if cfg.Storage.PDFToPPMPath != "" {
+		pdfRenderer = attachments.NewPopplerRenderer(cfg.Storage.PDFToPPMPath)
+	}
+	attachmentService := attachments.NewService(attachments.NewRepository(db), attachmentStore, attachments.Config{
+		MaxSize:      int64(cfg.Storage.MaxUploadMB) << 20,
+		AllowedTypes: cfg.Storage.AllowedTypes,
+		Scanner:      scanner,
+		PDFRenderer:  pdfRenderer,
+	}, eventBus, log.Logger)
+	attachmentWorker := attachments.NewWorker(attachmentService, log.Logger)
+	attachmentWorker.Start()
+	defer attachmentWorker.Stop()
+	// Avatars share the attachment store
+	avatarService := avatars.NewService(attachmentStore, userService, log.Logger)
+	geofenceService := todos.NewGeofenceService(todos.NewGeofenceRepository(db), todosRepo, deviceService, eventBus, log.Logger)
+	workflowExecutor.WithWorkItems(workitems.NewService(taskService, todosService, calendarService))
+	// Steps the last shutdown interrupted run again now that the executor is complete
+	if resumed, err := workflowService.ResumeInterrupted(context.Background()); err != nil {
+		log.Error("Failed to resume interrupted workflow steps", zap.Error(err))
+	} else if resumed > 0 {
+		log.Info("Resumed interrupted workflow steps", zap.Int("count", resumed))
+	}
+	// Steps of a process that crashed stay active until their heartbeat goes stale
+	workflowRecoveryWorker := workflow.NewRecoveryWorker(workflowService, workflowRecovery, log.Logger)
+	workflowRecoveryWorker.Start()
+	defer workflowRecoveryWorker.Stop()
+	onboardingService := onboarding.NewService(onboardingRepo, organizationService, invitationService, rolesService, projectService, taskService, habitsService, log.Logger)
+	commandService := commands.NewService(taskService, projectService, todosService, organizationService)
+	presenceService := presence.NewService(redisClient, log.Logger)
+	announcementService := announcements.NewService(announcementRepo, notificationSystem.DomainNotifier, log.Logger)
+	inboundService := inbound.NewService(inboundRepo, todosService, userService, redisClient, meteringPipeline,
+		inbound.DefaultConfig(cfg.Inbound.Domain), log.Logger)
+	inboundWorker := inbound.NewWorker(inboundService, redisClient, log.Logger)
+	inboundWorker.Start()
+	defer inboundWorker.Stop()
+	memberImportService := memberimport.NewService(memberimport.NewRepository(db), organizationService,
+		invitationService, userService, log.Logger)
+	memberImportWorker := memberimport.NewWorker(memberImportService, log.Logger)
+	memberImportWorker.Start()
+	defer memberImportWorker.Stop()
+	reminderWorker := calendar.NewReminderWorker(calendarRepo, []calendar.ReminderChannel{
+		calendar.NewEmailReminderChannel(notificationSystem.DomainNotifier),
+		calendar.NewPushReminderChannel(notificationSystem.DomainNotifier),
+		calendar.NewWebhookReminderChannel(),
+	}, calendar.DefaultReminderWorkerConfig(), log.Logger)
+	reminderWorker.Start()
+	defer reminderWorker.Stop()
+	searchService := search.NewService(searchRepo, searchEmbedder, organization.NewSearchSettings(organizationService))
+	tagService := tags.NewService(tags.NewRepository(db))
+	chatService := chat.NewService(chatRepo, chatProviders, projectService, commandService, userService,
+		organizationService, redisClient, cfg.Chat.AppURL, log.Logger)
+	automationService := automation.NewService(automationRepo)
+	vcsService := vcs.NewService(vcsRepo, taskService, projectService, cfg.Chat.AppURL,
+		cfg.VCS.AllowedHosts, integrationHealth, log.Logger)
+	integrationService := integrations.NewService(integrationRepo,
+		chat.NewIntegrationSource(chatRepo, chatService),
+		vcs.NewIntegrationSource(vcsRepo, vcsService))
+	timezoneService := timezone.NewService(timezoneRepo, redisClient, log.Logger)
+	agendaService := agenda.NewService(calendarService, taskService, habitsService, userService)
+	quickAddService := quickadd.NewService(calendarService, taskService, projectService, userService)
+	habitLinkService := habitlinks.NewService(habitlinks.NewRepository(db), habitsService, taskService, todosService, log.Logger)
+	habitLinkService.Subscribe(eventBus)
+	focusService := focus.NewService(focus.NewRepository(db), taskService, habitsService, userService)
+
+	var actionItemExtractor meetingnotes.Extractor = meetingnotes.NewChecklistExtractor()
+	if llmResolver.Enabled() {
+		actionItemExtractor = meetingnotes.NewLLMExtractor(llmResolver, actionItemExtractor, log.Logger)
+	}
+	meetingNoteService := meetingnotes.NewService(meetingnotes.NewRepository(db), calendarService, taskService, todosService,
+		projectService, organizationService, actionItemExtractor, log.Logger)
+	baselineService := baselines.NewService(baselines.NewRepository(db), taskService)
+	timeTrackingService := timetracking.NewService(timetracking.NewRepository(db), taskService, log.Logger)
+	projectCopyService := projectcopy.NewService(projectcopy.NewRepository(db), projectService, cacheMiddleware, log.Logger)
+	projectCopyWorker := projectcopy.NewWorker(projectCopyService, log.Logger)
+	projectCopyWorker.Start()
+	defer projectCopyWorker.Stop()
+
+	// Billing stays disabled, without plan limits, until Stripe is configured
+	var billingProvider billing.Provider
+	if cfg.Billing.StripeSecretKey != "" {
+		billingProvider, err = billing.NewStripeProvider(billing.StripeConfig{
+			SecretKey:     cfg.Billing.StripeSecretKey,
+			WebhookSecret: cfg.Billing.StripeWebhookSecret,
+		})
+		if err != nil {
+			log.Fatal("Failed to configure billing", zap.Error(err))
+		}
+	}
+	billingService := billing.NewService(billingRepo, billingProvider, organizationService, meteringService, billingConfig(cfg.Billing), log.Logger)
 
 	// Initialize OAuth2 service
 	oauthService := auth.NewOAuthService(cfg)
 
 	// Initialize MFA service and handler
-	// Create a logrus logger specifically for MFA handler
-	mfaLogger := logrus.New()
-	mfaLogger.SetFormatter(&logrus.JSONFormatter{})
-	if cfg.Server.Mode == "production" {
-		mfaLogger.SetLevel(logrus.InfoLevel)
-	} else {
-		mfaLogger.SetLevel(logrus.DebugLevel)
-	}
-
-	mfaHandler := handlers.NewMFAHandler(userService, cfg.Auth.JWTSecret, mfaLogger)
+	mfaHandler := handlers.NewMFAHandler(userService, refreshTokenService, cfg.Auth.JWTSecret, logger.Logrus("mfa"))
 
 	// Initialize and start the scheduler
-	habitScheduler := scheduler.NewScheduler(habitsService, log)
+	schedulerConfig := scheduler.DefaultConfig()
+	if cfg.Scheduler.HabitReset != "" {
+		schedulerConfig.HabitResetSchedule = cfg.Scheduler.HabitReset
+	}
+	if cfg.Scheduler.HabitReminders != "" {
+		schedulerConfig.HabitReminderSchedule = cfg.Scheduler.HabitReminders
+	}
+	if cfg.Scheduler.TodoRecurrence != "" {
+		schedulerConfig.TodoRecurrenceSchedule = cfg.Scheduler.TodoRecurrence
+	}
+	if cfg.Scheduler.TaskRisk != "" {
+		schedulerConfig.TaskRiskSchedule = cfg.Scheduler.TaskRisk
+	}
+	if cfg.Scheduler.TaskPriority != "" {
+		schedulerConfig.TaskPrioritySchedule = cfg.Scheduler.TaskPriority
+	}
+	if cfg.Scheduler.TrashPurge != "" {
+		schedulerConfig.TrashPurgeSchedule = cfg.Scheduler.TrashPurge
+	}
+	if cfg.Scheduler.Retention != "" {
+		schedulerConfig.RetentionSchedule = cfg.Scheduler.Retention
+	}
+	if cfg.Scheduler.Projections != "" {
+		schedulerConfig.ProjectionsSchedule = cfg.Scheduler.Projections
+	}
+	if cfg.Scheduler.TrashRetentionDays > 0 {
+		schedulerConfig.TrashRetention = time.Duration(cfg.Scheduler.TrashRetentionDays) * 24 * time.Hour
+	}
+	if cfg.Scheduler.Timezone != "" {
+		location, err := time.LoadLocation(cfg.Scheduler.Timezone)
+		if err != nil {
+			log.Fatal("Invalid scheduler timezone", zap.Error(err))
+		}
+		schedulerConfig.Location = location
+	}
+	retentionService := retention.NewService(retention.NewRepository(db), auditService, organizationService, attachmentService, notificationSystem.DomainNotifier, log.Logger)
+	habitScheduler, err := scheduler.NewScheduler(habitsService, todosService, taskService, retentionService, projectionService, redisClient, schedulerConfig, log)
+	if err != nil {
+		log.Fatal("Failed to create habit scheduler", zap.Error(err))
+	}
 	habitScheduler.Start()
+	defer habitScheduler.Stop()
 	log.Info("Habit scheduler started successfully")
 
+	trashService := trash.NewService(taskService, todosService, schedulerConfig.TrashRetention)
+
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userService, cfg.Auth.JWTSecret)
-	taskHandler := handlers.NewTaskHandler(taskService)
+	userHandler := handlers.NewUserHandler(userService, refreshTokenService, cfg.Auth.JWTSecret)
+	avatarHandler := handlers.NewAvatarHandler(avatarService)
+	taskHandler := handlers.NewTaskHandler(taskService, presenceService).WithAttachments(attachmentService).WithUsers(userService)
 	authHandler := handlers.NewAuthHandler(rolesService)
 	projectHandler := handlers.NewProjectHandler(projectService)
+	orgUnitHandler := handlers.NewOrgUnitHandler(orgUnitService)
 	organizationHandler := handlers.NewOrganizationHandler(organizationService)
 	habitsHandler := handlers.NewHabitsHandler(habitsService)
 	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	availabilityHandler := handlers.NewAvailabilityHandler(calendarService, organizationService, userService)
 	workflowHandler := handlers.NewWorkflowHandler(workflowService)
-	todosHandler := handlers.NewTodoHandler(todosService)
-
-	oauthHandler := handlers.NewOAuthHandler(oauthService, userService, cfg.Auth.JWTSecret, log.Logger)
+	todosHandler := handlers.NewTodoHandler(todosService).WithAttachments(attachmentService)
+	geofenceHandler := handlers.NewGeofenceHandler(geofenceService)
+	deviceHandler := handlers.NewDeviceHandler(deviceService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	onboardingHandler := handlers.NewOnboardingHandler(onboardingService)
+	commandHandler := handlers.NewCommandHandler(commandService)
+	activityHandler := handlers.NewActivityHandler(activityService, projectService, taskService)
+	presenceHandler := handlers.NewPresenceHandler(presenceService, organizationService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	announcementHandler := handlers.NewAnnouncementHandler(announcementService)
+	inboundHandler := handlers.NewInboundHandler(inboundService, cfg.Inbound.Secret)
+	chatHandler := handlers.NewChatHandler(chatService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	vcsHandler := handlers.NewVCSHandler(vcsService)
+	integrationHandler := handlers.NewIntegrationHandler(integrationService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	memberImportHandler := handlers.NewMemberImportHandler(memberImportService)
+	automationHandler := handlers.NewAutomationHandler(automationService)
+	timezoneHandler := handlers.NewTimezoneHandler(timezoneService)
+	agendaHandler := handlers.NewAgendaHandler(agendaService)
+	quickAddHandler := handlers.NewQuickAddHandler(quickAddService)
+	habitLinkHandler := handlers.NewHabitLinkHandler(habitLinkService)
+	focusHandler := handlers.NewFocusHandler(focusService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	meetingNoteHandler := handlers.NewMeetingNoteHandler(meetingNoteService)
+	baselineHandler := handlers.NewBaselineHandler(baselineService)
+	timeTrackingHandler := handlers.NewTimeTrackingHandler(timeTrackingService)
+	projectDuplicationHandler := handlers.NewProjectDuplicationHandler(projectCopyService)
+	slaHandler := handlers.NewSLAHandler(slaService)
+	settingsHandler := handlers.NewSettingsHandler(settingsService, slaService)
+	billingHandler := handlers.NewBillingHandler(billingService)
+	meteringHandler := handlers.NewMeteringHandler(meteringService)
+	legalHandler := handlers.NewLegalHandler(legalService)
+	realtimeHandler := handlers.NewRealtimeHandler(realtimeHub, log.Logger)
+	badgeHandler := handlers.NewBadgeHandler(badgeService)
+	usageHandler := handlers.NewUsageHandler(usageService)
+
+	oauthHandler := handlers.NewOAuthHandler(oauthService, userService, refreshTokenService, cfg.Auth.JWTSecret, log.Logger)
 
 	// Initialize dashboard handler
 	dashboardHandler := handlers.NewDashboardHandler(
@@ -293,8 +814,15 @@ func main() {
 	)
 	dashboardRoutes.Register(router.Group("/api"))
 
+	// Initialize admin handler with the notification broker as the managed queue
+	queueAdmin, ok := notificationSystem.MessageBroker.(broker.QueueAdmin)
+	if !ok {
+		log.Fatal("Notification message broker does not support queue administration")
+	}
+	adminHandler := handlers.NewAdminHandler(redisClient, habitScheduler, queueAdmin, db, maintenanceMode, rateLimits, log.Logger)
+
 	// Initialize notification handler
-	notificationHandler := handlers.NewNotificationHandler(notificationSystem.Service, log)
+	notificationHandler := handlers.NewNotificationHandler(notificationSystem.Service, presenceService, log).WithBadges(badgeService)
 
 	// Initialize habit notification handler
 	habitNotificationHandler := handlers.NewHabitNotificationHandler(habitsService, notificationSystem.Service, habitNotifySvc)
@@ -322,6 +850,11 @@ func main() {
 	userRoutes.RegisterRoutes(router)
 	log.Info("Registered user routes at /api/users")
 
+	// Set up avatar uploads and images
+	avatarRoutes := routes.NewAvatarRoutes(avatarHandler, cfg.Auth.JWTSecret, rateLimiter)
+	avatarRoutes.RegisterRoutes(router)
+	log.Info("Registered avatar routes at /api/users/profile/avatar and /api/avatars")
+
 	// Set up MFA routes
 	mfaRoutes := routes.NewMFARoutes(mfaHandler, cfg.Auth.JWTSecret)
 	mfaRoutes.RegisterRoutes(router)
@@ -332,6 +865,17 @@ func main() {
 	authRoutes.RegisterRoutes(router)
 	log.Info("Registered auth routes at /api/roles")
 
+	// Set up admin routes
+	adminRoutes := routes.NewAdminRoutes(adminHandler, cfg.Auth.JWTSecret)
+	adminRoutes.RegisterRoutes(router)
+	log.Info("Registered admin routes at /api/admin")
+
+	// Set up the account merge route
+	accountMergeService := accountmerge.NewService(accountmerge.NewRepository(db), auditService, log.Logger)
+	accountMergeRoutes := routes.NewAccountMergeRoutes(handlers.NewAccountMergeHandler(accountMergeService), cfg.Auth.JWTSecret)
+	accountMergeRoutes.RegisterRoutes(router)
+	log.Info("Registered account merge route at /api/admin/users/merge")
+
 	// Health check routes (no /api prefix as these are system endpoints)
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -364,37 +908,106 @@ func main() {
 		})
 	})
 
+	// Scheduler health check reports the last run of every background job across instances
+	router.GET("/health/scheduler", func(c *gin.Context) {
+		jobs := habitScheduler.Status(c.Request.Context())
+		status, code := "healthy", http.StatusOK
+		for _, job := range jobs {
+			if job.Overdue {
+				status, code = "unhealthy", http.StatusServiceUnavailable
+			}
+		}
+		c.JSON(code, gin.H{
+			"status":    status,
+			"component": "scheduler",
+			"jobs":      jobs,
+		})
+	})
+
 	// Apply rate limiting middleware globally
-	router.Use(middleware.RateLimitMiddleware(rateLimiter))
+	router.Use(rateLimits.Middleware())
+	router.Use(middleware.MeterAPICalls(meteringPipeline))
 
 	// Task routes (protected)
 	taskRoutes := routes.NewTaskRoutes(taskHandler, cfg.Auth.JWTSecret)
-	taskRoutes.RegisterRoutes(router, cacheMiddleware)
-	log.Info("Registered task routes at /api/tasks")
+	taskRoutes.RegisterRoutes(router, cacheMiddleware, orgContext)
+	log.Info("Registered task routes at /api/tasks and /api/me/work")
 
 	// Project routes (protected)
-	projectRoutes := routes.NewProjectRoutes(projectHandler, cfg.Auth.JWTSecret)
-	projectRoutes.RegisterRoutes(router, cacheMiddleware)
+	projectRoutes := routes.NewProjectRoutes(projectHandler, taskHandler, cfg.Auth.JWTSecret)
+	projectRoutes.RegisterRoutes(router, cacheMiddleware, orgContext, billingService, orgUnitService)
 	log.Info("Registered project routes at /api/projects")
 
+	// Departments and teams of organizations (protected)
+	orgUnitRoutes := routes.NewOrgUnitRoutes(orgUnitHandler, projectHandler, orgUnitService, cfg.Auth.JWTSecret)
+	orgUnitRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered organizational unit routes at /api/organizations/:id/units and /api/projects/:id/unit")
+
+	// Project baseline routes (protected)
+	baselineRoutes := routes.NewBaselineRoutes(baselineHandler, projectHandler, cfg.Auth.JWTSecret)
+	baselineRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered project baseline routes at /api/projects/:id/baselines")
+
+	// Project duplication routes (protected)
+	projectDuplicationRoutes := routes.NewProjectDuplicationRoutes(projectDuplicationHandler, projectHandler, cfg.Auth.JWTSecret)
+	projectDuplicationRoutes.RegisterRoutes(router, orgContext, billingService)
+	log.Info("Registered project duplication routes at /api/projects/:id/duplicate")
+
+	// Working hours and project clock routes (protected)
+	slaRoutes := routes.NewSLARoutes(slaHandler, projectHandler, cfg.Auth.JWTSecret)
+	slaRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered SLA clock routes at /api/organizations/:id/working-hours and /api/projects/:id/clock")
+
+	// Set up organization default settings and project overrides
+	settingsRoutes := routes.NewSettingsRoutes(settingsHandler, projectHandler, cfg.Auth.JWTSecret)
+	settingsRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered settings routes at /api/organizations/:id/settings/defaults and /api/projects/:id/settings")
+
+	// Timers, time entries and timesheets
+	timeTrackingRoutes := routes.NewTimeTrackingRoutes(timeTrackingHandler, taskHandler, projectHandler, cfg.Auth.JWTSecret)
+	timeTrackingRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered time tracking routes at /api/tasks/:id/timer, /api/tasks/:id/time-entries and /api/timesheets")
+
 	// Organization routes (protected)
 	organizationRoutes := routes.NewOrganizationRoutes(organizationHandler, cfg.Auth.JWTSecret)
 	organizationRoutes.RegisterRoutes(router)
 	log.Info("Registered organization routes at /api/organizations")
 
+	// Onboarding wizard routes (protected)
+	onboardingRoutes := routes.NewOnboardingRoutes(onboardingHandler, cfg.Auth.JWTSecret)
+	onboardingRoutes.RegisterRoutes(router)
+	log.Info("Registered onboarding routes at /api/onboarding")
+
+	// Activity feed routes (protected)
+	activityRoutes := routes.NewActivityRoutes(activityHandler, cfg.Auth.JWTSecret)
+	activityRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered activity feed routes at /api/projects/:id/feed and /api/organizations/:id/feed")
+
 	// Habits routes (protected)
 	habitsRoutes := routes.NewHabitsRoutes(habitsHandler, cfg.Auth.JWTSecret)
 	habitsRoutes.RegisterRoutes(router, cacheMiddleware)
 	log.Info("Registered habits routes at /habits")
 
+	habitLinkRoutes := routes.NewHabitLinkRoutes(habitLinkHandler, cfg.Auth.JWTSecret)
+	habitLinkRoutes.RegisterRoutes(router)
+	log.Info("Registered habit link routes at /api/habits/:id/link")
+
+	trashRoutes := routes.NewTrashRoutes(trashHandler, cfg.Auth.JWTSecret)
+	trashRoutes.RegisterRoutes(router)
+	log.Info("Registered trash routes at /api/trash")
+
 	// Calendar routes (protected)
 	calendarRoutes := routes.NewCalendarRoutes(calendarHandler, cfg.Auth.JWTSecret)
 	calendarRoutes.RegisterRoutes(router)
+	availabilityRoutes := routes.NewAvailabilityRoutes(availabilityHandler, cfg.Auth.JWTSecret)
+	availabilityRoutes.RegisterRoutes(router, orgContext)
+	meetingNoteRoutes := routes.NewMeetingNoteRoutes(meetingNoteHandler, cfg.Auth.JWTSecret)
+	meetingNoteRoutes.RegisterRoutes(router)
 	log.Info("Registered calendar routes at /api/calendar")
 
 	// Workflow routes (protected)
 	workflowRoutes := routes.NewWorkflowRoutes(workflowHandler, cfg.Auth.JWTSecret)
-	workflowRoutes.RegisterRoutes(router)
+	workflowRoutes.RegisterRoutes(router, orgContext, billingService)
 	log.Info("Registered workflow routes at /api/workflows")
 
 	// Todos routes (protected)
@@ -402,6 +1015,142 @@ func main() {
 	todosRoutes.RegisterRoutes(router, cacheMiddleware)
 	log.Info("Registered todos routes at /api/todos")
 
+	geofenceRoutes := routes.NewGeofenceRoutes(geofenceHandler, cfg.Auth.JWTSecret)
+	geofenceRoutes.RegisterRoutes(router)
+	deviceRoutes := routes.NewDeviceRoutes(deviceHandler, cfg.Auth.JWTSecret)
+	deviceRoutes.RegisterRoutes(router)
+	log.Info("Registered geofence routes at /api/todos/geofences and device routes at /api/me/devices")
+
+	apiKeyRoutes := routes.NewAPIKeyRoutes(apiKeyHandler, cfg.Auth.JWTSecret)
+	apiKeyRoutes.RegisterRoutes(router)
+	log.Info("Registered API key routes at /api/users/api-keys")
+
+	// Command palette routes (protected)
+	commandRoutes := routes.NewCommandRoutes(commandHandler, cfg.Auth.JWTSecret)
+	commandRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered command palette routes at /api/commands")
+
+	// Presence routes (protected)
+	presenceRoutes := routes.NewPresenceRoutes(presenceHandler, cfg.Auth.JWTSecret)
+	presenceRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered presence routes at /api/presence")
+
+	// Set up webhook routes
+	webhookRoutes := routes.NewWebhookRoutes(webhookHandler, cfg.Auth.JWTSecret)
+	webhookRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered webhook routes at /api/webhooks")
+
+	// Set up announcement routes
+	announcementRoutes := routes.NewAnnouncementRoutes(announcementHandler, cfg.Auth.JWTSecret)
+	announcementRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered announcement routes at /api/announcements")
+
+	// Set up email-in routes
+	inboundRoutes := routes.NewInboundRoutes(inboundHandler, cfg.Auth.JWTSecret)
+	inboundRoutes.RegisterRoutes(router)
+	log.Info("Registered email-in routes at /api/inbound")
+
+	// Set up Slack and Teams integration routes
+	chatRoutes := routes.NewChatRoutes(chatHandler, cfg.Auth.JWTSecret)
+	chatRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered chat integration routes at /api/integrations/chat")
+
+	// Set up search routes
+	searchRoutes := routes.NewSearchRoutes(searchHandler, cfg.Auth.JWTSecret)
+	searchRoutes.RegisterRoutes(router, orgContext)
+
+	tagRoutes := routes.NewTagRoutes(tagHandler, cfg.Auth.JWTSecret)
+	tagRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered tag routes at /api/organizations/:id/tags and /api/tags")
+
+	auditRoutes := routes.NewAuditRoutes(handlers.NewAuditHandler(auditService, log.Logger), cfg.Auth.JWTSecret)
+	auditRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered audit log routes at /api/organizations/:id/audit")
+
+	retentionRoutes := routes.NewRetentionRoutes(handlers.NewRetentionHandler(retentionService, log.Logger), cfg.Auth.JWTSecret)
+	retentionRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered retention policy and deletion routes at /api/organizations/:id/retention and /api/organizations/:id/deletion")
+
+	analyticsRoutes := routes.NewAnalyticsRoutes(handlers.NewAnalyticsHandler(projectionService, log.Logger), cfg.Auth.JWTSecret)
+	analyticsRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered analytics routes at /api/analytics")
+	log.Info("Registered search routes at /api/search")
+
+	// Set up GitHub and GitLab integration routes
+	vcsRoutes := routes.NewVCSRoutes(vcsHandler, cfg.Auth.JWTSecret)
+	vcsRoutes.RegisterRoutes(router, orgContext, billingService)
+	log.Info("Registered code platform integration routes at /api/integrations/vcs")
+
+	// Set up integration status routes
+	integrationRoutes := routes.NewIntegrationRoutes(integrationHandler, cfg.Auth.JWTSecret)
+	integrationRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered integration status routes at /api/organizations/:id/integrations")
+
+	// Set up organization invitation routes
+	invitationRoutes := routes.NewInvitationRoutes(invitationHandler, cfg.Auth.JWTSecret)
+	invitationRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered organization invitation routes at /api/organizations/:id/invites")
+
+	// Set up bulk member import routes
+	memberImportRoutes := routes.NewMemberImportRoutes(memberImportHandler, cfg.Auth.JWTSecret)
+	memberImportRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered member import routes at /api/organizations/:id/members")
+
+	// Set up automation catalog and trigger routes
+	automationRoutes := routes.NewAutomationRoutes(automationHandler, cfg.Auth.JWTSecret)
+	automationRoutes.RegisterRoutes(router, orgContext, billingService)
+	log.Info("Registered automation routes at /api/automation")
+
+	// Set up timezone migration routes
+	timezoneRoutes := routes.NewTimezoneRoutes(timezoneHandler, cfg.Auth.JWTSecret)
+	timezoneRoutes.RegisterRoutes(router, cacheMiddleware)
+	log.Info("Registered timezone migration routes at /api/users/timezone")
+
+	// Set up daily agenda routes
+	agendaRoutes := routes.NewAgendaRoutes(agendaHandler, cfg.Auth.JWTSecret)
+	agendaRoutes.RegisterRoutes(router)
+	log.Info("Registered agenda routes at /api/me/agenda")
+
+	// Set up quick-add routes
+	quickAddRoutes := routes.NewQuickAddRoutes(quickAddHandler, cfg.Auth.JWTSecret)
+	quickAddRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered quick-add routes at /api/quick-add")
+
+	// Set up focus session routes
+	focusRoutes := routes.NewFocusRoutes(focusHandler, cfg.Auth.JWTSecret)
+	focusRoutes.RegisterRoutes(router)
+	log.Info("Registered focus session routes at /api/focus")
+
+	// Set up billing routes
+	billingRoutes := routes.NewBillingRoutes(billingHandler, cfg.Auth.JWTSecret)
+	billingRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered billing routes at /api/billing")
+
+	// Set up metering routes
+	meteringRoutes := routes.NewMeteringRoutes(meteringHandler, cfg.Auth.JWTSecret)
+	meteringRoutes.RegisterRoutes(router, orgContext)
+	log.Info("Registered metering routes at /api/metering and /api/admin/metering")
+
+	// Set up legal document and consent routes
+	legalRoutes := routes.NewLegalRoutes(legalHandler, cfg.Auth.JWTSecret)
+	legalRoutes.RegisterRoutes(router)
+	log.Info("Registered legal routes at /api/legal")
+
+	// Set up the realtime event stream
+	realtimeRoutes := routes.NewRealtimeRoutes(realtimeHandler, cfg.Auth.JWTSecret)
+	realtimeRoutes.RegisterRoutes(router)
+	log.Info("Registered realtime WebSocket at /ws")
+
+	// Set up the current user's badge counts
+	badgeRoutes := routes.NewBadgeRoutes(badgeHandler, cfg.Auth.JWTSecret)
+	badgeRoutes.RegisterRoutes(router)
+	log.Info("Registered badge count routes at /api/me/counts")
+
+	// Set up the current user's API usage dashboard
+	usageRoutes := routes.NewUsageRoutes(usageHandler, cfg.Auth.JWTSecret)
+	usageRoutes.RegisterRoutes(router)
+	log.Info("Registered API usage routes at /api/me/usage")
+
 	// Notification routes (protected)
 	notificationRoutes := routes.NewNotificationRoutes(notificationHandler, cfg.Auth.JWTSecret, rateLimiter)
 	notificationRoutes.RegisterRoutes(router, cacheMiddleware)
@@ -424,6 +1173,9 @@ func main() {
 		log.Warn("OAuth2 routes not registered because OAuth2 is disabled")
 	}
 
+	// Plugin routes, each under /api/plugins/<name>
+	pluginRegistry.RegisterRoutes(router, authMiddleware)
+
 	// Print all registered routes for debugging
 	for _, route := range router.Routes() {
 		log.Info("Route registered",
@@ -432,15 +1184,24 @@ func main() {
 		)
 	}
 
-	// Start server
+	serve(router, cfg.Server, log, backgroundWork)
+}
+
+// defaultShutdownTimeout is used when the server configuration sets none
+const defaultShutdownTimeout = 30 * time.Second
+
+// serve runs the HTTP server until the process is interrupted, then shuts it down
+// gracefully: in-flight requests finish first, then the background work they started
+func serve(router *gin.Engine, cfg config.ServerConfig, log *logger.Logger, work *background.Group) {
+	port := cfg.Port
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
+		Addr:    fmt.Sprintf(":%d", port),
 		Handler: router,
 	}
 
 	// Graceful shutdown
 	go func() {
-		log.Info(fmt.Sprintf("Server starting on port %d", cfg.Server.Port))
+		log.Info(fmt.Sprintf("Server starting on port %d", port))
 		log.Info("Swagger documentation available at http://localhost:8000/swagger/index.html")
 
 		// Always use HTTP, never HTTPS
@@ -457,12 +1218,19 @@ func main() {
 	<-quit
 
 	// Shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	log.Info("Shutting down server...")
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown", zap.Error(err))
+		log.Error("Server forced to shutdown", zap.Error(err))
+	}
+	if err := work.Shutdown(ctx); err != nil {
+		log.Warn("Background work was interrupted by shutdown", zap.Error(err))
 	}
 
 	log.Info("Server exited properly")
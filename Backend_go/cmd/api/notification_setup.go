@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/email"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/broker"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/config"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/zap"
 )
@@ -27,16 +33,10 @@ type NotificationSystem struct {
 func SetupNotificationSystem(
 	db *connection.Database,
 	appLogger *logger.Logger,
-	isDevelopment bool,
+	mailer *email.Mailer,
+	recipients notification.EmailRecipients,
 ) (*NotificationSystem, error) {
-	// Initialize logger
-	notifLogger := logrus.New()
-	notifLogger.SetFormatter(&logrus.JSONFormatter{})
-	if isDevelopment {
-		notifLogger.SetLevel(logrus.DebugLevel)
-	} else {
-		notifLogger.SetLevel(logrus.InfoLevel)
-	}
+	notifLogger := logger.Logrus("notification")
 
 	// Initialize repositories
 	repo := notification.NewRepository(db, notifLogger)
@@ -47,7 +47,7 @@ func SetupNotificationSystem(
 
 	// Initialize delivery services
 	inAppDelivery := notification.NewInAppDeliveryService(signalRepo, notifLogger)
-	emailDelivery := notification.NewEmailDeliveryService(notifLogger)
+	emailDelivery := notification.NewEmailDeliveryService(mailer, recipients, notifLogger)
 
 	// Create delivery factory
 	deliveryFactory := notification.NewStandardDeliveryServiceFactory(
@@ -135,3 +135,57 @@ func (ns *NotificationSystem) Shutdown() error {
 	ns.Logger.Info("Notification system shut down successfully")
 	return nil
 }
+
+// sloAlertNotifier sends latency SLO alerts to the users configured to receive them
+type sloAlertNotifier struct {
+	notifier notification.DomainNotifier
+	users    []uuid.UUID
+	methods  []notification.DeliveryMethod
+}
+
+// newSLOAlertNotifier returns nil when no users are configured, leaving alerts to the logs.
+// Alerts are delivered in-app and by email unless other methods are configured.
+func newSLOAlertNotifier(notifier notification.DomainNotifier, cfg config.SLOConfig) (middleware.SLOAlerter, error) {
+	if len(cfg.AlertUsers) == 0 {
+		return nil, nil
+	}
+	alerter := &sloAlertNotifier{
+		notifier: notifier,
+		methods:  []notification.DeliveryMethod{notification.InApp, notification.Email},
+	}
+	for _, id := range cfg.AlertUsers {
+		userID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLO alert user %q: %w", id, err)
+		}
+		alerter.users = append(alerter.users, userID)
+	}
+	if len(cfg.AlertMethods) > 0 {
+		alerter.methods = alerter.methods[:0]
+		for _, method := range cfg.AlertMethods {
+			alerter.methods = append(alerter.methods, notification.DeliveryMethod(method))
+		}
+	}
+	return alerter, nil
+}
+
+func (a *sloAlertNotifier) SLOBudgetBurning(ctx context.Context, alert middleware.SLOAlert) error {
+	title := fmt.Sprintf("Latency SLO of %s is burning its budget", alert.Route)
+	content := fmt.Sprintf("Requests under %s are slower than %s %.1f times as often as the %.2f%% objective allows over the last %s.",
+		alert.Route, alert.Threshold, alert.BurnRate, alert.Objective*100, alert.Window)
+	data := map[string]string{
+		"route":     alert.Route,
+		"policy":    alert.Policy,
+		"burn_rate": fmt.Sprintf("%.2f", alert.BurnRate),
+		"window":    alert.Window.String(),
+	}
+
+	var errs []error
+	for _, userID := range a.users {
+		if err := a.notifier.NotifyUserWithDelivery(ctx, userID, notification.SLOBudgetBurning,
+			title, content, data, "slo", uuid.Nil, a.methods); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
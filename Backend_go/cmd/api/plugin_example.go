@@ -0,0 +1,50 @@
+//go:build plugin_example
+
+package main
+
+// An example of a compiled-in plugin, only part of builds made with -tags plugin_example.
+// It counts created tasks and failed workflow steps and reports them at
+// GET /api/plugins/example/stats.
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workflow"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/plugins"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	plugins.Register(&examplePlugin{})
+}
+
+type examplePlugin struct {
+	tasksCreated int64
+	stepsFailed  int64
+}
+
+func (p *examplePlugin) Name() string { return "example" }
+
+func (p *examplePlugin) Register(r *plugins.Registrar) error {
+	r.On(plugins.AfterTaskCreate, func(ctx context.Context, payload interface{}) error {
+		atomic.AddInt64(&p.tasksCreated, 1)
+		return nil
+	})
+	r.On(plugins.AfterWorkflowStepExecute, func(ctx context.Context, payload interface{}) error {
+		if step, ok := payload.(*workflow.StepHookPayload); ok && step.Err != nil {
+			atomic.AddInt64(&p.stepsFailed, 1)
+		}
+		return nil
+	})
+	r.Routes(func(group *gin.RouterGroup) {
+		group.GET("/stats", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"data": gin.H{
+				"tasks_created": atomic.LoadInt64(&p.tasksCreated),
+				"steps_failed":  atomic.LoadInt64(&p.stepsFailed),
+			}})
+		})
+	})
+	return nil
+}
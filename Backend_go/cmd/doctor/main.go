@@ -0,0 +1,95 @@
+// Command doctor checks the configured database for rows that reference records which no
+// longer exist, such as tasks of a deleted project or occurrences of a deleted event, and
+// repairs them with -fix.
+//
+//	go run ./cmd/doctor
+//	go run ./cmd/doctor -fix -checks tasks_missing_project,occurrences_missing_event
+//
+// It exits with status 1 while inconsistencies remain, so a cron job can alert on it.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	stdlog "log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/doctor"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/config"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "repair the inconsistent rows")
+	only := flag.String("checks", "", "comma-separated checks to run; all when empty: "+strings.Join(doctor.Checks(), ", "))
+	sample := flag.Int("sample", 10, "IDs of inconsistent rows to list per check")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		stdlog.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	log := logger.NewLogger()
+	defer log.Sync()
+
+	db, err := connection.NewDatabase(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	// The checks scan whole tables; their queries are not worth logging
+	db.DB = db.DB.Session(&gorm.Session{Logger: gormlogger.Default.LogMode(gormlogger.Warn)})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := doctor.Options{Fix: *fix, SampleSize: *sample}
+	if *only != "" {
+		for _, name := range strings.Split(*only, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Only = append(opts.Only, name)
+			}
+		}
+	}
+
+	findings, err := doctor.New(db.DB, log.Logger).Run(ctx, opts)
+	if err != nil {
+		log.Fatal("Consistency check failed", zap.Error(err))
+	}
+
+	if *asJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(findings); err != nil {
+			log.Fatal("Failed to write report", zap.Error(err))
+		}
+	} else {
+		printReport(findings)
+	}
+
+	for _, f := range findings {
+		if f.Count > f.Repaired {
+			os.Exit(1)
+		}
+	}
+}
+
+func printReport(findings []doctor.Finding) {
+	for _, f := range findings {
+		switch {
+		case f.Count == 0:
+			fmt.Printf("ok    %s\n", f.Check)
+		case f.Repaired > 0:
+			fmt.Printf("fixed %s: %d %s, %d %s\n", f.Check, f.Count, f.Description, f.Repaired, f.Repair)
+		default:
+			fmt.Printf("FAIL  %s: %d %s, e.g. %s\n", f.Check, f.Count, f.Description, strings.Join(f.Sample, ", "))
+		}
+	}
+}
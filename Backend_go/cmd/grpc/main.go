@@ -0,0 +1,199 @@
+// Command grpc serves the core services (tasks, todos, habits and calendar) over gRPC for
+// internal services and the MCP server. It shares the service layer, database and Redis
+// with the REST API, so changes made here reach webhooks, live updates, search and
+// cached REST responses the same way. The REST API owns the schema and its migrations.
+//
+// Generate the protobuf code first, then run:
+//
+//	go generate ./proto/core
+//	go run ./cmd/grpc -addr :50051
+//
+// Calls carry the JWT issued by the REST API as "authorization: Bearer <token>" metadata;
+// task calls also take the organization in "x-organization-id" metadata.
+package main
+
+import (
+	"flag"
+	stdlog "log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/rpc"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/activity"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/legal"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/projections"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/settings"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/plugins"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/realtime"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/config"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// legalDocuments reads the current legal documents from configuration, as the REST API
+// does, so both ask for the same versions
+func legalDocuments(c config.LegalConfig) []legal.Document {
+	document := func(t legal.DocumentType, d config.LegalDocumentConfig) legal.Document {
+		doc := legal.Document{Type: t, Version: d.Version, URL: d.URL}
+		if effective, err := time.Parse("2006-01-02", d.EffectiveDate); err == nil {
+			doc.EffectiveDate = &effective
+		}
+		return doc
+	}
+	return []legal.Document{
+		document(legal.DocumentTerms, c.Terms),
+		document(legal.DocumentPrivacy, c.Privacy),
+	}
+}
+
+func main() {
+	defaultAddr := os.Getenv("GRPC_ADDR")
+	if defaultAddr == "" {
+		defaultAddr = ":50051"
+	}
+	addr := flag.String("addr", defaultAddr, "address to serve gRPC on")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		stdlog.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if err := logger.Setup(logger.Config{
+		Level:   cfg.Logging.Level,
+		Format:  cfg.Logging.Format,
+		Modules: cfg.Logging.Modules,
+	}); err != nil {
+		stdlog.Fatalf("Invalid logging configuration: %v", err)
+	}
+	log := logger.NewLogger()
+	defer log.Sync()
+
+	db, err := connection.NewDatabase(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+
+	redisClient, err := cache.NewRedisClient(cache.NewConfigFromEnv(cfg))
+	if err != nil {
+		log.Fatal("Failed to connect to Redis", zap.Error(err))
+	}
+	defer redisClient.Close()
+
+	// Same prefix as the REST API, so writes here drop its cached responses
+	cacheMiddleware := middleware.NewCacheMiddleware(redisClient, "compass", 5*time.Minute)
+
+	// Notifications are stored for in-app delivery; email reminders are sent by the
+	// API's workers
+	notifLogger := logger.Logrus("notification")
+	signalRepo := notification.NewSignalRepository(100)
+	notificationService := notification.NewService(notification.ServiceConfig{
+		Repository: notification.NewRepository(db, notifLogger),
+		Logger:     notifLogger,
+		SignalRepo: signalRepo,
+		DeliveryServices: map[notification.DeliveryMethod]notification.DeliveryService{
+			notification.InApp: notification.NewInAppDeliveryService(signalRepo, notifLogger),
+		},
+	})
+	domainNotifier := notification.NewDomainNotifier(notificationService, nil, notifLogger)
+	habitNotifySvc := habits.NewHabitNotificationService(notificationService)
+	habitNotifySvc.WithDomainNotifier(domainNotifier)
+
+	auditService := audit.NewService(audit.NewRepository(db), log.Logger)
+	auditService.Start()
+	defer auditService.Stop()
+
+	rolesService := roles.NewService(roles.NewRepository(db.DB))
+	organizationService := organization.NewService(organization.NewRepository(db), rolesService, auditService)
+	activityService := activity.NewService(activity.NewRepository(db), organizationService, log.Logger)
+
+	webhookDispatcher := webhooks.NewDispatcher(webhooks.NewRepository(db), webhooks.DefaultDispatcherConfig(), log.Logger)
+	webhookDispatcher.Start()
+	defer webhookDispatcher.Stop()
+
+	realtimeHub := realtime.NewHub(redisClient, organizationService, log.Logger)
+	realtimeHub.Start()
+	defer realtimeHub.Stop()
+
+	// Embeddings are refreshed by the API, which has the LLM providers configured
+	searchIndexer := search.NewIndexer(search.NewRepository(db), nil, search.DefaultIndexerConfig(), log.Logger)
+	searchIndexer.Start()
+	defer searchIndexer.Stop()
+
+	eventPublisher := webhooks.Publishers{webhookDispatcher, realtimeHub, searchIndexer}
+
+	calendarRepo := calendar.NewRepository(db.DB)
+	eventBus := events.NewBus(events.DefaultBusConfig(), log.Logger)
+	habitNotifySvc.Subscribe(eventBus)
+	calendar.SubscribeRescheduleNotifications(eventBus, calendarRepo, domainNotifier)
+	task.SubscribeAssignmentNotifications(eventBus, domainNotifier)
+	task.SubscribeRiskNotifications(eventBus, domainNotifier)
+	todos.SubscribeGeofenceNotifications(eventBus, domainNotifier)
+	projections.NewService(projections.NewRepository(db), log.Logger).Subscribe(eventBus)
+	eventBus.Start()
+	defer eventBus.Stop()
+
+	pluginRegistry := plugins.NewRegistry(log.Logger)
+	if err := pluginRegistry.Load(cfg.Plugins.Dir); err != nil {
+		log.Fatal("Failed to load plugins", zap.Error(err))
+	}
+
+	settingsService := settings.NewService(settings.NewRepository(db))
+	services := rpc.Services{
+		Tasks: task.NewService(task.NewRepository(db), redisClient, activityService, eventPublisher, pluginRegistry,
+			eventBus, cacheMiddleware, sla.NewService(sla.NewRepository(db), settingsService), settingsService, auditService, log.Logger),
+		Todos:    todos.NewService(todos.NewTodoRepository(db), redisClient, eventPublisher, eventBus, cacheMiddleware, log.Logger),
+		Habits:   habits.NewService(habits.NewRepository(db), habitNotifySvc, redisClient, eventPublisher, eventBus, logger.Named("habits").Logger),
+		Calendar: calendar.NewService(calendarRepo, user.NewRepository(db), domainNotifier, redisClient, eventBus, log.Logger),
+	}
+	legalService := legal.NewService(legal.NewRepository(db), legalDocuments(cfg.Legal))
+	server := rpc.NewServer(services, rpc.NewAuthenticator(cfg.Auth.JWTSecret, organizationService, legalService), log.Logger)
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal("Failed to listen", zap.String("addr", *addr), zap.Error(err))
+	}
+	go func() {
+		log.Info("gRPC server starting", zap.String("addr", listener.Addr().String()))
+		if err := server.Serve(listener); err != nil {
+			log.Fatal("Failed to serve gRPC", zap.Error(err))
+		}
+	}()
+
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Info("Shutting down gRPC server...")
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		server.Stop()
+	}
+	log.Info("gRPC server exited properly")
+}
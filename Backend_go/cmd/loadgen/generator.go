@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Volumes sets how many records of each kind are generated
+type Volumes struct {
+	Users           int
+	Organizations   int
+	Projects        int
+	Tasks           int
+	Events          int
+	RecurringEvents int
+}
+
+// Repositories are the stores the generator writes through
+type Repositories struct {
+	Users         user.Repository
+	Organizations organization.Repository
+	Projects      project.Repository
+	Tasks         task.TaskRepository
+	Calendar      calendar.Repository
+}
+
+type weighted[T any] struct {
+	value  T
+	weight float64
+}
+
+// Task states skew towards finished work, as in a project that has been running a while
+var taskStatuses = []weighted[task.TaskStatus]{
+	{task.TaskStatusCompleted, 0.40},
+	{task.TaskStatusUpcoming, 0.25},
+	{task.TaskStatusInProgress, 0.15},
+	{task.TaskStatusUnderReview, 0.06},
+	{task.TaskStatusBlocked, 0.05},
+	{task.TaskStatusDeferred, 0.05},
+	{task.TaskStatusCancelled, 0.04},
+}
+
+var taskPriorities = []weighted[task.TaskPriority]{
+	{task.TaskPriorityMedium, 0.50},
+	{task.TaskPriorityLow, 0.25},
+	{task.TaskPriorityHigh, 0.20},
+	{task.TaskPriorityUrgent, 0.05},
+}
+
+var eventTypes = []weighted[calendar.EventType]{
+	{calendar.EventTypeMeeting, 0.55},
+	{calendar.EventTypeTask, 0.15},
+	{calendar.EventTypeReminder, 0.15},
+	{calendar.EventTypeTodo, 0.10},
+	{calendar.EventTypeHoliday, 0.05},
+}
+
+var recurrenceFreqs = []weighted[calendar.RecurrenceType]{
+	{calendar.RecurrenceTypeWeekly, 0.55},
+	{calendar.RecurrenceTypeDaily, 0.20},
+	{calendar.RecurrenceTypeBiweekly, 0.10},
+	{calendar.RecurrenceTypeMonthly, 0.12},
+	{calendar.RecurrenceTypeYearly, 0.03},
+}
+
+var weekdays = []string{"MO", "TU", "WE", "TH", "FR"}
+
+var taskVerbs = []string{"Implement", "Review", "Fix", "Design", "Document", "Test", "Refactor", "Deploy", "Investigate", "Plan"}
+var taskSubjects = []string{"login flow", "billing page", "search index", "API pagination", "onboarding email", "dashboard widgets", "export job", "mobile layout", "audit log", "notification settings"}
+var eventTitles = []string{"Standup", "Sprint planning", "1:1", "Design review", "Retrospective", "Customer call", "Focus time", "Lunch", "All hands", "Interview"}
+
+// generator creates a tenant-shaped dataset: organization sizes and project activity follow
+// a power law, so a few organizations and projects hold most of the rows as in production
+type generator struct {
+	repos        Repositories
+	volumes      Volumes
+	workers      int
+	seed         int64
+	run          string
+	passwordHash string
+	ownerRoleID  uuid.UUID
+	memberRoleID uuid.UUID
+	log          *logger.Logger
+
+	userIDs    []uuid.UUID
+	userOrg    []int
+	orgIDs     []uuid.UUID
+	orgMembers [][]uuid.UUID
+	projectIDs []uuid.UUID
+	projectOrg []int
+}
+
+// Run generates every volume in dependency order
+func (g *generator) Run(ctx context.Context) error {
+	rng := rand.New(rand.NewSource(g.seed))
+	g.planUsers(rng)
+	g.planProjects(rng)
+
+	phases := []struct {
+		name  string
+		count int
+		run   func(ctx context.Context, rng *rand.Rand, i int) error
+	}{
+		{"users", len(g.userIDs), g.createUser},
+		{"organizations", len(g.orgIDs), g.createOrganization},
+		{"memberships", len(g.userIDs), g.createMembership},
+		{"projects", len(g.projectIDs), g.createProject},
+		{"tasks", g.volumes.Tasks, g.createTask},
+		{"events", g.volumes.Events, g.createEvent},
+		{"recurring events", g.volumes.RecurringEvents, g.createRecurringEvent},
+	}
+	for _, phase := range phases {
+		start := time.Now()
+		if err := g.parallel(ctx, phase.name, phase.count, phase.run); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", phase.name, err)
+		}
+		g.log.Info("Generated records",
+			zap.String("kind", phase.name),
+			zap.Int("count", phase.count),
+			zap.Duration("duration", time.Since(start)))
+	}
+	return nil
+}
+
+// planUsers assigns users to organizations. The first user of each organization owns it;
+// the rest join organizations picked from a Zipf distribution.
+func (g *generator) planUsers(rng *rand.Rand) {
+	g.userIDs = make([]uuid.UUID, g.volumes.Users)
+	g.userOrg = make([]int, g.volumes.Users)
+	g.orgIDs = make([]uuid.UUID, g.volumes.Organizations)
+	g.orgMembers = make([][]uuid.UUID, g.volumes.Organizations)
+	for i := range g.orgIDs {
+		g.orgIDs[i] = uuid.New()
+	}
+
+	zipf := rand.NewZipf(rng, 1.1, 2, uint64(len(g.orgIDs)-1))
+	for i := range g.userIDs {
+		g.userIDs[i] = uuid.New()
+		org := i
+		if i >= len(g.orgIDs) {
+			org = int(zipf.Uint64())
+		}
+		g.userOrg[i] = org
+		g.orgMembers[org] = append(g.orgMembers[org], g.userIDs[i])
+	}
+}
+
+// planProjects gives organizations projects in proportion to their size
+func (g *generator) planProjects(rng *rand.Rand) {
+	g.projectIDs = make([]uuid.UUID, g.volumes.Projects)
+	g.projectOrg = make([]int, g.volumes.Projects)
+	for i := range g.projectIDs {
+		g.projectIDs[i] = uuid.New()
+		if i < len(g.orgIDs) {
+			g.projectOrg[i] = i
+			continue
+		}
+		g.projectOrg[i] = g.userOrg[rng.Intn(len(g.userIDs))]
+	}
+}
+
+// parallel runs fn for indexes [0, count) on the worker pool, stopping at the first error
+func (g *generator) parallel(ctx context.Context, name string, count int, fn func(ctx context.Context, rng *rand.Rand, i int) error) error {
+	if count == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		done     atomic.Int64
+		errOnce  sync.Once
+		firstErr error
+	)
+	step := int64(count / 10)
+	if step == 0 {
+		step = 1
+	}
+
+	for w := 0; w < g.workers; w++ {
+		wg.Add(1)
+		rng := rand.New(rand.NewSource(g.seed + int64(w) + 1))
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(ctx, rng, i); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				if n := done.Add(1); n%step == 0 {
+					g.log.Info("Progress", zap.String("kind", name), zap.Int64("done", n), zap.Int("total", count))
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < count; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func (g *generator) createUser(ctx context.Context, rng *rand.Rand, i int) error {
+	return g.repos.Users.Create(ctx, &user.User{
+		ID:           g.userIDs[i],
+		Email:        fmt.Sprintf("loadgen-%s-%d@loadgen.test", g.run, i),
+		Username:     fmt.Sprintf("loadgen_%s_%d", g.run, i),
+		FirstName:    "Load",
+		LastName:     fmt.Sprintf("User %d", i),
+		Timezone:     "UTC",
+		Locale:       "en-US",
+		PasswordHash: g.passwordHash,
+		Status:       user.UserStatusActive,
+		IsActive:     true,
+	})
+}
+
+func (g *generator) createOrganization(ctx context.Context, rng *rand.Rand, i int) error {
+	owner := g.orgMembers[i][0]
+	return g.repos.Organizations.Create(ctx, &organization.Organization{
+		ID:        g.orgIDs[i],
+		Name:      fmt.Sprintf("Load test org %s-%d", g.run, i),
+		Status:    organization.OrganizationStatusActive,
+		CreatorID: owner,
+		OwnerID:   owner,
+	})
+}
+
+func (g *generator) createMembership(ctx context.Context, rng *rand.Rand, i int) error {
+	org := g.userOrg[i]
+	role := g.memberRoleID
+	if g.orgMembers[org][0] == g.userIDs[i] {
+		role = g.ownerRoleID
+	}
+	return g.repos.Organizations.AddMember(ctx, &organization.Member{
+		OrganizationID: g.orgIDs[org],
+		UserID:         g.userIDs[i],
+		RoleID:         role,
+	})
+}
+
+func (g *generator) createProject(ctx context.Context, rng *rand.Rand, i int) error {
+	members := g.orgMembers[g.projectOrg[i]]
+	owner := members[rng.Intn(len(members))]
+	start := pastTime(rng, 180)
+	return g.repos.Projects.Create(ctx, &project.Project{
+		ID:             g.projectIDs[i],
+		Name:           fmt.Sprintf("Load test project %s-%d", g.run, i),
+		Status:         project.ProjectStatusActive,
+		OrganizationID: g.orgIDs[g.projectOrg[i]],
+		CreatorID:      owner,
+		OwnerID:        owner,
+		StartDate:      start,
+	})
+}
+
+// createTask spreads tasks over projects by a Zipf distribution, so a few projects are
+// large enough to exercise pagination and board ordering
+func (g *generator) createTask(ctx context.Context, rng *rand.Rand, i int) error {
+	p := int(rand.NewZipf(rng, 1.05, 4, uint64(len(g.projectIDs)-1)).Uint64())
+	org := g.projectOrg[p]
+	members := g.orgMembers[org]
+
+	start := pastTime(rng, 365)
+	t := &task.Task{
+		Title:          fmt.Sprintf("%s %s", pick(rng, taskVerbs), pick(rng, taskSubjects)),
+		Description:    "Generated for load testing",
+		Status:         choose(rng, taskStatuses),
+		Priority:       choose(rng, taskPriorities),
+		CreatorID:      members[rng.Intn(len(members))],
+		ProjectID:      g.projectIDs[p],
+		OrganizationID: g.orgIDs[org],
+		StartDate:      start,
+		EstimatedHours: math.Round(rng.ExpFloat64()*6*2) / 2,
+		// Spread positions so board queries see realistic ordering without the per-insert lookup
+		Position: float64(i+1) * 1024,
+	}
+	if rng.Float64() < 0.8 {
+		assignee := members[rng.Intn(len(members))]
+		t.AssigneeID = &assignee
+	}
+	if rng.Float64() < 0.7 {
+		due := start.Add(time.Duration(1+rng.Intn(45)) * 24 * time.Hour)
+		t.DueDate = &due
+	}
+	return g.repos.Tasks.Create(ctx, t)
+}
+
+func (g *generator) createEvent(ctx context.Context, rng *rand.Rand, i int) error {
+	return g.repos.Calendar.CreateEvent(ctx, g.event(rng, i))
+}
+
+func (g *generator) createRecurringEvent(ctx context.Context, rng *rand.Rand, i int) error {
+	event := g.event(rng, i)
+	rule := calendar.RecurrenceRule{
+		Freq:     choose(rng, recurrenceFreqs),
+		Interval: 1,
+	}
+	if rule.Freq == calendar.RecurrenceTypeWeekly && rng.Float64() < 0.4 {
+		rule.ByDay = calendar.StringArray{weekdays[rng.Intn(len(weekdays))], weekdays[rng.Intn(len(weekdays))]}
+	}
+	switch r := rng.Float64(); {
+	case r < 0.3:
+		until := time.Now().AddDate(0, 1+rng.Intn(12), 0)
+		rule.Until = &until
+	case r < 0.5:
+		count := 5 + rng.Intn(50)
+		rule.Count = &count
+	}
+	event.RecurrenceRules = []calendar.RecurrenceRule{rule}
+	return g.repos.Calendar.CreateEvent(ctx, event)
+}
+
+// event places a working-hours event within three months either side of today
+func (g *generator) event(rng *rand.Rand, i int) *calendar.CalendarEvent {
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, rng.Intn(181)-90)
+	start := day.Add(time.Duration(8*60+rng.Intn(10*4)*15) * time.Minute)
+	duration := time.Duration(15*(1+rng.Intn(8))) * time.Minute
+	return &calendar.CalendarEvent{
+		UserID:       g.userIDs[rng.Intn(len(g.userIDs))],
+		Title:        pick(rng, eventTitles),
+		EventType:    choose(rng, eventTypes),
+		StartTime:    start,
+		EndTime:      start.Add(duration),
+		Transparency: calendar.TransparencyOpaque,
+	}
+}
+
+// pastTime returns a time within the last maxDays days, weighted towards recent days
+func pastTime(rng *rand.Rand, maxDays int) time.Time {
+	days := math.Min(rng.ExpFloat64()*float64(maxDays)/4, float64(maxDays))
+	return time.Now().UTC().Add(-time.Duration(days * float64(24*time.Hour)))
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
+
+func choose[T any](rng *rand.Rand, options []weighted[T]) T {
+	r := rng.Float64()
+	for _, o := range options {
+		if r < o.weight {
+			return o.value
+		}
+		r -= o.weight
+	}
+	return options[len(options)-1].value
+}
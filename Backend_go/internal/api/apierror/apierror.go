@@ -0,0 +1,202 @@
+// Package apierror defines the error responses of the API: a message for people, a
+// stable code for programs, field-level details for invalid input and the request ID
+// to quote when reporting a problem.
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Code identifies an error for programs; unlike messages, codes do not change
+type Code string
+
+const (
+	CodeBadRequest         Code = "BAD_REQUEST"
+	CodeValidationFailed   Code = "VALIDATION_FAILED"
+	CodeUnauthorized       Code = "UNAUTHORIZED"
+	CodePaymentRequired    Code = "PAYMENT_REQUIRED"
+	CodeForbidden          Code = "FORBIDDEN"
+	CodeOrgForbidden       Code = "ORG_FORBIDDEN"
+	CodeOrgPendingDeletion Code = "ORG_PENDING_DELETION"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeConflict           Code = "CONFLICT"
+	CodePayloadTooLarge    Code = "PAYLOAD_TOO_LARGE"
+	CodeUnprocessable      Code = "UNPROCESSABLE"
+	CodeRateLimited        Code = "RATE_LIMITED"
+	CodeInternal           Code = "INTERNAL_ERROR"
+	CodeServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+
+	CodeTaskNotFound         Code = "TASK_NOT_FOUND"
+	CodeCommentNotFound      Code = "COMMENT_NOT_FOUND"
+	CodeTodoNotFound         Code = "TODO_NOT_FOUND"
+	CodeProjectNotFound      Code = "PROJECT_NOT_FOUND"
+	CodeHabitNotFound        Code = "HABIT_NOT_FOUND"
+	CodeEventNotFound        Code = "EVENT_NOT_FOUND"
+	CodeUserNotFound         Code = "USER_NOT_FOUND"
+	CodeOrganizationNotFound Code = "ORG_NOT_FOUND"
+	CodeUnitNotFound         Code = "ORG_UNIT_NOT_FOUND"
+	CodeTagNotFound          Code = "TAG_NOT_FOUND"
+	CodeInvalidTransition    Code = "INVALID_TRANSITION"
+)
+
+// statusCodes are the codes of errors known only by their HTTP status
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusPaymentRequired:       CodePaymentRequired,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusServiceUnavailable:    CodeServiceUnavailable,
+}
+
+// FieldError describes why one field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// Response is the body of every error response. Error stays a plain message so that
+// clients reading it keep working.
+type Response struct {
+	Error     string       `json:"error"`
+	Code      Code         `json:"code"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// Error is an error with the status and code it is reported with
+type Error struct {
+	Status  int
+	Code    Code
+	Message string
+	Details []FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New creates an error reported with status and code
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Validation reports invalid request input: the field errors of a validator, or a body
+// that could not be decoded
+func Validation(err error) *Error {
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		details := make([]FieldError, len(fieldErrors))
+		for i, fe := range fieldErrors {
+			details[i] = FieldError{Field: jsonField(fe), Rule: fe.Tag(), Message: fieldMessage(fe)}
+		}
+		return &Error{Status: http.StatusBadRequest, Code: CodeValidationFailed, Message: "validation failed", Details: details}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &Error{Status: http.StatusBadRequest, Code: CodeValidationFailed, Message: "validation failed", Details: []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("must be a %s", typeErr.Type),
+		}}}
+	}
+	return New(http.StatusBadRequest, CodeBadRequest, err.Error())
+}
+
+// jsonField returns the path of a field error without the name of the struct validated
+func jsonField(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		namespace = namespace[i+1:]
+	}
+	return strings.ToLower(namespace)
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "not_empty":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "uuid", "valid_uuid":
+		return "must be a valid UUID"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + fe.Param()
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
+
+// From returns how err is reported: as itself if it is an *Error, by the status and code
+// registered for the domain error it wraps, or as an internal error
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		return Validation(err)
+	}
+	for _, m := range domainErrors {
+		if errors.Is(err, m.err) {
+			return New(m.status, m.code, err.Error())
+		}
+	}
+	return New(http.StatusInternalServerError, CodeInternal, err.Error())
+}
+
+// CodeFor returns the code of an error response known only by its status and message,
+// such as the responses handlers write themselves. A message that is a registered domain
+// error's, with the same status, gets that error's code.
+func CodeFor(status int, message string) Code {
+	for _, m := range domainErrors {
+		if m.status == status && m.err.Error() == message {
+			return m.code
+		}
+	}
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// RequestID returns the ID of the request, as set by the tracing middleware
+func RequestID(c *gin.Context) string {
+	if tc := tracing.FromContext(c.Request.Context()); tc != nil {
+		return tc.RequestID
+	}
+	return ""
+}
+
+// Respond writes err as an error response and aborts the request
+func Respond(c *gin.Context, err error) {
+	apiErr := From(err)
+	c.AbortWithStatusJSON(apiErr.Status, Response{
+		Error:     apiErr.Message,
+		Code:      apiErr.Code,
+		Details:   apiErr.Details,
+		RequestID: RequestID(c),
+	})
+}
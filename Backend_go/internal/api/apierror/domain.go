@@ -0,0 +1,49 @@
+package apierror
+
+import (
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/orgunits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/tags"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+)
+
+type domainError struct {
+	err    error
+	status int
+	code   Code
+}
+
+// domainErrors maps domain errors to how they are reported. Errors wrapping them are
+// reported the same way. Statuses match what the handlers already respond with, so
+// that responses handlers write themselves get the same codes.
+var domainErrors = []domainError{
+	{task.ErrTaskNotFound, http.StatusNotFound, CodeTaskNotFound},
+	{task.ErrCommentNotFound, http.StatusNotFound, CodeCommentNotFound},
+	{task.ErrInvalidTransition, http.StatusBadRequest, CodeInvalidTransition},
+	{todos.ErrTodoNotFound, http.StatusNotFound, CodeTodoNotFound},
+	{project.ErrProjectNotFound, http.StatusNotFound, CodeProjectNotFound},
+	{habits.ErrHabitNotFound, http.StatusNotFound, CodeHabitNotFound},
+	{calendar.ErrEventNotFound, http.StatusNotFound, CodeEventNotFound},
+	{user.ErrUserNotFound, http.StatusNotFound, CodeUserNotFound},
+	{organization.ErrOrganizationNotFound, http.StatusNotFound, CodeOrganizationNotFound},
+	{organization.ErrNotMember, http.StatusForbidden, CodeOrgForbidden},
+	{organization.ErrPendingDeletion, http.StatusLocked, CodeOrgPendingDeletion},
+	{orgunits.ErrUnitNotFound, http.StatusNotFound, CodeUnitNotFound},
+	{tags.ErrTagNotFound, http.StatusNotFound, CodeTagNotFound},
+
+	// Each domain rejects invalid input with its own error
+	{task.ErrInvalidInput, http.StatusBadRequest, CodeValidationFailed},
+	{todos.ErrInvalidInput, http.StatusBadRequest, CodeValidationFailed},
+	{project.ErrInvalidInput, http.StatusBadRequest, CodeValidationFailed},
+	{habits.ErrInvalidInput, http.StatusBadRequest, CodeValidationFailed},
+	{organization.ErrInvalidInput, http.StatusBadRequest, CodeValidationFailed},
+	{orgunits.ErrInvalidInput, http.StatusBadRequest, CodeValidationFailed},
+	{tags.ErrInvalidInput, http.StatusBadRequest, CodeValidationFailed},
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/activity"
+	"github.com/google/uuid"
+)
+
+// ActivityEventResponse represents a single activity feed entry in API responses
+type ActivityEventResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id"`
+	ProjectID      *uuid.UUID      `json:"project_id,omitempty"`
+	ActorID        *uuid.UUID      `json:"actor_id,omitempty"`
+	Type           string          `json:"type" example:"task.status_changed"`
+	Category       string          `json:"category" example:"task"`
+	EntityType     string          `json:"entity_type" example:"task"`
+	EntityID       uuid.UUID       `json:"entity_id"`
+	Summary        string          `json:"summary" example:"Set up CI pipeline"`
+	Metadata       json.RawMessage `json:"metadata" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ActivityFeedResponse represents a page of an activity feed
+type ActivityFeedResponse struct {
+	Events     []ActivityEventResponse `json:"events"`
+	TotalCount int64                   `json:"total_count" example:"100"`
+	Page       int                     `json:"page" example:"0"`
+	PageSize   int                     `json:"page_size" example:"20"`
+}
+
+// ActivityEventsToResponse converts activity events to their response DTOs
+func ActivityEventsToResponse(events []activity.Event) []ActivityEventResponse {
+	response := make([]ActivityEventResponse, len(events))
+	for i, e := range events {
+		metadata := json.RawMessage(e.Metadata)
+		if len(metadata) == 0 {
+			metadata = json.RawMessage("{}")
+		}
+		response[i] = ActivityEventResponse{
+			ID:             e.ID,
+			OrganizationID: e.OrganizationID,
+			ProjectID:      e.ProjectID,
+			ActorID:        e.ActorID,
+			Type:           string(e.Type),
+			Category:       e.Category,
+			EntityType:     e.EntityType,
+			EntityID:       e.EntityID,
+			Summary:        e.Summary,
+			Metadata:       metadata,
+			CreatedAt:      e.CreatedAt,
+		}
+	}
+	return response
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReadOnlyModeRequest represents the request body for turning on read-only mode
+type ReadOnlyModeRequest struct {
+	// Domain is the first path segment after /api, e.g. "tasks". Omit it to make the whole API read-only.
+	Domain string     `json:"domain" example:"tasks"`
+	Reason string     `json:"reason" binding:"required" example:"Migrating task storage"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// MergeAccountsRequest represents the request body for merging a duplicate account into another
+type MergeAccountsRequest struct {
+	SurvivorID  uuid.UUID `json:"survivor_id" binding:"required"`
+	DuplicateID uuid.UUID `json:"duplicate_id" binding:"required"`
+	// DryRun reports what the merge would do without changing anything
+	DryRun bool `json:"dry_run" example:"true"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+import "github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/agenda"
+
+// AcceptAgendaRequest represents the request body for accepting proposed agenda items
+type AcceptAgendaRequest struct {
+	Items []agenda.Item `json:"items" binding:"required"`
+}
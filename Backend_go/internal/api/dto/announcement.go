@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/announcements"
+	"github.com/google/uuid"
+)
+
+// CreateAnnouncementRequest represents the request body for posting an announcement
+type CreateAnnouncementRequest struct {
+	Title        string      `json:"title" binding:"required" example:"Office closed on Friday"`
+	Body         string      `json:"body" binding:"required" example:"The office is closed for maintenance. Please work from home."`
+	Priority     string      `json:"priority,omitempty" example:"important"`
+	AudienceType string      `json:"audience_type,omitempty" example:"projects"`
+	AudienceIDs  []uuid.UUID `json:"audience_ids,omitempty"`
+	ExpiresAt    *time.Time  `json:"expires_at,omitempty" example:"2024-03-01T00:00:00Z"`
+}
+
+// AnnouncementResponse represents an announcement in API responses.
+// Acknowledged is only set when the announcement is listed for one of its recipients.
+type AnnouncementResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	OrganizationID  uuid.UUID  `json:"organization_id"`
+	AuthorID        uuid.UUID  `json:"author_id"`
+	Title           string     `json:"title"`
+	Body            string     `json:"body"`
+	Priority        string     `json:"priority" example:"info"`
+	AudienceType    string     `json:"audience_type" example:"all"`
+	AudienceIDs     []string   `json:"audience_ids,omitempty"`
+	RecipientCount  int        `json:"recipient_count"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Acknowledged    *bool      `json:"acknowledged,omitempty"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at,omitempty"`
+	Acknowledgments *int64     `json:"acknowledgments,omitempty"`
+}
+
+// AnnouncementListResponse represents a page of announcements
+type AnnouncementListResponse struct {
+	Announcements []AnnouncementResponse `json:"announcements"`
+	TotalCount    int64                  `json:"total_count"`
+	Page          int                    `json:"page"`
+	PageSize      int                    `json:"page_size"`
+}
+
+// AnnouncementAcknowledgmentResponse represents the acknowledgment state of one recipient
+type AnnouncementAcknowledgmentResponse struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	Acknowledged   bool       `json:"acknowledged"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// AnnouncementToResponse converts an announcement to its response DTO
+func AnnouncementToResponse(a *announcements.Announcement) AnnouncementResponse {
+	return AnnouncementResponse{
+		ID:             a.ID,
+		OrganizationID: a.OrganizationID,
+		AuthorID:       a.AuthorID,
+		Title:          a.Title,
+		Body:           a.Body,
+		Priority:       string(a.Priority),
+		AudienceType:   string(a.AudienceType),
+		AudienceIDs:    []string(a.AudienceIDs),
+		RecipientCount: a.RecipientCount,
+		ExpiresAt:      a.ExpiresAt,
+		CreatedAt:      a.CreatedAt,
+	}
+}
+
+// UserAnnouncementToResponse converts an announcement seen by a recipient to its response DTO
+func UserAnnouncementToResponse(a *announcements.UserAnnouncement) AnnouncementResponse {
+	response := AnnouncementToResponse(&a.Announcement)
+	acknowledged := a.AcknowledgedAt != nil
+	response.Acknowledged = &acknowledged
+	response.AcknowledgedAt = a.AcknowledgedAt
+	return response
+}
+
+// UserAnnouncementsToResponse converts a recipient's announcements to their response DTOs
+func UserAnnouncementsToResponse(list []announcements.UserAnnouncement) []AnnouncementResponse {
+	response := make([]AnnouncementResponse, len(list))
+	for i := range list {
+		response[i] = UserAnnouncementToResponse(&list[i])
+	}
+	return response
+}
+
+// AnnouncementsWithStatsToResponse converts an organization's announcements to their response DTOs
+func AnnouncementsWithStatsToResponse(list []announcements.AnnouncementWithStats) []AnnouncementResponse {
+	response := make([]AnnouncementResponse, len(list))
+	for i := range list {
+		response[i] = AnnouncementToResponse(&list[i].Announcement)
+		acknowledged := list[i].Stats.Acknowledged
+		response[i].Acknowledgments = &acknowledged
+	}
+	return response
+}
+
+// AnnouncementAcknowledgmentsToResponse converts recipients to their acknowledgment DTOs
+func AnnouncementAcknowledgmentsToResponse(recipients []announcements.Recipient) []AnnouncementAcknowledgmentResponse {
+	response := make([]AnnouncementAcknowledgmentResponse, len(recipients))
+	for i, r := range recipients {
+		response[i] = AnnouncementAcknowledgmentResponse{
+			UserID:         r.UserID,
+			Acknowledged:   r.AcknowledgedAt != nil,
+			AcknowledgedAt: r.AcknowledgedAt,
+		}
+	}
+	return response
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest creates an API key for a machine client
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Scopes are "read", "write", "<domain>:read", "<domain>:write" or "workflows:execute",
+	// e.g. "tasks:write"
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
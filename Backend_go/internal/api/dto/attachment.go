@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttachmentResponse describes a file attached to a task, todo or task comment.
+// The file itself is downloaded from the attachment's endpoint.
+type AttachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	// ScanStatus is pending while the file is quarantined for malware scanning; only clean
+	// and skipped files can be downloaded
+	ScanStatus string `json:"scan_status"`
+	Threat     string `json:"threat,omitempty"`
+	// PreviewStatus is pending until images and PDFs are rendered; ThumbnailURL and
+	// PreviewURL point at the PNG renderings once it is ready
+	PreviewStatus string    `json:"preview_status"`
+	ThumbnailURL  string    `json:"thumbnail_url,omitempty"`
+	PreviewURL    string    `json:"preview_url,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
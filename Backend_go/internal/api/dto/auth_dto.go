@@ -20,9 +20,11 @@ type OAuth2CallbackRequest struct {
 
 // OAuth2CallbackResponse contains the token and user information after successful OAuth2 authentication
 type OAuth2CallbackResponse struct {
-	Token     string       `json:"token"`
-	ExpiresAt int64        `json:"expires_at"`
-	User      UserResponse `json:"user"`
+	Token            string       `json:"token"`
+	ExpiresAt        int64        `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt int64        `json:"refresh_expires_at"`
+	User             UserResponse `json:"user"`
 }
 
 // ProviderInfo contains information about an OAuth2 provider
@@ -0,0 +1,7 @@
+package dto
+
+// CreateBaselineRequest names a snapshot of the project schedule
+type CreateBaselineRequest struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Kickoff plan"`
+	Description string `json:"description" example:"Schedule agreed at kickoff"`
+}
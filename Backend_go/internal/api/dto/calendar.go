@@ -37,6 +37,7 @@ type CreateRecurrenceRuleRequest struct {
 type CreateEventReminderRequest struct {
 	MinutesBefore int                         `json:"minutes_before" binding:"required,min=0"`
 	Method        calendar.NotificationMethod `json:"method" binding:"required"`
+	WebhookURL    string                      `json:"webhook_url,omitempty"`
 }
 
 type UpdateCalendarEventRequest struct {
@@ -120,3 +121,35 @@ type RemoveCollaboratorRequest struct {
 	EventID uuid.UUID `json:"event_id" binding:"required"`
 	UserID  uuid.UUID `json:"user_id" binding:"required"`
 }
+
+// Attendee DTOs
+
+// AttendeeInviteRequest names an attendee by user ID, or an external guest by email
+type AttendeeInviteRequest struct {
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+	Email  string     `json:"email,omitempty" example:"guest@example.com"`
+	Name   string     `json:"name,omitempty" example:"Jane Guest"`
+}
+
+// InviteAttendeesRequest invites attendees to an event
+type InviteAttendeesRequest struct {
+	Attendees []AttendeeInviteRequest `json:"attendees" binding:"required,min=1,max=50"`
+}
+
+// RSVPRequest answers an invitation to an event
+type RSVPRequest struct {
+	Status  calendar.AttendeeStatus `json:"status" binding:"required,oneof=accepted declined tentative" example:"tentative"`
+	Comment string                  `json:"comment" binding:"max=500" example:"Might be a few minutes late"`
+}
+
+// DuplicateEventRequest copies an event to another day
+type DuplicateEventRequest struct {
+	Offset calendar.DuplicateOffset `json:"offset" binding:"required,oneof=next_week date" example:"next_week"`
+	// Date is the day of the copy in the event's timezone, required when offset is date
+	Date          string `json:"date,omitempty" example:"2024-06-14"`
+	CopyAttendees bool   `json:"copy_attendees"`
+}
+
+type ListAttendeesResponse struct {
+	Attendees []calendar.EventAttendee `json:"attendees"`
+}
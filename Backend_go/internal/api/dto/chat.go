@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/google/uuid"
+
+// ChatInstallResponse carries the provider page that installs the chat app
+type ChatInstallResponse struct {
+	URL string `json:"url" example:"https://slack.com/oauth/v2/authorize?client_id=..."`
+}
+
+// UpdateChatInstallationRequest represents the request body for changing a chat installation.
+// A nil UUID clears the default project.
+type UpdateChatInstallationRequest struct {
+	DefaultProjectID *uuid.UUID `json:"default_project_id,omitempty"`
+	SigningSecret    *string    `json:"signing_secret,omitempty"`
+}
+
+// AddChatChannelRequest represents the request body for posting events to a chat channel.
+// For Teams, channel_id is the channel's incoming webhook URL.
+type AddChatChannelRequest struct {
+	ChannelID   string     `json:"channel_id" binding:"required" example:"C024BE91L"`
+	ChannelName string     `json:"channel_name,omitempty" example:"#engineering"`
+	Events      []string   `json:"events,omitempty" example:"task.created,workflow.execution.finished"`
+	ProjectID   *uuid.UUID `json:"project_id,omitempty"`
+}
+
+// LinkChatUserRequest represents the request body for linking a chat account
+type LinkChatUserRequest struct {
+	Token string `json:"token" binding:"required"`
+}
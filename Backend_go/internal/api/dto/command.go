@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"encoding/json"
+)
+
+// ExecuteCommandRequest represents a command palette invocation
+// @Description Request body for running a command palette action
+type ExecuteCommandRequest struct {
+	Command string          `json:"command" binding:"required" example:"task.create"`
+	Args    json.RawMessage `json:"args,omitempty" swaggertype:"object"`
+}
+
+// CommandResultResponse represents the outcome of a command palette action
+type CommandResultResponse struct {
+	Command  string      `json:"command" example:"task.create"`
+	Data     interface{} `json:"data"`
+	Redirect string      `json:"redirect,omitempty" example:"/projects/550e8400-e29b-41d4-a716-446655440000"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// RegisterDeviceRequest registers a client installation and its capabilities
+type RegisterDeviceRequest struct {
+	DeviceID        string `json:"device_id" binding:"required"`
+	Platform        string `json:"platform" binding:"required,oneof=ios android web"`
+	Name            string `json:"name"`
+	LocationEnabled bool   `json:"location_enabled"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "github.com/google/uuid"
+
+// StartFocusSessionRequest starts a focus session, optionally on a task or a habit
+type StartFocusSessionRequest struct {
+	PlannedMinutes int        `json:"planned_minutes" binding:"omitempty,min=1,max=240" example:"25"`
+	TaskID         *uuid.UUID `json:"task_id,omitempty"`
+	HabitID        *uuid.UUID `json:"habit_id,omitempty"`
+	Note           string     `json:"note" binding:"max=500" example:"Draft the release notes"`
+}
+
+// FocusInterruptionRequest records an interruption of a running focus session
+type FocusInterruptionRequest struct {
+	Kind string `json:"kind" binding:"omitempty,oneof=internal external" example:"external"`
+	Note string `json:"note" binding:"max=500" example:"Phone call"`
+}
+
+// FocusHeatmapResponse represents the minutes focused per day for a heatmap
+type FocusHeatmapResponse struct {
+	Data     map[string]int `json:"data"`
+	Period   string         `json:"period"`
+	MinValue int            `json:"min_value"`
+	MaxValue int            `json:"max_value"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "github.com/google/uuid"
+
+// SetHabitLinkRequest links a habit to a task template. A worklog link needs task_id and
+// hours; a todo link needs todo_title and may link the todo to task_id.
+type SetHabitLinkRequest struct {
+	Action          string     `json:"action" binding:"required,oneof=worklog todo"`
+	Enabled         *bool      `json:"enabled"`
+	TaskID          *uuid.UUID `json:"task_id"`
+	Hours           float64    `json:"hours"`
+	TodoTitle       string     `json:"todo_title"`
+	TodoDescription string     `json:"todo_description"`
+	TodoPriority    string     `json:"todo_priority"`
+	TodoDueInHours  int        `json:"todo_due_in_hours"`
+}
@@ -8,18 +8,20 @@ import (
 
 // CreateHabitRequest represents the request to create a new habit
 type CreateHabitRequest struct {
-	Title       string     `json:"title" binding:"required"`
-	Description string     `json:"description"`
-	StartDay    time.Time  `json:"start_day" binding:"required"`
-	EndDay      *time.Time `json:"end_day"`
+	Title        string     `json:"title" binding:"required"`
+	Description  string     `json:"description"`
+	StartDay     time.Time  `json:"start_day" binding:"required"`
+	EndDay       *time.Time `json:"end_day"`
+	ReminderTime *string    `json:"reminder_time,omitempty" example:"07:30"` // HH:MM in UTC
 }
 
 // UpdateHabitRequest represents the request to update an existing habit
 type UpdateHabitRequest struct {
-	Title       *string    `json:"title,omitempty"`
-	Description *string    `json:"description,omitempty"`
-	StartDay    *time.Time `json:"start_day,omitempty"`
-	EndDay      *time.Time `json:"end_day,omitempty"`
+	Title        *string    `json:"title,omitempty"`
+	Description  *string    `json:"description,omitempty"`
+	StartDay     *time.Time `json:"start_day,omitempty"`
+	EndDay       *time.Time `json:"end_day,omitempty"`
+	ReminderTime *string    `json:"reminder_time,omitempty" example:"07:30"` // HH:MM in UTC, empty to clear
 }
 
 // HabitCompletionRequest represents the request to mark a habit as completed
@@ -43,6 +45,7 @@ type HabitResponse struct {
 	CreatedAt         time.Time  `json:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at"`
 	StreakQuality     float64    `json:"streak_quality"`
+	ReminderTime      *string    `json:"reminder_time,omitempty"`
 }
 
 // HabitListResponse represents the response for listing habits
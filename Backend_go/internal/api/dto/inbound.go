@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/inbound"
+	"github.com/google/uuid"
+)
+
+// UpdateInboundAddressRequest represents the request body for changing the email-in settings.
+// A nil list_id keeps the current list; the zero UUID switches back to the default list.
+type UpdateInboundAddressRequest struct {
+	AllowedSenders []string   `json:"allowed_senders,omitempty" example:"me@work.example.com,@example.com"`
+	ListID         *uuid.UUID `json:"list_id,omitempty"`
+	Active         *bool      `json:"active,omitempty"`
+}
+
+// InboundAddressResponse represents a user's forward-to-inbox address
+type InboundAddressResponse struct {
+	Email          string     `json:"email" example:"k7x2m4q9w1c8d3fa@in.compass.example.com"`
+	ListID         *uuid.UUID `json:"list_id,omitempty"`
+	AllowedSenders []string   `json:"allowed_senders"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// InboundMessageResponse represents the processing outcome of one inbound email
+type InboundMessageResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	Sender          string     `json:"sender"`
+	Subject         string     `json:"subject"`
+	Status          string     `json:"status" example:"accepted"`
+	Reason          string     `json:"reason,omitempty" example:"sender is not on the allow-list"`
+	TodoID          *uuid.UUID `json:"todo_id,omitempty"`
+	AttachmentCount int        `json:"attachment_count"`
+	ReceivedAt      time.Time  `json:"received_at"`
+}
+
+// InboundMessageListResponse represents a page of inbound emails
+type InboundMessageListResponse struct {
+	Messages   []InboundMessageResponse `json:"messages"`
+	TotalCount int64                    `json:"total_count"`
+	Page       int                      `json:"page"`
+	PageSize   int                      `json:"page_size"`
+}
+
+// TodoAttachmentResponse represents an attachment stored with a todo
+type TodoAttachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	TodoID      uuid.UUID `json:"todo_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// InboundAddressToResponse converts an inbound address to its response DTO
+func InboundAddressToResponse(a *inbound.Address, domain string) InboundAddressResponse {
+	senders := []string(a.AllowedSenders)
+	if senders == nil {
+		senders = []string{}
+	}
+	return InboundAddressResponse{
+		Email:          a.Token + "@" + domain,
+		ListID:         a.ListID,
+		AllowedSenders: senders,
+		Active:         a.Active,
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+}
+
+// InboundMessagesToResponse converts inbound emails to their response DTOs
+func InboundMessagesToResponse(messages []inbound.Message) []InboundMessageResponse {
+	response := make([]InboundMessageResponse, len(messages))
+	for i, m := range messages {
+		response[i] = InboundMessageResponse{
+			ID:              m.ID,
+			Sender:          m.Sender,
+			Subject:         m.Subject,
+			Status:          string(m.Status),
+			Reason:          m.Reason,
+			TodoID:          m.TodoID,
+			AttachmentCount: m.AttachmentCount,
+			ReceivedAt:      m.CreatedAt,
+		}
+	}
+	return response
+}
+
+// TodoAttachmentsToResponse converts attachments to their response DTOs
+func TodoAttachmentsToResponse(attachments []inbound.Attachment) []TodoAttachmentResponse {
+	response := make([]TodoAttachmentResponse, len(attachments))
+	for i, a := range attachments {
+		response[i] = TodoAttachmentResponse{
+			ID:          a.ID,
+			TodoID:      a.TodoID,
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			CreatedAt:   a.CreatedAt,
+		}
+	}
+	return response
+}
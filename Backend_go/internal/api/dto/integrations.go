@@ -0,0 +1,7 @@
+package dto
+
+// ReconnectIntegrationRequest represents the optional body of an integration reconnect
+type ReconnectIntegrationRequest struct {
+	// AccessToken replaces the token of integrations authorized with one, such as repository connections
+	AccessToken string `json:"access_token,omitempty" example:"ghp_xxxxxxxxxxxxxxxx"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// AcceptConsentsRequest represents the request body for accepting legal documents.
+// Each version must be the current version of its document, as shown to the user.
+type AcceptConsentsRequest struct {
+	Documents []ConsentAcceptance `json:"documents" binding:"required,min=1,dive"`
+}
+
+// ConsentAcceptance is one document version being accepted
+type ConsentAcceptance struct {
+	Type    string `json:"type" binding:"required" example:"terms"`
+	Version string `json:"version" binding:"required" example:"2024-06-01"`
+}
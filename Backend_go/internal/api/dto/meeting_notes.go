@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SaveMeetingNoteRequest sets the notes of an event occurrence. occurrence_time is the start
+// of the occurrence and may be left out for events that do not recur.
+type SaveMeetingNoteRequest struct {
+	OccurrenceTime *time.Time `json:"occurrence_time"`
+	Content        string     `json:"content" binding:"required"`
+}
+
+// ExtractActionItemsRequest turns the action items of an occurrence's notes into todos, or
+// into tasks in project_id when target is task
+type ExtractActionItemsRequest struct {
+	OccurrenceTime *time.Time `json:"occurrence_time"`
+	Target         string     `json:"target" binding:"omitempty,oneof=todo task"`
+	ProjectID      *uuid.UUID `json:"project_id"`
+}
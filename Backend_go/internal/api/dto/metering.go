@@ -0,0 +1,7 @@
+package dto
+
+// ReportUsageRequest represents the request body for reporting usage produced outside the API
+type ReportUsageRequest struct {
+	Meter    string `json:"meter" binding:"required" example:"ai_suggestions"`
+	Quantity int64  `json:"quantity" binding:"required,min=1" example:"3"`
+}
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/onboarding"
+	"github.com/google/uuid"
+)
+
+// StartOnboardingRequest represents the request body for the first onboarding step
+// @Description Request body for creating the organization that is being onboarded
+type StartOnboardingRequest struct {
+	Name        string `json:"name" binding:"required" example:"Acme Corporation"`
+	Description string `json:"description" example:"A leading technology company"`
+}
+
+// InviteMembersRequest represents the request body for inviting members during onboarding
+type InviteMembersRequest struct {
+	Emails []string `json:"emails" binding:"required,min=1" example:"alice@example.com,bob@example.com"`
+}
+
+// ChooseTemplateRequest represents the request body for picking a project template
+type ChooseTemplateRequest struct {
+	Template string `json:"template" binding:"required" example:"software"`
+}
+
+// OnboardingProgressResponse represents the onboarding wizard state in API responses
+// @Description Onboarding progress used by the frontend wizard to resume
+type OnboardingProgressResponse struct {
+	OrganizationID uuid.UUID  `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CompletedSteps []string   `json:"completed_steps" example:"create_organization,invite_members"`
+	NextStep       string     `json:"next_step,omitempty" example:"choose_template"`
+	InvitedEmails  []string   `json:"invited_emails"`
+	Template       string     `json:"template,omitempty" example:"software"`
+	ProjectID      *uuid.UUID `json:"project_id,omitempty"`
+	SeededAt       *time.Time `json:"seeded_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// OnboardingProgressToResponse converts onboarding progress to its response DTO
+func OnboardingProgressToResponse(p *onboarding.Progress) OnboardingProgressResponse {
+	completed := []string(p.CompletedSteps)
+	if completed == nil {
+		completed = []string{}
+	}
+	invited := []string(p.InvitedEmails)
+	if invited == nil {
+		invited = []string{}
+	}
+
+	return OnboardingProgressResponse{
+		OrganizationID: p.OrganizationID,
+		CompletedSteps: completed,
+		NextStep:       string(p.NextStep()),
+		InvitedEmails:  invited,
+		Template:       p.Template,
+		ProjectID:      p.ProjectID,
+		SeededAt:       p.SeededAt,
+		CompletedAt:    p.CompletedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
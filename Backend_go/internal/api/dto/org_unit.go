@@ -0,0 +1,31 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CreateOrgUnitRequest creates a department or team. Without a parent it sits directly
+// under the organization.
+type CreateOrgUnitRequest struct {
+	Name        string     `json:"name" binding:"required" example:"Engineering"`
+	Description string     `json:"description" example:"Product engineering teams"`
+	ParentID    *uuid.UUID `json:"parent_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// UpdateOrgUnitRequest changes a unit. Set parent_id to move it under another unit, or
+// move_to_root to move it directly under the organization.
+type UpdateOrgUnitRequest struct {
+	Name        *string    `json:"name" example:"Platform Engineering"`
+	Description *string    `json:"description"`
+	ParentID    *uuid.UUID `json:"parent_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	MoveToRoot  bool       `json:"move_to_root"`
+}
+
+// OrgUnitMemberRequest gives a member of the organization a role in a unit
+type OrgUnitMemberRequest struct {
+	Role string `json:"role" binding:"required" example:"admin"`
+}
+
+// MoveProjectToUnitRequest moves a project into a unit, or out of any unit when unit_id
+// is null
+type MoveProjectToUnitRequest struct {
+	UnitID *uuid.UUID `json:"unit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
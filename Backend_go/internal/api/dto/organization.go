@@ -35,6 +35,8 @@ type OrganizationResponse struct {
 	UpdatedAt   time.Time                       `json:"updated_at" example:"2024-03-15T10:30:00Z"`
 	CreatorID   uuid.UUID                       `json:"creator_id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	OwnerID     uuid.UUID                       `json:"owner_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// DeletionScheduledAt is set while the organization is read-only awaiting deletion
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" example:"2024-04-14T10:30:00Z"`
 }
 
 // OrganizationListResponse represents a paginated list of organizations
@@ -69,6 +71,8 @@ func OrganizationToResponse(org *organization.Organization) *OrganizationRespons
 		UpdatedAt:   org.UpdatedAt,
 		CreatorID:   org.CreatorID,
 		OwnerID:     org.OwnerID,
+
+		DeletionScheduledAt: org.DeletionScheduledAt,
 	}
 }
 
@@ -80,3 +84,16 @@ func OrganizationsToResponse(orgs []organization.Organization) []*OrganizationRe
 	}
 	return responses
 }
+
+// CreateInvitationRequest invites someone to the organization by email. role defaults to
+// the member role and expires_in_hours to a week.
+type CreateInvitationRequest struct {
+	Email          string `json:"email" binding:"required,email"`
+	Role           string `json:"role"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+// AcceptInvitationRequest accepts an invitation with the token from its email
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
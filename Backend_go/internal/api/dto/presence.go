@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/presence"
+	"github.com/google/uuid"
+)
+
+// ViewerResponse represents a user currently looking at a task or project
+type ViewerResponse struct {
+	UserID   uuid.UUID `json:"user_id"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// EditorResponse represents a user with an open edit session on a task field
+type EditorResponse struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Field    string    `json:"field" example:"description"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// UserPresenceResponse represents the online status of a user
+type UserPresenceResponse struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	Status      string     `json:"status" example:"online"`
+	ViewingType string     `json:"viewing_type,omitempty" example:"task"`
+	ViewingID   *uuid.UUID `json:"viewing_id,omitempty"`
+	Editing     string     `json:"editing,omitempty" example:"description"`
+	LastSeen    time.Time  `json:"last_seen"`
+}
+
+// ViewersToResponse converts presence viewers to their response DTOs
+func ViewersToResponse(viewers []presence.Viewer) []ViewerResponse {
+	response := make([]ViewerResponse, len(viewers))
+	for i, v := range viewers {
+		response[i] = ViewerResponse{UserID: v.UserID, LastSeen: v.LastSeen}
+	}
+	return response
+}
+
+// EditorsToResponse converts presence editors to their response DTOs
+func EditorsToResponse(editors []presence.Editor) []EditorResponse {
+	response := make([]EditorResponse, len(editors))
+	for i, e := range editors {
+		response[i] = EditorResponse{UserID: e.UserID, Field: e.Field, LastSeen: e.LastSeen}
+	}
+	return response
+}
+
+// UserPresenceToResponse converts user presence to its response DTO
+func UserPresenceToResponse(p presence.UserPresence) UserPresenceResponse {
+	response := UserPresenceResponse{
+		UserID:   p.UserID,
+		Status:   string(p.Status),
+		LastSeen: p.LastSeen,
+	}
+	if p.Viewing != nil {
+		viewingID := p.Viewing.ID
+		response.ViewingType = p.Viewing.Type
+		response.ViewingID = &viewingID
+		response.Editing = p.Editing
+	}
+	return response
+}
@@ -39,6 +39,7 @@ type ProjectResponse struct {
 	Description    string                `json:"description" example:"A project for managing tasks"`
 	Status         project.ProjectStatus `json:"status" example:"active"`
 	OrganizationID uuid.UUID             `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	UnitID         *uuid.UUID            `json:"unit_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440004"`
 	CreatorID      uuid.UUID             `json:"creator_id" example:"550e8400-e29b-41d4-a716-446655440002"`
 	OwnerID        uuid.UUID             `json:"owner_id" example:"550e8400-e29b-41d4-a716-446655440003"`
 	StartDate      time.Time             `json:"start_date" example:"2024-01-01T00:00:00Z"`
@@ -91,6 +92,7 @@ func ProjectToResponse(p *project.Project) *ProjectResponse {
 		UpdatedAt:      p.UpdatedAt,
 		CreatorID:      p.CreatorID,
 		OrganizationID: p.OrganizationID,
+		UnitID:         p.UnitID,
 		OwnerID:        p.OwnerID,
 		StartDate:      p.StartDate,
 		EndDate:        p.EndDate,
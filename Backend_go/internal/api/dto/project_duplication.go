@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// DuplicateProjectRequest controls what a copy of a project includes
+type DuplicateProjectRequest struct {
+	// Name of the copy; "Copy of <name>" when empty
+	Name           string `json:"name" binding:"omitempty,max=100" example:"Website relaunch (Q3)"`
+	IncludeTasks   bool   `json:"include_tasks" example:"true"`
+	IncludeMembers bool   `json:"include_members" example:"false"`
+	// StartDate moves the copy and its tasks so that it starts on this date
+	StartDate *time.Time `json:"start_date,omitempty" example:"2025-07-01T00:00:00Z"`
+}
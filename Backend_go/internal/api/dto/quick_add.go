@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/quickadd"
+	"github.com/google/uuid"
+)
+
+// QuickAddRequest represents a natural-language text to turn into an event or a task
+// @Description Request body for quick-add. Mode preview only parses the text; commit also creates the event or task.
+type QuickAddRequest struct {
+	Text string `json:"text" binding:"required" example:"Lunch with Omar tomorrow 1pm for 1h #personal"`
+	// Mode is preview (the default) or commit
+	Mode string `json:"mode" example:"preview"`
+	// Kind forces an event or a task; texts with a time become events otherwise
+	Kind string `json:"kind,omitempty" example:"event"`
+	// ProjectID is the project a committed task is created in
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+}
+
+// QuickAddResponse represents a parsed quick-add text and what was created from it
+type QuickAddResponse struct {
+	Parsed    quickadd.Parsed         `json:"parsed"`
+	Committed bool                    `json:"committed"`
+	Event     *calendar.CalendarEvent `json:"event,omitempty"`
+	Task      *TaskResponse           `json:"task,omitempty"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// RetentionPolicyRequest replaces the retention policy of one category of data
+type RetentionPolicyRequest struct {
+	Enabled       bool `json:"enabled" example:"true"`
+	RetentionDays int  `json:"retention_days" binding:"required,min=1,max=3650" example:"365"`
+	NoticeDays    int  `json:"notice_days" binding:"min=0,max=90" example:"7"`
+}
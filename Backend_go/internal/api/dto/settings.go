@@ -0,0 +1,43 @@
+package dto
+
+// SettingsRequest replaces the default settings of an organization or the overrides of a
+// project. Omitted settings are inherited.
+type SettingsRequest struct {
+	EnabledStatuses []string               `json:"enabled_statuses" example:"Upcoming,In Progress,Completed"`
+	CustomStatuses  *CustomStatusesRequest `json:"custom_statuses"`
+	DefaultPriority string                 `json:"default_priority" example:"High"`
+	SLA             *SLADefaultsRequest    `json:"sla"`
+	Board           *BoardConfigRequest    `json:"board"`
+}
+
+// CustomStatusesRequest replaces the built-in task statuses with the project's own. It
+// cannot be combined with enabled_statuses.
+type CustomStatusesRequest struct {
+	Statuses []CustomStatusRequest `json:"statuses" binding:"required"`
+	// Transitions lists the keys each status may move to; statuses without an entry may
+	// move to any other
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// CustomStatusRequest is one custom status. Tasks in it are stored with Status, or the
+// default built-in status of its category.
+type CustomStatusRequest struct {
+	Key      string `json:"key" binding:"required" example:"in_review"`
+	Name     string `json:"name" binding:"required" example:"In Review"`
+	Category string `json:"category" binding:"required" example:"in_progress"`
+	Status   string `json:"status" example:"In Progress"`
+}
+
+// SLADefaultsRequest sets the clock of projects that have not chosen one and the deadline
+// of new workflows. Only organizations set it.
+type SLADefaultsRequest struct {
+	Clock                 string `json:"clock" example:"business"`
+	WorkflowDeadlineHours int    `json:"workflow_deadline_hours" example:"72"`
+}
+
+// BoardConfigRequest is the layout of a task board
+type BoardConfigRequest struct {
+	Columns   []string       `json:"columns" example:"Upcoming,In Progress,Completed"`
+	WIPLimits map[string]int `json:"wip_limits"`
+	Swimlanes string         `json:"swimlanes" example:"assignee"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// WorkingHoursRequest replaces the working hours and holiday calendar of an organization
+type WorkingHoursRequest struct {
+	Timezone     string           `json:"timezone" example:"Europe/Berlin"`
+	WorkdayStart string           `json:"workday_start" binding:"required" example:"09:00"`
+	WorkdayEnd   string           `json:"workday_end" binding:"required" example:"17:00"`
+	Days         []string         `json:"days" binding:"required,min=1" example:"mon,tue,wed,thu,fri"`
+	Holidays     []HolidayRequest `json:"holidays" binding:"dive"`
+}
+
+// HolidayRequest is one day off in the organization's time zone
+type HolidayRequest struct {
+	Date string `json:"date" binding:"required" example:"2026-12-25"`
+	Name string `json:"name" binding:"required" example:"Christmas Day"`
+}
+
+// ProjectClockRequest chooses how a project measures due-soon and staleness windows
+type ProjectClockRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=wall business" example:"business"`
+}
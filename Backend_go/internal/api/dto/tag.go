@@ -0,0 +1,26 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CreateTagRequest creates a tag of the organization
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required" example:"customer-facing"`
+	Color string `json:"color" example:"#3b82f6"`
+}
+
+// UpdateTagRequest renames or recolors a tag. Omitted fields are left unchanged.
+type UpdateTagRequest struct {
+	Name  *string `json:"name" example:"customer facing"`
+	Color *string `json:"color" example:"#3b82f6"`
+}
+
+// MergeTagsRequest lists the tags merged into the tag of the path and then deleted
+type MergeTagsRequest struct {
+	SourceIDs []uuid.UUID `json:"source_ids" binding:"required,min=1"`
+}
+
+// SetEntityTagsRequest replaces the tags of the organization on an entity. An empty list
+// removes them all.
+type SetEntityTagsRequest struct {
+	TagIDs []uuid.UUID `json:"tag_ids"`
+}
@@ -3,6 +3,7 @@ package dto
 import (
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
 	"github.com/google/uuid"
 )
 
@@ -36,11 +37,16 @@ type UpdateTaskRequest struct {
 	AssigneeID     *uuid.UUID  `json:"assignee_id,omitempty"`
 	ReviewerID     *uuid.UUID  `json:"reviewer_id,omitempty"`
 	CategoryID     *uuid.UUID  `json:"category_id,omitempty"`
+	ParentTaskID   *uuid.UUID  `json:"parent_task_id,omitempty"`
 	EstimatedHours *float64    `json:"estimated_hours,omitempty"`
 	StartDate      *time.Time  `json:"start_date,omitempty"`
 	Duration       *float64    `json:"duration,omitempty"`
 	DueDate        *time.Time  `json:"due_date,omitempty"`
 	Dependencies   []uuid.UUID `json:"dependencies,omitempty"`
+
+	// BaseDescriptionVersion is the description_version the client started editing from.
+	// When set, the save is rejected with 409 if someone else changed the description meanwhile.
+	BaseDescriptionVersion *int `json:"base_description_version,omitempty"`
 }
 
 // TaskResponse represents a task in API responses
@@ -50,6 +56,7 @@ type TaskResponse struct {
 	Title          string     `json:"title"`
 	Description    string     `json:"description"`
 	Status         string     `json:"status"`
+	CustomStatus   string     `json:"custom_status,omitempty"`
 	Priority       string     `json:"priority"`
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
@@ -64,6 +71,24 @@ type TaskResponse struct {
 	StartDate      time.Time  `json:"start_date"`
 	Duration       *float64   `json:"duration,omitempty"`
 	DueDate        *time.Time `json:"due_date,omitempty"`
+	ArchivedAt     *time.Time `json:"archived_at,omitempty"`
+
+	Position           float64 `json:"position"`
+	DescriptionVersion int     `json:"description_version"`
+
+	// Viewers lists who currently has the task open
+	Viewers []ViewerResponse `json:"viewers,omitempty"`
+	// Editors lists who is currently editing a field of the task
+	Editors []EditorResponse `json:"editors,omitempty"`
+	// Risk is the latest nightly risk analysis of an open task
+	Risk *task.RiskAnnotation `json:"risk,omitempty"`
+	// PriorityScore ranks open tasks from 0 to 100; in lists sorted by it, it is under
+	// the caller's weights
+	PriorityScore float64 `json:"priority_score"`
+	// PriorityFactors are what the score is made of, once the task has been scored
+	PriorityFactors *task.PriorityFactors `json:"priority_factors,omitempty"`
+	// Attachments lists the files attached to the task
+	Attachments []AttachmentResponse `json:"attachments,omitempty"`
 }
 
 // TaskListResponse represents a paginated list of tasks with metadata
@@ -72,6 +97,8 @@ type TaskListResponse struct {
 	TotalCount int64          `json:"total_count"`
 	Page       int            `json:"page"`
 	PageSize   int            `json:"page_size"`
+	// NextCursor continues a cursor listing; it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // TaskFilterRequest represents the query parameters for filtering tasks
@@ -94,7 +121,100 @@ type UpdateTaskStatusRequest struct {
 	Status string `json:"status" binding:"required" example:"In Progress"`
 }
 
+// MoveTaskRequest represents the request body for moving a task on the project board
+type MoveTaskRequest struct {
+	Status string `json:"status" binding:"required" example:"In Progress"`
+	// Position is the zero-based index in the target column; omit it to move the task to the bottom
+	Position *int `json:"position,omitempty" binding:"omitempty,min=0" example:"2"`
+}
+
 // AssignTaskRequest represents the request body for assigning a task to a user
 type AssignTaskRequest struct {
 	AssigneeID string `json:"assignee_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
 }
+
+// CreateTaskCommentRequest represents the request body for commenting on a task
+type CreateTaskCommentRequest struct {
+	Body string `json:"body" binding:"required" example:"Blocked until the API keys arrive"`
+}
+
+// SubtaskTreeResponse represents a task with its subtask tree and the completion rollup of
+// everything below it
+type SubtaskTreeResponse struct {
+	Task     *TaskResponse         `json:"task"`
+	Rollup   task.SubtaskRollup    `json:"rollup"`
+	Children []SubtaskTreeResponse `json:"children"`
+}
+
+// ProjectHealthResponse represents the schedule risk summary of a project
+type ProjectHealthResponse struct {
+	ProjectID      uuid.UUID      `json:"project_id"`
+	Score          float64        `json:"score" example:"82.5"`
+	TotalTasks     int            `json:"total_tasks"`
+	OpenTasks      int            `json:"open_tasks"`
+	CompletedTasks int            `json:"completed_tasks"`
+	Completion     float64        `json:"completion" example:"40"`
+	Overdue        int            `json:"overdue"`
+	AtRisk         int            `json:"at_risk"`
+	HighRisk       int            `json:"high_risk"`
+	Blocked        int            `json:"blocked"`
+	DueSoon        int            `json:"due_soon"`
+	Clock          string         `json:"clock" example:"business"`
+	AtRiskTasks    []TaskResponse `json:"at_risk_tasks"`
+	AnalyzedAt     *time.Time     `json:"analyzed_at,omitempty"`
+}
+
+// MyWorkResponse represents the caller's open assigned tasks grouped by urgency
+type MyWorkResponse struct {
+	Overdue []TaskResponse `json:"overdue"`
+	AtRisk  []TaskResponse `json:"at_risk"`
+	DueSoon []TaskResponse `json:"due_soon"`
+	Other   []TaskResponse `json:"other"`
+}
+
+// TaskDependencyLinkResponse represents a task reached by following dependencies
+type TaskDependencyLinkResponse struct {
+	Task  *TaskResponse `json:"task"`
+	Depth int           `json:"depth" example:"1"`
+}
+
+// TaskDependenciesResponse represents a task with the tasks it waits on and the tasks waiting on it
+type TaskDependenciesResponse struct {
+	Task       *TaskResponse                `json:"task"`
+	Upstream   []TaskDependencyLinkResponse `json:"upstream"`
+	Downstream []TaskDependencyLinkResponse `json:"downstream"`
+}
+
+// ScheduledTaskResponse represents a task placed on the project schedule, in hours from now
+type ScheduledTaskResponse struct {
+	Task           *TaskResponse `json:"task"`
+	RemainingHours float64       `json:"remaining_hours" example:"6"`
+	EarliestStart  float64       `json:"earliest_start" example:"8"`
+	EarliestFinish float64       `json:"earliest_finish" example:"14"`
+	LatestStart    float64       `json:"latest_start" example:"10"`
+	LatestFinish   float64       `json:"latest_finish" example:"16"`
+	Slack          float64       `json:"slack" example:"2"`
+	Critical       bool          `json:"critical"`
+}
+
+// CriticalPathResponse represents the longest chain of dependent work in a project
+type CriticalPathResponse struct {
+	ProjectID  uuid.UUID               `json:"project_id"`
+	TotalHours float64                 `json:"total_hours" example:"40"`
+	Path       []ScheduledTaskResponse `json:"path"`
+	Tasks      []ScheduledTaskResponse `json:"tasks"`
+}
+
+// TaskCommentResponse is a task comment with the files attached to it
+type TaskCommentResponse struct {
+	task.TaskComment
+	Attachments []AttachmentResponse `json:"attachments,omitempty"`
+}
+
+// TaskCommentListResponse represents a page of task comments, oldest first
+type TaskCommentListResponse struct {
+	Comments   []TaskCommentResponse `json:"comments"`
+	TotalCount int64                 `json:"total_count"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+}
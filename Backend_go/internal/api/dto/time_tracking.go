@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// StartTimerRequest optionally describes the work a timer tracks
+type StartTimerRequest struct {
+	Note string `json:"note" binding:"max=500" example:"Reviewing the API draft"`
+}
+
+// CreateTimeEntryRequest logs time spent on a task by hand
+type CreateTimeEntryRequest struct {
+	StartedAt time.Time `json:"started_at" binding:"required" example:"2024-03-04T09:00:00Z"`
+	Hours     float64   `json:"hours" binding:"required,gt=0,lte=24" example:"1.5"`
+	Note      string    `json:"note" binding:"max=500" example:"Pairing on the importer"`
+}
+
+// UpdateTimeEntryRequest corrects a time entry. Omitted fields are left unchanged.
+type UpdateTimeEntryRequest struct {
+	StartedAt *time.Time `json:"started_at,omitempty" example:"2024-03-04T09:00:00Z"`
+	Hours     *float64   `json:"hours,omitempty" binding:"omitempty,gt=0,lte=24" example:"2"`
+	Note      *string    `json:"note,omitempty" binding:"omitempty,max=500"`
+}
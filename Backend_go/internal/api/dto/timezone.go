@@ -0,0 +1,22 @@
+package dto
+
+import "github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/timezone"
+
+// TimezoneMigrationRequest represents the request body for previewing or applying a timezone change.
+// Each shift option defaults to true.
+type TimezoneMigrationRequest struct {
+	Timezone          string `json:"timezone" binding:"required" example:"America/New_York"`
+	ShiftEvents       *bool  `json:"shift_events,omitempty"`
+	ShiftHabits       *bool  `json:"shift_habits,omitempty"`
+	ShiftWorkingHours *bool  `json:"shift_working_hours,omitempty"`
+}
+
+// ToInput converts the request to a migration input, applying defaults
+func (r TimezoneMigrationRequest) ToInput() timezone.MigrationInput {
+	return timezone.MigrationInput{
+		Timezone:          r.Timezone,
+		ShiftEvents:       r.ShiftEvents == nil || *r.ShiftEvents,
+		ShiftHabits:       r.ShiftHabits == nil || *r.ShiftHabits,
+		ShiftWorkingHours: r.ShiftWorkingHours == nil || *r.ShiftWorkingHours,
+	}
+}
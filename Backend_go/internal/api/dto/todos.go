@@ -56,12 +56,15 @@ type TodoResponse struct {
 	Checklist             map[string]interface{} `json:"checklist"`
 	LinkedTaskID          *uuid.UUID             `json:"linked_task_id"`
 	LinkedCalendarEventID *uuid.UUID             `json:"linked_calendar_event_id"`
+	RecurrenceSourceID    *uuid.UUID             `json:"recurrence_source_id,omitempty"`
 	IsCompleted           bool                   `json:"is_completed"`
 	CompletedAt           *time.Time             `json:"completed_at"`
 	CreatedAt             time.Time              `json:"created_at"`
 	UpdatedAt             time.Time              `json:"updated_at"`
 	UserID                uuid.UUID              `json:"user_id"`
 	ListID                uuid.UUID              `json:"list_id"`
+	// Attachments lists the files attached to the todo
+	Attachments []AttachmentResponse `json:"attachments,omitempty"`
 }
 
 type TodoListResponse struct {
@@ -76,6 +79,8 @@ type TodoListResponse struct {
 	TotalCount  int64           `json:"total_count"`
 	Page        int             `json:"page"`
 	PageSize    int             `json:"page_size"`
+	// NextCursor continues a cursor listing; it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type TodoListsResponse struct {
@@ -111,3 +116,20 @@ type UpdateTodoStatusRequest struct {
 type UpdateTodoPriorityRequest struct {
 	Priority string `json:"priority" binding:"required" example:"High"`
 }
+
+// SetTodoGeofenceRequest places the geofence of a todo's reminder
+type SetTodoGeofenceRequest struct {
+	Latitude     *float64 `json:"latitude" binding:"required"`
+	Longitude    *float64 `json:"longitude" binding:"required"`
+	RadiusMeters float64  `json:"radius_meters" binding:"required"`
+	Trigger      string   `json:"trigger" binding:"required,oneof=enter exit"`
+	Label        string   `json:"label"`
+}
+
+// GeofenceEventRequest reports that a client crossed a geofence
+type GeofenceEventRequest struct {
+	DeviceID   string     `json:"device_id" binding:"required"`
+	GeofenceID uuid.UUID  `json:"geofence_id" binding:"required"`
+	Transition string     `json:"transition" binding:"required,oneof=enter exit"`
+	OccurredAt *time.Time `json:"occurred_at"`
+}
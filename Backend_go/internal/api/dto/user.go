@@ -91,10 +91,50 @@ type LoginRequest struct {
 // LoginResponse represents the response after successful login
 // @Description Response containing authentication token and user information
 type LoginResponse struct {
+	Token            string          `json:"token"`
+	User             UserResponse    `json:"user"`
+	Session          SessionResponse `json:"session"`
+	ExpiresAt        time.Time       `json:"expires_at"`
+	RefreshToken     string          `json:"refresh_token"`
+	RefreshExpiresAt time.Time       `json:"refresh_expires_at"`
+}
+
+// RefreshTokenRequest represents the request body for refreshing or revoking a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshTokenResponse represents a renewed access token and its replacement refresh token
+// @Description The previous refresh token is no longer valid once this response is returned
+type RefreshTokenResponse struct {
+	Token            string          `json:"token"`
+	ExpiresAt        time.Time       `json:"expires_at"`
+	RefreshToken     string          `json:"refresh_token"`
+	RefreshExpiresAt time.Time       `json:"refresh_expires_at"`
+	Session          SessionResponse `json:"session"`
+}
+
+// CreateScopedTokenRequest issues an access token limited to scopes, for an integration
+type CreateScopedTokenRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"Calendar sync"`
+	// Scopes use the same format as API key scopes, e.g. "tasks:read" or "workflows:execute"
+	Scopes []string `json:"scopes" binding:"required" example:"tasks:read,calendar:write"`
+	// ExpiresInMinutes defaults to 60 and may be at most a day
+	ExpiresInMinutes int `json:"expires_in_minutes" binding:"omitempty,min=1,max=1440" example:"60"`
+}
+
+// ScopedTokenResponse represents an access token limited to scopes. It cannot be refreshed.
+type ScopedTokenResponse struct {
 	Token     string          `json:"token"`
-	User      UserResponse    `json:"user"`
-	Session   SessionResponse `json:"session"`
+	Scopes    []string        `json:"scopes"`
 	ExpiresAt time.Time       `json:"expires_at"`
+	Session   SessionResponse `json:"session"`
+}
+
+// ChangePasswordRequest represents the request body for changing the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"securePass123"`
+	NewPassword     string `json:"new_password" binding:"required,min=8" example:"evenMoreSecure456"`
 }
 
 // TokenResponse represents a JWT token response
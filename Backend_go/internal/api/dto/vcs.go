@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/vcs"
+	"github.com/google/uuid"
+)
+
+// CreateVCSConnectionRequest represents the request body for connecting a repository to a project
+type CreateVCSConnectionRequest struct {
+	ProjectID   uuid.UUID `json:"project_id" binding:"required"`
+	Provider    string    `json:"provider" binding:"required" example:"github"`
+	Repository  string    `json:"repository" binding:"required" example:"acme/website"`
+	BaseURL     string    `json:"base_url,omitempty" example:"https://gitlab.example.com"`
+	AccessToken string    `json:"access_token" binding:"required"`
+	SyncStatus  *string   `json:"sync_status,omitempty" example:"Completed"`
+}
+
+// UpdateVCSConnectionRequest represents the request body for changing a repository connection.
+// An empty sync_status turns status sync off.
+type UpdateVCSConnectionRequest struct {
+	AccessToken  *string `json:"access_token,omitempty"`
+	SyncStatus   *string `json:"sync_status,omitempty" example:"Under Review"`
+	RotateSecret bool    `json:"rotate_secret,omitempty"`
+}
+
+// VCSConnectionResponse represents a repository connection in API responses.
+// WebhookSecret is only returned when the connection is created or its secret is rotated.
+type VCSConnectionResponse struct {
+	ID            uuid.UUID `json:"id"`
+	ProjectID     uuid.UUID `json:"project_id"`
+	Provider      string    `json:"provider"`
+	Repository    string    `json:"repository"`
+	BaseURL       string    `json:"base_url,omitempty"`
+	SyncStatus    string    `json:"sync_status"`
+	WebhookPath   string    `json:"webhook_path" example:"/api/integrations/vcs/webhooks/550e8400-e29b-41d4-a716-446655440000"`
+	WebhookSecret string    `json:"webhook_secret,omitempty"`
+	CreatedBy     uuid.UUID `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// LinkTaskRequest represents the request body for linking a task to an issue or pull request.
+// Either url, or connection_id with kind and number, identifies the item.
+type LinkTaskRequest struct {
+	URL          string     `json:"url,omitempty" example:"https://github.com/acme/website/pull/42"`
+	ConnectionID *uuid.UUID `json:"connection_id,omitempty"`
+	Kind         string     `json:"kind,omitempty" example:"pull_request"`
+	Number       int        `json:"number,omitempty" example:"42"`
+}
+
+// VCSConnectionToResponse converts a repository connection to its response, including the
+// webhook secret when withSecret is set
+func VCSConnectionToResponse(conn *vcs.Connection, withSecret bool) VCSConnectionResponse {
+	resp := VCSConnectionResponse{
+		ID:          conn.ID,
+		ProjectID:   conn.ProjectID,
+		Provider:    string(conn.Provider),
+		Repository:  conn.Repository,
+		BaseURL:     conn.BaseURL,
+		SyncStatus:  conn.SyncStatus,
+		WebhookPath: "/api/integrations/vcs/webhooks/" + conn.ID.String(),
+		CreatedBy:   conn.CreatedBy,
+		CreatedAt:   conn.CreatedAt,
+		UpdatedAt:   conn.UpdatedAt,
+	}
+	if withSecret {
+		resp.WebhookSecret = conn.WebhookSecret
+	}
+	return resp
+}
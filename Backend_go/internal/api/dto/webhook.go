@@ -0,0 +1,130 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/google/uuid"
+)
+
+// CreateWebhookRequest represents the request body for registering a webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required" example:"https://example.com/hooks/compass"`
+	Events      []string `json:"events" binding:"required,min=1" example:"task.created,habit.completed"`
+	Description string   `json:"description,omitempty" example:"Sync tasks to our CRM"`
+}
+
+// UpdateWebhookRequest represents the request body for changing a webhook
+type UpdateWebhookRequest struct {
+	URL          *string  `json:"url,omitempty"`
+	Events       []string `json:"events,omitempty"`
+	Description  *string  `json:"description,omitempty"`
+	Active       *bool    `json:"active,omitempty"`
+	RotateSecret bool     `json:"rotate_secret,omitempty"`
+}
+
+// WebhookResponse represents a webhook in API responses.
+// The secret is only included when it was just generated.
+type WebhookResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	OwnerID        uuid.UUID  `json:"owner_id"`
+	URL            string     `json:"url"`
+	Events         []string   `json:"events"`
+	Description    string     `json:"description,omitempty"`
+	Active         bool       `json:"active"`
+	Secret         string     `json:"secret,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveryResponse represents one entry of a webhook's delivery log
+type WebhookDeliveryResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhook_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type" example:"task.created"`
+	Status         string          `json:"status" example:"succeeded"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookDeliveryListResponse represents a page of a webhook's delivery log
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	TotalCount int64                     `json:"total_count"`
+	Page       int                       `json:"page"`
+	PageSize   int                       `json:"page_size"`
+}
+
+// WebhookToResponse converts a webhook to its response DTO
+func WebhookToResponse(w *webhooks.Webhook, includeSecret bool) *WebhookResponse {
+	if w == nil {
+		return nil
+	}
+	response := &WebhookResponse{
+		ID:             w.ID,
+		OrganizationID: w.OrganizationID,
+		OwnerID:        w.OwnerID,
+		URL:            w.URL,
+		Events:         []string(w.Events),
+		Description:    w.Description,
+		Active:         w.Active,
+		CreatedAt:      w.CreatedAt,
+		UpdatedAt:      w.UpdatedAt,
+	}
+	if includeSecret {
+		response.Secret = w.Secret
+	}
+	return response
+}
+
+// WebhooksToResponse converts webhooks to their response DTOs
+func WebhooksToResponse(list []webhooks.Webhook) []*WebhookResponse {
+	response := make([]*WebhookResponse, len(list))
+	for i := range list {
+		response[i] = WebhookToResponse(&list[i], false)
+	}
+	return response
+}
+
+// WebhookDeliveryToResponse converts a delivery to its response DTO
+func WebhookDeliveryToResponse(d *webhooks.Delivery) WebhookDeliveryResponse {
+	payload := json.RawMessage(d.Payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+	response := WebhookDeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		Payload:        payload,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == webhooks.DeliveryStatusPending {
+		next := d.NextAttemptAt
+		response.NextAttemptAt = &next
+	}
+	return response
+}
+
+// WebhookDeliveriesToResponse converts deliveries to their response DTOs
+func WebhookDeliveriesToResponse(deliveries []webhooks.Delivery) []WebhookDeliveryResponse {
+	response := make([]WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		response[i] = WebhookDeliveryToResponse(&deliveries[i])
+	}
+	return response
+}
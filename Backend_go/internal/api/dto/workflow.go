@@ -62,6 +62,12 @@ type UpdateWorkflowStepRequest struct {
 	AssignedToRoleID *uuid.UUID             `json:"assigned_to_role_id"`
 }
 
+// CloneWorkflowRequest represents the options for cloning a workflow
+type CloneWorkflowRequest struct {
+	Name       string `json:"name" binding:"max=255" example:"Client onboarding"`
+	AsTemplate bool   `json:"as_template"`
+}
+
 // WorkflowResponse represents the response for a workflow
 type WorkflowResponse struct {
 	ID                uuid.UUID              `json:"id"`
@@ -74,6 +80,8 @@ type WorkflowResponse struct {
 	Config            map[string]interface{} `json:"config"`
 	AIEnabled         bool                   `json:"ai_enabled"`
 	Tags              []string               `json:"tags"`
+	IsTemplate        bool                   `json:"is_template"`
+	SourceID          *uuid.UUID             `json:"source_id,omitempty"`
 	EstimatedDuration *int64                 `json:"estimated_duration,omitempty"`
 	ActualDuration    *int64                 `json:"actual_duration,omitempty"`
 	Deadline          *time.Time             `json:"deadline,omitempty"`
@@ -89,6 +97,7 @@ type WorkflowListResponse struct {
 	Total            int64              `json:"total"`
 	Page             int                `json:"page"`
 	PageSize         int                `json:"page_size"`
+	NextCursor       string             `json:"next_cursor,omitempty"`
 	Timeout          *int64             `json:"timeout,omitempty"`
 	IsRequired       bool               `json:"is_required"`
 	AssignedTo       *uuid.UUID         `json:"assigned_to,omitempty"`
@@ -158,6 +167,8 @@ func WorkflowToResponse(w *workflow.Workflow) *WorkflowResponse {
 		Config:            config,
 		AIEnabled:         w.AIEnabled,
 		Tags:              w.Tags,
+		IsTemplate:        w.IsTemplate,
+		SourceID:          w.SourceID,
 		EstimatedDuration: estimatedDuration,
 		ActualDuration:    actualDuration,
 		Deadline:          w.Deadline,
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/accountmerge"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountMergeHandler handles HTTP requests for merging duplicate user accounts
+type AccountMergeHandler struct {
+	service accountmerge.Service
+}
+
+// NewAccountMergeHandler creates a new AccountMergeHandler instance
+func NewAccountMergeHandler(service accountmerge.Service) *AccountMergeHandler {
+	return &AccountMergeHandler{service: service}
+}
+
+// MergeAccounts godoc
+// @Summary Merge a duplicate user account into another
+// @Description Move everything of the duplicate account to the survivor in one transaction: what it created or owns, its task assignments, memberships, comments and signed-in sessions. Where both accounts have the same membership or device the survivor's is kept. The duplicate is then deactivated and its access tokens revoked; its refresh tokens renew tokens of the survivor. The audit log and activity feed keep naming the account that acted. With dry_run the merge is reported but not applied.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MergeAccountsRequest true "Accounts to merge"
+// @Success 200 {object} accountmerge.Report "What the merge moved, or would move"
+// @Failure 400 {object} map[string]string "Invalid request or the same account twice"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Admin role required"
+// @Failure 404 {object} map[string]string "Account not found"
+// @Failure 409 {object} map[string]string "Both accounts have a running timer or focus session"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/users/merge [post]
+func (h *AccountMergeHandler) MergeAccounts(c *gin.Context) {
+	var req dto.MergeAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.Merge(c.Request.Context(), accountmerge.Request{
+		SurvivorID:  req.SurvivorID,
+		DuplicateID: req.DuplicateID,
+		DryRun:      req.DryRun,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, accountmerge.ErrSameAccount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, accountmerge.ErrAccountNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, accountmerge.ErrRunningOnBoth):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/activity"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ActivityHandler handles HTTP requests for project, organization and task activity feeds
+type ActivityHandler struct {
+	service        activity.Service
+	projectService project.Service
+	taskService    task.Service
+}
+
+// NewActivityHandler creates a new ActivityHandler instance
+func NewActivityHandler(service activity.Service, projectService project.Service, taskService task.Service) *ActivityHandler {
+	return &ActivityHandler{
+		service:        service,
+		projectService: projectService,
+		taskService:    taskService,
+	}
+}
+
+// GetProjectFeed godoc
+// @Summary Get project activity feed
+// @Description Get a paginated timeline of task activity, comments, membership changes and workflow runs for a project
+// @Tags activity
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Number of items per page" default(20)
+// @Param category query string false "Comma-separated categories (task, comment, member, workflow)"
+// @Param type query string false "Comma-separated event types, e.g. task.created,task.status_changed"
+// @Param actor_id query string false "Only events by this user" format(uuid)
+// @Param since query string false "Only events at or after this time (RFC3339)"
+// @Param until query string false "Only events at or before this time (RFC3339)"
+// @Success 200 {object} dto.ActivityFeedResponse "Activity feed"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/feed [get]
+func (h *ActivityHandler) GetProjectFeed(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	viewer, ok := viewerFromContext(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseFeedFilter(c)
+	if !ok {
+		return
+	}
+
+	proj, err := h.projectService.GetProject(c.Request.Context(), projectID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	events, total, err := h.service.ProjectFeed(c.Request.Context(), proj.OrganizationID, proj.ID, viewer, filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": feedResponse(events, total, filter)})
+}
+
+// GetOrganizationFeed godoc
+// @Summary Get organization activity feed
+// @Description Get a paginated timeline of activity across all projects and workflows of an organization
+// @Tags activity
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Number of items per page" default(20)
+// @Param category query string false "Comma-separated categories (task, comment, member, workflow)"
+// @Param type query string false "Comma-separated event types, e.g. task.created,member.added"
+// @Param actor_id query string false "Only events by this user" format(uuid)
+// @Param since query string false "Only events at or after this time (RFC3339)"
+// @Param until query string false "Only events at or before this time (RFC3339)"
+// @Success 200 {object} dto.ActivityFeedResponse "Activity feed"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Organization not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/feed [get]
+func (h *ActivityHandler) GetOrganizationFeed(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return
+	}
+
+	viewer, ok := viewerFromContext(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseFeedFilter(c)
+	if !ok {
+		return
+	}
+
+	events, total, err := h.service.OrganizationFeed(c.Request.Context(), orgID, viewer, filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": feedResponse(events, total, filter)})
+}
+
+// GetTaskActivity godoc
+// @Summary Get task activity
+// @Description Get a paginated history of a task: creation, edits, status changes, assignments and comments
+// @Tags activity
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Number of items per page" default(20)
+// @Param category query string false "Comma-separated categories (task, comment)"
+// @Param type query string false "Comma-separated event types, e.g. task.status_changed,comment.added"
+// @Param actor_id query string false "Only events by this user" format(uuid)
+// @Param since query string false "Only events at or after this time (RFC3339)"
+// @Param until query string false "Only events at or before this time (RFC3339)"
+// @Success 200 {object} dto.ActivityFeedResponse "Activity feed"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/activity [get]
+func (h *ActivityHandler) GetTaskActivity(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	viewer, ok := viewerFromContext(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseFeedFilter(c)
+	if !ok {
+		return
+	}
+
+	tsk, err := h.taskService.GetTask(c.Request.Context(), taskID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	events, total, err := h.service.EntityFeed(c.Request.Context(), tsk.OrganizationID, "task", tsk.ID, viewer, filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": feedResponse(events, total, filter)})
+}
+
+// viewerFromContext builds the feed viewer from the authenticated user's claims
+func viewerFromContext(c *gin.Context) (activity.Viewer, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return activity.Viewer{}, false
+	}
+
+	viewer := activity.Viewer{UserID: userID}
+	if orgID, exists := c.Get("org_id"); exists {
+		viewer.OrganizationID, _ = orgID.(uuid.UUID)
+	}
+	return viewer, true
+}
+
+// parseFeedFilter reads pagination and filter query parameters
+func parseFeedFilter(c *gin.Context) (activity.FeedFilter, bool) {
+	var filter activity.FeedFilter
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return filter, false
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return filter, false
+	}
+	filter.Page = page
+	filter.PageSize = pageSize
+
+	if categories := c.Query("category"); categories != "" {
+		filter.Categories = splitQueryList(categories)
+	}
+	if types := c.Query("type"); types != "" {
+		for _, t := range splitQueryList(types) {
+			filter.Types = append(filter.Types, activity.EventType(t))
+		}
+	}
+	if actor := c.Query("actor_id"); actor != "" {
+		actorID, err := uuid.Parse(actor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor ID"})
+			return filter, false
+		}
+		filter.ActorID = &actorID
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since, expected RFC3339"})
+			return filter, false
+		}
+		filter.Since = &t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until, expected RFC3339"})
+			return filter, false
+		}
+		filter.Until = &t
+	}
+
+	return filter, true
+}
+
+func splitQueryList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func feedResponse(events []activity.Event, total int64, filter activity.FeedFilter) dto.ActivityFeedResponse {
+	filter = filter.Normalize()
+	return dto.ActivityFeedResponse{
+		Events:     dto.ActivityEventsToResponse(events),
+		TotalCount: total,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+	}
+}
+
+// handleError maps activity errors to HTTP responses
+func (h *ActivityHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, project.ErrProjectNotFound), errors.Is(err, task.ErrTaskNotFound),
+		err == organization.ErrOrganizationNotFound:
+		statusCode = http.StatusNotFound
+	case errors.Is(err, activity.ErrNotAuthorized):
+		statusCode = http.StatusForbidden
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
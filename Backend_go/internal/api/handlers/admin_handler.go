@@ -0,0 +1,456 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/migrations"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/scheduler"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/broker"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminHandler exposes operational controls over the cache, scheduler, queues, schema,
+// maintenance mode and rate limits
+type AdminHandler struct {
+	redisClient *cache.RedisClient
+	scheduler   *scheduler.Scheduler
+	queues      broker.QueueAdmin
+	db          *connection.Database
+	maintenance *middleware.MaintenanceMode
+	rateLimits  *middleware.RateLimits
+	logger      *zap.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler instance
+func NewAdminHandler(redisClient *cache.RedisClient, scheduler *scheduler.Scheduler, queues broker.QueueAdmin, db *connection.Database, maintenance *middleware.MaintenanceMode, rateLimits *middleware.RateLimits, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		redisClient: redisClient,
+		scheduler:   scheduler,
+		queues:      queues,
+		db:          db,
+		maintenance: maintenance,
+		rateLimits:  rateLimits,
+		logger:      logger,
+	}
+}
+
+// cachePattern builds the key pattern from the pattern or tag query parameters.
+// A tag is the resource segment of a cache key, e.g. "tasks" for "tasks:*".
+func cachePattern(c *gin.Context) (string, bool) {
+	if pattern := c.Query("pattern"); pattern != "" {
+		return pattern, true
+	}
+	if tag := c.Query("tag"); tag != "" {
+		return tag + ":*", true
+	}
+	return "", false
+}
+
+// ListCacheKeys godoc
+// @Summary List cache keys
+// @Description List cache keys matching a pattern or tag (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param pattern query string false "Key pattern, e.g. tasks:*"
+// @Param tag query string false "Cache tag (resource name), e.g. tasks"
+// @Param limit query int false "Maximum number of keys to return" default(100)
+// @Success 200 {object} map[string]interface{} "Matching cache keys"
+// @Failure 400 {object} map[string]string "Missing pattern or tag"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/cache/keys [get]
+func (h *AdminHandler) ListCacheKeys(c *gin.Context) {
+	pattern, ok := cachePattern(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pattern or tag is required"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	keys, err := h.redisClient.ScanKeys(c.Request.Context(), pattern, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"pattern": pattern,
+		"keys":    keys,
+		"count":   len(keys),
+	}})
+}
+
+// ClearCacheKeys godoc
+// @Summary Clear cache keys
+// @Description Delete cache keys matching a pattern or tag (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param pattern query string false "Key pattern, e.g. tasks:*"
+// @Param tag query string false "Cache tag (resource name), e.g. tasks"
+// @Success 200 {object} map[string]interface{} "Cache cleared"
+// @Failure 400 {object} map[string]string "Missing pattern or tag"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/cache/keys [delete]
+func (h *AdminHandler) ClearCacheKeys(c *gin.Context) {
+	pattern, ok := cachePattern(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pattern or tag is required"})
+		return
+	}
+
+	if err := h.redisClient.ClearByPattern(c.Request.Context(), pattern); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Cache cleared by admin", zap.String("pattern", pattern))
+	c.JSON(http.StatusOK, gin.H{"message": "Cache cleared successfully", "pattern": pattern})
+}
+
+// ListJobRuns godoc
+// @Summary List scheduler job runs
+// @Description Get the most recent scheduler job runs, newest first (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param job query string false "Filter by job name"
+// @Param limit query int false "Maximum number of runs to return" default(50)
+// @Success 200 {object} map[string]interface{} "Job runs"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Router /api/admin/jobs/runs [get]
+func (h *AdminHandler) ListJobRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	runs := h.scheduler.RecentRuns(c.Query("job"), limit)
+	c.JSON(http.StatusOK, gin.H{"data": runs})
+}
+
+// ListQueues godoc
+// @Summary List queues
+// @Description Get the state of every message queue (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Queue stats"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Router /api/admin/queues [get]
+func (h *AdminHandler) ListQueues(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.queues.Stats()})
+}
+
+// PauseQueue godoc
+// @Summary Pause a queue
+// @Description Stop dispatching messages on a queue; new messages are held until resumed (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Queue name"
+// @Success 200 {object} map[string]string "Queue paused"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Queue not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/queues/{name}/pause [post]
+func (h *AdminHandler) PauseQueue(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.queues.PauseTopic(name); err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	h.logger.Info("Queue paused by admin", zap.String("queue", name))
+	c.JSON(http.StatusOK, gin.H{"message": "Queue paused successfully"})
+}
+
+// ResumeQueue godoc
+// @Summary Resume a queue
+// @Description Resume dispatching on a paused queue and deliver held messages (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Queue name"
+// @Success 200 {object} map[string]string "Queue resumed"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Queue not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/queues/{name}/resume [post]
+func (h *AdminHandler) ResumeQueue(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.queues.ResumeTopic(c.Request.Context(), name); err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	h.logger.Info("Queue resumed by admin", zap.String("queue", name))
+	c.JSON(http.StatusOK, gin.H{"message": "Queue resumed successfully"})
+}
+
+// ListDeadLetters godoc
+// @Summary List dead-letter messages
+// @Description Get the messages of a queue whose processing failed (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Queue name"
+// @Success 200 {object} map[string]interface{} "Dead-letter messages"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Queue not found"
+// @Router /api/admin/queues/{name}/dead-letters [get]
+func (h *AdminHandler) ListDeadLetters(c *gin.Context) {
+	letters, err := h.queues.DeadLetters(c.Param("name"))
+	if err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": letters})
+}
+
+// RequeueDeadLetter godoc
+// @Summary Requeue a dead-letter message
+// @Description Remove a failed message from the dead-letter queue and dispatch it again (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Queue name"
+// @Param id path string true "Message ID"
+// @Success 200 {object} map[string]string "Message requeued"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Message not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/queues/{name}/dead-letters/{id}/requeue [post]
+func (h *AdminHandler) RequeueDeadLetter(c *gin.Context) {
+	name := c.Param("name")
+	messageID := c.Param("id")
+	if err := h.queues.RequeueDeadLetter(c.Request.Context(), name, messageID); err != nil {
+		h.queueError(c, err)
+		return
+	}
+
+	h.logger.Info("Dead-letter message requeued by admin",
+		zap.String("queue", name),
+		zap.String("message_id", messageID),
+	)
+	c.JSON(http.StatusOK, gin.H{"message": "Message requeued successfully"})
+}
+
+// GetMigrationStatus godoc
+// @Summary Get database migration status
+// @Description Report pending migrations and schema drift against the models (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Migration status"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/migrations [get]
+func (h *AdminHandler) GetMigrationStatus(c *gin.Context) {
+	status, err := migrations.CheckStatus(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"up_to_date": status.UpToDate(),
+		"pending":    status.Pending,
+		"drift":      status.Drift,
+	}})
+}
+
+// ApplyMigrations godoc
+// @Summary Apply database migrations
+// @Description Apply pending migrations in a controlled way (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Migrations applied"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/migrations/apply [post]
+func (h *AdminHandler) ApplyMigrations(c *gin.Context) {
+	h.logger.Info("Database migrations triggered by admin")
+	if err := migrations.AutoMigrate(h.db, h.logger); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := migrations.GetMigrationHistory(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Migrations applied successfully", "data": history})
+}
+
+// ListReadOnlyModes godoc
+// @Summary List read-only switches
+// @Description List the domains currently rejecting changes; "*" means the whole API (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Read-only switches"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/maintenance [get]
+func (h *AdminHandler) ListReadOnlyModes(c *gin.Context) {
+	switches, err := h.maintenance.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": switches})
+}
+
+// EnableReadOnlyMode godoc
+// @Summary Turn on read-only mode
+// @Description Reject changes to one domain, or to the whole API when no domain is given, with 503 while reads keep working (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ReadOnlyModeRequest true "Read-only switch"
+// @Success 200 {object} map[string]interface{} "Read-only mode on"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/maintenance [put]
+func (h *AdminHandler) EnableReadOnlyMode(c *gin.Context) {
+	var req dto.ReadOnlyModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := middleware.GetUserID(c)
+
+	sw := middleware.ReadOnlySwitch{
+		Domain:    req.Domain,
+		Reason:    req.Reason,
+		EnabledBy: userID,
+		EnabledAt: time.Now().UTC(),
+		Until:     req.Until,
+	}
+	if sw.Domain == "" {
+		sw.Domain = middleware.MaintenanceGlobal
+	}
+	if err := h.maintenance.Enable(c.Request.Context(), sw); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Warn("Read-only mode enabled",
+		zap.String("domain", sw.Domain),
+		zap.String("reason", sw.Reason),
+		zap.String("enabled_by", userID.String()))
+	c.JSON(http.StatusOK, gin.H{"message": "Read-only mode enabled", "data": sw})
+}
+
+// DisableReadOnlyMode godoc
+// @Summary Turn off read-only mode
+// @Description Accept changes to a domain again, or lift the API-wide switch when no domain is given (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param domain query string false "Domain, e.g. tasks; omit for the whole API"
+// @Success 200 {object} map[string]string "Read-only mode off"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/maintenance [delete]
+func (h *AdminHandler) DisableReadOnlyMode(c *gin.Context) {
+	domain := c.Query("domain")
+	if err := h.maintenance.Disable(c.Request.Context(), domain); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Read-only mode disabled", zap.String("domain", domain))
+	c.JSON(http.StatusOK, gin.H{"message": "Read-only mode disabled"})
+}
+
+// GetRateLimits godoc
+// @Summary Inspect rate limit counters
+// @Description Show the token buckets of a principal, "user:<id>", "ip:<address>" or "org:<id>", with what is left in each (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param principal path string true "Principal, e.g. user:6f1c..."
+// @Success 200 {object} map[string]interface{} "Buckets of the principal"
+// @Failure 400 {object} map[string]string "Invalid principal"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/rate-limits/{principal} [get]
+func (h *AdminHandler) GetRateLimits(c *gin.Context) {
+	states, err := h.rateLimits.Inspect(c.Request.Context(), c.Param("principal"))
+	if err != nil {
+		h.rateLimitError(c, err)
+		return
+	}
+
+	buckets := make([]gin.H, len(states))
+	for i, state := range states {
+		buckets[i] = gin.H{
+			"key":                 state.Key,
+			"limit":               state.Limit,
+			"remaining":           state.Remaining,
+			"reset_after_seconds": state.ResetAfter.Seconds(),
+			"retry_after_seconds": state.RetryAfter.Seconds(),
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": buckets})
+}
+
+// ResetRateLimits godoc
+// @Summary Reset rate limit counters
+// @Description Refill all token buckets of a principal, "user:<id>", "ip:<address>" or "org:<id>" (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param principal path string true "Principal, e.g. ip:203.0.113.7"
+// @Success 200 {object} map[string]interface{} "Number of buckets reset"
+// @Failure 400 {object} map[string]string "Invalid principal"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/rate-limits/{principal} [delete]
+func (h *AdminHandler) ResetRateLimits(c *gin.Context) {
+	principal := c.Param("principal")
+	reset, err := h.rateLimits.Reset(c.Request.Context(), principal)
+	if err != nil {
+		h.rateLimitError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	h.logger.Info("Rate limits reset",
+		zap.String("principal", principal),
+		zap.Int64("buckets", reset),
+		zap.String("reset_by", userID.String()))
+	c.JSON(http.StatusOK, gin.H{"message": "Rate limits reset", "data": gin.H{"reset": reset}})
+}
+
+func (h *AdminHandler) rateLimitError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	if errors.Is(err, middleware.ErrInvalidPrincipal) {
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
+
+// queueError maps broker errors to HTTP responses
+func (h *AdminHandler) queueError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	if err == broker.ErrQueueNotFound || err == broker.ErrDeadLetterNotFound {
+		statusCode = http.StatusNotFound
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
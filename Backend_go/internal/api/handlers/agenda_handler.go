@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/agenda"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/gin-gonic/gin"
+)
+
+// AgendaHandler handles HTTP requests for the daily agenda
+type AgendaHandler struct {
+	service agenda.Service
+}
+
+// NewAgendaHandler creates a new AgendaHandler instance
+func NewAgendaHandler(service agenda.Service) *AgendaHandler {
+	return &AgendaHandler{service: service}
+}
+
+// GetAgenda godoc
+// @Summary Get the daily agenda
+// @Description Compose the plan for a day in the user's timezone: calendar events, travel buffers before events with a location, focus blocks for the top-priority open tasks within working hours, and suggested times for habits still to do. Proposed items can be accepted to create calendar blocks.
+// @Tags agenda
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Day as YYYY-MM-DD, defaults to today"
+// @Success 200 {object} agenda.Agenda "Agenda ordered by start time"
+// @Failure 400 {object} map[string]string "Invalid date"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/agenda [get]
+func (h *AgendaHandler) GetAgenda(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	result, err := h.service.GetAgenda(c.Request.Context(), userID, c.Query("date"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// AcceptAgenda godoc
+// @Summary Accept agenda items
+// @Description Create calendar events for proposed focus, habit and travel items of the agenda
+// @Tags agenda
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AcceptAgendaRequest true "Proposed items to accept"
+// @Success 201 {array} agenda.AcceptedBlock "Created calendar blocks"
+// @Failure 400 {object} map[string]string "Invalid items"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/agenda/accept [post]
+func (h *AgendaHandler) AcceptAgenda(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.AcceptAgendaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	blocks, err := h.service.Accept(c.Request.Context(), userID, req.Items)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": blocks})
+}
+
+func (h *AgendaHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, agenda.ErrInvalidDate), errors.Is(err, agenda.ErrNoItems),
+		errors.Is(err, agenda.ErrTooManyItems), errors.Is(err, agenda.ErrInvalidItem):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, user.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
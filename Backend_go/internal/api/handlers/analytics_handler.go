@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/projections"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// defaultStatsDays is how many days of stats are returned when no from date is given
+const defaultStatsDays = 30
+
+// AnalyticsHandler handles HTTP requests for the dashboard read models
+type AnalyticsHandler struct {
+	service projections.Service
+	logger  *zap.Logger
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler instance
+func NewAnalyticsHandler(service projections.Service, logger *zap.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{service: service, logger: logger}
+}
+
+// GetDailyStats godoc
+// @Summary Get the current user's daily activity
+// @Description Get how many tasks and todos the user created and completed, and how many habits they completed, on every UTC day of a range, with totals. The stats are projected from domain events: they are eventually consistent, and as_of tells when the latest change they include was applied.
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day, YYYY-MM-DD (defaults to 29 days before to)"
+// @Param to query string false "Last day, YYYY-MM-DD (defaults to today)"
+// @Success 200 {object} projections.DailyStats "Daily stats"
+// @Failure 400 {object} map[string]string "Invalid date or range"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/analytics/daily [get]
+func (h *AnalyticsHandler) GetDailyStats(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+
+	stats, err := h.service.DailyStats(c.Request.Context(), userID, from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// GetProjectCounters godoc
+// @Summary Get the task counters of a project
+// @Description Get how many tasks of the project are in each status, in total and open (neither completed nor cancelled). The counters are projected from domain events and recounted nightly: they are eventually consistent, and as_of tells when they last changed.
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} projections.ProjectCounters "Project counters"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/analytics/projects/{id} [get]
+func (h *AnalyticsHandler) GetProjectCounters(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	counters, err := h.service.ProjectCounters(c.Request.Context(), orgID, projectID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": counters})
+}
+
+func (h *AnalyticsHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, projections.ErrInvalidRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/announcements"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AnnouncementHandler handles HTTP requests for organization announcements
+type AnnouncementHandler struct {
+	service announcements.Service
+}
+
+// NewAnnouncementHandler creates a new AnnouncementHandler instance
+func NewAnnouncementHandler(service announcements.Service) *AnnouncementHandler {
+	return &AnnouncementHandler{service: service}
+}
+
+// CreateAnnouncement godoc
+// @Summary Post an announcement
+// @Description Broadcast an announcement to an organization. The audience is everyone in the organization ("all"), the members of the listed projects ("projects") or the listed users ("users"). Recipients are notified in-app; important announcements are also pushed and critical ones emailed.
+// @Tags announcements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param announcement body dto.CreateAnnouncementRequest true "Announcement details"
+// @Success 201 {object} dto.AnnouncementResponse "Announcement posted"
+// @Failure 400 {object} map[string]string "Invalid request or empty audience"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Missing the announcements:manage permission"
+// @Failure 404 {object} map[string]string "Organization not found"
+// @Router /api/organizations/{id}/announcements [post]
+func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
+	var req dto.CreateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return
+	}
+
+	announcement, err := h.service.CreateAnnouncement(c.Request.Context(), announcements.CreateAnnouncementInput{
+		OrganizationID: orgID,
+		AuthorID:       userID,
+		Title:          req.Title,
+		Body:           req.Body,
+		Priority:       announcements.Priority(req.Priority),
+		AudienceType:   announcements.AudienceType(req.AudienceType),
+		AudienceIDs:    req.AudienceIDs,
+		ExpiresAt:      req.ExpiresAt,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": dto.AnnouncementToResponse(announcement)})
+}
+
+// ListMyAnnouncements godoc
+// @Summary List my announcements
+// @Description Get the unexpired announcements addressed to the current user, newest first, with their acknowledgment state
+// @Tags announcements
+// @Produce json
+// @Security BearerAuth
+// @Param organization_id query string false "Only announcements of this organization" format(uuid)
+// @Param unacknowledged query bool false "Only announcements not yet acknowledged"
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} dto.AnnouncementListResponse "Announcements"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/announcements [get]
+func (h *AnnouncementHandler) ListMyAnnouncements(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	page, pageSize, ok := h.parsePage(c)
+	if !ok {
+		return
+	}
+
+	filter := announcements.UserFilter{
+		UnacknowledgedOnly: c.Query("unacknowledged") == "true",
+		Page:               page,
+		PageSize:           pageSize,
+	}
+	if raw := c.Query("organization_id"); raw != "" {
+		orgID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+			return
+		}
+		filter.OrganizationID = &orgID
+	}
+
+	list, total, err := h.service.ListForUser(c.Request.Context(), userID, filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.AnnouncementListResponse{
+		Announcements: dto.UserAnnouncementsToResponse(list),
+		TotalCount:    total,
+		Page:          page,
+		PageSize:      pageSize,
+	}})
+}
+
+// ListOrganizationAnnouncements godoc
+// @Summary List an organization's announcements
+// @Description Get every announcement of an organization, including expired ones, with acknowledgment counts. Needs the announcements:manage permission.
+// @Tags announcements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} dto.AnnouncementListResponse "Announcements"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Missing the announcements:manage permission"
+// @Failure 404 {object} map[string]string "Organization not found"
+// @Router /api/organizations/{id}/announcements [get]
+func (h *AnnouncementHandler) ListOrganizationAnnouncements(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return
+	}
+
+	page, pageSize, ok := h.parsePage(c)
+	if !ok {
+		return
+	}
+
+	list, total, err := h.service.ListOrganizationAnnouncements(c.Request.Context(), orgID, page, pageSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.AnnouncementListResponse{
+		Announcements: dto.AnnouncementsWithStatsToResponse(list),
+		TotalCount:    total,
+		Page:          page,
+		PageSize:      pageSize,
+	}})
+}
+
+// Acknowledge godoc
+// @Summary Acknowledge an announcement
+// @Description Mark an announcement as read by the current user. Acknowledging again keeps the first acknowledgment time.
+// @Tags announcements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Announcement ID" format(uuid)
+// @Success 200 {object} dto.AnnouncementResponse "Announcement acknowledged"
+// @Failure 400 {object} map[string]string "Invalid announcement ID"
+// @Failure 404 {object} map[string]string "Announcement not found, expired or not addressed to the user"
+// @Router /api/announcements/{id}/acknowledge [post]
+func (h *AnnouncementHandler) Acknowledge(c *gin.Context) {
+	id, userID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	announcement, err := h.service.Acknowledge(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.UserAnnouncementToResponse(announcement)})
+}
+
+// ListAcknowledgments godoc
+// @Summary List announcement acknowledgments
+// @Description Get every recipient of an announcement and whether they acknowledged it. Needs the announcements:manage permission.
+// @Tags announcements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param announcement_id path string true "Announcement ID" format(uuid)
+// @Success 200 {array} dto.AnnouncementAcknowledgmentResponse "Recipients"
+// @Failure 400 {object} map[string]string "Invalid announcement ID"
+// @Failure 403 {object} map[string]string "Missing the announcements:manage permission"
+// @Failure 404 {object} map[string]string "Announcement not found"
+// @Router /api/organizations/{id}/announcements/{announcement_id}/acknowledgments [get]
+func (h *AnnouncementHandler) ListAcknowledgments(c *gin.Context) {
+	orgID, id, ok := h.parseManaged(c)
+	if !ok {
+		return
+	}
+
+	recipients, err := h.service.ListAcknowledgments(c.Request.Context(), orgID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.AnnouncementAcknowledgmentsToResponse(recipients)})
+}
+
+// DeleteAnnouncement godoc
+// @Summary Delete an announcement
+// @Description Retract an announcement from every recipient
+// @Tags announcements
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param announcement_id path string true "Announcement ID" format(uuid)
+// @Success 204 "Announcement deleted"
+// @Failure 400 {object} map[string]string "Invalid announcement ID"
+// @Failure 403 {object} map[string]string "Missing the announcements:manage permission"
+// @Failure 404 {object} map[string]string "Announcement not found"
+// @Router /api/organizations/{id}/announcements/{announcement_id} [delete]
+func (h *AnnouncementHandler) DeleteAnnouncement(c *gin.Context) {
+	orgID, id, ok := h.parseManaged(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAnnouncement(c.Request.Context(), orgID, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseRequest extracts the announcement ID and the authenticated user
+func (h *AnnouncementHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid announcement ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
+
+// parseManaged extracts the organization and announcement IDs of the management routes
+func (h *AnnouncementHandler) parseManaged(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("announcement_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid announcement ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, id, true
+}
+
+// parsePage reads the page and pageSize query parameters
+func (h *AnnouncementHandler) parsePage(c *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return 0, 0, false
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// handleError maps announcement errors to HTTP responses
+func (h *AnnouncementHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, announcements.ErrAnnouncementNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, announcements.ErrInvalidInput), errors.Is(err, announcements.ErrInvalidAudience),
+		errors.Is(err, announcements.ErrEmptyAudience), errors.Is(err, announcements.ErrAlreadyExpired):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,563 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/attachments"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead is the room left for multipart headers on top of the file size limit
+const multipartOverhead = 1 << 20
+
+// attachmentOwner is the item an attachment request is about, once the caller's access
+// to it has been checked
+type attachmentOwner struct {
+	Type attachments.OwnerType
+	ID   uuid.UUID
+	// ItemOwnerID may delete any attachment of the item besides its uploader
+	ItemOwnerID    uuid.UUID
+	OrganizationID *uuid.UUID
+	// Path is the URL path of the item's attachments, which preview URLs are built on
+	Path string
+}
+
+func taskAttachmentsPath(taskID uuid.UUID) string {
+	return fmt.Sprintf("/api/tasks/%s/attachments", taskID)
+}
+
+func commentAttachmentsPath(taskID, commentID uuid.UUID) string {
+	return fmt.Sprintf("/api/tasks/%s/comments/%s/attachments", taskID, commentID)
+}
+
+func todoAttachmentsPath(todoID uuid.UUID) string {
+	return fmt.Sprintf("/api/todos/%s/attachments", todoID)
+}
+
+// UploadTaskAttachment godoc
+// @Summary Attach a file to a task
+// @Description Upload a file as multipart form field "file". The type is detected from the content and must be allowed.
+// @Tags tasks
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param file formData file true "File to attach"
+// @Success 201 {object} dto.AttachmentResponse "Attachment created"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 413 {object} map[string]string "File too large"
+// @Failure 415 {object} map[string]string "File type not allowed"
+// @Router /api/tasks/{id}/attachments [post]
+func (h *TaskHandler) UploadTaskAttachment(c *gin.Context) {
+	if owner, ok := h.taskAttachmentOwner(c); ok {
+		uploadAttachment(c, h.attachments, owner)
+	}
+}
+
+// ListTaskAttachments godoc
+// @Summary List the attachments of a task
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {array} dto.AttachmentResponse "Attachments, oldest first"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Router /api/tasks/{id}/attachments [get]
+func (h *TaskHandler) ListTaskAttachments(c *gin.Context) {
+	if owner, ok := h.taskAttachmentOwner(c); ok {
+		listAttachments(c, h.attachments, owner)
+	}
+}
+
+// DownloadTaskAttachment godoc
+// @Summary Download an attachment of a task
+// @Tags tasks
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Success 200 {file} file "The attached file"
+// @Failure 403 {object} map[string]string "File blocked as infected"
+// @Failure 404 {object} map[string]string "Task or attachment not found"
+// @Failure 409 {object} map[string]string "File quarantined until scanned"
+// @Router /api/tasks/{id}/attachments/{attachment_id} [get]
+func (h *TaskHandler) DownloadTaskAttachment(c *gin.Context) {
+	if owner, ok := h.taskAttachmentOwner(c); ok {
+		downloadAttachment(c, h.attachments, owner)
+	}
+}
+
+// DownloadTaskAttachmentPreview godoc
+// @Summary Get a rendered preview of a task attachment
+// @Description Images and PDFs get a PNG thumbnail and a larger preview once they passed malware scanning; the attachment's thumbnail_url and preview_url point here when they are ready.
+// @Tags tasks
+// @Produce png
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Param variant path string true "thumbnail or preview"
+// @Success 200 {file} file "PNG image"
+// @Failure 404 {object} map[string]string "Task, attachment or preview not found"
+// @Router /api/tasks/{id}/attachments/{attachment_id}/{variant} [get]
+func (h *TaskHandler) DownloadTaskAttachmentPreview(c *gin.Context) {
+	if owner, ok := h.taskAttachmentOwner(c); ok {
+		downloadAttachmentPreview(c, h.attachments, owner)
+	}
+}
+
+// DeleteTaskAttachment godoc
+// @Summary Delete an attachment of a task
+// @Description Only the uploader and the task creator can delete an attachment.
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Success 204 "Attachment deleted"
+// @Failure 403 {object} map[string]string "Not allowed"
+// @Failure 404 {object} map[string]string "Task or attachment not found"
+// @Router /api/tasks/{id}/attachments/{attachment_id} [delete]
+func (h *TaskHandler) DeleteTaskAttachment(c *gin.Context) {
+	if owner, ok := h.taskAttachmentOwner(c); ok {
+		deleteAttachment(c, h.attachments, owner)
+	}
+}
+
+// UploadCommentAttachment godoc
+// @Summary Attach a file to a task comment
+// @Description Upload a file as multipart form field "file". Only the comment's author can attach files to it.
+// @Tags tasks
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param comment_id path string true "Comment ID" format(uuid)
+// @Param file formData file true "File to attach"
+// @Success 201 {object} dto.AttachmentResponse "Attachment created"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Not the comment's author"
+// @Failure 404 {object} map[string]string "Task or comment not found"
+// @Failure 413 {object} map[string]string "File too large"
+// @Failure 415 {object} map[string]string "File type not allowed"
+// @Router /api/tasks/{id}/comments/{comment_id}/attachments [post]
+func (h *TaskHandler) UploadCommentAttachment(c *gin.Context) {
+	owner, comment, ok := h.commentAttachmentOwner(c)
+	if !ok {
+		return
+	}
+	if userID, _ := middleware.GetUserID(c); userID != comment.AuthorID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the comment's author can attach files to it"})
+		return
+	}
+	uploadAttachment(c, h.attachments, owner)
+}
+
+// ListCommentAttachments godoc
+// @Summary List the attachments of a task comment
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param comment_id path string true "Comment ID" format(uuid)
+// @Success 200 {array} dto.AttachmentResponse "Attachments, oldest first"
+// @Failure 404 {object} map[string]string "Task or comment not found"
+// @Router /api/tasks/{id}/comments/{comment_id}/attachments [get]
+func (h *TaskHandler) ListCommentAttachments(c *gin.Context) {
+	if owner, _, ok := h.commentAttachmentOwner(c); ok {
+		listAttachments(c, h.attachments, owner)
+	}
+}
+
+// DownloadCommentAttachment godoc
+// @Summary Download an attachment of a task comment
+// @Tags tasks
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param comment_id path string true "Comment ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Success 200 {file} file "The attached file"
+// @Failure 403 {object} map[string]string "File blocked as infected"
+// @Failure 404 {object} map[string]string "Task, comment or attachment not found"
+// @Failure 409 {object} map[string]string "File quarantined until scanned"
+// @Router /api/tasks/{id}/comments/{comment_id}/attachments/{attachment_id} [get]
+func (h *TaskHandler) DownloadCommentAttachment(c *gin.Context) {
+	if owner, _, ok := h.commentAttachmentOwner(c); ok {
+		downloadAttachment(c, h.attachments, owner)
+	}
+}
+
+// DownloadCommentAttachmentPreview godoc
+// @Summary Get a rendered preview of a task comment attachment
+// @Tags tasks
+// @Produce png
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param comment_id path string true "Comment ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Param variant path string true "thumbnail or preview"
+// @Success 200 {file} file "PNG image"
+// @Failure 404 {object} map[string]string "Task, comment, attachment or preview not found"
+// @Router /api/tasks/{id}/comments/{comment_id}/attachments/{attachment_id}/{variant} [get]
+func (h *TaskHandler) DownloadCommentAttachmentPreview(c *gin.Context) {
+	if owner, _, ok := h.commentAttachmentOwner(c); ok {
+		downloadAttachmentPreview(c, h.attachments, owner)
+	}
+}
+
+// DeleteCommentAttachment godoc
+// @Summary Delete an attachment of a task comment
+// @Description Only the uploader and the task creator can delete an attachment, as with comments.
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param comment_id path string true "Comment ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Success 204 "Attachment deleted"
+// @Failure 403 {object} map[string]string "Not allowed"
+// @Failure 404 {object} map[string]string "Task, comment or attachment not found"
+// @Router /api/tasks/{id}/comments/{comment_id}/attachments/{attachment_id} [delete]
+func (h *TaskHandler) DeleteCommentAttachment(c *gin.Context) {
+	if owner, _, ok := h.commentAttachmentOwner(c); ok {
+		deleteAttachment(c, h.attachments, owner)
+	}
+}
+
+// taskAttachmentOwner resolves the task of the request. Organization scoping is left
+// to RequireTaskInOrganization.
+func (h *TaskHandler) taskAttachmentOwner(c *gin.Context) (attachmentOwner, bool) {
+	if !attachmentsConfigured(c, h.attachments) {
+		return attachmentOwner{}, false
+	}
+	tsk, ok := h.attachmentTask(c)
+	if !ok {
+		return attachmentOwner{}, false
+	}
+	return attachmentOwner{
+		Type:           attachments.OwnerTask,
+		ID:             tsk.ID,
+		ItemOwnerID:    tsk.CreatorID,
+		OrganizationID: &tsk.OrganizationID,
+		Path:           taskAttachmentsPath(tsk.ID),
+	}, true
+}
+
+// commentAttachmentOwner resolves the comment of the request, which must be on its task
+func (h *TaskHandler) commentAttachmentOwner(c *gin.Context) (attachmentOwner, *task.TaskComment, bool) {
+	if !attachmentsConfigured(c, h.attachments) {
+		return attachmentOwner{}, nil, false
+	}
+	tsk, ok := h.attachmentTask(c)
+	if !ok {
+		return attachmentOwner{}, nil, false
+	}
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return attachmentOwner{}, nil, false
+	}
+	comment, err := h.service.GetComment(c.Request.Context(), tsk.ID, commentID)
+	if err != nil {
+		h.handleCommentError(c, err)
+		return attachmentOwner{}, nil, false
+	}
+	return attachmentOwner{
+		Type:           attachments.OwnerComment,
+		ID:             comment.ID,
+		ItemOwnerID:    tsk.CreatorID,
+		OrganizationID: &tsk.OrganizationID,
+		Path:           commentAttachmentsPath(tsk.ID, comment.ID),
+	}, comment, true
+}
+
+func (h *TaskHandler) attachmentTask(c *gin.Context) (*task.Task, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return nil, false
+	}
+	tsk, err := h.service.GetTask(c.Request.Context(), id)
+	if err != nil {
+		statuscode := http.StatusInternalServerError
+		if err == task.ErrTaskNotFound {
+			statuscode = http.StatusNotFound
+		}
+		c.JSON(statuscode, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return tsk, true
+}
+
+// UploadTodoAttachment godoc
+// @Summary Attach a file to a todo
+// @Description Upload a file as multipart form field "file". The type is detected from the content and must be allowed.
+// @Tags todos
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID" format(uuid)
+// @Param file formData file true "File to attach"
+// @Success 201 {object} dto.AttachmentResponse "Attachment created"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 413 {object} map[string]string "File too large"
+// @Failure 415 {object} map[string]string "File type not allowed"
+// @Router /api/todos/{id}/attachments [post]
+func (h *TodoHandler) UploadTodoAttachment(c *gin.Context) {
+	if owner, ok := h.todoAttachmentOwner(c); ok {
+		uploadAttachment(c, h.attachments, owner)
+	}
+}
+
+// ListTodoAttachments godoc
+// @Summary List the attachments of a todo
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID" format(uuid)
+// @Success 200 {array} dto.AttachmentResponse "Attachments, oldest first"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Router /api/todos/{id}/attachments [get]
+func (h *TodoHandler) ListTodoAttachments(c *gin.Context) {
+	if owner, ok := h.todoAttachmentOwner(c); ok {
+		listAttachments(c, h.attachments, owner)
+	}
+}
+
+// DownloadTodoAttachment godoc
+// @Summary Download an attachment of a todo
+// @Tags todos
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Todo ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Success 200 {file} file "The attached file"
+// @Failure 403 {object} map[string]string "File blocked as infected"
+// @Failure 404 {object} map[string]string "Todo or attachment not found"
+// @Failure 409 {object} map[string]string "File quarantined until scanned"
+// @Router /api/todos/{id}/attachments/{attachment_id} [get]
+func (h *TodoHandler) DownloadTodoAttachment(c *gin.Context) {
+	if owner, ok := h.todoAttachmentOwner(c); ok {
+		downloadAttachment(c, h.attachments, owner)
+	}
+}
+
+// DownloadTodoAttachmentPreview godoc
+// @Summary Get a rendered preview of a todo attachment
+// @Tags todos
+// @Produce png
+// @Security BearerAuth
+// @Param id path string true "Todo ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Param variant path string true "thumbnail or preview"
+// @Success 200 {file} file "PNG image"
+// @Failure 404 {object} map[string]string "Todo, attachment or preview not found"
+// @Router /api/todos/{id}/attachments/{attachment_id}/{variant} [get]
+func (h *TodoHandler) DownloadTodoAttachmentPreview(c *gin.Context) {
+	if owner, ok := h.todoAttachmentOwner(c); ok {
+		downloadAttachmentPreview(c, h.attachments, owner)
+	}
+}
+
+// DeleteTodoAttachment godoc
+// @Summary Delete an attachment of a todo
+// @Tags todos
+// @Security BearerAuth
+// @Param id path string true "Todo ID" format(uuid)
+// @Param attachment_id path string true "Attachment ID" format(uuid)
+// @Success 204 "Attachment deleted"
+// @Failure 404 {object} map[string]string "Todo or attachment not found"
+// @Router /api/todos/{id}/attachments/{attachment_id} [delete]
+func (h *TodoHandler) DeleteTodoAttachment(c *gin.Context) {
+	if owner, ok := h.todoAttachmentOwner(c); ok {
+		deleteAttachment(c, h.attachments, owner)
+	}
+}
+
+// todoAttachmentOwner resolves the todo of the request. Todos are private, so those of
+// other users are reported as not found.
+func (h *TodoHandler) todoAttachmentOwner(c *gin.Context) (attachmentOwner, bool) {
+	if !attachmentsConfigured(c, h.attachments) {
+		return attachmentOwner{}, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid todo ID"})
+		return attachmentOwner{}, false
+	}
+	todo, err := h.service.GetTodo(c.Request.Context(), id)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err == todos.ErrTodoNotFound {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return attachmentOwner{}, false
+	}
+	if userID, _ := middleware.GetUserID(c); todo.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": todos.ErrTodoNotFound.Error()})
+		return attachmentOwner{}, false
+	}
+	return attachmentOwner{Type: attachments.OwnerTodo, ID: todo.ID, ItemOwnerID: todo.UserID, Path: todoAttachmentsPath(todo.ID)}, true
+}
+
+func attachmentsConfigured(c *gin.Context, service attachments.Service) bool {
+	if service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "attachments are not available"})
+		return false
+	}
+	return true
+}
+
+func uploadAttachment(c *gin.Context, service attachments.Service, owner attachmentOwner) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxSize()+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": attachments.ErrFileTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file is required in the \"file\" form field"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read the uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := service.Upload(c.Request.Context(), attachments.UploadInput{
+		OwnerType:      owner.Type,
+		OwnerID:        owner.ID,
+		OrganizationID: owner.OrganizationID,
+		UploadedBy:     userID,
+		FileName:       header.Filename,
+		Size:           header.Size,
+		Content:        file,
+	})
+	if err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": AttachmentsToResponse([]attachments.Attachment{*attachment}, owner.Path)[0]})
+}
+
+func listAttachments(c *gin.Context, service attachments.Service, owner attachmentOwner) {
+	list, err := service.List(c.Request.Context(), owner.Type, owner.ID)
+	if err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": AttachmentsToResponse(list, owner.Path)})
+}
+
+// downloadAttachment always serves the file as a download with its sniffed type, so
+// uploaded content is never rendered inline by the browser
+func downloadAttachment(c *gin.Context, service attachments.Service, owner attachmentOwner) {
+	id, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+	attachment, err := service.Get(c.Request.Context(), owner.Type, owner.ID, id)
+	if err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+	content, err := service.Open(c.Request.Context(), attachment)
+	if err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+// downloadAttachmentPreview serves a rendered variant. Previews are PNGs generated by the
+// server, so unlike uploads they may be shown inline.
+func downloadAttachmentPreview(c *gin.Context, service attachments.Service, owner attachmentOwner) {
+	id, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+	variant := attachments.PreviewVariant(c.Param("variant"))
+	if variant != attachments.VariantThumbnail && variant != attachments.VariantPreview {
+		c.JSON(http.StatusNotFound, gin.H{"error": "variant must be thumbnail or preview"})
+		return
+	}
+	attachment, err := service.Get(c.Request.Context(), owner.Type, owner.ID, id)
+	if err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+	content, err := service.OpenPreview(c.Request.Context(), attachment, variant)
+	if err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "image/png", content, map[string]string{
+		"Content-Disposition":    "inline",
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=3600",
+	})
+}
+
+func deleteAttachment(c *gin.Context, service attachments.Service, owner attachmentOwner) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+	if err := service.Delete(c.Request.Context(), owner.Type, owner.ID, id, userID, owner.ItemOwnerID); err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func handleAttachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, attachments.ErrAttachmentNotFound), errors.Is(err, attachments.ErrNoPreview):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, attachments.ErrNotAllowed), errors.Is(err, attachments.ErrInfected):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, attachments.ErrQuarantined):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, attachments.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, attachments.ErrTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, attachments.ErrEmptyFile), errors.Is(err, attachments.ErrInvalidFileName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process attachment"})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AuditHandler handles HTTP requests for organization audit logs
+type AuditHandler struct {
+	service audit.Service
+	logger  *zap.Logger
+}
+
+// NewAuditHandler creates a new AuditHandler instance
+func NewAuditHandler(service audit.Service, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{service: service, logger: logger}
+}
+
+// ListAuditLog godoc
+// @Summary List the audit log
+// @Description List who did what in the organization, newest first: sign-ins, role changes, task deletions and workflow executions. With format=csv every matching entry, up to 100000, is exported as CSV instead and paging is ignored.
+// @Tags organizations
+// @Produce json,text/csv
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Number of items per page" default(20)
+// @Param actor_id query string false "Only entries by this user" format(uuid)
+// @Param action query string false "Comma-separated actions, e.g. user.login,task.deleted"
+// @Param target_type query string false "Only entries about this kind of target, e.g. task"
+// @Param target_id query string false "Only entries about this target" format(uuid)
+// @Param since query string false "Only entries at or after this time (RFC3339)"
+// @Param until query string false "Only entries before this time (RFC3339)"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} audit.Entry "Audit entries"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/audit [get]
+func (h *AuditHandler) ListAuditLog(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-%s.csv"`, orgID))
+		c.Status(http.StatusOK)
+		if err := h.service.ExportCSV(c.Request.Context(), orgID, filter, c.Writer); err != nil {
+			// The header is already sent, so the client only sees a truncated file
+			h.logger.Error("Failed to export audit log", zap.String("organization_id", orgID.String()), zap.Error(err))
+		}
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	entries, total, err := h.service.List(c.Request.Context(), orgID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries, "total": total})
+}
+
+// auditFilter reads the audit log filter from the query, responding with 400 when it
+// is invalid
+func auditFilter(c *gin.Context) (audit.Filter, bool) {
+	var filter audit.Filter
+	var err error
+	if filter.Page, err = strconv.Atoi(c.DefaultQuery("page", "0")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return filter, false
+	}
+	if filter.PageSize, err = strconv.Atoi(c.DefaultQuery("pageSize", "20")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return filter, false
+	}
+	if raw := c.Query("actor_id"); raw != "" {
+		actorID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor ID"})
+			return filter, false
+		}
+		filter.ActorID = &actorID
+	}
+	if raw := c.Query("target_id"); raw != "" {
+		targetID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target ID"})
+			return filter, false
+		}
+		filter.TargetID = &targetID
+	}
+	for _, action := range strings.Split(c.Query("action"), ",") {
+		if action = strings.TrimSpace(action); action != "" {
+			filter.Actions = append(filter.Actions, audit.Action(action))
+		}
+	}
+	filter.TargetType = c.Query("target_type")
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since, expected RFC3339"})
+			return filter, false
+		}
+		filter.Since = &t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until, expected RFC3339"})
+			return filter, false
+		}
+		filter.Until = &t
+	}
+	return filter, true
+}
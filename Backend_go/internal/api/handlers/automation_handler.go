@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/automation"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AutomationHandler handles HTTP requests from automation platforms such as Zapier
+type AutomationHandler struct {
+	service automation.Service
+}
+
+// NewAutomationHandler creates a new AutomationHandler instance
+func NewAutomationHandler(service automation.Service) *AutomationHandler {
+	return &AutomationHandler{service: service}
+}
+
+// GetCatalog godoc
+// @Summary Get the automation catalog
+// @Description Machine-readable list of polling triggers and actions, so automation platforms can integrate without custom code. Actions are regular API endpoints described with their input fields.
+// @Tags automation
+// @Produce json
+// @Success 200 {object} automation.Catalog "Trigger and action catalog"
+// @Router /api/automation/catalog [get]
+func (h *AutomationHandler) GetCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.service.Catalog()})
+}
+
+// PollTrigger godoc
+// @Summary Poll a trigger
+// @Description Get records created or updated after a cursor, oldest first. Pass next_cursor from the previous poll as cursor; without a cursor or since, polling starts from the first record.
+// @Tags automation
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Trigger key, such as task.created or todo.updated"
+// @Param cursor query string false "next_cursor from the previous poll"
+// @Param since query string false "Start after this time (RFC 3339) when no cursor is given"
+// @Param limit query int false "Maximum number of records" default(50)
+// @Success 200 {object} automation.PollResult "Records and the cursor to continue from"
+// @Failure 400 {object} map[string]string "Invalid cursor or limit"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Unknown trigger"
+// @Router /api/automation/triggers/{key} [get]
+func (h *AutomationHandler) PollTrigger(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	cursor := c.Query("cursor")
+	if raw := c.Query("since"); raw != "" && cursor == "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since time"})
+			return
+		}
+		cursor = automation.StartCursor(since)
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	// Members see what their role lets them search for in the organization
+	var orgID *uuid.UUID
+	var access search.Access
+	if membership, ok := middleware.GetOrganizationMembership(c); ok {
+		orgID = &membership.OrganizationID
+		access = searchAccess(membership)
+	}
+
+	result, err := h.service.Poll(c.Request.Context(), c.Param("key"), cursor, limit, userID, orgID, access)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, automation.ErrUnknownTrigger):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, automation.ErrInvalidCursor), errors.Is(err, automation.ErrInvalidLimit):
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result.Items, "next_cursor": result.NextCursor, "has_more": result.HasMore})
+}
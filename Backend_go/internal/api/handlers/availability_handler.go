@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultAvailabilityRange is the range shown when the request does not set an end
+	defaultAvailabilityRange = 7 * 24 * time.Hour
+	// defaultMeetingMinutes and defaultMeetingSuggestions shape the suggested slots when
+	// the request does not
+	defaultMeetingMinutes     = 30
+	defaultMeetingSuggestions = 5
+)
+
+// AvailabilityHandler handles HTTP requests for teammates' availability
+type AvailabilityHandler struct {
+	calendarService     calendar.Service
+	organizationService organization.Service
+	userService         user.Service
+}
+
+// NewAvailabilityHandler creates a new AvailabilityHandler instance. Users' working hours
+// limit the meeting slots suggested.
+func NewAvailabilityHandler(calendarService calendar.Service, organizationService organization.Service, userService user.Service) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		calendarService:     calendarService,
+		organizationService: organizationService,
+		userService:         userService,
+	}
+}
+
+// GetTeamAvailability godoc
+// @Summary Get teammates' availability
+// @Description Get the free and busy time, out-of-office periods and working locations of members of the organization, without event details, with recurring events expanded. Also returns the time the members are all free and suggests meeting slots in it during everyone's working hours: the hours set in their preferences, or 09:00 to 17:00 on weekdays in their timezone.
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param users query string false "Comma-separated member IDs, defaults to every member; user_ids is also accepted"
+// @Param start query string false "Range start (RFC3339), defaults to now"
+// @Param end query string false "Range end (RFC3339), defaults to 7 days after start; at most 31 days"
+// @Param duration query int false "Meeting length in minutes, 5 to 480" default(30)
+// @Param limit query int false "Most slots to suggest, up to 20" default(5)
+// @Success 200 {object} calendar.MeetingTimes "Availability per member, common free time and suggested slots"
+// @Failure 400 {object} map[string]string "Invalid range, duration or user IDs"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not a member of the organization"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/calendar/availability [get]
+func (h *AvailabilityHandler) GetTeamAvailability(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return
+	}
+
+	start := time.Now().UTC()
+	if raw := c.Query("start"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start time"})
+			return
+		}
+		start = parsed
+	}
+	end := start.Add(defaultAvailabilityRange)
+	if raw := c.Query("end"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end time"})
+			return
+		}
+		end = parsed
+	}
+
+	// Checked here as well as by the calendar, since working hours are laid out over the range first
+	if !end.After(start) || end.Sub(start) > calendar.MaxAvailabilityRange {
+		c.JSON(http.StatusBadRequest, gin.H{"error": calendar.ErrInvalidAvailabilityRange.Error()})
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	isMember := make(map[uuid.UUID]bool, len(members))
+	var userIDs []uuid.UUID
+	for _, m := range members {
+		isMember[m.UserID] = true
+		userIDs = append(userIDs, m.UserID)
+	}
+	raw := c.Query("users")
+	if raw == "" {
+		raw = c.Query("user_ids")
+	}
+	if raw != "" {
+		userIDs = nil
+		for _, part := range strings.Split(raw, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(part))
+			if err != nil || !isMember[id] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "not a member of the organization: " + part})
+				return
+			}
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	query := calendar.MeetingQuery{
+		UserIDs:      userIDs,
+		Start:        start,
+		End:          end,
+		Duration:     defaultMeetingMinutes * time.Minute,
+		Limit:        defaultMeetingSuggestions,
+		WorkingHours: make(map[uuid.UUID][]calendar.TimeSpan, len(userIDs)),
+	}
+	if raw := c.Query("duration"); raw != "" {
+		minutes, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid duration"})
+			return
+		}
+		query.Duration = time.Duration(minutes) * time.Minute
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > calendar.MaxMeetingSuggestions {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 20"})
+			return
+		}
+		query.Limit = limit
+	}
+	for _, userID := range userIDs {
+		u, err := h.userService.GetUser(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		hours := []calendar.TimeSpan{}
+		for _, period := range user.WorkingPeriods(u, start, end) {
+			hours = append(hours, calendar.TimeSpan{Start: period.Start, End: period.End})
+		}
+		query.WorkingHours[userID] = hours
+	}
+
+	times, err := h.calendarService.FindMeetingTimes(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, calendar.ErrInvalidAvailabilityRange) || errors.Is(err, calendar.ErrInvalidMeetingDuration) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": times})
+}
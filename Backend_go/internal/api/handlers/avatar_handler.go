@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/avatars"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AvatarHandler handles avatar uploads and serves the stored images
+type AvatarHandler struct {
+	service avatars.Service
+}
+
+// NewAvatarHandler creates a new AvatarHandler instance
+func NewAvatarHandler(service avatars.Service) *AvatarHandler {
+	return &AvatarHandler{service: service}
+}
+
+// UploadAvatar godoc
+// @Summary Upload an avatar
+// @Description Upload a PNG, JPEG or GIF image of up to 5 MB in the "file" form field. It is cropped to a centered square, resized to 32, 64, 128 and 256 pixels, and the caller's avatar_url is pointed at the 256 pixel image. The previous uploaded avatar is removed.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Image"
+// @Success 200 {object} avatars.Avatar "Avatar URLs by size"
+// @Failure 400 {object} map[string]string "Missing, empty or oversized image"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 413 {object} map[string]string "File too large"
+// @Failure 415 {object} map[string]string "Not a PNG, JPEG or GIF image"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/users/profile/avatar [post]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, avatars.MaxUploadSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": avatars.ErrFileTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "an image is required in the \"file\" form field"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read the uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	avatar, err := h.service.Upload(c.Request.Context(), userID, file, header.Size)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": avatar})
+}
+
+// GetAvatar godoc
+// @Summary Get an avatar image
+// @Description Serve one size of an uploaded avatar. Avatar URLs change with every upload, so the images are cached indefinitely.
+// @Tags users
+// @Produce png
+// @Param user_id path string true "User ID" format(uuid)
+// @Param file path string true "Image file named in the avatar URL"
+// @Success 200 {file} binary "PNG image"
+// @Failure 404 {object} map[string]string "Avatar not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/avatars/{user_id}/{file} [get]
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": avatars.ErrNotFound.Error()})
+		return
+	}
+
+	content, err := h.service.Open(c.Request.Context(), userID, c.Param("file"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "image/png", content, map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "public, max-age=31536000, immutable",
+	})
+}
+
+func (h *AvatarHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, avatars.ErrNotFound), errors.Is(err, user.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, avatars.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, avatars.ErrNotAnImage):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, avatars.ErrEmptyFile), errors.Is(err, avatars.ErrImageTooBig):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process avatar"})
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/badges"
+	"github.com/gin-gonic/gin"
+)
+
+// BadgeHandler handles requests for the current user's badge counts
+type BadgeHandler struct {
+	service badges.Service
+}
+
+// NewBadgeHandler creates a new BadgeHandler instance
+func NewBadgeHandler(service badges.Service) *BadgeHandler {
+	return &BadgeHandler{service: service}
+}
+
+// GetCounts godoc
+// @Summary Get badge counts
+// @Description Count the caller's overdue tasks, unread notifications, pending approvals and todos due today. Counts are cached for up to 30 seconds. For 15 minutes after a fetch, changes are also pushed over the /ws stream as badges.counts_changed events carrying the new counts and the delta.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} badges.Snapshot "Badge counts and when they were computed"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/counts [get]
+func (h *BadgeHandler) GetCounts(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	snapshot, err := h.service.Get(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count badges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": snapshot})
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/baselines"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BaselineHandler handles HTTP requests for project schedule baselines
+type BaselineHandler struct {
+	service baselines.Service
+}
+
+// NewBaselineHandler creates a new BaselineHandler instance
+func NewBaselineHandler(service baselines.Service) *BaselineHandler {
+	return &BaselineHandler{service: service}
+}
+
+// CreateBaseline godoc
+// @Summary Snapshot the project schedule
+// @Description Save the start and due dates of every task of the project that is not cancelled as a baseline to compare later schedules against
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param request body dto.CreateBaselineRequest true "Baseline name"
+// @Success 201 {object} baselines.Baseline "Created baseline with its tasks"
+// @Failure 400 {object} map[string]string "Invalid name, too many baselines, or no tasks"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/baselines [post]
+func (h *BaselineHandler) CreateBaseline(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	orgID, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateBaselineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	baseline, err := h.service.CreateBaseline(c.Request.Context(), projectID, orgID, userID, baselines.CreateBaselineInput{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": baseline})
+}
+
+// ListBaselines godoc
+// @Summary List the baselines of a project
+// @Description List the schedule snapshots of the project, newest first, without their tasks
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {array} baselines.Baseline "Baselines"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/baselines [get]
+func (h *BaselineHandler) ListBaselines(c *gin.Context) {
+	_, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.ListBaselines(c.Request.Context(), projectID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetBaseline godoc
+// @Summary Get a baseline
+// @Description Get a schedule snapshot of the project with the dates of its tasks
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param baseline_id path string true "Baseline ID" format(uuid)
+// @Success 200 {object} baselines.Baseline "Baseline with its tasks"
+// @Failure 400 {object} map[string]string "Invalid project or baseline ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project or baseline not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/baselines/{baseline_id} [get]
+func (h *BaselineHandler) GetBaseline(c *gin.Context) {
+	_, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+	baselineID, ok := h.parseBaseline(c)
+	if !ok {
+		return
+	}
+
+	baseline, err := h.service.GetBaseline(c.Request.Context(), projectID, baselineID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": baseline})
+}
+
+// CompareBaseline godoc
+// @Summary Compare the schedule with a baseline
+// @Description Get the slippage of each task since the baseline, in days, with the drift of the project end date. Tasks scheduled since the baseline are listed as added; tasks deleted or cancelled since are listed as removed.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param baseline_id path string true "Baseline ID" format(uuid)
+// @Success 200 {object} baselines.Comparison "Schedule changes since the baseline"
+// @Failure 400 {object} map[string]string "Invalid project or baseline ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project or baseline not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/baselines/{baseline_id}/diff [get]
+func (h *BaselineHandler) CompareBaseline(c *gin.Context) {
+	orgID, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+	baselineID, ok := h.parseBaseline(c)
+	if !ok {
+		return
+	}
+
+	comparison, err := h.service.CompareBaseline(c.Request.Context(), projectID, orgID, baselineID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comparison})
+}
+
+// DeleteBaseline godoc
+// @Summary Delete a baseline
+// @Description Delete a schedule snapshot of the project
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param baseline_id path string true "Baseline ID" format(uuid)
+// @Success 204 "Baseline deleted"
+// @Failure 400 {object} map[string]string "Invalid project or baseline ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project or baseline not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/baselines/{baseline_id} [delete]
+func (h *BaselineHandler) DeleteBaseline(c *gin.Context) {
+	_, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+	baselineID, ok := h.parseBaseline(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBaseline(c.Request.Context(), projectID, baselineID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseProject reads the organization context and the project ID, answering the
+// request when either is missing
+func (h *BaselineHandler) parseProject(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, projectID, true
+}
+
+func (h *BaselineHandler) parseBaseline(c *gin.Context) (uuid.UUID, bool) {
+	baselineID, err := uuid.Parse(c.Param("baseline_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid baseline ID"})
+		return uuid.Nil, false
+	}
+	return baselineID, true
+}
+
+func (h *BaselineHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, baselines.ErrInvalidBaseline), errors.Is(err, baselines.ErrTooManyBaselines),
+		errors.Is(err, baselines.ErrNothingToBaseline):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, baselines.ErrBaselineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
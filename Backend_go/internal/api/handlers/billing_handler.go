@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/gin-gonic/gin"
+)
+
+// maxBillingWebhookBytes bounds the size of payment provider webhook deliveries
+const maxBillingWebhookBytes = 1 << 20
+
+// BillingHandler handles HTTP requests for organization plans and subscriptions
+type BillingHandler struct {
+	service billing.Service
+}
+
+// NewBillingHandler creates a new BillingHandler instance
+func NewBillingHandler(service billing.Service) *BillingHandler {
+	return &BillingHandler{service: service}
+}
+
+// ListPlans godoc
+// @Summary List plans
+// @Description List the subscription plans with their features and limits
+// @Tags billing
+// @Produce json
+// @Success 200 {array} billing.Plan "Plans"
+// @Router /api/billing/plans [get]
+func (h *BillingHandler) ListPlans(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.service.ListPlans(), "enabled": h.service.Enabled()})
+}
+
+// GetSubscription godoc
+// @Summary Get the organization's subscription
+// @Description Get the plan that applies to the organization, its subscription status and its usage of each plan limit
+// @Tags billing
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID" format(uuid)
+// @Success 200 {object} billing.Summary "Subscription"
+// @Failure 400 {object} map[string]string "Organization context missing"
+// @Failure 403 {object} map[string]string "Not a member of the organization"
+// @Router /api/billing/subscription [get]
+func (h *BillingHandler) GetSubscription(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	summary, err := h.service.GetSummary(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// CreatePortalSession godoc
+// @Summary Open the billing portal
+// @Description Create a customer portal session where organization admins change plan, update payment details and download invoices. The billing account is created on first use.
+// @Tags billing
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID" format(uuid)
+// @Success 200 {object} map[string]interface{} "Portal URL to redirect to"
+// @Failure 400 {object} map[string]string "Organization context missing"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 503 {object} map[string]string "Billing is not enabled"
+// @Router /api/billing/portal [post]
+func (h *BillingHandler) CreatePortalSession(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	url, err := h.service.CreatePortalSession(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"url": url}})
+}
+
+// HandleStripeWebhook godoc
+// @Summary Receive a Stripe webhook
+// @Description Endpoint for Stripe subscription and checkout events. Deliveries are verified with the webhook signing secret and applied once.
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Success 204 "Event accepted"
+// @Failure 400 {object} map[string]string "Malformed delivery"
+// @Failure 401 {object} map[string]string "Invalid signature"
+// @Failure 503 {object} map[string]string "Billing is not enabled"
+// @Router /api/billing/webhooks/stripe [post]
+func (h *BillingHandler) HandleStripeWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBillingWebhookBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.HandleWebhook(c.Request.Context(), c.Request.Header, body); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleError maps billing errors to HTTP responses
+func (h *BillingHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, billing.ErrBillingDisabled):
+		statusCode = http.StatusServiceUnavailable
+	case errors.Is(err, billing.ErrInvalidSignature):
+		statusCode = http.StatusUnauthorized
+	case errors.Is(err, billing.ErrInvalidPayload):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, organization.ErrOrganizationNotFound):
+		statusCode = http.StatusNotFound
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -50,11 +51,15 @@ func (h *CalendarHandler) CreateEvent(c *gin.Context) {
 
 	event, err := h.service.CreateEvent(c.Request.Context(), req, userID)
 	if err != nil {
+		if errors.Is(err, calendar.ErrInvalidTimezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, calendar.CalendarEventResponse{Event: *event})
+	c.JSON(http.StatusCreated, h.eventResponse(c, userID, event))
 }
 
 // ListEvents godoc
@@ -69,6 +74,8 @@ func (h *CalendarHandler) CreateEvent(c *gin.Context) {
 // @Param event_type query string false "Event type filter"
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Page size (default: 10)"
+// @Param cursor query string false "next_cursor from the previous page; with cursor or limit, events are paged by start time and total is not counted"
+// @Param limit query int false "Number of events per cursor page (default: 20, max: 100)"
 // @Param search query string false "Search term"
 // @Success 200 {object} calendar.CalendarEventListResponse "List of events"
 // @Failure 400 {object} map[string]string "Invalid request"
@@ -88,6 +95,10 @@ func (h *CalendarHandler) ListEvents(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	keyset, ok := parseKeyset(c)
+	if !ok {
+		return
+	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, exists := middleware.GetUserID(c)
@@ -96,19 +107,27 @@ func (h *CalendarHandler) ListEvents(c *gin.Context) {
 		return
 	}
 
-	response, err := h.service.ListEvents(
-		c.Request.Context(),
-		userID,
-		params.StartTime,
-		params.EndTime,
-		params.EventType,
-		params.Page,
-		params.PageSize,
-	)
+	var response *calendar.CalendarEventListResponse
+	var err error
+	if keyset != nil {
+		response, err = h.service.ListEventsAfter(c.Request.Context(), userID,
+			params.StartTime, params.EndTime, params.EventType, keyset)
+	} else {
+		response, err = h.service.ListEvents(
+			c.Request.Context(),
+			userID,
+			params.StartTime,
+			params.EndTime,
+			params.EventType,
+			params.Page,
+			params.PageSize,
+		)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.service.LocalizeEvents(c.Request.Context(), userID, response.Events)
 
 	c.JSON(http.StatusOK, response)
 }
@@ -140,7 +159,8 @@ func (h *CalendarHandler) GetEvent(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, calendar.CalendarEventResponse{Event: *event})
+	userID, _ := middleware.GetUserID(c)
+	c.JSON(http.StatusOK, h.eventResponse(c, userID, event))
 }
 
 // UpdateEvent godoc
@@ -173,11 +193,68 @@ func (h *CalendarHandler) UpdateEvent(c *gin.Context) {
 
 	event, err := h.service.UpdateEvent(c.Request.Context(), id, req)
 	if err != nil {
+		if errors.Is(err, calendar.ErrInvalidTimezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, calendar.CalendarEventResponse{Event: *event})
+	userID, _ := middleware.GetUserID(c)
+	c.JSON(http.StatusOK, h.eventResponse(c, userID, event))
+}
+
+// eventResponse shows an event in the timezone preference of the requesting user
+func (h *CalendarHandler) eventResponse(c *gin.Context, userID uuid.UUID, event *calendar.CalendarEvent) calendar.CalendarEventResponse {
+	events := []calendar.CalendarEvent{*event}
+	h.service.LocalizeEvents(c.Request.Context(), userID, events)
+	return calendar.CalendarEventResponse{Event: events[0]}
+}
+
+// DuplicateEvent godoc
+// @Summary Duplicate a calendar event
+// @Description Copy an event to the same time next week or on another date, keeping its length and reminders. The recurrence rule is not copied; attendees are invited again when copy_attendees is set. Only the organizer can duplicate an event.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID" format(uuid)
+// @Param duplicate body dto.DuplicateEventRequest true "Where to put the copy"
+// @Success 201 {object} calendar.CalendarEventResponse "Event duplicated"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not the organizer"
+// @Failure 404 {object} map[string]string "Event not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/calendar/events/{id}/duplicate [post]
+func (h *CalendarHandler) DuplicateEvent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+	var req dto.DuplicateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	event, err := h.service.DuplicateEvent(c.Request.Context(), id, userID, calendar.DuplicateOptions{
+		Offset:        req.Offset,
+		Date:          req.Date,
+		CopyAttendees: req.CopyAttendees,
+	})
+	if err != nil {
+		h.handleAttendeeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, h.eventResponse(c, userID, event))
 }
 
 // DeleteEvent godoc
@@ -431,6 +508,7 @@ func (h *CalendarHandler) ListEventsSharedWithMe(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.service.LocalizeEvents(c.Request.Context(), userID, events)
 	c.JSON(http.StatusOK, calendar.CalendarEventListResponse{Events: events, Total: int64(len(events))})
 }
 
@@ -478,3 +556,214 @@ func (h *CalendarHandler) UpdateOccurrenceById(c *gin.Context) {
 
 	c.Status(http.StatusOK)
 }
+
+// InviteAttendees godoc
+// @Summary Invite attendees to an event
+// @Description Invite users by ID or external guests by email. Only the organizer can invite; people already invited are skipped.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID" format(uuid)
+// @Param invite body dto.InviteAttendeesRequest true "Attendees to invite"
+// @Success 201 {object} dto.ListAttendeesResponse "Attendees invited"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string "Not the organizer"
+// @Failure 404 {object} map[string]string "Event not found"
+// @Failure 500 {object} map[string]string
+// @Router /api/calendar/events/{id}/attendees [post]
+func (h *CalendarHandler) InviteAttendees(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+	var req dto.InviteAttendeesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	invites := make([]calendar.AttendeeInvite, 0, len(req.Attendees))
+	for _, a := range req.Attendees {
+		invites = append(invites, calendar.AttendeeInvite{UserID: a.UserID, Email: a.Email, Name: a.Name})
+	}
+	attendees, err := h.service.InviteAttendees(c.Request.Context(), eventID, userID, invites)
+	if err != nil {
+		h.handleAttendeeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ListAttendeesResponse{Attendees: attendees})
+}
+
+// ListAttendees godoc
+// @Summary List attendees of an event
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID" format(uuid)
+// @Success 200 {object} dto.ListAttendeesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string "Event not found"
+// @Failure 500 {object} map[string]string
+// @Router /api/calendar/events/{id}/attendees [get]
+func (h *CalendarHandler) ListAttendees(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+	attendees, err := h.service.ListAttendees(c.Request.Context(), eventID)
+	if err != nil {
+		h.handleAttendeeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.ListAttendeesResponse{Attendees: attendees})
+}
+
+// RespondToAttendance godoc
+// @Summary RSVP to an event
+// @Description Accept, decline or tentatively accept the current user's invitation to an event
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID" format(uuid)
+// @Param rsvp body dto.RSVPRequest true "Answer to the invitation"
+// @Success 200 {object} calendar.EventAttendee
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string "Not invited"
+// @Failure 500 {object} map[string]string
+// @Router /api/calendar/events/{id}/rsvp [post]
+func (h *CalendarHandler) RespondToAttendance(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+	var req dto.RSVPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	attendee, err := h.service.RespondToAttendance(c.Request.Context(), eventID, userID,
+		calendar.RSVP{Status: req.Status, Comment: req.Comment})
+	if err != nil {
+		h.handleAttendeeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, attendee)
+}
+
+// UpdateAttendeeStatus godoc
+// @Summary Record an attendee's RSVP
+// @Description Record the answer of an attendee. The organizer can record answers for external guests; users can only answer for themselves.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID" format(uuid)
+// @Param attendee_id path string true "Attendee ID" format(uuid)
+// @Param rsvp body dto.RSVPRequest true "Answer to the invitation"
+// @Success 200 {object} calendar.EventAttendee
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string "Attendee not found"
+// @Failure 500 {object} map[string]string
+// @Router /api/calendar/events/{id}/attendees/{attendee_id} [patch]
+func (h *CalendarHandler) UpdateAttendeeStatus(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+	attendeeID, err := uuid.Parse(c.Param("attendee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attendee ID"})
+		return
+	}
+	var req dto.RSVPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	attendee, err := h.service.UpdateAttendeeStatus(c.Request.Context(), eventID, attendeeID, userID,
+		calendar.RSVP{Status: req.Status, Comment: req.Comment})
+	if err != nil {
+		h.handleAttendeeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, attendee)
+}
+
+// RemoveAttendee godoc
+// @Summary Remove an attendee from an event
+// @Description The organizer can remove any attendee; users can remove themselves
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID" format(uuid)
+// @Param attendee_id path string true "Attendee ID" format(uuid)
+// @Success 204 "Attendee removed"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string "Attendee not found"
+// @Failure 500 {object} map[string]string
+// @Router /api/calendar/events/{id}/attendees/{attendee_id} [delete]
+func (h *CalendarHandler) RemoveAttendee(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+	attendeeID, err := uuid.Parse(c.Param("attendee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attendee ID"})
+		return
+	}
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.service.RemoveAttendee(c.Request.Context(), eventID, attendeeID, userID); err != nil {
+		h.handleAttendeeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// handleAttendeeError maps attendee errors to HTTP responses
+func (h *CalendarHandler) handleAttendeeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, calendar.ErrEventNotFound), errors.Is(err, calendar.ErrAttendeeNotFound),
+		errors.Is(err, calendar.ErrNotInvited):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, calendar.ErrNotEventOrganizer), errors.Is(err, calendar.ErrAttendeeForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.As(err, new(*calendar.Error)):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/chat"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxCommandBytes bounds the size of slash command requests
+const maxCommandBytes = 64 << 10
+
+// ChatHandler handles HTTP requests for the Slack and Teams integrations
+type ChatHandler struct {
+	service chat.Service
+}
+
+// NewChatHandler creates a new ChatHandler instance
+func NewChatHandler(service chat.Service) *ChatHandler {
+	return &ChatHandler{service: service}
+}
+
+// ListEvents godoc
+// @Summary List chat events
+// @Description Get the event types that can be posted to chat channels. "*" subscribes to all of them.
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Supported event types"
+// @Router /api/integrations/chat/events [get]
+func (h *ChatHandler) ListEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": chat.SupportedEvents})
+}
+
+// StartInstall godoc
+// @Summary Start a chat app install
+// @Description Get the Slack or Teams page where an organization admin installs the Compass app
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param provider path string true "slack or teams"
+// @Success 200 {object} dto.ChatInstallResponse "Install URL"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Missing the chat:manage permission"
+// @Failure 503 {object} map[string]string "Provider is not configured"
+// @Router /api/organizations/{id}/chat/{provider}/install [get]
+func (h *ChatHandler) StartInstall(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return
+	}
+
+	url, err := h.service.StartInstall(c.Request.Context(), chat.ProviderName(c.Param("provider")), orgID, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.ChatInstallResponse{URL: url}})
+}
+
+// CompleteInstall godoc
+// @Summary Complete a chat app install
+// @Description OAuth redirect target of Slack and Teams. Stores the installation for the organization that started the install.
+// @Tags chat
+// @Produce json
+// @Param provider path string true "slack or teams"
+// @Param state query string true "Install state"
+// @Success 201 {object} chat.Installation "Installation"
+// @Failure 400 {object} map[string]string "Invalid or expired state"
+// @Router /api/integrations/chat/{provider}/callback [get]
+func (h *ChatHandler) CompleteInstall(c *gin.Context) {
+	installation, err := h.service.CompleteInstall(c.Request.Context(), chat.ProviderName(c.Param("provider")), c.Request.URL.Query())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": installation})
+}
+
+// HandleCommand godoc
+// @Summary Receive a chat command
+// @Description Endpoint for Slack slash commands and Teams outgoing webhooks. Requests are verified with the provider signature and run as the linked Compass user.
+// @Tags chat
+// @Accept x-www-form-urlencoded,json
+// @Produce json
+// @Param provider path string true "slack or teams"
+// @Success 200 {object} map[string]interface{} "Provider response"
+// @Failure 400 {object} map[string]string "Malformed command"
+// @Failure 401 {object} map[string]string "Invalid signature"
+// @Failure 404 {object} map[string]string "Workspace is not installed"
+// @Router /api/integrations/chat/{provider}/commands [post]
+func (h *ChatHandler) HandleCommand(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCommandBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.HandleCommand(c.Request.Context(), chat.ProviderName(c.Param("provider")), c.Request.Header, body)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListInstallations godoc
+// @Summary List chat installations
+// @Description List the Slack workspaces and Teams tenants connected to an organization
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {array} chat.Installation "Installations"
+// @Failure 400 {object} map[string]string "Invalid organization ID"
+// @Failure 403 {object} map[string]string "Missing the chat:manage permission"
+// @Router /api/organizations/{id}/chat/installations [get]
+func (h *ChatHandler) ListInstallations(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return
+	}
+
+	installations, err := h.service.ListInstallations(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": installations})
+}
+
+// UpdateInstallation godoc
+// @Summary Update a chat installation
+// @Description Set the project slash commands create tasks in by default, or the security token of a Teams outgoing webhook
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param installation_id path string true "Installation ID" format(uuid)
+// @Param installation body dto.UpdateChatInstallationRequest true "Installation settings"
+// @Success 200 {object} chat.Installation "Installation"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Missing the chat:manage permission"
+// @Failure 404 {object} map[string]string "Installation not found"
+// @Router /api/organizations/{id}/chat/installations/{installation_id} [patch]
+func (h *ChatHandler) UpdateInstallation(c *gin.Context) {
+	orgID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateChatInstallationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	installation, err := h.service.UpdateInstallation(c.Request.Context(), orgID, id, chat.UpdateInstallationInput{
+		DefaultProjectID: req.DefaultProjectID,
+		SigningSecret:    req.SigningSecret,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": installation})
+}
+
+// DeleteInstallation godoc
+// @Summary Remove a chat installation
+// @Description Disconnect a Slack workspace or Teams tenant together with its channels and linked accounts
+// @Tags chat
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param installation_id path string true "Installation ID" format(uuid)
+// @Success 204 "Installation removed"
+// @Failure 403 {object} map[string]string "Missing the chat:manage permission"
+// @Failure 404 {object} map[string]string "Installation not found"
+// @Router /api/organizations/{id}/chat/installations/{installation_id} [delete]
+func (h *ChatHandler) DeleteInstallation(c *gin.Context) {
+	orgID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteInstallation(c.Request.Context(), orgID, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListChannels godoc
+// @Summary List chat channels
+// @Description List the channels an installation posts events to
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param installation_id path string true "Installation ID" format(uuid)
+// @Success 200 {array} chat.Channel "Channels"
+// @Failure 403 {object} map[string]string "Missing the chat:manage permission"
+// @Failure 404 {object} map[string]string "Installation not found"
+// @Router /api/organizations/{id}/chat/installations/{installation_id}/channels [get]
+func (h *ChatHandler) ListChannels(c *gin.Context) {
+	orgID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	channels, err := h.service.ListChannels(c.Request.Context(), orgID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": channels})
+}
+
+// AddChannel godoc
+// @Summary Post events to a chat channel
+// @Description Subscribe a channel to task and workflow events, optionally limited to one project
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param installation_id path string true "Installation ID" format(uuid)
+// @Param channel body dto.AddChatChannelRequest true "Channel details"
+// @Success 201 {object} chat.Channel "Channel"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Missing the chat:manage permission"
+// @Failure 404 {object} map[string]string "Installation not found"
+// @Router /api/organizations/{id}/chat/installations/{installation_id}/channels [post]
+func (h *ChatHandler) AddChannel(c *gin.Context) {
+	orgID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddChatChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel, err := h.service.AddChannel(c.Request.Context(), orgID, id, chat.AddChannelInput{
+		ChannelID:   req.ChannelID,
+		ChannelName: req.ChannelName,
+		Events:      req.Events,
+		ProjectID:   req.ProjectID,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": channel})
+}
+
+// RemoveChannel godoc
+// @Summary Stop posting to a chat channel
+// @Description Remove a channel subscription from an installation
+// @Tags chat
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param installation_id path string true "Installation ID" format(uuid)
+// @Param channel_id path string true "Channel subscription ID" format(uuid)
+// @Success 204 "Channel removed"
+// @Failure 403 {object} map[string]string "Missing the chat:manage permission"
+// @Failure 404 {object} map[string]string "Channel not found"
+// @Router /api/organizations/{id}/chat/installations/{installation_id}/channels/{channel_id} [delete]
+func (h *ChatHandler) RemoveChannel(c *gin.Context) {
+	orgID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+	channelID, err := uuid.Parse(c.Param("channel_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid channel ID"})
+		return
+	}
+
+	if err := h.service.RemoveChannel(c.Request.Context(), orgID, id, channelID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LinkUser godoc
+// @Summary Link a chat account
+// @Description Confirm the token from the chat "link" command so that slash commands run as the caller. The caller must be a member of the organization that installed the app.
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param link body dto.LinkChatUserRequest true "Link token"
+// @Success 200 {object} chat.UserLink "Linked account"
+// @Failure 400 {object} map[string]string "Invalid or expired token"
+// @Failure 403 {object} map[string]string "Not a member of the organization"
+// @Router /api/integrations/chat/link [post]
+func (h *ChatHandler) LinkUser(c *gin.Context) {
+	var req dto.LinkChatUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	link, err := h.service.LinkUser(c.Request.Context(), req.Token, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": link})
+}
+
+// parseRequest extracts the organization and installation IDs
+func (h *ChatHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("installation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid installation ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, id, true
+}
+
+// handleError maps chat integration errors to HTTP responses
+func (h *ChatHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, chat.ErrInstallationNotFound), errors.Is(err, chat.ErrChannelNotFound),
+		errors.Is(err, chat.ErrProviderNotSupported):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, chat.ErrInvalidSignature):
+		statusCode = http.StatusUnauthorized
+	case errors.Is(err, organization.ErrNotMember):
+		statusCode = http.StatusForbidden
+	case errors.Is(err, chat.ErrProviderDisabled):
+		statusCode = http.StatusServiceUnavailable
+	case errors.Is(err, chat.ErrInvalidInput), errors.Is(err, chat.ErrInvalidEvent),
+		errors.Is(err, chat.ErrInvalidState), errors.Is(err, chat.ErrInvalidLinkToken):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/commands"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CommandHandler handles HTTP requests for the command palette
+type CommandHandler struct {
+	service commands.Service
+}
+
+// NewCommandHandler creates a new CommandHandler instance
+func NewCommandHandler(service commands.Service) *CommandHandler {
+	return &CommandHandler{service: service}
+}
+
+// ListCommands godoc
+// @Summary List available commands
+// @Description Get the command palette actions the current user is allowed to run
+// @Tags commands
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} commands.Command "Available commands"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/commands [get]
+func (h *CommandHandler) ListCommands(c *gin.Context) {
+	if _, exists := middleware.GetUserID(c); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.service.ListCommands(permissionsFromContext(c))})
+}
+
+// ExecuteCommand godoc
+// @Summary Run a command
+// @Description Resolve a command palette action and its arguments to the matching service call
+// @Tags commands
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ExecuteCommandRequest true "Command and arguments"
+// @Success 200 {object} dto.CommandResultResponse "Command result"
+// @Failure 400 {object} map[string]string "Invalid request or arguments"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Unknown command or target not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/commands [post]
+func (h *CommandHandler) ExecuteCommand(c *gin.Context) {
+	var req dto.ExecuteCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	orgID, _ := c.Get("org_id")
+	organizationID, _ := orgID.(uuid.UUID)
+
+	result, err := h.service.Execute(c.Request.Context(), commands.ExecuteInput{
+		Command:        req.Command,
+		Args:           req.Args,
+		UserID:         userID,
+		OrganizationID: organizationID,
+		Permissions:    permissionsFromContext(c),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.CommandResultResponse{
+		Command:  result.Command,
+		Data:     commandDataToResponse(result.Data),
+		Redirect: result.Redirect,
+	}})
+}
+
+// permissionsFromContext returns the permissions granted by the caller's token
+func permissionsFromContext(c *gin.Context) []string {
+	value, exists := c.Get("permissions")
+	if !exists {
+		return nil
+	}
+	permissions, _ := value.([]string)
+	return permissions
+}
+
+// commandDataToResponse converts domain objects returned by commands to their response DTOs
+func commandDataToResponse(data interface{}) interface{} {
+	switch v := data.(type) {
+	case *task.Task:
+		return TaskToResponse(v)
+	case *todos.Todo:
+		return TodoToResponse(v)
+	case *project.Project:
+		return dto.ProjectToResponse(v)
+	default:
+		return v
+	}
+}
+
+// handleError maps command errors to HTTP responses
+func (h *CommandHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, commands.ErrUnknownCommand), errors.Is(err, project.ErrProjectNotFound),
+		errors.Is(err, todos.ErrTodoNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, commands.ErrForbidden):
+		statusCode = http.StatusForbidden
+	case errors.Is(err, commands.ErrInvalidArguments), errors.Is(err, task.ErrInvalidInput),
+		errors.Is(err, todos.ErrInvalidInput), errors.Is(err, project.ErrInvalidInput):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/devices"
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceHandler handles HTTP requests for the current user's registered devices
+type DeviceHandler struct {
+	service devices.Service
+}
+
+// NewDeviceHandler creates a new DeviceHandler instance
+func NewDeviceHandler(service devices.Service) *DeviceHandler {
+	return &DeviceHandler{service: service}
+}
+
+// RegisterDevice godoc
+// @Summary Register a device
+// @Description Register a client installation of the current user with its capabilities, or refresh a registration. Devices must register with location_enabled to fetch geofences and report crossings.
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RegisterDeviceRequest true "Device and capabilities"
+// @Success 200 {object} devices.Device "Registered device"
+// @Failure 400 {object} map[string]string "Invalid device"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/devices [post]
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	device, err := h.service.Register(c.Request.Context(), userID, devices.RegisterInput{
+		DeviceID:        req.DeviceID,
+		Platform:        devices.Platform(req.Platform),
+		Name:            req.Name,
+		LocationEnabled: req.LocationEnabled,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": device})
+}
+
+// ListDevices godoc
+// @Summary List registered devices
+// @Description List the current user's devices, most recently seen first
+// @Tags devices
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} devices.Device "Registered devices"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/devices [get]
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	list, err := h.service.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// UnregisterDevice godoc
+// @Summary Unregister a device
+// @Description Remove a registered device of the current user
+// @Tags devices
+// @Produce json
+// @Security BearerAuth
+// @Param device_id path string true "Client device ID"
+// @Success 204 "Device removed"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Device not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/devices/{device_id} [delete]
+func (h *DeviceHandler) UnregisterDevice(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	if err := h.service.Unregister(c.Request.Context(), userID, c.Param("device_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *DeviceHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, devices.ErrInvalidDevice):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, devices.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
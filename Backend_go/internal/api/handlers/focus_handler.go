@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/focus"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FocusHandler handles focus sessions and their statistics
+type FocusHandler struct {
+	service focus.Service
+}
+
+// NewFocusHandler creates a new FocusHandler instance
+func NewFocusHandler(service focus.Service) *FocusHandler {
+	return &FocusHandler{service: service}
+}
+
+// StartFocusSession godoc
+// @Summary Start a focus session
+// @Description Start a timed focus session, 25 minutes unless planned otherwise, optionally on a task created by or assigned to the caller or on one of their habits. Only one session can run at a time.
+// @Tags focus
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.StartFocusSessionRequest false "Planned minutes, linked item and note"
+// @Success 201 {object} focus.Session "Running session"
+// @Failure 400 {object} map[string]string "Invalid session"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Task not created by or assigned to the caller"
+// @Failure 404 {object} map[string]string "Linked task or habit not found"
+// @Failure 409 {object} map[string]string "A session is already running"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/sessions [post]
+func (h *FocusHandler) StartFocusSession(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.StartFocusSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.service.Start(c.Request.Context(), userID, focus.StartInput{
+		PlannedMinutes: req.PlannedMinutes,
+		TaskID:         req.TaskID,
+		HabitID:        req.HabitID,
+		Note:           req.Note,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": session})
+}
+
+// GetCurrentFocusSession godoc
+// @Summary Get the caller's running focus session
+// @Description Data is null when no session is running.
+// @Tags focus
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} focus.Session "Running session, or null"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/sessions/current [get]
+func (h *FocusHandler) GetCurrentFocusSession(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	session, err := h.service.Current(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": session})
+}
+
+// ListFocusSessions godoc
+// @Summary List the caller's focus sessions
+// @Description List the sessions the caller started between two dates in their timezone, oldest first. Defaults to the last 7 days.
+// @Tags focus
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD), today by default"
+// @Success 200 {array} focus.Session "Focus sessions"
+// @Failure 400 {object} map[string]string "Invalid date range"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/sessions [get]
+func (h *FocusHandler) ListFocusSessions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	sessions, err := h.service.List(c.Request.Context(), userID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// GetFocusSession godoc
+// @Summary Get a focus session
+// @Description Get one of the caller's sessions with the interruptions recorded in it.
+// @Tags focus
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID" format(uuid)
+// @Success 200 {object} focus.Session "Focus session"
+// @Failure 400 {object} map[string]string "Invalid session ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Session not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/sessions/{id} [get]
+func (h *FocusHandler) GetFocusSession(c *gin.Context) {
+	userID, sessionID, ok := h.parseSession(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.service.Get(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": session})
+}
+
+// StopFocusSession godoc
+// @Summary Stop a focus session
+// @Description End the caller's running session. Sessions that ran their planned length are completed, shorter ones abandoned. Sessions count for 8 hours at most.
+// @Tags focus
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID" format(uuid)
+// @Success 200 {object} focus.Session "Ended session"
+// @Failure 400 {object} map[string]string "Invalid session ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Session not found"
+// @Failure 409 {object} map[string]string "Session already ended"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/sessions/{id}/stop [post]
+func (h *FocusHandler) StopFocusSession(c *gin.Context) {
+	userID, sessionID, ok := h.parseSession(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.service.Stop(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": session})
+}
+
+// RecordFocusInterruption godoc
+// @Summary Record an interruption
+// @Description Record that the caller's running session was interrupted, by themselves (internal, the default) or by someone or something else (external).
+// @Tags focus
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID" format(uuid)
+// @Param request body dto.FocusInterruptionRequest false "Kind and note"
+// @Success 201 {object} focus.Interruption "Recorded interruption"
+// @Failure 400 {object} map[string]string "Invalid interruption"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Session not found"
+// @Failure 409 {object} map[string]string "Session already ended or too many interruptions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/sessions/{id}/interruptions [post]
+func (h *FocusHandler) RecordFocusInterruption(c *gin.Context) {
+	userID, sessionID, ok := h.parseSession(c)
+	if !ok {
+		return
+	}
+
+	var req dto.FocusInterruptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interruption, err := h.service.Interrupt(c.Request.Context(), userID, sessionID, focus.InterruptionKind(req.Kind), req.Note)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": interruption})
+}
+
+// DeleteFocusSession godoc
+// @Summary Delete a focus session
+// @Description Delete one of the caller's sessions and its interruptions. Deleting a running session discards it.
+// @Tags focus
+// @Security BearerAuth
+// @Param id path string true "Session ID" format(uuid)
+// @Success 204 "Session deleted"
+// @Failure 400 {object} map[string]string "Invalid session ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Session not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/sessions/{id} [delete]
+func (h *FocusHandler) DeleteFocusSession(c *gin.Context) {
+	userID, sessionID, ok := h.parseSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, sessionID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDailyFocusStats godoc
+// @Summary Get daily focus statistics
+// @Description Get the caller's sessions, completions, abandons, focused minutes and interruptions for each day between two dates in their timezone, with totals. Defaults to the last 7 days.
+// @Tags focus
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD), today by default"
+// @Success 200 {object} focus.Stats "Daily statistics"
+// @Failure 400 {object} map[string]string "Invalid date range"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/stats/daily [get]
+func (h *FocusHandler) GetDailyFocusStats(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	stats, err := h.service.DailyStats(c.Request.Context(), userID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// GetWeeklyFocusStats godoc
+// @Summary Get weekly focus statistics
+// @Description Get the caller's focus for each week, Monday to Sunday in their timezone, over the last weeks including this one, with totals.
+// @Tags focus
+// @Produce json
+// @Security BearerAuth
+// @Param weeks query int false "Number of weeks, 1 to 52" default(12)
+// @Success 200 {object} focus.Stats "Weekly statistics"
+// @Failure 400 {object} map[string]string "Invalid number of weeks"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/stats/weekly [get]
+func (h *FocusHandler) GetWeeklyFocusStats(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	weeks := focus.DefaultStatsWeeks
+	if value := c.Query("weeks"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid number of weeks"})
+			return
+		}
+		weeks = parsed
+	}
+
+	stats, err := h.service.WeeklyStats(c.Request.Context(), userID, weeks)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// GetFocusHeatmap godoc
+// @Summary Get focus heatmap data
+// @Description Get the minutes the caller focused on each day of the period, in their timezone, for visualization as a heatmap. Days without focus are left out.
+// @Tags focus
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "Time period for heatmap data (week, month, year)" Enums(week, month, year) default(year)
+// @Success 200 {object} dto.FocusHeatmapResponse "Heatmap data retrieved successfully"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/focus/heatmap [get]
+func (h *FocusHandler) GetFocusHeatmap(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	period := c.DefaultQuery("period", "year")
+	if period != "week" && period != "month" && period != "year" {
+		period = "year"
+	}
+
+	heatmapData, err := h.service.Heatmap(c.Request.Context(), userID, period)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	maxValue := 0
+	for _, value := range heatmapData {
+		if value > maxValue {
+			maxValue = value
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.FocusHeatmapResponse{
+		Data:     heatmapData,
+		Period:   period,
+		MinValue: 0,
+		MaxValue: maxValue,
+	}})
+}
+
+// parseSession reads the caller and the session ID, answering the request when either is missing
+func (h *FocusHandler) parseSession(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, sessionID, true
+}
+
+func (h *FocusHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, focus.ErrSessionNotFound), errors.Is(err, focus.ErrLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, focus.ErrSessionActive), errors.Is(err, focus.ErrSessionEnded), errors.Is(err, focus.ErrTooManyInterrupts):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, focus.ErrTaskForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, focus.ErrInvalidSession), errors.Is(err, focus.ErrInvalidLink),
+		errors.Is(err, focus.ErrInvalidKind), errors.Is(err, focus.ErrInvalidRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process focus session request"})
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GeofenceHandler handles HTTP requests for location-based todo reminders
+type GeofenceHandler struct {
+	service todos.GeofenceService
+}
+
+// NewGeofenceHandler creates a new GeofenceHandler instance
+func NewGeofenceHandler(service todos.GeofenceService) *GeofenceHandler {
+	return &GeofenceHandler{service: service}
+}
+
+// GetTodoGeofence godoc
+// @Summary Get the geofence of a todo
+// @Description Get the location that fires the todo's reminder
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID" format(uuid)
+// @Success 200 {object} todos.Geofence "Todo geofence"
+// @Failure 400 {object} map[string]string "Invalid todo ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Todo or geofence not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/todos/{id}/geofence [get]
+func (h *GeofenceHandler) GetTodoGeofence(c *gin.Context) {
+	userID, todoID, ok := h.parseTodoRequest(c)
+	if !ok {
+		return
+	}
+
+	geofence, err := h.service.GetGeofence(c.Request.Context(), userID, todoID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": geofence})
+}
+
+// SetTodoGeofence godoc
+// @Summary Set the geofence of a todo
+// @Description Add a location to the todo's reminder, or move it. The reminder fires when a registered device reports entering or leaving the area, as set by trigger, at most once an hour.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID" format(uuid)
+// @Param request body dto.SetTodoGeofenceRequest true "Geofence"
+// @Success 200 {object} todos.Geofence "Saved geofence"
+// @Failure 400 {object} map[string]string "Invalid geofence"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/todos/{id}/geofence [put]
+func (h *GeofenceHandler) SetTodoGeofence(c *gin.Context) {
+	userID, todoID, ok := h.parseTodoRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SetTodoGeofenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	geofence, err := h.service.SetGeofence(c.Request.Context(), userID, todoID, todos.GeofenceInput{
+		Latitude:     *req.Latitude,
+		Longitude:    *req.Longitude,
+		RadiusMeters: req.RadiusMeters,
+		Trigger:      todos.GeofenceTrigger(req.Trigger),
+		Label:        req.Label,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": geofence})
+}
+
+// DeleteTodoGeofence godoc
+// @Summary Remove the geofence of a todo
+// @Description Stop the todo's reminder from firing by location
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID" format(uuid)
+// @Success 204 "Geofence removed"
+// @Failure 400 {object} map[string]string "Invalid todo ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Todo or geofence not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/todos/{id}/geofence [delete]
+func (h *GeofenceHandler) DeleteTodoGeofence(c *gin.Context) {
+	userID, todoID, ok := h.parseTodoRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteGeofence(c.Request.Context(), userID, todoID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetActiveGeofences godoc
+// @Summary Get the geofences to monitor
+// @Description Get the geofences of the user's open todos for a mobile client to monitor, at most 20. With lat and lng they are the nearest ones, with their distance. The device must be registered with location enabled.
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param device_id query string true "Client device ID"
+// @Param lat query number false "Client latitude"
+// @Param lng query number false "Client longitude"
+// @Success 200 {array} todos.ActiveGeofence "Geofences to monitor"
+// @Failure 400 {object} map[string]string "Invalid position"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Device not registered with location enabled"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/todos/geofences/active [get]
+func (h *GeofenceHandler) GetActiveGeofences(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var near *todos.Position
+	if c.Query("lat") != "" || c.Query("lng") != "" {
+		lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+		if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must be a valid position"})
+			return
+		}
+		near = &todos.Position{Latitude: lat, Longitude: lng}
+	}
+
+	fences, err := h.service.ActiveGeofences(c.Request.Context(), userID, c.Query("device_id"), near)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": fences})
+}
+
+// ReportGeofenceEvent godoc
+// @Summary Report a geofence crossing
+// @Description Report that a device entered or left a geofence. The todo's reminder fires when the crossing matches the geofence trigger, the todo is open and the reminder did not fire within the last hour.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.GeofenceEventRequest true "Geofence crossing"
+// @Success 200 {object} map[string]bool "Whether the reminder fired"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Device not registered with location enabled"
+// @Failure 404 {object} map[string]string "Geofence not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/todos/geofences/events [post]
+func (h *GeofenceHandler) ReportGeofenceEvent(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.GeofenceEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var occurredAt time.Time
+	if req.OccurredAt != nil {
+		occurredAt = *req.OccurredAt
+	}
+
+	fired, err := h.service.ReportTransition(c.Request.Context(), userID, todos.GeofenceTransition{
+		DeviceID:   req.DeviceID,
+		GeofenceID: req.GeofenceID,
+		Transition: todos.GeofenceTrigger(req.Transition),
+		OccurredAt: occurredAt,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"fired": fired}})
+}
+
+// parseTodoRequest reads the current user and the todo ID, answering the request when
+// either is missing
+func (h *GeofenceHandler) parseTodoRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid todo ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, todoID, true
+}
+
+func (h *GeofenceHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, todos.ErrInvalidGeofence):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, todos.ErrLocationNotEnabled):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, todos.ErrTodoNotFound), errors.Is(err, todos.ErrGeofenceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
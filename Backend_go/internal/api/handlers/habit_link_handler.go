@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habitlinks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HabitLinkHandler handles HTTP requests for links between habits and task templates
+type HabitLinkHandler struct {
+	service habitlinks.Service
+}
+
+// NewHabitLinkHandler creates a new HabitLinkHandler instance
+func NewHabitLinkHandler(service habitlinks.Service) *HabitLinkHandler {
+	return &HabitLinkHandler{service: service}
+}
+
+// GetHabitLink godoc
+// @Summary Get the link of a habit
+// @Description Get the task template a habit runs when it is completed
+// @Tags habits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Habit ID"
+// @Success 200 {object} habitlinks.Link "Habit link"
+// @Failure 400 {object} map[string]string "Invalid habit ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Habit or link not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/habits/{id}/link [get]
+func (h *HabitLinkHandler) GetHabitLink(c *gin.Context) {
+	userID, habitID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	link, err := h.service.GetLink(c.Request.Context(), userID, habitID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": link})
+}
+
+// SetHabitLink godoc
+// @Summary Link a habit to a task template
+// @Description Create or replace the link of a habit. Each completion of the habit, at most once per day, then logs hours on a task (worklog) or creates a todo from the template (todo). Work created by a link never completes habits, so links cannot trigger each other.
+// @Tags habits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Habit ID"
+// @Param request body dto.SetHabitLinkRequest true "Link template"
+// @Success 200 {object} habitlinks.Link "Saved habit link"
+// @Failure 400 {object} map[string]string "Invalid link or too many links"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Task not created by or assigned to the user"
+// @Failure 404 {object} map[string]string "Habit not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/habits/{id}/link [put]
+func (h *HabitLinkHandler) SetHabitLink(c *gin.Context) {
+	userID, habitID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SetHabitLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	link, err := h.service.SetLink(c.Request.Context(), userID, habitID, habitlinks.LinkInput{
+		Action:          habitlinks.Action(req.Action),
+		Enabled:         enabled,
+		TaskID:          req.TaskID,
+		Hours:           req.Hours,
+		TodoTitle:       req.TodoTitle,
+		TodoDescription: req.TodoDescription,
+		TodoPriority:    req.TodoPriority,
+		TodoDueInHours:  req.TodoDueInHours,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": link})
+}
+
+// DeleteHabitLink godoc
+// @Summary Remove the link of a habit
+// @Description Stop a habit from logging work or creating todos when it is completed
+// @Tags habits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Habit ID"
+// @Success 204 "Link removed"
+// @Failure 400 {object} map[string]string "Invalid habit ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Habit or link not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/habits/{id}/link [delete]
+func (h *HabitLinkHandler) DeleteHabitLink(c *gin.Context) {
+	userID, habitID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteLink(c.Request.Context(), userID, habitID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseRequest reads the current user and the habit ID, answering the request when
+// either is missing
+func (h *HabitLinkHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	habitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid habit ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, habitID, true
+}
+
+func (h *HabitLinkHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, habitlinks.ErrInvalidLink), errors.Is(err, habitlinks.ErrTooManyLinks):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, habitlinks.ErrTaskForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, habits.ErrHabitNotFound), errors.Is(err, habitlinks.ErrLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
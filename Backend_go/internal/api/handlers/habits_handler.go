@@ -69,11 +69,12 @@ func (h *HabitsHandler) CreateHabit(c *gin.Context) {
 	}
 
 	input := habits.CreateHabitInput{
-		Title:       req.Title,
-		Description: req.Description,
-		StartDay:    req.StartDay,
-		EndDay:      req.EndDay,
-		UserID:      userID,
+		Title:        req.Title,
+		Description:  req.Description,
+		StartDay:     req.StartDay,
+		EndDay:       req.EndDay,
+		ReminderTime: req.ReminderTime,
+		UserID:       userID,
 	}
 
 	createdHabit, err := h.service.CreateHabit(c.Request.Context(), input)
@@ -269,10 +270,11 @@ func (h *HabitsHandler) UpdateHabit(c *gin.Context) {
 	}
 
 	input := habits.UpdateHabitInput{
-		Title:       req.Title,
-		Description: req.Description,
-		StartDay:    req.StartDay,
-		EndDay:      req.EndDay,
+		Title:        req.Title,
+		Description:  req.Description,
+		StartDay:     req.StartDay,
+		EndDay:       req.EndDay,
+		ReminderTime: req.ReminderTime,
 	}
 
 	updatedHabit, err := h.service.UpdateHabit(c.Request.Context(), id, input)
@@ -280,6 +282,8 @@ func (h *HabitsHandler) UpdateHabit(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		if err == habits.ErrHabitNotFound {
 			statusCode = http.StatusNotFound
+		} else if err == habits.ErrInvalidInput {
+			statusCode = http.StatusBadRequest
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/inbound"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// InboundSecretHeader carries the shared secret of the mail provider posting to the ingestion endpoint
+const InboundSecretHeader = "X-Inbound-Secret"
+
+// InboundHandler handles HTTP requests for email-in
+type InboundHandler struct {
+	service inbound.Service
+	secret  string
+}
+
+// NewInboundHandler creates a new InboundHandler instance
+func NewInboundHandler(service inbound.Service, secret string) *InboundHandler {
+	return &InboundHandler{service: service, secret: secret}
+}
+
+// ReceiveEmail godoc
+// @Summary Receive an inbound email
+// @Description Endpoint for the mail provider. Accepts a raw RFC 5322 message as the request body, or as the "email" field of a multipart form with the envelope recipient in "to". The email is queued and turned into a todo by the ingestion worker.
+// @Tags inbound
+// @Accept plain
+// @Param X-Inbound-Secret header string true "Shared ingestion secret"
+// @Param recipient query string false "Envelope recipient"
+// @Success 202 "Email queued"
+// @Failure 400 {object} map[string]string "Empty message"
+// @Failure 401 {object} map[string]string "Invalid secret"
+// @Failure 413 {object} map[string]string "Message too large"
+// @Failure 503 {object} map[string]string "Email-in is not configured"
+// @Router /api/inbound/email [post]
+func (h *InboundHandler) ReceiveEmail(c *gin.Context) {
+	if h.secret == "" {
+		h.handleError(c, inbound.ErrDisabled)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader(InboundSecretHeader)), []byte(h.secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid inbound secret"})
+		return
+	}
+
+	maxBytes := h.service.Config().MaxMessageBytes
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+
+	var raw []byte
+	recipient := c.Query("recipient")
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		raw = []byte(c.PostForm("email"))
+		if recipient == "" {
+			recipient = c.PostForm("to")
+		}
+	} else {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			h.handleError(c, inbound.ErrMessageTooLarge)
+			return
+		}
+		raw = body
+	}
+	if len(raw) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "empty email message"})
+		return
+	}
+
+	if err := h.service.Enqueue(c.Request.Context(), raw, recipient); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// GetAddress godoc
+// @Summary Get my email-in address
+// @Description Get the current user's forward-to-inbox address, generating it on first use. Mail from the user's own address or an allowed sender becomes a todo.
+// @Tags inbound
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.InboundAddressResponse "Inbound address"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 503 {object} map[string]string "Email-in is not configured"
+// @Router /api/inbound/address [get]
+func (h *InboundHandler) GetAddress(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	address, err := h.service.GetAddress(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.InboundAddressToResponse(address, h.service.Config().Domain)})
+}
+
+// UpdateAddress godoc
+// @Summary Update my email-in settings
+// @Description Change the sender allow-list, the todo list new todos go to, or disable the address. Allow-list entries are email addresses or "@domain".
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body dto.UpdateInboundAddressRequest true "Email-in settings"
+// @Success 200 {object} dto.InboundAddressResponse "Inbound address"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/inbound/address [patch]
+func (h *InboundHandler) UpdateAddress(c *gin.Context) {
+	var req dto.UpdateInboundAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	address, err := h.service.UpdateAddress(c.Request.Context(), userID, inbound.UpdateAddressInput{
+		AllowedSenders: req.AllowedSenders,
+		ListID:         req.ListID,
+		Active:         req.Active,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.InboundAddressToResponse(address, h.service.Config().Domain)})
+}
+
+// RegenerateAddress godoc
+// @Summary Regenerate my email-in address
+// @Description Replace the current user's address with a new one. Mail sent to the old address is no longer accepted.
+// @Tags inbound
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.InboundAddressResponse "New inbound address"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/inbound/address/regenerate [post]
+func (h *InboundHandler) RegenerateAddress(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	address, err := h.service.RegenerateAddress(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.InboundAddressToResponse(address, h.service.Config().Domain)})
+}
+
+// ListMessages godoc
+// @Summary List my inbound emails
+// @Description Get the emails received at the current user's address, newest first, with the todo they created or the reason they were rejected
+// @Tags inbound
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} dto.InboundMessageListResponse "Inbound emails"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/inbound/messages [get]
+func (h *InboundHandler) ListMessages(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+
+	messages, total, err := h.service.ListMessages(c.Request.Context(), userID, page, pageSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.InboundMessageListResponse{
+		Messages:   dto.InboundMessagesToResponse(messages),
+		TotalCount: total,
+		Page:       page,
+		PageSize:   pageSize,
+	}})
+}
+
+// ListTodoAttachments godoc
+// @Summary List todo attachments
+// @Description Get the attachments stored with a todo created from an email
+// @Tags inbound
+// @Produce json
+// @Security BearerAuth
+// @Param todo_id path string true "Todo ID" format(uuid)
+// @Success 200 {array} dto.TodoAttachmentResponse "Attachments"
+// @Failure 400 {object} map[string]string "Invalid todo ID"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Router /api/inbound/todos/{todo_id}/attachments [get]
+func (h *InboundHandler) ListTodoAttachments(c *gin.Context) {
+	todoID, err := uuid.Parse(c.Param("todo_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid todo ID"})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	attachments, err := h.service.ListAttachments(c.Request.Context(), todoID, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.TodoAttachmentsToResponse(attachments)})
+}
+
+// DownloadAttachment godoc
+// @Summary Download a todo attachment
+// @Description Download the content of an attachment stored with a todo
+// @Tags inbound
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Attachment ID" format(uuid)
+// @Success 200 {file} binary "Attachment content"
+// @Failure 400 {object} map[string]string "Invalid attachment ID"
+// @Failure 404 {object} map[string]string "Attachment not found"
+// @Router /api/inbound/attachments/{id} [get]
+func (h *InboundHandler) DownloadAttachment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	attachment, err := h.service.GetAttachment(c.Request.Context(), id, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.Filename))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, attachment.ContentType, attachment.Content)
+}
+
+// handleError maps email-in errors to HTTP responses
+func (h *InboundHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, inbound.ErrDisabled):
+		statusCode = http.StatusServiceUnavailable
+	case errors.Is(err, inbound.ErrAddressNotFound), errors.Is(err, inbound.ErrAttachmentNotFound),
+		errors.Is(err, todos.ErrTodoNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, inbound.ErrMessageTooLarge):
+		statusCode = http.StatusRequestEntityTooLarge
+	case errors.Is(err, inbound.ErrInvalidInput), errors.Is(err, inbound.ErrInvalidSender):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/chat"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/integrations"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/vcs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IntegrationHandler handles HTTP requests for the state of an organization's integrations
+type IntegrationHandler struct {
+	service integrations.Service
+}
+
+// NewIntegrationHandler creates a new IntegrationHandler instance
+func NewIntegrationHandler(service integrations.Service) *IntegrationHandler {
+	return &IntegrationHandler{service: service}
+}
+
+// ListIntegrations godoc
+// @Summary List integration status
+// @Description List every integration configured for the organization with its connection state, last sync time and recent errors
+// @Tags integrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {array} integrations.Status "Integration status"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not a member of the organization"
+// @Router /api/organizations/{id}/integrations [get]
+func (h *IntegrationHandler) ListIntegrations(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	statuses, err := h.service.List(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": statuses})
+}
+
+// ReconnectIntegration godoc
+// @Summary Reconnect an integration
+// @Description Authorize an integration again. OAuth integrations such as Slack return a redirect_url where the user completes it;
+// @Description token integrations such as GitHub are checked right away, with the access token replaced when one is given.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param integration_id path string true "Integration ID" format(uuid)
+// @Param request body dto.ReconnectIntegrationRequest false "New access token"
+// @Success 200 {object} integrations.ReconnectResult "Redirect URL or updated status"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Integration not found"
+// @Failure 502 {object} map[string]string "Integration could not be reached"
+// @Router /api/organizations/{id}/integrations/{integration_id}/reconnect [post]
+func (h *IntegrationHandler) ReconnectIntegration(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+	integrationID, err := uuid.Parse(c.Param("integration_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid integration ID"})
+		return
+	}
+
+	var req dto.ReconnectIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.Reconnect(c.Request.Context(), orgID, userID, integrationID,
+		integrations.ReconnectInput{AccessToken: req.AccessToken})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// handleError maps integration errors to HTTP responses
+func (h *IntegrationHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, integrations.ErrIntegrationNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, integrations.ErrReconnectFailed):
+		statusCode = http.StatusBadGateway
+	case errors.Is(err, vcs.ErrNotAuthorized):
+		statusCode = http.StatusForbidden
+	case errors.Is(err, chat.ErrProviderNotSupported), errors.Is(err, vcs.ErrInvalidInput):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
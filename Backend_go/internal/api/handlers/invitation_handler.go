@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// InvitationHandler handles HTTP requests for invitations to organizations
+type InvitationHandler struct {
+	service organization.InvitationService
+}
+
+// NewInvitationHandler creates a new InvitationHandler instance
+func NewInvitationHandler(service organization.InvitationService) *InvitationHandler {
+	return &InvitationHandler{service: service}
+}
+
+// CreateInvitation godoc
+// @Summary Invite someone to the organization
+// @Description Invite an email address to join the organization with a role. The invitation is emailed and its token is returned once, so the link can also be shared directly.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param request body dto.CreateInvitationRequest true "Invitation"
+// @Success 201 {object} organization.Invitation "Created invitation with its token"
+// @Failure 400 {object} map[string]string "Invalid email, role or expiry"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 409 {object} map[string]string "A pending invitation exists for the email"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/invites [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	var req dto.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.service.CreateInvitation(c.Request.Context(), orgID, userID, organization.InvitationInput{
+		Email:     req.Email,
+		Role:      req.Role,
+		ExpiresIn: time.Duration(req.ExpiresInHours) * time.Hour,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": invitation})
+}
+
+// ListInvitations godoc
+// @Summary List the invitations of the organization
+// @Description List every invitation of the organization with its status, newest first
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {array} organization.Invitation "Invitations"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/invites [get]
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	invitations, err := h.service.ListInvitations(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation so its token can no longer be accepted
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param invite_id path string true "Invitation ID" format(uuid)
+// @Success 204 "Invitation revoked"
+// @Failure 400 {object} map[string]string "Invalid invitation ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 409 {object} map[string]string "Invitation already accepted or revoked"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/invites/{invite_id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+	invitationID, err := uuid.Parse(c.Param("invite_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation ID"})
+		return
+	}
+
+	if err := h.service.RevokeInvitation(c.Request.Context(), orgID, invitationID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Join the organization of an invitation as the authenticated user, whose email must match the invited one
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AcceptInvitationRequest true "Invitation token"
+// @Success 200 {object} organization.Member "Membership in the organization"
+// @Failure 400 {object} map[string]string "Missing token"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Invitation sent to a different email"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 409 {object} map[string]string "Already a member, or invitation used"
+// @Failure 410 {object} map[string]string "Invitation expired"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/invites/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.service.AcceptInvitation(c.Request.Context(), req.Token, userID, c.GetString("email"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+func (h *InvitationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, organization.ErrInvalidInvitation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, organization.ErrInvitationEmail):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, organization.ErrInvitationNotFound), errors.Is(err, organization.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, organization.ErrInvitationExists), errors.Is(err, organization.ErrInvitationUsed),
+		errors.Is(err, organization.ErrMemberExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, organization.ErrInvitationExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
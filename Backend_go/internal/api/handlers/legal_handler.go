@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/legal"
+	"github.com/gin-gonic/gin"
+)
+
+// LegalHandler handles HTTP requests for legal documents and user consent
+type LegalHandler struct {
+	service legal.Service
+}
+
+// NewLegalHandler creates a new LegalHandler instance
+func NewLegalHandler(service legal.Service) *LegalHandler {
+	return &LegalHandler{service: service}
+}
+
+// GetDocuments godoc
+// @Summary Get current legal documents
+// @Description Get the current version and location of the terms of service and privacy policy
+// @Tags legal
+// @Produce json
+// @Success 200 {array} legal.Document "Current documents"
+// @Router /api/legal/documents [get]
+func (h *LegalHandler) GetDocuments(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.service.Documents()})
+}
+
+// GetConsents godoc
+// @Summary Get consent status
+// @Description Get which version of each current legal document the user accepted, and whether acceptance of the current version is required
+// @Tags legal
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} legal.ConsentStatus "Consent status per document"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/legal/consents [get]
+func (h *LegalHandler) GetConsents(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	statuses, err := h.service.Status(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": statuses})
+}
+
+// AcceptConsents godoc
+// @Summary Accept legal documents
+// @Description Record that the user accepted the current versions of legal documents. Accepting a version again is a no-op.
+// @Tags legal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AcceptConsentsRequest true "Documents and the versions shown to the user"
+// @Success 200 {array} legal.ConsentStatus "Consent status per document"
+// @Failure 400 {object} map[string]string "Unknown document"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Version is not current"
+// @Router /api/legal/consents [post]
+func (h *LegalHandler) AcceptConsents(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.AcceptConsentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := legal.AcceptInput{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	for _, d := range req.Documents {
+		input.Acceptances = append(input.Acceptances, legal.Acceptance{
+			Type:    legal.DocumentType(d.Type),
+			Version: d.Version,
+		})
+	}
+
+	statuses, err := h.service.Accept(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": statuses})
+}
+
+// handleError maps consent errors to HTTP responses
+func (h *LegalHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, legal.ErrUnknownDocument), errors.Is(err, legal.ErrInvalidInput):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, legal.ErrVersionMismatch):
+		statusCode = http.StatusConflict
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/attachments"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
@@ -27,6 +28,7 @@ func HabitToResponse(h *habits.Habit) *dto.HabitResponse {
 		CreatedAt:         h.CreatedAt,
 		UpdatedAt:         h.UpdatedAt,
 		StreakQuality:     h.StreakQuality,
+		ReminderTime:      h.ReminderTime,
 	}
 }
 
@@ -55,6 +57,7 @@ func TaskToResponse(t *task.Task) *dto.TaskResponse {
 		Title:          t.Title,
 		Description:    t.Description,
 		Status:         string(t.Status),
+		CustomStatus:   t.CustomStatus,
 		Priority:       string(t.Priority),
 		CreatedAt:      t.CreatedAt,
 		UpdatedAt:      t.UpdatedAt,
@@ -69,6 +72,116 @@ func TaskToResponse(t *task.Task) *dto.TaskResponse {
 		StartDate:      t.StartDate,
 		Duration:       t.Duration,
 		DueDate:        t.DueDate,
+		ArchivedAt:     t.ArchivedAt,
+
+		Position:           t.Position,
+		DescriptionVersion: t.DescriptionVersion,
+		Risk:               task.RiskOf(t),
+		PriorityScore:      t.PriorityScore,
+		PriorityFactors:    priorityFactorsOf(t),
+	}
+}
+
+func priorityFactorsOf(t *task.Task) *task.PriorityFactors {
+	if t.PriorityFactors.ScoredAt == nil {
+		return nil
+	}
+	factors := t.PriorityFactors
+	return &factors
+}
+
+func taskResponseList(tasks []task.Task) []dto.TaskResponse {
+	response := make([]dto.TaskResponse, len(tasks))
+	for i := range tasks {
+		response[i] = *TaskToResponse(&tasks[i])
+	}
+	return response
+}
+
+// ProjectHealthToResponse converts a project health summary
+func ProjectHealthToResponse(health *task.ProjectHealth) dto.ProjectHealthResponse {
+	return dto.ProjectHealthResponse{
+		ProjectID:      health.ProjectID,
+		Score:          health.Score,
+		TotalTasks:     health.TotalTasks,
+		OpenTasks:      health.OpenTasks,
+		CompletedTasks: health.CompletedTasks,
+		Completion:     health.Completion,
+		Overdue:        health.Overdue,
+		AtRisk:         health.AtRisk,
+		HighRisk:       health.HighRisk,
+		Blocked:        health.Blocked,
+		DueSoon:        health.DueSoon,
+		Clock:          string(health.Clock),
+		AtRiskTasks:    taskResponseList(health.AtRiskTasks),
+		AnalyzedAt:     health.AnalyzedAt,
+	}
+}
+
+// MyWorkToResponse converts a user's grouped open tasks
+func MyWorkToResponse(work *task.MyWork) dto.MyWorkResponse {
+	return dto.MyWorkResponse{
+		Overdue: taskResponseList(work.Overdue),
+		AtRisk:  taskResponseList(work.AtRisk),
+		DueSoon: taskResponseList(work.DueSoon),
+		Other:   taskResponseList(work.Other),
+	}
+}
+
+// SubtaskTreeToResponse converts a subtask tree, level by level
+func SubtaskTreeToResponse(node *task.SubtaskNode) dto.SubtaskTreeResponse {
+	response := dto.SubtaskTreeResponse{
+		Task:     TaskToResponse(&node.Task),
+		Rollup:   node.Rollup,
+		Children: make([]dto.SubtaskTreeResponse, len(node.Children)),
+	}
+	for i := range node.Children {
+		response.Children[i] = SubtaskTreeToResponse(&node.Children[i])
+	}
+	return response
+}
+
+func dependencyLinksToResponse(links []task.DependencyLink) []dto.TaskDependencyLinkResponse {
+	response := make([]dto.TaskDependencyLinkResponse, len(links))
+	for i := range links {
+		response[i] = dto.TaskDependencyLinkResponse{Task: TaskToResponse(&links[i].Task), Depth: links[i].Depth}
+	}
+	return response
+}
+
+// DependencyGraphToResponse converts a task's upstream and downstream dependencies
+func DependencyGraphToResponse(graph *task.DependencyGraph) dto.TaskDependenciesResponse {
+	return dto.TaskDependenciesResponse{
+		Task:       TaskToResponse(&graph.Task),
+		Upstream:   dependencyLinksToResponse(graph.Upstream),
+		Downstream: dependencyLinksToResponse(graph.Downstream),
+	}
+}
+
+func scheduledTasksToResponse(tasks []task.ScheduledTask) []dto.ScheduledTaskResponse {
+	response := make([]dto.ScheduledTaskResponse, len(tasks))
+	for i, st := range tasks {
+		response[i] = dto.ScheduledTaskResponse{
+			Task:           TaskToResponse(&tasks[i].Task),
+			RemainingHours: st.RemainingHours,
+			EarliestStart:  st.EarliestStart,
+			EarliestFinish: st.EarliestFinish,
+			LatestStart:    st.LatestStart,
+			LatestFinish:   st.LatestFinish,
+			Slack:          st.Slack,
+			Critical:       st.Critical,
+		}
+	}
+	return response
+}
+
+// CriticalPathToResponse converts a project's critical path and schedule
+func CriticalPathToResponse(path *task.CriticalPath) dto.CriticalPathResponse {
+	return dto.CriticalPathResponse{
+		ProjectID:  path.ProjectID,
+		TotalHours: path.TotalHours,
+		Path:       scheduledTasksToResponse(path.Path),
+		Tasks:      scheduledTasksToResponse(path.Tasks),
 	}
 }
 
@@ -99,6 +212,7 @@ func TodoToResponse(t *todos.Todo) *dto.TodoResponse {
 		Checklist:             t.Checklist,
 		LinkedTaskID:          t.LinkedTaskID,
 		LinkedCalendarEventID: t.LinkedCalendarEventID,
+		RecurrenceSourceID:    t.RecurrenceSourceID,
 		IsCompleted:           t.IsCompleted,
 		CompletedAt:           t.CompletionDate,
 		CreatedAt:             t.CreatedAt,
@@ -142,3 +256,28 @@ func TodoListsToResponse(lists []todos.TodoList) []*dto.TodoListResponse {
 	}
 	return response
 }
+
+// Attachments
+func AttachmentsToResponse(list []attachments.Attachment, path string) []dto.AttachmentResponse {
+	response := make([]dto.AttachmentResponse, len(list))
+	for i, a := range list {
+		response[i] = dto.AttachmentResponse{
+			ID:            a.ID,
+			FileName:      a.FileName,
+			ContentType:   a.ContentType,
+			Size:          a.Size,
+			Checksum:      a.Checksum,
+			UploadedBy:    a.UploadedBy,
+			ScanStatus:    string(a.ScanStatus),
+			Threat:        a.Threat,
+			PreviewStatus: string(a.PreviewStatus),
+			CreatedAt:     a.CreatedAt,
+		}
+		if a.PreviewStatus == attachments.PreviewReady && a.ScanStatus.Downloadable() {
+			base := path + "/" + a.ID.String() + "/"
+			response[i].ThumbnailURL = base + string(attachments.VariantThumbnail)
+			response[i].PreviewURL = base + string(attachments.VariantPreview)
+		}
+	}
+	return response
+}
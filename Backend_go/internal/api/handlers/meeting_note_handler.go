@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/meetingnotes"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MeetingNoteHandler handles HTTP requests for the notes of calendar event occurrences
+type MeetingNoteHandler struct {
+	service meetingnotes.Service
+}
+
+// NewMeetingNoteHandler creates a new MeetingNoteHandler instance
+func NewMeetingNoteHandler(service meetingnotes.Service) *MeetingNoteHandler {
+	return &MeetingNoteHandler{service: service}
+}
+
+// GetMeetingNote godoc
+// @Summary Get the notes of an event occurrence
+// @Description Get the meeting notes of an occurrence of a calendar event, for its organizer and invitees
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param occurrence_time query string false "Start of the occurrence (RFC3339), required for recurring events"
+// @Success 200 {object} meetingnotes.Note "Meeting notes"
+// @Failure 400 {object} map[string]string "Invalid event ID or occurrence"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not the organizer or an invitee"
+// @Failure 404 {object} map[string]string "Event or notes not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/calendar/events/{id}/notes [get]
+func (h *MeetingNoteHandler) GetMeetingNote(c *gin.Context) {
+	userID, eventID, occurrenceTime, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	note, err := h.service.GetNote(c.Request.Context(), userID, eventID, occurrenceTime)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": note})
+}
+
+// SaveMeetingNote godoc
+// @Summary Save the notes of an event occurrence
+// @Description Create or replace the meeting notes of an occurrence of a calendar event
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param request body dto.SaveMeetingNoteRequest true "Meeting notes"
+// @Success 200 {object} meetingnotes.Note "Saved meeting notes"
+// @Failure 400 {object} map[string]string "Invalid notes or occurrence"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not the organizer or an invitee"
+// @Failure 404 {object} map[string]string "Event not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/calendar/events/{id}/notes [put]
+func (h *MeetingNoteHandler) SaveMeetingNote(c *gin.Context) {
+	userID, eventID, _, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveMeetingNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	note, err := h.service.SaveNote(c.Request.Context(), userID, eventID, occurrenceOf(req.OccurrenceTime), req.Content)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": note})
+}
+
+// DeleteMeetingNote godoc
+// @Summary Delete the notes of an event occurrence
+// @Description Delete the meeting notes of an occurrence along with their links to extracted action items. The tasks and todos themselves are kept.
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param occurrence_time query string false "Start of the occurrence (RFC3339), required for recurring events"
+// @Success 204 "Notes deleted"
+// @Failure 400 {object} map[string]string "Invalid event ID or occurrence"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not the organizer or an invitee"
+// @Failure 404 {object} map[string]string "Event or notes not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/calendar/events/{id}/notes [delete]
+func (h *MeetingNoteHandler) DeleteMeetingNote(c *gin.Context) {
+	userID, eventID, occurrenceTime, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteNote(c.Request.Context(), userID, eventID, occurrenceTime); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListActionItems godoc
+// @Summary List the action items of an event occurrence
+// @Description List the tasks and todos extracted from the meeting notes of an occurrence
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param occurrence_time query string false "Start of the occurrence (RFC3339), required for recurring events"
+// @Success 200 {array} meetingnotes.ActionItem "Action items"
+// @Failure 400 {object} map[string]string "Invalid event ID or occurrence"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not the organizer or an invitee"
+// @Failure 404 {object} map[string]string "Event or notes not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/calendar/events/{id}/notes/action-items [get]
+func (h *MeetingNoteHandler) ListActionItems(c *gin.Context) {
+	userID, eventID, occurrenceTime, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.ListActionItems(c.Request.Context(), userID, eventID, occurrenceTime)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// ExtractActionItems godoc
+// @Summary Extract action items from meeting notes
+// @Description Turn the action items of an occurrence's notes into todos linked to the event, or into tasks assigned to the caller in a project. Unchecked checklist lines ("- [ ] ...") and lines starting with TODO:, Action: or AI: are action items; with an AI provider configured, the model finds further ones. Items extracted before are not created again.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param request body dto.ExtractActionItemsRequest true "Extraction target"
+// @Success 200 {object} meetingnotes.Extraction "Created and previously extracted action items"
+// @Failure 400 {object} map[string]string "Invalid target or occurrence"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not an invitee, or not allowed to create tasks in the project"
+// @Failure 404 {object} map[string]string "Event, notes or project not found"
+// @Failure 422 {object} map[string]string "No action items in the notes"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/calendar/events/{id}/notes/action-items [post]
+func (h *MeetingNoteHandler) ExtractActionItems(c *gin.Context) {
+	userID, eventID, _, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ExtractActionItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	extraction, err := h.service.ExtractActionItems(c.Request.Context(), userID, eventID, occurrenceOf(req.OccurrenceTime), meetingnotes.ExtractInput{
+		Target:    meetingnotes.Target(req.Target),
+		ProjectID: req.ProjectID,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": extraction})
+}
+
+// parseRequest reads the current user, the event ID and the optional occurrence_time
+// query parameter, answering the request when any is missing or invalid
+func (h *MeetingNoteHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, time.Time, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, time.Time{}, false
+	}
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return uuid.Nil, uuid.Nil, time.Time{}, false
+	}
+	var occurrenceTime time.Time
+	if raw := c.Query("occurrence_time"); raw != "" {
+		occurrenceTime, err = time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "occurrence_time must be an RFC3339 time"})
+			return uuid.Nil, uuid.Nil, time.Time{}, false
+		}
+	}
+	return userID, eventID, occurrenceTime, true
+}
+
+func occurrenceOf(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func (h *MeetingNoteHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, meetingnotes.ErrInvalidNote), errors.Is(err, meetingnotes.ErrInvalidOccurrence),
+		errors.Is(err, meetingnotes.ErrInvalidTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, meetingnotes.ErrEventForbidden), errors.Is(err, meetingnotes.ErrProjectForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, meetingnotes.ErrEventNotFound), errors.Is(err, meetingnotes.ErrNoteNotFound),
+		errors.Is(err, project.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, meetingnotes.ErrNoActionItems):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
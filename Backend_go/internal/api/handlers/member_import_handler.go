@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/memberimport"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MemberImportHandler handles HTTP requests for bulk member imports
+type MemberImportHandler struct {
+	service memberimport.Service
+}
+
+// NewMemberImportHandler creates a new MemberImportHandler instance
+func NewMemberImportHandler(service memberimport.Service) *MemberImportHandler {
+	return &MemberImportHandler{service: service}
+}
+
+// ImportMembers godoc
+// @Summary Import members from a CSV file
+// @Description Queue a CSV of emails and optional roles for import. Each row is invited to the organization in the background, or added directly when the email has an account and add_existing is set. The CSV is sent as the "file" field of a multipart form or as a text/csv body; a header row naming the email and role columns is optional. With dry_run the rows are checked right away and the report says what would happen to each, without changing anything.
+// @Tags organizations
+// @Accept multipart/form-data,text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param file formData file false "CSV file"
+// @Param add_existing query bool false "Add people who already have an account directly"
+// @Param dry_run query bool false "Report what the import would do for each row without inviting or adding anyone"
+// @Success 200 {object} memberimport.Import "Dry run report"
+// @Success 202 {object} memberimport.Import "Queued import"
+// @Failure 400 {object} map[string]string "Missing, malformed or empty CSV, or too many rows"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 413 {object} map[string]string "File too large"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/members/import [post]
+func (h *MemberImportHandler) ImportMembers(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, memberimport.MaxFileBytes+1<<20)
+
+	var csv io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			h.handleUploadError(c, err)
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+		defer file.Close()
+		csv = file
+	}
+	data, err := io.ReadAll(io.LimitReader(csv, memberimport.MaxFileBytes+1))
+	if err != nil {
+		h.handleUploadError(c, err)
+		return
+	}
+	if len(data) > memberimport.MaxFileBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		return
+	}
+
+	opts := memberimport.Options{
+		AddExisting: c.Query("add_existing") == "true",
+		DryRun:      c.Query("dry_run") == "true",
+	}
+	imp, err := h.service.StartImport(c.Request.Context(), orgID, userID, bytes.NewReader(data), opts)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if opts.DryRun {
+		c.JSON(http.StatusOK, gin.H{"data": imp})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": imp})
+}
+
+// ListImports godoc
+// @Summary List the member imports of the organization
+// @Description List the member imports of the organization with their status and counts, newest first. Rows are only included in the report of a single import.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {array} memberimport.Import "Imports"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/members/imports [get]
+func (h *MemberImportHandler) ListImports(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	imports, err := h.service.ListImports(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": imports})
+}
+
+// GetImport godoc
+// @Summary Get a member import report
+// @Description Get a member import with the outcome of every row: invited, added, skipped or failed, with the reason
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param import_id path string true "Import ID" format(uuid)
+// @Success 200 {object} memberimport.Import "Import with per-row results"
+// @Failure 400 {object} map[string]string "Invalid import ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Import not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/members/imports/{import_id} [get]
+func (h *MemberImportHandler) GetImport(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+	importID, err := uuid.Parse(c.Param("import_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid import ID"})
+		return
+	}
+
+	imp, err := h.service.GetImport(c.Request.Context(), orgID, importID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": imp})
+}
+
+// handleUploadError reports a body that is too large or cannot be read as a bad upload
+func (h *MemberImportHandler) handleUploadError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+	case errors.Is(err, http.ErrMissingFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a CSV file is required in the \"file\" field"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read the uploaded file"})
+	}
+}
+
+func (h *MemberImportHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, memberimport.ErrInvalidCSV), errors.Is(err, memberimport.ErrEmptyImport),
+		errors.Is(err, memberimport.ErrTooManyRows):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, memberimport.ErrImportNotFound), errors.Is(err, organization.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultUsageDays is the range of a usage report when no dates are given
+const defaultUsageDays = 30
+
+// MeteringHandler handles HTTP requests for metered usage
+type MeteringHandler struct {
+	service metering.Service
+}
+
+// NewMeteringHandler creates a new MeteringHandler instance
+func NewMeteringHandler(service metering.Service) *MeteringHandler {
+	return &MeteringHandler{service: service}
+}
+
+// GetUsage godoc
+// @Summary Get metered usage
+// @Description Get usage per organization, meter and UTC day, with totals per meter (admin only). Usage without an organization is reported under the nil UUID. Recent usage may take a few seconds to appear.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param organization_id query string false "Organization ID" format(uuid)
+// @Param meter query string false "Meter" Enums(api_calls, workflow_executions, storage_bytes, ai_suggestions)
+// @Param from query string false "First day (YYYY-MM-DD), defaults to 29 days before to"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} metering.UsageReport "Usage"
+// @Failure 400 {object} map[string]string "Invalid filter"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Router /api/admin/metering/usage [get]
+func (h *MeteringHandler) GetUsage(c *gin.Context) {
+	filter := metering.UsageFilter{To: time.Now()}
+
+	if value := c.Query("organization_id"); value != "" {
+		orgID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+			return
+		}
+		filter.OrganizationID = &orgID
+	}
+	if value := c.Query("meter"); value != "" {
+		meter := metering.Meter(value)
+		filter.Meter = &meter
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		filter.To = to
+	}
+	filter.From = filter.To.AddDate(0, 0, -(defaultUsageDays - 1))
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		filter.From = from
+	}
+
+	report, err := h.service.Usage(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// ReportUsage godoc
+// @Summary Report usage
+// @Description Report usage produced outside this API, such as AI suggestions shown by the AI service. Usage is attributed to the organization in the X-Organization-ID header, if any.
+// @Tags metering
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ReportUsageRequest true "Meter and quantity"
+// @Success 202 "Usage recorded"
+// @Failure 400 {object} map[string]string "Invalid meter or quantity"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/metering/events [post]
+func (h *MeteringHandler) ReportUsage(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.ReportUsageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orgID, _ := middleware.GetOrganizationID(c)
+	err := h.service.Report(c.Request.Context(), metering.Event{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Meter:          metering.Meter(req.Meter),
+		Quantity:       req.Quantity,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// handleError maps metering errors to HTTP responses
+func (h *MeteringHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, metering.ErrInvalidMeter), errors.Is(err, metering.ErrInvalidInput):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"encoding/base64"
 	"net/http"
-	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
@@ -17,12 +16,13 @@ import (
 // MFAHandler handles MFA-related operations
 type MFAHandler struct {
 	userService user.Service
+	tokens      *auth.RefreshTokenService
 	jwtSecret   string
 	logger      *logrus.Logger
 }
 
 // NewMFAHandler creates a new MFA handler
-func NewMFAHandler(userService user.Service, jwtSecret string, logger *logrus.Logger) *MFAHandler {
+func NewMFAHandler(userService user.Service, tokens *auth.RefreshTokenService, jwtSecret string, logger *logrus.Logger) *MFAHandler {
 	if logger == nil {
 		logger = logrus.New()
 		logger.SetFormatter(&logrus.JSONFormatter{})
@@ -30,6 +30,7 @@ func NewMFAHandler(userService user.Service, jwtSecret string, logger *logrus.Lo
 
 	return &MFAHandler{
 		userService: userService,
+		tokens:      tokens,
 		jwtSecret:   jwtSecret,
 		logger:      logger,
 	}
@@ -198,34 +199,26 @@ func (h *MFAHandler) ValidateMFA(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := auth.GenerateToken(
-		user.ID,
-		user.Email,
-		roles,
-		uuid.Nil,
-		permissions,
-		h.jwtSecret,
-		24,
-	)
+	// Generate access and refresh tokens and open a session with device info
+	pair, err := h.tokens.IssueTokenPair(c.Request.Context(), auth.Identity{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Roles:       roles,
+		OrgID:       uuid.Nil,
+		Permissions: permissions,
+	}, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to generate token")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
-
-	// Create session with device info
-	session := auth.GetSessionStore().CreateSession(
-		user.ID,
-		c.Request.UserAgent(),
-		c.ClientIP(),
-		token,
-		24*time.Hour,
-	)
+	session := pair.Session
 
 	response := dto.LoginResponse{
-		Token:     token,
-		ExpiresAt: session.ExpiresAt,
+		Token:            pair.AccessToken,
+		ExpiresAt:        pair.AccessExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
 		User: dto.UserResponse{
 			ID:          user.ID,
 			Email:       user.Email,
@@ -1,15 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/badges"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/presence"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
 	"github.com/gin-gonic/gin"
@@ -21,15 +25,18 @@ import (
 // NotificationHandler handles notification-related requests
 type NotificationHandler struct {
 	service  notification.Service
+	presence presence.Service
+	badges   badges.Service
 	logger   *logger.Logger
 	upgrader websocket.Upgrader
 }
 
 // NewNotificationHandler creates a new notification handler
-func NewNotificationHandler(service notification.Service, logger *logger.Logger) *NotificationHandler {
+func NewNotificationHandler(service notification.Service, presenceService presence.Service, logger *logger.Logger) *NotificationHandler {
 	return &NotificationHandler{
-		service: service,
-		logger:  logger,
+		service:  service,
+		presence: presenceService,
+		logger:   logger,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -40,6 +47,18 @@ func NewNotificationHandler(service notification.Service, logger *logger.Logger)
 	}
 }
 
+// WithBadges refreshes the caller's badge counts after notifications are read or deleted
+func (h *NotificationHandler) WithBadges(badgeService badges.Service) *NotificationHandler {
+	h.badges = badgeService
+	return h
+}
+
+func (h *NotificationHandler) refreshBadges(userID uuid.UUID) {
+	if h.badges != nil {
+		h.badges.Refresh(userID)
+	}
+}
+
 // GetAll godoc
 // @Summary Get all notifications for a user
 // @Description Get all notifications for the authenticated user with pagination
@@ -301,6 +320,7 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
 		return
 	}
+	h.refreshBadges(notif.UserID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
 }
@@ -334,6 +354,7 @@ func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark all notifications as read"})
 		return
 	}
+	h.refreshBadges(uid)
 
 	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
 }
@@ -427,6 +448,7 @@ func (h *NotificationHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification"})
 		return
 	}
+	h.refreshBadges(notif.UserID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Notification deleted"})
 }
@@ -574,6 +596,36 @@ func (h *NotificationHandler) WebSocketHandler(c *gin.Context) {
 		}
 	}
 
+	// Track presence for as long as the socket is open
+	conn := &presenceConnection{}
+	presenceEvents := make(chan *presence.Event, 16)
+	if h.presence != nil {
+		if err := h.presence.Connect(c.Request.Context(), uid); err != nil {
+			h.logger.Error("Failed to record presence", zap.Error(err), zap.String("user_id", uid.String()))
+		}
+		defer func() {
+			if err := h.presence.Disconnect(context.Background(), uid); err != nil {
+				h.logger.Error("Failed to clear presence", zap.Error(err), zap.String("user_id", uid.String()))
+			}
+		}()
+
+		presenceCtx, cancelPresence := context.WithCancel(context.Background())
+		defer cancelPresence()
+		go func() {
+			err := h.presence.Subscribe(presenceCtx, func(event *presence.Event) error {
+				select {
+				case presenceEvents <- event:
+				default:
+					// Drop events for slow clients rather than blocking the subscription
+				}
+				return nil
+			})
+			if err != nil && presenceCtx.Err() == nil {
+				h.logger.Error("Presence subscription ended", zap.Error(err), zap.String("user_id", uid.String()))
+			}
+		}()
+	}
+
 	// Create a ping ticker to keep connection alive
 	pingTicker := time.NewTicker(30 * time.Second)
 	defer pingTicker.Stop()
@@ -624,6 +676,8 @@ func (h *NotificationHandler) WebSocketHandler(c *gin.Context) {
 							}
 						case "mark_all_read":
 							h.service.MarkAllAsRead(c.Request.Context(), uid)
+						case "presence.view", "presence.leave", "presence.status", "presence.edit_start", "presence.edit_stop":
+							h.handlePresenceCommand(c.Request.Context(), uid, conn, cmd, msgData)
 						}
 					}
 				}
@@ -652,6 +706,18 @@ func (h *NotificationHandler) WebSocketHandler(c *gin.Context) {
 				return
 			}
 
+		case event := <-presenceEvents:
+			// Only forward activity on the entity this client has open
+			if event.UserID == uid || event.Entity == nil || !conn.isViewing(*event.Entity) {
+				continue
+			}
+			if err := ws.WriteJSON(event); err != nil {
+				h.logger.Error("WebSocket write error",
+					zap.Error(err),
+					zap.String("user_id", uid.String()))
+				return
+			}
+
 		case <-pingTicker.C:
 			// Send ping to keep connection alive
 			if err := ws.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -660,6 +726,11 @@ func (h *NotificationHandler) WebSocketHandler(c *gin.Context) {
 					zap.String("user_id", uid.String()))
 				return
 			}
+			if h.presence != nil {
+				if err := h.presence.Heartbeat(c.Request.Context(), uid); err != nil {
+					h.logger.Error("Failed to refresh presence", zap.Error(err), zap.String("user_id", uid.String()))
+				}
+			}
 
 		case <-done:
 			// WebSocket closed by client
@@ -667,3 +738,71 @@ func (h *NotificationHandler) WebSocketHandler(c *gin.Context) {
 		}
 	}
 }
+
+// presenceConnection tracks what a single WebSocket client currently has open
+type presenceConnection struct {
+	mu      sync.RWMutex
+	viewing *presence.Entity
+}
+
+func (p *presenceConnection) setViewing(entity *presence.Entity) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.viewing = entity
+}
+
+func (p *presenceConnection) isViewing(entity presence.Entity) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.viewing != nil && *p.viewing == entity
+}
+
+// handlePresenceCommand applies a presence command received over the WebSocket
+func (h *NotificationHandler) handlePresenceCommand(ctx context.Context, userID uuid.UUID, conn *presenceConnection, cmd string, msgData map[string]interface{}) {
+	if h.presence == nil {
+		return
+	}
+
+	var err error
+	switch cmd {
+	case "presence.view":
+		entityType, _ := msgData["entity_type"].(string)
+		rawID, _ := msgData["entity_id"].(string)
+		entityID, parseErr := uuid.Parse(rawID)
+		if parseErr != nil {
+			return
+		}
+		entity := presence.Entity{Type: entityType, ID: entityID}
+		if err = h.presence.View(ctx, userID, entity); err == nil {
+			conn.setViewing(&entity)
+		}
+	case "presence.leave":
+		if err = h.presence.Leave(ctx, userID); err == nil {
+			conn.setViewing(nil)
+		}
+	case "presence.edit_start":
+		entityType, _ := msgData["entity_type"].(string)
+		rawID, _ := msgData["entity_id"].(string)
+		field, _ := msgData["field"].(string)
+		entityID, parseErr := uuid.Parse(rawID)
+		if parseErr != nil {
+			return
+		}
+		entity := presence.Entity{Type: entityType, ID: entityID}
+		if err = h.presence.StartEditing(ctx, userID, entity, field); err == nil {
+			conn.setViewing(&entity)
+		}
+	case "presence.edit_stop":
+		err = h.presence.StopEditing(ctx, userID)
+	case "presence.status":
+		status, _ := msgData["status"].(string)
+		err = h.presence.SetStatus(ctx, userID, presence.Status(status))
+	}
+
+	if err != nil {
+		h.logger.Warn("Failed to apply presence command",
+			zap.String("command", cmd),
+			zap.String("user_id", userID.String()),
+			zap.Error(err))
+	}
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
@@ -17,15 +16,17 @@ import (
 type OAuthHandler struct {
 	oauthService *auth.OAuthService
 	userService  user.Service
+	tokens       *auth.RefreshTokenService
 	jwtSecret    string
 	logger       *zap.Logger
 }
 
 // NewOAuthHandler creates a new OAuthHandler
-func NewOAuthHandler(oauthService *auth.OAuthService, userService user.Service, jwtSecret string, logger *zap.Logger) *OAuthHandler {
+func NewOAuthHandler(oauthService *auth.OAuthService, userService user.Service, tokens *auth.RefreshTokenService, jwtSecret string, logger *zap.Logger) *OAuthHandler {
 	return &OAuthHandler{
 		oauthService: oauthService,
 		userService:  userService,
+		tokens:       tokens,
 		jwtSecret:    jwtSecret,
 		logger:       logger,
 	}
@@ -288,34 +289,25 @@ func (h *OAuthHandler) HandleCallback(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	jwtToken, err := auth.GenerateToken(
-		userRecord.ID,
-		userRecord.Email,
-		roles,
-		uuid.Nil, // No org ID for now
-		permissions,
-		h.jwtSecret,
-		24, // 24 hour expiry
-	)
+	// Generate access and refresh tokens and open a session
+	pair, err := h.tokens.IssueTokenPair(c.Request.Context(), auth.Identity{
+		UserID:      userRecord.ID,
+		Email:       userRecord.Email,
+		Roles:       roles,
+		OrgID:       uuid.Nil, // No org ID for now
+		Permissions: permissions,
+	}, fmt.Sprintf("OAuth via %s", req.Provider), c.ClientIP())
 	if err != nil {
 		h.logger.Error("Failed to generate JWT token", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate authentication token"})
 		return
 	}
 
-	// Create session
-	session := auth.GetSessionStore().CreateSession(
-		userRecord.ID,
-		fmt.Sprintf("OAuth via %s", req.Provider),
-		c.ClientIP(),
-		jwtToken,
-		24*time.Hour,
-	)
-
 	response := dto.OAuth2CallbackResponse{
-		Token:     jwtToken,
-		ExpiresAt: session.ExpiresAt.Unix(),
+		Token:            pair.AccessToken,
+		ExpiresAt:        pair.AccessExpiresAt.Unix(),
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt.Unix(),
 		User: dto.UserResponse{
 			ID:          userRecord.ID,
 			Email:       userRecord.Email,
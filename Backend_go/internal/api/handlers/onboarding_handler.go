@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/onboarding"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OnboardingHandler handles HTTP requests for the organization onboarding wizard
+type OnboardingHandler struct {
+	service onboarding.Service
+}
+
+// NewOnboardingHandler creates a new OnboardingHandler instance
+func NewOnboardingHandler(service onboarding.Service) *OnboardingHandler {
+	return &OnboardingHandler{service: service}
+}
+
+// StartOnboarding godoc
+// @Summary Start organization onboarding
+// @Description Create the organization and begin tracking onboarding progress
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.StartOnboardingRequest true "Organization details"
+// @Success 201 {object} map[string]interface{} "Organization and onboarding progress"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Organization name already exists"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/onboarding [post]
+func (h *OnboardingHandler) StartOnboarding(c *gin.Context) {
+	var req dto.StartOnboardingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	org, progress, err := h.service.Start(c.Request.Context(), onboarding.StartInput{
+		Name:        req.Name,
+		Description: req.Description,
+		UserID:      userID,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": gin.H{
+		"organization": dto.OrganizationToResponse(org),
+		"progress":     dto.OnboardingProgressToResponse(progress),
+	}})
+}
+
+// ListTemplates godoc
+// @Summary List project templates
+// @Description Get the project templates offered by the onboarding wizard
+// @Tags onboarding
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Project templates"
+// @Router /api/onboarding/templates [get]
+func (h *OnboardingHandler) ListTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": onboarding.Templates})
+}
+
+// GetProgress godoc
+// @Summary Get onboarding progress
+// @Description Get the onboarding progress of an organization so the wizard can resume
+// @Tags onboarding
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {object} dto.OnboardingProgressResponse "Onboarding progress"
+// @Failure 400 {object} map[string]string "Invalid organization ID"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Onboarding progress not found"
+// @Router /api/onboarding/{id} [get]
+func (h *OnboardingHandler) GetProgress(c *gin.Context) {
+	orgID, userID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.service.GetProgress(c.Request.Context(), orgID, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.OnboardingProgressToResponse(progress)})
+}
+
+// InviteMembers godoc
+// @Summary Invite members during onboarding
+// @Description Send organization invitations to the email addresses and record them. Addresses already invited are skipped.
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param request body dto.InviteMembersRequest true "Emails to invite"
+// @Success 200 {object} dto.OnboardingProgressResponse "Updated onboarding progress"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Onboarding progress not found"
+// @Router /api/onboarding/{id}/invites [post]
+func (h *OnboardingHandler) InviteMembers(c *gin.Context) {
+	orgID, userID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.InviteMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	progress, err := h.service.InviteMembers(c.Request.Context(), orgID, userID, req.Emails)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.OnboardingProgressToResponse(progress)})
+}
+
+// ChooseTemplate godoc
+// @Summary Choose a project template
+// @Description Pick the project template used to seed the organization
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param request body dto.ChooseTemplateRequest true "Template key"
+// @Success 200 {object} dto.OnboardingProgressResponse "Updated onboarding progress"
+// @Failure 400 {object} map[string]string "Invalid request or unknown template"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Onboarding progress not found"
+// @Router /api/onboarding/{id}/template [post]
+func (h *OnboardingHandler) ChooseTemplate(c *gin.Context) {
+	orgID, userID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ChooseTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	progress, err := h.service.ChooseTemplate(c.Request.Context(), orgID, userID, req.Template)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.OnboardingProgressToResponse(progress)})
+}
+
+// SeedSamples godoc
+// @Summary Seed sample data
+// @Description Create the chosen template project with sample tasks and habits
+// @Tags onboarding
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {object} map[string]interface{} "Seeded data and updated progress"
+// @Failure 400 {object} map[string]string "No template chosen"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Onboarding progress not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/onboarding/{id}/seed [post]
+func (h *OnboardingHandler) SeedSamples(c *gin.Context) {
+	orgID, userID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	progress, result, err := h.service.SeedSamples(c.Request.Context(), orgID, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"seeded":   result,
+		"progress": dto.OnboardingProgressToResponse(progress),
+	}})
+}
+
+// CompleteStep godoc
+// @Summary Complete an onboarding step
+// @Description Mark a wizard step as completed, e.g. when it is skipped
+// @Tags onboarding
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param step path string true "Step name" Enums(create_organization, invite_members, choose_template, seed_samples, finish)
+// @Success 200 {object} dto.OnboardingProgressResponse "Updated onboarding progress"
+// @Failure 400 {object} map[string]string "Invalid step"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Onboarding progress not found"
+// @Router /api/onboarding/{id}/steps/{step}/complete [post]
+func (h *OnboardingHandler) CompleteStep(c *gin.Context) {
+	orgID, userID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.service.CompleteStep(c.Request.Context(), orgID, userID, onboarding.Step(c.Param("step")))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.OnboardingProgressToResponse(progress)})
+}
+
+// parseRequest extracts the organization ID path parameter and the authenticated user
+func (h *OnboardingHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, userID, true
+}
+
+// handleError maps onboarding errors to HTTP responses
+func (h *OnboardingHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, onboarding.ErrProgressNotFound), err == organization.ErrOrganizationNotFound:
+		statusCode = http.StatusNotFound
+	case errors.Is(err, onboarding.ErrNotAuthorized):
+		statusCode = http.StatusForbidden
+	case errors.Is(err, onboarding.ErrInvalidInput), errors.Is(err, onboarding.ErrInvalidStep),
+		errors.Is(err, onboarding.ErrInvalidTemplate), errors.Is(err, onboarding.ErrTemplateRequired),
+		err == organization.ErrInvalidInput, err == organization.ErrInvalidInvitation:
+		statusCode = http.StatusBadRequest
+	case err == organization.ErrDuplicateName:
+		statusCode = http.StatusConflict
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,536 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/orgunits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OrgUnitHandler handles HTTP requests for the departments and teams of organizations
+type OrgUnitHandler struct {
+	service orgunits.Service
+}
+
+// NewOrgUnitHandler creates a new OrgUnitHandler instance
+func NewOrgUnitHandler(service orgunits.Service) *OrgUnitHandler {
+	return &OrgUnitHandler{service: service}
+}
+
+// ListUnits godoc
+// @Summary List the units of the organization
+// @Description Get the departments, teams and other units of the organization as a tree
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {array} orgunits.Node "Units, top-level first"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units [get]
+func (h *OrgUnitHandler) ListUnits(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	nodes, err := h.service.ListUnits(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": nodes})
+}
+
+// CreateUnit godoc
+// @Summary Create a unit
+// @Description Create a department or team, directly under the organization or under another unit
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param request body dto.CreateOrgUnitRequest true "Unit"
+// @Success 201 {object} orgunits.Unit "Created unit"
+// @Failure 400 {object} map[string]string "Invalid name or units nested too deep"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Parent unit not found"
+// @Failure 409 {object} map[string]string "Name taken under the parent"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units [post]
+func (h *OrgUnitHandler) CreateUnit(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	var req dto.CreateOrgUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Creating a sub-unit needs the permission in the parent, which a unit role may grant
+	if req.ParentID != nil {
+		inherited, err := h.service.UnitPermissions(c.Request.Context(), orgID, *req.ParentID, callerID(c))
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+		if !middleware.AllowInherited(c, inherited, "organizations:update") {
+			return
+		}
+	} else if !middleware.AllowInherited(c, nil, "organizations:update") {
+		return
+	}
+
+	unit, err := h.service.CreateUnit(c.Request.Context(), orgID, orgunits.CreateUnitInput{
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": unit})
+}
+
+// GetUnit godoc
+// @Summary Get a unit
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param unit_id path string true "Unit ID" format(uuid)
+// @Success 200 {object} orgunits.Unit "Unit"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Unit not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units/{unit_id} [get]
+func (h *OrgUnitHandler) GetUnit(c *gin.Context) {
+	orgID, unitID, ok := h.parseUnit(c)
+	if !ok {
+		return
+	}
+
+	unit, err := h.service.GetUnit(c.Request.Context(), orgID, unitID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": unit})
+}
+
+// UpdateUnit godoc
+// @Summary Update a unit
+// @Description Rename a unit or move it, with the units and projects under it, to another parent. Moving it under another unit needs permission to update that unit too.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param unit_id path string true "Unit ID" format(uuid)
+// @Param request body dto.UpdateOrgUnitRequest true "Changes"
+// @Success 200 {object} orgunits.Unit "Updated unit"
+// @Failure 400 {object} map[string]string "Invalid name, a move under its own sub-unit, or units nested too deep"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Unit not found"
+// @Failure 409 {object} map[string]string "Name taken under the parent"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units/{unit_id} [put]
+func (h *OrgUnitHandler) UpdateUnit(c *gin.Context) {
+	orgID, unitID, ok := h.parseUnit(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateOrgUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The unit's own permissions are checked here rather than by a middleware, so that
+	// they do not count towards the parent it moves to
+	inherited, err := h.service.UnitPermissions(c.Request.Context(), orgID, unitID, callerID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if !middleware.AllowInherited(c, inherited, "organizations:update") {
+		return
+	}
+	if !h.allowMoveTo(c, orgID, req.ParentID, req.MoveToRoot, "organizations:update") {
+		return
+	}
+
+	unit, err := h.service.UpdateUnit(c.Request.Context(), orgID, unitID, orgunits.UpdateUnitInput{
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+		MoveToRoot:  req.MoveToRoot,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": unit})
+}
+
+// DeleteUnit godoc
+// @Summary Delete a unit
+// @Description Delete a unit and the roles given in it. Its sub-units and projects move up to its parent.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param unit_id path string true "Unit ID" format(uuid)
+// @Success 204 "Unit deleted"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Unit not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units/{unit_id} [delete]
+func (h *OrgUnitHandler) DeleteUnit(c *gin.Context) {
+	orgID, unitID, ok := h.parseUnit(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteUnit(c.Request.Context(), orgID, unitID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUnitMembers godoc
+// @Summary List the members of a unit
+// @Description List the users given a role in the unit itself. Roles given in the units above it apply too but are listed there.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param unit_id path string true "Unit ID" format(uuid)
+// @Success 200 {array} orgunits.Member "Members"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Unit not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units/{unit_id}/members [get]
+func (h *OrgUnitHandler) ListUnitMembers(c *gin.Context) {
+	orgID, unitID, ok := h.parseUnit(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListMembers(c.Request.Context(), orgID, unitID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// SetUnitMember godoc
+// @Summary Give a member a role in a unit
+// @Description Give a member of the organization a role in the unit. Its permissions apply in the unit, the units under it and their projects, on top of the member's organization role. The caller can only give roles whose permissions they hold themselves in the unit.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param unit_id path string true "Unit ID" format(uuid)
+// @Param user_id path string true "User ID" format(uuid)
+// @Param request body dto.OrgUnitMemberRequest true "Role"
+// @Success 200 {object} orgunits.Member "Membership"
+// @Failure 400 {object} map[string]string "Invalid user or role"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions, the role grants permissions the caller lacks, or the user is not a member of the organization"
+// @Failure 404 {object} map[string]string "Unit or role not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units/{unit_id}/members/{user_id} [put]
+func (h *OrgUnitHandler) SetUnitMember(c *gin.Context) {
+	orgID, unitID, ok := h.parseUnit(c)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req dto.OrgUnitMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var granted []string
+	if membership, ok := middleware.GetOrganizationMembership(c); ok {
+		granted = membership.Permissions
+	}
+
+	member, err := h.service.SetMember(c.Request.Context(), orgID, unitID, userID, req.Role, granted)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// RemoveUnitMember godoc
+// @Summary Remove a member's role in a unit
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param unit_id path string true "Unit ID" format(uuid)
+// @Param user_id path string true "User ID" format(uuid)
+// @Success 204 "Role removed"
+// @Failure 400 {object} map[string]string "Invalid user ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Unit not found or the user has no role in it"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units/{unit_id}/members/{user_id} [delete]
+func (h *OrgUnitHandler) RemoveUnitMember(c *gin.Context) {
+	orgID, unitID, ok := h.parseUnit(c)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), orgID, unitID, userID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUnitProjects godoc
+// @Summary List the projects of a unit
+// @Description List the projects in the unit, and with include_sub_units those of every unit under it
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param unit_id path string true "Unit ID" format(uuid)
+// @Param include_sub_units query bool false "Include the projects of the units under it"
+// @Param page query int false "Page number (default: 0)"
+// @Param pageSize query int false "Page size (default: 10)"
+// @Success 200 {object} dto.ProjectListResponse "Projects"
+// @Failure 400 {object} map[string]string "Invalid pagination parameters"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Unit not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units/{unit_id}/projects [get]
+func (h *OrgUnitHandler) ListUnitProjects(c *gin.Context) {
+	orgID, unitID, ok := h.parseUnit(c)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil || page < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+	includeSubUnits := c.Query("include_sub_units") == "true"
+
+	projects, total, err := h.service.ListProjects(c.Request.Context(), orgID, unitID, includeSubUnits, page, pageSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	responses := make([]dto.ProjectResponse, len(projects))
+	for i := range projects {
+		responses[i] = *dto.ProjectToResponse(&projects[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.ProjectListResponse{
+		Projects:   responses,
+		TotalCount: total,
+		Page:       page,
+		PageSize:   pageSize,
+	}})
+}
+
+// GetUnitReport godoc
+// @Summary Get the roll-up report of a unit
+// @Description Count the projects, tasks by status and members of the unit and of every unit under it, with the totals of each subtree
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param unit_id path string true "Unit ID" format(uuid)
+// @Success 200 {object} orgunits.Report "Report"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Unit not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/units/{unit_id}/report [get]
+func (h *OrgUnitHandler) GetUnitReport(c *gin.Context) {
+	orgID, unitID, ok := h.parseUnit(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.Report(c.Request.Context(), orgID, unitID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// MoveProjectToUnit godoc
+// @Summary Move a project to a unit
+// @Description Move the project into a unit, or out of any unit with a null unit_id. It needs permission to update projects both where the project is and where it goes; a role in a unit grants it there.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param request body dto.MoveProjectToUnitRequest true "Target unit"
+// @Success 200 {object} dto.ProjectResponse "Moved project"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project or unit not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/unit [put]
+func (h *OrgUnitHandler) MoveProjectToUnit(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var req dto.MoveProjectToUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := h.service.ProjectUnitPermissions(c.Request.Context(), orgID, projectID, callerID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if !middleware.AllowInherited(c, from, "projects:update") {
+		return
+	}
+	if !h.allowMoveTo(c, orgID, req.UnitID, req.UnitID == nil, "projects:update") {
+		return
+	}
+
+	proj, err := h.service.MoveProject(c.Request.Context(), orgID, projectID, req.UnitID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.ProjectToResponse(proj)})
+}
+
+// allowMoveTo checks the permission where something is moved: in the target unit, which
+// a unit role may grant, or in the organization when it moves out of every unit. It
+// responds and returns false when the caller lacks it. Nothing moving passes.
+func (h *OrgUnitHandler) allowMoveTo(c *gin.Context, orgID uuid.UUID, unitID *uuid.UUID, toRoot bool, permission string) bool {
+	switch {
+	case toRoot:
+		return middleware.AllowInherited(c, nil, permission)
+	case unitID != nil:
+		inherited, err := h.service.UnitPermissions(c.Request.Context(), orgID, *unitID, callerID(c))
+		if err != nil {
+			h.handleError(c, err)
+			return false
+		}
+		return middleware.AllowInherited(c, inherited, permission)
+	default:
+		return true
+	}
+}
+
+// parseUnit reads the organization context and the unit ID, answering the request when
+// either is missing
+func (h *OrgUnitHandler) parseUnit(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	unitID, err := uuid.Parse(c.Param("unit_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unit ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, unitID, true
+}
+
+// callerID is the user the organization membership was resolved for
+func callerID(c *gin.Context) uuid.UUID {
+	if membership, ok := middleware.GetOrganizationMembership(c); ok {
+		return membership.UserID
+	}
+	return uuid.Nil
+}
+
+func (h *OrgUnitHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, orgunits.ErrInvalidInput), errors.Is(err, orgunits.ErrCycle),
+		errors.Is(err, orgunits.ErrTooDeep):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, orgunits.ErrUnitNotFound), errors.Is(err, orgunits.ErrNotMember),
+		errors.Is(err, project.ErrProjectNotFound), errors.Is(err, roles.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, orgunits.ErrDuplicateName):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, organization.ErrNotMember), errors.Is(err, orgunits.ErrRoleTooBroad):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -256,7 +256,7 @@ func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
 	}
 
 	// Only the owner can update the organization
-	if org.OwnerID.String() != userID.(string) {
+	if org.OwnerID != userID.(uuid.UUID) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "only the organization owner can update it"})
 		return
 	}
@@ -277,6 +277,8 @@ func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
 			statusCode = http.StatusBadRequest
 		} else if err == organization.ErrDuplicateName {
 			statusCode = http.StatusConflict
+		} else if err == organization.ErrPendingDeletion {
+			statusCode = http.StatusLocked
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
@@ -326,7 +328,7 @@ func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
 	}
 
 	// Only the owner can delete the organization
-	if org.OwnerID.String() != userID.(string) {
+	if org.OwnerID != userID.(uuid.UUID) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "only the organization owner can delete it"})
 		return
 	}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// parseKeyset reads the cursor and limit query parameters of list endpoints. It returns a
+// nil keyset when neither is given, so the endpoint keeps its page and page size. On
+// invalid input it responds with 400 and returns false.
+func parseKeyset(c *gin.Context) (*pagination.Keyset, bool) {
+	cursor, hasCursor := c.GetQuery("cursor")
+	rawLimit, hasLimit := c.GetQuery("limit")
+	if !hasCursor && !hasLimit {
+		return nil, true
+	}
+
+	limit := 0
+	if rawLimit != "" {
+		var err error
+		if limit, err = strconv.Atoi(rawLimit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return nil, false
+		}
+	}
+	keyset, err := pagination.NewKeyset(cursor, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return keyset, true
+}
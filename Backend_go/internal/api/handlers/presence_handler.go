@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/presence"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxPresenceLookup = 100
+
+// PresenceHandler handles HTTP requests for user presence
+type PresenceHandler struct {
+	service             presence.Service
+	organizationService organization.Service
+}
+
+// NewPresenceHandler creates a new PresenceHandler instance. Presence is only shown
+// for members of the caller's organization.
+func NewPresenceHandler(service presence.Service, organizationService organization.Service) *PresenceHandler {
+	return &PresenceHandler{service: service, organizationService: organizationService}
+}
+
+// GetPresence godoc
+// @Summary Get user presence
+// @Description Get the online status and currently open task or project of the given members of the organization
+// @Tags presence
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param user_ids query string true "Comma-separated member IDs (max 100)"
+// @Success 200 {array} dto.UserPresenceResponse "User presence"
+// @Failure 400 {object} map[string]string "Invalid user IDs or not members of the organization"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not a member of the organization"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/presence [get]
+func (h *PresenceHandler) GetPresence(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return
+	}
+
+	var userIDs []uuid.UUID
+	for _, raw := range strings.Split(c.Query("user_ids"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID: " + raw})
+			return
+		}
+		userIDs = append(userIDs, id)
+	}
+	if len(userIDs) == 0 || len(userIDs) > maxPresenceLookup {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "between 1 and 100 user IDs are required"})
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	isMember := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+	for _, id := range userIDs {
+		if !isMember[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "not a member of the organization: " + id.String()})
+			return
+		}
+	}
+
+	presences, err := h.service.GetPresence(c.Request.Context(), userIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]dto.UserPresenceResponse, len(presences))
+	for i, p := range presences {
+		response[i] = dto.UserPresenceToResponse(p)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// GetViewers godoc
+// @Summary Get current viewers
+// @Description Get the users who currently have a task or project open
+// @Tags presence
+// @Produce json
+// @Security BearerAuth
+// @Param entity_type query string true "Entity type" Enums(task, project)
+// @Param entity_id query string true "Entity ID" format(uuid)
+// @Success 200 {array} dto.ViewerResponse "Current viewers"
+// @Failure 400 {object} map[string]string "Invalid entity"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/presence/viewers [get]
+func (h *PresenceHandler) GetViewers(c *gin.Context) {
+	entityID, err := uuid.Parse(c.Query("entity_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity ID"})
+		return
+	}
+
+	viewers, err := h.service.Viewers(c.Request.Context(), presence.Entity{Type: c.Query("entity_type"), ID: entityID})
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, presence.ErrInvalidEntity) {
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.ViewersToResponse(viewers)})
+}
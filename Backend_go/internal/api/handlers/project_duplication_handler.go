@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/projectcopy"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ProjectDuplicationHandler handles HTTP requests for copying projects
+type ProjectDuplicationHandler struct {
+	service projectcopy.Service
+}
+
+// NewProjectDuplicationHandler creates a new ProjectDuplicationHandler instance
+func NewProjectDuplicationHandler(service projectcopy.Service) *ProjectDuplicationHandler {
+	return &ProjectDuplicationHandler{service: service}
+}
+
+// DuplicateProject godoc
+// @Summary Duplicate a project
+// @Description Queue a copy of the project, optionally with its tasks and members. With a start date, the copy and the dates of its tasks move so the copy starts on that date. Copied tasks start out as Upcoming; assignees are only kept when the members are copied. Poll the returned duplication for progress; it carries the new project once it has completed.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param request body dto.DuplicateProjectRequest false "What to copy"
+// @Success 202 {object} projectcopy.Duplication "Queued duplication"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 409 {object} map[string]string "Project name already exists"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/duplicate [post]
+func (h *ProjectDuplicationHandler) DuplicateProject(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	var req dto.DuplicateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	duplication, err := h.service.StartDuplication(c.Request.Context(), orgID, projectID, userID, projectcopy.Options{
+		Name:           req.Name,
+		IncludeTasks:   req.IncludeTasks,
+		IncludeMembers: req.IncludeMembers,
+		StartDate:      req.StartDate,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": duplication})
+}
+
+// GetDuplication godoc
+// @Summary Get a project duplication
+// @Description Get the status and progress of a copy of the project, with the new project once it has completed
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param duplication_id path string true "Duplication ID" format(uuid)
+// @Success 200 {object} projectcopy.Duplication "Duplication"
+// @Failure 400 {object} map[string]string "Invalid project or duplication ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project or duplication not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/duplications/{duplication_id} [get]
+func (h *ProjectDuplicationHandler) GetDuplication(c *gin.Context) {
+	projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+	duplicationID, err := uuid.Parse(c.Param("duplication_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid duplication ID"})
+		return
+	}
+
+	duplication, err := h.service.GetDuplication(c.Request.Context(), projectID, duplicationID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": duplication})
+}
+
+func (h *ProjectDuplicationHandler) parseProject(c *gin.Context) (uuid.UUID, bool) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return uuid.Nil, false
+	}
+	return projectID, true
+}
+
+func (h *ProjectDuplicationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, projectcopy.ErrInvalidName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, project.ErrProjectNotFound), errors.Is(err, projectcopy.ErrDuplicationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, project.ErrProjectNameExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -133,6 +133,41 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": dto.ProjectToResponse(proj)})
 }
 
+// RequireProjectInOrganization rejects requests for a project outside the caller's
+// organization context
+func (h *ProjectHandler) RequireProjectInOrganization(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		c.Abort()
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		c.Abort()
+		return
+	}
+
+	proj, err := h.service.GetProject(c.Request.Context(), id)
+	if err != nil {
+		statuscode := http.StatusInternalServerError
+		if err == project.ErrProjectNotFound {
+			statuscode = http.StatusNotFound
+		}
+		c.JSON(statuscode, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+	if proj.OrganizationID != orgID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "project does not belong to the organization"})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
 // GetProjectDetails godoc
 // @Summary Get detailed project information
 // @Description Get project details including members and task counts
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/quickadd"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// QuickAddHandler handles HTTP requests for natural-language quick-add
+type QuickAddHandler struct {
+	service quickadd.Service
+}
+
+// NewQuickAddHandler creates a new QuickAddHandler instance
+func NewQuickAddHandler(service quickadd.Service) *QuickAddHandler {
+	return &QuickAddHandler{service: service}
+}
+
+// QuickAdd godoc
+// @Summary Quick-add an event or task
+// @Description Parse a text such as "Lunch with Omar tomorrow 1pm for 1h #personal" in the user's timezone. Dates, times, time ranges, durations after "for", #tags and priorities (!high, p1) are read deterministically and the rest is the title. Texts with a time become calendar events and others tasks, unless kind is given. Mode preview returns the parse; mode commit also creates the event, or the task in project_id.
+// @Tags quick-add
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.QuickAddRequest true "Text to parse"
+// @Success 200 {object} dto.QuickAddResponse "Parsed preview"
+// @Success 201 {object} dto.QuickAddResponse "Created event or task"
+// @Failure 400 {object} map[string]string "Invalid text, kind or mode"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions to create a task"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/quick-add [post]
+func (h *QuickAddHandler) QuickAdd(c *gin.Context) {
+	var req dto.QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Mode != "" && req.Mode != "preview" && req.Mode != "commit" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be preview or commit"})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	orgID, _ := c.Get("org_id")
+	organizationID, _ := orgID.(uuid.UUID)
+
+	result, err := h.service.QuickAdd(c.Request.Context(), quickadd.Input{
+		Text:           req.Text,
+		Kind:           quickadd.Kind(req.Kind),
+		Commit:         req.Mode == "commit",
+		ProjectID:      req.ProjectID,
+		UserID:         userID,
+		OrganizationID: organizationID,
+		Permissions:    permissionsFromContext(c),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dto.QuickAddResponse{
+		Parsed:    result.Parsed,
+		Committed: result.Committed,
+		Event:     result.Event,
+	}
+	if result.Task != nil {
+		response.Task = TaskToResponse(result.Task)
+	}
+	status := http.StatusOK
+	if result.Committed {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"data": response})
+}
+
+func (h *QuickAddHandler) handleError(c *gin.Context, err error) {
+	var calendarErr *calendar.Error
+	switch {
+	case errors.Is(err, quickadd.ErrEmptyText), errors.Is(err, quickadd.ErrTextTooLong),
+		errors.Is(err, quickadd.ErrNoTitle), errors.Is(err, quickadd.ErrInvalidKind),
+		errors.Is(err, quickadd.ErrProjectMissing), errors.Is(err, task.ErrInvalidInput),
+		errors.As(err, &calendarErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, quickadd.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, project.ErrProjectNotFound), errors.Is(err, user.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
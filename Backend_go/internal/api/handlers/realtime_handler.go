@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// RealtimeHandler upgrades connections to the realtime event stream
+type RealtimeHandler struct {
+	hub      *realtime.Hub
+	upgrader websocket.Upgrader
+	logger   *zap.Logger
+}
+
+// NewRealtimeHandler creates a new realtime handler
+func NewRealtimeHandler(hub *realtime.Hub, logger *zap.Logger) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub:    hub,
+		logger: logger,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				return true // Allow all origins in development
+			},
+		},
+	}
+}
+
+// Connect godoc
+// @Summary Stream entity changes
+// @Description Open a WebSocket that streams entity change events such as task.updated, todo.completed and workflow.step.transitioned.
+// @Description Events about the user's own items arrive without subscribing. Send {"command":"subscribe","organization_id":"..."} to receive an organization's events,
+// @Description {"command":"unsubscribe","organization_id":"..."} to stop, and {"command":"filter","events":["task.updated"]} to limit the event types.
+// @Description Organization events are limited like search: task events of the projects the member belongs to, or all of them for members who can update projects, plus tasks they created, are assigned or review. Membership is checked again every minute; members who left receive an unsubscribed message.
+// @Description Browsers may pass the access token in the token query parameter instead of the Authorization header.
+// @Tags realtime
+// @Param token query string false "JWT access token"
+// @Security BearerAuth
+// @Success 101 "Switching Protocols"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /ws [get]
+func (h *RealtimeHandler) Connect(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.Error("Failed to upgrade realtime connection", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+
+	h.hub.Serve(c.Request.Context(), conn, userID)
+}
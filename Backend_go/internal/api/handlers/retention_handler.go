@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/retention"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RetentionHandler handles HTTP requests for organization retention policies
+type RetentionHandler struct {
+	service retention.Service
+	logger  *zap.Logger
+}
+
+// NewRetentionHandler creates a new RetentionHandler instance
+func NewRetentionHandler(service retention.Service, logger *zap.Logger) *RetentionHandler {
+	return &RetentionHandler{service: service, logger: logger}
+}
+
+// ListPolicies godoc
+// @Summary List the retention policies of the organization
+// @Description List how long the organization keeps completed todos, closed tasks and audit log entries. Categories without a policy are listed disabled with their defaults: 30 days, 180 days and 365 days.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {array} retention.Policy "Retention policies"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/retention [get]
+func (h *RetentionHandler) ListPolicies(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	policies, err := h.service.ListPolicies(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policies})
+}
+
+// SetPolicy godoc
+// @Summary Set the retention policy of a category
+// @Description Set how long the organization keeps a category of data: completed todos are deleted, closed tasks archived and audit log entries purged once they are older than the retention. A nightly job tells the admins notice_days ahead, with a link to export what expires; changing the policy withdraws a notice already given.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param category path string true "completed_todos, closed_tasks or audit_logs"
+// @Param request body dto.RetentionPolicyRequest true "Retention policy"
+// @Success 200 {object} retention.Policy "Saved retention policy"
+// @Failure 400 {object} map[string]string "Invalid category, retention or notice"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/retention/{category} [put]
+func (h *RetentionHandler) SetPolicy(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	var req dto.RetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.service.SetPolicy(c.Request.Context(), orgID, retention.Category(c.Param("category")), retention.PolicyInput{
+		Enabled:       req.Enabled,
+		RetentionDays: req.RetentionDays,
+		NoticeDays:    req.NoticeDays,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
+
+// GetPending godoc
+// @Summary Get what a retention policy acts on next
+// @Description Count the data the policy of a category deletes, archives or purges on its next run: what the admins were told about, or what they will be told about next. With format=csv that data, up to 100000 rows, is exported as CSV instead.
+// @Tags organizations
+// @Produce json,text/csv
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param category path string true "completed_todos, closed_tasks or audit_logs"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} retention.Pending "Pending data"
+// @Failure 400 {object} map[string]string "Invalid category or format"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 409 {object} map[string]string "Policy not enabled"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/retention/{category}/pending [get]
+func (h *RetentionHandler) GetPending(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	category := retention.Category(c.Param("category"))
+	pending, err := h.service.Pending(c.Request.Context(), orgID, category)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{"data": pending})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.csv"`, category, orgID))
+	c.Status(http.StatusOK)
+	if err := h.service.ExportCSV(c.Request.Context(), orgID, category, pending.Before, c.Writer); err != nil {
+		// The header is already sent, so the client only sees a truncated file
+		h.logger.Error("Failed to export expiring data",
+			zap.String("organization_id", orgID.String()),
+			zap.String("category", string(category)),
+			zap.Error(err))
+	}
+}
+
+// ScheduleDeletion godoc
+// @Summary Schedule the organization for deletion
+// @Description Schedule the organization and all its data to be deleted after a 30 day grace period. Until then the organization is read-only and members are told when it goes; the owner can cancel the deletion. Members keep their personal todos, habits and calendars.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 202 {object} retention.Deletion "Scheduled deletion"
+// @Failure 400 {object} map[string]string "Invalid organization ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not the organization owner"
+// @Failure 404 {object} map[string]string "Organization not found"
+// @Failure 409 {object} map[string]string "Deletion already scheduled"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/deletion [post]
+func (h *RetentionHandler) ScheduleDeletion(c *gin.Context) {
+	orgID, userID, ok := deletionRequest(c)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.service.ScheduleDeletion(c.Request.Context(), orgID, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": deletion})
+}
+
+// GetDeletion godoc
+// @Summary Get the scheduled deletion of the organization
+// @Description Get when the organization, read-only until then, is deleted and who asked for it
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {object} retention.Deletion "Scheduled deletion"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not a member"
+// @Failure 404 {object} map[string]string "No deletion scheduled"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/deletion [get]
+func (h *RetentionHandler) GetDeletion(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	deletion, err := h.service.GetDeletion(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": deletion})
+}
+
+// CancelDeletion godoc
+// @Summary Cancel the deletion of the organization
+// @Description Cancel a scheduled deletion before the grace period ends. The organization accepts changes again and members are told.
+// @Tags organizations
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 204 "Deletion cancelled"
+// @Failure 400 {object} map[string]string "Invalid organization ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not the organization owner"
+// @Failure 404 {object} map[string]string "Organization not found or no deletion scheduled"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/deletion [delete]
+func (h *RetentionHandler) CancelDeletion(c *gin.Context) {
+	orgID, userID, ok := deletionRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelDeletion(c.Request.Context(), orgID, userID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// deletionRequest reads the organization and the caller of a request that schedules or
+// cancels a deletion. These routes skip the organization context, which would reject
+// them while the organization is read-only; the service checks the caller is the owner.
+func deletionRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, userID, true
+}
+
+func (h *RetentionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, retention.ErrInvalidCategory), errors.Is(err, retention.ErrInvalidPolicy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, retention.ErrPolicyDisabled), errors.Is(err, retention.ErrDeletionScheduled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, retention.ErrNotOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, retention.ErrDeletionNotScheduled), errors.Is(err, organization.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SearchHandler handles HTTP requests for full-text search
+type SearchHandler struct {
+	service search.Service
+}
+
+// NewSearchHandler creates a new SearchHandler instance
+func NewSearchHandler(service search.Service) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// Search godoc
+// @Summary Search
+// @Description Full-text search across tasks, todos, calendar events, habits and projects, best match first. Supports quoted phrases, "or" and "-" to exclude words. Words are stemmed and stop words dropped in the language of the rows searched: the organization setting search.language for tasks and projects, the language of the caller's locale for their own todos, events and habits. Tasks and projects of the caller's organization are included as far as their role allows: members with read access find those of the projects they belong to, and members who can update projects find all of them. Organizations with search auditing enabled record the query.
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param types query string false "Comma-separated result types: task, todo, event, habit, project"
+// @Param limit query int false "Maximum number of results" default(20)
+// @Success 200 {array} search.Result "Search results"
+// @Failure 400 {object} map[string]string "Invalid query"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	query, ok := searchQuery(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.service.Search(c.Request.Context(), query)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// SemanticSearch godoc
+// @Summary Semantic search
+// @Description Finds tasks and todos by meaning rather than exact words, e.g. "that task about the contract renewal". Results are ranked by embedding similarity blended with the full-text rank. Needs an LLM provider with embeddings and the pgvector extension.
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param types query string false "Comma-separated result types: task, todo"
+// @Param limit query int false "Maximum number of results" default(20)
+// @Success 200 {array} search.Result "Search results"
+// @Failure 400 {object} map[string]string "Invalid query"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 503 {object} map[string]string "Semantic search is not available"
+// @Router /api/search/semantic [get]
+func (h *SearchHandler) SemanticSearch(c *gin.Context) {
+	query, ok := searchQuery(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.service.SemanticSearch(c.Request.Context(), query)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// ListLanguages godoc
+// @Summary List search languages
+// @Description List the languages organizations can set as search.language. Each has its own stemmer and stop words, except simple, which only lowercases words and is used for locales without a stemmer.
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} string "Search languages"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/search/languages [get]
+func (h *SearchHandler) ListLanguages(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": search.SupportedLanguages()})
+}
+
+// ReindexPersonal godoc
+// @Summary Reindex the caller's items for search
+// @Description Rebuild the search index of the caller's todos, calendar events and habits in the language of their current locale. This happens in the background within minutes of a locale change; call this to apply it right away.
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]int64 "Number of reindexed items"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/search/reindex [post]
+func (h *SearchHandler) ReindexPersonal(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	reindexed, err := h.service.Reindex(c.Request.Context(), search.ReindexScope{UserID: &userID})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"reindexed": reindexed}})
+}
+
+// ReindexOrganization godoc
+// @Summary Reindex the organization for search
+// @Description Rebuild the search index of the organization's tasks and projects in its search.language setting. This happens in the background within minutes of a change to the setting; call this to apply it right away.
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {object} map[string]int64 "Number of reindexed items"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/search-reindex [post]
+func (h *SearchHandler) ReindexOrganization(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	reindexed, err := h.service.Reindex(c.Request.Context(), search.ReindexScope{OrganizationID: &orgID})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"reindexed": reindexed}})
+}
+
+// searchQuery reads the search query of the request, writing the error response when it is invalid
+func searchQuery(c *gin.Context) (search.Query, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return search.Query{}, false
+	}
+
+	query := search.Query{
+		Text:   c.Query("q"),
+		UserID: userID,
+	}
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			query.Types = append(query.Types, search.ResultType(strings.TrimSpace(t)))
+		}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return search.Query{}, false
+		}
+		query.Limit = limit
+	}
+	if membership, ok := middleware.GetOrganizationMembership(c); ok {
+		query.OrganizationID = &membership.OrganizationID
+		query.Access = searchAccess(membership)
+	}
+
+	return query, true
+}
+
+// searchAccess is what a member's role lets them find in their organization
+func searchAccess(membership *organization.Membership) search.Access {
+	return search.Access{
+		Tasks:       membership.HasPermission("tasks:read"),
+		Projects:    membership.HasPermission("projects:read"),
+		AllProjects: membership.HasPermission("projects:update"),
+	}
+}
+
+// ListAudit godoc
+// @Summary List the search audit trail
+// @Description List the searches members ran in the organization, newest first. Searches are only recorded while the organization setting search.audit is true.
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Number of items per page" default(20)
+// @Param user_id query string false "Only searches by this user" format(uuid)
+// @Param since query string false "Only searches at or after this time (RFC3339)"
+// @Param until query string false "Only searches before this time (RFC3339)"
+// @Success 200 {array} search.AuditEntry "Audit entries"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/search-audit [get]
+func (h *SearchHandler) ListAudit(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	var filter search.AuditFilter
+	var err error
+	if filter.Page, err = strconv.Atoi(c.DefaultQuery("page", "0")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+	if filter.PageSize, err = strconv.Atoi(c.DefaultQuery("pageSize", "20")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		filter.UserID = &userID
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since, expected RFC3339"})
+			return
+		}
+		filter.Since = &t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until, expected RFC3339"})
+			return
+		}
+		filter.Until = &t
+	}
+
+	entries, total, err := h.service.ListAudit(c.Request.Context(), orgID, filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries, "total": total})
+}
+
+// handleError maps search errors to HTTP responses
+func (h *SearchHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, search.ErrInvalidQuery), errors.Is(err, search.ErrInvalidType):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, search.ErrSemanticUnavailable):
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
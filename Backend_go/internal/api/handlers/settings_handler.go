@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/settings"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SettingsHandler handles HTTP requests for organization default settings and project
+// overrides
+type SettingsHandler struct {
+	service settings.Service
+	clocks  sla.Service
+}
+
+// NewSettingsHandler creates a new SettingsHandler instance. The SLA service reports the
+// clock projects chose for themselves.
+func NewSettingsHandler(service settings.Service, clocks sla.Service) *SettingsHandler {
+	return &SettingsHandler{service: service, clocks: clocks}
+}
+
+// GetOrganizationDefaults godoc
+// @Summary Get the default settings of the organization
+// @Description Get the task statuses, default priority, SLA defaults and board layout the organization's projects and workflows inherit. Settings it has not set come from the system defaults.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {object} settings.OrganizationDefaults "Organization defaults"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/settings/defaults [get]
+func (h *SettingsHandler) GetOrganizationDefaults(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	defaults, err := h.service.GetOrganizationDefaults(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": defaults})
+}
+
+// SetOrganizationDefaults godoc
+// @Summary Set the default settings of the organization
+// @Description Replace the settings every project and workflow of the organization inherits. Projects use them unless they override them; new workflows get the default deadline, and projects without a clock use the default clock.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param request body dto.SettingsRequest true "Default settings"
+// @Success 200 {object} settings.OrganizationDefaults "Saved organization defaults"
+// @Failure 400 {object} map[string]string "Invalid statuses, priority, SLA defaults or board layout"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/settings/defaults [put]
+func (h *SettingsHandler) SetOrganizationDefaults(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.SettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	defaults, err := h.service.SetOrganizationDefaults(c.Request.Context(), orgID, userID, settingsFromRequest(req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": defaults})
+}
+
+// GetProjectSettings godoc
+// @Summary Get the settings a project overrides
+// @Description Get the task statuses, default priority and board layout the project sets instead of inheriting them from its organization
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} settings.ProjectOverrides "Project overrides"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/settings [get]
+func (h *SettingsHandler) GetProjectSettings(c *gin.Context) {
+	orgID, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.service.GetProjectOverrides(c.Request.Context(), orgID, projectID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": overrides})
+}
+
+// SetProjectSettings godoc
+// @Summary Set the settings a project overrides
+// @Description Replace the overrides of the project. Omitted settings are inherited from the organization. Custom statuses replace the built-in statuses with the project's own, each mapped to a built-in status of its category, with optional transitions between them. SLA defaults cannot be overridden here; set the project clock instead.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param request body dto.SettingsRequest true "Project overrides"
+// @Success 200 {object} settings.ProjectOverrides "Saved project overrides"
+// @Failure 400 {object} map[string]string "Invalid statuses, priority or board layout"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/settings [put]
+func (h *SettingsHandler) SetProjectSettings(c *gin.Context) {
+	orgID, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.SettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	overrides, err := h.service.SetProjectOverrides(c.Request.Context(), orgID, projectID, userID, settingsFromRequest(req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": overrides})
+}
+
+// ResolveProjectSettings godoc
+// @Summary Resolve the effective settings of a project
+// @Description Get the settings the project actually uses, after applying its overrides over the organization defaults and the system defaults. Sources names the level each setting comes from: system, organization or project.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} settings.Resolved "Effective settings"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/settings/resolved [get]
+func (h *SettingsHandler) ResolveProjectSettings(c *gin.Context) {
+	orgID, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	resolved, err := h.service.Resolve(c.Request.Context(), orgID, projectID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// The project clock is kept by the SLA service
+	clock, err := h.clocks.GetProjectClock(c.Request.Context(), orgID, projectID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if !clock.Inherited {
+		resolved.SLA.Clock = clock.Mode
+		resolved.Sources[settings.KeySLAClock] = settings.SourceProject
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resolved})
+}
+
+// parseProject reads the organization context and the project ID, answering the
+// request when either is missing
+func (h *SettingsHandler) parseProject(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, projectID, true
+}
+
+func (h *SettingsHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, settings.ErrInvalidStatus), errors.Is(err, settings.ErrInvalidPriority),
+		errors.Is(err, settings.ErrInvalidBoard), errors.Is(err, settings.ErrInvalidSLA),
+		errors.Is(err, settings.ErrProjectSLA), errors.Is(err, settings.ErrStatusConflict),
+		errors.Is(err, task.ErrInvalidWorkflow):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func settingsFromRequest(req dto.SettingsRequest) settings.Settings {
+	s := settings.Settings{
+		DefaultPriority: task.TaskPriority(req.DefaultPriority),
+	}
+	for _, status := range req.EnabledStatuses {
+		s.EnabledStatuses = append(s.EnabledStatuses, task.TaskStatus(status))
+	}
+	if req.CustomStatuses != nil {
+		workflow := &task.StatusWorkflow{Transitions: req.CustomStatuses.Transitions}
+		for _, cs := range req.CustomStatuses.Statuses {
+			workflow.Statuses = append(workflow.Statuses, task.CustomStatus{
+				Key:      cs.Key,
+				Name:     cs.Name,
+				Category: task.StatusCategory(cs.Category),
+				Status:   task.TaskStatus(cs.Status),
+			})
+		}
+		s.CustomStatuses = workflow
+	}
+	if req.SLA != nil {
+		s.SLA = &settings.SLADefaults{
+			Clock:                 sla.ClockMode(req.SLA.Clock),
+			WorkflowDeadlineHours: req.SLA.WorkflowDeadlineHours,
+		}
+	}
+	if req.Board != nil {
+		board := &settings.BoardConfig{Swimlanes: settings.Swimlane(req.Board.Swimlanes)}
+		for _, status := range req.Board.Columns {
+			board.Columns = append(board.Columns, task.TaskStatus(status))
+		}
+		if len(req.Board.WIPLimits) > 0 {
+			board.WIPLimits = make(map[task.TaskStatus]int, len(req.Board.WIPLimits))
+			for status, limit := range req.Board.WIPLimits {
+				board.WIPLimits[task.TaskStatus(status)] = limit
+			}
+		}
+		s.Board = board
+	}
+	return s
+}
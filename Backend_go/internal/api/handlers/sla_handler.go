@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SLAHandler handles HTTP requests for working hours and project clocks
+type SLAHandler struct {
+	service sla.Service
+}
+
+// NewSLAHandler creates a new SLAHandler instance
+func NewSLAHandler(service sla.Service) *SLAHandler {
+	return &SLAHandler{service: service}
+}
+
+// GetWorkingHours godoc
+// @Summary Get the working hours of the organization
+// @Description Get the working week and holidays that projects on the business clock count. Organizations that have not set them work 09:00 to 17:00 UTC, Monday to Friday.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {object} sla.Schedule "Working hours"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/working-hours [get]
+func (h *SLAHandler) GetWorkingHours(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": schedule})
+}
+
+// SetWorkingHours godoc
+// @Summary Set the working hours of the organization
+// @Description Replace the working week and holiday calendar of the organization. Projects on the business clock only count these hours when checking due dates and inactivity.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param request body dto.WorkingHoursRequest true "Working hours and holidays"
+// @Success 200 {object} sla.Schedule "Saved working hours"
+// @Failure 400 {object} map[string]string "Invalid time zone, hours, days or holidays"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/working-hours [put]
+func (h *SLAHandler) SetWorkingHours(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	var req dto.WorkingHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := sla.ScheduleInput{
+		Timezone:     req.Timezone,
+		WorkdayStart: req.WorkdayStart,
+		WorkdayEnd:   req.WorkdayEnd,
+		Days:         req.Days,
+		Holidays:     make([]sla.HolidayInput, len(req.Holidays)),
+	}
+	for i, holiday := range req.Holidays {
+		input.Holidays[i] = sla.HolidayInput{Date: holiday.Date, Name: holiday.Name}
+	}
+
+	schedule, err := h.service.SetSchedule(c.Request.Context(), orgID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": schedule})
+}
+
+// GetProjectClock godoc
+// @Summary Get the clock of a project
+// @Description Get whether the project measures due-soon and inactivity windows in wall time or in the organization's working hours
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} sla.ProjectClock "Project clock"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/clock [get]
+func (h *SLAHandler) GetProjectClock(c *gin.Context) {
+	orgID, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	clock, err := h.service.GetProjectClock(c.Request.Context(), orgID, projectID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": clock})
+}
+
+// SetProjectClock godoc
+// @Summary Set the clock of a project
+// @Description Choose the wall clock, which counts every hour, or the business clock, which counts only the organization's working hours and skips holidays. Risk analysis, project health and my work all use the project's clock.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param request body dto.ProjectClockRequest true "Clock"
+// @Success 200 {object} sla.ProjectClock "Saved project clock"
+// @Failure 400 {object} map[string]string "Invalid clock"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/clock [put]
+func (h *SLAHandler) SetProjectClock(c *gin.Context) {
+	orgID, projectID, ok := h.parseProject(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ProjectClockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clock, err := h.service.SetProjectClock(c.Request.Context(), orgID, projectID, sla.ClockMode(req.Mode))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": clock})
+}
+
+// parseProject reads the organization context and the project ID, answering the
+// request when either is missing
+func (h *SLAHandler) parseProject(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, projectID, true
+}
+
+func (h *SLAHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sla.ErrInvalidSchedule), errors.Is(err, sla.ErrInvalidHoliday),
+		errors.Is(err, sla.ErrTooManyHolidays), errors.Is(err, sla.ErrInvalidClock):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/tags"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TagHandler handles HTTP requests for the tags of organizations and the entities carrying them
+type TagHandler struct {
+	service tags.Service
+}
+
+// NewTagHandler creates a new TagHandler instance
+func NewTagHandler(service tags.Service) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// ListTags godoc
+// @Summary List the tags of the organization
+// @Description Get the tags of the organization by name, with the number of entities carrying each
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Success 200 {array} tags.TagUsage "Tags"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/tags [get]
+func (h *TagHandler) ListTags(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	list, err := h.service.ListTags(c.Request.Context(), orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// CreateTag godoc
+// @Summary Create a tag
+// @Description Create a tag members can put on their tasks, todos, events, meeting notes and projects. Names are unique regardless of case.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param request body dto.CreateTagRequest true "Tag"
+// @Success 201 {object} tags.Tag "Created tag"
+// @Failure 400 {object} map[string]string "Invalid name or color"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 409 {object} map[string]string "Name taken"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/tags [post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	membership, ok := middleware.GetOrganizationMembership(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	var req dto.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.service.CreateTag(c.Request.Context(), membership.OrganizationID, membership.UserID, tags.CreateTagInput{
+		Name:  req.Name,
+		Color: req.Color,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": tag})
+}
+
+// UpdateTag godoc
+// @Summary Rename or recolor a tag
+// @Description Every entity carrying the tag shows the new name at once. Renaming to the name of another tag is refused; merge the tags instead.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param tag_id path string true "Tag ID" format(uuid)
+// @Param request body dto.UpdateTagRequest true "Changes"
+// @Success 200 {object} tags.Tag "Updated tag"
+// @Failure 400 {object} map[string]string "Invalid name or color"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Tag not found"
+// @Failure 409 {object} map[string]string "Name taken"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/tags/{tag_id} [put]
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	orgID, tagID, ok := h.parseTag(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.service.UpdateTag(c.Request.Context(), orgID, tagID, tags.UpdateTagInput{
+		Name:  req.Name,
+		Color: req.Color,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// DeleteTag godoc
+// @Summary Delete a tag
+// @Description Delete a tag and remove it from every entity carrying it
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param tag_id path string true "Tag ID" format(uuid)
+// @Success 204 "Tag deleted"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Tag not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/tags/{tag_id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	orgID, tagID, ok := h.parseTag(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTag(c.Request.Context(), orgID, tagID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MergeTags godoc
+// @Summary Merge tags into a tag
+// @Description Put the tag on every entity carrying one of the source tags, then delete the sources
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID" format(uuid)
+// @Param tag_id path string true "ID of the tag merged into" format(uuid)
+// @Param request body dto.MergeTagsRequest true "Tags to merge"
+// @Success 200 {object} tags.MergeResult "Merge result"
+// @Failure 400 {object} map[string]string "No source tags, or the tag itself among them"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Tag not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/organizations/{id}/tags/{tag_id}/merge [post]
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	orgID, tagID, ok := h.parseTag(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.MergeTags(c.Request.Context(), orgID, tagID, req.SourceIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// GetEntityTags godoc
+// @Summary Get the tags of an entity
+// @Description Get the tags of the organization on a task, todo, event, meeting note or project the caller can see
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID" format(uuid)
+// @Param type path string true "Entity type" Enums(task, todo, event, note, project)
+// @Param entity_id path string true "Entity ID" format(uuid)
+// @Success 200 {array} tags.Tag "Tags"
+// @Failure 400 {object} map[string]string "Invalid entity type or ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Entity not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tags/entities/{type}/{entity_id} [get]
+func (h *TagHandler) GetEntityTags(c *gin.Context) {
+	viewer, ref, ok := h.parseEntity(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.EntityTags(c.Request.Context(), viewer, ref)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// SetEntityTags godoc
+// @Summary Set the tags of an entity
+// @Description Replace the tags of the organization on a task, todo, event, meeting note or project the caller can see
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID" format(uuid)
+// @Param type path string true "Entity type" Enums(task, todo, event, note, project)
+// @Param entity_id path string true "Entity ID" format(uuid)
+// @Param request body dto.SetEntityTagsRequest true "Tags"
+// @Success 200 {array} tags.Tag "Tags of the entity"
+// @Failure 400 {object} map[string]string "Invalid entity type or too many tags"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Entity or tag not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tags/entities/{type}/{entity_id} [put]
+func (h *TagHandler) SetEntityTags(c *gin.Context) {
+	viewer, ref, ok := h.parseEntity(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SetEntityTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.service.SetEntityTags(c.Request.Context(), viewer, ref, req.TagIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// SearchTagged godoc
+// @Summary Find entities by tag
+// @Description Find the tasks, todos, events, meeting notes and projects the caller can see that carry any of the tags, or all of them with match=all. Most recently updated first.
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID" format(uuid)
+// @Param tags query string true "Comma-separated tag names"
+// @Param match query string false "any (default) or all"
+// @Param types query string false "Comma-separated entity types (default: all)"
+// @Param page query int false "Page number (default: 0)"
+// @Param pageSize query int false "Page size (default: 20)"
+// @Success 200 {object} tags.SearchResult "Tagged entities"
+// @Failure 400 {object} map[string]string "Invalid tags, types or pagination"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tags/search [get]
+func (h *TagHandler) SearchTagged(c *gin.Context) {
+	viewer, ok := tagViewer(c)
+	if !ok {
+		return
+	}
+
+	query := tags.SearchQuery{MatchAll: c.Query("match") == "all"}
+	if raw := c.Query("tags"); raw != "" {
+		query.Tags = strings.Split(raw, ",")
+	}
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			query.Types = append(query.Types, tags.EntityType(strings.TrimSpace(t)))
+		}
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil || page < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(tags.DefaultLimit)))
+	if err != nil || pageSize < 1 || pageSize > tags.MaxLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+	query.Limit = pageSize
+	query.Offset = page * pageSize
+
+	result, err := h.service.Search(c.Request.Context(), viewer, query)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// tagViewer is the caller with what their role lets them see of the organization
+func tagViewer(c *gin.Context) (tags.Viewer, bool) {
+	membership, ok := middleware.GetOrganizationMembership(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return tags.Viewer{}, false
+	}
+	return tags.Viewer{
+		UserID:         membership.UserID,
+		OrganizationID: membership.OrganizationID,
+		Access:         searchAccess(membership),
+	}, true
+}
+
+func (h *TagHandler) parseEntity(c *gin.Context) (tags.Viewer, tags.EntityRef, bool) {
+	viewer, ok := tagViewer(c)
+	if !ok {
+		return tags.Viewer{}, tags.EntityRef{}, false
+	}
+	entityType := tags.EntityType(c.Param("type"))
+	if !entityType.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tags.ErrInvalidEntityType.Error()})
+		return tags.Viewer{}, tags.EntityRef{}, false
+	}
+	entityID, err := uuid.Parse(c.Param("entity_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity ID"})
+		return tags.Viewer{}, tags.EntityRef{}, false
+	}
+	return viewer, tags.EntityRef{Type: entityType, ID: entityID}, true
+}
+
+func (h *TagHandler) parseTag(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	tagID, err := uuid.Parse(c.Param("tag_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, tagID, true
+}
+
+func (h *TagHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, tags.ErrInvalidInput), errors.Is(err, tags.ErrInvalidEntityType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, tags.ErrTagNotFound), errors.Is(err, tags.ErrEntityNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, tags.ErrDuplicateName):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
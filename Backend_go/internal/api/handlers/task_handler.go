@@ -1,26 +1,86 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/apierror"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/attachments"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/presence"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/plugins"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 // TaskHandler handles HTTP requests for task operations
 type TaskHandler struct {
-	service task.Service
+	service     task.Service
+	presence    presence.Service
+	attachments attachments.Service
+	users       user.Service
 }
 
 // NewTaskHandler creates a new TaskHandler instance
-func NewTaskHandler(service task.Service) *TaskHandler {
-	return &TaskHandler{service: service}
+func NewTaskHandler(service task.Service, presenceService presence.Service) *TaskHandler {
+	return &TaskHandler{service: service, presence: presenceService}
+}
+
+// WithAttachments lists the attachments of tasks and comments in their responses
+func (h *TaskHandler) WithAttachments(attachmentService attachments.Service) *TaskHandler {
+	h.attachments = attachmentService
+	return h
+}
+
+// WithUsers ranks tasks by the priority weights in each caller's preferences; without
+// it the default weights are used
+func (h *TaskHandler) WithUsers(userService user.Service) *TaskHandler {
+	h.users = userService
+	return h
+}
+
+// priorityWeights returns the caller's priority weights
+func (h *TaskHandler) priorityWeights(ctx context.Context, userID uuid.UUID) (task.PriorityWeights, error) {
+	if h.users == nil {
+		return task.DefaultPriorityWeights(), nil
+	}
+	u, err := h.users.GetUser(ctx, userID)
+	if err != nil {
+		return task.PriorityWeights{}, err
+	}
+	return task.PriorityWeightsFromPreferences(user.ResolvePreferences(u.Preferences)), nil
+}
+
+// parseTaskSort reads the sort query parameter. Sorting by priority score ranks with the
+// caller's weights, which are returned with it.
+func (h *TaskHandler) parseTaskSort(c *gin.Context) (task.TaskSort, *task.PriorityWeights, bool) {
+	sort := task.TaskSort(c.Query("sort"))
+	if !sort.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be priority_score"})
+		return "", nil, false
+	}
+	if sort != task.TaskSortPriorityScore {
+		return sort, nil, true
+	}
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return "", nil, false
+	}
+	weights, err := h.priorityWeights(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", nil, false
+	}
+	return sort, &weights, true
 }
 
 // CreateTask godoc
@@ -35,6 +95,7 @@ func NewTaskHandler(service task.Service) *TaskHandler {
 // @Failure 400 {object} map[string]string "Invalid request"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 422 {object} map[string]string "Rejected by a plugin"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
@@ -53,7 +114,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	} else {
 		// If validation middleware didn't run, do manual binding
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apierror.Respond(c, apierror.Validation(err))
 			return
 		}
 	}
@@ -65,13 +126,17 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	// Convert and validate status
-	status := task.TaskStatus(req.Status)
-	if !status.IsValid() {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status value"})
-		return
+	// Tasks are created in the organization the caller is acting in
+	if orgID, ok := middleware.GetOrganizationID(c); ok {
+		if req.OrganizationID != orgID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "organization_id does not match the organization context"})
+			return
+		}
 	}
 
+	// The service validates the status, which may be one of the project's custom statuses
+	status := task.TaskStatus(req.Status)
+
 	// Convert and validate priority
 	priority := task.TaskPriority(req.Priority)
 	if !priority.IsValid() {
@@ -101,10 +166,13 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	createdTask, err := h.service.CreateTask(c.Request.Context(), input)
 	if err != nil {
 		statuscode := http.StatusInternalServerError
-		if err == task.ErrInvalidInput {
+		if err == task.ErrInvalidInput || err == task.ErrInvalidParent || err == task.ErrTaskCycle ||
+			err == task.ErrInvalidDependency || err == task.ErrDependencyCycle || err == task.ErrStatusDisabled {
 			statuscode = http.StatusBadRequest
 		} else if err == task.ErrInvalidCreator {
 			statuscode = http.StatusForbidden
+		} else if errors.Is(err, plugins.ErrRejected) {
+			statuscode = http.StatusUnprocessableEntity
 		}
 		c.JSON(statuscode, gin.H{"error": err.Error()})
 		return
@@ -144,7 +212,66 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": TaskToResponse(tsk)})
+	response := TaskToResponse(tsk)
+	h.attachPresence(c.Request.Context(), response)
+	if h.attachments != nil {
+		list, err := h.attachments.List(c.Request.Context(), attachments.OwnerTask, tsk.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response.Attachments = AttachmentsToResponse(list, taskAttachmentsPath(tsk.ID))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// RequireTaskInOrganization rejects requests for a task outside the caller's organization
+// context. Tasks in other organizations are reported as not found.
+func (h *TaskHandler) RequireTaskInOrganization(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.Next()
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		c.Abort()
+		return
+	}
+
+	tsk, err := h.service.GetTask(c.Request.Context(), id)
+	if err != nil {
+		statuscode := http.StatusInternalServerError
+		if err == task.ErrTaskNotFound {
+			statuscode = http.StatusNotFound
+		}
+		c.JSON(statuscode, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+	if tsk.OrganizationID != orgID {
+		c.JSON(http.StatusNotFound, gin.H{"error": task.ErrTaskNotFound.Error()})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// attachPresence adds the task's current viewers and editors to the response
+func (h *TaskHandler) attachPresence(ctx context.Context, response *dto.TaskResponse) {
+	if h.presence == nil {
+		return
+	}
+	entity := presence.Entity{Type: presence.EntityTask, ID: response.ID}
+	if viewers, err := h.presence.Viewers(ctx, entity); err == nil {
+		response.Viewers = dto.ViewersToResponse(viewers)
+	}
+	if editors, err := h.presence.Editors(ctx, entity); err == nil && len(editors) > 0 {
+		response.Editors = dto.EditorsToResponse(editors)
+	}
 }
 
 // ListTasks godoc
@@ -156,6 +283,8 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number (default: 0)"
 // @Param pageSize query int false "Number of items per page (default: 10)"
+// @Param cursor query string false "next_cursor from the previous page; with cursor or limit, tasks are paged oldest first and total_count is not counted"
+// @Param limit query int false "Number of items per cursor page (default: 20, max: 100)"
 // @Param organization_id query string false "Filter by organization ID"
 // @Param project_id query string false "Filter by project ID"
 // @Param status query string false "Filter by status"
@@ -163,8 +292,10 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 // @Param assignee_id query string false "Filter by assignee ID"
 // @Param creator_id query string false "Filter by creator ID"
 // @Param reviewer_id query string false "Filter by reviewer ID"
+// @Param archived query bool false "Also list closed tasks archived by the organization's retention policy"
+// @Param sort query string false "priority_score ranks the highest priority score first, under the caller's task_priority weights; it cannot be combined with cursor paging" Enums(priority_score)
 // @Success 200 {object} dto.TaskListResponse "List of tasks retrieved successfully"
-// @Failure 400 {object} map[string]string "Invalid pagination parameters"
+// @Failure 400 {object} map[string]string "Invalid pagination parameters, cursor or sort"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 403 {object} map[string]string "Insufficient permissions"
 // @Failure 500 {object} map[string]string "Internal server error"
@@ -183,10 +314,29 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
 		return
 	}
+	keyset, ok := parseKeyset(c)
+	if !ok {
+		return
+	}
+	sort, weights, ok := h.parseTaskSort(c)
+	if !ok {
+		return
+	}
+	// Cursors page by creation time, which a ranked list is not in
+	if keyset != nil && sort != task.TaskSortDefault {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort cannot be combined with cursor or limit"})
+		return
+	}
 
 	filter := task.TaskFilter{
 		Page:     page,
 		PageSize: pageSize,
+		Keyset:   keyset,
+		Sort:     sort,
+		Weights:  weights,
+	}
+	if orgID, ok := middleware.GetOrganizationID(c); ok {
+		filter.OrganizationID = &orgID
 	}
 
 	// Parse optional filters
@@ -222,12 +372,18 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 			filter.ReviewerID = &reviewerID
 		}
 	}
+	filter.IncludeArchived = c.Query("archived") == "true"
 
 	tasks, total, err := h.service.ListTasks(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	var nextCursor string
+	if keyset != nil {
+		tasks, nextCursor = pagination.Trim(keyset, tasks, (*task.Task).PageCursor)
+		page, pageSize = 0, keyset.Limit
+	}
 
 	// Convert tasks to response DTOs
 	taskResponses := make([]dto.TaskResponse, len(tasks))
@@ -241,6 +397,7 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 		TotalCount: total,
 		Page:       page,
 		PageSize:   pageSize,
+		NextCursor: nextCursor,
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": response})
@@ -259,6 +416,7 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 // @Failure 400 {object} map[string]string "Invalid request or task ID"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 404 {object} map[string]string "Task not found"
+// @Failure 409 {object} map[string]interface{} "Description was changed by someone else; includes the current task"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/tasks/{id} [put]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
@@ -283,7 +441,7 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	} else {
 		// If validation middleware didn't run, do manual binding
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apierror.Respond(c, apierror.Validation(err))
 			return
 		}
 	}
@@ -294,20 +452,19 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		AssigneeID:     req.AssigneeID,
 		ReviewerID:     req.ReviewerID,
 		CategoryID:     req.CategoryID,
+		ParentTaskID:   req.ParentTaskID,
 		EstimatedHours: req.EstimatedHours,
 		StartDate:      req.StartDate,
 		Duration:       req.Duration,
 		DueDate:        req.DueDate,
 		Dependencies:   req.Dependencies,
+
+		BaseDescriptionVersion: req.BaseDescriptionVersion,
 	}
 
 	// Convert status if provided
 	if req.Status != nil {
 		status := task.TaskStatus(*req.Status)
-		if !status.IsValid() {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status value"})
-			return
-		}
 		input.Status = &status
 	}
 
@@ -322,11 +479,23 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	}
 
 	updatedTask, err := h.service.UpdateTask(c.Request.Context(), id, input)
+	if err == task.ErrEditConflict {
+		// Hand back the latest version so the client can merge instead of overwriting
+		response := gin.H{"error": err.Error()}
+		if current, getErr := h.service.GetTask(c.Request.Context(), id); getErr == nil {
+			currentResponse := TaskToResponse(current)
+			h.attachPresence(c.Request.Context(), currentResponse)
+			response["current"] = currentResponse
+		}
+		c.JSON(http.StatusConflict, response)
+		return
+	}
 	if err != nil {
 		statuscode := http.StatusInternalServerError
 		if err == task.ErrTaskNotFound {
 			statuscode = http.StatusNotFound
-		} else if err == task.ErrInvalidInput {
+		} else if err == task.ErrInvalidInput || err == task.ErrInvalidParent || err == task.ErrTaskCycle ||
+			err == task.ErrInvalidDependency || err == task.ErrDependencyCycle || err == task.ErrStatusDisabled {
 			statuscode = http.StatusBadRequest
 		}
 		c.JSON(statuscode, gin.H{"error": err.Error()})
@@ -336,6 +505,111 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": TaskToResponse(updatedTask)})
 }
 
+// GetSubtasks godoc
+// @Summary Get the subtask tree of a task
+// @Description Get the task with all of its subtasks at any depth. Every level carries a rollup of the subtasks below it: how many there are, how many are completed, and their estimated and actual hours.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {object} dto.SubtaskTreeResponse "Subtask tree"
+// @Failure 400 {object} map[string]string "Invalid task ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/subtasks [get]
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	tree, err := h.service.GetSubtasks(c.Request.Context(), id)
+	if err != nil {
+		statuscode := http.StatusInternalServerError
+		if errors.Is(err, task.ErrTaskNotFound) {
+			statuscode = http.StatusNotFound
+		}
+		c.JSON(statuscode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": SubtaskTreeToResponse(tree)})
+}
+
+// GetTaskDependencies godoc
+// @Summary Get the dependency graph of a task
+// @Description Get the tasks this task waits on (upstream) and the tasks waiting on it (downstream), directly or through other tasks. Each linked task carries its depth, 1 for direct links.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {object} dto.TaskDependenciesResponse "Dependency graph"
+// @Failure 400 {object} map[string]string "Invalid task ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/dependencies [get]
+func (h *TaskHandler) GetTaskDependencies(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	graph, err := h.service.GetDependencies(c.Request.Context(), id)
+	if err != nil {
+		statuscode := http.StatusInternalServerError
+		if errors.Is(err, task.ErrTaskNotFound) {
+			statuscode = http.StatusNotFound
+		}
+		c.JSON(statuscode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": DependencyGraphToResponse(graph)})
+}
+
+// GetCriticalPath godoc
+// @Summary Get the critical path of a project
+// @Description Schedule the project's remaining work by task dependencies and return the chain of tasks that decides when the project can finish. Times are hours of remaining work from now; tasks without an estimate count as 8 hours and completed tasks as none.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} dto.CriticalPathResponse "Critical path"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 409 {object} map[string]string "Task dependencies form a cycle"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/critical-path [get]
+func (h *TaskHandler) GetCriticalPath(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var filter task.TaskFilter
+	if orgID, ok := middleware.GetOrganizationID(c); ok {
+		filter.OrganizationID = &orgID
+	}
+
+	path, err := h.service.GetCriticalPath(c.Request.Context(), projectID, filter)
+	if err != nil {
+		statuscode := http.StatusInternalServerError
+		if errors.Is(err, task.ErrDependencyCycle) {
+			statuscode = http.StatusConflict
+		}
+		c.JSON(statuscode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": CriticalPathToResponse(path)})
+}
+
 // DeleteTask godoc
 // @Summary Delete a task
 // @Description Delete an existing task
@@ -411,6 +685,9 @@ func (h *TaskHandler) GetProjectTasks(c *gin.Context) {
 		Page:     page,
 		PageSize: pageSize,
 	}
+	if orgID, ok := middleware.GetOrganizationID(c); ok {
+		filter.OrganizationID = &orgID
+	}
 
 	tasks, total, err := h.service.GetProjectTasks(c.Request.Context(), projectID, filter)
 	if err != nil {
@@ -418,10 +695,23 @@ func (h *TaskHandler) GetProjectTasks(c *gin.Context) {
 		return
 	}
 
+	// Look up who has each task on the board open
+	var viewers map[uuid.UUID][]presence.Viewer
+	if h.presence != nil {
+		taskIDs := make([]uuid.UUID, len(tasks))
+		for i, t := range tasks {
+			taskIDs[i] = t.ID
+		}
+		viewers, _ = h.presence.ViewersOf(c.Request.Context(), presence.EntityTask, taskIDs)
+	}
+
 	// Convert tasks to response DTOs
 	taskResponses := make([]dto.TaskResponse, len(tasks))
 	for i, t := range tasks {
 		response := TaskToResponse(&t)
+		if v, ok := viewers[t.ID]; ok {
+			response.Viewers = dto.ViewersToResponse(v)
+		}
 		taskResponses[i] = *response
 	}
 
@@ -435,6 +725,73 @@ func (h *TaskHandler) GetProjectTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": response})
 }
 
+// GetProjectHealth godoc
+// @Summary Get the health of a project
+// @Description Summarize the schedule risk of a project: completion, overdue and blocked tasks, and the tasks the nightly risk analysis flagged as at risk. The score runs from 100, when no open task is at risk, down to 0.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param project_id path string true "Project ID" format(uuid)
+// @Success 200 {object} dto.ProjectHealthResponse "Project health"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/project/{project_id}/health [get]
+func (h *TaskHandler) GetProjectHealth(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("project_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var filter task.TaskFilter
+	if orgID, ok := middleware.GetOrganizationID(c); ok {
+		filter.OrganizationID = &orgID
+	}
+
+	health, err := h.service.GetProjectHealth(c.Request.Context(), projectID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": ProjectHealthToResponse(health)})
+}
+
+// GetMyWork godoc
+// @Summary Get my work
+// @Description Get the open tasks assigned to the caller in every organization, grouped by urgency: overdue, flagged at risk by the nightly analysis, due within three days, and the rest. Each task appears in the first group it qualifies for. Groups are ordered by due date unless sorted by priority score.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param sort query string false "priority_score ranks each group by priority score under the caller's task_priority weights" Enums(priority_score)
+// @Success 200 {object} dto.MyWorkResponse "Open tasks grouped by urgency"
+// @Failure 400 {object} map[string]string "Invalid sort"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/work [get]
+func (h *TaskHandler) GetMyWork(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	_, weights, ok := h.parseTaskSort(c)
+	if !ok {
+		return
+	}
+
+	work, err := h.service.GetMyWork(c.Request.Context(), userID, weights)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": MyWorkToResponse(work)})
+}
+
 // UpdateTaskStatus godoc
 // @Summary Update task status
 // @Description Update the status of a task
@@ -473,23 +830,18 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 	} else {
 		// If validation middleware didn't run, do manual binding
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apierror.Respond(c, apierror.Validation(err))
 			return
 		}
 	}
 
 	status := task.TaskStatus(req.Status)
-	if !status.IsValid() {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status value"})
-		return
-	}
-
 	updatedTask, err := h.service.UpdateTaskStatus(c.Request.Context(), id, status)
 	if err != nil {
 		statuscode := http.StatusInternalServerError
 		if err == task.ErrTaskNotFound {
 			statuscode = http.StatusNotFound
-		} else if err == task.ErrInvalidInput || err == task.ErrInvalidTransition {
+		} else if err == task.ErrInvalidInput || err == task.ErrInvalidTransition || err == task.ErrStatusDisabled {
 			statuscode = http.StatusBadRequest
 		}
 		c.JSON(statuscode, gin.H{"error": err.Error()})
@@ -499,6 +851,62 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": TaskToResponse(updatedTask)})
 }
 
+// MoveTask godoc
+// @Summary Move a task on the board
+// @Description Reorder a task within its status column, or move it to another column at a given position
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param move body dto.MoveTaskRequest true "Target status and position"
+// @Success 200 {object} dto.TaskResponse "Task moved successfully"
+// @Failure 400 {object} map[string]string "Invalid request, status transition or unfinished dependencies"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/move [patch]
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var req dto.MoveTaskRequest
+	if validatedModel, exists := c.Get("validated_model"); exists {
+		validatedPtr, ok := validatedModel.(*dto.MoveTaskRequest)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid model type from validation"})
+			return
+		}
+		req = *validatedPtr
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.Validation(err))
+		return
+	}
+
+	movedTask, err := h.service.MoveTask(c.Request.Context(), id, task.MoveTaskInput{
+		Status:   task.TaskStatus(req.Status),
+		Position: req.Position,
+	})
+	if err != nil {
+		statuscode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, task.ErrTaskNotFound):
+			statuscode = http.StatusNotFound
+		case errors.Is(err, task.ErrInvalidInput), errors.Is(err, task.ErrInvalidTransition), errors.Is(err, task.ErrDependencyFailed),
+			errors.Is(err, task.ErrStatusDisabled):
+			statuscode = http.StatusBadRequest
+		}
+		c.JSON(statuscode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": TaskToResponse(movedTask)})
+}
+
 // AssignTask godoc
 // @Summary Assign a task
 // @Description Assign a task to a user
@@ -537,7 +945,7 @@ func (h *TaskHandler) AssignTask(c *gin.Context) {
 	} else {
 		// If validation middleware didn't run, do manual binding
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apierror.Respond(c, apierror.Validation(err))
 			return
 		}
 	}
@@ -962,7 +1370,7 @@ func (h *TaskHandler) RecordTaskActivity(c *gin.Context) {
 	} else {
 		// If validation middleware didn't run, do manual binding
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apierror.Respond(c, apierror.Validation(err))
 			return
 		}
 	}
@@ -993,3 +1401,164 @@ func (h *TaskHandler) RecordTaskActivity(c *gin.Context) {
 
 	c.Status(http.StatusCreated)
 }
+
+// AddTaskComment godoc
+// @Summary Comment on a task
+// @Description Add a comment to a task. The comment also appears in the task's activity feed.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param comment body dto.CreateTaskCommentRequest true "Comment"
+// @Success 201 {object} task.TaskComment "Comment added"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/comments [post]
+func (h *TaskHandler) AddTaskComment(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.CreateTaskCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.Validation(err))
+		return
+	}
+
+	comment, err := h.service.AddComment(c.Request.Context(), taskID, userID, req.Body)
+	if err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
+}
+
+// ListTaskComments godoc
+// @Summary List task comments
+// @Description Get the comments of a task, oldest first
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Number of items per page" default(50)
+// @Success 200 {object} dto.TaskCommentListResponse "Comments"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/comments [get]
+func (h *TaskHandler) ListTaskComments(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil || page < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+
+	comments, total, err := h.service.ListComments(c.Request.Context(), taskID, page, pageSize)
+	if err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	response := make([]dto.TaskCommentResponse, len(comments))
+	ids := make([]uuid.UUID, len(comments))
+	for i, comment := range comments {
+		response[i].TaskComment = comment
+		ids[i] = comment.ID
+	}
+	if h.attachments != nil {
+		byComment, err := h.attachments.ListByOwners(c.Request.Context(), attachments.OwnerComment, ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for i := range response {
+			if list := byComment[response[i].ID]; len(list) > 0 {
+				response[i].Attachments = AttachmentsToResponse(list, commentAttachmentsPath(taskID, response[i].ID))
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.TaskCommentListResponse{
+		Comments:   response,
+		TotalCount: total,
+		Page:       page,
+		PageSize:   pageSize,
+	}})
+}
+
+// DeleteTaskComment godoc
+// @Summary Delete a task comment
+// @Description Delete a comment. Only its author and the task creator can delete it.
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param comment_id path string true "Comment ID" format(uuid)
+// @Success 204 "Comment deleted"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not the author or task creator"
+// @Failure 404 {object} map[string]string "Task or comment not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/comments/{comment_id} [delete]
+func (h *TaskHandler) DeleteTaskComment(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	if err := h.service.DeleteComment(c.Request.Context(), taskID, commentID, userID); err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleCommentError maps task comment errors to HTTP responses
+func (h *TaskHandler) handleCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, task.ErrCommentNotAllowed):
+		apierror.Respond(c, apierror.New(http.StatusForbidden, apierror.CodeForbidden, err.Error()))
+	case errors.Is(err, task.ErrInvalidComment):
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, apierror.CodeValidationFailed, err.Error()))
+	default:
+		apierror.Respond(c, err)
+	}
+}
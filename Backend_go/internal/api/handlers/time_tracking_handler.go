@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/timetracking"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultTimesheetDays is the range of a timesheet requested without dates
+const defaultTimesheetDays = 7
+
+// TimeTrackingHandler handles timers, time entries and timesheets
+type TimeTrackingHandler struct {
+	service timetracking.Service
+}
+
+// NewTimeTrackingHandler creates a new TimeTrackingHandler instance
+func NewTimeTrackingHandler(service timetracking.Service) *TimeTrackingHandler {
+	return &TimeTrackingHandler{service: service}
+}
+
+// StartTimer godoc
+// @Summary Start a timer on a task
+// @Description Start tracking the caller's time on the task. A timer the caller has running on another task is stopped first and its time logged.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param request body dto.StartTimerRequest false "Note"
+// @Success 201 {object} timetracking.TimeEntry "Running timer"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 409 {object} map[string]string "Timer already running on the task"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/timer/start [post]
+func (h *TimeTrackingHandler) StartTimer(c *gin.Context) {
+	userID, taskID, ok := h.parseTask(c)
+	if !ok {
+		return
+	}
+
+	var req dto.StartTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.service.StartTimer(c.Request.Context(), taskID, userID, req.Note)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": entry})
+}
+
+// StopTimer godoc
+// @Summary Stop the timer on a task
+// @Description Stop the caller's timer on the task and add its time to the task's actual hours. Timers are stopped at 24 hours at most.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {object} timetracking.TimeEntry "Stopped time entry"
+// @Failure 400 {object} map[string]string "Invalid task ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "No timer running on the task"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/timer/stop [post]
+func (h *TimeTrackingHandler) StopTimer(c *gin.Context) {
+	userID, taskID, ok := h.parseTask(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.StopTimer(c.Request.Context(), taskID, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entry})
+}
+
+// GetRunningTimer godoc
+// @Summary Get the caller's running timer
+// @Description Get the timer the caller has running, in any organization. Data is null when no timer is running.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} timetracking.TimeEntry "Running timer, or null"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/timer [get]
+func (h *TimeTrackingHandler) GetRunningTimer(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	entry, err := h.service.RunningTimer(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entry})
+}
+
+// ListTimeEntries godoc
+// @Summary List the time entries of a task
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {array} timetracking.TimeEntry "Time entries, newest first"
+// @Failure 400 {object} map[string]string "Invalid task ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/time-entries [get]
+func (h *TimeTrackingHandler) ListTimeEntries(c *gin.Context) {
+	_, taskID, ok := h.parseTask(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.service.ListTaskEntries(c.Request.Context(), taskID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+// CreateTimeEntry godoc
+// @Summary Log time on a task
+// @Description Log time the caller spent on the task without a timer. The hours are added to the task's actual hours.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param request body dto.CreateTimeEntryRequest true "Start, hours and note"
+// @Success 201 {object} timetracking.TimeEntry "Created time entry"
+// @Failure 400 {object} map[string]string "Invalid entry"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/time-entries [post]
+func (h *TimeTrackingHandler) CreateTimeEntry(c *gin.Context) {
+	userID, taskID, ok := h.parseTask(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.service.CreateEntry(c.Request.Context(), taskID, userID, timetracking.CreateEntryInput{
+		StartedAt: req.StartedAt,
+		Hours:     req.Hours,
+		Note:      req.Note,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": entry})
+}
+
+// UpdateTimeEntry godoc
+// @Summary Correct a time entry
+// @Description Change the start, hours or note of one of the caller's stopped entries. The task's actual hours follow the change.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param entry_id path string true "Time entry ID" format(uuid)
+// @Param request body dto.UpdateTimeEntryRequest true "Fields to change"
+// @Success 200 {object} timetracking.TimeEntry "Updated time entry"
+// @Failure 400 {object} map[string]string "Invalid entry"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Entry logged by another user"
+// @Failure 404 {object} map[string]string "Task or time entry not found"
+// @Failure 409 {object} map[string]string "Timer still running"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/time-entries/{entry_id} [put]
+func (h *TimeTrackingHandler) UpdateTimeEntry(c *gin.Context) {
+	userID, taskID, ok := h.parseTask(c)
+	if !ok {
+		return
+	}
+	entryID, err := uuid.Parse(c.Param("entry_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time entry ID"})
+		return
+	}
+
+	var req dto.UpdateTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.service.UpdateEntry(c.Request.Context(), taskID, entryID, userID, timetracking.UpdateEntryInput{
+		StartedAt: req.StartedAt,
+		Hours:     req.Hours,
+		Note:      req.Note,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entry})
+}
+
+// DeleteTimeEntry godoc
+// @Summary Delete a time entry
+// @Description Delete one of the caller's entries and take its hours off the task. Deleting a running timer discards it.
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param entry_id path string true "Time entry ID" format(uuid)
+// @Success 204 "Time entry deleted"
+// @Failure 400 {object} map[string]string "Invalid ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Entry logged by another user"
+// @Failure 404 {object} map[string]string "Task or time entry not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/time-entries/{entry_id} [delete]
+func (h *TimeTrackingHandler) DeleteTimeEntry(c *gin.Context) {
+	userID, taskID, ok := h.parseTask(c)
+	if !ok {
+		return
+	}
+	entryID, err := uuid.Parse(c.Param("entry_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time entry ID"})
+		return
+	}
+
+	if err := h.service.DeleteEntry(c.Request.Context(), taskID, entryID, userID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTaskTime godoc
+// @Summary Compare the time logged on a task with its estimate
+// @Description Get the task's estimated and actual hours, the remaining hours and the variance, with the tracked hours of each user. Actual hours also count work logged without time entries.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {object} timetracking.TaskSummary "Time summary"
+// @Failure 400 {object} map[string]string "Invalid task ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/tasks/{id}/time [get]
+func (h *TimeTrackingHandler) GetTaskTime(c *gin.Context) {
+	_, taskID, ok := h.parseTask(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.TaskSummary(c.Request.Context(), taskID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// GetUserTimesheet godoc
+// @Summary Get a user's timesheet
+// @Description Get the time a member logged in the organization between two dates, by day, task and user, with the entries. Defaults to the last 7 days.
+// @Tags timesheets
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path string true "User ID" format(uuid)
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD), today by default"
+// @Success 200 {object} timetracking.Timesheet "Timesheet"
+// @Failure 400 {object} map[string]string "Invalid user ID or date range"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/timesheets/users/{user_id} [get]
+func (h *TimeTrackingHandler) GetUserTimesheet(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	filter, ok := h.parseTimesheetFilter(c)
+	if !ok {
+		return
+	}
+	filter.UserID = &userID
+	h.writeTimesheet(c, filter)
+}
+
+// GetProjectTimesheet godoc
+// @Summary Get a project's timesheet
+// @Description Get the time logged on the project's tasks between two dates, by day, task and user, with the entries. Defaults to the last 7 days.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD), today by default"
+// @Success 200 {object} timetracking.Timesheet "Timesheet"
+// @Failure 400 {object} map[string]string "Invalid project ID or date range"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/projects/{id}/timesheet [get]
+func (h *TimeTrackingHandler) GetProjectTimesheet(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+	filter, ok := h.parseTimesheetFilter(c)
+	if !ok {
+		return
+	}
+	filter.ProjectID = &projectID
+	h.writeTimesheet(c, filter)
+}
+
+func (h *TimeTrackingHandler) writeTimesheet(c *gin.Context, filter timetracking.TimesheetFilter) {
+	sheet, err := h.service.Timesheet(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sheet})
+}
+
+// parseTask reads the caller and the task ID, answering the request when either is missing
+func (h *TimeTrackingHandler) parseTask(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, taskID, true
+}
+
+// parseTimesheetFilter reads the organization context and the date range of a timesheet
+func (h *TimeTrackingHandler) parseTimesheetFilter(c *gin.Context) (timetracking.TimesheetFilter, bool) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return timetracking.TimesheetFilter{}, false
+	}
+	filter := timetracking.TimesheetFilter{OrganizationID: orgID}
+
+	now := time.Now().UTC()
+	filter.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return timetracking.TimesheetFilter{}, false
+		}
+		filter.To = to
+	}
+	filter.From = filter.To.AddDate(0, 0, -(defaultTimesheetDays - 1))
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return timetracking.TimesheetFilter{}, false
+		}
+		filter.From = from
+	}
+	return filter, true
+}
+
+func (h *TimeTrackingHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, task.ErrTaskNotFound), errors.Is(err, timetracking.ErrEntryNotFound),
+		errors.Is(err, timetracking.ErrNoTimer):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, timetracking.ErrTimerRunning), errors.Is(err, timetracking.ErrEntryIsRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, timetracking.ErrNotEntryOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, timetracking.ErrInvalidEntry), errors.Is(err, timetracking.ErrInvalidRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process time tracking request"})
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/timezone"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/gin-gonic/gin"
+)
+
+// TimezoneHandler handles HTTP requests for moving a user to a new timezone
+type TimezoneHandler struct {
+	service timezone.Service
+}
+
+// NewTimezoneHandler creates a new TimezoneHandler instance
+func NewTimezoneHandler(service timezone.Service) *TimezoneHandler {
+	return &TimezoneHandler{service: service}
+}
+
+// PreviewTimezoneMigration godoc
+// @Summary Preview a timezone change
+// @Description List the future events, habit reminders and working hours that would be shifted to keep their local times if the user moved to a new timezone. Nothing is changed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TimezoneMigrationRequest true "New timezone and what to shift"
+// @Success 200 {object} timezone.Plan "Changes the migration would make"
+// @Failure 400 {object} map[string]string "Invalid timezone or request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/users/timezone/preview [post]
+func (h *TimezoneHandler) PreviewTimezoneMigration(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.TimezoneMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.service.Preview(c.Request.Context(), userID, req.ToInput())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// MigrateTimezone godoc
+// @Summary Change timezone and keep local times
+// @Description Change the user's timezone and, in the same transaction, shift future events, habit reminder times and working hours so they keep their local times. All-day events and events shared with other users are left unchanged.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TimezoneMigrationRequest true "New timezone and what to shift"
+// @Success 200 {object} timezone.Plan "Changes that were applied"
+// @Failure 400 {object} map[string]string "Invalid timezone or request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/users/timezone/migrate [post]
+func (h *TimezoneHandler) MigrateTimezone(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.TimezoneMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.service.Migrate(c.Request.Context(), userID, req.ToInput())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+func (h *TimezoneHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, user.ErrInvalidTimezone), errors.Is(err, timezone.ErrSameTimezone),
+		errors.Is(err, timezone.ErrNothingToMigrate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, timezone.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,25 +1,35 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/attachments"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type TodoHandler struct {
-	service todos.Service
+	service     todos.Service
+	attachments attachments.Service
 }
 
 func NewTodoHandler(service todos.Service) *TodoHandler {
 	return &TodoHandler{service: service}
 }
 
+// WithAttachments lists the attachments of a todo in its response
+func (h *TodoHandler) WithAttachments(attachmentService attachments.Service) *TodoHandler {
+	h.attachments = attachmentService
+	return h
+}
+
 // CreateTodo godoc
 // @Summary Create a new todo
 // @Description Create a new todo with the provided information
@@ -95,7 +105,7 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 	createdTodo, err := h.service.CreateTodo(c.Request.Context(), input)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err == todos.ErrInvalidInput {
+		if err == todos.ErrInvalidInput || errors.Is(err, todos.ErrInvalidRecurrence) {
 			statusCode = http.StatusBadRequest
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
@@ -136,7 +146,17 @@ func (h *TodoHandler) GetTodo(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": TodoToResponse(todo)})
+	response := TodoToResponse(todo)
+	if h.attachments != nil {
+		list, err := h.attachments.List(c.Request.Context(), attachments.OwnerTodo, todo.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response.Attachments = AttachmentsToResponse(list, todoAttachmentsPath(todo.ID))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
 }
 
 // ListTodos godoc
@@ -148,6 +168,8 @@ func (h *TodoHandler) GetTodo(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number (default: 0)"
 // @Param pageSize query int false "Number of items per page (default: 10)"
+// @Param cursor query string false "next_cursor from the previous page; with cursor or limit, todos are paged oldest first and total_count is not counted"
+// @Param limit query int false "Number of items per cursor page (default: 20, max: 100)"
 // @Param status query string false "Filter by status"
 // @Param priority query string false "Filter by priority"
 // @Param is_completed query bool false "Filter by completion status"
@@ -175,11 +197,16 @@ func (h *TodoHandler) ListTodos(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
 		return
 	}
+	keyset, ok := parseKeyset(c)
+	if !ok {
+		return
+	}
 
 	filter := todos.TodoFilter{
 		Page:     page,
 		PageSize: pageSize,
 		UserID:   &userID,
+		Keyset:   keyset,
 	}
 
 	// Parse optional filters
@@ -205,12 +232,18 @@ func (h *TodoHandler) ListTodos(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	var nextCursor string
+	if keyset != nil {
+		todosList, nextCursor = pagination.Trim(keyset, todosList, (*todos.Todo).PageCursor)
+		page, pageSize = 0, keyset.Limit
+	}
 
 	response := dto.TodoListResponse{
 		Todos:      TodosToResponse(todosList),
 		TotalCount: total,
 		Page:       page,
 		PageSize:   pageSize,
+		NextCursor: nextCursor,
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": response})
@@ -292,7 +325,7 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		if err == todos.ErrTodoNotFound {
 			statusCode = http.StatusNotFound
-		} else if err == todos.ErrInvalidInput {
+		} else if err == todos.ErrInvalidInput || errors.Is(err, todos.ErrInvalidRecurrence) {
 			statusCode = http.StatusBadRequest
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
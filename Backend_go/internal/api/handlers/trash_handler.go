@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/trash"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TrashHandler handles HTTP requests for deleted tasks and todos
+type TrashHandler struct {
+	service trash.Service
+}
+
+// NewTrashHandler creates a new TrashHandler instance
+func NewTrashHandler(service trash.Service) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// ListTrash godoc
+// @Summary List the trash
+// @Description List the tasks and todos the user deleted recently, most recent first. Items are purged for good once they expire.
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} trash.Item "Deleted items"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/trash [get]
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	items, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// RestoreTrashItem godoc
+// @Summary Restore an item from the trash
+// @Description Restore a deleted task or todo. A task comes back under its parent if that still exists, and a todo into its list or else the default list.
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Item type (task or todo)"
+// @Param id path string true "Item ID"
+// @Success 200 {object} object "Restored task or todo"
+// @Failure 400 {object} map[string]string "Invalid item type or ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Task neither created nor deleted by the user"
+// @Failure 404 {object} map[string]string "Item not found in trash"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/trash/{type}/{id}/restore [post]
+func (h *TrashHandler) RestoreTrashItem(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item ID"})
+		return
+	}
+
+	restored, err := h.service.Restore(c.Request.Context(), userID, trash.ItemType(c.Param("type")), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	switch item := restored.(type) {
+	case *task.Task:
+		c.JSON(http.StatusOK, gin.H{"data": TaskToResponse(item)})
+	case *todos.Todo:
+		c.JSON(http.StatusOK, gin.H{"data": TodoToResponse(item)})
+	default:
+		c.JSON(http.StatusOK, gin.H{"data": item})
+	}
+}
+
+func (h *TrashHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, trash.ErrInvalidItemType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, task.ErrRestoreNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, trash.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
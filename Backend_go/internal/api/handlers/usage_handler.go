@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/usage"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler serves the API usage dashboard of the caller
+type UsageHandler struct {
+	service usage.Service
+}
+
+// NewUsageHandler creates a new UsageHandler instance
+func NewUsageHandler(service usage.Service) *UsageHandler {
+	return &UsageHandler{service: service}
+}
+
+// GetMyUsage godoc
+// @Summary Get the caller's API usage
+// @Description Get the caller's API calls by hour over the window, with failed, rate limited and API key calls, the rate limits left right now, active sessions and refresh tokens, API keys with when they were last used, and the deliveries of the webhooks they created. Usage is aggregated every few seconds, so the latest calls may not show yet.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param hours query int false "Window in hours, 1 to 168" default(24)
+// @Success 200 {object} usage.Dashboard "Usage dashboard"
+// @Failure 400 {object} map[string]string "Invalid window"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/me/usage [get]
+func (h *UsageHandler) GetMyUsage(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	window := usage.DefaultWindow
+	if value := c.Query("hours"); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": usage.ErrInvalidWindow.Error()})
+			return
+		}
+		window = time.Duration(hours) * time.Hour
+	}
+
+	dashboard, err := h.service.Dashboard(c.Request.Context(), userID, window)
+	if err != nil {
+		if errors.Is(err, usage.ErrInvalidWindow) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dashboard})
+}
@@ -2,27 +2,30 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 	"go.uber.org/zap"
 )
 
-var log = logrus.New()
+var log = logger.Logrus("users")
 
 type UserHandler struct {
 	userService user.Service
+	tokens      *auth.RefreshTokenService
 	jwtSecret   string
 }
 
-func NewUserHandler(userService user.Service, jwtSecret string) *UserHandler {
-	return &UserHandler{userService: userService, jwtSecret: jwtSecret}
+func NewUserHandler(userService user.Service, tokens *auth.RefreshTokenService, jwtSecret string) *UserHandler {
+	return &UserHandler{userService: userService, tokens: tokens, jwtSecret: jwtSecret}
 }
 
 // CreateUser handles user registration
@@ -183,37 +186,29 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := auth.GenerateToken(
-		user.ID,
-		user.Email,
-		roles,
-		uuid.Nil,
-		permissions,
-		h.jwtSecret,
-		24,
-	)
+	// Generate access and refresh tokens and open a session with device info
+	pair, err := h.tokens.IssueTokenPair(c.Request.Context(), auth.Identity{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Roles:       roles,
+		OrgID:       uuid.Nil,
+		Permissions: permissions,
+	}, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		log.Error("Failed to generate token", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
-
-	// Create session with device info
-	session := auth.GetSessionStore().CreateSession(
-		user.ID,
-		c.Request.UserAgent(),
-		c.ClientIP(),
-		token,
-		24*time.Hour,
-	)
+	session := pair.Session
 
 	// Record session analytics
 	h.recordSessionActivity(c, user.ID, session.ID, "login", session.DeviceInfo, session.IPAddress)
 
 	response := dto.LoginResponse{
-		Token:     token,
-		ExpiresAt: session.ExpiresAt,
+		Token:            pair.AccessToken,
+		ExpiresAt:        pair.AccessExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
 		User: dto.UserResponse{
 			ID:          user.ID,
 			Email:       user.Email,
@@ -399,6 +394,80 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// GetPreferences handles retrieving the current user's UI preferences
+// @Summary Get user preferences
+// @Description Get the current user's UI preferences (theme, default views, notification settings) merged with defaults
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/users/preferences [get]
+func (h *UserHandler) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	preferences, err := h.userService.GetPreferences(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		h.handlePreferencesError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+// UpdatePreferences handles partially updating the current user's UI preferences
+// @Summary Update user preferences
+// @Description Merge a partial preferences document into the current user's preferences. Objects are merged recursively and a null value resets the key to its default.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body object true "Partial preferences keyed by namespace (theme, default_views, notifications)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/users/preferences [patch]
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var patch map[string]interface{}
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := h.userService.UpdatePreferences(c.Request.Context(), userID.(uuid.UUID), patch)
+	if err != nil {
+		h.handlePreferencesError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+func (h *UserHandler) handlePreferencesError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, user.ErrInvalidPreferences):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, user.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // GetUserRolesAndPermissions retrieves the roles and permissions for a user
 func (h *UserHandler) GetUserRolesAndPermissions(c *gin.Context, userID uuid.UUID) ([]string, []string, error) {
 	roles, permissions, err := h.userService.GetUserRolesAndPermissions(c.Request.Context(), userID)
@@ -441,7 +510,13 @@ func (h *UserHandler) Logout(c *gin.Context) {
 		h.recordSessionActivity(c, userID, session.ID, "logout", session.DeviceInfo, session.IPAddress)
 	}
 
-	// Invalidate session
+	// Invalidate session and the refresh tokens that would renew it
+	if exists {
+		session := sessionVal.(*auth.Session)
+		if err := h.tokens.RevokeSession(c.Request.Context(), userID, session.ID); err != nil {
+			log.Errorf("Failed to revoke refresh tokens on logout: %v", err)
+		}
+	}
 	auth.GetSessionStore().InvalidateSession(token.(string))
 
 	// Add token to blacklist
@@ -450,6 +525,220 @@ func (h *UserHandler) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "successfully logged out"})
 }
 
+// RefreshToken exchanges a refresh token for a new access token
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new short-lived access token and a new refresh token. Refresh tokens are single use; presenting one that was already exchanged signs the session out.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dto.RefreshTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string "Invalid, expired or reused refresh token"
+// @Router /api/users/refresh [post]
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	current, err := h.tokens.Validate(ctx, req.RefreshToken)
+	if err != nil {
+		h.handleRefreshError(c, err)
+		return
+	}
+
+	// Reload the user so deactivation and role changes apply on refresh
+	foundUser, err := h.userService.GetUser(ctx, current.UserID)
+	if err != nil || foundUser == nil || !foundUser.IsActive {
+		h.handleRefreshError(c, auth.ErrInvalidRefreshToken)
+		return
+	}
+	roles, permissions, err := h.userService.GetUserRolesAndPermissions(ctx, foundUser.ID)
+	if err != nil {
+		log.Error("Failed to get user roles and permissions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user permissions"})
+		return
+	}
+
+	pair, err := h.tokens.Rotate(ctx, current, auth.Identity{
+		UserID:      foundUser.ID,
+		Email:       foundUser.Email,
+		Roles:       roles,
+		OrgID:       uuid.Nil,
+		Permissions: permissions,
+	}, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		h.handleRefreshError(c, err)
+		return
+	}
+
+	session := pair.Session
+	h.recordSessionActivity(c, foundUser.ID, session.ID, "token_refresh", session.DeviceInfo, session.IPAddress)
+
+	c.JSON(http.StatusOK, dto.RefreshTokenResponse{
+		Token:            pair.AccessToken,
+		ExpiresAt:        pair.AccessExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
+		Session: dto.SessionResponse{
+			ID:           session.ID,
+			DeviceInfo:   session.DeviceInfo,
+			IPAddress:    session.IPAddress,
+			LastActivity: session.LastActivity,
+			ExpiresAt:    session.ExpiresAt,
+		},
+	})
+}
+
+// RevokeRefreshToken revokes a refresh token and ends its session
+// @Summary Revoke refresh token
+// @Description Revoke a refresh token, together with every token issued for the same session
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string "Unknown refresh token"
+// @Router /api/users/refresh/revoke [post]
+func (h *UserHandler) RevokeRefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tokens.Revoke(c.Request.Context(), req.RefreshToken); err != nil {
+		h.handleRefreshError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "refresh token revoked"})
+}
+
+// CreateScopedToken issues an access token limited to scopes
+// @Summary Create scoped access token
+// @Description Issue an access token for an integration that can only do what both its scopes and the user's permissions allow, e.g. "tasks:read", "calendar:write" or "workflows:execute". The token opens a session of its own that can be revoked, and cannot be refreshed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateScopedTokenRequest true "Token name, scopes and lifetime"
+// @Success 201 {object} dto.ScopedTokenResponse
+// @Failure 400 {object} map[string]string "Invalid scopes"
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string "Requested with an API key or a scoped token"
+// @Router /api/users/tokens [post]
+func (h *UserHandler) CreateScopedToken(c *gin.Context) {
+	var req dto.CreateScopedTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scopes, err := auth.NormalizeScopes(req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	roles, permissions, err := h.userService.GetUserRolesAndPermissions(c.Request.Context(), userID)
+	if err != nil {
+		log.Error("Failed to get user roles and permissions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user permissions"})
+		return
+	}
+	orgID, _ := middleware.GetOrganizationID(c)
+
+	ttl := time.Hour
+	if req.ExpiresInMinutes > 0 {
+		ttl = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+	token, session, err := h.tokens.IssueScopedToken(auth.Identity{
+		UserID:      userID,
+		Email:       c.GetString("email"),
+		Roles:       roles,
+		OrgID:       orgID,
+		Permissions: permissions,
+		Scopes:      scopes,
+	}, req.Name, c.ClientIP(), ttl)
+	if err != nil {
+		log.Error("Failed to generate scoped token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ScopedTokenResponse{
+		Token:     token,
+		Scopes:    scopes,
+		ExpiresAt: session.ExpiresAt,
+		Session: dto.SessionResponse{
+			ID:           session.ID,
+			DeviceInfo:   session.DeviceInfo,
+			IPAddress:    session.IPAddress,
+			LastActivity: session.LastActivity,
+			ExpiresAt:    session.ExpiresAt,
+		},
+	})
+}
+
+// ChangePassword changes the current user's password
+// @Summary Change password
+// @Description Change the current user's password. All sessions and refresh tokens of the user are revoked, so the user has to log in again.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string "Current password is wrong"
+// @Router /api/users/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	err := h.userService.UpdatePassword(c.Request.Context(), userID.(uuid.UUID), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, user.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "current password is incorrect"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed, please log in again"})
+}
+
+// handleRefreshError maps refresh token errors to HTTP responses
+func (h *UserHandler) handleRefreshError(c *gin.Context, err error) {
+	if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	log.Errorf("Failed to process refresh token: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process refresh token"})
+}
+
 // GetUserSessions returns all active sessions for the current user
 // @Summary Get user sessions
 // @Description Get all active sessions for the current user
@@ -508,6 +797,10 @@ func (h *UserHandler) RevokeSession(c *gin.Context) {
 			// Record session revocation in analytics
 			h.recordSessionActivity(c, userID.(uuid.UUID), session.ID, "session_revoked", session.DeviceInfo, session.IPAddress)
 
+			if err := h.tokens.RevokeSession(c.Request.Context(), userID.(uuid.UUID), session.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
 			auth.GetSessionStore().InvalidateSession(session.Token)
 			auth.GetTokenBlacklist().AddToBlacklist(session.Token, session.ExpiresAt)
 			c.JSON(http.StatusOK, gin.H{"message": "session revoked successfully"})
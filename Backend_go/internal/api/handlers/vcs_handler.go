@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/vcs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxVCSWebhookBytes bounds the size of code platform webhook deliveries
+const maxVCSWebhookBytes = 1 << 20
+
+// VCSHandler handles HTTP requests for the GitHub and GitLab integrations
+type VCSHandler struct {
+	service vcs.Service
+}
+
+// NewVCSHandler creates a new VCSHandler instance
+func NewVCSHandler(service vcs.Service) *VCSHandler {
+	return &VCSHandler{service: service}
+}
+
+// CreateConnection godoc
+// @Summary Connect a repository
+// @Description Connect a GitHub or GitLab repository to a project of the organization. Needs the vcs:manage permission. The response includes the webhook secret to configure on the repository; it is not shown again. A base_url for a self-hosted GitLab or GitHub Enterprise instance must be one the server allows.
+// @Tags vcs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param connection body dto.CreateVCSConnectionRequest true "Repository details"
+// @Success 201 {object} dto.VCSConnectionResponse "Connection"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Missing the vcs:manage permission"
+// @Failure 404 {object} map[string]string "Project not found in the organization"
+// @Failure 409 {object} map[string]string "Repository already connected"
+// @Router /api/integrations/vcs/connections [post]
+func (h *VCSHandler) CreateConnection(c *gin.Context) {
+	var req dto.CreateVCSConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	caller, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	conn, err := h.service.CreateConnection(c.Request.Context(), caller, vcs.CreateConnectionInput{
+		ProjectID:   req.ProjectID,
+		Provider:    vcs.ProviderName(req.Provider),
+		Repository:  req.Repository,
+		BaseURL:     req.BaseURL,
+		AccessToken: req.AccessToken,
+		SyncStatus:  req.SyncStatus,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": dto.VCSConnectionToResponse(conn, true)})
+}
+
+// ListConnections godoc
+// @Summary List repository connections
+// @Description List the repositories connected to a project of the organization
+// @Tags vcs
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param project_id query string true "Project ID" format(uuid)
+// @Success 200 {array} dto.VCSConnectionResponse "Connections"
+// @Failure 400 {object} map[string]string "Invalid project ID"
+// @Failure 403 {object} map[string]string "Not a member of the organization"
+// @Failure 404 {object} map[string]string "Project not found in the organization"
+// @Router /api/integrations/vcs/connections [get]
+func (h *VCSHandler) ListConnections(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	caller, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	conns, err := h.service.ListConnections(c.Request.Context(), caller, projectID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]dto.VCSConnectionResponse, len(conns))
+	for i := range conns {
+		response[i] = dto.VCSConnectionToResponse(&conns[i], false)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// UpdateConnection godoc
+// @Summary Update a repository connection
+// @Description Replace the access token, change the task status applied when items close, or rotate the webhook secret
+// @Tags vcs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param id path string true "Connection ID" format(uuid)
+// @Param connection body dto.UpdateVCSConnectionRequest true "Connection settings"
+// @Success 200 {object} dto.VCSConnectionResponse "Connection"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Missing the vcs:manage permission"
+// @Failure 404 {object} map[string]string "Connection not found"
+// @Router /api/integrations/vcs/connections/{id} [patch]
+func (h *VCSHandler) UpdateConnection(c *gin.Context) {
+	id, ok := h.parseID(c, "id", "invalid connection ID")
+	if !ok {
+		return
+	}
+	caller, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateVCSConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := h.service.UpdateConnection(c.Request.Context(), caller, id, vcs.UpdateConnectionInput{
+		AccessToken:  req.AccessToken,
+		SyncStatus:   req.SyncStatus,
+		RotateSecret: req.RotateSecret,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.VCSConnectionToResponse(conn, req.RotateSecret)})
+}
+
+// DeleteConnection godoc
+// @Summary Disconnect a repository
+// @Description Remove a repository connection together with its task links
+// @Tags vcs
+// @Security BearerAuth
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param id path string true "Connection ID" format(uuid)
+// @Success 204 "Connection removed"
+// @Failure 403 {object} map[string]string "Missing the vcs:manage permission"
+// @Failure 404 {object} map[string]string "Connection not found"
+// @Router /api/integrations/vcs/connections/{id} [delete]
+func (h *VCSHandler) DeleteConnection(c *gin.Context) {
+	id, ok := h.parseID(c, "id", "invalid connection ID")
+	if !ok {
+		return
+	}
+	caller, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteConnection(c.Request.Context(), caller, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListTaskLinks godoc
+// @Summary List task links
+// @Description List the issues and pull requests linked to a task
+// @Tags vcs
+// @Produce json
+// @Security BearerAuth
+// @Param task_id path string true "Task ID" format(uuid)
+// @Success 200 {array} vcs.Link "Links"
+// @Failure 403 {object} map[string]string "Not allowed to view the task"
+// @Failure 404 {object} map[string]string "Task not found"
+// @Router /api/integrations/vcs/tasks/{task_id}/links [get]
+func (h *VCSHandler) ListTaskLinks(c *gin.Context) {
+	taskID, ok := h.parseID(c, "task_id", "invalid task ID")
+	if !ok {
+		return
+	}
+	caller, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	links, err := h.service.ListTaskLinks(c.Request.Context(), caller, taskID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": links})
+}
+
+// LinkTask godoc
+// @Summary Link a task to an issue or pull request
+// @Description Link a task to an item of a repository connected to its project. A comment linking back to the task is posted on the item.
+// @Tags vcs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param task_id path string true "Task ID" format(uuid)
+// @Param link body dto.LinkTaskRequest true "Issue or pull request"
+// @Success 201 {object} vcs.Link "Link"
+// @Failure 400 {object} map[string]string "Invalid request or repository not connected"
+// @Failure 403 {object} map[string]string "Not allowed to change the task"
+// @Failure 404 {object} map[string]string "Task or item not found"
+// @Failure 409 {object} map[string]string "Already linked"
+// @Router /api/integrations/vcs/tasks/{task_id}/links [post]
+func (h *VCSHandler) LinkTask(c *gin.Context) {
+	taskID, ok := h.parseID(c, "task_id", "invalid task ID")
+	if !ok {
+		return
+	}
+	caller, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	var req dto.LinkTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.service.LinkTask(c.Request.Context(), caller, vcs.LinkTaskInput{
+		TaskID:       taskID,
+		URL:          req.URL,
+		ConnectionID: req.ConnectionID,
+		Kind:         vcs.ItemKind(req.Kind),
+		Number:       req.Number,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": link})
+}
+
+// UnlinkTask godoc
+// @Summary Remove a task link
+// @Description Unlink an issue or pull request from a task
+// @Tags vcs
+// @Security BearerAuth
+// @Param id path string true "Link ID" format(uuid)
+// @Success 204 "Link removed"
+// @Failure 403 {object} map[string]string "Not allowed to change the task"
+// @Failure 404 {object} map[string]string "Link not found"
+// @Router /api/integrations/vcs/links/{id} [delete]
+func (h *VCSHandler) UnlinkTask(c *gin.Context) {
+	id, ok := h.parseID(c, "id", "invalid link ID")
+	if !ok {
+		return
+	}
+	caller, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnlinkTask(c.Request.Context(), caller, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// HandleWebhook godoc
+// @Summary Receive a repository webhook
+// @Description Endpoint for GitHub and GitLab issue and pull request webhooks. Deliveries are verified with the connection's secret; merged pull requests and closed issues move linked tasks to the connection's sync status.
+// @Tags vcs
+// @Accept json
+// @Produce json
+// @Param id path string true "Connection ID" format(uuid)
+// @Success 200 {object} map[string]interface{} "Number of tasks updated"
+// @Failure 400 {object} map[string]string "Malformed delivery"
+// @Failure 401 {object} map[string]string "Invalid signature"
+// @Failure 404 {object} map[string]string "Connection not found"
+// @Router /api/integrations/vcs/webhooks/{id} [post]
+func (h *VCSHandler) HandleWebhook(c *gin.Context) {
+	id, ok := h.parseID(c, "id", "invalid connection ID")
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxVCSWebhookBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	synced, err := h.service.HandleWebhook(c.Request.Context(), id, c.Request.Header, body)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"tasks_updated": synced}})
+}
+
+func (h *VCSHandler) parseID(c *gin.Context, param, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *VCSHandler) caller(c *gin.Context) (vcs.Caller, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return vcs.Caller{}, false
+	}
+
+	caller := vcs.Caller{UserID: userID}
+	if orgID, ok := c.Get("org_id"); ok {
+		if id, ok := orgID.(uuid.UUID); ok {
+			caller.OrganizationID = id
+		}
+	}
+	return caller, true
+}
+
+// handleError maps code platform integration errors to HTTP responses
+func (h *VCSHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case vcs.IsNotFound(err), errors.Is(err, task.ErrTaskNotFound),
+		errors.Is(err, project.ErrProjectNotFound), errors.Is(err, organization.ErrOrganizationNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, vcs.ErrNotAuthorized):
+		statusCode = http.StatusForbidden
+	case errors.Is(err, vcs.ErrInvalidSignature):
+		statusCode = http.StatusUnauthorized
+	case errors.Is(err, vcs.ErrConnectionExists), errors.Is(err, vcs.ErrLinkExists):
+		statusCode = http.StatusConflict
+	case errors.Is(err, vcs.ErrInvalidInput), errors.Is(err, vcs.ErrInvalidReference),
+		errors.Is(err, vcs.ErrRepositoryMismatch):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WebhookHandler handles HTTP requests for outbound webhooks
+type WebhookHandler struct {
+	service webhooks.Service
+}
+
+// NewWebhookHandler creates a new WebhookHandler instance
+func NewWebhookHandler(service webhooks.Service) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// ListEvents godoc
+// @Summary List webhook events
+// @Description Get the event types a webhook can subscribe to. "*" subscribes to all of them.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Supported event types"
+// @Router /api/webhooks/events [get]
+func (h *WebhookHandler) ListEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": webhooks.SupportedEvents})
+}
+
+// CreateWebhook godoc
+// @Summary Register a webhook
+// @Description Register an endpoint for domain events. Under /api/webhooks the webhook is personal and receives events caused by the caller; under an organization it receives the organization's events and needs the webhooks:manage permission. The signing secret is only returned in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string false "Organization ID, on organization routes" format(uuid)
+// @Param webhook body dto.CreateWebhookRequest true "Webhook details"
+// @Success 201 {object} dto.WebhookResponse "Webhook registered"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Missing the webhooks:manage permission"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/webhooks [post]
+// @Router /api/organizations/{id}/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, orgID, ok := h.scope(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(c.Request.Context(), webhooks.CreateWebhookInput{
+		OrganizationID: orgID,
+		OwnerID:        userID,
+		URL:            req.URL,
+		Events:         req.Events,
+		Description:    req.Description,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": dto.WebhookToResponse(webhook, true)})
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description List the caller's personal webhooks, or the webhooks of an organization
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string false "Organization ID, on organization routes" format(uuid)
+// @Success 200 {array} dto.WebhookResponse "Webhooks"
+// @Failure 400 {object} map[string]string "Invalid organization ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Missing the webhooks:manage permission"
+// @Router /api/webhooks [get]
+// @Router /api/organizations/{id}/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, orgID, ok := h.scope(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.ListWebhooks(c.Request.Context(), userID, orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.WebhooksToResponse(list)})
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook
+// @Description Get a webhook by ID
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID" format(uuid)
+// @Success 200 {object} dto.WebhookResponse "Webhook"
+// @Failure 400 {object} map[string]string "Invalid webhook ID"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /api/webhooks/{id} [get]
+// @Router /api/organizations/{id}/webhooks/{webhook_id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, userID, orgID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.GetWebhook(c.Request.Context(), id, userID, orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.WebhookToResponse(webhook, false)})
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Change the URL, subscribed events or active state of a webhook. Set rotate_secret to issue a new signing secret, which is returned once.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID" format(uuid)
+// @Param webhook body dto.UpdateWebhookRequest true "Webhook changes"
+// @Success 200 {object} dto.WebhookResponse "Webhook updated"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /api/webhooks/{id} [patch]
+// @Router /api/organizations/{id}/webhooks/{webhook_id} [patch]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, userID, orgID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(c.Request.Context(), id, userID, orgID, webhooks.UpdateWebhookInput{
+		URL:          req.URL,
+		Events:       req.Events,
+		Description:  req.Description,
+		Active:       req.Active,
+		RotateSecret: req.RotateSecret,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.WebhookToResponse(webhook, req.RotateSecret)})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Delete a webhook and its delivery log
+// @Tags webhooks
+// @Security BearerAuth
+// @Param id path string true "Webhook ID" format(uuid)
+// @Success 204 "Webhook deleted"
+// @Failure 400 {object} map[string]string "Invalid webhook ID"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /api/webhooks/{id} [delete]
+// @Router /api/organizations/{id}/webhooks/{webhook_id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, userID, orgID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), id, userID, orgID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Get the delivery log of a webhook, newest first
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID" format(uuid)
+// @Param status query string false "Filter by status (pending, succeeded, failed)"
+// @Param page query int false "Page number (0-based)" default(0)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} dto.WebhookDeliveryListResponse "Delivery log"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /api/webhooks/{id}/deliveries [get]
+// @Router /api/organizations/{id}/webhooks/{webhook_id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, userID, orgID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+
+	filter := webhooks.DeliveryFilter{WebhookID: id, Page: page, PageSize: pageSize}
+	if status := c.Query("status"); status != "" {
+		s := webhooks.DeliveryStatus(status)
+		filter.Status = &s
+	}
+
+	deliveries, total, err := h.service.ListDeliveries(c.Request.Context(), userID, orgID, filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.WebhookDeliveryListResponse{
+		Deliveries: dto.WebhookDeliveriesToResponse(deliveries),
+		TotalCount: total,
+		Page:       page,
+		PageSize:   pageSize,
+	}})
+}
+
+// RetryDelivery godoc
+// @Summary Retry a webhook delivery
+// @Description Schedule a pending or failed delivery to be sent again immediately
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID" format(uuid)
+// @Param delivery_id path string true "Delivery ID" format(uuid)
+// @Success 202 {object} dto.WebhookDeliveryResponse "Delivery rescheduled"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Webhook or delivery not found"
+// @Router /api/webhooks/{id}/deliveries/{delivery_id}/retry [post]
+// @Router /api/organizations/{id}/webhooks/{webhook_id}/deliveries/{delivery_id}/retry [post]
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	id, userID, orgID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery ID"})
+		return
+	}
+
+	delivery, err := h.service.RetryDelivery(c.Request.Context(), id, deliveryID, userID, orgID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": dto.WebhookDeliveryToResponse(delivery)})
+}
+
+// scope returns the authenticated user and, on organization routes, the organization
+// whose webhooks are managed
+func (h *WebhookHandler) scope(c *gin.Context) (uuid.UUID, *uuid.UUID, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, nil, false
+	}
+	if membership, ok := middleware.GetOrganizationMembership(c); ok {
+		return userID, &membership.OrganizationID, true
+	}
+	return userID, nil, true
+}
+
+// parseRequest extracts the webhook ID, the authenticated user and the organization.
+// Organization routes name the webhook webhook_id, as id is the organization.
+func (h *WebhookHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, *uuid.UUID, bool) {
+	userID, orgID, ok := h.scope(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, nil, false
+	}
+
+	param := "id"
+	if orgID != nil {
+		param = "webhook_id"
+	}
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return uuid.Nil, uuid.Nil, nil, false
+	}
+
+	return id, userID, orgID, true
+}
+
+// handleError maps webhook errors to HTTP responses
+func (h *WebhookHandler) handleError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, webhooks.ErrWebhookNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, webhooks.ErrInvalidURL), errors.Is(err, webhooks.ErrBlockedAddress), errors.Is(err, webhooks.ErrInvalidEvent),
+		errors.Is(err, webhooks.ErrInvalidInput):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
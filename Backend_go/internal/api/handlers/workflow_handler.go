@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/dto"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workflow"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -116,6 +118,12 @@ func (h *WorkflowHandler) CreateWorkflow(c *gin.Context) {
 		return
 	}
 
+	// Workflows are created in the organization the caller is acting in
+	if orgID, ok := middleware.GetOrganizationID(c); ok && req.OrganizationID != orgID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "organization_id does not match the organization context"})
+		return
+	}
+
 	// Convert and validate workflow type
 	workflowType := workflow.WorkflowType(req.WorkflowType)
 	if !workflowType.IsValid() {
@@ -154,16 +162,10 @@ func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
 		return
 	}
 
-	// Get organization ID from header
-	orgIDStr := c.GetHeader("X-Organization-ID")
-	if orgIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "X-Organization-ID header is required"})
-		return
-	}
-
-	orgID, err := uuid.Parse(orgIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID format"})
+	// Get organization ID from context (set by organization context middleware)
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
 		return
 	}
 
@@ -182,6 +184,37 @@ func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": response})
 }
 
+// RequireWorkflowInOrganization rejects requests for a workflow outside the caller's
+// organization context
+func (h *WorkflowHandler) RequireWorkflowInOrganization(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		c.Abort()
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+		c.Abort()
+		return
+	}
+
+	existing, err := h.service.GetWorkflow(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+	if existing.Workflow.OrganizationID != orgID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "workflow does not belong to the organization"})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
 // ListWorkflows godoc
 // @Summary List all workflows
 // @Description Get a paginated list of workflows with optional filters
@@ -191,6 +224,8 @@ func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Number of items per page (default: 10)"
+// @Param cursor query string false "next_cursor from the previous page; with cursor or limit, workflows are paged oldest first and total is not counted"
+// @Param limit query int false "Number of items per cursor page (default: 20, max: 100)"
 // @Param organization_id query string false "Filter by organization ID"
 // @Param workflow_type query string false "Filter by workflow type"
 // @Param status query string false "Filter by status"
@@ -214,17 +249,15 @@ func (h *WorkflowHandler) ListWorkflows(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
 		return
 	}
-
-	// Get organization ID from header
-	orgIDStr := c.GetHeader("X-Organization-ID")
-	if orgIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "X-Organization-ID header is required"})
+	keyset, ok := parseKeyset(c)
+	if !ok {
 		return
 	}
 
-	orgID, err := uuid.Parse(orgIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID format"})
+	// Get organization ID from context (set by organization context middleware)
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
 		return
 	}
 
@@ -232,6 +265,7 @@ func (h *WorkflowHandler) ListWorkflows(c *gin.Context) {
 		Page:           page,
 		PageSize:       pageSize,
 		OrganizationID: &orgID,
+		Keyset:         keyset,
 	}
 
 	// Parse optional filters
@@ -290,16 +324,10 @@ func (h *WorkflowHandler) UpdateWorkflow(c *gin.Context) {
 		return
 	}
 
-	// Get organization ID from header
-	orgIDStr := c.GetHeader("X-Organization-ID")
-	if orgIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "X-Organization-ID header is required"})
-		return
-	}
-
-	orgID, err := uuid.Parse(orgIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID format"})
+	// Get organization ID from context (set by organization context middleware)
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
 		return
 	}
 
@@ -346,16 +374,10 @@ func (h *WorkflowHandler) DeleteWorkflow(c *gin.Context) {
 		return
 	}
 
-	// Get organization ID from header
-	orgIDStr := c.GetHeader("X-Organization-ID")
-	if orgIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "X-Organization-ID header is required"})
-		return
-	}
-
-	orgID, err := uuid.Parse(orgIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID format"})
+	// Get organization ID from context (set by organization context middleware)
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
 		return
 	}
 
@@ -381,16 +403,18 @@ func (h *WorkflowHandler) DeleteWorkflow(c *gin.Context) {
 
 // ExecuteWorkflow godoc
 // @Summary Execute a workflow
-// @Description Start the execution of a workflow
+// @Description Start the execution of a workflow. The optional input is handed to every step, along with the outputs of the steps before it.
 // @Tags workflows
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Workflow ID" format(uuid)
+// @Param request body workflow.ExecuteWorkflowRequest false "Execution input"
 // @Success 200 {object} dto.WorkflowResponse "Workflow execution started successfully"
-// @Failure 400 {object} map[string]string "Invalid workflow ID"
+// @Failure 400 {object} map[string]string "Invalid workflow ID or input"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 404 {object} map[string]string "Workflow not found"
+// @Failure 409 {object} map[string]string "Workflow is a template"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/workflows/{id}/execute [post]
 func (h *WorkflowHandler) ExecuteWorkflow(c *gin.Context) {
@@ -400,7 +424,169 @@ func (h *WorkflowHandler) ExecuteWorkflow(c *gin.Context) {
 		return
 	}
 
-	response, err := h.service.ExecuteWorkflow(c.Request.Context(), id)
+	// The body is optional; workflows without input are started with an empty one
+	var req workflow.ExecuteWorkflowRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	response, err := h.service.ExecuteWorkflow(c.Request.Context(), id, datatypes.JSON(req.Input))
+	if err != nil {
+		if errors.Is(err, workflow.ErrInvalidExecutionInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, workflow.ErrTemplateNotExecutable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// CloneWorkflow godoc
+// @Summary Clone a workflow
+// @Description Copy a workflow with its steps and transitions under new IDs, in one transaction. Cloning a template instantiates a workflow that can run; set as_template to add the copy to the template library instead. Run history and metrics are not copied.
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow ID" format(uuid)
+// @Param request body dto.CloneWorkflowRequest false "Clone options"
+// @Success 201 {object} dto.WorkflowResponse "Cloned workflow"
+// @Failure 400 {object} map[string]string "Invalid workflow ID or name"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions or plan limit reached"
+// @Failure 404 {object} map[string]string "Workflow not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/workflows/{id}/clone [post]
+func (h *WorkflowHandler) CloneWorkflow(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+		return
+	}
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	// The body is optional; without it the clone is named after its source
+	var req dto.CloneWorkflowRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	response, err := h.service.CloneWorkflow(c.Request.Context(), id, userID, workflow.CloneWorkflowRequest{
+		Name:       req.Name,
+		AsTemplate: req.AsTemplate,
+	})
+	if err != nil {
+		if errors.Is(err, workflow.ErrInvalidCloneName) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": response})
+}
+
+// ListWorkflowTemplates godoc
+// @Summary List workflow templates
+// @Description Get the template library of the organization named by the X-Organization-ID header
+// @Tags workflows
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10)"
+// @Success 200 {object} dto.WorkflowListResponse "Workflow templates"
+// @Failure 400 {object} map[string]string "Invalid pagination parameters"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/workflows/templates [get]
+func (h *WorkflowHandler) ListWorkflowTemplates(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	response, err := h.service.ListWorkflowTemplates(c.Request.Context(), orgID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// MarkWorkflowTemplate godoc
+// @Summary Mark a workflow as a template
+// @Description Add the workflow to the organization's template library. Templates can be cloned but not executed.
+// @Tags workflows
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow ID" format(uuid)
+// @Success 200 {object} dto.WorkflowResponse "Workflow template"
+// @Failure 400 {object} map[string]string "Invalid workflow ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Workflow not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/workflows/{id}/template [put]
+func (h *WorkflowHandler) MarkWorkflowTemplate(c *gin.Context) {
+	h.setWorkflowTemplate(c, true)
+}
+
+// UnmarkWorkflowTemplate godoc
+// @Summary Remove a workflow from the template library
+// @Description Turn a template back into a workflow that can be executed
+// @Tags workflows
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow ID" format(uuid)
+// @Success 200 {object} dto.WorkflowResponse "Workflow"
+// @Failure 400 {object} map[string]string "Invalid workflow ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 404 {object} map[string]string "Workflow not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/workflows/{id}/template [delete]
+func (h *WorkflowHandler) UnmarkWorkflowTemplate(c *gin.Context) {
+	h.setWorkflowTemplate(c, false)
+}
+
+func (h *WorkflowHandler) setWorkflowTemplate(c *gin.Context, isTemplate bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+		return
+	}
+
+	response, err := h.service.SetWorkflowTemplate(c.Request.Context(), id, isTemplate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -543,6 +729,10 @@ func (h *WorkflowHandler) CreateWorkflowStep(c *gin.Context) {
 
 	response, err := h.service.AddWorkflowStep(c.Request.Context(), id, req)
 	if err != nil {
+		if errors.Is(err, workflow.ErrInvalidRetryPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -663,6 +853,10 @@ func (h *WorkflowHandler) UpdateWorkflowStep(c *gin.Context) {
 
 	response, err := h.service.UpdateWorkflowStep(c.Request.Context(), stepID, req)
 	if err != nil {
+		if errors.Is(err, workflow.ErrInvalidRetryPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -1101,6 +1295,17 @@ func (h *WorkflowHandler) UpdateStepExecution(c *gin.Context) {
 		return
 	}
 
+	// Approval steps only move on through a decision of their approvers
+	step, err := h.service.GetWorkflowStep(c.Request.Context(), stepExecution.StepID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "step not found"})
+		return
+	}
+	if step.Step.StepType == workflow.StepTypeApproval && req.Status != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "approval steps are approved or rejected through their approve and reject endpoints"})
+		return
+	}
+
 	// Update fields
 	if req.Status != nil {
 		stepExecution.Status = workflow.StepStatus(*req.Status)
@@ -1122,14 +1327,13 @@ func (h *WorkflowHandler) UpdateStepExecution(c *gin.Context) {
 		return
 	}
 
-	// Get the step to check for auto-advance
-	step, err := h.service.GetWorkflowStep(c.Request.Context(), stepExecution.StepID)
-	if err == nil && step.Step.AutoAdvance && stepExecution.Status == workflow.StepStatusCompleted {
+	// Process next steps for auto-advancing steps
+	if step.Step.AutoAdvance && stepExecution.Status == workflow.StepStatusCompleted {
 		// Process next steps if the step is completed and auto-advance is enabled
 		// This would typically be handled by the executor, but we'll trigger it manually here
 		if h.service.GetExecutor() != nil {
+			ctx := tracing.Detach(c.Request.Context()) // Detached from the request but keeps its trace
 			go func() {
-				ctx := context.Background() // Use a new context for async execution
 				_ = h.service.GetExecutor().ProcessTransitions(ctx, step.Step, stepExecution, "on_approve")
 			}()
 		}
@@ -1175,14 +1379,7 @@ func (h *WorkflowHandler) ApproveStepExecution(c *gin.Context) {
 	}
 
 	if err := h.service.ApproveStepExecution(c.Request.Context(), executionID, userID, req.Reason); err != nil {
-		// A more robust error handling can be done here to check specific error types
-		if err.Error() == "step execution not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else if err.Error() == "not authorized" || err.Error() == "step is not of type approval or is not pending" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		handleApprovalError(c, err)
 		return
 	}
 
@@ -1224,26 +1421,293 @@ func (h *WorkflowHandler) RejectStepExecution(c *gin.Context) {
 	}
 
 	if err := h.service.RejectStepExecution(c.Request.Context(), executionID, userID, req.Reason); err != nil {
-		// A more robust error handling can be done here to check specific error types
-		if err.Error() == "step execution not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else if err.Error() == "not authorized" || err.Error() == "step is not of type approval or is not pending" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		handleApprovalError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Step execution rejected successfully"})
 }
 
+// RequireExecutionInOrganization rejects requests for a workflow execution outside the
+// caller's organization context
+func (h *WorkflowHandler) RequireExecutionInOrganization(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		c.Abort()
+		return
+	}
+	if h.checkExecutionInOrganization(c, executionID) {
+		c.Next()
+	}
+}
+
+// RequireStepExecutionInOrganization rejects requests for a step execution, named by the
+// executionId parameter of the step execution routes, outside the caller's organization
+// context
+func (h *WorkflowHandler) RequireStepExecutionInOrganization(c *gin.Context) {
+	stepExecutionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		c.Abort()
+		return
+	}
+	stepExecution, err := h.service.GetRepo().GetStepExecutionByID(c.Request.Context(), stepExecutionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "step execution not found"})
+		c.Abort()
+		return
+	}
+	if h.checkExecutionInOrganization(c, stepExecution.ExecutionID) {
+		c.Next()
+	}
+}
+
+// checkExecutionInOrganization aborts the request and returns false unless the execution
+// belongs to a workflow of the caller's organization context
+func (h *WorkflowHandler) checkExecutionInOrganization(c *gin.Context, executionID uuid.UUID) bool {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		c.Abort()
+		return false
+	}
+
+	execution, err := h.service.GetWorkflowExecution(c.Request.Context(), executionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		c.Abort()
+		return false
+	}
+	existing, err := h.service.GetWorkflow(c.Request.Context(), execution.Execution.WorkflowID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		c.Abort()
+		return false
+	}
+	if existing.Workflow.OrganizationID != orgID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "execution does not belong to the organization"})
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// StepDecisionRequest represents the request body for approving or rejecting a step of an
+// execution
+type StepDecisionRequest struct {
+	Comment string `json:"comment,omitempty" binding:"max=2000" example:"Budget checked, go ahead"`
+}
+
+// ApproveStep godoc
+// @Summary Approve an approval step of an execution
+// @Description Approve a pending approval step, recording the approver, comment and time, then continue the execution along the step's on_approve transitions. Steps assigned to a user or role can only be approved by them.
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param executionId path string true "Execution ID" format(uuid)
+// @Param stepExecutionId path string true "Step Execution ID" format(uuid)
+// @Param decision body StepDecisionRequest false "Approval comment"
+// @Success 200 {object} workflow.WorkflowStepExecution "Step approved"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not an approver of the step"
+// @Failure 404 {object} map[string]string "Step execution not found"
+// @Failure 409 {object} map[string]string "Step already decided or execution no longer running"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/workflows/executions/{executionId}/steps/{stepExecutionId}/approve [post]
+func (h *WorkflowHandler) ApproveStep(c *gin.Context) {
+	h.decideStep(c, true)
+}
+
+// RejectStep godoc
+// @Summary Reject an approval step of an execution
+// @Description Reject a pending approval step, recording the approver, comment and time, then continue the execution along the step's on_reject transitions, or fail it when there are none. A comment is required. Steps assigned to a user or role can only be rejected by them.
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param executionId path string true "Execution ID" format(uuid)
+// @Param stepExecutionId path string true "Step Execution ID" format(uuid)
+// @Param decision body StepDecisionRequest true "Rejection comment"
+// @Success 200 {object} workflow.WorkflowStepExecution "Step rejected"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not an approver of the step"
+// @Failure 404 {object} map[string]string "Step execution not found"
+// @Failure 409 {object} map[string]string "Step already decided or execution no longer running"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/workflows/executions/{executionId}/steps/{stepExecutionId}/reject [post]
+func (h *WorkflowHandler) RejectStep(c *gin.Context) {
+	h.decideStep(c, false)
+}
+
+func (h *WorkflowHandler) decideStep(c *gin.Context, approved bool) {
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		return
+	}
+	stepExecutionID, err := uuid.Parse(c.Param("stepExecutionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid step execution ID"})
+		return
+	}
+
+	// The body is optional for approvals
+	var req StepDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	stepExecution, err := h.service.DecideStep(c.Request.Context(), executionID, stepExecutionID, userID,
+		workflow.StepDecision{Approved: approved, Comment: req.Comment})
+	if err != nil {
+		handleApprovalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": stepExecution})
+}
+
+// handleApprovalError maps approval errors to HTTP responses
+func handleApprovalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, workflow.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "step execution not found"})
+	case errors.Is(err, workflow.ErrNotAuthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, workflow.ErrStepNotApprovable), errors.Is(err, workflow.ErrExecutionNotRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, workflow.ErrRejectionRequiresReason):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // UpdateStepExecutionRequest represents the request body for updating a step execution
 type UpdateStepExecutionRequest struct {
 	Status *string        `json:"status,omitempty"`
 	Result datatypes.JSON `json:"result,omitempty"`
 }
 
+// GetAICostReport godoc
+// @Summary Get AI cost report
+// @Description Roll up the token usage and cost of AI steps in the organization, or in one workflow, per workflow, provider and model, along with the monthly AI budgets. Dates are inclusive UTC days and default to the current month.
+// @Tags workflows
+// @Produce json
+// @Security BearerAuth
+// @Param id path string false "Workflow ID" format(uuid)
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Success 200 {object} workflow.AICostReport "AI cost report"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/workflows/ai-cost [get]
+// @Router /api/workflows/{id}/ai-cost [get]
+func (h *WorkflowHandler) GetAICostReport(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	var workflowID *uuid.UUID
+	if value := c.Param("id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+		workflowID = &id
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+	if value := c.Query("from"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = day
+	}
+	if value := c.Query("to"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		to = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	report, err := h.service.GetAICostReport(c.Request.Context(), orgID, workflowID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// SetAIBudget godoc
+// @Summary Set an AI budget
+// @Description Set the monthly AI budget in USD of the organization, or of one of its workflows when workflow_id is given. A null limit removes the budget. AI steps pause once a budget covering them is spent and resume when it is raised.
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param budget body workflow.SetAIBudgetRequest true "Budget"
+// @Success 200 {object} workflow.AIBudget "Budget saved"
+// @Success 204 "Budget removed"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/workflows/ai-budget [put]
+func (h *WorkflowHandler) SetAIBudget(c *gin.Context) {
+	orgID, ok := middleware.GetOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return
+	}
+
+	var req workflow.SetAIBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budget, err := h.service.SetAIBudget(c.Request.Context(), orgID, req)
+	if err != nil {
+		if errors.Is(err, workflow.ErrInvalidAIBudget) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if budget == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": budget})
+}
+
 // Helper function to check if a step type is valid
 func isValidStepType(stepType string) bool {
 	validTypes := map[string]bool{
@@ -1254,6 +1718,7 @@ func isValidStepType(stepType string) bool {
 		string(workflow.StepTypeIntegration):  true,
 		string(workflow.StepTypeDecision):     true,
 		string(workflow.StepTypeAITask):       true,
+		string(workflow.StepTypeAction):       true,
 	}
 	return validTypes[stepType]
 }
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditClient stores the client address and user agent of every request in its context,
+// so audit entries logged while handling it record where the change came from. The auth
+// middleware adds the authenticated user.
+func AuditClient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(audit.WithClient(c.Request.Context(), c.ClientIP(), c.Request.UserAgent()))
+		c.Next()
+	}
+}
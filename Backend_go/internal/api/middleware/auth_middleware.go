@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
 	"github.com/gin-gonic/gin"
@@ -29,6 +30,19 @@ type RateLimiterConfig struct {
 func NewAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		if key := c.GetHeader(APIKeyHeader); authHeader == "" && key != "" {
+			apiKey, ok := authenticateAPIKey(c, key)
+			if !ok {
+				c.Abort()
+				return
+			}
+			if gate := consentGate.Load(); gate != nil && !gate.allow(c, apiKey.UserID) {
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
 		if authHeader == "" {
 			log.Error("Missing authorization header")
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header is required"})
@@ -62,6 +76,13 @@ func NewAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		// Scoped tokens issued for integrations are limited like API keys
+		if len(claims.Scopes) > 0 && !scopesAllowRequest(c, claims.Scopes) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "token scopes do not allow this request"})
+			c.Abort()
+			return
+		}
+
 		// Check for service-to-service call indicator
 		serviceToService := c.GetHeader("X-Service-Call") == "true" ||
 			c.GetHeader("X-Internal-Service") != "" ||
@@ -81,6 +102,10 @@ func NewAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			c.Set("permissions", claims.Permissions)
 			c.Set("token", tokenString)
 			c.Set("is_service_call", true)
+			if len(claims.Scopes) > 0 {
+				c.Set("scopes", claims.Scopes)
+			}
+			c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), claims.UserID))
 
 			c.Next()
 			return
@@ -114,6 +139,16 @@ func NewAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		c.Set("permissions", claims.Permissions)
 		c.Set("token", tokenString)
 		c.Set("session", session)
+		if len(claims.Scopes) > 0 {
+			c.Set("scopes", claims.Scopes)
+		}
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), claims.UserID))
+
+		// Users must accept the current legal documents before using the API
+		if gate := consentGate.Load(); gate != nil && !gate.allow(c, claims.UserID) {
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -243,13 +278,18 @@ func RequirePermissions(permissions ...string) gin.HandlerFunc {
 			userPermissionsMap[perm] = struct{}{}
 		}
 
-		// Check if the user has all the required permissions
+		// Check if the user has all the required permissions, and that scoped requests
+		// were granted them
 		for _, requiredPerm := range permissions {
 			if _, found := userPermissionsMap[requiredPerm]; !found {
 				c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
 				c.Abort()
 				return
 			}
+			if !scopesAllowPermission(c, requiredPerm) {
+				c.Abort()
+				return
+			}
 		}
 
 		c.Next()
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PlanEnforcer checks an organization's plan before it uses a feature or adds a resource
+type PlanEnforcer interface {
+	CheckFeature(ctx context.Context, orgID uuid.UUID, feature billing.Feature) error
+	CheckQuota(ctx context.Context, orgID uuid.UUID, quota billing.Quota) error
+}
+
+// RequirePlanFeature rejects requests with 402 when the organization's plan lacks the
+// feature. Requests without an organization context pass. It must run after the
+// organization context middleware.
+func RequirePlanFeature(enforcer PlanEnforcer, feature billing.Feature) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID, ok := GetOrganizationID(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		if err := enforcer.CheckFeature(c.Request.Context(), orgID, feature); err != nil {
+			abortPlanCheck(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePlanQuota rejects requests with 402 when the organization has reached its
+// plan's limit for the quota. Place it on the routes that create the counted resource.
+func RequirePlanQuota(enforcer PlanEnforcer, quota billing.Quota) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID, ok := GetOrganizationID(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		if err := enforcer.CheckQuota(c.Request.Context(), orgID, quota); err != nil {
+			abortPlanCheck(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+func abortPlanCheck(c *gin.Context, err error) {
+	if errors.Is(err, billing.ErrFeatureNotInPlan) || errors.Is(err, billing.ErrQuotaExceeded) {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	} else {
+		log.Error("Failed to check organization plan", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check organization plan"})
+	}
+	c.Abort()
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -14,13 +15,20 @@ import (
 	"go.uber.org/zap"
 )
 
+// ResponseCache stores cached responses; *cache.RedisClient implements it
+type ResponseCache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	ClearByPattern(ctx context.Context, pattern string) error
+}
+
 type CacheMiddleware struct {
-	cache  *cache.RedisClient
+	cache  ResponseCache
 	prefix string
 	ttl    time.Duration
 }
 
-func NewCacheMiddleware(cache *cache.RedisClient, prefix string, ttl time.Duration) *CacheMiddleware {
+func NewCacheMiddleware(cache ResponseCache, prefix string, ttl time.Duration) *CacheMiddleware {
 	return &CacheMiddleware{
 		cache:  cache,
 		prefix: prefix,
@@ -114,6 +122,32 @@ func (m *CacheMiddleware) CacheInvalidate(patterns ...string) gin.HandlerFunc {
 	}
 }
 
+// cacheDependents lists, for each cached resource, the other resources whose responses
+// are built from it
+var cacheDependents = map[string][]string{
+	// Project details include task counts
+	cache.EntityTasks:     {cache.EntityProjects},
+	cache.EntityTodos:     {cache.EntityTodoLists},
+	cache.EntityTodoLists: {cache.EntityTodos},
+}
+
+// EntityChanged drops every cached response of the changed resources and of the resources
+// built from them. It implements cache.ChangeNotifier.
+func (m *CacheMiddleware) EntityChanged(ctx context.Context, entities ...string) {
+	cleared := make(map[string]bool)
+	for _, entity := range entities {
+		for _, resource := range append([]string{entity}, cacheDependents[entity]...) {
+			if cleared[resource] {
+				continue
+			}
+			cleared[resource] = true
+			if err := m.cache.ClearByPattern(ctx, fmt.Sprintf("%s:%s:*", m.prefix, resource)); err != nil {
+				log.Error("Failed to invalidate cache", zap.Error(err), zap.String("resource", resource))
+			}
+		}
+	}
+}
+
 func (m *CacheMiddleware) generateCacheKey(c *gin.Context) string {
 	// Get user ID from context for user-specific caching
 	userID, _ := GetUserID(c)
@@ -150,6 +184,11 @@ func (m *CacheMiddleware) generateCacheKey(c *gin.Context) string {
 		parts = append(parts, userID.String())
 	}
 
+	// Responses differ between the organizations a user belongs to
+	if orgID, ok := GetOrganizationID(c); ok {
+		parts = append(parts, "org", orgID.String())
+	}
+
 	return strings.Join(parts, ":")
 }
 
@@ -230,5 +269,10 @@ func (m *CacheMiddleware) generateCacheKeyWithPrefix(prefix string, c *gin.Conte
 		parts = append(parts, userID)
 	}
 
+	// Responses differ between the organizations a user belongs to
+	if orgID, ok := GetOrganizationID(c); ok {
+		parts = append(parts, "org", orgID.String())
+	}
+
 	return strings.Join(parts, ":")
 }
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryResponseCache is a ResponseCache kept in memory. Patterns only support a
+// trailing *, which is all the middleware uses.
+type memoryResponseCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func newMemoryResponseCache() *memoryResponseCache {
+	return &memoryResponseCache{entries: make(map[string]string)}
+}
+
+func (m *memoryResponseCache) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.entries[key]
+	if !ok {
+		return "", errors.New("not cached")
+	}
+	return value, nil
+}
+
+func (m *memoryResponseCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = value
+	return nil
+}
+
+func (m *memoryResponseCache) ClearByPattern(ctx context.Context, pattern string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.entries {
+		if key == pattern || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(key, strings.TrimSuffix(pattern, "*"))) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+// cachedStore serves values through cached GET routes, the way list and detail
+// endpoints read from the database
+type cachedStore struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *cachedStore) set(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[name] = value
+}
+
+func (s *cachedStore) handler(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		c.JSON(http.StatusOK, gin.H{"data": s.values[name]})
+	}
+}
+
+func newCachedRouter(t *testing.T) (*CacheMiddleware, *cachedStore, func(method, path string) string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	m := NewCacheMiddleware(newMemoryResponseCache(), "compass", time.Minute)
+	store := &cachedStore{values: map[string]string{"tasks": "v1", "projects": "v1", "todos": "v1"}}
+	userID := uuid.New()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", userID) })
+	router.GET("/api/tasks", m.CacheResponse(), store.handler("tasks"))
+	router.GET("/api/projects/:id/details", m.CacheResponse(), store.handler("projects"))
+	router.GET("/api/todos", m.CacheResponse(), store.handler("todos"))
+	router.POST("/api/tasks", m.CacheInvalidate("tasks:*"), func(c *gin.Context) {
+		store.set("tasks", "v2")
+		c.Status(http.StatusCreated)
+	})
+
+	do := func(method, path string) string {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Body.String()
+	}
+	return m, store, do
+}
+
+func TestCacheResponseServesCachedReads(t *testing.T) {
+	_, store, do := newCachedRouter(t)
+
+	assert.JSONEq(t, `{"data":"v1"}`, do(http.MethodGet, "/api/tasks"))
+	store.set("tasks", "v2")
+	assert.JSONEq(t, `{"data":"v1"}`, do(http.MethodGet, "/api/tasks"), "an unannounced write is hidden by the cache")
+}
+
+func TestReadAfterWriteThroughRoute(t *testing.T) {
+	_, _, do := newCachedRouter(t)
+
+	assert.JSONEq(t, `{"data":"v1"}`, do(http.MethodGet, "/api/tasks"))
+	do(http.MethodPost, "/api/tasks")
+	assert.JSONEq(t, `{"data":"v2"}`, do(http.MethodGet, "/api/tasks"))
+}
+
+func TestReadAfterWriteThroughServiceNotification(t *testing.T) {
+	m, store, do := newCachedRouter(t)
+	var notifier cache.ChangeNotifier = m
+	projectPath := "/api/projects/" + uuid.NewString() + "/details"
+
+	assert.JSONEq(t, `{"data":"v1"}`, do(http.MethodGet, "/api/tasks"))
+	assert.JSONEq(t, `{"data":"v1"}`, do(http.MethodGet, projectPath))
+	assert.JSONEq(t, `{"data":"v1"}`, do(http.MethodGet, "/api/todos"))
+
+	// A write outside the API, such as a scheduled job, announced by the task service
+	store.set("tasks", "v2")
+	store.set("projects", "v2")
+	notifier.EntityChanged(context.Background(), cache.EntityTasks)
+
+	assert.JSONEq(t, `{"data":"v2"}`, do(http.MethodGet, "/api/tasks"))
+	assert.JSONEq(t, `{"data":"v2"}`, do(http.MethodGet, projectPath), "project details count tasks")
+
+	store.set("todos", "v2")
+	assert.JSONEq(t, `{"data":"v1"}`, do(http.MethodGet, "/api/todos"), "task changes leave todos cached")
+	notifier.EntityChanged(context.Background(), cache.EntityTodos)
+	assert.JSONEq(t, `{"data":"v2"}`, do(http.MethodGet, "/api/todos"))
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorEnvelope gives every error response of the API the apierror envelope. Errors that
+// handlers attach with c.Error without responding are written with the status and code
+// apierror maps them to. Error bodies that handlers write themselves, such as
+// {"error": "task not found"}, keep their fields and get the code their status and
+// message map to and the request ID.
+func ErrorEnvelope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &envelopeWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if !writer.Written() && len(c.Errors) > 0 {
+			apierror.Respond(c, c.Errors.Last().Err)
+		}
+		writer.flush(apierror.RequestID(c))
+	}
+}
+
+// envelopeWriter holds back JSON error bodies until the handler is done, so that they
+// can be completed before they are sent
+type envelopeWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+// holds reports whether what is written now is an error body to hold back
+func (w *envelopeWriter) holds() bool {
+	return w.body != nil || (w.Status() >= 400 && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json"))
+}
+
+func (w *envelopeWriter) Write(data []byte) (int, error) {
+	if !w.holds() {
+		return w.ResponseWriter.Write(data)
+	}
+	if w.body == nil {
+		w.body = &bytes.Buffer{}
+	}
+	return w.body.Write(data)
+}
+
+func (w *envelopeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *envelopeWriter) Written() bool {
+	return w.body != nil || w.ResponseWriter.Written()
+}
+
+// flush completes the held back error body and sends it
+func (w *envelopeWriter) flush(requestID string) {
+	if w.body == nil {
+		return
+	}
+	body := w.body.Bytes()
+	w.body = nil
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err == nil {
+		if message, ok := fields["error"].(string); ok {
+			if _, ok := fields["code"]; !ok {
+				fields["code"] = apierror.CodeFor(w.Status(), message)
+			}
+			if _, ok := fields["request_id"]; !ok && requestID != "" {
+				fields["request_id"] = requestID
+			}
+			if completed, err := json.Marshal(fields); err == nil {
+				body = completed
+			}
+		}
+	}
+	w.ResponseWriter.Write(body)
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// maintenanceKey is the Redis hash holding the read-only switches, keyed by domain
+	maintenanceKey = "maintenance:read_only"
+	// maintenanceRefresh is how long an instance uses its copy of the switches before reloading
+	maintenanceRefresh = 2 * time.Second
+)
+
+// MaintenanceGlobal is the domain of the switch that makes the whole API read-only
+const MaintenanceGlobal = "*"
+
+// ReadOnlySwitch makes the API, or one domain of it, reject changes
+type ReadOnlySwitch struct {
+	// Domain is the first path segment after /api, e.g. "tasks", or MaintenanceGlobal
+	Domain    string    `json:"domain"`
+	Reason    string    `json:"reason,omitempty"`
+	EnabledBy uuid.UUID `json:"enabled_by"`
+	EnabledAt time.Time `json:"enabled_at"`
+	// Until is when the switch is expected to be turned off. It is advisory: the switch
+	// stays on until an admin removes it.
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// MaintenanceMode rejects mutations with 503 while a read-only switch is on, so data can be
+// migrated or an incident contained without taking the API down. Switches live in Redis and
+// apply to every instance; each instance rereads them at most every couple of seconds.
+type MaintenanceMode struct {
+	redis  *redis.Client
+	exempt []string
+
+	mu       sync.Mutex
+	switches map[string]ReadOnlySwitch
+	loadedAt time.Time
+}
+
+// NewMaintenanceMode creates the maintenance switchboard. Paths under an exempt prefix
+// accept changes even in read-only mode, so admins can turn it off and users can sign in.
+func NewMaintenanceMode(redisClient *cache.RedisClient, exemptPrefixes ...string) *MaintenanceMode {
+	return &MaintenanceMode{
+		redis:  redisClient.GetClient(),
+		exempt: exemptPrefixes,
+	}
+}
+
+// Enable turns on a read-only switch, replacing an existing one for the same domain
+func (m *MaintenanceMode) Enable(ctx context.Context, sw ReadOnlySwitch) error {
+	if sw.Domain == "" {
+		sw.Domain = MaintenanceGlobal
+	}
+	data, err := json.Marshal(sw)
+	if err != nil {
+		return err
+	}
+	if err := m.redis.HSet(ctx, maintenanceKey, sw.Domain, data).Err(); err != nil {
+		return err
+	}
+	m.invalidate()
+	return nil
+}
+
+// Disable turns off the read-only switch of a domain
+func (m *MaintenanceMode) Disable(ctx context.Context, domain string) error {
+	if domain == "" {
+		domain = MaintenanceGlobal
+	}
+	if err := m.redis.HDel(ctx, maintenanceKey, domain).Err(); err != nil {
+		return err
+	}
+	m.invalidate()
+	return nil
+}
+
+// List returns the switches that are on
+func (m *MaintenanceMode) List(ctx context.Context) ([]ReadOnlySwitch, error) {
+	switches, err := m.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]ReadOnlySwitch, 0, len(switches))
+	for _, sw := range switches {
+		list = append(list, sw)
+	}
+	return list, nil
+}
+
+// Guard rejects POST, PUT, PATCH and DELETE requests to read-only domains.
+// If Redis cannot be reached the last known switches keep applying.
+func (m *MaintenanceMode) Guard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		path := c.Request.URL.Path
+		for _, prefix := range m.exempt {
+			if strings.HasPrefix(path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		sw, ok := m.active(c.Request.Context(), requestDomain(path))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if sw.Until != nil {
+			if wait := time.Until(*sw.Until); wait > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			}
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":  "the API is read-only for maintenance; changes are not accepted right now",
+			"code":   "read_only",
+			"domain": sw.Domain,
+			"reason": sw.Reason,
+			"since":  sw.EnabledAt,
+			"until":  sw.Until,
+		})
+		c.Abort()
+	}
+}
+
+// active returns the switch that applies to a domain, preferring the domain's own
+func (m *MaintenanceMode) active(ctx context.Context, domain string) (ReadOnlySwitch, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if time.Since(m.loadedAt) >= maintenanceRefresh {
+		switches, err := m.fetch(ctx)
+		if err != nil {
+			log.Error("Failed to load maintenance switches", zap.Error(err))
+		} else {
+			m.switches = switches
+		}
+		m.loadedAt = time.Now()
+	}
+
+	if sw, ok := m.switches[domain]; ok {
+		return sw, true
+	}
+	sw, ok := m.switches[MaintenanceGlobal]
+	return sw, ok
+}
+
+func (m *MaintenanceMode) load(ctx context.Context) (map[string]ReadOnlySwitch, error) {
+	switches, err := m.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.switches = switches
+	m.loadedAt = time.Now()
+	m.mu.Unlock()
+	return switches, nil
+}
+
+func (m *MaintenanceMode) fetch(ctx context.Context) (map[string]ReadOnlySwitch, error) {
+	raw, err := m.redis.HGetAll(ctx, maintenanceKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	switches := make(map[string]ReadOnlySwitch, len(raw))
+	for domain, value := range raw {
+		var sw ReadOnlySwitch
+		if err := json.Unmarshal([]byte(value), &sw); err != nil {
+			sw = ReadOnlySwitch{Domain: domain}
+		}
+		switches[domain] = sw
+	}
+	return switches, nil
+}
+
+// invalidate makes the next request on this instance reload the switches
+func (m *MaintenanceMode) invalidate() {
+	m.mu.Lock()
+	m.loadedAt = time.Time{}
+	m.mu.Unlock()
+}
+
+// requestDomain returns the first path segment after /api, e.g. "tasks" for /api/tasks/123
+func requestDomain(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	path = strings.TrimPrefix(path, "api/")
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+	"github.com/gin-gonic/gin"
+)
+
+// MeterAPICalls counts authenticated API calls per organization, along with those that
+// failed and those made with an API key. It runs after the request so it sees the user
+// and organization set by the route's middleware.
+func MeterAPICalls(recorder metering.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID, ok := GetUserID(c)
+		if !ok {
+			return
+		}
+		orgID, _ := GetOrganizationID(c)
+		record := func(meter metering.Meter) {
+			recorder.Record(metering.Event{
+				OrganizationID: orgID,
+				UserID:         userID,
+				Meter:          meter,
+				Quantity:       1,
+			})
+		}
+		record(metering.MeterAPICalls)
+		if c.Writer.Status() >= 400 {
+			record(metering.MeterAPIErrors)
+		}
+		if _, viaKey := c.Get("api_key_id"); viaKey {
+			record(metering.MeterAPIKeyCalls)
+		}
+	}
+}
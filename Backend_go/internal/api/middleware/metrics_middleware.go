@@ -51,18 +51,30 @@ var (
 )
 
 // MetricsMiddleware collects metrics for HTTP requests
-type MetricsMiddleware struct{}
+type MetricsMiddleware struct {
+	slos *SLOs
+}
 
 // NewMetricsMiddleware creates a new metrics middleware
 func NewMetricsMiddleware() *MetricsMiddleware {
 	return &MetricsMiddleware{}
 }
 
+// WithSLOs also checks requests against the latency objectives of their route group
+func (m *MetricsMiddleware) WithSLOs(slos *SLOs) *MetricsMiddleware {
+	m.slos = slos
+	return m
+}
+
 // CollectMetrics collects metrics for HTTP requests
 func (m *MetricsMiddleware) CollectMetrics() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
+		// Label by route template rather than URL, so IDs don't make a series each
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
 		method := c.Request.Method
 
 		// Track request size
@@ -74,7 +86,11 @@ func (m *MetricsMiddleware) CollectMetrics() gin.HandlerFunc {
 		c.Next()
 
 		// Calculate duration
-		duration := time.Since(start).Seconds()
+		elapsed := time.Since(start)
+		duration := elapsed.Seconds()
+		if m.slos != nil {
+			m.slos.Observe(c.Request.URL.Path, elapsed)
+		}
 
 		// Get status code
 		status := strconv.Itoa(c.Writer.Status())
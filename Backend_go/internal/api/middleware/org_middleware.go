@@ -1,31 +1,226 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/apierror"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
-// OrganizationMiddleware extracts organization ID from header and sets it in context
-func OrganizationMiddleware() gin.HandlerFunc {
+// OrganizationHeader selects the organization a request acts in
+const OrganizationHeader = "X-Organization-ID"
+
+// MembershipResolver resolves a user's role and permissions in an organization
+type MembershipResolver interface {
+	ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*organization.Membership, error)
+}
+
+// OrganizationContext resolves the organization a request acts in and checks that the
+// caller is a member of it. It must run after the auth middleware. On success it sets
+// "org_id" (uuid.UUID), "org_role" (string) and "org_membership" in the context.
+// Organizations scheduled for deletion are read-only: changes are rejected with 423.
+type OrganizationContext struct {
+	resolver MembershipResolver
+}
+
+// NewOrganizationContext creates a new organization context middleware
+func NewOrganizationContext(resolver MembershipResolver) *OrganizationContext {
+	return &OrganizationContext{resolver: resolver}
+}
+
+// Require rejects requests that do not name an organization the caller belongs to
+func (o *OrganizationContext) Require() gin.HandlerFunc {
+	return o.handle(true, requestedOrganization)
+}
+
+// Optional resolves the organization when the request names one, and otherwise
+// clears org_id so handlers fall back to the caller's own data
+func (o *OrganizationContext) Optional() gin.HandlerFunc {
+	return o.handle(false, requestedOrganization)
+}
+
+// RequireParam resolves the organization from a path parameter, for routes such as
+// /api/organizations/:id/... that name the organization in the URL
+func (o *OrganizationContext) RequireParam(name string) gin.HandlerFunc {
+	return o.handle(true, func(c *gin.Context) (uuid.UUID, bool) {
+		orgID, err := uuid.Parse(c.Param(name))
+		return orgID, err == nil
+	})
+}
+
+func (o *OrganizationContext) handle(required bool, requested func(*gin.Context) (uuid.UUID, bool)) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		orgID := c.GetHeader("X-Organization-ID")
-		if orgID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "X-Organization-ID header is required"})
+		userID, exists := GetUserID(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 			c.Abort()
 			return
 		}
 
-		// Validate UUID format
-		_, err := uuid.Parse(orgID)
-		if err != nil {
+		orgID, ok := requested(c)
+		if !ok {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID format"})
 			c.Abort()
 			return
 		}
+		if orgID == uuid.Nil {
+			if required {
+				c.JSON(http.StatusBadRequest, gin.H{"error": OrganizationHeader + " header is required"})
+				c.Abort()
+				return
+			}
+			c.Set("org_id", uuid.Nil)
+			c.Next()
+			return
+		}
+
+		membership, err := o.resolver.ResolveMembership(c.Request.Context(), orgID, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, organization.ErrOrganizationNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, organization.ErrNotMember):
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			default:
+				log.Error("Failed to resolve organization membership",
+					zap.String("org_id", orgID.String()),
+					zap.String("user_id", userID.String()),
+					zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve organization membership"})
+			}
+			c.Abort()
+			return
+		}
+
+		if membership.DeletionScheduledAt != nil {
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				c.JSON(http.StatusLocked, gin.H{
+					"error":                 organization.ErrPendingDeletion.Error(),
+					"code":                  apierror.CodeOrgPendingDeletion,
+					"deletion_scheduled_at": membership.DeletionScheduledAt,
+				})
+				c.Abort()
+				return
+			}
+		}
 
-		c.Set("organization_id", orgID)
+		c.Set("org_id", membership.OrganizationID)
+		c.Set("org_role", membership.Role)
+		c.Set("org_membership", membership)
 		c.Next()
 	}
 }
+
+// requestedOrganization reads the organization from the header, falling back to the
+// organization in the token. It returns false if the header is not a valid ID.
+func requestedOrganization(c *gin.Context) (uuid.UUID, bool) {
+	if header := c.GetHeader(OrganizationHeader); header != "" {
+		orgID, err := uuid.Parse(header)
+		if err != nil {
+			return uuid.Nil, false
+		}
+		return orgID, true
+	}
+	if claimed, ok := c.Get("org_id"); ok {
+		if orgID, ok := claimed.(uuid.UUID); ok {
+			return orgID, true
+		}
+	}
+	return uuid.Nil, true
+}
+
+// RequireOrgPermissions checks that the caller's role in the current organization grants
+// every permission, and that the scopes of a scoped request do too. Without an
+// organization context the request is rejected.
+func RequireOrgPermissions(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		membership, ok := GetOrganizationMembership(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+			c.Abort()
+			return
+		}
+		for _, p := range permissions {
+			if !membership.HasPermission(p) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "code": apierror.CodeOrgForbidden, "required": p})
+				c.Abort()
+				return
+			}
+			if !scopesAllowPermission(c, p) {
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// resourceActions maps HTTP methods to permission actions
+var resourceActions = map[string]string{
+	http.MethodGet:    "read",
+	http.MethodHead:   "read",
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "update",
+	http.MethodDelete: "delete",
+}
+
+// RequireResourcePermission checks "<resource>:<action>" for every request in a route
+// group, where the action follows the HTTP method: GET reads, POST creates, PUT and
+// PATCH update and DELETE deletes. Requests without an organization context pass, so
+// the group can also serve the caller's personal data.
+func RequireResourcePermission(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		membership, ok := GetOrganizationMembership(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		action, known := resourceActions[c.Request.Method]
+		if !known {
+			c.Next()
+			return
+		}
+		permission := resource + ":" + action
+		if !membership.HasPermission(permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "code": apierror.CodeOrgForbidden, "required": permission})
+			c.Abort()
+			return
+		}
+		if !scopesAllowPermission(c, permission) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetOrganizationID retrieves the resolved organization from the context
+func GetOrganizationID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get("org_id")
+	if !exists {
+		return uuid.Nil, false
+	}
+	orgID, ok := value.(uuid.UUID)
+	if !ok || orgID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// GetOrganizationMembership retrieves the caller's membership in the resolved organization
+func GetOrganizationMembership(c *gin.Context) (*organization.Membership, bool) {
+	value, exists := c.Get("org_membership")
+	if !exists {
+		return nil, false
+	}
+	membership, ok := value.(*organization.Membership)
+	return membership, ok
+}
@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/config"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Standard rate limit response headers
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// defaultRateLimitTier applies when the configuration sets no default
+var defaultRateLimitTier = config.RateLimitTier{RequestsPerMinute: 1000}
+
+// ErrInvalidPrincipal is returned for a principal that is not "user:<id>", "ip:<address>"
+// or "org:<id>"
+var ErrInvalidPrincipal = errors.New("principal must be user:<id>, ip:<address> or org:<id>")
+
+// RateLimits limits requests with token buckets per caller, per route group and per
+// organization, as configured by tiers. Callers are the user of a valid bearer token,
+// or the client IP otherwise.
+type RateLimits struct {
+	limiter       *auth.TokenBucketLimiter
+	jwtSecret     string
+	fallback      config.RateLimitTier
+	routes        map[string]config.RateLimitTier
+	roles         map[string]config.RateLimitTier
+	organizations map[string]config.RateLimitTier
+	recorder      metering.Recorder
+}
+
+// NewRateLimits creates the rate limits of the API from its configuration
+func NewRateLimits(redisClient *cache.RedisClient, jwtSecret string, cfg config.RateLimitConfig) *RateLimits {
+	rl := &RateLimits{
+		limiter:       auth.NewTokenBucketLimiter(redisClient.GetClient()),
+		jwtSecret:     jwtSecret,
+		fallback:      cfg.Default,
+		routes:        lowerKeys(cfg.Routes),
+		roles:         lowerKeys(cfg.Roles),
+		organizations: lowerKeys(cfg.Organizations),
+	}
+	if rl.fallback.RequestsPerMinute <= 0 {
+		rl.fallback = defaultRateLimitTier
+	}
+	return rl
+}
+
+func lowerKeys(tiers map[string]config.RateLimitTier) map[string]config.RateLimitTier {
+	lowered := make(map[string]config.RateLimitTier, len(tiers))
+	for key, tier := range tiers {
+		if tier.RequestsPerMinute > 0 {
+			lowered[strings.ToLower(key)] = tier
+		}
+	}
+	return lowered
+}
+
+// WithRecorder meters the requests of signed-in users that are rejected, so users can see
+// how often they hit their limits
+func (rl *RateLimits) WithRecorder(recorder metering.Recorder) *RateLimits {
+	rl.recorder = recorder
+	return rl
+}
+
+// rateLimitCaller is who a request is limited as
+type rateLimitCaller struct {
+	principal string
+	userID    uuid.UUID
+	orgID     uuid.UUID
+}
+
+// rateLimitBucket is one bucket a request draws from
+type rateLimitBucket struct {
+	key  string
+	tier config.RateLimitTier
+}
+
+func (b rateLimitBucket) bucket() auth.Bucket {
+	burst := b.tier.Burst
+	if burst <= 0 {
+		burst = b.tier.RequestsPerMinute
+	}
+	return auth.Bucket{Capacity: int64(burst), PerSecond: float64(b.tier.RequestsPerMinute) / 60}
+}
+
+func (b rateLimitBucket) policy() string {
+	return fmt.Sprintf("%d;w=60;burst=%d", b.tier.RequestsPerMinute, b.bucket().Capacity)
+}
+
+// buckets resolves the buckets of a request. The organization is the one of the token
+// rather than the X-Organization-ID header, which is only checked against the caller's
+// memberships later on and would let anyone drain another organization's bucket.
+func (rl *RateLimits) buckets(c *gin.Context) ([]rateLimitBucket, rateLimitCaller) {
+	caller := rateLimitCaller{principal: "ip:" + c.ClientIP()}
+	tier := rl.fallback
+
+	if header := c.GetHeader("Authorization"); strings.HasPrefix(header, bearerSchema) {
+		if claims, err := auth.ValidateToken(header[len(bearerSchema):], rl.jwtSecret); err == nil {
+			caller = rateLimitCaller{
+				principal: "user:" + claims.UserID.String(),
+				userID:    claims.UserID,
+				orgID:     claims.OrgID,
+			}
+			for _, role := range claims.Roles {
+				if roleTier, ok := rl.roles[strings.ToLower(role)]; ok && roleTier.RequestsPerMinute > tier.RequestsPerMinute {
+					tier = roleTier
+				}
+			}
+		}
+	}
+
+	buckets := []rateLimitBucket{{key: caller.principal, tier: tier}}
+	if prefix, routeTier, ok := rl.route(c.Request.URL.Path); ok {
+		buckets = append(buckets, rateLimitBucket{key: caller.principal + "|" + prefix, tier: routeTier})
+	}
+	if caller.orgID != uuid.Nil {
+		if orgTier, ok := rl.organizations[caller.orgID.String()]; ok {
+			buckets = append(buckets, rateLimitBucket{key: "org:" + caller.orgID.String(), tier: orgTier})
+		}
+	}
+	return buckets, caller
+}
+
+// route finds the route group with the longest prefix of the path
+func (rl *RateLimits) route(path string) (string, config.RateLimitTier, bool) {
+	path = strings.ToLower(path)
+	var match string
+	for prefix := range rl.routes {
+		if len(prefix) > len(match) && (path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")) {
+			match = prefix
+		}
+	}
+	tier, ok := rl.routes[match]
+	return match, tier, ok
+}
+
+// Middleware takes a token from every bucket of the request and rejects it with 429 once
+// one of them is empty. The RateLimit-* headers describe the bucket closest to running out.
+func (rl *RateLimits) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			closest rateLimitBucket
+			state   auth.BucketState
+			denied  bool
+		)
+		buckets, caller := rl.buckets(c)
+		for i, b := range buckets {
+			allowed, s, err := rl.limiter.Take(c.Request.Context(), b.key, b.bucket())
+			if err != nil {
+				log.Error("Rate limiter error", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				c.Abort()
+				return
+			}
+			if i == 0 || !allowed || (!denied && s.Remaining < state.Remaining) {
+				closest, state = b, s
+			}
+			if !allowed {
+				denied = true
+				break
+			}
+		}
+
+		resetTime := time.Now().Add(state.ResetAfter)
+		c.Header(RateLimitLimitHeader, strconv.FormatInt(state.Limit, 10))
+		c.Header(RateLimitRemainingHeader, strconv.FormatInt(state.Remaining, 10))
+		c.Header(RateLimitResetHeader, strconv.Itoa(seconds(state.ResetAfter)))
+		c.Header(RateLimitPolicyHeader, closest.policy())
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(state.Remaining, 10))
+		c.Header("X-RateLimit-Reset", resetTime.String())
+
+		if denied {
+			if rl.recorder != nil && caller.userID != uuid.Nil {
+				rl.recorder.Record(metering.Event{
+					OrganizationID: caller.orgID,
+					UserID:         caller.userID,
+					Meter:          metering.MeterAPIRateLimited,
+					Quantity:       1,
+				})
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds(state.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":    "rate limit exceeded",
+				"reset_in": state.RetryAfter.String(),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds rounds a duration up to whole seconds
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// validPrincipal checks the form of a principal given by an admin
+func validPrincipal(principal string) error {
+	kind, value, ok := strings.Cut(principal, ":")
+	if !ok || value == "" {
+		return ErrInvalidPrincipal
+	}
+	switch kind {
+	case "user", "org":
+		if _, err := uuid.Parse(value); err != nil {
+			return ErrInvalidPrincipal
+		}
+	case "ip":
+		if net.ParseIP(value) == nil {
+			return ErrInvalidPrincipal
+		}
+	default:
+		return ErrInvalidPrincipal
+	}
+	return nil
+}
+
+// Inspect returns the buckets of a principal: "user:<id>", "ip:<address>" or "org:<id>"
+func (rl *RateLimits) Inspect(ctx context.Context, principal string) ([]auth.BucketState, error) {
+	if err := validPrincipal(principal); err != nil {
+		return nil, err
+	}
+	return rl.limiter.Inspect(ctx, principal)
+}
+
+// Reset refills the buckets of a principal and returns how many there were
+func (rl *RateLimits) Reset(ctx context.Context, principal string) (int64, error) {
+	if err := validPrincipal(principal); err != nil {
+		return 0, err
+	}
+	return rl.limiter.Reset(ctx, principal)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// requestScopes returns the scopes the request is limited to. Requests made with a user's
+// own session have none and are only limited by the user's permissions.
+func requestScopes(c *gin.Context) ([]string, bool) {
+	value, exists := c.Get("scopes")
+	if !exists {
+		return nil, false
+	}
+	scopes, ok := value.([]string)
+	return scopes, ok
+}
+
+// scopesAllowRequest checks the scopes against the domain of the path. Reading methods
+// need read; anything else needs execute, which write also grants. Routes of domains that
+// grant execute check the permission of every action, which narrows it down further.
+func scopesAllowRequest(c *gin.Context, scopes []string) bool {
+	action := auth.ActionExecute
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		action = auth.ActionRead
+	}
+	return auth.ScopesAllow(scopes, requestDomain(c.Request.URL.Path), action)
+}
+
+// scopesAllowPermission checks a permission against the scopes of a scoped request,
+// responding with 403 when they do not grant it
+func scopesAllowPermission(c *gin.Context, permission string) bool {
+	scopes, scoped := requestScopes(c)
+	if !scoped {
+		return true
+	}
+	domain, action := auth.PermissionScope(permission)
+	if auth.ScopesAllow(scopes, domain, action) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "insufficient scopes", "required": domain + ":" + action})
+	return false
+}
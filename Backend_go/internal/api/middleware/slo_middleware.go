@@ -0,0 +1,322 @@
+package middleware
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// defaultSLO applies to the whole API when the configuration sets no route groups
+var defaultSLO = map[string]config.LatencySLO{
+	"/api": {Threshold: 500 * time.Millisecond, Objective: 0.99},
+}
+
+// sloEvaluateInterval is how often burn rates are recomputed and checked for alerts
+const sloEvaluateInterval = time.Minute
+
+// sloHistory is how far back the request counts of a route group are kept, one bucket
+// per minute. It covers the longest window of the burn-rate policies.
+const sloHistory = 6 * 60
+
+// sloPolicy alerts when the budget burns faster than BurnRate over both windows. The long
+// window shows the burn is significant, the short one that it is still going on. The
+// values are the usual ones for a 30 day budget: the fast policy fires once 2% of it is
+// gone in an hour, the slow one once 5% is gone in six hours.
+type sloPolicy struct {
+	name     string
+	long     time.Duration
+	short    time.Duration
+	burnRate float64
+}
+
+var sloPolicies = []sloPolicy{
+	{name: "fast", long: time.Hour, short: 5 * time.Minute, burnRate: 14.4},
+	{name: "slow", long: 6 * time.Hour, short: 30 * time.Minute, burnRate: 6},
+}
+
+var (
+	// sloRequests counts the requests of each route group by whether they met the threshold
+	sloRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_slo_requests_total",
+			Help: "Requests of an SLO route group, by whether they were answered within its latency threshold",
+		},
+		[]string{"slo", "result"},
+	)
+
+	sloObjective = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_slo_objective",
+			Help: "Share of the requests of an SLO route group that should meet its latency threshold",
+		},
+		[]string{"slo"},
+	)
+
+	sloThreshold = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_slo_threshold_seconds",
+			Help: "Latency threshold of an SLO route group in seconds",
+		},
+		[]string{"slo"},
+	)
+
+	// sloBurnRate is how many times faster than sustainable this instance burns the error
+	// budget of a route group over a window; 1 spends exactly the budget
+	sloBurnRate = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_slo_burn_rate",
+			Help: "Error budget burn rate of an SLO route group over a window",
+		},
+		[]string{"slo", "window"},
+	)
+)
+
+// SLOAlert describes a route group burning its latency error budget too fast
+type SLOAlert struct {
+	// Route is the path prefix of the route group
+	Route     string
+	Threshold time.Duration
+	Objective float64
+	// Policy is "fast" or "slow"
+	Policy string
+	// Window is the long window of the policy, and BurnRate the burn rate over it
+	Window   time.Duration
+	BurnRate float64
+}
+
+// SLOAlerter is told when a route group starts burning its budget too fast. It is told
+// again only after the burn rate fell back below the policy's.
+type SLOAlerter interface {
+	SLOBudgetBurning(ctx context.Context, alert SLOAlert) error
+}
+
+// SLOs tracks the latency objectives of route groups. Requests are counted in memory per
+// instance for the burn rates and alerts, and exported to Prometheus for dashboards that
+// aggregate the instances.
+type SLOs struct {
+	groups  []*sloGroup
+	alerter SLOAlerter
+	logger  *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// sloGroup is the objective of the requests under a path prefix
+type sloGroup struct {
+	prefix    string
+	threshold time.Duration
+	objective float64
+
+	mu       sync.Mutex
+	buckets  [sloHistory]sloBucket
+	alerting map[string]bool
+}
+
+// sloBucket counts the requests of one minute
+type sloBucket struct {
+	minute int64
+	good   int64
+	total  int64
+}
+
+// NewSLOs creates the latency objectives of the API from its configuration. Groups
+// without a threshold, or with an objective outside (0, 1), are ignored.
+func NewSLOs(cfg config.SLOConfig, logger *zap.Logger) *SLOs {
+	routes := cfg.Routes
+	if len(routes) == 0 {
+		routes = defaultSLO
+	}
+
+	s := &SLOs{logger: logger}
+	for prefix, slo := range routes {
+		if slo.Threshold <= 0 || slo.Objective <= 0 || slo.Objective >= 1 {
+			logger.Warn("Ignoring invalid latency SLO",
+				zap.String("route", prefix),
+				zap.Duration("threshold", slo.Threshold),
+				zap.Float64("objective", slo.Objective))
+			continue
+		}
+		group := &sloGroup{
+			prefix:    strings.ToLower(prefix),
+			threshold: slo.Threshold,
+			objective: slo.Objective,
+			alerting:  map[string]bool{},
+		}
+		s.groups = append(s.groups, group)
+		sloObjective.WithLabelValues(group.prefix).Set(group.objective)
+		sloThreshold.WithLabelValues(group.prefix).Set(group.threshold.Seconds())
+	}
+	// A request belongs to the group of the longest prefix it matches
+	sort.Slice(s.groups, func(i, j int) bool {
+		return len(s.groups[i].prefix) > len(s.groups[j].prefix)
+	})
+	return s
+}
+
+// WithAlerter sends the alerts of the burn-rate policies to alerter; without one they are
+// only logged
+func (s *SLOs) WithAlerter(alerter SLOAlerter) *SLOs {
+	s.alerter = alerter
+	return s
+}
+
+// Observe records how long a request to path took
+func (s *SLOs) Observe(path string, duration time.Duration) {
+	group := s.group(path)
+	if group == nil {
+		return
+	}
+	good := duration <= group.threshold
+	if good {
+		sloRequests.WithLabelValues(group.prefix, "good").Inc()
+	} else {
+		sloRequests.WithLabelValues(group.prefix, "bad").Inc()
+	}
+
+	minute := time.Now().Unix() / 60
+	group.mu.Lock()
+	bucket := &group.buckets[minute%sloHistory]
+	if bucket.minute != minute {
+		*bucket = sloBucket{minute: minute}
+	}
+	bucket.total++
+	if good {
+		bucket.good++
+	}
+	group.mu.Unlock()
+}
+
+func (s *SLOs) group(path string) *sloGroup {
+	path = strings.ToLower(path)
+	for _, group := range s.groups {
+		if strings.HasPrefix(path, group.prefix) {
+			return group
+		}
+	}
+	return nil
+}
+
+// Start recomputes the burn rates every minute in the background
+func (s *SLOs) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(sloEvaluateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.evaluate(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops recomputing the burn rates
+func (s *SLOs) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// evaluate updates the burn rate gauges and alerts on the policies that started firing
+func (s *SLOs) evaluate(ctx context.Context) {
+	now := time.Now()
+	for _, group := range s.groups {
+		for _, policy := range sloPolicies {
+			long := group.burnRate(now, policy.long)
+			short := group.burnRate(now, policy.short)
+			sloBurnRate.WithLabelValues(group.prefix, windowLabel(policy.long)).Set(long)
+			sloBurnRate.WithLabelValues(group.prefix, windowLabel(policy.short)).Set(short)
+
+			firing := long >= policy.burnRate && short >= policy.burnRate
+			if !group.transition(policy.name, firing) {
+				continue
+			}
+			if !firing {
+				s.logger.Info("Latency SLO budget burn recovered",
+					zap.String("route", group.prefix),
+					zap.String("policy", policy.name))
+				continue
+			}
+
+			alert := SLOAlert{
+				Route:     group.prefix,
+				Threshold: group.threshold,
+				Objective: group.objective,
+				Policy:    policy.name,
+				Window:    policy.long,
+				BurnRate:  long,
+			}
+			s.logger.Warn("Latency SLO budget burning too fast",
+				zap.String("route", alert.Route),
+				zap.String("policy", alert.Policy),
+				zap.Float64("burn_rate", alert.BurnRate),
+				zap.Duration("window", alert.Window))
+			if s.alerter != nil {
+				if err := s.alerter.SLOBudgetBurning(ctx, alert); err != nil {
+					s.logger.Error("Failed to send latency SLO alert",
+						zap.Error(err),
+						zap.String("route", alert.Route))
+				}
+			}
+		}
+	}
+}
+
+// burnRate is the share of slow requests over the window ending now, divided by the
+// share the objective allows. The minute in progress is left out, as it is incomplete.
+func (g *sloGroup) burnRate(now time.Time, window time.Duration) float64 {
+	current := now.Unix() / 60
+	minutes := int64(window / time.Minute)
+
+	g.mu.Lock()
+	var good, total int64
+	for minute := current - minutes; minute < current; minute++ {
+		bucket := g.buckets[minute%sloHistory]
+		if bucket.minute == minute {
+			good += bucket.good
+			total += bucket.total
+		}
+	}
+	g.mu.Unlock()
+
+	if total == 0 {
+		return 0
+	}
+	bad := float64(total-good) / float64(total)
+	return bad / (1 - g.objective)
+}
+
+// transition records whether a policy is firing and reports whether that changed
+func (g *sloGroup) transition(policy string, firing bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.alerting[policy] == firing {
+		return false
+	}
+	g.alerting[policy] = firing
+	return true
+}
+
+func windowLabel(window time.Duration) string {
+	if window >= time.Hour {
+		return strings.TrimSuffix(window.String(), "0m0s")
+	}
+	return strings.TrimSuffix(window.String(), "0s")
+}
@@ -1,11 +1,10 @@
 package middleware
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -42,26 +41,36 @@ func NewTracingMiddleware() *TracingMiddleware {
 // TraceRequest adds tracing information to requests
 func (m *TracingMiddleware) TraceRequest() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get or generate request ID
+		// Skip if the request has already been traced further up the chain
+		if _, exists := c.Get("trace_context"); exists {
+			c.Next()
+			return
+		}
+
+		// Get or generate request ID. IDs a client sends that are too long or hold
+		// characters unsafe to log are replaced.
 		requestID := c.GetHeader(RequestIDHeader)
-		if requestID == "" {
+		if !tracing.ValidID(requestID) {
 			requestID = generateID()
 		}
 
 		// Get or generate trace ID
 		traceID := c.GetHeader(TraceIDHeader)
-		if traceID == "" {
+		if !tracing.ValidID(traceID) {
 			traceID = generateID()
 		}
 
 		// Get or generate span ID
 		spanID := c.GetHeader(SpanIDHeader)
-		if spanID == "" {
+		if !tracing.ValidID(spanID) {
 			spanID = generateID()
 		}
 
 		// Get parent span ID
 		parentSpanID := c.GetHeader(ParentSpanIDHeader)
+		if !tracing.ValidID(parentSpanID) {
+			parentSpanID = ""
+		}
 
 		// Create trace context
 		traceCtx := &TraceContext{
@@ -75,6 +84,15 @@ func (m *TracingMiddleware) TraceRequest() gin.HandlerFunc {
 		// Store trace context in gin context
 		c.Set("trace_context", traceCtx)
 
+		// Propagate trace context through the request context so that
+		// services and async continuations can pick it up
+		c.Request = c.Request.WithContext(tracing.WithContext(c.Request.Context(), &tracing.Context{
+			RequestID:    requestID,
+			TraceID:      traceID,
+			SpanID:       spanID,
+			ParentSpanID: parentSpanID,
+		}))
+
 		// Add trace headers to response
 		c.Header(RequestIDHeader, requestID)
 		c.Header(TraceIDHeader, traceID)
@@ -123,7 +141,5 @@ func GetTraceContext(c *gin.Context) *TraceContext {
 
 // generateID generates a random ID
 func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b)
-	return hex.EncodeToString(b)
+	return tracing.NewID()
 }
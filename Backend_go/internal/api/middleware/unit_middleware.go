@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/apierror"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/orgunits"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// UnitPermissionResolver resolves the permissions a user holds through roles in an
+// organizational unit and the units above it
+type UnitPermissionResolver interface {
+	UnitPermissions(ctx context.Context, orgID, unitID, userID uuid.UUID) ([]string, error)
+	ProjectUnitPermissions(ctx context.Context, orgID, projectID, userID uuid.UUID) ([]string, error)
+}
+
+// InheritUnitPermissions adds to the caller's membership the permissions of their roles
+// in the unit named by the path parameter, so the permission checks after it also pass
+// on those. It must run after the organization context.
+func InheritUnitPermissions(resolver UnitPermissionResolver, param string) gin.HandlerFunc {
+	return inheritPermissions(func(c *gin.Context, membership *organization.Membership) ([]string, error) {
+		unitID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return nil, nil
+		}
+		return resolver.UnitPermissions(c.Request.Context(), membership.OrganizationID, unitID, membership.UserID)
+	})
+}
+
+// InheritProjectUnitPermissions adds to the caller's membership the permissions of their
+// roles in the unit of the project named by the path parameter. Routes without the
+// parameter, or for projects in no unit, keep the organization permissions only.
+func InheritProjectUnitPermissions(resolver UnitPermissionResolver, param string) gin.HandlerFunc {
+	return inheritPermissions(func(c *gin.Context, membership *organization.Membership) ([]string, error) {
+		projectID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return nil, nil
+		}
+		return resolver.ProjectUnitPermissions(c.Request.Context(), membership.OrganizationID, projectID, membership.UserID)
+	})
+}
+
+func inheritPermissions(resolve func(*gin.Context, *organization.Membership) ([]string, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		membership, ok := GetOrganizationMembership(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// Units and projects that are not found are left to the handler to report
+		inherited, err := resolve(c, membership)
+		if err != nil && !errors.Is(err, orgunits.ErrUnitNotFound) {
+			log.Error("Failed to resolve unit permissions",
+				zap.String("org_id", membership.OrganizationID.String()),
+				zap.String("user_id", membership.UserID.String()),
+				zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve unit permissions"})
+			c.Abort()
+			return
+		}
+
+		var added []string
+		for _, p := range inherited {
+			if !membership.HasPermission(p) {
+				added = append(added, p)
+			}
+		}
+		if len(added) > 0 {
+			// The membership may be shared, so the request gets a copy of its own
+			extended := *membership
+			extended.Permissions = append(append([]string{}, membership.Permissions...), added...)
+			c.Set("org_membership", &extended)
+		}
+		c.Next()
+	}
+}
+
+// AllowInherited checks a permission against the caller's organization role and the
+// permissions inherited from a unit, then against the scopes of a scoped request. It
+// responds and returns false when they do not grant it. Handlers use it when the unit
+// is only known from the request body.
+func AllowInherited(c *gin.Context, inherited []string, permission string) bool {
+	membership, ok := GetOrganizationMembership(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization context not found"})
+		return false
+	}
+	granted := membership.HasPermission(permission)
+	for _, p := range inherited {
+		if p == permission {
+			granted = true
+		}
+	}
+	if !granted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "code": apierror.CodeOrgForbidden, "required": permission})
+		return false
+	}
+	return scopesAllowPermission(c, permission)
+}
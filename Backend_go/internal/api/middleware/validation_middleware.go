@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/apierror"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -66,30 +67,16 @@ func (m *ValidationMiddleware) ValidateRequest(model interface{}) gin.HandlerFun
 			m.log.Error("JSON unmarshal failed",
 				zap.Error(err),
 				zap.String("body", string(bodyBytes)))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": fmt.Sprintf("Invalid JSON format: %v", err.Error()),
-			})
-			c.Abort()
+			apierror.Respond(c, apierror.Validation(fmt.Errorf("Invalid JSON format: %w", err)))
 			return
 		}
 
 		// Validate model
 		if err := m.validator.Struct(modelValue); err != nil {
-			// Format validation errors
-			errors := make(map[string]string)
-			for _, err := range err.(validator.ValidationErrors) {
-				field := strings.ToLower(err.Field())
-				errors[field] = formatValidationError(err)
-			}
-
 			m.log.Error("Validation failed",
-				zap.Any("errors", errors),
+				zap.Error(err),
 				zap.String("path", c.Request.URL.Path))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "validation failed",
-				"details": errors,
-			})
-			c.Abort()
+			apierror.Respond(c, apierror.Validation(err))
 			return
 		}
 
@@ -123,21 +110,10 @@ func (m *ValidationMiddleware) ValidateQuery(model interface{}) gin.HandlerFunc
 
 		// Validate model
 		if err := m.validator.Struct(modelValue); err != nil {
-			// Format validation errors
-			errors := make(map[string]string)
-			for _, err := range err.(validator.ValidationErrors) {
-				field := strings.ToLower(err.Field())
-				errors[field] = formatValidationError(err)
-			}
-
 			m.log.Error("Query validation failed",
-				zap.Any("errors", errors),
+				zap.Error(err),
 				zap.String("path", c.Request.URL.Path))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "validation failed",
-				"details": errors,
-			})
-			c.Abort()
+			apierror.Respond(c, apierror.Validation(err))
 			return
 		}
 
@@ -158,23 +134,3 @@ func validateUUID(fl validator.FieldLevel) bool {
 	// Basic UUID format validation
 	return len(value) == 36 && strings.Count(value, "-") == 4
 }
-
-// Helper function to format validation errors
-func formatValidationError(err validator.FieldError) string {
-	switch err.Tag() {
-	case "required":
-		return "this field is required"
-	case "email":
-		return "invalid email format"
-	case "min":
-		return "value is too short"
-	case "max":
-		return "value is too long"
-	case "not_empty":
-		return "this field cannot be empty"
-	case "valid_uuid":
-		return "invalid UUID format"
-	default:
-		return "invalid value"
-	}
-}
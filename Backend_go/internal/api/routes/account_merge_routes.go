@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountMergeRoutes handles the setup of the account merge route
+type AccountMergeRoutes struct {
+	handler   *handlers.AccountMergeHandler
+	jwtSecret string
+}
+
+// NewAccountMergeRoutes creates a new AccountMergeRoutes instance
+func NewAccountMergeRoutes(handler *handlers.AccountMergeHandler, jwtSecret string) *AccountMergeRoutes {
+	return &AccountMergeRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the account merge route for system admins
+func (ar *AccountMergeRoutes) RegisterRoutes(router *gin.Engine) {
+	users := router.Group("/api/admin/users")
+	users.Use(middleware.NewAuthMiddleware(ar.jwtSecret))
+	users.Use(middleware.RequireRoles("admin"), middleware.RejectAPIKeys())
+	users.POST("/merge", ar.handler.MergeAccounts)
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityRoutes handles the setup of activity feed routes
+type ActivityRoutes struct {
+	handler   *handlers.ActivityHandler
+	jwtSecret string
+}
+
+// NewActivityRoutes creates a new ActivityRoutes instance
+func NewActivityRoutes(handler *handlers.ActivityHandler, jwtSecret string) *ActivityRoutes {
+	return &ActivityRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the project, organization and task feed routes
+func (ar *ActivityRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	feedGroup := router.Group("/api")
+	feedGroup.Use(middleware.NewAuthMiddleware(ar.jwtSecret), orgContext.Optional())
+
+	feedGroup.GET("/projects/:id/feed", ar.handler.GetProjectFeed)
+	feedGroup.GET("/organizations/:id/feed", ar.handler.GetOrganizationFeed)
+	feedGroup.GET("/tasks/:id/activity", ar.handler.GetTaskActivity)
+}
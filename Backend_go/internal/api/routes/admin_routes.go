@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRoutes handles the setup of admin-only operational routes
+type AdminRoutes struct {
+	handler   *handlers.AdminHandler
+	jwtSecret string
+}
+
+// NewAdminRoutes creates a new AdminRoutes instance
+func NewAdminRoutes(handler *handlers.AdminHandler, jwtSecret string) *AdminRoutes {
+	return &AdminRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all admin routes
+func (ar *AdminRoutes) RegisterRoutes(router *gin.Engine) {
+	adminGroup := router.Group("/api/admin")
+	adminGroup.Use(middleware.NewAuthMiddleware(ar.jwtSecret))
+	adminGroup.Use(middleware.RequireRoles("admin"), middleware.RejectAPIKeys())
+
+	// Cache management
+	adminGroup.GET("/cache/keys", ar.handler.ListCacheKeys)
+	adminGroup.DELETE("/cache/keys", ar.handler.ClearCacheKeys)
+
+	// Scheduler job runs
+	adminGroup.GET("/jobs/runs", ar.handler.ListJobRuns)
+
+	// Queue management
+	adminGroup.GET("/queues", ar.handler.ListQueues)
+	adminGroup.POST("/queues/:name/pause", ar.handler.PauseQueue)
+	adminGroup.POST("/queues/:name/resume", ar.handler.ResumeQueue)
+	adminGroup.GET("/queues/:name/dead-letters", ar.handler.ListDeadLetters)
+	adminGroup.POST("/queues/:name/dead-letters/:id/requeue", ar.handler.RequeueDeadLetter)
+
+	// Database migrations
+	adminGroup.GET("/migrations", ar.handler.GetMigrationStatus)
+	adminGroup.POST("/migrations/apply", ar.handler.ApplyMigrations)
+
+	// Read-only maintenance mode
+	adminGroup.GET("/maintenance", ar.handler.ListReadOnlyModes)
+	adminGroup.PUT("/maintenance", ar.handler.EnableReadOnlyMode)
+	adminGroup.DELETE("/maintenance", ar.handler.DisableReadOnlyMode)
+
+	// Rate limit counters
+	adminGroup.GET("/rate-limits/:principal", ar.handler.GetRateLimits)
+	adminGroup.DELETE("/rate-limits/:principal", ar.handler.ResetRateLimits)
+}
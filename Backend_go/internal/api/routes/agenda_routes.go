@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// AgendaRoutes handles the setup of daily agenda routes
+type AgendaRoutes struct {
+	handler   *handlers.AgendaHandler
+	jwtSecret string
+}
+
+// NewAgendaRoutes creates a new AgendaRoutes instance
+func NewAgendaRoutes(handler *handlers.AgendaHandler, jwtSecret string) *AgendaRoutes {
+	return &AgendaRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the agenda routes of the current user
+func (ar *AgendaRoutes) RegisterRoutes(router *gin.Engine) {
+	agendaGroup := router.Group("/api/me/agenda")
+	agendaGroup.Use(middleware.NewAuthMiddleware(ar.jwtSecret))
+
+	agendaGroup.GET("", ar.handler.GetAgenda)
+	agendaGroup.POST("/accept", ar.handler.AcceptAgenda)
+}
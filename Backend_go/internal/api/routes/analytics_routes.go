@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsRoutes handles the setup of analytics routes
+type AnalyticsRoutes struct {
+	handler   *handlers.AnalyticsHandler
+	jwtSecret string
+}
+
+// NewAnalyticsRoutes creates a new AnalyticsRoutes instance
+func NewAnalyticsRoutes(handler *handlers.AnalyticsHandler, jwtSecret string) *AnalyticsRoutes {
+	return &AnalyticsRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the analytics routes. Daily stats are the user's own; project
+// counters need the organization and read access to its projects.
+func (ar *AnalyticsRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	analytics := router.Group("/api/analytics")
+	analytics.Use(middleware.NewAuthMiddleware(ar.jwtSecret))
+	analytics.GET("/daily", ar.handler.GetDailyStats)
+	analytics.GET("/projects/:id", orgContext.Require(), middleware.RequireResourcePermission("projects"), ar.handler.GetProjectCounters)
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// AnnouncementRoutes handles the setup of announcement routes
+type AnnouncementRoutes struct {
+	handler   *handlers.AnnouncementHandler
+	jwtSecret string
+}
+
+// NewAnnouncementRoutes creates a new AnnouncementRoutes instance
+func NewAnnouncementRoutes(handler *handlers.AnnouncementHandler, jwtSecret string) *AnnouncementRoutes {
+	return &AnnouncementRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all announcement routes. Recipients read and acknowledge
+// announcements under /api/announcements; posting and managing an organization's
+// announcements needs the announcements:manage permission.
+func (ar *AnnouncementRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(ar.jwtSecret)
+
+	announcementGroup := router.Group("/api/announcements")
+	announcementGroup.Use(auth)
+
+	announcementGroup.GET("", ar.handler.ListMyAnnouncements)
+	announcementGroup.POST("/:id/acknowledge", ar.handler.Acknowledge)
+
+	manage := router.Group("/api/organizations/:id/announcements")
+	manage.Use(auth, orgContext.RequireParam("id"), middleware.RequireOrgPermissions("announcements:manage"))
+
+	manage.POST("", ar.handler.CreateAnnouncement)
+	manage.GET("", ar.handler.ListOrganizationAnnouncements)
+	manage.GET("/:announcement_id/acknowledgments", ar.handler.ListAcknowledgments)
+	manage.DELETE("/:announcement_id", ar.handler.DeleteAnnouncement)
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyRoutes handles the setup of API key management routes
+type APIKeyRoutes struct {
+	handler   *handlers.APIKeyHandler
+	jwtSecret string
+}
+
+// NewAPIKeyRoutes creates a new APIKeyRoutes instance
+func NewAPIKeyRoutes(handler *handlers.APIKeyHandler, jwtSecret string) *APIKeyRoutes {
+	return &APIKeyRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the API key routes of the current user. Keys are managed
+// with a session only, so a key cannot create or revoke keys.
+func (ar *APIKeyRoutes) RegisterRoutes(router *gin.Engine) {
+	keys := router.Group("/api/users/api-keys")
+	keys.Use(middleware.NewAuthMiddleware(ar.jwtSecret), middleware.RejectAPIKeys())
+
+	keys.GET("", ar.handler.ListAPIKeys)
+	keys.POST("", ar.handler.CreateAPIKey)
+	keys.DELETE("/:id", ar.handler.RevokeAPIKey)
+}
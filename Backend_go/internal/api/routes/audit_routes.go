@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditRoutes handles the setup of audit log routes
+type AuditRoutes struct {
+	handler   *handlers.AuditHandler
+	jwtSecret string
+}
+
+// NewAuditRoutes creates a new AuditRoutes instance
+func NewAuditRoutes(handler *handlers.AuditHandler, jwtSecret string) *AuditRoutes {
+	return &AuditRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the audit log routes. The audit log of an organization is for
+// those who manage it.
+func (ar *AuditRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auditGroup := router.Group("/api/organizations/:id/audit")
+	auditGroup.Use(middleware.NewAuthMiddleware(ar.jwtSecret), orgContext.RequireParam("id"), middleware.RequireOrgPermissions("organizations:update"))
+
+	auditGroup.GET("", ar.handler.ListAuditLog)
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
+	"github.com/gin-gonic/gin"
+)
+
+// AutomationRoutes handles the setup of automation catalog and trigger routes
+type AutomationRoutes struct {
+	handler   *handlers.AutomationHandler
+	jwtSecret string
+}
+
+// NewAutomationRoutes creates a new AutomationRoutes instance
+func NewAutomationRoutes(handler *handlers.AutomationHandler, jwtSecret string) *AutomationRoutes {
+	return &AutomationRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all automation routes
+func (ar *AutomationRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext, plans middleware.PlanEnforcer) {
+	automationGroup := router.Group("/api/automation")
+
+	// The catalog is read by platforms before the user connects an account
+	automationGroup.GET("/catalog", ar.handler.GetCatalog)
+
+	userGroup := automationGroup.Group("")
+	userGroup.Use(
+		middleware.NewAuthMiddleware(ar.jwtSecret),
+		orgContext.Optional(),
+		middleware.RequirePlanFeature(plans, billing.FeatureAutomation),
+	)
+	userGroup.GET("/triggers/:key", ar.handler.PollTrigger)
+}
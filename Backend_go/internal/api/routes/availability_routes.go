@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// AvailabilityRoutes handles the setup of team availability routes
+type AvailabilityRoutes struct {
+	handler   *handlers.AvailabilityHandler
+	jwtSecret string
+}
+
+// NewAvailabilityRoutes creates a new AvailabilityRoutes instance
+func NewAvailabilityRoutes(handler *handlers.AvailabilityHandler, jwtSecret string) *AvailabilityRoutes {
+	return &AvailabilityRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the availability routes, which only show members of the
+// organization the request acts in
+func (ar *AvailabilityRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	availability := router.Group("/api/calendar/availability")
+	availability.Use(middleware.NewAuthMiddleware(ar.jwtSecret), orgContext.Require())
+
+	availability.GET("", ar.handler.GetTeamAvailability)
+}
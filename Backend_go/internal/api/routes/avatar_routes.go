@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// AvatarRoutes handles the setup of avatar upload and image routes
+type AvatarRoutes struct {
+	handler     *handlers.AvatarHandler
+	jwtSecret   string
+	rateLimiter *auth.RedisRateLimiter
+}
+
+// NewAvatarRoutes creates a new AvatarRoutes instance
+func NewAvatarRoutes(handler *handlers.AvatarHandler, jwtSecret string, rateLimiter *auth.RedisRateLimiter) *AvatarRoutes {
+	return &AvatarRoutes{
+		handler:     handler,
+		jwtSecret:   jwtSecret,
+		rateLimiter: rateLimiter,
+	}
+}
+
+// RegisterRoutes registers the avatar upload of the current user and the public avatar
+// images, which are embedded where no token is sent
+func (ar *AvatarRoutes) RegisterRoutes(router *gin.Engine) {
+	router.POST("/api/users/profile/avatar",
+		middleware.NewAuthMiddleware(ar.jwtSecret),
+		middleware.RateLimitMiddleware(ar.rateLimiter),
+		ar.handler.UploadAvatar)
+	router.GET("/api/avatars/:user_id/:file", ar.handler.GetAvatar)
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// BadgeRoutes handles the setup of badge count routes
+type BadgeRoutes struct {
+	handler   *handlers.BadgeHandler
+	jwtSecret string
+}
+
+// NewBadgeRoutes creates a new BadgeRoutes instance
+func NewBadgeRoutes(handler *handlers.BadgeHandler, jwtSecret string) *BadgeRoutes {
+	return &BadgeRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the badge count route of the current user
+func (br *BadgeRoutes) RegisterRoutes(router *gin.Engine) {
+	router.GET("/api/me/counts", middleware.NewAuthMiddleware(br.jwtSecret), br.handler.GetCounts)
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// BaselineRoutes handles the setup of project baseline routes
+type BaselineRoutes struct {
+	handler        *handlers.BaselineHandler
+	projectHandler *handlers.ProjectHandler
+	jwtSecret      string
+}
+
+// NewBaselineRoutes creates a new BaselineRoutes instance. The project handler checks
+// that the project belongs to the caller's organization.
+func NewBaselineRoutes(handler *handlers.BaselineHandler, projectHandler *handlers.ProjectHandler, jwtSecret string) *BaselineRoutes {
+	return &BaselineRoutes{
+		handler:        handler,
+		projectHandler: projectHandler,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the baseline routes of a project. Reading baselines needs
+// read access to projects; taking and deleting them changes the project.
+func (br *BaselineRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	baselines := router.Group("/api/projects/:id/baselines")
+	baselines.Use(
+		middleware.NewAuthMiddleware(br.jwtSecret),
+		orgContext.Require(),
+		br.projectHandler.RequireProjectInOrganization,
+	)
+	read := middleware.RequireOrgPermissions("projects:read")
+	update := middleware.RequireOrgPermissions("projects:update")
+
+	baselines.GET("", read, br.handler.ListBaselines)
+	baselines.POST("", update, br.handler.CreateBaseline)
+	baselines.GET("/:baseline_id", read, br.handler.GetBaseline)
+	baselines.GET("/:baseline_id/diff", read, br.handler.CompareBaseline)
+	baselines.DELETE("/:baseline_id", update, br.handler.DeleteBaseline)
+}
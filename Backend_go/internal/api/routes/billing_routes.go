@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// BillingRoutes handles the setup of plan and subscription routes
+type BillingRoutes struct {
+	handler   *handlers.BillingHandler
+	jwtSecret string
+}
+
+// NewBillingRoutes creates a new BillingRoutes instance
+func NewBillingRoutes(handler *handlers.BillingHandler, jwtSecret string) *BillingRoutes {
+	return &BillingRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all billing routes
+func (br *BillingRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	billingGroup := router.Group("/api/billing")
+
+	billingGroup.GET("/plans", br.handler.ListPlans)
+
+	// Called by Stripe: deliveries are verified with the webhook signing secret
+	billingGroup.POST("/webhooks/stripe", br.handler.HandleStripeWebhook)
+
+	orgGroup := billingGroup.Group("")
+	orgGroup.Use(middleware.NewAuthMiddleware(br.jwtSecret), orgContext.Require())
+	orgGroup.GET("/subscription", middleware.RequireOrgPermissions("organizations:read"), br.handler.GetSubscription)
+	orgGroup.POST("/portal", middleware.RequireOrgPermissions("billing:manage"), br.handler.CreatePortalSession)
+}
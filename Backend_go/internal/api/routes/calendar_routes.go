@@ -36,12 +36,20 @@ func (cr *CalendarRoutes) RegisterRoutes(router *gin.Engine) {
 		events.GET("/:id/collaborators", cr.handler.ListCollaborators)
 		events.DELETE("/:id/collaborators/:user_id", cr.handler.RemoveCollaborator)
 
+		// Attendees and RSVP
+		events.POST("/:id/attendees", cr.handler.InviteAttendees)
+		events.GET("/:id/attendees", cr.handler.ListAttendees)
+		events.PATCH("/:id/attendees/:attendee_id", cr.handler.UpdateAttendeeStatus)
+		events.DELETE("/:id/attendees/:attendee_id", cr.handler.RemoveAttendee)
+		events.POST("/:id/rsvp", cr.handler.RespondToAttendance)
+
 		// Core event operations AFTER
 		events.POST("", cr.handler.CreateEvent)
 		events.GET("", cr.handler.ListEvents)
 		events.GET("/:id", cr.handler.GetEvent)
 		events.PUT("/:id", cr.handler.UpdateEvent)
 		events.DELETE("/:id", cr.handler.DeleteEvent)
+		events.POST("/:id/duplicate", cr.handler.DuplicateEvent)
 
 		// Occurrence operations
 		events.DELETE("/occurrence", cr.handler.DeleteOccurrence)
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// ChatRoutes handles the setup of Slack and Teams integration routes
+type ChatRoutes struct {
+	handler   *handlers.ChatHandler
+	jwtSecret string
+}
+
+// NewChatRoutes creates a new ChatRoutes instance
+func NewChatRoutes(handler *handlers.ChatHandler, jwtSecret string) *ChatRoutes {
+	return &ChatRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all chat integration routes. Installing the app and
+// managing an organization's installations needs the chat:manage permission.
+func (cr *ChatRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(cr.jwtSecret)
+
+	chatGroup := router.Group("/api/integrations/chat")
+
+	// Called by the chat platforms: installs are tied to the OAuth state and commands are signed
+	chatGroup.GET("/:provider/callback", cr.handler.CompleteInstall)
+	chatGroup.POST("/:provider/commands", cr.handler.HandleCommand)
+
+	userGroup := chatGroup.Group("")
+	userGroup.Use(auth)
+	userGroup.GET("/events", cr.handler.ListEvents)
+	userGroup.POST("/link", cr.handler.LinkUser)
+
+	manage := router.Group("/api/organizations/:id/chat")
+	manage.Use(auth, orgContext.RequireParam("id"), middleware.RequireOrgPermissions("chat:manage"))
+	manage.GET("/:provider/install", cr.handler.StartInstall)
+	manage.GET("/installations", cr.handler.ListInstallations)
+	manage.PATCH("/installations/:installation_id", cr.handler.UpdateInstallation)
+	manage.DELETE("/installations/:installation_id", cr.handler.DeleteInstallation)
+	manage.GET("/installations/:installation_id/channels", cr.handler.ListChannels)
+	manage.POST("/installations/:installation_id/channels", cr.handler.AddChannel)
+	manage.DELETE("/installations/:installation_id/channels/:channel_id", cr.handler.RemoveChannel)
+}
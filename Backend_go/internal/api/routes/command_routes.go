@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// CommandRoutes handles the setup of command palette routes
+type CommandRoutes struct {
+	handler   *handlers.CommandHandler
+	jwtSecret string
+}
+
+// NewCommandRoutes creates a new CommandRoutes instance
+func NewCommandRoutes(handler *handlers.CommandHandler, jwtSecret string) *CommandRoutes {
+	return &CommandRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all command palette routes
+func (cr *CommandRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	commandGroup := router.Group("/api/commands")
+	commandGroup.Use(middleware.NewAuthMiddleware(cr.jwtSecret), orgContext.Optional())
+
+	commandGroup.GET("", cr.handler.ListCommands)
+	commandGroup.POST("", cr.handler.ExecuteCommand)
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceRoutes handles the setup of device registration routes
+type DeviceRoutes struct {
+	handler   *handlers.DeviceHandler
+	jwtSecret string
+}
+
+// NewDeviceRoutes creates a new DeviceRoutes instance
+func NewDeviceRoutes(handler *handlers.DeviceHandler, jwtSecret string) *DeviceRoutes {
+	return &DeviceRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the device routes of the current user
+func (dr *DeviceRoutes) RegisterRoutes(router *gin.Engine) {
+	devices := router.Group("/api/me/devices")
+	devices.Use(middleware.NewAuthMiddleware(dr.jwtSecret))
+
+	devices.GET("", dr.handler.ListDevices)
+	devices.POST("", dr.handler.RegisterDevice)
+	devices.DELETE("/:device_id", dr.handler.UnregisterDevice)
+}
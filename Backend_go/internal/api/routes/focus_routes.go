@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// FocusRoutes handles the setup of focus session routes
+type FocusRoutes struct {
+	handler   *handlers.FocusHandler
+	jwtSecret string
+}
+
+// NewFocusRoutes creates a new FocusRoutes instance
+func NewFocusRoutes(handler *handlers.FocusHandler, jwtSecret string) *FocusRoutes {
+	return &FocusRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the focus session, statistics and heatmap routes of the
+// current user
+func (fr *FocusRoutes) RegisterRoutes(router *gin.Engine) {
+	focus := router.Group("/api/focus")
+	focus.Use(middleware.NewAuthMiddleware(fr.jwtSecret))
+
+	focus.POST("/sessions", fr.handler.StartFocusSession)
+	focus.GET("/sessions", fr.handler.ListFocusSessions)
+	focus.GET("/sessions/current", fr.handler.GetCurrentFocusSession)
+	focus.GET("/sessions/:id", fr.handler.GetFocusSession)
+	focus.DELETE("/sessions/:id", fr.handler.DeleteFocusSession)
+	focus.POST("/sessions/:id/stop", fr.handler.StopFocusSession)
+	focus.POST("/sessions/:id/interruptions", fr.handler.RecordFocusInterruption)
+
+	focus.GET("/stats/daily", fr.handler.GetDailyFocusStats)
+	focus.GET("/stats/weekly", fr.handler.GetWeeklyFocusStats)
+	focus.GET("/heatmap", fr.handler.GetFocusHeatmap)
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// GeofenceRoutes handles the setup of location-based todo reminder routes
+type GeofenceRoutes struct {
+	handler   *handlers.GeofenceHandler
+	jwtSecret string
+}
+
+// NewGeofenceRoutes creates a new GeofenceRoutes instance
+func NewGeofenceRoutes(handler *handlers.GeofenceHandler, jwtSecret string) *GeofenceRoutes {
+	return &GeofenceRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the geofence routes of todos and of mobile clients
+func (gr *GeofenceRoutes) RegisterRoutes(router *gin.Engine) {
+	todos := router.Group("/api/todos")
+	todos.Use(middleware.NewAuthMiddleware(gr.jwtSecret))
+
+	todos.GET("/:id/geofence", gr.handler.GetTodoGeofence)
+	todos.PUT("/:id/geofence", gr.handler.SetTodoGeofence)
+	todos.DELETE("/:id/geofence", gr.handler.DeleteTodoGeofence)
+
+	todos.GET("/geofences/active", gr.handler.GetActiveGeofences)
+	todos.POST("/geofences/events", gr.handler.ReportGeofenceEvent)
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// HabitLinkRoutes handles the setup of habit link routes
+type HabitLinkRoutes struct {
+	handler   *handlers.HabitLinkHandler
+	jwtSecret string
+}
+
+// NewHabitLinkRoutes creates a new HabitLinkRoutes instance
+func NewHabitLinkRoutes(handler *handlers.HabitLinkHandler, jwtSecret string) *HabitLinkRoutes {
+	return &HabitLinkRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the link routes of a habit
+func (hr *HabitLinkRoutes) RegisterRoutes(router *gin.Engine) {
+	links := router.Group("/api/habits/:id/link")
+	links.Use(middleware.NewAuthMiddleware(hr.jwtSecret))
+
+	links.GET("", hr.handler.GetHabitLink)
+	links.PUT("", hr.handler.SetHabitLink)
+	links.DELETE("", hr.handler.DeleteHabitLink)
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// InboundRoutes handles the setup of email-in routes
+type InboundRoutes struct {
+	handler   *handlers.InboundHandler
+	jwtSecret string
+}
+
+// NewInboundRoutes creates a new InboundRoutes instance
+func NewInboundRoutes(handler *handlers.InboundHandler, jwtSecret string) *InboundRoutes {
+	return &InboundRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all email-in routes
+func (ir *InboundRoutes) RegisterRoutes(router *gin.Engine) {
+	inboundGroup := router.Group("/api/inbound")
+
+	// The mail provider authenticates with the shared ingestion secret instead of a JWT
+	inboundGroup.POST("/email", ir.handler.ReceiveEmail)
+
+	userGroup := inboundGroup.Group("")
+	userGroup.Use(middleware.NewAuthMiddleware(ir.jwtSecret))
+	userGroup.GET("/address", ir.handler.GetAddress)
+	userGroup.PATCH("/address", ir.handler.UpdateAddress)
+	userGroup.POST("/address/regenerate", ir.handler.RegenerateAddress)
+	userGroup.GET("/messages", ir.handler.ListMessages)
+	userGroup.GET("/todos/:todo_id/attachments", ir.handler.ListTodoAttachments)
+	userGroup.GET("/attachments/:id", ir.handler.DownloadAttachment)
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// IntegrationRoutes handles the setup of organization integration status routes
+type IntegrationRoutes struct {
+	handler   *handlers.IntegrationHandler
+	jwtSecret string
+}
+
+// NewIntegrationRoutes creates a new IntegrationRoutes instance
+func NewIntegrationRoutes(handler *handlers.IntegrationHandler, jwtSecret string) *IntegrationRoutes {
+	return &IntegrationRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the integration routes. Any member can see the status;
+// reconnecting needs permission to update the organization.
+func (ir *IntegrationRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	integrationGroup := router.Group("/api/organizations/:id/integrations")
+	integrationGroup.Use(middleware.NewAuthMiddleware(ir.jwtSecret), orgContext.RequireParam("id"))
+
+	integrationGroup.GET("", middleware.RequireOrgPermissions("organizations:read"), ir.handler.ListIntegrations)
+	integrationGroup.POST("/:integration_id/reconnect", middleware.RequireOrgPermissions("organizations:update"), ir.handler.ReconnectIntegration)
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// InvitationRoutes handles the setup of organization invitation routes
+type InvitationRoutes struct {
+	handler   *handlers.InvitationHandler
+	jwtSecret string
+}
+
+// NewInvitationRoutes creates a new InvitationRoutes instance
+func NewInvitationRoutes(handler *handlers.InvitationHandler, jwtSecret string) *InvitationRoutes {
+	return &InvitationRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the invitation routes. Managing invitations needs permission
+// to update the organization; accepting one only needs to be signed in as the invitee.
+func (ir *InvitationRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(ir.jwtSecret)
+
+	router.POST("/api/organizations/invites/accept", auth, ir.handler.AcceptInvitation)
+
+	invites := router.Group("/api/organizations/:id/invites")
+	invites.Use(auth, orgContext.RequireParam("id"), middleware.RequireOrgPermissions("organizations:update"))
+
+	invites.GET("", ir.handler.ListInvitations)
+	invites.POST("", ir.handler.CreateInvitation)
+	invites.DELETE("/:invite_id", ir.handler.RevokeInvitation)
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// LegalRoutes handles the setup of legal document and consent routes
+type LegalRoutes struct {
+	handler   *handlers.LegalHandler
+	jwtSecret string
+}
+
+// NewLegalRoutes creates a new LegalRoutes instance
+func NewLegalRoutes(handler *handlers.LegalHandler, jwtSecret string) *LegalRoutes {
+	return &LegalRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all legal routes. They are exempt from the consent gate.
+func (lr *LegalRoutes) RegisterRoutes(router *gin.Engine) {
+	legalGroup := router.Group("/api/legal")
+	legalGroup.GET("/documents", lr.handler.GetDocuments)
+
+	consentGroup := legalGroup.Group("/consents")
+	consentGroup.Use(middleware.NewAuthMiddleware(lr.jwtSecret))
+	consentGroup.GET("", lr.handler.GetConsents)
+	consentGroup.POST("", lr.handler.AcceptConsents)
+}
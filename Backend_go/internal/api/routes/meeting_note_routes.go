@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// MeetingNoteRoutes handles the setup of meeting note routes
+type MeetingNoteRoutes struct {
+	handler   *handlers.MeetingNoteHandler
+	jwtSecret string
+}
+
+// NewMeetingNoteRoutes creates a new MeetingNoteRoutes instance
+func NewMeetingNoteRoutes(handler *handlers.MeetingNoteHandler, jwtSecret string) *MeetingNoteRoutes {
+	return &MeetingNoteRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the routes for the notes and action items of event occurrences
+func (mr *MeetingNoteRoutes) RegisterRoutes(router *gin.Engine) {
+	notes := router.Group("/api/calendar/events/:id/notes")
+	notes.Use(middleware.NewAuthMiddleware(mr.jwtSecret))
+
+	notes.GET("", mr.handler.GetMeetingNote)
+	notes.PUT("", mr.handler.SaveMeetingNote)
+	notes.DELETE("", mr.handler.DeleteMeetingNote)
+	notes.GET("/action-items", mr.handler.ListActionItems)
+	notes.POST("/action-items", mr.handler.ExtractActionItems)
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// MemberImportRoutes handles the setup of bulk member import routes
+type MemberImportRoutes struct {
+	handler   *handlers.MemberImportHandler
+	jwtSecret string
+}
+
+// NewMemberImportRoutes creates a new MemberImportRoutes instance
+func NewMemberImportRoutes(handler *handlers.MemberImportHandler, jwtSecret string) *MemberImportRoutes {
+	return &MemberImportRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the member import routes. Importing members invites people,
+// so it needs the same permission as managing invitations.
+func (mr *MemberImportRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	members := router.Group("/api/organizations/:id/members")
+	members.Use(middleware.NewAuthMiddleware(mr.jwtSecret), orgContext.RequireParam("id"), middleware.RequireOrgPermissions("organizations:update"))
+
+	members.POST("/import", mr.handler.ImportMembers)
+	members.GET("/imports", mr.handler.ListImports)
+	members.GET("/imports/:import_id", mr.handler.GetImport)
+}
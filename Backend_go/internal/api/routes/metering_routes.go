@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// MeteringRoutes handles the setup of metered usage routes
+type MeteringRoutes struct {
+	handler   *handlers.MeteringHandler
+	jwtSecret string
+}
+
+// NewMeteringRoutes creates a new MeteringRoutes instance
+func NewMeteringRoutes(handler *handlers.MeteringHandler, jwtSecret string) *MeteringRoutes {
+	return &MeteringRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the usage reporting and admin usage routes
+func (mr *MeteringRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	meteringGroup := router.Group("/api/metering")
+	meteringGroup.Use(middleware.NewAuthMiddleware(mr.jwtSecret), orgContext.Optional())
+	meteringGroup.POST("/events", mr.handler.ReportUsage)
+
+	adminGroup := router.Group("/api/admin/metering")
+	adminGroup.Use(middleware.NewAuthMiddleware(mr.jwtSecret))
+	adminGroup.Use(middleware.RequireRoles("admin"))
+	adminGroup.GET("/usage", mr.handler.GetUsage)
+}
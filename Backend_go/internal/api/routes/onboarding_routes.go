@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// OnboardingRoutes handles the setup of onboarding wizard routes
+type OnboardingRoutes struct {
+	handler   *handlers.OnboardingHandler
+	jwtSecret string
+}
+
+// NewOnboardingRoutes creates a new OnboardingRoutes instance
+func NewOnboardingRoutes(handler *handlers.OnboardingHandler, jwtSecret string) *OnboardingRoutes {
+	return &OnboardingRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all onboarding routes
+func (or *OnboardingRoutes) RegisterRoutes(router *gin.Engine) {
+	onboardingGroup := router.Group("/api/onboarding")
+	onboardingGroup.Use(middleware.NewAuthMiddleware(or.jwtSecret))
+
+	onboardingGroup.POST("", or.handler.StartOnboarding)
+	onboardingGroup.GET("/templates", or.handler.ListTemplates)
+	onboardingGroup.GET("/:id", or.handler.GetProgress)
+	onboardingGroup.POST("/:id/invites", or.handler.InviteMembers)
+	onboardingGroup.POST("/:id/template", or.handler.ChooseTemplate)
+	onboardingGroup.POST("/:id/seed", or.handler.SeedSamples)
+	onboardingGroup.POST("/:id/steps/:step/complete", or.handler.CompleteStep)
+}
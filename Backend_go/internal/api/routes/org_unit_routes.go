@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// OrgUnitRoutes handles the setup of organizational unit routes
+type OrgUnitRoutes struct {
+	handler        *handlers.OrgUnitHandler
+	projectHandler *handlers.ProjectHandler
+	units          middleware.UnitPermissionResolver
+	jwtSecret      string
+}
+
+// NewOrgUnitRoutes creates a new OrgUnitRoutes instance. The resolver grants the roles
+// members hold in a unit on the unit routes.
+func NewOrgUnitRoutes(handler *handlers.OrgUnitHandler, projectHandler *handlers.ProjectHandler, units middleware.UnitPermissionResolver, jwtSecret string) *OrgUnitRoutes {
+	return &OrgUnitRoutes{
+		handler:        handler,
+		projectHandler: projectHandler,
+		units:          units,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the unit routes of organizations and the route that moves a
+// project between units
+func (ur *OrgUnitRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(ur.jwtSecret)
+	inherit := middleware.InheritUnitPermissions(ur.units, "unit_id")
+
+	units := router.Group("/api/organizations/:id/units")
+	units.Use(auth, orgContext.RequireParam("id"))
+	units.GET("", middleware.RequireOrgPermissions("organizations:read"), ur.handler.ListUnits)
+	// Creating and moving units check the parent units in the handler
+	units.POST("", ur.handler.CreateUnit)
+	units.GET("/:unit_id", middleware.RequireOrgPermissions("organizations:read"), ur.handler.GetUnit)
+	units.PUT("/:unit_id", ur.handler.UpdateUnit)
+	// Deleting a unit moves its projects to the parent, so unit roles alone do not allow it
+	units.DELETE("/:unit_id", middleware.RequireOrgPermissions("organizations:update"), ur.handler.DeleteUnit)
+
+	units.GET("/:unit_id/members", middleware.RequireOrgPermissions("organizations:read"), ur.handler.ListUnitMembers)
+	units.PUT("/:unit_id/members/:user_id", inherit, middleware.RequireOrgPermissions("roles:assign"), ur.handler.SetUnitMember)
+	units.DELETE("/:unit_id/members/:user_id", inherit, middleware.RequireOrgPermissions("roles:assign"), ur.handler.RemoveUnitMember)
+
+	units.GET("/:unit_id/projects", inherit, middleware.RequireOrgPermissions("projects:read"), ur.handler.ListUnitProjects)
+	units.GET("/:unit_id/report", inherit, middleware.RequireOrgPermissions("organizations:read"), ur.handler.GetUnitReport)
+
+	router.PUT("/api/projects/:id/unit", auth, orgContext.Require(), ur.projectHandler.RequireProjectInOrganization, ur.handler.MoveProjectToUnit)
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// PresenceRoutes handles the setup of presence routes
+type PresenceRoutes struct {
+	handler   *handlers.PresenceHandler
+	jwtSecret string
+}
+
+// NewPresenceRoutes creates a new PresenceRoutes instance
+func NewPresenceRoutes(handler *handlers.PresenceHandler, jwtSecret string) *PresenceRoutes {
+	return &PresenceRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all presence routes. Presence changes are pushed over
+// the notifications WebSocket; these endpoints serve the initial state.
+func (pr *PresenceRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	presenceGroup := router.Group("/api/presence")
+	presenceGroup.Use(middleware.NewAuthMiddleware(pr.jwtSecret))
+
+	presenceGroup.GET("", orgContext.Require(), pr.handler.GetPresence)
+	presenceGroup.GET("/viewers", pr.handler.GetViewers)
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
+	"github.com/gin-gonic/gin"
+)
+
+// ProjectDuplicationRoutes handles the setup of project duplication routes
+type ProjectDuplicationRoutes struct {
+	handler        *handlers.ProjectDuplicationHandler
+	projectHandler *handlers.ProjectHandler
+	jwtSecret      string
+}
+
+// NewProjectDuplicationRoutes creates a new ProjectDuplicationRoutes instance. The project
+// handler checks that the project belongs to the caller's organization.
+func NewProjectDuplicationRoutes(handler *handlers.ProjectDuplicationHandler, projectHandler *handlers.ProjectHandler, jwtSecret string) *ProjectDuplicationRoutes {
+	return &ProjectDuplicationRoutes{
+		handler:        handler,
+		projectHandler: projectHandler,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the duplication routes of a project. Duplicating creates a
+// project, so it needs the create permission and counts against the plan's projects.
+func (dr *ProjectDuplicationRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext, plans middleware.PlanEnforcer) {
+	projects := router.Group("/api/projects/:id")
+	projects.Use(
+		middleware.NewAuthMiddleware(dr.jwtSecret),
+		orgContext.Require(),
+		dr.projectHandler.RequireProjectInOrganization,
+	)
+
+	projects.POST("/duplicate",
+		middleware.RequireOrgPermissions("projects:read", "projects:create"),
+		middleware.RequirePlanQuota(plans, billing.QuotaProjects),
+		dr.handler.DuplicateProject)
+	projects.GET("/duplications/:duplication_id",
+		middleware.RequireOrgPermissions("projects:read"),
+		dr.handler.GetDuplication)
+}
@@ -3,28 +3,40 @@ package routes
 import (
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
 	"github.com/gin-gonic/gin"
 )
 
 // ProjectRoutes handles the setup of project-related routes
 type ProjectRoutes struct {
-	handler   *handlers.ProjectHandler
-	jwtSecret string
+	handler     *handlers.ProjectHandler
+	taskHandler *handlers.TaskHandler
+	jwtSecret   string
 }
 
-// NewProjectRoutes creates a new ProjectRoutes instance
-func NewProjectRoutes(handler *handlers.ProjectHandler, jwtSecret string) *ProjectRoutes {
+// NewProjectRoutes creates a new ProjectRoutes instance. The task handler serves the
+// project views computed from its tasks.
+func NewProjectRoutes(handler *handlers.ProjectHandler, taskHandler *handlers.TaskHandler, jwtSecret string) *ProjectRoutes {
 	return &ProjectRoutes{
-		handler:   handler,
-		jwtSecret: jwtSecret,
+		handler:     handler,
+		taskHandler: taskHandler,
+		jwtSecret:   jwtSecret,
 	}
 }
 
 // RegisterRoutes registers all project-related routes
-func (pr *ProjectRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.CacheMiddleware) {
-	// Create a project group with authentication middleware
+func (pr *ProjectRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.CacheMiddleware, orgContext *middleware.OrganizationContext, plans middleware.PlanEnforcer, units middleware.UnitPermissionResolver) {
+	// Create a project group with authentication middleware; projects always belong to the
+	// organization named by the X-Organization-ID header, and roles in the unit of a project
+	// apply to it as well
 	projectGroup := router.Group("/api/projects")
-	projectGroup.Use(middleware.NewAuthMiddleware(pr.jwtSecret))
+	projectGroup.Use(
+		middleware.NewAuthMiddleware(pr.jwtSecret),
+		orgContext.Require(),
+		middleware.InheritProjectUnitPermissions(units, "id"),
+		middleware.RequireResourcePermission("projects"),
+	)
+	scoped := pr.handler.RequireProjectInOrganization
 
 	// @Summary Create a new project
 	// @Description Create a new project with the provided information
@@ -40,7 +52,7 @@ func (pr *ProjectRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.Ca
 	// @Failure 409 {object} map[string]string "Project name already exists"
 	// @Failure 500 {object} map[string]string "Internal server error"
 	// @Router /api/projects [post]
-	projectGroup.POST("", cache.CacheInvalidate("projects:*"), pr.handler.CreateProject)
+	projectGroup.POST("", middleware.RequirePlanQuota(plans, billing.QuotaProjects), cache.CacheInvalidate("projects:*"), pr.handler.CreateProject)
 
 	// @Summary Get all projects
 	// @Description Get all projects with pagination and filtering
@@ -89,7 +101,7 @@ func (pr *ProjectRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.Ca
 	// @Failure 404 {object} map[string]string "Project not found"
 	// @Failure 500 {object} map[string]string "Internal server error"
 	// @Router /api/projects/{id}/details [get]
-	projectGroup.GET("/:id/details", cache.CacheResponse(), pr.handler.GetProjectDetails)
+	projectGroup.GET("/:id/details", scoped, cache.CacheResponse(), pr.handler.GetProjectDetails)
 
 	// @Summary Update a project
 	// @Description Update an existing project's information
@@ -140,7 +152,7 @@ func (pr *ProjectRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.Ca
 	// @Failure 404 {object} map[string]string "Project not found"
 	// @Failure 500 {object} map[string]string "Internal server error"
 	// @Router /api/projects/{id}/members [post]
-	projectGroup.POST("/:id/members", cache.CacheInvalidate("projects:*"), pr.handler.AddProjectMember)
+	projectGroup.POST("/:id/members", scoped, cache.CacheInvalidate("projects:*"), pr.handler.AddProjectMember)
 
 	// @Summary Remove a member from a project
 	// @Description Remove a member from an existing project
@@ -157,7 +169,7 @@ func (pr *ProjectRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.Ca
 	// @Failure 404 {object} map[string]string "Project or member not found"
 	// @Failure 500 {object} map[string]string "Internal server error"
 	// @Router /api/projects/{id}/members/{userId} [delete]
-	projectGroup.DELETE("/:id/members/:userId", cache.CacheInvalidate("projects:*"), pr.handler.RemoveProjectMember)
+	projectGroup.DELETE("/:id/members/:userId", scoped, cache.CacheInvalidate("projects:*"), pr.handler.RemoveProjectMember)
 
 	// @Summary Update project status
 	// @Description Update the status of an existing project
@@ -174,5 +186,20 @@ func (pr *ProjectRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.Ca
 	// @Failure 404 {object} map[string]string "Project not found"
 	// @Failure 500 {object} map[string]string "Internal server error"
 	// @Router /api/projects/{id}/status [put]
-	projectGroup.PUT("/:id/status", cache.CacheInvalidate("projects:*"), pr.handler.UpdateProjectStatus)
+	projectGroup.PUT("/:id/status", scoped, cache.CacheInvalidate("projects:*"), pr.handler.UpdateProjectStatus)
+
+	// @Summary Get the critical path of a project
+	// @Description Get the chain of dependent tasks that decides when the project can finish
+	// @Tags projects
+	// @Produce json
+	// @Security BearerAuth
+	// @Param id path string true "Project ID" format(uuid)
+	// @Success 200 {object} dto.CriticalPathResponse "Critical path"
+	// @Failure 400 {object} map[string]string "Invalid project ID"
+	// @Failure 401 {object} map[string]string "Unauthorized"
+	// @Failure 403 {object} map[string]string "Insufficient permissions"
+	// @Failure 409 {object} map[string]string "Task dependencies form a cycle"
+	// @Failure 500 {object} map[string]string "Internal server error"
+	// @Router /api/projects/{id}/critical-path [get]
+	projectGroup.GET("/:id/critical-path", scoped, pr.taskHandler.GetCriticalPath)
 }
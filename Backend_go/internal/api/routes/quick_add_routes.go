@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// QuickAddRoutes handles the setup of quick-add routes
+type QuickAddRoutes struct {
+	handler   *handlers.QuickAddHandler
+	jwtSecret string
+}
+
+// NewQuickAddRoutes creates a new QuickAddRoutes instance
+func NewQuickAddRoutes(handler *handlers.QuickAddHandler, jwtSecret string) *QuickAddRoutes {
+	return &QuickAddRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the quick-add route. The organization context is optional and
+// only limits the projects tasks may be created in.
+func (qr *QuickAddRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	quickAddGroup := router.Group("/api/quick-add")
+	quickAddGroup.Use(middleware.NewAuthMiddleware(qr.jwtSecret), orgContext.Optional())
+
+	quickAddGroup.POST("", qr.handler.QuickAdd)
+}
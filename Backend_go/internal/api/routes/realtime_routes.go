@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RealtimeRoutes handles the setup of the realtime event stream
+type RealtimeRoutes struct {
+	handler   *handlers.RealtimeHandler
+	jwtSecret string
+}
+
+// NewRealtimeRoutes creates a new RealtimeRoutes instance
+func NewRealtimeRoutes(handler *handlers.RealtimeHandler, jwtSecret string) *RealtimeRoutes {
+	return &RealtimeRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the realtime WebSocket endpoint
+func (rr *RealtimeRoutes) RegisterRoutes(router *gin.Engine) {
+	// Browsers cannot set headers on a WebSocket handshake, so accept the token as a query parameter
+	tokenFromQuery := func(c *gin.Context) {
+		if token := c.Query("token"); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+
+	router.GET("/ws", tokenFromQuery, middleware.NewAuthMiddleware(rr.jwtSecret), rr.handler.Connect)
+}
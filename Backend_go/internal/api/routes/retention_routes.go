@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionRoutes handles the setup of retention policy routes
+type RetentionRoutes struct {
+	handler   *handlers.RetentionHandler
+	jwtSecret string
+}
+
+// NewRetentionRoutes creates a new RetentionRoutes instance
+func NewRetentionRoutes(handler *handlers.RetentionHandler, jwtSecret string) *RetentionRoutes {
+	return &RetentionRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the retention policy routes of organizations. Exporting what
+// is about to expire may include the audit log, so it needs the same permission.
+func (rr *RetentionRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	retention := router.Group("/api/organizations/:id/retention")
+	retention.Use(middleware.NewAuthMiddleware(rr.jwtSecret), orgContext.RequireParam("id"))
+	retention.GET("", middleware.RequireOrgPermissions("organizations:read"), rr.handler.ListPolicies)
+	retention.PUT("/:category", middleware.RequireOrgPermissions("organizations:update"), rr.handler.SetPolicy)
+	retention.GET("/:category/pending", middleware.RequireOrgPermissions("organizations:update"), rr.handler.GetPending)
+
+	// Scheduling and cancelling a deletion skip the organization context, which rejects
+	// changes to an organization awaiting deletion; only its owner may do either
+	deletion := router.Group("/api/organizations/:id/deletion")
+	deletion.Use(middleware.NewAuthMiddleware(rr.jwtSecret))
+	deletion.GET("", orgContext.RequireParam("id"), rr.handler.GetDeletion)
+	deletion.POST("", rr.handler.ScheduleDeletion)
+	deletion.DELETE("", rr.handler.CancelDeletion)
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchRoutes handles the setup of search routes
+type SearchRoutes struct {
+	handler   *handlers.SearchHandler
+	jwtSecret string
+}
+
+// NewSearchRoutes creates a new SearchRoutes instance
+func NewSearchRoutes(handler *handlers.SearchHandler, jwtSecret string) *SearchRoutes {
+	return &SearchRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all search routes. The search audit trail and reindex of an
+// organization are for those who manage it.
+func (sr *SearchRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	searchGroup := router.Group("/api/search")
+	searchGroup.Use(middleware.NewAuthMiddleware(sr.jwtSecret), orgContext.Optional())
+
+	searchGroup.GET("", sr.handler.Search)
+	searchGroup.GET("/semantic", sr.handler.SemanticSearch)
+	searchGroup.GET("/languages", sr.handler.ListLanguages)
+	searchGroup.POST("/reindex", sr.handler.ReindexPersonal)
+
+	auditGroup := router.Group("/api/organizations/:id/search-audit")
+	auditGroup.Use(middleware.NewAuthMiddleware(sr.jwtSecret), orgContext.RequireParam("id"), middleware.RequireOrgPermissions("organizations:update"))
+
+	auditGroup.GET("", sr.handler.ListAudit)
+
+	reindexGroup := router.Group("/api/organizations/:id/search-reindex")
+	reindexGroup.Use(middleware.NewAuthMiddleware(sr.jwtSecret), orgContext.RequireParam("id"), middleware.RequireOrgPermissions("organizations:update"))
+
+	reindexGroup.POST("", sr.handler.ReindexOrganization)
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// SettingsRoutes handles the setup of organization default and project settings routes
+type SettingsRoutes struct {
+	handler        *handlers.SettingsHandler
+	projectHandler *handlers.ProjectHandler
+	jwtSecret      string
+}
+
+// NewSettingsRoutes creates a new SettingsRoutes instance. The project handler checks
+// that the project belongs to the caller's organization.
+func NewSettingsRoutes(handler *handlers.SettingsHandler, projectHandler *handlers.ProjectHandler, jwtSecret string) *SettingsRoutes {
+	return &SettingsRoutes{
+		handler:        handler,
+		projectHandler: projectHandler,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the default settings routes of organizations and the
+// override and resolution routes of projects
+func (sr *SettingsRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(sr.jwtSecret)
+
+	defaults := router.Group("/api/organizations/:id/settings/defaults")
+	defaults.Use(auth, orgContext.RequireParam("id"))
+	defaults.GET("", middleware.RequireOrgPermissions("organizations:read"), sr.handler.GetOrganizationDefaults)
+	defaults.PUT("", middleware.RequireOrgPermissions("organizations:update"), sr.handler.SetOrganizationDefaults)
+
+	projectSettings := router.Group("/api/projects/:id/settings")
+	projectSettings.Use(auth, orgContext.Require(), sr.projectHandler.RequireProjectInOrganization)
+	projectSettings.GET("", middleware.RequireOrgPermissions("projects:read"), sr.handler.GetProjectSettings)
+	projectSettings.PUT("", middleware.RequireOrgPermissions("projects:update"), sr.handler.SetProjectSettings)
+	projectSettings.GET("/resolved", middleware.RequireOrgPermissions("projects:read"), sr.handler.ResolveProjectSettings)
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// SLARoutes handles the setup of working hours and project clock routes
+type SLARoutes struct {
+	handler        *handlers.SLAHandler
+	projectHandler *handlers.ProjectHandler
+	jwtSecret      string
+}
+
+// NewSLARoutes creates a new SLARoutes instance. The project handler checks that the
+// project belongs to the caller's organization.
+func NewSLARoutes(handler *handlers.SLAHandler, projectHandler *handlers.ProjectHandler, jwtSecret string) *SLARoutes {
+	return &SLARoutes{
+		handler:        handler,
+		projectHandler: projectHandler,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the working hours routes of organizations and the clock
+// routes of projects
+func (sr *SLARoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(sr.jwtSecret)
+
+	workingHours := router.Group("/api/organizations/:id/working-hours")
+	workingHours.Use(auth, orgContext.RequireParam("id"))
+	workingHours.GET("", middleware.RequireOrgPermissions("organizations:read"), sr.handler.GetWorkingHours)
+	workingHours.PUT("", middleware.RequireOrgPermissions("organizations:update"), sr.handler.SetWorkingHours)
+
+	clock := router.Group("/api/projects/:id/clock")
+	clock.Use(auth, orgContext.Require(), sr.projectHandler.RequireProjectInOrganization)
+	clock.GET("", middleware.RequireOrgPermissions("projects:read"), sr.handler.GetProjectClock)
+	clock.PUT("", middleware.RequireOrgPermissions("projects:update"), sr.handler.SetProjectClock)
+}
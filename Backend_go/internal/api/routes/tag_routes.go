@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TagRoutes handles the setup of tag routes
+type TagRoutes struct {
+	handler   *handlers.TagHandler
+	jwtSecret string
+}
+
+// NewTagRoutes creates a new TagRoutes instance
+func NewTagRoutes(handler *handlers.TagHandler, jwtSecret string) *TagRoutes {
+	return &TagRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the tag management routes of organizations and the routes
+// that tag entities and search by tag
+func (tr *TagRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(tr.jwtSecret)
+
+	manage := router.Group("/api/organizations/:id/tags")
+	manage.Use(auth, orgContext.RequireParam("id"))
+	manage.GET("", middleware.RequireOrgPermissions("organizations:read"), tr.handler.ListTags)
+	manage.POST("", middleware.RequireOrgPermissions("tags:create"), tr.handler.CreateTag)
+	manage.PUT("/:tag_id", middleware.RequireOrgPermissions("tags:manage"), tr.handler.UpdateTag)
+	manage.DELETE("/:tag_id", middleware.RequireOrgPermissions("tags:manage"), tr.handler.DeleteTag)
+	manage.POST("/:tag_id/merge", middleware.RequireOrgPermissions("tags:manage"), tr.handler.MergeTags)
+
+	// Members tag and find what they can see, so these need no permission of their own
+	tagged := router.Group("/api/tags")
+	tagged.Use(auth, orgContext.Require())
+	tagged.GET("/search", tr.handler.SearchTagged)
+	tagged.GET("/entities/:type/:entity_id", tr.handler.GetEntityTags)
+	tagged.PUT("/entities/:type/:entity_id", tr.handler.SetEntityTags)
+}
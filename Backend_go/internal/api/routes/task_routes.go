@@ -23,8 +23,9 @@ func NewTaskRoutes(handler *handlers.TaskHandler, jwtSecret string) *TaskRoutes
 	}
 }
 
-// RegisterRoutes registers all task-related routes
-func (r *TaskRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.CacheMiddleware) {
+// RegisterRoutes registers all task-related routes. Apart from the caller's own analytics
+// and work, task routes act inside the organization named by the X-Organization-ID header.
+func (r *TaskRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.CacheMiddleware, orgContext *middleware.OrganizationContext) {
 	// Initialize task-specific middleware
 	validation := middleware.NewValidationMiddleware()
 	metrics := middleware.NewMetricsMiddleware()
@@ -35,37 +36,68 @@ func (r *TaskRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.CacheM
 		HalfOpenMaxRequests: 3,
 	})
 
-	tasks := router.Group("/api/tasks")
-	tasks.Use(middleware.NewAuthMiddleware(r.jwtSecret))
-	tasks.Use(metrics.CollectMetrics())
+	taskGroup := router.Group("/api/tasks")
+	taskGroup.Use(middleware.NewAuthMiddleware(r.jwtSecret))
+	taskGroup.Use(metrics.CollectMetrics())
 
 	// Apply circuit breaker to task operations to prevent cascading failures
-	tasks.Use(circuitBreaker.CircuitBreakerMiddleware())
+	taskGroup.Use(circuitBreaker.CircuitBreakerMiddleware())
+
+	// User-specific analytics cover the caller's tasks in every organization
+	analytics := taskGroup.Group("/analytics")
+	analytics.GET("/user", r.handler.GetUserTaskAnalytics)
+	analytics.GET("/user/summary", r.handler.GetUserTaskActivitySummary)
+
+	// Everything else needs membership in the organization and the matching tasks permission
+	tasks := taskGroup.Group("")
+	tasks.Use(orgContext.Require(), middleware.RequireResourcePermission("tasks"))
+	scoped := r.handler.RequireTaskInOrganization
 
 	// Read operations with caching
 	tasks.GET("", cache.CacheResponse(), r.handler.ListTasks)
-	tasks.GET("/:id", cache.CacheResponse(), r.handler.GetTask)
 	tasks.GET("/user/:user_id", cache.CacheResponse(), r.handler.ListTasks)
-	tasks.GET("/project/:project_id", cache.CacheResponse(), r.handler.GetProjectTasks)
+
+	// Not cached: these responses include live presence of viewers
+	tasks.GET("/:id", scoped, r.handler.GetTask)
+	tasks.GET("/:id/subtasks", scoped, r.handler.GetSubtasks)
+	tasks.GET("/:id/dependencies", scoped, r.handler.GetTaskDependencies)
+	tasks.GET("/project/:project_id", r.handler.GetProjectTasks)
+	tasks.GET("/project/:project_id/health", r.handler.GetProjectHealth)
 
 	// Write operations with cache invalidation and validation
 	tasks.POST("", validation.ValidateRequest(&dto.CreateTaskRequest{}), cache.CacheInvalidate("tasks:*"), r.handler.CreateTask)
-	tasks.PUT("/:id", validation.ValidateRequest(&dto.UpdateTaskRequest{}), cache.CacheInvalidate("tasks:*"), r.handler.UpdateTask)
-	tasks.DELETE("/:id", cache.CacheInvalidate("tasks:*"), r.handler.DeleteTask)
+	tasks.PUT("/:id", scoped, validation.ValidateRequest(&dto.UpdateTaskRequest{}), cache.CacheInvalidate("tasks:*"), r.handler.UpdateTask)
+	tasks.DELETE("/:id", scoped, cache.CacheInvalidate("tasks:*"), r.handler.DeleteTask)
 
 	// Status updates
-	tasks.PATCH("/:id/status", validation.ValidateRequest(&dto.UpdateTaskStatusRequest{}), cache.CacheInvalidate("tasks:*"), r.handler.UpdateTaskStatus)
-	tasks.PATCH("/:id/assign", validation.ValidateRequest(&dto.AssignTaskRequest{}), cache.CacheInvalidate("tasks:*"), r.handler.AssignTask)
+	tasks.PATCH("/:id/status", scoped, validation.ValidateRequest(&dto.UpdateTaskStatusRequest{}), cache.CacheInvalidate("tasks:*"), r.handler.UpdateTaskStatus)
+	tasks.PATCH("/:id/move", scoped, validation.ValidateRequest(&dto.MoveTaskRequest{}), cache.CacheInvalidate("tasks:*"), r.handler.MoveTask)
+	tasks.PATCH("/:id/assign", scoped, validation.ValidateRequest(&dto.AssignTaskRequest{}), cache.CacheInvalidate("tasks:*"), r.handler.AssignTask)
 
-	// Task analytics routes
-	analytics := tasks.Group("/analytics")
+	// Comments
+	tasks.GET("/:id/comments", scoped, r.handler.ListTaskComments)
+	tasks.POST("/:id/comments", scoped, r.handler.AddTaskComment)
+	tasks.DELETE("/:id/comments/:comment_id", scoped, r.handler.DeleteTaskComment)
 
-	// User-specific analytics
-	analytics.GET("/user", r.handler.GetUserTaskAnalytics)
-	analytics.GET("/user/summary", r.handler.GetUserTaskActivitySummary)
+	// Attachments of tasks and comments
+	tasks.GET("/:id/attachments", scoped, r.handler.ListTaskAttachments)
+	tasks.POST("/:id/attachments", scoped, r.handler.UploadTaskAttachment)
+	tasks.GET("/:id/attachments/:attachment_id", scoped, r.handler.DownloadTaskAttachment)
+	tasks.GET("/:id/attachments/:attachment_id/:variant", scoped, r.handler.DownloadTaskAttachmentPreview)
+	tasks.DELETE("/:id/attachments/:attachment_id", scoped, r.handler.DeleteTaskAttachment)
+	tasks.GET("/:id/comments/:comment_id/attachments", scoped, r.handler.ListCommentAttachments)
+	tasks.POST("/:id/comments/:comment_id/attachments", scoped, r.handler.UploadCommentAttachment)
+	tasks.GET("/:id/comments/:comment_id/attachments/:attachment_id", scoped, r.handler.DownloadCommentAttachment)
+	tasks.GET("/:id/comments/:comment_id/attachments/:attachment_id/:variant", scoped, r.handler.DownloadCommentAttachmentPreview)
+	tasks.DELETE("/:id/comments/:comment_id/attachments/:attachment_id", scoped, r.handler.DeleteCommentAttachment)
 
 	// Task-specific analytics
-	tasks.GET("/:id/analytics", r.handler.GetTaskAnalytics)
-	tasks.GET("/:id/analytics/summary", r.handler.GetTaskActivitySummary)
-	tasks.POST("/:id/analytics/record", validation.ValidateRequest(&dto.RecordUserActivityRequest{}), r.handler.RecordTaskActivity)
+	tasks.GET("/:id/analytics", scoped, r.handler.GetTaskAnalytics)
+	tasks.GET("/:id/analytics/summary", scoped, r.handler.GetTaskActivitySummary)
+	tasks.POST("/:id/analytics/record", scoped, validation.ValidateRequest(&dto.RecordUserActivityRequest{}), r.handler.RecordTaskActivity)
+
+	// The caller's own work spans every organization they belong to
+	me := router.Group("/api/me")
+	me.Use(middleware.NewAuthMiddleware(r.jwtSecret))
+	me.GET("/work", r.handler.GetMyWork)
 }
@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TimeTrackingRoutes handles the setup of timer, time entry and timesheet routes
+type TimeTrackingRoutes struct {
+	handler        *handlers.TimeTrackingHandler
+	taskHandler    *handlers.TaskHandler
+	projectHandler *handlers.ProjectHandler
+	jwtSecret      string
+}
+
+// NewTimeTrackingRoutes creates a new TimeTrackingRoutes instance. The task and project
+// handlers check that the task or project belongs to the caller's organization.
+func NewTimeTrackingRoutes(handler *handlers.TimeTrackingHandler, taskHandler *handlers.TaskHandler, projectHandler *handlers.ProjectHandler, jwtSecret string) *TimeTrackingRoutes {
+	return &TimeTrackingRoutes{
+		handler:        handler,
+		taskHandler:    taskHandler,
+		projectHandler: projectHandler,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the time tracking routes of tasks, the timesheets of members
+// and projects, and the caller's running timer
+func (tr *TimeTrackingRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(tr.jwtSecret)
+	read := middleware.RequireOrgPermissions("tasks:read")
+	write := middleware.RequireOrgPermissions("tasks:update")
+
+	// Logging time changes the task's actual hours, so it needs permission to update tasks
+	tasks := router.Group("/api/tasks/:id")
+	tasks.Use(auth, orgContext.Require(), tr.taskHandler.RequireTaskInOrganization)
+	tasks.POST("/timer/start", write, tr.handler.StartTimer)
+	tasks.POST("/timer/stop", write, tr.handler.StopTimer)
+	tasks.GET("/time", read, tr.handler.GetTaskTime)
+	tasks.GET("/time-entries", read, tr.handler.ListTimeEntries)
+	tasks.POST("/time-entries", write, tr.handler.CreateTimeEntry)
+	tasks.PUT("/time-entries/:entry_id", write, tr.handler.UpdateTimeEntry)
+	tasks.DELETE("/time-entries/:entry_id", write, tr.handler.DeleteTimeEntry)
+
+	timesheets := router.Group("/api/timesheets")
+	timesheets.Use(auth, orgContext.Require(), read)
+	timesheets.GET("/users/:user_id", tr.handler.GetUserTimesheet)
+
+	projects := router.Group("/api/projects/:id/timesheet")
+	projects.Use(auth, orgContext.Require(), tr.projectHandler.RequireProjectInOrganization)
+	projects.GET("", middleware.RequireOrgPermissions("projects:read"), tr.handler.GetProjectTimesheet)
+
+	// The running timer is the caller's own, whichever organization it is in
+	me := router.Group("/api/me")
+	me.Use(auth)
+	me.GET("/timer", tr.handler.GetRunningTimer)
+}
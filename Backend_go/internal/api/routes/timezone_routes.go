@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TimezoneRoutes handles the setup of timezone migration routes
+type TimezoneRoutes struct {
+	handler   *handlers.TimezoneHandler
+	jwtSecret string
+}
+
+// NewTimezoneRoutes creates a new TimezoneRoutes instance
+func NewTimezoneRoutes(handler *handlers.TimezoneHandler, jwtSecret string) *TimezoneRoutes {
+	return &TimezoneRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the timezone preview and migration routes
+func (tr *TimezoneRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.CacheMiddleware) {
+	tzGroup := router.Group("/api/users/timezone")
+	tzGroup.Use(middleware.NewAuthMiddleware(tr.jwtSecret))
+
+	tzGroup.POST("/preview", tr.handler.PreviewTimezoneMigration)
+	tzGroup.POST("/migrate", cache.CacheInvalidate("habits:*"), tr.handler.MigrateTimezone)
+}
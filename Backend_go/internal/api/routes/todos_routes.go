@@ -43,6 +43,13 @@ func (r *TodosRoutes) RegisterRoutes(router *gin.Engine, cache *middleware.Cache
 	todos.PATCH("/:id/complete", cache.CacheInvalidate("todos:*", "todo-lists:*"), r.handler.CompleteTodo)
 	todos.PATCH("/:id/uncomplete", cache.CacheInvalidate("todos:*", "todo-lists:*"), r.handler.UncompleteTodo)
 
+	// Attachments - the cached todo lists its attachments
+	todos.GET("/:id/attachments", r.handler.ListTodoAttachments)
+	todos.POST("/:id/attachments", cache.CacheInvalidate("todos:*"), r.handler.UploadTodoAttachment)
+	todos.GET("/:id/attachments/:attachment_id", r.handler.DownloadTodoAttachment)
+	todos.GET("/:id/attachments/:attachment_id/:variant", r.handler.DownloadTodoAttachmentPreview)
+	todos.DELETE("/:id/attachments/:attachment_id", cache.CacheInvalidate("todos:*"), r.handler.DeleteTodoAttachment)
+
 	// Todo Lists routes
 	todoLists := router.Group("/api/todo-lists")
 	todoLists.Use(middleware.NewAuthMiddleware(r.jwtSecret))
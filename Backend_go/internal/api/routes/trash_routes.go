@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TrashRoutes handles the setup of trash routes
+type TrashRoutes struct {
+	handler   *handlers.TrashHandler
+	jwtSecret string
+}
+
+// NewTrashRoutes creates a new TrashRoutes instance
+func NewTrashRoutes(handler *handlers.TrashHandler, jwtSecret string) *TrashRoutes {
+	return &TrashRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the routes for listing and restoring deleted items
+func (tr *TrashRoutes) RegisterRoutes(router *gin.Engine) {
+	trash := router.Group("/api/trash")
+	trash.Use(middleware.NewAuthMiddleware(tr.jwtSecret))
+
+	trash.GET("", tr.handler.ListTrash)
+	trash.POST("/:type/:id/restore", tr.handler.RestoreTrashItem)
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageRoutes handles the setup of the API usage dashboard route
+type UsageRoutes struct {
+	handler   *handlers.UsageHandler
+	jwtSecret string
+}
+
+// NewUsageRoutes creates a new UsageRoutes instance
+func NewUsageRoutes(handler *handlers.UsageHandler, jwtSecret string) *UsageRoutes {
+	return &UsageRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers the API usage route of the current user
+func (ur *UsageRoutes) RegisterRoutes(router *gin.Engine) {
+	router.GET("/api/me/usage", middleware.NewAuthMiddleware(ur.jwtSecret), ur.handler.GetMyUsage)
+}
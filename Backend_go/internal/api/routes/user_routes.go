@@ -36,6 +36,10 @@ func (ur *UserRoutes) RegisterRoutes(router *gin.Engine) {
 			// Apply validation to registration and login
 			public.POST("/register", validation.ValidateRequest(&dto.CreateUserRequest{}), ur.userHandler.CreateUser)
 			public.POST("/login", validation.ValidateRequest(&dto.LoginRequest{}), ur.userHandler.Login)
+
+			// Refresh tokens authenticate these requests themselves
+			public.POST("/refresh", ur.userHandler.RefreshToken)
+			public.POST("/refresh/revoke", ur.userHandler.RevokeRefreshToken)
 		}
 
 		// Protected routes with general API rate limiting
@@ -49,12 +53,20 @@ func (ur *UserRoutes) RegisterRoutes(router *gin.Engine) {
 			protected.GET("/profile", ur.userHandler.GetUser)
 			protected.PUT("/profile", validation.ValidateRequest(&dto.UpdateUserRequest{}), ur.userHandler.UpdateUser)
 			protected.DELETE("/profile", ur.userHandler.DeleteUser)
+			protected.PUT("/password", ur.userHandler.ChangePassword)
+
+			// UI preferences
+			protected.GET("/preferences", ur.userHandler.GetPreferences)
+			protected.PATCH("/preferences", ur.userHandler.UpdatePreferences)
 
 			// Session management
 			protected.GET("/sessions", ur.userHandler.GetUserSessions)
 			protected.POST("/sessions/:id/revoke", ur.userHandler.RevokeSession)
 			protected.POST("/logout", ur.userHandler.Logout)
 
+			// Access tokens limited to scopes, for integrations
+			protected.POST("/tokens", middleware.RejectAPIKeys(), ur.userHandler.CreateScopedToken)
+
 			// Analytics routes
 			analyticsGroup := protected.Group("/analytics")
 			{
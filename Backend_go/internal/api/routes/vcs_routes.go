@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
+	"github.com/gin-gonic/gin"
+)
+
+// VCSRoutes handles the setup of GitHub and GitLab integration routes
+type VCSRoutes struct {
+	handler   *handlers.VCSHandler
+	jwtSecret string
+}
+
+// NewVCSRoutes creates a new VCSRoutes instance
+func NewVCSRoutes(handler *handlers.VCSHandler, jwtSecret string) *VCSRoutes {
+	return &VCSRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all code platform integration routes. Connections belong to
+// the organization: any member can list them, and changing them needs the vcs:manage
+// permission.
+func (vr *VCSRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext, plans middleware.PlanEnforcer) {
+	auth := middleware.NewAuthMiddleware(vr.jwtSecret)
+	manage := middleware.RequireOrgPermissions("vcs:manage")
+
+	vcsGroup := router.Group("/api/integrations/vcs")
+
+	// Called by GitHub and GitLab: deliveries are verified with the connection's webhook secret
+	vcsGroup.POST("/webhooks/:id", vr.handler.HandleWebhook)
+
+	connections := vcsGroup.Group("/connections")
+	connections.Use(auth, orgContext.Require())
+	connections.GET("", vr.handler.ListConnections)
+	connections.POST("", manage, middleware.RequirePlanFeature(plans, billing.FeatureIntegrations), vr.handler.CreateConnection)
+	connections.PATCH("/:id", manage, vr.handler.UpdateConnection)
+	connections.DELETE("/:id", manage, vr.handler.DeleteConnection)
+
+	userGroup := vcsGroup.Group("")
+	userGroup.Use(auth, orgContext.Optional())
+	userGroup.GET("/tasks/:task_id/links", vr.handler.ListTaskLinks)
+	userGroup.POST("/tasks/:task_id/links", vr.handler.LinkTask)
+	userGroup.DELETE("/links/:id", vr.handler.UnlinkTask)
+}
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookRoutes handles the setup of webhook routes
+type WebhookRoutes struct {
+	handler   *handlers.WebhookHandler
+	jwtSecret string
+}
+
+// NewWebhookRoutes creates a new WebhookRoutes instance
+func NewWebhookRoutes(handler *handlers.WebhookHandler, jwtSecret string) *WebhookRoutes {
+	return &WebhookRoutes{
+		handler:   handler,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// RegisterRoutes registers all webhook routes. Personal webhooks live under
+// /api/webhooks; an organization's webhooks need the webhooks:manage permission.
+func (wr *WebhookRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext) {
+	auth := middleware.NewAuthMiddleware(wr.jwtSecret)
+
+	webhookGroup := router.Group("/api/webhooks")
+	webhookGroup.Use(auth)
+
+	webhookGroup.GET("/events", wr.handler.ListEvents)
+	webhookGroup.POST("", wr.handler.CreateWebhook)
+	webhookGroup.GET("", wr.handler.ListWebhooks)
+	webhookGroup.GET("/:id", wr.handler.GetWebhook)
+	webhookGroup.PATCH("/:id", wr.handler.UpdateWebhook)
+	webhookGroup.DELETE("/:id", wr.handler.DeleteWebhook)
+	webhookGroup.GET("/:id/deliveries", wr.handler.ListDeliveries)
+	webhookGroup.POST("/:id/deliveries/:delivery_id/retry", wr.handler.RetryDelivery)
+
+	orgGroup := router.Group("/api/organizations/:id/webhooks")
+	orgGroup.Use(auth, orgContext.RequireParam("id"), middleware.RequireOrgPermissions("webhooks:manage"))
+
+	orgGroup.POST("", wr.handler.CreateWebhook)
+	orgGroup.GET("", wr.handler.ListWebhooks)
+	orgGroup.GET("/:webhook_id", wr.handler.GetWebhook)
+	orgGroup.PATCH("/:webhook_id", wr.handler.UpdateWebhook)
+	orgGroup.DELETE("/:webhook_id", wr.handler.DeleteWebhook)
+	orgGroup.GET("/:webhook_id/deliveries", wr.handler.ListDeliveries)
+	orgGroup.POST("/:webhook_id/deliveries/:delivery_id/retry", wr.handler.RetryDelivery)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/handlers"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/api/middleware"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,43 +21,66 @@ func NewWorkflowRoutes(handler *handlers.WorkflowHandler, jwtSecret string) *Wor
 	}
 }
 
-// RegisterRoutes registers all workflow-related routes
-func (wr *WorkflowRoutes) RegisterRoutes(router *gin.Engine) {
-	// Create a workflow group with authentication middleware
+// RegisterRoutes registers all workflow-related routes. Every route acts inside the
+// organization named by the X-Organization-ID header and checks the caller's permissions there.
+func (wr *WorkflowRoutes) RegisterRoutes(router *gin.Engine, orgContext *middleware.OrganizationContext, plans middleware.PlanEnforcer) {
+	// Create a workflow group with authentication and organization middleware
 	workflowGroup := router.Group("/api/workflows")
-	workflowGroup.Use(middleware.NewAuthMiddleware(wr.jwtSecret))
+	workflowGroup.Use(middleware.NewAuthMiddleware(wr.jwtSecret), orgContext.Require())
+
+	read := middleware.RequireOrgPermissions("workflows:read")
+	create := middleware.RequireOrgPermissions("workflows:create")
+	update := middleware.RequireOrgPermissions("workflows:update")
+	remove := middleware.RequireOrgPermissions("workflows:delete")
+	execute := middleware.RequireOrgPermissions("workflows:execute")
+	scoped := wr.handler.RequireWorkflowInOrganization
+	execution := wr.handler.RequireExecutionInOrganization
+	stepExecution := wr.handler.RequireStepExecutionInOrganization
 
 	// Core workflow operations
-	workflowGroup.POST("", wr.handler.CreateWorkflow)
-	workflowGroup.GET("", wr.handler.ListWorkflows)
-	workflowGroup.GET("/:id", wr.handler.GetWorkflow)
-	workflowGroup.PUT("/:id", wr.handler.UpdateWorkflow)
-	workflowGroup.DELETE("/:id", wr.handler.DeleteWorkflow)
+	workflowGroup.POST("", create, middleware.RequirePlanQuota(plans, billing.QuotaWorkflows), wr.handler.CreateWorkflow)
+	workflowGroup.GET("", read, wr.handler.ListWorkflows)
+	workflowGroup.GET("/:id", read, wr.handler.GetWorkflow)
+	workflowGroup.PUT("/:id", update, wr.handler.UpdateWorkflow)
+	workflowGroup.DELETE("/:id", remove, wr.handler.DeleteWorkflow)
+
+	// Template library and cloning
+	workflowGroup.GET("/templates", read, wr.handler.ListWorkflowTemplates)
+	workflowGroup.PUT("/:id/template", update, scoped, wr.handler.MarkWorkflowTemplate)
+	workflowGroup.DELETE("/:id/template", update, scoped, wr.handler.UnmarkWorkflowTemplate)
+	workflowGroup.POST("/:id/clone", create, scoped, middleware.RequirePlanQuota(plans, billing.QuotaWorkflows), wr.handler.CloneWorkflow)
 
 	// Workflow steps endpoints
-	workflowGroup.POST("/:id/steps", wr.handler.CreateWorkflowStep)
-	workflowGroup.GET("/:id/steps", wr.handler.ListWorkflowSteps)
-	workflowGroup.GET("/:id/steps/:stepId", wr.handler.GetWorkflowStep)
-	workflowGroup.PUT("/:id/steps/:stepId", wr.handler.UpdateWorkflowStep)
-	workflowGroup.DELETE("/:id/steps/:stepId", wr.handler.DeleteWorkflowStep)
+	workflowGroup.POST("/:id/steps", update, scoped, wr.handler.CreateWorkflowStep)
+	workflowGroup.GET("/:id/steps", read, scoped, wr.handler.ListWorkflowSteps)
+	workflowGroup.GET("/:id/steps/:stepId", read, scoped, wr.handler.GetWorkflowStep)
+	workflowGroup.PUT("/:id/steps/:stepId", update, scoped, wr.handler.UpdateWorkflowStep)
+	workflowGroup.DELETE("/:id/steps/:stepId", update, scoped, wr.handler.DeleteWorkflowStep)
 
 	// Workflow transitions endpoints
-	workflowGroup.POST("/:id/transitions", wr.handler.CreateTransition)
-	workflowGroup.GET("/:id/transitions", wr.handler.ListTransitions)
-	workflowGroup.GET("/:id/transitions/:transitionId", wr.handler.GetTransition)
-	workflowGroup.PUT("/:id/transitions/:transitionId", wr.handler.UpdateTransition)
-	workflowGroup.DELETE("/:id/transitions/:transitionId", wr.handler.DeleteTransition)
+	workflowGroup.POST("/:id/transitions", update, scoped, wr.handler.CreateTransition)
+	workflowGroup.GET("/:id/transitions", read, scoped, wr.handler.ListTransitions)
+	workflowGroup.GET("/:id/transitions/:transitionId", read, scoped, wr.handler.GetTransition)
+	workflowGroup.PUT("/:id/transitions/:transitionId", update, scoped, wr.handler.UpdateTransition)
+	workflowGroup.DELETE("/:id/transitions/:transitionId", update, scoped, wr.handler.DeleteTransition)
 
 	// Workflow execution operations
-	workflowGroup.POST("/:id/execute", wr.handler.ExecuteWorkflow)
-	workflowGroup.POST("/executions/:executionId/cancel", wr.handler.CancelWorkflowExecution)
-	workflowGroup.GET("/executions/:executionId", wr.handler.GetWorkflowExecution)
-	workflowGroup.GET("/:id/executions", wr.handler.ListWorkflowExecutions)
-	workflowGroup.PUT("/step-executions/:executionId", wr.handler.UpdateStepExecution)
-	workflowGroup.POST("/step-executions/:executionId/approve", wr.handler.ApproveStepExecution)
-	workflowGroup.POST("/step-executions/:executionId/reject", wr.handler.RejectStepExecution)
+	workflowGroup.POST("/:id/execute", execute, scoped, middleware.RequirePlanQuota(plans, billing.QuotaWorkflowExecutions), wr.handler.ExecuteWorkflow)
+	workflowGroup.POST("/executions/:executionId/cancel", execute, execution, wr.handler.CancelWorkflowExecution)
+	workflowGroup.GET("/executions/:executionId", read, execution, wr.handler.GetWorkflowExecution)
+	workflowGroup.GET("/:id/executions", read, scoped, wr.handler.ListWorkflowExecutions)
+	workflowGroup.PUT("/step-executions/:executionId", execute, stepExecution, wr.handler.UpdateStepExecution)
+	workflowGroup.POST("/step-executions/:executionId/approve", execute, stepExecution, wr.handler.ApproveStepExecution)
+	workflowGroup.POST("/step-executions/:executionId/reject", execute, stepExecution, wr.handler.RejectStepExecution)
+	workflowGroup.POST("/executions/:executionId/steps/:stepExecutionId/approve", execute, execution, wr.handler.ApproveStep)
+	workflowGroup.POST("/executions/:executionId/steps/:stepExecutionId/reject", execute, execution, wr.handler.RejectStep)
 
 	// Workflow analysis and optimization
-	workflowGroup.GET("/:id/analyze", wr.handler.AnalyzeWorkflow)
-	workflowGroup.POST("/:id/optimize", wr.handler.OptimizeWorkflow)
+	workflowGroup.GET("/:id/analyze", read, scoped, wr.handler.AnalyzeWorkflow)
+	workflowGroup.POST("/:id/optimize", update, scoped, wr.handler.OptimizeWorkflow)
+
+	// AI cost reports and budgets
+	workflowGroup.GET("/ai-cost", read, wr.handler.GetAICostReport)
+	workflowGroup.GET("/:id/ai-cost", read, scoped, wr.handler.GetAICostReport)
+	workflowGroup.PUT("/ai-budget", update, wr.handler.SetAIBudget)
 }
@@ -0,0 +1,200 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/legal"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// organizationKey is the metadata key selecting the organization a call acts in,
+	// like the X-Organization-ID header of the REST API
+	organizationKey = "x-organization-id"
+	bearerSchema    = "Bearer "
+)
+
+// MembershipResolver resolves a user's role and permissions in an organization
+type MembershipResolver interface {
+	ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*organization.Membership, error)
+}
+
+// ConsentChecker returns the current legal documents a user has not accepted
+type ConsentChecker interface {
+	Pending(ctx context.Context, userID uuid.UUID) ([]legal.Document, error)
+}
+
+// Caller is the authenticated user of a call
+type Caller struct {
+	UserID uuid.UUID
+	Email  string
+	OrgID  uuid.UUID
+	// Scopes limit a token issued for an integration; they are empty for a user's own
+	// session
+	Scopes []string
+}
+
+// serviceDomains map the gRPC services to the scope domain of their REST routes
+var serviceDomains = map[string]string{
+	"TaskService":     "tasks",
+	"TodoService":     "todos",
+	"HabitService":    "habits",
+	"CalendarService": "calendar",
+}
+
+type callerKey struct{}
+
+// Authenticator checks the bearer token of every call. Tokens are the JWTs issued by
+// the REST API. Like service-to-service calls there, no browser session is required.
+// Users who have not accepted the current legal documents are turned away, as by the
+// consent gate of the REST API.
+type Authenticator struct {
+	jwtSecret string
+	resolver  MembershipResolver
+	consent   ConsentChecker
+}
+
+// NewAuthenticator creates a new Authenticator
+func NewAuthenticator(jwtSecret string, resolver MembershipResolver, consent ConsentChecker) *Authenticator {
+	return &Authenticator{
+		jwtSecret: jwtSecret,
+		resolver:  resolver,
+		consent:   consent,
+	}
+}
+
+// UnaryInterceptor rejects calls without a valid token and stores the caller in the
+// context of the others. Health checks and reflection are left open.
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, "/grpc.health.") || strings.HasPrefix(info.FullMethod, "/grpc.reflection.") {
+			return handler(ctx, req)
+		}
+		caller, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(caller.Scopes) > 0 && !scopesAllowMethod(caller.Scopes, info.FullMethod) {
+			return nil, status.Error(codes.PermissionDenied, "token scopes do not allow this call")
+		}
+		if err := a.checkConsent(ctx, caller.UserID); err != nil {
+			return nil, err
+		}
+		ctx = audit.WithActor(context.WithValue(ctx, callerKey{}, caller), caller.UserID)
+		return handler(ctx, req)
+	}
+}
+
+func (a *Authenticator) authenticate(ctx context.Context) (*Caller, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+	if !strings.HasPrefix(values[0], bearerSchema) {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+	}
+	token := values[0][len(bearerSchema):]
+
+	if auth.GetTokenBlacklist().IsBlacklisted(token) {
+		return nil, status.Error(codes.Unauthenticated, "token has been invalidated")
+	}
+	claims, err := auth.ValidateToken(token, a.jwtSecret)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return &Caller{UserID: claims.UserID, Email: claims.Email, OrgID: claims.OrgID, Scopes: claims.Scopes}, nil
+}
+
+// scopesAllowMethod checks the scopes against the domain of the method's service, like
+// the REST API does with the path. Get and List calls need read; anything else needs
+// execute. Task calls check the permission of their action on top.
+func scopesAllowMethod(scopes []string, fullMethod string) bool {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if i := strings.LastIndex(service, "."); i >= 0 {
+		service = service[i+1:]
+	}
+	action := auth.ActionExecute
+	if strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "List") {
+		action = auth.ActionRead
+	}
+	return auth.ScopesAllow(scopes, serviceDomains[service], action)
+}
+
+// checkConsent turns away users with current legal documents left to accept. Errors
+// checking consent let the call through rather than lock users out.
+func (a *Authenticator) checkConsent(ctx context.Context, userID uuid.UUID) error {
+	if a.consent == nil {
+		return nil
+	}
+	pending, err := a.consent.Pending(ctx, userID)
+	if err != nil || len(pending) == 0 {
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, "the updated terms must be accepted to continue")
+}
+
+// callerFrom returns the caller stored by the interceptor
+func callerFrom(ctx context.Context) (*Caller, error) {
+	caller, ok := ctx.Value(callerKey{}).(*Caller)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	return caller, nil
+}
+
+// requireOrganization resolves the caller's membership in the organization the call
+// acts in and checks that it grants the permission. Scoped tokens must also have a scope
+// covering the permission. An organization scheduled for deletion is read-only, as with
+// the 423 of the REST API.
+func (a *Authenticator) requireOrganization(ctx context.Context, permission string) (*organization.Membership, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(caller.Scopes) > 0 {
+		domain, action := auth.PermissionScope(permission)
+		if !auth.ScopesAllow(caller.Scopes, domain, action) {
+			return nil, status.Errorf(codes.PermissionDenied, "insufficient scopes: %s:%s is required", domain, action)
+		}
+	}
+
+	orgID := caller.OrgID
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(organizationKey); len(values) > 0 {
+		if orgID, err = uuid.Parse(values[0]); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid organization ID format")
+		}
+	}
+	if orgID == uuid.Nil {
+		return nil, status.Error(codes.InvalidArgument, organizationKey+" metadata is required")
+	}
+
+	membership, err := a.resolver.ResolveMembership(ctx, orgID, caller.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, organization.ErrOrganizationNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, organization.ErrNotMember):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "failed to resolve organization membership")
+		}
+	}
+	if !membership.HasPermission(permission) {
+		return nil, status.Errorf(codes.PermissionDenied, "insufficient permissions: %s is required", permission)
+	}
+	if membership.DeletionScheduledAt != nil && !strings.HasSuffix(permission, ":read") {
+		return nil, status.Error(codes.FailedPrecondition, organization.ErrPendingDeletion.Error())
+	}
+	return membership, nil
+}
@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	pb "github.com/ahmedelhadi17776/Compass/Backend_go/proto/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"gorm.io/gorm"
+)
+
+// calendarServer serves the CalendarService over the calendar service layer. Events
+// the caller neither owns nor was invited to are reported as not found.
+type calendarServer struct {
+	pb.UnimplementedCalendarServiceServer
+	service calendar.Service
+}
+
+func (s *calendarServer) GetEvent(ctx context.Context, req *pb.GetEventRequest) (*pb.Event, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.event(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if event.UserID != caller.UserID {
+		if _, err := s.service.GetCollaborator(ctx, event.ID, caller.UserID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errEventNotFound
+			}
+			return nil, toStatus(err)
+		}
+	}
+	return eventToProto(event), nil
+}
+
+func (s *calendarServer) ListEvents(ctx context.Context, req *pb.ListEventsRequest) (*pb.ListEventsResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetStartTime() == nil || req.GetEndTime() == nil {
+		return nil, status.Error(codes.InvalidArgument, "start_time and end_time are required")
+	}
+
+	var eventType *calendar.EventType
+	if req.EventType != nil {
+		value := calendar.EventType(req.GetEventType())
+		eventType = &value
+	}
+
+	// Calendar listings count pages from 1
+	list, err := s.service.ListEvents(ctx, caller.UserID, req.GetStartTime().AsTime(), req.GetEndTime().AsTime(),
+		eventType, page(req.GetPage())+1, pageSize(req.GetPageSize()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	response := &pb.ListEventsResponse{Events: make([]*pb.Event, len(list.Events)), Total: list.Total}
+	for i := range list.Events {
+		response.Events[i] = eventToProto(&list.Events[i])
+	}
+	return response, nil
+}
+
+func (s *calendarServer) CreateEvent(ctx context.Context, req *pb.CreateEventRequest) (*pb.Event, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetTitle() == "" || req.GetStartTime() == nil || req.GetEndTime() == nil {
+		return nil, status.Error(codes.InvalidArgument, "title, start_time and end_time are required")
+	}
+
+	event, err := s.service.CreateEvent(ctx, calendar.CreateCalendarEventRequest{
+		Title:           req.GetTitle(),
+		Description:     req.GetDescription(),
+		EventType:       calendar.EventType(req.GetEventType()),
+		StartTime:       req.GetStartTime().AsTime(),
+		EndTime:         req.GetEndTime().AsTime(),
+		IsAllDay:        req.GetIsAllDay(),
+		Location:        req.GetLocation(),
+		Color:           req.GetColor(),
+		Transparency:    calendar.Transparency(req.GetTransparency()),
+		WorkingLocation: calendar.WorkingLocation(req.GetWorkingLocation()),
+		DeclineInvites:  req.GetDeclineInvites(),
+	}, caller.UserID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return eventToProto(event), nil
+}
+
+func (s *calendarServer) DeleteEvent(ctx context.Context, req *pb.DeleteEventRequest) (*emptypb.Empty, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.event(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if event.UserID != caller.UserID {
+		return nil, errEventNotFound
+	}
+	if err := s.service.DeleteEvent(ctx, event.ID); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *calendarServer) event(ctx context.Context, rawID string) (*calendar.CalendarEvent, error) {
+	id, err := parseID("event ID", rawID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.service.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errEventNotFound
+		}
+		return nil, toStatus(err)
+	}
+	return event, nil
+}
+
+func eventToProto(e *calendar.CalendarEvent) *pb.Event {
+	return &pb.Event{
+		Id:              e.ID.String(),
+		UserId:          e.UserID.String(),
+		Title:           e.Title,
+		Description:     e.Description,
+		EventType:       string(e.EventType),
+		StartTime:       timestamp(e.StartTime),
+		EndTime:         timestamp(e.EndTime),
+		IsAllDay:        e.IsAllDay,
+		Location:        e.Location,
+		Color:           e.Color,
+		Transparency:    string(e.Transparency),
+		WorkingLocation: string(e.WorkingLocation),
+		DeclineInvites:  e.DeclineInvites,
+		CreatedAt:       timestamp(e.CreatedAt),
+		UpdatedAt:       timestamp(e.UpdatedAt),
+	}
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/plugins"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+var (
+	errTaskNotFound  = status.Error(codes.NotFound, task.ErrTaskNotFound.Error())
+	errTodoNotFound  = status.Error(codes.NotFound, todos.ErrTodoNotFound.Error())
+	errHabitNotFound = status.Error(codes.NotFound, habits.ErrHabitNotFound.Error())
+	errEventNotFound = status.Error(codes.NotFound, "event not found")
+)
+
+// toStatus maps a service error to a gRPC status. Errors that already are statuses
+// are returned unchanged.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var taskErr *task.Error
+	var todoErr *todos.Error
+	var calendarErr *calendar.Error
+	switch {
+	case errors.Is(err, task.ErrTaskNotFound):
+		return errTaskNotFound
+	case errors.Is(err, todos.ErrTodoNotFound):
+		return errTodoNotFound
+	case errors.Is(err, habits.ErrHabitNotFound):
+		return errHabitNotFound
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, task.ErrInvalidCreator):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, task.ErrInvalidInput), errors.Is(err, task.ErrInvalidParent), errors.Is(err, task.ErrTaskCycle),
+		errors.Is(err, task.ErrInvalidDependency), errors.Is(err, task.ErrDependencyCycle),
+		errors.Is(err, habits.ErrInvalidInput), errors.As(err, &taskErr), errors.As(err, &todoErr), errors.As(err, &calendarErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, task.ErrInvalidTransition), errors.Is(err, task.ErrDependencyFailed), errors.Is(err, task.ErrStatusDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, plugins.ErrRejected):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,155 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	pb "github.com/ahmedelhadi17776/Compass/Backend_go/proto/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// habitServer serves the HabitService over the habits service layer. Habits of other
+// users are reported as not found.
+type habitServer struct {
+	pb.UnimplementedHabitServiceServer
+	service habits.Service
+}
+
+func (s *habitServer) GetHabit(ctx context.Context, req *pb.GetHabitRequest) (*pb.Habit, error) {
+	habit, _, err := s.ownHabit(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return habitToProto(habit), nil
+}
+
+func (s *habitServer) ListHabits(ctx context.Context, req *pb.ListHabitsRequest) (*pb.ListHabitsResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, total, err := s.service.ListHabits(ctx, habits.HabitFilter{
+		UserID:   &caller.UserID,
+		Title:    req.Title,
+		Page:     page(req.GetPage()),
+		PageSize: pageSize(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	response := &pb.ListHabitsResponse{Habits: make([]*pb.Habit, len(list)), Total: total}
+	for i := range list {
+		response.Habits[i] = habitToProto(&list[i])
+	}
+	return response, nil
+}
+
+func (s *habitServer) CreateHabit(ctx context.Context, req *pb.CreateHabitRequest) (*pb.Habit, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetTitle() == "" || req.GetStartDay() == nil {
+		return nil, status.Error(codes.InvalidArgument, "title and start_day are required")
+	}
+
+	input := habits.CreateHabitInput{
+		Title:        req.GetTitle(),
+		Description:  req.GetDescription(),
+		StartDay:     req.GetStartDay().AsTime(),
+		EndDay:       optionalTime(req.GetEndDay()),
+		ReminderTime: req.ReminderTime,
+		UserID:       caller.UserID,
+	}
+
+	created, err := s.service.CreateHabit(ctx, input)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return habitToProto(created), nil
+}
+
+func (s *habitServer) CompleteHabit(ctx context.Context, req *pb.CompleteHabitRequest) (*pb.Habit, error) {
+	habit, caller, err := s.ownHabit(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.service.MarkCompleted(ctx, habit.ID, caller.UserID, optionalTime(req.GetCompletionDate())); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.reload(ctx, habit)
+}
+
+func (s *habitServer) UncompleteHabit(ctx context.Context, req *pb.UncompleteHabitRequest) (*pb.Habit, error) {
+	habit, caller, err := s.ownHabit(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.service.UnmarkCompleted(ctx, habit.ID, caller.UserID); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.reload(ctx, habit)
+}
+
+func (s *habitServer) DeleteHabit(ctx context.Context, req *pb.DeleteHabitRequest) (*emptypb.Empty, error) {
+	habit, _, err := s.ownHabit(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.service.DeleteHabit(ctx, habit.ID); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ownHabit loads a habit of the caller
+func (s *habitServer) ownHabit(ctx context.Context, rawID string) (*habits.Habit, *Caller, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := parseID("habit ID", rawID)
+	if err != nil {
+		return nil, nil, err
+	}
+	habit, err := s.service.GetHabit(ctx, id)
+	if err != nil {
+		return nil, nil, toStatus(err)
+	}
+	if habit.UserID != caller.UserID {
+		return nil, nil, errHabitNotFound
+	}
+	return habit, caller, nil
+}
+
+// reload returns the habit with the streak a completion change left it with
+func (s *habitServer) reload(ctx context.Context, habit *habits.Habit) (*pb.Habit, error) {
+	updated, err := s.service.GetHabit(ctx, habit.ID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return habitToProto(updated), nil
+}
+
+func habitToProto(h *habits.Habit) *pb.Habit {
+	return &pb.Habit{
+		Id:                h.ID.String(),
+		UserId:            h.UserID.String(),
+		Title:             h.Title,
+		Description:       h.Description,
+		StartDay:          timestamp(h.StartDay),
+		EndDay:            optionalTimestamp(h.EndDay),
+		CurrentStreak:     int32(h.CurrentStreak),
+		LongestStreak:     int32(h.LongestStreak),
+		IsCompleted:       h.IsCompleted,
+		LastCompletedDate: optionalTimestamp(h.LastCompletedDate),
+		StreakQuality:     h.StreakQuality,
+		ReminderTime:      h.ReminderTime,
+		CreatedAt:         timestamp(h.CreatedAt),
+		UpdatedAt:         timestamp(h.UpdatedAt),
+	}
+}
@@ -0,0 +1,107 @@
+// Package rpc exposes the core services (tasks, todos, habits and calendar) over gRPC.
+// The servers are thin adapters over the same service layer as the REST handlers; the
+// protobuf definitions live in proto/core.
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	pb "github.com/ahmedelhadi17776/Compass/Backend_go/proto/core"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key of the request ID, as in the X-Request-ID header of the
+// REST API
+const requestIDKey = "x-request-id"
+
+// Services are the service-layer dependencies of the gRPC servers
+type Services struct {
+	Tasks    task.Service
+	Todos    todos.Service
+	Habits   habits.Service
+	Calendar calendar.Service
+}
+
+// NewServer creates a gRPC server with every core service, health checks and reflection
+// registered
+func NewServer(services Services, authenticator *Authenticator, logger *zap.Logger) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		traceInterceptor(),
+		recoverInterceptor(logger),
+		logInterceptor(logger),
+		authenticator.UnaryInterceptor(),
+	))
+
+	pb.RegisterTaskServiceServer(server, &taskServer{service: services.Tasks, auth: authenticator})
+	pb.RegisterTodoServiceServer(server, &todoServer{service: services.Todos})
+	pb.RegisterHabitServiceServer(server, &habitServer{service: services.Habits})
+	pb.RegisterCalendarServiceServer(server, &calendarServer{service: services.Calendar})
+
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+	return server
+}
+
+// traceInterceptor starts the trace of a call under the request ID in its metadata, or a
+// new one, and returns the request ID in the response header
+func traceInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = tracing.Start(ctx)
+		tc := tracing.FromContext(ctx)
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(requestIDKey); len(values) > 0 && tracing.ValidID(values[0]) {
+			tc.RequestID = values[0]
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, tc.RequestID))
+		return handler(ctx, req)
+	}
+}
+
+// recoverInterceptor turns a panic in a handler into an internal error
+func recoverInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				tracing.Logger(ctx, logger).Error("Recovered from panic in gRPC handler",
+					zap.String("method", info.FullMethod), zap.Any("panic", r))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// logInterceptor logs every call with its outcome and duration
+func logInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		fields := []zap.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("code", code.String()),
+			zap.Duration("duration", time.Since(start)),
+		}
+		log := tracing.Logger(ctx, logger)
+		if code == codes.Internal || code == codes.Unknown {
+			log.Error("gRPC call failed", append(fields, zap.Error(err))...)
+		} else {
+			log.Debug("gRPC call", fields...)
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,208 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	pb "github.com/ahmedelhadi17776/Compass/Backend_go/proto/core"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// taskServer serves the TaskService over the task service layer. Tasks of other
+// organizations are reported as not found, like the REST API does.
+type taskServer struct {
+	pb.UnimplementedTaskServiceServer
+	service task.Service
+	auth    *Authenticator
+}
+
+func (s *taskServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Task, error) {
+	tsk, err := s.scopedTask(ctx, req.GetId(), "tasks:read")
+	if err != nil {
+		return nil, err
+	}
+	return taskToProto(tsk), nil
+}
+
+func (s *taskServer) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	membership, err := s.auth.requireOrganization(ctx, "tasks:read")
+	if err != nil {
+		return nil, err
+	}
+
+	filter := task.TaskFilter{
+		OrganizationID: &membership.OrganizationID,
+		Page:           page(req.GetPage()),
+		PageSize:       pageSize(req.GetPageSize()),
+	}
+	if filter.ProjectID, err = parseOptionalID("project_id", req.ProjectId); err != nil {
+		return nil, err
+	}
+	if filter.AssigneeID, err = parseOptionalID("assignee_id", req.AssigneeId); err != nil {
+		return nil, err
+	}
+	if req.Status != nil {
+		taskStatus := task.TaskStatus(req.GetStatus())
+		if !taskStatus.IsValid() {
+			return nil, status.Error(codes.InvalidArgument, "invalid status value")
+		}
+		filter.Status = &taskStatus
+	}
+	if req.Priority != nil {
+		priority := task.TaskPriority(req.GetPriority())
+		if !priority.IsValid() {
+			return nil, status.Error(codes.InvalidArgument, "invalid priority value")
+		}
+		filter.Priority = &priority
+	}
+
+	tasks, total, err := s.service.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	response := &pb.ListTasksResponse{Tasks: make([]*pb.Task, len(tasks)), Total: total}
+	for i := range tasks {
+		response.Tasks[i] = taskToProto(&tasks[i])
+	}
+	return response, nil
+}
+
+func (s *taskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.Task, error) {
+	membership, err := s.auth.requireOrganization(ctx, "tasks:create")
+	if err != nil {
+		return nil, err
+	}
+
+	// The service validates the status, which may be one of the project's custom statuses
+	taskStatus := task.TaskStatus(req.GetStatus())
+	priority := task.TaskPriority(req.GetPriority())
+	if !priority.IsValid() {
+		return nil, status.Error(codes.InvalidArgument, "invalid priority value")
+	}
+	projectID, err := parseID("project_id", req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetStartDate() == nil {
+		return nil, status.Error(codes.InvalidArgument, "start_date is required")
+	}
+
+	input := task.CreateTaskInput{
+		Title:          req.GetTitle(),
+		Description:    req.GetDescription(),
+		Status:         taskStatus,
+		Priority:       priority,
+		CreatorID:      membership.UserID,
+		ProjectID:      projectID,
+		OrganizationID: membership.OrganizationID,
+		EstimatedHours: req.GetEstimatedHours(),
+		StartDate:      req.GetStartDate().AsTime(),
+		Duration:       req.Duration,
+		DueDate:        optionalTime(req.GetDueDate()),
+	}
+	if input.AssigneeID, err = parseOptionalID("assignee_id", req.AssigneeId); err != nil {
+		return nil, err
+	}
+	if input.ReviewerID, err = parseOptionalID("reviewer_id", req.ReviewerId); err != nil {
+		return nil, err
+	}
+	if input.ParentTaskID, err = parseOptionalID("parent_task_id", req.ParentTaskId); err != nil {
+		return nil, err
+	}
+	if input.Dependencies, err = parseIDs("dependencies", req.GetDependencies()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.service.CreateTask(ctx, input)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskToProto(created), nil
+}
+
+func (s *taskServer) UpdateTaskStatus(ctx context.Context, req *pb.UpdateTaskStatusRequest) (*pb.Task, error) {
+	tsk, err := s.scopedTask(ctx, req.GetId(), "tasks:update")
+	if err != nil {
+		return nil, err
+	}
+	taskStatus := task.TaskStatus(req.GetStatus())
+	updated, err := s.service.UpdateTaskStatus(ctx, tsk.ID, taskStatus)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskToProto(updated), nil
+}
+
+func (s *taskServer) AssignTask(ctx context.Context, req *pb.AssignTaskRequest) (*pb.Task, error) {
+	tsk, err := s.scopedTask(ctx, req.GetId(), "tasks:update")
+	if err != nil {
+		return nil, err
+	}
+	assigneeID, err := parseID("assignee_id", req.GetAssigneeId())
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.service.AssignTask(ctx, tsk.ID, assigneeID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskToProto(updated), nil
+}
+
+func (s *taskServer) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*emptypb.Empty, error) {
+	tsk, err := s.scopedTask(ctx, req.GetId(), "tasks:delete")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.service.DeleteTask(ctx, tsk.ID); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// scopedTask loads a task of the organization the call acts in
+func (s *taskServer) scopedTask(ctx context.Context, rawID, permission string) (*task.Task, error) {
+	membership, err := s.auth.requireOrganization(ctx, permission)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID("task ID", rawID)
+	if err != nil {
+		return nil, err
+	}
+	tsk, err := s.service.GetTask(ctx, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if tsk.OrganizationID != membership.OrganizationID {
+		return nil, errTaskNotFound
+	}
+	return tsk, nil
+}
+
+func taskToProto(t *task.Task) *pb.Task {
+	return &pb.Task{
+		Id:             t.ID.String(),
+		Title:          t.Title,
+		Description:    t.Description,
+		Status:         string(t.Status),
+		Priority:       string(t.Priority),
+		ProjectId:      t.ProjectID.String(),
+		OrganizationId: t.OrganizationID.String(),
+		CreatorId:      t.CreatorID.String(),
+		AssigneeId:     optionalIDString(t.AssigneeID),
+		ReviewerId:     optionalIDString(t.ReviewerID),
+		ParentTaskId:   optionalIDString(t.ParentTaskID),
+		EstimatedHours: t.EstimatedHours,
+		ActualHours:    t.ActualHours,
+		StartDate:      timestamp(t.StartDate),
+		DueDate:        optionalTimestamp(t.DueDate),
+		Dependencies:   idStrings([]uuid.UUID(t.Dependencies)),
+		CreatedAt:      timestamp(t.CreatedAt),
+		UpdatedAt:      timestamp(t.UpdatedAt),
+	}
+}
@@ -0,0 +1,217 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	pb "github.com/ahmedelhadi17776/Compass/Backend_go/proto/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// todoServer serves the TodoService over the todos service layer. Todos of other users
+// are reported as not found.
+type todoServer struct {
+	pb.UnimplementedTodoServiceServer
+	service todos.Service
+}
+
+func (s *todoServer) GetTodo(ctx context.Context, req *pb.GetTodoRequest) (*pb.Todo, error) {
+	todo, err := s.ownTodo(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return todoToProto(todo), nil
+}
+
+func (s *todoServer) ListTodos(ctx context.Context, req *pb.ListTodosRequest) (*pb.ListTodosResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := todos.TodoFilter{
+		UserID:       &caller.UserID,
+		IsCompleted:  req.IsCompleted,
+		DueDateStart: optionalTime(req.GetDueDateStart()),
+		DueDateEnd:   optionalTime(req.GetDueDateEnd()),
+		Page:         page(req.GetPage()),
+		PageSize:     pageSize(req.GetPageSize()),
+	}
+	if req.Status != nil {
+		todoStatus := todos.TodoStatus(req.GetStatus())
+		if !todoStatus.IsValid() {
+			return nil, status.Error(codes.InvalidArgument, "invalid status value")
+		}
+		filter.Status = &todoStatus
+	}
+	if req.Priority != nil {
+		priority := todos.TodoPriority(req.GetPriority())
+		if !priority.IsValid() {
+			return nil, status.Error(codes.InvalidArgument, "invalid priority value")
+		}
+		filter.Priority = &priority
+	}
+
+	list, total, err := s.service.ListTodos(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	response := &pb.ListTodosResponse{Todos: make([]*pb.Todo, len(list)), Total: total}
+	for i := range list {
+		response.Todos[i] = todoToProto(&list[i])
+	}
+	return response, nil
+}
+
+func (s *todoServer) CreateTodo(ctx context.Context, req *pb.CreateTodoRequest) (*pb.Todo, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todoStatus := todos.TodoStatus(req.GetStatus())
+	if !todoStatus.IsValid() {
+		return nil, status.Error(codes.InvalidArgument, "invalid status value")
+	}
+	priority := todos.TodoPriority(req.GetPriority())
+	if !priority.IsValid() {
+		return nil, status.Error(codes.InvalidArgument, "invalid priority value")
+	}
+
+	input := todos.CreateTodoInput{
+		Title:             req.GetTitle(),
+		Description:       req.GetDescription(),
+		Status:            todoStatus,
+		Priority:          priority,
+		DueDate:           optionalTime(req.GetDueDate()),
+		ReminderTime:      optionalTime(req.GetReminderTime()),
+		IsRecurring:       req.GetIsRecurring(),
+		RecurrencePattern: req.GetRecurrencePattern().AsMap(),
+		Tags:              req.GetTags().AsMap(),
+		UserID:            caller.UserID,
+	}
+	if input.LinkedTaskID, err = parseOptionalID("linked_task_id", req.LinkedTaskId); err != nil {
+		return nil, err
+	}
+	if input.LinkedCalendarEventID, err = parseOptionalID("linked_calendar_event_id", req.LinkedCalendarEventId); err != nil {
+		return nil, err
+	}
+
+	listID, err := parseOptionalID("list_id", req.ListId)
+	if err != nil {
+		return nil, err
+	}
+	if listID == nil {
+		list, err := s.service.GetOrCreateDefaultList(ctx, caller.UserID)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		input.ListID = list.ID
+	} else {
+		list, err := s.service.GetTodoList(ctx, *listID)
+		if err != nil || list.UserID != caller.UserID {
+			return nil, status.Error(codes.NotFound, "todo list not found")
+		}
+		input.ListID = list.ID
+	}
+
+	created, err := s.service.CreateTodo(ctx, input)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return todoToProto(created), nil
+}
+
+func (s *todoServer) CompleteTodo(ctx context.Context, req *pb.CompleteTodoRequest) (*pb.Todo, error) {
+	todo, err := s.ownTodo(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	completed, err := s.service.CompleteTodo(ctx, todo.ID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return todoToProto(completed), nil
+}
+
+func (s *todoServer) UncompleteTodo(ctx context.Context, req *pb.UncompleteTodoRequest) (*pb.Todo, error) {
+	todo, err := s.ownTodo(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	reopened, err := s.service.UncompleteTodo(ctx, todo.ID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return todoToProto(reopened), nil
+}
+
+func (s *todoServer) DeleteTodo(ctx context.Context, req *pb.DeleteTodoRequest) (*emptypb.Empty, error) {
+	todo, err := s.ownTodo(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.service.DeleteTodo(ctx, todo.ID); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ownTodo loads a todo of the caller
+func (s *todoServer) ownTodo(ctx context.Context, rawID string) (*todos.Todo, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID("todo ID", rawID)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := s.service.GetTodo(ctx, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if todo.UserID != caller.UserID {
+		return nil, errTodoNotFound
+	}
+	return todo, nil
+}
+
+func todoToProto(t *todos.Todo) *pb.Todo {
+	return &pb.Todo{
+		Id:                    t.ID.String(),
+		UserId:                t.UserID.String(),
+		ListId:                t.ListID.String(),
+		Title:                 t.Title,
+		Description:           t.Description,
+		Status:                string(t.Status),
+		Priority:              string(t.Priority),
+		IsCompleted:           t.IsCompleted,
+		CompletionDate:        optionalTimestamp(t.CompletionDate),
+		DueDate:               optionalTimestamp(t.DueDate),
+		ReminderTime:          optionalTimestamp(t.ReminderTime),
+		IsRecurring:           t.IsRecurring,
+		RecurrencePattern:     toStruct(t.RecurrencePattern),
+		Tags:                  toStruct(t.Tags),
+		LinkedTaskId:          optionalIDString(t.LinkedTaskID),
+		LinkedCalendarEventId: optionalIDString(t.LinkedCalendarEventID),
+		CreatedAt:             timestamp(t.CreatedAt),
+		UpdatedAt:             timestamp(t.UpdatedAt),
+	}
+}
+
+// toStruct converts a JSON column to a Struct, leaving it unset when it is empty or
+// holds values protobuf cannot represent
+func toStruct(values map[string]interface{}) *structpb.Struct {
+	if len(values) == 0 {
+		return nil
+	}
+	converted, err := structpb.NewStruct(values)
+	if err != nil {
+		return nil
+	}
+	return converted
+}
@@ -0,0 +1,78 @@
+package accountmerge
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Category groups the references to a user that a merge moves
+type Category string
+
+const (
+	// CategoryOwned are records the user created or owns
+	CategoryOwned Category = "owned"
+	// CategoryAssigned are tasks assigned to the user or waiting for their review
+	CategoryAssigned Category = "assigned"
+	// CategoryMembership are the organizations, projects, events and roles the user belongs to
+	CategoryMembership Category = "membership"
+	// CategoryComment are comments the user wrote
+	CategoryComment Category = "comment"
+	// CategorySession are the user's signed-in sessions
+	CategorySession Category = "session"
+	// CategoryAttribution records who invited, updated or deleted something
+	CategoryAttribution Category = "attribution"
+)
+
+var (
+	ErrSameAccount     = errors.New("the surviving and the duplicate account must differ")
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrRunningOnBoth is returned when both accounts have a running timer or focus
+	// session, which a user can only have one of
+	ErrRunningOnBoth = errors.New("both accounts have a running timer or focus session; stop one first")
+)
+
+// Request names the accounts to merge
+type Request struct {
+	// SurvivorID is the account that is kept
+	SurvivorID uuid.UUID
+	// DuplicateID is the account whose data moves to the survivor before it is deactivated
+	DuplicateID uuid.UUID
+	// DryRun reports what the merge would do without changing anything
+	DryRun bool
+}
+
+// TableReport counts what a merge did to one user column
+type TableReport struct {
+	Table    string   `json:"table"`
+	Column   string   `json:"column"`
+	Category Category `json:"category"`
+	// Reassigned counts the rows moved to the survivor
+	Reassigned int64 `json:"reassigned"`
+	// Dropped counts the rows of the duplicate removed because the survivor already had
+	// the same one, like a membership of the same organization
+	Dropped int64 `json:"dropped"`
+}
+
+// Report describes a merge. A dry run reports exactly what the merge would do at the time
+// it ran.
+type Report struct {
+	SurvivorID  uuid.UUID `json:"survivor_id"`
+	DuplicateID uuid.UUID `json:"duplicate_id"`
+	DryRun      bool      `json:"dry_run"`
+	// Tables lists the user columns that had rows of the duplicate
+	Tables     []TableReport `json:"tables"`
+	Reassigned int64         `json:"reassigned"`
+	Dropped    int64         `json:"dropped"`
+	// Organizations are the organizations the survivor belongs to after the merge
+	Organizations []uuid.UUID `json:"organizations"`
+}
+
+func (r *Report) add(table TableReport) {
+	if table.Reassigned == 0 && table.Dropped == 0 {
+		return
+	}
+	r.Tables = append(r.Tables, table)
+	r.Reassigned += table.Reassigned
+	r.Dropped += table.Dropped
+}
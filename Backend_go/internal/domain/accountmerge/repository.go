@@ -0,0 +1,219 @@
+package accountmerge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errDryRun rolls back the transaction of a dry run once it has been reported
+var errDryRun = errors.New("dry run")
+
+// reference is a column that points at a user
+type reference struct {
+	table    string
+	column   string
+	category Category
+	// unique lists the other columns of a unique key that includes column. Rows of the
+	// duplicate that match a row of the survivor on them are dropped, not reassigned. An
+	// empty list means a user has at most one row.
+	unique []string
+	// running is the condition of the one row a user may have at a time
+	running string
+}
+
+// references lists every column a merge moves to the survivor. The audit log, the activity
+// feed, search history and analytics are left alone: they record what each account did.
+var references = []reference{
+	{table: "tasks", column: "creator_id", category: CategoryOwned},
+	{table: "projects", column: "creator_id", category: CategoryOwned},
+	{table: "projects", column: "owner_id", category: CategoryOwned},
+	{table: "organizations", column: "creator_id", category: CategoryOwned},
+	{table: "organizations", column: "owner_id", category: CategoryOwned},
+	{table: "todo_lists", column: "user_id", category: CategoryOwned},
+	{table: "todos", column: "user_id", category: CategoryOwned},
+	{table: "todo_geofences", column: "user_id", category: CategoryOwned},
+	{table: "todo_attachments", column: "user_id", category: CategoryOwned},
+	{table: "habits", column: "user_id", category: CategoryOwned},
+	{table: "habit_completion_logs", column: "user_id", category: CategoryOwned},
+	{table: "habit_links", column: "user_id", category: CategoryOwned},
+	{table: "calendar_events", column: "user_id", category: CategoryOwned},
+	{table: "reminder_deliveries", column: "user_id", category: CategoryOwned},
+	{table: "focus_sessions", column: "user_id", category: CategoryOwned, running: "ended_at IS NULL"},
+	{table: "focus_interruptions", column: "user_id", category: CategoryOwned},
+	{table: "time_entries", column: "user_id", category: CategoryOwned, running: "ended_at IS NULL"},
+	{table: "notifications", column: "user_id", category: CategoryOwned},
+	{table: "webhooks", column: "owner_id", category: CategoryOwned},
+	{table: "workflows", column: "created_by", category: CategoryOwned},
+	{table: "meeting_action_items", column: "created_by", category: CategoryOwned},
+	{table: "tags", column: "created_by", category: CategoryOwned},
+	{table: "tag_assignments", column: "created_by", category: CategoryOwned},
+	{table: "project_baselines", column: "created_by", category: CategoryOwned},
+	{table: "project_duplications", column: "created_by", category: CategoryOwned},
+	{table: "organization_member_imports", column: "created_by", category: CategoryOwned},
+	{table: "vcs_connections", column: "created_by", category: CategoryOwned},
+	{table: "vcs_links", column: "created_by", category: CategoryOwned},
+	{table: "announcements", column: "author_id", category: CategoryOwned},
+	{table: "api_keys", column: "user_id", category: CategoryOwned},
+	{table: "user_devices", column: "user_id", category: CategoryOwned, unique: []string{"device_id"}},
+	{table: "inbound_addresses", column: "user_id", category: CategoryOwned, unique: []string{}},
+	{table: "inbound_messages", column: "user_id", category: CategoryOwned},
+	{table: "chat_user_links", column: "user_id", category: CategoryOwned},
+	{table: "onboarding_progress", column: "user_id", category: CategoryOwned},
+	{table: "consents", column: "user_id", category: CategoryOwned, unique: []string{"document_type", "version"}},
+	{table: "metering_user_hourly_usage", column: "user_id", category: CategoryOwned, unique: []string{"meter", "hour"}},
+	{table: "user_daily_stats", column: "user_id", category: CategoryOwned, unique: []string{"day"}},
+
+	{table: "tasks", column: "assignee_id", category: CategoryAssigned},
+	{table: "tasks", column: "reviewer_id", category: CategoryAssigned},
+
+	{table: "organization_members", column: "user_id", category: CategoryMembership, unique: []string{"organization_id"}},
+	{table: "organization_unit_members", column: "user_id", category: CategoryMembership, unique: []string{"unit_id"}},
+	{table: "project_members", column: "user_id", category: CategoryMembership, unique: []string{"project_id"}},
+	{table: "event_collaborators", column: "user_id", category: CategoryMembership, unique: []string{"event_id"}},
+	{table: "event_attendees", column: "user_id", category: CategoryMembership, unique: []string{"event_id"}},
+	{table: "announcement_recipients", column: "user_id", category: CategoryMembership, unique: []string{"announcement_id"}},
+	{table: "user_roles", column: "user_id", category: CategoryMembership, unique: []string{"role_id"}},
+
+	{table: "task_comments", column: "author_id", category: CategoryComment},
+
+	{table: "refresh_tokens", column: "user_id", category: CategorySession},
+
+	{table: "tasks", column: "deleted_by", category: CategoryAttribution},
+	{table: "attachments", column: "uploaded_by", category: CategoryAttribution},
+	{table: "meeting_notes", column: "updated_by", category: CategoryAttribution},
+	{table: "organization_invitations", column: "invited_by", category: CategoryAttribution},
+	{table: "organization_invitations", column: "accepted_by", category: CategoryAttribution},
+	{table: "event_collaborators", column: "invited_by", category: CategoryAttribution},
+	{table: "event_attendees", column: "invited_by", category: CategoryAttribution},
+	{table: "organization_default_settings", column: "updated_by", category: CategoryAttribution},
+	{table: "project_settings", column: "updated_by", category: CategoryAttribution},
+	{table: "chat_installations", column: "installed_by", category: CategoryAttribution},
+	{table: "workflow_step_executions", column: "decided_by", category: CategoryAttribution},
+	{table: "organizations", column: "deletion_requested_by", category: CategoryAttribution},
+}
+
+// Repository defines the interface for merging accounts
+type Repository interface {
+	// Merge moves every reference to the duplicate to the survivor and deactivates the
+	// duplicate, in one transaction. A dry run rolls the transaction back.
+	Merge(ctx context.Context, req Request) (*Report, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new account merge repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Merge(ctx context.Context, req Request) (*Report, error) {
+	report := &Report{
+		SurvivorID:  req.SurvivorID,
+		DuplicateID: req.DuplicateID,
+		DryRun:      req.DryRun,
+		Tables:      []TableReport{},
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock both accounts in a fixed order so concurrent merges cannot deadlock
+		var locked []uuid.UUID
+		err := tx.Raw(`SELECT id FROM users WHERE id IN ? AND deleted_at IS NULL ORDER BY id FOR UPDATE`,
+			[]uuid.UUID{req.SurvivorID, req.DuplicateID}).Scan(&locked).Error
+		if err != nil {
+			return err
+		}
+		if len(locked) != 2 {
+			return ErrAccountNotFound
+		}
+
+		for _, ref := range references {
+			if ref.running == "" {
+				continue
+			}
+			var users int64
+			err := tx.Raw(`SELECT COUNT(DISTINCT `+ref.column+`) FROM `+ref.table+` WHERE `+ref.column+` IN ? AND `+ref.running,
+				[]uuid.UUID{req.SurvivorID, req.DuplicateID}).Scan(&users).Error
+			if err != nil {
+				return err
+			}
+			if users > 1 {
+				return ErrRunningOnBoth
+			}
+		}
+
+		// The survivor takes over organizations the duplicate owned, so it takes the
+		// duplicate's role in them too
+		err = tx.Exec(`UPDATE organization_members AS s SET role_id = d.role_id, updated_at = ?
+			FROM organization_members AS d, organizations AS o
+			WHERE s.user_id = ? AND d.user_id = ? AND d.organization_id = s.organization_id
+				AND o.id = s.organization_id AND o.owner_id = ?`,
+			time.Now(), req.SurvivorID, req.DuplicateID, req.DuplicateID).Error
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range references {
+			table, err := mergeReference(tx, ref, req)
+			if err != nil {
+				return err
+			}
+			report.add(table)
+		}
+
+		err = tx.Exec(`UPDATE users SET deleted_at = ?, is_active = false, status = 'inactive', updated_at = ? WHERE id = ?`,
+			time.Now(), time.Now(), req.DuplicateID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Raw(`SELECT organization_id FROM organization_members WHERE user_id = ? ORDER BY created_at`,
+			req.SurvivorID).Scan(&report.Organizations).Error
+		if err != nil {
+			return err
+		}
+
+		if req.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// mergeReference drops the rows of the duplicate that would collide with a row of the
+// survivor, then moves the rest
+func mergeReference(tx *gorm.DB, ref reference, req Request) (TableReport, error) {
+	report := TableReport{Table: ref.table, Column: ref.column, Category: ref.category}
+
+	if ref.unique != nil {
+		conditions := []string{"s." + ref.column + " = ?"}
+		for _, column := range ref.unique {
+			conditions = append(conditions, "s."+column+" = d."+column)
+		}
+		result := tx.Exec(`DELETE FROM `+ref.table+` AS d WHERE d.`+ref.column+` = ?
+			AND EXISTS (SELECT 1 FROM `+ref.table+` AS s WHERE `+strings.Join(conditions, " AND ")+`)`,
+			req.DuplicateID, req.SurvivorID)
+		if result.Error != nil {
+			return report, result.Error
+		}
+		report.Dropped = result.RowsAffected
+	}
+
+	result := tx.Exec(`UPDATE `+ref.table+` SET `+ref.column+` = ? WHERE `+ref.column+` = ?`,
+		req.SurvivorID, req.DuplicateID)
+	if result.Error != nil {
+		return report, result.Error
+	}
+	report.Reassigned = result.RowsAffected
+	return report, nil
+}
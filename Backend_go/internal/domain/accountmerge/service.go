@@ -0,0 +1,69 @@
+package accountmerge
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// Service defines the interface for merging duplicate accounts
+type Service interface {
+	// Merge moves the data, memberships and sessions of the duplicate account to the
+	// survivor and deactivates the duplicate, or only reports what it would do
+	Merge(ctx context.Context, req Request) (*Report, error)
+}
+
+type service struct {
+	repo     Repository
+	auditLog audit.Auditor
+	logger   *zap.Logger
+}
+
+// NewService creates a new account merge service
+func NewService(repo Repository, auditLog audit.Auditor, logger *zap.Logger) Service {
+	return &service{repo: repo, auditLog: auditLog, logger: logger}
+}
+
+func (s *service) Merge(ctx context.Context, req Request) (*Report, error) {
+	if req.SurvivorID == req.DuplicateID {
+		return nil, ErrSameAccount
+	}
+
+	report, err := s.repo.Merge(ctx, req)
+	if err != nil || req.DryRun {
+		return report, err
+	}
+
+	// The refresh tokens of the duplicate now renew access tokens of the survivor. Its
+	// access tokens still name the duplicate, so they stop working and clients refresh.
+	sessions := auth.GetSessionStore()
+	for _, session := range sessions.GetUserSessions(req.DuplicateID) {
+		sessions.InvalidateSession(session.Token)
+		auth.GetTokenBlacklist().AddToBlacklist(session.Token, session.ExpiresAt)
+	}
+
+	tracing.Logger(ctx, s.logger).Info("Merged user accounts",
+		zap.String("survivor_id", req.SurvivorID.String()),
+		zap.String("duplicate_id", req.DuplicateID.String()),
+		zap.Int64("reassigned", report.Reassigned),
+		zap.Int64("dropped", report.Dropped))
+
+	survivorID := req.SurvivorID
+	for _, orgID := range report.Organizations {
+		s.auditLog.Audit(ctx, audit.Event{
+			OrganizationID: orgID,
+			Action:         audit.ActionUserMerged,
+			TargetType:     "user",
+			TargetID:       &survivorID,
+			Metadata: map[string]interface{}{
+				"duplicate_id": req.DuplicateID.String(),
+				"reassigned":   report.Reassigned,
+				"dropped":      report.Dropped,
+			},
+		})
+	}
+	return report, nil
+}
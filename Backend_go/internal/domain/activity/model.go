@@ -0,0 +1,118 @@
+package activity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// EventType identifies the kind of change recorded in the activity feed
+type EventType string
+
+const (
+	EventTaskCreated        EventType = "task.created"
+	EventTaskUpdated        EventType = "task.updated"
+	EventTaskStatusChanged  EventType = "task.status_changed"
+	EventTaskAssigned       EventType = "task.assigned"
+	EventTaskDeleted        EventType = "task.deleted"
+	EventCommentAdded       EventType = "comment.added"
+	EventCommentDeleted     EventType = "comment.deleted"
+	EventMemberAdded        EventType = "member.added"
+	EventMemberRemoved      EventType = "member.removed"
+	EventWorkflowRunStarted EventType = "workflow.run_started"
+)
+
+// Categories group event types so clients can filter the feed
+const (
+	CategoryTask     = "task"
+	CategoryComment  = "comment"
+	CategoryMember   = "member"
+	CategoryWorkflow = "workflow"
+)
+
+var (
+	ErrNotAuthorized = errors.New("not authorized to view this feed")
+)
+
+// Event is a single entry of the activity feed. Producers append events as they
+// change data so the feed can be read without joining every source table.
+type Event struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID      `json:"organization_id" gorm:"type:uuid;not null;index:idx_activity_org_created,priority:1"`
+	ProjectID      *uuid.UUID     `json:"project_id,omitempty" gorm:"type:uuid;index:idx_activity_project_created,priority:1"`
+	ActorID        *uuid.UUID     `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+	Type           EventType      `json:"type" gorm:"type:varchar(50);not null;index"`
+	Category       string         `json:"category" gorm:"type:varchar(20);not null;index"`
+	EntityType     string         `json:"entity_type" gorm:"type:varchar(50);not null"`
+	EntityID       uuid.UUID      `json:"entity_id" gorm:"type:uuid;not null;index"`
+	Summary        string         `json:"summary" gorm:"type:varchar(255)"`
+	Metadata       datatypes.JSON `json:"metadata" gorm:"type:jsonb;default:'{}'"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"not null;index:idx_activity_org_created,priority:2;index:idx_activity_project_created,priority:2"`
+}
+
+// TableName specifies the table name for the Event model
+func (Event) TableName() string {
+	return "activity_events"
+}
+
+// BeforeCreate is called before creating a new event record
+func (e *Event) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// CategoryOf returns the category an event type belongs to
+func CategoryOf(t EventType) string {
+	switch t {
+	case EventCommentAdded, EventCommentDeleted:
+		return CategoryComment
+	case EventMemberAdded, EventMemberRemoved:
+		return CategoryMember
+	case EventWorkflowRunStarted:
+		return CategoryWorkflow
+	default:
+		return CategoryTask
+	}
+}
+
+// FeedFilter defines filtering and pagination options for a feed
+type FeedFilter struct {
+	OrganizationID *uuid.UUID
+	ProjectID      *uuid.UUID
+	ActorID        *uuid.UUID
+	EntityType     string
+	EntityID       *uuid.UUID
+	Categories     []string
+	Types          []EventType
+	Since          *time.Time
+	Until          *time.Time
+	Page           int
+	PageSize       int
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Normalize applies the default page size and clamps pagination values
+func (f FeedFilter) Normalize() FeedFilter {
+	if f.Page < 0 {
+		f.Page = 0
+	}
+	if f.PageSize <= 0 {
+		f.PageSize = defaultPageSize
+	}
+	if f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+	return f
+}
@@ -0,0 +1,144 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// RecordInput describes a change to append to the activity feed
+type RecordInput struct {
+	OrganizationID uuid.UUID
+	ProjectID      *uuid.UUID
+	ActorID        *uuid.UUID
+	Type           EventType
+	EntityType     string
+	EntityID       uuid.UUID
+	Summary        string
+	Metadata       map[string]interface{}
+}
+
+// Viewer identifies who is reading a feed
+type Viewer struct {
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+// Recorder is implemented by anything that can append to the activity feed.
+// Recording is best effort and never fails the change that produced the event.
+type Recorder interface {
+	Record(ctx context.Context, input RecordInput)
+}
+
+// Service defines the interface for activity feed business logic
+type Service interface {
+	Recorder
+	ProjectFeed(ctx context.Context, orgID, projectID uuid.UUID, viewer Viewer, filter FeedFilter) ([]Event, int64, error)
+	OrganizationFeed(ctx context.Context, orgID uuid.UUID, viewer Viewer, filter FeedFilter) ([]Event, int64, error)
+	EntityFeed(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID, viewer Viewer, filter FeedFilter) ([]Event, int64, error)
+}
+
+type service struct {
+	repo       Repository
+	orgService organization.Service
+	logger     *zap.Logger
+}
+
+// NewService creates a new activity service instance
+func NewService(repo Repository, orgService organization.Service, logger *zap.Logger) Service {
+	return &service{
+		repo:       repo,
+		orgService: orgService,
+		logger:     logger,
+	}
+}
+
+// Record appends an event to the activity feed
+func (s *service) Record(ctx context.Context, input RecordInput) {
+	if input.OrganizationID == uuid.Nil || input.Type == "" {
+		tracing.Logger(ctx, s.logger).Warn("Skipping activity event without organization or type",
+			zap.String("type", string(input.Type)),
+			zap.String("entity_id", input.EntityID.String()))
+		return
+	}
+
+	metadata := datatypes.JSON("{}")
+	if len(input.Metadata) > 0 {
+		if b, err := json.Marshal(input.Metadata); err == nil {
+			metadata = datatypes.JSON(b)
+		}
+	}
+
+	event := &Event{
+		OrganizationID: input.OrganizationID,
+		ProjectID:      input.ProjectID,
+		ActorID:        input.ActorID,
+		Type:           input.Type,
+		Category:       CategoryOf(input.Type),
+		EntityType:     input.EntityType,
+		EntityID:       input.EntityID,
+		Summary:        input.Summary,
+		Metadata:       metadata,
+	}
+	if err := s.repo.Create(ctx, event); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to record activity event",
+			zap.String("type", string(input.Type)),
+			zap.String("entity_id", input.EntityID.String()),
+			zap.Error(err))
+	}
+}
+
+// ProjectFeed returns the activity timeline of a project that belongs to the given organization
+func (s *service) ProjectFeed(ctx context.Context, orgID, projectID uuid.UUID, viewer Viewer, filter FeedFilter) ([]Event, int64, error) {
+	if err := s.authorize(ctx, orgID, viewer); err != nil {
+		return nil, 0, err
+	}
+
+	filter.OrganizationID = &orgID
+	filter.ProjectID = &projectID
+	return s.repo.List(ctx, filter.Normalize())
+}
+
+// OrganizationFeed returns the activity timeline of an organization across all its projects
+func (s *service) OrganizationFeed(ctx context.Context, orgID uuid.UUID, viewer Viewer, filter FeedFilter) ([]Event, int64, error) {
+	if err := s.authorize(ctx, orgID, viewer); err != nil {
+		return nil, 0, err
+	}
+
+	filter.OrganizationID = &orgID
+	filter.ProjectID = nil
+	return s.repo.List(ctx, filter.Normalize())
+}
+
+// EntityFeed returns the history of a single entity, such as one task, of the given organization
+func (s *service) EntityFeed(ctx context.Context, orgID uuid.UUID, entityType string, entityID uuid.UUID, viewer Viewer, filter FeedFilter) ([]Event, int64, error) {
+	if err := s.authorize(ctx, orgID, viewer); err != nil {
+		return nil, 0, err
+	}
+
+	filter.OrganizationID = &orgID
+	filter.EntityType = entityType
+	filter.EntityID = &entityID
+	return s.repo.List(ctx, filter.Normalize())
+}
+
+// authorize allows members of the organization and its owner to read a feed
+func (s *service) authorize(ctx context.Context, orgID uuid.UUID, viewer Viewer) error {
+	if viewer.OrganizationID == orgID {
+		return nil
+	}
+
+	org, err := s.orgService.GetOrganization(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if org.OwnerID != viewer.UserID {
+		return ErrNotAuthorized
+	}
+	return nil
+}
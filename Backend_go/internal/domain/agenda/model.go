@@ -0,0 +1,95 @@
+package agenda
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ItemKind identifies what an agenda item is
+type ItemKind string
+
+const (
+	// ItemEvent is an existing calendar event
+	ItemEvent ItemKind = "event"
+	// ItemFocus is a proposed block of time to work on a task
+	ItemFocus ItemKind = "focus"
+	// ItemHabit is a proposed time to do a habit
+	ItemHabit ItemKind = "habit"
+	// ItemTravel is a proposed buffer to get to an event with a location
+	ItemTravel ItemKind = "travel"
+)
+
+const (
+	// FocusTasks is how many of the top-priority open tasks get a focus block
+	FocusTasks = 3
+	// DefaultFocusDuration is used for tasks without an estimate
+	DefaultFocusDuration = time.Hour
+	MinFocusDuration     = 30 * time.Minute
+	MaxFocusDuration     = 2 * time.Hour
+	// HabitDuration is the length of a suggested habit slot
+	HabitDuration = 15 * time.Minute
+	// TravelBuffer is blocked before events that have a location
+	TravelBuffer = 15 * time.Minute
+
+	// MaxAcceptItems caps the blocks created by one accept request
+	MaxAcceptItems = 50
+
+	// defaultWorkStart and defaultWorkEnd are the local working hours used when the
+	// user has not set any
+	defaultWorkStart = "09:00"
+	defaultWorkEnd   = "17:00"
+)
+
+var (
+	ErrInvalidDate  = errors.New("date must be formatted as YYYY-MM-DD")
+	ErrNoItems      = errors.New("no agenda items to accept")
+	ErrTooManyItems = errors.New("too many agenda items")
+	ErrInvalidItem  = errors.New("agenda item must be a focus, habit or travel block that ends after it starts")
+)
+
+// Item is one entry of the agenda. Events are already on the calendar; focus, habit and
+// travel items are proposals the user can accept.
+type Item struct {
+	Kind     ItemKind   `json:"kind"`
+	Title    string     `json:"title"`
+	Start    time.Time  `json:"start"`
+	End      time.Time  `json:"end"`
+	AllDay   bool       `json:"all_day,omitempty"`
+	Location string     `json:"location,omitempty"`
+	Proposed bool       `json:"proposed"`
+	EventID  *uuid.UUID `json:"event_id,omitempty"`
+	TaskID   *uuid.UUID `json:"task_id,omitempty"`
+	HabitID  *uuid.UUID `json:"habit_id,omitempty"`
+	Priority string     `json:"priority,omitempty"`
+}
+
+// UnscheduledTask is a top-priority task no focus block could be found for
+type UnscheduledTask struct {
+	TaskID   uuid.UUID `json:"task_id"`
+	Title    string    `json:"title"`
+	Priority string    `json:"priority"`
+	Minutes  int       `json:"minutes"`
+}
+
+// Agenda is the plan for one day in the user's timezone, ordered by start time
+type Agenda struct {
+	Date        string            `json:"date"`
+	Timezone    string            `json:"timezone"`
+	WorkStart   time.Time         `json:"work_start"`
+	WorkEnd     time.Time         `json:"work_end"`
+	Items       []Item            `json:"items"`
+	Unscheduled []UnscheduledTask `json:"unscheduled"`
+}
+
+// AcceptedBlock is a calendar event created from an accepted agenda item
+type AcceptedBlock struct {
+	Item    Item      `json:"item"`
+	EventID uuid.UUID `json:"event_id"`
+}
+
+// interval is a span of busy time
+type interval struct {
+	start, end time.Time
+}
@@ -0,0 +1,444 @@
+package agenda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// maxDayEvents bounds the events read for one day
+const maxDayEvents = 200
+
+// Service defines the interface for daily agenda generation
+type Service interface {
+	// GetAgenda composes the plan for a date (YYYY-MM-DD in the user's timezone), today when empty
+	GetAgenda(ctx context.Context, userID uuid.UUID, date string) (*Agenda, error)
+	// Accept turns proposed agenda items into calendar events
+	Accept(ctx context.Context, userID uuid.UUID, items []Item) ([]AcceptedBlock, error)
+}
+
+type service struct {
+	calendarService calendar.Service
+	taskService     task.Service
+	habitService    habits.Service
+	userService     user.Service
+	now             func() time.Time
+}
+
+// NewService creates a new agenda service instance
+func NewService(calendarService calendar.Service, taskService task.Service, habitService habits.Service, userService user.Service) Service {
+	return &service{
+		calendarService: calendarService,
+		taskService:     taskService,
+		habitService:    habitService,
+		userService:     userService,
+		now:             time.Now,
+	}
+}
+
+// GetAgenda lays out the day: the user's events, travel buffers before events with a
+// location, focus blocks for the top-priority open tasks in the free working time, and
+// a slot for each habit still to do, at its reminder time when that is free.
+func (s *service) GetAgenda(ctx context.Context, userID uuid.UUID, date string) (*Agenda, error) {
+	u, err := s.userService.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := user.ParseTimezone(u.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	now := s.now().In(loc)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if date != "" {
+		dayStart, err = time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			return nil, ErrInvalidDate
+		}
+	}
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	prefs, err := s.userService.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	workStart, workEnd, workday := workingHours(prefs, dayStart, loc)
+
+	agenda := &Agenda{
+		Date:        dayStart.Format("2006-01-02"),
+		Timezone:    loc.String(),
+		WorkStart:   workStart,
+		WorkEnd:     workEnd,
+		Items:       []Item{},
+		Unscheduled: []UnscheduledTask{},
+	}
+
+	events, err := s.calendarService.ListEvents(ctx, userID, dayStart, dayEnd, nil, 1, maxDayEvents)
+	if err != nil {
+		return nil, err
+	}
+	var busy []interval
+	for _, item := range eventItems(events.Events, dayStart, dayEnd) {
+		agenda.Items = append(agenda.Items, item)
+		if item.AllDay {
+			continue
+		}
+		busy = append(busy, interval{item.Start, item.End})
+	}
+
+	// Travel buffers go before events with a location unless the time is already taken
+	for _, item := range agenda.Items {
+		if item.Kind != ItemEvent || item.AllDay || item.Location == "" {
+			continue
+		}
+		buffer := interval{item.Start.Add(-TravelBuffer), item.Start}
+		if overlapsAny(busy, buffer) {
+			continue
+		}
+		eventID := *item.EventID
+		agenda.Items = append(agenda.Items, Item{
+			Kind:     ItemTravel,
+			Title:    "Travel to " + item.Title,
+			Start:    buffer.start,
+			End:      buffer.end,
+			Location: item.Location,
+			Proposed: true,
+			EventID:  &eventID,
+		})
+		busy = append(busy, buffer)
+	}
+
+	// Nothing is proposed in the past
+	earliest := dayStart
+	if now.After(earliest) {
+		earliest = now.Truncate(15 * time.Minute).Add(15 * time.Minute)
+	}
+
+	if workday {
+		tasks, err := s.topTasks(ctx, userID, dayEnd)
+		if err != nil {
+			return nil, err
+		}
+		from := workStart
+		if earliest.After(from) {
+			from = earliest
+		}
+		for _, t := range tasks {
+			duration := focusDuration(t)
+			start, ok := firstFit(busy, from, workEnd, duration)
+			if !ok {
+				agenda.Unscheduled = append(agenda.Unscheduled, UnscheduledTask{
+					TaskID:   t.ID,
+					Title:    t.Title,
+					Priority: string(t.Priority),
+					Minutes:  int(duration.Minutes()),
+				})
+				continue
+			}
+			taskID := t.ID
+			agenda.Items = append(agenda.Items, Item{
+				Kind:     ItemFocus,
+				Title:    "Focus: " + t.Title,
+				Start:    start,
+				End:      start.Add(duration),
+				Proposed: true,
+				TaskID:   &taskID,
+				Priority: string(t.Priority),
+			})
+			busy = append(busy, interval{start, start.Add(duration)})
+		}
+	}
+
+	dueHabits, err := s.habitService.GetHabitsDueToday(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range dueHabits {
+		if h.StartDay.After(dayEnd) || (h.EndDay != nil && h.EndDay.Before(dayStart)) {
+			continue
+		}
+		// Habits default to the end of the working day, outside the focus time
+		from := workEnd
+		if h.ReminderTime != nil {
+			if t, ok := utcClockOn(*h.ReminderTime, dayStart); ok {
+				from = t
+			}
+		}
+		if earliest.After(from) {
+			from = earliest
+		}
+		start, ok := firstFit(busy, from, dayEnd, HabitDuration)
+		if !ok {
+			start, ok = firstFit(busy, earliest, from, HabitDuration)
+		}
+		if !ok {
+			continue
+		}
+		habitID := h.ID
+		agenda.Items = append(agenda.Items, Item{
+			Kind:     ItemHabit,
+			Title:    h.Title,
+			Start:    start,
+			End:      start.Add(HabitDuration),
+			Proposed: true,
+			HabitID:  &habitID,
+		})
+		busy = append(busy, interval{start, start.Add(HabitDuration)})
+	}
+
+	sort.SliceStable(agenda.Items, func(i, j int) bool {
+		return agenda.Items[i].Start.Before(agenda.Items[j].Start)
+	})
+	return agenda, nil
+}
+
+// Accept creates a calendar event for each proposed item. Focus and travel blocks are
+// busy time; habit slots are left free.
+func (s *service) Accept(ctx context.Context, userID uuid.UUID, items []Item) ([]AcceptedBlock, error) {
+	if len(items) == 0 {
+		return nil, ErrNoItems
+	}
+	if len(items) > MaxAcceptItems {
+		return nil, ErrTooManyItems
+	}
+	for _, item := range items {
+		if item.Kind == ItemEvent || (item.Kind != ItemFocus && item.Kind != ItemHabit && item.Kind != ItemTravel) ||
+			!item.End.After(item.Start) {
+			return nil, ErrInvalidItem
+		}
+	}
+
+	accepted := make([]AcceptedBlock, 0, len(items))
+	for _, item := range items {
+		req := calendar.CreateCalendarEventRequest{
+			Title:        item.Title,
+			EventType:    calendar.EventTypeTask,
+			StartTime:    item.Start,
+			EndTime:      item.End,
+			Location:     item.Location,
+			Transparency: calendar.TransparencyOpaque,
+		}
+		switch item.Kind {
+		case ItemHabit:
+			req.EventType = calendar.EventTypeReminder
+			req.Transparency = calendar.TransparencyTransparent
+		case ItemTravel:
+			req.EventType = calendar.EventTypeNone
+		}
+		if req.Title == "" {
+			req.Title = string(item.Kind)
+		}
+
+		event, err := s.calendarService.CreateEvent(ctx, req, userID)
+		if err != nil {
+			return accepted, fmt.Errorf("failed to create %s block: %w", item.Kind, err)
+		}
+		item.Proposed = false
+		accepted = append(accepted, AcceptedBlock{Item: item, EventID: event.ID})
+	}
+	return accepted, nil
+}
+
+// topTasks returns the user's open tasks, most urgent first: by priority, then due date
+func (s *service) topTasks(ctx context.Context, userID uuid.UUID, before time.Time) ([]task.Task, error) {
+	tasks, _, err := s.taskService.ListTasks(ctx, task.TaskFilter{AssigneeID: &userID, PageSize: 100})
+	if err != nil {
+		return nil, err
+	}
+	open := tasks[:0]
+	for _, t := range tasks {
+		switch t.Status {
+		case task.TaskStatusCompleted, task.TaskStatusCancelled, task.TaskStatusDeferred:
+			continue
+		}
+		if t.StartDate.After(before) {
+			continue
+		}
+		open = append(open, t)
+	}
+
+	sort.SliceStable(open, func(i, j int) bool {
+		pi, pj := priorityRank(open[i].Priority), priorityRank(open[j].Priority)
+		if pi != pj {
+			return pi > pj
+		}
+		di, dj := open[i].DueDate, open[j].DueDate
+		switch {
+		case di == nil:
+			return false
+		case dj == nil:
+			return true
+		default:
+			return di.Before(*dj)
+		}
+	})
+	if len(open) > FocusTasks {
+		open = open[:FocusTasks]
+	}
+	return open, nil
+}
+
+func priorityRank(p task.TaskPriority) int {
+	switch p {
+	case task.TaskPriorityUrgent:
+		return 4
+	case task.TaskPriorityHigh:
+		return 3
+	case task.TaskPriorityMedium:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// focusDuration is the remaining estimate of a task, within the focus block bounds
+func focusDuration(t task.Task) time.Duration {
+	remaining := t.EstimatedHours - t.ActualHours
+	if remaining <= 0 {
+		return DefaultFocusDuration
+	}
+	d := time.Duration(remaining * float64(time.Hour)).Round(15 * time.Minute)
+	if d < MinFocusDuration {
+		return MinFocusDuration
+	}
+	if d > MaxFocusDuration {
+		return MaxFocusDuration
+	}
+	return d
+}
+
+// eventItems flattens events and their occurrences into items overlapping the day.
+// Transparent events are listed but do not block time.
+func eventItems(events []calendar.CalendarEvent, dayStart, dayEnd time.Time) []Item {
+	var items []Item
+	add := func(event calendar.CalendarEvent, title, location string, start, end time.Time, transparent bool) {
+		if !start.Before(dayEnd) || !end.After(dayStart) {
+			return
+		}
+		eventID := event.ID
+		items = append(items, Item{
+			Kind:     ItemEvent,
+			Title:    title,
+			Start:    start,
+			End:      end,
+			AllDay:   event.IsAllDay || transparent,
+			Location: location,
+			EventID:  &eventID,
+		})
+	}
+
+	for _, event := range events {
+		transparent := event.Transparency == calendar.TransparencyTransparent
+		if len(event.RecurrenceRules) == 0 {
+			add(event, event.Title, event.Location, event.StartTime, event.EndTime, transparent)
+			continue
+		}
+		length := event.EndTime.Sub(event.StartTime)
+		for _, occ := range event.Occurrences {
+			if occ.Status == calendar.OccurrenceStatusCancelled {
+				continue
+			}
+			title, location, end := event.Title, event.Location, occ.OccurrenceTime.Add(length)
+			if occ.Title != nil {
+				title = *occ.Title
+			}
+			if occ.Location != nil {
+				location = *occ.Location
+			}
+			if occ.EndTime != nil {
+				end = *occ.EndTime
+			}
+			occTransparent := transparent
+			if occ.Transparency != nil {
+				occTransparent = *occ.Transparency == calendar.TransparencyTransparent
+			}
+			add(event, title, location, occ.OccurrenceTime, end, occTransparent)
+		}
+	}
+	return items
+}
+
+// workingHours returns the working period that starts on the local day and whether the
+// day is a working day. Preferences store working hours in UTC; without them the
+// default local hours are used.
+func workingHours(prefs map[string]interface{}, dayStart time.Time, loc *time.Location) (time.Time, time.Time, bool) {
+	wh, _ := prefs[user.PreferenceNamespaceWorkingHours].(map[string]interface{})
+	startClock, _ := wh["start"].(string)
+	endClock, _ := wh["end"].(string)
+
+	start, okStart := utcClockOn(startClock, dayStart)
+	end, okEnd := utcClockOn(endClock, dayStart)
+	if !okStart || !okEnd {
+		start, _ = time.ParseInLocation("2006-01-02 15:04", dayStart.Format("2006-01-02")+" "+defaultWorkStart, loc)
+		end, _ = time.ParseInLocation("2006-01-02 15:04", dayStart.Format("2006-01-02")+" "+defaultWorkEnd, loc)
+		return start, end, dayStart.Weekday() != time.Saturday && dayStart.Weekday() != time.Sunday
+	}
+	if !end.After(start) {
+		end = end.Add(24 * time.Hour)
+	}
+
+	workday := true
+	if days, ok := wh["days"].([]interface{}); ok && len(days) > 0 {
+		weekday := user.Weekdays[start.UTC().Weekday()]
+		workday = false
+		for _, d := range days {
+			if d == weekday {
+				workday = true
+			}
+		}
+	}
+	return start, end, workday
+}
+
+// utcClockOn returns the first instant on or after dayStart whose UTC time of day is
+// clock (HH:MM)
+func utcClockOn(clock string, dayStart time.Time) (time.Time, bool) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, false
+	}
+	day := dayStart.UTC()
+	t := time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, time.UTC)
+	if t.Before(dayStart) {
+		t = t.Add(24 * time.Hour)
+	}
+	return t.In(dayStart.Location()), true
+}
+
+// firstFit returns the earliest start in [from, to) where d fits without overlapping busy time
+func firstFit(busy []interval, from, to time.Time, d time.Duration) (time.Time, bool) {
+	sorted := append([]interval(nil), busy...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start.Before(sorted[j].start) })
+
+	start := from
+	for _, b := range sorted {
+		if !b.end.After(start) {
+			continue
+		}
+		if !b.start.Before(start.Add(d)) {
+			break
+		}
+		start = b.end
+	}
+	if start.Add(d).After(to) {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+func overlapsAny(busy []interval, span interval) bool {
+	for _, b := range busy {
+		if b.start.Before(span.end) && span.start.Before(b.end) {
+			return true
+		}
+	}
+	return false
+}
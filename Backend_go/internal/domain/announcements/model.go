@@ -0,0 +1,161 @@
+package announcements
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Priority controls how prominently an announcement is delivered
+type Priority string
+
+const (
+	PriorityInfo      Priority = "info"
+	PriorityImportant Priority = "important"
+	PriorityCritical  Priority = "critical"
+)
+
+// IsValid checks if the priority is valid
+func (p Priority) IsValid() bool {
+	switch p {
+	case PriorityInfo, PriorityImportant, PriorityCritical:
+		return true
+	default:
+		return false
+	}
+}
+
+// AudienceType selects who receives an announcement
+type AudienceType string
+
+const (
+	// AudienceAll targets every member of the organization
+	AudienceAll AudienceType = "all"
+	// AudienceProjects targets the members of the listed projects
+	AudienceProjects AudienceType = "projects"
+	// AudienceUsers targets the listed users
+	AudienceUsers AudienceType = "users"
+)
+
+// IsValid checks if the audience type is valid
+func (a AudienceType) IsValid() bool {
+	switch a {
+	case AudienceAll, AudienceProjects, AudienceUsers:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	ErrAnnouncementNotFound = errors.New("announcement not found")
+	ErrInvalidInput         = errors.New("invalid input")
+	ErrInvalidAudience      = errors.New("invalid audience")
+	ErrEmptyAudience        = errors.New("announcement audience has no members")
+	ErrAlreadyExpired       = errors.New("expiry must be in the future")
+)
+
+// Announcement is a message broadcast by an organization admin to part or all of the organization.
+// The audience is resolved into recipients when the announcement is posted.
+type Announcement struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID      `json:"organization_id" gorm:"type:uuid;not null;index:idx_announcement_org"`
+	AuthorID       uuid.UUID      `json:"author_id" gorm:"type:uuid;not null"`
+	Title          string         `json:"title" gorm:"type:varchar(255);not null"`
+	Body           string         `json:"body" gorm:"type:text;not null"`
+	Priority       Priority       `json:"priority" gorm:"type:varchar(20);not null;default:'info'"`
+	AudienceType   AudienceType   `json:"audience_type" gorm:"type:varchar(20);not null;default:'all'"`
+	AudienceIDs    pq.StringArray `json:"audience_ids,omitempty" gorm:"type:text[]"`
+	RecipientCount int            `json:"recipient_count" gorm:"not null;default:0"`
+	ExpiresAt      *time.Time     `json:"expires_at,omitempty" gorm:"index"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Announcement model
+func (Announcement) TableName() string {
+	return "announcements"
+}
+
+// BeforeCreate is called before creating a new announcement record
+func (a *Announcement) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.Priority == "" {
+		a.Priority = PriorityInfo
+	}
+	if a.AudienceType == "" {
+		a.AudienceType = AudienceAll
+	}
+	a.CreatedAt = time.Now()
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating an announcement record
+func (a *Announcement) BeforeUpdate(tx *gorm.DB) error {
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsExpired reports whether the announcement is no longer shown at the given time
+func (a *Announcement) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !a.ExpiresAt.After(now)
+}
+
+// Recipient tracks delivery and acknowledgment of an announcement for one user
+type Recipient struct {
+	AnnouncementID uuid.UUID  `json:"announcement_id" gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;primary_key;index:idx_announcement_recipient_user"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Recipient model
+func (Recipient) TableName() string {
+	return "announcement_recipients"
+}
+
+// BeforeCreate is called before creating a new recipient record
+func (r *Recipient) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	return nil
+}
+
+// UserAnnouncement is an announcement as seen by one of its recipients
+type UserAnnouncement struct {
+	Announcement
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// Stats summarizes acknowledgments of an announcement
+type Stats struct {
+	Recipients   int64 `json:"recipients"`
+	Acknowledged int64 `json:"acknowledged"`
+}
+
+// UserFilter defines the options for listing a user's announcements
+type UserFilter struct {
+	UnacknowledgedOnly bool
+	OrganizationID     *uuid.UUID
+	Page               int
+	PageSize           int
+}
+
+// Normalize clamps pagination values to sane defaults
+func (f UserFilter) Normalize() UserFilter {
+	if f.PageSize <= 0 {
+		f.PageSize = 20
+	}
+	if f.PageSize > 100 {
+		f.PageSize = 100
+	}
+	if f.Page < 0 {
+		f.Page = 0
+	}
+	return f
+}
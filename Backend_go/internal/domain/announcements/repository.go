@@ -0,0 +1,244 @@
+package announcements
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// organizationMembersQuery selects the active users that belong to an organization.
+// There is no membership table, so membership is derived from ownership,
+// project membership and task participation.
+const organizationMembersQuery = `
+SELECT u.id FROM users u
+WHERE u.deleted_at IS NULL AND u.id IN (
+	SELECT owner_id FROM organizations WHERE id = @org
+	UNION SELECT creator_id FROM organizations WHERE id = @org
+	UNION SELECT pm.user_id FROM project_members pm
+		JOIN projects p ON p.id = pm.project_id
+		WHERE p.organization_id = @org AND p.deleted_at IS NULL
+	UNION SELECT creator_id FROM tasks WHERE organization_id = @org
+	UNION SELECT assignee_id FROM tasks WHERE organization_id = @org AND assignee_id IS NOT NULL
+)`
+
+// projectMembersQuery selects the active members of the given projects of an organization
+const projectMembersQuery = `
+SELECT DISTINCT u.id FROM users u
+JOIN project_members pm ON pm.user_id = u.id
+JOIN projects p ON p.id = pm.project_id
+WHERE u.deleted_at IS NULL AND p.deleted_at IS NULL
+	AND p.organization_id = @org AND p.id IN @projects`
+
+// Repository defines the interface for announcement data access
+type Repository interface {
+	Create(ctx context.Context, announcement *Announcement, recipients []uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Announcement, error)
+	ListByOrganization(ctx context.Context, orgID uuid.UUID, page, pageSize int) ([]Announcement, int64, error)
+	ListForUser(ctx context.Context, userID uuid.UUID, now time.Time, filter UserFilter) ([]UserAnnouncement, int64, error)
+	FindRecipient(ctx context.Context, announcementID, userID uuid.UUID) (*Recipient, error)
+	Acknowledge(ctx context.Context, announcementID, userID uuid.UUID, at time.Time) error
+	ListRecipients(ctx context.Context, announcementID uuid.UUID) ([]Recipient, error)
+	Stats(ctx context.Context, announcementIDs []uuid.UUID) (map[uuid.UUID]Stats, error)
+
+	OrganizationMembers(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error)
+	ProjectMembers(ctx context.Context, orgID uuid.UUID, projectIDs []uuid.UUID) ([]uuid.UUID, error)
+	FilterOrganizationMembers(ctx context.Context, orgID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new announcement repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// Create stores an announcement together with its resolved recipients
+func (r *repository) Create(ctx context.Context, announcement *Announcement, recipients []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		announcement.RecipientCount = len(recipients)
+		if err := tx.Create(announcement).Error; err != nil {
+			return err
+		}
+		if len(recipients) == 0 {
+			return nil
+		}
+		rows := make([]Recipient, len(recipients))
+		for i, userID := range recipients {
+			rows[i] = Recipient{AnnouncementID: announcement.ID, UserID: userID}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 500).Error
+	})
+}
+
+// Delete removes an announcement and its recipients
+func (r *repository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("announcement_id = ?", id).Delete(&Recipient{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Announcement{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAnnouncementNotFound
+		}
+		return nil
+	})
+}
+
+// FindByID retrieves an announcement by ID
+func (r *repository) FindByID(ctx context.Context, id uuid.UUID) (*Announcement, error) {
+	var announcement Announcement
+	if err := r.db.WithContext(ctx).First(&announcement, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAnnouncementNotFound
+		}
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+// ListByOrganization returns every announcement of an organization, newest first
+func (r *repository) ListByOrganization(ctx context.Context, orgID uuid.UUID, page, pageSize int) ([]Announcement, int64, error) {
+	var list []Announcement
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Announcement{}).Where("organization_id = ?", orgID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Offset(page * pageSize).
+		Limit(pageSize).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// ListForUser returns the unexpired announcements addressed to a user, newest first
+func (r *repository) ListForUser(ctx context.Context, userID uuid.UUID, now time.Time, filter UserFilter) ([]UserAnnouncement, int64, error) {
+	var list []UserAnnouncement
+	var total int64
+
+	query := r.db.WithContext(ctx).Table("announcements a").
+		Joins("JOIN announcement_recipients ar ON ar.announcement_id = a.id").
+		Where("ar.user_id = ?", userID).
+		Where("a.expires_at IS NULL OR a.expires_at > ?", now)
+	if filter.OrganizationID != nil {
+		query = query.Where("a.organization_id = ?", *filter.OrganizationID)
+	}
+	if filter.UnacknowledgedOnly {
+		query = query.Where("ar.acknowledged_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Select("a.*, ar.acknowledged_at").
+		Order("a.created_at DESC").
+		Offset(filter.Page * filter.PageSize).
+		Limit(filter.PageSize).
+		Scan(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// FindRecipient retrieves the recipient record of a user for an announcement
+func (r *repository) FindRecipient(ctx context.Context, announcementID, userID uuid.UUID) (*Recipient, error) {
+	var recipient Recipient
+	err := r.db.WithContext(ctx).
+		First(&recipient, "announcement_id = ? AND user_id = ?", announcementID, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAnnouncementNotFound
+		}
+		return nil, err
+	}
+	return &recipient, nil
+}
+
+// Acknowledge records the first acknowledgment of an announcement by a recipient
+func (r *repository) Acknowledge(ctx context.Context, announcementID, userID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&Recipient{}).
+		Where("announcement_id = ? AND user_id = ? AND acknowledged_at IS NULL", announcementID, userID).
+		Update("acknowledged_at", at).Error
+}
+
+// ListRecipients returns the recipients of an announcement, acknowledged ones first
+func (r *repository) ListRecipients(ctx context.Context, announcementID uuid.UUID) ([]Recipient, error) {
+	var recipients []Recipient
+	err := r.db.WithContext(ctx).
+		Where("announcement_id = ?", announcementID).
+		Order("acknowledged_at DESC NULLS LAST").
+		Find(&recipients).Error
+	return recipients, err
+}
+
+// Stats counts recipients and acknowledgments for the given announcements
+func (r *repository) Stats(ctx context.Context, announcementIDs []uuid.UUID) (map[uuid.UUID]Stats, error) {
+	stats := make(map[uuid.UUID]Stats, len(announcementIDs))
+	if len(announcementIDs) == 0 {
+		return stats, nil
+	}
+
+	var rows []struct {
+		AnnouncementID uuid.UUID
+		Recipients     int64
+		Acknowledged   int64
+	}
+	err := r.db.WithContext(ctx).Model(&Recipient{}).
+		Select("announcement_id, COUNT(*) AS recipients, COUNT(acknowledged_at) AS acknowledged").
+		Where("announcement_id IN ?", announcementIDs).
+		Group("announcement_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stats[row.AnnouncementID] = Stats{Recipients: row.Recipients, Acknowledged: row.Acknowledged}
+	}
+	return stats, nil
+}
+
+// OrganizationMembers returns the active users belonging to an organization
+func (r *repository) OrganizationMembers(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Raw(organizationMembersQuery, map[string]interface{}{"org": orgID}).
+		Scan(&ids).Error
+	return ids, err
+}
+
+// ProjectMembers returns the active members of the given projects of an organization
+func (r *repository) ProjectMembers(ctx context.Context, orgID uuid.UUID, projectIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Raw(projectMembersQuery, map[string]interface{}{"org": orgID, "projects": projectIDs}).
+		Scan(&ids).Error
+	return ids, err
+}
+
+// FilterOrganizationMembers keeps the users that belong to the organization
+func (r *repository) FilterOrganizationMembers(ctx context.Context, orgID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Raw("SELECT m.id FROM ("+organizationMembersQuery+") m WHERE m.id IN @users",
+			map[string]interface{}{"org": orgID, "users": userIDs}).
+		Scan(&ids).Error
+	return ids, err
+}
@@ -0,0 +1,252 @@
+package announcements
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CreateAnnouncementInput is the input for posting an announcement
+type CreateAnnouncementInput struct {
+	OrganizationID uuid.UUID
+	AuthorID       uuid.UUID
+	Title          string
+	Body           string
+	Priority       Priority
+	AudienceType   AudienceType
+	AudienceIDs    []uuid.UUID
+	ExpiresAt      *time.Time
+}
+
+// AnnouncementWithStats is an announcement together with its acknowledgment counts
+type AnnouncementWithStats struct {
+	Announcement
+	Stats Stats
+}
+
+// Service defines the interface for announcement business logic. The methods that
+// manage an organization's announcements leave checking the user's permission to the
+// caller.
+type Service interface {
+	CreateAnnouncement(ctx context.Context, input CreateAnnouncementInput) (*Announcement, error)
+	DeleteAnnouncement(ctx context.Context, orgID, id uuid.UUID) error
+	ListOrganizationAnnouncements(ctx context.Context, orgID uuid.UUID, page, pageSize int) ([]AnnouncementWithStats, int64, error)
+	ListAcknowledgments(ctx context.Context, orgID, id uuid.UUID) ([]Recipient, error)
+
+	ListForUser(ctx context.Context, userID uuid.UUID, filter UserFilter) ([]UserAnnouncement, int64, error)
+	Acknowledge(ctx context.Context, id, userID uuid.UUID) (*UserAnnouncement, error)
+}
+
+type service struct {
+	repo     Repository
+	notifier notification.DomainNotifier
+	logger   *zap.Logger
+}
+
+// NewService creates a new announcement service instance
+func NewService(repo Repository, notifier notification.DomainNotifier, logger *zap.Logger) Service {
+	return &service{
+		repo:     repo,
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// CreateAnnouncement stores an announcement, resolves its audience and notifies every recipient
+func (s *service) CreateAnnouncement(ctx context.Context, input CreateAnnouncementInput) (*Announcement, error) {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Title == "" || input.Body == "" || input.OrganizationID == uuid.Nil || input.AuthorID == uuid.Nil {
+		return nil, ErrInvalidInput
+	}
+	if input.Priority == "" {
+		input.Priority = PriorityInfo
+	}
+	if !input.Priority.IsValid() {
+		return nil, fmt.Errorf("%w: unknown priority %q", ErrInvalidInput, input.Priority)
+	}
+	if input.AudienceType == "" {
+		input.AudienceType = AudienceAll
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrAlreadyExpired
+	}
+	recipients, err := s.resolveAudience(ctx, input.OrganizationID, input.AudienceType, input.AudienceIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, ErrEmptyAudience
+	}
+
+	audienceIDs := make([]string, len(input.AudienceIDs))
+	for i, id := range input.AudienceIDs {
+		audienceIDs[i] = id.String()
+	}
+	announcement := &Announcement{
+		OrganizationID: input.OrganizationID,
+		AuthorID:       input.AuthorID,
+		Title:          input.Title,
+		Body:           input.Body,
+		Priority:       input.Priority,
+		AudienceType:   input.AudienceType,
+		AudienceIDs:    audienceIDs,
+		ExpiresAt:      input.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, announcement, recipients); err != nil {
+		return nil, err
+	}
+
+	// Fan out without holding up the author's request
+	go s.notifyRecipients(context.Background(), announcement, recipients)
+
+	return announcement, nil
+}
+
+// DeleteAnnouncement retracts an announcement of the organization from every recipient
+func (s *service) DeleteAnnouncement(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.findInOrganization(ctx, orgID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// ListOrganizationAnnouncements returns every announcement of an organization, including expired ones, with acknowledgment counts
+func (s *service) ListOrganizationAnnouncements(ctx context.Context, orgID uuid.UUID, page, pageSize int) ([]AnnouncementWithStats, int64, error) {
+	filter := UserFilter{Page: page, PageSize: pageSize}.Normalize()
+
+	list, total, err := s.repo.ListByOrganization(ctx, orgID, filter.Page, filter.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uuid.UUID, len(list))
+	for i := range list {
+		ids[i] = list[i].ID
+	}
+	stats, err := s.repo.Stats(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]AnnouncementWithStats, len(list))
+	for i := range list {
+		result[i] = AnnouncementWithStats{Announcement: list[i], Stats: stats[list[i].ID]}
+	}
+	return result, total, nil
+}
+
+// ListAcknowledgments returns who received an announcement of the organization and when
+// they acknowledged it
+func (s *service) ListAcknowledgments(ctx context.Context, orgID, id uuid.UUID) ([]Recipient, error) {
+	if _, err := s.findInOrganization(ctx, orgID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRecipients(ctx, id)
+}
+
+// ListForUser returns the active announcements addressed to a user
+func (s *service) ListForUser(ctx context.Context, userID uuid.UUID, filter UserFilter) ([]UserAnnouncement, int64, error) {
+	return s.repo.ListForUser(ctx, userID, time.Now(), filter.Normalize())
+}
+
+// Acknowledge marks an announcement as read by one of its recipients.
+// Acknowledging twice keeps the original acknowledgment time.
+func (s *service) Acknowledge(ctx context.Context, id, userID uuid.UUID) (*UserAnnouncement, error) {
+	announcement, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.FindRecipient(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	if announcement.IsExpired(time.Now()) {
+		return nil, ErrAnnouncementNotFound
+	}
+
+	if err := s.repo.Acknowledge(ctx, id, userID, time.Now()); err != nil {
+		return nil, err
+	}
+	recipient, err := s.repo.FindRecipient(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &UserAnnouncement{Announcement: *announcement, AcknowledgedAt: recipient.AcknowledgedAt}, nil
+}
+
+// resolveAudience turns the audience of an announcement into the users that receive it
+func (s *service) resolveAudience(ctx context.Context, orgID uuid.UUID, audience AudienceType, ids []uuid.UUID) ([]uuid.UUID, error) {
+	switch audience {
+	case AudienceAll:
+		if len(ids) > 0 {
+			return nil, fmt.Errorf("%w: audience_ids must be empty when targeting everyone", ErrInvalidAudience)
+		}
+		return s.repo.OrganizationMembers(ctx, orgID)
+	case AudienceProjects:
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("%w: at least one project is required", ErrInvalidAudience)
+		}
+		return s.repo.ProjectMembers(ctx, orgID, ids)
+	case AudienceUsers:
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("%w: at least one user is required", ErrInvalidAudience)
+		}
+		return s.repo.FilterOrganizationMembers(ctx, orgID, ids)
+	default:
+		return nil, fmt.Errorf("%w: unknown audience type %q", ErrInvalidAudience, audience)
+	}
+}
+
+// notifyRecipients delivers the announcement through the notification channels matching its priority
+func (s *service) notifyRecipients(ctx context.Context, announcement *Announcement, recipients []uuid.UUID) {
+	if s.notifier == nil {
+		return
+	}
+
+	methods := deliveryMethods(announcement.Priority)
+	data := map[string]string{
+		"announcement_id": announcement.ID.String(),
+		"organization_id": announcement.OrganizationID.String(),
+		"priority":        string(announcement.Priority),
+	}
+	for _, userID := range recipients {
+		err := s.notifier.NotifyUserWithDelivery(ctx, userID, notification.Announcement,
+			announcement.Title, announcement.Body, data, "announcement", announcement.ID, methods)
+		if err != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to notify announcement recipient",
+				zap.String("announcement_id", announcement.ID.String()),
+				zap.String("user_id", userID.String()),
+				zap.Error(err))
+		}
+	}
+}
+
+// deliveryMethods escalates the notification channels with the announcement priority
+func deliveryMethods(priority Priority) []notification.DeliveryMethod {
+	switch priority {
+	case PriorityCritical:
+		return []notification.DeliveryMethod{notification.InApp, notification.Push, notification.Email}
+	case PriorityImportant:
+		return []notification.DeliveryMethod{notification.InApp, notification.Push}
+	default:
+		return []notification.DeliveryMethod{notification.InApp}
+	}
+}
+
+// findInOrganization returns an announcement, treating those of other organizations as missing
+func (s *service) findInOrganization(ctx context.Context, orgID, id uuid.UUID) (*Announcement, error) {
+	announcement, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if announcement.OrganizationID != orgID {
+		return nil, ErrAnnouncementNotFound
+	}
+	return announcement, nil
+}
@@ -0,0 +1,59 @@
+package apikeys
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrKeyNotFound  = errors.New("API key not found")
+	ErrInvalidKey   = errors.New("invalid or revoked API key")
+	ErrInvalidName  = errors.New("name must be between 1 and 100 characters")
+	ErrInvalidScope = auth.ErrInvalidScope
+	ErrTooManyKeys  = errors.New("too many active API keys")
+)
+
+// APIKey lets a machine client act as the user who created it, limited to its scopes
+// (see auth.ScopesAllow).
+// Only a hash of the key is stored; the key itself is shown once when it is created.
+type APIKey struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	// OrganizationID is the organization requests act in when they name none
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty" gorm:"type:uuid"`
+	Name           string     `json:"name" gorm:"size:100;not null"`
+	// Prefix is the start of the key, so users can tell their keys apart
+	Prefix     string         `json:"prefix" gorm:"size:16;not null"`
+	KeyHash    string         `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Scopes     pq.StringArray `json:"scopes" gorm:"type:text[];not null"`
+	LastUsedAt *time.Time     `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time     `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time     `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"not null;default:current_timestamp"`
+
+	// Roles and Permissions are those of the user, loaded when the key authenticates
+	Roles       []string `json:"-" gorm:"-"`
+	Permissions []string `json:"-" gorm:"-"`
+}
+
+// TableName specifies the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// CreateInput describes a new key
+type CreateInput struct {
+	Name           string
+	Scopes         []string
+	OrganizationID *uuid.UUID
+	ExpiresAt      *time.Time
+}
+
+// Active reports whether the key can still authenticate
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,146 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// keyPrefix marks Compass API keys so they are easy to spot, e.g. by secret scanners
+	keyPrefix = "cmp_"
+	// maxActiveKeys is how many unrevoked keys a user may have
+	maxActiveKeys = 25
+	// touchInterval is how stale the last use of a key may get before it is recorded again,
+	// so busy clients do not write on every request
+	touchInterval = time.Minute
+)
+
+// RoleSource provides the roles and permissions of a user
+type RoleSource interface {
+	GetUserRolesAndPermissions(ctx context.Context, userID uuid.UUID) ([]string, []string, error)
+}
+
+// Service defines the interface for managing and authenticating API keys
+type Service interface {
+	// CreateKey creates a key for the user and returns it along with the key itself,
+	// which cannot be retrieved later
+	CreateKey(ctx context.Context, userID uuid.UUID, input CreateInput) (*APIKey, string, error)
+	ListKeys(ctx context.Context, userID uuid.UUID) ([]APIKey, error)
+	RevokeKey(ctx context.Context, userID, id uuid.UUID) error
+	// Authenticate returns the active key matching a key presented by a client, with the
+	// roles and permissions of its user
+	Authenticate(ctx context.Context, key string) (*APIKey, error)
+}
+
+type service struct {
+	repo   Repository
+	roles  RoleSource
+	logger *zap.Logger
+}
+
+// NewService creates a new API key service
+func NewService(repo Repository, roles RoleSource, logger *zap.Logger) Service {
+	return &service{
+		repo:   repo,
+		roles:  roles,
+		logger: logger,
+	}
+}
+
+// hashKey is how keys are stored. Keys are random enough that a fast hash is safe.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *service) CreateKey(ctx context.Context, userID uuid.UUID, input CreateInput) (*APIKey, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 100 {
+		return nil, "", ErrInvalidName
+	}
+	scopes, err := auth.NormalizeScopes(input.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	active, err := s.repo.CountActive(ctx, userID, now)
+	if err != nil {
+		return nil, "", err
+	}
+	if active >= maxActiveKeys {
+		return nil, "", ErrTooManyKeys
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	key := keyPrefix + hex.EncodeToString(secret)
+
+	apiKey := &APIKey{
+		ID:             uuid.New(),
+		UserID:         userID,
+		OrganizationID: input.OrganizationID,
+		Name:           name,
+		Prefix:         key[:len(keyPrefix)+8],
+		KeyHash:        hashKey(key),
+		Scopes:         scopes,
+		ExpiresAt:      input.ExpiresAt,
+		CreatedAt:      now,
+	}
+	if err := s.repo.Create(ctx, apiKey); err != nil {
+		return nil, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (s *service) ListKeys(ctx context.Context, userID uuid.UUID) ([]APIKey, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) RevokeKey(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.Revoke(ctx, userID, id, time.Now())
+}
+
+func (s *service) Authenticate(ctx context.Context, key string) (*APIKey, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return nil, ErrInvalidKey
+	}
+	apiKey, err := s.repo.FindByHash(ctx, hashKey(key))
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !apiKey.Active(now) {
+		return nil, ErrInvalidKey
+	}
+
+	apiKey.Roles, apiKey.Permissions, err = s.roles.GetUserRolesAndPermissions(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= touchInterval {
+		if err := s.repo.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+			// Tracking use must not lock clients out
+			tracing.Logger(ctx, s.logger).Warn("Failed to record API key use", zap.String("key_id", apiKey.ID.String()), zap.Error(err))
+		} else {
+			apiKey.LastUsedAt = &now
+		}
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,117 @@
+package attachments
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OwnerType is the kind of item a file is attached to
+type OwnerType string
+
+const (
+	OwnerTask    OwnerType = "task"
+	OwnerTodo    OwnerType = "todo"
+	OwnerComment OwnerType = "comment"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrEmptyFile          = errors.New("file is empty")
+	ErrFileTooLarge       = errors.New("file exceeds the upload size limit")
+	ErrTypeNotAllowed     = errors.New("file type is not allowed")
+	ErrInvalidFileName    = errors.New("invalid file name")
+	ErrNotAllowed         = errors.New("only the uploader or the item's owner can delete an attachment")
+	ErrQuarantined        = errors.New("attachment is quarantined until it has been scanned for malware")
+	ErrInfected           = errors.New("attachment was blocked because it contains malware")
+	ErrNoPreview          = errors.New("attachment has no preview")
+)
+
+// ScanStatus is where an attachment stands in malware scanning
+type ScanStatus string
+
+const (
+	// ScanPending files are quarantined until a scanner has checked them
+	ScanPending ScanStatus = "pending"
+	ScanClean   ScanStatus = "clean"
+	// ScanInfected files are blocked and their content is deleted
+	ScanInfected ScanStatus = "infected"
+	// ScanFailed files could not be scanned after several attempts and stay quarantined
+	ScanFailed ScanStatus = "failed"
+	// ScanSkipped files were uploaded while no scanner was configured
+	ScanSkipped ScanStatus = "skipped"
+)
+
+// Downloadable reports whether files in this status may be served
+func (s ScanStatus) Downloadable() bool {
+	return s == ScanClean || s == ScanSkipped
+}
+
+// PreviewStatus is where an attachment stands in thumbnail and preview generation
+type PreviewStatus string
+
+const (
+	// PreviewPending files are waiting for the worker, which only renders files that
+	// passed the malware scan
+	PreviewPending PreviewStatus = "pending"
+	PreviewReady   PreviewStatus = "ready"
+	// PreviewNone files are of a type without previews or could not be rendered
+	PreviewNone PreviewStatus = "none"
+)
+
+// PreviewVariant is one of the images rendered for an attachment
+type PreviewVariant string
+
+const (
+	// VariantThumbnail fits in ThumbnailSize pixels, for list views
+	VariantThumbnail PreviewVariant = "thumbnail"
+	// VariantPreview fits in PreviewSize pixels: the image itself, or the first page of a PDF
+	VariantPreview PreviewVariant = "preview"
+)
+
+// PreviewVariants lists the images rendered for every previewable attachment
+var PreviewVariants = []PreviewVariant{VariantThumbnail, VariantPreview}
+
+// Attachment is a file uploaded to a task, todo or task comment. The file itself lives in
+// the configured store under StorageKey; the row only holds its metadata.
+type Attachment struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	OwnerType      OwnerType  `json:"owner_type" gorm:"type:varchar(20);not null;index:idx_attachment_owner,priority:1"`
+	OwnerID        uuid.UUID  `json:"owner_id" gorm:"type:uuid;not null;index:idx_attachment_owner,priority:2"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty" gorm:"type:uuid;index"`
+	UploadedBy     uuid.UUID  `json:"uploaded_by" gorm:"type:uuid;not null;index"`
+	FileName       string     `json:"file_name" gorm:"size:255;not null"`
+	ContentType    string     `json:"content_type" gorm:"size:255;not null"`
+	Size           int64      `json:"size" gorm:"not null"`
+	// Checksum is the hex SHA-256 of the content
+	Checksum   string `json:"checksum" gorm:"size:64;not null"`
+	StorageKey string `json:"-" gorm:"size:255;not null;uniqueIndex"`
+	// ScanStatus defaults to skipped for files uploaded before scanning existed
+	ScanStatus   ScanStatus `json:"scan_status" gorm:"type:varchar(20);not null;default:'skipped';index"`
+	Threat       string     `json:"threat,omitempty" gorm:"size:255"`
+	ScanAttempts int        `json:"-" gorm:"not null;default:0"`
+	ScannedAt    *time.Time `json:"scanned_at,omitempty"`
+	// PreviewStatus defaults to pending so files uploaded before previews existed get them
+	PreviewStatus PreviewStatus `json:"preview_status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the Attachment model
+func (Attachment) TableName() string {
+	return "attachments"
+}
+
+// PreviewKey is where a rendered variant of the attachment is stored, next to the file
+func (a *Attachment) PreviewKey(variant PreviewVariant) string {
+	return a.StorageKey + "-" + string(variant) + ".png"
+}
+
+// BeforeCreate is called before creating a new attachment record
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"  // register the GIF decoder
+	_ "image/jpeg" // register the JPEG decoder
+	"image/png"
+	"io"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+const (
+	// ThumbnailSize and PreviewSize bound the width and height, in pixels, of the
+	// rendered variants
+	ThumbnailSize = 256
+	PreviewSize   = 1024
+	// maxPreviewPixels guards against small files that decode into huge images
+	maxPreviewPixels = 25_000_000
+	// renderTimeout bounds rendering the first page of a PDF
+	renderTimeout = time.Minute
+)
+
+// imagePreviewTypes are the image types previews are rendered for; the standard library
+// cannot decode the other allowed image types
+var imagePreviewTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// PDFRenderer renders the first page of a PDF as an image
+type PDFRenderer interface {
+	RenderFirstPage(ctx context.Context, content io.Reader, size int) (image.Image, error)
+}
+
+// popplerRenderer renders pages with poppler's pdftoppm
+type popplerRenderer struct {
+	path string
+}
+
+// NewPopplerRenderer creates a renderer that runs the pdftoppm binary at path
+func NewPopplerRenderer(path string) PDFRenderer {
+	return &popplerRenderer{path: path}
+}
+
+func (r *popplerRenderer) RenderFirstPage(ctx context.Context, content io.Reader, size int) (image.Image, error) {
+	ctx, cancel := context.WithTimeout(ctx, renderTimeout)
+	defer cancel()
+
+	// With "-" as input and no output root, pdftoppm reads the PDF from stdin and writes
+	// the page to stdout
+	cmd := exec.CommandContext(ctx, r.path, "-f", "1", "-l", "1", "-singlefile", "-png",
+		"-scale-to", strconv.Itoa(size), "-")
+	cmd.Stdin = content
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftoppm failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return png.Decode(&stdout)
+}
+
+// decodeImage decodes an image after checking its dimensions
+func decodeImage(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPreviewPixels {
+		return nil, fmt.Errorf("image dimensions %dx%d are out of range", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// fitWithin scales img down to fit a size×size box, keeping its aspect ratio. Each pixel
+// of the result averages the box of source pixels it covers; images that already fit are
+// only copied.
+func fitWithin(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+	dstWidth, dstHeight := size, size
+	if width > height {
+		dstHeight = max(1, height*size/width)
+	} else {
+		dstWidth = max(1, width*size/height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := span(y, dstHeight, height)
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := span(x, dstWidth, width)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := src.RGBAAt(sx, sy)
+					r += uint32(c.R)
+					g += uint32(c.G)
+					b += uint32(c.B)
+					a += uint32(c.A)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// span returns the source pixels [from, to) covered by destination pixel i when scaling
+// length source pixels to size destination pixels. It is never empty.
+func span(i, size, length int) (int, int) {
+	from := i * length / size
+	to := (i + 1) * length / size
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
@@ -0,0 +1,117 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for attachment data access
+type Repository interface {
+	Create(ctx context.Context, attachment *Attachment) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Attachment, error)
+	// ListByOwners returns the attachments of the owners, oldest first
+	ListByOwners(ctx context.Context, ownerType OwnerType, ownerIDs []uuid.UUID) ([]Attachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListPending returns up to limit attachments, oldest first, that are quarantined or
+	// that passed scanning and still need previews
+	ListPending(ctx context.Context, limit int) ([]Attachment, error)
+	// RecordScan stores the verdict on a quarantined attachment. It reports false when the
+	// attachment was already scanned or deleted, so a verdict is only acted on once.
+	RecordScan(ctx context.Context, id uuid.UUID, status ScanStatus, threat string, scannedAt time.Time) (bool, error)
+	// RecordScanFailure counts a failed scan of a quarantined attachment, giving up on it
+	// with ScanFailed after maxAttempts
+	RecordScanFailure(ctx context.Context, id uuid.UUID, maxAttempts int) error
+	// RecordPreview stores the outcome of preview generation. It reports false when the
+	// attachment was deleted in the meantime.
+	RecordPreview(ctx context.Context, id uuid.UUID, status PreviewStatus) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new attachment repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Create(ctx context.Context, attachment *Attachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+func (r *repository) FindByID(ctx context.Context, id uuid.UUID) (*Attachment, error) {
+	var attachment Attachment
+	if err := r.db.WithContext(ctx).First(&attachment, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *repository) ListByOwners(ctx context.Context, ownerType OwnerType, ownerIDs []uuid.UUID) ([]Attachment, error) {
+	var list []Attachment
+	if len(ownerIDs) == 0 {
+		return list, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("owner_type = ? AND owner_id IN ?", ownerType, ownerIDs).
+		Order("created_at ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *repository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&Attachment{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (r *repository) ListPending(ctx context.Context, limit int) ([]Attachment, error) {
+	var list []Attachment
+	err := r.db.WithContext(ctx).
+		Where("scan_status = ? OR (preview_status = ? AND scan_status IN ?)",
+			ScanPending, PreviewPending, []ScanStatus{ScanClean, ScanSkipped}).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+func (r *repository) RecordScan(ctx context.Context, id uuid.UUID, status ScanStatus, threat string, scannedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Attachment{}).
+		Where("id = ? AND scan_status = ?", id, ScanPending).
+		Updates(map[string]interface{}{
+			"scan_status": status,
+			"threat":      threat,
+			"scanned_at":  scannedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *repository) RecordScanFailure(ctx context.Context, id uuid.UUID, maxAttempts int) error {
+	return r.db.WithContext(ctx).Model(&Attachment{}).
+		Where("id = ? AND scan_status = ?", id, ScanPending).
+		Updates(map[string]interface{}{
+			"scan_attempts": gorm.Expr("scan_attempts + 1"),
+			"scan_status":   gorm.Expr("CASE WHEN scan_attempts + 1 >= ? THEN ? ELSE scan_status END", maxAttempts, ScanFailed),
+		}).Error
+}
+
+func (r *repository) RecordPreview(ctx context.Context, id uuid.UUID, status PreviewStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Attachment{}).
+		Where("id = ?", id).
+		Update("preview_status", status)
+	return result.RowsAffected > 0, result.Error
+}
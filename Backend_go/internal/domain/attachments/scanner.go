@@ -0,0 +1,153 @@
+package attachments
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// scanTimeout bounds a single scan, including sending the file to the scanner
+const scanTimeout = 2 * time.Minute
+
+// ScanResult is a scanner's verdict on a file
+type ScanResult struct {
+	Infected bool
+	// Threat names what was found in an infected file
+	Threat string
+}
+
+// Scanner checks the content of an uploaded file for malware
+type Scanner interface {
+	Scan(ctx context.Context, content io.Reader) (*ScanResult, error)
+}
+
+// clamAVScanner streams files to a clamd daemon with its INSTREAM command
+type clamAVScanner struct {
+	address string
+}
+
+// NewClamAVScanner creates a scanner for the clamd daemon listening on address, such as
+// "localhost:3310"
+func NewClamAVScanner(address string) Scanner {
+	return &clamAVScanner{address: address}
+}
+
+// clamChunkSize is the size of the chunks a file is streamed in; clamd rejects chunks
+// larger than its StreamMaxLength
+const clamChunkSize = 64 << 10
+
+func (s *clamAVScanner) Scan(ctx context.Context, content io.Reader) (*ScanResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to send file to clamd: %w", err)
+	}
+	buf := make([]byte, clamChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := content.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, fmt.Errorf("failed to send file to clamd: %w", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, fmt.Errorf("failed to send file to clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	// A zero-length chunk ends the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("failed to send file to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamReply reads replies such as "stream: OK" and "stream: Eicar-Signature FOUND"
+func parseClamReply(reply string) (*ScanResult, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return &ScanResult{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &ScanResult{Infected: true, Threat: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("clamd could not scan the file: %s", reply)
+	}
+}
+
+// httpScanner posts files to an external scanning API
+type httpScanner struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPScanner creates a scanner that posts each file as the body of a request to url.
+// The API answers with JSON such as {"infected": true, "threat": "Eicar-Signature"}.
+// apiKey, when set, is sent as a bearer token.
+func NewHTTPScanner(url, apiKey string) Scanner {
+	return &httpScanner{url: url, apiKey: apiKey, client: &http.Client{Timeout: scanTimeout}}
+}
+
+func (s *httpScanner) Scan(ctx context.Context, content io.Reader) (*ScanResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, content)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach scanning API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("scanning API returned status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	var verdict struct {
+		Infected *bool  `json:"infected"`
+		Threat   string `json:"threat"`
+	}
+	if err := json.Unmarshal(body, &verdict); err != nil || verdict.Infected == nil {
+		return nil, fmt.Errorf("scanning API returned an invalid verdict")
+	}
+	return &ScanResult{Infected: *verdict.Infected, Threat: verdict.Threat}, nil
+}
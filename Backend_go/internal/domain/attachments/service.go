@@ -0,0 +1,527 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/storage"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxSize is the upload size limit when none is configured
+const DefaultMaxSize = 25 << 20
+
+// DefaultAllowedTypes are the accepted MIME types when none are configured. Types
+// browsers render as active content, like HTML and SVG, are left out on purpose.
+var DefaultAllowedTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"application/pdf", "text/plain", "text/csv", "text/markdown", "application/json",
+	"application/zip",
+	"application/msword", "application/vnd.ms-excel", "application/vnd.ms-powerpoint",
+	"application/vnd.openxmlformats-officedocument.*", "application/vnd.oasis.opendocument.*",
+}
+
+// extensionTypes are trusted over content sniffing for formats it cannot tell apart:
+// office documents sniff as zip or octet-stream, and text formats as text/plain
+var extensionTypes = map[string]string{
+	".csv":  "text/csv",
+	".md":   "text/markdown",
+	".json": "application/json",
+	".doc":  "application/msword",
+	".xls":  "application/vnd.ms-excel",
+	".ppt":  "application/vnd.ms-powerpoint",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	".odp":  "application/vnd.oasis.opendocument.presentation",
+}
+
+const (
+	// maxScanAttempts is how often a file the scanner fails on is retried before it is
+	// left quarantined as failed
+	maxScanAttempts = 5
+	// processBatchSize caps the files picked up by one poll of the worker
+	processBatchSize = 100
+)
+
+// Config holds the upload limits. Zero values use the defaults.
+type Config struct {
+	MaxSize int64
+	// AllowedTypes lists the accepted MIME types; a trailing "*" matches a prefix
+	AllowedTypes []string
+	// Scanner checks uploads for malware. Without one uploads are served right away and
+	// marked as skipped.
+	Scanner Scanner
+	// PDFRenderer renders the first page of PDFs for their previews. Without one PDFs
+	// get no preview; images always do.
+	PDFRenderer PDFRenderer
+}
+
+// UploadInput is a file to attach
+type UploadInput struct {
+	OwnerType      OwnerType
+	OwnerID        uuid.UUID
+	OrganizationID *uuid.UUID
+	UploadedBy     uuid.UUID
+	FileName       string
+	Size           int64
+	Content        io.Reader
+}
+
+// Service defines the interface for attachment operations. Callers check access to
+// the owning item; the service only checks the file and who may delete it.
+type Service interface {
+	Upload(ctx context.Context, input UploadInput) (*Attachment, error)
+	List(ctx context.Context, ownerType OwnerType, ownerID uuid.UUID) ([]Attachment, error)
+	// ListByOwners groups the attachments of several owners by owner
+	ListByOwners(ctx context.Context, ownerType OwnerType, ownerIDs []uuid.UUID) (map[uuid.UUID][]Attachment, error)
+	Get(ctx context.Context, ownerType OwnerType, ownerID, id uuid.UUID) (*Attachment, error)
+	// Open returns the content of an attachment, failing with ErrQuarantined until it has
+	// been scanned and with ErrInfected once malware was found in it
+	Open(ctx context.Context, attachment *Attachment) (io.ReadCloser, error)
+	// OpenPreview returns a rendered PNG variant of an attachment, failing with
+	// ErrNoPreview when it has none
+	OpenPreview(ctx context.Context, attachment *Attachment, variant PreviewVariant) (io.ReadCloser, error)
+	// Delete removes an attachment. Its uploader and itemOwnerID, the owner of the item
+	// it is attached to, may delete it.
+	Delete(ctx context.Context, ownerType OwnerType, ownerID, id, userID, itemOwnerID uuid.UUID) error
+	// RemoveFiles deletes the stored files and previews of attachments whose rows were
+	// deleted elsewhere, such as when their organization is purged
+	RemoveFiles(ctx context.Context, attachments []Attachment)
+	MaxSize() int64
+	// Pending lists the attachments waiting for the worker
+	Pending(ctx context.Context) ([]uuid.UUID, error)
+	// Queued signals the ids of new uploads that need processing
+	Queued() <-chan uuid.UUID
+	// Process scans a quarantined attachment for malware, releasing it when it is clean
+	// and deleting its content when it is infected, then renders the previews of
+	// attachments that passed
+	Process(ctx context.Context, id uuid.UUID) error
+}
+
+type service struct {
+	repo         Repository
+	store        storage.Store
+	maxSize      int64
+	allowedTypes []string
+	scanner      Scanner
+	pdfRenderer  PDFRenderer
+	bus          events.Publisher
+	queued       chan uuid.UUID
+	logger       *zap.Logger
+}
+
+// NewService creates a new attachment service
+func NewService(repo Repository, store storage.Store, config Config, bus events.Publisher, logger *zap.Logger) Service {
+	s := &service{
+		repo:         repo,
+		store:        store,
+		maxSize:      config.MaxSize,
+		allowedTypes: config.AllowedTypes,
+		scanner:      config.Scanner,
+		pdfRenderer:  config.PDFRenderer,
+		bus:          bus,
+		queued:       make(chan uuid.UUID, 64),
+		logger:       logger,
+	}
+	if s.maxSize <= 0 {
+		s.maxSize = DefaultMaxSize
+	}
+	if len(s.allowedTypes) == 0 {
+		s.allowedTypes = DefaultAllowedTypes
+	}
+	return s
+}
+
+func (s *service) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload validates the file, stores it and records its metadata. The type is sniffed
+// from the content rather than taken from the client.
+func (s *service) Upload(ctx context.Context, input UploadInput) (*Attachment, error) {
+	fileName, err := cleanFileName(input.FileName)
+	if err != nil {
+		return nil, err
+	}
+	if input.Size <= 0 {
+		return nil, ErrEmptyFile
+	}
+	if input.Size > s.maxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(input.Content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	contentType := detectContentType(head, fileName)
+	if !s.allowed(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrTypeNotAllowed, contentType)
+	}
+
+	attachment := &Attachment{
+		ID:             uuid.New(),
+		OwnerType:      input.OwnerType,
+		OwnerID:        input.OwnerID,
+		OrganizationID: input.OrganizationID,
+		UploadedBy:     input.UploadedBy,
+		FileName:       fileName,
+		ContentType:    contentType,
+		Size:           input.Size,
+		ScanStatus:     ScanSkipped,
+		PreviewStatus:  PreviewNone,
+	}
+	if s.scanner != nil {
+		attachment.ScanStatus = ScanPending
+	}
+	if s.previewable(contentType) {
+		attachment.PreviewStatus = PreviewPending
+	}
+	attachment.StorageKey = fmt.Sprintf("%ss/%s/%s", attachment.OwnerType, attachment.OwnerID, attachment.ID)
+
+	hash := sha256.New()
+	content := io.TeeReader(io.MultiReader(bytes.NewReader(head), io.LimitReader(input.Content, input.Size-int64(n))), hash)
+	if err := s.store.Put(ctx, attachment.StorageKey, content, input.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		s.removeObject(ctx, attachment.StorageKey)
+		return nil, err
+	}
+	if attachment.ScanStatus == ScanPending || attachment.PreviewStatus == PreviewPending {
+		// The worker also polls for pending attachments, so a full channel only delays them
+		select {
+		case s.queued <- attachment.ID:
+		default:
+		}
+	}
+	return attachment, nil
+}
+
+func (s *service) List(ctx context.Context, ownerType OwnerType, ownerID uuid.UUID) ([]Attachment, error) {
+	return s.repo.ListByOwners(ctx, ownerType, []uuid.UUID{ownerID})
+}
+
+func (s *service) ListByOwners(ctx context.Context, ownerType OwnerType, ownerIDs []uuid.UUID) (map[uuid.UUID][]Attachment, error) {
+	list, err := s.repo.ListByOwners(ctx, ownerType, ownerIDs)
+	if err != nil {
+		return nil, err
+	}
+	byOwner := make(map[uuid.UUID][]Attachment)
+	for _, attachment := range list {
+		byOwner[attachment.OwnerID] = append(byOwner[attachment.OwnerID], attachment)
+	}
+	return byOwner, nil
+}
+
+// Get returns an attachment of an owner; attachments of other owners are not found
+func (s *service) Get(ctx context.Context, ownerType OwnerType, ownerID, id uuid.UUID) (*Attachment, error) {
+	attachment, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.OwnerType != ownerType || attachment.OwnerID != ownerID {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+func (s *service) Open(ctx context.Context, attachment *Attachment) (io.ReadCloser, error) {
+	switch {
+	case attachment.ScanStatus == ScanInfected:
+		return nil, ErrInfected
+	case attachment.ScanStatus != "" && !attachment.ScanStatus.Downloadable():
+		return nil, ErrQuarantined
+	}
+	content, err := s.store.Open(ctx, attachment.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, ErrAttachmentNotFound
+	}
+	return content, err
+}
+
+func (s *service) OpenPreview(ctx context.Context, attachment *Attachment, variant PreviewVariant) (io.ReadCloser, error) {
+	if attachment.PreviewStatus != PreviewReady || !attachment.ScanStatus.Downloadable() {
+		return nil, ErrNoPreview
+	}
+	content, err := s.store.Open(ctx, attachment.PreviewKey(variant))
+	if err == storage.ErrNotFound {
+		return nil, ErrNoPreview
+	}
+	return content, err
+}
+
+func (s *service) Delete(ctx context.Context, ownerType OwnerType, ownerID, id, userID, itemOwnerID uuid.UUID) error {
+	attachment, err := s.Get(ctx, ownerType, ownerID, id)
+	if err != nil {
+		return err
+	}
+	if attachment.UploadedBy != userID && itemOwnerID != userID {
+		return ErrNotAllowed
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.removeFiles(ctx, attachment)
+	return nil
+}
+
+func (s *service) RemoveFiles(ctx context.Context, attachments []Attachment) {
+	for i := range attachments {
+		s.removeFiles(ctx, &attachments[i])
+	}
+}
+
+// removeFiles deletes the stored file of an attachment and its previews
+func (s *service) removeFiles(ctx context.Context, attachment *Attachment) {
+	s.removeObject(ctx, attachment.StorageKey)
+	if attachment.PreviewStatus == PreviewReady {
+		s.removePreviews(ctx, attachment)
+	}
+}
+
+func (s *service) Pending(ctx context.Context) ([]uuid.UUID, error) {
+	list, err := s.repo.ListPending(ctx, processBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(list))
+	for i, attachment := range list {
+		ids[i] = attachment.ID
+	}
+	return ids, nil
+}
+
+func (s *service) Queued() <-chan uuid.UUID {
+	return s.queued
+}
+
+func (s *service) Process(ctx context.Context, id uuid.UUID) error {
+	attachment, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if err == ErrAttachmentNotFound {
+			return nil
+		}
+		return err
+	}
+	if attachment.ScanStatus == ScanPending {
+		if err := s.scan(ctx, attachment); err != nil {
+			return err
+		}
+	}
+	if attachment.PreviewStatus == PreviewPending && attachment.ScanStatus.Downloadable() {
+		return s.renderPreviews(ctx, attachment)
+	}
+	return nil
+}
+
+// scan checks a quarantined attachment and updates its ScanStatus with the verdict
+func (s *service) scan(ctx context.Context, attachment *Attachment) error {
+	if s.scanner == nil {
+		return nil
+	}
+	id := attachment.ID
+	result, err := s.scanContent(ctx, attachment.StorageKey)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		if recordErr := s.repo.RecordScanFailure(ctx, id, maxScanAttempts); recordErr != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to record attachment scan failure", zap.String("attachment_id", id.String()), zap.Error(recordErr))
+		}
+		return fmt.Errorf("failed to scan attachment: %w", err)
+	}
+
+	status := ScanClean
+	if result.Infected {
+		status = ScanInfected
+	}
+	recorded, err := s.repo.RecordScan(ctx, id, status, result.Threat, time.Now())
+	if err != nil || !recorded {
+		return err
+	}
+	attachment.ScanStatus = status
+	if !result.Infected {
+		return nil
+	}
+
+	tracing.Logger(ctx, s.logger).Warn("Malware found in attachment",
+		zap.String("attachment_id", id.String()), zap.String("threat", result.Threat))
+	s.removeObject(ctx, attachment.StorageKey)
+	s.bus.Publish(ctx, events.AttachmentInfected{
+		AttachmentID:   attachment.ID,
+		OwnerType:      string(attachment.OwnerType),
+		OwnerID:        attachment.OwnerID,
+		OrganizationID: attachment.OrganizationID,
+		UploadedBy:     attachment.UploadedBy,
+		FileName:       attachment.FileName,
+		Threat:         result.Threat,
+	})
+	return nil
+}
+
+func (s *service) scanContent(ctx context.Context, key string) (*ScanResult, error) {
+	content, err := s.store.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	return s.scanner.Scan(ctx, content)
+}
+
+// renderPreviews stores the thumbnail and preview of an image or PDF. Files of other
+// types, and files that cannot be decoded or rendered, are marked as having no preview;
+// store failures are returned so the worker retries them.
+func (s *service) renderPreviews(ctx context.Context, attachment *Attachment) error {
+	if !s.previewable(attachment.ContentType) {
+		_, err := s.repo.RecordPreview(ctx, attachment.ID, PreviewNone)
+		return err
+	}
+	content, err := s.store.Open(ctx, attachment.StorageKey)
+	if err != nil && err != storage.ErrNotFound {
+		return err
+	}
+	var img image.Image
+	if err == nil {
+		img, err = s.previewSource(ctx, attachment, content)
+		content.Close()
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		tracing.Logger(ctx, s.logger).Warn("Failed to render attachment preview",
+			zap.String("attachment_id", attachment.ID.String()), zap.Error(err))
+		_, err := s.repo.RecordPreview(ctx, attachment.ID, PreviewNone)
+		return err
+	}
+
+	preview := fitWithin(img, PreviewSize)
+	variants := map[PreviewVariant]image.Image{
+		VariantPreview:   preview,
+		VariantThumbnail: fitWithin(preview, ThumbnailSize),
+	}
+	for variant, rendered := range variants {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, rendered); err != nil {
+			return err
+		}
+		if err := s.store.Put(ctx, attachment.PreviewKey(variant), &buf, int64(buf.Len()), "image/png"); err != nil {
+			return fmt.Errorf("failed to store attachment %s: %w", variant, err)
+		}
+	}
+
+	recorded, err := s.repo.RecordPreview(ctx, attachment.ID, PreviewReady)
+	if err == nil && !recorded {
+		// The attachment was deleted while its previews were rendered
+		s.removePreviews(ctx, attachment)
+	}
+	return err
+}
+
+// previewSource decodes an image attachment, or renders the first page of a PDF
+func (s *service) previewSource(ctx context.Context, attachment *Attachment, content io.Reader) (image.Image, error) {
+	if attachment.ContentType == "application/pdf" {
+		return s.pdfRenderer.RenderFirstPage(ctx, content, PreviewSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(content, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	return decodeImage(data)
+}
+
+// previewable reports whether previews are rendered for files of a type
+func (s *service) previewable(contentType string) bool {
+	return imagePreviewTypes[contentType] || (contentType == "application/pdf" && s.pdfRenderer != nil)
+}
+
+func (s *service) removePreviews(ctx context.Context, attachment *Attachment) {
+	for _, variant := range PreviewVariants {
+		s.removeObject(ctx, attachment.PreviewKey(variant))
+	}
+}
+
+// removeObject deletes a stored file. Failures only leave an orphaned file behind, so
+// they are logged rather than returned.
+func (s *service) removeObject(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		tracing.Logger(ctx, s.logger).Warn("Failed to delete attachment file", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (s *service) allowed(contentType string) bool {
+	for _, allowed := range s.allowedTypes {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(contentType, prefix) {
+				return true
+			}
+		} else if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// detectContentType sniffs the type of the content, only falling back to the extension
+// for formats sniffing cannot identify
+func detectContentType(head []byte, fileName string) string {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	byExtension, ok := extensionTypes[strings.ToLower(path.Ext(fileName))]
+	if !ok {
+		return sniffed
+	}
+	switch {
+	case sniffed == "application/octet-stream", sniffed == "application/zip":
+		if !strings.HasPrefix(byExtension, "text/") && byExtension != "application/json" {
+			return byExtension
+		}
+	case sniffed == "text/plain":
+		if strings.HasPrefix(byExtension, "text/") || byExtension == "application/json" {
+			return byExtension
+		}
+	}
+	return sniffed
+}
+
+// cleanFileName keeps the base name of an uploaded file without control characters
+func cleanFileName(name string) (string, error) {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == "/" || name == ".." || !utf8.ValidString(name) {
+		return "", ErrInvalidFileName
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		runes := []rune(name)
+		name = string(runes[:255])
+	}
+	return name, nil
+}
@@ -0,0 +1,49 @@
+package attachments
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+)
+
+// SubscribeInfectionNotifications tells the uploader of an infected file, and the admins
+// of its organization, that it was blocked
+func SubscribeInfectionNotifications(bus *events.Bus, orgs organization.AdminDirectory, notifier notification.DomainNotifier) {
+	events.Subscribe(bus, "attachment_infection_notifications", events.Async, func(ctx context.Context, event events.AttachmentInfected) error {
+		data := map[string]string{
+			"attachmentId": event.AttachmentID.String(),
+			"fileName":     event.FileName,
+			"ownerType":    event.OwnerType,
+			"ownerId":      event.OwnerID.String(),
+			"threat":       event.Threat,
+		}
+		title := "File blocked: " + event.FileName
+		if err := notifier.NotifyUser(ctx, event.UploadedBy, notification.AttachmentInfected, title,
+			fmt.Sprintf("Your upload %s contains malware (%s) and was removed.", event.FileName, event.Threat),
+			data, "attachment", event.AttachmentID); err != nil {
+			return err
+		}
+		if event.OrganizationID == nil {
+			return nil
+		}
+
+		admins, err := organization.Admins(ctx, orgs, *event.OrganizationID)
+		if err != nil {
+			return err
+		}
+		content := fmt.Sprintf("A file uploaded to your organization, %s, contains malware (%s) and was removed.", event.FileName, event.Threat)
+		for _, adminID := range admins {
+			if adminID == event.UploadedBy {
+				continue
+			}
+			if err := notifier.NotifyUser(ctx, adminID, notification.AttachmentInfected, title, content,
+				data, "attachment", event.AttachmentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
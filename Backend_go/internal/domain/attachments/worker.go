@@ -0,0 +1,87 @@
+package attachments
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// pollInterval is how often the worker looks for attachments it was not signalled
+// about, such as uploads left behind by a restart or scans to retry
+const pollInterval = 30 * time.Second
+
+// Worker processes new attachments one at a time: it scans quarantined files and renders
+// the previews of files that passed
+type Worker struct {
+	service Service
+	logger  *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorker creates a new attachment worker
+func NewWorker(service Service, logger *zap.Logger) *Worker {
+	return &Worker{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Start begins processing attachments in the background
+func (w *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		w.poll(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case id := <-w.service.Queued():
+				w.process(ctx, id)
+			case <-ticker.C:
+				w.poll(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the worker; an attachment it interrupts is picked up again after a restart
+func (w *Worker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+func (w *Worker) poll(ctx context.Context) {
+	ids, err := w.service.Pending(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("Failed to list pending attachments", zap.Error(err))
+		}
+		return
+	}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		w.process(ctx, id)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, id uuid.UUID) {
+	if err := w.service.Process(ctx, id); err != nil && ctx.Err() == nil {
+		w.logger.Error("Failed to process attachment", zap.String("attachment_id", id.String()), zap.Error(err))
+	}
+}
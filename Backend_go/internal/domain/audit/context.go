@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type requestKey struct{}
+
+// request is who made a request and from where
+type request struct {
+	actorID   *uuid.UUID
+	ipAddress string
+	userAgent string
+}
+
+func requestFrom(ctx context.Context) request {
+	r, _ := ctx.Value(requestKey{}).(request)
+	return r
+}
+
+// WithClient returns a copy of ctx carrying the client of a request, for the entries
+// logged while handling it
+func WithClient(ctx context.Context, ipAddress, userAgent string) context.Context {
+	r := requestFrom(ctx)
+	r.ipAddress = ipAddress
+	r.userAgent = userAgent
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// WithActor returns a copy of ctx carrying the authenticated user of a request
+func WithActor(ctx context.Context, userID uuid.UUID) context.Context {
+	r := requestFrom(ctx)
+	r.actorID = &userID
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// ActorFrom returns the authenticated user of the request ctx belongs to, if any
+func ActorFrom(ctx context.Context) (uuid.UUID, bool) {
+	if actorID := requestFrom(ctx).actorID; actorID != nil {
+		return *actorID, true
+	}
+	return uuid.Nil, false
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Action is what an audited user did
+type Action string
+
+const (
+	ActionLogin                Action = "user.login"
+	ActionLoginFailed          Action = "user.login_failed"
+	ActionMemberRoleChanged    Action = "member.role_changed"
+	ActionOrgDeletionScheduled Action = "organization.deletion_scheduled"
+	ActionOrgDeletionCancelled Action = "organization.deletion_cancelled"
+	ActionTaskDeleted          Action = "task.deleted"
+	ActionUserMerged           Action = "user.merged"
+	ActionWorkflowExecuted     Action = "workflow.executed"
+)
+
+// Entry is a record of the audit log: who did what to which target, when and from where.
+// Entries are only ever appended.
+type Entry struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID      `json:"organization_id" gorm:"type:uuid;not null;index:idx_audit_org_created,priority:1"`
+	ActorID        *uuid.UUID     `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+	Action         Action         `json:"action" gorm:"type:varchar(50);not null;index"`
+	TargetType     string         `json:"target_type,omitempty" gorm:"type:varchar(50)"`
+	TargetID       *uuid.UUID     `json:"target_id,omitempty" gorm:"type:uuid;index"`
+	IPAddress      string         `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
+	UserAgent      string         `json:"user_agent,omitempty" gorm:"type:varchar(255)"`
+	Metadata       datatypes.JSON `json:"metadata" gorm:"type:jsonb;default:'{}'"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"not null;index:idx_audit_org_created,priority:2"`
+
+	// ActorEmail is the email of the actor, joined in when entries are listed
+	ActorEmail string `json:"actor_email,omitempty" gorm:"->;-:migration"`
+}
+
+// TableName specifies the table name for the Entry model
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+// Event describes something to append to the audit log. The actor, IP address and user
+// agent default to those of the request in the context.
+type Event struct {
+	// OrganizationID is the organization the event happened in. Events of a user outside
+	// any organization, like signing in, are logged in every organization of the actor.
+	OrganizationID uuid.UUID
+	ActorID        *uuid.UUID
+	Action         Action
+	TargetType     string
+	TargetID       *uuid.UUID
+	Metadata       map[string]interface{}
+}
+
+// Filter narrows down the entries of an organization
+type Filter struct {
+	ActorID    *uuid.UUID
+	Actions    []Action
+	TargetType string
+	TargetID   *uuid.UUID
+	Since      *time.Time
+	Until      *time.Time
+	Page       int
+	PageSize   int
+}
@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for audit log data access
+type Repository interface {
+	CreateBatch(ctx context.Context, entries []Entry) error
+	// CreateForActorOrganizations appends the entry to the log of every organization its
+	// actor is a member of
+	CreateForActorOrganizations(ctx context.Context, entry *Entry) error
+	List(ctx context.Context, orgID uuid.UUID, filter Filter) ([]Entry, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new audit log repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) CreateBatch(ctx context.Context, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(entries, 100).Error
+}
+
+func (r *repository) CreateForActorOrganizations(ctx context.Context, entry *Entry) error {
+	return r.db.WithContext(ctx).Exec(`INSERT INTO audit_log
+		(id, organization_id, actor_id, action, target_type, target_id, ip_address, user_agent, metadata, created_at)
+		SELECT uuid_generate_v4(), m.organization_id, ?, ?, ?, ?, ?, ?, ?, ?
+		FROM organization_members m WHERE m.user_id = ?`,
+		entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, entry.IPAddress, entry.UserAgent,
+		entry.Metadata, entry.CreatedAt, *entry.ActorID).Error
+}
+
+func (r *repository) List(ctx context.Context, orgID uuid.UUID, filter Filter) ([]Entry, int64, error) {
+	query := r.db.WithContext(ctx).Model(&Entry{}).Where("audit_log.organization_id = ?", orgID)
+	if filter.ActorID != nil {
+		query = query.Where("audit_log.actor_id = ?", *filter.ActorID)
+	}
+	if len(filter.Actions) > 0 {
+		query = query.Where("audit_log.action IN ?", filter.Actions)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("audit_log.target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("audit_log.target_id = ?", *filter.TargetID)
+	}
+	if filter.Since != nil {
+		query = query.Where("audit_log.created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("audit_log.created_at < ?", *filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []Entry
+	err := query.
+		Select("audit_log.*, users.email AS actor_email").
+		Joins("LEFT JOIN users ON users.id = audit_log.actor_id").
+		Order("audit_log.created_at DESC, audit_log.id DESC").
+		Offset(filter.Page * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&entries).Error
+	return entries, total, err
+}
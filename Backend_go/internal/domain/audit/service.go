@@ -0,0 +1,250 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+const (
+	// bufferSize is how many entries can wait for the writer before Audit writes inline
+	bufferSize = 1024
+	// batchSize is how many entries the writer inserts at once
+	batchSize = 100
+	// flushInterval is how long an entry waits at most for its batch to fill
+	flushInterval = time.Second
+	// writeTimeout bounds writing a batch
+	writeTimeout = 10 * time.Second
+	// maxExportRows caps a CSV export; narrower filters export the rest
+	maxExportRows  = 100000
+	exportPageSize = 1000
+)
+
+// Auditor is implemented by anything that can append to the audit log. Entries are
+// written in the background, so auditing never slows down or fails the audited change.
+type Auditor interface {
+	Audit(ctx context.Context, event Event)
+}
+
+// Service defines the interface for the audit log
+type Service interface {
+	Auditor
+	// List returns a page of the organization's entries, newest first
+	List(ctx context.Context, orgID uuid.UUID, filter Filter) ([]Entry, int64, error)
+	// ExportCSV writes the organization's entries matching the filter as CSV, newest
+	// first, ignoring its paging
+	ExportCSV(ctx context.Context, orgID uuid.UUID, filter Filter, w io.Writer) error
+	// Start runs the background writer
+	Start()
+	// Stop writes the entries still waiting and stops the background writer
+	Stop()
+}
+
+type service struct {
+	repo    Repository
+	logger  *zap.Logger
+	entries chan Entry
+
+	mu      sync.RWMutex
+	stopped bool
+	done    sync.WaitGroup
+}
+
+// NewService creates a new audit log service
+func NewService(repo Repository, logger *zap.Logger) Service {
+	return &service{
+		repo:    repo,
+		logger:  logger,
+		entries: make(chan Entry, bufferSize),
+	}
+}
+
+func (s *service) Audit(ctx context.Context, event Event) {
+	entry := Entry{
+		ID:             uuid.New(),
+		OrganizationID: event.OrganizationID,
+		ActorID:        event.ActorID,
+		Action:         event.Action,
+		TargetType:     event.TargetType,
+		TargetID:       event.TargetID,
+		Metadata:       datatypes.JSON("{}"),
+		CreatedAt:      time.Now(),
+	}
+	req := requestFrom(ctx)
+	if entry.ActorID == nil {
+		entry.ActorID = req.actorID
+	}
+	entry.IPAddress = req.ipAddress
+	if len(req.userAgent) > 255 {
+		entry.UserAgent = req.userAgent[:255]
+	} else {
+		entry.UserAgent = req.userAgent
+	}
+	if len(event.Metadata) > 0 {
+		if b, err := json.Marshal(event.Metadata); err == nil {
+			entry.Metadata = datatypes.JSON(b)
+		}
+	}
+
+	if entry.Action == "" || (entry.OrganizationID == uuid.Nil && entry.ActorID == nil) {
+		tracing.Logger(ctx, s.logger).Warn("Skipping audit entry without action or organization",
+			zap.String("action", string(entry.Action)))
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.stopped {
+		select {
+		case s.entries <- entry:
+			return
+		default:
+		}
+	}
+	// The writer is stopped or behind; an audit entry is not worth losing to save a write
+	s.write([]Entry{entry})
+}
+
+func (s *service) Start() {
+	s.done.Add(1)
+	go s.run()
+}
+
+func (s *service) Stop() {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.entries)
+	}
+	s.mu.Unlock()
+	s.done.Wait()
+}
+
+func (s *service) run() {
+	defer s.done.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, batchSize)
+	for {
+		select {
+		case entry, ok := <-s.entries:
+			if !ok {
+				s.write(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= batchSize {
+				s.write(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.write(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// write inserts entries, logging those it could not write
+func (s *service) write(entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	orgEntries := make([]Entry, 0, len(entries))
+	for i := range entries {
+		if entries[i].OrganizationID != uuid.Nil {
+			orgEntries = append(orgEntries, entries[i])
+			continue
+		}
+		if err := s.repo.CreateForActorOrganizations(ctx, &entries[i]); err != nil {
+			s.logger.Error("Failed to write audit entry",
+				zap.String("action", string(entries[i].Action)),
+				zap.String("actor_id", entries[i].ActorID.String()),
+				zap.Error(err))
+		}
+	}
+	if err := s.repo.CreateBatch(ctx, orgEntries); err != nil {
+		s.logger.Error("Failed to write audit entries", zap.Int("entries", len(orgEntries)), zap.Error(err))
+	}
+}
+
+func (s *service) List(ctx context.Context, orgID uuid.UUID, filter Filter) ([]Entry, int64, error) {
+	if filter.Page < 0 {
+		filter.Page = 0
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	return s.repo.List(ctx, orgID, filter)
+}
+
+func (s *service) ExportCSV(ctx context.Context, orgID uuid.UUID, filter Filter, w io.Writer) error {
+	out := csv.NewWriter(w)
+	err := out.Write([]string{"created_at", "action", "actor_id", "actor_email", "target_type", "target_id", "ip_address", "user_agent", "metadata"})
+	if err != nil {
+		return err
+	}
+
+	filter.PageSize = exportPageSize
+	for filter.Page = 0; filter.Page*exportPageSize < maxExportRows; filter.Page++ {
+		entries, _, err := s.repo.List(ctx, orgID, filter)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			err := out.Write([]string{
+				e.CreatedAt.UTC().Format(time.RFC3339),
+				string(e.Action),
+				optionalID(e.ActorID),
+				CSVCell(e.ActorEmail),
+				e.TargetType,
+				optionalID(e.TargetID),
+				e.IPAddress,
+				CSVCell(e.UserAgent),
+				CSVCell(string(e.Metadata)),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		out.Flush()
+		if err := out.Error(); err != nil {
+			return err
+		}
+		if len(entries) < exportPageSize {
+			break
+		}
+	}
+	return nil
+}
+
+// CSVCell keeps a value a spreadsheet would read as a formula as plain text. Every CSV
+// export of user input goes through it.
+func CSVCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
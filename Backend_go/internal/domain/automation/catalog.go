@@ -0,0 +1,132 @@
+package automation
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// TriggersPath is where polling triggers are served; the trigger key is appended
+const TriggersPath = "/api/automation/triggers/"
+
+// entityLabels holds the human-readable name of each entity
+var entityLabels = map[Entity]string{
+	EntityTask:    "Task",
+	EntityTodo:    "Todo",
+	EntityEvent:   "Calendar Event",
+	EntityHabit:   "Habit",
+	EntityProject: "Project",
+}
+
+var (
+	taskStatuses   = []string{"Upcoming", "In Progress", "Completed", "Cancelled", "Blocked", "Under Review", "Deferred"}
+	taskPriorities = []string{"Low", "Medium", "High", "Urgent"}
+	todoStatuses   = []string{"pending", "in_progress", "archived"}
+	todoPriorities = []string{"low", "medium", "high"}
+	eventTypes     = []string{"None", "Task", "Meeting", "Todo", "Holiday", "Reminder"}
+)
+
+// actions lists the API endpoints exposed as automation actions
+var actions = []Action{
+	{
+		Key: "task.create", Noun: "Task", Label: "Create Task",
+		Description: "Creates a task in a project.",
+		Method:      http.MethodPost, Path: "/api/tasks",
+		Fields: []Field{
+			{Key: "title", Label: "Title", Type: "string", Required: true, In: "body"},
+			{Key: "description", Label: "Description", Type: "text", In: "body"},
+			{Key: "project_id", Label: "Project", Type: "uuid", Required: true, In: "body"},
+			{Key: "organization_id", Label: "Organization", Type: "uuid", Required: true, In: "body"},
+			{Key: "status", Label: "Status", Type: "string", Required: true, In: "body", Choices: taskStatuses},
+			{Key: "priority", Label: "Priority", Type: "string", Required: true, In: "body", Choices: taskPriorities},
+			{Key: "start_date", Label: "Start Date", Type: "datetime", Required: true, In: "body"},
+			{Key: "due_date", Label: "Due Date", Type: "datetime", In: "body"},
+			{Key: "assignee_id", Label: "Assignee", Type: "uuid", In: "body"},
+		},
+	},
+	{
+		Key: "task.update_status", Noun: "Task", Label: "Update Task Status",
+		Description: "Moves a task to another status.",
+		Method:      http.MethodPatch, Path: "/api/tasks/{id}/status",
+		Fields: []Field{
+			{Key: "id", Label: "Task", Type: "uuid", Required: true, In: "path"},
+			{Key: "status", Label: "Status", Type: "string", Required: true, In: "body", Choices: taskStatuses},
+		},
+	},
+	{
+		Key: "todo.create", Noun: "Todo", Label: "Create Todo",
+		Description: "Adds a todo to the user's default list.",
+		Method:      http.MethodPost, Path: "/api/todos",
+		Fields: []Field{
+			{Key: "title", Label: "Title", Type: "string", Required: true, In: "body"},
+			{Key: "description", Label: "Description", Type: "text", In: "body"},
+			{Key: "status", Label: "Status", Type: "string", Required: true, In: "body", Choices: todoStatuses},
+			{Key: "priority", Label: "Priority", Type: "string", Required: true, In: "body", Choices: todoPriorities},
+			{Key: "due_date", Label: "Due Date", Type: "datetime", In: "body"},
+		},
+	},
+	{
+		Key: "todo.complete", Noun: "Todo", Label: "Complete Todo",
+		Description: "Marks a todo as done.",
+		Method:      http.MethodPatch, Path: "/api/todos/{id}/complete",
+		Fields: []Field{
+			{Key: "id", Label: "Todo", Type: "uuid", Required: true, In: "path"},
+		},
+	},
+	{
+		Key: "event.create", Noun: "Calendar Event", Label: "Create Calendar Event",
+		Description: "Adds an event to the user's calendar.",
+		Method:      http.MethodPost, Path: "/api/calendar/events",
+		Fields: []Field{
+			{Key: "title", Label: "Title", Type: "string", Required: true, In: "body"},
+			{Key: "description", Label: "Description", Type: "text", In: "body"},
+			{Key: "event_type", Label: "Type", Type: "string", Required: true, In: "body", Choices: eventTypes},
+			{Key: "start_time", Label: "Start", Type: "datetime", Required: true, In: "body"},
+			{Key: "end_time", Label: "End", Type: "datetime", Required: true, In: "body"},
+			{Key: "location", Label: "Location", Type: "string", In: "body"},
+		},
+	},
+	{
+		Key: "habit.complete", Noun: "Habit", Label: "Complete Habit",
+		Description: "Marks a habit as done for today.",
+		Method:      http.MethodPost, Path: "/api/habits/{id}/complete",
+		Fields: []Field{
+			{Key: "id", Label: "Habit", Type: "uuid", Required: true, In: "path"},
+		},
+	},
+}
+
+// BuildCatalog returns the trigger and action catalog
+func BuildCatalog() Catalog {
+	var triggers []Trigger
+	for _, entity := range Entities {
+		label := entityLabels[entity]
+		triggers = append(triggers,
+			Trigger{
+				Key:         TriggerKey(entity, TriggerCreated),
+				Noun:        label,
+				Label:       "New " + label,
+				Description: fmt.Sprintf("Triggers when a %s is created.", label),
+				Method:      http.MethodGet,
+				Path:        TriggersPath + TriggerKey(entity, TriggerCreated),
+				DedupeKey:   []string{"id"},
+				CursorParam: "cursor",
+			},
+			Trigger{
+				Key:         TriggerKey(entity, TriggerUpdated),
+				Noun:        label,
+				Label:       "New or Updated " + label,
+				Description: fmt.Sprintf("Triggers when a %s is created or changed.", label),
+				Method:      http.MethodGet,
+				Path:        TriggersPath + TriggerKey(entity, TriggerUpdated),
+				DedupeKey:   []string{"id", "updated_at"},
+				CursorParam: "cursor",
+			})
+	}
+
+	return Catalog{
+		Version:  CatalogVersion,
+		Auth:     Auth{Type: "bearer", Header: "Authorization", Scheme: "Bearer"},
+		Triggers: triggers,
+		Actions:  actions,
+	}
+}
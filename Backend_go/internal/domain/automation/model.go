@@ -0,0 +1,142 @@
+package automation
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Entity is a kind of record that polling triggers report on
+type Entity string
+
+const (
+	EntityTask    Entity = "task"
+	EntityTodo    Entity = "todo"
+	EntityEvent   Entity = "event"
+	EntityHabit   Entity = "habit"
+	EntityProject Entity = "project"
+)
+
+// Entities lists every entity with polling triggers, in catalog order
+var Entities = []Entity{EntityTask, EntityTodo, EntityEvent, EntityHabit, EntityProject}
+
+// TriggerKind selects whether a trigger reports new records or every change
+type TriggerKind string
+
+const (
+	TriggerCreated TriggerKind = "created"
+	TriggerUpdated TriggerKind = "updated"
+)
+
+const (
+	// CatalogVersion changes whenever triggers or actions change incompatibly
+	CatalogVersion = "1"
+	// DefaultLimit and MaxLimit bound how many records one poll returns
+	DefaultLimit = 50
+	MaxLimit     = 100
+)
+
+var (
+	ErrUnknownTrigger = errors.New("unknown trigger")
+	ErrInvalidCursor  = pagination.ErrInvalidCursor
+	ErrInvalidLimit   = errors.New("limit must be between 1 and 100")
+)
+
+// Catalog describes the triggers and actions automation platforms can use
+type Catalog struct {
+	Version  string    `json:"version"`
+	Auth     Auth      `json:"auth"`
+	Triggers []Trigger `json:"triggers"`
+	Actions  []Action  `json:"actions"`
+}
+
+// Auth tells a platform how to authenticate its requests
+type Auth struct {
+	Type   string `json:"type"`
+	Header string `json:"header"`
+	Scheme string `json:"scheme"`
+}
+
+// Trigger is a polling endpoint reporting records created or updated after a cursor
+type Trigger struct {
+	Key         string `json:"key"`
+	Noun        string `json:"noun"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	// DedupeKey lists the fields that together identify one emitted record
+	DedupeKey []string `json:"dedupe_key"`
+	// CursorParam is the query parameter that carries next_cursor from the previous poll
+	CursorParam string `json:"cursor_param"`
+}
+
+// Action is an existing API endpoint that changes data
+type Action struct {
+	Key         string  `json:"key"`
+	Noun        string  `json:"noun"`
+	Label       string  `json:"label"`
+	Description string  `json:"description"`
+	Method      string  `json:"method"`
+	Path        string  `json:"path"`
+	Fields      []Field `json:"fields"`
+}
+
+// Field is an input of an action. Fields with In "path" fill the matching {placeholder} in Path.
+type Field struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	In       string   `json:"in"`
+	Choices  []string `json:"choices,omitempty"`
+}
+
+// PollQuery asks for the records of a trigger after a cursor, limited to what the user may see
+type PollQuery struct {
+	Entity         Entity
+	Kind           TriggerKind
+	Cursor         *Cursor
+	Limit          int
+	UserID         uuid.UUID
+	OrganizationID *uuid.UUID
+	// Access is what the user's role lets them see in the organization
+	Access search.Access
+}
+
+// PollResult is one page of records and the cursor to continue from
+type PollResult struct {
+	Items      []datatypes.JSON `json:"items"`
+	NextCursor string           `json:"next_cursor"`
+	HasMore    bool             `json:"has_more"`
+}
+
+// Cursor is a position in a trigger's (timestamp, id) order
+type Cursor = pagination.Cursor
+
+// TriggerKey returns the catalog key of a trigger, such as "task.updated"
+func TriggerKey(entity Entity, kind TriggerKind) string {
+	return string(entity) + "." + string(kind)
+}
+
+// ParseTriggerKey splits a trigger key into its entity and kind
+func ParseTriggerKey(key string) (Entity, TriggerKind, error) {
+	parts := strings.SplitN(key, ".", 2)
+	if len(parts) != 2 {
+		return "", "", ErrUnknownTrigger
+	}
+	kind := TriggerKind(parts[1])
+	if kind != TriggerCreated && kind != TriggerUpdated {
+		return "", "", ErrUnknownTrigger
+	}
+	for _, e := range Entities {
+		if string(e) == parts[0] {
+			return e, kind, nil
+		}
+	}
+	return "", "", ErrUnknownTrigger
+}
@@ -0,0 +1,99 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// entityTables maps each entity to its table
+var entityTables = map[Entity]string{
+	EntityTask:    "tasks",
+	EntityTodo:    "todos",
+	EntityEvent:   "calendar_events",
+	EntityHabit:   "habits",
+	EntityProject: "projects",
+}
+
+// entityResults maps each entity to its search result type, whose visibility rules it shares
+var entityResults = map[Entity]search.ResultType{
+	EntityTask:    search.ResultTask,
+	EntityTodo:    search.ResultTodo,
+	EntityEvent:   search.ResultEvent,
+	EntityHabit:   search.ResultHabit,
+	EntityProject: search.ResultProject,
+}
+
+// hiddenColumns are left out of polled records. Rows in the trash are never polled, so
+// deleted_at carries nothing.
+var hiddenColumns = []string{"search_vector", "deleted_at"}
+
+// Repository defines the interface for trigger polling data access
+type Repository interface {
+	Poll(ctx context.Context, query PollQuery) ([]Row, error)
+}
+
+// Row is one polled record with the position it sorts at
+type Row struct {
+	Item     datatypes.JSON
+	ID       uuid.UUID
+	SortTime time.Time
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new trigger polling repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// Poll returns up to query.Limit records after the cursor, ordered by the trigger's timestamp
+// column and then by id, so that records sharing a timestamp are neither skipped nor repeated
+func (r *repository) Poll(ctx context.Context, query PollQuery) ([]Row, error) {
+	table, ok := entityTables[query.Entity]
+	if !ok {
+		return nil, ErrUnknownTrigger
+	}
+	column := "updated_at"
+	if query.Kind == TriggerCreated {
+		column = "created_at"
+	}
+
+	item := "to_jsonb(t)"
+	for _, c := range hiddenColumns {
+		item += " - '" + c + "'"
+	}
+	scope, args := scopeFor(query)
+
+	sql := fmt.Sprintf("SELECT %s AS item, t.id, t.%s AS sort_time FROM %s t WHERE (%s)", item, column, table, scope)
+	if query.Cursor != nil {
+		sql += fmt.Sprintf(" AND (t.%s, t.id) > (?, ?)", column)
+		args = append(args, query.Cursor.Time, query.Cursor.ID)
+	}
+	sql += fmt.Sprintf(" ORDER BY t.%s ASC, t.id ASC LIMIT ?", column)
+	args = append(args, query.Limit)
+
+	var rows []Row
+	if err := r.db.WithContext(ctx).Raw(sql, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// scopeFor restricts a table to the rows the user could find by searching with the same
+// access, rows in the trash left out
+func scopeFor(query PollQuery) (string, []interface{}) {
+	return search.VisibleScope(entityResults[query.Entity], search.Query{
+		UserID:         query.UserID,
+		OrganizationID: query.OrganizationID,
+		Access:         query.Access,
+	})
+}
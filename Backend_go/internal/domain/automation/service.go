@@ -0,0 +1,86 @@
+package automation
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Service defines the interface for the automation catalog and polling triggers
+type Service interface {
+	Catalog() Catalog
+	// Poll sees the same records in the organization as a search with the access would find
+	Poll(ctx context.Context, triggerKey, cursor string, limit int, userID uuid.UUID, orgID *uuid.UUID, access search.Access) (*PollResult, error)
+}
+
+type service struct {
+	repo    Repository
+	catalog Catalog
+}
+
+// NewService creates a new automation service
+func NewService(repo Repository) Service {
+	return &service{repo: repo, catalog: BuildCatalog()}
+}
+
+// Catalog returns the triggers and actions automation platforms can use
+func (s *service) Catalog() Catalog {
+	return s.catalog
+}
+
+// Poll returns the records of a trigger after cursor. An empty cursor starts from the
+// beginning; a poll that returns no records hands back the same cursor.
+func (s *service) Poll(ctx context.Context, triggerKey, cursor string, limit int, userID uuid.UUID, orgID *uuid.UUID, access search.Access) (*PollResult, error) {
+	entity, kind, err := ParseTriggerKey(triggerKey)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 1 || limit > MaxLimit {
+		return nil, ErrInvalidLimit
+	}
+
+	query := PollQuery{
+		Entity:         entity,
+		Kind:           kind,
+		Limit:          limit + 1,
+		UserID:         userID,
+		OrganizationID: orgID,
+		Access:         access,
+	}
+	if cursor != "" {
+		if query.Cursor, err = pagination.ParseCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.repo.Poll(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PollResult{NextCursor: cursor, HasMore: len(rows) > limit}
+	if result.HasMore {
+		rows = rows[:limit]
+	}
+	result.Items = make([]datatypes.JSON, 0, len(rows))
+	for _, row := range rows {
+		result.Items = append(result.Items, row.Item)
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		result.NextCursor = Cursor{Time: last.SortTime, ID: last.ID}.Encode()
+	}
+	return result, nil
+}
+
+// StartCursor returns a cursor positioned at t, for platforms that only want records changed from now on
+func StartCursor(t time.Time) string {
+	return Cursor{Time: t}.Encode()
+}
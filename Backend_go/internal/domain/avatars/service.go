@@ -0,0 +1,188 @@
+package avatars
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the GIF decoder
+	_ "image/jpeg" // register the JPEG decoder
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/storage"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxUploadSize is the largest image accepted
+	MaxUploadSize = 5 << 20
+	// maxPixels guards against small files that decode into huge images
+	maxPixels = 25_000_000
+	// URLPrefix is where avatars are served from; AvatarURL points below it
+	URLPrefix = "/api/avatars/"
+)
+
+// Sizes are the widths, in pixels, of the square images every avatar is stored in
+var Sizes = []int{32, 64, 128, 256}
+
+var (
+	ErrEmptyFile    = errors.New("file is empty")
+	ErrFileTooLarge = errors.New("avatar exceeds the 5 MB upload limit")
+	ErrNotAnImage   = errors.New("avatar must be a PNG, JPEG or GIF image")
+	ErrImageTooBig  = errors.New("avatar dimensions are too large")
+	ErrNotFound     = errors.New("avatar not found")
+)
+
+// fileName matches the stored files of an avatar: its version and size
+var fileName = regexp.MustCompile(`^([0-9a-z]+)-([0-9]+)\.png$`)
+
+// Avatar is an uploaded avatar, with the URL of each of its sizes
+type Avatar struct {
+	// URL is the largest size, stored as the user's AvatarURL
+	URL   string         `json:"avatar_url"`
+	Sizes map[int]string `json:"sizes"`
+}
+
+// UserStore reads and updates the users avatars belong to
+type UserStore interface {
+	GetUser(ctx context.Context, id uuid.UUID) (*user.User, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, input user.UpdateUserInput) (*user.User, error)
+}
+
+// Service resizes uploaded avatars, keeps them in the store and points the user's
+// AvatarURL at them
+type Service interface {
+	// Upload replaces the user's avatar with the image read from content
+	Upload(ctx context.Context, userID uuid.UUID, content io.Reader, size int64) (*Avatar, error)
+	// Open reads one of the stored files of a user's avatar, named as in its URL
+	Open(ctx context.Context, userID uuid.UUID, name string) (io.ReadCloser, error)
+}
+
+type service struct {
+	store  storage.Store
+	users  UserStore
+	logger *zap.Logger
+}
+
+// NewService creates a new avatar service
+func NewService(store storage.Store, users UserStore, logger *zap.Logger) Service {
+	return &service{store: store, users: users, logger: logger}
+}
+
+func objectKey(userID uuid.UUID, name string) string {
+	return fmt.Sprintf("avatars/%s/%s", userID, name)
+}
+
+func objectName(version string, size int) string {
+	return fmt.Sprintf("%s-%d.png", version, size)
+}
+
+// Upload decodes the image, stores a square PNG of every size under a new version and
+// then removes the files of the previous avatar
+func (s *service) Upload(ctx context.Context, userID uuid.UUID, content io.Reader, size int64) (*Avatar, error) {
+	if size <= 0 {
+		return nil, ErrEmptyFile
+	}
+	if size > MaxUploadSize {
+		return nil, ErrFileTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(content, MaxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxUploadSize {
+		return nil, ErrFileTooLarge
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotAnImage
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrImageTooBig
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotAnImage
+	}
+
+	previous, err := s.users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new version per upload gives every avatar its own URLs, so they can be cached forever
+	version := strconv.FormatInt(time.Now().UnixNano(), 36)
+	avatar := &Avatar{Sizes: make(map[int]string, len(Sizes))}
+	var stored []string
+	for _, px := range Sizes {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, resizeSquare(img, px)); err != nil {
+			s.remove(ctx, stored)
+			return nil, err
+		}
+		name := objectName(version, px)
+		if err := s.store.Put(ctx, objectKey(userID, name), &buf, int64(buf.Len()), "image/png"); err != nil {
+			s.remove(ctx, stored)
+			return nil, fmt.Errorf("failed to store avatar: %w", err)
+		}
+		stored = append(stored, objectKey(userID, name))
+		avatar.Sizes[px] = URLPrefix + userID.String() + "/" + name
+		avatar.URL = avatar.Sizes[px]
+	}
+
+	if _, err := s.users.UpdateUser(ctx, userID, user.UpdateUserInput{AvatarURL: &avatar.URL}); err != nil {
+		s.remove(ctx, stored)
+		return nil, err
+	}
+	s.remove(ctx, previousKeys(userID, previous.AvatarURL))
+	return avatar, nil
+}
+
+func (s *service) Open(ctx context.Context, userID uuid.UUID, name string) (io.ReadCloser, error) {
+	if !fileName.MatchString(name) {
+		return nil, ErrNotFound
+	}
+	content, err := s.store.Open(ctx, objectKey(userID, name))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return content, err
+}
+
+// previousKeys returns the stored files of an avatar URL this service issued, and none
+// for avatars hosted elsewhere
+func previousKeys(userID uuid.UUID, avatarURL string) []string {
+	name, ok := strings.CutPrefix(avatarURL, URLPrefix+userID.String()+"/")
+	if !ok {
+		return nil
+	}
+	match := fileName.FindStringSubmatch(name)
+	if match == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(Sizes))
+	for _, px := range Sizes {
+		keys = append(keys, objectKey(userID, objectName(match[1], px)))
+	}
+	return keys
+}
+
+// remove deletes stored files. Failures only leave orphaned files behind, so they are
+// logged rather than returned.
+func (s *service) remove(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			tracing.Logger(ctx, s.logger).Warn("Failed to delete avatar file", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
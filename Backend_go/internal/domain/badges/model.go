@@ -0,0 +1,43 @@
+package badges
+
+import "time"
+
+// Counts are the numbers shown on a user's navigation badges
+type Counts struct {
+	// OverdueTasks are the open tasks past their due date the user is responsible for:
+	// assigned to them, or created by them and unassigned
+	OverdueTasks int64 `json:"overdue_tasks"`
+	// UnreadNotifications are the user's unread in-app notifications
+	UnreadNotifications int64 `json:"unread_notifications"`
+	// PendingApprovals are the approval steps waiting on the user or one of their roles
+	PendingApprovals int64 `json:"pending_approvals"`
+	// TodosDueToday are the open todos due today in the user's timezone
+	TodosDueToday int64 `json:"todos_due_today"`
+}
+
+// Sub returns the change from previous to c
+func (c Counts) Sub(previous Counts) Counts {
+	return Counts{
+		OverdueTasks:        c.OverdueTasks - previous.OverdueTasks,
+		UnreadNotifications: c.UnreadNotifications - previous.UnreadNotifications,
+		PendingApprovals:    c.PendingApprovals - previous.PendingApprovals,
+		TodosDueToday:       c.TodosDueToday - previous.TodosDueToday,
+	}
+}
+
+// IsZero reports whether all counts are zero, which for a delta means nothing changed
+func (c Counts) IsZero() bool {
+	return c == Counts{}
+}
+
+// Snapshot is the counts of a user as last computed
+type Snapshot struct {
+	Counts
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// Change is the payload of webhooks.EventBadgeCountsChanged
+type Change struct {
+	Counts Counts `json:"counts"`
+	Delta  Counts `json:"delta"`
+}
@@ -0,0 +1,57 @@
+package badges
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// countsQuery computes every badge in one round trip. Each subquery is served by an
+// index on its owner column.
+const countsQuery = `
+SELECT
+	(SELECT COUNT(*) FROM tasks
+		WHERE COALESCE(assignee_id, creator_id) = @user AND deleted_at IS NULL
+		AND due_date < @now AND status NOT IN ('Completed', 'Cancelled')) AS overdue_tasks,
+	(SELECT COUNT(*) FROM notifications
+		WHERE user_id = @user AND status = 'UNREAD') AS unread_notifications,
+	(SELECT COUNT(*) FROM workflow_step_executions se
+		JOIN workflow_steps ws ON ws.id = se.step_id
+		WHERE se.status = 'pending' AND ws.step_type = 'approval'
+		AND (ws.assigned_to = @user OR (ws.assigned_to IS NULL
+			AND ws.assigned_to_role_id IN (SELECT role_id FROM user_roles WHERE user_id = @user)))) AS pending_approvals,
+	(SELECT COUNT(*) FROM todos
+		WHERE user_id = @user AND deleted_at IS NULL AND is_completed = false
+		AND due_date >= @day_start AND due_date < @day_end) AS todos_due_today`
+
+// Repository defines the interface for badge count queries
+type Repository interface {
+	// Count computes the counts of a user at now, with today starting at dayStart
+	Count(ctx context.Context, userID uuid.UUID, now, dayStart time.Time) (*Counts, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new badge count repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Count(ctx context.Context, userID uuid.UUID, now, dayStart time.Time) (*Counts, error) {
+	var counts Counts
+	err := r.db.WithContext(ctx).Raw(countsQuery, map[string]interface{}{
+		"user":      userID,
+		"now":       now,
+		"day_start": dayStart,
+		"day_end":   dayStart.AddDate(0, 0, 1),
+	}).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
@@ -0,0 +1,229 @@
+package badges
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// freshFor is how long computed counts are served from the cache
+	freshFor = 30 * time.Second
+	// watchFor is how long after fetching their counts a user gets changes pushed.
+	// Snapshots are kept that long so there is something to diff against.
+	watchFor = 15 * time.Minute
+	// refreshInterval batches the refreshes queued by a burst of changes
+	refreshInterval = time.Second
+	// refreshTimeout bounds recomputing the counts of one user
+	refreshTimeout = 5 * time.Second
+)
+
+// UserSource resolves the timezone "today" is counted in
+type UserSource interface {
+	GetUser(ctx context.Context, id uuid.UUID) (*user.User, error)
+}
+
+// Service computes the badge counts of users and pushes their changes as realtime
+// deltas. It implements webhooks.Publisher to hear about task, todo and workflow
+// changes, and observes new notifications through Watch.
+type Service interface {
+	// Get returns the user's counts, from the cache while they are fresh
+	Get(ctx context.Context, userID uuid.UUID) (*Snapshot, error)
+	// Refresh queues recomputing the counts of users who fetched them recently. Those
+	// whose counts changed are sent webhooks.EventBadgeCountsChanged.
+	Refresh(userIDs ...uuid.UUID)
+	Publish(ctx context.Context, event webhooks.Event)
+	// Watch refreshes the recipient's counts whenever a notification is published
+	Watch(signals notification.SignalRepository)
+	Start()
+	Stop()
+}
+
+type service struct {
+	repo     Repository
+	users    UserSource
+	redis    *cache.RedisClient
+	realtime webhooks.Publisher
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewService creates a new badge count service. Changes are pushed through realtime.
+func NewService(repo Repository, users UserSource, redisClient *cache.RedisClient, realtime webhooks.Publisher, logger *zap.Logger) Service {
+	return &service{
+		repo:     repo,
+		users:    users,
+		redis:    redisClient,
+		realtime: realtime,
+		logger:   logger,
+		now:      time.Now,
+		pending:  make(map[uuid.UUID]struct{}),
+	}
+}
+
+func snapshotKey(userID uuid.UUID) string {
+	return "badges:counts:" + userID.String()
+}
+
+func (s *service) Get(ctx context.Context, userID uuid.UUID) (*Snapshot, error) {
+	if snapshot, ok := s.cached(ctx, userID); ok && s.now().Sub(snapshot.ComputedAt) < freshFor {
+		return snapshot, nil
+	}
+	return s.compute(ctx, userID)
+}
+
+// compute counts from the database and stores the snapshot
+func (s *service) compute(ctx context.Context, userID uuid.UUID) (*Snapshot, error) {
+	loc := time.UTC
+	if u, err := s.users.GetUser(ctx, userID); err == nil {
+		if userLoc, err := user.ParseTimezone(u.Timezone); err == nil {
+			loc = userLoc
+		}
+	} else if !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+
+	now := s.now().In(loc)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	counts, err := s.repo.Count(ctx, userID, now, dayStart)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{Counts: *counts, ComputedAt: s.now().UTC()}
+	if data, err := json.Marshal(snapshot); err == nil {
+		if err := s.redis.Set(ctx, snapshotKey(userID), string(data), watchFor); err != nil {
+			tracing.Logger(ctx, s.logger).Warn("Failed to cache badge counts", zap.String("user_id", userID.String()), zap.Error(err))
+		}
+	}
+	return snapshot, nil
+}
+
+func (s *service) cached(ctx context.Context, userID uuid.UUID) (*Snapshot, bool) {
+	data, err := s.redis.Get(ctx, snapshotKey(userID))
+	if err != nil {
+		return nil, false
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return nil, false
+	}
+	return &snapshot, true
+}
+
+func (s *service) Refresh(userIDs ...uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range userIDs {
+		if id != uuid.Nil {
+			s.pending[id] = struct{}{}
+		}
+	}
+}
+
+// Publish refreshes the users a domain event may have changed counts for: the user of
+// the event, the actor of the request and, for tasks, whoever the task is on
+func (s *service) Publish(ctx context.Context, event webhooks.Event) {
+	users := []uuid.UUID{event.UserID}
+	if actorID, ok := audit.ActorFrom(ctx); ok {
+		users = append(users, actorID)
+	}
+	if data, ok := event.Data.(map[string]interface{}); ok {
+		if t, ok := data["task"].(*task.Task); ok {
+			users = append(users, t.DashboardUserID())
+		}
+	}
+	s.Refresh(users...)
+}
+
+func (s *service) Watch(signals notification.SignalRepository) {
+	signals.Observe(func(n *notification.Notification) {
+		s.Refresh(n.UserID)
+	})
+}
+
+// Start refreshes the queued users every refreshInterval
+func (s *service) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.refreshPending(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *service) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+func (s *service) refreshPending(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[uuid.UUID]struct{})
+	s.mu.Unlock()
+
+	for userID := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		s.refresh(ctx, userID)
+	}
+}
+
+// refresh recomputes the counts of a watched user and pushes the delta when they changed.
+// Users without a snapshot are skipped: nobody is looking, and their next fetch is fresh.
+func (s *service) refresh(ctx context.Context, userID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	previous, ok := s.cached(ctx, userID)
+	if !ok {
+		return
+	}
+	current, err := s.compute(ctx, userID)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Warn("Failed to refresh badge counts", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+	delta := current.Counts.Sub(previous.Counts)
+	if delta.IsZero() {
+		return
+	}
+	s.realtime.Publish(ctx, webhooks.Event{
+		Type:   webhooks.EventBadgeCountsChanged,
+		UserID: userID,
+		Data:   Change{Counts: current.Counts, Delta: delta},
+	})
+}
@@ -0,0 +1,110 @@
+package baselines
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxBaselinesPerProject caps the snapshots a project keeps
+	MaxBaselinesPerProject = 20
+	// MaxNameLength caps the name of a baseline
+	MaxNameLength = 100
+)
+
+var (
+	ErrBaselineNotFound  = errors.New("baseline not found")
+	ErrInvalidBaseline   = errors.New("a baseline needs a name of at most 100 characters")
+	ErrTooManyBaselines  = errors.New("project has too many baselines")
+	ErrNothingToBaseline = errors.New("project has no scheduled tasks to baseline")
+)
+
+// Baseline is a snapshot of a project schedule, kept to measure later changes against
+type Baseline struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	ProjectID      uuid.UUID `json:"project_id" gorm:"type:uuid;not null;index"`
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;index"`
+	Name           string    `json:"name" gorm:"size:100;not null"`
+	Description    string    `json:"description,omitempty" gorm:"type:text"`
+	CreatedBy      uuid.UUID `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+
+	// PlannedStart and PlannedEnd bound the schedule when the snapshot was taken
+	PlannedStart time.Time `json:"planned_start"`
+	PlannedEnd   time.Time `json:"planned_end"`
+	TaskCount    int       `json:"task_count" gorm:"not null;default:0"`
+
+	Tasks []BaselineTask `json:"tasks,omitempty" gorm:"foreignKey:BaselineID;constraint:OnDelete:CASCADE"`
+}
+
+func (Baseline) TableName() string {
+	return "project_baselines"
+}
+
+// BaselineTask holds the dates of one task when its baseline was taken
+type BaselineTask struct {
+	ID         uuid.UUID  `json:"-" gorm:"type:uuid;primaryKey"`
+	BaselineID uuid.UUID  `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_baseline_task"`
+	TaskID     uuid.UUID  `json:"task_id" gorm:"type:uuid;not null;uniqueIndex:idx_baseline_task"`
+	Title      string     `json:"title" gorm:"size:255;not null"`
+	StartDate  time.Time  `json:"start_date" gorm:"not null"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+}
+
+func (BaselineTask) TableName() string {
+	return "project_baseline_tasks"
+}
+
+// end is the last date of the task, its due date or else its start date
+func (t BaselineTask) end() time.Time {
+	if t.DueDate != nil {
+		return *t.DueDate
+	}
+	return t.StartDate
+}
+
+// CreateBaselineInput names a new snapshot
+type CreateBaselineInput struct {
+	Name        string
+	Description string
+}
+
+// TaskSlippage compares the dates of a task with those in a baseline. Slips are in
+// days; positive values mean the task moved later.
+type TaskSlippage struct {
+	TaskID        uuid.UUID  `json:"task_id"`
+	Title         string     `json:"title"`
+	Status        string     `json:"status"`
+	BaselineStart time.Time  `json:"baseline_start"`
+	CurrentStart  time.Time  `json:"current_start"`
+	BaselineDue   *time.Time `json:"baseline_due,omitempty"`
+	CurrentDue    *time.Time `json:"current_due,omitempty"`
+	StartSlipDays float64    `json:"start_slip_days"`
+	// DueSlipDays is missing when the task has no due date in the baseline or now
+	DueSlipDays *float64 `json:"due_slip_days,omitempty"`
+}
+
+// Comparison is the difference between a baseline and the current project schedule
+type Comparison struct {
+	BaselineID   uuid.UUID `json:"baseline_id"`
+	BaselineName string    `json:"baseline_name"`
+	CapturedAt   time.Time `json:"captured_at"`
+	ComparedAt   time.Time `json:"compared_at"`
+
+	PlannedEnd time.Time `json:"planned_end"`
+	CurrentEnd time.Time `json:"current_end"`
+	// DriftDays is how far the end of the project moved since the baseline
+	DriftDays float64 `json:"drift_days"`
+	// AverageSlipDays is the mean end slip of the tasks in both schedules
+	AverageSlipDays float64 `json:"average_slip_days"`
+	SlippedCount    int     `json:"slipped_count"`
+	AheadCount      int     `json:"ahead_count"`
+
+	Tasks []TaskSlippage `json:"tasks"`
+	// Added are tasks scheduled since the baseline; Removed are baseline tasks that were
+	// deleted or cancelled since
+	Added   []uuid.UUID `json:"added"`
+	Removed []uuid.UUID `json:"removed"`
+}
@@ -0,0 +1,78 @@
+package baselines
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for baseline data access
+type Repository interface {
+	// Create stores the baseline together with its tasks
+	Create(ctx context.Context, baseline *Baseline) error
+	// FindByID retrieves a baseline of the project with its tasks
+	FindByID(ctx context.Context, projectID, id uuid.UUID) (*Baseline, error)
+	// ListByProject lists the baselines of a project, newest first, without their tasks
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]Baseline, error)
+	CountByProject(ctx context.Context, projectID uuid.UUID) (int64, error)
+	Delete(ctx context.Context, projectID, id uuid.UUID) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new baseline repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Create(ctx context.Context, baseline *Baseline) error {
+	return r.db.WithContext(ctx).Create(baseline).Error
+}
+
+func (r *repository) FindByID(ctx context.Context, projectID, id uuid.UUID) (*Baseline, error) {
+	var baseline Baseline
+	err := r.db.WithContext(ctx).
+		Preload("Tasks", func(db *gorm.DB) *gorm.DB { return db.Order("start_date ASC") }).
+		First(&baseline, "id = ? AND project_id = ?", id, projectID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBaselineNotFound
+		}
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+func (r *repository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]Baseline, error) {
+	var baselines []Baseline
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("created_at DESC").
+		Find(&baselines).Error
+	return baselines, err
+}
+
+func (r *repository) CountByProject(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Baseline{}).Where("project_id = ?", projectID).Count(&count).Error
+	return count, err
+}
+
+// Delete removes the baseline and its tasks
+func (r *repository) Delete(ctx context.Context, projectID, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND project_id = ?", id, projectID).Delete(&Baseline{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrBaselineNotFound
+		}
+		return tx.Where("baseline_id = ?", id).Delete(&BaselineTask{}).Error
+	})
+}
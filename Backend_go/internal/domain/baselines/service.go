@@ -0,0 +1,229 @@
+package baselines
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/google/uuid"
+)
+
+// Service defines the interface for project baseline operations. Callers check that
+// the project belongs to the organization before calling it.
+type Service interface {
+	CreateBaseline(ctx context.Context, projectID, organizationID, userID uuid.UUID, input CreateBaselineInput) (*Baseline, error)
+	ListBaselines(ctx context.Context, projectID uuid.UUID) ([]Baseline, error)
+	GetBaseline(ctx context.Context, projectID, id uuid.UUID) (*Baseline, error)
+	DeleteBaseline(ctx context.Context, projectID, id uuid.UUID) error
+	// CompareBaseline measures how the current schedule of the project slipped from a baseline
+	CompareBaseline(ctx context.Context, projectID, organizationID, id uuid.UUID) (*Comparison, error)
+}
+
+type service struct {
+	repo        Repository
+	taskService task.Service
+}
+
+// NewService creates a new baseline service
+func NewService(repo Repository, taskService task.Service) Service {
+	return &service{
+		repo:        repo,
+		taskService: taskService,
+	}
+}
+
+// scheduledTasks returns the tasks of the project that are still planned to happen
+func (s *service) scheduledTasks(ctx context.Context, projectID, organizationID uuid.UUID) ([]task.Task, error) {
+	tasks, _, err := s.taskService.GetProjectTasks(ctx, projectID, task.TaskFilter{OrganizationID: &organizationID})
+	if err != nil {
+		return nil, err
+	}
+	scheduled := tasks[:0]
+	for _, t := range tasks {
+		if t.Status != task.TaskStatusCancelled {
+			scheduled = append(scheduled, t)
+		}
+	}
+	return scheduled, nil
+}
+
+// CreateBaseline snapshots the dates of every scheduled task of the project
+func (s *service) CreateBaseline(ctx context.Context, projectID, organizationID, userID uuid.UUID, input CreateBaselineInput) (*Baseline, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > MaxNameLength {
+		return nil, ErrInvalidBaseline
+	}
+
+	count, err := s.repo.CountByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxBaselinesPerProject {
+		return nil, ErrTooManyBaselines
+	}
+
+	tasks, err := s.scheduledTasks(ctx, projectID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, ErrNothingToBaseline
+	}
+
+	baseline := &Baseline{
+		ID:             uuid.New(),
+		ProjectID:      projectID,
+		OrganizationID: organizationID,
+		Name:           name,
+		Description:    input.Description,
+		CreatedBy:      userID,
+		CreatedAt:      time.Now(),
+		TaskCount:      len(tasks),
+		Tasks:          make([]BaselineTask, 0, len(tasks)),
+	}
+	for i, t := range tasks {
+		entry := BaselineTask{
+			ID:         uuid.New(),
+			BaselineID: baseline.ID,
+			TaskID:     t.ID,
+			Title:      t.Title,
+			StartDate:  t.StartDate,
+			DueDate:    t.DueDate,
+		}
+		if i == 0 || entry.StartDate.Before(baseline.PlannedStart) {
+			baseline.PlannedStart = entry.StartDate
+		}
+		if i == 0 || entry.end().After(baseline.PlannedEnd) {
+			baseline.PlannedEnd = entry.end()
+		}
+		baseline.Tasks = append(baseline.Tasks, entry)
+	}
+
+	if err := s.repo.Create(ctx, baseline); err != nil {
+		return nil, err
+	}
+	return baseline, nil
+}
+
+func (s *service) ListBaselines(ctx context.Context, projectID uuid.UUID) ([]Baseline, error) {
+	return s.repo.ListByProject(ctx, projectID)
+}
+
+func (s *service) GetBaseline(ctx context.Context, projectID, id uuid.UUID) (*Baseline, error) {
+	return s.repo.FindByID(ctx, projectID, id)
+}
+
+func (s *service) DeleteBaseline(ctx context.Context, projectID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, projectID, id)
+}
+
+// CompareBaseline matches the tasks of the baseline with the current ones by ID. Project
+// drift compares the end of the baseline with the end of the current schedule, so tasks
+// added since count towards it.
+func (s *service) CompareBaseline(ctx context.Context, projectID, organizationID, id uuid.UUID) (*Comparison, error) {
+	baseline, err := s.repo.FindByID(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.scheduledTasks(ctx, projectID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &Comparison{
+		BaselineID:   baseline.ID,
+		BaselineName: baseline.Name,
+		CapturedAt:   baseline.CreatedAt,
+		ComparedAt:   time.Now(),
+		PlannedEnd:   baseline.PlannedEnd,
+		CurrentEnd:   baseline.PlannedEnd,
+		Tasks:        []TaskSlippage{},
+		Added:        []uuid.UUID{},
+		Removed:      []uuid.UUID{},
+	}
+
+	current := make(map[uuid.UUID]*task.Task, len(tasks))
+	for i := range tasks {
+		t := &tasks[i]
+		current[t.ID] = t
+		end := t.StartDate
+		if t.DueDate != nil {
+			end = *t.DueDate
+		}
+		if i == 0 || end.After(comparison.CurrentEnd) {
+			comparison.CurrentEnd = end
+		}
+	}
+
+	inBaseline := make(map[uuid.UUID]bool, len(baseline.Tasks))
+	var totalSlip float64
+	for _, entry := range baseline.Tasks {
+		inBaseline[entry.TaskID] = true
+		t, ok := current[entry.TaskID]
+		if !ok {
+			comparison.Removed = append(comparison.Removed, entry.TaskID)
+			continue
+		}
+
+		slippage := TaskSlippage{
+			TaskID:        t.ID,
+			Title:         t.Title,
+			Status:        string(t.Status),
+			BaselineStart: entry.StartDate,
+			CurrentStart:  t.StartDate,
+			BaselineDue:   entry.DueDate,
+			CurrentDue:    t.DueDate,
+			StartSlipDays: slipDays(entry.StartDate, t.StartDate),
+		}
+		endSlip := slippage.StartSlipDays
+		if entry.DueDate != nil && t.DueDate != nil {
+			dueSlip := slipDays(*entry.DueDate, *t.DueDate)
+			slippage.DueSlipDays = &dueSlip
+			endSlip = dueSlip
+		}
+		switch {
+		case endSlip > 0:
+			comparison.SlippedCount++
+		case endSlip < 0:
+			comparison.AheadCount++
+		}
+		totalSlip += endSlip
+		comparison.Tasks = append(comparison.Tasks, slippage)
+	}
+	for _, t := range tasks {
+		if !inBaseline[t.ID] {
+			comparison.Added = append(comparison.Added, t.ID)
+		}
+	}
+
+	comparison.DriftDays = slipDays(comparison.PlannedEnd, comparison.CurrentEnd)
+	if len(comparison.Tasks) > 0 {
+		comparison.AverageSlipDays = roundDays(totalSlip / float64(len(comparison.Tasks)))
+	}
+
+	// Worst slips first
+	sort.SliceStable(comparison.Tasks, func(i, j int) bool {
+		return endSlipOf(comparison.Tasks[i]) > endSlipOf(comparison.Tasks[j])
+	})
+	return comparison, nil
+}
+
+// endSlipOf is the due date slip of the task, or its start slip without due dates
+func endSlipOf(t TaskSlippage) float64 {
+	if t.DueSlipDays != nil {
+		return *t.DueSlipDays
+	}
+	return t.StartSlipDays
+}
+
+// slipDays is how many days to is after from, to two decimals
+func slipDays(from, to time.Time) float64 {
+	return roundDays(to.Sub(from).Hours() / 24)
+}
+
+func roundDays(days float64) float64 {
+	return math.Round(days*100) / 100
+}
@@ -0,0 +1,139 @@
+package billing
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlanName identifies a subscription plan
+type PlanName string
+
+const (
+	PlanFree     PlanName = "free"
+	PlanPro      PlanName = "pro"
+	PlanBusiness PlanName = "business"
+)
+
+// SubscriptionStatus mirrors the status of the subscription at the payment provider
+type SubscriptionStatus string
+
+const (
+	StatusActive     SubscriptionStatus = "active"
+	StatusTrialing   SubscriptionStatus = "trialing"
+	StatusPastDue    SubscriptionStatus = "past_due"
+	StatusIncomplete SubscriptionStatus = "incomplete"
+	StatusUnpaid     SubscriptionStatus = "unpaid"
+	StatusCanceled   SubscriptionStatus = "canceled"
+)
+
+// GrantsPlan reports whether a subscription in this status gets its plan's features.
+// Past-due subscriptions keep their plan while the provider retries the payment.
+func (s SubscriptionStatus) GrantsPlan() bool {
+	switch s {
+	case StatusActive, StatusTrialing, StatusPastDue:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	ErrBillingDisabled      = errors.New("billing is not enabled")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrInvalidSignature     = errors.New("invalid webhook signature")
+	ErrInvalidPayload       = errors.New("invalid webhook payload")
+	ErrMissingWebhookSecret = errors.New("a Stripe webhook secret is required with a Stripe secret key")
+	ErrEventProcessed       = errors.New("webhook event already processed")
+	ErrFeatureNotInPlan     = errors.New("feature is not included in the organization's plan")
+	ErrQuotaExceeded        = errors.New("plan limit reached")
+)
+
+// Subscription is an organization's billing state. Organizations without a row are on the free plan.
+type Subscription struct {
+	ID             uuid.UUID          `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID          `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Plan           PlanName           `json:"plan" gorm:"type:varchar(50);not null;default:'free'"`
+	Status         SubscriptionStatus `json:"status" gorm:"type:varchar(30);not null;default:'active'"`
+	// CustomerID and SubscriptionID are the payment provider's identifiers
+	CustomerID        string     `json:"-" gorm:"type:varchar(255);index"`
+	SubscriptionID    string     `json:"-" gorm:"type:varchar(255);index"`
+	CurrentPeriodEnd  *time.Time `json:"current_period_end,omitempty"`
+	CancelAtPeriodEnd bool       `json:"cancel_at_period_end" gorm:"not null;default:false"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+func (Subscription) TableName() string {
+	return "billing_subscriptions"
+}
+
+// EffectivePlan is the plan whose features and limits apply now
+func (s *Subscription) EffectivePlan() PlanName {
+	if s == nil || !s.Status.GrantsPlan() {
+		return PlanFree
+	}
+	return s.Plan
+}
+
+// ProcessedEvent records a webhook event so redelivered events are applied once
+type ProcessedEvent struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	EventID     string    `json:"event_id" gorm:"type:varchar(255);not null;uniqueIndex"`
+	Type        string    `json:"type" gorm:"type:varchar(100);not null"`
+	ProcessedAt time.Time `json:"processed_at" gorm:"not null;default:current_timestamp"`
+}
+
+func (ProcessedEvent) TableName() string {
+	return "billing_events"
+}
+
+// QuotaUsage is how much of a plan limit an organization uses. Limit is nil when unlimited.
+// ResetsAt is set for monthly quotas.
+type QuotaUsage struct {
+	Quota    Quota      `json:"quota"`
+	Used     int64      `json:"used"`
+	Limit    *int64     `json:"limit"`
+	ResetsAt *time.Time `json:"resets_at,omitempty"`
+}
+
+// Summary is an organization's subscription with the plan that applies and its usage
+type Summary struct {
+	OrganizationID    uuid.UUID          `json:"organization_id"`
+	Plan              Plan               `json:"plan"`
+	Status            SubscriptionStatus `json:"status"`
+	CurrentPeriodEnd  *time.Time         `json:"current_period_end,omitempty"`
+	CancelAtPeriodEnd bool               `json:"cancel_at_period_end"`
+	HasBillingAccount bool               `json:"has_billing_account"`
+	Usage             []QuotaUsage       `json:"usage"`
+}
+
+// SubscriptionUpdate is a subscription change reported by the payment provider
+type SubscriptionUpdate struct {
+	CustomerID     string
+	SubscriptionID string
+	// OrganizationID comes from the subscription's metadata, when set
+	OrganizationID    uuid.UUID
+	PriceID           string
+	Status            SubscriptionStatus
+	CurrentPeriodEnd  *time.Time
+	CancelAtPeriodEnd bool
+	Deleted           bool
+}
+
+// CheckoutCompleted links a provider customer to an organization after checkout
+type CheckoutCompleted struct {
+	CustomerID     string
+	SubscriptionID string
+	OrganizationID uuid.UUID
+}
+
+// WebhookEvent is a verified payment provider event. At most one of the payloads is set;
+// events that do not affect billing state carry neither.
+type WebhookEvent struct {
+	ID           string
+	Type         string
+	Subscription *SubscriptionUpdate
+	Checkout     *CheckoutCompleted
+}
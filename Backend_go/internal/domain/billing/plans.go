@@ -0,0 +1,106 @@
+package billing
+
+import "github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+
+// Feature is a capability that only some plans include
+type Feature string
+
+const (
+	// FeatureIntegrations covers code platform and chat integrations
+	FeatureIntegrations Feature = "integrations"
+	// FeatureAutomation covers automation triggers polled by external services
+	FeatureAutomation Feature = "automation"
+)
+
+// Quota is a countable resource limited per organization
+type Quota string
+
+const (
+	QuotaProjects  Quota = "projects"
+	QuotaWorkflows Quota = "workflows"
+	QuotaMembers   Quota = "members"
+	// Metered quotas limit usage per calendar month (UTC)
+	QuotaWorkflowExecutions Quota = Quota(metering.MeterWorkflowExecutions)
+	QuotaAISuggestions      Quota = Quota(metering.MeterAISuggestions)
+)
+
+// Quotas lists every quota, in display order
+var Quotas = []Quota{QuotaProjects, QuotaWorkflows, QuotaMembers, QuotaWorkflowExecutions, QuotaAISuggestions}
+
+// Meter returns the meter behind a monthly quota, and false for quotas that count stored resources
+func (q Quota) Meter() (metering.Meter, bool) {
+	switch q {
+	case QuotaWorkflowExecutions, QuotaAISuggestions:
+		return metering.Meter(q), true
+	default:
+		return "", false
+	}
+}
+
+// Plan defines what a subscription includes. A quota missing from Limits is unlimited.
+type Plan struct {
+	Name     PlanName        `json:"name"`
+	Title    string          `json:"title"`
+	Features []Feature       `json:"features"`
+	Limits   map[Quota]int64 `json:"limits"`
+}
+
+// HasFeature checks if the plan includes a feature
+func (p Plan) HasFeature(feature Feature) bool {
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Limit returns the plan's limit for a quota, and false when it is unlimited
+func (p Plan) Limit(quota Quota) (int64, bool) {
+	limit, ok := p.Limits[quota]
+	return limit, ok
+}
+
+// Plans is the plan catalog, cheapest first
+var Plans = []Plan{
+	{
+		Name:     PlanFree,
+		Title:    "Free",
+		Features: []Feature{},
+		Limits: map[Quota]int64{
+			QuotaProjects:           3,
+			QuotaWorkflows:          3,
+			QuotaMembers:            5,
+			QuotaWorkflowExecutions: 500,
+			QuotaAISuggestions:      100,
+		},
+	},
+	{
+		Name:     PlanPro,
+		Title:    "Pro",
+		Features: []Feature{FeatureIntegrations},
+		Limits: map[Quota]int64{
+			QuotaProjects:           50,
+			QuotaWorkflows:          50,
+			QuotaMembers:            50,
+			QuotaWorkflowExecutions: 20000,
+			QuotaAISuggestions:      5000,
+		},
+	},
+	{
+		Name:     PlanBusiness,
+		Title:    "Business",
+		Features: []Feature{FeatureIntegrations, FeatureAutomation},
+		Limits:   map[Quota]int64{},
+	},
+}
+
+// FindPlan returns a plan from the catalog, falling back to the free plan
+func FindPlan(name PlanName) Plan {
+	for _, p := range Plans {
+		if p.Name == name {
+			return p
+		}
+	}
+	return Plans[0]
+}
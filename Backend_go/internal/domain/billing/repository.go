@@ -0,0 +1,109 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workflow"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for billing data access
+type Repository interface {
+	FindSubscription(ctx context.Context, orgID uuid.UUID) (*Subscription, error)
+	FindSubscriptionByCustomer(ctx context.Context, customerID string) (*Subscription, error)
+	SaveSubscription(ctx context.Context, sub *Subscription) error
+	// RecordEvent stores a webhook event ID, returning ErrEventProcessed if it was stored before
+	RecordEvent(ctx context.Context, eventID, eventType string) error
+	// ForgetEvent removes a recorded event so a failed delivery can be retried
+	ForgetEvent(ctx context.Context, eventID string) error
+	// CountUsage counts the organization's stored resources of a quota
+	CountUsage(ctx context.Context, orgID uuid.UUID, quota Quota) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new billing repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) FindSubscription(ctx context.Context, orgID uuid.UUID) (*Subscription, error) {
+	var sub Subscription
+	err := r.db.WithContext(ctx).Where("organization_id = ?", orgID).First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *repository) FindSubscriptionByCustomer(ctx context.Context, customerID string) (*Subscription, error) {
+	var sub Subscription
+	err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *repository) SaveSubscription(ctx context.Context, sub *Subscription) error {
+	if sub.ID == uuid.Nil {
+		sub.ID = uuid.New()
+		sub.CreatedAt = time.Now()
+	}
+	sub.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Save(sub).Error
+}
+
+func (r *repository) RecordEvent(ctx context.Context, eventID, eventType string) error {
+	event := ProcessedEvent{
+		ID:          uuid.New(),
+		EventID:     eventID,
+		Type:        eventType,
+		ProcessedAt: time.Now(),
+	}
+	if err := r.db.WithContext(ctx).Create(&event).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "SQLSTATE 23505") {
+			return ErrEventProcessed
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ForgetEvent(ctx context.Context, eventID string) error {
+	return r.db.WithContext(ctx).Where("event_id = ?", eventID).Delete(&ProcessedEvent{}).Error
+}
+
+func (r *repository) CountUsage(ctx context.Context, orgID uuid.UUID, quota Quota) (int64, error) {
+	var model interface{}
+	switch quota {
+	case QuotaProjects:
+		model = &project.Project{}
+	case QuotaWorkflows:
+		model = &workflow.Workflow{}
+	case QuotaMembers:
+		model = &organization.Member{}
+	default:
+		return 0, fmt.Errorf("unknown quota %q", quota)
+	}
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(model).Where("organization_id = ?", orgID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,313 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Config configures plan pricing and the customer portal
+type Config struct {
+	// Prices maps paid plans to the provider's price IDs
+	Prices map[PlanName]string
+	// PortalReturnURL is where the customer portal sends users back to
+	PortalReturnURL string
+}
+
+// Service manages organization subscriptions and enforces plan features and limits.
+// Without a payment provider billing is disabled: every organization gets every
+// feature without limits, as in self-hosted deployments.
+type Service interface {
+	Enabled() bool
+	ListPlans() []Plan
+	GetSummary(ctx context.Context, orgID uuid.UUID) (*Summary, error)
+	// CreatePortalSession returns the customer portal URL, creating the billing account on first use
+	CreatePortalSession(ctx context.Context, orgID uuid.UUID) (string, error)
+	HandleWebhook(ctx context.Context, header http.Header, body []byte) error
+
+	// CheckFeature returns ErrFeatureNotInPlan when the organization's plan lacks the feature
+	CheckFeature(ctx context.Context, orgID uuid.UUID, feature Feature) error
+	// CheckQuota returns ErrQuotaExceeded when the organization cannot add another unit of the quota
+	CheckQuota(ctx context.Context, orgID uuid.UUID, quota Quota) error
+}
+
+// UsageMeter reads the metered usage behind monthly quotas
+type UsageMeter interface {
+	Total(ctx context.Context, orgID uuid.UUID, meter metering.Meter, from, to time.Time) (int64, error)
+}
+
+type service struct {
+	repo       Repository
+	provider   Provider
+	orgService organization.Service
+	meters     UsageMeter
+	config     Config
+	logger     *zap.Logger
+}
+
+// NewService creates a new billing service. A nil provider disables billing.
+func NewService(repo Repository, provider Provider, orgService organization.Service, meters UsageMeter, config Config, logger *zap.Logger) Service {
+	return &service{
+		repo:       repo,
+		provider:   provider,
+		orgService: orgService,
+		meters:     meters,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+func (s *service) Enabled() bool {
+	return s.provider != nil
+}
+
+func (s *service) ListPlans() []Plan {
+	return Plans
+}
+
+func (s *service) GetSummary(ctx context.Context, orgID uuid.UUID) (*Summary, error) {
+	sub, err := s.findSubscription(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := FindPlan(sub.EffectivePlan())
+	summary := &Summary{
+		OrganizationID: orgID,
+		Plan:           plan,
+		Status:         StatusActive,
+		Usage:          make([]QuotaUsage, 0, len(Quotas)),
+	}
+	if sub != nil {
+		summary.Status = sub.Status
+		summary.CurrentPeriodEnd = sub.CurrentPeriodEnd
+		summary.CancelAtPeriodEnd = sub.CancelAtPeriodEnd
+		summary.HasBillingAccount = sub.CustomerID != ""
+	}
+
+	now := time.Now()
+	for _, quota := range Quotas {
+		used, resetsAt, err := s.countUsage(ctx, orgID, quota, now)
+		if err != nil {
+			return nil, err
+		}
+		usage := QuotaUsage{Quota: quota, Used: used, ResetsAt: resetsAt}
+		if limit, ok := plan.Limit(quota); ok && s.Enabled() {
+			usage.Limit = &limit
+		}
+		summary.Usage = append(summary.Usage, usage)
+	}
+	return summary, nil
+}
+
+func (s *service) CreatePortalSession(ctx context.Context, orgID uuid.UUID) (string, error) {
+	if !s.Enabled() {
+		return "", ErrBillingDisabled
+	}
+
+	sub, err := s.findSubscription(ctx, orgID)
+	if err != nil {
+		return "", err
+	}
+	if sub == nil {
+		sub = &Subscription{OrganizationID: orgID, Plan: PlanFree, Status: StatusActive}
+	}
+
+	if sub.CustomerID == "" {
+		org, err := s.orgService.GetOrganization(ctx, orgID)
+		if err != nil {
+			return "", err
+		}
+		customerID, err := s.provider.CreateCustomer(ctx, orgID, org.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to create billing account: %w", err)
+		}
+		sub.CustomerID = customerID
+		if err := s.repo.SaveSubscription(ctx, sub); err != nil {
+			return "", err
+		}
+	}
+
+	return s.provider.CreatePortalSession(ctx, sub.CustomerID, s.config.PortalReturnURL)
+}
+
+func (s *service) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	if !s.Enabled() {
+		return ErrBillingDisabled
+	}
+
+	event, err := s.provider.ParseWebhook(header, body, time.Now())
+	if err != nil {
+		return err
+	}
+	if event.Subscription == nil && event.Checkout == nil {
+		return nil
+	}
+
+	if err := s.repo.RecordEvent(ctx, event.ID, event.Type); err != nil {
+		if errors.Is(err, ErrEventProcessed) {
+			return nil
+		}
+		return err
+	}
+
+	switch {
+	case event.Checkout != nil:
+		err = s.applyCheckout(ctx, event.Checkout)
+	case event.Subscription != nil:
+		err = s.applySubscription(ctx, event.Subscription)
+	}
+	if err != nil {
+		// Let the provider redeliver the event
+		if forgetErr := s.repo.ForgetEvent(ctx, event.ID); forgetErr != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to forget billing event", zap.String("event_id", event.ID), zap.Error(forgetErr))
+		}
+		return err
+	}
+
+	tracing.Logger(ctx, s.logger).Info("Applied billing event",
+		zap.String("event_id", event.ID),
+		zap.String("type", event.Type))
+	return nil
+}
+
+func (s *service) applyCheckout(ctx context.Context, checkout *CheckoutCompleted) error {
+	sub, err := s.findSubscription(ctx, checkout.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if sub == nil {
+		sub = &Subscription{OrganizationID: checkout.OrganizationID, Plan: PlanFree, Status: StatusIncomplete}
+	}
+	sub.CustomerID = checkout.CustomerID
+	if checkout.SubscriptionID != "" {
+		sub.SubscriptionID = checkout.SubscriptionID
+	}
+	return s.repo.SaveSubscription(ctx, sub)
+}
+
+func (s *service) applySubscription(ctx context.Context, update *SubscriptionUpdate) error {
+	sub, err := s.repo.FindSubscriptionByCustomer(ctx, update.CustomerID)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		// The customer was created outside the portal flow; fall back to the metadata
+		if update.OrganizationID == uuid.Nil {
+			tracing.Logger(ctx, s.logger).Warn("Ignoring subscription for unknown customer",
+				zap.String("customer_id", update.CustomerID),
+				zap.String("subscription_id", update.SubscriptionID))
+			return nil
+		}
+		sub, err = s.findSubscription(ctx, update.OrganizationID)
+		if err == nil && sub == nil {
+			sub = &Subscription{OrganizationID: update.OrganizationID}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// A deleted subscription only downgrades the organization if it is the current one
+	if update.Deleted {
+		if sub.SubscriptionID != "" && sub.SubscriptionID != update.SubscriptionID {
+			return nil
+		}
+		sub.Plan = PlanFree
+		sub.Status = StatusCanceled
+		sub.CancelAtPeriodEnd = false
+	} else {
+		sub.Plan = s.planForPrice(update.PriceID)
+		sub.Status = update.Status
+		sub.CancelAtPeriodEnd = update.CancelAtPeriodEnd
+	}
+	sub.CustomerID = update.CustomerID
+	sub.SubscriptionID = update.SubscriptionID
+	sub.CurrentPeriodEnd = update.CurrentPeriodEnd
+	return s.repo.SaveSubscription(ctx, sub)
+}
+
+// planForPrice maps a provider price to a plan; unknown prices grant the free plan
+func (s *service) planForPrice(priceID string) PlanName {
+	for plan, id := range s.config.Prices {
+		if id != "" && id == priceID {
+			return plan
+		}
+	}
+	s.logger.Warn("Subscription uses an unknown price", zap.String("price_id", priceID))
+	return PlanFree
+}
+
+func (s *service) CheckFeature(ctx context.Context, orgID uuid.UUID, feature Feature) error {
+	if !s.Enabled() {
+		return nil
+	}
+	plan, err := s.currentPlan(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !plan.HasFeature(feature) {
+		return fmt.Errorf("%w: %s", ErrFeatureNotInPlan, feature)
+	}
+	return nil
+}
+
+func (s *service) CheckQuota(ctx context.Context, orgID uuid.UUID, quota Quota) error {
+	if !s.Enabled() {
+		return nil
+	}
+	plan, err := s.currentPlan(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	limit, limited := plan.Limit(quota)
+	if !limited {
+		return nil
+	}
+	used, _, err := s.countUsage(ctx, orgID, quota, time.Now())
+	if err != nil {
+		return err
+	}
+	if used >= limit {
+		return fmt.Errorf("%w: the %s plan allows %d %s", ErrQuotaExceeded, plan.Title, limit, quota)
+	}
+	return nil
+}
+
+// countUsage counts stored resources, or metered usage since the start of the month for
+// monthly quotas, which also return when the count resets
+func (s *service) countUsage(ctx context.Context, orgID uuid.UUID, quota Quota, now time.Time) (int64, *time.Time, error) {
+	meter, metered := quota.Meter()
+	if !metered {
+		used, err := s.repo.CountUsage(ctx, orgID, quota)
+		return used, nil, err
+	}
+
+	now = now.UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	resetsAt := monthStart.AddDate(0, 1, 0)
+	used, err := s.meters.Total(ctx, orgID, meter, monthStart, now)
+	return used, &resetsAt, err
+}
+
+func (s *service) currentPlan(ctx context.Context, orgID uuid.UUID) (Plan, error) {
+	sub, err := s.findSubscription(ctx, orgID)
+	if err != nil {
+		return Plan{}, err
+	}
+	return FindPlan(sub.EffectivePlan()), nil
+}
+
+// findSubscription returns the organization's subscription, or nil when it has none
+func (s *service) findSubscription(ctx context.Context, orgID uuid.UUID) (*Subscription, error) {
+	sub, err := s.repo.FindSubscription(ctx, orgID)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		return nil, nil
+	}
+	return sub, err
+}
@@ -0,0 +1,243 @@
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Provider adapts a payment provider's API and webhooks
+type Provider interface {
+	// CreateCustomer creates a billing account for an organization and returns its ID
+	CreateCustomer(ctx context.Context, orgID uuid.UUID, name string) (string, error)
+	// CreatePortalSession returns a URL where the customer manages their subscription
+	CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error)
+	// ParseWebhook verifies and decodes a webhook delivery
+	ParseWebhook(header http.Header, body []byte, now time.Time) (*WebhookEvent, error)
+}
+
+const (
+	stripeAPIURL = "https://api.stripe.com/v1"
+	// stripeSignatureTolerance bounds the age of a webhook delivery, against replays
+	stripeSignatureTolerance = 5 * time.Minute
+)
+
+// StripeConfig holds the Stripe credentials
+type StripeConfig struct {
+	SecretKey     string
+	WebhookSecret string
+}
+
+type stripeProvider struct {
+	config StripeConfig
+	client *http.Client
+}
+
+// NewStripeProvider creates the Stripe adapter. The webhook secret is required, since
+// subscription changes only arrive through webhooks.
+func NewStripeProvider(config StripeConfig) (Provider, error) {
+	if config.WebhookSecret == "" {
+		return nil, ErrMissingWebhookSecret
+	}
+	return &stripeProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *stripeProvider) CreateCustomer(ctx context.Context, orgID uuid.UUID, name string) (string, error) {
+	form := url.Values{}
+	form.Set("name", name)
+	form.Set("metadata[organization_id]", orgID.String())
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := p.post(ctx, "/customers", form, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+func (p *stripeProvider) CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error) {
+	form := url.Values{}
+	form.Set("customer", customerID)
+	if returnURL != "" {
+		form.Set("return_url", returnURL)
+	}
+
+	var resp struct {
+		URL string `json:"url"`
+	}
+	if err := p.post(ctx, "/billing_portal/sessions", form, &resp); err != nil {
+		return "", err
+	}
+	return resp.URL, nil
+}
+
+func (p *stripeProvider) post(ctx context.Context, path string, form url.Values, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stripeAPIURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("stripe responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+// ParseWebhook checks the Stripe-Signature header, "t=<timestamp>,v1=<signature>", where the
+// signature is an HMAC-SHA256 over "<timestamp>.<body>", and decodes subscription and checkout events
+func (p *stripeProvider) ParseWebhook(header http.Header, body []byte, now time.Time) (*WebhookEvent, error) {
+	if err := p.verify(header.Get("Stripe-Signature"), body, now); err != nil {
+		return nil, err
+	}
+
+	var envelope struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object json.RawMessage `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	event := &WebhookEvent{ID: envelope.ID, Type: envelope.Type}
+
+	switch envelope.Type {
+	case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+		update, err := parseStripeSubscription(envelope.Data.Object)
+		if err != nil {
+			return nil, err
+		}
+		update.Deleted = envelope.Type == "customer.subscription.deleted"
+		event.Subscription = update
+	case "checkout.session.completed":
+		var session struct {
+			Customer          string `json:"customer"`
+			Subscription      string `json:"subscription"`
+			ClientReferenceID string `json:"client_reference_id"`
+		}
+		if err := json.Unmarshal(envelope.Data.Object, &session); err != nil {
+			return nil, fmt.Errorf("%w: checkout session: %v", ErrInvalidPayload, err)
+		}
+		orgID, err := uuid.Parse(session.ClientReferenceID)
+		if err == nil && session.Customer != "" {
+			event.Checkout = &CheckoutCompleted{
+				CustomerID:     session.Customer,
+				SubscriptionID: session.Subscription,
+				OrganizationID: orgID,
+			}
+		}
+	}
+	return event, nil
+}
+
+func (p *stripeProvider) verify(signatureHeader string, body []byte, now time.Time) error {
+	if p.config.WebhookSecret == "" {
+		return ErrInvalidSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.config.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(expected), []byte(sig)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func parseStripeSubscription(raw json.RawMessage) (*SubscriptionUpdate, error) {
+	var sub struct {
+		ID                string            `json:"id"`
+		Customer          string            `json:"customer"`
+		Status            string            `json:"status"`
+		CurrentPeriodEnd  int64             `json:"current_period_end"`
+		CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+		Metadata          map[string]string `json:"metadata"`
+		Items             struct {
+			Data []struct {
+				Price struct {
+					ID string `json:"id"`
+				} `json:"price"`
+			} `json:"data"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &sub); err != nil {
+		return nil, fmt.Errorf("%w: subscription: %v", ErrInvalidPayload, err)
+	}
+
+	update := &SubscriptionUpdate{
+		CustomerID:        sub.Customer,
+		SubscriptionID:    sub.ID,
+		Status:            SubscriptionStatus(sub.Status),
+		CancelAtPeriodEnd: sub.CancelAtPeriodEnd,
+	}
+	// Stripe's incomplete_expired means the first payment never succeeded
+	if sub.Status == "incomplete_expired" {
+		update.Status = StatusCanceled
+	}
+	if sub.CurrentPeriodEnd > 0 {
+		end := time.Unix(sub.CurrentPeriodEnd, 0).UTC()
+		update.CurrentPeriodEnd = &end
+	}
+	if orgID, err := uuid.Parse(sub.Metadata["organization_id"]); err == nil {
+		update.OrganizationID = orgID
+	}
+	if len(sub.Items.Data) > 0 {
+		update.PriceID = sub.Items.Data[0].Price.ID
+	}
+	return update, nil
+}
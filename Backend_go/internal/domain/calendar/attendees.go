@@ -0,0 +1,335 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AttendeeStatus is an attendee's answer to an invitation
+type AttendeeStatus string
+
+const (
+	AttendeeStatusPending   AttendeeStatus = "pending"
+	AttendeeStatusAccepted  AttendeeStatus = "accepted"
+	AttendeeStatusDeclined  AttendeeStatus = "declined"
+	AttendeeStatusTentative AttendeeStatus = "tentative"
+)
+
+// MaxAttendees is the most attendees an event can have
+const MaxAttendees = 200
+
+var (
+	ErrEventNotFound     = NewError("event not found")
+	ErrAttendeeNotFound  = NewError("attendee not found")
+	ErrInvalidAttendee   = NewError("each attendee needs either a user_id or a valid email")
+	ErrInvalidRSVP       = NewError("status must be accepted, declined or tentative")
+	ErrTooManyAttendees  = NewError("an event can have at most 200 attendees")
+	ErrNotEventOrganizer = NewError("only the organizer of the event can manage its attendees")
+	ErrNotInvited        = NewError("you are not invited to this event")
+	ErrAttendeeForbidden = NewError("only the organizer or the attendee can change this attendance")
+)
+
+// EventAttendee is someone invited to take part in an event: a user of the app, or an
+// external guest known only by email. Unlike collaborators, attendees cannot see or edit
+// the event through sharing; they only answer the invitation.
+type EventAttendee struct {
+	ID      uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	EventID uuid.UUID  `json:"event_id" gorm:"type:uuid;not null;index:idx_event_attendee_event"`
+	UserID  *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index:idx_event_attendee_user"`
+	// Email identifies external attendees and is empty for users
+	Email       string         `json:"email,omitempty" gorm:"type:varchar(255)"`
+	Name        string         `json:"name,omitempty" gorm:"type:varchar(255)"`
+	Status      AttendeeStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Comment     string         `json:"comment,omitempty" gorm:"type:varchar(500)"`
+	InvitedBy   uuid.UUID      `json:"invited_by" gorm:"type:uuid;not null"`
+	RespondedAt *time.Time     `json:"responded_at,omitempty"`
+	// OutOfOffice is set when the attendee was out of office for the event when invited
+	OutOfOffice bool      `json:"out_of_office" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// PartStat returns the iCalendar PARTSTAT of the status, for ATTENDEE lines of an export
+func (s AttendeeStatus) PartStat() string {
+	switch s {
+	case AttendeeStatusAccepted:
+		return "ACCEPTED"
+	case AttendeeStatusDeclined:
+		return "DECLINED"
+	case AttendeeStatusTentative:
+		return "TENTATIVE"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// AttendeeInvite names one attendee to invite, by user ID or by email
+type AttendeeInvite struct {
+	UserID *uuid.UUID
+	Email  string
+	Name   string
+}
+
+// RSVP is an answer to an invitation
+type RSVP struct {
+	Status  AttendeeStatus
+	Comment string
+}
+
+func (r RSVP) validate() error {
+	switch r.Status {
+	case AttendeeStatusAccepted, AttendeeStatusDeclined, AttendeeStatusTentative:
+	default:
+		return ErrInvalidRSVP
+	}
+	if len(r.Comment) > 500 {
+		return NewError("comment must be at most 500 characters")
+	}
+	return nil
+}
+
+// InviteAttendees invites users and external guests to an event of the organizer. People
+// already invited are skipped. Users who are out of office during the event are flagged,
+// or declined right away if their out-of-office event says so, as with collaborators.
+func (s *service) InviteAttendees(ctx context.Context, eventID, organizerID uuid.UUID, invites []AttendeeInvite) ([]EventAttendee, error) {
+	event, err := s.organizedEvent(ctx, eventID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListAttendeesByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	invitedUsers := make(map[uuid.UUID]bool)
+	invitedEmails := make(map[string]bool)
+	for _, a := range existing {
+		if a.UserID != nil {
+			invitedUsers[*a.UserID] = true
+		} else {
+			invitedEmails[a.Email] = true
+		}
+	}
+
+	var pending []EventAttendee
+	for _, invite := range invites {
+		attendee := EventAttendee{
+			EventID:   eventID,
+			Name:      strings.TrimSpace(invite.Name),
+			Status:    AttendeeStatusPending,
+			InvitedBy: organizerID,
+		}
+		email := strings.ToLower(strings.TrimSpace(invite.Email))
+		switch {
+		case invite.UserID != nil && email == "" && *invite.UserID != uuid.Nil:
+			// The organizer attends their own event without an invitation
+			if *invite.UserID == event.UserID || invitedUsers[*invite.UserID] {
+				continue
+			}
+			invitedUsers[*invite.UserID] = true
+			userID := *invite.UserID
+			attendee.UserID = &userID
+		case invite.UserID == nil && email != "":
+			if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+				return nil, ErrInvalidAttendee
+			}
+			if invitedEmails[email] {
+				continue
+			}
+			invitedEmails[email] = true
+			attendee.Email = email
+		default:
+			return nil, ErrInvalidAttendee
+		}
+		if len(attendee.Name) > 255 {
+			return nil, NewError("attendee name must be at most 255 characters")
+		}
+		pending = append(pending, attendee)
+	}
+	if len(existing)+len(pending) > MaxAttendees {
+		return nil, ErrTooManyAttendees
+	}
+
+	added := make([]EventAttendee, 0, len(pending))
+	for _, attendee := range pending {
+		if attendee.UserID != nil {
+			ooo, err := s.outOfOfficeDuring(ctx, *attendee.UserID, event.StartTime, event.EndTime)
+			if err != nil {
+				return added, err
+			}
+			if ooo != nil {
+				attendee.OutOfOffice = true
+				if ooo.DeclineInvites {
+					respondedAt := time.Now()
+					attendee.Status = AttendeeStatusDeclined
+					attendee.RespondedAt = &respondedAt
+				}
+			}
+		}
+		if err := s.repo.AddAttendee(ctx, &attendee); err != nil {
+			return added, err
+		}
+		added = append(added, attendee)
+		s.notifyInvitedAttendee(ctx, event, attendee)
+	}
+	return added, nil
+}
+
+// notifyInvitedAttendee tells a user they were invited, or the organizer that the invite
+// was declined because the user is away. External guests are not notified here.
+func (s *service) notifyInvitedAttendee(ctx context.Context, event *CalendarEvent, attendee EventAttendee) {
+	if s.notifier == nil || attendee.UserID == nil {
+		return
+	}
+	if attendee.Status == AttendeeStatusDeclined {
+		title := "Your event invitation was declined"
+		content := "The attendee is out of office during event: " + event.Title
+		_ = s.notifier.NotifyUser(ctx, event.UserID, notification.EventInviteDeclined, title, content, nil, "calendar_event", event.ID)
+		return
+	}
+	title := "You have been invited to an event"
+	content := "Event: " + event.Title
+	if attendee.OutOfOffice {
+		content += " (you are out of office at this time)"
+	}
+	_ = s.notifier.NotifyUser(ctx, *attendee.UserID, notification.EventInvite, title, content, nil, "calendar_event", event.ID)
+}
+
+func (s *service) ListAttendees(ctx context.Context, eventID uuid.UUID) ([]EventAttendee, error) {
+	if _, err := s.repo.GetEventByID(ctx, eventID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return s.repo.ListAttendeesByEventID(ctx, eventID)
+}
+
+// RespondToAttendance records the user's answer to their invitation to an event
+func (s *service) RespondToAttendance(ctx context.Context, eventID, userID uuid.UUID, rsvp RSVP) (*EventAttendee, error) {
+	if err := rsvp.validate(); err != nil {
+		return nil, err
+	}
+	attendees, err := s.repo.ListAttendeesByEventID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attendee := range attendees {
+		if attendee.UserID != nil && *attendee.UserID == userID {
+			return s.recordRSVP(ctx, &attendee, rsvp)
+		}
+	}
+	return nil, ErrNotInvited
+}
+
+// UpdateAttendeeStatus records an answer for an attendee. The organizer can record the
+// answers of external guests, who reply outside the app; users can only answer for
+// themselves.
+func (s *service) UpdateAttendeeStatus(ctx context.Context, eventID, attendeeID, callerID uuid.UUID, rsvp RSVP) (*EventAttendee, error) {
+	if err := rsvp.validate(); err != nil {
+		return nil, err
+	}
+	attendee, err := s.eventAttendee(ctx, eventID, attendeeID)
+	if err != nil {
+		return nil, err
+	}
+	if attendee.UserID == nil || *attendee.UserID != callerID {
+		if _, err := s.organizedEvent(ctx, eventID, callerID); err != nil {
+			return nil, ErrAttendeeForbidden
+		}
+	}
+	return s.recordRSVP(ctx, attendee, rsvp)
+}
+
+func (s *service) recordRSVP(ctx context.Context, attendee *EventAttendee, rsvp RSVP) (*EventAttendee, error) {
+	respondedAt := time.Now()
+	comment := strings.TrimSpace(rsvp.Comment)
+	if err := s.repo.UpdateAttendeeStatus(ctx, attendee.ID, rsvp.Status, comment, respondedAt); err != nil {
+		return nil, err
+	}
+	attendee.Status = rsvp.Status
+	attendee.Comment = comment
+	attendee.RespondedAt = &respondedAt
+
+	if s.notifier != nil && attendee.UserID != nil {
+		if event, err := s.repo.GetEventByID(ctx, attendee.EventID); err == nil && event.UserID != *attendee.UserID {
+			var nType notification.Type = notification.EventInviteAccepted
+			title := "Your event invitation was accepted"
+			content := "An attendee accepted your invitation to event: " + event.Title
+			switch rsvp.Status {
+			case AttendeeStatusDeclined:
+				nType = notification.EventInviteDeclined
+				title = "Your event invitation was declined"
+				content = "An attendee declined your invitation to event: " + event.Title
+			case AttendeeStatusTentative:
+				nType = notification.EventInviteTentative
+				title = "An attendee might join your event"
+				content = "An attendee tentatively accepted your invitation to event: " + event.Title
+			}
+			_ = s.notifier.NotifyUser(ctx, event.UserID, nType, title, content, nil, "calendar_event", event.ID)
+		}
+	}
+	return attendee, nil
+}
+
+// RemoveAttendee uninvites an attendee. The organizer can remove anyone; users can remove
+// themselves.
+func (s *service) RemoveAttendee(ctx context.Context, eventID, attendeeID, callerID uuid.UUID) error {
+	attendee, err := s.eventAttendee(ctx, eventID, attendeeID)
+	if err != nil {
+		return err
+	}
+	self := attendee.UserID != nil && *attendee.UserID == callerID
+	if !self {
+		if _, err := s.organizedEvent(ctx, eventID, callerID); err != nil {
+			return ErrAttendeeForbidden
+		}
+	}
+	if err := s.repo.RemoveAttendee(ctx, attendeeID); err != nil {
+		return err
+	}
+	if s.notifier != nil && attendee.UserID != nil && !self {
+		if event, err := s.repo.GetEventByID(ctx, eventID); err == nil {
+			title := "You have been removed from an event"
+			content := "Event: " + event.Title
+			_ = s.notifier.NotifyUser(ctx, *attendee.UserID, notification.EventRemovedFromCollab, title, content, nil, "calendar_event", eventID)
+		}
+	}
+	return nil
+}
+
+// organizedEvent loads an event and checks that the user organizes it
+func (s *service) organizedEvent(ctx context.Context, eventID, userID uuid.UUID) (*CalendarEvent, error) {
+	event, err := s.repo.GetEventByID(ctx, eventID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if event.UserID != userID {
+		return nil, ErrNotEventOrganizer
+	}
+	return event, nil
+}
+
+// eventAttendee loads an attendee of the event
+func (s *service) eventAttendee(ctx context.Context, eventID, attendeeID uuid.UUID) (*EventAttendee, error) {
+	attendee, err := s.repo.GetAttendee(ctx, attendeeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAttendeeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if attendee.EventID != eventID {
+		return nil, ErrAttendeeNotFound
+	}
+	return attendee, nil
+}
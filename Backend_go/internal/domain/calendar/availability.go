@@ -0,0 +1,322 @@
+package calendar
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxAvailabilityRange is the longest range one availability lookup covers
+	MaxAvailabilityRange = 31 * 24 * time.Hour
+	// maxAvailabilityEvents caps the events read for one user in a lookup
+	maxAvailabilityEvents = 500
+	// occurrenceLookback is how long before a range occurrences are read from, since
+	// occurrences are listed by their start and one that began earlier can run into it
+	occurrenceLookback = 24 * time.Hour
+	// MinMeetingDuration and MaxMeetingDuration bound the length of a meeting to find
+	// slots for
+	MinMeetingDuration = 5 * time.Minute
+	MaxMeetingDuration = 8 * time.Hour
+	// MaxMeetingSuggestions caps the slots suggested for a meeting
+	MaxMeetingSuggestions = 20
+	// slotAlignment is what suggested slots start on a multiple of
+	slotAlignment = 15 * time.Minute
+)
+
+var (
+	ErrInvalidAvailabilityRange = NewError("availability range must end after it starts and span at most 31 days")
+	ErrInvalidMeetingDuration   = NewError("meeting duration must be between 5 minutes and 8 hours")
+)
+
+// AvailabilityStatus is why a user is not free
+type AvailabilityStatus string
+
+const (
+	AvailabilityBusy        AvailabilityStatus = "busy"
+	AvailabilityOutOfOffice AvailabilityStatus = "out_of_office"
+)
+
+// BusySpan is a period a user is not free
+type BusySpan struct {
+	Start  time.Time          `json:"start"`
+	End    time.Time          `json:"end"`
+	Status AvailabilityStatus `json:"status"`
+}
+
+// LocationSpan is a period a user works from a known place
+type LocationSpan struct {
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end"`
+	Location WorkingLocation `json:"location"`
+}
+
+// TimeSpan is a period of time
+type TimeSpan struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Availability is a user's free/busy time and working locations over a range. It carries
+// no event details, so it can be shown to teammates.
+type Availability struct {
+	UserID           uuid.UUID      `json:"user_id"`
+	Busy             []BusySpan     `json:"busy"`
+	Free             []TimeSpan     `json:"free"`
+	WorkingLocations []LocationSpan `json:"working_locations"`
+}
+
+// MeetingQuery looks for times a group of users can all meet
+type MeetingQuery struct {
+	UserIDs  []uuid.UUID
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	// Limit caps the suggested slots, MaxMeetingSuggestions when zero
+	Limit int
+	// WorkingHours are the periods each user works. Slots are only suggested while every
+	// user works; users without an entry are taken to work at any time.
+	WorkingHours map[uuid.UUID][]TimeSpan
+}
+
+// MeetingTimes is the availability of a group of users and the times they can all meet
+type MeetingTimes struct {
+	Start           time.Time      `json:"start"`
+	End             time.Time      `json:"end"`
+	DurationMinutes int            `json:"duration_minutes"`
+	Members         []Availability `json:"members"`
+	// CommonFree is the time in the range no member is busy
+	CommonFree []TimeSpan `json:"common_free"`
+	// Suggestions are slots of the meeting's length in the common free time during
+	// everyone's working hours, earliest first and at most one per free stretch
+	Suggestions []TimeSpan `json:"suggestions"`
+}
+
+// instance is one occurrence of an event
+type instance struct {
+	start, end  time.Time
+	transparent bool
+}
+
+// eventInstances lists the occurrences of an event, or the event itself when it does not
+// recur, skipping cancelled occurrences
+func eventInstances(event CalendarEvent) []instance {
+	transparent := event.Transparency == TransparencyTransparent
+	if len(event.RecurrenceRules) == 0 {
+		return []instance{{event.StartTime, event.EndTime, transparent}}
+	}
+	var instances []instance
+	length := event.EndTime.Sub(event.StartTime)
+	for _, occ := range event.Occurrences {
+		if occ.Status == OccurrenceStatusCancelled {
+			continue
+		}
+		inst := instance{occ.OccurrenceTime, occ.OccurrenceTime.Add(length), transparent}
+		if occ.EndTime != nil {
+			inst.end = *occ.EndTime
+		}
+		if occ.Transparency != nil {
+			inst.transparent = *occ.Transparency == TransparencyTransparent
+		}
+		instances = append(instances, inst)
+	}
+	return instances
+}
+
+// GetAvailability returns the free/busy time and working locations of each user between
+// start and end. Opaque events make a user busy, out-of-office events mark them out of
+// office, and working location events only report where they work.
+func (s *service) GetAvailability(ctx context.Context, userIDs []uuid.UUID, start, end time.Time) ([]Availability, error) {
+	if !end.After(start) || end.Sub(start) > MaxAvailabilityRange {
+		return nil, ErrInvalidAvailabilityRange
+	}
+
+	result := make([]Availability, 0, len(userIDs))
+	for _, userID := range userIDs {
+		events, err := s.ListEvents(ctx, userID, start.Add(-occurrenceLookback), end, nil, 1, maxAvailabilityEvents)
+		if err != nil {
+			return nil, err
+		}
+
+		availability := Availability{UserID: userID, Busy: []BusySpan{}, WorkingLocations: []LocationSpan{}}
+		for _, event := range events.Events {
+			for _, inst := range eventInstances(event) {
+				spanStart, spanEnd := maxTime(inst.start, start), minTime(inst.end, end)
+				if !spanEnd.After(spanStart) {
+					continue
+				}
+				switch {
+				case event.EventType == EventTypeWorkingLocation:
+					availability.WorkingLocations = append(availability.WorkingLocations,
+						LocationSpan{Start: spanStart, End: spanEnd, Location: event.WorkingLocation})
+				case event.EventType == EventTypeOutOfOffice:
+					availability.Busy = append(availability.Busy,
+						BusySpan{Start: spanStart, End: spanEnd, Status: AvailabilityOutOfOffice})
+				case !inst.transparent:
+					availability.Busy = append(availability.Busy,
+						BusySpan{Start: spanStart, End: spanEnd, Status: AvailabilityBusy})
+				}
+			}
+		}
+		availability.Busy = mergeBusy(availability.Busy)
+		availability.Free = freeSpans(availability.Busy, start, end)
+		sort.Slice(availability.WorkingLocations, func(i, j int) bool {
+			return availability.WorkingLocations[i].Start.Before(availability.WorkingLocations[j].Start)
+		})
+		result = append(result, availability)
+	}
+	return result, nil
+}
+
+// FindMeetingTimes returns the availability of the users over the range, the time they
+// are all free, and slots of the meeting's length in it while they all work. Slots are
+// not suggested in the past.
+func (s *service) FindMeetingTimes(ctx context.Context, query MeetingQuery) (*MeetingTimes, error) {
+	if query.Duration < MinMeetingDuration || query.Duration > MaxMeetingDuration {
+		return nil, ErrInvalidMeetingDuration
+	}
+	limit := query.Limit
+	if limit <= 0 || limit > MaxMeetingSuggestions {
+		limit = MaxMeetingSuggestions
+	}
+	members, err := s.GetAvailability(ctx, query.UserIDs, query.Start, query.End)
+	if err != nil {
+		return nil, err
+	}
+
+	common := []TimeSpan{{Start: query.Start, End: query.End}}
+	for _, member := range members {
+		common = intersectSpans(common, member.Free)
+	}
+	windows := intersectSpans(common, []TimeSpan{{Start: maxTime(query.Start, time.Now()), End: query.End}})
+	for _, userID := range query.UserIDs {
+		if hours, ok := query.WorkingHours[userID]; ok {
+			windows = intersectSpans(windows, hours)
+		}
+	}
+
+	suggestions := []TimeSpan{}
+	for _, window := range windows {
+		if len(suggestions) == limit {
+			break
+		}
+		slotStart := window.Start.Truncate(slotAlignment)
+		if slotStart.Before(window.Start) {
+			slotStart = slotStart.Add(slotAlignment)
+		}
+		if slotEnd := slotStart.Add(query.Duration); !slotEnd.After(window.End) {
+			suggestions = append(suggestions, TimeSpan{Start: slotStart, End: slotEnd})
+		}
+	}
+
+	return &MeetingTimes{
+		Start:           query.Start,
+		End:             query.End,
+		DurationMinutes: int(query.Duration / time.Minute),
+		Members:         members,
+		CommonFree:      common,
+		Suggestions:     suggestions,
+	}, nil
+}
+
+// freeSpans returns the time between start and end outside the busy spans
+func freeSpans(busy []BusySpan, start, end time.Time) []TimeSpan {
+	free := []TimeSpan{}
+	cursor := start
+	for _, span := range busy {
+		if span.Start.After(cursor) {
+			free = append(free, TimeSpan{Start: cursor, End: minTime(span.Start, end)})
+		}
+		cursor = maxTime(cursor, span.End)
+		if !cursor.Before(end) {
+			return free
+		}
+	}
+	return append(free, TimeSpan{Start: cursor, End: end})
+}
+
+// intersectSpans returns the time covered by both lists. The spans of a list may be
+// unordered and overlap.
+func intersectSpans(a, b []TimeSpan) []TimeSpan {
+	a, b = mergeSpans(a), mergeSpans(b)
+	result := []TimeSpan{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		spanStart, spanEnd := maxTime(a[i].Start, b[j].Start), minTime(a[i].End, b[j].End)
+		if spanEnd.After(spanStart) {
+			result = append(result, TimeSpan{Start: spanStart, End: spanEnd})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// mergeSpans orders the spans and joins the ones that overlap or touch
+func mergeSpans(spans []TimeSpan) []TimeSpan {
+	sorted := append([]TimeSpan(nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+	merged := make([]TimeSpan, 0, len(sorted))
+	for _, span := range sorted {
+		if n := len(merged); n > 0 && !span.Start.After(merged[n-1].End) {
+			merged[n-1].End = maxTime(merged[n-1].End, span.End)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// mergeBusy orders the spans and joins overlapping spans of the same status
+func mergeBusy(spans []BusySpan) []BusySpan {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start.Before(spans[j].Start) })
+	merged := make([]BusySpan, 0, len(spans))
+	for _, span := range spans {
+		if n := len(merged); n > 0 && merged[n-1].Status == span.Status && !span.Start.After(merged[n-1].End) {
+			merged[n-1].End = maxTime(merged[n-1].End, span.End)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// outOfOfficeDuring returns the user's out-of-office event overlapping the period, if
+// any, preferring one that declines invites
+func (s *service) outOfOfficeDuring(ctx context.Context, userID uuid.UUID, start, end time.Time) (*CalendarEvent, error) {
+	oooType := EventTypeOutOfOffice
+	events, err := s.ListEvents(ctx, userID, start, end, &oooType, 1, maxAvailabilityEvents)
+	if err != nil {
+		return nil, err
+	}
+	var found *CalendarEvent
+	for i, event := range events.Events {
+		for _, inst := range eventInstances(event) {
+			if inst.start.Before(end) && inst.end.After(start) {
+				if found == nil || (event.DeclineInvites && !found.DeclineInvites) {
+					found = &events.Events[i]
+				}
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
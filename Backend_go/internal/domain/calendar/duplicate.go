@@ -0,0 +1,97 @@
+package calendar
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DuplicateOffset says where the copy of a duplicated event goes
+type DuplicateOffset string
+
+const (
+	// DuplicateNextWeek puts the copy at the same local time a week later
+	DuplicateNextWeek DuplicateOffset = "next_week"
+	// DuplicateOnDate puts the copy at the same local time on another date
+	DuplicateOnDate DuplicateOffset = "date"
+)
+
+// DuplicateDateLayout is the layout of the date a copy is put on
+const DuplicateDateLayout = "2006-01-02"
+
+var ErrInvalidDuplicate = NewError("offset must be next_week, or date with a date in YYYY-MM-DD format")
+
+// DuplicateOptions configures a copy of an event
+type DuplicateOptions struct {
+	Offset DuplicateOffset
+	// Date is the day of the copy in the event's timezone, for DuplicateOnDate
+	Date string
+	// CopyAttendees invites the attendees of the event to the copy
+	CopyAttendees bool
+}
+
+// DuplicateEvent copies an event the user organizes to another day, keeping its local
+// start time and its length. The copy gets the event's reminders but not its recurrence,
+// so one-off meetings can be repeated without a rule. Attendees are invited again rather
+// than copied, so their answers to the original are not carried over.
+func (s *service) DuplicateEvent(ctx context.Context, eventID, userID uuid.UUID, opts DuplicateOptions) (*CalendarEvent, error) {
+	event, err := s.organizedEvent(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := location(event.Timezone)
+	start := event.StartTime.In(loc)
+	switch opts.Offset {
+	case DuplicateNextWeek:
+		start = start.AddDate(0, 0, 7)
+	case DuplicateOnDate:
+		day, err := time.ParseInLocation(DuplicateDateLayout, opts.Date, loc)
+		if err != nil {
+			return nil, ErrInvalidDuplicate
+		}
+		start = time.Date(day.Year(), day.Month(), day.Day(),
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), loc)
+	default:
+		return nil, ErrInvalidDuplicate
+	}
+
+	req := CreateCalendarEventRequest{
+		Title:           event.Title,
+		Description:     event.Description,
+		EventType:       event.EventType,
+		StartTime:       start,
+		EndTime:         start.Add(event.EndTime.Sub(event.StartTime)),
+		IsAllDay:        event.IsAllDay,
+		Location:        event.Location,
+		Color:           event.Color,
+		Transparency:    event.Transparency,
+		WorkingLocation: event.WorkingLocation,
+		DeclineInvites:  event.DeclineInvites,
+		Timezone:        event.Timezone,
+	}
+	for _, reminder := range event.Reminders {
+		req.Reminders = append(req.Reminders, CreateEventReminderRequest{
+			MinutesBefore: reminder.MinutesBefore,
+			Method:        reminder.Method,
+			WebhookURL:    reminder.WebhookURL,
+		})
+	}
+	duplicate, err := s.CreateEvent(ctx, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.CopyAttendees && len(event.Attendees) > 0 {
+		invites := make([]AttendeeInvite, 0, len(event.Attendees))
+		for _, attendee := range event.Attendees {
+			invites = append(invites, AttendeeInvite{UserID: attendee.UserID, Email: attendee.Email, Name: attendee.Name})
+		}
+		if _, err := s.InviteAttendees(ctx, duplicate.ID, userID, invites); err != nil {
+			return nil, err
+		}
+		return s.GetEventByID(ctx, duplicate.ID)
+	}
+	return duplicate, nil
+}
@@ -0,0 +1,690 @@
+package calendar
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryRepository keeps calendar data in memory, for tests and the demo server.
+// Records are stored by value so callers never share them with the store. Lookups of
+// missing records return gorm.ErrRecordNotFound, as the database repository does.
+type memoryRepository struct {
+	mu            sync.RWMutex
+	events        map[uuid.UUID]CalendarEvent
+	rules         map[uuid.UUID]RecurrenceRule
+	occurrences   map[uuid.UUID]EventOccurrence
+	exceptions    map[uuid.UUID]EventException
+	reminders     map[uuid.UUID]EventReminder
+	deliveries    map[uuid.UUID]ReminderDelivery
+	collaborators map[uuid.UUID]EventCollaborator
+	attendees     map[uuid.UUID]EventAttendee
+}
+
+// NewMemoryRepository creates a Repository that keeps everything in memory
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		events:        make(map[uuid.UUID]CalendarEvent),
+		rules:         make(map[uuid.UUID]RecurrenceRule),
+		occurrences:   make(map[uuid.UUID]EventOccurrence),
+		exceptions:    make(map[uuid.UUID]EventException),
+		reminders:     make(map[uuid.UUID]EventReminder),
+		deliveries:    make(map[uuid.UUID]ReminderDelivery),
+		collaborators: make(map[uuid.UUID]EventCollaborator),
+		attendees:     make(map[uuid.UUID]EventAttendee),
+	}
+}
+
+// stamp fills in the ID and timestamps the database would
+func stamp(id *uuid.UUID, createdAt, updatedAt *time.Time) {
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	*updatedAt = now
+}
+
+func (r *memoryRepository) CreateEvent(ctx context.Context, event *CalendarEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.putEvent(event)
+	return nil
+}
+
+// putEvent stores an event and the rules and reminders it carries. The caller holds the lock.
+func (r *memoryRepository) putEvent(event *CalendarEvent) {
+	stamp(&event.ID, &event.CreatedAt, &event.UpdatedAt)
+	if event.EventType == "" {
+		event.EventType = EventTypeNone
+	}
+	if event.Transparency == "" {
+		event.Transparency = TransparencyOpaque
+	}
+	for i := range event.RecurrenceRules {
+		rule := &event.RecurrenceRules[i]
+		rule.EventID = event.ID
+		stamp(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+		r.rules[rule.ID] = *rule
+	}
+	for i := range event.Reminders {
+		reminder := &event.Reminders[i]
+		reminder.EventID = event.ID
+		stamp(&reminder.ID, &reminder.CreatedAt, &reminder.UpdatedAt)
+		r.reminders[reminder.ID] = *reminder
+	}
+
+	stored := *event
+	stored.RecurrenceRules = nil
+	stored.Occurrences = nil
+	stored.Exceptions = nil
+	stored.Reminders = nil
+	stored.Collaborators = nil
+	stored.Attendees = nil
+	r.events[event.ID] = stored
+}
+
+// withRelations returns a copy of an event with its rules, reminders and attendees, as the
+// database repository preloads them. The caller holds the lock.
+func (r *memoryRepository) withRelations(event CalendarEvent) CalendarEvent {
+	event.RecurrenceRules = []RecurrenceRule{}
+	for _, rule := range r.rules {
+		if rule.EventID == event.ID {
+			event.RecurrenceRules = append(event.RecurrenceRules, rule)
+		}
+	}
+	event.Reminders = []EventReminder{}
+	for _, reminder := range r.reminders {
+		if reminder.EventID == event.ID {
+			event.Reminders = append(event.Reminders, reminder)
+		}
+	}
+	event.Attendees = r.eventAttendees(event.ID)
+	return event
+}
+
+func (r *memoryRepository) GetEventByID(ctx context.Context, id uuid.UUID) (*CalendarEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	event, ok := r.events[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	event = r.withRelations(event)
+	return &event, nil
+}
+
+func (r *memoryRepository) UpdateEvent(ctx context.Context, event *CalendarEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.putEvent(event)
+	return nil
+}
+
+func (r *memoryRepository) DeleteEvent(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ruleID, rule := range r.rules {
+		if rule.EventID == id {
+			delete(r.rules, ruleID)
+		}
+	}
+	for exceptionID, exception := range r.exceptions {
+		if exception.EventID == id {
+			delete(r.exceptions, exceptionID)
+		}
+	}
+	for occurrenceID, occurrence := range r.occurrences {
+		if occurrence.EventID == id {
+			delete(r.occurrences, occurrenceID)
+		}
+	}
+	for reminderID, reminder := range r.reminders {
+		if reminder.EventID == id {
+			delete(r.reminders, reminderID)
+		}
+	}
+	for attendeeID, attendee := range r.attendees {
+		if attendee.EventID == id {
+			delete(r.attendees, attendeeID)
+		}
+	}
+	delete(r.events, id)
+	return nil
+}
+
+func (r *memoryRepository) ListEvents(ctx context.Context, filter EventFilter) ([]CalendarEvent, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	search := strings.ToLower(filter.Search)
+	events := []CalendarEvent{}
+	for _, event := range r.events {
+		if event.UserID != filter.UserID {
+			continue
+		}
+		if filter.StartTime != nil && filter.EndTime != nil && !r.inRange(&event, *filter.StartTime, *filter.EndTime) {
+			continue
+		}
+		if filter.EventType != nil && event.EventType != *filter.EventType {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(event.Title), search) &&
+			!strings.Contains(strings.ToLower(event.Description), search) {
+			continue
+		}
+		events = append(events, r.withRelations(event))
+	}
+	if filter.Keyset != nil {
+		return pagination.Slice(filter.Keyset, events, (*CalendarEvent).PageCursor), 0, nil
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].StartTime.Before(events[j].StartTime) })
+
+	total := int64(len(events))
+	if filter.Page > 0 && filter.PageSize > 0 {
+		start := (filter.Page - 1) * filter.PageSize
+		if start >= len(events) {
+			return []CalendarEvent{}, total, nil
+		}
+		end := start + filter.PageSize
+		if end > len(events) {
+			end = len(events)
+		}
+		events = events[start:end]
+	}
+	return events, total, nil
+}
+
+// inRange reports whether an event overlaps [start, end] or has an occurrence in it. The
+// caller holds the lock.
+func (r *memoryRepository) inRange(event *CalendarEvent, start, end time.Time) bool {
+	if !event.StartTime.After(end) && !event.EndTime.Before(start) {
+		return true
+	}
+	for _, occurrence := range r.occurrences {
+		if occurrence.EventID == event.ID && between(occurrence.OccurrenceTime, start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+func between(t, start, end time.Time) bool {
+	return !t.Before(start) && !t.After(end)
+}
+
+func (r *memoryRepository) FindAll(ctx context.Context, filter EventFilter) ([]CalendarEvent, int64, error) {
+	return r.ListEvents(ctx, filter)
+}
+
+func (r *memoryRepository) AddRecurrenceRule(ctx context.Context, rule *RecurrenceRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stamp(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	r.rules[rule.ID] = *rule
+	return nil
+}
+
+func (r *memoryRepository) UpdateRecurrenceRule(ctx context.Context, rule *RecurrenceRule) error {
+	return r.AddRecurrenceRule(ctx, rule)
+}
+
+func (r *memoryRepository) DeleteRecurrenceRule(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rules, id)
+	return nil
+}
+
+func (r *memoryRepository) CreateException(ctx context.Context, exception *EventException) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stamp(&exception.ID, &exception.CreatedAt, &exception.UpdatedAt)
+	r.exceptions[exception.ID] = *exception
+	return nil
+}
+
+func (r *memoryRepository) UpdateException(ctx context.Context, exception *EventException) error {
+	return r.CreateException(ctx, exception)
+}
+
+// findExceptions returns copies of the exceptions that match, in original time order
+func (r *memoryRepository) findExceptions(match func(*EventException) bool) []EventException {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	exceptions := []EventException{}
+	for _, exception := range r.exceptions {
+		if match(&exception) {
+			exceptions = append(exceptions, exception)
+		}
+	}
+	sort.Slice(exceptions, func(i, j int) bool { return exceptions[i].OriginalTime.Before(exceptions[j].OriginalTime) })
+	return exceptions
+}
+
+func (r *memoryRepository) GetExceptions(ctx context.Context, eventID uuid.UUID, startTime, endTime time.Time) ([]EventException, error) {
+	return r.findExceptions(func(e *EventException) bool {
+		return e.EventID == eventID && between(e.OriginalTime, startTime, endTime)
+	}), nil
+}
+
+func (r *memoryRepository) GetAllExceptionsByEventID(ctx context.Context, eventID uuid.UUID) ([]EventException, error) {
+	return r.findExceptions(func(e *EventException) bool { return e.EventID == eventID }), nil
+}
+
+func (r *memoryRepository) GetExceptionsByOccurrenceId(ctx context.Context, occurrenceID uuid.UUID) ([]EventException, error) {
+	return r.findExceptions(func(e *EventException) bool { return e.OccurrenceID == occurrenceID }), nil
+}
+
+func (r *memoryRepository) CreateOccurrence(ctx context.Context, occurrence *EventOccurrence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stamp(&occurrence.ID, &occurrence.CreatedAt, &occurrence.UpdatedAt)
+	if occurrence.Status == "" {
+		occurrence.Status = OccurrenceStatusUpcoming
+	}
+	r.occurrences[occurrence.ID] = *occurrence
+	return nil
+}
+
+func (r *memoryRepository) UpdateOccurrence(ctx context.Context, occurrence *EventOccurrence) error {
+	return r.CreateOccurrence(ctx, occurrence)
+}
+
+func (r *memoryRepository) UpdateOccurrenceStatus(ctx context.Context, id uuid.UUID, status OccurrenceStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if occurrence, ok := r.occurrences[id]; ok {
+		occurrence.Status = status
+		occurrence.UpdatedAt = time.Now()
+		r.occurrences[id] = occurrence
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetOccurrences(ctx context.Context, eventID uuid.UUID, startTime, endTime time.Time) ([]EventOccurrence, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	occurrences := []EventOccurrence{}
+	for _, occurrence := range r.occurrences {
+		if occurrence.EventID == eventID && between(occurrence.OccurrenceTime, startTime, endTime) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].OccurrenceTime.Before(occurrences[j].OccurrenceTime)
+	})
+	return occurrences, nil
+}
+
+func (r *memoryRepository) GetOccurrenceById(ctx context.Context, id uuid.UUID) (*EventOccurrence, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	occurrence, ok := r.occurrences[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &occurrence, nil
+}
+
+func (r *memoryRepository) AddReminder(ctx context.Context, reminder *EventReminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stamp(&reminder.ID, &reminder.CreatedAt, &reminder.UpdatedAt)
+	r.reminders[reminder.ID] = *reminder
+	return nil
+}
+
+func (r *memoryRepository) UpdateReminder(ctx context.Context, reminder *EventReminder) error {
+	return r.AddReminder(ctx, reminder)
+}
+
+func (r *memoryRepository) DeleteReminder(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reminders, id)
+	return nil
+}
+
+// GetUpcomingReminders returns the reminders due to fire between startTime and endTime,
+// for the event itself or for any occurrence or moved occurrence of a recurring event
+func (r *memoryRepository) GetUpcomingReminders(ctx context.Context, startTime, endTime time.Time) ([]EventReminder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reminders := []EventReminder{}
+	for _, reminder := range r.reminders {
+		event, ok := r.events[reminder.EventID]
+		if !ok {
+			continue
+		}
+		lead := time.Duration(reminder.MinutesBefore) * time.Minute
+		due := between(event.StartTime.Add(-lead), startTime, endTime)
+		for _, occurrence := range r.occurrences {
+			if due {
+				break
+			}
+			due = occurrence.EventID == event.ID && between(occurrence.OccurrenceTime.Add(-lead), startTime, endTime)
+		}
+		for _, exception := range r.exceptions {
+			if due {
+				break
+			}
+			due = exception.EventID == event.ID && exception.OverrideStartTime != nil &&
+				between(exception.OverrideStartTime.Add(-lead), startTime, endTime)
+		}
+		if due {
+			reminders = append(reminders, reminder)
+		}
+	}
+	return reminders, nil
+}
+
+// ClaimReminderDelivery stores a pending delivery. It returns false when the reminder was
+// already claimed for the occurrence, so that only one worker sends it.
+func (r *memoryRepository) ClaimReminderDelivery(ctx context.Context, delivery *ReminderDelivery) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.deliveries {
+		if existing.ReminderID == delivery.ReminderID && existing.OccurrenceTime.Equal(delivery.OccurrenceTime) {
+			return false, nil
+		}
+	}
+	stamp(&delivery.ID, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if delivery.Status == "" {
+		delivery.Status = DeliveryStatusPending
+	}
+	r.deliveries[delivery.ID] = *delivery
+	return true, nil
+}
+
+// CompleteReminderDelivery records the outcome of a delivery on it and on its reminder
+func (r *memoryRepository) CompleteReminderDelivery(ctx context.Context, delivery *ReminderDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.deliveries[delivery.ID]; ok {
+		stored.Status = delivery.Status
+		stored.Error = delivery.Error
+		stored.SentAt = delivery.SentAt
+		stored.UpdatedAt = time.Now()
+		r.deliveries[delivery.ID] = stored
+	}
+	if reminder, ok := r.reminders[delivery.ReminderID]; ok {
+		reminder.LastStatus = delivery.Status
+		reminder.LastError = delivery.Error
+		if delivery.SentAt != nil {
+			reminder.LastDeliveredAt = delivery.SentAt
+		}
+		r.reminders[delivery.ReminderID] = reminder
+	}
+	return nil
+}
+
+func (r *memoryRepository) AddCollaborator(ctx context.Context, collaborator *EventCollaborator) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stamp(&collaborator.ID, &collaborator.CreatedAt, &collaborator.UpdatedAt)
+	if collaborator.InvitedAt.IsZero() {
+		collaborator.InvitedAt = collaborator.CreatedAt
+	}
+	r.collaborators[collaborator.ID] = *collaborator
+	return nil
+}
+
+func (r *memoryRepository) RemoveCollaborator(ctx context.Context, eventID, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, c := range r.collaborators {
+		if c.EventID == eventID && c.UserID == userID {
+			delete(r.collaborators, id)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) ListCollaboratorsByEventID(ctx context.Context, eventID uuid.UUID) ([]EventCollaborator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	collaborators := []EventCollaborator{}
+	for _, c := range r.collaborators {
+		if c.EventID == eventID {
+			collaborators = append(collaborators, c)
+		}
+	}
+	sort.Slice(collaborators, func(i, j int) bool { return collaborators[i].CreatedAt.Before(collaborators[j].CreatedAt) })
+	return collaborators, nil
+}
+
+func (r *memoryRepository) ListEventsSharedWithUser(ctx context.Context, userID uuid.UUID) ([]CalendarEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	events := []CalendarEvent{}
+	for _, c := range r.collaborators {
+		if c.UserID != userID || c.Status != "accepted" {
+			continue
+		}
+		if event, ok := r.events[c.EventID]; ok {
+			events = append(events, r.withRelations(event))
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].StartTime.Before(events[j].StartTime) })
+	return events, nil
+}
+
+func (r *memoryRepository) UpdateCollaboratorStatus(ctx context.Context, eventID, userID uuid.UUID, status string, respondedAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, c := range r.collaborators {
+		if c.EventID == eventID && c.UserID == userID {
+			c.Status = status
+			if respondedAt != nil {
+				c.RespondedAt = respondedAt
+			}
+			r.collaborators[id] = c
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetCollaborator(ctx context.Context, eventID, userID uuid.UUID) (*EventCollaborator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.collaborators {
+		if c.EventID == eventID && c.UserID == userID {
+			return &c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryRepository) AddAttendee(ctx context.Context, attendee *EventAttendee) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stamp(&attendee.ID, &attendee.CreatedAt, &attendee.UpdatedAt)
+	if attendee.Status == "" {
+		attendee.Status = AttendeeStatusPending
+	}
+	r.attendees[attendee.ID] = *attendee
+	return nil
+}
+
+func (r *memoryRepository) GetAttendee(ctx context.Context, id uuid.UUID) (*EventAttendee, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	attendee, ok := r.attendees[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &attendee, nil
+}
+
+func (r *memoryRepository) ListAttendeesByEventID(ctx context.Context, eventID uuid.UUID) ([]EventAttendee, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.eventAttendees(eventID), nil
+}
+
+// eventAttendees returns the attendees of an event, oldest first. The caller holds the lock.
+func (r *memoryRepository) eventAttendees(eventID uuid.UUID) []EventAttendee {
+	attendees := []EventAttendee{}
+	for _, a := range r.attendees {
+		if a.EventID == eventID {
+			attendees = append(attendees, a)
+		}
+	}
+	sort.Slice(attendees, func(i, j int) bool { return attendees[i].CreatedAt.Before(attendees[j].CreatedAt) })
+	return attendees
+}
+
+func (r *memoryRepository) UpdateAttendeeStatus(ctx context.Context, id uuid.UUID, status AttendeeStatus, comment string, respondedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.attendees[id]; ok {
+		a.Status = status
+		a.Comment = comment
+		a.RespondedAt = &respondedAt
+		a.UpdatedAt = time.Now()
+		r.attendees[id] = a
+	}
+	return nil
+}
+
+func (r *memoryRepository) RemoveAttendee(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attendees, id)
+	return nil
+}
+
+// BeginTransaction returns a transaction that writes through to the store and undoes its
+// writes when rolled back before it is committed
+func (r *memoryRepository) BeginTransaction(ctx context.Context) Transaction {
+	return &memoryTransaction{ctx: ctx, repo: r}
+}
+
+type memoryTransaction struct {
+	ctx       context.Context
+	repo      *memoryRepository
+	undo      []func()
+	committed bool
+}
+
+// track remembers how to undo a write of the record with the given id in table
+func track[T any](t *memoryTransaction, table map[uuid.UUID]T, id uuid.UUID) {
+	t.repo.mu.RLock()
+	previous, existed := table[id]
+	t.repo.mu.RUnlock()
+	t.undo = append(t.undo, func() {
+		if existed {
+			table[id] = previous
+		} else {
+			delete(table, id)
+		}
+	})
+}
+
+func (t *memoryTransaction) Commit() error {
+	t.committed = true
+	t.undo = nil
+	return nil
+}
+
+func (t *memoryTransaction) Rollback() error {
+	if t.committed {
+		return nil
+	}
+	t.repo.mu.Lock()
+	defer t.repo.mu.Unlock()
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.undo = nil
+	return nil
+}
+
+func (t *memoryTransaction) CreateEvent(event *CalendarEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	for i := range event.RecurrenceRules {
+		if event.RecurrenceRules[i].ID == uuid.Nil {
+			event.RecurrenceRules[i].ID = uuid.New()
+		}
+		track(t, t.repo.rules, event.RecurrenceRules[i].ID)
+	}
+	for i := range event.Reminders {
+		if event.Reminders[i].ID == uuid.Nil {
+			event.Reminders[i].ID = uuid.New()
+		}
+		track(t, t.repo.reminders, event.Reminders[i].ID)
+	}
+	track(t, t.repo.events, event.ID)
+	return t.repo.CreateEvent(t.ctx, event)
+}
+
+func (t *memoryTransaction) UpdateEvent(event *CalendarEvent) error {
+	return t.CreateEvent(event)
+}
+
+func (t *memoryTransaction) CreateRecurrenceRule(rule *RecurrenceRule) error {
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	track(t, t.repo.rules, rule.ID)
+	return t.repo.AddRecurrenceRule(t.ctx, rule)
+}
+
+func (t *memoryTransaction) UpdateRecurrenceRule(rule *RecurrenceRule) error {
+	return t.CreateRecurrenceRule(rule)
+}
+
+func (t *memoryTransaction) CreateOccurrence(occurrence *EventOccurrence) error {
+	if occurrence.ID == uuid.Nil {
+		occurrence.ID = uuid.New()
+	}
+	track(t, t.repo.occurrences, occurrence.ID)
+	return t.repo.CreateOccurrence(t.ctx, occurrence)
+}
+
+func (t *memoryTransaction) UpdateOccurrence(occurrence *EventOccurrence) error {
+	return t.CreateOccurrence(occurrence)
+}
+
+func (t *memoryTransaction) CreateReminder(reminder *EventReminder) error {
+	if reminder.ID == uuid.Nil {
+		reminder.ID = uuid.New()
+	}
+	track(t, t.repo.reminders, reminder.ID)
+	return t.repo.AddReminder(t.ctx, reminder)
+}
+
+func (t *memoryTransaction) CreateException(exception *EventException) error {
+	if exception.ID == uuid.Nil {
+		exception.ID = uuid.New()
+	}
+	track(t, t.repo.exceptions, exception.ID)
+	return t.repo.CreateException(t.ctx, exception)
+}
+
+func (t *memoryTransaction) UpdateException(exception *EventException) error {
+	return t.CreateException(exception)
+}
+
+func (t *memoryTransaction) GetExceptions(eventID uuid.UUID, startTime, endTime time.Time) ([]EventException, error) {
+	return t.repo.GetExceptions(t.ctx, eventID, startTime, endTime)
+}
+
+func (t *memoryTransaction) GetExceptionsByOccurrenceId(occurrenceID uuid.UUID) ([]EventException, error) {
+	return t.repo.GetExceptionsByOccurrenceId(t.ctx, occurrenceID)
+}
+
+func (t *memoryTransaction) GetOccurrences(eventID uuid.UUID, startTime, endTime time.Time) ([]EventOccurrence, error) {
+	return t.repo.GetOccurrences(t.ctx, eventID, startTime, endTime)
+}
@@ -2,8 +2,10 @@ package calendar
 
 import (
 	"database/sql/driver"
+	"net/url"
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
@@ -18,6 +20,11 @@ const (
 	EventTypeTodo     EventType = "Todo"
 	EventTypeHoliday  EventType = "Holiday"
 	EventTypeReminder EventType = "Reminder"
+	// EventTypeWorkingLocation says where the user works during the event. It never makes
+	// them busy.
+	EventTypeWorkingLocation EventType = "WorkingLocation"
+	// EventTypeOutOfOffice marks the user away. Invites during it are declined or flagged.
+	EventTypeOutOfOffice EventType = "OutOfOffice"
 )
 
 type RecurrenceType string
@@ -43,9 +50,28 @@ const (
 type NotificationMethod string
 
 const (
-	NotificationMethodEmail NotificationMethod = "Email"
-	NotificationMethodPush  NotificationMethod = "Push"
-	NotificationMethodSMS   NotificationMethod = "SMS"
+	NotificationMethodEmail   NotificationMethod = "Email"
+	NotificationMethodPush    NotificationMethod = "Push"
+	NotificationMethodSMS     NotificationMethod = "SMS"
+	NotificationMethodWebhook NotificationMethod = "Webhook"
+)
+
+// DeliveryStatus tracks whether a reminder reached its channel
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "Pending"
+	DeliveryStatusSent    DeliveryStatus = "Sent"
+	DeliveryStatusFailed  DeliveryStatus = "Failed"
+	DeliveryStatusSkipped DeliveryStatus = "Skipped"
+)
+
+// WorkingLocation is where a working location event places the user
+type WorkingLocation string
+
+const (
+	WorkingLocationHome   WorkingLocation = "home"
+	WorkingLocationOffice WorkingLocation = "office"
 )
 
 type Transparency string
@@ -81,6 +107,43 @@ func (a *Int64Array) Scan(src interface{}) error {
 	return (*pq.Int64Array)(a).Scan(src)
 }
 
+// TimeArray stores times in a PostgreSQL text array as RFC 3339 strings
+type TimeArray []time.Time
+
+// Value implements the driver.Valuer interface
+func (a TimeArray) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	values := make(pq.StringArray, len(a))
+	for i, t := range a {
+		values[i] = t.UTC().Format(time.RFC3339)
+	}
+	return values.Value()
+}
+
+// Scan implements the sql.Scanner interface
+func (a *TimeArray) Scan(src interface{}) error {
+	var values pq.StringArray
+	if err := values.Scan(src); err != nil {
+		return err
+	}
+	if values == nil {
+		*a = nil
+		return nil
+	}
+	times := make(TimeArray, len(values))
+	for i, value := range values {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		times[i] = t
+	}
+	*a = times
+	return nil
+}
+
 // EventCollaborator represents a user collaborating on a calendar event (sharing, invitation, permissions)
 type EventCollaborator struct {
 	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
@@ -91,8 +154,10 @@ type EventCollaborator struct {
 	InvitedBy   uuid.UUID  `json:"invited_by" gorm:"type:uuid;not null"`
 	InvitedAt   time.Time  `json:"invited_at" gorm:"not null;default:current_timestamp"`
 	RespondedAt *time.Time `json:"responded_at"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
-	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+	// OutOfOffice is set when the invitee was out of office for the event when invited
+	OutOfOffice bool      `json:"out_of_office" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
 }
 
 // CalendarEvent represents a calendar event or series
@@ -108,8 +173,16 @@ type CalendarEvent struct {
 	Location     string       `json:"location,omitempty" gorm:"type:varchar(255)"`
 	Color        string       `json:"color,omitempty" gorm:"type:varchar(7)"`
 	Transparency Transparency `json:"transparency" gorm:"type:varchar(20);not null;default:'opaque'"`
-	CreatedAt    time.Time    `json:"created_at" gorm:"not null;default:current_timestamp"`
-	UpdatedAt    time.Time    `json:"updated_at" gorm:"not null;default:current_timestamp"`
+	// WorkingLocation is set on working location events
+	WorkingLocation WorkingLocation `json:"working_location,omitempty" gorm:"type:varchar(20)"`
+	// DeclineInvites makes an out-of-office event decline invites to meetings during it
+	// instead of only flagging them
+	DeclineInvites bool `json:"decline_invites,omitempty" gorm:"not null;default:false"`
+	// Timezone is the zone the event was created in. Times are stored in UTC; the
+	// series repeats at the same local time in this zone.
+	Timezone  string    `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
 
 	// Relationships (for preload fun)
 	RecurrenceRules []RecurrenceRule     `json:"recurrence_rules,omitempty" gorm:"foreignKey:EventID"`
@@ -117,6 +190,7 @@ type CalendarEvent struct {
 	Exceptions      []EventException     `json:"exceptions,omitempty" gorm:"foreignKey:EventID"`
 	Reminders       []EventReminder      `json:"reminders,omitempty" gorm:"foreignKey:EventID"`
 	Collaborators   []EventCollaborator  `json:"collaborators,omitempty" gorm:"foreignKey:EventID"`
+	Attendees       []EventAttendee      `json:"attendees,omitempty" gorm:"foreignKey:EventID"`
 }
 
 // RecurrenceRule represents the recurrence pattern for a calendar event
@@ -130,8 +204,20 @@ type RecurrenceRule struct {
 	ByMonthDay Int64Array     `json:"by_month_day,omitempty" gorm:"type:integer[]"`
 	Count      *int           `json:"count,omitempty"`
 	Until      *time.Time     `json:"until,omitempty"`
-	CreatedAt  time.Time      `json:"created_at" gorm:"not null;default:current_timestamp"`
-	UpdatedAt  time.Time      `json:"updated_at" gorm:"not null;default:current_timestamp"`
+	BySetPos   Int64Array     `json:"by_set_pos,omitempty" gorm:"type:integer[]"`
+	ByWeekNo   Int64Array     `json:"by_week_no,omitempty" gorm:"type:integer[]"`
+	WeekStart  string         `json:"week_start,omitempty" gorm:"type:varchar(2)"`
+	// RRule is the rule as an RFC 5545 RRULE, which occurrences are generated from; the
+	// fields above mirror it. Rules stored before it are generated from the fields.
+	RRule string `json:"rrule,omitempty" gorm:"type:text"`
+	// RDates adds occurrences to the series and ExDates removes them
+	RDates  TimeArray `json:"rdates,omitempty" gorm:"type:text[]"`
+	ExDates TimeArray `json:"exdates,omitempty" gorm:"type:text[]"`
+	// Timezone is the zone occurrences are generated in, so days and times of day
+	// hold across DST changes
+	Timezone  string    `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
 }
 
 // EventOccurrence represents a single instance of a recurring event
@@ -179,8 +265,31 @@ type EventReminder struct {
 	EventID       uuid.UUID          `json:"event_id" gorm:"type:uuid;not null;index:idx_reminder_event"`
 	MinutesBefore int                `json:"minutes_before" gorm:"not null"`
 	Method        NotificationMethod `json:"method" gorm:"type:varchar(50);not null"`
+	WebhookURL    string             `json:"webhook_url,omitempty" gorm:"type:varchar(2048)"`
 	CreatedAt     time.Time          `json:"created_at" gorm:"not null;default:current_timestamp"`
 	UpdatedAt     time.Time          `json:"updated_at" gorm:"not null;default:current_timestamp"`
+
+	// Outcome of the most recent delivery, across all occurrences
+	LastStatus      DeliveryStatus `json:"last_status,omitempty" gorm:"type:varchar(20)"`
+	LastDeliveredAt *time.Time     `json:"last_delivered_at,omitempty"`
+	LastError       string         `json:"last_error,omitempty" gorm:"type:text"`
+}
+
+// ReminderDelivery records a reminder sent for one occurrence of an event. The unique
+// index on reminder and occurrence keeps a reminder from firing twice for the same occurrence.
+type ReminderDelivery struct {
+	ID             uuid.UUID          `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ReminderID     uuid.UUID          `json:"reminder_id" gorm:"type:uuid;not null;uniqueIndex:idx_reminder_delivery_occurrence,priority:1"`
+	EventID        uuid.UUID          `json:"event_id" gorm:"type:uuid;not null;index:idx_reminder_delivery_event"`
+	UserID         uuid.UUID          `json:"user_id" gorm:"type:uuid;not null"`
+	OccurrenceTime time.Time          `json:"occurrence_time" gorm:"not null;uniqueIndex:idx_reminder_delivery_occurrence,priority:2"`
+	FireAt         time.Time          `json:"fire_at" gorm:"not null"`
+	Method         NotificationMethod `json:"method" gorm:"type:varchar(50);not null"`
+	Status         DeliveryStatus     `json:"status" gorm:"type:varchar(20);not null;default:'Pending'"`
+	Error          string             `json:"error,omitempty" gorm:"type:text"`
+	SentAt         *time.Time         `json:"sent_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time          `json:"updated_at" gorm:"not null;default:current_timestamp"`
 }
 
 // TableName specifies the table names for each model
@@ -190,6 +299,8 @@ func (EventOccurrence) TableName() string   { return "event_occurrences" }
 func (EventException) TableName() string    { return "event_exceptions" }
 func (EventReminder) TableName() string     { return "event_reminders" }
 func (EventCollaborator) TableName() string { return "event_collaborators" }
+func (EventAttendee) TableName() string     { return "event_attendees" }
+func (ReminderDelivery) TableName() string  { return "reminder_deliveries" }
 
 // BeforeCreate hooks for UUID generation
 func (e *CalendarEvent) BeforeCreate(tx *gorm.DB) error {
@@ -227,6 +338,15 @@ func (r *EventReminder) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (d *ReminderDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	d.CreatedAt = time.Now()
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
 // BeforeCreate hook for EventCollaborator
 func (c *EventCollaborator) BeforeCreate(tx *gorm.DB) error {
 	if c.ID == uuid.Nil {
@@ -240,6 +360,16 @@ func (c *EventCollaborator) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeCreate hook for EventAttendee
+func (a *EventAttendee) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	a.CreatedAt = time.Now()
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
 // Request/Response DTOs
 type CreateCalendarEventRequest struct {
 	Title        string       `json:"title" binding:"required"`
@@ -251,6 +381,12 @@ type CreateCalendarEventRequest struct {
 	Location     string       `json:"location"`
 	Color        string       `json:"color"`
 	Transparency Transparency `json:"transparency"`
+	// WorkingLocation is required for WorkingLocation events
+	WorkingLocation WorkingLocation `json:"working_location,omitempty"`
+	// DeclineInvites applies to OutOfOffice events
+	DeclineInvites bool `json:"decline_invites,omitempty"`
+	// Timezone defaults to the creator's timezone preference
+	Timezone string `json:"timezone,omitempty" example:"Europe/Berlin"`
 
 	// Optional recurrence
 	RecurrenceRule *CreateRecurrenceRuleRequest `json:"recurrence_rule,omitempty"`
@@ -259,31 +395,43 @@ type CreateCalendarEventRequest struct {
 }
 
 type CreateRecurrenceRuleRequest struct {
-	Freq       RecurrenceType `json:"freq" binding:"required"`
-	Interval   int            `json:"interval" binding:"required,min=1"`
+	Freq       RecurrenceType `json:"freq" binding:"required_without=RRule"`
+	Interval   int            `json:"interval" binding:"omitempty,min=1"`
 	ByDay      []string       `json:"by_day,omitempty"`
 	ByMonth    []int          `json:"by_month,omitempty"`
 	ByMonthDay []int          `json:"by_month_day,omitempty"`
+	BySetPos   []int          `json:"by_set_pos,omitempty"`
+	ByWeekNo   []int          `json:"by_week_no,omitempty"`
+	WeekStart  string         `json:"week_start,omitempty"`
 	Count      *int           `json:"count,omitempty"`
 	Until      *time.Time     `json:"until,omitempty"`
+	// RRule is an RFC 5545 RRULE and replaces the fields above when given
+	RRule   string      `json:"rrule,omitempty" example:"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"`
+	RDates  []time.Time `json:"rdates,omitempty"`
+	ExDates []time.Time `json:"exdates,omitempty"`
 }
 
 type CreateEventReminderRequest struct {
 	MinutesBefore int                `json:"minutes_before" binding:"required,min=0"`
 	Method        NotificationMethod `json:"method" binding:"required"`
+	// WebhookURL receives the reminder when Method is Webhook
+	WebhookURL string `json:"webhook_url,omitempty"`
 }
 
 type UpdateCalendarEventRequest struct {
-	Title                *string       `json:"title,omitempty"`
-	Description          *string       `json:"description,omitempty"`
-	EventType            *EventType    `json:"event_type,omitempty"`
-	StartTime            *time.Time    `json:"start_time,omitempty"`
-	EndTime              *time.Time    `json:"end_time,omitempty"`
-	IsAllDay             *bool         `json:"is_all_day,omitempty"`
-	Location             *string       `json:"location,omitempty"`
-	Color                *string       `json:"color,omitempty"`
-	Transparency         *Transparency `json:"transparency,omitempty"`
-	PreserveDateSequence *bool         `json:"preserve_date_sequence,omitempty"`
+	Title                *string          `json:"title,omitempty"`
+	Description          *string          `json:"description,omitempty"`
+	EventType            *EventType       `json:"event_type,omitempty"`
+	StartTime            *time.Time       `json:"start_time,omitempty"`
+	EndTime              *time.Time       `json:"end_time,omitempty"`
+	IsAllDay             *bool            `json:"is_all_day,omitempty"`
+	Location             *string          `json:"location,omitempty"`
+	Color                *string          `json:"color,omitempty"`
+	Transparency         *Transparency    `json:"transparency,omitempty"`
+	WorkingLocation      *WorkingLocation `json:"working_location,omitempty"`
+	DeclineInvites       *bool            `json:"decline_invites,omitempty"`
+	Timezone             *string          `json:"timezone,omitempty"`
+	PreserveDateSequence *bool            `json:"preserve_date_sequence,omitempty"`
 }
 
 type CalendarEventResponse struct {
@@ -293,16 +441,22 @@ type CalendarEventResponse struct {
 
 type CalendarEventListResponse struct {
 	Events []CalendarEvent `json:"events"`
-	Total  int64           `json:"total"`
+	// Total is only counted for offset pages
+	Total int64 `json:"total"`
+	// NextCursor continues a keyset listing; it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // Common errors
 var (
-	ErrInvalidEventType    = NewError("invalid event type")
-	ErrInvalidTimeRange    = NewError("end time must be after start time")
-	ErrInvalidRecurrence   = NewError("invalid recurrence configuration")
-	ErrInvalidReminderTime = NewError("invalid reminder time")
-	ErrInvalidTransparency = NewError("invalid transparency value")
+	ErrInvalidEventType       = NewError("invalid event type")
+	ErrInvalidTimeRange       = NewError("end time must be after start time")
+	ErrInvalidRecurrence      = NewError("invalid recurrence configuration")
+	ErrInvalidReminderTime    = NewError("invalid reminder time")
+	ErrInvalidTransparency    = NewError("invalid transparency value")
+	ErrInvalidWebhookURL      = NewError("webhook reminders need an https webhook_url")
+	ErrInvalidWorkingLocation = NewError("working location events need a working_location of home or office")
+	ErrInvalidTimezone        = NewError("invalid timezone")
 )
 
 // Error type
@@ -332,9 +486,34 @@ func (e *CalendarEvent) Validate() error {
 	if !isValidTransparency(e.Transparency) {
 		return ErrInvalidTransparency
 	}
+	if e.EventType == EventTypeWorkingLocation &&
+		e.WorkingLocation != WorkingLocationHome && e.WorkingLocation != WorkingLocationOffice {
+		return ErrInvalidWorkingLocation
+	}
 	return nil
 }
 
+// PageCursor is the position of the event in keyset pages, which follow start time
+func (e *CalendarEvent) PageCursor() pagination.Cursor {
+	return pagination.Cursor{Time: e.StartTime, ID: e.ID}
+}
+
+// applyStatusType fits the event to its type: working locations never make the user
+// busy, out-of-office events always do, and other events carry neither setting
+func (e *CalendarEvent) applyStatusType() {
+	switch e.EventType {
+	case EventTypeWorkingLocation:
+		e.Transparency = TransparencyTransparent
+		e.DeclineInvites = false
+	case EventTypeOutOfOffice:
+		e.Transparency = TransparencyOpaque
+		e.WorkingLocation = ""
+	default:
+		e.WorkingLocation = ""
+		e.DeclineInvites = false
+	}
+}
+
 func (r *RecurrenceRule) Validate() error {
 	if !isValidRecurrenceType(r.Freq) {
 		return ErrInvalidRecurrence
@@ -348,7 +527,8 @@ func (r *RecurrenceRule) Validate() error {
 	if r.Until != nil && r.Until.Before(time.Now()) {
 		return NewError("until date must be in the future")
 	}
-	return nil
+	_, err := r.rrule()
+	return err
 }
 
 func (r *EventReminder) Validate() error {
@@ -358,6 +538,12 @@ func (r *EventReminder) Validate() error {
 	if !isValidNotificationMethod(r.Method) {
 		return NewError("invalid notification method")
 	}
+	if r.Method == NotificationMethodWebhook {
+		u, err := url.Parse(r.WebhookURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return ErrInvalidWebhookURL
+		}
+	}
 	return nil
 }
 
@@ -365,7 +551,7 @@ func (r *EventReminder) Validate() error {
 func isValidEventType(t EventType) bool {
 	switch t {
 	case EventTypeNone, EventTypeTask, EventTypeMeeting, EventTypeTodo,
-		EventTypeHoliday, EventTypeReminder:
+		EventTypeHoliday, EventTypeReminder, EventTypeWorkingLocation, EventTypeOutOfOffice:
 		return true
 	}
 	return false
@@ -383,7 +569,7 @@ func isValidRecurrenceType(t RecurrenceType) bool {
 
 func isValidNotificationMethod(m NotificationMethod) bool {
 	switch m {
-	case NotificationMethodEmail, NotificationMethodPush, NotificationMethodSMS:
+	case NotificationMethodEmail, NotificationMethodPush, NotificationMethodSMS, NotificationMethodWebhook:
 		return true
 	}
 	return false
@@ -0,0 +1,112 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/google/uuid"
+)
+
+// ReminderMessage describes one reminder for one occurrence of an event
+type ReminderMessage struct {
+	Reminder       EventReminder
+	Event          *CalendarEvent
+	UserID         uuid.UUID
+	OccurrenceTime time.Time
+	Title          string
+	Location       string
+}
+
+// ReminderChannel delivers reminders for one notification method
+type ReminderChannel interface {
+	Method() NotificationMethod
+	Send(ctx context.Context, msg ReminderMessage) error
+}
+
+// notifierChannel delivers reminders through the notification system
+type notifierChannel struct {
+	notifier notification.DomainNotifier
+	method   NotificationMethod
+	delivery notification.DeliveryMethod
+}
+
+// NewEmailReminderChannel sends reminders as email notifications, also shown in-app
+func NewEmailReminderChannel(notifier notification.DomainNotifier) ReminderChannel {
+	return &notifierChannel{notifier: notifier, method: NotificationMethodEmail, delivery: notification.Email}
+}
+
+// NewPushReminderChannel sends reminders as push notifications, also shown in-app
+func NewPushReminderChannel(notifier notification.DomainNotifier) ReminderChannel {
+	return &notifierChannel{notifier: notifier, method: NotificationMethodPush, delivery: notification.Push}
+}
+
+func (c *notifierChannel) Method() NotificationMethod {
+	return c.method
+}
+
+func (c *notifierChannel) Send(ctx context.Context, msg ReminderMessage) error {
+	content := fmt.Sprintf("%s starts at %s", msg.Title, msg.OccurrenceTime.UTC().Format(time.RFC1123))
+	if msg.Location != "" {
+		content += " at " + msg.Location
+	}
+	data := map[string]string{
+		"event_id":        msg.Event.ID.String(),
+		"reminder_id":     msg.Reminder.ID.String(),
+		"occurrence_time": msg.OccurrenceTime.UTC().Format(time.RFC3339),
+	}
+	return c.notifier.NotifyUserWithDelivery(ctx, msg.UserID, notification.Reminder, "Upcoming: "+msg.Title, content,
+		data, "calendar", msg.Event.ID, []notification.DeliveryMethod{notification.InApp, c.delivery})
+}
+
+// webhookChannel posts reminders as JSON to the reminder's webhook URL
+type webhookChannel struct {
+	client *http.Client
+}
+
+// NewWebhookReminderChannel posts reminders to the URL configured on each reminder. Like
+// webhook deliveries it never calls internal addresses.
+func NewWebhookReminderChannel() ReminderChannel {
+	return &webhookChannel{client: webhooks.NewClient(10 * time.Second)}
+}
+
+func (c *webhookChannel) Method() NotificationMethod {
+	return NotificationMethodWebhook
+}
+
+func (c *webhookChannel) Send(ctx context.Context, msg ReminderMessage) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"type":            "calendar.reminder",
+		"reminder_id":     msg.Reminder.ID,
+		"event_id":        msg.Event.ID,
+		"user_id":         msg.UserID,
+		"title":           msg.Title,
+		"location":        msg.Location,
+		"occurrence_time": msg.OccurrenceTime.UTC(),
+		"minutes_before":  msg.Reminder.MinutesBefore,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Reminder.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
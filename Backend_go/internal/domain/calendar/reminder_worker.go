@@ -0,0 +1,262 @@
+package calendar
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReminderWorkerConfig holds settings for the reminder dispatch worker
+type ReminderWorkerConfig struct {
+	// PollInterval is how often due reminders are looked up
+	PollInterval time.Duration
+	// Lookback is how far back a poll looks for reminders, so reminders due while
+	// the worker was down are still sent
+	Lookback time.Duration
+	// SendTimeout bounds a single delivery
+	SendTimeout time.Duration
+}
+
+// DefaultReminderWorkerConfig returns the default reminder worker configuration
+func DefaultReminderWorkerConfig() ReminderWorkerConfig {
+	return ReminderWorkerConfig{
+		PollInterval: time.Minute,
+		Lookback:     15 * time.Minute,
+		SendTimeout:  15 * time.Second,
+	}
+}
+
+// occurrenceSlack widens occurrence lookups so that occurrences moved by an exception are found
+const occurrenceSlack = 24 * time.Hour
+
+// ReminderWorker sends event reminders when they fall due, once per occurrence.
+// Deliveries are claimed in the database, so several API instances can run a worker.
+type ReminderWorker struct {
+	repo     Repository
+	channels map[NotificationMethod]ReminderChannel
+	config   ReminderWorkerConfig
+	logger   *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewReminderWorker creates a new reminder dispatch worker
+func NewReminderWorker(repo Repository, channels []ReminderChannel, config ReminderWorkerConfig, logger *zap.Logger) *ReminderWorker {
+	byMethod := make(map[NotificationMethod]ReminderChannel, len(channels))
+	for _, ch := range channels {
+		byMethod[ch.Method()] = ch
+	}
+	return &ReminderWorker{
+		repo:     repo,
+		channels: byMethod,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Start begins dispatching reminders in the background
+func (w *ReminderWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			w.Dispatch(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the current poll and stops the worker
+func (w *ReminderWorker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+// Dispatch sends every reminder due between now-Lookback and now
+func (w *ReminderWorker) Dispatch(ctx context.Context, now time.Time) {
+	from := now.Add(-w.config.Lookback)
+	reminders, err := w.repo.GetUpcomingReminders(ctx, from, now)
+	if err != nil {
+		w.logger.Error("Failed to load upcoming reminders", zap.Error(err))
+		return
+	}
+
+	events := make(map[string]*CalendarEvent)
+	for _, reminder := range reminders {
+		if ctx.Err() != nil {
+			return
+		}
+
+		key := reminder.EventID.String()
+		event, ok := events[key]
+		if !ok {
+			if event, err = w.repo.GetEventByID(ctx, reminder.EventID); err != nil {
+				w.logger.Warn("Failed to load event for reminder",
+					zap.String("reminder_id", reminder.ID.String()),
+					zap.Error(err))
+				continue
+			}
+			events[key] = event
+		}
+
+		occurrences, err := w.dueOccurrences(ctx, event, reminder, from, now)
+		if err != nil {
+			w.logger.Warn("Failed to expand event occurrences",
+				zap.String("event_id", event.ID.String()),
+				zap.Error(err))
+			continue
+		}
+		for _, occ := range occurrences {
+			w.deliver(ctx, event, reminder, occ)
+		}
+	}
+}
+
+// dueOccurrence is an occurrence whose reminder falls due in the polled window
+type dueOccurrence struct {
+	originalTime time.Time
+	startTime    time.Time
+	title        string
+	location     string
+}
+
+// dueOccurrences expands an event into the occurrences whose reminder fires between from and to,
+// skipping deleted and cancelled occurrences and applying rescheduled times
+func (w *ReminderWorker) dueOccurrences(ctx context.Context, event *CalendarEvent, reminder EventReminder, from, to time.Time) ([]dueOccurrence, error) {
+	lead := time.Duration(reminder.MinutesBefore) * time.Minute
+	isDue := func(start time.Time) bool {
+		fireAt := start.Add(-lead)
+		return !fireAt.Before(from) && !fireAt.After(to)
+	}
+
+	if len(event.RecurrenceRules) == 0 {
+		if !isDue(event.StartTime) {
+			return nil, nil
+		}
+		return []dueOccurrence{{
+			originalTime: event.StartTime,
+			startTime:    event.StartTime,
+			title:        event.Title,
+			location:     event.Location,
+		}}, nil
+	}
+
+	windowStart := from.Add(lead - occurrenceSlack)
+	windowEnd := to.Add(lead + occurrenceSlack)
+	occurrences, err := w.repo.GetOccurrences(ctx, event.ID, windowStart, windowEnd)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := w.repo.GetExceptions(ctx, event.ID, windowStart, windowEnd)
+	if err != nil {
+		return nil, err
+	}
+	exceptionMap := make(map[time.Time]*EventException)
+	for i := range exceptions {
+		exceptionMap[exceptions[i].OriginalTime] = &exceptions[i]
+	}
+
+	var due []dueOccurrence
+	for _, occ := range occurrences {
+		if occ.Status == OccurrenceStatusCancelled {
+			continue
+		}
+		d := dueOccurrence{
+			originalTime: occ.OccurrenceTime,
+			startTime:    occ.OccurrenceTime,
+			title:        event.Title,
+			location:     event.Location,
+		}
+		if exception, exists := exceptionMap[occ.OccurrenceTime]; exists {
+			if exception.IsDeleted {
+				continue
+			}
+			if exception.OverrideStartTime != nil {
+				d.startTime = *exception.OverrideStartTime
+			}
+			if exception.OverrideTitle != nil {
+				d.title = *exception.OverrideTitle
+			}
+			if exception.OverrideLocation != nil {
+				d.location = *exception.OverrideLocation
+			}
+		}
+		if isDue(d.startTime) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (w *ReminderWorker) deliver(ctx context.Context, event *CalendarEvent, reminder EventReminder, occ dueOccurrence) {
+	delivery := &ReminderDelivery{
+		ReminderID:     reminder.ID,
+		EventID:        event.ID,
+		UserID:         event.UserID,
+		OccurrenceTime: occ.originalTime,
+		FireAt:         occ.startTime.Add(-time.Duration(reminder.MinutesBefore) * time.Minute),
+		Method:         reminder.Method,
+		Status:         DeliveryStatusPending,
+	}
+	claimed, err := w.repo.ClaimReminderDelivery(ctx, delivery)
+	if err != nil {
+		w.logger.Error("Failed to claim reminder delivery",
+			zap.String("reminder_id", reminder.ID.String()),
+			zap.Error(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	channel, ok := w.channels[reminder.Method]
+	if !ok {
+		delivery.Status = DeliveryStatusSkipped
+		delivery.Error = "no channel configured for " + string(reminder.Method)
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, w.config.SendTimeout)
+		err := channel.Send(sendCtx, ReminderMessage{
+			Reminder:       reminder,
+			Event:          event,
+			UserID:         event.UserID,
+			OccurrenceTime: occ.startTime,
+			Title:          occ.title,
+			Location:       occ.location,
+		})
+		cancel()
+
+		if err != nil {
+			delivery.Status = DeliveryStatusFailed
+			delivery.Error = err.Error()
+			w.logger.Warn("Failed to send reminder",
+				zap.String("reminder_id", reminder.ID.String()),
+				zap.String("method", string(reminder.Method)),
+				zap.Error(err))
+		} else {
+			sentAt := time.Now()
+			delivery.Status = DeliveryStatusSent
+			delivery.SentAt = &sentAt
+		}
+	}
+
+	// Record the outcome even if the worker is stopping
+	if err := w.repo.CompleteReminderDelivery(context.Background(), delivery); err != nil {
+		w.logger.Error("Failed to record reminder delivery",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Error(err))
+	}
+}
@@ -2,10 +2,13 @@ package calendar
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository interface defines the data access methods for calendar events
@@ -43,6 +46,8 @@ type Repository interface {
 	UpdateReminder(ctx context.Context, reminder *EventReminder) error
 	DeleteReminder(ctx context.Context, id uuid.UUID) error
 	GetUpcomingReminders(ctx context.Context, startTime, endTime time.Time) ([]EventReminder, error)
+	ClaimReminderDelivery(ctx context.Context, delivery *ReminderDelivery) (bool, error)
+	CompleteReminderDelivery(ctx context.Context, delivery *ReminderDelivery) error
 
 	// Collaborator operations
 	AddCollaborator(ctx context.Context, collaborator *EventCollaborator) error
@@ -51,6 +56,13 @@ type Repository interface {
 	ListEventsSharedWithUser(ctx context.Context, userID uuid.UUID) ([]CalendarEvent, error)
 	UpdateCollaboratorStatus(ctx context.Context, eventID, userID uuid.UUID, status string, respondedAt *time.Time) error
 	GetCollaborator(ctx context.Context, eventID, userID uuid.UUID) (*EventCollaborator, error)
+
+	// Attendee operations
+	AddAttendee(ctx context.Context, attendee *EventAttendee) error
+	GetAttendee(ctx context.Context, id uuid.UUID) (*EventAttendee, error)
+	ListAttendeesByEventID(ctx context.Context, eventID uuid.UUID) ([]EventAttendee, error)
+	UpdateAttendeeStatus(ctx context.Context, id uuid.UUID, status AttendeeStatus, comment string, respondedAt time.Time) error
+	RemoveAttendee(ctx context.Context, id uuid.UUID) error
 }
 
 // Transaction represents a database transaction
@@ -59,6 +71,7 @@ type Transaction interface {
 	Rollback() error
 	CreateEvent(event *CalendarEvent) error
 	CreateRecurrenceRule(rule *RecurrenceRule) error
+	UpdateRecurrenceRule(rule *RecurrenceRule) error
 	CreateOccurrence(occurrence *EventOccurrence) error
 	UpdateOccurrence(occurrence *EventOccurrence) error
 	CreateReminder(reminder *EventReminder) error
@@ -79,6 +92,8 @@ type EventFilter struct {
 	Search    string
 	Page      int
 	PageSize  int
+	// Keyset pages by start time instead of Page and PageSize, without counting the total
+	Keyset *pagination.Keyset
 }
 
 // repository implements the Repository interface
@@ -101,6 +116,7 @@ func (r *repository) GetEventByID(ctx context.Context, id uuid.UUID) (*CalendarE
 	err := r.db.WithContext(ctx).
 		Preload("RecurrenceRules").
 		Preload("Reminders").
+		Preload("Attendees").
 		First(&event, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -127,6 +143,9 @@ func (r *repository) DeleteEvent(ctx context.Context, id uuid.UUID) error {
 		if err := tx.Where("event_id = ?", id).Delete(&EventReminder{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("event_id = ?", id).Delete(&EventAttendee{}).Error; err != nil {
+			return err
+		}
 		// Delete the event itself
 		return tx.Delete(&CalendarEvent{}, id).Error
 	})
@@ -171,13 +190,15 @@ func (r *repository) ListEvents(ctx context.Context, filter EventFilter) ([]Cale
 			"%"+filter.Search+"%", "%"+filter.Search+"%")
 	}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
+	// Get total count, which keyset pages skip
+	if filter.Keyset != nil {
+		query = query.Scopes(filter.Keyset.Scope("start_time", "id"))
+	} else if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Apply pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
+	if filter.Keyset == nil && filter.Page > 0 && filter.PageSize > 0 {
 		offset := (filter.Page - 1) * filter.PageSize
 		query = query.Offset(offset).Limit(filter.PageSize)
 	}
@@ -186,6 +207,7 @@ func (r *repository) ListEvents(ctx context.Context, filter EventFilter) ([]Cale
 	err := query.
 		Preload("RecurrenceRules").
 		Preload("Reminders").
+		Preload("Attendees").
 		Find(&events).Error
 
 	return events, total, err
@@ -250,15 +272,61 @@ func (r *repository) DeleteReminder(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&EventReminder{}, id).Error
 }
 
+// GetUpcomingReminders returns the reminders due to fire between startTime and endTime,
+// for the event itself or for any occurrence of a recurring event
 func (r *repository) GetUpcomingReminders(ctx context.Context, startTime, endTime time.Time) ([]EventReminder, error) {
 	var reminders []EventReminder
 	err := r.db.WithContext(ctx).
 		Joins("JOIN calendar_events ON calendar_events.id = event_reminders.event_id").
-		Where("calendar_events.start_time BETWEEN ? AND ?", startTime, endTime).
+		Where(`calendar_events.start_time - event_reminders.minutes_before * interval '1 minute' BETWEEN @start AND @end
+			OR EXISTS (
+				SELECT 1 FROM event_occurrences
+				WHERE event_occurrences.event_id = calendar_events.id
+				AND event_occurrences.occurrence_time - event_reminders.minutes_before * interval '1 minute' BETWEEN @start AND @end
+			)
+			OR EXISTS (
+				SELECT 1 FROM event_exceptions
+				WHERE event_exceptions.event_id = calendar_events.id
+				AND event_exceptions.override_start_time - event_reminders.minutes_before * interval '1 minute' BETWEEN @start AND @end
+			)`, sql.Named("start", startTime), sql.Named("end", endTime)).
 		Find(&reminders).Error
 	return reminders, err
 }
 
+// ClaimReminderDelivery stores a pending delivery. It returns false when the reminder was
+// already claimed for the occurrence, so that only one worker sends it.
+func (r *repository) ClaimReminderDelivery(ctx context.Context, delivery *ReminderDelivery) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CompleteReminderDelivery records the outcome of a delivery on it and on its reminder
+func (r *repository) CompleteReminderDelivery(ctx context.Context, delivery *ReminderDelivery) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(delivery).Updates(map[string]interface{}{
+			"status":     delivery.Status,
+			"error":      delivery.Error,
+			"sent_at":    delivery.SentAt,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"last_status": delivery.Status,
+			"last_error":  delivery.Error,
+		}
+		if delivery.SentAt != nil {
+			updates["last_delivered_at"] = delivery.SentAt
+		}
+		return tx.Model(&EventReminder{}).Where("id = ?", delivery.ReminderID).Updates(updates).Error
+	})
+}
+
 func (r *repository) BeginTransaction(ctx context.Context) Transaction {
 	tx := r.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -287,6 +355,10 @@ func (t *transaction) CreateRecurrenceRule(rule *RecurrenceRule) error {
 	return t.tx.Create(rule).Error
 }
 
+func (t *transaction) UpdateRecurrenceRule(rule *RecurrenceRule) error {
+	return t.tx.Save(rule).Error
+}
+
 func (t *transaction) CreateOccurrence(occurrence *EventOccurrence) error {
 	return t.tx.Create(occurrence).Error
 }
@@ -389,6 +461,7 @@ func (r *repository) ListEventsSharedWithUser(ctx context.Context, userID uuid.U
 		Where("event_collaborators.user_id = ? AND event_collaborators.status = ?", userID, "accepted").
 		Preload("RecurrenceRules").
 		Preload("Reminders").
+		Preload("Attendees").
 		Find(&events).Error
 	return events, err
 }
@@ -413,6 +486,40 @@ func (r *repository) GetCollaborator(ctx context.Context, eventID, userID uuid.U
 	return &collaborator, nil
 }
 
+func (r *repository) AddAttendee(ctx context.Context, attendee *EventAttendee) error {
+	return r.db.WithContext(ctx).Create(attendee).Error
+}
+
+func (r *repository) GetAttendee(ctx context.Context, id uuid.UUID) (*EventAttendee, error) {
+	var attendee EventAttendee
+	if err := r.db.WithContext(ctx).First(&attendee, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+func (r *repository) ListAttendeesByEventID(ctx context.Context, eventID uuid.UUID) ([]EventAttendee, error) {
+	var attendees []EventAttendee
+	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Order("created_at ASC").Find(&attendees).Error
+	return attendees, err
+}
+
+func (r *repository) UpdateAttendeeStatus(ctx context.Context, id uuid.UUID, status AttendeeStatus, comment string, respondedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&EventAttendee{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"comment":      comment,
+			"responded_at": respondedAt,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+func (r *repository) RemoveAttendee(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&EventAttendee{}, "id = ?", id).Error
+}
+
 func (r *repository) FindAll(ctx context.Context, filter EventFilter) ([]CalendarEvent, int64, error) {
 	var events []CalendarEvent
 	var total int64
@@ -452,13 +559,15 @@ func (r *repository) FindAll(ctx context.Context, filter EventFilter) ([]Calenda
 			"%"+filter.Search+"%", "%"+filter.Search+"%")
 	}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
+	// Get total count, which keyset pages skip
+	if filter.Keyset != nil {
+		query = query.Scopes(filter.Keyset.Scope("start_time", "id"))
+	} else if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Apply pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
+	if filter.Keyset == nil && filter.Page > 0 && filter.PageSize > 0 {
 		offset := (filter.Page - 1) * filter.PageSize
 		query = query.Offset(offset).Limit(filter.PageSize)
 	}
@@ -467,6 +576,7 @@ func (r *repository) FindAll(ctx context.Context, filter EventFilter) ([]Calenda
 	err := query.
 		Preload("RecurrenceRules").
 		Preload("Reminders").
+		Preload("Attendees").
 		Find(&events).Error
 
 	return events, total, err
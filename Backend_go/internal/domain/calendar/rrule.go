@@ -0,0 +1,887 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Frequency is the FREQ of an RFC 5545 recurrence rule, from the longest period to the
+// shortest
+type Frequency int
+
+const (
+	FreqYearly Frequency = iota
+	FreqMonthly
+	FreqWeekly
+	FreqDaily
+	FreqHourly
+	FreqMinutely
+	FreqSecondly
+)
+
+var frequencyNames = [...]string{"YEARLY", "MONTHLY", "WEEKLY", "DAILY", "HOURLY", "MINUTELY", "SECONDLY"}
+
+func (f Frequency) String() string {
+	return frequencyNames[f]
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum is a BYDAY entry. N picks the nth such weekday of the month or year in
+// monthly and yearly rules, counting from the end when negative; zero means every one.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Weekday]
+}
+
+// RRule is a parsed RFC 5545 recurrence rule. Count and Until may both be set, in
+// which case the series ends at whichever is reached first.
+type RRule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	WeekStart  time.Weekday
+	BySetPos   []int
+	ByMonth    []int
+	ByWeekNo   []int
+	ByYearDay  []int
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	ByHour     []int
+	ByMinute   []int
+	BySecond   []int
+
+	// untilFloating marks an UNTIL without a timezone, which is read in the zone of the series
+	untilFloating bool
+}
+
+func rruleError(format string, args ...interface{}) *Error {
+	return NewError("invalid RRULE: " + fmt.Sprintf(format, args...))
+}
+
+// ParseRRule parses the value of an RRULE property, with or without the "RRULE:" name
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+
+	rule := &RRule{Interval: 1, WeekStart: time.Monday}
+	hasFreq := false
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if !ok || val == "" {
+			return nil, rruleError("%q is not NAME=VALUE", part)
+		}
+		if seen[name] {
+			return nil, rruleError("%s is given twice", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq, err = parseFrequency(val)
+			hasFreq = err == nil
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err != nil || rule.Interval < 1 {
+				return nil, rruleError("INTERVAL must be a positive number")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err != nil || rule.Count < 1 {
+				return nil, rruleError("COUNT must be a positive number")
+			}
+		case "UNTIL":
+			err = rule.parseUntil(val)
+		case "WKST":
+			rule.WeekStart, err = parseWeekday(val)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseInts(name, val)
+		case "BYMONTH":
+			rule.ByMonth, err = parseInts(name, val)
+		case "BYWEEKNO":
+			rule.ByWeekNo, err = parseInts(name, val)
+		case "BYYEARDAY":
+			rule.ByYearDay, err = parseInts(name, val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseInts(name, val)
+		case "BYDAY":
+			rule.ByDay, err = parseWeekdayNums(val)
+		case "BYHOUR":
+			rule.ByHour, err = parseInts(name, val)
+		case "BYMINUTE":
+			rule.ByMinute, err = parseInts(name, val)
+		case "BYSECOND":
+			rule.BySecond, err = parseInts(name, val)
+		default:
+			return nil, rruleError("unsupported rule part %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !hasFreq {
+		return nil, rruleError("FREQ is required")
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Validate checks the ranges of rule parts and the combinations RFC 5545 does not allow
+func (r *RRule) Validate() error {
+	if r.Interval < 1 {
+		return rruleError("INTERVAL must be a positive number")
+	}
+	ranges := []struct {
+		name     string
+		values   []int
+		min, max int
+		// negative values count from the end
+		negative bool
+	}{
+		{"BYSETPOS", r.BySetPos, 1, 366, true},
+		{"BYMONTH", r.ByMonth, 1, 12, false},
+		{"BYWEEKNO", r.ByWeekNo, 1, 53, true},
+		{"BYYEARDAY", r.ByYearDay, 1, 366, true},
+		{"BYMONTHDAY", r.ByMonthDay, 1, 31, true},
+		{"BYHOUR", r.ByHour, 0, 23, false},
+		{"BYMINUTE", r.ByMinute, 0, 59, false},
+		{"BYSECOND", r.BySecond, 0, 59, false},
+	}
+	for _, part := range ranges {
+		for _, v := range part.values {
+			abs := v
+			if part.negative && v < 0 {
+				abs = -v
+			}
+			if abs < part.min || abs > part.max {
+				return rruleError("%s value %d is out of range", part.name, v)
+			}
+		}
+	}
+	if len(r.ByWeekNo) > 0 && r.Freq != FreqYearly {
+		return rruleError("BYWEEKNO is only valid in YEARLY rules")
+	}
+	if len(r.ByYearDay) > 0 && (r.Freq == FreqMonthly || r.Freq == FreqWeekly || r.Freq == FreqDaily) {
+		return rruleError("BYYEARDAY is not valid in %s rules", r.Freq)
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == FreqWeekly {
+		return rruleError("BYMONTHDAY is not valid in WEEKLY rules")
+	}
+	for _, day := range r.ByDay {
+		if day.N == 0 {
+			continue
+		}
+		if r.Freq != FreqMonthly && r.Freq != FreqYearly {
+			return rruleError("numbered BYDAY values are only valid in MONTHLY and YEARLY rules")
+		}
+		if r.Freq == FreqYearly && len(r.ByWeekNo) > 0 {
+			return rruleError("numbered BYDAY values are not valid together with BYWEEKNO")
+		}
+	}
+	if len(r.BySetPos) > 0 && len(r.ByMonth)+len(r.ByWeekNo)+len(r.ByYearDay)+len(r.ByMonthDay)+
+		len(r.ByDay)+len(r.ByHour)+len(r.ByMinute)+len(r.BySecond) == 0 {
+		return rruleError("BYSETPOS needs another BYxxx rule part")
+	}
+	return nil
+}
+
+func (r *RRule) parseUntil(val string) error {
+	var err error
+	var until time.Time
+	switch {
+	case len(val) == 8:
+		// A date includes the whole day
+		until, err = time.Parse("20060102", val)
+		until = until.Add(24*time.Hour - time.Second)
+		r.untilFloating = true
+	case strings.HasSuffix(val, "Z"):
+		until, err = time.Parse("20060102T150405Z", val)
+	default:
+		until, err = time.Parse("20060102T150405", val)
+		r.untilFloating = true
+	}
+	if err != nil {
+		return rruleError("UNTIL %q is not a DATE or DATE-TIME", val)
+	}
+	r.Until = &until
+	return nil
+}
+
+func parseFrequency(val string) (Frequency, error) {
+	for f, name := range frequencyNames {
+		if name == val {
+			return Frequency(f), nil
+		}
+	}
+	return 0, rruleError("unknown FREQ %q", val)
+}
+
+func parseWeekday(val string) (time.Weekday, error) {
+	for d, name := range weekdayNames {
+		if name == val {
+			return time.Weekday(d), nil
+		}
+	}
+	return 0, rruleError("unknown weekday %q", val)
+}
+
+func parseWeekdayNums(val string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(val, ",") {
+		if len(item) < 2 {
+			return nil, rruleError("unknown BYDAY value %q", item)
+		}
+		weekday, err := parseWeekday(item[len(item)-2:])
+		if err != nil {
+			return nil, err
+		}
+		day := WeekdayNum{Weekday: weekday}
+		if prefix := item[:len(item)-2]; prefix != "" {
+			day.N, err = strconv.Atoi(prefix)
+			if err != nil || day.N == 0 || day.N < -53 || day.N > 53 {
+				return nil, rruleError("unknown BYDAY value %q", item)
+			}
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func parseInts(name, val string) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, rruleError("%s value %q is not a number", name, item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// String writes the rule as an RRULE value
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		if r.untilFloating {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	ints := func(name string, values []int) {
+		if len(values) == 0 {
+			return
+		}
+		items := make([]string, len(values))
+		for i, v := range values {
+			items[i] = strconv.Itoa(v)
+		}
+		parts = append(parts, name+"="+strings.Join(items, ","))
+	}
+	ints("BYSETPOS", r.BySetPos)
+	ints("BYMONTH", r.ByMonth)
+	ints("BYWEEKNO", r.ByWeekNo)
+	ints("BYYEARDAY", r.ByYearDay)
+	ints("BYMONTHDAY", r.ByMonthDay)
+	if len(r.ByDay) > 0 {
+		items := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			items[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(items, ","))
+	}
+	ints("BYHOUR", r.ByHour)
+	ints("BYMINUTE", r.ByMinute)
+	ints("BYSECOND", r.BySecond)
+	return strings.Join(parts, ";")
+}
+
+// RecurrenceSet is a series as RFC 5545 defines it: the occurrences of a rule from
+// DTSTART, plus the RDATEs, minus the EXDATEs. The location of Start is the timezone
+// the rule is evaluated in.
+type RecurrenceSet struct {
+	Start   time.Time
+	Rule    *RRule
+	RDates  []time.Time
+	ExDates []time.Time
+}
+
+// Between returns the occurrences from "from" up to but excluding "to", in order
+func (s *RecurrenceSet) Between(from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	if s.Rule != nil {
+		s.Rule.iterate(s.Start, to, func(t time.Time) bool {
+			if !t.Before(to) {
+				return false
+			}
+			if !t.Before(from) {
+				occurrences = append(occurrences, t)
+			}
+			return true
+		})
+	}
+	for _, t := range s.RDates {
+		if !t.Before(from) && t.Before(to) {
+			occurrences = append(occurrences, t.In(s.Start.Location()))
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+
+	result := occurrences[:0]
+	for _, t := range occurrences {
+		if len(result) > 0 && result[len(result)-1].Equal(t) {
+			continue
+		}
+		if containsTime(s.ExDates, t) {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, other := range times {
+		if other.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// iterate yields the occurrences of the rule from start in order until yield returns
+// false, the rule ends, or its periods pass "to".
+//
+// Dates are worked out on wall-clock times, held in UTC so that adding days and hours
+// is never skewed by DST, and only placed in the series' timezone when yielded.
+func (r *RRule) iterate(start, to time.Time, yield func(time.Time) bool) {
+	loc := start.Location()
+	dtstart := wallClock(start)
+	last := wallClock(to.In(loc)).AddDate(0, 0, 1)
+	rule := r.withDefaults(dtstart)
+
+	var until *time.Time
+	if r.Until != nil {
+		u := *r.Until
+		if r.untilFloating {
+			u = time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+		}
+		until = &u
+	}
+
+	count := 0
+	for period := rule.periodStart(dtstart); !period.After(last); period = rule.next(period) {
+		for _, candidate := range rule.candidates(period) {
+			if candidate.Before(dtstart) {
+				continue
+			}
+			t := time.Date(candidate.Year(), candidate.Month(), candidate.Day(),
+				candidate.Hour(), candidate.Minute(), candidate.Second(), 0, loc)
+			if until != nil && t.After(*until) {
+				return
+			}
+			count++
+			if !yield(t) || (r.Count > 0 && count >= r.Count) {
+				return
+			}
+		}
+	}
+}
+
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// withDefaults fills in what RFC 5545 takes from DTSTART when the rule leaves it out:
+// the day of a yearly, monthly or weekly rule, and the time of day
+func (r *RRule) withDefaults(dtstart time.Time) *RRule {
+	rule := *r
+	if len(r.ByWeekNo) == 0 && len(r.ByYearDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		switch r.Freq {
+		case FreqYearly:
+			if len(r.ByMonth) == 0 {
+				rule.ByMonth = []int{int(dtstart.Month())}
+			}
+			rule.ByMonthDay = []int{dtstart.Day()}
+		case FreqMonthly:
+			rule.ByMonthDay = []int{dtstart.Day()}
+		case FreqWeekly:
+			rule.ByDay = []WeekdayNum{{Weekday: dtstart.Weekday()}}
+		}
+	}
+	if len(r.ByHour) == 0 && r.Freq <= FreqDaily {
+		rule.ByHour = []int{dtstart.Hour()}
+	}
+	if len(r.ByMinute) == 0 && r.Freq <= FreqHourly {
+		rule.ByMinute = []int{dtstart.Minute()}
+	}
+	if len(r.BySecond) == 0 && r.Freq <= FreqMinutely {
+		rule.BySecond = []int{dtstart.Second()}
+	}
+	rule.ByHour = sortedInts(rule.ByHour)
+	rule.ByMinute = sortedInts(rule.ByMinute)
+	rule.BySecond = sortedInts(rule.BySecond)
+	return &rule
+}
+
+func sortedInts(values []int) []int {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// periodStart returns the start of the period containing t
+func (r *RRule) periodStart(t time.Time) time.Time {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch r.Freq {
+	case FreqYearly:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case FreqMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case FreqWeekly:
+		return date.AddDate(0, 0, -((int(t.Weekday()) - int(r.WeekStart) + 7) % 7))
+	case FreqDaily:
+		return date
+	case FreqHourly:
+		return t.Truncate(time.Hour)
+	case FreqMinutely:
+		return t.Truncate(time.Minute)
+	}
+	return t
+}
+
+func (r *RRule) next(period time.Time) time.Time {
+	switch r.Freq {
+	case FreqYearly:
+		return period.AddDate(r.Interval, 0, 0)
+	case FreqMonthly:
+		return period.AddDate(0, r.Interval, 0)
+	case FreqWeekly:
+		return period.AddDate(0, 0, 7*r.Interval)
+	case FreqDaily:
+		return period.AddDate(0, 0, r.Interval)
+	case FreqHourly:
+		return period.Add(time.Duration(r.Interval) * time.Hour)
+	case FreqMinutely:
+		return period.Add(time.Duration(r.Interval) * time.Minute)
+	}
+	return period.Add(time.Duration(r.Interval) * time.Second)
+}
+
+// candidates returns the times of a period that match the rule, in order, after BYSETPOS
+func (r *RRule) candidates(period time.Time) []time.Time {
+	times := r.timesOfDay(period)
+	if len(times) == 0 {
+		return nil
+	}
+	var candidates []time.Time
+	for _, day := range r.days(period) {
+		for _, t := range times {
+			candidates = append(candidates, day.Add(t))
+		}
+	}
+	if len(r.BySetPos) == 0 || len(candidates) == 0 {
+		return candidates
+	}
+
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(candidates) + pos
+		}
+		if i >= 0 && i < len(candidates) && !containsTime(selected, candidates[i]) {
+			selected = append(selected, candidates[i])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return selected
+}
+
+// days returns the dates of a period that match the rule's date parts
+func (r *RRule) days(period time.Time) []time.Time {
+	first := time.Date(period.Year(), period.Month(), period.Day(), 0, 0, 0, 0, time.UTC)
+	last := first
+	switch r.Freq {
+	case FreqYearly:
+		last = first.AddDate(1, 0, -1)
+	case FreqMonthly:
+		last = first.AddDate(0, 1, -1)
+	case FreqWeekly:
+		last = first.AddDate(0, 0, 6)
+	}
+	var days []time.Time
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if r.matchesDay(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (r *RRule) matchesDay(day time.Time) bool {
+	if len(r.ByMonth) > 0 && !containsInt(r.ByMonth, int(day.Month())) {
+		return false
+	}
+	if len(r.ByWeekNo) > 0 {
+		week, weeks := weekNumber(day, r.WeekStart)
+		if !containsInt(r.ByWeekNo, week) && !containsInt(r.ByWeekNo, week-weeks-1) {
+			return false
+		}
+	}
+	if len(r.ByYearDay) > 0 {
+		yearDay, yearLength := day.YearDay(), daysBetween(yearStart(day), yearStart(day).AddDate(1, 0, 0))
+		if !containsInt(r.ByYearDay, yearDay) && !containsInt(r.ByYearDay, yearDay-yearLength-1) {
+			return false
+		}
+	}
+	if len(r.ByMonthDay) > 0 {
+		monthLength := monthEnd(day).Day()
+		if !containsInt(r.ByMonthDay, day.Day()) && !containsInt(r.ByMonthDay, day.Day()-monthLength-1) {
+			return false
+		}
+	}
+	if len(r.ByDay) > 0 && !r.matchesWeekday(day) {
+		return false
+	}
+	return true
+}
+
+// matchesWeekday checks BYDAY. Numbered weekdays count within the month in monthly
+// rules and yearly rules with BYMONTH, and within the year otherwise.
+func (r *RRule) matchesWeekday(day time.Time) bool {
+	for _, weekday := range r.ByDay {
+		if weekday.Weekday != day.Weekday() {
+			continue
+		}
+		if weekday.N == 0 || r.Freq > FreqMonthly {
+			return true
+		}
+		first, last := yearStart(day), yearStart(day).AddDate(1, 0, -1)
+		if r.Freq == FreqMonthly || len(r.ByMonth) > 0 {
+			first, last = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC), monthEnd(day)
+		}
+		if weekday.N > 0 && daysBetween(first, day)/7+1 == weekday.N {
+			return true
+		}
+		if weekday.N < 0 && daysBetween(day, last)/7+1 == -weekday.N {
+			return true
+		}
+	}
+	return false
+}
+
+// timesOfDay returns the times of day, as offsets from midnight, the period produces.
+// Periods shorter than a day produce their own hour, minute or second when the rule
+// allows it.
+func (r *RRule) timesOfDay(period time.Time) []time.Duration {
+	hours, minutes, seconds := r.ByHour, r.ByMinute, r.BySecond
+	if r.Freq >= FreqHourly {
+		if len(hours) > 0 && !containsInt(hours, period.Hour()) {
+			return nil
+		}
+		hours = []int{period.Hour()}
+	}
+	if r.Freq >= FreqMinutely {
+		if len(minutes) > 0 && !containsInt(minutes, period.Minute()) {
+			return nil
+		}
+		minutes = []int{period.Minute()}
+	}
+	if r.Freq == FreqSecondly {
+		if len(seconds) > 0 && !containsInt(seconds, period.Second()) {
+			return nil
+		}
+		seconds = []int{period.Second()}
+	}
+
+	var times []time.Duration
+	for _, h := range hours {
+		for _, m := range minutes {
+			for _, s := range seconds {
+				times = append(times, time.Duration(h)*time.Hour+time.Duration(m)*time.Minute+time.Duration(s)*time.Second)
+			}
+		}
+	}
+	return times
+}
+
+// weekNumber returns the week of the year a date is in and the number of weeks in that
+// year. Weeks begin on weekStart and week 1 is the first with at least four days in
+// the year, so the first and last days of a year can fall in another year's weeks.
+func weekNumber(day time.Time, weekStart time.Weekday) (int, int) {
+	start := firstWeekStart(day.Year(), weekStart)
+	next := firstWeekStart(day.Year()+1, weekStart)
+	if day.Before(start) {
+		start, next = firstWeekStart(day.Year()-1, weekStart), start
+	} else if !day.Before(next) {
+		start, next = next, firstWeekStart(day.Year()+2, weekStart)
+	}
+	return daysBetween(start, day)/7 + 1, daysBetween(start, next) / 7
+}
+
+// firstWeekStart returns the first day of week 1 of a year, the week holding January 4th
+func firstWeekStart(year int, weekStart time.Weekday) time.Time {
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)
+	return jan4.AddDate(0, 0, -((int(jan4.Weekday()) - int(weekStart) + 7) % 7))
+}
+
+func yearStart(day time.Time) time.Time {
+	return time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthEnd(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween counts the days from one midnight to another
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// recurrenceTypes are the types rules with an RRULE report. Rules repeating more often
+// than daily are Custom.
+var recurrenceTypes = map[Frequency]RecurrenceType{
+	FreqYearly:  RecurrenceTypeYearly,
+	FreqMonthly: RecurrenceTypeMonthly,
+	FreqWeekly:  RecurrenceTypeWeekly,
+	FreqDaily:   RecurrenceTypeDaily,
+}
+
+// syncRRule keeps the RRULE of a rule and its fields in step: a given RRULE fills in
+// the fields, otherwise the fields are written out as an RRULE
+func (r *RecurrenceRule) syncRRule() error {
+	if r.RRule == "" {
+		rule, err := r.fieldsRRule()
+		if err != nil {
+			return err
+		}
+		r.RRule = rule.String()
+		return nil
+	}
+
+	rule, err := ParseRRule(r.RRule)
+	if err != nil {
+		return err
+	}
+	r.RRule = strings.TrimSpace(r.RRule)
+	r.Freq = RecurrenceTypeCustom
+	if t, ok := recurrenceTypes[rule.Freq]; ok {
+		r.Freq = t
+	}
+	r.Interval = rule.Interval
+	r.Count = nil
+	if rule.Count > 0 {
+		r.Count = &rule.Count
+	}
+	r.Until = rule.Until
+	r.ByDay = nil
+	for _, day := range rule.ByDay {
+		r.ByDay = append(r.ByDay, day.String())
+	}
+	r.ByMonth = Int64Array(convertToInt64(rule.ByMonth))
+	r.ByMonthDay = Int64Array(convertToInt64(rule.ByMonthDay))
+	r.BySetPos = Int64Array(convertToInt64(rule.BySetPos))
+	r.ByWeekNo = Int64Array(convertToInt64(rule.ByWeekNo))
+	r.WeekStart = weekdayNames[rule.WeekStart]
+	return nil
+}
+
+// rrule returns the rule occurrences are generated from
+func (r *RecurrenceRule) rrule() (*RRule, error) {
+	if r.RRule != "" {
+		return ParseRRule(r.RRule)
+	}
+	return r.fieldsRRule()
+}
+
+// fieldsRRule builds an RRULE from the fields of a rule
+func (r *RecurrenceRule) fieldsRRule() (*RRule, error) {
+	rule := &RRule{Interval: max(r.Interval, 1), Until: r.Until, WeekStart: time.Monday}
+	switch r.Freq {
+	case RecurrenceTypeDaily:
+		rule.Freq = FreqDaily
+	case RecurrenceTypeWeekly:
+		rule.Freq = FreqWeekly
+	case RecurrenceTypeBiweekly:
+		rule.Freq, rule.Interval = FreqWeekly, 2
+	case RecurrenceTypeMonthly:
+		rule.Freq = FreqMonthly
+	case RecurrenceTypeYearly:
+		rule.Freq = FreqYearly
+	default:
+		// Custom rules only exist as an RRULE
+		return nil, ErrInvalidRecurrence
+	}
+	if r.Count != nil {
+		rule.Count = *r.Count
+	}
+
+	var err error
+	if r.WeekStart != "" {
+		if rule.WeekStart, err = parseWeekday(strings.ToUpper(r.WeekStart)); err != nil {
+			return nil, err
+		}
+	}
+	if len(r.ByDay) > 0 {
+		if rule.ByDay, err = parseWeekdayNums(strings.ToUpper(strings.Join(r.ByDay, ","))); err != nil {
+			return nil, err
+		}
+	}
+	rule.ByMonth = toInts(r.ByMonth)
+	rule.ByMonthDay = toInts(r.ByMonthDay)
+	rule.BySetPos = toInts(r.BySetPos)
+	rule.ByWeekNo = toInts(r.ByWeekNo)
+	return rule, rule.Validate()
+}
+
+// recurrenceSet returns the series of an event, evaluated in the rule's timezone
+func (r *RecurrenceRule) recurrenceSet(event *CalendarEvent) (*RecurrenceSet, error) {
+	rule, err := r.rrule()
+	if err != nil {
+		return nil, err
+	}
+	timezone := r.Timezone
+	if timezone == "" {
+		timezone = event.Timezone
+	}
+	return &RecurrenceSet{
+		Start:   event.StartTime.In(location(timezone)),
+		Rule:    rule,
+		RDates:  r.RDates,
+		ExDates: r.ExDates,
+	}, nil
+}
+
+// SeriesSplit is the series of an event divided at a time, for changes that must leave
+// past occurrences alone. Head is the event's rule ended before the split; Tail is a new
+// rule for the rest of the series, without an ID or event yet.
+type SeriesSplit struct {
+	// Start is the first occurrence of the tail, before it is moved
+	Start time.Time
+	Head  *RecurrenceRule
+	Tail  *RecurrenceRule
+}
+
+// SplitSeries divides the series of a recurring event before its first occurrence at or
+// after "at". A COUNT is shared between the two rules. The times of the tail are passed
+// through move, e.g. to keep their local time in another timezone; an UNTIL without a
+// timezone already follows the zone of its series. It returns nil when no occurrence is
+// left at or after "at".
+func SplitSeries(event *CalendarEvent, at time.Time, move func(time.Time) time.Time) (*SeriesSplit, error) {
+	if len(event.RecurrenceRules) == 0 {
+		return nil, ErrInvalidRecurrence
+	}
+	rule := &event.RecurrenceRules[0]
+	set, err := rule.recurrenceSet(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var start time.Time
+	before := 0
+	// iterate only needs "to" to stop rules without an end; yield stops it sooner
+	set.Rule.iterate(set.Start, at.AddDate(100, 0, 0), func(t time.Time) bool {
+		if t.Before(at) {
+			before++
+			return true
+		}
+		start = t
+		return false
+	})
+	if start.IsZero() {
+		return nil, nil
+	}
+
+	headRRule, tailRRule := *set.Rule, *set.Rule
+	until := start.Add(-time.Second).UTC()
+	headRRule.Count, headRRule.Until, headRRule.untilFloating = 0, &until, false
+	if set.Rule.Count > 0 {
+		tailRRule.Count = set.Rule.Count - before
+	}
+	if tailRRule.Until != nil && !tailRRule.untilFloating {
+		moved := move(*tailRRule.Until)
+		tailRRule.Until = &moved
+	}
+
+	head, tail := *rule, *rule
+	head.RRule = headRRule.String()
+	tail.ID = uuid.Nil
+	tail.RRule = tailRRule.String()
+	head.RDates, tail.RDates = splitTimes(rule.RDates, at, move)
+	head.ExDates, tail.ExDates = splitTimes(rule.ExDates, at, move)
+	if err := head.syncRRule(); err != nil {
+		return nil, err
+	}
+	if err := tail.syncRRule(); err != nil {
+		return nil, err
+	}
+	return &SeriesSplit{Start: start.UTC(), Head: &head, Tail: &tail}, nil
+}
+
+// splitTimes divides times at "at", moving the later ones
+func splitTimes(times TimeArray, at time.Time, move func(time.Time) time.Time) (TimeArray, TimeArray) {
+	var before, after TimeArray
+	for _, t := range times {
+		if t.Before(at) {
+			before = append(before, t)
+		} else {
+			after = append(after, move(t))
+		}
+	}
+	return before, after
+}
+
+func toInts(values Int64Array) []int {
+	if values == nil {
+		return nil
+	}
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return ints
+}
@@ -0,0 +1,146 @@
+package calendar
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// rruleFixture is a series with its known occurrences. Fixtures that are not complete
+// only list the first occurrences of the series.
+type rruleFixture struct {
+	Name     string   `json:"name"`
+	TZID     string   `json:"tzid"`
+	DTStart  string   `json:"dtstart"`
+	RRule    string   `json:"rrule"`
+	RDate    []string `json:"rdate"`
+	ExDate   []string `json:"exdate"`
+	Complete bool     `json:"complete"`
+	Expected []string `json:"expected"`
+}
+
+func TestRecurrenceSetFixtures(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "rrule.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []rruleFixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			loc, err := time.LoadLocation(f.TZID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start, err := time.ParseInLocation("20060102T150405", f.DTStart, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rule, err := ParseRRule(f.RRule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			set := &RecurrenceSet{Start: start, Rule: rule, RDates: utcTimes(t, f.RDate), ExDates: utcTimes(t, f.ExDate)}
+
+			var got []string
+			for _, occurrence := range set.Between(start, start.AddDate(10, 0, 0)) {
+				got = append(got, occurrence.Format(time.RFC3339))
+			}
+			if !f.Complete && len(got) > len(f.Expected) {
+				got = got[:len(f.Expected)]
+			}
+			assert.Equal(t, f.Expected, got)
+		})
+	}
+}
+
+func utcTimes(t *testing.T, values []string) []time.Time {
+	times := make([]time.Time, len(values))
+	for i, value := range values {
+		var err error
+		if times[i], err = time.Parse("20060102T150405Z", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return times
+}
+
+func TestRRuleStringRoundTrip(t *testing.T) {
+	rules := []string{
+		"FREQ=DAILY;COUNT=10",
+		"FREQ=WEEKLY;INTERVAL=2;WKST=SU;UNTIL=19971224T000000Z;BYDAY=MO,WE,FR",
+		"FREQ=MONTHLY;BYSETPOS=-2;BYDAY=MO,TU,WE,TH,FR",
+		"FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO",
+		"FREQ=YEARLY;INTERVAL=3;COUNT=10;BYYEARDAY=1,100,200",
+		"FREQ=MONTHLY;COUNT=10;BYDAY=1SU,-1SU",
+		"FREQ=DAILY;UNTIL=19970904T090000;BYHOUR=9,10;BYMINUTE=0,20,40",
+	}
+	for _, value := range rules {
+		rule, err := ParseRRule(value)
+		if assert.NoError(t, err, value) {
+			assert.Equal(t, value, rule.String())
+		}
+	}
+}
+
+func TestParseRRuleRejectsInvalidRules(t *testing.T) {
+	rules := []string{
+		"",
+		"COUNT=5",
+		"FREQ=FORTNIGHTLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=5;COUNT=6",
+		"FREQ=MONTHLY;BYWEEKNO=20",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=DAILY;BYYEARDAY=100",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=YEARLY;BYWEEKNO=1;BYDAY=1MO",
+		"FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;X-NAME=1",
+	}
+	for _, value := range rules {
+		_, err := ParseRRule(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestSplitSeries(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := time.Date(2025, 1, 4, 12, 0, 0, 0, time.UTC)
+	later := func(t time.Time) time.Time { return t.Add(time.Hour) }
+	series := func(rrule string) *CalendarEvent {
+		return &CalendarEvent{
+			StartTime:       start,
+			EndTime:         start.Add(time.Hour),
+			Timezone:        "UTC",
+			RecurrenceRules: []RecurrenceRule{{ID: uuid.New(), RRule: rrule, Timezone: "UTC"}},
+		}
+	}
+
+	split, err := SplitSeries(series("FREQ=DAILY;COUNT=10"), at, later)
+	if assert.NoError(t, err) && assert.NotNil(t, split) {
+		assert.Equal(t, time.Date(2025, 1, 5, 9, 0, 0, 0, time.UTC), split.Start)
+		assert.Equal(t, "FREQ=DAILY;UNTIL=20250105T085959Z", split.Head.RRule)
+		assert.Equal(t, "FREQ=DAILY;COUNT=6", split.Tail.RRule)
+		assert.Equal(t, uuid.Nil, split.Tail.ID)
+	}
+
+	split, err = SplitSeries(series("FREQ=DAILY;UNTIL=20250110T090000Z"), at, later)
+	if assert.NoError(t, err) && assert.NotNil(t, split) {
+		assert.Equal(t, "FREQ=DAILY;UNTIL=20250110T100000Z", split.Tail.RRule)
+	}
+
+	split, err = SplitSeries(series("FREQ=DAILY;COUNT=3"), at, later)
+	assert.NoError(t, err)
+	assert.Nil(t, split)
+}
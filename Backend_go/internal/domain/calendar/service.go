@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -22,6 +24,17 @@ type Service interface {
 	DeleteEvent(ctx context.Context, id uuid.UUID) error
 	GetEventByID(ctx context.Context, id uuid.UUID) (*CalendarEvent, error)
 	ListEvents(ctx context.Context, userID uuid.UUID, startTime, endTime time.Time, eventType *EventType, page, pageSize int) (*CalendarEventListResponse, error)
+	// ListEventsAfter lists the same events as ListEvents a keyset page at a time, ordered
+	// by start time
+	ListEventsAfter(ctx context.Context, userID uuid.UUID, startTime, endTime time.Time, eventType *EventType, keyset *pagination.Keyset) (*CalendarEventListResponse, error)
+	// LocalizeEvents converts the times of events to the user's timezone preference for output
+	LocalizeEvents(ctx context.Context, userID uuid.UUID, events []CalendarEvent)
+	// GetAvailability returns free/busy time and working locations without event details
+	GetAvailability(ctx context.Context, userIDs []uuid.UUID, start, end time.Time) ([]Availability, error)
+	// FindMeetingTimes adds the time a group of users is all free and suggests meeting slots in it
+	FindMeetingTimes(ctx context.Context, query MeetingQuery) (*MeetingTimes, error)
+	// DuplicateEvent copies an event the user organizes, with its reminders, to another day
+	DuplicateEvent(ctx context.Context, eventID, userID uuid.UUID, opts DuplicateOptions) (*CalendarEvent, error)
 
 	// Occurrence operations
 	UpdateOccurrenceById(ctx context.Context, occurrenceId uuid.UUID, req UpdateCalendarEventRequest) error
@@ -40,6 +53,14 @@ type Service interface {
 	ListEventsSharedWithMe(ctx context.Context, userID uuid.UUID) ([]CalendarEvent, error)
 	RespondToEventInvite(ctx context.Context, eventID, userID uuid.UUID, accept bool) error
 	GetCollaborator(ctx context.Context, eventID, userID uuid.UUID) (*EventCollaborator, error)
+
+	// Attendee operations
+	InviteAttendees(ctx context.Context, eventID, organizerID uuid.UUID, invites []AttendeeInvite) ([]EventAttendee, error)
+	ListAttendees(ctx context.Context, eventID uuid.UUID) ([]EventAttendee, error)
+	RespondToAttendance(ctx context.Context, eventID, userID uuid.UUID, rsvp RSVP) (*EventAttendee, error)
+	UpdateAttendeeStatus(ctx context.Context, eventID, attendeeID, callerID uuid.UUID, rsvp RSVP) (*EventAttendee, error)
+	RemoveAttendee(ctx context.Context, eventID, attendeeID, callerID uuid.UUID) error
+
 	GetDashboardMetrics(userID uuid.UUID) (CalendarDashboardMetrics, error)
 	GetTodayEvents(ctx context.Context, userID uuid.UUID) ([]CalendarEvent, error)
 	GetUpcomingEvents(ctx context.Context, userID uuid.UUID, limit int) ([]CalendarEvent, error)
@@ -47,14 +68,16 @@ type Service interface {
 
 type service struct {
 	repo     Repository
+	users    Users
 	notifier notification.DomainNotifier
 	redis    *cache.RedisClient
+	bus      events.Publisher
 	logger   *zap.Logger
 }
 
 // NewService creates a new calendar service instance
-func NewService(repo Repository, notifier notification.DomainNotifier, redis *cache.RedisClient, logger *zap.Logger) Service {
-	return &service{repo: repo, notifier: notifier, redis: redis, logger: logger}
+func NewService(repo Repository, users Users, notifier notification.DomainNotifier, redis *cache.RedisClient, bus events.Publisher, logger *zap.Logger) Service {
+	return &service{repo: repo, users: users, notifier: notifier, redis: redis, bus: bus, logger: logger}
 }
 
 // Define CalendarDashboardMetrics struct for dashboard metrics aggregation
@@ -66,6 +89,11 @@ type CalendarDashboardMetrics struct {
 }
 
 func (s *service) CreateEvent(ctx context.Context, req CreateCalendarEventRequest, userID uuid.UUID) (*CalendarEvent, error) {
+	timezone, err := s.eventTimezone(ctx, req.Timezone, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start a transaction
 	tx := s.repo.BeginTransaction(ctx)
 	if tx == nil {
@@ -89,7 +117,12 @@ func (s *service) CreateEvent(ctx context.Context, req CreateCalendarEventReques
 		Location:     req.Location,
 		Color:        req.Color,
 		Transparency: req.Transparency,
+
+		WorkingLocation: req.WorkingLocation,
+		DeclineInvites:  req.DeclineInvites,
+		Timezone:        timezone,
 	}
+	event.applyStatusType()
 
 	// Validate the event
 	if err := event.Validate(); err != nil {
@@ -111,8 +144,18 @@ func (s *service) CreateEvent(ctx context.Context, req CreateCalendarEventReques
 			ByDay:      StringArray(req.RecurrenceRule.ByDay),
 			ByMonth:    Int64Array(convertToInt64(req.RecurrenceRule.ByMonth)),
 			ByMonthDay: Int64Array(convertToInt64(req.RecurrenceRule.ByMonthDay)),
+			BySetPos:   Int64Array(convertToInt64(req.RecurrenceRule.BySetPos)),
+			ByWeekNo:   Int64Array(convertToInt64(req.RecurrenceRule.ByWeekNo)),
+			WeekStart:  req.RecurrenceRule.WeekStart,
 			Count:      req.RecurrenceRule.Count,
 			Until:      req.RecurrenceRule.Until,
+			RRule:      req.RecurrenceRule.RRule,
+			RDates:     TimeArray(req.RecurrenceRule.RDates),
+			ExDates:    TimeArray(req.RecurrenceRule.ExDates),
+			Timezone:   timezone,
+		}
+		if err := rule.syncRRule(); err != nil {
+			return nil, err
 		}
 		if err := rule.Validate(); err != nil {
 			return nil, err
@@ -136,6 +179,7 @@ func (s *service) CreateEvent(ctx context.Context, req CreateCalendarEventReques
 			EventID:       event.ID,
 			MinutesBefore: reminderReq.MinutesBefore,
 			Method:        reminderReq.Method,
+			WebhookURL:    reminderReq.WebhookURL,
 		}
 		if err := reminder.Validate(); err != nil {
 			return nil, err
@@ -150,7 +194,7 @@ func (s *service) CreateEvent(ctx context.Context, req CreateCalendarEventReques
 	}
 
 	// Fetch the complete event with all relationships
-	event, err := s.GetEventByID(ctx, event.ID)
+	event, err = s.GetEventByID(ctx, event.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -170,272 +214,40 @@ func (s *service) CreateEvent(ctx context.Context, req CreateCalendarEventReques
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, eventDashboard); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
 	return event, nil
 }
 
-// generateOccurrences generates event occurrences based on the recurrence rule
+// generateOccurrences generates the occurrences of a series up to its UNTIL, or a year
+// ahead when it has none. They are returned in UTC.
 func (s *service) generateOccurrences(event *CalendarEvent, rule *RecurrenceRule) []*EventOccurrence {
-	var occurrences []*EventOccurrence
-	currentTime := event.StartTime
-
-	// Create map for faster day lookup
-	allowedDays := make(map[string]bool)
-	if len(rule.ByDay) > 0 {
-		for _, day := range rule.ByDay {
-			allowedDays[day] = true
-		}
+	set, err := rule.recurrenceSet(event)
+	if err != nil {
+		s.logger.Warn("Skipping invalid recurrence rule",
+			zap.String("event_id", event.ID.String()), zap.String("rrule", rule.RRule), zap.Error(err))
+		return nil
 	}
 
-	// Determine the end date for occurrence generation
-	var endDate time.Time
-	if rule.Until != nil {
-		endDate = *rule.Until
-	} else if rule.Count != nil {
-		// If count is specified, we'll generate that many occurrences
-		endDate = event.StartTime.AddDate(1, 0, 0) // Use 1 year as maximum
-	} else {
-		// If neither until nor count is specified, generate occurrences for 1 year
-		endDate = event.StartTime.AddDate(1, 0, 0)
+	end := event.StartTime.AddDate(1, 0, 0)
+	if set.Rule.Until != nil {
+		end = set.Rule.Until.Add(24 * time.Hour)
 	}
 
-	count := 0
-
-	// Handle different frequencies
-	switch rule.Freq {
-	case RecurrenceTypeDaily:
-		// Generate daily occurrences
-		for currentTime.Before(endDate) {
-			if rule.Count != nil && count >= *rule.Count {
-				break
-			}
-
-			occurrence := &EventOccurrence{
-				EventID:        event.ID,
-				OccurrenceTime: currentTime,
-				Status:         OccurrenceStatusUpcoming,
-				CreatedAt:      time.Now(),
-				UpdatedAt:      time.Now(),
-			}
-			occurrences = append(occurrences, occurrence)
-			count++
-
-			// Move to next occurrence based on interval
-			currentTime = currentTime.AddDate(0, 0, rule.Interval)
-		}
-
-	case RecurrenceTypeWeekly:
-		if len(allowedDays) > 0 {
-			// Weekly with specific days
-			for currentTime.Before(endDate) {
-				if rule.Count != nil && count >= *rule.Count {
-					break
-				}
-
-				dayStr := strings.ToUpper(currentTime.Weekday().String()[:2])
-				if allowedDays[dayStr] {
-					occurrence := &EventOccurrence{
-						EventID:        event.ID,
-						OccurrenceTime: currentTime,
-						Status:         OccurrenceStatusUpcoming,
-						CreatedAt:      time.Now(),
-						UpdatedAt:      time.Now(),
-					}
-					occurrences = append(occurrences, occurrence)
-					count++
-				}
-
-				// Move to next day
-				nextDay := currentTime.AddDate(0, 0, 1)
-				// If we're moving to a new week (Sunday to Monday)
-				if nextDay.Weekday() == time.Monday && rule.Interval > 1 {
-					// Skip to the next week based on interval
-					currentTime = nextDay.AddDate(0, 0, (rule.Interval-1)*7)
-				} else {
-					currentTime = nextDay
-				}
-			}
-		} else {
-			// Simple weekly recurrence without specific days
-			for currentTime.Before(endDate) {
-				if rule.Count != nil && count >= *rule.Count {
-					break
-				}
-
-				occurrence := &EventOccurrence{
-					EventID:        event.ID,
-					OccurrenceTime: currentTime,
-					Status:         OccurrenceStatusUpcoming,
-					CreatedAt:      time.Now(),
-					UpdatedAt:      time.Now(),
-				}
-				occurrences = append(occurrences, occurrence)
-				count++
-
-				// Move to next week based on interval
-				currentTime = currentTime.AddDate(0, 0, 7*rule.Interval)
-			}
-		}
-
-	case RecurrenceTypeBiweekly:
-		// Biweekly is just weekly with interval of 2
-		for currentTime.Before(endDate) {
-			if rule.Count != nil && count >= *rule.Count {
-				break
-			}
-
-			occurrence := &EventOccurrence{
-				EventID:        event.ID,
-				OccurrenceTime: currentTime,
-				Status:         OccurrenceStatusUpcoming,
-				CreatedAt:      time.Now(),
-				UpdatedAt:      time.Now(),
-			}
-			occurrences = append(occurrences, occurrence)
-			count++
-
-			// Move to next occurrence (2 weeks)
-			currentTime = currentTime.AddDate(0, 0, 14)
-		}
-
-	case RecurrenceTypeMonthly:
-		// Generate monthly occurrences
-		for currentTime.Before(endDate) {
-			if rule.Count != nil && count >= *rule.Count {
-				break
-			}
-
-			if len(rule.ByMonthDay) > 0 {
-				// Handle specific days of the month
-				currentDay := currentTime.Day()
-				for _, day := range rule.ByMonthDay {
-					if int(day) == currentDay {
-						occurrence := &EventOccurrence{
-							EventID:        event.ID,
-							OccurrenceTime: currentTime,
-							Status:         OccurrenceStatusUpcoming,
-							CreatedAt:      time.Now(),
-							UpdatedAt:      time.Now(),
-						}
-						occurrences = append(occurrences, occurrence)
-						count++
-						break
-					}
-				}
-			} else {
-				// Simple monthly recurrence
-				occurrence := &EventOccurrence{
-					EventID:        event.ID,
-					OccurrenceTime: currentTime,
-					Status:         OccurrenceStatusUpcoming,
-					CreatedAt:      time.Now(),
-					UpdatedAt:      time.Now(),
-				}
-				occurrences = append(occurrences, occurrence)
-				count++
-			}
-
-			// Move to next month based on interval
-			currentTime = currentTime.AddDate(0, rule.Interval, 0)
-		}
-
-	case RecurrenceTypeYearly:
-		// Generate yearly occurrences
-		for currentTime.Before(endDate) {
-			if rule.Count != nil && count >= *rule.Count {
-				break
-			}
-
-			if len(rule.ByMonth) > 0 {
-				// Handle specific months
-				currentMonth := int64(currentTime.Month())
-				for _, month := range rule.ByMonth {
-					if month == currentMonth {
-						occurrence := &EventOccurrence{
-							EventID:        event.ID,
-							OccurrenceTime: currentTime,
-							Status:         OccurrenceStatusUpcoming,
-							CreatedAt:      time.Now(),
-							UpdatedAt:      time.Now(),
-						}
-						occurrences = append(occurrences, occurrence)
-						count++
-						break
-					}
-				}
-			} else {
-				// Simple yearly recurrence
-				occurrence := &EventOccurrence{
-					EventID:        event.ID,
-					OccurrenceTime: currentTime,
-					Status:         OccurrenceStatusUpcoming,
-					CreatedAt:      time.Now(),
-					UpdatedAt:      time.Now(),
-				}
-				occurrences = append(occurrences, occurrence)
-				count++
-			}
-
-			// Move to next year based on interval
-			currentTime = currentTime.AddDate(rule.Interval, 0, 0)
-		}
+	var occurrences []*EventOccurrence
+	for _, t := range set.Between(event.StartTime, end) {
+		occurrences = append(occurrences, &EventOccurrence{
+			EventID:        event.ID,
+			OccurrenceTime: t.UTC(),
+			Status:         OccurrenceStatusUpcoming,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		})
 	}
-
 	return occurrences
 }
 
-// isValidOccurrence checks if a given date matches the recurrence rule
-func (s *service) isValidOccurrence(date time.Time, rule *RecurrenceRule) bool {
-	// Check ByDay (if specified)
-	if len(rule.ByDay) > 0 {
-		weekday := strings.ToUpper(date.Weekday().String()[:2])
-		found := false
-		for _, day := range rule.ByDay {
-			if string(day) == weekday {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-
-	// Check ByMonth (if specified)
-	if len(rule.ByMonth) > 0 {
-		month := int64(date.Month())
-		found := false
-		for _, m := range rule.ByMonth {
-			if m == month {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-
-	// Check ByMonthDay (if specified)
-	if len(rule.ByMonthDay) > 0 {
-		day := int64(date.Day())
-		found := false
-		for _, d := range rule.ByMonthDay {
-			if d == day {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-
-	return true
-}
-
 // Helper function to convert []int to []int64
 func convertToInt64(input []int) []int64 {
 	if input == nil {
@@ -461,42 +273,27 @@ func (s *service) UpdateEvent(ctx context.Context, id uuid.UUID, req UpdateCalen
 	}
 	defer tx.Rollback()
 
-	// Store original start time before updating
+	// Store original start and end times before updating
 	originalStartTime := event.StartTime
+	originalEndTime := event.EndTime
+
+	if req.Timezone != nil {
+		if _, err := user.ParseTimezone(*req.Timezone); err != nil {
+			return nil, ErrInvalidTimezone
+		}
+		event.Timezone = *req.Timezone
+	}
+	// Times of day are read in the event's timezone, so a series keeps its local time
+	loc := location(event.Timezone)
 
 	// Handle preserve_date_sequence flag - only update time of day, not the date
 	if req.PreserveDateSequence != nil && *req.PreserveDateSequence && req.StartTime != nil {
-		// Convert the UTC input time to local time zone of the original event
-		inputTimeInEventTZ := req.StartTime.In(originalStartTime.Location())
-
-		// Extract time components from the timezone-adjusted input time
-		newHour, newMinute, newSecond := inputTimeInEventTZ.Hour(), inputTimeInEventTZ.Minute(), inputTimeInEventTZ.Second()
-
-		// Create a new time that preserves the original date but uses the new time
-		updatedTime := time.Date(
-			originalStartTime.Year(),
-			originalStartTime.Month(),
-			originalStartTime.Day(),
-			newHour, newMinute, newSecond,
-			req.StartTime.Nanosecond(),
-			originalStartTime.Location(),
-		)
-
-		// Replace the requested start time with our date-preserved version
+		updatedTime := withTimeOf(originalStartTime, *req.StartTime, loc)
 		req.StartTime = &updatedTime
 
 		// Do the same for end time if it's provided
 		if req.EndTime != nil {
-			inputEndTimeInEventTZ := req.EndTime.In(event.EndTime.Location())
-			newHourEnd, newMinuteEnd, newSecondEnd := inputEndTimeInEventTZ.Hour(), inputEndTimeInEventTZ.Minute(), inputEndTimeInEventTZ.Second()
-			updatedEndTime := time.Date(
-				event.EndTime.Year(),
-				event.EndTime.Month(),
-				event.EndTime.Day(),
-				newHourEnd, newMinuteEnd, newSecondEnd,
-				req.EndTime.Nanosecond(),
-				event.EndTime.Location(),
-			)
+			updatedEndTime := withTimeOf(event.EndTime, *req.EndTime, loc)
 			req.EndTime = &updatedEndTime
 		}
 	}
@@ -535,6 +332,13 @@ func (s *service) UpdateEvent(ctx context.Context, id uuid.UUID, req UpdateCalen
 	if req.Transparency != nil {
 		event.Transparency = *req.Transparency
 	}
+	if req.WorkingLocation != nil {
+		event.WorkingLocation = *req.WorkingLocation
+	}
+	if req.DeclineInvites != nil {
+		event.DeclineInvites = *req.DeclineInvites
+	}
+	event.applyStatusType()
 
 	// Validate and update the main event
 	if err := event.Validate(); err != nil {
@@ -544,6 +348,15 @@ func (s *service) UpdateEvent(ctx context.Context, id uuid.UUID, req UpdateCalen
 		return nil, err
 	}
 
+	if req.Timezone != nil {
+		for i := range event.RecurrenceRules {
+			event.RecurrenceRules[i].Timezone = event.Timezone
+			if err := tx.UpdateRecurrenceRule(&event.RecurrenceRules[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// If this is a recurring event and time was updated
 	if len(event.RecurrenceRules) > 0 && (req.StartTime != nil || req.EndTime != nil) {
 		// If we're preserving date sequence, update all occurrences with their original date but new time
@@ -558,26 +371,8 @@ func (s *service) UpdateEvent(ctx context.Context, id uuid.UUID, req UpdateCalen
 
 			// For each occurrence, preserve the date but update the time
 			for _, occ := range occurrences {
-				origDate := occ.OccurrenceTime
-
-				// Create a new time that preserves the original date but uses the new time
 				if req.StartTime != nil {
-					// Convert the UTC input time to local time zone of the occurrence
-					inputTimeInOccurrenceTZ := req.StartTime.In(origDate.Location())
-
-					// Extract time components from the timezone-adjusted input time
-					newHour, newMinute, newSecond := inputTimeInOccurrenceTZ.Hour(), inputTimeInOccurrenceTZ.Minute(), inputTimeInOccurrenceTZ.Second()
-
-					updatedTime := time.Date(
-						origDate.Year(),
-						origDate.Month(),
-						origDate.Day(),
-						newHour, newMinute, newSecond,
-						req.StartTime.Nanosecond(),
-						origDate.Location(),
-					)
-
-					occ.OccurrenceTime = updatedTime
+					occ.OccurrenceTime = withTimeOf(occ.OccurrenceTime, *req.StartTime, loc)
 					if err := tx.UpdateOccurrence(&occ); err != nil {
 						return nil, err
 					}
@@ -591,59 +386,17 @@ func (s *service) UpdateEvent(ctx context.Context, id uuid.UUID, req UpdateCalen
 			}
 
 			for _, exception := range exceptions {
-				// For original time, preserve date but update time
 				if req.StartTime != nil {
-					// Convert the UTC input time to local time zone of the exception
-					inputTimeInExceptionTZ := req.StartTime.In(exception.OriginalTime.Location())
-
-					// Extract time components from the timezone-adjusted input time
-					newHour, newMinute, newSecond := inputTimeInExceptionTZ.Hour(), inputTimeInExceptionTZ.Minute(), inputTimeInExceptionTZ.Second()
-
-					updatedTime := time.Date(
-						exception.OriginalTime.Year(),
-						exception.OriginalTime.Month(),
-						exception.OriginalTime.Day(),
-						newHour, newMinute, newSecond,
-						req.StartTime.Nanosecond(),
-						exception.OriginalTime.Location(),
-					)
-					exception.OriginalTime = updatedTime
+					exception.OriginalTime = withTimeOf(exception.OriginalTime, *req.StartTime, loc)
 				}
 
 				// Do the same for any overridden times
 				if exception.OverrideStartTime != nil && req.StartTime != nil {
-					// Convert the UTC input time to local time zone of the exception's start time
-					inputTimeInOverrideStartTZ := req.StartTime.In(exception.OverrideStartTime.Location())
-
-					// Extract time components from the timezone-adjusted input time
-					newHour, newMinute, newSecond := inputTimeInOverrideStartTZ.Hour(), inputTimeInOverrideStartTZ.Minute(), inputTimeInOverrideStartTZ.Second()
-
-					updatedTime := time.Date(
-						exception.OverrideStartTime.Year(),
-						exception.OverrideStartTime.Month(),
-						exception.OverrideStartTime.Day(),
-						newHour, newMinute, newSecond,
-						req.StartTime.Nanosecond(),
-						exception.OverrideStartTime.Location(),
-					)
+					updatedTime := withTimeOf(*exception.OverrideStartTime, *req.StartTime, loc)
 					exception.OverrideStartTime = &updatedTime
 				}
-
 				if exception.OverrideEndTime != nil && req.EndTime != nil {
-					// Convert the UTC input time to local time zone of the exception's end time
-					inputTimeInOverrideEndTZ := req.EndTime.In(exception.OverrideEndTime.Location())
-
-					// Extract time components from the timezone-adjusted input time
-					newHour, newMinute, newSecond := inputTimeInOverrideEndTZ.Hour(), inputTimeInOverrideEndTZ.Minute(), inputTimeInOverrideEndTZ.Second()
-
-					updatedTime := time.Date(
-						exception.OverrideEndTime.Year(),
-						exception.OverrideEndTime.Month(),
-						exception.OverrideEndTime.Day(),
-						newHour, newMinute, newSecond,
-						req.EndTime.Nanosecond(),
-						exception.OverrideEndTime.Location(),
-					)
+					updatedTime := withTimeOf(*exception.OverrideEndTime, *req.EndTime, loc)
 					exception.OverrideEndTime = &updatedTime
 				}
 
@@ -723,7 +476,19 @@ func (s *service) UpdateEvent(ctx context.Context, id uuid.UUID, req UpdateCalen
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, eventDashboard); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
+	}
+
+	if s.bus != nil && (!event.StartTime.Equal(originalStartTime) || !event.EndTime.Equal(originalEndTime)) {
+		s.bus.Publish(ctx, events.EventRescheduled{
+			EventID:       event.ID,
+			UserID:        event.UserID,
+			Title:         event.Title,
+			PreviousStart: originalStartTime,
+			PreviousEnd:   originalEndTime,
+			StartTime:     event.StartTime,
+			EndTime:       event.EndTime,
+		})
 	}
 
 	return event, nil
@@ -754,7 +519,7 @@ func (s *service) DeleteEvent(ctx context.Context, id uuid.UUID) error {
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, eventDashboard); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
 	return nil
@@ -765,19 +530,38 @@ func (s *service) GetEventByID(ctx context.Context, id uuid.UUID) (*CalendarEven
 }
 
 func (s *service) ListEvents(ctx context.Context, userID uuid.UUID, startTime, endTime time.Time, eventType *EventType, page, pageSize int) (*CalendarEventListResponse, error) {
-	filter := EventFilter{
+	return s.listEvents(ctx, EventFilter{
 		UserID:    userID,
 		StartTime: &startTime,
 		EndTime:   &endTime,
 		EventType: eventType,
 		Page:      page,
 		PageSize:  pageSize,
-	}
+	})
+}
+
+func (s *service) ListEventsAfter(ctx context.Context, userID uuid.UUID, startTime, endTime time.Time, eventType *EventType, keyset *pagination.Keyset) (*CalendarEventListResponse, error) {
+	return s.listEvents(ctx, EventFilter{
+		UserID:    userID,
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		EventType: eventType,
+		Keyset:    keyset,
+	})
+}
 
+// listEvents lists the events matching the filter, expanding the occurrences of recurring
+// events within its time range
+func (s *service) listEvents(ctx context.Context, filter EventFilter) (*CalendarEventListResponse, error) {
+	startTime, endTime := *filter.StartTime, *filter.EndTime
 	events, total, err := s.repo.ListEvents(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
+	var nextCursor string
+	if filter.Keyset != nil {
+		events, nextCursor = pagination.Trim(filter.Keyset, events, (*CalendarEvent).PageCursor)
+	}
 
 	// For each recurring event, generate and apply exceptions
 	for i, event := range events {
@@ -869,8 +653,9 @@ func (s *service) ListEvents(ctx context.Context, userID uuid.UUID, startTime, e
 	}
 
 	return &CalendarEventListResponse{
-		Events: events,
-		Total:  total,
+		Events:     events,
+		Total:      total,
+		NextCursor: nextCursor,
 	}, nil
 }
 
@@ -940,6 +725,7 @@ func (s *service) AddReminder(ctx context.Context, eventID uuid.UUID, req Create
 		EventID:       eventID,
 		MinutesBefore: req.MinutesBefore,
 		Method:        req.Method,
+		WebhookURL:    req.WebhookURL,
 	}
 	if err := reminder.Validate(); err != nil {
 		return err
@@ -952,6 +738,7 @@ func (s *service) UpdateReminder(ctx context.Context, id uuid.UUID, req CreateEv
 		ID:            id,
 		MinutesBefore: req.MinutesBefore,
 		Method:        req.Method,
+		WebhookURL:    req.WebhookURL,
 	}
 	if err := reminder.Validate(); err != nil {
 		return err
@@ -1045,6 +832,8 @@ func (s *service) UpdateOccurrenceById(ctx context.Context, occurrenceId uuid.UU
 	return tx.Commit()
 }
 
+// ShareEvent invites a user to an event. When the invitee is out of office during the
+// event the invite is flagged, or declined right away if their out-of-office event says so.
 func (s *service) ShareEvent(ctx context.Context, eventID, invitedUserID, invitedBy uuid.UUID, role string) error {
 	collaborator := &EventCollaborator{
 		EventID:   eventID,
@@ -1053,11 +842,36 @@ func (s *service) ShareEvent(ctx context.Context, eventID, invitedUserID, invite
 		Status:    "pending",
 		InvitedBy: invitedBy,
 	}
-	err := s.repo.AddCollaborator(ctx, collaborator)
+	event, err := s.repo.GetEventByID(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	ooo, err := s.outOfOfficeDuring(ctx, invitedUserID, event.StartTime, event.EndTime)
+	if err != nil {
+		return err
+	}
+	if ooo != nil {
+		collaborator.OutOfOffice = true
+		if ooo.DeclineInvites {
+			respondedAt := time.Now()
+			collaborator.Status = "declined"
+			collaborator.RespondedAt = &respondedAt
+		}
+	}
+
+	err = s.repo.AddCollaborator(ctx, collaborator)
 	if err == nil && s.notifier != nil {
-		event, _ := s.repo.GetEventByID(ctx, eventID)
+		if collaborator.Status == "declined" {
+			title := "Your event invitation was declined"
+			content := "The invitee is out of office during event: " + event.Title
+			_ = s.notifier.NotifyUser(ctx, invitedBy, notification.EventInviteDeclined, title, content, nil, "calendar_event", eventID)
+			return nil
+		}
 		title := "You have been invited to collaborate on an event"
 		content := "Event: " + event.Title
+		if collaborator.OutOfOffice {
+			content += " (you are out of office at this time)"
+		}
 		_ = s.notifier.NotifyUser(ctx, invitedUserID, notification.EventInvite, title, content, nil, "calendar_event", eventID)
 	}
 	return err
@@ -1197,6 +1011,6 @@ func (s *service) recordCalendarActivity(ctx context.Context, event *CalendarEve
 		Details:   metadata,
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, dashboardEvent); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 }
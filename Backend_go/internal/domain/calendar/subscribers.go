@@ -0,0 +1,59 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/google/uuid"
+)
+
+// SubscribeRescheduleNotifications tells everyone who accepted an invite to an event, or
+// who attends it, when the event is moved
+func SubscribeRescheduleNotifications(bus *events.Bus, repo Repository, notifier notification.DomainNotifier) {
+	events.Subscribe(bus, "calendar_reschedule_notifications", events.Async, func(ctx context.Context, event events.EventRescheduled) error {
+		collaborators, err := repo.ListCollaboratorsByEventID(ctx, event.EventID)
+		if err != nil {
+			return fmt.Errorf("failed to list collaborators: %w", err)
+		}
+
+		title := "An event you are attending has been rescheduled"
+		content := fmt.Sprintf("Event: %s\nNow: %s - %s", event.Title,
+			event.StartTime.Format("Mon Jan 2 15:04 MST"), event.EndTime.Format("Mon Jan 2 15:04 MST"))
+		data := map[string]string{
+			"previous_start": event.PreviousStart.Format(time.RFC3339),
+			"start_time":     event.StartTime.Format(time.RFC3339),
+			"end_time":       event.EndTime.Format(time.RFC3339),
+		}
+		notified := map[uuid.UUID]bool{event.UserID: true}
+		for _, collaborator := range collaborators {
+			if collaborator.Status != "accepted" || notified[collaborator.UserID] {
+				continue
+			}
+			notified[collaborator.UserID] = true
+			if err := notifier.NotifyUser(ctx, collaborator.UserID, notification.EventRescheduled, title, content,
+				data, "calendar_event", event.EventID); err != nil {
+				return fmt.Errorf("failed to notify collaborator %s: %w", collaborator.UserID, err)
+			}
+		}
+
+		// Attendees who have not declined still plan to come
+		attendees, err := repo.ListAttendeesByEventID(ctx, event.EventID)
+		if err != nil {
+			return fmt.Errorf("failed to list attendees: %w", err)
+		}
+		for _, attendee := range attendees {
+			if attendee.UserID == nil || attendee.Status == AttendeeStatusDeclined || notified[*attendee.UserID] {
+				continue
+			}
+			notified[*attendee.UserID] = true
+			if err := notifier.NotifyUser(ctx, *attendee.UserID, notification.EventRescheduled, title, content,
+				data, "calendar_event", event.EventID); err != nil {
+				return fmt.Errorf("failed to notify attendee %s: %w", *attendee.UserID, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,112 @@
+package calendar
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// Users reads the timezone preferences events are created and shown in
+type Users interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*user.User, error)
+}
+
+// location resolves a stored event timezone. Events from before timezones were
+// stored, and zones the server no longer knows, fall back to UTC.
+func location(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := user.ParseTimezone(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// eventTimezone picks the timezone of a new event: the requested one, otherwise the
+// creator's preference, otherwise UTC
+func (s *service) eventTimezone(ctx context.Context, requested string, userID uuid.UUID) (string, error) {
+	if requested != "" {
+		if _, err := user.ParseTimezone(requested); err != nil {
+			return "", ErrInvalidTimezone
+		}
+		return requested, nil
+	}
+	if s.users == nil {
+		return time.UTC.String(), nil
+	}
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return time.UTC.String(), nil
+	}
+	if _, err := user.ParseTimezone(u.Timezone); err != nil {
+		return time.UTC.String(), nil
+	}
+	return u.Timezone, nil
+}
+
+// userLocation returns the zone a user wants times shown in
+func (s *service) userLocation(ctx context.Context, userID uuid.UUID) *time.Location {
+	if s.users == nil {
+		return time.UTC
+	}
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return time.UTC
+	}
+	return location(u.Timezone)
+}
+
+func (s *service) LocalizeEvents(ctx context.Context, userID uuid.UUID, events []CalendarEvent) {
+	if len(events) == 0 {
+		return
+	}
+	loc := s.userLocation(ctx, userID)
+	for i := range events {
+		localizeEvent(&events[i], loc)
+	}
+}
+
+// localizeEvent converts the times of an event, its series and its occurrences to loc
+func localizeEvent(event *CalendarEvent, loc *time.Location) {
+	event.StartTime = event.StartTime.In(loc)
+	event.EndTime = event.EndTime.In(loc)
+	for i := range event.RecurrenceRules {
+		rule := &event.RecurrenceRules[i]
+		if rule.Until != nil {
+			until := rule.Until.In(loc)
+			rule.Until = &until
+		}
+	}
+	for i := range event.Occurrences {
+		occ := &event.Occurrences[i]
+		occ.OccurrenceTime = occ.OccurrenceTime.In(loc)
+		if occ.EndTime != nil {
+			end := occ.EndTime.In(loc)
+			occ.EndTime = &end
+		}
+	}
+	for i := range event.Exceptions {
+		exception := &event.Exceptions[i]
+		exception.OriginalTime = exception.OriginalTime.In(loc)
+		if exception.OverrideStartTime != nil {
+			start := exception.OverrideStartTime.In(loc)
+			exception.OverrideStartTime = &start
+		}
+		if exception.OverrideEndTime != nil {
+			end := exception.OverrideEndTime.In(loc)
+			exception.OverrideEndTime = &end
+		}
+	}
+}
+
+// withTimeOf returns the date of day at the time of day of clock, both read in loc. It
+// keeps a series at the same local time when its time of day changes.
+func withTimeOf(day, clock time.Time, loc *time.Location) time.Time {
+	day, clock = day.In(loc), clock.In(loc)
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), loc).UTC()
+}
@@ -0,0 +1,54 @@
+package chat
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/integrations"
+	"github.com/google/uuid"
+)
+
+// IntegrationSource reports an organization's chat installations on its integrations page
+type IntegrationSource struct {
+	repo    Repository
+	service Service
+}
+
+// NewIntegrationSource creates an integration source for chat installations
+func NewIntegrationSource(repo Repository, service Service) *IntegrationSource {
+	return &IntegrationSource{repo: repo, service: service}
+}
+
+// List returns the chat installations of an organization
+func (s *IntegrationSource) List(ctx context.Context, orgID uuid.UUID) ([]integrations.Integration, error) {
+	installations, err := s.repo.ListInstallations(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]integrations.Integration, 0, len(installations))
+	for _, installation := range installations {
+		items = append(items, integrations.Integration{
+			ID:          installation.ID,
+			Provider:    string(installation.Provider),
+			Name:        installation.TeamName,
+			ConnectedAt: installation.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// Reconnect starts the app install again; completing it replaces the installation's token
+func (s *IntegrationSource) Reconnect(ctx context.Context, orgID, userID, id uuid.UUID, input integrations.ReconnectInput) (string, error) {
+	installation, err := s.repo.FindInstallationByID(ctx, id)
+	if errors.Is(err, ErrInstallationNotFound) || (err == nil && installation.OrganizationID != orgID) {
+		return "", integrations.ErrIntegrationNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.service.StartInstall(ctx, installation.Provider, orgID, userID)
+}
+
+func (i *Installation) integrationRef() integrations.Ref {
+	return integrations.Ref{ID: i.ID, OrganizationID: i.OrganizationID, Provider: string(i.Provider)}
+}
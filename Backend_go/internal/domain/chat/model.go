@@ -0,0 +1,204 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// ProviderName identifies a chat platform
+type ProviderName string
+
+const (
+	ProviderSlack ProviderName = "slack"
+	ProviderTeams ProviderName = "teams"
+)
+
+// IsValid checks if the provider is supported
+func (p ProviderName) IsValid() bool {
+	switch p {
+	case ProviderSlack, ProviderTeams:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportedEvents lists the events that can be posted to chat channels
+var SupportedEvents = []webhooks.EventType{
+	webhooks.EventTaskCreated,
+	webhooks.EventTaskUpdated,
+	webhooks.EventTaskStatusChanged,
+	webhooks.EventTaskAssigned,
+	webhooks.EventTaskDeleted,
+	webhooks.EventWorkflowExecutionStarted,
+	webhooks.EventWorkflowExecutionFinished,
+}
+
+// IsSupportedEvent checks if the event can be posted to chat channels
+func IsSupportedEvent(eventType webhooks.EventType) bool {
+	if eventType == webhooks.EventAll {
+		return true
+	}
+	for _, e := range SupportedEvents {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrInstallationNotFound = errors.New("chat installation not found")
+	ErrChannelNotFound      = errors.New("chat channel not found")
+	ErrProviderNotSupported = errors.New("chat provider is not supported")
+	ErrProviderDisabled     = errors.New("chat provider is not configured")
+	ErrInvalidState         = errors.New("invalid or expired install state")
+	ErrInvalidSignature     = errors.New("invalid request signature")
+	ErrInvalidEvent         = errors.New("event cannot be posted to chat")
+	ErrInvalidInput         = errors.New("invalid input")
+	ErrInvalidLinkToken     = errors.New("invalid or expired link token")
+	ErrUserNotLinked        = errors.New("chat user is not linked to a Compass account")
+)
+
+// Installation connects an organization to a Slack workspace or a Teams tenant
+type Installation struct {
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID    `json:"organization_id" gorm:"type:uuid;not null;index:idx_chat_installation_org"`
+	Provider       ProviderName `json:"provider" gorm:"type:varchar(20);not null;uniqueIndex:idx_chat_installation_team"`
+	// ExternalID is the Slack team ID or the Teams tenant ID
+	ExternalID  string `json:"external_id" gorm:"type:varchar(255);not null;uniqueIndex:idx_chat_installation_team"`
+	TeamName    string `json:"team_name,omitempty" gorm:"type:varchar(255)"`
+	AccessToken string `json:"-" gorm:"type:text"`
+	BotUserID   string `json:"bot_user_id,omitempty" gorm:"type:varchar(255)"`
+	// SigningSecret verifies commands sent by Teams outgoing webhooks
+	SigningSecret    string     `json:"-" gorm:"type:text"`
+	DefaultProjectID *uuid.UUID `json:"default_project_id,omitempty" gorm:"type:uuid"`
+	InstalledBy      uuid.UUID  `json:"installed_by" gorm:"type:uuid;not null"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Installation model
+func (Installation) TableName() string {
+	return "chat_installations"
+}
+
+// BeforeCreate is called before creating a new installation record
+func (i *Installation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	i.CreatedAt = time.Now()
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating an installation record
+func (i *Installation) BeforeUpdate(tx *gorm.DB) error {
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// Channel is a chat channel that receives the organization's events.
+// For Teams, ChannelID holds the channel's incoming webhook URL.
+type Channel struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	InstallationID uuid.UUID      `json:"installation_id" gorm:"type:uuid;not null;index:idx_chat_channel_installation"`
+	ChannelID      string         `json:"channel_id" gorm:"type:text;not null"`
+	ChannelName    string         `json:"channel_name,omitempty" gorm:"type:varchar(255)"`
+	Events         pq.StringArray `json:"events" gorm:"type:text[]"`
+	// ProjectID limits task events to a single project
+	ProjectID *uuid.UUID `json:"project_id,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Channel model
+func (Channel) TableName() string {
+	return "chat_channels"
+}
+
+// BeforeCreate is called before creating a new channel record
+func (c *Channel) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating a channel record
+func (c *Channel) BeforeUpdate(tx *gorm.DB) error {
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// Subscribes reports whether the channel wants events of the given type
+func (c *Channel) Subscribes(eventType webhooks.EventType) bool {
+	for _, e := range c.Events {
+		if webhooks.EventType(e) == webhooks.EventAll || webhooks.EventType(e) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// UserLink maps a chat user to the Compass account that runs their slash commands
+type UserLink struct {
+	InstallationID uuid.UUID `json:"installation_id" gorm:"type:uuid;primaryKey"`
+	ExternalUserID string    `json:"external_user_id" gorm:"type:varchar(255);primaryKey"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_chat_user_link_user"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the UserLink model
+func (UserLink) TableName() string {
+	return "chat_user_links"
+}
+
+// Message is a notification rendered for a chat channel
+type Message struct {
+	Title string
+	Text  string
+	URL   string
+}
+
+// SlashCommand is a command typed by a chat user, normalized across providers
+type SlashCommand struct {
+	Provider       ProviderName
+	ExternalID     string
+	ExternalUserID string
+	ChannelID      string
+	Text           string
+}
+
+// Args splits the command text into words, keeping quoted phrases together
+func (c SlashCommand) Args() []string {
+	var args []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range c.Text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			if current.Len() > 0 {
+				args = append(args, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		args = append(args, current.String())
+	}
+	return args
+}
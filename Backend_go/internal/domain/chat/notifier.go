@@ -0,0 +1,175 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/integrations"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// postTimeout bounds a single message sent to a chat platform
+const postTimeout = 10 * time.Second
+
+// Notifier posts task and workflow events to the chat channels of the organization they happened in.
+// It implements webhooks.Publisher so that it receives the same events as outbound webhooks.
+type Notifier struct {
+	repo      Repository
+	providers map[ProviderName]Provider
+	appURL    string
+	health    integrations.Recorder
+	logger    *zap.Logger
+}
+
+// NewNotifier creates a chat notifier. appURL is the web app base used for links in messages.
+// The outcome of every post is reported to health.
+func NewNotifier(repo Repository, providers []Provider, appURL string, health integrations.Recorder, logger *zap.Logger) *Notifier {
+	byName := make(map[ProviderName]Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &Notifier{
+		repo:      repo,
+		providers: byName,
+		appURL:    strings.TrimRight(appURL, "/"),
+		health:    health,
+		logger:    logger,
+	}
+}
+
+// Publish posts the event to every subscribed channel in the background
+func (n *Notifier) Publish(ctx context.Context, event webhooks.Event) {
+	if event.OrganizationID == uuid.Nil || event.Type == webhooks.EventAll || !IsSupportedEvent(event.Type) {
+		return
+	}
+	go n.deliver(event)
+}
+
+func (n *Notifier) deliver(event webhooks.Event) {
+	ctx := context.Background()
+
+	installations, channels, err := n.repo.ListOrganizationChannels(ctx, event.OrganizationID)
+	if err != nil {
+		n.logger.Error("Failed to load chat channels", zap.String("event", string(event.Type)), zap.Error(err))
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	payload := decodeEventPayload(event)
+	message := n.render(event.Type, payload)
+
+	byID := make(map[uuid.UUID]*Installation, len(installations))
+	for i := range installations {
+		byID[installations[i].ID] = &installations[i]
+	}
+
+	for i := range channels {
+		channel := &channels[i]
+		if !channel.Subscribes(event.Type) {
+			continue
+		}
+		if channel.ProjectID != nil && (payload.Task == nil || payload.Task.ProjectID != *channel.ProjectID) {
+			continue
+		}
+		installation := byID[channel.InstallationID]
+		provider, ok := n.providers[installation.Provider]
+		if !ok {
+			continue
+		}
+
+		postCtx, cancel := context.WithTimeout(ctx, postTimeout)
+		err := provider.PostMessage(postCtx, installation, channel.ChannelID, message)
+		cancel()
+		if err != nil {
+			n.logger.Warn("Failed to post event to chat channel",
+				zap.String("event", string(event.Type)),
+				zap.String("provider", string(installation.Provider)),
+				zap.String("channel_id", channel.ID.String()),
+				zap.Error(err))
+		}
+		if n.health != nil {
+			ref := installation.integrationRef()
+			if err != nil {
+				n.health.RecordFailure(ctx, ref, err)
+			} else {
+				n.health.RecordSuccess(ctx, ref)
+			}
+		}
+	}
+}
+
+// eventPayload holds the fields of task and workflow events that messages are rendered from
+type eventPayload struct {
+	Task *struct {
+		ID        uuid.UUID `json:"id"`
+		Title     string    `json:"title"`
+		Status    string    `json:"status"`
+		Priority  string    `json:"priority"`
+		ProjectID uuid.UUID `json:"project_id"`
+	} `json:"task"`
+	WorkflowID   uuid.UUID `json:"workflow_id"`
+	WorkflowName string    `json:"workflow_name"`
+	Execution    *struct {
+		Status string  `json:"status"`
+		Error  *string `json:"error"`
+	} `json:"execution"`
+}
+
+func decodeEventPayload(event webhooks.Event) eventPayload {
+	var payload eventPayload
+	if b, err := json.Marshal(event.Data); err == nil {
+		_ = json.Unmarshal(b, &payload)
+	}
+	return payload
+}
+
+func (n *Notifier) render(eventType webhooks.EventType, payload eventPayload) Message {
+	if payload.Task != nil {
+		task := payload.Task
+		titles := map[webhooks.EventType]string{
+			webhooks.EventTaskCreated:       "Task created",
+			webhooks.EventTaskUpdated:       "Task updated",
+			webhooks.EventTaskStatusChanged: "Task status changed",
+			webhooks.EventTaskAssigned:      "Task assigned",
+			webhooks.EventTaskDeleted:       "Task deleted",
+		}
+		message := Message{
+			Title: titles[eventType],
+			Text:  fmt.Sprintf("%s\nStatus: %s · Priority: %s", task.Title, task.Status, task.Priority),
+		}
+		if eventType != webhooks.EventTaskDeleted {
+			message.URL = n.link(fmt.Sprintf("/projects/%s/tasks/%s", task.ProjectID, task.ID))
+		}
+		return message
+	}
+
+	message := Message{
+		Title: "Workflow started",
+		Text:  payload.WorkflowName,
+		URL:   n.link(fmt.Sprintf("/workflows/%s", payload.WorkflowID)),
+	}
+	if eventType == webhooks.EventWorkflowExecutionFinished {
+		message.Title = "Workflow finished"
+		if payload.Execution != nil {
+			message.Text = fmt.Sprintf("%s\nStatus: %s", payload.WorkflowName, payload.Execution.Status)
+			if payload.Execution.Error != nil && *payload.Execution.Error != "" {
+				message.Text += "\nError: " + *payload.Execution.Error
+			}
+		}
+	}
+	return message
+}
+
+func (n *Notifier) link(path string) string {
+	if n.appURL == "" {
+		return ""
+	}
+	return n.appURL + path
+}
@@ -0,0 +1,50 @@
+package chat
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ProviderConfig holds the OAuth client and signing credentials of a chat app
+type ProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// SigningSecret verifies slash commands; Teams uses a secret per installation instead
+	SigningSecret string
+}
+
+// Enabled reports whether the provider has been configured
+func (c ProviderConfig) Enabled() bool {
+	return c.ClientID != "" && c.ClientSecret != ""
+}
+
+// InstallResult is what a provider returns after its OAuth install flow completes
+type InstallResult struct {
+	ExternalID  string
+	TeamName    string
+	AccessToken string
+	BotUserID   string
+	// InstallerID is the chat user who installed the app, when the provider reports it
+	InstallerID string
+}
+
+// Provider adapts a chat platform to installs, channel messages and slash commands
+type Provider interface {
+	Name() ProviderName
+	// AuthorizeURL returns the page an organization admin visits to install the app
+	AuthorizeURL(state string) string
+	// CompleteInstall exchanges the query parameters of the OAuth callback for an installation
+	CompleteInstall(ctx context.Context, params url.Values) (*InstallResult, error)
+	// PostMessage sends a message to a channel of an installation
+	PostMessage(ctx context.Context, installation *Installation, channelID string, message Message) error
+	// ParseCommand reads a slash command from an unverified request body
+	ParseCommand(header http.Header, body []byte) (*SlashCommand, error)
+	// VerifyCommand checks the signature of a command request sent to an installation
+	VerifyCommand(installation *Installation, header http.Header, body []byte) error
+	// CommandResponse builds the response body returned to the chat platform
+	CommandResponse(text string, public bool) interface{}
+	// ValidateChannel checks a channel identifier before it is subscribed
+	ValidateChannel(channelID string) error
+}
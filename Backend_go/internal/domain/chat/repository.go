@@ -0,0 +1,178 @@
+package chat
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for chat integration data access
+type Repository interface {
+	SaveInstallation(ctx context.Context, installation *Installation) error
+	UpdateInstallation(ctx context.Context, installation *Installation) error
+	DeleteInstallation(ctx context.Context, id uuid.UUID) error
+	FindInstallationByID(ctx context.Context, id uuid.UUID) (*Installation, error)
+	FindInstallationByExternalID(ctx context.Context, provider ProviderName, externalID string) (*Installation, error)
+	ListInstallations(ctx context.Context, orgID uuid.UUID) ([]Installation, error)
+
+	CreateChannel(ctx context.Context, channel *Channel) error
+	DeleteChannel(ctx context.Context, installationID, id uuid.UUID) error
+	ListChannels(ctx context.Context, installationID uuid.UUID) ([]Channel, error)
+	ListOrganizationChannels(ctx context.Context, orgID uuid.UUID) ([]Installation, []Channel, error)
+
+	SaveUserLink(ctx context.Context, link *UserLink) error
+	FindUserLink(ctx context.Context, installationID uuid.UUID, externalUserID string) (*UserLink, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new chat integration repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// SaveInstallation stores an installation, replacing the token of an existing
+// installation of the same workspace when the app is reinstalled
+func (r *repository) SaveInstallation(ctx context.Context, installation *Installation) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "provider"}, {Name: "external_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"organization_id", "team_name", "access_token", "bot_user_id", "installed_by", "updated_at",
+		}),
+	}).Create(installation).Error
+}
+
+// UpdateInstallation saves changes to an installation
+func (r *repository) UpdateInstallation(ctx context.Context, installation *Installation) error {
+	return r.db.WithContext(ctx).Save(installation).Error
+}
+
+// DeleteInstallation removes an installation together with its channels and user links
+func (r *repository) DeleteInstallation(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("installation_id = ?", id).Delete(&Channel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("installation_id = ?", id).Delete(&UserLink{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Installation{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInstallationNotFound
+		}
+		return nil
+	})
+}
+
+// FindInstallationByID retrieves an installation by its ID
+func (r *repository) FindInstallationByID(ctx context.Context, id uuid.UUID) (*Installation, error) {
+	var installation Installation
+	if err := r.db.WithContext(ctx).First(&installation, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInstallationNotFound
+		}
+		return nil, err
+	}
+	return &installation, nil
+}
+
+// FindInstallationByExternalID retrieves the installation of a Slack workspace or Teams tenant
+func (r *repository) FindInstallationByExternalID(ctx context.Context, provider ProviderName, externalID string) (*Installation, error) {
+	var installation Installation
+	err := r.db.WithContext(ctx).
+		First(&installation, "provider = ? AND external_id = ?", provider, externalID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInstallationNotFound
+		}
+		return nil, err
+	}
+	return &installation, nil
+}
+
+// ListInstallations returns the chat installations of an organization
+func (r *repository) ListInstallations(ctx context.Context, orgID uuid.UUID) ([]Installation, error) {
+	var installations []Installation
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("created_at ASC").
+		Find(&installations).Error
+	return installations, err
+}
+
+// CreateChannel stores a new channel subscription
+func (r *repository) CreateChannel(ctx context.Context, channel *Channel) error {
+	return r.db.WithContext(ctx).Create(channel).Error
+}
+
+// DeleteChannel removes a channel subscription of an installation
+func (r *repository) DeleteChannel(ctx context.Context, installationID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&Channel{}, "id = ? AND installation_id = ?", id, installationID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChannelNotFound
+	}
+	return nil
+}
+
+// ListChannels returns the channels subscribed through an installation
+func (r *repository) ListChannels(ctx context.Context, installationID uuid.UUID) ([]Channel, error) {
+	var channels []Channel
+	err := r.db.WithContext(ctx).
+		Where("installation_id = ?", installationID).
+		Order("created_at ASC").
+		Find(&channels).Error
+	return channels, err
+}
+
+// ListOrganizationChannels returns every installation of an organization with all their channels
+func (r *repository) ListOrganizationChannels(ctx context.Context, orgID uuid.UUID) ([]Installation, []Channel, error) {
+	installations, err := r.ListInstallations(ctx, orgID)
+	if err != nil || len(installations) == 0 {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, len(installations))
+	for i, installation := range installations {
+		ids[i] = installation.ID
+	}
+
+	var channels []Channel
+	if err := r.db.WithContext(ctx).Where("installation_id IN ?", ids).Find(&channels).Error; err != nil {
+		return nil, nil, err
+	}
+	return installations, channels, nil
+}
+
+// SaveUserLink links a chat user to a Compass account, replacing an earlier link
+func (r *repository) SaveUserLink(ctx context.Context, link *UserLink) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "installation_id"}, {Name: "external_user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id"}),
+	}).Create(link).Error
+}
+
+// FindUserLink retrieves the Compass account linked to a chat user
+func (r *repository) FindUserLink(ctx context.Context, installationID uuid.UUID, externalUserID string) (*UserLink, error) {
+	var link UserLink
+	err := r.db.WithContext(ctx).
+		First(&link, "installation_id = ? AND external_user_id = ?", installationID, externalUserID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotLinked
+		}
+		return nil, err
+	}
+	return &link, nil
+}
@@ -0,0 +1,597 @@
+package chat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/commands"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	installStateKeyPrefix = "chat:install:"
+	linkTokenKeyPrefix    = "chat:link:"
+	installStateTTL       = 10 * time.Minute
+	linkTokenTTL          = 15 * time.Minute
+)
+
+// PermissionSource resolves the permissions a linked user runs commands with
+type PermissionSource interface {
+	GetUserRolesAndPermissions(ctx context.Context, userID uuid.UUID) ([]string, []string, error)
+}
+
+// MembershipResolver checks that a user belongs to an organization
+type MembershipResolver interface {
+	ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*organization.Membership, error)
+}
+
+// UpdateInstallationInput holds the settings that can be changed on an installation
+type UpdateInstallationInput struct {
+	DefaultProjectID *uuid.UUID
+	// SigningSecret is the security token of the Teams outgoing webhook
+	SigningSecret *string
+}
+
+// AddChannelInput describes a channel to post events to
+type AddChannelInput struct {
+	ChannelID   string
+	ChannelName string
+	Events      []string
+	ProjectID   *uuid.UUID
+}
+
+// Service defines the interface for chat integration business logic. The methods that
+// manage an organization's installations leave checking the user's permission to the
+// caller.
+type Service interface {
+	StartInstall(ctx context.Context, provider ProviderName, orgID, userID uuid.UUID) (string, error)
+	CompleteInstall(ctx context.Context, provider ProviderName, params url.Values) (*Installation, error)
+	ListInstallations(ctx context.Context, orgID uuid.UUID) ([]Installation, error)
+	UpdateInstallation(ctx context.Context, orgID, id uuid.UUID, input UpdateInstallationInput) (*Installation, error)
+	DeleteInstallation(ctx context.Context, orgID, id uuid.UUID) error
+
+	ListChannels(ctx context.Context, orgID, installationID uuid.UUID) ([]Channel, error)
+	AddChannel(ctx context.Context, orgID, installationID uuid.UUID, input AddChannelInput) (*Channel, error)
+	RemoveChannel(ctx context.Context, orgID, installationID, channelID uuid.UUID) error
+
+	LinkUser(ctx context.Context, token string, userID uuid.UUID) (*UserLink, error)
+	HandleCommand(ctx context.Context, provider ProviderName, header http.Header, body []byte) (interface{}, error)
+}
+
+type service struct {
+	repo        Repository
+	providers   map[ProviderName]Provider
+	projects    project.Service
+	commands    commands.Service
+	permissions PermissionSource
+	memberships MembershipResolver
+	redis       *redis.Client
+	appURL      string
+	logger      *zap.Logger
+}
+
+// NewService creates a new chat integration service. Only configured providers are passed in.
+func NewService(repo Repository, providers []Provider, projectService project.Service, commandService commands.Service,
+	permissions PermissionSource, memberships MembershipResolver, redisClient *cache.RedisClient, appURL string, logger *zap.Logger) Service {
+	byName := make(map[ProviderName]Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &service{
+		repo:        repo,
+		providers:   byName,
+		projects:    projectService,
+		commands:    commandService,
+		permissions: permissions,
+		memberships: memberships,
+		redis:       redisClient.GetClient(),
+		appURL:      strings.TrimRight(appURL, "/"),
+		logger:      logger,
+	}
+}
+
+// installState is kept in Redis between StartInstall and the provider's OAuth callback
+type installState struct {
+	Provider       ProviderName `json:"provider"`
+	OrganizationID uuid.UUID    `json:"organization_id"`
+	UserID         uuid.UUID    `json:"user_id"`
+}
+
+// linkRequest is kept in Redis between the link command and the user confirming it in Compass
+type linkRequest struct {
+	InstallationID uuid.UUID `json:"installation_id"`
+	ExternalUserID string    `json:"external_user_id"`
+}
+
+// StartInstall returns the provider page where an organization admin installs the app
+func (s *service) StartInstall(ctx context.Context, providerName ProviderName, orgID, userID uuid.UUID) (string, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(installState{Provider: providerName, OrganizationID: orgID, UserID: userID})
+	if err != nil {
+		return "", err
+	}
+	if err := s.redis.Set(ctx, installStateKeyPrefix+state, data, installStateTTL).Err(); err != nil {
+		return "", err
+	}
+	return provider.AuthorizeURL(state), nil
+}
+
+// CompleteInstall finishes the OAuth install started by StartInstall
+func (s *service) CompleteInstall(ctx context.Context, providerName ProviderName, params url.Values) (*Installation, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	var state installState
+	if err := s.consume(ctx, installStateKeyPrefix+params.Get("state"), &state); err != nil {
+		return nil, ErrInvalidState
+	}
+	if state.Provider != providerName {
+		return nil, ErrInvalidState
+	}
+
+	result, err := provider.CompleteInstall(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveInstallation(ctx, &Installation{
+		OrganizationID: state.OrganizationID,
+		Provider:       providerName,
+		ExternalID:     result.ExternalID,
+		TeamName:       result.TeamName,
+		AccessToken:    result.AccessToken,
+		BotUserID:      result.BotUserID,
+		InstalledBy:    state.UserID,
+	}); err != nil {
+		return nil, err
+	}
+	installation, err := s.repo.FindInstallationByExternalID(ctx, providerName, result.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The installer can run commands straight away
+	if result.InstallerID != "" {
+		if err := s.repo.SaveUserLink(ctx, &UserLink{
+			InstallationID: installation.ID,
+			ExternalUserID: result.InstallerID,
+			UserID:         state.UserID,
+		}); err != nil {
+			tracing.Logger(ctx, s.logger).Warn("Failed to link chat installer", zap.String("installation_id", installation.ID.String()), zap.Error(err))
+		}
+	}
+
+	tracing.Logger(ctx, s.logger).Info("Chat app installed",
+		zap.String("provider", string(providerName)),
+		zap.String("organization_id", installation.OrganizationID.String()),
+		zap.String("installation_id", installation.ID.String()))
+	return installation, nil
+}
+
+// ListInstallations returns the chat installations of an organization
+func (s *service) ListInstallations(ctx context.Context, orgID uuid.UUID) ([]Installation, error) {
+	return s.repo.ListInstallations(ctx, orgID)
+}
+
+// UpdateInstallation changes the default project or the Teams signing secret of an installation
+func (s *service) UpdateInstallation(ctx context.Context, orgID, id uuid.UUID, input UpdateInstallationInput) (*Installation, error) {
+	installation, err := s.getInOrganization(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.DefaultProjectID != nil {
+		if *input.DefaultProjectID == uuid.Nil {
+			installation.DefaultProjectID = nil
+		} else {
+			if err := s.checkProject(ctx, installation, *input.DefaultProjectID); err != nil {
+				return nil, err
+			}
+			installation.DefaultProjectID = input.DefaultProjectID
+		}
+	}
+	if input.SigningSecret != nil {
+		if installation.Provider != ProviderTeams {
+			return nil, fmt.Errorf("%w: signing_secret only applies to Teams", ErrInvalidInput)
+		}
+		installation.SigningSecret = strings.TrimSpace(*input.SigningSecret)
+	}
+
+	if err := s.repo.UpdateInstallation(ctx, installation); err != nil {
+		return nil, err
+	}
+	return installation, nil
+}
+
+// DeleteInstallation disconnects the chat workspace from the organization
+func (s *service) DeleteInstallation(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.getInOrganization(ctx, orgID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteInstallation(ctx, id)
+}
+
+// ListChannels returns the channels an installation posts events to
+func (s *service) ListChannels(ctx context.Context, orgID, installationID uuid.UUID) ([]Channel, error) {
+	if _, err := s.getInOrganization(ctx, orgID, installationID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListChannels(ctx, installationID)
+}
+
+// AddChannel subscribes a channel to task and workflow events
+func (s *service) AddChannel(ctx context.Context, orgID, installationID uuid.UUID, input AddChannelInput) (*Channel, error) {
+	installation, err := s.getInOrganization(ctx, orgID, installationID)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := s.provider(installation.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	input.ChannelID = strings.TrimSpace(input.ChannelID)
+	if err := provider.ValidateChannel(input.ChannelID); err != nil {
+		return nil, err
+	}
+	if len(input.Events) == 0 {
+		input.Events = []string{string(webhooks.EventAll)}
+	}
+	for _, e := range input.Events {
+		if !IsSupportedEvent(webhooks.EventType(e)) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidEvent, e)
+		}
+	}
+
+	if input.ProjectID != nil {
+		if err := s.checkProject(ctx, installation, *input.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	channel := &Channel{
+		InstallationID: installationID,
+		ChannelID:      input.ChannelID,
+		ChannelName:    strings.TrimSpace(input.ChannelName),
+		Events:         input.Events,
+		ProjectID:      input.ProjectID,
+	}
+	if err := s.repo.CreateChannel(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// checkProject rejects projects outside the organization of the installation, whose tasks
+// chat commands would otherwise create in another organization
+func (s *service) checkProject(ctx context.Context, installation *Installation, projectID uuid.UUID) error {
+	proj, err := s.projects.GetProject(ctx, projectID)
+	if errors.Is(err, project.ErrProjectNotFound) || (err == nil && proj.OrganizationID != installation.OrganizationID) {
+		return fmt.Errorf("%w: project not found in the organization", ErrInvalidInput)
+	}
+	return err
+}
+
+// RemoveChannel stops posting events to a channel
+func (s *service) RemoveChannel(ctx context.Context, orgID, installationID, channelID uuid.UUID) error {
+	if _, err := s.getInOrganization(ctx, orgID, installationID); err != nil {
+		return err
+	}
+	return s.repo.DeleteChannel(ctx, installationID, channelID)
+}
+
+// LinkUser connects the chat user who ran the link command to the signed-in Compass account
+func (s *service) LinkUser(ctx context.Context, token string, userID uuid.UUID) (*UserLink, error) {
+	var request linkRequest
+	if token == "" || s.consume(ctx, linkTokenKeyPrefix+token, &request) != nil {
+		return nil, ErrInvalidLinkToken
+	}
+	installation, err := s.repo.FindInstallationByID(ctx, request.InstallationID)
+	if err != nil {
+		return nil, err
+	}
+	// Commands run as the linked account, so it must belong to the installing organization
+	if _, err := s.memberships.ResolveMembership(ctx, installation.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+
+	link := &UserLink{
+		InstallationID: request.InstallationID,
+		ExternalUserID: request.ExternalUserID,
+		UserID:         userID,
+	}
+	if err := s.repo.SaveUserLink(ctx, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// HandleCommand verifies a slash command, runs it through the command service as the linked
+// user and returns the provider response. Failures of the command itself are reported to the
+// chat user in the response; only requests that cannot be trusted return an error.
+func (s *service) HandleCommand(ctx context.Context, providerName ProviderName, header http.Header, body []byte) (interface{}, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := provider.ParseCommand(header, body)
+	if err != nil {
+		return nil, err
+	}
+	installation, err := s.repo.FindInstallationByExternalID(ctx, providerName, command.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.VerifyCommand(installation, header, body); err != nil {
+		return nil, err
+	}
+
+	args := command.Args()
+	if len(args) == 0 || strings.EqualFold(args[0], "help") {
+		return provider.CommandResponse(commandHelp, false), nil
+	}
+	if strings.EqualFold(args[0], "link") {
+		return provider.CommandResponse(s.startLink(ctx, installation, command), false), nil
+	}
+
+	link, err := s.repo.FindUserLink(ctx, installation.ID, command.ExternalUserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotLinked) {
+			return provider.CommandResponse("Your chat account is not linked to Compass yet. Run `link` first.", false), nil
+		}
+		return nil, err
+	}
+
+	reply, public := s.runCommand(ctx, installation, link.UserID, args)
+	return provider.CommandResponse(reply, public), nil
+}
+
+const commandHelp = "Compass commands:\n" +
+	"• `create task <title> [--project <name or id>] [--priority Low|Medium|High|Urgent] [--due YYYY-MM-DD]`\n" +
+	"• `create todo <title> [--due YYYY-MM-DD]`\n" +
+	"• `complete todo <todo id>`\n" +
+	"• `open project <name>`\n" +
+	"• `link` connects your chat account to Compass"
+
+// startLink issues a single-use token the chat user confirms while signed in to Compass
+func (s *service) startLink(ctx context.Context, installation *Installation, command *SlashCommand) string {
+	token, err := randomToken()
+	if err != nil {
+		return "Could not start linking, please try again."
+	}
+	data, _ := json.Marshal(linkRequest{InstallationID: installation.ID, ExternalUserID: command.ExternalUserID})
+	if err := s.redis.Set(ctx, linkTokenKeyPrefix+token, data, linkTokenTTL).Err(); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to store chat link token", zap.Error(err))
+		return "Could not start linking, please try again."
+	}
+
+	if s.appURL == "" {
+		return fmt.Sprintf("Sign in to Compass and confirm the link with token `%s` within 15 minutes.", token)
+	}
+	return fmt.Sprintf("Open %s/integrations/chat/link?token=%s within 15 minutes to link your account.", s.appURL, token)
+}
+
+// runCommand maps the words of a slash command to a command palette action
+func (s *service) runCommand(ctx context.Context, installation *Installation, userID uuid.UUID, args []string) (string, bool) {
+	_, permissions, err := s.permissions.GetUserRolesAndPermissions(ctx, userID)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to load permissions for chat command", zap.String("user_id", userID.String()), zap.Error(err))
+		return "Something went wrong, please try again.", false
+	}
+
+	words, flags := splitFlags(args)
+	verb := strings.ToLower(strings.Join(firstN(words, 2), " "))
+	rest := strings.Join(dropN(words, 2), " ")
+
+	input := commands.ExecuteInput{
+		UserID:         userID,
+		OrganizationID: installation.OrganizationID,
+		Permissions:    permissions,
+	}
+	cmdArgs := map[string]interface{}{}
+
+	switch verb {
+	case "create task":
+		input.Command = commands.CommandCreateTask
+		cmdArgs["title"] = rest
+		projectID, err := s.resolveProject(ctx, input, flags["project"], installation.DefaultProjectID)
+		if err != nil {
+			return commandErrorReply(err), false
+		}
+		cmdArgs["project_id"] = projectID
+		if priority := flags["priority"]; priority != "" {
+			cmdArgs["priority"] = priority
+		}
+	case "create todo":
+		input.Command = commands.CommandCreateTodo
+		cmdArgs["title"] = rest
+	case "complete todo":
+		input.Command = commands.CommandCompleteTodo
+		cmdArgs["todo_id"] = rest
+	case "open project":
+		input.Command = commands.CommandOpenProject
+		if id, err := uuid.Parse(rest); err == nil {
+			cmdArgs["project_id"] = id
+		} else {
+			cmdArgs["name"] = rest
+		}
+	default:
+		return "Unknown command. Run `help` to see what Compass can do.", false
+	}
+
+	if due := flags["due"]; due != "" {
+		dueDate, err := time.Parse("2006-01-02", due)
+		if err != nil {
+			return "Due dates must look like 2025-01-31.", false
+		}
+		cmdArgs["due_date"] = dueDate
+	}
+
+	input.Args, _ = json.Marshal(cmdArgs)
+	result, err := s.commands.Execute(ctx, input)
+	if err != nil {
+		return commandErrorReply(err), false
+	}
+	return s.commandReply(result), true
+}
+
+// resolveProject accepts a project ID or name, falling back to the installation's default project
+func (s *service) resolveProject(ctx context.Context, input commands.ExecuteInput, value string, fallback *uuid.UUID) (uuid.UUID, error) {
+	if value == "" {
+		if fallback == nil {
+			return uuid.Nil, fmt.Errorf("%w: --project is required", commands.ErrInvalidArguments)
+		}
+		return *fallback, nil
+	}
+	if id, err := uuid.Parse(value); err == nil {
+		return id, nil
+	}
+
+	input.Command = commands.CommandOpenProject
+	input.Args, _ = json.Marshal(map[string]string{"name": value})
+	result, err := s.commands.Execute(ctx, input)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	proj, ok := result.Data.(*project.Project)
+	if !ok {
+		return uuid.Nil, project.ErrProjectNotFound
+	}
+	return proj.ID, nil
+}
+
+func (s *service) commandReply(result *commands.Result) string {
+	var reply string
+	switch v := result.Data.(type) {
+	case *task.Task:
+		reply = fmt.Sprintf("Created task *%s*", v.Title)
+	case *todos.Todo:
+		if result.Command == commands.CommandCompleteTodo {
+			reply = fmt.Sprintf("Completed todo *%s*", v.Title)
+		} else {
+			reply = fmt.Sprintf("Created todo *%s*", v.Title)
+		}
+	case *project.Project:
+		reply = fmt.Sprintf("Project *%s*", v.Name)
+	default:
+		reply = "Done."
+	}
+	if result.Redirect != "" && s.appURL != "" {
+		reply += "\n" + s.appURL + result.Redirect
+	}
+	return reply
+}
+
+func commandErrorReply(err error) string {
+	switch {
+	case errors.Is(err, commands.ErrForbidden):
+		return "You don't have permission to do that."
+	case errors.Is(err, project.ErrProjectNotFound):
+		return "Project not found."
+	case errors.Is(err, todos.ErrTodoNotFound):
+		return "Todo not found."
+	case errors.Is(err, commands.ErrInvalidArguments), errors.Is(err, task.ErrInvalidInput),
+		errors.Is(err, todos.ErrInvalidInput), errors.Is(err, project.ErrInvalidInput):
+		return err.Error()
+	default:
+		return "Something went wrong, please try again."
+	}
+}
+
+// splitFlags separates "--name value" pairs from the positional words of a command
+func splitFlags(args []string) ([]string, map[string]string) {
+	words := make([]string, 0, len(args))
+	flags := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "--") && i+1 < len(args) {
+			flags[strings.ToLower(strings.TrimPrefix(args[i], "--"))] = args[i+1]
+			i++
+			continue
+		}
+		words = append(words, args[i])
+	}
+	return words, flags
+}
+
+func firstN(words []string, n int) []string {
+	if len(words) < n {
+		return words
+	}
+	return words[:n]
+}
+
+func dropN(words []string, n int) []string {
+	if len(words) < n {
+		return nil
+	}
+	return words[n:]
+}
+
+func (s *service) provider(name ProviderName) (Provider, error) {
+	if !name.IsValid() {
+		return nil, ErrProviderNotSupported
+	}
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrProviderDisabled
+	}
+	return provider, nil
+}
+
+// getInOrganization loads an installation, treating those of other organizations as missing
+func (s *service) getInOrganization(ctx context.Context, orgID, id uuid.UUID) (*Installation, error) {
+	installation, err := s.repo.FindInstallationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if installation.OrganizationID != orgID {
+		return nil, ErrInstallationNotFound
+	}
+	return installation, nil
+}
+
+// consume reads and deletes a single-use value stored in Redis
+func (s *service) consume(ctx context.Context, key string, dst interface{}) error {
+	data, err := s.redis.Get(ctx, key).Bytes()
+	if err != nil {
+		return err
+	}
+	s.redis.Del(ctx, key)
+	return json.Unmarshal(data, dst)
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,202 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	slackAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+	slackAPIURL       = "https://slack.com/api/"
+	slackBotScopes    = "commands,chat:write,chat:write.public"
+	// slackMaxSkew rejects replayed commands older than five minutes
+	slackMaxSkew = 5 * time.Minute
+)
+
+type slackProvider struct {
+	config ProviderConfig
+	client *http.Client
+}
+
+// NewSlackProvider creates the Slack adapter
+func NewSlackProvider(config ProviderConfig) Provider {
+	return &slackProvider{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *slackProvider) Name() ProviderName {
+	return ProviderSlack
+}
+
+func (p *slackProvider) AuthorizeURL(state string) string {
+	q := url.Values{}
+	q.Set("client_id", p.config.ClientID)
+	q.Set("scope", slackBotScopes)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("state", state)
+	return slackAuthorizeURL + "?" + q.Encode()
+}
+
+func (p *slackProvider) CompleteInstall(ctx context.Context, params url.Values) (*InstallResult, error) {
+	code := params.Get("code")
+	if code == "" {
+		return nil, fmt.Errorf("%w: missing authorization code", ErrInvalidInput)
+	}
+
+	form := url.Values{}
+	form.Set("client_id", p.config.ClientID)
+	form.Set("client_secret", p.config.ClientSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIURL+"oauth.v2.access", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp struct {
+		OK          bool   `json:"ok"`
+		Error       string `json:"error"`
+		AccessToken string `json:"access_token"`
+		BotUserID   string `json:"bot_user_id"`
+		Team        struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"team"`
+		AuthedUser struct {
+			ID string `json:"id"`
+		} `json:"authed_user"`
+	}
+	if err := p.call(req, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("slack install failed: %s", resp.Error)
+	}
+
+	return &InstallResult{
+		ExternalID:  resp.Team.ID,
+		TeamName:    resp.Team.Name,
+		AccessToken: resp.AccessToken,
+		BotUserID:   resp.BotUserID,
+		InstallerID: resp.AuthedUser.ID,
+	}, nil
+}
+
+func (p *slackProvider) PostMessage(ctx context.Context, installation *Installation, channelID string, message Message) error {
+	text := message.Text
+	if message.Title != "" {
+		text = "*" + message.Title + "*\n" + text
+	}
+	if message.URL != "" {
+		text += "\n<" + message.URL + "|Open in Compass>"
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"channel": channelID,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIURL+"chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+installation.AccessToken)
+
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := p.call(req, &resp); err != nil {
+		return err
+	}
+	if !resp.OK {
+		return fmt.Errorf("slack rejected message: %s", resp.Error)
+	}
+	return nil
+}
+
+func (p *slackProvider) ParseCommand(header http.Header, body []byte) (*SlashCommand, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if form.Get("team_id") == "" || form.Get("user_id") == "" {
+		return nil, fmt.Errorf("%w: missing team or user", ErrInvalidInput)
+	}
+	return &SlashCommand{
+		Provider:       ProviderSlack,
+		ExternalID:     form.Get("team_id"),
+		ExternalUserID: form.Get("user_id"),
+		ChannelID:      form.Get("channel_id"),
+		Text:           strings.TrimSpace(form.Get("text")),
+	}, nil
+}
+
+// VerifyCommand checks the X-Slack-Signature header, an HMAC-SHA256 over "v0:<timestamp>:<body>"
+func (p *slackProvider) VerifyCommand(installation *Installation, header http.Header, body []byte) error {
+	if p.config.SigningSecret == "" {
+		return ErrProviderDisabled
+	}
+
+	timestamp, err := strconv.ParseInt(header.Get("X-Slack-Request-Timestamp"), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if skew := time.Since(time.Unix(timestamp, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.config.SigningSecret))
+	mac.Write([]byte("v0:" + strconv.FormatInt(timestamp, 10) + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (p *slackProvider) CommandResponse(text string, public bool) interface{} {
+	responseType := "ephemeral"
+	if public {
+		responseType = "in_channel"
+	}
+	return map[string]string{"response_type": responseType, "text": text}
+}
+
+// ValidateChannel accepts Slack conversation IDs such as C024BE91L
+func (p *slackProvider) ValidateChannel(channelID string) error {
+	if channelID == "" || strings.ContainsAny(channelID, " #/") {
+		return fmt.Errorf("%w: channel_id must be a Slack channel ID", ErrInvalidInput)
+	}
+	return nil
+}
+
+func (p *slackProvider) call(req *http.Request, dst interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack responded with status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
@@ -0,0 +1,187 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const teamsAdminConsentURL = "https://login.microsoftonline.com/common/adminconsent"
+
+// teamsWebhookHosts are the domains Teams issues channel incoming webhook URLs on
+var teamsWebhookHosts = []string{".webhook.office.com", ".logic.azure.com"}
+
+var (
+	teamsMentionPattern = regexp.MustCompile(`<at>[^<]*</at>`)
+	teamsTagPattern     = regexp.MustCompile(`<[^>]+>`)
+)
+
+// teamsProvider installs through tenant admin consent, posts to channels through their
+// incoming webhooks and receives commands from an outgoing webhook mentioning the app
+type teamsProvider struct {
+	config ProviderConfig
+	client *http.Client
+}
+
+// NewTeamsProvider creates the Microsoft Teams adapter
+func NewTeamsProvider(config ProviderConfig) Provider {
+	return &teamsProvider{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *teamsProvider) Name() ProviderName {
+	return ProviderTeams
+}
+
+func (p *teamsProvider) AuthorizeURL(state string) string {
+	q := url.Values{}
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("state", state)
+	return teamsAdminConsentURL + "?" + q.Encode()
+}
+
+func (p *teamsProvider) CompleteInstall(ctx context.Context, params url.Values) (*InstallResult, error) {
+	if errCode := params.Get("error"); errCode != "" {
+		return nil, fmt.Errorf("teams install failed: %s %s", errCode, params.Get("error_description"))
+	}
+	tenant := params.Get("tenant")
+	if tenant == "" || !strings.EqualFold(params.Get("admin_consent"), "true") {
+		return nil, fmt.Errorf("%w: admin consent was not granted", ErrInvalidInput)
+	}
+	return &InstallResult{ExternalID: tenant, TeamName: tenant}, nil
+}
+
+func (p *teamsProvider) PostMessage(ctx context.Context, installation *Installation, channelID string, message Message) error {
+	if err := p.ValidateChannel(channelID); err != nil {
+		return err
+	}
+
+	card := map[string]interface{}{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  message.Title,
+		"title":    message.Title,
+		"text":     message.Text,
+	}
+	if message.URL != "" {
+		card["potentialAction"] = []map[string]interface{}{{
+			"@type":   "OpenUri",
+			"name":    "Open in Compass",
+			"targets": []map[string]string{{"os": "default", "uri": message.URL}},
+		}}
+	}
+	body, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channelID, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("teams responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *teamsProvider) ParseCommand(header http.Header, body []byte) (*SlashCommand, error) {
+	var activity struct {
+		Text string `json:"text"`
+		From struct {
+			ID          string `json:"id"`
+			AADObjectID string `json:"aadObjectId"`
+		} `json:"from"`
+		ChannelData struct {
+			Tenant struct {
+				ID string `json:"id"`
+			} `json:"tenant"`
+			Channel struct {
+				ID string `json:"id"`
+			} `json:"channel"`
+		} `json:"channelData"`
+	}
+	if err := json.Unmarshal(body, &activity); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	userID := activity.From.AADObjectID
+	if userID == "" {
+		userID = activity.From.ID
+	}
+	if activity.ChannelData.Tenant.ID == "" || userID == "" {
+		return nil, fmt.Errorf("%w: missing tenant or user", ErrInvalidInput)
+	}
+
+	// The message arrives as HTML with the app's mention in front of the command
+	text := teamsMentionPattern.ReplaceAllString(activity.Text, "")
+	text = teamsTagPattern.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, "&nbsp;", " ")
+
+	return &SlashCommand{
+		Provider:       ProviderTeams,
+		ExternalID:     activity.ChannelData.Tenant.ID,
+		ExternalUserID: userID,
+		ChannelID:      activity.ChannelData.Channel.ID,
+		Text:           strings.TrimSpace(text),
+	}, nil
+}
+
+// VerifyCommand checks the "Authorization: HMAC <signature>" header of an outgoing webhook,
+// an HMAC-SHA256 over the body keyed with the base64 security token Teams issued for it
+func (p *teamsProvider) VerifyCommand(installation *Installation, header http.Header, body []byte) error {
+	if installation.SigningSecret == "" {
+		return ErrInvalidSignature
+	}
+	key, err := base64.StdEncoding.DecodeString(installation.SigningSecret)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	expected := "HMAC " + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get("Authorization"))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (p *teamsProvider) CommandResponse(text string, public bool) interface{} {
+	return map[string]string{"type": "message", "text": text}
+}
+
+// ValidateChannel accepts the incoming webhook URL Teams generates for a channel
+func (p *teamsProvider) ValidateChannel(channelID string) error {
+	u, err := url.Parse(channelID)
+	if err != nil || u.Scheme != "https" {
+		return fmt.Errorf("%w: channel_id must be the channel's incoming webhook URL", ErrInvalidInput)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, suffix := range teamsWebhookHosts {
+		if strings.HasSuffix(host, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: channel_id must be the channel's incoming webhook URL", ErrInvalidInput)
+}
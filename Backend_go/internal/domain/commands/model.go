@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"errors"
+)
+
+// Command identifiers understood by the command palette
+const (
+	CommandCreateTask   = "task.create"
+	CommandOpenProject  = "project.open"
+	CommandCreateTodo   = "todo.create"
+	CommandCompleteTodo = "todo.complete"
+)
+
+var (
+	ErrUnknownCommand   = errors.New("unknown command")
+	ErrInvalidArguments = errors.New("invalid command arguments")
+	ErrForbidden        = errors.New("insufficient permissions for command")
+)
+
+// Argument describes a single argument accepted by a command
+type Argument struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// Command describes an action that can be run from the command palette
+type Command struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Keywords    []string   `json:"keywords,omitempty"`
+	Permission  string     `json:"permission,omitempty"`
+	Arguments   []Argument `json:"arguments"`
+}
+
+// Result is returned after a command has been executed
+type Result struct {
+	Command  string      `json:"command"`
+	Data     interface{} `json:"data"`
+	Redirect string      `json:"redirect,omitempty"`
+}
+
+// Available reports whether a caller holding the given permissions can see and run the command
+func (c Command) Available(permissions []string) bool {
+	if c.Permission == "" {
+		return true
+	}
+	for _, p := range permissions {
+		if p == c.Permission {
+			return true
+		}
+	}
+	return false
+}
+
+// catalog lists the commands in the order they are offered to clients
+var catalog = []Command{
+	{
+		ID:          CommandCreateTask,
+		Title:       "Create task",
+		Description: "Create a new task in a project",
+		Keywords:    []string{"new", "task", "add"},
+		Permission:  "tasks:create",
+		Arguments: []Argument{
+			{Name: "title", Type: "string", Required: true},
+			{Name: "project_id", Type: "uuid", Required: true},
+			{Name: "description", Type: "string"},
+			{Name: "priority", Type: "string", Description: "Low, Medium, High or Urgent"},
+			{Name: "due_date", Type: "datetime"},
+		},
+	},
+	{
+		ID:          CommandOpenProject,
+		Title:       "Jump to project",
+		Description: "Open a project by ID or by name within the current organization",
+		Keywords:    []string{"go", "open", "project", "jump"},
+		Permission:  "projects:read",
+		Arguments: []Argument{
+			{Name: "project_id", Type: "uuid", Description: "Takes precedence over name"},
+			{Name: "name", Type: "string"},
+		},
+	},
+	{
+		ID:          CommandCreateTodo,
+		Title:       "Create todo",
+		Description: "Add a todo to your default list",
+		Keywords:    []string{"new", "todo", "add"},
+		Arguments: []Argument{
+			{Name: "title", Type: "string", Required: true},
+			{Name: "due_date", Type: "datetime"},
+		},
+	},
+	{
+		ID:          CommandCompleteTodo,
+		Title:       "Complete todo",
+		Description: "Mark one of your todos as done",
+		Keywords:    []string{"done", "todo", "complete", "check"},
+		Arguments: []Argument{
+			{Name: "todo_id", Type: "uuid", Required: true},
+		},
+	},
+}
+
+// FindCommand looks up a command by its identifier
+func FindCommand(id string) (Command, bool) {
+	for _, cmd := range catalog {
+		if cmd.ID == id {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
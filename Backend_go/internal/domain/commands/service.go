@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/google/uuid"
+)
+
+// ExecuteInput carries a command invocation together with the caller's identity
+type ExecuteInput struct {
+	Command        string
+	Args           json.RawMessage
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+	Permissions    []string
+}
+
+// Service defines the interface for the command palette backend
+type Service interface {
+	ListCommands(permissions []string) []Command
+	Execute(ctx context.Context, input ExecuteInput) (*Result, error)
+}
+
+type service struct {
+	taskService         task.Service
+	projectService      project.Service
+	todoService         todos.Service
+	organizationService organization.Service
+}
+
+// NewService creates a new command service instance
+func NewService(taskService task.Service, projectService project.Service, todoService todos.Service, organizationService organization.Service) Service {
+	return &service{
+		taskService:         taskService,
+		projectService:      projectService,
+		todoService:         todoService,
+		organizationService: organizationService,
+	}
+}
+
+// ListCommands returns the commands visible to a caller with the given permissions
+func (s *service) ListCommands(permissions []string) []Command {
+	visible := make([]Command, 0, len(catalog))
+	for _, cmd := range catalog {
+		if cmd.Available(permissions) {
+			visible = append(visible, cmd)
+		}
+	}
+	return visible
+}
+
+// Execute resolves a command to the matching service call and runs it
+func (s *service) Execute(ctx context.Context, input ExecuteInput) (*Result, error) {
+	cmd, ok := FindCommand(input.Command)
+	if !ok {
+		return nil, ErrUnknownCommand
+	}
+	if !cmd.Available(input.Permissions) {
+		return nil, ErrForbidden
+	}
+
+	switch cmd.ID {
+	case CommandCreateTask:
+		return s.createTask(ctx, input)
+	case CommandOpenProject:
+		return s.openProject(ctx, input)
+	case CommandCreateTodo:
+		return s.createTodo(ctx, input)
+	case CommandCompleteTodo:
+		return s.completeTodo(ctx, input)
+	}
+	return nil, ErrUnknownCommand
+}
+
+func (s *service) createTask(ctx context.Context, input ExecuteInput) (*Result, error) {
+	var args struct {
+		Title       string     `json:"title"`
+		ProjectID   uuid.UUID  `json:"project_id"`
+		Description string     `json:"description"`
+		Priority    string     `json:"priority"`
+		DueDate     *time.Time `json:"due_date"`
+	}
+	if err := decodeArgs(input.Args, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Title) == "" || args.ProjectID == uuid.Nil {
+		return nil, fmt.Errorf("%w: title and project_id are required", ErrInvalidArguments)
+	}
+
+	proj, err := s.project(ctx, input, args.ProjectID, "tasks:create")
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.taskService.CreateTask(ctx, task.CreateTaskInput{
+		Title:          strings.TrimSpace(args.Title),
+		Description:    args.Description,
+		Priority:       task.TaskPriority(args.Priority),
+		CreatorID:      input.UserID,
+		AssigneeID:     &input.UserID,
+		ProjectID:      proj.ID,
+		OrganizationID: proj.OrganizationID,
+		StartDate:      time.Now(),
+		DueDate:        args.DueDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Command:  CommandCreateTask,
+		Data:     created,
+		Redirect: fmt.Sprintf("/projects/%s/tasks/%s", proj.ID, created.ID),
+	}, nil
+}
+
+func (s *service) openProject(ctx context.Context, input ExecuteInput) (*Result, error) {
+	var args struct {
+		ProjectID uuid.UUID `json:"project_id"`
+		Name      string    `json:"name"`
+	}
+	if err := decodeArgs(input.Args, &args); err != nil {
+		return nil, err
+	}
+
+	projectID := args.ProjectID
+	switch {
+	case projectID != uuid.Nil:
+	case strings.TrimSpace(args.Name) != "" && input.OrganizationID != uuid.Nil:
+		name := strings.TrimSpace(args.Name)
+		projects, _, err := s.projectService.ListProjects(ctx, project.ProjectFilter{
+			Page:           0,
+			PageSize:       1,
+			Name:           &name,
+			OrganizationID: &input.OrganizationID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(projects) == 0 {
+			return nil, project.ErrProjectNotFound
+		}
+		projectID = projects[0].ID
+	default:
+		return nil, fmt.Errorf("%w: project_id or name is required", ErrInvalidArguments)
+	}
+
+	// A project found by name is checked like one given by ID
+	proj, err := s.project(ctx, input, projectID, "projects:read")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Command:  CommandOpenProject,
+		Data:     proj,
+		Redirect: fmt.Sprintf("/projects/%s", proj.ID),
+	}, nil
+}
+
+// project loads a project of the caller's organization in which they hold the permission.
+// Projects of other organizations are reported as not found, so their existence is not
+// revealed.
+func (s *service) project(ctx context.Context, input ExecuteInput, projectID uuid.UUID, permission string) (*project.Project, error) {
+	proj, err := s.projectService.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if input.OrganizationID != uuid.Nil && proj.OrganizationID != input.OrganizationID {
+		return nil, project.ErrProjectNotFound
+	}
+	membership, err := s.organizationService.ResolveMembership(ctx, proj.OrganizationID, input.UserID)
+	if err != nil {
+		if errors.Is(err, organization.ErrNotMember) {
+			return nil, project.ErrProjectNotFound
+		}
+		return nil, err
+	}
+	if !membership.HasPermission(permission) {
+		return nil, ErrForbidden
+	}
+	return proj, nil
+}
+
+func (s *service) createTodo(ctx context.Context, input ExecuteInput) (*Result, error) {
+	var args struct {
+		Title   string     `json:"title"`
+		DueDate *time.Time `json:"due_date"`
+	}
+	if err := decodeArgs(input.Args, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Title) == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidArguments)
+	}
+
+	list, err := s.todoService.GetOrCreateDefaultList(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.todoService.CreateTodo(ctx, todos.CreateTodoInput{
+		Title:   strings.TrimSpace(args.Title),
+		DueDate: args.DueDate,
+		UserID:  input.UserID,
+		ListID:  list.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Command: CommandCreateTodo, Data: created}, nil
+}
+
+func (s *service) completeTodo(ctx context.Context, input ExecuteInput) (*Result, error) {
+	var args struct {
+		TodoID uuid.UUID `json:"todo_id"`
+	}
+	if err := decodeArgs(input.Args, &args); err != nil {
+		return nil, err
+	}
+	if args.TodoID == uuid.Nil {
+		return nil, fmt.Errorf("%w: todo_id is required", ErrInvalidArguments)
+	}
+
+	todo, err := s.todoService.GetTodo(ctx, args.TodoID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.UserID != input.UserID {
+		return nil, todos.ErrTodoNotFound
+	}
+
+	completed, err := s.todoService.CompleteTodo(ctx, todo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Command: CommandCompleteTodo, Data: completed}, nil
+}
+
+// decodeArgs unmarshals the raw command arguments into the command's argument struct
+func decodeArgs(raw json.RawMessage, dst interface{}) error {
+	if len(raw) == 0 {
+		raw = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package devices
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Platform is the kind of client a device runs
+type Platform string
+
+const (
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformWeb     Platform = "web"
+)
+
+var (
+	ErrDeviceNotFound = errors.New("device not found")
+	ErrInvalidDevice  = errors.New("device needs an ID of at most 255 characters and a platform of ios, android or web")
+)
+
+// Device is a client installation of a user, with the capabilities it reported when it
+// registered
+type Device struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_device_user_client"`
+	// DeviceID is the identifier the client generated for itself
+	DeviceID string   `json:"device_id" gorm:"size:255;not null;uniqueIndex:idx_device_user_client"`
+	Platform Platform `json:"platform" gorm:"type:varchar(20);not null"`
+	Name     string   `json:"name,omitempty" gorm:"size:255"`
+	// LocationEnabled is set when the client can monitor geofences
+	LocationEnabled bool      `json:"location_enabled" gorm:"not null"`
+	LastSeenAt      time.Time `json:"last_seen_at" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Device model
+func (Device) TableName() string {
+	return "user_devices"
+}
+
+// RegisterInput describes a device and its capabilities
+type RegisterInput struct {
+	DeviceID        string
+	Platform        Platform
+	Name            string
+	LocationEnabled bool
+}
@@ -0,0 +1,69 @@
+package devices
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for device data access
+type Repository interface {
+	// Save creates the device or updates the one the user registered with the same device ID
+	Save(ctx context.Context, device *Device) error
+	FindByDeviceID(ctx context.Context, userID uuid.UUID, deviceID string) (*Device, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]Device, error)
+	Delete(ctx context.Context, userID uuid.UUID, deviceID string) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new device repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// Save upserts the device on the user and client device ID
+func (r *repository) Save(ctx context.Context, device *Device) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"platform", "name", "location_enabled", "last_seen_at", "updated_at"}),
+	}).Create(device).Error
+}
+
+// FindByDeviceID retrieves a device of the user by its client device ID
+func (r *repository) FindByDeviceID(ctx context.Context, userID uuid.UUID, deviceID string) (*Device, error) {
+	var device Device
+	err := r.db.WithContext(ctx).First(&device, "user_id = ? AND device_id = ?", userID, deviceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDeviceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// ListByUser returns the user's devices, most recently seen first
+func (r *repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Device, error) {
+	var devices []Device
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error
+	return devices, err
+}
+
+// Delete removes a device of the user
+func (r *repository) Delete(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	result := r.db.WithContext(ctx).Delete(&Device{}, "user_id = ? AND device_id = ?", userID, deviceID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
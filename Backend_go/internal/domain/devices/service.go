@@ -0,0 +1,77 @@
+package devices
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the interface for device registration
+type Service interface {
+	Register(ctx context.Context, userID uuid.UUID, input RegisterInput) (*Device, error)
+	GetDevice(ctx context.Context, userID uuid.UUID, deviceID string) (*Device, error)
+	ListDevices(ctx context.Context, userID uuid.UUID) ([]Device, error)
+	Unregister(ctx context.Context, userID uuid.UUID, deviceID string) error
+	// Touch records that the device was just used
+	Touch(ctx context.Context, device *Device) error
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService creates a new device service
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// Register records a device of the user, or refreshes its capabilities when the client
+// registered before
+func (s *service) Register(ctx context.Context, userID uuid.UUID, input RegisterInput) (*Device, error) {
+	deviceID := strings.TrimSpace(input.DeviceID)
+	if deviceID == "" || len(deviceID) > 255 {
+		return nil, ErrInvalidDevice
+	}
+	switch input.Platform {
+	case PlatformIOS, PlatformAndroid, PlatformWeb:
+	default:
+		return nil, ErrInvalidDevice
+	}
+
+	now := time.Now()
+	device := &Device{
+		ID:              uuid.New(),
+		UserID:          userID,
+		DeviceID:        deviceID,
+		Platform:        input.Platform,
+		Name:            strings.TrimSpace(input.Name),
+		LocationEnabled: input.LocationEnabled,
+		LastSeenAt:      now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.repo.Save(ctx, device); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByDeviceID(ctx, userID, deviceID)
+}
+
+func (s *service) GetDevice(ctx context.Context, userID uuid.UUID, deviceID string) (*Device, error) {
+	return s.repo.FindByDeviceID(ctx, userID, deviceID)
+}
+
+func (s *service) ListDevices(ctx context.Context, userID uuid.UUID) ([]Device, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Unregister(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	return s.repo.Delete(ctx, userID, deviceID)
+}
+
+func (s *service) Touch(ctx context.Context, device *Device) error {
+	device.LastSeenAt = time.Now()
+	device.UpdatedAt = device.LastSeenAt
+	return s.repo.Save(ctx, device)
+}
@@ -0,0 +1,189 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// Event is a domain event published on the bus. EventName must not depend on the value,
+// as subscribers are matched by the name of the zero value of their event type.
+type Event interface {
+	EventName() string
+}
+
+// Mode selects how a subscriber receives events
+type Mode int
+
+const (
+	// Sync handlers run in the publisher's goroutine, before Publish returns
+	Sync Mode = iota
+	// Async handlers run on the bus workers, after Publish returns
+	Async
+)
+
+// Publisher is implemented by anything that accepts domain events. Publishing is best
+// effort: handler errors are logged and never fail the change that produced the event.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// BusConfig controls the async workers
+type BusConfig struct {
+	Workers   int
+	QueueSize int
+}
+
+// DefaultBusConfig returns the configuration used in production
+func DefaultBusConfig() BusConfig {
+	return BusConfig{
+		Workers:   4,
+		QueueSize: 1024,
+	}
+}
+
+type subscription struct {
+	name   string
+	mode   Mode
+	handle func(ctx context.Context, event Event) error
+}
+
+type delivery struct {
+	ctx   context.Context
+	sub   subscription
+	event Event
+}
+
+// Bus delivers domain events from the services that produce them to the features that
+// react to them, so neither side has to know about the other
+type Bus struct {
+	config BusConfig
+	logger *zap.Logger
+
+	mu   sync.RWMutex
+	subs map[string][]subscription
+
+	queue   chan delivery
+	stop    chan struct{}
+	stopped atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// NewBus creates a bus. Async handlers only run once the bus is started.
+func NewBus(config BusConfig, logger *zap.Logger) *Bus {
+	return &Bus{
+		config: config,
+		logger: logger,
+		subs:   make(map[string][]subscription),
+		queue:  make(chan delivery, config.QueueSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Subscribe registers handler for events of type E. name identifies the subscriber in logs.
+func Subscribe[E Event](b *Bus, name string, mode Mode, handler func(ctx context.Context, event E) error) {
+	var zero E
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[zero.EventName()] = append(b.subs[zero.EventName()], subscription{
+		name: name,
+		mode: mode,
+		handle: func(ctx context.Context, event Event) error {
+			typed, ok := event.(E)
+			if !ok {
+				return fmt.Errorf("unexpected event type %T", event)
+			}
+			return handler(ctx, typed)
+		},
+	})
+}
+
+// Publish hands event to its subscribers. Async deliveries keep the values of ctx but not
+// its cancellation, so they outlive the request that published them. When the queue is
+// full, or the bus is stopped, async handlers run in the caller's goroutine instead.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subs := b.subs[event.EventName()]
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if sub.mode == Async && !b.stopped.Load() {
+			select {
+			case b.queue <- delivery{ctx: asyncContext(ctx), sub: sub, event: event}:
+				continue
+			default:
+				tracing.Logger(ctx, b.logger).Warn("Event queue full, handling event inline",
+					zap.String("event", event.EventName()),
+					zap.String("subscriber", sub.name))
+			}
+		}
+		b.deliver(ctx, sub, event)
+	}
+}
+
+// asyncContext detaches ctx from the cancellation of the publisher for an async handler,
+// which runs in a child span of the publisher's trace
+func asyncContext(ctx context.Context) context.Context {
+	async := context.WithoutCancel(ctx)
+	if tc := tracing.FromContext(ctx); tc != nil {
+		async = tracing.WithContext(async, tc.ChildSpan())
+	}
+	return async
+}
+
+// Start launches the async workers
+func (b *Bus) Start() {
+	for i := 0; i < b.config.Workers; i++ {
+		b.wg.Add(1)
+		go b.work()
+	}
+}
+
+// Stop waits for the workers to handle the queued events
+func (b *Bus) Stop() {
+	b.stopped.Store(true)
+	close(b.stop)
+	b.wg.Wait()
+}
+
+func (b *Bus) work() {
+	defer b.wg.Done()
+	for {
+		select {
+		case d := <-b.queue:
+			b.deliver(d.ctx, d.sub, d.event)
+		case <-b.stop:
+			// Drain what was queued before stopping
+			for {
+				select {
+				case d := <-b.queue:
+					b.deliver(d.ctx, d.sub, d.event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver runs a single handler, keeping its errors and panics away from the publisher
+func (b *Bus) deliver(ctx context.Context, sub subscription, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			tracing.Logger(ctx, b.logger).Error("Event handler panicked",
+				zap.String("event", event.EventName()),
+				zap.String("subscriber", sub.name),
+				zap.Any("panic", r))
+		}
+	}()
+	if err := sub.handle(ctx, event); err != nil {
+		tracing.Logger(ctx, b.logger).Error("Event handler failed",
+			zap.String("event", event.EventName()),
+			zap.String("subscriber", sub.name),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,169 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskCreated is published when a task is created
+type TaskCreated struct {
+	TaskID         uuid.UUID
+	ProjectID      uuid.UUID
+	OrganizationID uuid.UUID
+	CreatorID      uuid.UUID
+	Status         string
+	CreatedAt      time.Time
+}
+
+func (TaskCreated) EventName() string { return "task.created" }
+
+// TaskStatusChanged is published when a task moves from one status to another. A move to
+// the completed status is also published as TaskCompleted.
+type TaskStatusChanged struct {
+	TaskID         uuid.UUID
+	ProjectID      uuid.UUID
+	OrganizationID uuid.UUID
+	PreviousStatus string
+	Status         string
+	ChangedBy      uuid.UUID
+	ChangedAt      time.Time
+}
+
+func (TaskStatusChanged) EventName() string { return "task.status_changed" }
+
+// TaskDeleted is published when a task is deleted, with the status it had
+type TaskDeleted struct {
+	TaskID         uuid.UUID
+	ProjectID      uuid.UUID
+	OrganizationID uuid.UUID
+	Status         string
+	DeletedAt      time.Time
+}
+
+func (TaskDeleted) EventName() string { return "task.deleted" }
+
+// TaskCompleted is published when a task moves to the completed status
+type TaskCompleted struct {
+	TaskID         uuid.UUID
+	ProjectID      uuid.UUID
+	OrganizationID uuid.UUID
+	CompletedBy    uuid.UUID
+	CompletedAt    time.Time
+}
+
+func (TaskCompleted) EventName() string { return "task.completed" }
+
+// TaskAssigned is published when a task gets a new assignee
+type TaskAssigned struct {
+	TaskID         uuid.UUID
+	ProjectID      uuid.UUID
+	OrganizationID uuid.UUID
+	Title          string
+	Priority       string
+	DueDate        *time.Time
+	AssigneeID     uuid.UUID
+	AssignedBy     uuid.UUID
+}
+
+func (TaskAssigned) EventName() string { return "task.assigned" }
+
+// TaskAtRisk is published when the nightly analysis first flags a task as at risk, or
+// finds new reasons for it to be
+type TaskAtRisk struct {
+	TaskID         uuid.UUID
+	ProjectID      uuid.UUID
+	OrganizationID uuid.UUID
+	Title          string
+	DueDate        *time.Time
+	// OwnerID is the assignee, or the creator of an unassigned task
+	OwnerID uuid.UUID
+	Level   string
+	Reasons []string
+}
+
+func (TaskAtRisk) EventName() string { return "task.at_risk" }
+
+// TodoCreated is published when a todo is created, including the next occurrence of a
+// recurring todo
+type TodoCreated struct {
+	TodoID    uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (TodoCreated) EventName() string { return "todo.created" }
+
+// TodoCompleted is published when a todo is marked completed
+type TodoCompleted struct {
+	TodoID      uuid.UUID
+	UserID      uuid.UUID
+	CompletedAt time.Time
+}
+
+func (TodoCompleted) EventName() string { return "todo.completed" }
+
+// TodoGeofenceTriggered is published when a client reports crossing a todo's geofence in
+// the direction that fires its reminder
+type TodoGeofenceTriggered struct {
+	TodoID     uuid.UUID
+	GeofenceID uuid.UUID
+	UserID     uuid.UUID
+	Title      string
+	Label      string
+	Transition string
+	DeviceID   string
+	OccurredAt time.Time
+}
+
+func (TodoGeofenceTriggered) EventName() string { return "todo.geofence_triggered" }
+
+// AttachmentInfected is published when the malware scanner finds a threat in an uploaded
+// file, after its content was deleted
+type AttachmentInfected struct {
+	AttachmentID   uuid.UUID
+	OwnerType      string
+	OwnerID        uuid.UUID
+	OrganizationID *uuid.UUID
+	UploadedBy     uuid.UUID
+	FileName       string
+	Threat         string
+}
+
+func (AttachmentInfected) EventName() string { return "attachment.infected" }
+
+// HabitCompleted is published when a habit is marked completed
+type HabitCompleted struct {
+	HabitID       uuid.UUID
+	UserID        uuid.UUID
+	Title         string
+	CompletedAt   time.Time
+	CurrentStreak int
+}
+
+func (HabitCompleted) EventName() string { return "habit.completed" }
+
+// HabitStreakBroken is published when a habit's streak is reset because a day was missed
+type HabitStreakBroken struct {
+	HabitID           uuid.UUID
+	UserID            uuid.UUID
+	Title             string
+	PreviousStreak    int
+	LastCompletedDate *time.Time
+	BrokenAt          time.Time
+}
+
+func (HabitStreakBroken) EventName() string { return "habit.streak_broken" }
+
+// EventRescheduled is published when the start or end time of a calendar event changes
+type EventRescheduled struct {
+	EventID       uuid.UUID
+	UserID        uuid.UUID
+	Title         string
+	PreviousStart time.Time
+	PreviousEnd   time.Time
+	StartTime     time.Time
+	EndTime       time.Time
+}
+
+func (EventRescheduled) EventName() string { return "calendar.event_rescheduled" }
@@ -0,0 +1,175 @@
+package focus
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPlannedMinutes is the length of a session started without one, a pomodoro
+	DefaultPlannedMinutes = 25
+	// MaxPlannedMinutes is the longest session that can be planned
+	MaxPlannedMinutes = 240
+	// MaxSessionDuration is the longest a session counts for. Sessions left running
+	// longer are ended at this length.
+	MaxSessionDuration = 8 * time.Hour
+	// MaxInterruptions caps the interruptions recorded in one session
+	MaxInterruptions = 100
+	// MaxRangeDays is the longest range the statistics cover
+	MaxRangeDays = 366
+	// maxNoteLength is the longest note a session or interruption can carry
+	maxNoteLength = 500
+)
+
+var (
+	ErrSessionNotFound   = errors.New("focus session not found")
+	ErrSessionActive     = errors.New("a focus session is already running")
+	ErrSessionEnded      = errors.New("focus session has already ended")
+	ErrInvalidSession    = errors.New("focus sessions need between 1 and 240 planned minutes and a note of at most 500 characters")
+	ErrInvalidLink       = errors.New("a focus session can be linked to a task or a habit, not both")
+	ErrLinkNotFound      = errors.New("linked task or habit not found")
+	ErrTaskForbidden     = errors.New("linked task must be created by or assigned to you")
+	ErrInvalidKind       = errors.New("interruption kind must be internal or external")
+	ErrTooManyInterrupts = errors.New("too many interruptions in this focus session")
+	ErrInvalidRange      = errors.New("range must end after it starts and cover at most 366 days")
+)
+
+// Status is the state of a focus session
+type Status string
+
+const (
+	StatusActive Status = "active"
+	// StatusCompleted sessions ran at least their planned length
+	StatusCompleted Status = "completed"
+	// StatusAbandoned sessions were stopped before their planned length
+	StatusAbandoned Status = "abandoned"
+)
+
+// InterruptionKind tells where an interruption came from
+type InterruptionKind string
+
+const (
+	// InterruptionInternal is the user breaking their own focus
+	InterruptionInternal InterruptionKind = "internal"
+	// InterruptionExternal is someone or something else breaking it
+	InterruptionExternal InterruptionKind = "external"
+)
+
+// IsValid checks if the interruption kind is valid
+func (k InterruptionKind) IsValid() bool {
+	return k == InterruptionInternal || k == InterruptionExternal
+}
+
+// Session is a timed block of focus, optionally on a task or habit. A session is active
+// until it is stopped; a user has at most one active session.
+type Session struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_focus_session_user,priority:1;uniqueIndex:idx_focus_session_active,where:ended_at is null"`
+	TaskID         *uuid.UUID `json:"task_id,omitempty" gorm:"type:uuid;index"`
+	HabitID        *uuid.UUID `json:"habit_id,omitempty" gorm:"type:uuid;index"`
+	PlannedMinutes int        `json:"planned_minutes" gorm:"not null"`
+	StartedAt      time.Time  `json:"started_at" gorm:"not null;index:idx_focus_session_user,priority:2"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	// FocusedSeconds is zero while the session runs
+	FocusedSeconds int64     `json:"focused_seconds" gorm:"not null;default:0"`
+	Status         Status    `json:"status" gorm:"type:varchar(20);not null"`
+	Interruptions  int       `json:"interruptions" gorm:"not null;default:0"`
+	Note           string    `json:"note,omitempty" gorm:"size:500"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null"`
+
+	// InterruptionLog is filled when a single session is read
+	InterruptionLog []Interruption `json:"interruption_log,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for the Session model
+func (Session) TableName() string {
+	return "focus_sessions"
+}
+
+// BeforeCreate is called before creating a new focus session record
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// Active reports whether the session is still running
+func (s *Session) Active() bool {
+	return s.EndedAt == nil
+}
+
+// PlannedEnd returns when the session reaches its planned length
+func (s *Session) PlannedEnd() time.Time {
+	return s.StartedAt.Add(time.Duration(s.PlannedMinutes) * time.Minute)
+}
+
+// Interruption is a break in the focus of a session
+type Interruption struct {
+	ID         uuid.UUID        `json:"id" gorm:"type:uuid;primary_key"`
+	SessionID  uuid.UUID        `json:"session_id" gorm:"type:uuid;not null;index"`
+	UserID     uuid.UUID        `json:"user_id" gorm:"type:uuid;not null"`
+	Kind       InterruptionKind `json:"kind" gorm:"type:varchar(20);not null"`
+	Note       string           `json:"note,omitempty" gorm:"size:500"`
+	OccurredAt time.Time        `json:"occurred_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the Interruption model
+func (Interruption) TableName() string {
+	return "focus_interruptions"
+}
+
+// BeforeCreate is called before creating a new interruption record
+func (i *Interruption) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// StartInput starts a focus session. Zero PlannedMinutes plans a pomodoro.
+type StartInput struct {
+	PlannedMinutes int
+	TaskID         *uuid.UUID
+	HabitID        *uuid.UUID
+	Note           string
+}
+
+// PeriodStats is the focus of a day or week. Start is its first day, YYYY-MM-DD in the
+// user's timezone.
+type PeriodStats struct {
+	Start          string `json:"start"`
+	Sessions       int    `json:"sessions"`
+	Completed      int    `json:"completed"`
+	Abandoned      int    `json:"abandoned"`
+	FocusedMinutes int    `json:"focused_minutes"`
+	Interruptions  int    `json:"interruptions"`
+}
+
+// Totals sums the focus of a range
+type Totals struct {
+	Sessions       int `json:"sessions"`
+	Completed      int `json:"completed"`
+	Abandoned      int `json:"abandoned"`
+	FocusedMinutes int `json:"focused_minutes"`
+	Interruptions  int `json:"interruptions"`
+	// CompletionRate is the share of sessions that ran their planned length
+	CompletionRate float64 `json:"completion_rate"`
+	// AverageMinutes is the mean focused time of a session
+	AverageMinutes float64 `json:"average_minutes"`
+}
+
+// Stats is the focus of a user over a range of days, by day or by week. Sessions count
+// on the day they started, and only once they have ended.
+type Stats struct {
+	Timezone string        `json:"timezone"`
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Totals   Totals        `json:"totals"`
+	Days     []PeriodStats `json:"days,omitempty"`
+	Weeks    []PeriodStats `json:"weeks,omitempty"`
+}
@@ -0,0 +1,155 @@
+package focus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for focus session data access
+type Repository interface {
+	// Create stores a session. Starting a second session for a user returns ErrSessionActive.
+	Create(ctx context.Context, session *Session) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Session, error)
+	// FindActive returns the user's running session, or nil when there is none
+	FindActive(ctx context.Context, userID uuid.UUID) (*Session, error)
+	// Finish ends a running session. It returns ErrSessionEnded when the session already
+	// ended, so a session is only counted once.
+	Finish(ctx context.Context, id uuid.UUID, endedAt time.Time, focusedSeconds int64, status Status) error
+	// Delete removes a session and its interruptions
+	Delete(ctx context.Context, id uuid.UUID) error
+	// AddInterruption records an interruption of a running session. It returns
+	// ErrSessionEnded when the session already ended and ErrTooManyInterrupts when the
+	// session has MaxInterruptions.
+	AddInterruption(ctx context.Context, interruption *Interruption) error
+	ListInterruptions(ctx context.Context, sessionID uuid.UUID) ([]Interruption, error)
+	// ListByUser returns the user's sessions that started in the range, oldest first
+	ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]Session, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new focus session repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Create(ctx context.Context, session *Session) error {
+	err := r.db.WithContext(ctx).Create(session).Error
+	if err != nil && (errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "SQLSTATE 23505")) {
+		return ErrSessionActive
+	}
+	return err
+}
+
+func (r *repository) FindByID(ctx context.Context, id uuid.UUID) (*Session, error) {
+	var session Session
+	if err := r.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *repository) FindActive(ctx context.Context, userID uuid.UUID) (*Session, error) {
+	var session Session
+	err := r.db.WithContext(ctx).First(&session, "user_id = ? AND ended_at IS NULL", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *repository) Finish(ctx context.Context, id uuid.UUID, endedAt time.Time, focusedSeconds int64, status Status) error {
+	result := r.db.WithContext(ctx).Model(&Session{}).
+		Where("id = ? AND ended_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"ended_at":        endedAt,
+			"focused_seconds": focusedSeconds,
+			"status":          status,
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionEnded
+	}
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&Interruption{}, "session_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Session{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSessionNotFound
+		}
+		return nil
+	})
+}
+
+func (r *repository) AddInterruption(ctx context.Context, interruption *Interruption) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Counting the interruption first locks the session row, so a concurrent stop
+		// either waits for it or makes it fail
+		result := tx.Model(&Session{}).
+			Where("id = ? AND ended_at IS NULL AND interruptions < ?", interruption.SessionID, MaxInterruptions).
+			Updates(map[string]interface{}{
+				"interruptions": gorm.Expr("interruptions + 1"),
+				"updated_at":    time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var session Session
+			if err := tx.Select("ended_at").First(&session, "id = ?", interruption.SessionID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrSessionNotFound
+				}
+				return err
+			}
+			if !session.Active() {
+				return ErrSessionEnded
+			}
+			return ErrTooManyInterrupts
+		}
+		return tx.Create(interruption).Error
+	})
+}
+
+func (r *repository) ListInterruptions(ctx context.Context, sessionID uuid.UUID) ([]Interruption, error) {
+	var interruptions []Interruption
+	err := r.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("occurred_at ASC").
+		Find(&interruptions).Error
+	return interruptions, err
+}
+
+func (r *repository) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]Session, error) {
+	var sessions []Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND started_at >= ? AND started_at < ?", userID, from, to).
+		Order("started_at ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
@@ -0,0 +1,460 @@
+package focus
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultStatsDays is the range of daily statistics requested without dates
+	defaultStatsDays = 7
+	// DefaultStatsWeeks is the number of weeks of weekly statistics requested without one
+	DefaultStatsWeeks = 12
+	// MaxStatsWeeks is the most weeks weekly statistics cover
+	MaxStatsWeeks = 52
+)
+
+// Tasks reads the tasks sessions are linked to
+type Tasks interface {
+	GetTask(ctx context.Context, id uuid.UUID) (*task.Task, error)
+}
+
+// Habits reads the habits sessions are linked to
+type Habits interface {
+	GetHabit(ctx context.Context, id uuid.UUID) (*habits.Habit, error)
+}
+
+// Users reads the timezone days are counted in
+type Users interface {
+	GetUser(ctx context.Context, id uuid.UUID) (*user.User, error)
+}
+
+// Service defines the interface for focus sessions. Sessions are personal: every method
+// only reaches the sessions of the given user.
+type Service interface {
+	// Start starts a session for the user. It returns ErrSessionActive when the user
+	// already has one running.
+	Start(ctx context.Context, userID uuid.UUID, input StartInput) (*Session, error)
+	// Stop ends the user's session. Sessions that ran their planned length are
+	// completed, shorter ones abandoned.
+	Stop(ctx context.Context, userID, id uuid.UUID) (*Session, error)
+	// Current returns the user's running session, or nil when there is none
+	Current(ctx context.Context, userID uuid.UUID) (*Session, error)
+	// Get returns a session with its interruptions
+	Get(ctx context.Context, userID, id uuid.UUID) (*Session, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// Interrupt records an interruption of the user's running session
+	Interrupt(ctx context.Context, userID, id uuid.UUID, kind InterruptionKind, note string) (*Interruption, error)
+
+	// List returns the sessions started between two dates (YYYY-MM-DD in the user's
+	// timezone), the last 7 days when empty
+	List(ctx context.Context, userID uuid.UUID, from, to string) ([]Session, error)
+	// DailyStats reports the user's focus by day between two dates, the last 7 days
+	// when empty
+	DailyStats(ctx context.Context, userID uuid.UUID, from, to string) (*Stats, error)
+	// WeeklyStats reports the user's focus by week, Monday to Sunday, over the last
+	// weeks including this one
+	WeeklyStats(ctx context.Context, userID uuid.UUID, weeks int) (*Stats, error)
+	// Heatmap returns the minutes focused on each day of the period (week, month or
+	// year) that had any, by YYYY-MM-DD
+	Heatmap(ctx context.Context, userID uuid.UUID, period string) (map[string]int, error)
+}
+
+type service struct {
+	repo   Repository
+	tasks  Tasks
+	habits Habits
+	users  Users
+	now    func() time.Time
+}
+
+// NewService creates a new focus session service
+func NewService(repo Repository, tasks Tasks, habits Habits, users Users) Service {
+	return &service{
+		repo:   repo,
+		tasks:  tasks,
+		habits: habits,
+		users:  users,
+		now:    time.Now,
+	}
+}
+
+func (s *service) Start(ctx context.Context, userID uuid.UUID, input StartInput) (*Session, error) {
+	planned := input.PlannedMinutes
+	if planned == 0 {
+		planned = DefaultPlannedMinutes
+	}
+	note := strings.TrimSpace(input.Note)
+	if planned < 1 || planned > MaxPlannedMinutes || len(note) > maxNoteLength {
+		return nil, ErrInvalidSession
+	}
+	if err := s.checkLink(ctx, userID, input.TaskID, input.HabitID); err != nil {
+		return nil, err
+	}
+
+	active, err := s.repo.FindActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, ErrSessionActive
+	}
+
+	now := s.now()
+	session := &Session{
+		UserID:         userID,
+		TaskID:         input.TaskID,
+		HabitID:        input.HabitID,
+		PlannedMinutes: planned,
+		StartedAt:      now,
+		Status:         StatusActive,
+		Note:           note,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// checkLink checks that a session links at most one item, and that the user may focus
+// on it: a task they created or were assigned, or a habit of their own
+func (s *service) checkLink(ctx context.Context, userID uuid.UUID, taskID, habitID *uuid.UUID) error {
+	if taskID != nil && habitID != nil {
+		return ErrInvalidLink
+	}
+	if taskID != nil {
+		t, err := s.tasks.GetTask(ctx, *taskID)
+		if errors.Is(err, task.ErrTaskNotFound) {
+			return ErrLinkNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if t.CreatorID != userID && (t.AssigneeID == nil || *t.AssigneeID != userID) {
+			return ErrTaskForbidden
+		}
+	}
+	if habitID != nil {
+		habit, err := s.habits.GetHabit(ctx, *habitID)
+		if errors.Is(err, habits.ErrHabitNotFound) {
+			return ErrLinkNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if habit == nil || habit.UserID != userID {
+			return ErrLinkNotFound
+		}
+	}
+	return nil
+}
+
+func (s *service) Stop(ctx context.Context, userID, id uuid.UUID) (*Session, error) {
+	session, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !session.Active() {
+		return nil, ErrSessionEnded
+	}
+
+	endedAt := s.now()
+	if endedAt.Sub(session.StartedAt) > MaxSessionDuration {
+		endedAt = session.StartedAt.Add(MaxSessionDuration)
+	}
+	seconds := int64(endedAt.Sub(session.StartedAt) / time.Second)
+	status := StatusAbandoned
+	if !endedAt.Before(session.PlannedEnd()) {
+		status = StatusCompleted
+	}
+	if err := s.repo.Finish(ctx, session.ID, endedAt, seconds, status); err != nil {
+		return nil, err
+	}
+	session.EndedAt = &endedAt
+	session.FocusedSeconds = seconds
+	session.Status = status
+	session.UpdatedAt = time.Now()
+	return session, nil
+}
+
+func (s *service) Current(ctx context.Context, userID uuid.UUID) (*Session, error) {
+	return s.repo.FindActive(ctx, userID)
+}
+
+func (s *service) Get(ctx context.Context, userID, id uuid.UUID) (*Session, error) {
+	session, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	interruptions, err := s.repo.ListInterruptions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	session.InterruptionLog = interruptions
+	return session, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.owned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) Interrupt(ctx context.Context, userID, id uuid.UUID, kind InterruptionKind, note string) (*Interruption, error) {
+	if kind == "" {
+		kind = InterruptionInternal
+	}
+	if !kind.IsValid() {
+		return nil, ErrInvalidKind
+	}
+	note = strings.TrimSpace(note)
+	if len(note) > maxNoteLength {
+		return nil, ErrInvalidSession
+	}
+	session, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !session.Active() {
+		return nil, ErrSessionEnded
+	}
+
+	interruption := &Interruption{
+		SessionID:  session.ID,
+		UserID:     userID,
+		Kind:       kind,
+		Note:       note,
+		OccurredAt: s.now(),
+	}
+	if err := s.repo.AddInterruption(ctx, interruption); err != nil {
+		return nil, err
+	}
+	return interruption, nil
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID, from, to string) ([]Session, error) {
+	loc := s.location(ctx, userID)
+	start, end, err := s.dateRange(from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByUser(ctx, userID, start, end.AddDate(0, 0, 1))
+}
+
+func (s *service) DailyStats(ctx context.Context, userID uuid.UUID, from, to string) (*Stats, error) {
+	loc := s.location(ctx, userID)
+	start, end, err := s.dateRange(from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.repo.ListByUser(ctx, userID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := newStats(start, end, loc)
+	stats.Days = bucketSessions(sessions, start, end, loc, 1, func(day time.Time) time.Time { return day })
+	stats.Totals = totals(sessions)
+	return stats, nil
+}
+
+func (s *service) WeeklyStats(ctx context.Context, userID uuid.UUID, weeks int) (*Stats, error) {
+	if weeks == 0 {
+		weeks = DefaultStatsWeeks
+	}
+	if weeks < 1 || weeks > MaxStatsWeeks {
+		return nil, ErrInvalidRange
+	}
+	loc := s.location(ctx, userID)
+	thisWeek := weekStart(today(s.now(), loc))
+	start := thisWeek.AddDate(0, 0, -7*(weeks-1))
+	end := thisWeek.AddDate(0, 0, 6)
+	sessions, err := s.repo.ListByUser(ctx, userID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := newStats(start, end, loc)
+	stats.Weeks = bucketSessions(sessions, start, end, loc, 7, weekStart)
+	stats.Totals = totals(sessions)
+	return stats, nil
+}
+
+func (s *service) Heatmap(ctx context.Context, userID uuid.UUID, period string) (map[string]int, error) {
+	loc := s.location(ctx, userID)
+	end := today(s.now(), loc)
+	var start time.Time
+	switch period {
+	case "week":
+		start = end.AddDate(0, 0, -7)
+	case "month":
+		start = end.AddDate(0, -1, 0)
+	default:
+		start = end.AddDate(-1, 0, 0)
+	}
+	sessions, err := s.repo.ListByUser(ctx, userID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	seconds := make(map[string]int64)
+	for i := range sessions {
+		if sessions[i].Active() || sessions[i].FocusedSeconds == 0 {
+			continue
+		}
+		seconds[sessions[i].StartedAt.In(loc).Format("2006-01-02")] += sessions[i].FocusedSeconds
+	}
+	heatmap := make(map[string]int, len(seconds))
+	for date, total := range seconds {
+		heatmap[date] = minutes(total)
+	}
+	return heatmap, nil
+}
+
+// owned returns a session of the user. Sessions of other users are not found.
+func (s *service) owned(ctx context.Context, userID, id uuid.UUID) (*Session, error) {
+	session, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// location returns the user's timezone, UTC when it is unknown
+func (s *service) location(ctx context.Context, userID uuid.UUID) *time.Location {
+	u, err := s.users.GetUser(ctx, userID)
+	if err != nil || u == nil {
+		return time.UTC
+	}
+	loc, err := user.ParseTimezone(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// dateRange parses a range of dates in the location. An empty end is today and an empty
+// start is 7 days before the end.
+func (s *service) dateRange(from, to string, loc *time.Location) (time.Time, time.Time, error) {
+	end := today(s.now(), loc)
+	if to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidRange
+		}
+		end = parsed
+	}
+	start := end.AddDate(0, 0, -(defaultStatsDays - 1))
+	if from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidRange
+		}
+		start = parsed
+	}
+	if end.Before(start) || end.Sub(start) >= MaxRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidRange
+	}
+	return start, end, nil
+}
+
+func newStats(start, end time.Time, loc *time.Location) *Stats {
+	return &Stats{
+		Timezone: loc.String(),
+		From:     start.Format("2006-01-02"),
+		To:       end.Format("2006-01-02"),
+	}
+}
+
+// bucketSessions adds the ended sessions up into periods of the given number of days
+// from start to end, listing periods without focus as zeros. bucket maps a day to the
+// first day of its period.
+func bucketSessions(sessions []Session, start, end time.Time, loc *time.Location, days int, bucket func(time.Time) time.Time) []PeriodStats {
+	periods := []PeriodStats{}
+	index := make(map[string]int)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, days) {
+		date := day.Format("2006-01-02")
+		index[date] = len(periods)
+		periods = append(periods, PeriodStats{Start: date})
+	}
+
+	seconds := make([]int64, len(periods))
+	for i := range sessions {
+		if sessions[i].Active() {
+			continue
+		}
+		started := sessions[i].StartedAt.In(loc)
+		day := time.Date(started.Year(), started.Month(), started.Day(), 0, 0, 0, 0, loc)
+		j, ok := index[bucket(day).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		periods[j].Sessions++
+		if sessions[i].Status == StatusCompleted {
+			periods[j].Completed++
+		} else {
+			periods[j].Abandoned++
+		}
+		periods[j].Interruptions += sessions[i].Interruptions
+		seconds[j] += sessions[i].FocusedSeconds
+	}
+	for j := range periods {
+		periods[j].FocusedMinutes = minutes(seconds[j])
+	}
+	return periods
+}
+
+// totals adds up the ended sessions
+func totals(sessions []Session) Totals {
+	var t Totals
+	var seconds int64
+	for i := range sessions {
+		if sessions[i].Active() {
+			continue
+		}
+		t.Sessions++
+		if sessions[i].Status == StatusCompleted {
+			t.Completed++
+		} else {
+			t.Abandoned++
+		}
+		t.Interruptions += sessions[i].Interruptions
+		seconds += sessions[i].FocusedSeconds
+	}
+	t.FocusedMinutes = minutes(seconds)
+	if t.Sessions > 0 {
+		t.CompletionRate = math.Round(float64(t.Completed)/float64(t.Sessions)*1000) / 1000
+		t.AverageMinutes = math.Round(float64(seconds)/60/float64(t.Sessions)*10) / 10
+	}
+	return t
+}
+
+// today returns the start of the current day in the location
+func today(now time.Time, loc *time.Location) time.Time {
+	now = now.In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+}
+
+// weekStart returns the Monday of the day's week
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// minutes rounds seconds to whole minutes
+func minutes(seconds int64) int {
+	return int(math.Round(float64(seconds) / 60))
+}
@@ -0,0 +1,83 @@
+package habitlinks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action is what a linked habit does when it is completed
+type Action string
+
+const (
+	// ActionWorklog logs time on a task
+	ActionWorklog Action = "worklog"
+	// ActionTodo creates a todo from the link's template
+	ActionTodo Action = "todo"
+)
+
+const (
+	// MaxWorklogHours caps the time one completion can log
+	MaxWorklogHours = 12.0
+	// MaxLinksPerUser caps the links a user can have, and so the work their habits can
+	// create in a day
+	MaxLinksPerUser = 25
+)
+
+var (
+	ErrLinkNotFound  = errors.New("habit link not found")
+	ErrInvalidLink   = errors.New("a worklog link needs a task and between 0 and 12 hours; a todo link needs a title")
+	ErrTaskForbidden = errors.New("linked task must be created by or assigned to you")
+	ErrTooManyLinks  = errors.New("too many habit links")
+)
+
+// Link connects a habit to a task template. Completing the habit logs work on the task
+// or creates a todo from the template, at most once per completion day.
+type Link struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	HabitID uuid.UUID `json:"habit_id" gorm:"type:uuid;not null;uniqueIndex"`
+	UserID  uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Action  Action    `json:"action" gorm:"type:varchar(20);not null"`
+	Enabled bool      `json:"enabled" gorm:"not null"`
+
+	// TaskID is the task work is logged on; created todos are linked to it when set
+	TaskID *uuid.UUID `json:"task_id,omitempty" gorm:"type:uuid;index"`
+	// Hours is the time logged per completion
+	Hours float64 `json:"hours,omitempty"`
+
+	// TodoTitle, TodoDescription and TodoPriority are the template for created todos
+	TodoTitle       string `json:"todo_title,omitempty" gorm:"size:255"`
+	TodoDescription string `json:"todo_description,omitempty" gorm:"type:text"`
+	TodoPriority    string `json:"todo_priority,omitempty" gorm:"type:varchar(10)"`
+	// TodoDueInHours makes created todos due that long after the completion; 0 leaves
+	// them without a due date
+	TodoDueInHours int `json:"todo_due_in_hours,omitempty"`
+
+	// LastRunOn is the completion day the link last ran for. Completing a habit again on
+	// the same day, after undoing it, does not run the link twice.
+	LastRunOn *time.Time `json:"last_run_on,omitempty" gorm:"type:date"`
+	// LastItemID is the todo created, or the task logged on, by the last run
+	LastItemID *uuid.UUID `json:"last_item_id,omitempty" gorm:"type:uuid"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Link model
+func (Link) TableName() string {
+	return "habit_links"
+}
+
+// LinkInput sets up the link of a habit. Fields that do not apply to the action are
+// ignored.
+type LinkInput struct {
+	Action          Action
+	Enabled         bool
+	TaskID          *uuid.UUID
+	Hours           float64
+	TodoTitle       string
+	TodoDescription string
+	TodoPriority    string
+	TodoDueInHours  int
+}
@@ -0,0 +1,98 @@
+package habitlinks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for habit link data access
+type Repository interface {
+	FindByHabitID(ctx context.Context, habitID uuid.UUID) (*Link, error)
+	// Save creates the link or replaces the one the habit already has
+	Save(ctx context.Context, link *Link) error
+	DeleteByHabitID(ctx context.Context, habitID uuid.UUID) error
+	CountByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	// ClaimRun marks the link as run for day and reports false when it already ran for
+	// that day, so concurrent completions run it once
+	ClaimRun(ctx context.Context, id uuid.UUID, day time.Time) (bool, error)
+	// ReleaseRun restores the previous run day after a run failed
+	ReleaseRun(ctx context.Context, id uuid.UUID, previous *time.Time) error
+	SetLastItem(ctx context.Context, id uuid.UUID, itemID uuid.UUID) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new habit link repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// FindByHabitID retrieves the link of a habit
+func (r *repository) FindByHabitID(ctx context.Context, habitID uuid.UUID) (*Link, error) {
+	var link Link
+	if err := r.db.WithContext(ctx).First(&link, "habit_id = ?", habitID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+// Save upserts the link on its habit
+func (r *repository) Save(ctx context.Context, link *Link) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "habit_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"action", "enabled", "task_id", "hours", "todo_title", "todo_description",
+			"todo_priority", "todo_due_in_hours", "updated_at",
+		}),
+	}).Create(link).Error
+}
+
+// DeleteByHabitID removes the link of a habit
+func (r *repository) DeleteByHabitID(ctx context.Context, habitID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&Link{}, "habit_id = ?", habitID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+// CountByUser counts the links a user has
+func (r *repository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Link{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ClaimRun sets the run day only when it differs, so just one caller wins the day
+func (r *repository) ClaimRun(ctx context.Context, id uuid.UUID, day time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Link{}).
+		Where("id = ? AND (last_run_on IS NULL OR last_run_on <> ?)", id, day).
+		UpdateColumn("last_run_on", day)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseRun puts back the run day the link had before a failed run
+func (r *repository) ReleaseRun(ctx context.Context, id uuid.UUID, previous *time.Time) error {
+	return r.db.WithContext(ctx).Model(&Link{}).Where("id = ?", id).
+		UpdateColumn("last_run_on", previous).Error
+}
+
+// SetLastItem records what the last run created or logged on
+func (r *repository) SetLastItem(ctx context.Context, id uuid.UUID, itemID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&Link{}).Where("id = ?", id).
+		UpdateColumn("last_item_id", itemID).Error
+}
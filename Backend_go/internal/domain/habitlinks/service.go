@@ -0,0 +1,226 @@
+package habitlinks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// linkRunKey marks the context of a link run. Completions published while a link runs
+// are not acted on, so linked work can never start a chain of habit runs.
+type linkRunKey struct{}
+
+// Service defines the interface for habit link operations
+type Service interface {
+	GetLink(ctx context.Context, userID, habitID uuid.UUID) (*Link, error)
+	SetLink(ctx context.Context, userID, habitID uuid.UUID, input LinkInput) (*Link, error)
+	DeleteLink(ctx context.Context, userID, habitID uuid.UUID) error
+	// Run performs the link of a completed habit
+	Run(ctx context.Context, event events.HabitCompleted) error
+	Subscribe(bus *events.Bus)
+}
+
+type service struct {
+	repo         Repository
+	habitService habits.Service
+	taskService  task.Service
+	todoService  todos.Service
+	logger       *zap.Logger
+}
+
+// NewService creates a new habit link service
+func NewService(repo Repository, habitService habits.Service, taskService task.Service, todoService todos.Service, logger *zap.Logger) Service {
+	return &service{
+		repo:         repo,
+		habitService: habitService,
+		taskService:  taskService,
+		todoService:  todoService,
+		logger:       logger,
+	}
+}
+
+// Subscribe runs habit links when their habits are completed
+func (s *service) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, "habit-links", events.Async, s.Run)
+}
+
+// ownHabit checks that the habit exists and belongs to the user
+func (s *service) ownHabit(ctx context.Context, userID, habitID uuid.UUID) error {
+	habit, err := s.habitService.GetHabit(ctx, habitID)
+	if err != nil {
+		return err
+	}
+	if habit == nil || habit.UserID != userID {
+		return habits.ErrHabitNotFound
+	}
+	return nil
+}
+
+func (s *service) GetLink(ctx context.Context, userID, habitID uuid.UUID) (*Link, error) {
+	if err := s.ownHabit(ctx, userID, habitID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByHabitID(ctx, habitID)
+}
+
+// SetLink creates or replaces the link of a habit the user owns
+func (s *service) SetLink(ctx context.Context, userID, habitID uuid.UUID, input LinkInput) (*Link, error) {
+	if err := s.ownHabit(ctx, userID, habitID); err != nil {
+		return nil, err
+	}
+
+	link := &Link{
+		ID:        uuid.New(),
+		HabitID:   habitID,
+		UserID:    userID,
+		Action:    input.Action,
+		Enabled:   input.Enabled,
+		TaskID:    input.TaskID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	switch input.Action {
+	case ActionWorklog:
+		if input.TaskID == nil || input.Hours <= 0 || input.Hours > MaxWorklogHours {
+			return nil, ErrInvalidLink
+		}
+		link.Hours = input.Hours
+	case ActionTodo:
+		link.TodoTitle = strings.TrimSpace(input.TodoTitle)
+		link.TodoDescription = input.TodoDescription
+		link.TodoPriority = input.TodoPriority
+		link.TodoDueInHours = input.TodoDueInHours
+		if link.TodoTitle == "" || len(link.TodoTitle) > 255 || link.TodoDueInHours < 0 {
+			return nil, ErrInvalidLink
+		}
+		switch todos.TodoPriority(link.TodoPriority) {
+		case "":
+			link.TodoPriority = string(todos.PriorityMedium)
+		case todos.PriorityHigh, todos.PriorityMedium, todos.PriorityLow:
+		default:
+			return nil, ErrInvalidLink
+		}
+	default:
+		return nil, ErrInvalidLink
+	}
+
+	if link.TaskID != nil {
+		t, err := s.taskService.GetTask(ctx, *link.TaskID)
+		if errors.Is(err, task.ErrTaskNotFound) {
+			return nil, ErrInvalidLink
+		}
+		if err != nil {
+			return nil, err
+		}
+		if t.CreatorID != userID && (t.AssigneeID == nil || *t.AssigneeID != userID) {
+			return nil, ErrTaskForbidden
+		}
+	}
+
+	if _, err := s.repo.FindByHabitID(ctx, habitID); errors.Is(err, ErrLinkNotFound) {
+		count, err := s.repo.CountByUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= MaxLinksPerUser {
+			return nil, ErrTooManyLinks
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, link); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByHabitID(ctx, habitID)
+}
+
+func (s *service) DeleteLink(ctx context.Context, userID, habitID uuid.UUID) error {
+	if err := s.ownHabit(ctx, userID, habitID); err != nil {
+		return err
+	}
+	return s.repo.DeleteByHabitID(ctx, habitID)
+}
+
+// Run logs work or creates a todo for a completed habit. A link runs once per
+// completion day, and never for completions that happen while a link is running.
+func (s *service) Run(ctx context.Context, event events.HabitCompleted) error {
+	if ctx.Value(linkRunKey{}) != nil {
+		return nil
+	}
+	link, err := s.repo.FindByHabitID(ctx, event.HabitID)
+	if errors.Is(err, ErrLinkNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !link.Enabled || link.UserID != event.UserID {
+		return nil
+	}
+
+	completedAt := event.CompletedAt.UTC()
+	day := time.Date(completedAt.Year(), completedAt.Month(), completedAt.Day(), 0, 0, 0, 0, time.UTC)
+	claimed, err := s.repo.ClaimRun(ctx, link.ID, day)
+	if err != nil || !claimed {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, linkRunKey{}, link.ID)
+	itemID, err := s.perform(ctx, link, event)
+	if err != nil {
+		if releaseErr := s.repo.ReleaseRun(ctx, link.ID, link.LastRunOn); releaseErr != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to release habit link run",
+				zap.String("link_id", link.ID.String()), zap.Error(releaseErr))
+		}
+		return err
+	}
+	return s.repo.SetLastItem(ctx, link.ID, itemID)
+}
+
+// perform carries out the link's action and returns the todo created or the task logged on
+func (s *service) perform(ctx context.Context, link *Link, event events.HabitCompleted) (uuid.UUID, error) {
+	switch link.Action {
+	case ActionWorklog:
+		if link.TaskID == nil {
+			return uuid.Nil, ErrInvalidLink
+		}
+		t, err := s.taskService.LogWork(ctx, *link.TaskID, link.UserID, link.Hours, "Logged by habit: "+event.Title)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return t.ID, nil
+	case ActionTodo:
+		list, err := s.todoService.GetOrCreateDefaultList(ctx, link.UserID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		input := todos.CreateTodoInput{
+			Title:        link.TodoTitle,
+			Description:  link.TodoDescription,
+			Priority:     todos.TodoPriority(link.TodoPriority),
+			LinkedTaskID: link.TaskID,
+			UserID:       link.UserID,
+			ListID:       list.ID,
+		}
+		if link.TodoDueInHours > 0 {
+			due := event.CompletedAt.Add(time.Duration(link.TodoDueInHours) * time.Hour)
+			input.DueDate = &due
+		}
+		todo, err := s.todoService.CreateTodo(ctx, input)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return todo.ID, nil
+	}
+	return uuid.Nil, ErrInvalidLink
+}
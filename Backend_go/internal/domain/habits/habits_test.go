@@ -198,3 +198,37 @@ func (m *mockRepository) LogStreakHistory(ctx context.Context, habitID uuid.UUID
 
 	return nil
 }
+
+func TestMemoryRepositoryStreaks(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	userID := uuid.New()
+
+	habit := &Habit{UserID: userID, Title: "Read", StartDay: time.Now().AddDate(0, 0, -3)}
+	assert.NoError(t, repo.Create(ctx, habit))
+	assert.NotEqual(t, uuid.Nil, habit.ID)
+
+	due, err := repo.GetHabitsDueToday(ctx, userID)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	assert.NoError(t, repo.MarkCompleted(ctx, habit.ID, userID, nil))
+	assert.ErrorIs(t, repo.MarkCompleted(ctx, habit.ID, uuid.New(), nil), ErrHabitNotFound)
+
+	stored, err := repo.FindByID(ctx, habit.ID)
+	assert.NoError(t, err)
+	assert.True(t, stored.IsCompleted)
+	assert.Equal(t, 1, stored.CurrentStreak)
+	assert.Equal(t, 1, stored.LongestStreak)
+
+	due, err = repo.GetHabitsDueToday(ctx, userID)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	assert.NoError(t, repo.UnmarkCompleted(ctx, habit.ID, userID))
+	stored, err = repo.FindByID(ctx, habit.ID)
+	assert.NoError(t, err)
+	assert.False(t, stored.IsCompleted)
+	assert.Equal(t, 0, stored.CurrentStreak)
+	assert.Equal(t, 1, stored.LongestStreak)
+}
@@ -0,0 +1,427 @@
+package habits
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryRepository keeps habits and their history in memory, for tests and the demo server.
+// Records are stored by value so callers never share them with the store.
+type memoryRepository struct {
+	mu          sync.RWMutex
+	habits      map[uuid.UUID]Habit
+	history     []StreakHistory
+	completions []HabitCompletionLog
+	analytics   []HabitAnalytics
+}
+
+// NewMemoryRepository creates a Repository that keeps everything in memory
+func NewMemoryRepository() Repository {
+	return &memoryRepository{habits: make(map[uuid.UUID]Habit)}
+}
+
+// utcDay truncates t to the start of its day in UTC
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (r *memoryRepository) Create(ctx context.Context, habit *Habit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if habit.ID == uuid.Nil {
+		habit.ID = uuid.New()
+	}
+	now := time.Now()
+	if habit.CreatedAt.IsZero() {
+		habit.CreatedAt = now
+	}
+	if habit.StartDay.IsZero() {
+		habit.StartDay = now
+	}
+	habit.UpdatedAt = now
+	r.habits[habit.ID] = *habit
+	return nil
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*Habit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	habit, ok := r.habits[id]
+	if !ok {
+		return nil, ErrHabitNotFound
+	}
+	return &habit, nil
+}
+
+// find returns copies of the habits that match, oldest first
+func (r *memoryRepository) find(match func(*Habit) bool) []Habit {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := []Habit{}
+	for _, habit := range r.habits {
+		if match(&habit) {
+			result = append(result, habit)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+// update applies fn to the habit with the given id, if there is one
+func (r *memoryRepository) update(id uuid.UUID, fn func(*Habit) bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	habit, ok := r.habits[id]
+	if !ok || !fn(&habit) {
+		return false
+	}
+	habit.UpdatedAt = time.Now()
+	r.habits[id] = habit
+	return true
+}
+
+func (r *memoryRepository) FindAll(ctx context.Context, filter HabitFilter) ([]Habit, int64, error) {
+	matches := r.find(func(h *Habit) bool {
+		if filter.UserID != nil && h.UserID != *filter.UserID {
+			return false
+		}
+		return filter.Title == nil || strings.Contains(h.Title, *filter.Title)
+	})
+
+	total := int64(len(matches))
+	pageSize := filter.PageSize
+	if pageSize == 0 {
+		pageSize = 10000
+	}
+	start := filter.Page * pageSize
+	if start >= len(matches) {
+		return []Habit{}, total, nil
+	}
+	end := start + pageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end], total, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, habit *Habit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.habits[habit.ID]; !ok {
+		return ErrHabitNotFound
+	}
+	habit.UpdatedAt = time.Now()
+	r.habits[habit.ID] = *habit
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.habits[id]; !ok {
+		return ErrHabitNotFound
+	}
+	delete(r.habits, id)
+	return nil
+}
+
+func (r *memoryRepository) FindByTitle(ctx context.Context, title string, userID uuid.UUID) (*Habit, error) {
+	matches := r.find(func(h *Habit) bool { return h.Title == title && h.UserID == userID })
+	if len(matches) == 0 {
+		return nil, ErrHabitNotFound
+	}
+	return &matches[0], nil
+}
+
+func (r *memoryRepository) MarkCompleted(ctx context.Context, id uuid.UUID, userID uuid.UUID, completionDate *time.Time) error {
+	now := time.Now()
+	if completionDate == nil {
+		completionDate = &now
+	}
+	updated := r.update(id, func(h *Habit) bool {
+		if h.UserID != userID {
+			return false
+		}
+		h.IsCompleted = true
+		date := *completionDate
+		h.LastCompletedDate = &date
+		if h.CurrentStreak+1 > h.LongestStreak {
+			h.LongestStreak = h.CurrentStreak + 1
+		}
+		h.CurrentStreak++
+		return true
+	})
+	if !updated {
+		return ErrHabitNotFound
+	}
+	return nil
+}
+
+func (r *memoryRepository) UnmarkCompleted(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	updated := r.update(id, func(h *Habit) bool {
+		if h.UserID != userID {
+			return false
+		}
+		h.IsCompleted = false
+		h.CurrentStreak--
+		return true
+	})
+	if !updated {
+		return ErrHabitNotFound
+	}
+	return nil
+}
+
+func (r *memoryRepository) ResetDailyCompletions(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	today := utcDay(time.Now())
+	var count int64
+	for id, h := range r.habits {
+		if h.IsCompleted && h.LastCompletedDate != nil && utcDay(*h.LastCompletedDate).Before(today) {
+			h.IsCompleted = false
+			r.habits[id] = h
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryRepository) CheckAndResetBrokenStreaks(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for id, h := range r.habits {
+		if h.CurrentStreak > 0 && streakBroken(h.LastCompletedDate) {
+			h.CurrentStreak = 0
+			r.habits[id] = h
+			count++
+		}
+	}
+	return count, nil
+}
+
+// streakBroken reports whether a habit last completed at lastCompletedDate missed yesterday
+func streakBroken(lastCompletedDate *time.Time) bool {
+	if lastCompletedDate == nil {
+		return true
+	}
+	yesterday := utcDay(time.Now()).AddDate(0, 0, -1)
+	return utcDay(*lastCompletedDate).Before(yesterday)
+}
+
+func (r *memoryRepository) GetTopStreaks(ctx context.Context, userID uuid.UUID, limit int) ([]Habit, error) {
+	habits := r.find(func(h *Habit) bool { return h.UserID == userID })
+	sort.SliceStable(habits, func(i, j int) bool { return habits[i].CurrentStreak > habits[j].CurrentStreak })
+	if limit > 0 && len(habits) > limit {
+		habits = habits[:limit]
+	}
+	return habits, nil
+}
+
+// dueToday reports whether a habit is scheduled for today
+func dueToday(h *Habit) bool {
+	today := utcDay(time.Now())
+	return !h.IsCompleted && !h.StartDay.After(today) && (h.EndDay == nil || !h.EndDay.Before(today))
+}
+
+func (r *memoryRepository) GetHabitsDueToday(ctx context.Context, userID uuid.UUID) ([]Habit, error) {
+	return r.find(func(h *Habit) bool { return h.UserID == userID && dueToday(h) }), nil
+}
+
+func (r *memoryRepository) GetUncompletedHabitsDueToday(ctx context.Context) ([]Habit, error) {
+	return r.find(dueToday), nil
+}
+
+func (r *memoryRepository) FindCompletedHabits(ctx context.Context, habits *[]Habit) error {
+	*habits = r.find(func(h *Habit) bool { return h.IsCompleted })
+	return nil
+}
+
+func (r *memoryRepository) GetActiveStreaks(ctx context.Context) ([]Habit, error) {
+	return r.find(func(h *Habit) bool { return h.CurrentStreak > 0 }), nil
+}
+
+func (r *memoryRepository) LogStreakHistory(ctx context.Context, habitID uuid.UUID, streakLength int, lastCompletedDate time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	startDate := lastCompletedDate.AddDate(0, 0, -streakLength+1)
+
+	// Subtract days already counted by an overlapping history entry, as the database
+	// repository does
+	adjustedCompletedDays := streakLength
+	for _, h := range r.history {
+		if h.HabitID != habitID {
+			continue
+		}
+		if h.StartDate.After(startDate) && h.StartDate.Before(lastCompletedDate) ||
+			h.EndDate.After(startDate) && h.EndDate.Before(lastCompletedDate) {
+			overlap := int(h.EndDate.Sub(h.StartDate).Hours()/24) + 1
+			adjustedCompletedDays = streakLength - overlap
+		}
+	}
+
+	r.history = append(r.history, StreakHistory{
+		ID:            uuid.New(),
+		HabitID:       habitID,
+		StartDate:     startDate,
+		EndDate:       lastCompletedDate,
+		StreakLength:  streakLength,
+		CompletedDays: adjustedCompletedDays,
+		CreatedAt:     time.Now(),
+	})
+	return nil
+}
+
+func (r *memoryRepository) ResetStreak(ctx context.Context, habitID uuid.UUID) error {
+	r.update(habitID, func(h *Habit) bool {
+		h.CurrentStreak = 0
+		h.StreakStartDate = nil
+		return true
+	})
+	return nil
+}
+
+func (r *memoryRepository) GetStreakHistory(ctx context.Context, habitID uuid.UUID) ([]StreakHistory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	history := []StreakHistory{}
+	for _, h := range r.history {
+		if h.HabitID == habitID {
+			history = append(history, h)
+		}
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].EndDate.After(history[j].EndDate) })
+	return history, nil
+}
+
+func (r *memoryRepository) UpdateStreakQuality(ctx context.Context, habitID uuid.UUID) error {
+	history, _ := r.GetStreakHistory(ctx, habitID)
+	var totalDays, completedDays int
+	for _, h := range history {
+		totalDays += int(h.EndDate.Sub(h.StartDate).Hours() / 24)
+		completedDays += h.CompletedDays
+	}
+	quality := 0.0
+	if totalDays > 0 {
+		quality = float64(completedDays) / float64(totalDays)
+	}
+	r.update(habitID, func(h *Habit) bool {
+		h.StreakQuality = quality
+		return true
+	})
+	return nil
+}
+
+func (r *memoryRepository) IsStreakBroken(ctx context.Context, lastCompletedDate *time.Time) (bool, error) {
+	return streakBroken(lastCompletedDate), nil
+}
+
+func (r *memoryRepository) LogHabitCompletion(ctx context.Context, habitID uuid.UUID, userID uuid.UUID, date time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completions = append(r.completions, HabitCompletionLog{
+		ID:        uuid.New(),
+		HabitID:   habitID,
+		UserID:    userID,
+		Date:      date,
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+func (r *memoryRepository) RemoveHabitCompletion(ctx context.Context, habitID uuid.UUID, userID uuid.UUID, date time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	day := date.Format("2006-01-02")
+	kept := r.completions[:0]
+	for _, log := range r.completions {
+		if log.HabitID == habitID && log.UserID == userID && log.Date.Format("2006-01-02") == day {
+			continue
+		}
+		kept = append(kept, log)
+	}
+	r.completions = kept
+	return nil
+}
+
+func (r *memoryRepository) GetHeatmapData(ctx context.Context, userID uuid.UUID, startDate time.Time, endDate time.Time) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	heatmap := make(map[string]int)
+	for _, log := range r.completions {
+		if log.UserID == userID && !log.Date.Before(startDate) && !log.Date.After(endDate) {
+			heatmap[log.Date.Format("2006-01-02")]++
+		}
+	}
+	return heatmap, nil
+}
+
+func (r *memoryRepository) RecordHabitActivity(ctx context.Context, analytics *HabitAnalytics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if analytics.ID == uuid.Nil {
+		analytics.ID = uuid.New()
+	}
+	if analytics.Timestamp.IsZero() {
+		analytics.Timestamp = time.Now()
+	}
+	r.analytics = append(r.analytics, *analytics)
+	return nil
+}
+
+func (r *memoryRepository) GetHabitAnalytics(ctx context.Context, filter AnalyticsFilter) ([]HabitAnalytics, int64, error) {
+	r.mu.RLock()
+	matches := []HabitAnalytics{}
+	for _, a := range r.analytics {
+		switch {
+		case filter.HabitID != nil && a.HabitID != *filter.HabitID,
+			filter.UserID != nil && a.UserID != *filter.UserID,
+			filter.Action != nil && a.Action != *filter.Action,
+			filter.StartTime != nil && a.Timestamp.Before(*filter.StartTime),
+			filter.EndTime != nil && a.Timestamp.After(*filter.EndTime):
+			continue
+		}
+		matches = append(matches, a)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Timestamp.After(matches[j].Timestamp) })
+	total := int64(len(matches))
+	start := filter.Page * filter.PageSize
+	if start >= len(matches) {
+		return []HabitAnalytics{}, total, nil
+	}
+	end := start + filter.PageSize
+	if filter.PageSize <= 0 || end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end], total, nil
+}
+
+func (r *memoryRepository) GetHabitActivitySummary(ctx context.Context, habitID uuid.UUID, startTime, endTime time.Time) (map[string]int, error) {
+	return r.activitySummary(func(a *HabitAnalytics) bool { return a.HabitID == habitID }, startTime, endTime), nil
+}
+
+func (r *memoryRepository) GetUserHabitActivitySummary(ctx context.Context, userID uuid.UUID, startTime, endTime time.Time) (map[string]int, error) {
+	return r.activitySummary(func(a *HabitAnalytics) bool { return a.UserID == userID }, startTime, endTime), nil
+}
+
+// activitySummary counts the matching activity between startTime and endTime by action
+func (r *memoryRepository) activitySummary(match func(*HabitAnalytics) bool, startTime, endTime time.Time) map[string]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	summary := make(map[string]int)
+	for _, a := range r.analytics {
+		if match(&a) && !a.Timestamp.Before(startTime) && !a.Timestamp.After(endTime) {
+			summary[a.Action]++
+		}
+	}
+	return summary
+}
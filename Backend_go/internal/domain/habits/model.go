@@ -1,6 +1,7 @@
 package habits
 
 import (
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +23,14 @@ type Habit struct {
 	CreatedAt         time.Time  `gorm:"not null;default:current_timestamp"`
 	UpdatedAt         time.Time  `gorm:"not null;default:current_timestamp;autoUpdateTime"`
 	StreakQuality     float64    `gorm:"default:0;not null"` // Stored in DB for faster retrieval
+	ReminderTime      *string    `gorm:"type:varchar(5)"`    // Time of day to remind, HH:MM in UTC
+}
+
+var reminderTimePattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// IsValidReminderTime reports whether value is a time of day in HH:MM form
+func IsValidReminderTime(value string) bool {
+	return reminderTimePattern.MatchString(value)
 }
 
 // StreakHistory represents a historical record of a habit streak
@@ -37,19 +46,21 @@ type StreakHistory struct {
 
 // CreateHabitInput represents the input for creating a new habit
 type CreateHabitInput struct {
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	StartDay    time.Time  `json:"start_day"`
-	EndDay      *time.Time `json:"end_day"`
-	UserID      uuid.UUID  `json:"user_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	StartDay     time.Time  `json:"start_day"`
+	EndDay       *time.Time `json:"end_day"`
+	ReminderTime *string    `json:"reminder_time,omitempty"`
+	UserID       uuid.UUID  `json:"user_id"`
 }
 
 // UpdateHabitInput represents the input for updating a habit
 type UpdateHabitInput struct {
-	Title       *string    `json:"title,omitempty"`
-	Description *string    `json:"description,omitempty"`
-	StartDay    *time.Time `json:"start_day,omitempty"`
-	EndDay      *time.Time `json:"end_day,omitempty"`
+	Title        *string    `json:"title,omitempty"`
+	Description  *string    `json:"description,omitempty"`
+	StartDay     *time.Time `json:"start_day,omitempty"`
+	EndDay       *time.Time `json:"end_day,omitempty"`
+	ReminderTime *string    `json:"reminder_time,omitempty"`
 }
 
 // HabitResponse represents the response body for a habit
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
 	"github.com/google/uuid"
 )
@@ -28,6 +29,23 @@ func (s *HabitNotificationService) WithDomainNotifier(notifier notification.Doma
 	return s
 }
 
+// Subscribe sends the habit notifications driven by domain events
+func (s *HabitNotificationService) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, "habit_notifications", events.Async, func(ctx context.Context, event events.HabitStreakBroken) error {
+		// Short streaks are not worth a notification
+		if event.PreviousStreak < 3 {
+			return nil
+		}
+		habit := &Habit{
+			ID:                event.HabitID,
+			UserID:            event.UserID,
+			Title:             event.Title,
+			LastCompletedDate: event.LastCompletedDate,
+		}
+		return s.NotifyHabitStreakBroken(ctx, event.UserID, habit, event.PreviousStreak)
+	})
+}
+
 // NotifyHabitCompleted sends a notification when a habit is completed
 func (s *HabitNotificationService) NotifyHabitCompleted(ctx context.Context, userID uuid.UUID, habit *Habit) error {
 	title := "Habit Completed"
@@ -148,13 +166,13 @@ func (s *HabitNotificationService) NotifyHabitReminder(ctx context.Context, user
 	title := "Habit Reminder"
 	content := fmt.Sprintf("Don't forget to complete your habit: %s", habit.Title)
 	data := map[string]string{
-		"habitID": habit.ID.String(),
-		"title":   habit.Title,
+		"habitID":       habit.ID.String(),
+		"title":         habit.Title,
+		"currentStreak": fmt.Sprintf("%d", habit.CurrentStreak),
 	}
 
 	// Use domain notifier if available
 	if s.domainNotifier != nil {
-		// For reminders, we only use InApp for now
 		return s.domainNotifier.NotifyUserWithDelivery(
 			ctx,
 			userID,
@@ -164,7 +182,7 @@ func (s *HabitNotificationService) NotifyHabitReminder(ctx context.Context, user
 			data,
 			"habits",
 			habit.ID,
-			[]notification.DeliveryMethod{notification.InApp},
+			[]notification.DeliveryMethod{notification.InApp, notification.Email},
 		)
 	}
 
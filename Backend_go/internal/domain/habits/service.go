@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"sort"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -54,26 +55,35 @@ type service struct {
 	repo      Repository
 	notifySvc *HabitNotificationService
 	redis     *cache.RedisClient
+	webhooks  webhooks.Publisher
+	bus       events.Publisher
 	logger    *zap.Logger
 }
 
-func NewService(repo Repository, notifySvc *HabitNotificationService, redis *cache.RedisClient, logger *zap.Logger) Service {
+func NewService(repo Repository, notifySvc *HabitNotificationService, redis *cache.RedisClient, webhookPublisher webhooks.Publisher, bus events.Publisher, logger *zap.Logger) Service {
 	return &service{
 		repo:      repo,
 		notifySvc: notifySvc,
 		redis:     redis,
+		webhooks:  webhookPublisher,
+		bus:       bus,
 		logger:    logger,
 	}
 }
 
 func (s *service) CreateHabit(ctx context.Context, input CreateHabitInput) (*Habit, error) {
+	if input.ReminderTime != nil && !IsValidReminderTime(*input.ReminderTime) {
+		return nil, ErrInvalidInput
+	}
+
 	habit := &Habit{
-		ID:          uuid.New(),
-		UserID:      input.UserID,
-		Title:       input.Title,
-		Description: input.Description,
-		StartDay:    input.StartDay,
-		EndDay:      input.EndDay,
+		ID:           uuid.New(),
+		UserID:       input.UserID,
+		Title:        input.Title,
+		Description:  input.Description,
+		StartDay:     input.StartDay,
+		EndDay:       input.EndDay,
+		ReminderTime: input.ReminderTime,
 	}
 
 	err := s.repo.Create(ctx, habit)
@@ -98,9 +108,11 @@ func (s *service) CreateHabit(ctx context.Context, input CreateHabitInput) (*Hab
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
+	s.publishWebhook(ctx, webhooks.EventHabitCreated, habit)
+
 	return habit, nil
 }
 
@@ -171,6 +183,20 @@ func (s *service) UpdateHabit(ctx context.Context, id uuid.UUID, input UpdateHab
 			changed = true
 		}
 	}
+	if input.ReminderTime != nil {
+		// An empty string clears the reminder
+		if *input.ReminderTime == "" {
+			if habit.ReminderTime != nil {
+				habit.ReminderTime = nil
+				changed = true
+			}
+		} else if !IsValidReminderTime(*input.ReminderTime) {
+			return nil, ErrInvalidInput
+		} else if habit.ReminderTime == nil || *habit.ReminderTime != *input.ReminderTime {
+			habit.ReminderTime = input.ReminderTime
+			changed = true
+		}
+	}
 
 	if !changed {
 		return habit, nil
@@ -263,7 +289,7 @@ func (s *service) MarkCompleted(ctx context.Context, id uuid.UUID, userID uuid.U
 
 	// Update streak quality after marking completed
 	if err := s.repo.UpdateStreakQuality(ctx, id); err != nil {
-		log.Printf("failed to update streak quality for habit %s: %v", id, err)
+		tracing.Logger(ctx, s.logger).Error("Failed to update streak quality", zap.String("habit_id", id.String()), zap.Error(err))
 	}
 
 	// Log the habit completion for heatmap
@@ -273,13 +299,13 @@ func (s *service) MarkCompleted(ctx context.Context, id uuid.UUID, userID uuid.U
 	}
 
 	if err := s.repo.LogHabitCompletion(ctx, id, userID, completionTime); err != nil {
-		log.Printf("failed to log habit completion for heatmap: %v", err)
+		tracing.Logger(ctx, s.logger).Error("Failed to log habit completion for heatmap", zap.String("habit_id", id.String()), zap.Error(err))
 	}
 
 	// Get updated habit with new streak information
 	updatedHabit, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		log.Printf("failed to fetch updated habit data: %v", err)
+		tracing.Logger(ctx, s.logger).Error("Failed to fetch updated habit data", zap.String("habit_id", id.String()), zap.Error(err))
 		return nil
 	}
 
@@ -299,13 +325,13 @@ func (s *service) MarkCompleted(ctx context.Context, id uuid.UUID, userID uuid.U
 	// Send habit completion notification
 	if s.notifySvc != nil {
 		if err := s.notifySvc.NotifyHabitCompleted(ctx, userID, updatedHabit); err != nil {
-			log.Printf("failed to send habit completion notification: %v", err)
+			tracing.Logger(ctx, s.logger).Error("Failed to send habit completion notification", zap.String("habit_id", id.String()), zap.Error(err))
 		}
 
 		// Check if we should send a streak notification
 		if s.notifySvc.ShouldSendStreakNotification(updatedHabit.CurrentStreak) {
 			if err := s.notifySvc.NotifyHabitStreak(ctx, userID, updatedHabit); err != nil {
-				log.Printf("failed to send habit streak notification: %v", err)
+				tracing.Logger(ctx, s.logger).Error("Failed to send habit streak notification", zap.String("habit_id", id.String()), zap.Error(err))
 			}
 		}
 	}
@@ -322,12 +348,36 @@ func (s *service) MarkCompleted(ctx context.Context, id uuid.UUID, userID uuid.U
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
+	}
+
+	s.publishWebhook(ctx, webhooks.EventHabitCompleted, updatedHabit)
+
+	if s.bus != nil {
+		s.bus.Publish(ctx, events.HabitCompleted{
+			HabitID:       updatedHabit.ID,
+			UserID:        userID,
+			Title:         updatedHabit.Title,
+			CompletedAt:   completionTime,
+			CurrentStreak: updatedHabit.CurrentStreak,
+		})
 	}
 
 	return nil
 }
 
+// publishWebhook sends a habit event to the owner's personal webhooks
+func (s *service) publishWebhook(ctx context.Context, eventType webhooks.EventType, habit *Habit) {
+	if s.webhooks == nil {
+		return
+	}
+	s.webhooks.Publish(ctx, webhooks.Event{
+		Type:   eventType,
+		UserID: habit.UserID,
+		Data:   map[string]interface{}{"habit": habit},
+	})
+}
+
 // Helper to record habit completion
 func (s *service) recordHabitCompletion(ctx context.Context, habit *Habit, completionTime time.Time) {
 	metadata := map[string]interface{}{
@@ -383,7 +433,7 @@ func (s *service) UnmarkCompleted(ctx context.Context, id uuid.UUID, userID uuid
 
 	// First remove the completion log for heatmap
 	if err := s.repo.RemoveHabitCompletion(ctx, id, userID, lastCompletedDate); err != nil {
-		log.Printf("failed to remove habit completion log: %v", err)
+		tracing.Logger(ctx, s.logger).Error("Failed to remove habit completion log", zap.String("habit_id", id.String()), zap.Error(err))
 		// Don't return here as we still want to unmark the habit
 	}
 
@@ -394,7 +444,7 @@ func (s *service) UnmarkCompleted(ctx context.Context, id uuid.UUID, userID uuid
 
 	// Update streak quality after unmarking completed
 	if err := s.repo.UpdateStreakQuality(ctx, id); err != nil {
-		log.Printf("failed to update streak quality for habit %s: %v", id, err)
+		tracing.Logger(ctx, s.logger).Error("Failed to update streak quality", zap.String("habit_id", id.String()), zap.Error(err))
 	}
 
 	// Record habit uncompletion activity
@@ -414,7 +464,7 @@ func (s *service) UnmarkCompleted(ctx context.Context, id uuid.UUID, userID uuid
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
 	return nil
@@ -455,7 +505,7 @@ func (s *service) CheckAndResetBrokenStreaks(ctx context.Context) (int64, error)
 		// Check if streak is broken using timezone-aware database function
 		isBroken, err := s.repo.IsStreakBroken(ctx, habit.LastCompletedDate)
 		if err != nil {
-			log.Printf("failed to check if streak is broken for habit %s: %v", habit.ID, err)
+			tracing.Logger(ctx, s.logger).Error("Failed to check if streak is broken", zap.String("habit_id", habit.ID.String()), zap.Error(err))
 			continue
 		}
 
@@ -471,28 +521,33 @@ func (s *service) CheckAndResetBrokenStreaks(ctx context.Context) (int64, error)
 
 			// Before resetting, store the streak history
 			if err := s.repo.LogStreakHistory(ctx, habit.ID, habit.CurrentStreak, lastDate); err != nil {
-				log.Printf("failed to log streak history for habit %s: %v", habit.ID, err)
+				tracing.Logger(ctx, s.logger).Error("Failed to log streak history", zap.String("habit_id", habit.ID.String()), zap.Error(err))
 			}
 
 			// Update streak quality after logging history
 			if err := s.repo.UpdateStreakQuality(ctx, habit.ID); err != nil {
-				log.Printf("failed to update streak quality for habit %s: %v", habit.ID, err)
+				tracing.Logger(ctx, s.logger).Error("Failed to update streak quality", zap.String("habit_id", habit.ID.String()), zap.Error(err))
 			}
 
 			// Reset the streak
 			if err := s.repo.ResetStreak(ctx, habit.ID); err != nil {
-				log.Printf("failed to reset streak for habit %s: %v", habit.ID, err)
+				tracing.Logger(ctx, s.logger).Error("Failed to reset streak", zap.String("habit_id", habit.ID.String()), zap.Error(err))
 				continue
 			}
 
 			// Record streak broken analytics event
 			s.recordStreakBroken(ctx, &habitCopy, previousStreak)
 
-			// Send streak broken notification if streak was significant
-			if s.notifySvc != nil && previousStreak >= 3 {
-				if err := s.notifySvc.NotifyHabitStreakBroken(ctx, habit.UserID, &habitCopy, previousStreak); err != nil {
-					log.Printf("failed to send habit streak broken notification: %v", err)
-				}
+			// Notifications and other reactions subscribe to the event
+			if s.bus != nil {
+				s.bus.Publish(ctx, events.HabitStreakBroken{
+					HabitID:           habit.ID,
+					UserID:            habit.UserID,
+					Title:             habit.Title,
+					PreviousStreak:    previousStreak,
+					LastCompletedDate: habit.LastCompletedDate,
+					BrokenAt:          time.Now(),
+				})
 			}
 
 			totalReset++
@@ -534,7 +589,7 @@ func (s *service) GetTopStreaks(ctx context.Context, userID uuid.UUID, limit int
 		// Get streak history
 		history, err := s.repo.GetStreakHistory(ctx, habits[i].ID)
 		if err != nil {
-			log.Printf("failed to fetch streak history for habit %s: %v", habits[i].ID, err)
+			tracing.Logger(ctx, s.logger).Error("Failed to fetch streak history", zap.String("habit_id", habits[i].ID.String()), zap.Error(err))
 			continue
 		}
 
@@ -623,7 +678,7 @@ func (s *service) GetHabitsDueToday(ctx context.Context, userID uuid.UUID) ([]Ha
 		}
 	}
 
-	s.logger.Info("GetHabitsDueToday results",
+	tracing.Logger(ctx, s.logger).Info("GetHabitsDueToday results",
 		zap.String("user_id", userID.String()),
 		zap.Int("total_found", len(activeHabits)))
 
@@ -669,14 +724,14 @@ func (s *service) SendHabitReminders(ctx context.Context) error {
 		// Only send reminders if the notification service is available
 		if s.notifySvc != nil {
 			if err := s.notifySvc.NotifyHabitReminder(ctx, habit.UserID, &habit); err != nil {
-				log.Printf("failed to send habit reminder notification for habit %s: %v", habit.ID, err)
+				tracing.Logger(ctx, s.logger).Error("Failed to send habit reminder notification", zap.String("habit_id", habit.ID.String()), zap.Error(err))
 				continue
 			}
 			sent++
 		}
 	}
 
-	log.Printf("sent %d habit reminders", sent)
+	tracing.Logger(ctx, s.logger).Info("Sent habit reminders", zap.Int("sent", sent))
 	return nil
 }
 
@@ -845,6 +900,6 @@ func (s *service) recordHabitActivity(ctx context.Context, habit *Habit, userID
 		Details:   metadata,
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 }
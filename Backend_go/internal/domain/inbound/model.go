@@ -0,0 +1,141 @@
+package inbound
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// MessageStatus is the outcome of processing an inbound email
+type MessageStatus string
+
+const (
+	MessageStatusAccepted MessageStatus = "accepted"
+	MessageStatusRejected MessageStatus = "rejected"
+)
+
+var (
+	ErrDisabled           = errors.New("email-in is not configured")
+	ErrAddressNotFound    = errors.New("inbound address not found")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrInvalidMessage     = errors.New("invalid email message")
+	ErrMessageTooLarge    = errors.New("email message too large")
+	ErrInvalidSender      = errors.New("invalid sender address")
+	ErrInvalidInput       = errors.New("invalid input")
+)
+
+// Config controls the email-in address domain and the limits applied to inbound mail
+type Config struct {
+	// Domain is the mail domain of generated addresses. Email-in is disabled when empty.
+	Domain             string
+	MaxMessageBytes    int64
+	MaxAttachmentBytes int64
+	MaxAttachments     int
+	MaxBodyLength      int
+	HourlyLimit        int64
+}
+
+// DefaultConfig returns the limits used in production for the given mail domain
+func DefaultConfig(domain string) Config {
+	return Config{
+		Domain:             domain,
+		MaxMessageBytes:    25 << 20,
+		MaxAttachmentBytes: 10 << 20,
+		MaxAttachments:     10,
+		MaxBodyLength:      20000,
+		HourlyLimit:        50,
+	}
+}
+
+// Address is a user's personal forward-to-inbox address.
+// Mail sent to <token>@<domain> from an allowed sender becomes a todo.
+type Address struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Token          string         `json:"token" gorm:"type:varchar(32);not null;uniqueIndex"`
+	ListID         *uuid.UUID     `json:"list_id,omitempty" gorm:"type:uuid"`
+	AllowedSenders pq.StringArray `json:"allowed_senders" gorm:"type:text[]"`
+	Active         bool           `json:"active" gorm:"not null;default:true"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Address model
+func (Address) TableName() string {
+	return "inbound_addresses"
+}
+
+// BeforeCreate is called before creating a new address record
+func (a *Address) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	a.CreatedAt = time.Now()
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating an address record
+func (a *Address) BeforeUpdate(tx *gorm.DB) error {
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// Message records the outcome of one inbound email, so users can see why mail was rejected
+type Message struct {
+	ID              uuid.UUID     `json:"id" gorm:"type:uuid;primary_key"`
+	AddressID       uuid.UUID     `json:"address_id" gorm:"type:uuid;not null;index:idx_inbound_message_address"`
+	UserID          uuid.UUID     `json:"user_id" gorm:"type:uuid;not null;index:idx_inbound_message_user"`
+	MessageID       string        `json:"message_id,omitempty" gorm:"type:varchar(998);index"`
+	Sender          string        `json:"sender" gorm:"type:varchar(320)"`
+	Subject         string        `json:"subject" gorm:"type:text"`
+	Status          MessageStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Reason          string        `json:"reason,omitempty" gorm:"type:text"`
+	TodoID          *uuid.UUID    `json:"todo_id,omitempty" gorm:"type:uuid"`
+	AttachmentCount int           `json:"attachment_count" gorm:"not null;default:0"`
+	CreatedAt       time.Time     `json:"created_at" gorm:"not null;default:current_timestamp;index"`
+}
+
+// TableName specifies the table name for the Message model
+func (Message) TableName() string {
+	return "inbound_messages"
+}
+
+// BeforeCreate is called before creating a new message record
+func (m *Message) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	m.CreatedAt = time.Now()
+	return nil
+}
+
+// Attachment is a file that arrived with an email and is stored with the resulting todo
+type Attachment struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	TodoID      uuid.UUID `json:"todo_id" gorm:"type:uuid;not null;index:idx_todo_attachment_todo"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	MessageID   uuid.UUID `json:"message_id" gorm:"type:uuid;not null"`
+	Filename    string    `json:"filename" gorm:"type:varchar(255);not null"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(255);not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+	Content     []byte    `json:"-" gorm:"type:bytea;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Attachment model
+func (Attachment) TableName() string {
+	return "todo_attachments"
+}
+
+// BeforeCreate is called before creating a new attachment record
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	a.CreatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,256 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// Email is the part of a parsed message used to build a todo
+type Email struct {
+	MessageID   string
+	From        string
+	Recipients  []string
+	Subject     string
+	Body        string
+	Attachments []EmailAttachment
+	// Skipped counts attachments dropped for exceeding the size limit
+	Skipped int
+	Spam    bool
+}
+
+// EmailAttachment is a decoded attachment of a parsed message
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+var (
+	htmlTagPattern     = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesPattern  = regexp.MustCompile(`\n{3,}`)
+	replyPrefixPattern = regexp.MustCompile(`(?i)^\s*(fwd?|fw|re)\s*:\s*`)
+	wordDecoder        = new(mime.WordDecoder)
+)
+
+// ParseEmail reads a raw RFC 5322 message. Attachments larger than maxAttachmentBytes are skipped.
+func ParseEmail(raw []byte, maxAttachmentBytes int64) (*Email, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSender, err)
+	}
+
+	email := &Email{
+		MessageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+		From:      strings.ToLower(from.Address),
+		Subject:   cleanSubject(decodeHeader(msg.Header.Get("Subject"))),
+		Spam:      isSpam(msg.Header),
+	}
+	for _, key := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		list, err := msg.Header.AddressList(key)
+		if err != nil {
+			continue
+		}
+		for _, addr := range list {
+			email.Recipients = append(email.Recipients, strings.ToLower(addr.Address))
+		}
+	}
+
+	var text, htmlText string
+	err = walkPart(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body,
+		func(mediaType, filename string, content []byte, oversized bool) {
+			switch {
+			case filename != "":
+				if oversized {
+					email.Skipped++
+					return
+				}
+				email.Attachments = append(email.Attachments, EmailAttachment{
+					Filename:    filename,
+					ContentType: mediaType,
+					Content:     content,
+				})
+			case mediaType == "text/plain" && text == "":
+				text = string(content)
+			case mediaType == "text/html" && htmlText == "":
+				htmlText = string(content)
+			}
+		}, maxAttachmentBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	if text == "" && htmlText != "" {
+		text = stripHTML(htmlText)
+	}
+	email.Body = normalizeBody(text)
+	return email, nil
+}
+
+type partVisitor func(mediaType, filename string, content []byte, oversized bool)
+
+// walkPart decodes a MIME part and visits every leaf part of it
+func walkPart(contentType, encoding, disposition string, body io.Reader, visit partVisitor, maxAttachmentBytes int64) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			err = walkPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"), part, visit, maxAttachmentBytes)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	filename := attachmentName(disposition, params)
+	limit := maxAttachmentBytes
+	if filename == "" {
+		// Bodies are truncated later; this only bounds memory use
+		limit = 4 << 20
+	}
+	content, err := io.ReadAll(io.LimitReader(decodeTransfer(encoding, body), limit+1))
+	if err != nil {
+		return err
+	}
+	oversized := int64(len(content)) > limit
+	if oversized {
+		content = content[:limit]
+	}
+	visit(mediaType, filename, content, oversized)
+	return nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// newlineStripper drops line breaks, which the base64 decoder does not accept mid-stream
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (n *newlineStripper) Read(p []byte) (int, error) {
+	for {
+		read, err := n.r.Read(p)
+		kept := 0
+		for _, b := range p[:read] {
+			if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// attachmentName returns the filename of a part that should be stored as an attachment
+func attachmentName(disposition string, params map[string]string) string {
+	if disposition != "" {
+		kind, dispParams, err := mime.ParseMediaType(disposition)
+		if err == nil {
+			if name := dispParams["filename"]; name != "" {
+				return sanitizeFilename(decodeHeader(name))
+			}
+			if kind == "attachment" {
+				return "attachment"
+			}
+		}
+	}
+	if name := params["name"]; name != "" {
+		return sanitizeFilename(decodeHeader(name))
+	}
+	return ""
+}
+
+func sanitizeFilename(name string) string {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	if name == "" {
+		return "attachment"
+	}
+	return name
+}
+
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// cleanSubject removes forwarding and reply prefixes such as "Fwd:" and "Re:"
+func cleanSubject(subject string) string {
+	for {
+		cleaned := replyPrefixPattern.ReplaceAllString(subject, "")
+		if cleaned == subject {
+			return strings.TrimSpace(subject)
+		}
+		subject = cleaned
+	}
+}
+
+func stripHTML(s string) string {
+	s = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n", "</div>", "\n").Replace(s)
+	return html.UnescapeString(htmlTagPattern.ReplaceAllString(s, ""))
+}
+
+func normalizeBody(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = blankLinesPattern.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// isSpam honours the verdicts of upstream spam filters and sender authentication
+func isSpam(header mail.Header) bool {
+	if strings.EqualFold(strings.TrimSpace(header.Get("X-Spam-Flag")), "yes") {
+		return true
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(header.Get("X-Spam-Status"))), "yes") {
+		return true
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(header.Get("Received-SPF"))), "fail") {
+		return true
+	}
+	results := strings.ToLower(header.Get("Authentication-Results"))
+	return strings.Contains(results, "spf=fail") || strings.Contains(results, "dmarc=fail")
+}
@@ -0,0 +1,142 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for email-in data access
+type Repository interface {
+	CreateAddress(ctx context.Context, address *Address) error
+	UpdateAddress(ctx context.Context, address *Address) error
+	FindAddressByUser(ctx context.Context, userID uuid.UUID) (*Address, error)
+	FindAddressByToken(ctx context.Context, token string) (*Address, error)
+
+	CreateMessage(ctx context.Context, message *Message) error
+	MessageExists(ctx context.Context, addressID uuid.UUID, messageID string) (bool, error)
+	ListMessages(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]Message, int64, error)
+
+	// CreateAttachments stores the attachments of a stored message and sets its attachment count
+	CreateAttachments(ctx context.Context, message *Message, attachments []*Attachment) error
+	ListAttachments(ctx context.Context, todoID uuid.UUID) ([]Attachment, error)
+	FindAttachmentByID(ctx context.Context, id uuid.UUID) (*Attachment, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new email-in repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// CreateAddress stores a new inbound address
+func (r *repository) CreateAddress(ctx context.Context, address *Address) error {
+	return r.db.WithContext(ctx).Create(address).Error
+}
+
+// UpdateAddress saves changes to an inbound address
+func (r *repository) UpdateAddress(ctx context.Context, address *Address) error {
+	return r.db.WithContext(ctx).Save(address).Error
+}
+
+// FindAddressByUser retrieves the inbound address of a user
+func (r *repository) FindAddressByUser(ctx context.Context, userID uuid.UUID) (*Address, error) {
+	var address Address
+	if err := r.db.WithContext(ctx).First(&address, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// FindAddressByToken retrieves the inbound address with the given local part
+func (r *repository) FindAddressByToken(ctx context.Context, token string) (*Address, error) {
+	var address Address
+	if err := r.db.WithContext(ctx).First(&address, "token = ?", token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// CreateMessage records the outcome of an inbound email
+func (r *repository) CreateMessage(ctx context.Context, message *Message) error {
+	return r.db.WithContext(ctx).Create(message).Error
+}
+
+// MessageExists reports whether an email with the given Message-ID was already processed for the address
+func (r *repository) MessageExists(ctx context.Context, addressID uuid.UUID, messageID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Message{}).
+		Where("address_id = ? AND message_id = ?", addressID, messageID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListMessages returns the inbound emails of a user, newest first
+func (r *repository) ListMessages(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]Message, int64, error) {
+	var messages []Message
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Message{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Offset(page * pageSize).
+		Limit(pageSize).
+		Find(&messages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return messages, total, nil
+}
+
+// CreateAttachments stores the attachments of an inbound email and the message's count of
+// them in one transaction
+func (r *repository) CreateAttachments(ctx context.Context, message *Message, attachments []*Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attachments).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Message{}).Where("id = ?", message.ID).
+			Update("attachment_count", len(attachments)).Error
+	})
+}
+
+// ListAttachments returns the attachments of a todo without their content
+func (r *repository) ListAttachments(ctx context.Context, todoID uuid.UUID) ([]Attachment, error) {
+	var attachments []Attachment
+	err := r.db.WithContext(ctx).
+		Select("id", "todo_id", "user_id", "message_id", "filename", "content_type", "size", "created_at").
+		Where("todo_id = ?", todoID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// FindAttachmentByID retrieves an attachment including its content
+func (r *repository) FindAttachmentByID(ctx context.Context, id uuid.UUID) (*Attachment, error) {
+	var attachment Attachment
+	if err := r.db.WithContext(ctx).First(&attachment, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,471 @@
+package inbound
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// queueKey is the Redis list holding raw emails waiting for the ingestion worker
+const queueKey = "inbound:email:queue"
+
+var tokenEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// UpdateAddressInput is the input for changing an inbound address
+type UpdateAddressInput struct {
+	AllowedSenders []string
+	ListID         *uuid.UUID
+	Active         *bool
+}
+
+// Service defines the interface for email-in business logic
+type Service interface {
+	Config() Config
+	GetAddress(ctx context.Context, userID uuid.UUID) (*Address, error)
+	RegenerateAddress(ctx context.Context, userID uuid.UUID) (*Address, error)
+	UpdateAddress(ctx context.Context, userID uuid.UUID, input UpdateAddressInput) (*Address, error)
+	ListMessages(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]Message, int64, error)
+	ListAttachments(ctx context.Context, todoID, userID uuid.UUID) ([]Attachment, error)
+	GetAttachment(ctx context.Context, id, userID uuid.UUID) (*Attachment, error)
+
+	// Enqueue hands a raw email to the ingestion worker
+	Enqueue(ctx context.Context, raw []byte, recipient string) error
+	// Process turns a raw email into a todo, or records why it was rejected
+	Process(ctx context.Context, raw []byte, recipient string) (*Message, error)
+}
+
+type service struct {
+	repo        Repository
+	todoService todos.Service
+	userService user.Service
+	redis       *redis.Client
+	usage       metering.Recorder
+	config      Config
+	logger      *zap.Logger
+}
+
+// NewService creates a new email-in service instance
+func NewService(repo Repository, todoService todos.Service, userService user.Service, redisClient *cache.RedisClient, usage metering.Recorder, config Config, logger *zap.Logger) Service {
+	return &service{
+		repo:        repo,
+		todoService: todoService,
+		userService: userService,
+		redis:       redisClient.GetClient(),
+		usage:       usage,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// Config returns the address domain and limits of email-in
+func (s *service) Config() Config {
+	return s.config
+}
+
+// GetAddress returns the user's inbound address, generating one on first use
+func (s *service) GetAddress(ctx context.Context, userID uuid.UUID) (*Address, error) {
+	if s.config.Domain == "" {
+		return nil, ErrDisabled
+	}
+	address, err := s.repo.FindAddressByUser(ctx, userID)
+	if err == nil {
+		return address, nil
+	}
+	if err != ErrAddressNotFound {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	address = &Address{UserID: userID, Token: token, Active: true}
+	if err := s.repo.CreateAddress(ctx, address); err != nil {
+		// Another request may have created the address concurrently
+		if existing, findErr := s.repo.FindAddressByUser(ctx, userID); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return address, nil
+}
+
+// RegenerateAddress replaces the user's address, so mail to the old one is no longer accepted
+func (s *service) RegenerateAddress(ctx context.Context, userID uuid.UUID) (*Address, error) {
+	address, err := s.GetAddress(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	address.Token = token
+	if err := s.repo.UpdateAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// UpdateAddress changes the sender allow-list, target list or state of the user's address
+func (s *service) UpdateAddress(ctx context.Context, userID uuid.UUID, input UpdateAddressInput) (*Address, error) {
+	address, err := s.GetAddress(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.AllowedSenders != nil {
+		senders, err := normalizeSenders(input.AllowedSenders)
+		if err != nil {
+			return nil, err
+		}
+		address.AllowedSenders = senders
+	}
+	if input.ListID != nil {
+		if *input.ListID == uuid.Nil {
+			address.ListID = nil
+		} else {
+			list, err := s.todoService.GetTodoList(ctx, *input.ListID)
+			if err != nil {
+				return nil, err
+			}
+			if list == nil || list.UserID != userID {
+				return nil, fmt.Errorf("%w: unknown todo list", ErrInvalidInput)
+			}
+			address.ListID = input.ListID
+		}
+	}
+	if input.Active != nil {
+		address.Active = *input.Active
+	}
+
+	if err := s.repo.UpdateAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// ListMessages returns the processing log of the user's inbound mail
+func (s *service) ListMessages(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]Message, int64, error) {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	if page < 0 {
+		page = 0
+	}
+	return s.repo.ListMessages(ctx, userID, page, pageSize)
+}
+
+// ListAttachments returns the attachments of one of the user's todos
+func (s *service) ListAttachments(ctx context.Context, todoID, userID uuid.UUID) ([]Attachment, error) {
+	todo, err := s.todoService.GetTodo(ctx, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, todos.ErrTodoNotFound
+	}
+	return s.repo.ListAttachments(ctx, todoID)
+}
+
+// GetAttachment returns an attachment with its content
+func (s *service) GetAttachment(ctx context.Context, id, userID uuid.UUID) (*Attachment, error) {
+	attachment, err := s.repo.FindAttachmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.UserID != userID {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+type queuedEmail struct {
+	Recipient string `json:"recipient,omitempty"`
+	Raw       []byte `json:"raw"`
+}
+
+// Enqueue hands a raw email to the ingestion worker
+func (s *service) Enqueue(ctx context.Context, raw []byte, recipient string) error {
+	if s.config.Domain == "" {
+		return ErrDisabled
+	}
+	if int64(len(raw)) > s.config.MaxMessageBytes {
+		return ErrMessageTooLarge
+	}
+	payload, err := json.Marshal(queuedEmail{Recipient: recipient, Raw: raw})
+	if err != nil {
+		return err
+	}
+	return s.redis.LPush(ctx, queueKey, payload).Err()
+}
+
+// Process turns a raw email into a todo, or records why it was rejected.
+// Mail that cannot be matched to an address is dropped without a record.
+func (s *service) Process(ctx context.Context, raw []byte, recipient string) (*Message, error) {
+	email, err := ParseEmail(raw, s.config.MaxAttachmentBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := s.findAddress(ctx, append([]string{recipient}, email.Recipients...))
+	if err != nil {
+		return nil, err
+	}
+
+	if email.MessageID != "" {
+		exists, err := s.repo.MessageExists(ctx, address.ID, email.MessageID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, nil
+		}
+	}
+
+	message := &Message{
+		AddressID: address.ID,
+		UserID:    address.UserID,
+		MessageID: truncate(email.MessageID, 998),
+		Sender:    truncate(email.From, 320),
+		Subject:   email.Subject,
+		Status:    MessageStatusRejected,
+	}
+
+	if reason, err := s.screen(ctx, address, email); err != nil {
+		return nil, err
+	} else if reason != "" {
+		message.Reason = reason
+		return message, s.repo.CreateMessage(ctx, message)
+	}
+
+	todo, err := s.createTodo(ctx, address, email)
+	if err != nil {
+		return nil, err
+	}
+	message.Status = MessageStatusAccepted
+	message.TodoID = &todo.ID
+	if err := s.repo.CreateMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	attachments := make([]*Attachment, 0, len(email.Attachments))
+	for _, a := range email.Attachments {
+		if len(attachments) == s.config.MaxAttachments {
+			email.Skipped++
+			continue
+		}
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		attachments = append(attachments, &Attachment{
+			TodoID:      todo.ID,
+			UserID:      address.UserID,
+			MessageID:   message.ID,
+			Filename:    a.Filename,
+			ContentType: contentType,
+			Size:        int64(len(a.Content)),
+			Content:     a.Content,
+		})
+	}
+	if err := s.repo.CreateAttachments(ctx, message, attachments); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to store inbound email attachments",
+			zap.String("todo_id", todo.ID.String()),
+			zap.Error(err))
+	} else {
+		message.AttachmentCount = len(attachments)
+		var stored int64
+		for _, a := range attachments {
+			stored += a.Size
+		}
+		s.usage.Record(metering.Event{
+			UserID:   address.UserID,
+			Meter:    metering.MeterStorageBytes,
+			Quantity: stored,
+		})
+	}
+	if email.Skipped > 0 {
+		tracing.Logger(ctx, s.logger).Info("Skipped inbound email attachments over the limits",
+			zap.String("todo_id", todo.ID.String()),
+			zap.Int("skipped", email.Skipped))
+	}
+	return message, nil
+}
+
+// findAddress resolves the first recipient that is an inbound address on the configured domain
+func (s *service) findAddress(ctx context.Context, recipients []string) (*Address, error) {
+	domain := "@" + strings.ToLower(s.config.Domain)
+	for _, r := range recipients {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if parsed, err := mail.ParseAddress(r); err == nil {
+			r = parsed.Address
+		}
+		if !strings.HasSuffix(r, domain) {
+			continue
+		}
+		token := strings.TrimSuffix(r, domain)
+		// Allow plus addressing, e.g. abc+groceries@domain
+		if i := strings.IndexByte(token, '+'); i >= 0 {
+			token = token[:i]
+		}
+		address, err := s.repo.FindAddressByToken(ctx, token)
+		if err == ErrAddressNotFound {
+			continue
+		}
+		return address, err
+	}
+	return nil, ErrAddressNotFound
+}
+
+// screen applies spam protection and returns the reason to reject the email, if any
+func (s *service) screen(ctx context.Context, address *Address, email *Email) (string, error) {
+	if !address.Active {
+		return "address is disabled", nil
+	}
+	if email.Spam {
+		return "marked as spam", nil
+	}
+
+	allowed, err := s.senderAllowed(ctx, address, email.From)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return "sender is not on the allow-list", nil
+	}
+
+	if s.config.HourlyLimit > 0 {
+		key := fmt.Sprintf("inbound:rate:%s:%d", address.ID, time.Now().Unix()/3600)
+		count, err := s.redis.Incr(ctx, key).Result()
+		if err != nil {
+			return "", err
+		}
+		if count == 1 {
+			s.redis.Expire(ctx, key, time.Hour)
+		}
+		if count > s.config.HourlyLimit {
+			return "hourly limit reached", nil
+		}
+	}
+	return "", nil
+}
+
+// senderAllowed accepts the user's own email address and the configured allow-list.
+// Allow-list entries are either full addresses or "@domain".
+func (s *service) senderAllowed(ctx context.Context, address *Address, sender string) (bool, error) {
+	owner, err := s.userService.GetUser(ctx, address.UserID)
+	if err != nil {
+		return false, err
+	}
+	if owner != nil && strings.EqualFold(owner.Email, sender) {
+		return true, nil
+	}
+	for _, entry := range address.AllowedSenders {
+		if strings.HasPrefix(entry, "@") {
+			if strings.HasSuffix(sender, entry) {
+				return true, nil
+			}
+		} else if entry == sender {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *service) createTodo(ctx context.Context, address *Address, email *Email) (*todos.Todo, error) {
+	listID := uuid.Nil
+	if address.ListID != nil {
+		listID = *address.ListID
+	} else {
+		list, err := s.todoService.GetOrCreateDefaultList(ctx, address.UserID)
+		if err != nil {
+			return nil, err
+		}
+		listID = list.ID
+	}
+
+	title := email.Subject
+	if title == "" {
+		title = firstLine(email.Body)
+	}
+	if title == "" {
+		title = "Email from " + email.From
+	}
+
+	return s.todoService.CreateTodo(ctx, todos.CreateTodoInput{
+		Title:       truncate(title, 255),
+		Description: truncate(email.Body, s.config.MaxBodyLength),
+		UserID:      address.UserID,
+		ListID:      listID,
+	})
+}
+
+func normalizeSenders(senders []string) ([]string, error) {
+	normalized := make([]string, 0, len(senders))
+	seen := make(map[string]struct{}, len(senders))
+	for _, entry := range senders {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if strings.HasPrefix(entry, "@") {
+			if len(entry) < 4 || !strings.Contains(entry, ".") {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidSender, entry)
+			}
+		} else if parsed, err := mail.ParseAddress(entry); err != nil || parsed.Address != entry {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSender, entry)
+		}
+		if _, ok := seen[entry]; ok {
+			continue
+		}
+		seen[entry] = struct{}{}
+		normalized = append(normalized, entry)
+	}
+	return normalized, nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToLower(tokenEncoding.EncodeToString(b)), nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// truncate shortens s to at most max bytes without splitting a UTF-8 character
+func truncate(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Worker consumes queued emails and turns them into todos
+type Worker struct {
+	service Service
+	redis   *redis.Client
+	logger  *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorker creates a new mail-ingestion worker
+func NewWorker(service Service, redisClient *cache.RedisClient, logger *zap.Logger) *Worker {
+	return &Worker{
+		service: service,
+		redis:   redisClient.GetClient(),
+		logger:  logger,
+	}
+}
+
+// Start begins processing queued emails in the background
+func (w *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			result, err := w.redis.BRPop(ctx, 5*time.Second, queueKey).Result()
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				w.logger.Error("Failed to read inbound email queue", zap.Error(err))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+				}
+				continue
+			}
+			// BRPop returns the key followed by the value
+			w.process(ctx, result[1])
+		}
+	}()
+}
+
+// Stop waits for the email being processed and stops the worker
+func (w *Worker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+func (w *Worker) process(ctx context.Context, payload string) {
+	var queued queuedEmail
+	if err := json.Unmarshal([]byte(payload), &queued); err != nil {
+		w.logger.Error("Dropping malformed inbound email payload", zap.Error(err))
+		return
+	}
+
+	message, err := w.service.Process(ctx, queued.Raw, queued.Recipient)
+	switch {
+	case err != nil:
+		w.logger.Warn("Failed to process inbound email",
+			zap.String("recipient", queued.Recipient),
+			zap.Error(err))
+	case message == nil:
+		w.logger.Debug("Skipped duplicate inbound email", zap.String("recipient", queued.Recipient))
+	case message.Status == MessageStatusRejected:
+		w.logger.Info("Rejected inbound email",
+			zap.String("user_id", message.UserID.String()),
+			zap.String("reason", message.Reason))
+	}
+}
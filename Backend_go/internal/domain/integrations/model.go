@@ -0,0 +1,115 @@
+package integrations
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// State summarizes whether an integration is working
+type State string
+
+const (
+	// StateConnected means the last call to the integration succeeded
+	StateConnected State = "connected"
+	// StateDegraded means recent calls failed but the integration may recover on its own
+	StateDegraded State = "degraded"
+	// StateDisconnected means calls keep failing and the integration needs reconnecting
+	StateDisconnected State = "disconnected"
+)
+
+const (
+	// disconnectAfter is the number of failures in a row after which an integration is disconnected
+	disconnectAfter = 3
+	// recentFailureLimit is how many errors are shown per integration
+	recentFailureLimit = 5
+	// failureRetention is how long errors are kept
+	failureRetention = 30 * 24 * time.Hour
+)
+
+var (
+	ErrIntegrationNotFound = errors.New("integration not found")
+	ErrReconnectFailed     = errors.New("integration could not be reached")
+)
+
+// Integration is a connection to an external service configured for an organization
+type Integration struct {
+	ID       uuid.UUID `json:"id"`
+	Provider string    `json:"provider"`
+	// Name identifies the integration to people, e.g. the Slack workspace or the repository
+	Name string `json:"name"`
+	// ProjectID is set for integrations that belong to a single project
+	ProjectID   *uuid.UUID `json:"project_id,omitempty"`
+	ConnectedAt time.Time  `json:"connected_at"`
+}
+
+// Ref identifies the integration a call was made through
+type Ref struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	Provider       string
+}
+
+// Health tracks the outcome of the latest calls made through an integration
+type Health struct {
+	IntegrationID       uuid.UUID  `json:"integration_id" gorm:"type:uuid;primary_key"`
+	OrganizationID      uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null;index:idx_integration_health_org"`
+	Provider            string     `json:"provider" gorm:"type:varchar(20);not null"`
+	LastSyncAt          *time.Time `json:"last_sync_at,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty" gorm:"type:text"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"not null;default:0"`
+	UpdatedAt           time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Health model
+func (Health) TableName() string {
+	return "integration_health"
+}
+
+// Failure is an error returned by a call made through an integration
+type Failure struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	IntegrationID  uuid.UUID `json:"-" gorm:"type:uuid;not null;index:idx_integration_failure_time,priority:1"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	Message        string    `json:"message" gorm:"type:text;not null"`
+	OccurredAt     time.Time `json:"occurred_at" gorm:"not null;index:idx_integration_failure_time,priority:2"`
+}
+
+// TableName specifies the table name for the Failure model
+func (Failure) TableName() string {
+	return "integration_failures"
+}
+
+// BeforeCreate is called before creating a new failure record
+func (f *Failure) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// Status reports the connection state of an integration
+type Status struct {
+	Integration
+	State        State      `json:"state"`
+	LastSyncAt   *time.Time `json:"last_sync_at,omitempty"`
+	LastErrorAt  *time.Time `json:"last_error_at,omitempty"`
+	RecentErrors []Failure  `json:"recent_errors"`
+}
+
+// ReconnectInput holds what a reconnect may replace
+type ReconnectInput struct {
+	// AccessToken replaces the token of integrations authorized with one
+	AccessToken string
+}
+
+// ReconnectResult tells the caller how a reconnect went
+type ReconnectResult struct {
+	// RedirectURL is the page where the user authorizes the integration again. It is set
+	// for OAuth integrations, which are reconnected once the user completes it.
+	RedirectURL string  `json:"redirect_url,omitempty"`
+	Status      *Status `json:"status,omitempty"`
+}
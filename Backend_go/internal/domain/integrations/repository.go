@@ -0,0 +1,102 @@
+package integrations
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for integration health data access
+type Repository interface {
+	// RecordSuccess marks the integration synced at the given time
+	RecordSuccess(ctx context.Context, ref Ref, at time.Time) error
+	// RecordFailure stores an error and counts it against the integration
+	RecordFailure(ctx context.Context, ref Ref, message string, at time.Time) error
+	ListHealth(ctx context.Context, orgID uuid.UUID) ([]Health, error)
+	// ListFailures returns the errors of an organization's integrations since a time, newest first
+	ListFailures(ctx context.Context, orgID uuid.UUID, since time.Time) ([]Failure, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new integration health repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) RecordSuccess(ctx context.Context, ref Ref, at time.Time) error {
+	health := &Health{
+		IntegrationID:  ref.ID,
+		OrganizationID: ref.OrganizationID,
+		Provider:       ref.Provider,
+		LastSyncAt:     &at,
+		UpdatedAt:      at,
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "integration_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_sync_at":         at,
+			"consecutive_failures": 0,
+			"updated_at":           at,
+		}),
+	}).Create(health).Error
+}
+
+func (r *repository) RecordFailure(ctx context.Context, ref Ref, message string, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		health := &Health{
+			IntegrationID:       ref.ID,
+			OrganizationID:      ref.OrganizationID,
+			Provider:            ref.Provider,
+			LastErrorAt:         &at,
+			LastError:           message,
+			ConsecutiveFailures: 1,
+			UpdatedAt:           at,
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "integration_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"last_error_at":        at,
+				"last_error":           message,
+				"consecutive_failures": gorm.Expr("integration_health.consecutive_failures + 1"),
+				"updated_at":           at,
+			}),
+		}).Create(health).Error
+		if err != nil {
+			return err
+		}
+
+		failure := &Failure{
+			IntegrationID:  ref.ID,
+			OrganizationID: ref.OrganizationID,
+			Message:        message,
+			OccurredAt:     at,
+		}
+		if err := tx.Create(failure).Error; err != nil {
+			return err
+		}
+		return tx.Where("integration_id = ? AND occurred_at < ?", ref.ID, at.Add(-failureRetention)).
+			Delete(&Failure{}).Error
+	})
+}
+
+func (r *repository) ListHealth(ctx context.Context, orgID uuid.UUID) ([]Health, error) {
+	var health []Health
+	err := r.db.WithContext(ctx).Where("organization_id = ?", orgID).Find(&health).Error
+	return health, err
+}
+
+func (r *repository) ListFailures(ctx context.Context, orgID uuid.UUID, since time.Time) ([]Failure, error) {
+	var failures []Failure
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND occurred_at >= ?", orgID, since).
+		Order("occurred_at DESC").
+		Find(&failures).Error
+	return failures, err
+}
@@ -0,0 +1,152 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Source lists the integrations one subsystem has configured for an organization
+type Source interface {
+	List(ctx context.Context, orgID uuid.UUID) ([]Integration, error)
+	// Reconnect authorizes an integration again, returning a URL when the user must finish
+	// it with the external service. It returns ErrIntegrationNotFound for integrations
+	// that are not its own.
+	Reconnect(ctx context.Context, orgID, userID, id uuid.UUID, input ReconnectInput) (string, error)
+}
+
+// Recorder is told the outcome of calls made through integrations
+type Recorder interface {
+	RecordSuccess(ctx context.Context, ref Ref)
+	RecordFailure(ctx context.Context, ref Ref, err error)
+}
+
+type recorder struct {
+	repo   Repository
+	logger *zap.Logger
+}
+
+// NewRecorder creates a recorder that stores outcomes in the repository. Recording
+// never fails the call it describes; errors are only logged.
+func NewRecorder(repo Repository, logger *zap.Logger) Recorder {
+	return &recorder{repo: repo, logger: logger}
+}
+
+func (r *recorder) RecordSuccess(ctx context.Context, ref Ref) {
+	if err := r.repo.RecordSuccess(ctx, ref, time.Now().UTC()); err != nil {
+		r.logger.Error("Failed to record integration sync", zap.String("integration_id", ref.ID.String()), zap.Error(err))
+	}
+}
+
+func (r *recorder) RecordFailure(ctx context.Context, ref Ref, callErr error) {
+	if err := r.repo.RecordFailure(ctx, ref, callErr.Error(), time.Now().UTC()); err != nil {
+		r.logger.Error("Failed to record integration error", zap.String("integration_id", ref.ID.String()), zap.Error(err))
+	}
+}
+
+// Service reports the state of an organization's integrations
+type Service interface {
+	List(ctx context.Context, orgID uuid.UUID) ([]Status, error)
+	Reconnect(ctx context.Context, orgID, userID, id uuid.UUID, input ReconnectInput) (*ReconnectResult, error)
+}
+
+type service struct {
+	repo    Repository
+	sources []Source
+}
+
+// NewService creates a new integration status service over the given sources
+func NewService(repo Repository, sources ...Source) Service {
+	return &service{repo: repo, sources: sources}
+}
+
+func (s *service) List(ctx context.Context, orgID uuid.UUID) ([]Status, error) {
+	var configured []Integration
+	for _, source := range s.sources {
+		items, err := source.List(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		configured = append(configured, items...)
+	}
+	if len(configured) == 0 {
+		return []Status{}, nil
+	}
+
+	health, err := s.repo.ListHealth(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]Health, len(health))
+	for _, h := range health {
+		byID[h.IntegrationID] = h
+	}
+
+	failures, err := s.repo.ListFailures(ctx, orgID, time.Now().UTC().Add(-failureRetention))
+	if err != nil {
+		return nil, err
+	}
+	recent := make(map[uuid.UUID][]Failure)
+	for _, f := range failures {
+		if len(recent[f.IntegrationID]) < recentFailureLimit {
+			recent[f.IntegrationID] = append(recent[f.IntegrationID], f)
+		}
+	}
+
+	statuses := make([]Status, 0, len(configured))
+	for _, integration := range configured {
+		statuses = append(statuses, buildStatus(integration, byID[integration.ID], recent[integration.ID]))
+	}
+	return statuses, nil
+}
+
+// Reconnect asks each source in turn until one owns the integration
+func (s *service) Reconnect(ctx context.Context, orgID, userID, id uuid.UUID, input ReconnectInput) (*ReconnectResult, error) {
+	for _, source := range s.sources {
+		redirectURL, err := source.Reconnect(ctx, orgID, userID, id, input)
+		if errors.Is(err, ErrIntegrationNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if redirectURL != "" {
+			return &ReconnectResult{RedirectURL: redirectURL}, nil
+		}
+
+		statuses, err := s.List(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range statuses {
+			if statuses[i].ID == id {
+				return &ReconnectResult{Status: &statuses[i]}, nil
+			}
+		}
+		return &ReconnectResult{}, nil
+	}
+	return nil, ErrIntegrationNotFound
+}
+
+func buildStatus(integration Integration, health Health, failures []Failure) Status {
+	status := Status{
+		Integration:  integration,
+		State:        StateConnected,
+		LastSyncAt:   health.LastSyncAt,
+		LastErrorAt:  health.LastErrorAt,
+		RecentErrors: failures,
+	}
+	if status.RecentErrors == nil {
+		status.RecentErrors = []Failure{}
+	}
+	switch {
+	case health.ConsecutiveFailures >= disconnectAfter:
+		status.State = StateDisconnected
+	case health.ConsecutiveFailures > 0:
+		status.State = StateDegraded
+	}
+	return status
+}
@@ -0,0 +1,85 @@
+package legal
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DocumentType identifies a legal document users must accept
+type DocumentType string
+
+const (
+	DocumentTerms   DocumentType = "terms"
+	DocumentPrivacy DocumentType = "privacy"
+)
+
+// IsValid checks if the document type is known
+func (t DocumentType) IsValid() bool {
+	return t == DocumentTerms || t == DocumentPrivacy
+}
+
+var (
+	ErrUnknownDocument = errors.New("unknown legal document")
+	ErrVersionMismatch = errors.New("document version is not the current version")
+	ErrInvalidInput    = errors.New("invalid input")
+)
+
+// Document is the current version of a legal document
+type Document struct {
+	Type          DocumentType `json:"type"`
+	Version       string       `json:"version"`
+	URL           string       `json:"url"`
+	EffectiveDate *time.Time   `json:"effective_date,omitempty"`
+}
+
+// Consent records that a user accepted a version of a legal document
+type Consent struct {
+	ID           uuid.UUID    `json:"id" gorm:"type:uuid;primary_key"`
+	UserID       uuid.UUID    `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_consent_user_document,priority:1"`
+	DocumentType DocumentType `json:"document_type" gorm:"type:varchar(30);not null;uniqueIndex:idx_consent_user_document,priority:2"`
+	Version      string       `json:"version" gorm:"type:varchar(50);not null;uniqueIndex:idx_consent_user_document,priority:3"`
+	AcceptedAt   time.Time    `json:"accepted_at" gorm:"not null"`
+	IPAddress    string       `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
+	UserAgent    string       `json:"user_agent,omitempty" gorm:"type:text"`
+}
+
+// TableName specifies the table name for the Consent model
+func (Consent) TableName() string {
+	return "consents"
+}
+
+// BeforeCreate is called before creating a new consent record
+func (c *Consent) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	if c.AcceptedAt.IsZero() {
+		c.AcceptedAt = time.Now()
+	}
+	return nil
+}
+
+// Acceptance is a document version a user agrees to
+type Acceptance struct {
+	Type    DocumentType
+	Version string
+}
+
+// AcceptInput records the request a user accepted documents from
+type AcceptInput struct {
+	Acceptances []Acceptance
+	IPAddress   string
+	UserAgent   string
+}
+
+// ConsentStatus is a user's standing for one current document
+type ConsentStatus struct {
+	Document        Document   `json:"document"`
+	AcceptedVersion string     `json:"accepted_version,omitempty"`
+	AcceptedAt      *time.Time `json:"accepted_at,omitempty"`
+	// Required is true when the user has not accepted the current version
+	Required bool `json:"required"`
+}
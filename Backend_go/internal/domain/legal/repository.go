@@ -0,0 +1,60 @@
+package legal
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for consent data access
+type Repository interface {
+	// Create stores consents, ignoring versions the user accepted before
+	Create(ctx context.Context, consents []Consent) error
+	// ListLatest returns the most recent consent per document type
+	ListLatest(ctx context.Context, userID uuid.UUID) ([]Consent, error)
+	// CountAccepted counts how many of the given document versions the user accepted
+	CountAccepted(ctx context.Context, userID uuid.UUID, documents []Document) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new consent repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Create(ctx context.Context, consents []Consent) error {
+	if len(consents) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&consents).Error
+}
+
+func (r *repository) ListLatest(ctx context.Context, userID uuid.UUID) ([]Consent, error) {
+	var consents []Consent
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (document_type) * FROM consents
+			WHERE user_id = ? ORDER BY document_type, accepted_at DESC`, userID).
+		Scan(&consents).Error
+	return consents, err
+}
+
+func (r *repository) CountAccepted(ctx context.Context, userID uuid.UUID, documents []Document) (int64, error) {
+	if len(documents) == 0 {
+		return 0, nil
+	}
+	query := r.db.WithContext(ctx).Model(&Consent{}).Where("user_id = ?", userID)
+	match := r.db.Where("document_type = ? AND version = ?", documents[0].Type, documents[0].Version)
+	for _, d := range documents[1:] {
+		match = match.Or("document_type = ? AND version = ?", d.Type, d.Version)
+	}
+
+	var count int64
+	err := query.Where(match).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,143 @@
+package legal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service tracks which versions of the terms of service and privacy policy users accepted.
+// Current versions come from configuration, so publishing a new version means bumping it
+// there; users are then asked to accept again.
+type Service interface {
+	// Documents returns the current documents
+	Documents() []Document
+	// Status returns the user's standing for each current document
+	Status(ctx context.Context, userID uuid.UUID) ([]ConsentStatus, error)
+	// Accept records that the user accepted current document versions
+	Accept(ctx context.Context, userID uuid.UUID, input AcceptInput) ([]ConsentStatus, error)
+	// Pending returns the current documents the user has not accepted
+	Pending(ctx context.Context, userID uuid.UUID) ([]Document, error)
+}
+
+type service struct {
+	repo      Repository
+	documents []Document
+
+	// upToDate remembers users who accepted every current document. Versions only
+	// change with configuration, so an entry stays valid for the life of the process.
+	upToDate sync.Map
+}
+
+// NewService creates a new consent service. Documents without a version are not tracked.
+func NewService(repo Repository, documents []Document) Service {
+	current := make([]Document, 0, len(documents))
+	for _, d := range documents {
+		if d.Version != "" {
+			current = append(current, d)
+		}
+	}
+	return &service{repo: repo, documents: current}
+}
+
+func (s *service) Documents() []Document {
+	return s.documents
+}
+
+func (s *service) Status(ctx context.Context, userID uuid.UUID) ([]ConsentStatus, error) {
+	latest, err := s.repo.ListLatest(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[DocumentType]Consent, len(latest))
+	for _, c := range latest {
+		byType[c.DocumentType] = c
+	}
+
+	statuses := make([]ConsentStatus, 0, len(s.documents))
+	for _, d := range s.documents {
+		status := ConsentStatus{Document: d, Required: true}
+		if c, ok := byType[d.Type]; ok {
+			acceptedAt := c.AcceptedAt
+			status.AcceptedVersion = c.Version
+			status.AcceptedAt = &acceptedAt
+			status.Required = c.Version != d.Version
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (s *service) Accept(ctx context.Context, userID uuid.UUID, input AcceptInput) ([]ConsentStatus, error) {
+	if len(input.Acceptances) == 0 {
+		return nil, ErrInvalidInput
+	}
+
+	now := time.Now()
+	consents := make([]Consent, 0, len(input.Acceptances))
+	for _, a := range input.Acceptances {
+		current, ok := s.document(a.Type)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownDocument, a.Type)
+		}
+		// The client sends the version it showed, so users never accept text they did not see
+		if a.Version != current.Version {
+			return nil, fmt.Errorf("%w: %s is at version %s", ErrVersionMismatch, a.Type, current.Version)
+		}
+		consents = append(consents, Consent{
+			UserID:       userID,
+			DocumentType: a.Type,
+			Version:      a.Version,
+			AcceptedAt:   now,
+			IPAddress:    input.IPAddress,
+			UserAgent:    input.UserAgent,
+		})
+	}
+
+	if err := s.repo.Create(ctx, consents); err != nil {
+		return nil, err
+	}
+	return s.Status(ctx, userID)
+}
+
+func (s *service) Pending(ctx context.Context, userID uuid.UUID) ([]Document, error) {
+	if len(s.documents) == 0 {
+		return nil, nil
+	}
+	if _, ok := s.upToDate.Load(userID); ok {
+		return nil, nil
+	}
+
+	accepted, err := s.repo.CountAccepted(ctx, userID, s.documents)
+	if err != nil {
+		return nil, err
+	}
+	if accepted == int64(len(s.documents)) {
+		s.upToDate.Store(userID, struct{}{})
+		return nil, nil
+	}
+
+	statuses, err := s.Status(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Document
+	for _, status := range statuses {
+		if status.Required {
+			pending = append(pending, status.Document)
+		}
+	}
+	return pending, nil
+}
+
+func (s *service) document(t DocumentType) (Document, bool) {
+	for _, d := range s.documents {
+		if d.Type == t {
+			return d, true
+		}
+	}
+	return Document{}, false
+}
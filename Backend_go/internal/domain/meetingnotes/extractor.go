@@ -0,0 +1,112 @@
+package meetingnotes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/providers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Extractor finds the action items in meeting notes, returning one title per item
+type Extractor interface {
+	Extract(ctx context.Context, orgID uuid.UUID, content string) ([]string, error)
+}
+
+var (
+	// checklistPattern matches unchecked Markdown checklist lines such as "- [ ] Send the deck"
+	checklistPattern = regexp.MustCompile(`^(?:[-*+]\s*)?\[\s?\]\s+(.+)$`)
+	// actionPattern matches lines labelled as action items such as "TODO: Send the deck"
+	actionPattern = regexp.MustCompile(`(?i)^(?:[-*+]\s*)?(?:todo|action(?:\s+item)?|ai)\s*:\s*(.+)$`)
+)
+
+// checklistExtractor picks checklist-style lines out of the notes
+type checklistExtractor struct{}
+
+// NewChecklistExtractor creates an extractor that takes unchecked checklist lines and lines
+// starting with TODO:, Action: or AI: as action items
+func NewChecklistExtractor() Extractor {
+	return checklistExtractor{}
+}
+
+func (checklistExtractor) Extract(ctx context.Context, orgID uuid.UUID, content string) ([]string, error) {
+	var items []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		for _, pattern := range []*regexp.Regexp{checklistPattern, actionPattern} {
+			if match := pattern.FindStringSubmatch(line); match != nil {
+				items = append(items, match[1])
+				break
+			}
+		}
+	}
+	return items, nil
+}
+
+const extractionPrompt = `Extract the action items from these meeting notes. Reply with only a JSON array of strings, one short imperative title per action item, in the order they appear. Reply with [] if there are none.
+
+Notes:
+%s`
+
+// llmExtractor asks the organization's LLM provider for the action items
+type llmExtractor struct {
+	providers *providers.Resolver
+	fallback  Extractor
+	logger    *zap.Logger
+}
+
+// NewLLMExtractor creates an extractor backed by the organization's LLM provider. Checklist
+// lines are always found by fallback, which also takes over when the provider fails.
+func NewLLMExtractor(resolver *providers.Resolver, fallback Extractor, logger *zap.Logger) Extractor {
+	return &llmExtractor{providers: resolver, fallback: fallback, logger: logger}
+}
+
+func (e *llmExtractor) Extract(ctx context.Context, orgID uuid.UUID, content string) ([]string, error) {
+	checklist, err := e.fallback.Extract(ctx, orgID, content)
+	if err != nil {
+		return nil, err
+	}
+	items, err := e.complete(ctx, orgID, content)
+	if err != nil {
+		e.logger.Warn("Falling back to checklist extraction of meeting notes", zap.Error(err))
+		return checklist, nil
+	}
+	return append(checklist, items...), nil
+}
+
+func (e *llmExtractor) complete(ctx context.Context, orgID uuid.UUID, content string) ([]string, error) {
+	provider, err := e.providers.For(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	temperature := 0.0
+	completion, err := provider.Complete(ctx, providers.CompletionRequest{
+		Messages:    []providers.Message{{Role: "user", Content: fmt.Sprintf(extractionPrompt, content)}},
+		MaxTokens:   1024,
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	if err := json.Unmarshal([]byte(jsonBody(completion.Text)), &items); err != nil {
+		return nil, fmt.Errorf("action item reply is not a JSON array of strings: %w", err)
+	}
+	return items, nil
+}
+
+// jsonBody strips the Markdown code fence models often wrap JSON replies in
+func jsonBody(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimPrefix(text, "json")
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
@@ -0,0 +1,84 @@
+package meetingnotes
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Target is what action items are turned into
+type Target string
+
+const (
+	TargetTodo Target = "todo"
+	TargetTask Target = "task"
+)
+
+const (
+	// MaxNoteLength bounds the content of a note
+	MaxNoteLength = 50000
+	// MaxActionItems bounds how many items one extraction creates
+	MaxActionItems = 50
+	// maxItemTitle is the longest title an action item gets, matching the todo title column
+	maxItemTitle = 255
+)
+
+var (
+	ErrEventNotFound     = errors.New("event not found")
+	ErrNoteNotFound      = errors.New("meeting notes not found")
+	ErrInvalidNote       = errors.New("meeting notes must be between 1 and 50000 characters")
+	ErrInvalidOccurrence = errors.New("occurrence_time must be the start of an occurrence of the event")
+	ErrEventForbidden    = errors.New("only the organizer and invitees of an event can use its notes")
+	ErrInvalidTarget     = errors.New("target must be todo, or task with a project_id")
+	ErrProjectForbidden  = errors.New("creating tasks in the project is not allowed")
+	ErrNoActionItems     = errors.New("no action items found in the meeting notes")
+)
+
+// Note holds the notes taken during one occurrence of a calendar event. Events that do not
+// recur have a single occurrence at their start time.
+type Note struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	EventID        uuid.UUID `json:"event_id" gorm:"type:uuid;not null;uniqueIndex:idx_meeting_note_occurrence,priority:1"`
+	OccurrenceTime time.Time `json:"occurrence_time" gorm:"not null;uniqueIndex:idx_meeting_note_occurrence,priority:2"`
+	Content        string    `json:"content" gorm:"type:text;not null"`
+	UpdatedBy      uuid.UUID `json:"updated_by" gorm:"type:uuid;not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Note model
+func (Note) TableName() string {
+	return "meeting_notes"
+}
+
+// ActionItem links a line of meeting notes to the task or todo created from it. An item is
+// only created once per note, so notes can be extracted again after they are edited.
+type ActionItem struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	NoteID    uuid.UUID `json:"note_id" gorm:"type:uuid;not null;uniqueIndex:idx_meeting_action_item_text,priority:1"`
+	EventID   uuid.UUID `json:"event_id" gorm:"type:uuid;not null;index"`
+	Text      string    `json:"text" gorm:"type:varchar(255);not null;uniqueIndex:idx_meeting_action_item_text,priority:2"`
+	Target    Target    `json:"target" gorm:"type:varchar(10);not null"`
+	ItemID    uuid.UUID `json:"item_id" gorm:"type:uuid;not null"`
+	CreatedBy uuid.UUID `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the ActionItem model
+func (ActionItem) TableName() string {
+	return "meeting_action_items"
+}
+
+// ExtractInput chooses what the action items of a note become. Tasks are created in
+// ProjectID and assigned to the caller; todos go to the caller's default list.
+type ExtractInput struct {
+	Target    Target
+	ProjectID *uuid.UUID
+}
+
+// Extraction reports the action items created from a note and those it already had
+type Extraction struct {
+	Created  []ActionItem `json:"created"`
+	Existing []ActionItem `json:"existing"`
+}
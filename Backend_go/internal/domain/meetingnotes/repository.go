@@ -0,0 +1,76 @@
+package meetingnotes
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for meeting note data access
+type Repository interface {
+	FindNote(ctx context.Context, eventID uuid.UUID, occurrenceTime time.Time) (*Note, error)
+	// SaveNote creates the note of an occurrence or replaces its content
+	SaveNote(ctx context.Context, note *Note) error
+	DeleteNote(ctx context.Context, id uuid.UUID) error
+	ListActionItems(ctx context.Context, noteID uuid.UUID) ([]ActionItem, error)
+	CreateActionItem(ctx context.Context, item *ActionItem) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new meeting note repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) FindNote(ctx context.Context, eventID uuid.UUID, occurrenceTime time.Time) (*Note, error) {
+	var note Note
+	err := r.db.WithContext(ctx).Where("event_id = ? AND occurrence_time = ?", eventID, occurrenceTime).First(&note).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoteNotFound
+		}
+		return nil, err
+	}
+	return &note, nil
+}
+
+func (r *repository) SaveNote(ctx context.Context, note *Note) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "occurrence_time"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content", "updated_by", "updated_at"}),
+	}).Create(note).Error
+}
+
+func (r *repository) DeleteNote(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("note_id = ?", id).Delete(&ActionItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Note{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNoteNotFound
+		}
+		return nil
+	})
+}
+
+func (r *repository) ListActionItems(ctx context.Context, noteID uuid.UUID) ([]ActionItem, error) {
+	var items []ActionItem
+	err := r.db.WithContext(ctx).Where("note_id = ?", noteID).Order("created_at").Find(&items).Error
+	return items, err
+}
+
+func (r *repository) CreateActionItem(ctx context.Context, item *ActionItem) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
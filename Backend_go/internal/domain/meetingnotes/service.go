@@ -0,0 +1,282 @@
+package meetingnotes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Service defines the interface for meeting note operations. A zero occurrence time
+// means the start of an event that does not recur.
+type Service interface {
+	GetNote(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time) (*Note, error)
+	SaveNote(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time, content string) (*Note, error)
+	DeleteNote(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time) error
+	ListActionItems(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time) ([]ActionItem, error)
+	// ExtractActionItems turns the action items of a note into todos or tasks linked back
+	// to the event. Items extracted before are skipped.
+	ExtractActionItems(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time, input ExtractInput) (*Extraction, error)
+}
+
+type service struct {
+	repo                Repository
+	calendarService     calendar.Service
+	taskService         task.Service
+	todoService         todos.Service
+	projectService      project.Service
+	organizationService organization.Service
+	extractor           Extractor
+	logger              *zap.Logger
+}
+
+// NewService creates a new meeting note service
+func NewService(repo Repository, calendarService calendar.Service, taskService task.Service, todoService todos.Service,
+	projectService project.Service, organizationService organization.Service, extractor Extractor, logger *zap.Logger) Service {
+	return &service{
+		repo:                repo,
+		calendarService:     calendarService,
+		taskService:         taskService,
+		todoService:         todoService,
+		projectService:      projectService,
+		organizationService: organizationService,
+		extractor:           extractor,
+		logger:              logger,
+	}
+}
+
+// occurrence checks that the user takes part in the event and resolves the occurrence
+// the notes belong to
+func (s *service) occurrence(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time) (*calendar.CalendarEvent, time.Time, error) {
+	event, err := s.calendarService.GetEventByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, time.Time{}, ErrEventNotFound
+		}
+		return nil, time.Time{}, err
+	}
+	if event.UserID != userID {
+		collaborator, err := s.calendarService.GetCollaborator(ctx, eventID, userID)
+		if err != nil || collaborator.Status == "declined" {
+			return nil, time.Time{}, ErrEventForbidden
+		}
+	}
+
+	if len(event.RecurrenceRules) == 0 {
+		if !occurrenceTime.IsZero() && !occurrenceTime.Equal(event.StartTime) {
+			return nil, time.Time{}, ErrInvalidOccurrence
+		}
+		return event, event.StartTime.UTC(), nil
+	}
+	if occurrenceTime.IsZero() {
+		return nil, time.Time{}, ErrInvalidOccurrence
+	}
+	occurrences, err := s.calendarService.ListOccurrences(ctx, eventID, occurrenceTime, occurrenceTime)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for _, occ := range occurrences {
+		if occ.OccurrenceTime.Equal(occurrenceTime) {
+			return event, occurrenceTime.UTC(), nil
+		}
+	}
+	return nil, time.Time{}, ErrInvalidOccurrence
+}
+
+func (s *service) GetNote(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time) (*Note, error) {
+	_, occurrenceTime, err := s.occurrence(ctx, userID, eventID, occurrenceTime)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindNote(ctx, eventID, occurrenceTime)
+}
+
+func (s *service) SaveNote(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time, content string) (*Note, error) {
+	if strings.TrimSpace(content) == "" || len(content) > MaxNoteLength {
+		return nil, ErrInvalidNote
+	}
+	_, occurrenceTime, err := s.occurrence(ctx, userID, eventID, occurrenceTime)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &Note{
+		EventID:        eventID,
+		OccurrenceTime: occurrenceTime,
+		Content:        content,
+		UpdatedBy:      userID,
+		UpdatedAt:      time.Now(),
+	}
+	if err := s.repo.SaveNote(ctx, note); err != nil {
+		return nil, err
+	}
+	return s.repo.FindNote(ctx, eventID, occurrenceTime)
+}
+
+func (s *service) DeleteNote(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time) error {
+	note, err := s.GetNote(ctx, userID, eventID, occurrenceTime)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteNote(ctx, note.ID)
+}
+
+func (s *service) ListActionItems(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time) ([]ActionItem, error) {
+	note, err := s.GetNote(ctx, userID, eventID, occurrenceTime)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListActionItems(ctx, note.ID)
+}
+
+func (s *service) ExtractActionItems(ctx context.Context, userID, eventID uuid.UUID, occurrenceTime time.Time, input ExtractInput) (*Extraction, error) {
+	if input.Target == "" {
+		input.Target = TargetTodo
+	}
+	if input.Target != TargetTodo && (input.Target != TargetTask || input.ProjectID == nil) {
+		return nil, ErrInvalidTarget
+	}
+
+	event, occurrenceTime, err := s.occurrence(ctx, userID, eventID, occurrenceTime)
+	if err != nil {
+		return nil, err
+	}
+	note, err := s.repo.FindNote(ctx, eventID, occurrenceTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tasks go to a project the user may create tasks in, whose organization's AI
+	// settings are used for the extraction
+	var proj *project.Project
+	orgID := uuid.Nil
+	if input.Target == TargetTask {
+		proj, err = s.projectService.GetProject(ctx, *input.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		membership, err := s.organizationService.ResolveMembership(ctx, proj.OrganizationID, userID)
+		if err != nil {
+			if errors.Is(err, organization.ErrNotMember) {
+				return nil, ErrProjectForbidden
+			}
+			return nil, err
+		}
+		if !membership.HasPermission("tasks:create") {
+			return nil, ErrProjectForbidden
+		}
+		orgID = proj.OrganizationID
+	}
+
+	titles, err := s.extractor.Extract(ctx, orgID, note.Content)
+	if err != nil {
+		return nil, err
+	}
+	titles = normalizeTitles(titles)
+	if len(titles) == 0 {
+		return nil, ErrNoActionItems
+	}
+
+	existing, err := s.repo.ListActionItems(ctx, note.ID)
+	if err != nil {
+		return nil, err
+	}
+	extracted := make(map[string]bool, len(existing))
+	for _, item := range existing {
+		extracted[strings.ToLower(item.Text)] = true
+	}
+
+	result := &Extraction{Created: []ActionItem{}, Existing: existing}
+	description := fmt.Sprintf("From the meeting notes of %s on %s", event.Title, occurrenceTime.Format("Jan 2, 2006"))
+	for _, title := range titles {
+		if extracted[strings.ToLower(title)] {
+			continue
+		}
+		if len(result.Created) >= MaxActionItems {
+			break
+		}
+		itemID, err := s.createItem(ctx, userID, event, proj, title, description)
+		if err != nil {
+			return result, err
+		}
+		item := &ActionItem{
+			NoteID:    note.ID,
+			EventID:   eventID,
+			Text:      title,
+			Target:    input.Target,
+			ItemID:    itemID,
+			CreatedBy: userID,
+		}
+		if err := s.repo.CreateActionItem(ctx, item); err != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to link action item to meeting notes", zap.String("note_id", note.ID.String()), zap.Error(err))
+			return result, err
+		}
+		extracted[strings.ToLower(title)] = true
+		result.Created = append(result.Created, *item)
+	}
+	return result, nil
+}
+
+// createItem creates the todo, or the task when a project is given, for an action item
+func (s *service) createItem(ctx context.Context, userID uuid.UUID, event *calendar.CalendarEvent, proj *project.Project, title, description string) (uuid.UUID, error) {
+	if proj != nil {
+		created, err := s.taskService.CreateTask(ctx, task.CreateTaskInput{
+			Title:          title,
+			Description:    description,
+			CreatorID:      userID,
+			AssigneeID:     &userID,
+			ProjectID:      proj.ID,
+			OrganizationID: proj.OrganizationID,
+			StartDate:      time.Now(),
+		})
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return created.ID, nil
+	}
+
+	list, err := s.todoService.GetOrCreateDefaultList(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	created, err := s.todoService.CreateTodo(ctx, todos.CreateTodoInput{
+		Title:                 title,
+		Description:           description,
+		UserID:                userID,
+		ListID:                list.ID,
+		LinkedCalendarEventID: &event.ID,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return created.ID, nil
+}
+
+// normalizeTitles trims the titles, shortens them to fit and drops blanks and duplicates
+func normalizeTitles(titles []string) []string {
+	seen := make(map[string]bool, len(titles))
+	normalized := make([]string, 0, len(titles))
+	for _, title := range titles {
+		title = strings.Join(strings.Fields(title), " ")
+		if runes := []rune(title); len(runes) > maxItemTitle {
+			title = string(runes[:maxItemTitle])
+		}
+		if title == "" || seen[strings.ToLower(title)] {
+			continue
+		}
+		seen[strings.ToLower(title)] = true
+		normalized = append(normalized, title)
+	}
+	return normalized
+}
@@ -0,0 +1,136 @@
+package memberimport
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRows bounds the number of rows in a single import
+	MaxRows = 5000
+	// MaxFileBytes bounds the size of an uploaded CSV file
+	MaxFileBytes = 2 << 20
+)
+
+var (
+	ErrImportNotFound = errors.New("member import not found")
+	ErrInvalidCSV     = errors.New("file is not a valid CSV with an email column")
+	ErrEmptyImport    = errors.New("the CSV does not contain any rows")
+	ErrTooManyRows    = fmt.Errorf("an import can contain at most %d rows", MaxRows)
+)
+
+// Status is where an import job stands
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	// StatusDryRun marks the report of a dry run, which is not saved
+	StatusDryRun Status = "dry_run"
+)
+
+// Outcome is what happened to a single row of an import
+type Outcome string
+
+const (
+	OutcomePending Outcome = "pending"
+	// OutcomeInvited means an invitation was emailed to the address
+	OutcomeInvited Outcome = "invited"
+	// OutcomeAdded means an existing account was added to the organization directly
+	OutcomeAdded   Outcome = "added"
+	OutcomeSkipped Outcome = "skipped"
+	OutcomeFailed  Outcome = "failed"
+)
+
+// RowResult is a row of the CSV and, once processed, its outcome
+type RowResult struct {
+	Line         int        `json:"line"`
+	Email        string     `json:"email"`
+	Role         string     `json:"role,omitempty"`
+	Outcome      Outcome    `json:"outcome"`
+	Reason       string     `json:"reason,omitempty"`
+	InvitationID *uuid.UUID `json:"invitation_id,omitempty"`
+}
+
+// RowResults stores the rows of an import in a JSONB column
+type RowResults []RowResult
+
+func (r *RowResults) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value: %v", value)
+	}
+	var result []RowResult
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return err
+	}
+	*r = result
+	return nil
+}
+
+func (r RowResults) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+// Import is a bulk import of members into an organization from a CSV file. Rows are
+// processed in the background and the import doubles as the per-row report.
+type Import struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null;index"`
+	CreatedBy      uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	Status         Status     `json:"status" gorm:"type:varchar(20);not null;index"`
+	AddExisting    bool       `json:"add_existing" gorm:"not null;default:false"`
+	TotalRows      int        `json:"total_rows" gorm:"not null;default:0"`
+	Invited        int        `json:"invited" gorm:"not null;default:0"`
+	Added          int        `json:"added" gorm:"not null;default:0"`
+	Skipped        int        `json:"skipped" gorm:"not null;default:0"`
+	Failed         int        `json:"failed" gorm:"not null;default:0"`
+	Rows           RowResults `json:"rows,omitempty" gorm:"type:jsonb;not null"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Import model
+func (Import) TableName() string {
+	return "organization_member_imports"
+}
+
+// Options controls how the rows of an import are applied
+type Options struct {
+	// AddExisting adds people who already have an account straight to the organization
+	// instead of inviting them
+	AddExisting bool
+	// DryRun checks every row right away and reports what the import would do, without
+	// inviting or adding anyone
+	DryRun bool
+}
+
+func (i *Import) record(row *RowResult, outcome Outcome, reason string) {
+	row.Outcome = outcome
+	row.Reason = reason
+	switch outcome {
+	case OutcomeInvited:
+		i.Invited++
+	case OutcomeAdded:
+		i.Added++
+	case OutcomeSkipped:
+		i.Skipped++
+	case OutcomeFailed:
+		i.Failed++
+	}
+}
@@ -0,0 +1,88 @@
+package memberimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ParseCSV reads the rows of an import. A header naming an "email" column and an
+// optional "role" column may come first; without one the first column is the email and
+// the second the role. Rows repeating an email are reported as skipped straight away.
+func ParseCSV(r io.Reader) (RowResults, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	emailCol, roleCol := 0, 1
+	var rows RowResults
+	seen := make(map[string]bool)
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidCSV
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first && isHeader(record) {
+			emailCol, roleCol = -1, -1
+			for i, name := range record {
+				switch strings.ToLower(strings.TrimSpace(name)) {
+				case "email", "e-mail", "email address":
+					emailCol = i
+				case "role":
+					roleCol = i
+				}
+			}
+			if emailCol < 0 {
+				return nil, ErrInvalidCSV
+			}
+			continue
+		}
+
+		row := RowResult{
+			Line:    line,
+			Email:   strings.ToLower(field(record, emailCol)),
+			Role:    strings.ToLower(field(record, roleCol)),
+			Outcome: OutcomePending,
+		}
+		if row.Email == "" && row.Role == "" {
+			continue
+		}
+		if seen[row.Email] {
+			row.Outcome = OutcomeSkipped
+			row.Reason = "duplicate of an earlier row"
+		}
+		seen[row.Email] = true
+
+		rows = append(rows, row)
+		if len(rows) > MaxRows {
+			return nil, ErrTooManyRows
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmptyImport
+	}
+	return rows, nil
+}
+
+func isHeader(record []string) bool {
+	for _, name := range record {
+		if strings.Contains(strings.ToLower(name), "email") && !strings.Contains(name, "@") {
+			return true
+		}
+	}
+	return false
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
@@ -0,0 +1,87 @@
+package memberimport
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for member import data access
+type Repository interface {
+	Create(ctx context.Context, imp *Import) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Import, error)
+	// ListByOrganization lists the imports of an organization, newest first, without their rows
+	ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]Import, error)
+	// Claim marks a queued import, or a running one that stopped making progress before
+	// staleBefore, as running. It reports false when another worker holds the import.
+	Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error)
+	// Runnable lists the imports that are waiting to be claimed, oldest first
+	Runnable(ctx context.Context, staleBefore time.Time) ([]uuid.UUID, error)
+	Save(ctx context.Context, imp *Import) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new member import repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Create(ctx context.Context, imp *Import) error {
+	return r.db.WithContext(ctx).Create(imp).Error
+}
+
+func (r *repository) FindByID(ctx context.Context, id uuid.UUID) (*Import, error) {
+	var imp Import
+	if err := r.db.WithContext(ctx).First(&imp, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportNotFound
+		}
+		return nil, err
+	}
+	return &imp, nil
+}
+
+func (r *repository) ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]Import, error) {
+	var imports []Import
+	err := r.db.WithContext(ctx).
+		Omit("rows").
+		Where("organization_id = ?", orgID).
+		Order("created_at DESC").
+		Find(&imports).Error
+	return imports, err
+}
+
+func (r *repository) runnable(query *gorm.DB, staleBefore time.Time) *gorm.DB {
+	return query.Where("status = ? OR (status = ? AND updated_at < ?)", StatusQueued, StatusRunning, staleBefore)
+}
+
+func (r *repository) Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error) {
+	now := time.Now()
+	result := r.runnable(r.db.WithContext(ctx).Model(&Import{}).Where("id = ?", id), staleBefore).
+		Updates(map[string]interface{}{
+			"status":     StatusRunning,
+			"started_at": gorm.Expr("COALESCE(started_at, ?)", now),
+			"updated_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *repository) Runnable(ctx context.Context, staleBefore time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.runnable(r.db.WithContext(ctx).Model(&Import{}), staleBefore).
+		Order("created_at ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) Save(ctx context.Context, imp *Import) error {
+	imp.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Save(imp).Error
+}
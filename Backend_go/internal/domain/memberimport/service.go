@@ -0,0 +1,247 @@
+package memberimport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// staleAfter is how long a running import may go without progress before another
+	// worker picks it up again, e.g. after the server running it crashed
+	staleAfter = 10 * time.Minute
+	// saveEvery is how many rows are processed between progress saves
+	saveEvery = 25
+)
+
+// Service defines the interface for bulk member imports
+type Service interface {
+	// StartImport parses the CSV and queues it for processing. A dry run is processed
+	// right away instead and returns the report of what the import would do.
+	StartImport(ctx context.Context, orgID, createdBy uuid.UUID, csv io.Reader, opts Options) (*Import, error)
+	GetImport(ctx context.Context, orgID, id uuid.UUID) (*Import, error)
+	ListImports(ctx context.Context, orgID uuid.UUID) ([]Import, error)
+	// Run processes the remaining rows of an import unless another worker holds it.
+	// Cancelling ctx stops it between rows and leaves the import queued.
+	Run(ctx context.Context, id uuid.UUID) error
+	// Runnable lists the imports waiting for a worker
+	Runnable(ctx context.Context) ([]uuid.UUID, error)
+	// Queued signals the ids of newly started imports
+	Queued() <-chan uuid.UUID
+}
+
+type service struct {
+	repo              Repository
+	orgService        organization.Service
+	invitationService organization.InvitationService
+	userService       user.Service
+	queued            chan uuid.UUID
+	logger            *zap.Logger
+}
+
+// NewService creates a new member import service
+func NewService(repo Repository, orgService organization.Service, invitationService organization.InvitationService, userService user.Service, logger *zap.Logger) Service {
+	return &service{
+		repo:              repo,
+		orgService:        orgService,
+		invitationService: invitationService,
+		userService:       userService,
+		queued:            make(chan uuid.UUID, 64),
+		logger:            logger,
+	}
+}
+
+func (s *service) StartImport(ctx context.Context, orgID, createdBy uuid.UUID, csv io.Reader, opts Options) (*Import, error) {
+	rows, err := ParseCSV(csv)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.orgService.GetOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	imp := &Import{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		CreatedBy:      createdBy,
+		Status:         StatusQueued,
+		AddExisting:    opts.AddExisting,
+		TotalRows:      len(rows),
+		Rows:           rows,
+		CreatedAt:      time.Now(),
+	}
+	// Duplicates are already reported by the parser
+	for _, row := range rows {
+		if row.Outcome == OutcomeSkipped {
+			imp.Skipped++
+		}
+	}
+	if opts.DryRun {
+		return s.dryRun(ctx, imp)
+	}
+	if err := s.repo.Create(ctx, imp); err != nil {
+		return nil, err
+	}
+
+	// The worker also polls for queued imports, so a full channel only delays the job
+	select {
+	case s.queued <- imp.ID:
+	default:
+	}
+	return imp, nil
+}
+
+func (s *service) GetImport(ctx context.Context, orgID, id uuid.UUID) (*Import, error) {
+	imp, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if imp.OrganizationID != orgID {
+		return nil, ErrImportNotFound
+	}
+	return imp, nil
+}
+
+func (s *service) ListImports(ctx context.Context, orgID uuid.UUID) ([]Import, error) {
+	return s.repo.ListByOrganization(ctx, orgID)
+}
+
+func (s *service) Runnable(ctx context.Context) ([]uuid.UUID, error) {
+	return s.repo.Runnable(ctx, time.Now().Add(-staleAfter))
+}
+
+func (s *service) Queued() <-chan uuid.UUID {
+	return s.queued
+}
+
+func (s *service) Run(ctx context.Context, id uuid.UUID) error {
+	claimed, err := s.repo.Claim(ctx, id, time.Now().Add(-staleAfter))
+	if err != nil || !claimed {
+		return err
+	}
+	imp, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	sinceSave := 0
+	for i := range imp.Rows {
+		row := &imp.Rows[i]
+		if row.Outcome != OutcomePending {
+			continue
+		}
+		if ctx.Err() != nil {
+			// Hand the import back so the next worker resumes where this one stopped
+			imp.Status = StatusQueued
+			return s.repo.Save(context.Background(), imp)
+		}
+
+		// A row that has started is finished even when the worker is stopping
+		s.processRow(context.WithoutCancel(ctx), imp, row, false)
+		if sinceSave++; sinceSave == saveEvery {
+			sinceSave = 0
+			if err := s.repo.Save(ctx, imp); err != nil {
+				tracing.Logger(ctx, s.logger).Warn("Failed to save member import progress",
+					zap.String("import_id", imp.ID.String()), zap.Error(err))
+			}
+		}
+	}
+
+	now := time.Now()
+	imp.Status = StatusCompleted
+	imp.CompletedAt = &now
+	return s.repo.Save(context.Background(), imp)
+}
+
+// dryRun checks every row of an import the way Run would process it and returns the
+// report. Nothing is saved, so the report has no ID.
+func (s *service) dryRun(ctx context.Context, imp *Import) (*Import, error) {
+	imp.ID = uuid.Nil
+	imp.Status = StatusDryRun
+	startedAt := time.Now()
+	imp.StartedAt = &startedAt
+	for i := range imp.Rows {
+		row := &imp.Rows[i]
+		if row.Outcome != OutcomePending {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s.processRow(ctx, imp, row, true)
+	}
+	completedAt := time.Now()
+	imp.CompletedAt = &completedAt
+	return imp, nil
+}
+
+// processRow invites the row's email, or adds the account directly when the import asks
+// for it. People who are already members or already invited are skipped. A dry run
+// records the outcome the row would have without inviting or adding anyone.
+func (s *service) processRow(ctx context.Context, imp *Import, row *RowResult, dryRun bool) {
+	role := row.Role
+	if role == "" {
+		role = organization.MemberRole
+	}
+
+	existing, err := s.userService.GetUserByEmail(ctx, row.Email)
+	if err != nil {
+		imp.record(row, OutcomeFailed, err.Error())
+		return
+	}
+	if existing != nil {
+		_, err := s.orgService.ResolveMembership(ctx, imp.OrganizationID, existing.ID)
+		switch {
+		case err == nil:
+			imp.record(row, OutcomeSkipped, "already a member of the organization")
+			return
+		case !errors.Is(err, organization.ErrNotMember):
+			imp.record(row, OutcomeFailed, err.Error())
+			return
+		}
+
+		if imp.AddExisting {
+			// A dry run adds the member in a transaction that is rolled back, so it fails
+			// the way the import would
+			if dryRun {
+				err = s.orgService.CheckAddMember(ctx, imp.OrganizationID, existing.ID, role)
+			} else {
+				_, err = s.orgService.AddMember(ctx, imp.OrganizationID, existing.ID, role)
+			}
+			if err != nil {
+				if errors.Is(err, organization.ErrMemberExists) {
+					imp.record(row, OutcomeSkipped, "already a member of the organization")
+					return
+				}
+				imp.record(row, OutcomeFailed, err.Error())
+				return
+			}
+			imp.record(row, OutcomeAdded, "")
+			return
+		}
+	}
+
+	invitation, err := s.invitationService.CreateInvitation(ctx, imp.OrganizationID, imp.CreatedBy, organization.InvitationInput{
+		Email:  row.Email,
+		Role:   role,
+		DryRun: dryRun,
+	})
+	switch {
+	case errors.Is(err, organization.ErrInvitationExists):
+		imp.record(row, OutcomeSkipped, "a pending invitation was already sent to this email")
+	case err != nil:
+		imp.record(row, OutcomeFailed, err.Error())
+	default:
+		if !dryRun {
+			row.InvitationID = &invitation.ID
+		}
+		imp.record(row, OutcomeInvited, "")
+	}
+}
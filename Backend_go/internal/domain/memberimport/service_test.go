@@ -0,0 +1,169 @@
+package memberimport
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeRepository panics through its nil embedded interface if a dry run saves anything
+type fakeRepository struct {
+	Repository
+}
+
+type fakeOrganizations struct {
+	organization.Service
+	members map[uuid.UUID]bool
+	// addErr is returned by both AddMember and CheckAddMember
+	addErr  error
+	added   []uuid.UUID
+	checked []uuid.UUID
+}
+
+func (f *fakeOrganizations) GetOrganization(ctx context.Context, id uuid.UUID) (*organization.Organization, error) {
+	return &organization.Organization{ID: id}, nil
+}
+
+func (f *fakeOrganizations) ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*organization.Membership, error) {
+	if !f.members[userID] {
+		return nil, organization.ErrNotMember
+	}
+	return &organization.Membership{OrganizationID: orgID, UserID: userID}, nil
+}
+
+func (f *fakeOrganizations) AddMember(ctx context.Context, orgID, userID uuid.UUID, roleName string) (*organization.Member, error) {
+	if f.addErr != nil {
+		return nil, f.addErr
+	}
+	f.added = append(f.added, userID)
+	return &organization.Member{OrganizationID: orgID, UserID: userID}, nil
+}
+
+func (f *fakeOrganizations) CheckAddMember(ctx context.Context, orgID, userID uuid.UUID, roleName string) error {
+	f.checked = append(f.checked, userID)
+	return f.addErr
+}
+
+type fakeInvitations struct {
+	organization.InvitationService
+	inputs []organization.InvitationInput
+}
+
+func (f *fakeInvitations) CreateInvitation(ctx context.Context, orgID, invitedBy uuid.UUID, input organization.InvitationInput) (*organization.Invitation, error) {
+	f.inputs = append(f.inputs, input)
+	return &organization.Invitation{ID: uuid.New(), OrganizationID: orgID, Email: input.Email, Role: input.Role}, nil
+}
+
+type fakeUsers struct {
+	user.Service
+	byEmail map[string]*user.User
+}
+
+func (f *fakeUsers) GetUserByEmail(ctx context.Context, email string) (*user.User, error) {
+	return f.byEmail[email], nil
+}
+
+type fixture struct {
+	service     *service
+	orgs        *fakeOrganizations
+	invitations *fakeInvitations
+	existing    *user.User
+	member      *user.User
+}
+
+func newFixture() *fixture {
+	existing := &user.User{ID: uuid.New(), Email: "existing@example.com"}
+	member := &user.User{ID: uuid.New(), Email: "member@example.com"}
+	orgs := &fakeOrganizations{members: map[uuid.UUID]bool{member.ID: true}}
+	invitations := &fakeInvitations{}
+	users := &fakeUsers{byEmail: map[string]*user.User{existing.Email: existing, member.Email: member}}
+	return &fixture{
+		service:     NewService(&fakeRepository{}, orgs, invitations, users, zap.NewNop()).(*service),
+		orgs:        orgs,
+		invitations: invitations,
+		existing:    existing,
+		member:      member,
+	}
+}
+
+const importCSV = "email,role\nexisting@example.com,user\nmember@example.com,user\nnew@example.com,user\n"
+
+func TestDryRunChecksAddingExistingAccounts(t *testing.T) {
+	f := newFixture()
+
+	imp, err := f.service.StartImport(context.Background(), uuid.New(), uuid.New(), strings.NewReader(importCSV),
+		Options{AddExisting: true, DryRun: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDryRun, imp.Status)
+	assert.Equal(t, uuid.Nil, imp.ID)
+	assert.Equal(t, []uuid.UUID{f.existing.ID}, f.orgs.checked)
+	assert.Empty(t, f.orgs.added)
+	assert.Equal(t, 1, imp.Added)
+	assert.Equal(t, 1, imp.Skipped)
+	assert.Equal(t, 1, imp.Invited)
+	assert.Equal(t, 0, imp.Failed)
+
+	assert.Len(t, f.invitations.inputs, 1)
+	assert.True(t, f.invitations.inputs[0].DryRun)
+	for _, row := range imp.Rows {
+		assert.Nil(t, row.InvitationID, row.Email)
+	}
+}
+
+func TestDryRunReportsFailuresOfAddingMembers(t *testing.T) {
+	tests := []struct {
+		name    string
+		addErr  error
+		outcome Outcome
+		reason  string
+	}{
+		{
+			name:    "Organization scheduled for deletion fails the row",
+			addErr:  organization.ErrPendingDeletion,
+			outcome: OutcomeFailed,
+			reason:  organization.ErrPendingDeletion.Error(),
+		},
+		{
+			name:    "Membership added meanwhile skips the row",
+			addErr:  organization.ErrMemberExists,
+			outcome: OutcomeSkipped,
+			reason:  "already a member of the organization",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.orgs.addErr = tt.addErr
+
+			imp, err := f.service.StartImport(context.Background(), uuid.New(), uuid.New(),
+				strings.NewReader("email\nexisting@example.com\n"), Options{AddExisting: true, DryRun: true})
+
+			assert.NoError(t, err)
+			assert.Len(t, imp.Rows, 1)
+			assert.Equal(t, tt.outcome, imp.Rows[0].Outcome)
+			assert.Equal(t, tt.reason, imp.Rows[0].Reason)
+			assert.Equal(t, 0, imp.Added)
+		})
+	}
+}
+
+func TestProcessRowAddsExistingAccounts(t *testing.T) {
+	f := newFixture()
+	imp := &Import{OrganizationID: uuid.New(), AddExisting: true}
+	row := &RowResult{Line: 2, Email: f.existing.Email, Outcome: OutcomePending}
+
+	f.service.processRow(context.Background(), imp, row, false)
+
+	assert.Equal(t, OutcomeAdded, row.Outcome)
+	assert.Equal(t, []uuid.UUID{f.existing.ID}, f.orgs.added)
+	assert.Empty(t, f.orgs.checked)
+	assert.Equal(t, 1, imp.Added)
+}
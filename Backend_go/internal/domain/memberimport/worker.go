@@ -0,0 +1,86 @@
+package memberimport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// pollInterval is how often the worker looks for imports it was not signalled about,
+// such as ones left behind by a restart
+const pollInterval = 30 * time.Second
+
+// Worker processes queued member imports one at a time
+type Worker struct {
+	service Service
+	logger  *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorker creates a new member import worker
+func NewWorker(service Service, logger *zap.Logger) *Worker {
+	return &Worker{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Start begins processing imports in the background
+func (w *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		w.poll(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case id := <-w.service.Queued():
+				w.run(ctx, id)
+			case <-ticker.C:
+				w.poll(ctx)
+			}
+		}
+	}()
+}
+
+// Stop hands the import being processed back to the queue and stops the worker
+func (w *Worker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+func (w *Worker) poll(ctx context.Context) {
+	ids, err := w.service.Runnable(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("Failed to list queued member imports", zap.Error(err))
+		}
+		return
+	}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		w.run(ctx, id)
+	}
+}
+
+func (w *Worker) run(ctx context.Context, id uuid.UUID) {
+	if err := w.service.Run(ctx, id); err != nil {
+		w.logger.Error("Failed to run member import", zap.String("import_id", id.String()), zap.Error(err))
+	}
+}
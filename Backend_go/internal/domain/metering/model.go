@@ -0,0 +1,129 @@
+package metering
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Meter names a metered action
+type Meter string
+
+const (
+	MeterAPICalls           Meter = "api_calls"
+	MeterWorkflowExecutions Meter = "workflow_executions"
+	// MeterStorageBytes counts bytes of uploaded content stored, such as email attachments
+	MeterStorageBytes  Meter = "storage_bytes"
+	MeterAISuggestions Meter = "ai_suggestions"
+	// MeterAPIErrors counts API calls answered with a 4xx or 5xx status
+	MeterAPIErrors Meter = "api_errors"
+	// MeterAPIRateLimited counts API calls rejected by the rate limiter. They are not
+	// counted as API calls.
+	MeterAPIRateLimited Meter = "api_rate_limited"
+	// MeterAPIKeyCalls counts the API calls authenticated with an API key
+	MeterAPIKeyCalls Meter = "api_key_calls"
+)
+
+// Meters lists every meter, in display order
+var Meters = []Meter{
+	MeterAPICalls, MeterWorkflowExecutions, MeterStorageBytes, MeterAISuggestions,
+	MeterAPIErrors, MeterAPIRateLimited, MeterAPIKeyCalls,
+}
+
+// IsValid checks if the meter is known
+func (m Meter) IsValid() bool {
+	for _, meter := range Meters {
+		if m == meter {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReportable reports whether clients may report usage of the meter themselves.
+// AI suggestions are produced by the AI service, outside this API.
+func (m Meter) IsReportable() bool {
+	return m == MeterAISuggestions
+}
+
+var (
+	ErrInvalidMeter = errors.New("invalid meter")
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// Event is one metered action. Events without an organization are attributed to the
+// user's personal workspace and aggregated under uuid.Nil. Events with a user are also
+// aggregated per user and hour.
+type Event struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+	Meter          Meter
+	Quantity       int64
+	OccurredAt     time.Time
+}
+
+// DailyUsage is the total of a meter for an organization on one UTC day
+type DailyUsage struct {
+	ID             uuid.UUID `json:"-" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex:idx_metering_daily_key,priority:1"`
+	Meter          Meter     `json:"meter" gorm:"type:varchar(50);not null;uniqueIndex:idx_metering_daily_key,priority:2"`
+	Day            time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_metering_daily_key,priority:3;index"`
+	Quantity       int64     `json:"quantity" gorm:"not null;default:0"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the DailyUsage model
+func (DailyUsage) TableName() string {
+	return "metering_daily_usage"
+}
+
+// UserHourlyUsage is the total of a meter for a user in one UTC hour, across
+// organizations. Rows are kept for UserUsageRetention.
+type UserHourlyUsage struct {
+	ID       uuid.UUID `json:"-" gorm:"type:uuid;primary_key"`
+	UserID   uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_metering_user_hourly_key,priority:1"`
+	Meter    Meter     `json:"meter" gorm:"type:varchar(50);not null;uniqueIndex:idx_metering_user_hourly_key,priority:2"`
+	Hour     time.Time `json:"hour" gorm:"not null;uniqueIndex:idx_metering_user_hourly_key,priority:3;index"`
+	Quantity int64     `json:"quantity" gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for the UserHourlyUsage model
+func (UserHourlyUsage) TableName() string {
+	return "metering_user_hourly_usage"
+}
+
+// UserUsageReport is a user's hourly usage in a time range with totals per meter
+type UserUsageReport struct {
+	From   time.Time         `json:"from"`
+	To     time.Time         `json:"to"`
+	Hours  []UserHourlyUsage `json:"hours"`
+	Totals map[Meter]int64   `json:"totals"`
+}
+
+// UsageFilter selects daily usage rows. From and To are inclusive UTC days.
+type UsageFilter struct {
+	OrganizationID *uuid.UUID
+	Meter          *Meter
+	From           time.Time
+	To             time.Time
+}
+
+// UsageReport is the daily usage matching a filter with totals per meter
+type UsageReport struct {
+	From   time.Time       `json:"from"`
+	To     time.Time       `json:"to"`
+	Days   []DailyUsage    `json:"days"`
+	Totals map[Meter]int64 `json:"totals"`
+}
+
+// Hour truncates a time to its UTC hour
+func Hour(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Hour)
+}
+
+// Day truncates a time to its UTC day
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,219 @@
+package metering
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Recorder accepts metered events. Recording never blocks the caller.
+type Recorder interface {
+	Record(event Event)
+}
+
+// PipelineConfig controls buffering and how often aggregated usage is written
+type PipelineConfig struct {
+	BufferSize    int
+	FlushInterval time.Duration
+	// MaxPending flushes early once this many daily and hourly totals are pending
+	MaxPending int
+	// UserUsageRetention is how long hourly user totals are kept
+	UserUsageRetention time.Duration
+}
+
+// DefaultPipelineConfig returns the configuration used in production
+func DefaultPipelineConfig() PipelineConfig {
+	return PipelineConfig{
+		BufferSize:         4096,
+		FlushInterval:      10 * time.Second,
+		MaxPending:         1000,
+		UserUsageRetention: 30 * 24 * time.Hour,
+	}
+}
+
+// pruneInterval is how often hourly user totals past their retention are deleted
+const pruneInterval = time.Hour
+
+type usageKey struct {
+	orgID uuid.UUID
+	meter Meter
+	day   time.Time
+}
+
+type userUsageKey struct {
+	userID uuid.UUID
+	meter  Meter
+	hour   time.Time
+}
+
+// pendingUsage is the usage aggregated since the last flush
+type pendingUsage struct {
+	daily  map[usageKey]int64
+	hourly map[userUsageKey]int64
+}
+
+func newPendingUsage() *pendingUsage {
+	return &pendingUsage{
+		daily:  make(map[usageKey]int64),
+		hourly: make(map[userUsageKey]int64),
+	}
+}
+
+func (p *pendingUsage) add(event Event) {
+	p.daily[usageKey{orgID: event.OrganizationID, meter: event.Meter, day: Day(event.OccurredAt)}] += event.Quantity
+	if event.UserID != uuid.Nil {
+		p.hourly[userUsageKey{userID: event.UserID, meter: event.Meter, hour: Hour(event.OccurredAt)}] += event.Quantity
+	}
+}
+
+func (p *pendingUsage) size() int {
+	return len(p.daily) + len(p.hourly)
+}
+
+// Pipeline aggregates events in memory per organization, meter and day, and per user,
+// meter and hour, and adds the totals to the database periodically, so hot paths such as API calls cost one
+// channel send. Usage recorded since the last flush is not yet visible to queries.
+type Pipeline struct {
+	repo   Repository
+	config PipelineConfig
+	logger *zap.Logger
+
+	events     chan Event
+	dropped    atomic.Int64
+	stop       chan struct{}
+	wg         sync.WaitGroup
+	lastPruned time.Time
+}
+
+// NewPipeline creates a new metering pipeline
+func NewPipeline(repo Repository, config PipelineConfig, logger *zap.Logger) *Pipeline {
+	return &Pipeline{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		events: make(chan Event, config.BufferSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Record queues an event. Events are dropped, and counted, when the buffer is full.
+func (p *Pipeline) Record(event Event) {
+	if event.Quantity == 0 || !event.Meter.IsValid() {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	select {
+	case p.events <- event:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// Start begins aggregating and flushing events in the background
+func (p *Pipeline) Start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.config.FlushInterval)
+		defer ticker.Stop()
+
+		pending := newPendingUsage()
+		for {
+			select {
+			case event := <-p.events:
+				pending.add(event)
+				if pending.size() >= p.config.MaxPending {
+					pending = p.flush(pending)
+				}
+			case <-ticker.C:
+				pending = p.flush(pending)
+				p.prune()
+			case <-p.stop:
+				// Drain what was queued before stopping
+				for {
+					select {
+					case event := <-p.events:
+						pending.add(event)
+					default:
+						p.flush(pending)
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop flushes pending usage and stops the pipeline
+func (p *Pipeline) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// flush writes the pending totals. On failure they are kept and retried on the next flush.
+func (p *Pipeline) flush(pending *pendingUsage) *pendingUsage {
+	if dropped := p.dropped.Swap(0); dropped > 0 {
+		p.logger.Warn("Dropped metering events, buffer full", zap.Int64("dropped", dropped))
+	}
+	if pending.size() == 0 {
+		return pending
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if len(pending.daily) > 0 {
+		rows := make([]DailyUsage, 0, len(pending.daily))
+		for key, quantity := range pending.daily {
+			rows = append(rows, DailyUsage{
+				OrganizationID: key.orgID,
+				Meter:          key.meter,
+				Day:            key.day,
+				Quantity:       quantity,
+			})
+		}
+		if err := p.repo.AddDaily(ctx, rows); err != nil {
+			p.logger.Error("Failed to write metered usage", zap.Int("rows", len(rows)), zap.Error(err))
+			return pending
+		}
+		pending.daily = make(map[usageKey]int64)
+	}
+
+	if len(pending.hourly) > 0 {
+		rows := make([]UserHourlyUsage, 0, len(pending.hourly))
+		for key, quantity := range pending.hourly {
+			rows = append(rows, UserHourlyUsage{
+				UserID:   key.userID,
+				Meter:    key.meter,
+				Hour:     key.hour,
+				Quantity: quantity,
+			})
+		}
+		if err := p.repo.AddUserHourly(ctx, rows); err != nil {
+			p.logger.Error("Failed to write metered user usage", zap.Int("rows", len(rows)), zap.Error(err))
+			return pending
+		}
+		pending.hourly = make(map[userUsageKey]int64)
+	}
+	return pending
+}
+
+// prune deletes hourly user totals past their retention, at most once per pruneInterval
+func (p *Pipeline) prune() {
+	if p.config.UserUsageRetention <= 0 || time.Since(p.lastPruned) < pruneInterval {
+		return
+	}
+	p.lastPruned = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := p.repo.PruneUserHourly(ctx, Hour(time.Now().Add(-p.config.UserUsageRetention))); err != nil {
+		p.logger.Error("Failed to prune metered user usage", zap.Error(err))
+	}
+}
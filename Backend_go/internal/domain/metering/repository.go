@@ -0,0 +1,109 @@
+package metering
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for metering data access
+type Repository interface {
+	// AddDaily adds the quantities to the stored daily totals
+	AddDaily(ctx context.Context, rows []DailyUsage) error
+	ListDaily(ctx context.Context, filter UsageFilter) ([]DailyUsage, error)
+	Total(ctx context.Context, orgID uuid.UUID, meter Meter, from, to time.Time) (int64, error)
+	// AddUserHourly adds the quantities to the stored hourly totals of users
+	AddUserHourly(ctx context.Context, rows []UserHourlyUsage) error
+	// ListUserHourly returns a user's hourly totals between two hours, inclusive
+	ListUserHourly(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]UserHourlyUsage, error)
+	// PruneUserHourly deletes the hourly totals of hours before the given time
+	PruneUserHourly(ctx context.Context, before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new metering repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) AddDaily(ctx context.Context, rows []DailyUsage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range rows {
+		if rows[i].ID == uuid.Nil {
+			rows[i].ID = uuid.New()
+		}
+		rows[i].UpdatedAt = now
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}, {Name: "meter"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity":   gorm.Expr("metering_daily_usage.quantity + excluded.quantity"),
+			"updated_at": now,
+		}),
+	}).Create(&rows).Error
+}
+
+func (r *repository) ListDaily(ctx context.Context, filter UsageFilter) ([]DailyUsage, error) {
+	query := r.db.WithContext(ctx).Model(&DailyUsage{}).
+		Where("day BETWEEN ? AND ?", filter.From, filter.To)
+	if filter.OrganizationID != nil {
+		query = query.Where("organization_id = ?", *filter.OrganizationID)
+	}
+	if filter.Meter != nil {
+		query = query.Where("meter = ?", *filter.Meter)
+	}
+
+	var rows []DailyUsage
+	err := query.Order("day ASC, organization_id ASC, meter ASC").Find(&rows).Error
+	return rows, err
+}
+
+func (r *repository) Total(ctx context.Context, orgID uuid.UUID, meter Meter, from, to time.Time) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&DailyUsage{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("organization_id = ? AND meter = ? AND day BETWEEN ? AND ?", orgID, meter, from, to).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *repository) AddUserHourly(ctx context.Context, rows []UserHourlyUsage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		if rows[i].ID == uuid.Nil {
+			rows[i].ID = uuid.New()
+		}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "meter"}, {Name: "hour"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity": gorm.Expr("metering_user_hourly_usage.quantity + excluded.quantity"),
+		}),
+	}).Create(&rows).Error
+}
+
+func (r *repository) ListUserHourly(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]UserHourlyUsage, error) {
+	var rows []UserHourlyUsage
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND hour BETWEEN ? AND ?", userID, from, to).
+		Order("hour ASC, meter ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *repository) PruneUserHourly(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("hour < ?", before).Delete(&UserHourlyUsage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,107 @@
+package metering
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxReportedQuantity bounds a single client-reported event
+const maxReportedQuantity = 1000
+
+// maxReportDays bounds the range of a usage report
+const maxReportDays = 366
+
+// maxUserReportHours bounds the range of a user usage report
+const maxUserReportHours = 7 * 24
+
+// Service defines the interface for querying and reporting metered usage
+type Service interface {
+	// Usage returns daily usage and totals per meter
+	Usage(ctx context.Context, filter UsageFilter) (*UsageReport, error)
+	// Total sums a meter for an organization between two times, by UTC day
+	Total(ctx context.Context, orgID uuid.UUID, meter Meter, from, to time.Time) (int64, error)
+	// Report records usage reported by a client, for meters produced outside this API
+	Report(ctx context.Context, event Event) error
+	// UserUsage returns a user's hourly usage across organizations and totals per meter,
+	// for up to the last 7 days
+	UserUsage(ctx context.Context, userID uuid.UUID, from, to time.Time) (*UserUsageReport, error)
+}
+
+type service struct {
+	repo     Repository
+	recorder Recorder
+}
+
+// NewService creates a new metering service
+func NewService(repo Repository, recorder Recorder) Service {
+	return &service{repo: repo, recorder: recorder}
+}
+
+func (s *service) Usage(ctx context.Context, filter UsageFilter) (*UsageReport, error) {
+	if filter.Meter != nil && !filter.Meter.IsValid() {
+		return nil, ErrInvalidMeter
+	}
+	filter.From = Day(filter.From)
+	filter.To = Day(filter.To)
+	if filter.To.Before(filter.From) || filter.To.Sub(filter.From) > maxReportDays*24*time.Hour {
+		return nil, ErrInvalidInput
+	}
+
+	rows, err := s.repo.ListDaily(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{
+		From:   filter.From,
+		To:     filter.To,
+		Days:   rows,
+		Totals: make(map[Meter]int64),
+	}
+	for _, row := range rows {
+		report.Totals[row.Meter] += row.Quantity
+	}
+	return report, nil
+}
+
+func (s *service) Total(ctx context.Context, orgID uuid.UUID, meter Meter, from, to time.Time) (int64, error) {
+	return s.repo.Total(ctx, orgID, meter, Day(from), Day(to))
+}
+
+func (s *service) Report(ctx context.Context, event Event) error {
+	if !event.Meter.IsReportable() {
+		return ErrInvalidMeter
+	}
+	if event.Quantity <= 0 || event.Quantity > maxReportedQuantity {
+		return ErrInvalidInput
+	}
+	event.OccurredAt = time.Now()
+	s.recorder.Record(event)
+	return nil
+}
+
+func (s *service) UserUsage(ctx context.Context, userID uuid.UUID, from, to time.Time) (*UserUsageReport, error) {
+	from = Hour(from)
+	to = Hour(to)
+	if to.Before(from) || to.Sub(from) > maxUserReportHours*time.Hour {
+		return nil, ErrInvalidInput
+	}
+
+	rows, err := s.repo.ListUserHourly(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UserUsageReport{
+		From:   from,
+		To:     to,
+		Hours:  rows,
+		Totals: make(map[Meter]int64),
+	}
+	for _, row := range rows {
+		report.Totals[row.Meter] += row.Quantity
+	}
+	return report, nil
+}
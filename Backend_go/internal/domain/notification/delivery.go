@@ -154,44 +154,6 @@ func (s *inAppDeliveryService) DeliverWithConfig(ctx context.Context, notificati
 	return s.Deliver(ctx, notification, InApp)
 }
 
-// emailDeliveryService implements DeliveryService for email notifications
-type emailDeliveryService struct {
-	// Email service components would go here
-	logger *logrus.Logger
-}
-
-// NewEmailDeliveryService creates a new email delivery service
-func NewEmailDeliveryService(logger *logrus.Logger) DeliveryService {
-	return &emailDeliveryService{
-		logger: logger,
-	}
-}
-
-// Deliver sends an email notification
-func (s *emailDeliveryService) Deliver(ctx context.Context, notification *Notification, method DeliveryMethod) error {
-	// In a real implementation, this would send an email
-	s.logger.WithFields(logrus.Fields{
-		"notification_id": notification.ID,
-		"user_id":         notification.UserID,
-		"title":           notification.Title,
-	}).Info("Would send email notification")
-
-	return nil
-}
-
-// DeliverWithConfig sends an email notification with configuration
-func (s *emailDeliveryService) DeliverWithConfig(ctx context.Context, notification *Notification, config map[string]interface{}) error {
-	// In a real implementation, this would use the config to customize the email
-	s.logger.WithFields(logrus.Fields{
-		"notification_id": notification.ID,
-		"user_id":         notification.UserID,
-		"title":           notification.Title,
-		"config":          config,
-	}).Info("Would send customized email notification")
-
-	return nil
-}
-
 // Standard implementation of DeliveryServiceFactory
 type standardDeliveryServiceFactory struct {
 	emailSvc DeliveryService
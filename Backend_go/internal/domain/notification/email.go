@@ -0,0 +1,109 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/email"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Email categories users can opt out of in their notification preferences
+const (
+	EmailCategoryHabits    = "habits"
+	EmailCategoryTasks     = "tasks"
+	EmailCategoryWorkflows = "workflows"
+	EmailCategoryCalendar  = "calendar"
+	EmailCategoryGeneral   = "general"
+)
+
+// EmailRecipients looks up where to email a user and whether they want the email
+type EmailRecipients interface {
+	// EmailRecipient returns the user's address, and false when they do not accept
+	// email of the category
+	EmailRecipient(ctx context.Context, userID uuid.UUID, category string) (email.Address, bool, error)
+}
+
+// emailTemplates picks a dedicated template for some notification types. Others use
+// the generic notification template.
+var emailTemplates = map[Type]string{
+	HabitReminder:          email.TemplateHabitReminder,
+	TaskAssigned:           email.TemplateTaskAssigned,
+	WorkflowActionRequired: email.TemplateWorkflowApproval,
+}
+
+// referenceEmails maps the domain a notification refers to onto its email category and
+// the web app path of the referenced entity
+var referenceEmails = map[string]struct {
+	category string
+	path     string
+}{
+	"habits":         {EmailCategoryHabits, "/habits/%s"},
+	"task":           {EmailCategoryTasks, "/tasks/%s"},
+	"workflow":       {EmailCategoryWorkflows, "/workflows/%s"},
+	"calendar_event": {EmailCategoryCalendar, "/calendar?event=%s"},
+}
+
+// emailDeliveryService implements DeliveryService for email notifications
+type emailDeliveryService struct {
+	mailer     *email.Mailer
+	recipients EmailRecipients
+	logger     *logrus.Logger
+}
+
+// NewEmailDeliveryService creates a delivery service that emails notifications to users
+// who have not turned off email for their category
+func NewEmailDeliveryService(mailer *email.Mailer, recipients EmailRecipients, logger *logrus.Logger) DeliveryService {
+	return &emailDeliveryService{
+		mailer:     mailer,
+		recipients: recipients,
+		logger:     logger,
+	}
+}
+
+// Deliver sends an email notification
+func (s *emailDeliveryService) Deliver(ctx context.Context, notification *Notification, method DeliveryMethod) error {
+	category := EmailCategoryGeneral
+	var actionURL string
+	if ref, ok := referenceEmails[notification.Reference]; ok {
+		category = ref.category
+		if notification.ReferenceID != uuid.Nil {
+			actionURL = s.mailer.URL(fmt.Sprintf(ref.path, notification.ReferenceID))
+		}
+	}
+
+	to, wanted, err := s.recipients.EmailRecipient(ctx, notification.UserID, category)
+	if err != nil {
+		return fmt.Errorf("failed to resolve email recipient: %w", err)
+	}
+	if !wanted {
+		s.logger.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"user_id":         notification.UserID,
+			"category":        category,
+		}).Debug("User does not receive this email, skipping")
+		return nil
+	}
+
+	template, ok := emailTemplates[notification.Type]
+	if !ok {
+		template = email.TemplateNotification
+	}
+	data := email.Data{
+		Title:       notification.Title,
+		Content:     notification.Content,
+		ActionURL:   actionURL,
+		ActionLabel: "Open in Compass",
+		Fields:      notification.Data,
+	}
+	if actionURL == "" {
+		data.ActionLabel = ""
+	}
+	return s.mailer.Send(ctx, to, notification.Title, template, data, notification.Content)
+}
+
+// DeliverWithConfig sends an email notification. Emails take no configuration.
+func (s *emailDeliveryService) DeliverWithConfig(ctx context.Context, notification *Notification, config map[string]interface{}) error {
+	return s.Deliver(ctx, notification, Email)
+}
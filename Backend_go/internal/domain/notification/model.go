@@ -20,6 +20,7 @@ const (
 	NewComment   = "new_comment"
 	NewLike      = "new_like"
 	TaskAssigned = "task_assigned"
+	TaskAtRisk   = "task_at_risk"
 	Reminder     = "reminder"
 	System       = "system"
 
@@ -34,7 +35,9 @@ const (
 	EventInvite            = "event_invite"
 	EventInviteAccepted    = "event_invite_accepted"
 	EventInviteDeclined    = "event_invite_declined"
+	EventInviteTentative   = "event_invite_tentative"
 	EventRemovedFromCollab = "event_removed_from_collab"
+	EventRescheduled       = "event_rescheduled"
 
 	// Workflow notification types
 	WorkflowActionRequired = "workflow_action_required"
@@ -42,6 +45,20 @@ const (
 	WorkflowRejected       = "workflow_rejected"
 	WorkflowCompleted      = "workflow_completed"
 	WorkflowFailed         = "workflow_failed"
+
+	// Organization notification types
+	Announcement                  = "announcement"
+	OrganizationDeletionScheduled = "organization_deletion_scheduled"
+	OrganizationDeletionCancelled = "organization_deletion_cancelled"
+
+	// Attachment notification types
+	AttachmentInfected = "attachment_infected"
+
+	// Retention notification types
+	RetentionScheduled = "retention_scheduled"
+
+	// Operations notification types
+	SLOBudgetBurning = "slo_budget_burning"
 )
 
 // Status represents the status of a notification
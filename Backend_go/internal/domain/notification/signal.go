@@ -21,6 +21,10 @@ type SignalRepository interface {
 
 	// Publish publishes a notification to a topic
 	Publish(topic string, notification *Notification) error
+
+	// Observe calls the observer for every notification published, whatever its topic.
+	// Observers run on the publishing goroutine and must not block.
+	Observe(observer func(notification *Notification))
 }
 
 // Topic represents a notification topic
@@ -33,6 +37,7 @@ type Topic struct {
 type signalRepository struct {
 	mutex     sync.Mutex
 	topics    map[string]map[string]chan *Notification
+	observers []func(notification *Notification)
 	topicSize int
 }
 
@@ -84,8 +89,22 @@ func (r *signalRepository) Subscribe(topic string) (<-chan *Notification, func()
 	return ch, cancel, nil
 }
 
+// Observe registers an observer of every published notification
+func (r *signalRepository) Observe(observer func(notification *Notification)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.observers = append(r.observers, observer)
+}
+
 // Publish publishes a notification to a topic
 func (r *signalRepository) Publish(topic string, notification *Notification) error {
+	r.mutex.Lock()
+	observers := r.observers
+	r.mutex.Unlock()
+	for _, observe := range observers {
+		observe(notification)
+	}
+
 	r.mutex.Lock()
 
 	// Create topic if it doesn't exist
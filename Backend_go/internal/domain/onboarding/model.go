@@ -0,0 +1,188 @@
+package onboarding
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Step identifies a step of the onboarding wizard
+type Step string
+
+const (
+	StepCreateOrganization Step = "create_organization"
+	StepInviteMembers      Step = "invite_members"
+	StepChooseTemplate     Step = "choose_template"
+	StepSeedSamples        Step = "seed_samples"
+	StepFinish             Step = "finish"
+)
+
+// Steps lists the wizard steps in the order the frontend presents them
+var Steps = []Step{
+	StepCreateOrganization,
+	StepInviteMembers,
+	StepChooseTemplate,
+	StepSeedSamples,
+	StepFinish,
+}
+
+// IsValid checks if the step is a known onboarding step
+func (s Step) IsValid() bool {
+	for _, step := range Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrProgressNotFound = errors.New("onboarding progress not found")
+	ErrInvalidStep      = errors.New("invalid onboarding step")
+	ErrInvalidTemplate  = errors.New("unknown project template")
+	ErrTemplateRequired = errors.New("a project template must be chosen first")
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrNotAuthorized    = errors.New("not authorized")
+)
+
+// Progress persists the state of an organization's onboarding wizard so it can be resumed
+type Progress struct {
+	OrganizationID uuid.UUID      `json:"organization_id" gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	CompletedSteps pq.StringArray `json:"completed_steps" gorm:"type:text[]"`
+	InvitedEmails  pq.StringArray `json:"invited_emails" gorm:"type:text[]"`
+	Template       string         `json:"template,omitempty" gorm:"type:varchar(50)"`
+	ProjectID      *uuid.UUID     `json:"project_id,omitempty" gorm:"type:uuid"`
+	SeededAt       *time.Time     `json:"seeded_at,omitempty"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Progress model
+func (Progress) TableName() string {
+	return "onboarding_progress"
+}
+
+// BeforeCreate is called before creating a new progress record
+func (p *Progress) BeforeCreate(tx *gorm.DB) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating a progress record
+func (p *Progress) BeforeUpdate(tx *gorm.DB) error {
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsStepCompleted reports whether the given step has been completed
+func (p *Progress) IsStepCompleted(step Step) bool {
+	for _, completed := range p.CompletedSteps {
+		if completed == string(step) {
+			return true
+		}
+	}
+	return false
+}
+
+// markStep records a step as completed, keeping the list free of duplicates
+func (p *Progress) markStep(step Step) {
+	if !p.IsStepCompleted(step) {
+		p.CompletedSteps = append(p.CompletedSteps, string(step))
+	}
+}
+
+// NextStep returns the first step that is not yet completed, or an empty step when done
+func (p *Progress) NextStep() Step {
+	for _, step := range Steps {
+		if !p.IsStepCompleted(step) {
+			return step
+		}
+	}
+	return ""
+}
+
+// TemplateTask describes a sample task seeded by a project template
+type TemplateTask struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Priority    string `json:"priority"`
+	DueInDays   int    `json:"due_in_days"`
+}
+
+// TemplateHabit describes a sample habit seeded by a project template
+type TemplateHabit struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// ProjectTemplate describes a starter project the wizard can create
+type ProjectTemplate struct {
+	Key                string          `json:"key"`
+	Name               string          `json:"name"`
+	ProjectName        string          `json:"project_name"`
+	ProjectDescription string          `json:"project_description"`
+	Tasks              []TemplateTask  `json:"tasks"`
+	Habits             []TemplateHabit `json:"habits"`
+}
+
+// Templates are the project templates offered by the onboarding wizard
+var Templates = []ProjectTemplate{
+	{
+		Key:                "software",
+		Name:               "Software Development",
+		ProjectName:        "Product Launch",
+		ProjectDescription: "Plan, build and ship the first version of your product",
+		Tasks: []TemplateTask{
+			{Title: "Define MVP scope", Description: "List the features needed for the first release", Priority: "High", DueInDays: 3},
+			{Title: "Set up repository and CI", Description: "Create the code repository and a basic build pipeline", Priority: "Medium", DueInDays: 5},
+			{Title: "Plan first sprint", Description: "Break the MVP into tasks for the first two weeks", Priority: "Medium", DueInDays: 7},
+		},
+		Habits: []TemplateHabit{
+			{Title: "Daily stand-up notes", Description: "Write down yesterday's progress and today's plan"},
+		},
+	},
+	{
+		Key:                "marketing",
+		Name:               "Marketing Campaign",
+		ProjectName:        "Campaign Launch",
+		ProjectDescription: "Coordinate content and channels for a marketing campaign",
+		Tasks: []TemplateTask{
+			{Title: "Define target audience", Description: "Describe the audience and key messages", Priority: "High", DueInDays: 2},
+			{Title: "Draft content calendar", Description: "Schedule posts and emails for the next month", Priority: "Medium", DueInDays: 5},
+			{Title: "Set up campaign analytics", Description: "Decide which metrics to track and where", Priority: "Low", DueInDays: 10},
+		},
+		Habits: []TemplateHabit{
+			{Title: "Review campaign metrics", Description: "Check the dashboard for yesterday's results"},
+		},
+	},
+	{
+		Key:                "personal",
+		Name:               "Personal Productivity",
+		ProjectName:        "My Goals",
+		ProjectDescription: "Track personal goals and routines",
+		Tasks: []TemplateTask{
+			{Title: "Write down quarterly goals", Description: "Pick three goals for the next three months", Priority: "High", DueInDays: 1},
+			{Title: "Plan this week", Description: "Block time in the calendar for your goals", Priority: "Medium", DueInDays: 2},
+		},
+		Habits: []TemplateHabit{
+			{Title: "Morning planning", Description: "Spend five minutes planning the day"},
+			{Title: "Evening review", Description: "Reflect on what went well today"},
+		},
+	},
+}
+
+// FindTemplate returns the template with the given key
+func FindTemplate(key string) (*ProjectTemplate, bool) {
+	for i := range Templates {
+		if Templates[i].Key == key {
+			return &Templates[i], true
+		}
+	}
+	return nil, false
+}
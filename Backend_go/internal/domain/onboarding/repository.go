@@ -0,0 +1,67 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for onboarding progress data access
+type Repository interface {
+	Create(ctx context.Context, progress *Progress) error
+	// CreateWithOrganization stores a new organization, its owner's membership and the
+	// progress in one transaction
+	CreateWithOrganization(ctx context.Context, org *organization.Organization, owner *organization.Member, progress *Progress) error
+	FindByOrganizationID(ctx context.Context, orgID uuid.UUID) (*Progress, error)
+	Update(ctx context.Context, progress *Progress) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new onboarding repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// Create stores a new progress record
+func (r *repository) Create(ctx context.Context, progress *Progress) error {
+	return r.db.WithContext(ctx).Create(progress).Error
+}
+
+// CreateWithOrganization stores the organization, membership and progress together, so a
+// failed step leaves no organization without its wizard
+func (r *repository) CreateWithOrganization(ctx context.Context, org *organization.Organization, owner *organization.Member, progress *Progress) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(owner).Error; err != nil {
+			return err
+		}
+		return tx.Create(progress).Error
+	})
+}
+
+// FindByOrganizationID retrieves the progress record of an organization
+func (r *repository) FindByOrganizationID(ctx context.Context, orgID uuid.UUID) (*Progress, error) {
+	var progress Progress
+	result := r.db.WithContext(ctx).First(&progress, "organization_id = ?", orgID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrProgressNotFound
+		}
+		return nil, result.Error
+	}
+	return &progress, nil
+}
+
+// Update saves changes to a progress record
+func (r *repository) Update(ctx context.Context, progress *Progress) error {
+	return r.db.WithContext(ctx).Save(progress).Error
+}
@@ -0,0 +1,317 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StartInput is the input for the first wizard step
+type StartInput struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	UserID      uuid.UUID `json:"user_id"`
+}
+
+// SeedResult describes what was created by the seed step
+type SeedResult struct {
+	ProjectID uuid.UUID   `json:"project_id"`
+	TaskIDs   []uuid.UUID `json:"task_ids"`
+	HabitIDs  []uuid.UUID `json:"habit_ids"`
+}
+
+// Service defines the interface for the organization onboarding wizard
+type Service interface {
+	Start(ctx context.Context, input StartInput) (*organization.Organization, *Progress, error)
+	GetProgress(ctx context.Context, orgID, userID uuid.UUID) (*Progress, error)
+	InviteMembers(ctx context.Context, orgID, userID uuid.UUID, emails []string) (*Progress, error)
+	ChooseTemplate(ctx context.Context, orgID, userID uuid.UUID, templateKey string) (*Progress, error)
+	SeedSamples(ctx context.Context, orgID, userID uuid.UUID) (*Progress, *SeedResult, error)
+	CompleteStep(ctx context.Context, orgID, userID uuid.UUID, step Step) (*Progress, error)
+}
+
+type service struct {
+	repo           Repository
+	orgService     organization.Service
+	invitations    organization.InvitationService
+	rolesService   roles.Service
+	projectService project.Service
+	taskService    task.Service
+	habitsService  habits.Service
+	logger         *zap.Logger
+}
+
+// NewService creates a new onboarding service instance
+func NewService(
+	repo Repository,
+	orgService organization.Service,
+	invitations organization.InvitationService,
+	rolesService roles.Service,
+	projectService project.Service,
+	taskService task.Service,
+	habitsService habits.Service,
+	logger *zap.Logger,
+) Service {
+	return &service{
+		repo:           repo,
+		orgService:     orgService,
+		invitations:    invitations,
+		rolesService:   rolesService,
+		projectService: projectService,
+		taskService:    taskService,
+		habitsService:  habitsService,
+		logger:         logger,
+	}
+}
+
+// Start creates the organization with the user as its owner and begins tracking
+// onboarding progress. Nothing is stored unless all of it is.
+func (s *service) Start(ctx context.Context, input StartInput) (*organization.Organization, *Progress, error) {
+	if input.UserID == uuid.Nil {
+		return nil, nil, ErrInvalidInput
+	}
+	if input.Name == "" {
+		return nil, nil, organization.ErrInvalidInput
+	}
+
+	_, err := s.orgService.GetOrganizationByName(ctx, input.Name)
+	if err == nil {
+		return nil, nil, organization.ErrDuplicateName
+	}
+	if !errors.Is(err, organization.ErrOrganizationNotFound) {
+		return nil, nil, err
+	}
+	ownerRole, err := s.rolesService.GetRoleByName(ctx, organization.OwnerRole)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	org := &organization.Organization{
+		ID:          uuid.New(),
+		Name:        input.Name,
+		Description: input.Description,
+		Status:      organization.OrganizationStatusActive,
+		CreatorID:   input.UserID,
+		OwnerID:     input.UserID,
+	}
+	owner := &organization.Member{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		UserID:         input.UserID,
+		RoleID:         ownerRole.ID,
+	}
+	progress := &Progress{
+		OrganizationID: org.ID,
+		UserID:         input.UserID,
+	}
+	progress.markStep(StepCreateOrganization)
+
+	if err := s.repo.CreateWithOrganization(ctx, org, owner, progress); err != nil {
+		return nil, nil, err
+	}
+
+	return org, progress, nil
+}
+
+// GetProgress returns the onboarding progress so the wizard can resume
+func (s *service) GetProgress(ctx context.Context, orgID, userID uuid.UUID) (*Progress, error) {
+	return s.authorizedProgress(ctx, orgID, userID)
+}
+
+// InviteMembers sends organization invitations to the email addresses and records them.
+// Addresses already invited are skipped, so the step can be retried.
+func (s *service) InviteMembers(ctx context.Context, orgID, userID uuid.UUID, emails []string) (*Progress, error) {
+	progress, err := s.authorizedProgress(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]struct{}, len(progress.InvitedEmails))
+	for _, email := range progress.InvitedEmails {
+		existing[email] = struct{}{}
+	}
+
+	// Every address is checked before anyone is invited
+	var invite []string
+	for _, email := range emails {
+		addr, err := mail.ParseAddress(strings.TrimSpace(email))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid email %q", ErrInvalidInput, email)
+		}
+		normalized := strings.ToLower(addr.Address)
+		if _, ok := existing[normalized]; ok {
+			continue
+		}
+		existing[normalized] = struct{}{}
+		invite = append(invite, normalized)
+	}
+
+	for _, email := range invite {
+		_, err := s.invitations.CreateInvitation(ctx, orgID, userID, organization.InvitationInput{Email: email})
+		if err != nil && !errors.Is(err, organization.ErrInvitationExists) {
+			return nil, err
+		}
+		progress.InvitedEmails = append(progress.InvitedEmails, email)
+	}
+
+	progress.markStep(StepInviteMembers)
+	if err := s.repo.Update(ctx, progress); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// ChooseTemplate records the project template picked in the wizard
+func (s *service) ChooseTemplate(ctx context.Context, orgID, userID uuid.UUID, templateKey string) (*Progress, error) {
+	if _, ok := FindTemplate(templateKey); !ok {
+		return nil, ErrInvalidTemplate
+	}
+
+	progress, err := s.authorizedProgress(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress.Template = templateKey
+	progress.markStep(StepChooseTemplate)
+	if err := s.repo.Update(ctx, progress); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// SeedSamples creates the template project with its sample tasks and habits.
+// Seeding is done once per organization; later calls return the existing project.
+func (s *service) SeedSamples(ctx context.Context, orgID, userID uuid.UUID) (*Progress, *SeedResult, error) {
+	progress, err := s.authorizedProgress(ctx, orgID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if progress.SeededAt != nil && progress.ProjectID != nil {
+		return progress, &SeedResult{ProjectID: *progress.ProjectID}, nil
+	}
+
+	tmpl, ok := FindTemplate(progress.Template)
+	if !ok {
+		return nil, nil, ErrTemplateRequired
+	}
+
+	now := time.Now()
+	proj, err := s.projectService.CreateProject(ctx, project.CreateProjectInput{
+		Name:           tmpl.ProjectName,
+		Description:    tmpl.ProjectDescription,
+		Status:         project.ProjectStatusActive,
+		OrganizationID: orgID,
+		CreatorID:      userID,
+		OwnerID:        userID,
+		StartDate:      now,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create template project: %w", err)
+	}
+
+	result := &SeedResult{ProjectID: proj.ID}
+
+	for _, t := range tmpl.Tasks {
+		dueDate := now.AddDate(0, 0, t.DueInDays)
+		created, err := s.taskService.CreateTask(ctx, task.CreateTaskInput{
+			Title:          t.Title,
+			Description:    t.Description,
+			Priority:       task.TaskPriority(t.Priority),
+			CreatorID:      userID,
+			AssigneeID:     &userID,
+			ProjectID:      proj.ID,
+			OrganizationID: orgID,
+			StartDate:      now,
+			DueDate:        &dueDate,
+		})
+		if err != nil {
+			s.logger.Error("Failed to seed sample task", zap.String("title", t.Title), zap.Error(err))
+			continue
+		}
+		result.TaskIDs = append(result.TaskIDs, created.ID)
+	}
+
+	for _, h := range tmpl.Habits {
+		created, err := s.habitsService.CreateHabit(ctx, habits.CreateHabitInput{
+			Title:       h.Title,
+			Description: h.Description,
+			StartDay:    now,
+			UserID:      userID,
+		})
+		if err != nil {
+			s.logger.Error("Failed to seed sample habit", zap.String("title", h.Title), zap.Error(err))
+			continue
+		}
+		result.HabitIDs = append(result.HabitIDs, created.ID)
+	}
+
+	progress.ProjectID = &proj.ID
+	progress.SeededAt = &now
+	progress.markStep(StepSeedSamples)
+	if err := s.repo.Update(ctx, progress); err != nil {
+		return nil, nil, err
+	}
+
+	return progress, result, nil
+}
+
+// CompleteStep marks a wizard step as completed, e.g. when the user skips it
+func (s *service) CompleteStep(ctx context.Context, orgID, userID uuid.UUID, step Step) (*Progress, error) {
+	if !step.IsValid() {
+		return nil, ErrInvalidStep
+	}
+
+	progress, err := s.authorizedProgress(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress.markStep(step)
+	if step == StepFinish && progress.CompletedAt == nil {
+		now := time.Now()
+		progress.CompletedAt = &now
+	}
+
+	if err := s.repo.Update(ctx, progress); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// authorizedProgress loads the progress and checks that the user is running the wizard or owns the organization
+func (s *service) authorizedProgress(ctx context.Context, orgID, userID uuid.UUID) (*Progress, error) {
+	progress, err := s.repo.FindByOrganizationID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if progress.UserID == userID {
+		return progress, nil
+	}
+
+	org, err := s.orgService.GetOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org.OwnerID != userID {
+		return nil, ErrNotAuthorized
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,40 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// AdminDirectory looks up who administers an organization
+type AdminDirectory interface {
+	GetOrganization(ctx context.Context, id uuid.UUID) (*Organization, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]Member, error)
+	ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*Membership, error)
+}
+
+// Admins returns the owner of an organization and its members with the owner role
+func Admins(ctx context.Context, orgs AdminDirectory, orgID uuid.UUID) ([]uuid.UUID, error) {
+	org, err := orgs.GetOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := orgs.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	admins := []uuid.UUID{org.OwnerID}
+	for _, member := range members {
+		if member.UserID == org.OwnerID {
+			continue
+		}
+		membership, err := orgs.ResolveMembership(ctx, orgID, member.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if membership.Role == OwnerRole {
+			admins = append(admins, member.UserID)
+		}
+	}
+	return admins, nil
+}
@@ -0,0 +1,41 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/providers"
+	"github.com/google/uuid"
+)
+
+// aiSettingsKey is the organization setting holding the LLM provider choice, e.g.
+// {"ai": {"provider": "anthropic", "model": "claude-3-5-haiku-latest"}}
+const aiSettingsKey = "ai"
+
+// ProviderSettings reads the LLM provider settings of organizations
+type ProviderSettings struct {
+	service Service
+}
+
+// NewProviderSettings creates a provider settings source backed by organization settings
+func NewProviderSettings(service Service) *ProviderSettings {
+	return &ProviderSettings{service: service}
+}
+
+// ProviderSettings returns the provider and models the organization chose, empty when it
+// has not chosen any
+func (p *ProviderSettings) ProviderSettings(ctx context.Context, orgID uuid.UUID) (providers.Settings, error) {
+	org, err := p.service.GetOrganization(ctx, orgID)
+	if err != nil {
+		return providers.Settings{}, err
+	}
+	ai, _ := org.Settings[aiSettingsKey].(map[string]interface{})
+	str := func(key string) string {
+		value, _ := ai[key].(string)
+		return value
+	}
+	return providers.Settings{
+		Provider:       str("provider"),
+		Model:          str("model"),
+		EmbeddingModel: str("embedding_model"),
+	}, nil
+}
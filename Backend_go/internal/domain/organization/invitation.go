@@ -0,0 +1,267 @@
+package organization
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultInvitationTTL is how long an invitation can be accepted when no expiry is given
+	DefaultInvitationTTL = 7 * 24 * time.Hour
+	// MaxInvitationTTL bounds the expiry of an invitation
+	MaxInvitationTTL = 30 * 24 * time.Hour
+)
+
+// InvitationStatus is where an invitation stands
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+	InvitationExpired  InvitationStatus = "expired"
+)
+
+var (
+	ErrInvitationNotFound = NewError("invitation not found")
+	ErrInvalidInvitation  = NewError("invitation needs a valid email, an existing role and an expiry of at most 30 days")
+	ErrInvitationExists   = NewError("a pending invitation was already sent to this email")
+	ErrInvitationExpired  = NewError("invitation has expired")
+	ErrInvitationUsed     = NewError("invitation was already accepted or revoked")
+	ErrInvitationEmail    = NewError("invitation was sent to a different email address")
+)
+
+// Invitation invites someone to join an organization by email. Only a hash of the token
+// is stored; the token itself is returned once, when the invitation is created.
+type Invitation struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null;index:idx_org_invitation_email,priority:1"`
+	Email          string     `json:"email" gorm:"type:varchar(255);not null;index:idx_org_invitation_email,priority:2"`
+	Role           string     `json:"role" gorm:"type:varchar(50);not null"`
+	TokenHash      string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	InvitedBy      uuid.UUID  `json:"invited_by" gorm:"type:uuid;not null"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"not null"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedBy     *uuid.UUID `json:"accepted_by,omitempty" gorm:"type:uuid"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+
+	// Token is only set on a newly created invitation
+	Token  string           `json:"token,omitempty" gorm:"-"`
+	Status InvitationStatus `json:"status" gorm:"-"`
+}
+
+// TableName specifies the table name for the Invitation model
+func (Invitation) TableName() string {
+	return "organization_invitations"
+}
+
+// StatusAt returns the status of the invitation at a time
+func (i *Invitation) StatusAt(now time.Time) InvitationStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+// InvitationInput describes an invitation to create. An empty Role invites a member and
+// a zero ExpiresIn uses DefaultInvitationTTL.
+type InvitationInput struct {
+	Email     string
+	Role      string
+	ExpiresIn time.Duration
+	// DryRun validates the invitation and returns it without a token, without storing or
+	// sending it
+	DryRun bool
+}
+
+// InvitationSender delivers invitations, typically by email. Errors are logged and do not
+// fail the invitation, since its token is also returned to the inviter.
+type InvitationSender interface {
+	SendInvitation(ctx context.Context, org *Organization, invitation *Invitation) error
+}
+
+// InvitationService manages invitations to organizations
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, orgID, invitedBy uuid.UUID, input InvitationInput) (*Invitation, error)
+	ListInvitations(ctx context.Context, orgID uuid.UUID) ([]Invitation, error)
+	RevokeInvitation(ctx context.Context, orgID, id uuid.UUID) error
+	// AcceptInvitation joins the user to the organization of the invitation. The user's
+	// email must be the one the invitation was sent to.
+	AcceptInvitation(ctx context.Context, token string, userID uuid.UUID, userEmail string) (*Member, error)
+}
+
+type invitationService struct {
+	repo         InvitationRepository
+	orgService   Service
+	rolesService roles.Service
+	sender       InvitationSender
+	logger       *zap.Logger
+}
+
+// NewInvitationService creates a new invitation service. sender may be nil, in which case
+// invitations are only shared through their token.
+func NewInvitationService(repo InvitationRepository, orgService Service, rolesService roles.Service, sender InvitationSender, logger *zap.Logger) InvitationService {
+	return &invitationService{
+		repo:         repo,
+		orgService:   orgService,
+		rolesService: rolesService,
+		sender:       sender,
+		logger:       logger,
+	}
+}
+
+func (s *invitationService) CreateInvitation(ctx context.Context, orgID, invitedBy uuid.UUID, input InvitationInput) (*Invitation, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(input.Email))
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+	if input.Role == "" {
+		input.Role = MemberRole
+	}
+	if input.ExpiresIn == 0 {
+		input.ExpiresIn = DefaultInvitationTTL
+	}
+	if input.ExpiresIn < 0 || input.ExpiresIn > MaxInvitationTTL {
+		return nil, ErrInvalidInvitation
+	}
+	if _, err := s.rolesService.GetRoleByName(ctx, input.Role); err != nil {
+		if errors.Is(err, roles.ErrRoleNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	org, err := s.orgService.GetOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	emailAddress := strings.ToLower(address.Address)
+	pending, err := s.repo.FindPending(ctx, orgID, emailAddress, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, ErrInvitationExists
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &Invitation{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Email:          emailAddress,
+		Role:           input.Role,
+		TokenHash:      hashInvitationToken(token),
+		InvitedBy:      invitedBy,
+		ExpiresAt:      time.Now().Add(input.ExpiresIn),
+		CreatedAt:      time.Now(),
+	}
+	if input.DryRun {
+		invitation.TokenHash = ""
+		invitation.Status = InvitationPending
+		return invitation, nil
+	}
+	if err := s.repo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+	invitation.Token = token
+	invitation.Status = InvitationPending
+
+	if s.sender != nil {
+		if err := s.sender.SendInvitation(ctx, org, invitation); err != nil {
+			tracing.Logger(ctx, s.logger).Warn("Failed to send organization invitation",
+				zap.String("invitation_id", invitation.ID.String()), zap.Error(err))
+		}
+	}
+	return invitation, nil
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context, orgID uuid.UUID) ([]Invitation, error) {
+	invitations, err := s.repo.ListByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range invitations {
+		invitations[i].Status = invitations[i].StatusAt(now)
+	}
+	return invitations, nil
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, orgID, id uuid.UUID) error {
+	invitation, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if invitation.OrganizationID != orgID {
+		return ErrInvitationNotFound
+	}
+	return s.repo.Revoke(ctx, id)
+}
+
+func (s *invitationService) AcceptInvitation(ctx context.Context, token string, userID uuid.UUID, userEmail string) (*Member, error) {
+	invitation, err := s.repo.FindByTokenHash(ctx, hashInvitationToken(strings.TrimSpace(token)))
+	if err != nil {
+		return nil, err
+	}
+	switch invitation.StatusAt(time.Now()) {
+	case InvitationExpired:
+		return nil, ErrInvitationExpired
+	case InvitationAccepted, InvitationRevoked:
+		return nil, ErrInvitationUsed
+	}
+	if !strings.EqualFold(invitation.Email, strings.TrimSpace(userEmail)) {
+		return nil, ErrInvitationEmail
+	}
+
+	// Claim the invitation first so it is used once even when accepted twice at a time
+	if err := s.repo.MarkAccepted(ctx, invitation.ID, userID); err != nil {
+		return nil, err
+	}
+	member, err := s.orgService.AddMember(ctx, invitation.OrganizationID, userID, invitation.Role)
+	if err != nil {
+		if !errors.Is(err, ErrMemberExists) {
+			if releaseErr := s.repo.ReleaseAccepted(ctx, invitation.ID); releaseErr != nil {
+				tracing.Logger(ctx, s.logger).Error("Failed to release organization invitation",
+					zap.String("invitation_id", invitation.ID.String()), zap.Error(releaseErr))
+			}
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// newInvitationToken returns a random URL-safe token
+func newInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
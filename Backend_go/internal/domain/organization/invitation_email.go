@@ -0,0 +1,24 @@
+package organization
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/email"
+)
+
+// emailInvitationSender emails invitations with a link to accept them in the web app
+type emailInvitationSender struct {
+	mailer *email.Mailer
+}
+
+// NewEmailInvitationSender creates an InvitationSender that emails invitations
+func NewEmailInvitationSender(mailer *email.Mailer) InvitationSender {
+	return &emailInvitationSender{mailer: mailer}
+}
+
+func (s *emailInvitationSender) SendInvitation(ctx context.Context, org *Organization, invitation *Invitation) error {
+	acceptURL := s.mailer.URL("/invites/accept?token=" + url.QueryEscape(invitation.Token))
+	return s.mailer.SendOrganizationInvitation(ctx, email.Address{Email: invitation.Email},
+		org.Name, invitation.Role, acceptURL, invitation.ExpiresAt)
+}
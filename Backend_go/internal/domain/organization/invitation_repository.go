@@ -0,0 +1,105 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InvitationRepository defines the interface for invitation data access
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *Invitation) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Invitation, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error)
+	// FindPending returns the invitation to an email that can still be accepted, or nil
+	FindPending(ctx context.Context, orgID uuid.UUID, email string, now time.Time) (*Invitation, error)
+	ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]Invitation, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// MarkAccepted claims a pending invitation for a user. It fails with ErrInvitationUsed
+	// when the invitation was accepted or revoked meanwhile.
+	MarkAccepted(ctx context.Context, id, userID uuid.UUID) error
+	// ReleaseAccepted makes a claimed invitation pending again
+	ReleaseAccepted(ctx context.Context, id uuid.UUID) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository creates a new invitation repository
+func NewInvitationRepository(db *connection.Database) InvitationRepository {
+	return &invitationRepository{db: db.DB}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *Invitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+func (r *invitationRepository) FindByID(ctx context.Context, id uuid.UUID) (*Invitation, error) {
+	return r.first(r.db.WithContext(ctx).Where("id = ?", id))
+}
+
+func (r *invitationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error) {
+	return r.first(r.db.WithContext(ctx).Where("token_hash = ?", tokenHash))
+}
+
+func (r *invitationRepository) first(query *gorm.DB) (*Invitation, error) {
+	var invitation Invitation
+	if err := query.First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) FindPending(ctx context.Context, orgID uuid.UUID, email string, now time.Time) (*Invitation, error) {
+	invitation, err := r.first(r.db.WithContext(ctx).
+		Where("organization_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", orgID, email, now))
+	if errors.Is(err, ErrInvitationNotFound) {
+		return nil, nil
+	}
+	return invitation, err
+}
+
+func (r *invitationRepository) ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]Invitation, error) {
+	var invitations []Invitation
+	err := r.db.WithContext(ctx).Where("organization_id = ?", orgID).Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvitationUsed
+	}
+	return nil
+}
+
+func (r *invitationRepository) MarkAccepted(ctx context.Context, id, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		UpdateColumns(map[string]interface{}{"accepted_at": time.Now(), "accepted_by": userID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvitationUsed
+	}
+	return nil
+}
+
+func (r *invitationRepository) ReleaseAccepted(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&Invitation{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"accepted_at": nil, "accepted_by": nil}).Error
+}
@@ -45,6 +45,11 @@ type Organization struct {
 	OwnerID     uuid.UUID              `json:"owner_id" gorm:"type:uuid;not null"`
 	Settings    map[string]interface{} `json:"settings,omitempty" gorm:"type:jsonb"`
 	Preferences map[string]interface{} `json:"preferences,omitempty" gorm:"type:jsonb"`
+	// DeletionScheduledAt is when the organization and all its data are purged. Until
+	// then the organization is read-only and its owner can cancel the deletion.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" gorm:"index"`
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"`
+	DeletionRequestedBy *uuid.UUID `json:"deletion_requested_by,omitempty" gorm:"type:uuid"`
 }
 
 // TableName specifies the table name for the Organization model
@@ -88,6 +93,50 @@ func (o *Organization) BeforeUpdate(tx *gorm.DB) error {
 	return o.Validate()
 }
 
+// Role names given to members by default. The roles and their permissions are
+// managed by the roles service.
+const (
+	OwnerRole  = "admin"
+	MemberRole = "user"
+)
+
+// Member is a user's membership in an organization, with the role that decides
+// what the user may do inside it
+type Member struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex:idx_org_member,priority:1"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_org_member,priority:2;index:idx_org_member_user"`
+	RoleID         uuid.UUID `json:"role_id" gorm:"type:uuid;not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Member model
+func (Member) TableName() string {
+	return "organization_members"
+}
+
+// Membership is a member's resolved role and permissions in an organization
+type Membership struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	RoleID         uuid.UUID `json:"role_id"`
+	Role           string    `json:"role"`
+	Permissions    []string  `json:"permissions"`
+	// DeletionScheduledAt is set while the organization is read-only awaiting deletion
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// HasPermission reports whether the membership grants a permission
+func (m *Membership) HasPermission(permission string) bool {
+	for _, p := range m.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 // Common errors
 var (
 	ErrOrganizationNotFound = NewError("organization not found")
@@ -96,6 +145,10 @@ var (
 	ErrDuplicateName        = NewError("organization name already exists")
 	ErrInvalidCreator       = NewError("invalid creator ID")
 	ErrInvalidOwner         = NewError("invalid owner ID")
+	ErrNotMember            = NewError("user is not a member of this organization")
+	ErrMemberExists         = NewError("user is already a member of this organization")
+	ErrCannotRemoveOwner    = NewError("the organization owner cannot be removed")
+	ErrPendingDeletion      = NewError("the organization is scheduled for deletion and is read-only")
 )
 
 // Error represents a domain error
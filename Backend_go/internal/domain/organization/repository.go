@@ -2,12 +2,17 @@ package organization
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
 // Repository defines the interface for organization data access
 type Repository interface {
 	Create(ctx context.Context, org *Organization) error
@@ -16,6 +21,16 @@ type Repository interface {
 	Update(ctx context.Context, org *Organization) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	FindByName(ctx context.Context, name string) (*Organization, error)
+
+	// Membership operations
+	AddMember(ctx context.Context, member *Member) error
+	// TryAddMember inserts a membership in a transaction that is rolled back, returning
+	// the error AddMember would fail with
+	TryAddMember(ctx context.Context, member *Member) error
+	UpdateMember(ctx context.Context, member *Member) error
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+	FindMember(ctx context.Context, orgID, userID uuid.UUID) (*Member, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]Member, error)
 }
 
 // OrganizationFilter represents the filter options for listing organizations
@@ -116,3 +131,86 @@ func (r *repository) FindByName(ctx context.Context, name string) (*Organization
 	}
 	return &org, nil
 }
+
+// AddMember adds a user to an organization
+func (r *repository) AddMember(ctx context.Context, member *Member) error {
+	return addMember(r.db.WithContext(ctx), member)
+}
+
+// TryAddMember inserts a membership and rolls it back
+func (r *repository) TryAddMember(ctx context.Context, member *Member) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := addMember(tx, member); err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
+
+// addMember inserts a membership, reporting a duplicate as ErrMemberExists
+func addMember(db *gorm.DB, member *Member) error {
+	result := db.Create(member)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) || strings.Contains(result.Error.Error(), "SQLSTATE 23505") {
+			return ErrMemberExists
+		}
+		return result.Error
+	}
+	return nil
+}
+
+// UpdateMember saves changes to a membership
+func (r *repository) UpdateMember(ctx context.Context, member *Member) error {
+	result := r.db.WithContext(ctx).Save(member)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// RemoveMember removes a user from an organization
+func (r *repository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Delete(&Member{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// FindMember retrieves a user's membership in an organization, or nil if there is none
+func (r *repository) FindMember(ctx context.Context, orgID, userID uuid.UUID) (*Member, error) {
+	var member Member
+	result := r.db.WithContext(ctx).First(&member, "organization_id = ? AND user_id = ?", orgID, userID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &member, nil
+}
+
+// ListMembers retrieves the members of an organization, oldest first
+func (r *repository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]Member, error) {
+	var members []Member
+	result := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("created_at ASC").
+		Find(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
@@ -0,0 +1,34 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// searchSettingsKey is the organization setting controlling search, e.g.
+// {"search": {"audit": true}} to keep an audit trail of the searches run in it. Its
+// "language" sets the stemming and stop words of the organization's tasks and projects;
+// the search repository reads it in SQL, since it applies to rows as they are indexed.
+const searchSettingsKey = "search"
+
+// SearchSettings reads the search settings of organizations
+type SearchSettings struct {
+	service Service
+}
+
+// NewSearchSettings creates a search settings source backed by organization settings
+func NewSearchSettings(service Service) *SearchSettings {
+	return &SearchSettings{service: service}
+}
+
+// SearchAuditEnabled reports whether the organization records the searches of its members
+func (s *SearchSettings) SearchAuditEnabled(ctx context.Context, orgID uuid.UUID) (bool, error) {
+	org, err := s.service.GetOrganization(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+	settings, _ := org.Settings[searchSettingsKey].(map[string]interface{})
+	enabled, _ := settings["audit"].(bool)
+	return enabled, nil
+}
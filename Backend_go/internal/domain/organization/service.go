@@ -3,6 +3,8 @@ package organization
 import (
 	"context"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
 	"github.com/google/uuid"
 )
 
@@ -30,15 +32,27 @@ type Service interface {
 	UpdateOrganization(ctx context.Context, id uuid.UUID, input UpdateOrganizationInput) (*Organization, error)
 	DeleteOrganization(ctx context.Context, id uuid.UUID) error
 	GetOrganizationByName(ctx context.Context, name string) (*Organization, error)
+
+	// Membership
+	ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*Membership, error)
+	AddMember(ctx context.Context, orgID, userID uuid.UUID, roleName string) (*Member, error)
+	// CheckAddMember runs AddMember without keeping the membership, returning the error
+	// it would fail with
+	CheckAddMember(ctx context.Context, orgID, userID uuid.UUID, roleName string) error
+	UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, roleName string) (*Member, error)
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]Member, error)
 }
 
 type service struct {
-	repo Repository
+	repo         Repository
+	rolesService roles.Service
+	auditor      audit.Auditor
 }
 
 // NewService creates a new organization service instance
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, rolesService roles.Service, auditor audit.Auditor) Service {
+	return &service{repo: repo, rolesService: rolesService, auditor: auditor}
 }
 
 // CreateOrganization creates a new organization
@@ -82,6 +96,10 @@ func (s *service) CreateOrganization(ctx context.Context, input CreateOrganizati
 		return nil, err
 	}
 
+	// The owner resolves as a member even without a membership row, so a failure
+	// here does not lock them out
+	_, _ = s.AddMember(ctx, org.ID, org.OwnerID, OwnerRole)
+
 	return org, nil
 }
 
@@ -114,6 +132,9 @@ func (s *service) UpdateOrganization(ctx context.Context, id uuid.UUID, input Up
 	if err != nil {
 		return nil, err
 	}
+	if org.DeletionScheduledAt != nil {
+		return nil, ErrPendingDeletion
+	}
 
 	// Check name uniqueness if name is being updated
 	if input.Name != nil && *input.Name != org.Name {
@@ -181,3 +202,150 @@ func (s *service) GetOrganizationByName(ctx context.Context, name string) (*Orga
 
 	return org, nil
 }
+
+// ResolveMembership returns a user's role and permissions in an organization. The
+// owner always resolves, with the owner role if they have no membership of their own.
+func (s *service) ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*Membership, error) {
+	org, err := s.repo.FindByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.FindMember(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var role *roles.Role
+	switch {
+	case member != nil:
+		role, err = s.rolesService.GetRole(ctx, member.RoleID)
+	case org.OwnerID == userID:
+		role, err = s.rolesService.GetRoleByName(ctx, OwnerRole)
+	default:
+		return nil, ErrNotMember
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.rolesService.GetRolePermissions(ctx, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(permissions))
+	for i, p := range permissions {
+		names[i] = p.Name
+	}
+
+	return &Membership{
+		OrganizationID:      orgID,
+		UserID:              userID,
+		RoleID:              role.ID,
+		Role:                role.Name,
+		Permissions:         names,
+		DeletionScheduledAt: org.DeletionScheduledAt,
+	}, nil
+}
+
+// AddMember adds a user to an organization with the named role
+func (s *service) AddMember(ctx context.Context, orgID, userID uuid.UUID, roleName string) (*Member, error) {
+	member, err := s.newMember(ctx, orgID, userID, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// CheckAddMember adds the member in a transaction that is rolled back
+func (s *service) CheckAddMember(ctx context.Context, orgID, userID uuid.UUID, roleName string) error {
+	member, err := s.newMember(ctx, orgID, userID, roleName)
+	if err != nil {
+		return err
+	}
+	return s.repo.TryAddMember(ctx, member)
+}
+
+// newMember checks that a user can join an organization with the named role
+func (s *service) newMember(ctx context.Context, orgID, userID uuid.UUID, roleName string) (*Member, error) {
+	if userID == uuid.Nil {
+		return nil, ErrInvalidInput
+	}
+	org, err := s.repo.FindByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org.DeletionScheduledAt != nil {
+		return nil, ErrPendingDeletion
+	}
+	role, err := s.rolesService.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Member{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		UserID:         userID,
+		RoleID:         role.ID,
+	}, nil
+}
+
+// UpdateMemberRole changes the role of an existing member
+func (s *service) UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, roleName string) (*Member, error) {
+	member, err := s.repo.FindMember(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotMember
+	}
+	role, err := s.rolesService.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	previousRoleID := member.RoleID
+	member.RoleID = role.ID
+	if err := s.repo.UpdateMember(ctx, member); err != nil {
+		return nil, err
+	}
+
+	if s.auditor != nil && previousRoleID != role.ID {
+		metadata := map[string]interface{}{"role": role.Name, "previous_role_id": previousRoleID}
+		if previous, err := s.rolesService.GetRole(ctx, previousRoleID); err == nil && previous != nil {
+			metadata["previous_role"] = previous.Name
+		}
+		s.auditor.Audit(ctx, audit.Event{
+			OrganizationID: orgID,
+			Action:         audit.ActionMemberRoleChanged,
+			TargetType:     "user",
+			TargetID:       &userID,
+			Metadata:       metadata,
+		})
+	}
+	return member, nil
+}
+
+// RemoveMember removes a user from an organization. The owner cannot be removed.
+func (s *service) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	org, err := s.repo.FindByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if org.OwnerID == userID {
+		return ErrCannotRemoveOwner
+	}
+	return s.repo.RemoveMember(ctx, orgID, userID)
+}
+
+// ListMembers retrieves the members of an organization
+func (s *service) ListMembers(ctx context.Context, orgID uuid.UUID) ([]Member, error) {
+	if _, err := s.repo.FindByID(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, orgID)
+}
@@ -0,0 +1,106 @@
+package orgunits
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxDepth is how many levels of units an organization may nest
+const MaxDepth = 8
+
+// Common errors
+var (
+	ErrUnitNotFound  = errors.New("organizational unit not found")
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrDuplicateName = errors.New("a unit with this name already exists under the same parent")
+	ErrCycle         = errors.New("a unit cannot be moved under itself or one of its sub-units")
+	ErrTooDeep       = errors.New("organizational units cannot be nested this deep")
+	ErrNotMember     = errors.New("user is not a member of this unit")
+	// ErrRoleTooBroad is returned when a role grants permissions the caller does not hold
+	ErrRoleTooBroad = errors.New("the role grants permissions you do not hold")
+)
+
+// Unit is a department, team or other part of an organization. Units nest: a unit without
+// a parent sits directly under the organization.
+type Unit struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null;index:idx_org_unit_org"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid;index:idx_org_unit_parent"`
+	Name           string     `json:"name" gorm:"type:varchar(255);not null"`
+	Description    string     `json:"description" gorm:"type:text"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Unit model
+func (Unit) TableName() string {
+	return "organization_units"
+}
+
+// Member gives a user a role in a unit. The role's permissions apply in the unit and in
+// every unit under it, on top of what the user's role in the organization grants.
+type Member struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;index:idx_org_unit_member_org"`
+	UnitID         uuid.UUID `json:"unit_id" gorm:"type:uuid;not null;uniqueIndex:idx_org_unit_member,priority:1"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_org_unit_member,priority:2;index:idx_org_unit_member_user"`
+	RoleID         uuid.UUID `json:"role_id" gorm:"type:uuid;not null"`
+	Role           string    `json:"role" gorm:"-"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Member model
+func (Member) TableName() string {
+	return "organization_unit_members"
+}
+
+// Node is a unit with the units under it
+type Node struct {
+	Unit
+	Children []*Node `json:"children"`
+}
+
+// Counts sums up the work of one or more units
+type Counts struct {
+	Projects      int64            `json:"projects"`
+	Members       int64            `json:"members"`
+	Tasks         int64            `json:"tasks"`
+	TasksByStatus map[string]int64 `json:"tasks_by_status"`
+}
+
+// Report rolls up the work of a unit: Own counts the projects and members of the unit
+// itself, Total adds those of every unit under it. A user in several of the units is
+// counted once in Total.
+type Report struct {
+	UnitID   uuid.UUID `json:"unit_id"`
+	Name     string    `json:"name"`
+	Own      Counts    `json:"own"`
+	Total    Counts    `json:"total"`
+	Children []*Report `json:"children"`
+}
+
+// TaskCount is how many tasks in the projects of a unit have a status
+type TaskCount struct {
+	UnitID uuid.UUID
+	Status string
+	Count  int64
+}
+
+// CreateUnitInput holds the fields of a new unit
+type CreateUnitInput struct {
+	ParentID    *uuid.UUID
+	Name        string
+	Description string
+}
+
+// UpdateUnitInput holds the fields to change. Set MoveToRoot to move the unit directly
+// under the organization, or ParentID to move it under another unit.
+type UpdateUnitInput struct {
+	Name        *string
+	Description *string
+	ParentID    *uuid.UUID
+	MoveToRoot  bool
+}
@@ -0,0 +1,179 @@
+package orgunits
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for organizational unit data access
+type Repository interface {
+	CreateUnit(ctx context.Context, unit *Unit) error
+	UpdateUnit(ctx context.Context, unit *Unit) error
+	// DeleteUnit removes a unit and its members. Its sub-units and projects move up to
+	// its parent.
+	DeleteUnit(ctx context.Context, unit *Unit) error
+	// ListUnits lists every unit of an organization
+	ListUnits(ctx context.Context, organizationID uuid.UUID) ([]Unit, error)
+
+	// SaveMember adds a member to a unit, or changes the role of an existing one
+	SaveMember(ctx context.Context, member *Member) error
+	RemoveMember(ctx context.Context, unitID, userID uuid.UUID) error
+	ListMembers(ctx context.Context, unitID uuid.UUID) ([]Member, error)
+	// ListOrganizationMembers lists the members of every unit of an organization
+	ListOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]Member, error)
+	// ListUserMembers lists the memberships of a user in the given units
+	ListUserMembers(ctx context.Context, userID uuid.UUID, unitIDs []uuid.UUID) ([]Member, error)
+
+	// ProjectUnit returns the unit of a project of the organization, nil if the project is
+	// in no unit, or ErrUnitNotFound if the organization has no such project
+	ProjectUnit(ctx context.Context, organizationID, projectID uuid.UUID) (*uuid.UUID, error)
+	// CountProjects counts the projects of each unit of an organization
+	CountProjects(ctx context.Context, organizationID uuid.UUID) (map[uuid.UUID]int64, error)
+	// CountTasks counts the tasks in the projects of each unit by status
+	CountTasks(ctx context.Context, organizationID uuid.UUID) ([]TaskCount, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new organizational unit repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) CreateUnit(ctx context.Context, unit *Unit) error {
+	return r.db.WithContext(ctx).Create(unit).Error
+}
+
+func (r *repository) UpdateUnit(ctx context.Context, unit *Unit) error {
+	return r.db.WithContext(ctx).Save(unit).Error
+}
+
+func (r *repository) DeleteUnit(ctx context.Context, unit *Unit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Unit{}).
+			Where("organization_id = ? AND parent_id = ?", unit.OrganizationID, unit.ID).
+			Update("parent_id", unit.ParentID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Table("projects").
+			Where("organization_id = ? AND unit_id = ?", unit.OrganizationID, unit.ID).
+			Update("unit_id", unit.ParentID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("unit_id = ?", unit.ID).Delete(&Member{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Unit{}, "id = ?", unit.ID).Error
+	})
+}
+
+func (r *repository) ListUnits(ctx context.Context, organizationID uuid.UUID) ([]Unit, error) {
+	var units []Unit
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("name ASC").
+		Find(&units).Error
+	return units, err
+}
+
+func (r *repository) SaveMember(ctx context.Context, member *Member) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "unit_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role_id", "updated_at"}),
+	}).Create(member).Error
+}
+
+func (r *repository) RemoveMember(ctx context.Context, unitID, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("unit_id = ? AND user_id = ?", unitID, userID).Delete(&Member{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotMember
+	}
+	return nil
+}
+
+func (r *repository) ListMembers(ctx context.Context, unitID uuid.UUID) ([]Member, error) {
+	var members []Member
+	err := r.db.WithContext(ctx).
+		Where("unit_id = ?", unitID).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+func (r *repository) ListOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]Member, error) {
+	var members []Member
+	err := r.db.WithContext(ctx).Where("organization_id = ?", organizationID).Find(&members).Error
+	return members, err
+}
+
+func (r *repository) ListUserMembers(ctx context.Context, userID uuid.UUID, unitIDs []uuid.UUID) ([]Member, error) {
+	if len(unitIDs) == 0 {
+		return nil, nil
+	}
+	var members []Member
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND unit_id IN ?", userID, unitIDs).
+		Find(&members).Error
+	return members, err
+}
+
+func (r *repository) ProjectUnit(ctx context.Context, organizationID, projectID uuid.UUID) (*uuid.UUID, error) {
+	var row struct {
+		UnitID *uuid.UUID
+	}
+	err := r.db.WithContext(ctx).Table("projects").
+		Select("unit_id").
+		Where("id = ? AND organization_id = ? AND deleted_at IS NULL", projectID, organizationID).
+		Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUnitNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row.UnitID, nil
+}
+
+func (r *repository) CountProjects(ctx context.Context, organizationID uuid.UUID) (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		UnitID uuid.UUID
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Table("projects").
+		Select("unit_id, COUNT(*) AS count").
+		Where("organization_id = ? AND unit_id IS NOT NULL AND deleted_at IS NULL", organizationID).
+		Group("unit_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.UnitID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *repository) CountTasks(ctx context.Context, organizationID uuid.UUID) ([]TaskCount, error) {
+	var counts []TaskCount
+	err := r.db.WithContext(ctx).Table("tasks").
+		Select("projects.unit_id, tasks.status, COUNT(*) AS count").
+		Joins("JOIN projects ON projects.id = tasks.project_id").
+		Where("projects.organization_id = ? AND projects.unit_id IS NOT NULL", organizationID).
+		Where("projects.deleted_at IS NULL AND tasks.deleted_at IS NULL").
+		Group("projects.unit_id, tasks.status").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,504 @@
+package orgunits
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/google/uuid"
+)
+
+// Service defines the interface for organizational unit operations. Every call is
+// scoped to an organization: units of other organizations are not found.
+type Service interface {
+	CreateUnit(ctx context.Context, organizationID uuid.UUID, input CreateUnitInput) (*Unit, error)
+	GetUnit(ctx context.Context, organizationID, unitID uuid.UUID) (*Unit, error)
+	// ListUnits returns the units of the organization as a tree, top-level units first
+	ListUnits(ctx context.Context, organizationID uuid.UUID) ([]*Node, error)
+	UpdateUnit(ctx context.Context, organizationID, unitID uuid.UUID, input UpdateUnitInput) (*Unit, error)
+	// DeleteUnit removes a unit. Its sub-units and projects move up to its parent.
+	DeleteUnit(ctx context.Context, organizationID, unitID uuid.UUID) error
+
+	// SetMember gives a member of the organization the named role in a unit. granted are
+	// the caller's effective permissions in the unit; a role beyond them is refused.
+	SetMember(ctx context.Context, organizationID, unitID, userID uuid.UUID, roleName string, granted []string) (*Member, error)
+	RemoveMember(ctx context.Context, organizationID, unitID, userID uuid.UUID) error
+	ListMembers(ctx context.Context, organizationID, unitID uuid.UUID) ([]Member, error)
+
+	// UnitPermissions returns the permissions a user holds in a unit through roles in it
+	// or in the units above it
+	UnitPermissions(ctx context.Context, organizationID, unitID, userID uuid.UUID) ([]string, error)
+	// ProjectUnitPermissions returns the permissions a user holds in the unit of a
+	// project, none if the project is in no unit
+	ProjectUnitPermissions(ctx context.Context, organizationID, projectID, userID uuid.UUID) ([]string, error)
+
+	// ListProjects lists the projects of a unit, and of the units under it if asked to
+	ListProjects(ctx context.Context, organizationID, unitID uuid.UUID, includeSubUnits bool, page, pageSize int) ([]project.Project, int64, error)
+	// MoveProject moves a project into a unit, or out of any unit when unitID is nil
+	MoveProject(ctx context.Context, organizationID, projectID uuid.UUID, unitID *uuid.UUID) (*project.Project, error)
+	// Report rolls up the projects, tasks and members of a unit and the units under it
+	Report(ctx context.Context, organizationID, unitID uuid.UUID) (*Report, error)
+}
+
+// MembershipResolver checks that a user belongs to an organization
+type MembershipResolver interface {
+	ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*organization.Membership, error)
+}
+
+type service struct {
+	repo        Repository
+	projects    project.Service
+	roles       roles.Service
+	memberships MembershipResolver
+}
+
+// NewService creates a new organizational unit service
+func NewService(repo Repository, projects project.Service, rolesService roles.Service, memberships MembershipResolver) Service {
+	return &service{
+		repo:        repo,
+		projects:    projects,
+		roles:       rolesService,
+		memberships: memberships,
+	}
+}
+
+// tree indexes the units of an organization by ID and by parent. Top-level units are
+// the children of uuid.Nil.
+type tree struct {
+	units    map[uuid.UUID]*Unit
+	children map[uuid.UUID][]*Unit
+}
+
+func (s *service) loadTree(ctx context.Context, organizationID uuid.UUID) (*tree, error) {
+	units, err := s.repo.ListUnits(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	t := &tree{
+		units:    make(map[uuid.UUID]*Unit, len(units)),
+		children: make(map[uuid.UUID][]*Unit),
+	}
+	for i := range units {
+		unit := &units[i]
+		t.units[unit.ID] = unit
+		parent := uuid.Nil
+		if unit.ParentID != nil {
+			parent = *unit.ParentID
+		}
+		t.children[parent] = append(t.children[parent], unit)
+	}
+	return t, nil
+}
+
+// ancestors returns the unit and the units above it, nearest first
+func (t *tree) ancestors(id uuid.UUID) []uuid.UUID {
+	var ids []uuid.UUID
+	for unit := t.units[id]; unit != nil && len(ids) <= MaxDepth; {
+		ids = append(ids, unit.ID)
+		if unit.ParentID == nil {
+			break
+		}
+		unit = t.units[*unit.ParentID]
+	}
+	return ids
+}
+
+// descendants returns the unit and every unit under it
+func (t *tree) descendants(id uuid.UUID) []uuid.UUID {
+	ids := []uuid.UUID{id}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range t.children[ids[i]] {
+			ids = append(ids, child.ID)
+		}
+	}
+	return ids
+}
+
+// height counts the levels of the unit and the units under it
+func (t *tree) height(id uuid.UUID) int {
+	highest := 0
+	for _, child := range t.children[id] {
+		if h := t.height(child.ID); h > highest {
+			highest = h
+		}
+	}
+	return highest + 1
+}
+
+// nameTaken reports whether a unit other than except already has the name under parent
+func (t *tree) nameTaken(parent *uuid.UUID, name string, except uuid.UUID) bool {
+	key := uuid.Nil
+	if parent != nil {
+		key = *parent
+	}
+	for _, sibling := range t.children[key] {
+		if sibling.ID != except && strings.EqualFold(sibling.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *tree) node(unit *Unit) *Node {
+	node := &Node{Unit: *unit, Children: []*Node{}}
+	for _, child := range t.children[unit.ID] {
+		node.Children = append(node.Children, t.node(child))
+	}
+	return node
+}
+
+func (s *service) CreateUnit(ctx context.Context, organizationID uuid.UUID, input CreateUnitInput) (*Unit, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return nil, ErrInvalidInput
+	}
+	t, err := s.loadTree(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if input.ParentID != nil {
+		if t.units[*input.ParentID] == nil {
+			return nil, ErrUnitNotFound
+		}
+		if len(t.ancestors(*input.ParentID))+1 > MaxDepth {
+			return nil, ErrTooDeep
+		}
+	}
+	if t.nameTaken(input.ParentID, input.Name, uuid.Nil) {
+		return nil, ErrDuplicateName
+	}
+
+	now := time.Now()
+	unit := &Unit{
+		ID:             uuid.New(),
+		OrganizationID: organizationID,
+		ParentID:       input.ParentID,
+		Name:           input.Name,
+		Description:    input.Description,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.CreateUnit(ctx, unit); err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+func (s *service) GetUnit(ctx context.Context, organizationID, unitID uuid.UUID) (*Unit, error) {
+	t, err := s.loadTree(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	unit := t.units[unitID]
+	if unit == nil {
+		return nil, ErrUnitNotFound
+	}
+	return unit, nil
+}
+
+func (s *service) ListUnits(ctx context.Context, organizationID uuid.UUID) ([]*Node, error) {
+	t, err := s.loadTree(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*Node{}
+	for _, unit := range t.children[uuid.Nil] {
+		nodes = append(nodes, t.node(unit))
+	}
+	return nodes, nil
+}
+
+func (s *service) UpdateUnit(ctx context.Context, organizationID, unitID uuid.UUID, input UpdateUnitInput) (*Unit, error) {
+	t, err := s.loadTree(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	unit := t.units[unitID]
+	if unit == nil {
+		return nil, ErrUnitNotFound
+	}
+
+	parent := unit.ParentID
+	switch {
+	case input.MoveToRoot:
+		parent = nil
+	case input.ParentID != nil:
+		if t.units[*input.ParentID] == nil {
+			return nil, ErrUnitNotFound
+		}
+		for _, id := range t.descendants(unitID) {
+			if id == *input.ParentID {
+				return nil, ErrCycle
+			}
+		}
+		if len(t.ancestors(*input.ParentID))+t.height(unitID) > MaxDepth {
+			return nil, ErrTooDeep
+		}
+		parent = input.ParentID
+	}
+
+	name := unit.Name
+	if input.Name != nil {
+		name = strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, ErrInvalidInput
+		}
+	}
+	if t.nameTaken(parent, name, unitID) {
+		return nil, ErrDuplicateName
+	}
+
+	unit.Name = name
+	unit.ParentID = parent
+	if input.Description != nil {
+		unit.Description = *input.Description
+	}
+	unit.UpdatedAt = time.Now()
+	if err := s.repo.UpdateUnit(ctx, unit); err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+func (s *service) DeleteUnit(ctx context.Context, organizationID, unitID uuid.UUID) error {
+	unit, err := s.GetUnit(ctx, organizationID, unitID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteUnit(ctx, unit)
+}
+
+func (s *service) SetMember(ctx context.Context, organizationID, unitID, userID uuid.UUID, roleName string, granted []string) (*Member, error) {
+	if userID == uuid.Nil || roleName == "" {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.GetUnit(ctx, organizationID, unitID); err != nil {
+		return nil, err
+	}
+	// Unit roles add to an organization role, so only members of the organization get one
+	if _, err := s.memberships.ResolveMembership(ctx, organizationID, userID); err != nil {
+		return nil, err
+	}
+	role, err := s.roles.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	// Otherwise whoever may assign roles could hand themselves or others any permission
+	held := make(map[string]bool, len(granted))
+	for _, p := range granted {
+		held[p] = true
+	}
+	for _, p := range role.Permissions {
+		if !held[p.Name] {
+			return nil, fmt.Errorf("%w: %s", ErrRoleTooBroad, p.Name)
+		}
+	}
+
+	now := time.Now()
+	member := &Member{
+		ID:             uuid.New(),
+		OrganizationID: organizationID,
+		UnitID:         unitID,
+		UserID:         userID,
+		RoleID:         role.ID,
+		Role:           role.Name,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.SaveMember(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *service) RemoveMember(ctx context.Context, organizationID, unitID, userID uuid.UUID) error {
+	if _, err := s.GetUnit(ctx, organizationID, unitID); err != nil {
+		return err
+	}
+	return s.repo.RemoveMember(ctx, unitID, userID)
+}
+
+func (s *service) ListMembers(ctx context.Context, organizationID, unitID uuid.UUID) ([]Member, error) {
+	if _, err := s.GetUnit(ctx, organizationID, unitID); err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	roleNames := map[uuid.UUID]string{}
+	for i := range members {
+		name, ok := roleNames[members[i].RoleID]
+		if !ok {
+			if role, err := s.roles.GetRole(ctx, members[i].RoleID); err == nil && role != nil {
+				name = role.Name
+			}
+			roleNames[members[i].RoleID] = name
+		}
+		members[i].Role = name
+	}
+	return members, nil
+}
+
+func (s *service) UnitPermissions(ctx context.Context, organizationID, unitID, userID uuid.UUID) ([]string, error) {
+	t, err := s.loadTree(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if t.units[unitID] == nil {
+		return nil, ErrUnitNotFound
+	}
+	members, err := s.repo.ListUserMembers(ctx, userID, t.ancestors(unitID))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	seenRoles := map[uuid.UUID]bool{}
+	seen := map[string]bool{}
+	for _, member := range members {
+		if seenRoles[member.RoleID] {
+			continue
+		}
+		seenRoles[member.RoleID] = true
+		permissions, err := s.roles.GetRolePermissions(ctx, member.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range permissions {
+			if !seen[p.Name] {
+				seen[p.Name] = true
+				names = append(names, p.Name)
+			}
+		}
+	}
+	return names, nil
+}
+
+func (s *service) ProjectUnitPermissions(ctx context.Context, organizationID, projectID, userID uuid.UUID) ([]string, error) {
+	unitID, err := s.repo.ProjectUnit(ctx, organizationID, projectID)
+	if err != nil || unitID == nil {
+		return nil, err
+	}
+	return s.UnitPermissions(ctx, organizationID, *unitID, userID)
+}
+
+func (s *service) ListProjects(ctx context.Context, organizationID, unitID uuid.UUID, includeSubUnits bool, page, pageSize int) ([]project.Project, int64, error) {
+	t, err := s.loadTree(ctx, organizationID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if t.units[unitID] == nil {
+		return nil, 0, ErrUnitNotFound
+	}
+	unitIDs := []uuid.UUID{unitID}
+	if includeSubUnits {
+		unitIDs = t.descendants(unitID)
+	}
+	return s.projects.ListProjects(ctx, project.ProjectFilter{
+		Page:           page,
+		PageSize:       pageSize,
+		OrganizationID: &organizationID,
+		UnitIDs:        unitIDs,
+	})
+}
+
+func (s *service) MoveProject(ctx context.Context, organizationID, projectID uuid.UUID, unitID *uuid.UUID) (*project.Project, error) {
+	proj, err := s.projects.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if proj.OrganizationID != organizationID {
+		return nil, project.ErrProjectNotFound
+	}
+	if unitID != nil {
+		if _, err := s.GetUnit(ctx, organizationID, *unitID); err != nil {
+			return nil, err
+		}
+	}
+	return s.projects.MoveProjectToUnit(ctx, projectID, unitID)
+}
+
+func (s *service) Report(ctx context.Context, organizationID, unitID uuid.UUID) (*Report, error) {
+	t, err := s.loadTree(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	unit := t.units[unitID]
+	if unit == nil {
+		return nil, ErrUnitNotFound
+	}
+
+	projects, err := s.repo.CountProjects(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	taskCounts, err := s.repo.CountTasks(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	tasks := map[uuid.UUID]map[string]int64{}
+	for _, count := range taskCounts {
+		if tasks[count.UnitID] == nil {
+			tasks[count.UnitID] = map[string]int64{}
+		}
+		tasks[count.UnitID][count.Status] += count.Count
+	}
+	members, err := s.repo.ListOrganizationMembers(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	users := map[uuid.UUID][]uuid.UUID{}
+	for _, member := range members {
+		users[member.UnitID] = append(users[member.UnitID], member.UserID)
+	}
+
+	report, _ := t.report(unit, projects, tasks, users)
+	return report, nil
+}
+
+// report builds the report of a unit and returns it with the users of its subtree, so
+// that the parent can count each of them once
+func (t *tree) report(unit *Unit, projects map[uuid.UUID]int64, tasks map[uuid.UUID]map[string]int64, users map[uuid.UUID][]uuid.UUID) (*Report, map[uuid.UUID]bool) {
+	r := &Report{
+		UnitID:   unit.ID,
+		Name:     unit.Name,
+		Own:      Counts{Projects: projects[unit.ID], Members: int64(len(users[unit.ID])), TasksByStatus: map[string]int64{}},
+		Total:    Counts{TasksByStatus: map[string]int64{}},
+		Children: []*Report{},
+	}
+	for status, count := range tasks[unit.ID] {
+		r.Own.TasksByStatus[status] = count
+		r.Own.Tasks += count
+	}
+
+	subtreeUsers := map[uuid.UUID]bool{}
+	for _, userID := range users[unit.ID] {
+		subtreeUsers[userID] = true
+	}
+	r.Total.Projects = r.Own.Projects
+	r.Total.Tasks = r.Own.Tasks
+	for status, count := range r.Own.TasksByStatus {
+		r.Total.TasksByStatus[status] = count
+	}
+
+	for _, child := range t.children[unit.ID] {
+		childReport, childUsers := t.report(child, projects, tasks, users)
+		r.Children = append(r.Children, childReport)
+		r.Total.Projects += childReport.Total.Projects
+		r.Total.Tasks += childReport.Total.Tasks
+		for status, count := range childReport.Total.TasksByStatus {
+			r.Total.TasksByStatus[status] += count
+		}
+		for userID := range childUsers {
+			subtreeUsers[userID] = true
+		}
+	}
+	r.Total.Members = int64(len(subtreeUsers))
+	return r, subtreeUsers
+}
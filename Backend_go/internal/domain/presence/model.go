@@ -0,0 +1,96 @@
+package presence
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the online status of a user
+type Status string
+
+const (
+	StatusOnline  Status = "online"
+	StatusAway    Status = "away"
+	StatusOffline Status = "offline"
+)
+
+// Entity types that can be viewed
+const (
+	EntityTask    = "task"
+	EntityProject = "project"
+)
+
+// Event types published when presence changes
+const (
+	EventOnline  = "presence.online"
+	EventOffline = "presence.offline"
+	EventViewing = "presence.viewing"
+	EventLeft    = "presence.left"
+
+	EventEditing        = "presence.editing"
+	EventStoppedEditing = "presence.stopped_editing"
+)
+
+// Fields of an entity that support edit sessions
+const (
+	FieldDescription = "description"
+)
+
+// TTL is how long presence is kept without a heartbeat
+const TTL = 90 * time.Second
+
+var (
+	ErrInvalidEntity = errors.New("invalid presence entity")
+	ErrInvalidStatus = errors.New("invalid presence status")
+	ErrInvalidField  = errors.New("invalid editable field")
+)
+
+// Entity identifies something a user can have open
+type Entity struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// IsValid checks if the entity refers to a supported type
+func (e Entity) IsValid() bool {
+	return (e.Type == EntityTask || e.Type == EntityProject) && e.ID != uuid.Nil
+}
+
+// IsEditableField checks if the field supports edit sessions
+func IsEditableField(field string) bool {
+	return field == FieldDescription
+}
+
+// UserPresence is the presence state of a single user
+type UserPresence struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Status   Status    `json:"status"`
+	Viewing  *Entity   `json:"viewing,omitempty"`
+	Editing  string    `json:"editing,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Viewer is a user currently looking at an entity
+type Viewer struct {
+	UserID   uuid.UUID `json:"user_id"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Editor is a user with an open edit session on a field of an entity
+type Editor struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Field    string    `json:"field"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Event is broadcast to connected clients when presence changes
+type Event struct {
+	Type      string    `json:"type"`
+	UserID    uuid.UUID `json:"user_id"`
+	Status    Status    `json:"status,omitempty"`
+	Entity    *Entity   `json:"entity,omitempty"`
+	Field     string    `json:"field,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,515 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// EventChannel is the Redis channel presence changes are published on
+const EventChannel = "presence_events"
+
+// Service defines the interface for user presence tracking
+type Service interface {
+	Connect(ctx context.Context, userID uuid.UUID) error
+	Disconnect(ctx context.Context, userID uuid.UUID) error
+	Heartbeat(ctx context.Context, userID uuid.UUID) error
+	SetStatus(ctx context.Context, userID uuid.UUID, status Status) error
+	View(ctx context.Context, userID uuid.UUID, entity Entity) error
+	Leave(ctx context.Context, userID uuid.UUID) error
+	StartEditing(ctx context.Context, userID uuid.UUID, entity Entity, field string) error
+	StopEditing(ctx context.Context, userID uuid.UUID) error
+	GetPresence(ctx context.Context, userIDs []uuid.UUID) ([]UserPresence, error)
+	Viewers(ctx context.Context, entity Entity) ([]Viewer, error)
+	ViewersOf(ctx context.Context, entityType string, ids []uuid.UUID) (map[uuid.UUID][]Viewer, error)
+	Editors(ctx context.Context, entity Entity) ([]Editor, error)
+	Subscribe(ctx context.Context, callback func(*Event) error) error
+}
+
+type service struct {
+	redis  *redis.Client
+	logger *zap.Logger
+}
+
+// NewService creates a new presence service backed by Redis
+func NewService(redisClient *cache.RedisClient, logger *zap.Logger) Service {
+	return &service{
+		redis:  redisClient.GetClient(),
+		logger: logger,
+	}
+}
+
+func userKey(userID uuid.UUID) string {
+	return fmt.Sprintf("presence:user:%s", userID)
+}
+
+func connectionsKey(userID uuid.UUID) string {
+	return fmt.Sprintf("presence:connections:%s", userID)
+}
+
+func viewersKey(entity Entity) string {
+	return fmt.Sprintf("presence:viewers:%s:%s", entity.Type, entity.ID)
+}
+
+func editorsKey(entity Entity) string {
+	return fmt.Sprintf("presence:editors:%s:%s", entity.Type, entity.ID)
+}
+
+func editorMember(userID uuid.UUID, field string) string {
+	return userID.String() + "|" + field
+}
+
+// Connect marks the user online. Each open connection is counted so the user
+// only goes offline once the last one disconnects.
+func (s *service) Connect(ctx context.Context, userID uuid.UUID) error {
+	pipe := s.redis.TxPipeline()
+	pipe.Incr(ctx, connectionsKey(userID))
+	pipe.Expire(ctx, connectionsKey(userID), TTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	current, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	wasOnline := current != nil && current.Status != StatusOffline
+	if current == nil {
+		current = &UserPresence{UserID: userID}
+	}
+	current.Status = StatusOnline
+	if err := s.save(ctx, current); err != nil {
+		return err
+	}
+
+	if !wasOnline {
+		s.publish(ctx, &Event{Type: EventOnline, UserID: userID, Status: StatusOnline})
+	}
+	return nil
+}
+
+// Disconnect releases one connection and marks the user offline when none remain
+func (s *service) Disconnect(ctx context.Context, userID uuid.UUID) error {
+	remaining, err := s.redis.Decr(ctx, connectionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return nil
+	}
+
+	if err := s.Leave(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.redis.Del(ctx, userKey(userID), connectionsKey(userID)).Err(); err != nil {
+		return err
+	}
+
+	s.publish(ctx, &Event{Type: EventOffline, UserID: userID, Status: StatusOffline})
+	return nil
+}
+
+// Heartbeat extends the user's presence and the entity they are viewing
+func (s *service) Heartbeat(ctx context.Context, userID uuid.UUID) error {
+	current, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return s.Connect(ctx, userID)
+	}
+
+	if err := s.redis.Expire(ctx, connectionsKey(userID), TTL).Err(); err != nil {
+		return err
+	}
+	if current.Viewing != nil {
+		if err := s.touchViewer(ctx, *current.Viewing, userID); err != nil {
+			return err
+		}
+		if current.Editing != "" {
+			if err := s.touchEditor(ctx, *current.Viewing, userID, current.Editing); err != nil {
+				return err
+			}
+		}
+	}
+	return s.save(ctx, current)
+}
+
+// SetStatus changes the user's status, e.g. to away when the tab loses focus
+func (s *service) SetStatus(ctx context.Context, userID uuid.UUID, status Status) error {
+	if status != StatusOnline && status != StatusAway {
+		return ErrInvalidStatus
+	}
+
+	current, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		current = &UserPresence{UserID: userID}
+	}
+	current.Status = status
+	if err := s.save(ctx, current); err != nil {
+		return err
+	}
+
+	s.publish(ctx, &Event{Type: EventOnline, UserID: userID, Status: status})
+	return nil
+}
+
+// View records that the user has the entity open, leaving whatever they viewed before
+func (s *service) View(ctx context.Context, userID uuid.UUID, entity Entity) error {
+	if !entity.IsValid() {
+		return ErrInvalidEntity
+	}
+
+	current, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		current = &UserPresence{UserID: userID, Status: StatusOnline}
+	}
+	if current.Viewing != nil && *current.Viewing != entity {
+		if err := s.leaveEntity(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	if err := s.touchViewer(ctx, entity, userID); err != nil {
+		return err
+	}
+	current.Viewing = &entity
+	if err := s.save(ctx, current); err != nil {
+		return err
+	}
+
+	s.publish(ctx, &Event{Type: EventViewing, UserID: userID, Entity: &entity})
+	return nil
+}
+
+// Leave clears the entity the user is viewing
+func (s *service) Leave(ctx context.Context, userID uuid.UUID) error {
+	current, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Viewing == nil {
+		return nil
+	}
+
+	if err := s.leaveEntity(ctx, current); err != nil {
+		return err
+	}
+	return s.save(ctx, current)
+}
+
+// StartEditing opens an edit session on a field of the entity so other viewers
+// can be warned before they start a conflicting edit. The user is moved to
+// viewing the entity if they were not already.
+func (s *service) StartEditing(ctx context.Context, userID uuid.UUID, entity Entity, field string) error {
+	if !IsEditableField(field) {
+		return ErrInvalidField
+	}
+
+	current, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Viewing == nil || *current.Viewing != entity {
+		if err := s.View(ctx, userID, entity); err != nil {
+			return err
+		}
+		if current, err = s.get(ctx, userID); err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrInvalidEntity
+		}
+	}
+	if current.Editing == field {
+		return s.touchEditor(ctx, entity, userID, field)
+	}
+	if current.Editing != "" {
+		if err := s.stopEditing(ctx, userID, entity, current.Editing); err != nil {
+			return err
+		}
+	}
+
+	if err := s.touchEditor(ctx, entity, userID, field); err != nil {
+		return err
+	}
+	current.Editing = field
+	if err := s.save(ctx, current); err != nil {
+		return err
+	}
+
+	s.publish(ctx, &Event{Type: EventEditing, UserID: userID, Entity: &entity, Field: field})
+	return nil
+}
+
+// StopEditing closes the user's edit session, if any
+func (s *service) StopEditing(ctx context.Context, userID uuid.UUID) error {
+	current, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Viewing == nil || current.Editing == "" {
+		return nil
+	}
+
+	if err := s.stopEditing(ctx, userID, *current.Viewing, current.Editing); err != nil {
+		return err
+	}
+	current.Editing = ""
+	return s.save(ctx, current)
+}
+
+// GetPresence returns the presence of the given users; users without presence are reported offline
+func (s *service) GetPresence(ctx context.Context, userIDs []uuid.UUID) ([]UserPresence, error) {
+	if len(userIDs) == 0 {
+		return []UserPresence{}, nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = userKey(id)
+	}
+	values, err := s.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]UserPresence, len(userIDs))
+	for i, value := range values {
+		result[i] = UserPresence{UserID: userIDs[i], Status: StatusOffline}
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var p UserPresence
+		if err := json.Unmarshal([]byte(raw), &p); err == nil {
+			result[i] = p
+		}
+	}
+	return result, nil
+}
+
+// Viewers returns the users currently viewing the entity
+func (s *service) Viewers(ctx context.Context, entity Entity) ([]Viewer, error) {
+	if !entity.IsValid() {
+		return nil, ErrInvalidEntity
+	}
+
+	key := viewersKey(entity)
+	cutoff := strconv.FormatInt(time.Now().Add(-TTL).Unix(), 10)
+	if err := s.redis.ZRemRangeByScore(ctx, key, "-inf", "("+cutoff).Err(); err != nil {
+		return nil, err
+	}
+
+	members, err := s.redis.ZRangeWithScores(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return toViewers(members), nil
+}
+
+// ViewersOf returns the viewers of several entities of the same type, keyed by entity ID.
+// Entities without viewers are omitted. All entities are read in one round trip.
+func (s *service) ViewersOf(ctx context.Context, entityType string, ids []uuid.UUID) (map[uuid.UUID][]Viewer, error) {
+	result := make(map[uuid.UUID][]Viewer)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	cutoff := strconv.FormatInt(time.Now().Add(-TTL).Unix(), 10)
+	pipe := s.redis.Pipeline()
+	ranges := make([]*redis.ZSliceCmd, len(ids))
+	for i, id := range ids {
+		entity := Entity{Type: entityType, ID: id}
+		if !entity.IsValid() {
+			return nil, ErrInvalidEntity
+		}
+		key := viewersKey(entity)
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+cutoff)
+		ranges[i] = pipe.ZRangeWithScores(ctx, key, 0, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	for i, cmd := range ranges {
+		if viewers := toViewers(cmd.Val()); len(viewers) > 0 {
+			result[ids[i]] = viewers
+		}
+	}
+	return result, nil
+}
+
+// toViewers reads the members of a viewers set, skipping any that are not user IDs
+func toViewers(members []redis.Z) []Viewer {
+	viewers := make([]Viewer, 0, len(members))
+	for _, m := range members {
+		member, _ := m.Member.(string)
+		userID, err := uuid.Parse(member)
+		if err != nil {
+			continue
+		}
+		viewers = append(viewers, Viewer{
+			UserID:   userID,
+			LastSeen: time.Unix(int64(m.Score), 0).UTC(),
+		})
+	}
+	return viewers
+}
+
+// Editors returns the users with an open edit session on the entity
+func (s *service) Editors(ctx context.Context, entity Entity) ([]Editor, error) {
+	if !entity.IsValid() {
+		return nil, ErrInvalidEntity
+	}
+
+	key := editorsKey(entity)
+	cutoff := strconv.FormatInt(time.Now().Add(-TTL).Unix(), 10)
+	if err := s.redis.ZRemRangeByScore(ctx, key, "-inf", "("+cutoff).Err(); err != nil {
+		return nil, err
+	}
+
+	members, err := s.redis.ZRangeWithScores(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	editors := make([]Editor, 0, len(members))
+	for _, m := range members {
+		member, _ := m.Member.(string)
+		parts := strings.SplitN(member, "|", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		userID, err := uuid.Parse(parts[0])
+		if err != nil {
+			continue
+		}
+		editors = append(editors, Editor{
+			UserID:   userID,
+			Field:    parts[1],
+			LastSeen: time.Unix(int64(m.Score), 0).UTC(),
+		})
+	}
+	return editors, nil
+}
+
+// Subscribe delivers presence events to the callback until the context is cancelled
+func (s *service) Subscribe(ctx context.Context, callback func(*Event) error) error {
+	pubsub := s.redis.Subscribe(ctx, EventChannel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				tracing.Logger(ctx, s.logger).Warn("Discarding malformed presence event", zap.Error(err))
+				continue
+			}
+			if err := callback(&event); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *service) get(ctx context.Context, userID uuid.UUID) (*UserPresence, error) {
+	raw, err := s.redis.Get(ctx, userKey(userID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p UserPresence
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (s *service) save(ctx context.Context, p *UserPresence) error {
+	p.LastSeen = time.Now().UTC()
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(ctx, userKey(p.UserID), data, TTL).Err()
+}
+
+func (s *service) touchViewer(ctx context.Context, entity Entity, userID uuid.UUID) error {
+	key := viewersKey(entity)
+	pipe := s.redis.TxPipeline()
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(time.Now().Unix()), Member: userID.String()})
+	pipe.Expire(ctx, key, TTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *service) touchEditor(ctx context.Context, entity Entity, userID uuid.UUID, field string) error {
+	key := editorsKey(entity)
+	pipe := s.redis.TxPipeline()
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(time.Now().Unix()), Member: editorMember(userID, field)})
+	pipe.Expire(ctx, key, TTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *service) stopEditing(ctx context.Context, userID uuid.UUID, entity Entity, field string) error {
+	if err := s.redis.ZRem(ctx, editorsKey(entity), editorMember(userID, field)).Err(); err != nil {
+		return err
+	}
+	s.publish(ctx, &Event{Type: EventStoppedEditing, UserID: userID, Entity: &entity, Field: field})
+	return nil
+}
+
+// leaveEntity removes the user from the entity they are viewing, closing any edit session on it
+func (s *service) leaveEntity(ctx context.Context, p *UserPresence) error {
+	entity := *p.Viewing
+	if p.Editing != "" {
+		if err := s.stopEditing(ctx, p.UserID, entity, p.Editing); err != nil {
+			return err
+		}
+		p.Editing = ""
+	}
+	if err := s.redis.ZRem(ctx, viewersKey(entity), p.UserID.String()).Err(); err != nil {
+		return err
+	}
+	p.Viewing = nil
+	s.publish(ctx, &Event{Type: EventLeft, UserID: p.UserID, Entity: &entity})
+	return nil
+}
+
+func (s *service) publish(ctx context.Context, event *Event) {
+	event.Timestamp = time.Now().UTC()
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := s.redis.Publish(ctx, EventChannel, data).Err(); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to publish presence event", zap.String("type", event.Type), zap.Error(err))
+	}
+}
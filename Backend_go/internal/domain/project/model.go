@@ -39,6 +39,7 @@ type Project struct {
 	Description    string         `json:"description" gorm:"type:text"`
 	Status         ProjectStatus  `json:"status" gorm:"not null;default:'active';index:idx_project_status"`
 	OrganizationID uuid.UUID      `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex:idx_project_name_org,priority:1"`
+	UnitID         *uuid.UUID     `json:"unit_id,omitempty" gorm:"type:uuid;index:idx_project_unit"`
 	CreatorID      uuid.UUID      `json:"creator_id" gorm:"type:uuid;not null;index:idx_project_creator"`
 	OwnerID        uuid.UUID      `json:"owner_id" gorm:"type:uuid;not null;index:idx_project_owner"`
 	StartDate      time.Time      `json:"start_date" gorm:"not null;index:idx_project_dates"`
@@ -96,6 +97,8 @@ type ProjectFilter struct {
 	Name           *string        `validate:"omitempty,max=100"`
 	Status         *ProjectStatus `validate:"omitempty,oneof=active inactive archived"`
 	OrganizationID *uuid.UUID     `validate:"required"`
+	// UnitIDs limits the list to projects in one of the organizational units
+	UnitIDs []uuid.UUID
 }
 
 type ProjectMember struct {
@@ -19,6 +19,7 @@ type Repository interface {
 	FindByName(ctx context.Context, name string, organizationID uuid.UUID) (*Project, error)
 	AddMember(ctx context.Context, projectID uuid.UUID, userID uuid.UUID, role string) error
 	RemoveMember(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) error
+	MemberProjectIDs(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error)
 }
 
 type repository struct {
@@ -53,6 +54,9 @@ func (r *repository) FindAll(ctx context.Context, filter ProjectFilter) ([]Proje
 	if filter.OrganizationID != nil {
 		query = query.Where("organization_id = ?", filter.OrganizationID)
 	}
+	if len(filter.UnitIDs) > 0 {
+		query = query.Where("unit_id IN ?", filter.UnitIDs)
+	}
 	if filter.Status != nil {
 		query = query.Where("status = ?", filter.Status)
 	}
@@ -136,3 +140,13 @@ func (r *repository) RemoveMember(ctx context.Context, projectID uuid.UUID, user
 	}
 	return nil
 }
+
+// MemberProjectIDs lists the projects of an organization a user belongs to, owns or created
+func (r *repository) MemberProjectIDs(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&Project{}).
+		Where("organization_id = ?", organizationID).
+		Where("owner_id = ? OR creator_id = ? OR id IN (SELECT project_id FROM project_members WHERE user_id = ?)", userID, userID, userID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
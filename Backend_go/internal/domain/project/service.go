@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/activity"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
 	"github.com/google/uuid"
 )
 
@@ -20,14 +22,26 @@ type Service interface {
 	AddProjectMember(ctx context.Context, projectID uuid.UUID, userID uuid.UUID, role string) error
 	RemoveProjectMember(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) error
 	UpdateProjectStatus(ctx context.Context, id uuid.UUID, status ProjectStatus) (*Project, error)
+	// MoveProjectToUnit moves a project into an organizational unit, or out of any unit
+	// when unitID is nil. Callers check that the unit belongs to the project's organization.
+	MoveProjectToUnit(ctx context.Context, id uuid.UUID, unitID *uuid.UUID) (*Project, error)
 }
 
 type service struct {
-	repo Repository
+	repo     Repository
+	activity activity.Recorder
+	changes  cache.ChangeNotifier
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, activityRecorder activity.Recorder, changes cache.ChangeNotifier) Service {
+	return &service{repo: repo, activity: activityRecorder, changes: changes}
+}
+
+// projectsChanged drops cached project responses after a write
+func (s *service) projectsChanged(ctx context.Context) {
+	if s.changes != nil {
+		s.changes.EntityChanged(ctx, cache.EntityProjects)
+	}
 }
 
 func (s *service) CreateProject(ctx context.Context, input CreateProjectInput) (*Project, error) {
@@ -66,6 +80,7 @@ func (s *service) CreateProject(ctx context.Context, input CreateProjectInput) (
 	if err != nil {
 		return nil, err
 	}
+	s.projectsChanged(ctx)
 
 	return project, nil
 }
@@ -129,6 +144,7 @@ func (s *service) UpdateProject(ctx context.Context, id uuid.UUID, input UpdateP
 	if err != nil {
 		return nil, err
 	}
+	s.projectsChanged(ctx)
 
 	return project, nil
 }
@@ -142,7 +158,11 @@ func (s *service) DeleteProject(ctx context.Context, id uuid.UUID) error {
 		return ErrProjectNotFound
 	}
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.projectsChanged(ctx)
+	return nil
 }
 
 func (s *service) GetProjectDetails(ctx context.Context, id uuid.UUID) (*ProjectDetails, error) {
@@ -175,7 +195,13 @@ func (s *service) AddProjectMember(ctx context.Context, projectID uuid.UUID, use
 		return ErrProjectNotFound
 	}
 
-	return s.repo.AddMember(ctx, projectID, userID, role)
+	if err := s.repo.AddMember(ctx, projectID, userID, role); err != nil {
+		return err
+	}
+	s.projectsChanged(ctx)
+
+	s.recordMemberChange(ctx, project, userID, activity.EventMemberAdded, role)
+	return nil
 }
 
 func (s *service) RemoveProjectMember(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) error {
@@ -187,7 +213,36 @@ func (s *service) RemoveProjectMember(ctx context.Context, projectID uuid.UUID,
 		return ErrProjectNotFound
 	}
 
-	return s.repo.RemoveMember(ctx, projectID, userID)
+	if err := s.repo.RemoveMember(ctx, projectID, userID); err != nil {
+		return err
+	}
+	s.projectsChanged(ctx)
+
+	s.recordMemberChange(ctx, project, userID, activity.EventMemberRemoved, "")
+	return nil
+}
+
+// recordMemberChange appends a membership change to the project activity feed
+func (s *service) recordMemberChange(ctx context.Context, project *Project, userID uuid.UUID, eventType activity.EventType, role string) {
+	if s.activity == nil {
+		return
+	}
+
+	metadata := map[string]interface{}{"user_id": userID}
+	if role != "" {
+		metadata["role"] = role
+	}
+
+	projectID := project.ID
+	s.activity.Record(ctx, activity.RecordInput{
+		OrganizationID: project.OrganizationID,
+		ProjectID:      &projectID,
+		Type:           eventType,
+		EntityType:     "project_member",
+		EntityID:       userID,
+		Summary:        project.Name,
+		Metadata:       metadata,
+	})
 }
 
 func (s *service) UpdateProjectStatus(ctx context.Context, id uuid.UUID, status ProjectStatus) (*Project, error) {
@@ -210,6 +265,26 @@ func (s *service) UpdateProjectStatus(ctx context.Context, id uuid.UUID, status
 	if err != nil {
 		return nil, err
 	}
+	s.projectsChanged(ctx)
+
+	return project, nil
+}
+
+func (s *service) MoveProjectToUnit(ctx context.Context, id uuid.UUID, unitID *uuid.UUID) (*Project, error) {
+	project, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	project.UnitID = unitID
+	project.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, project); err != nil {
+		return nil, err
+	}
+	s.projectsChanged(ctx)
 
 	return project, nil
 }
@@ -0,0 +1,94 @@
+package projectcopy
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDuplicationNotFound = errors.New("project duplication not found")
+	ErrInvalidName         = errors.New("name must be between 3 and 100 characters")
+)
+
+// Status is where a duplication job stands
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	// StatusFailed means the copy could not be created, e.g. because the name was taken
+	// in the meantime. Error says why.
+	StatusFailed Status = "failed"
+)
+
+// Duplication is a copy of a project made in the background. The new project is
+// created first, with its members, and its tasks are copied in batches afterwards.
+type Duplication struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID  uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;index"`
+	SourceProjectID uuid.UUID `json:"source_project_id" gorm:"type:uuid;not null;index"`
+	CreatedBy       uuid.UUID `json:"created_by" gorm:"type:uuid;not null"`
+	Name            string    `json:"name" gorm:"type:varchar(255);not null"`
+	IncludeTasks    bool      `json:"include_tasks" gorm:"not null;default:false"`
+	IncludeMembers  bool      `json:"include_members" gorm:"not null;default:false"`
+	// StartDate moves the copy to a new start; the dates of its tasks keep their offset
+	// from the project start. The copy keeps the original dates when it is unset.
+	StartDate    *time.Time `json:"start_date,omitempty"`
+	Status       Status     `json:"status" gorm:"type:varchar(20);not null;index"`
+	NewProjectID *uuid.UUID `json:"new_project_id,omitempty" gorm:"type:uuid"`
+	TotalTasks   int        `json:"total_tasks" gorm:"not null;default:0"`
+	CopiedTasks  int        `json:"copied_tasks" gorm:"not null;default:0"`
+	// LastTaskID is the last source task copied, tasks being copied in ID order
+	LastTaskID  *uuid.UUID `json:"-" gorm:"type:uuid"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+
+	// Progress is the share of the work done, from 0 to 100
+	Progress int `json:"progress" gorm:"-"`
+	// Project is the new project, set once the duplication has completed
+	Project *project.Project `json:"project,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for the Duplication model
+func (Duplication) TableName() string {
+	return "project_duplications"
+}
+
+// Options controls what a duplication copies
+type Options struct {
+	// Name of the copy; "Copy of <name>" when empty
+	Name           string
+	IncludeTasks   bool
+	IncludeMembers bool
+	StartDate      *time.Time
+}
+
+// progress is the share of the work done, from 0 to 100
+func (d *Duplication) progress() int {
+	switch {
+	case d.Status == StatusCompleted:
+		return 100
+	case d.NewProjectID == nil:
+		return 0
+	case d.TotalTasks == 0 || d.CopiedTasks >= d.TotalTasks:
+		// Tasks restored from the trash while copying can outnumber the initial count
+		return 99
+	default:
+		return d.CopiedTasks * 99 / d.TotalTasks
+	}
+}
+
+// shift is how far the dates of the copy move from those of the source
+func (d *Duplication) shift(source *project.Project) time.Duration {
+	if d.StartDate == nil {
+		return 0
+	}
+	return d.StartDate.Sub(source.StartDate)
+}
@@ -0,0 +1,161 @@
+package projectcopy
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for project duplication data access
+type Repository interface {
+	Create(ctx context.Context, d *Duplication) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Duplication, error)
+	// Claim marks a queued duplication, or a running one that stopped making progress
+	// before staleBefore, as running. It reports false when another worker holds it.
+	Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error)
+	// Runnable lists the duplications that are waiting to be claimed, oldest first
+	Runnable(ctx context.Context, staleBefore time.Time) ([]uuid.UUID, error)
+	Save(ctx context.Context, d *Duplication) error
+
+	// NameTaken reports whether a live project of the organization has the name
+	NameTaken(ctx context.Context, orgID uuid.UUID, name string) (bool, error)
+	// CreateProject creates the copy, with the source's members when the duplication
+	// includes them, and records it on the duplication in the same transaction
+	CreateProject(ctx context.Context, d *Duplication, proj *project.Project) error
+	// SourceTaskIDs lists the tasks of the source project that the duplication copies:
+	// those that existed when it was requested and are not in the trash
+	SourceTaskIDs(ctx context.Context, d *Duplication) ([]uuid.UUID, error)
+	// NextTasks returns the next source tasks to copy, in ID order
+	NextTasks(ctx context.Context, d *Duplication, limit int) ([]task.Task, error)
+	// CopyTasks inserts copies of a batch of tasks and saves the duplication's progress
+	// in the same transaction. Copies that already exist are left alone.
+	CopyTasks(ctx context.Context, d *Duplication, copies []task.Task) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new project duplication repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Create(ctx context.Context, d *Duplication) error {
+	return r.db.WithContext(ctx).Create(d).Error
+}
+
+func (r *repository) FindByID(ctx context.Context, id uuid.UUID) (*Duplication, error) {
+	var d Duplication
+	if err := r.db.WithContext(ctx).First(&d, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDuplicationNotFound
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *repository) runnable(query *gorm.DB, staleBefore time.Time) *gorm.DB {
+	return query.Where("status = ? OR (status = ? AND updated_at < ?)", StatusQueued, StatusRunning, staleBefore)
+}
+
+func (r *repository) Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error) {
+	now := time.Now()
+	result := r.runnable(r.db.WithContext(ctx).Model(&Duplication{}).Where("id = ?", id), staleBefore).
+		Updates(map[string]interface{}{
+			"status":     StatusRunning,
+			"started_at": gorm.Expr("COALESCE(started_at, ?)", now),
+			"updated_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *repository) Runnable(ctx context.Context, staleBefore time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.runnable(r.db.WithContext(ctx).Model(&Duplication{}), staleBefore).
+		Order("created_at ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) Save(ctx context.Context, d *Duplication) error {
+	d.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Save(d).Error
+}
+
+func (r *repository) NameTaken(ctx context.Context, orgID uuid.UUID, name string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&project.Project{}).
+		Where("organization_id = ? AND name = ?", orgID, name).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// sourceTasks selects the tasks a duplication copies
+func sourceTasks(db *gorm.DB, d *Duplication) *gorm.DB {
+	return db.Model(&task.Task{}).
+		Where("project_id = ? AND created_at <= ?", d.SourceProjectID, d.CreatedAt)
+}
+
+func (r *repository) CreateProject(ctx context.Context, d *Duplication, proj *project.Project) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(proj).Error; err != nil {
+			return err
+		}
+		if d.IncludeMembers {
+			err := tx.Exec(`INSERT INTO project_members (project_id, user_id, role)
+				SELECT ?, user_id, role FROM project_members WHERE project_id = ?`,
+				proj.ID, d.SourceProjectID).Error
+			if err != nil {
+				return err
+			}
+		}
+		if d.IncludeTasks {
+			var total int64
+			if err := sourceTasks(tx, d).Count(&total).Error; err != nil {
+				return err
+			}
+			d.TotalTasks = int(total)
+		}
+
+		d.NewProjectID = &proj.ID
+		d.UpdatedAt = time.Now()
+		return tx.Save(d).Error
+	})
+}
+
+func (r *repository) SourceTaskIDs(ctx context.Context, d *Duplication) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := sourceTasks(r.db.WithContext(ctx), d).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) NextTasks(ctx context.Context, d *Duplication, limit int) ([]task.Task, error) {
+	query := sourceTasks(r.db.WithContext(ctx), d)
+	if d.LastTaskID != nil {
+		query = query.Where("id > ?", *d.LastTaskID)
+	}
+	var tasks []task.Task
+	err := query.Order("id ASC").Limit(limit).Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *repository) CopyTasks(ctx context.Context, d *Duplication, copies []task.Task) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(copies) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&copies).Error; err != nil {
+				return err
+			}
+		}
+		d.UpdatedAt = time.Now()
+		return tx.Save(d).Error
+	})
+}
@@ -0,0 +1,307 @@
+package projectcopy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// staleAfter is how long a running duplication may go without progress before
+	// another worker picks it up again, e.g. after the server running it crashed
+	staleAfter = 10 * time.Minute
+	// batchSize is how many tasks are copied per transaction
+	batchSize = 100
+)
+
+// Service defines the interface for duplicating projects
+type Service interface {
+	// StartDuplication queues a copy of a project of the organization
+	StartDuplication(ctx context.Context, orgID, sourceID, createdBy uuid.UUID, opts Options) (*Duplication, error)
+	// GetDuplication returns a duplication of the project with its progress, and the new
+	// project once it has completed
+	GetDuplication(ctx context.Context, sourceID, id uuid.UUID) (*Duplication, error)
+	// Run copies what is left of a duplication unless another worker holds it.
+	// Cancelling ctx stops it between batches and leaves it queued.
+	Run(ctx context.Context, id uuid.UUID) error
+	// Runnable lists the duplications waiting for a worker
+	Runnable(ctx context.Context) ([]uuid.UUID, error)
+	// Queued signals the ids of newly started duplications
+	Queued() <-chan uuid.UUID
+}
+
+type service struct {
+	repo     Repository
+	projects project.Service
+	changes  cache.ChangeNotifier
+	queued   chan uuid.UUID
+	logger   *zap.Logger
+}
+
+// NewService creates a new project duplication service
+func NewService(repo Repository, projects project.Service, changes cache.ChangeNotifier, logger *zap.Logger) Service {
+	return &service{
+		repo:     repo,
+		projects: projects,
+		changes:  changes,
+		queued:   make(chan uuid.UUID, 64),
+		logger:   logger,
+	}
+}
+
+func (s *service) StartDuplication(ctx context.Context, orgID, sourceID, createdBy uuid.UUID, opts Options) (*Duplication, error) {
+	source, err := s.projects.GetProject(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if source.OrganizationID != orgID {
+		return nil, project.ErrProjectNotFound
+	}
+
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		name = "Copy of " + source.Name
+	}
+	if len(name) < 3 || len(name) > 100 {
+		return nil, ErrInvalidName
+	}
+	taken, err := s.repo.NameTaken(ctx, orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, project.ErrProjectNameExists
+	}
+
+	d := &Duplication{
+		ID:              uuid.New(),
+		OrganizationID:  orgID,
+		SourceProjectID: sourceID,
+		CreatedBy:       createdBy,
+		Name:            name,
+		IncludeTasks:    opts.IncludeTasks,
+		IncludeMembers:  opts.IncludeMembers,
+		StartDate:       opts.StartDate,
+		Status:          StatusQueued,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.repo.Create(ctx, d); err != nil {
+		return nil, err
+	}
+
+	// The worker also polls for queued duplications, so a full channel only delays the job
+	select {
+	case s.queued <- d.ID:
+	default:
+	}
+	return d, nil
+}
+
+func (s *service) GetDuplication(ctx context.Context, sourceID, id uuid.UUID) (*Duplication, error) {
+	d, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.SourceProjectID != sourceID {
+		return nil, ErrDuplicationNotFound
+	}
+
+	d.Progress = d.progress()
+	if d.Status == StatusCompleted && d.NewProjectID != nil {
+		proj, err := s.projects.GetProject(ctx, *d.NewProjectID)
+		switch {
+		case err == nil:
+			d.Project = proj
+		case !errors.Is(err, project.ErrProjectNotFound):
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+func (s *service) Runnable(ctx context.Context) ([]uuid.UUID, error) {
+	return s.repo.Runnable(ctx, time.Now().Add(-staleAfter))
+}
+
+func (s *service) Queued() <-chan uuid.UUID {
+	return s.queued
+}
+
+func (s *service) Run(ctx context.Context, id uuid.UUID) error {
+	claimed, err := s.repo.Claim(ctx, id, time.Now().Add(-staleAfter))
+	if err != nil || !claimed {
+		return err
+	}
+	d, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	source, err := s.projects.GetProject(ctx, d.SourceProjectID)
+	if errors.Is(err, project.ErrProjectNotFound) {
+		return s.fail(d, "the project was deleted before it could be copied")
+	}
+	if err != nil {
+		return err
+	}
+
+	if d.NewProjectID == nil {
+		taken, err := s.repo.NameTaken(ctx, d.OrganizationID, d.Name)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return s.fail(d, project.ErrProjectNameExists.Error())
+		}
+		if err := s.repo.CreateProject(ctx, d, s.projectCopy(d, source)); err != nil {
+			return err
+		}
+		s.entityChanged(ctx, cache.EntityProjects)
+	}
+
+	if d.IncludeTasks {
+		done, err := s.copyTasks(ctx, d, source)
+		if err != nil || !done {
+			return err
+		}
+	}
+
+	now := time.Now()
+	d.Status = StatusCompleted
+	d.CompletedAt = &now
+	return s.repo.Save(context.Background(), d)
+}
+
+// copyTasks copies the remaining tasks batch by batch and reports whether all of them
+// were copied. When ctx is cancelled it hands the duplication back to the queue so the
+// next worker resumes where this one stopped.
+func (s *service) copyTasks(ctx context.Context, d *Duplication, source *project.Project) (bool, error) {
+	ids, err := s.repo.SourceTaskIDs(ctx, d)
+	if err != nil {
+		return false, err
+	}
+	inSource := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		inSource[id] = true
+	}
+	shift := d.shift(source)
+
+	for {
+		if ctx.Err() != nil {
+			d.Status = StatusQueued
+			return false, s.repo.Save(context.Background(), d)
+		}
+
+		batch, err := s.repo.NextTasks(ctx, d, batchSize)
+		if err != nil {
+			return false, err
+		}
+		if len(batch) == 0 {
+			return true, nil
+		}
+
+		copies := make([]task.Task, len(batch))
+		for i := range batch {
+			copies[i] = taskCopy(d, &batch[i], shift, inSource)
+		}
+		d.LastTaskID = &batch[len(batch)-1].ID
+		d.CopiedTasks += len(batch)
+		if err := s.repo.CopyTasks(ctx, d, copies); err != nil {
+			return false, err
+		}
+		s.entityChanged(ctx, cache.EntityTasks)
+	}
+}
+
+// fail ends a duplication that cannot complete
+func (s *service) fail(d *Duplication, reason string) error {
+	s.logger.Warn("Project duplication failed",
+		zap.String("duplication_id", d.ID.String()), zap.String("reason", reason))
+	now := time.Now()
+	d.Status = StatusFailed
+	d.Error = reason
+	d.CompletedAt = &now
+	return s.repo.Save(context.Background(), d)
+}
+
+func (s *service) entityChanged(ctx context.Context, entity string) {
+	if s.changes != nil {
+		s.changes.EntityChanged(ctx, entity)
+	}
+}
+
+// projectCopy is the new project of a duplication. The requester owns it and it starts
+// out active.
+func (s *service) projectCopy(d *Duplication, source *project.Project) *project.Project {
+	shift := d.shift(source)
+	proj := &project.Project{
+		ID:             uuid.New(),
+		Name:           d.Name,
+		Description:    source.Description,
+		Status:         project.ProjectStatusActive,
+		OrganizationID: d.OrganizationID,
+		CreatorID:      d.CreatedBy,
+		OwnerID:        d.CreatedBy,
+		StartDate:      source.StartDate.Add(shift),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if source.EndDate != nil {
+		end := source.EndDate.Add(shift)
+		proj.EndDate = &end
+	}
+	return proj
+}
+
+// copyID derives the ID of a task's copy, so a batch that is retried after a crash
+// recreates the same tasks and links between copies can be resolved up front
+func copyID(d *Duplication, sourceID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(d.ID, sourceID[:])
+}
+
+// taskCopy copies a task into the new project as upcoming work. Assignees and reviewers
+// are only kept when the members are copied too, and links to tasks outside the
+// project are dropped.
+func taskCopy(d *Duplication, t *task.Task, shift time.Duration, inSource map[uuid.UUID]bool) task.Task {
+	c := task.Task{
+		ID:             copyID(d, t.ID),
+		Title:          t.Title,
+		Description:    t.Description,
+		Status:         task.TaskStatusUpcoming,
+		Priority:       t.Priority,
+		CreatorID:      d.CreatedBy,
+		CategoryID:     t.CategoryID,
+		ProjectID:      *d.NewProjectID,
+		OrganizationID: d.OrganizationID,
+		EstimatedHours: t.EstimatedHours,
+		StartDate:      t.StartDate.Add(shift),
+		Duration:       t.Duration,
+		Dependencies:   task.UUIDSlice{},
+		Position:       t.Position,
+	}
+	if t.DueDate != nil {
+		due := t.DueDate.Add(shift)
+		c.DueDate = &due
+	}
+	if d.IncludeMembers {
+		c.AssigneeID = t.AssigneeID
+		c.ReviewerID = t.ReviewerID
+	}
+	if t.ParentTaskID != nil && inSource[*t.ParentTaskID] {
+		parentID := copyID(d, *t.ParentTaskID)
+		c.ParentTaskID = &parentID
+	}
+	for _, dependency := range t.Dependencies {
+		if inSource[dependency] {
+			c.Dependencies = append(c.Dependencies, copyID(d, dependency))
+		}
+	}
+	return c
+}
@@ -0,0 +1,93 @@
+package projections
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConsistencyEventual marks data projected from domain events: it trails the change that
+// produced it by the time the event takes to be handled
+const ConsistencyEventual = "eventual"
+
+// MaxRangeDays bounds the days of one daily stats request
+const MaxRangeDays = 366
+
+var ErrInvalidRange = errors.New("end date must not be before start date, and the range must not exceed 366 days")
+
+// DailyCounts counts what a user did on one day
+type DailyCounts struct {
+	TasksCreated    int `json:"tasks_created" gorm:"not null;default:0"`
+	TasksCompleted  int `json:"tasks_completed" gorm:"not null;default:0"`
+	TodosCreated    int `json:"todos_created" gorm:"not null;default:0"`
+	TodosCompleted  int `json:"todos_completed" gorm:"not null;default:0"`
+	HabitsCompleted int `json:"habits_completed" gorm:"not null;default:0"`
+}
+
+func (c *DailyCounts) add(other DailyCounts) {
+	c.TasksCreated += other.TasksCreated
+	c.TasksCompleted += other.TasksCompleted
+	c.TodosCreated += other.TodosCreated
+	c.TodosCompleted += other.TodosCompleted
+	c.HabitsCompleted += other.HabitsCompleted
+}
+
+// UserDailyStats is the projection of a user's activity on one UTC day. Tasks count for
+// the user who created or completed them.
+type UserDailyStats struct {
+	UserID      uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Day         time.Time `json:"day" gorm:"type:date;primaryKey"`
+	DailyCounts `gorm:"embedded"`
+	UpdatedAt   time.Time `json:"-"`
+}
+
+func (UserDailyStats) TableName() string {
+	return "user_daily_stats"
+}
+
+// ProjectTaskCount is the projection of how many tasks of a project are in one status.
+// Deleted tasks are not counted; tasks archived by a retention policy still are.
+type ProjectTaskCount struct {
+	ProjectID      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Status         string    `gorm:"type:varchar(50);primaryKey"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Count          int64     `gorm:"not null;default:0"`
+	UpdatedAt      time.Time
+}
+
+func (ProjectTaskCount) TableName() string {
+	return "project_task_counts"
+}
+
+// Freshness tells clients how current projected data is
+type Freshness struct {
+	Consistency string `json:"consistency"`
+	// AsOf is when the latest event included was applied, or nil when none was
+	AsOf *time.Time `json:"as_of,omitempty"`
+}
+
+// observe moves AsOf forward to t
+func (f *Freshness) observe(t time.Time) {
+	if f.AsOf == nil || t.After(*f.AsOf) {
+		f.AsOf = &t
+	}
+}
+
+// DailyStats is a user's activity for every day of a range, oldest first
+type DailyStats struct {
+	UserID uuid.UUID        `json:"user_id"`
+	Days   []UserDailyStats `json:"days"`
+	Totals DailyCounts      `json:"totals"`
+	Freshness
+}
+
+// ProjectCounters counts the tasks of a project by status
+type ProjectCounters struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Total     int64     `json:"total"`
+	// Open counts the tasks that are neither completed nor cancelled
+	Open     int64            `json:"open"`
+	ByStatus map[string]int64 `json:"by_status"`
+	Freshness
+}
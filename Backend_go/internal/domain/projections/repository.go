@@ -0,0 +1,105 @@
+package projections
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for the projection tables
+type Repository interface {
+	// AddDaily adds counts to a user's day, creating the day when needed
+	AddDaily(ctx context.Context, userID uuid.UUID, day time.Time, counts DailyCounts) error
+	// AddTaskCounts adds a delta to the tasks of a project in each status, atomically
+	AddTaskCounts(ctx context.Context, organizationID, projectID uuid.UUID, deltas map[string]int64) error
+	// FindDaily returns the days of a user from from to to that have activity
+	FindDaily(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]UserDailyStats, error)
+	FindTaskCounts(ctx context.Context, organizationID, projectID uuid.UUID) ([]ProjectTaskCount, error)
+	// RebuildTaskCounts replaces the task counts of every project with a count of the
+	// tasks table and returns the number of counts written
+	RebuildTaskCounts(ctx context.Context) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new projections repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) AddDaily(ctx context.Context, userID uuid.UUID, day time.Time, counts DailyCounts) error {
+	row := &UserDailyStats{UserID: userID, Day: day, DailyCounts: counts, UpdatedAt: time.Now()}
+	increment := func(column string) clause.Expr {
+		return gorm.Expr("user_daily_stats." + column + " + excluded." + column)
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"tasks_created":    increment("tasks_created"),
+			"tasks_completed":  increment("tasks_completed"),
+			"todos_created":    increment("todos_created"),
+			"todos_completed":  increment("todos_completed"),
+			"habits_completed": increment("habits_completed"),
+			"updated_at":       gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(row).Error
+}
+
+func (r *repository) AddTaskCounts(ctx context.Context, organizationID, projectID uuid.UUID, deltas map[string]int64) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for status, delta := range deltas {
+			row := &ProjectTaskCount{ProjectID: projectID, Status: status, OrganizationID: organizationID, Count: delta, UpdatedAt: now}
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "project_id"}, {Name: "status"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"count":      gorm.Expr("project_task_counts.count + excluded.count"),
+					"updated_at": gorm.Expr("excluded.updated_at"),
+				}),
+			}).Create(row).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *repository) FindDaily(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]UserDailyStats, error) {
+	var days []UserDailyStats
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND day BETWEEN ? AND ?", userID, from, to).
+		Order("day ASC").
+		Find(&days).Error
+	return days, err
+}
+
+func (r *repository) FindTaskCounts(ctx context.Context, organizationID, projectID uuid.UUID) ([]ProjectTaskCount, error) {
+	var counts []ProjectTaskCount
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND project_id = ?", organizationID, projectID).
+		Find(&counts).Error
+	return counts, err
+}
+
+func (r *repository) RebuildTaskCounts(ctx context.Context) (int64, error) {
+	var written int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM project_task_counts").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`INSERT INTO project_task_counts (project_id, status, organization_id, count, updated_at)
+			SELECT project_id, status, organization_id, COUNT(*), ? FROM tasks
+			WHERE deleted_at IS NULL
+			GROUP BY project_id, status, organization_id`, time.Now())
+		written = result.RowsAffected
+		return result.Error
+	})
+	return written, err
+}
@@ -0,0 +1,105 @@
+package projections
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Service defines the interface for the dashboard read models. They are kept up to date
+// by domain events, so reads are cheap but may trail the latest writes.
+type Service interface {
+	// Subscribe registers the projection handlers on the bus
+	Subscribe(bus *events.Bus)
+	// DailyStats returns a user's activity for every UTC day from from to to
+	DailyStats(ctx context.Context, userID uuid.UUID, from, to time.Time) (*DailyStats, error)
+	// ProjectCounters returns the task counts of a project of the organization
+	ProjectCounters(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectCounters, error)
+	// Reconcile recounts the project task counters from the tasks table, correcting drift
+	// from changes that publish no events, and returns the number of counters written
+	Reconcile(ctx context.Context) (int64, error)
+}
+
+type service struct {
+	repo   Repository
+	logger *zap.Logger
+}
+
+// NewService creates a new projections service
+func NewService(repo Repository, logger *zap.Logger) Service {
+	return &service{repo: repo, logger: logger}
+}
+
+// day returns the UTC day t falls on
+func day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func (s *service) DailyStats(ctx context.Context, userID uuid.UUID, from, to time.Time) (*DailyStats, error) {
+	from, to = day(from), day(to)
+	if to.Before(from) || to.Sub(from) >= MaxRangeDays*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+
+	stored, err := s.repo.FindDaily(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byDay := make(map[time.Time]UserDailyStats, len(stored))
+	for _, d := range stored {
+		byDay[day(d.Day)] = d
+	}
+
+	stats := &DailyStats{UserID: userID, Freshness: Freshness{Consistency: ConsistencyEventual}}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		entry, ok := byDay[d]
+		if ok {
+			stats.Freshness.observe(entry.UpdatedAt)
+		}
+		entry.UserID, entry.Day = userID, d
+		stats.Days = append(stats.Days, entry)
+		stats.Totals.add(entry.DailyCounts)
+	}
+	return stats, nil
+}
+
+func (s *service) ProjectCounters(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectCounters, error) {
+	counts, err := s.repo.FindTaskCounts(ctx, organizationID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := &ProjectCounters{
+		ProjectID: projectID,
+		ByStatus:  make(map[string]int64, len(counts)),
+		Freshness: Freshness{Consistency: ConsistencyEventual},
+	}
+	for _, c := range counts {
+		counters.Freshness.observe(c.UpdatedAt)
+		// A count only goes below zero when events for tasks from before the projection
+		// existed arrive ahead of the first reconcile
+		if c.Count <= 0 {
+			continue
+		}
+		counters.ByStatus[c.Status] = c.Count
+		counters.Total += c.Count
+		if c.Status != string(task.TaskStatusCompleted) && c.Status != string(task.TaskStatusCancelled) {
+			counters.Open += c.Count
+		}
+	}
+	return counters, nil
+}
+
+func (s *service) Reconcile(ctx context.Context) (int64, error) {
+	written, err := s.repo.RebuildTaskCounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	tracing.Logger(ctx, s.logger).Info("Reconciled project task counters", zap.Int64("counters", written))
+	return written, nil
+}
@@ -0,0 +1,37 @@
+package projections
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+)
+
+func (s *service) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, "projection_task_created", events.Async, func(ctx context.Context, event events.TaskCreated) error {
+		if err := s.repo.AddDaily(ctx, event.CreatorID, day(event.CreatedAt), DailyCounts{TasksCreated: 1}); err != nil {
+			return err
+		}
+		return s.repo.AddTaskCounts(ctx, event.OrganizationID, event.ProjectID, map[string]int64{event.Status: 1})
+	})
+	events.Subscribe(bus, "projection_task_status", events.Async, func(ctx context.Context, event events.TaskStatusChanged) error {
+		return s.repo.AddTaskCounts(ctx, event.OrganizationID, event.ProjectID, map[string]int64{
+			event.PreviousStatus: -1,
+			event.Status:         1,
+		})
+	})
+	events.Subscribe(bus, "projection_task_completed", events.Async, func(ctx context.Context, event events.TaskCompleted) error {
+		return s.repo.AddDaily(ctx, event.CompletedBy, day(event.CompletedAt), DailyCounts{TasksCompleted: 1})
+	})
+	events.Subscribe(bus, "projection_task_deleted", events.Async, func(ctx context.Context, event events.TaskDeleted) error {
+		return s.repo.AddTaskCounts(ctx, event.OrganizationID, event.ProjectID, map[string]int64{event.Status: -1})
+	})
+	events.Subscribe(bus, "projection_todo_created", events.Async, func(ctx context.Context, event events.TodoCreated) error {
+		return s.repo.AddDaily(ctx, event.UserID, day(event.CreatedAt), DailyCounts{TodosCreated: 1})
+	})
+	events.Subscribe(bus, "projection_todo_completed", events.Async, func(ctx context.Context, event events.TodoCompleted) error {
+		return s.repo.AddDaily(ctx, event.UserID, day(event.CompletedAt), DailyCounts{TodosCompleted: 1})
+	})
+	events.Subscribe(bus, "projection_habit_completed", events.Async, func(ctx context.Context, event events.HabitCompleted) error {
+		return s.repo.AddDaily(ctx, event.UserID, day(event.CompletedAt), DailyCounts{HabitsCompleted: 1})
+	})
+}
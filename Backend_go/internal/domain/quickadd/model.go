@@ -0,0 +1,56 @@
+package quickadd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+)
+
+// Kind is what a quick-add text becomes
+type Kind string
+
+const (
+	KindEvent Kind = "event"
+	KindTask  Kind = "task"
+)
+
+const (
+	// MaxTextLength caps the text of one quick-add
+	MaxTextLength = 500
+	// DefaultEventDuration is used for events without a duration or end time
+	DefaultEventDuration = time.Hour
+)
+
+var (
+	ErrEmptyText      = errors.New("text is required")
+	ErrTextTooLong    = errors.New("text is too long")
+	ErrNoTitle        = errors.New("text has nothing left for a title once dates, times and tags are taken out")
+	ErrInvalidKind    = errors.New("kind must be event or task")
+	ErrProjectMissing = errors.New("project_id is required to create a task")
+	ErrForbidden      = errors.New("insufficient permissions to create a task")
+)
+
+// Parsed is what the parser read from a quick-add text. Start and End are set for events;
+// Due is set for tasks with a date.
+type Parsed struct {
+	Kind     Kind               `json:"kind"`
+	Title    string             `json:"title"`
+	Start    *time.Time         `json:"start,omitempty"`
+	End      *time.Time         `json:"end,omitempty"`
+	AllDay   bool               `json:"all_day,omitempty"`
+	Due      *time.Time         `json:"due,omitempty"`
+	Duration int                `json:"duration_minutes,omitempty"`
+	Tags     []string           `json:"tags"`
+	Priority *task.TaskPriority `json:"priority,omitempty"`
+	Timezone string             `json:"timezone"`
+}
+
+// Result is a parsed quick-add text and, when it was committed, what was created from it
+type Result struct {
+	Parsed    Parsed                  `json:"parsed"`
+	Committed bool                    `json:"committed"`
+	Event     *calendar.CalendarEvent `json:"event,omitempty"`
+	Task      *task.Task              `json:"task,omitempty"`
+}
@@ -0,0 +1,462 @@
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+)
+
+var (
+	clockPattern       = regexp.MustCompile(`^(\d{1,2})(?::([0-5]\d))?(am|pm)?$`)
+	durationPattern    = regexp.MustCompile(`^(\d+(?:\.\d+)?)(h|hr|hrs|hour|hours|m|min|mins|minute|minutes)?$`)
+	hourMinutePattern  = regexp.MustCompile(`^(\d+)h(\d+)m?$`)
+	dayOfMonthPattern  = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)?$`)
+	fourDigitYearRegex = regexp.MustCompile(`^\d{4}$`)
+)
+
+var priorityTokens = map[string]task.TaskPriority{
+	"!low": task.TaskPriorityLow, "!medium": task.TaskPriorityMedium,
+	"!high": task.TaskPriorityHigh, "!urgent": task.TaskPriorityUrgent,
+	"!1": task.TaskPriorityUrgent, "!2": task.TaskPriorityHigh, "!3": task.TaskPriorityMedium, "!4": task.TaskPriorityLow,
+	"p1": task.TaskPriorityUrgent, "p2": task.TaskPriorityHigh, "p3": task.TaskPriorityMedium, "p4": task.TaskPriorityLow,
+	"!!!": task.TaskPriorityUrgent, "!!": task.TaskPriorityHigh,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// shortWeekdays are only read as days after "on", "next", "this", "due" or "by", since
+// words like "sat" and "sun" are common in titles
+var shortWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "tues": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January, "feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March, "apr": time.April, "april": time.April, "may": time.May,
+	"jun": time.June, "june": time.June, "jul": time.July, "july": time.July, "aug": time.August,
+	"august": time.August, "sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October, "nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// clock is a time of day
+type clock struct {
+	hour, minute int
+	// meridiem is "am" or "pm", empty for 24-hour times
+	meridiem string
+}
+
+// parser reads the tokens of a quick-add text. The first date, time and duration found
+// are used; whatever no rule consumed is the title.
+type parser struct {
+	words []string
+	norm  []string
+	used  []bool
+	today time.Time
+
+	tags     []string
+	priority *task.TaskPriority
+	date     *time.Time
+	start    *clock
+	end      *clock
+	duration time.Duration
+}
+
+// Parse reads a quick-add text such as "Lunch with Omar tomorrow 1pm for 1h #personal".
+// It understands:
+//   - dates: today, tomorrow, weekdays ("friday", "next fri"), "in 3 days", "in 2 weeks",
+//     2024-10-20, "oct 20" and "20th october"
+//   - times: 1pm, 1:30pm, 13:00, noon and midnight, optionally after "at", and ranges
+//     such as "1-2pm" or "1pm to 2:30pm"
+//   - durations after "for": 1h, 30m, 1h30m, 90 min, 2 hours, an hour
+//   - tags: #personal
+//   - priorities: !low to !urgent, !1 to !4, p1 to p4, !! and !!!
+//
+// A text with a time becomes an event and anything else a task, unless kind says
+// otherwise. Times without a date that have already passed today are taken as
+// tomorrow's. now is in the user's timezone, which the result is read in.
+func Parse(text string, kind Kind, now time.Time) (*Parsed, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return nil, ErrTextTooLong
+	}
+	switch kind {
+	case "", KindEvent, KindTask:
+	default:
+		return nil, ErrInvalidKind
+	}
+
+	p := &parser{
+		words: strings.Fields(text),
+		today: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+	}
+	p.norm = make([]string, len(p.words))
+	p.used = make([]bool, len(p.words))
+	for i, word := range p.words {
+		p.norm[i] = strings.TrimRight(strings.ToLower(word), ",;.")
+	}
+	for i := 0; i < len(p.words); {
+		n := p.match(i)
+		if n == 0 {
+			i++
+			continue
+		}
+		for j := i; j < i+n; j++ {
+			p.used[j] = true
+		}
+		i += n
+	}
+
+	var title []string
+	for i, word := range p.words {
+		if !p.used[i] {
+			title = append(title, word)
+		}
+	}
+	parsed := &Parsed{
+		Kind:     kind,
+		Title:    strings.TrimRight(strings.Join(title, " "), " ,;-"),
+		Tags:     p.tags,
+		Priority: p.priority,
+		Timezone: now.Location().String(),
+	}
+	if parsed.Title == "" {
+		return nil, ErrNoTitle
+	}
+	if parsed.Tags == nil {
+		parsed.Tags = []string{}
+	}
+	if parsed.Kind == "" {
+		parsed.Kind = KindTask
+		if p.start != nil {
+			parsed.Kind = KindEvent
+		}
+	}
+
+	day := p.today
+	if p.date != nil {
+		day = *p.date
+	}
+	switch {
+	case parsed.Kind == KindEvent && p.start == nil:
+		start, end := day, day.AddDate(0, 0, 1)
+		parsed.Start, parsed.End, parsed.AllDay = &start, &end, true
+	case parsed.Kind == KindEvent:
+		start := p.startAt(day, now)
+		end := start.Add(DefaultEventDuration)
+		switch {
+		case p.end != nil:
+			end = at(start, *p.end)
+			if !end.After(start) {
+				end = end.AddDate(0, 0, 1)
+			}
+		case p.duration > 0:
+			end = start.Add(p.duration)
+		}
+		parsed.Start, parsed.End = &start, &end
+		parsed.Duration = int(end.Sub(start).Minutes())
+	default:
+		if p.start != nil {
+			due := p.startAt(day, now)
+			parsed.Due = &due
+		} else if p.date != nil {
+			due := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 0, 0, day.Location())
+			parsed.Due = &due
+		}
+		parsed.Duration = int(p.duration.Minutes())
+	}
+	return parsed, nil
+}
+
+// startAt returns the time read on day. A time that has passed today is taken as
+// tomorrow's unless the text named the day.
+func (p *parser) startAt(day, now time.Time) time.Time {
+	t := at(day, *p.start)
+	if p.date == nil && t.Before(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// at returns the time of day c on the day of t
+func at(t time.Time, c clock) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), c.hour, c.minute, 0, 0, t.Location())
+}
+
+// match tries every rule at token i and returns how many tokens it consumed
+func (p *parser) match(i int) int {
+	word := p.norm[i]
+	if strings.HasPrefix(word, "#") {
+		if tag := strings.TrimLeft(word, "#"); tag != "" {
+			p.addTag(tag)
+			return 1
+		}
+	}
+	if priority, ok := priorityTokens[word]; ok && p.priority == nil {
+		p.priority = &priority
+		return 1
+	}
+	if word == "for" && p.duration == 0 {
+		if d, n := p.matchDuration(i + 1); n > 0 {
+			p.duration = d
+			return n + 1
+		}
+	}
+	if p.date == nil {
+		if date, n := p.matchDate(i, false); n > 0 {
+			p.date = &date
+			return n
+		}
+	}
+	if p.start == nil {
+		if n := p.matchTime(i); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func (p *parser) addTag(tag string) {
+	for _, existing := range p.tags {
+		if existing == tag {
+			return
+		}
+	}
+	p.tags = append(p.tags, tag)
+}
+
+func (p *parser) word(i int) string {
+	if i < 0 || i >= len(p.norm) {
+		return ""
+	}
+	return p.norm[i]
+}
+
+// matchDuration reads "1h", "30m", "1h30m", "1.5h", "90 min", "2 hours" or "an hour" at
+// token i
+func (p *parser) matchDuration(i int) (time.Duration, int) {
+	word := p.word(i)
+	if (word == "an" || word == "a") && p.word(i+1) == "hour" {
+		return time.Hour, 2
+	}
+	if m := hourMinutePattern.FindStringSubmatch(word); m != nil {
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, 1
+	}
+	m := durationPattern.FindStringSubmatch(word)
+	if m == nil {
+		return 0, 0
+	}
+	n, unit := 1, m[2]
+	if unit == "" {
+		if unitMatch := durationPattern.FindStringSubmatch("1" + p.word(i+1)); unitMatch != nil && unitMatch[2] != "" {
+			n, unit = 2, unitMatch[2]
+		} else {
+			return 0, 0
+		}
+	}
+	amount, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || amount <= 0 {
+		return 0, 0
+	}
+	if strings.HasPrefix(unit, "h") {
+		return time.Duration(amount * float64(time.Hour)), n
+	}
+	return time.Duration(amount * float64(time.Minute)), n
+}
+
+// matchDate reads a date at token i. Short weekday names are only read after a word
+// that introduces a date, which sets afterKeyword.
+func (p *parser) matchDate(i int, afterKeyword bool) (time.Time, int) {
+	word := p.word(i)
+	switch word {
+	case "":
+		return time.Time{}, 0
+	case "today":
+		return p.today, 1
+	case "tomorrow", "tmrw":
+		return p.today.AddDate(0, 0, 1), 1
+	case "on", "next", "this", "due", "by":
+		if date, n := p.matchDate(i+1, true); n > 0 {
+			return date, n + 1
+		}
+		return time.Time{}, 0
+	case "in":
+		amount, err := strconv.Atoi(p.word(i + 1))
+		if err != nil || amount <= 0 {
+			return time.Time{}, 0
+		}
+		switch p.word(i + 2) {
+		case "day", "days":
+			return p.today.AddDate(0, 0, amount), 3
+		case "week", "weeks":
+			return p.today.AddDate(0, 0, 7*amount), 3
+		}
+		return time.Time{}, 0
+	}
+
+	if weekday, ok := weekdays[word]; ok {
+		return p.nextWeekday(weekday), 1
+	}
+	if weekday, ok := shortWeekdays[word]; ok && afterKeyword {
+		return p.nextWeekday(weekday), 1
+	}
+	if date, err := time.ParseInLocation("2006-01-02", word, p.today.Location()); err == nil {
+		return date, 1
+	}
+	if month, ok := months[word]; ok {
+		if m := dayOfMonthPattern.FindStringSubmatch(p.word(i + 1)); m != nil {
+			day, _ := strconv.Atoi(m[1])
+			return p.monthDay(month, day, i+2, 2)
+		}
+	}
+	if m := dayOfMonthPattern.FindStringSubmatch(word); m != nil {
+		if month, ok := months[p.word(i+1)]; ok {
+			day, _ := strconv.Atoi(m[1])
+			return p.monthDay(month, day, i+2, 2)
+		}
+	}
+	return time.Time{}, 0
+}
+
+// nextWeekday returns the next day that falls on weekday, a week from today when today
+// is that day
+func (p *parser) nextWeekday(weekday time.Weekday) time.Time {
+	days := (int(weekday) - int(p.today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return p.today.AddDate(0, 0, days)
+}
+
+// monthDay returns the date of a day and month read from n tokens, in the year at token
+// yearAt if there is one, or else in the next year the date has not passed
+func (p *parser) monthDay(month time.Month, day, yearAt, n int) (time.Time, int) {
+	year := p.today.Year()
+	explicitYear := false
+	if word := p.word(yearAt); fourDigitYearRegex.MatchString(word) {
+		year, _ = strconv.Atoi(word)
+		explicitYear = true
+		n++
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, p.today.Location())
+	if date.Day() != day {
+		return time.Time{}, 0
+	}
+	if !explicitYear && date.Before(p.today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date, n
+}
+
+// matchTime reads a time or a time range at token i, optionally after "at", "@" or "from"
+func (p *parser) matchTime(i int) int {
+	j := i
+	switch p.word(j) {
+	case "at", "@", "from":
+		j++
+	}
+
+	// Ranges written as one token, like 1-2pm or 9:30-11:00
+	if before, after, ok := strings.Cut(p.word(j), "-"); ok {
+		end, endOK := parseClock(after, false)
+		start, startOK := parseClock(before, end.meridiem != "")
+		if !startOK || !endOK {
+			return 0
+		}
+		start = start.withMeridiemOf(end)
+		p.start, p.end = &start, &end
+		return j + 1 - i
+	}
+
+	start, n := p.clockAt(j)
+	if n == 0 {
+		return 0
+	}
+	j += n
+	p.start = &start
+	switch p.word(j) {
+	case "to", "until", "till", "-":
+		if end, n := p.clockAt(j + 1); n > 0 {
+			p.end = &end
+			j += n + 1
+		}
+	}
+	return j - i
+}
+
+// clockAt reads a time at token i, which may have its am or pm as the next token
+func (p *parser) clockAt(i int) (clock, int) {
+	if next := p.word(i + 1); next == "am" || next == "pm" {
+		if c, ok := parseClock(p.word(i)+next, false); ok {
+			return c, 2
+		}
+	}
+	if c, ok := parseClock(p.word(i), false); ok {
+		return c, 1
+	}
+	return clock{}, 0
+}
+
+// parseClock reads times such as 1pm, 1:30pm, 13:00, noon and midnight. A bare hour is
+// only read as a time when bare is set, for the start of a range such as 1-2pm.
+func parseClock(s string, bare bool) (clock, bool) {
+	switch s {
+	case "noon":
+		return clock{hour: 12, meridiem: "pm"}, true
+	case "midnight":
+		return clock{meridiem: "am"}, true
+	}
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil {
+		return clock{}, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch {
+	case m[3] != "":
+		if hour < 1 || hour > 12 {
+			return clock{}, false
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	case m[2] != "" || bare:
+		if hour > 23 {
+			return clock{}, false
+		}
+	default:
+		return clock{}, false
+	}
+	return clock{hour: hour, minute: minute, meridiem: m[3]}, true
+}
+
+// withMeridiemOf gives the start of a range like 1-2pm the am or pm of its end, unless
+// that would put it after the end, as in 11-1pm
+func (c clock) withMeridiemOf(end clock) clock {
+	if c.meridiem != "" || end.meridiem == "" || c.hour < 1 || c.hour > 12 {
+		return c
+	}
+	pm := c
+	pm.hour = c.hour%12 + 12
+	if end.meridiem == "pm" && pm.hour*60+pm.minute < end.hour*60+end.minute {
+		return pm
+	}
+	return c
+}
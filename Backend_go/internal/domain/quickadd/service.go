@@ -0,0 +1,150 @@
+package quickadd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// createTaskPermission is needed to commit a quick-add as a task
+const createTaskPermission = "tasks:create"
+
+// Input is a quick-add text with the caller's identity
+type Input struct {
+	Text string
+	// Kind forces the text to become an event or a task; it is inferred when empty
+	Kind Kind
+	// Commit creates the event or task; otherwise the parse is only previewed
+	Commit bool
+	// ProjectID is the project tasks are created in
+	ProjectID      *uuid.UUID
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+	Permissions    []string
+}
+
+// Service defines the interface for quick-add
+type Service interface {
+	// QuickAdd parses a text in the user's timezone and, when committing, creates the
+	// event or task it describes
+	QuickAdd(ctx context.Context, input Input) (*Result, error)
+}
+
+type service struct {
+	calendarService calendar.Service
+	taskService     task.Service
+	projectService  project.Service
+	userService     user.Service
+	now             func() time.Time
+}
+
+// NewService creates a new quick-add service instance
+func NewService(calendarService calendar.Service, taskService task.Service, projectService project.Service, userService user.Service) Service {
+	return &service{
+		calendarService: calendarService,
+		taskService:     taskService,
+		projectService:  projectService,
+		userService:     userService,
+		now:             time.Now,
+	}
+}
+
+func (s *service) QuickAdd(ctx context.Context, input Input) (*Result, error) {
+	u, err := s.userService.GetUser(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := user.ParseTimezone(u.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	parsed, err := Parse(input.Text, input.Kind, s.now().In(loc))
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Parsed: *parsed}
+	if !input.Commit {
+		return result, nil
+	}
+
+	switch parsed.Kind {
+	case KindEvent:
+		result.Event, err = s.createEvent(ctx, input, parsed)
+	default:
+		result.Task, err = s.createTask(ctx, input, parsed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Committed = true
+	return result, nil
+}
+
+func (s *service) createEvent(ctx context.Context, input Input, parsed *Parsed) (*calendar.CalendarEvent, error) {
+	return s.calendarService.CreateEvent(ctx, calendar.CreateCalendarEventRequest{
+		Title:        parsed.Title,
+		Description:  tagLine(parsed.Tags),
+		EventType:    calendar.EventTypeNone,
+		StartTime:    *parsed.Start,
+		EndTime:      *parsed.End,
+		IsAllDay:     parsed.AllDay,
+		Transparency: calendar.TransparencyOpaque,
+	}, input.UserID)
+}
+
+func (s *service) createTask(ctx context.Context, input Input, parsed *Parsed) (*task.Task, error) {
+	if !hasPermission(input.Permissions, createTaskPermission) {
+		return nil, ErrForbidden
+	}
+	if input.ProjectID == nil || *input.ProjectID == uuid.Nil {
+		return nil, ErrProjectMissing
+	}
+	proj, err := s.projectService.GetProject(ctx, *input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if input.OrganizationID != uuid.Nil && proj.OrganizationID != input.OrganizationID {
+		return nil, project.ErrProjectNotFound
+	}
+
+	create := task.CreateTaskInput{
+		Title:          parsed.Title,
+		Description:    tagLine(parsed.Tags),
+		CreatorID:      input.UserID,
+		AssigneeID:     &input.UserID,
+		ProjectID:      proj.ID,
+		OrganizationID: proj.OrganizationID,
+		StartDate:      time.Now(),
+		DueDate:        parsed.Due,
+		EstimatedHours: float64(parsed.Duration) / 60,
+	}
+	if parsed.Priority != nil {
+		create.Priority = *parsed.Priority
+	}
+	return s.taskService.CreateTask(ctx, create)
+}
+
+// tagLine writes tags back as hashtags. Neither events nor tasks have labels, so tags
+// are kept in the description where they stay searchable.
+func tagLine(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "#" + strings.Join(tags, " #")
+}
+
+func hasPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package retention
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is a kind of data an organization can set a retention policy for
+type Category string
+
+const (
+	// CategoryCompletedTodos are todos completed by members of the organization
+	CategoryCompletedTodos Category = "completed_todos"
+	// CategoryClosedTasks are completed and cancelled tasks of the organization
+	CategoryClosedTasks Category = "closed_tasks"
+	// CategoryAuditLogs are the entries of the organization's audit log
+	CategoryAuditLogs Category = "audit_logs"
+)
+
+// Categories lists every category in the order policies are shown
+var Categories = []Category{CategoryCompletedTodos, CategoryClosedTasks, CategoryAuditLogs}
+
+// Action is what a policy does to the data it expires
+type Action string
+
+const (
+	ActionDelete  Action = "delete"
+	ActionArchive Action = "archive"
+	ActionPurge   Action = "purge"
+)
+
+const (
+	// MaxRetentionDays bounds how long a policy can keep data before acting on it
+	MaxRetentionDays = 3650
+	// MaxNoticeDays bounds how far ahead admins are told about expiring data
+	MaxNoticeDays = 90
+	// DefaultNoticeDays is how far ahead admins are told unless the policy says otherwise
+	DefaultNoticeDays = 7
+	// DeletionGraceDays is how long an organization scheduled for deletion stays read-only,
+	// and can be restored by its owner, before its data is purged
+	DeletionGraceDays = 30
+)
+
+// defaultRetentionDays is what a category keeps data for before its policy is set
+var defaultRetentionDays = map[Category]int{
+	CategoryCompletedTodos: 30,
+	CategoryClosedTasks:    180,
+	CategoryAuditLogs:      365,
+}
+
+var (
+	ErrInvalidCategory = errors.New("category must be completed_todos, closed_tasks or audit_logs")
+	ErrInvalidPolicy   = errors.New("retention must be between 1 and 3650 days and the notice between 0 and 90 days")
+	ErrPolicyDisabled  = errors.New("retention policy is not enabled")
+
+	ErrNotOwner             = errors.New("only the organization owner can delete it")
+	ErrDeletionScheduled    = errors.New("the organization is already scheduled for deletion")
+	ErrDeletionNotScheduled = errors.New("the organization is not scheduled for deletion")
+)
+
+// IsValid reports whether the category is known
+func (c Category) IsValid() bool {
+	_, ok := defaultRetentionDays[c]
+	return ok
+}
+
+// Action returns what policies of the category do: completed todos are deleted, closed
+// tasks archived and audit entries purged
+func (c Category) Action() Action {
+	switch c {
+	case CategoryClosedTasks:
+		return ActionArchive
+	case CategoryAuditLogs:
+		return ActionPurge
+	default:
+		return ActionDelete
+	}
+}
+
+// Policy is how long an organization keeps one category of data. Enabled policies are
+// applied by a nightly job in two steps: it first tells the admins what will expire and
+// acts on it once the notice period has passed, so the data can be exported in between.
+type Policy struct {
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;primaryKey"`
+	Category       Category  `json:"category" gorm:"type:varchar(30);primaryKey"`
+	Action         Action    `json:"action" gorm:"-"`
+	Enabled        bool      `json:"enabled" gorm:"not null;default:false;index"`
+	RetentionDays  int       `json:"retention_days" gorm:"not null"`
+	NoticeDays     int       `json:"notice_days" gorm:"not null"`
+	// PendingBefore is set once admins were told that the data from before it expires.
+	// The job acts on it at ScheduledAt; changing the policy withdraws the notice.
+	PendingBefore *time.Time `json:"pending_before,omitempty"`
+	NoticedAt     *time.Time `json:"noticed_at,omitempty"`
+	ScheduledAt   *time.Time `json:"scheduled_at,omitempty" gorm:"-"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (Policy) TableName() string {
+	return "retention_policies"
+}
+
+// PolicyInput replaces the retention policy of a category
+type PolicyInput struct {
+	Enabled       bool
+	RetentionDays int
+	NoticeDays    int
+}
+
+// Pending describes the data a policy acts on next
+type Pending struct {
+	Category Category `json:"category"`
+	Action   Action   `json:"action"`
+	// Before is the cutoff: data last touched before it expires
+	Before time.Time `json:"before"`
+	Count  int64     `json:"count"`
+	// ScheduledAt is when the data is acted on, or nil when admins were not told yet
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+// Deletion is the scheduled deletion of an organization
+type Deletion struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	RequestedBy    uuid.UUID `json:"requested_by"`
+	RequestedAt    time.Time `json:"requested_at"`
+	// PurgeAt is when the retention job purges the organization and all its data
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// Result sums up what one run of the retention job did
+type Result struct {
+	Policies int
+	Noticed  int
+	Deleted  int64
+	Archived int64
+	Purged   int64
+	Failed   int
+	// Organizations counts the organizations purged once their deletion was due
+	Organizations int
+}
@@ -0,0 +1,293 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/attachments"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for retention policies and the data they expire
+type Repository interface {
+	// FindPolicies returns the policies the organization has set
+	FindPolicies(ctx context.Context, organizationID uuid.UUID) ([]Policy, error)
+	SavePolicy(ctx context.Context, policy *Policy) error
+	// SetNotice records the notice given for a policy, or withdraws it when before is nil.
+	// It reports false when the policy was changed since it was read.
+	SetNotice(ctx context.Context, policy *Policy, before, noticedAt *time.Time) (bool, error)
+	// FindEnabled returns the enabled policies of every organization
+	FindEnabled(ctx context.Context) ([]Policy, error)
+	// Count returns how much data of the category the organization has from before a time
+	Count(ctx context.Context, organizationID uuid.UUID, category Category, before time.Time) (int64, error)
+	// Expire deletes, archives or purges the data of the category from before a time
+	Expire(ctx context.Context, organizationID uuid.UUID, category Category, before time.Time) (int64, error)
+	// FindTodos returns up to limit completed todos the policy of the organization expires
+	FindTodos(ctx context.Context, organizationID uuid.UUID, before time.Time, limit int) ([]todos.Todo, error)
+	// FindTasks returns up to limit closed tasks the policy of the organization archives
+	FindTasks(ctx context.Context, organizationID uuid.UUID, before time.Time, limit int) ([]task.Task, error)
+
+	// ScheduleDeletion makes the organization read-only until it is purged. It reports
+	// false when a deletion is already scheduled.
+	ScheduleDeletion(ctx context.Context, deletion *Deletion) (bool, error)
+	// CancelDeletion withdraws the deletion of the organization. It reports false when
+	// none was scheduled.
+	CancelDeletion(ctx context.Context, organizationID uuid.UUID) (bool, error)
+	// FindDeletionsDue returns the organizations whose deletion is due at a time
+	FindDeletionsDue(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+	// Purge deletes the organization and all its data if its deletion is still due at a
+	// time. It returns how many rows it deleted and the attachments whose stored files
+	// are left to delete.
+	Purge(ctx context.Context, organizationID uuid.UUID, now time.Time) (int64, []attachments.Attachment, error)
+}
+
+// tenantTable is a table purged with an organization. Its rows are matched by
+// organization_id unless scope, with the organization as its one parameter, says otherwise.
+type tenantTable struct {
+	table string
+	scope string
+}
+
+const (
+	orgTasks     = "SELECT id FROM tasks WHERE organization_id = ?"
+	orgProjects  = "SELECT id FROM projects WHERE organization_id = ?"
+	orgWorkflows = "SELECT id FROM workflows WHERE organization_id = ?"
+)
+
+// tenantTables lists the data of an organization, children before the rows they point
+// at. Personal data of the members, such as their todos, habits and calendars, is kept.
+var tenantTables = []tenantTable{
+	{table: "workflow_transitions", scope: "from_step_id IN (SELECT id FROM workflow_steps WHERE workflow_id IN (" + orgWorkflows + "))"},
+	{table: "workflow_step_executions", scope: "execution_id IN (SELECT id FROM workflow_executions WHERE workflow_id IN (" + orgWorkflows + "))"},
+	{table: "workflow_ai_usage"},
+	{table: "workflow_ai_budgets"},
+	{table: "workflow_agent_links", scope: "workflow_id IN (" + orgWorkflows + ")"},
+	{table: "workflow_executions", scope: "workflow_id IN (" + orgWorkflows + ")"},
+	{table: "workflow_steps", scope: "workflow_id IN (" + orgWorkflows + ")"},
+	{table: "workflows"},
+	{table: "webhook_deliveries", scope: "webhook_id IN (SELECT id FROM webhooks WHERE organization_id = ?)"},
+	{table: "webhooks"},
+	{table: "vcs_links", scope: "connection_id IN (SELECT id FROM vcs_connections WHERE organization_id = ?)"},
+	{table: "vcs_connections"},
+	{table: "chat_channels", scope: "installation_id IN (SELECT id FROM chat_installations WHERE organization_id = ?)"},
+	{table: "chat_user_links", scope: "installation_id IN (SELECT id FROM chat_installations WHERE organization_id = ?)"},
+	{table: "chat_installations"},
+	{table: "announcement_recipients", scope: "announcement_id IN (SELECT id FROM announcements WHERE organization_id = ?)"},
+	{table: "announcements"},
+	{table: "project_baseline_tasks", scope: "baseline_id IN (SELECT id FROM project_baselines WHERE organization_id = ?)"},
+	{table: "project_baselines"},
+	{table: "task_comments", scope: "task_id IN (" + orgTasks + ")"},
+	{table: "task_analytics", scope: "task_id IN (" + orgTasks + ")"},
+	{table: "time_entries"},
+	{table: "tasks"},
+	{table: "project_members", scope: "project_id IN (" + orgProjects + ")"},
+	{table: "project_task_counts"},
+	{table: "project_settings"},
+	{table: "project_duplications"},
+	{table: "sla_project_clocks"},
+	{table: "projects"},
+	{table: "organization_unit_members"},
+	{table: "organization_units"},
+	{table: "tag_assignments"},
+	{table: "tags"},
+	{table: "sla_schedules"},
+	{table: "sla_holidays"},
+	{table: "organization_default_settings"},
+	{table: "integration_health"},
+	{table: "integration_failures"},
+	{table: "attachments"},
+	{table: "api_keys"},
+	{table: "activity_events"},
+	{table: "search_audit_log"},
+	{table: "metering_daily_usage"},
+	{table: "onboarding_progress"},
+	{table: "organization_member_imports"},
+	{table: "billing_subscriptions"},
+	{table: "retention_policies"},
+	{table: "audit_log"},
+	{table: "organization_invitations"},
+	{table: "organization_members"},
+	{table: "organizations", scope: "id = ?"},
+}
+
+// taskLinks are columns of personal data that point at tasks of an organization. They
+// are cleared when it is purged.
+var taskLinks = []struct{ table, column string }{
+	{"todos", "linked_task_id"},
+	{"focus_sessions", "task_id"},
+	{"habit_links", "task_id"},
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new retention repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) FindPolicies(ctx context.Context, organizationID uuid.UUID) ([]Policy, error) {
+	var policies []Policy
+	err := r.db.WithContext(ctx).Where("organization_id = ?", organizationID).Find(&policies).Error
+	return policies, err
+}
+
+func (r *repository) SavePolicy(ctx context.Context, policy *Policy) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "retention_days", "notice_days", "pending_before", "noticed_at", "updated_at"}),
+	}).Create(policy).Error
+}
+
+func (r *repository) SetNotice(ctx context.Context, policy *Policy, before, noticedAt *time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Policy{}).
+		Where("organization_id = ? AND category = ? AND updated_at = ?", policy.OrganizationID, policy.Category, policy.UpdatedAt).
+		UpdateColumns(map[string]interface{}{"pending_before": before, "noticed_at": noticedAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *repository) FindEnabled(ctx context.Context) ([]Policy, error) {
+	var policies []Policy
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Order("organization_id, category").Find(&policies).Error
+	return policies, err
+}
+
+// expiring scopes a query to the data of the category the organization has from before a
+// time. Todos are personal, so the policy covers those of every member; a member of
+// several organizations keeps completed todos for the shortest of their retentions.
+// Tasks count as closed from their last update.
+func (r *repository) expiring(ctx context.Context, organizationID uuid.UUID, category Category, before time.Time) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	switch category {
+	case CategoryCompletedTodos:
+		return db.Model(&todos.Todo{}).
+			Where("is_completed = ? AND COALESCE(completion_date, updated_at) < ?", true, before).
+			Where("user_id IN (?)", db.Model(&organization.Member{}).
+				Select("user_id").Where("organization_id = ?", organizationID))
+	case CategoryClosedTasks:
+		return db.Model(&task.Task{}).
+			Where("organization_id = ? AND status IN ? AND updated_at < ? AND archived_at IS NULL",
+				organizationID, []task.TaskStatus{task.TaskStatusCompleted, task.TaskStatusCancelled}, before)
+	default:
+		return db.Model(&audit.Entry{}).Where("organization_id = ? AND created_at < ?", organizationID, before)
+	}
+}
+
+func (r *repository) Count(ctx context.Context, organizationID uuid.UUID, category Category, before time.Time) (int64, error) {
+	var count int64
+	err := r.expiring(ctx, organizationID, category, before).Count(&count).Error
+	return count, err
+}
+
+func (r *repository) Expire(ctx context.Context, organizationID uuid.UUID, category Category, before time.Time) (int64, error) {
+	query := r.expiring(ctx, organizationID, category, before)
+	var result *gorm.DB
+	switch category {
+	case CategoryCompletedTodos:
+		result = query.Unscoped().Delete(&todos.Todo{})
+	case CategoryClosedTasks:
+		// UpdateColumn leaves updated_at alone, which still tells when the task was closed
+		result = query.UpdateColumn("archived_at", time.Now())
+	default:
+		result = query.Delete(&audit.Entry{})
+	}
+	return result.RowsAffected, result.Error
+}
+
+func (r *repository) FindTodos(ctx context.Context, organizationID uuid.UUID, before time.Time, limit int) ([]todos.Todo, error) {
+	var expiring []todos.Todo
+	err := r.expiring(ctx, organizationID, CategoryCompletedTodos, before).
+		Order("completion_date ASC").Limit(limit).Find(&expiring).Error
+	return expiring, err
+}
+
+func (r *repository) FindTasks(ctx context.Context, organizationID uuid.UUID, before time.Time, limit int) ([]task.Task, error) {
+	var expiring []task.Task
+	err := r.expiring(ctx, organizationID, CategoryClosedTasks, before).
+		Order("updated_at ASC").Limit(limit).Find(&expiring).Error
+	return expiring, err
+}
+
+func (r *repository) ScheduleDeletion(ctx context.Context, deletion *Deletion) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&organization.Organization{}).
+		Where("id = ? AND deletion_scheduled_at IS NULL", deletion.OrganizationID).
+		UpdateColumns(map[string]interface{}{
+			"deletion_scheduled_at": deletion.PurgeAt,
+			"deletion_requested_at": deletion.RequestedAt,
+			"deletion_requested_by": deletion.RequestedBy,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *repository) CancelDeletion(ctx context.Context, organizationID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&organization.Organization{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", organizationID).
+		UpdateColumns(map[string]interface{}{
+			"deletion_scheduled_at": nil,
+			"deletion_requested_at": nil,
+			"deletion_requested_by": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *repository) FindDeletionsDue(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&organization.Organization{}).
+		Where("deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) Purge(ctx context.Context, organizationID uuid.UUID, now time.Time) (int64, []attachments.Attachment, error) {
+	var purged int64
+	var files []attachments.Attachment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The lock makes a cancellation racing the purge either win or wait for it
+		var due []uuid.UUID
+		err := tx.Raw(`SELECT id FROM organizations WHERE id = ? AND deletion_scheduled_at <= ? FOR UPDATE`,
+			organizationID, now).Scan(&due).Error
+		if err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return ErrDeletionNotScheduled
+		}
+
+		// The rows are the only record of where the files are stored
+		if err := tx.Where("organization_id = ?", organizationID).Find(&files).Error; err != nil {
+			return err
+		}
+
+		for _, link := range taskLinks {
+			err := tx.Exec(`UPDATE `+link.table+` SET `+link.column+` = NULL WHERE `+link.column+` IN (`+orgTasks+`)`,
+				organizationID).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, t := range tenantTables {
+			scope := t.scope
+			if scope == "" {
+				scope = "organization_id = ?"
+			}
+			result := tx.Exec(`DELETE FROM `+t.table+` WHERE `+scope, organizationID)
+			if result.Error != nil {
+				return result.Error
+			}
+			purged += result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return purged, files, nil
+}
@@ -0,0 +1,491 @@
+package retention
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/attachments"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxExportRows caps an export of expiring todos or tasks
+const maxExportRows = 100000
+
+// AuditLog exports the audit entries a policy is about to purge and records deletions
+// of organizations
+type AuditLog interface {
+	audit.Auditor
+	ExportCSV(ctx context.Context, orgID uuid.UUID, filter audit.Filter, w io.Writer) error
+}
+
+// Service defines the interface for retention policies
+type Service interface {
+	// ListPolicies returns the policy of every category, with the defaults for those the
+	// organization has not set
+	ListPolicies(ctx context.Context, organizationID uuid.UUID) ([]Policy, error)
+	// SetPolicy replaces the policy of a category, withdrawing any pending notice
+	SetPolicy(ctx context.Context, organizationID uuid.UUID, category Category, input PolicyInput) (*Policy, error)
+	// Pending returns what the policy of a category acts on next
+	Pending(ctx context.Context, organizationID uuid.UUID, category Category) (*Pending, error)
+	// ExportCSV writes the data of a category from before a time as CSV, oldest first
+	ExportCSV(ctx context.Context, organizationID uuid.UUID, category Category, before time.Time, w io.Writer) error
+	// Run applies every enabled policy: it acts on data whose notice has run out and
+	// tells the admins about the data that expires next. It then purges organizations
+	// whose deletion is due.
+	Run(ctx context.Context) (*Result, error)
+
+	// ScheduleDeletion schedules the organization to be purged after DeletionGraceDays
+	// and makes it read-only until then. Only the owner can delete it.
+	ScheduleDeletion(ctx context.Context, organizationID, requestedBy uuid.UUID) (*Deletion, error)
+	// GetDeletion returns the scheduled deletion of the organization
+	GetDeletion(ctx context.Context, organizationID uuid.UUID) (*Deletion, error)
+	// CancelDeletion withdraws the scheduled deletion, making the organization writable again
+	CancelDeletion(ctx context.Context, organizationID, cancelledBy uuid.UUID) error
+}
+
+type service struct {
+	repo        Repository
+	auditLog    AuditLog
+	orgs        organization.AdminDirectory
+	attachments attachments.Service
+	notifier    notification.DomainNotifier
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewService creates a new retention service
+func NewService(repo Repository, auditLog AuditLog, orgs organization.AdminDirectory, attachmentService attachments.Service, notifier notification.DomainNotifier, logger *zap.Logger) Service {
+	return &service{
+		repo:        repo,
+		auditLog:    auditLog,
+		orgs:        orgs,
+		attachments: attachmentService,
+		notifier:    notifier,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// defaultPolicy is the disabled policy of a category the organization has not set
+func defaultPolicy(organizationID uuid.UUID, category Category) Policy {
+	return Policy{
+		OrganizationID: organizationID,
+		Category:       category,
+		RetentionDays:  defaultRetentionDays[category],
+		NoticeDays:     DefaultNoticeDays,
+	}
+}
+
+// cutoff returns the time data must be older than to expire once a notice given at now runs out
+func (p *Policy) cutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, p.NoticeDays-p.RetentionDays)
+}
+
+// describe fills in the fields derived from the stored policy
+func (p *Policy) describe() {
+	p.Action = p.Category.Action()
+	p.ScheduledAt = nil
+	if p.NoticedAt != nil {
+		scheduled := p.NoticedAt.AddDate(0, 0, p.NoticeDays)
+		p.ScheduledAt = &scheduled
+	}
+}
+
+func (s *service) ListPolicies(ctx context.Context, organizationID uuid.UUID) ([]Policy, error) {
+	stored, err := s.repo.FindPolicies(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	byCategory := make(map[Category]Policy, len(stored))
+	for _, policy := range stored {
+		byCategory[policy.Category] = policy
+	}
+
+	policies := make([]Policy, 0, len(Categories))
+	for _, category := range Categories {
+		policy, ok := byCategory[category]
+		if !ok {
+			policy = defaultPolicy(organizationID, category)
+		}
+		policy.describe()
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+func (s *service) policy(ctx context.Context, organizationID uuid.UUID, category Category) (*Policy, error) {
+	if !category.IsValid() {
+		return nil, ErrInvalidCategory
+	}
+	policies, err := s.ListPolicies(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range policies {
+		if policies[i].Category == category {
+			return &policies[i], nil
+		}
+	}
+	return nil, ErrInvalidCategory
+}
+
+func (s *service) SetPolicy(ctx context.Context, organizationID uuid.UUID, category Category, input PolicyInput) (*Policy, error) {
+	if !category.IsValid() {
+		return nil, ErrInvalidCategory
+	}
+	if input.RetentionDays < 1 || input.RetentionDays > MaxRetentionDays ||
+		input.NoticeDays < 0 || input.NoticeDays > MaxNoticeDays {
+		return nil, ErrInvalidPolicy
+	}
+
+	policy := &Policy{
+		OrganizationID: organizationID,
+		Category:       category,
+		Enabled:        input.Enabled,
+		RetentionDays:  input.RetentionDays,
+		NoticeDays:     input.NoticeDays,
+		UpdatedAt:      s.now(),
+	}
+	if err := s.repo.SavePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	policy.describe()
+	return policy, nil
+}
+
+func (s *service) Pending(ctx context.Context, organizationID uuid.UUID, category Category) (*Pending, error) {
+	policy, err := s.policy(ctx, organizationID, category)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Enabled {
+		return nil, ErrPolicyDisabled
+	}
+
+	pending := &Pending{
+		Category:    category,
+		Action:      policy.Action,
+		Before:      policy.cutoff(s.now()),
+		ScheduledAt: policy.ScheduledAt,
+	}
+	if policy.PendingBefore != nil {
+		pending.Before = *policy.PendingBefore
+	}
+	pending.Count, err = s.repo.Count(ctx, organizationID, category, pending.Before)
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func (s *service) ExportCSV(ctx context.Context, organizationID uuid.UUID, category Category, before time.Time, w io.Writer) error {
+	switch category {
+	case CategoryAuditLogs:
+		return s.auditLog.ExportCSV(ctx, organizationID, audit.Filter{Until: &before}, w)
+	case CategoryCompletedTodos:
+		expiring, err := s.repo.FindTodos(ctx, organizationID, before, maxExportRows)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, len(expiring))
+		for i, t := range expiring {
+			rows[i] = []string{t.ID.String(), t.UserID.String(), audit.CSVCell(t.Title), audit.CSVCell(t.Description),
+				string(t.Priority), optionalTime(t.CompletionDate), t.CreatedAt.UTC().Format(time.RFC3339)}
+		}
+		return writeCSV(w, []string{"id", "user_id", "title", "description", "priority", "completed_at", "created_at"}, rows)
+	case CategoryClosedTasks:
+		expiring, err := s.repo.FindTasks(ctx, organizationID, before, maxExportRows)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, len(expiring))
+		for i, t := range expiring {
+			assignee := ""
+			if t.AssigneeID != nil {
+				assignee = t.AssigneeID.String()
+			}
+			rows[i] = []string{t.ID.String(), t.ProjectID.String(), audit.CSVCell(t.Title), audit.CSVCell(t.Description),
+				string(t.Status), assignee, t.UpdatedAt.UTC().Format(time.RFC3339), t.CreatedAt.UTC().Format(time.RFC3339)}
+		}
+		return writeCSV(w, []string{"id", "project_id", "title", "description", "status", "assignee_id", "closed_at", "created_at"}, rows)
+	default:
+		return ErrInvalidCategory
+	}
+}
+
+func (s *service) Run(ctx context.Context) (*Result, error) {
+	policies, err := s.repo.FindEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Policies: len(policies)}
+	now := s.now()
+	var runErr error
+	for i := range policies {
+		if err := s.apply(ctx, &policies[i], now, result); err != nil {
+			runErr = err
+			result.Failed++
+			tracing.Logger(ctx, s.logger).Error("Failed to apply retention policy",
+				zap.String("organization_id", policies[i].OrganizationID.String()),
+				zap.String("category", string(policies[i].Category)),
+				zap.Error(err))
+		}
+	}
+
+	due, err := s.repo.FindDeletionsDue(ctx, now)
+	if err != nil {
+		return result, err
+	}
+	for _, organizationID := range due {
+		purged, files, err := s.repo.Purge(ctx, organizationID, now)
+		switch {
+		case errors.Is(err, ErrDeletionNotScheduled):
+			// The owner cancelled the deletion since it was found due
+		case err != nil:
+			runErr = err
+			result.Failed++
+			tracing.Logger(ctx, s.logger).Error("Failed to purge organization",
+				zap.String("organization_id", organizationID.String()),
+				zap.Error(err))
+		default:
+			result.Organizations++
+			s.attachments.RemoveFiles(ctx, files)
+			tracing.Logger(ctx, s.logger).Info("Purged organization",
+				zap.String("organization_id", organizationID.String()),
+				zap.Int64("rows", purged))
+		}
+	}
+	return result, runErr
+}
+
+// apply acts on the data a policy gave notice of once the notice has run out, then gives
+// notice of the data that expires next
+func (s *service) apply(ctx context.Context, policy *Policy, now time.Time, result *Result) error {
+	policy.describe()
+	if policy.PendingBefore != nil {
+		if now.Before(*policy.ScheduledAt) {
+			return nil
+		}
+		expired, err := s.repo.Expire(ctx, policy.OrganizationID, policy.Category, *policy.PendingBefore)
+		if err != nil {
+			return err
+		}
+		switch policy.Category.Action() {
+		case ActionArchive:
+			result.Archived += expired
+		case ActionPurge:
+			result.Purged += expired
+		default:
+			result.Deleted += expired
+		}
+		if _, err := s.repo.SetNotice(ctx, policy, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	before := policy.cutoff(now)
+	count, err := s.repo.Count(ctx, policy.OrganizationID, policy.Category, before)
+	if err != nil || count == 0 {
+		return err
+	}
+	// The policy may have changed since it was read, which withdraws the notice
+	noticed, err := s.repo.SetNotice(ctx, policy, &before, &now)
+	if err != nil || !noticed {
+		return err
+	}
+	policy.PendingBefore, policy.NoticedAt = &before, &now
+	policy.describe()
+	result.Noticed++
+	return s.notify(ctx, policy, count)
+}
+
+// notify tells the admins of the organization what the policy is about to do and when
+func (s *service) notify(ctx context.Context, policy *Policy, count int64) error {
+	admins, err := organization.Admins(ctx, s.orgs, policy.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	scheduled := policy.ScheduledAt.UTC().Format("January 2, 2006")
+	title := fmt.Sprintf("%d %s will be %s on %s", count, policy.Category.label(), policy.Category.Action().pastTense(), scheduled)
+	content := fmt.Sprintf("Your organization keeps %s for %d days. Export them before %s if you need a copy.",
+		policy.Category.label(), policy.RetentionDays, scheduled)
+	data := map[string]string{
+		"organizationId": policy.OrganizationID.String(),
+		"category":       string(policy.Category),
+		"action":         string(policy.Category.Action()),
+		"count":          fmt.Sprint(count),
+		"before":         policy.PendingBefore.UTC().Format(time.RFC3339),
+		"scheduledAt":    policy.ScheduledAt.UTC().Format(time.RFC3339),
+		"exportUrl":      fmt.Sprintf("/api/organizations/%s/retention/%s/pending?format=csv", policy.OrganizationID, policy.Category),
+	}
+	for _, adminID := range admins {
+		if err := s.notifier.NotifyUser(ctx, adminID, notification.RetentionScheduled, title, content,
+			data, "organization", policy.OrganizationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) ScheduleDeletion(ctx context.Context, organizationID, requestedBy uuid.UUID) (*Deletion, error) {
+	org, err := s.orgs.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if org.OwnerID != requestedBy {
+		return nil, ErrNotOwner
+	}
+
+	now := s.now()
+	deletion := &Deletion{
+		OrganizationID: organizationID,
+		RequestedBy:    requestedBy,
+		RequestedAt:    now,
+		PurgeAt:        now.AddDate(0, 0, DeletionGraceDays),
+	}
+	scheduled, err := s.repo.ScheduleDeletion(ctx, deletion)
+	if err != nil {
+		return nil, err
+	}
+	if !scheduled {
+		return nil, ErrDeletionScheduled
+	}
+
+	s.auditLog.Audit(ctx, audit.Event{
+		OrganizationID: organizationID,
+		Action:         audit.ActionOrgDeletionScheduled,
+		TargetType:     "organization",
+		TargetID:       &organizationID,
+		Metadata:       map[string]interface{}{"purge_at": deletion.PurgeAt},
+	})
+
+	purgeDate := deletion.PurgeAt.UTC().Format("January 2, 2006")
+	s.notifyMembers(ctx, org, notification.OrganizationDeletionScheduled,
+		fmt.Sprintf("%s will be deleted on %s", org.Name, purgeDate),
+		fmt.Sprintf("The owner scheduled the organization for deletion. It is read-only until %s, when its projects, tasks and other data are deleted for good unless the owner cancels the deletion.", purgeDate),
+		map[string]string{"purgeAt": deletion.PurgeAt.UTC().Format(time.RFC3339)})
+	return deletion, nil
+}
+
+func (s *service) GetDeletion(ctx context.Context, organizationID uuid.UUID) (*Deletion, error) {
+	org, err := s.orgs.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if org.DeletionScheduledAt == nil {
+		return nil, ErrDeletionNotScheduled
+	}
+
+	deletion := &Deletion{OrganizationID: organizationID, PurgeAt: *org.DeletionScheduledAt}
+	if org.DeletionRequestedBy != nil {
+		deletion.RequestedBy = *org.DeletionRequestedBy
+	}
+	if org.DeletionRequestedAt != nil {
+		deletion.RequestedAt = *org.DeletionRequestedAt
+	}
+	return deletion, nil
+}
+
+func (s *service) CancelDeletion(ctx context.Context, organizationID, cancelledBy uuid.UUID) error {
+	org, err := s.orgs.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if org.OwnerID != cancelledBy {
+		return ErrNotOwner
+	}
+
+	cancelled, err := s.repo.CancelDeletion(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrDeletionNotScheduled
+	}
+
+	s.auditLog.Audit(ctx, audit.Event{
+		OrganizationID: organizationID,
+		Action:         audit.ActionOrgDeletionCancelled,
+		TargetType:     "organization",
+		TargetID:       &organizationID,
+	})
+	s.notifyMembers(ctx, org, notification.OrganizationDeletionCancelled,
+		fmt.Sprintf("%s will not be deleted", org.Name),
+		"The owner cancelled the deletion of the organization. It accepts changes again.", nil)
+	return nil
+}
+
+// notifyMembers tells the owner and every member about the deletion of the organization.
+// The deletion stands either way, so failures are only logged.
+func (s *service) notifyMembers(ctx context.Context, org *organization.Organization, notificationType notification.Type, title, content string, data map[string]string) {
+	members, err := s.orgs.ListMembers(ctx, org.ID)
+	if err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to list members to notify of organization deletion",
+			zap.String("organization_id", org.ID.String()), zap.Error(err))
+		return
+	}
+
+	recipients := []uuid.UUID{org.OwnerID}
+	for _, member := range members {
+		if member.UserID != org.OwnerID {
+			recipients = append(recipients, member.UserID)
+		}
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+	data["organizationId"] = org.ID.String()
+	for _, userID := range recipients {
+		if err := s.notifier.NotifyUser(ctx, userID, notificationType, title, content,
+			data, "organization", org.ID); err != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to notify member of organization deletion",
+				zap.String("organization_id", org.ID.String()),
+				zap.String("user_id", userID.String()),
+				zap.Error(err))
+		}
+	}
+}
+
+func (c Category) label() string {
+	switch c {
+	case CategoryClosedTasks:
+		return "closed tasks"
+	case CategoryAuditLogs:
+		return "audit log entries"
+	default:
+		return "completed todos"
+	}
+}
+
+func (a Action) pastTense() string {
+	return string(a) + "d"
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	if err := out.WriteAll(rows); err != nil {
+		return err
+	}
+	return out.Error()
+}
+
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mode is how a search was run
+type Mode string
+
+const (
+	ModeFullText Mode = "fulltext"
+	ModeSemantic Mode = "semantic"
+)
+
+// AuditEntry records a search run in an organization that audits its searches
+type AuditEntry struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;index:idx_search_audit_org_time,priority:1"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Mode           Mode      `json:"mode" gorm:"type:varchar(20);not null"`
+	Query          string    `json:"query" gorm:"type:text;not null"`
+	// Types lists the result types searched, comma-separated
+	Types       string    `json:"types" gorm:"type:varchar(100);not null"`
+	ResultCount int       `json:"result_count" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;default:current_timestamp;index:idx_search_audit_org_time,priority:2"`
+}
+
+// TableName specifies the table name for the AuditEntry model
+func (AuditEntry) TableName() string {
+	return "search_audit_log"
+}
+
+// AuditFilter selects entries of an organization's search audit trail
+type AuditFilter struct {
+	UserID   *uuid.UUID
+	Since    *time.Time
+	Until    *time.Time
+	Page     int
+	PageSize int
+}
+
+// AuditPolicy tells whether an organization records the searches run in it
+type AuditPolicy interface {
+	SearchAuditEnabled(ctx context.Context, orgID uuid.UUID) (bool, error)
+}
+
+func newAuditEntry(query Query, mode Mode, resultCount int) *AuditEntry {
+	types := make([]string, len(query.Types))
+	for i, t := range query.Types {
+		types[i] = string(t)
+	}
+	return &AuditEntry{
+		ID:             uuid.New(),
+		OrganizationID: *query.OrganizationID,
+		UserID:         query.UserID,
+		Mode:           mode,
+		Query:          query.Text,
+		Types:          strings.Join(types, ","),
+		ResultCount:    resultCount,
+		CreatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/providers"
+	"github.com/google/uuid"
+)
+
+const (
+	// EmbeddingTable stores one vector per embedded task and todo
+	EmbeddingTable = "search_embeddings"
+	// SemanticWeight is the share of a semantic result's rank that comes from vector
+	// similarity; the rest comes from the full-text rank
+	SemanticWeight = 0.7
+
+	// maxEmbeddingText caps the characters sent to the embedding model per item
+	maxEmbeddingText = 8000
+)
+
+// EmbeddingTableSQL creates the embedding table. It needs the pgvector extension. The
+// vector column has no fixed dimension so organizations can use different models;
+// similarity is only computed between vectors of the same model.
+const EmbeddingTableSQL = `CREATE TABLE IF NOT EXISTS ` + EmbeddingTable + ` (
+	entity_type varchar(20) NOT NULL,
+	entity_id uuid NOT NULL,
+	model varchar(100) NOT NULL,
+	embedding vector NOT NULL,
+	source_updated_at timestamptz NOT NULL,
+	embedded_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (entity_type, entity_id)
+)`
+
+// SemanticTypes lists the result types that are embedded for semantic search
+var SemanticTypes = []ResultType{ResultTask, ResultTodo}
+
+var ErrSemanticUnavailable = errors.New("semantic search is not available")
+
+// Embedding is the vector of a task or todo, computed from its title and description
+type Embedding struct {
+	EntityType ResultType
+	EntityID   uuid.UUID
+	Model      string
+	Vector     []float32
+	// SourceUpdatedAt is the entity's updated_at when it was embedded; newer rows are re-embedded
+	SourceUpdatedAt time.Time
+}
+
+// EmbeddingSource is a row that has no embedding yet or changed since it was embedded
+type EmbeddingSource struct {
+	Type           ResultType
+	ID             uuid.UUID
+	OrganizationID *uuid.UUID
+	Title          string
+	Body           string
+	UpdatedAt      time.Time
+}
+
+// Text returns what is embedded for the row
+func (s EmbeddingSource) Text() string {
+	text := strings.TrimSpace(s.Title + "\n\n" + s.Body)
+	if len(text) > maxEmbeddingText {
+		text = strings.ToValidUTF8(text[:maxEmbeddingText], "")
+	}
+	return text
+}
+
+// QueryVector is a search text embedded with one model
+type QueryVector struct {
+	Vector []float32
+	Model  string
+}
+
+// Embedder turns text into vectors with the embedding model of an organization.
+// uuid.Nil uses the server's default model.
+type Embedder interface {
+	Embed(ctx context.Context, orgID uuid.UUID, texts []string) ([][]float32, string, error)
+}
+
+// providerEmbedder embeds text with the organization's LLM provider
+type providerEmbedder struct {
+	providers *providers.Resolver
+}
+
+// NewProviderEmbedder creates an embedder backed by the configured LLM providers
+func NewProviderEmbedder(resolver *providers.Resolver) Embedder {
+	return &providerEmbedder{providers: resolver}
+}
+
+func (e *providerEmbedder) Embed(ctx context.Context, orgID uuid.UUID, texts []string) ([][]float32, string, error) {
+	provider, err := e.providers.For(ctx, orgID)
+	if err != nil {
+		return nil, "", err
+	}
+	embeddings, err := provider.Embed(ctx, providers.EmbeddingRequest{Input: texts})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(embeddings.Vectors) != len(texts) {
+		return nil, "", errors.New("embedding provider returned the wrong number of vectors")
+	}
+	return embeddings.Vectors, provider.Name() + "/" + embeddings.Model, nil
+}
+
+// vectorLiteral formats a vector in pgvector's text representation
+func vectorLiteral(vector []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range vector {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
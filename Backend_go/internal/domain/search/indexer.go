@@ -0,0 +1,197 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// IndexerConfig controls how embeddings are kept up to date
+type IndexerConfig struct {
+	// BatchSize is the number of rows embedded per request to the provider
+	BatchSize int
+	// Interval is how often changed rows are swept up without an event, such as todo
+	// edits, and how often every row's search language is checked against its owner's
+	Interval time.Duration
+}
+
+// DefaultIndexerConfig returns the configuration used in production
+func DefaultIndexerConfig() IndexerConfig {
+	return IndexerConfig{
+		BatchSize: 32,
+		Interval:  10 * time.Minute,
+	}
+}
+
+// Indexer embeds new and changed tasks and todos. It receives domain events as a
+// webhooks.Publisher to embed changes right away, and on start and every interval it
+// backfills every row that is missing an embedding or changed since it was embedded.
+// It also keeps the search language of rows in line with their owners': rows are
+// written in DefaultLanguage, so new rows are reindexed when an event arrives, and
+// every row on start and every interval, which picks up changed settings and locales.
+type Indexer struct {
+	repo     Repository
+	embedder Embedder
+	config   IndexerConfig
+	logger   *zap.Logger
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewIndexer creates a search indexer. With a nil embedder it only reindexes languages.
+func NewIndexer(repo Repository, embedder Embedder, config IndexerConfig, logger *zap.Logger) *Indexer {
+	return &Indexer{
+		repo:     repo,
+		embedder: embedder,
+		config:   config,
+		logger:   logger,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Publish wakes the indexer when a task, todo or habit is created or a task's text changes
+func (i *Indexer) Publish(ctx context.Context, event webhooks.Event) {
+	switch event.Type {
+	case webhooks.EventTaskCreated, webhooks.EventTaskUpdated, webhooks.EventTodoCreated, webhooks.EventHabitCreated:
+		select {
+		case i.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start launches the background indexing loop
+func (i *Indexer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		ticker := time.NewTicker(i.config.Interval)
+		defer ticker.Stop()
+
+		semantic := i.embedder != nil
+		var since *time.Time
+		for {
+			// Overlap the previous sweep by a minute to catch rows committed during it
+			started := time.Now().Add(-time.Minute)
+			if reindexed, err := i.repo.Reindex(ctx, ReindexScope{CreatedSince: since}); err != nil && ctx.Err() == nil {
+				i.logger.Error("Failed to reindex search languages", zap.Error(err))
+			} else if reindexed > 0 {
+				i.logger.Info("Reindexed search languages", zap.Int64("rows", reindexed))
+			}
+
+			if semantic {
+				if _, err := i.Backfill(ctx); err != nil && ctx.Err() == nil {
+					if errors.Is(err, ErrSemanticUnavailable) {
+						i.logger.Info("Semantic search is disabled because pgvector is not available")
+						semantic = false
+					} else {
+						i.logger.Error("Failed to index embeddings", zap.Error(err))
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				since = nil
+			case <-i.wake:
+				since = &started
+			}
+		}
+	}()
+}
+
+// Stop stops the indexing loop and waits for the current batch to finish
+func (i *Indexer) Stop() {
+	if i.cancel != nil {
+		i.cancel()
+	}
+	i.wg.Wait()
+}
+
+// Backfill embeds every row that is missing an embedding or changed since it was
+// embedded and returns how many it embedded. Rows whose organization's provider fails
+// are skipped until the next run.
+func (i *Indexer) Backfill(ctx context.Context) (int, error) {
+	if i.embedder == nil {
+		return 0, ErrSemanticUnavailable
+	}
+	available, err := i.repo.SemanticAvailable(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !available {
+		return 0, ErrSemanticUnavailable
+	}
+
+	embedded, skipped := 0, 0
+	for {
+		// Embedded rows drop out of the pending set, so only skipped rows are offset
+		pending, err := i.repo.PendingEmbeddings(ctx, i.config.BatchSize, skipped)
+		if err != nil {
+			return embedded, err
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		byOrg := make(map[uuid.UUID][]EmbeddingSource)
+		for _, source := range pending {
+			orgID := uuid.Nil
+			if source.OrganizationID != nil {
+				orgID = *source.OrganizationID
+			}
+			byOrg[orgID] = append(byOrg[orgID], source)
+		}
+
+		for orgID, sources := range byOrg {
+			texts := make([]string, len(sources))
+			for n, source := range sources {
+				texts[n] = source.Text()
+			}
+			vectors, model, err := i.embedder.Embed(ctx, orgID, texts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return embedded, ctx.Err()
+				}
+				i.logger.Warn("Failed to embed search rows",
+					zap.String("organization_id", orgID.String()),
+					zap.Int("rows", len(sources)),
+					zap.Error(err))
+				skipped += len(sources)
+				continue
+			}
+
+			embeddings := make([]Embedding, len(sources))
+			for n, source := range sources {
+				embeddings[n] = Embedding{
+					EntityType:      source.Type,
+					EntityID:        source.ID,
+					Model:           model,
+					Vector:          vectors[n],
+					SourceUpdatedAt: source.UpdatedAt,
+				}
+			}
+			if err := i.repo.SaveEmbeddings(ctx, embeddings); err != nil {
+				return embedded, err
+			}
+			embedded += len(sources)
+		}
+	}
+
+	if embedded > 0 {
+		i.logger.Info("Indexed search embeddings", zap.Int("embedded", embedded), zap.Int("skipped", skipped))
+	}
+	return embedded, nil
+}
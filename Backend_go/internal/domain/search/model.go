@@ -0,0 +1,155 @@
+package search
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResultType identifies the kind of entity a search result points to
+type ResultType string
+
+const (
+	ResultTask    ResultType = "task"
+	ResultTodo    ResultType = "todo"
+	ResultEvent   ResultType = "event"
+	ResultHabit   ResultType = "habit"
+	ResultProject ResultType = "project"
+)
+
+// AllTypes lists every searchable entity type
+var AllTypes = []ResultType{ResultTask, ResultTodo, ResultEvent, ResultHabit, ResultProject}
+
+// IsValid checks if the result type is searchable
+func (t ResultType) IsValid() bool {
+	for _, v := range AllTypes {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// VectorColumn is the generated tsvector column added to every searchable table
+	VectorColumn = "search_vector"
+	// LanguageColumn holds the text search configuration a row's vector is built with:
+	// its organization's search language for tasks and projects, its owner's locale for
+	// personal rows
+	LanguageColumn = "search_language"
+	// DefaultLanguage is the search language of organizations that do not choose one
+	DefaultLanguage = "english"
+	// FallbackLanguage is used for locales without a stemmer; it lowercases words but
+	// neither stems them nor drops stop words
+	FallbackLanguage = "simple"
+
+	MinQueryLength = 2
+	MaxQueryLength = 200
+	DefaultLimit   = 20
+	MaxLimit       = 50
+)
+
+var (
+	ErrInvalidQuery = errors.New("search query must be between 2 and 200 characters")
+	ErrInvalidType  = errors.New("unknown search result type")
+)
+
+// localeLanguages maps the language subtag of a locale to the PostgreSQL text search
+// configuration with its stemmer and stop words
+var localeLanguages = map[string]string{
+	"ar": "arabic",
+	"da": "danish",
+	"de": "german",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"ga": "irish",
+	"hu": "hungarian",
+	"id": "indonesian",
+	"it": "italian",
+	"lt": "lithuanian",
+	"nb": "norwegian",
+	"ne": "nepali",
+	"nl": "dutch",
+	"nn": "norwegian",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"ta": "tamil",
+	"tr": "turkish",
+}
+
+// SupportedLanguages returns the search languages organizations can choose, sorted
+func SupportedLanguages() []string {
+	seen := map[string]bool{FallbackLanguage: true}
+	languages := []string{FallbackLanguage}
+	for _, language := range localeLanguages {
+		if !seen[language] {
+			seen[language] = true
+			languages = append(languages, language)
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Languages are the search languages a query is parsed with, one per kind of row
+type Languages struct {
+	// User is the language of the caller's locale, used for their personal rows
+	User string
+	// Organization is the language of the caller's organization, used for its tasks
+	// and projects
+	Organization string
+}
+
+// Query describes a search and who is running it
+type Query struct {
+	Text  string
+	Types []ResultType
+	Limit int
+
+	UserID uuid.UUID
+	// OrganizationID widens task and project results to the caller's organization, as
+	// far as Access allows
+	OrganizationID *uuid.UUID
+	Access         Access
+	// Languages are resolved by the service
+	Languages Languages
+}
+
+// ReindexScope selects the rows whose search language is brought up to date. Nil fields
+// select every row.
+type ReindexScope struct {
+	// OrganizationID limits the reindex to the tasks and projects of an organization
+	OrganizationID *uuid.UUID
+	// UserID limits the reindex to the personal rows of a user
+	UserID *uuid.UUID
+	// CreatedSince limits the reindex to rows created since a time
+	CreatedSince *time.Time
+}
+
+// Access is what the caller's role lets them find in the organization a search runs in.
+// Without the read permission of a type, only the caller's own tasks and projects are
+// found. Otherwise members find those of the projects they belong to, own or created,
+// unless AllProjects opens up every project of the organization.
+type Access struct {
+	Tasks       bool
+	Projects    bool
+	AllProjects bool
+}
+
+// Result is a single ranked search hit
+type Result struct {
+	Type      ResultType `json:"type"`
+	ID        uuid.UUID  `json:"id"`
+	Title     string     `json:"title"`
+	Snippet   string     `json:"snippet,omitempty"`
+	Rank      float64    `json:"rank"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
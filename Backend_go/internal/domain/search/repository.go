@@ -0,0 +1,420 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Source describes a searchable table. The migrations add its LanguageColumn, and its
+// VectorColumn as a generated column over Weighted in that language indexed with GIN.
+type Source struct {
+	Type  ResultType
+	Table string
+	// Weighted maps columns to tsvector weights, most relevant first
+	Weighted      [][2]string
+	TitleColumn   string
+	BodyColumn    string
+	ProjectColumn string
+	// OrganizationColumn holds the organization whose embedding model and search
+	// language the rows use
+	OrganizationColumn string
+	// UserColumn holds the user whose locale sets the search language of personal rows
+	UserColumn string
+}
+
+// Sources lists every searchable table
+var Sources = []Source{
+	{Type: ResultTask, Table: "tasks", Weighted: [][2]string{{"title", "A"}, {"description", "B"}},
+		TitleColumn: "title", BodyColumn: "description", ProjectColumn: "project_id", OrganizationColumn: "organization_id"},
+	{Type: ResultTodo, Table: "todos", Weighted: [][2]string{{"title", "A"}, {"description", "B"}},
+		TitleColumn: "title", BodyColumn: "description", UserColumn: "user_id"},
+	{Type: ResultEvent, Table: "calendar_events", Weighted: [][2]string{{"title", "A"}, {"description", "B"}, {"location", "C"}},
+		TitleColumn: "title", BodyColumn: "description", UserColumn: "user_id"},
+	{Type: ResultHabit, Table: "habits", Weighted: [][2]string{{"title", "A"}, {"description", "B"}},
+		TitleColumn: "title", BodyColumn: "description", UserColumn: "user_id"},
+	{Type: ResultProject, Table: "projects", Weighted: [][2]string{{"name", "A"}, {"description", "B"}},
+		TitleColumn: "name", BodyColumn: "description", OrganizationColumn: "organization_id"},
+}
+
+// VectorExpression returns the immutable expression the generated search column is computed from
+func (s Source) VectorExpression() string {
+	parts := make([]string, len(s.Weighted))
+	for i, w := range s.Weighted {
+		parts[i] = fmt.Sprintf("setweight(to_tsvector(%s, coalesce(%s, '')), '%s')", LanguageColumn, w[0], w[1])
+	}
+	return strings.Join(parts, " || ")
+}
+
+// language returns the search language of the source's rows for a query
+func (s Source) language(languages Languages) string {
+	if s.OrganizationColumn != "" {
+		return languages.Organization
+	}
+	return languages.User
+}
+
+// IndexName returns the name of the GIN index over the search column
+func (s Source) IndexName() string {
+	return "idx_" + s.Table + "_search"
+}
+
+// Repository defines the interface for search data access
+type Repository interface {
+	Search(ctx context.Context, query Query) ([]Result, error)
+	// Languages returns the search languages of a user and, if any, their organization
+	Languages(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID) (Languages, error)
+	// Reindex sets the search language of rows whose owner's language changed since
+	// they were written, which regenerates their vectors, and returns how many changed
+	Reindex(ctx context.Context, scope ReindexScope) (int64, error)
+
+	// SemanticAvailable reports whether the embedding table exists, which needs pgvector
+	SemanticAvailable(ctx context.Context) (bool, error)
+	SemanticSearch(ctx context.Context, query Query, vectors map[ResultType]QueryVector) ([]Result, error)
+	// PendingEmbeddings returns rows without an embedding or changed since, oldest first
+	PendingEmbeddings(ctx context.Context, limit, offset int) ([]EmbeddingSource, error)
+	SaveEmbeddings(ctx context.Context, embeddings []Embedding) error
+
+	RecordAudit(ctx context.Context, entry *AuditEntry) error
+	// ListAudit lists an organization's audit entries, newest first
+	ListAudit(ctx context.Context, orgID uuid.UUID, filter AuditFilter) ([]AuditEntry, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new search repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// Search runs one ranked query over the requested tables, limited to rows the caller may see
+func (r *repository) Search(ctx context.Context, query Query) ([]Result, error) {
+	var parts []string
+	var args []interface{}
+
+	for _, source := range Sources {
+		if !containsType(query.Types, source.Type) {
+			continue
+		}
+		scope, scopeArgs := scopeFor(source.Type, query)
+
+		projectColumn := "NULL::uuid"
+		if source.ProjectColumn != "" {
+			projectColumn = source.ProjectColumn
+		}
+
+		parts = append(parts, fmt.Sprintf(`(SELECT '%s' AS type, id, %s AS title,
+				ts_headline(%s, coalesce(%s, ''), q, 'MaxWords=25, MinWords=8, MaxFragments=1') AS snippet,
+				ts_rank_cd(%s, q) AS rank, %s AS project_id, updated_at
+			FROM %s, websearch_to_tsquery(?::regconfig, ?) q
+			WHERE %s @@ q AND %s)`,
+			source.Type, source.TitleColumn,
+			LanguageColumn, source.BodyColumn,
+			VectorColumn, projectColumn,
+			source.Table,
+			VectorColumn, scope))
+		args = append(args, source.language(query.Languages), query.Text)
+		args = append(args, scopeArgs...)
+	}
+	if len(parts) == 0 {
+		return []Result{}, nil
+	}
+
+	sql := strings.Join(parts, " UNION ALL ") + " ORDER BY rank DESC, updated_at DESC LIMIT ?"
+	args = append(args, query.Limit)
+
+	var results []Result
+	if err := r.db.WithContext(ctx).Raw(sql, args...).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// userLanguageSQL maps the locale in column to the search language of its language
+// subtag, or to FallbackLanguage
+func userLanguageSQL(column string) (string, []interface{}) {
+	subtags := make([]string, 0, len(localeLanguages))
+	for subtag := range localeLanguages {
+		subtags = append(subtags, subtag)
+	}
+	sort.Strings(subtags)
+
+	var b strings.Builder
+	args := make([]interface{}, 0, 2*len(subtags)+1)
+	fmt.Fprintf(&b, "(CASE lower(split_part(replace(%s, '_', '-'), '-', 1))", column)
+	for _, subtag := range subtags {
+		b.WriteString(" WHEN ? THEN ?")
+		args = append(args, subtag, localeLanguages[subtag])
+	}
+	b.WriteString(" ELSE ? END)")
+	args = append(args, FallbackLanguage)
+	return b.String(), args
+}
+
+// organizationLanguageSQL reads the search.language organization setting from the
+// settings column, falling back to DefaultLanguage when it is unset or unsupported
+func organizationLanguageSQL(column string) (string, []interface{}) {
+	setting := column + "->'search'->>'language'"
+	return fmt.Sprintf("(CASE WHEN %s IN ? THEN %s ELSE ? END)", setting, setting),
+		[]interface{}{SupportedLanguages(), DefaultLanguage}
+}
+
+func (r *repository) Languages(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID) (Languages, error) {
+	userLanguage, args := userLanguageSQL("u.locale")
+	orgLanguage, orgArgs := organizationLanguageSQL("o.settings")
+	org := uuid.Nil
+	if orgID != nil {
+		org = *orgID
+	}
+	args = append(append(args, orgArgs...), org, DefaultLanguage, userID)
+
+	var row struct {
+		UserLanguage         string
+		OrganizationLanguage string
+	}
+	err := r.db.WithContext(ctx).Raw(fmt.Sprintf(`SELECT %s AS user_language,
+			coalesce((SELECT %s FROM organizations o WHERE o.id = ?), ?) AS organization_language
+		FROM users u WHERE u.id = ?`, userLanguage, orgLanguage), args...).Scan(&row).Error
+	return Languages{User: row.UserLanguage, Organization: row.OrganizationLanguage}, err
+}
+
+// Reindex compares each row's language with its owner's, so rows of organizations and
+// users whose language did not change are left alone
+func (r *repository) Reindex(ctx context.Context, scope ReindexScope) (int64, error) {
+	var reindexed int64
+	for _, source := range Sources {
+		var owner, ownerColumn, language string
+		var languageArgs []interface{}
+		var ownerID *uuid.UUID
+		if source.OrganizationColumn != "" {
+			if scope.UserID != nil {
+				continue
+			}
+			owner, ownerColumn, ownerID = "organizations", source.OrganizationColumn, scope.OrganizationID
+			language, languageArgs = organizationLanguageSQL("o.settings")
+		} else {
+			if scope.OrganizationID != nil {
+				continue
+			}
+			owner, ownerColumn, ownerID = "users", source.UserColumn, scope.UserID
+			language, languageArgs = userLanguageSQL("o.locale")
+		}
+
+		sql := fmt.Sprintf(`UPDATE %s s SET %s = %s::regconfig FROM %s o
+			WHERE o.id = s.%s AND s.%s IS DISTINCT FROM %s::regconfig`,
+			source.Table, LanguageColumn, language, owner,
+			ownerColumn, LanguageColumn, language)
+		args := append(append([]interface{}{}, languageArgs...), languageArgs...)
+		if ownerID != nil {
+			sql += " AND o.id = ?"
+			args = append(args, *ownerID)
+		}
+		if scope.CreatedSince != nil {
+			sql += " AND s.created_at >= ?"
+			args = append(args, *scope.CreatedSince)
+		}
+
+		result := r.db.WithContext(ctx).Exec(sql, args...)
+		if result.Error != nil {
+			return reindexed, fmt.Errorf("failed to reindex %s: %w", source.Table, result.Error)
+		}
+		reindexed += result.RowsAffected
+	}
+	return reindexed, nil
+}
+
+// SemanticAvailable reports whether the embedding table exists
+func (r *repository) SemanticAvailable(ctx context.Context) (bool, error) {
+	var available bool
+	err := r.db.WithContext(ctx).Raw("SELECT to_regclass(?) IS NOT NULL", EmbeddingTable).Scan(&available).Error
+	return available, err
+}
+
+// SemanticSearch ranks embedded rows by a blend of their cosine similarity to the query
+// vector and their full-text rank, so exact keyword matches still come out on top among
+// similar rows. Each type is only compared against embeddings of the model its vector
+// was made with.
+func (r *repository) SemanticSearch(ctx context.Context, query Query, vectors map[ResultType]QueryVector) ([]Result, error) {
+	var parts []string
+	var args []interface{}
+
+	for _, source := range Sources {
+		vector, ok := vectors[source.Type]
+		if !ok || !containsType(query.Types, source.Type) {
+			continue
+		}
+		scope, scopeArgs := scopeFor(source.Type, query)
+
+		projectColumn := "NULL::uuid"
+		if source.ProjectColumn != "" {
+			projectColumn = "s." + source.ProjectColumn
+		}
+
+		// ts_rank_cd normalization 32 scales the rank into [0, 1) like the similarity
+		parts = append(parts, fmt.Sprintf(`(SELECT '%s' AS type, s.id, s.%s AS title,
+				left(coalesce(s.%s, ''), 200) AS snippet,
+				? * (1 - (e.embedding <=> ?::vector)) + ? * ts_rank_cd(s.%s, q, 32) AS rank,
+				%s AS project_id, s.updated_at
+			FROM %s s JOIN %s e ON e.entity_type = '%s' AND e.entity_id = s.id,
+				websearch_to_tsquery(?::regconfig, ?) q
+			WHERE e.model = ? AND %s)`,
+			source.Type, source.TitleColumn,
+			source.BodyColumn,
+			VectorColumn,
+			projectColumn,
+			source.Table, EmbeddingTable, source.Type,
+			scope))
+		args = append(args, SemanticWeight, vectorLiteral(vector.Vector), 1-SemanticWeight,
+			source.language(query.Languages), query.Text, vector.Model)
+		args = append(args, scopeArgs...)
+	}
+	if len(parts) == 0 {
+		return []Result{}, nil
+	}
+
+	sql := strings.Join(parts, " UNION ALL ") + " ORDER BY rank DESC, updated_at DESC LIMIT ?"
+	args = append(args, query.Limit)
+
+	var results []Result
+	if err := r.db.WithContext(ctx).Raw(sql, args...).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// PendingEmbeddings returns rows of the semantic types that need to be embedded
+func (r *repository) PendingEmbeddings(ctx context.Context, limit, offset int) ([]EmbeddingSource, error) {
+	var parts []string
+	for _, source := range Sources {
+		if !containsType(SemanticTypes, source.Type) {
+			continue
+		}
+		orgColumn := "NULL::uuid"
+		if source.OrganizationColumn != "" {
+			orgColumn = "s." + source.OrganizationColumn
+		}
+		parts = append(parts, fmt.Sprintf(`(SELECT '%s' AS type, s.id, %s AS organization_id,
+				coalesce(s.%s, '') AS title, coalesce(s.%s, '') AS body, s.updated_at
+			FROM %s s LEFT JOIN %s e ON e.entity_type = '%s' AND e.entity_id = s.id
+			WHERE e.entity_id IS NULL OR e.source_updated_at < s.updated_at)`,
+			source.Type, orgColumn,
+			source.TitleColumn, source.BodyColumn,
+			source.Table, EmbeddingTable, source.Type))
+	}
+
+	sql := strings.Join(parts, " UNION ALL ") + " ORDER BY updated_at, id LIMIT ? OFFSET ?"
+	var pending []EmbeddingSource
+	if err := r.db.WithContext(ctx).Raw(sql, limit, offset).Scan(&pending).Error; err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// SaveEmbeddings inserts or replaces the embeddings of their entities
+func (r *repository) SaveEmbeddings(ctx context.Context, embeddings []Embedding) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, e := range embeddings {
+			err := tx.Exec(`INSERT INTO `+EmbeddingTable+` (entity_type, entity_id, model, embedding, source_updated_at, embedded_at)
+				VALUES (?, ?, ?, ?::vector, ?, now())
+				ON CONFLICT (entity_type, entity_id) DO UPDATE SET model = EXCLUDED.model,
+					embedding = EXCLUDED.embedding, source_updated_at = EXCLUDED.source_updated_at,
+					embedded_at = EXCLUDED.embedded_at`,
+				e.EntityType, e.EntityID, e.Model, vectorLiteral(e.Vector), e.SourceUpdatedAt).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// memberProjects selects the projects of an organization a user belongs to, owns or created
+const memberProjects = `SELECT id FROM projects WHERE organization_id = ? AND (owner_id = ? OR creator_id = ?)
+	UNION SELECT project_id FROM project_members WHERE user_id = ?`
+
+// scopeFor restricts a table to rows visible to the user: personal items they own, in
+// lists they own, tasks they created, are assigned or review, projects they own or
+// created, and the tasks and projects of their organization that their access covers.
+// Rows in the trash are left out.
+func scopeFor(resultType ResultType, query Query) (string, []interface{}) {
+	userID, orgID, access := query.UserID, query.OrganizationID, query.Access
+	switch resultType {
+	case ResultTask:
+		own := "creator_id = ? OR assignee_id = ? OR reviewer_id = ?"
+		ownArgs := []interface{}{userID, userID, userID}
+		switch {
+		case orgID == nil || !access.Tasks:
+			return "deleted_at IS NULL AND (" + own + ")", ownArgs
+		case access.AllProjects:
+			return "deleted_at IS NULL AND (organization_id = ? OR " + own + ")", append([]interface{}{*orgID}, ownArgs...)
+		default:
+			return "deleted_at IS NULL AND ((organization_id = ? AND project_id IN (" + memberProjects + ")) OR " + own + ")",
+				append([]interface{}{*orgID, *orgID, userID, userID, userID}, ownArgs...)
+		}
+	case ResultTodo:
+		return "deleted_at IS NULL AND user_id = ? AND list_id IN (SELECT id FROM todo_lists WHERE user_id = ?)", []interface{}{userID, userID}
+	case ResultEvent:
+		return "(user_id = ? OR id IN (SELECT event_id FROM event_collaborators WHERE user_id = ?))", []interface{}{userID, userID}
+	case ResultProject:
+		switch {
+		case orgID == nil || !access.Projects:
+			return "deleted_at IS NULL AND (creator_id = ? OR owner_id = ?)", []interface{}{userID, userID}
+		case access.AllProjects:
+			return "deleted_at IS NULL AND organization_id = ?", []interface{}{*orgID}
+		default:
+			return "deleted_at IS NULL AND id IN (" + memberProjects + ")", []interface{}{*orgID, userID, userID, userID}
+		}
+	default:
+		return "user_id = ?", []interface{}{userID}
+	}
+}
+
+// VisibleScope returns the condition that restricts the table of a result type to the
+// rows the caller of query may find, for listing those rows outside of a search
+func VisibleScope(resultType ResultType, query Query) (string, []interface{}) {
+	return scopeFor(resultType, query)
+}
+
+func containsType(types []ResultType, t ResultType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *repository) RecordAudit(ctx context.Context, entry *AuditEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *repository) ListAudit(ctx context.Context, orgID uuid.UUID, filter AuditFilter) ([]AuditEntry, int64, error) {
+	query := r.db.WithContext(ctx).Model(&AuditEntry{}).Where("organization_id = ?", orgID)
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []AuditEntry
+	err := query.Order("created_at DESC").
+		Offset(filter.Page * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&entries).Error
+	return entries, total, err
+}
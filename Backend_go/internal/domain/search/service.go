@@ -0,0 +1,175 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the interface for search business logic
+type Service interface {
+	Search(ctx context.Context, query Query) ([]Result, error)
+	SemanticSearch(ctx context.Context, query Query) ([]Result, error)
+	// Reindex brings the search language of rows up to date with their owners' settings
+	// right away, instead of at the indexer's next sweep
+	Reindex(ctx context.Context, scope ReindexScope) (int64, error)
+	// ListAudit lists the searches run in an organization, newest first
+	ListAudit(ctx context.Context, orgID uuid.UUID, filter AuditFilter) ([]AuditEntry, int64, error)
+}
+
+type service struct {
+	repo     Repository
+	embedder Embedder
+	audit    AuditPolicy
+}
+
+// NewService creates a new search service instance. embedder may be nil, in which case
+// semantic search is unavailable; audit may be nil, in which case no searches are audited.
+func NewService(repo Repository, embedder Embedder, audit AuditPolicy) Service {
+	return &service{repo: repo, embedder: embedder, audit: audit}
+}
+
+// Search validates the query and returns hits across the requested types, best match first
+func (s *service) Search(ctx context.Context, query Query) ([]Result, error) {
+	query, err := normalize(query, AllTypes)
+	if err != nil {
+		return nil, err
+	}
+	if query.Languages, err = s.languages(ctx, query); err != nil {
+		return nil, err
+	}
+	results, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, query, ModeFullText, len(results)); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SemanticSearch returns tasks and todos whose meaning is closest to the query, blended
+// with their full-text rank. Tasks are compared using the embedding model of the caller's
+// organization, todos using the server's default model.
+func (s *service) SemanticSearch(ctx context.Context, query Query) ([]Result, error) {
+	query, err := normalize(query, SemanticTypes)
+	if err != nil {
+		return nil, err
+	}
+	if s.embedder == nil {
+		return nil, ErrSemanticUnavailable
+	}
+	available, err := s.repo.SemanticAvailable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, ErrSemanticUnavailable
+	}
+	if query.Languages, err = s.languages(ctx, query); err != nil {
+		return nil, err
+	}
+
+	// Embed the query once per organization whose model is needed
+	byOrg := make(map[uuid.UUID]QueryVector)
+	vectors := make(map[ResultType]QueryVector, len(query.Types))
+	for _, t := range query.Types {
+		orgID := uuid.Nil
+		if t == ResultTask && query.OrganizationID != nil {
+			orgID = *query.OrganizationID
+		}
+		vector, ok := byOrg[orgID]
+		if !ok {
+			embedded, model, err := s.embedder.Embed(ctx, orgID, []string{query.Text})
+			if err != nil {
+				return nil, err
+			}
+			vector = QueryVector{Vector: embedded[0], Model: model}
+			byOrg[orgID] = vector
+		}
+		vectors[t] = vector
+	}
+
+	results, err := s.repo.SemanticSearch(ctx, query, vectors)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, query, ModeSemantic, len(results)); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *service) Reindex(ctx context.Context, scope ReindexScope) (int64, error) {
+	return s.repo.Reindex(ctx, scope)
+}
+
+// languages resolves the languages the query text is parsed in, so that it is stemmed
+// the same way as the rows it is matched against
+func (s *service) languages(ctx context.Context, query Query) (Languages, error) {
+	languages, err := s.repo.Languages(ctx, query.UserID, query.OrganizationID)
+	if err != nil {
+		return languages, err
+	}
+	if languages.User == "" {
+		languages.User = DefaultLanguage
+	}
+	if languages.Organization == "" {
+		languages.Organization = DefaultLanguage
+	}
+	return languages, nil
+}
+
+func (s *service) ListAudit(ctx context.Context, orgID uuid.UUID, filter AuditFilter) ([]AuditEntry, int64, error) {
+	if filter.PageSize <= 0 {
+		filter.PageSize = DefaultLimit
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	if filter.Page < 0 {
+		filter.Page = 0
+	}
+	return s.repo.ListAudit(ctx, orgID, filter)
+}
+
+// record adds a search run in an organization to its audit trail when the organization
+// asks for one. Results are withheld when the entry cannot be written, so no search goes
+// unaudited.
+func (s *service) record(ctx context.Context, query Query, mode Mode, resultCount int) error {
+	if s.audit == nil || query.OrganizationID == nil {
+		return nil
+	}
+	enabled, err := s.audit.SearchAuditEnabled(ctx, *query.OrganizationID)
+	if err != nil || !enabled {
+		return err
+	}
+	return s.repo.RecordAudit(ctx, newAuditEntry(query, mode, resultCount))
+}
+
+// normalize validates the query text and types and clamps the limit
+func normalize(query Query, allowed []ResultType) (Query, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if n := utf8.RuneCountInString(query.Text); n < MinQueryLength || n > MaxQueryLength {
+		return query, ErrInvalidQuery
+	}
+
+	if len(query.Types) == 0 {
+		query.Types = allowed
+	}
+	for _, t := range query.Types {
+		if !containsType(allowed, t) {
+			return query, ErrInvalidType
+		}
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultLimit
+	}
+	if query.Limit > MaxLimit {
+		query.Limit = MaxLimit
+	}
+	return query, nil
+}
@@ -0,0 +1,195 @@
+package settings
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxWorkflowDeadlineHours caps the default deadline of new workflows at a year
+	MaxWorkflowDeadlineHours = 24 * 366
+)
+
+var (
+	ErrInvalidStatus   = errors.New("enabled statuses must be distinct task statuses")
+	ErrInvalidPriority = errors.New("default priority must be Low, Medium, High or Urgent")
+	ErrInvalidBoard    = errors.New("board columns and WIP limits must name task statuses, limits must be positive, and swimlanes must be none, assignee or priority")
+	ErrInvalidSLA      = errors.New("SLA clock must be wall or business and the workflow deadline between 0 and 8784 hours")
+	ErrProjectSLA      = errors.New("SLA defaults are set on the organization; projects choose their own clock through the project clock")
+	ErrStatusConflict  = errors.New("enabled statuses and custom statuses cannot be set together")
+)
+
+// Swimlane groups the cards of a board
+type Swimlane string
+
+const (
+	SwimlaneNone     Swimlane = "none"
+	SwimlaneAssignee Swimlane = "assignee"
+	SwimlanePriority Swimlane = "priority"
+)
+
+// Source is the level a resolved setting comes from
+type Source string
+
+const (
+	SourceSystem       Source = "system"
+	SourceOrganization Source = "organization"
+	SourceProject      Source = "project"
+)
+
+// Settings are the task, SLA and board settings of an organization or project. Unset
+// fields are inherited: projects from their organization, organizations from the
+// system defaults.
+type Settings struct {
+	// EnabledStatuses are the statuses tasks may be in
+	EnabledStatuses []task.TaskStatus `json:"enabled_statuses,omitempty"`
+	// CustomStatuses replace the built-in statuses; tasks are stored with the built-in
+	// status each custom status maps to
+	CustomStatuses *task.StatusWorkflow `json:"custom_statuses,omitempty"`
+	// DefaultPriority is given to tasks created without one
+	DefaultPriority task.TaskPriority `json:"default_priority,omitempty"`
+	// SLA can only be set on organizations
+	SLA   *SLADefaults `json:"sla,omitempty"`
+	Board *BoardConfig `json:"board,omitempty"`
+}
+
+// SLADefaults are the clock and deadline new projects and workflows start with
+type SLADefaults struct {
+	// Clock is used by projects that have not chosen their own
+	Clock sla.ClockMode `json:"clock,omitempty"`
+	// WorkflowDeadlineHours sets the deadline of workflows created without one
+	WorkflowDeadlineHours int `json:"workflow_deadline_hours,omitempty"`
+}
+
+// BoardConfig is the layout of a task board. It is inherited as a whole.
+type BoardConfig struct {
+	// Columns are the statuses shown, in order; empty shows every enabled status
+	Columns []task.TaskStatus `json:"columns,omitempty"`
+	// WIPLimits caps the number of tasks in a status
+	WIPLimits map[task.TaskStatus]int `json:"wip_limits,omitempty"`
+	Swimlanes Swimlane                `json:"swimlanes,omitempty"`
+}
+
+// OrganizationDefaults are the settings every project and workflow of an organization
+// inherits
+type OrganizationDefaults struct {
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;primaryKey"`
+	Settings       Settings   `json:"settings" gorm:"type:jsonb;not null;default:'{}';serializer:json"`
+	UpdatedBy      *uuid.UUID `json:"updated_by,omitempty" gorm:"type:uuid"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (OrganizationDefaults) TableName() string {
+	return "organization_default_settings"
+}
+
+// ProjectOverrides are the settings a project sets instead of inheriting them
+type ProjectOverrides struct {
+	ProjectID      uuid.UUID  `json:"project_id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null;index"`
+	Settings       Settings   `json:"settings" gorm:"type:jsonb;not null;default:'{}';serializer:json"`
+	UpdatedBy      *uuid.UUID `json:"updated_by,omitempty" gorm:"type:uuid"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (ProjectOverrides) TableName() string {
+	return "project_settings"
+}
+
+// Resolved are the effective settings of an organization or project, with the level
+// each one comes from
+type Resolved struct {
+	OrganizationID  uuid.UUID         `json:"organization_id"`
+	ProjectID       *uuid.UUID        `json:"project_id,omitempty"`
+	EnabledStatuses []task.TaskStatus `json:"enabled_statuses"`
+	// CustomStatuses are set when the project or organization defines its own statuses.
+	// EnabledStatuses then lists the built-in statuses they map to.
+	CustomStatuses  *task.StatusWorkflow `json:"custom_statuses,omitempty"`
+	DefaultPriority task.TaskPriority    `json:"default_priority"`
+	SLA             SLADefaults          `json:"sla"`
+	Board           BoardConfig          `json:"board"`
+	Sources         map[string]Source    `json:"sources"`
+}
+
+// Keys of Resolved.Sources
+const (
+	KeyEnabledStatuses       = "enabled_statuses"
+	KeyCustomStatuses        = "custom_statuses"
+	KeyDefaultPriority       = "default_priority"
+	KeySLAClock              = "sla.clock"
+	KeyWorkflowDeadlineHours = "sla.workflow_deadline_hours"
+	KeyBoard                 = "board"
+)
+
+// AllStatuses are the statuses enabled when no level restricts them
+var AllStatuses = []task.TaskStatus{
+	task.TaskStatusUpcoming,
+	task.TaskStatusInProgress,
+	task.TaskStatusBlocked,
+	task.TaskStatusUnderReview,
+	task.TaskStatusDeferred,
+	task.TaskStatusCompleted,
+	task.TaskStatusCancelled,
+}
+
+// Validate checks the settings of an organization, or of a project when forProject is
+// set, and fills in the built-in statuses of custom statuses
+func (s *Settings) Validate(forProject bool) error {
+	if s.CustomStatuses != nil {
+		if len(s.EnabledStatuses) > 0 {
+			return ErrStatusConflict
+		}
+		if err := s.CustomStatuses.Normalize(); err != nil {
+			return err
+		}
+	}
+	seen := make(map[task.TaskStatus]bool, len(s.EnabledStatuses))
+	for _, status := range s.EnabledStatuses {
+		if !status.IsValid() || seen[status] {
+			return ErrInvalidStatus
+		}
+		seen[status] = true
+	}
+	if s.DefaultPriority != "" && !s.DefaultPriority.IsValid() {
+		return ErrInvalidPriority
+	}
+	if s.SLA != nil {
+		if forProject {
+			return ErrProjectSLA
+		}
+		if s.SLA.Clock != "" && s.SLA.Clock != sla.ClockWall && s.SLA.Clock != sla.ClockBusiness {
+			return ErrInvalidSLA
+		}
+		if s.SLA.WorkflowDeadlineHours < 0 || s.SLA.WorkflowDeadlineHours > MaxWorkflowDeadlineHours {
+			return ErrInvalidSLA
+		}
+	}
+	if s.Board != nil {
+		return s.Board.validate()
+	}
+	return nil
+}
+
+func (b *BoardConfig) validate() error {
+	seen := make(map[task.TaskStatus]bool, len(b.Columns))
+	for _, status := range b.Columns {
+		if !status.IsValid() || seen[status] {
+			return ErrInvalidBoard
+		}
+		seen[status] = true
+	}
+	for status, limit := range b.WIPLimits {
+		if !status.IsValid() || limit <= 0 {
+			return ErrInvalidBoard
+		}
+	}
+	switch b.Swimlanes {
+	case "", SwimlaneNone, SwimlaneAssignee, SwimlanePriority:
+		return nil
+	}
+	return ErrInvalidBoard
+}
@@ -0,0 +1,69 @@
+package settings
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for default settings data access
+type Repository interface {
+	// FindOrganizationDefaults retrieves the defaults of an organization, or nil when it
+	// has not set any
+	FindOrganizationDefaults(ctx context.Context, organizationID uuid.UUID) (*OrganizationDefaults, error)
+	SaveOrganizationDefaults(ctx context.Context, defaults *OrganizationDefaults) error
+	// FindProjectOverrides retrieves the overrides of a project, or nil when it has none
+	FindProjectOverrides(ctx context.Context, projectID uuid.UUID) (*ProjectOverrides, error)
+	SaveProjectOverrides(ctx context.Context, overrides *ProjectOverrides) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new default settings repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) FindOrganizationDefaults(ctx context.Context, organizationID uuid.UUID) (*OrganizationDefaults, error) {
+	var defaults OrganizationDefaults
+	err := r.db.WithContext(ctx).First(&defaults, "organization_id = ?", organizationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &defaults, nil
+}
+
+func (r *repository) SaveOrganizationDefaults(ctx context.Context, defaults *OrganizationDefaults) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"settings", "updated_by", "updated_at"}),
+	}).Create(defaults).Error
+}
+
+func (r *repository) FindProjectOverrides(ctx context.Context, projectID uuid.UUID) (*ProjectOverrides, error) {
+	var overrides ProjectOverrides
+	err := r.db.WithContext(ctx).First(&overrides, "project_id = ?", projectID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &overrides, nil
+}
+
+func (r *repository) SaveProjectOverrides(ctx context.Context, overrides *ProjectOverrides) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"settings", "updated_by", "updated_at"}),
+	}).Create(overrides).Error
+}
@@ -0,0 +1,245 @@
+package settings
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/google/uuid"
+)
+
+// Service defines the interface for organization defaults and project overrides. Callers
+// check that the project belongs to the organization before calling it.
+//
+// It implements task.Defaults, sla.Defaults and workflow.Defaults, so new tasks, project
+// clocks and workflows pick up the resolved settings.
+type Service interface {
+	// GetOrganizationDefaults returns the defaults the organization set, empty when it
+	// has not set any
+	GetOrganizationDefaults(ctx context.Context, organizationID uuid.UUID) (*OrganizationDefaults, error)
+	SetOrganizationDefaults(ctx context.Context, organizationID, userID uuid.UUID, settings Settings) (*OrganizationDefaults, error)
+	// GetProjectOverrides returns the settings the project overrides, empty when it
+	// inherits all of them
+	GetProjectOverrides(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectOverrides, error)
+	SetProjectOverrides(ctx context.Context, organizationID, projectID, userID uuid.UUID, settings Settings) (*ProjectOverrides, error)
+	// Resolve returns the effective settings of a project, or of the organization when
+	// projectID is uuid.Nil
+	Resolve(ctx context.Context, organizationID, projectID uuid.UUID) (*Resolved, error)
+
+	TaskDefaults(ctx context.Context, organizationID, projectID uuid.UUID) (task.ProjectDefaults, error)
+	DefaultClock(ctx context.Context, organizationID uuid.UUID) (sla.ClockMode, error)
+	WorkflowDeadline(ctx context.Context, organizationID uuid.UUID) (time.Duration, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService creates a new default settings service
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// systemDefaults are the settings of organizations that have not set their own
+func systemDefaults(organizationID uuid.UUID) *Resolved {
+	return &Resolved{
+		OrganizationID:  organizationID,
+		EnabledStatuses: append([]task.TaskStatus(nil), AllStatuses...),
+		DefaultPriority: task.TaskPriorityMedium,
+		SLA:             SLADefaults{Clock: sla.ClockWall},
+		Board:           BoardConfig{Swimlanes: SwimlaneNone},
+		Sources: map[string]Source{
+			KeyEnabledStatuses:       SourceSystem,
+			KeyCustomStatuses:        SourceSystem,
+			KeyDefaultPriority:       SourceSystem,
+			KeySLAClock:              SourceSystem,
+			KeyWorkflowDeadlineHours: SourceSystem,
+			KeyBoard:                 SourceSystem,
+		},
+	}
+}
+
+func (s *service) GetOrganizationDefaults(ctx context.Context, organizationID uuid.UUID) (*OrganizationDefaults, error) {
+	defaults, err := s.repo.FindOrganizationDefaults(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if defaults == nil {
+		return &OrganizationDefaults{OrganizationID: organizationID}, nil
+	}
+	return defaults, nil
+}
+
+// SetOrganizationDefaults validates and replaces the defaults of the organization
+func (s *service) SetOrganizationDefaults(ctx context.Context, organizationID, userID uuid.UUID, settings Settings) (*OrganizationDefaults, error) {
+	if err := settings.Validate(false); err != nil {
+		return nil, err
+	}
+	defaults := &OrganizationDefaults{
+		OrganizationID: organizationID,
+		Settings:       settings,
+		UpdatedBy:      &userID,
+		UpdatedAt:      time.Now(),
+	}
+	if err := s.repo.SaveOrganizationDefaults(ctx, defaults); err != nil {
+		return nil, err
+	}
+	return defaults, nil
+}
+
+func (s *service) GetProjectOverrides(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectOverrides, error) {
+	overrides, err := s.repo.FindProjectOverrides(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if overrides == nil {
+		return &ProjectOverrides{ProjectID: projectID, OrganizationID: organizationID}, nil
+	}
+	return overrides, nil
+}
+
+// SetProjectOverrides validates and replaces the overrides of the project. Settings left
+// unset go back to being inherited.
+func (s *service) SetProjectOverrides(ctx context.Context, organizationID, projectID, userID uuid.UUID, settings Settings) (*ProjectOverrides, error) {
+	if err := settings.Validate(true); err != nil {
+		return nil, err
+	}
+	overrides := &ProjectOverrides{
+		ProjectID:      projectID,
+		OrganizationID: organizationID,
+		Settings:       settings,
+		UpdatedBy:      &userID,
+		UpdatedAt:      time.Now(),
+	}
+	if err := s.repo.SaveProjectOverrides(ctx, overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+func (s *service) Resolve(ctx context.Context, organizationID, projectID uuid.UUID) (*Resolved, error) {
+	resolved := systemDefaults(organizationID)
+
+	defaults, err := s.repo.FindOrganizationDefaults(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if defaults != nil {
+		resolved.apply(defaults.Settings, SourceOrganization)
+	}
+
+	if projectID != uuid.Nil {
+		resolved.ProjectID = &projectID
+		overrides, err := s.repo.FindProjectOverrides(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		if overrides != nil {
+			resolved.apply(overrides.Settings, SourceProject)
+		}
+	}
+
+	resolved.Board.Columns = resolved.boardColumns()
+	return resolved, nil
+}
+
+// apply overrides the resolved settings with those set at a level
+func (r *Resolved) apply(settings Settings, source Source) {
+	// Custom statuses and enabled statuses replace each other
+	if settings.CustomStatuses != nil {
+		r.CustomStatuses = settings.CustomStatuses
+		r.EnabledStatuses = mappedStatuses(settings.CustomStatuses)
+		r.Sources[KeyCustomStatuses] = source
+		r.Sources[KeyEnabledStatuses] = source
+	} else if len(settings.EnabledStatuses) > 0 {
+		r.CustomStatuses = nil
+		r.EnabledStatuses = append([]task.TaskStatus(nil), settings.EnabledStatuses...)
+		r.Sources[KeyCustomStatuses] = source
+		r.Sources[KeyEnabledStatuses] = source
+	}
+	if settings.DefaultPriority != "" {
+		r.DefaultPriority = settings.DefaultPriority
+		r.Sources[KeyDefaultPriority] = source
+	}
+	if settings.SLA != nil {
+		if settings.SLA.Clock != "" {
+			r.SLA.Clock = settings.SLA.Clock
+			r.Sources[KeySLAClock] = source
+		}
+		if settings.SLA.WorkflowDeadlineHours > 0 {
+			r.SLA.WorkflowDeadlineHours = settings.SLA.WorkflowDeadlineHours
+			r.Sources[KeyWorkflowDeadlineHours] = source
+		}
+	}
+	if settings.Board != nil {
+		r.Board = *settings.Board
+		if r.Board.Swimlanes == "" {
+			r.Board.Swimlanes = SwimlaneNone
+		}
+		r.Sources[KeyBoard] = source
+	}
+}
+
+// mappedStatuses returns the distinct built-in statuses custom statuses map to
+func mappedStatuses(workflow *task.StatusWorkflow) []task.TaskStatus {
+	var statuses []task.TaskStatus
+	seen := make(map[task.TaskStatus]bool)
+	for _, cs := range workflow.Statuses {
+		if !seen[cs.Status] {
+			seen[cs.Status] = true
+			statuses = append(statuses, cs.Status)
+		}
+	}
+	return statuses
+}
+
+// boardColumns returns the configured columns that are enabled, or every enabled
+// status when none are configured
+func (r *Resolved) boardColumns() []task.TaskStatus {
+	if len(r.Board.Columns) == 0 {
+		return append([]task.TaskStatus(nil), r.EnabledStatuses...)
+	}
+	enabled := make(map[task.TaskStatus]bool, len(r.EnabledStatuses))
+	for _, status := range r.EnabledStatuses {
+		enabled[status] = true
+	}
+	columns := make([]task.TaskStatus, 0, len(r.Board.Columns))
+	for _, status := range r.Board.Columns {
+		if enabled[status] {
+			columns = append(columns, status)
+		}
+	}
+	return columns
+}
+
+// TaskDefaults returns the default priority, enabled statuses and custom statuses of the project
+func (s *service) TaskDefaults(ctx context.Context, organizationID, projectID uuid.UUID) (task.ProjectDefaults, error) {
+	resolved, err := s.Resolve(ctx, organizationID, projectID)
+	if err != nil {
+		return task.ProjectDefaults{}, err
+	}
+	return task.ProjectDefaults{
+		Priority: resolved.DefaultPriority,
+		Statuses: resolved.EnabledStatuses,
+		Workflow: resolved.CustomStatuses,
+	}, nil
+}
+
+// DefaultClock returns the clock of the organization's projects that have not chosen one
+func (s *service) DefaultClock(ctx context.Context, organizationID uuid.UUID) (sla.ClockMode, error) {
+	resolved, err := s.Resolve(ctx, organizationID, uuid.Nil)
+	if err != nil {
+		return "", err
+	}
+	return resolved.SLA.Clock, nil
+}
+
+// WorkflowDeadline returns how long after creation new workflows of the organization are due
+func (s *service) WorkflowDeadline(ctx context.Context, organizationID uuid.UUID) (time.Duration, error) {
+	resolved, err := s.Resolve(ctx, organizationID, uuid.Nil)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(resolved.SLA.WorkflowDeadlineHours) * time.Hour, nil
+}
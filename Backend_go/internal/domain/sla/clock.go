@@ -0,0 +1,135 @@
+package sla
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Clock measures how much time counts between two instants. Thresholds such as "due
+// within 72 hours" are compared against clock time, so on the business clock they
+// mean working time.
+type Clock interface {
+	Mode() ClockMode
+	// Elapsed is the clock time from from to to, negative when to is before from
+	Elapsed(from, to time.Time) time.Duration
+	// Describe formats a clock duration for people, such as "3 days"
+	Describe(d time.Duration) string
+}
+
+// WallClock counts every hour
+var WallClock Clock = wallClock{}
+
+type wallClock struct{}
+
+func (wallClock) Mode() ClockMode { return ClockWall }
+
+func (wallClock) Elapsed(from, to time.Time) time.Duration { return to.Sub(from) }
+
+// Describe formats a duration in whole days, or hours when under two days
+func (wallClock) Describe(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+	}
+	hours := int(d / time.Hour)
+	if hours == 1 {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", hours)
+}
+
+// businessClock counts the working hours of a schedule. A full working day counts as
+// 24 hours, so thresholds written in days read as working days.
+type businessClock struct {
+	loc        *time.Location
+	start, end time.Duration // offsets of the working day from midnight
+	days       [7]bool
+	holidays   map[string]bool
+}
+
+// NewBusinessClock creates the clock of an organization's working hours
+func NewBusinessClock(schedule *Schedule) (Clock, error) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, ErrInvalidSchedule
+	}
+	start, okStart := parseTimeOfDay(schedule.WorkdayStart)
+	end, okEnd := parseTimeOfDay(schedule.WorkdayEnd)
+	if !okStart || !okEnd || end <= start {
+		return nil, ErrInvalidSchedule
+	}
+
+	clock := &businessClock{loc: loc, start: start, end: end, holidays: make(map[string]bool, len(schedule.Holidays))}
+	for _, day := range schedule.Days {
+		index := slices.Index(weekdays, day)
+		if index < 0 {
+			return nil, ErrInvalidSchedule
+		}
+		clock.days[index] = true
+	}
+	if !slices.Contains(clock.days[:], true) {
+		return nil, ErrInvalidSchedule
+	}
+	for _, holiday := range schedule.Holidays {
+		clock.holidays[holiday.Date.Format(time.DateOnly)] = true
+	}
+	return clock, nil
+}
+
+func (c *businessClock) Mode() ClockMode { return ClockBusiness }
+
+func (c *businessClock) Elapsed(from, to time.Time) time.Duration {
+	if to.Before(from) {
+		return -c.Elapsed(to, from)
+	}
+
+	var worked time.Duration
+	from, to = from.In(c.loc), to.In(c.loc)
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, c.loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !c.workday(day) {
+			continue
+		}
+		// Building the bounds from the date keeps them on the wall time across DST changes
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, c.loc).Add(c.start)
+		end := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, c.loc).Add(c.end)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			worked += end.Sub(start)
+		}
+	}
+	return time.Duration(float64(worked) * float64(24*time.Hour) / float64(c.end-c.start))
+}
+
+// Describe formats a duration in working days, or working hours when under two days
+func (c *businessClock) Describe(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%d working days", int(d/(24*time.Hour)))
+	}
+	hours := int(time.Duration(float64(d)*float64(c.end-c.start)/float64(24*time.Hour)) / time.Hour)
+	if hours == 1 {
+		return "1 working hour"
+	}
+	return fmt.Sprintf("%d working hours", hours)
+}
+
+func (c *businessClock) workday(day time.Time) bool {
+	return c.days[day.Weekday()] && !c.holidays[day.Format(time.DateOnly)]
+}
+
+// weekdays are the accepted day names, indexed by time.Weekday, as in the
+// working_hours user preference
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// parseTimeOfDay reads HH:MM as an offset from midnight
+func parseTimeOfDay(value string) (time.Duration, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
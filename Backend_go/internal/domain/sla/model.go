@@ -0,0 +1,97 @@
+package sla
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ClockMode is how a project measures time for due-soon and staleness checks
+type ClockMode string
+
+const (
+	// ClockWall counts every hour
+	ClockWall ClockMode = "wall"
+	// ClockBusiness counts only the working hours of the organization, skipping holidays
+	ClockBusiness ClockMode = "business"
+)
+
+const (
+	// DefaultWorkdayStart and DefaultWorkdayEnd bound the working day of organizations
+	// that have not set their working hours
+	DefaultWorkdayStart = "09:00"
+	DefaultWorkdayEnd   = "17:00"
+	// MaxHolidays caps the holidays an organization can keep
+	MaxHolidays = 200
+)
+
+// DefaultWorkdays are the working days of organizations that have not set their own
+var DefaultWorkdays = []string{"mon", "tue", "wed", "thu", "fri"}
+
+var (
+	ErrInvalidSchedule = errors.New("working hours need a valid time zone, a start before the end as HH:MM, and weekdays")
+	ErrInvalidHoliday  = errors.New("holidays need a date as YYYY-MM-DD and a name, at most once per date")
+	ErrTooManyHolidays = errors.New("too many holidays")
+	ErrInvalidClock    = errors.New("clock must be wall or business")
+)
+
+// Schedule is the working week and holiday calendar of an organization, used by
+// projects on the business clock
+type Schedule struct {
+	OrganizationID uuid.UUID      `json:"organization_id" gorm:"type:uuid;primaryKey"`
+	Timezone       string         `json:"timezone" gorm:"size:64;not null;default:'UTC'"`
+	WorkdayStart   string         `json:"workday_start" gorm:"size:5;not null"`
+	WorkdayEnd     string         `json:"workday_end" gorm:"size:5;not null"`
+	Days           pq.StringArray `json:"days" gorm:"type:text[]"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	Holidays []Holiday `json:"holidays" gorm:"foreignKey:OrganizationID;references:OrganizationID"`
+}
+
+func (Schedule) TableName() string {
+	return "sla_schedules"
+}
+
+// Holiday is a day without working hours in the organization's time zone
+type Holiday struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_sla_holiday_date"`
+	Date           time.Time `json:"date" gorm:"type:date;not null;uniqueIndex:idx_sla_holiday_date"`
+	Name           string    `json:"name" gorm:"size:100;not null"`
+}
+
+func (Holiday) TableName() string {
+	return "sla_holidays"
+}
+
+// ProjectClock is the clock a project uses; projects without one use the default clock
+// of their organization
+type ProjectClock struct {
+	ProjectID      uuid.UUID `json:"project_id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;index"`
+	Mode           ClockMode `json:"mode" gorm:"type:varchar(20);not null"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// Inherited is set when the project has not chosen a clock and uses the default
+	Inherited bool `json:"inherited" gorm:"-"`
+}
+
+func (ProjectClock) TableName() string {
+	return "sla_project_clocks"
+}
+
+// ScheduleInput replaces the working hours and holidays of an organization
+type ScheduleInput struct {
+	Timezone     string
+	WorkdayStart string
+	WorkdayEnd   string
+	Days         []string
+	Holidays     []HolidayInput
+}
+
+// HolidayInput is one holiday, its date formatted as YYYY-MM-DD
+type HolidayInput struct {
+	Date string
+	Name string
+}
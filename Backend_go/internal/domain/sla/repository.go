@@ -0,0 +1,84 @@
+package sla
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for SLA clock data access
+type Repository interface {
+	// FindSchedule retrieves the working hours of an organization with its holidays,
+	// or nil when it has not set them
+	FindSchedule(ctx context.Context, organizationID uuid.UUID) (*Schedule, error)
+	// SaveSchedule replaces the working hours and holidays of the organization
+	SaveSchedule(ctx context.Context, schedule *Schedule) error
+	// FindProjectClock retrieves the clock of a project, or nil when it has none
+	FindProjectClock(ctx context.Context, projectID uuid.UUID) (*ProjectClock, error)
+	SaveProjectClock(ctx context.Context, clock *ProjectClock) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new SLA clock repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) FindSchedule(ctx context.Context, organizationID uuid.UUID) (*Schedule, error) {
+	var schedule Schedule
+	err := r.db.WithContext(ctx).
+		Preload("Holidays", func(db *gorm.DB) *gorm.DB { return db.Order("date ASC") }).
+		First(&schedule, "organization_id = ?", organizationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *repository) SaveSchedule(ctx context.Context, schedule *Schedule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit("Holidays").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"timezone", "workday_start", "workday_end", "days", "updated_at"}),
+		}).Create(schedule).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", schedule.OrganizationID).Delete(&Holiday{}).Error; err != nil {
+			return err
+		}
+		if len(schedule.Holidays) == 0 {
+			return nil
+		}
+		return tx.Create(&schedule.Holidays).Error
+	})
+}
+
+func (r *repository) FindProjectClock(ctx context.Context, projectID uuid.UUID) (*ProjectClock, error) {
+	var clock ProjectClock
+	err := r.db.WithContext(ctx).First(&clock, "project_id = ?", projectID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &clock, nil
+}
+
+func (r *repository) SaveProjectClock(ctx context.Context, clock *ProjectClock) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"mode", "updated_at"}),
+	}).Create(clock).Error
+}
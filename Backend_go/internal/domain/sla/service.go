@@ -0,0 +1,185 @@
+package sla
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Resolver picks the clock of a project
+type Resolver interface {
+	// ClockFor returns the business clock of the organization when the project uses it,
+	// directly or through the organization default, and the wall clock otherwise
+	ClockFor(ctx context.Context, organizationID, projectID uuid.UUID) (Clock, error)
+}
+
+// Service defines the interface for SLA clock operations. Callers check that the
+// project belongs to the organization before calling it.
+type Service interface {
+	Resolver
+	// GetSchedule returns the working hours of the organization, or the defaults when
+	// it has not set them
+	GetSchedule(ctx context.Context, organizationID uuid.UUID) (*Schedule, error)
+	SetSchedule(ctx context.Context, organizationID uuid.UUID, input ScheduleInput) (*Schedule, error)
+	// GetProjectClock returns the clock of the project, the organization default when
+	// it has not chosen one
+	GetProjectClock(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectClock, error)
+	SetProjectClock(ctx context.Context, organizationID, projectID uuid.UUID, mode ClockMode) (*ProjectClock, error)
+}
+
+// Defaults supplies the clock of projects that have not chosen their own
+type Defaults interface {
+	// DefaultClock returns the clock the organization chose for its projects, or
+	// ClockWall when it has not chosen one
+	DefaultClock(ctx context.Context, organizationID uuid.UUID) (ClockMode, error)
+}
+
+type service struct {
+	repo     Repository
+	defaults Defaults
+}
+
+// NewService creates a new SLA clock service. Projects without a clock use the
+// organization default from defaults, or the wall clock when defaults is nil.
+func NewService(repo Repository, defaults Defaults) Service {
+	return &service{repo: repo, defaults: defaults}
+}
+
+// defaultClock returns the clock of an organization's projects that have none
+func (s *service) defaultClock(ctx context.Context, organizationID uuid.UUID) (ClockMode, error) {
+	if s.defaults == nil {
+		return ClockWall, nil
+	}
+	return s.defaults.DefaultClock(ctx, organizationID)
+}
+
+// defaultSchedule is the working week of organizations that have not set their own
+func defaultSchedule(organizationID uuid.UUID) *Schedule {
+	return &Schedule{
+		OrganizationID: organizationID,
+		Timezone:       "UTC",
+		WorkdayStart:   DefaultWorkdayStart,
+		WorkdayEnd:     DefaultWorkdayEnd,
+		Days:           append([]string(nil), DefaultWorkdays...),
+		Holidays:       []Holiday{},
+	}
+}
+
+func (s *service) GetSchedule(ctx context.Context, organizationID uuid.UUID) (*Schedule, error) {
+	schedule, err := s.repo.FindSchedule(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return defaultSchedule(organizationID), nil
+	}
+	return schedule, nil
+}
+
+// SetSchedule validates and replaces the working hours and holidays of the organization
+func (s *service) SetSchedule(ctx context.Context, organizationID uuid.UUID, input ScheduleInput) (*Schedule, error) {
+	if len(input.Holidays) > MaxHolidays {
+		return nil, ErrTooManyHolidays
+	}
+
+	schedule := &Schedule{
+		OrganizationID: organizationID,
+		Timezone:       strings.TrimSpace(input.Timezone),
+		WorkdayStart:   input.WorkdayStart,
+		WorkdayEnd:     input.WorkdayEnd,
+		UpdatedAt:      time.Now(),
+		Holidays:       make([]Holiday, 0, len(input.Holidays)),
+	}
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	seenDays := make(map[string]bool, len(input.Days))
+	for _, day := range input.Days {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if !seenDays[day] {
+			seenDays[day] = true
+			schedule.Days = append(schedule.Days, day)
+		}
+	}
+
+	seenDates := make(map[string]bool, len(input.Holidays))
+	for _, holiday := range input.Holidays {
+		date, err := time.Parse(time.DateOnly, holiday.Date)
+		name := strings.TrimSpace(holiday.Name)
+		if err != nil || name == "" || len(name) > 100 || seenDates[holiday.Date] {
+			return nil, ErrInvalidHoliday
+		}
+		seenDates[holiday.Date] = true
+		schedule.Holidays = append(schedule.Holidays, Holiday{
+			ID:             uuid.New(),
+			OrganizationID: organizationID,
+			Date:           date,
+			Name:           name,
+		})
+	}
+	sort.Slice(schedule.Holidays, func(i, j int) bool { return schedule.Holidays[i].Date.Before(schedule.Holidays[j].Date) })
+
+	// Building the clock validates the time zone, hours and days
+	if _, err := NewBusinessClock(schedule); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *service) GetProjectClock(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectClock, error) {
+	clock, err := s.repo.FindProjectClock(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if clock == nil {
+		mode, err := s.defaultClock(ctx, organizationID)
+		if err != nil {
+			return nil, err
+		}
+		return &ProjectClock{ProjectID: projectID, OrganizationID: organizationID, Mode: mode, Inherited: true}, nil
+	}
+	return clock, nil
+}
+
+func (s *service) SetProjectClock(ctx context.Context, organizationID, projectID uuid.UUID, mode ClockMode) (*ProjectClock, error) {
+	if mode != ClockWall && mode != ClockBusiness {
+		return nil, ErrInvalidClock
+	}
+	clock := &ProjectClock{
+		ProjectID:      projectID,
+		OrganizationID: organizationID,
+		Mode:           mode,
+		UpdatedAt:      time.Now(),
+	}
+	if err := s.repo.SaveProjectClock(ctx, clock); err != nil {
+		return nil, err
+	}
+	return clock, nil
+}
+
+func (s *service) ClockFor(ctx context.Context, organizationID, projectID uuid.UUID) (Clock, error) {
+	projectClock, err := s.repo.FindProjectClock(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var mode ClockMode
+	if projectClock != nil {
+		mode = projectClock.Mode
+	} else if mode, err = s.defaultClock(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	if mode != ClockBusiness {
+		return WallClock, nil
+	}
+	schedule, err := s.GetSchedule(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	return NewBusinessClock(schedule)
+}
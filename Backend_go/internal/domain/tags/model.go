@@ -0,0 +1,180 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxNameLength = 50
+	// MaxEntityTags is the number of tags a single entity can carry
+	MaxEntityTags = 30
+	// MaxSearchTags is the number of tags a search can combine
+	MaxSearchTags = 10
+	DefaultLimit  = 20
+	MaxLimit      = 100
+)
+
+var (
+	ErrTagNotFound       = errors.New("tag not found")
+	ErrEntityNotFound    = errors.New("entity not found")
+	ErrInvalidInput      = errors.New("invalid tag input")
+	ErrDuplicateName     = errors.New("a tag with this name already exists, merge the tags instead")
+	ErrInvalidEntityType = errors.New("entity type must be task, todo, event, note or project")
+)
+
+// EntityType identifies the kind of entity a tag is put on
+type EntityType string
+
+const (
+	EntityTask    EntityType = "task"
+	EntityTodo    EntityType = "todo"
+	EntityEvent   EntityType = "event"
+	EntityNote    EntityType = "note"
+	EntityProject EntityType = "project"
+)
+
+// AllEntityTypes lists every kind of entity that can be tagged
+var AllEntityTypes = []EntityType{EntityTask, EntityTodo, EntityEvent, EntityNote, EntityProject}
+
+// IsValid checks if entities of the type can be tagged
+func (t EntityType) IsValid() bool {
+	_, ok := entitySources[t]
+	return ok
+}
+
+// Tag is a label of an organization that its members put on their tasks, todos, events,
+// meeting notes and projects. Names are unique in an organization regardless of case.
+type Tag struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex:idx_tag_name,priority:1"`
+	Name           string    `json:"name" gorm:"type:varchar(50);not null"`
+	// NameKey is the lowercased name the uniqueness of names is checked on
+	NameKey   string    `json:"-" gorm:"type:varchar(50);not null;uniqueIndex:idx_tag_name,priority:2"`
+	Color     string    `json:"color,omitempty" gorm:"type:varchar(7)"`
+	CreatedBy uuid.UUID `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Tag model
+func (Tag) TableName() string {
+	return "tags"
+}
+
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Assignment puts a tag on an entity
+type Assignment struct {
+	TagID          uuid.UUID  `json:"tag_id" gorm:"type:uuid;primaryKey"`
+	EntityType     EntityType `json:"entity_type" gorm:"type:varchar(20);primaryKey;index:idx_tag_assignment_entity,priority:1"`
+	EntityID       uuid.UUID  `json:"entity_id" gorm:"type:uuid;primaryKey;index:idx_tag_assignment_entity,priority:2"`
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null;index"`
+	CreatedBy      uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Assignment model
+func (Assignment) TableName() string {
+	return "tag_assignments"
+}
+
+// TagUsage is a tag with the number of entities carrying it
+type TagUsage struct {
+	Tag
+	Uses int64 `json:"uses"`
+}
+
+// Viewer is the member tagging or searching entities, and what their role lets them see
+// of the organization. Personal entities are only seen by their owners, and meeting
+// notes by those who see their event.
+type Viewer struct {
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+	Access         search.Access
+}
+
+// EntityRef points at a tagged entity
+type EntityRef struct {
+	Type EntityType `json:"type"`
+	ID   uuid.UUID  `json:"id"`
+}
+
+// CreateTagInput creates a tag
+type CreateTagInput struct {
+	Name  string
+	Color string
+}
+
+// UpdateTagInput renames or recolors a tag. Nil fields are left unchanged.
+type UpdateTagInput struct {
+	Name  *string
+	Color *string
+}
+
+// MergeResult reports a merge of tags into another
+type MergeResult struct {
+	Tag *Tag `json:"tag"`
+	// Merged is the number of tags merged and deleted
+	Merged int `json:"merged"`
+	// Moved is the number of entities that gained the tag merged into
+	Moved int64 `json:"moved"`
+}
+
+// SearchQuery looks for the entities carrying some or all of a set of tags
+type SearchQuery struct {
+	// Tags are names, matched regardless of case
+	Tags []string
+	// MatchAll requires entities to carry every tag instead of any of them
+	MatchAll bool
+	Types    []EntityType
+	Limit    int
+	Offset   int
+}
+
+// TaggedEntity is an entity found by a tag search
+type TaggedEntity struct {
+	Type      EntityType `json:"type"`
+	ID        uuid.UUID  `json:"id"`
+	Title     string     `json:"title"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Tags      []Tag      `json:"tags" gorm:"-"`
+}
+
+// SearchResult is a page of tagged entities, most recently updated first
+type SearchResult struct {
+	Entities []TaggedEntity `json:"entities"`
+	Total    int64          `json:"total"`
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// normalizeName trims a tag name and collapses the whitespace inside it
+func normalizeName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || len([]rune(name)) > MaxNameLength {
+		return "", fmt.Errorf("%w: name must be between 1 and %d characters", ErrInvalidInput, MaxNameLength)
+	}
+	return name, nil
+}
+
+func nameKey(name string) string {
+	return strings.ToLower(name)
+}
+
+func validColor(color string) bool {
+	return color == "" || colorPattern.MatchString(color)
+}
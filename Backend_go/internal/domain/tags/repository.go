@@ -0,0 +1,290 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// entitySource describes the table of a kind of tagged entity
+type entitySource struct {
+	table string
+	// title and project are the expressions of the entity's title and project
+	title   string
+	project string
+	// resultType is the search type whose visibility rules apply to the entity
+	resultType search.ResultType
+	// organizational entities belong to an organization and only carry its tags
+	organizational bool
+}
+
+var entitySources = map[EntityType]entitySource{
+	EntityTask:  {table: "tasks", title: "title", project: "project_id", resultType: search.ResultTask, organizational: true},
+	EntityTodo:  {table: "todos", title: "title", project: "NULL::uuid", resultType: search.ResultTodo},
+	EntityEvent: {table: "calendar_events", title: "title", project: "NULL::uuid", resultType: search.ResultEvent},
+	// Notes are titled after their event and seen by those who see it
+	EntityNote: {table: "meeting_notes", title: "(SELECT title FROM calendar_events WHERE calendar_events.id = meeting_notes.event_id)",
+		project: "NULL::uuid", resultType: search.ResultEvent},
+	EntityProject: {table: "projects", title: "name", project: "id", resultType: search.ResultProject, organizational: true},
+}
+
+// visibleScope restricts the table of an entity type to the rows the viewer may tag
+func visibleScope(entityType EntityType, viewer Viewer) (string, []interface{}) {
+	source := entitySources[entityType]
+	scope, args := search.VisibleScope(source.resultType, search.Query{
+		UserID:         viewer.UserID,
+		OrganizationID: &viewer.OrganizationID,
+		Access:         viewer.Access,
+	})
+	switch {
+	case entityType == EntityNote:
+		return "event_id IN (SELECT id FROM calendar_events WHERE " + scope + ")", args
+	case source.organizational:
+		// The viewer's own tasks and projects in other organizations stay out
+		return "organization_id = ? AND (" + scope + ")", append([]interface{}{viewer.OrganizationID}, args...)
+	default:
+		return scope, args
+	}
+}
+
+// Repository defines the interface for tag data access
+type Repository interface {
+	CreateTag(ctx context.Context, tag *Tag) error
+	UpdateTag(ctx context.Context, tag *Tag) error
+	// DeleteTag removes a tag from every entity and deletes it
+	DeleteTag(ctx context.Context, tag *Tag) error
+	GetTag(ctx context.Context, organizationID, id uuid.UUID) (*Tag, error)
+	// FindTags returns the tags of an organization with the given IDs
+	FindTags(ctx context.Context, organizationID uuid.UUID, ids []uuid.UUID) ([]Tag, error)
+	// FindTagsByName returns the tags of an organization with the given name keys
+	FindTagsByName(ctx context.Context, organizationID uuid.UUID, keys []string) ([]Tag, error)
+	// ListTags lists the tags of an organization by name, with how often each is used
+	ListTags(ctx context.Context, organizationID uuid.UUID) ([]TagUsage, error)
+	// MergeTags puts target on every entity carrying one of the sources, then deletes the
+	// sources. It returns the number of entities that gained target.
+	MergeTags(ctx context.Context, target *Tag, sourceIDs []uuid.UUID) (int64, error)
+
+	// EntityVisible reports whether the entity exists and the viewer may tag it
+	EntityVisible(ctx context.Context, viewer Viewer, ref EntityRef) (bool, error)
+	// EntityTags returns the tags of an organization on each of the entities
+	EntityTags(ctx context.Context, organizationID uuid.UUID, refs []EntityRef) (map[EntityRef][]Tag, error)
+	// SetEntityTags replaces the tags of an organization on an entity
+	SetEntityTags(ctx context.Context, viewer Viewer, ref EntityRef, tagIDs []uuid.UUID) error
+	// Search lists the entities the viewer may see that carry any, or with matchAll every,
+	// tag, and counts them
+	Search(ctx context.Context, viewer Viewer, tagIDs []uuid.UUID, query SearchQuery) ([]TaggedEntity, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new tag repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) CreateTag(ctx context.Context, tag *Tag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+func (r *repository) UpdateTag(ctx context.Context, tag *Tag) error {
+	return r.db.WithContext(ctx).Save(tag).Error
+}
+
+func (r *repository) DeleteTag(ctx context.Context, tag *Tag) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tag.ID).Delete(&Assignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Tag{}, "id = ?", tag.ID).Error
+	})
+}
+
+func (r *repository) GetTag(ctx context.Context, organizationID, id uuid.UUID) (*Tag, error) {
+	var tag Tag
+	err := r.db.WithContext(ctx).First(&tag, "id = ? AND organization_id = ?", id, organizationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *repository) FindTags(ctx context.Context, organizationID uuid.UUID, ids []uuid.UUID) ([]Tag, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var tags []Tag
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND id IN ?", organizationID, ids).
+		Order("name_key ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+func (r *repository) FindTagsByName(ctx context.Context, organizationID uuid.UUID, keys []string) ([]Tag, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var tags []Tag
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND name_key IN ?", organizationID, keys).
+		Order("name_key ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+func (r *repository) ListTags(ctx context.Context, organizationID uuid.UUID) ([]TagUsage, error) {
+	var tags []TagUsage
+	err := r.db.WithContext(ctx).Table("tags").
+		Select("tags.*, COUNT(tag_assignments.tag_id) AS uses").
+		Joins("LEFT JOIN tag_assignments ON tag_assignments.tag_id = tags.id").
+		Where("tags.organization_id = ?", organizationID).
+		Group("tags.id").
+		Order("tags.name_key ASC").
+		Scan(&tags).Error
+	return tags, err
+}
+
+func (r *repository) MergeTags(ctx context.Context, target *Tag, sourceIDs []uuid.UUID) (int64, error) {
+	var moved int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// An entity carrying several sources gains target once, as first tagged
+		result := tx.Exec(`INSERT INTO tag_assignments (tag_id, entity_type, entity_id, organization_id, created_by, created_at)
+			SELECT DISTINCT ON (entity_type, entity_id) ?, entity_type, entity_id, organization_id, created_by, created_at
+			FROM tag_assignments
+			WHERE organization_id = ? AND tag_id IN ?
+			ORDER BY entity_type, entity_id, created_at
+			ON CONFLICT DO NOTHING`, target.ID, target.OrganizationID, sourceIDs)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		if err := tx.Where("tag_id IN ?", sourceIDs).Delete(&Assignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("organization_id = ? AND id IN ?", target.OrganizationID, sourceIDs).Delete(&Tag{}).Error
+	})
+	return moved, err
+}
+
+func (r *repository) EntityVisible(ctx context.Context, viewer Viewer, ref EntityRef) (bool, error) {
+	source := entitySources[ref.Type]
+	scope, args := visibleScope(ref.Type, viewer)
+	var visible bool
+	err := r.db.WithContext(ctx).
+		Raw(fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = ? AND %s)", source.table, scope),
+			append([]interface{}{ref.ID}, args...)...).
+		Scan(&visible).Error
+	return visible, err
+}
+
+func (r *repository) EntityTags(ctx context.Context, organizationID uuid.UUID, refs []EntityRef) (map[EntityRef][]Tag, error) {
+	result := make(map[EntityRef][]Tag, len(refs))
+	if len(refs) == 0 {
+		return result, nil
+	}
+	ids := make([]uuid.UUID, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID
+	}
+
+	var rows []struct {
+		EntityType EntityType
+		EntityID   uuid.UUID
+		Tag        `gorm:"embedded"`
+	}
+	err := r.db.WithContext(ctx).Table("tag_assignments").
+		Select("tag_assignments.entity_type, tag_assignments.entity_id, tags.*").
+		Joins("JOIN tags ON tags.id = tag_assignments.tag_id").
+		Where("tag_assignments.organization_id = ? AND tag_assignments.entity_id IN ?", organizationID, ids).
+		Order("tags.name_key ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		ref := EntityRef{Type: row.EntityType, ID: row.EntityID}
+		result[ref] = append(result[ref], row.Tag)
+	}
+	return result, nil
+}
+
+func (r *repository) SetEntityTags(ctx context.Context, viewer Viewer, ref EntityRef, tagIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stale := tx.Where("organization_id = ? AND entity_type = ? AND entity_id = ?", viewer.OrganizationID, ref.Type, ref.ID)
+		if len(tagIDs) > 0 {
+			stale = stale.Where("tag_id NOT IN ?", tagIDs)
+		}
+		if err := stale.Delete(&Assignment{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		assignments := make([]Assignment, len(tagIDs))
+		for i, tagID := range tagIDs {
+			assignments[i] = Assignment{
+				TagID:          tagID,
+				EntityType:     ref.Type,
+				EntityID:       ref.ID,
+				OrganizationID: viewer.OrganizationID,
+				CreatedBy:      viewer.UserID,
+			}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignments).Error
+	})
+}
+
+func (r *repository) Search(ctx context.Context, viewer Viewer, tagIDs []uuid.UUID, query SearchQuery) ([]TaggedEntity, int64, error) {
+	tagged := "SELECT entity_id FROM tag_assignments WHERE organization_id = ? AND entity_type = ? AND tag_id IN ?"
+	if query.MatchAll {
+		tagged += " GROUP BY entity_id HAVING COUNT(*) = ?"
+	}
+
+	var parts []string
+	var args []interface{}
+	for _, entityType := range query.Types {
+		source := entitySources[entityType]
+		scope, scopeArgs := visibleScope(entityType, viewer)
+		parts = append(parts, fmt.Sprintf(`(SELECT '%s' AS type, id, %s AS title, %s AS project_id, updated_at
+			FROM %s WHERE id IN (%s) AND %s)`,
+			entityType, source.title, source.project, source.table, tagged, scope))
+		args = append(args, viewer.OrganizationID, entityType, tagIDs)
+		if query.MatchAll {
+			args = append(args, len(tagIDs))
+		}
+		args = append(args, scopeArgs...)
+	}
+	if len(parts) == 0 {
+		return []TaggedEntity{}, 0, nil
+	}
+	union := strings.Join(parts, " UNION ALL ")
+
+	var total int64
+	if err := r.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM ("+union+") AS tagged", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entities []TaggedEntity
+	err := r.db.WithContext(ctx).
+		Raw("SELECT * FROM ("+union+") AS tagged ORDER BY updated_at DESC, id LIMIT ? OFFSET ?",
+			append(args, query.Limit, query.Offset)...).
+		Scan(&entities).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entities, total, nil
+}
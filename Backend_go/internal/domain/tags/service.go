@@ -0,0 +1,298 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Service defines the interface for tag business logic
+type Service interface {
+	CreateTag(ctx context.Context, organizationID, userID uuid.UUID, input CreateTagInput) (*Tag, error)
+	ListTags(ctx context.Context, organizationID uuid.UUID) ([]TagUsage, error)
+	// UpdateTag renames or recolors a tag. Entities refer to tags by ID, so they carry
+	// the new name at once.
+	UpdateTag(ctx context.Context, organizationID, tagID uuid.UUID, input UpdateTagInput) (*Tag, error)
+	DeleteTag(ctx context.Context, organizationID, tagID uuid.UUID) error
+	// MergeTags moves the entities of the sources to the target and deletes the sources
+	MergeTags(ctx context.Context, organizationID, targetID uuid.UUID, sourceIDs []uuid.UUID) (*MergeResult, error)
+
+	// EntityTags returns the tags of the viewer's organization on an entity they may see
+	EntityTags(ctx context.Context, viewer Viewer, ref EntityRef) ([]Tag, error)
+	// SetEntityTags replaces the tags of the viewer's organization on an entity they may see
+	SetEntityTags(ctx context.Context, viewer Viewer, ref EntityRef, tagIDs []uuid.UUID) ([]Tag, error)
+	// Search finds the entities of every type the viewer may see that carry the tags
+	Search(ctx context.Context, viewer Viewer, query SearchQuery) (*SearchResult, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService creates a new tag service
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) CreateTag(ctx context.Context, organizationID, userID uuid.UUID, input CreateTagInput) (*Tag, error) {
+	name, err := normalizeName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !validColor(input.Color) {
+		return nil, fmt.Errorf("%w: color must be a hex color such as #1a2b3c", ErrInvalidInput)
+	}
+	if err := s.checkNameFree(ctx, organizationID, uuid.Nil, name); err != nil {
+		return nil, err
+	}
+
+	tag := &Tag{
+		OrganizationID: organizationID,
+		Name:           name,
+		NameKey:        nameKey(name),
+		Color:          input.Color,
+		CreatedBy:      userID,
+	}
+	if err := s.repo.CreateTag(ctx, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *service) ListTags(ctx context.Context, organizationID uuid.UUID) ([]TagUsage, error) {
+	return s.repo.ListTags(ctx, organizationID)
+}
+
+func (s *service) UpdateTag(ctx context.Context, organizationID, tagID uuid.UUID, input UpdateTagInput) (*Tag, error) {
+	tag, err := s.repo.GetTag(ctx, organizationID, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name, err := normalizeName(*input.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkNameFree(ctx, organizationID, tag.ID, name); err != nil {
+			return nil, err
+		}
+		tag.Name = name
+		tag.NameKey = nameKey(name)
+	}
+	if input.Color != nil {
+		if !validColor(*input.Color) {
+			return nil, fmt.Errorf("%w: color must be a hex color such as #1a2b3c", ErrInvalidInput)
+		}
+		tag.Color = *input.Color
+	}
+
+	if err := s.repo.UpdateTag(ctx, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// checkNameFree returns ErrDuplicateName when another tag of the organization than except
+// has the name
+func (s *service) checkNameFree(ctx context.Context, organizationID, except uuid.UUID, name string) error {
+	existing, err := s.repo.FindTagsByName(ctx, organizationID, []string{nameKey(name)})
+	if err != nil {
+		return err
+	}
+	for _, tag := range existing {
+		if tag.ID != except {
+			return ErrDuplicateName
+		}
+	}
+	return nil
+}
+
+func (s *service) DeleteTag(ctx context.Context, organizationID, tagID uuid.UUID) error {
+	tag, err := s.repo.GetTag(ctx, organizationID, tagID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteTag(ctx, tag)
+}
+
+func (s *service) MergeTags(ctx context.Context, organizationID, targetID uuid.UUID, sourceIDs []uuid.UUID) (*MergeResult, error) {
+	target, err := s.repo.GetTag(ctx, organizationID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := uniqueIDs(sourceIDs)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag to merge is required", ErrInvalidInput)
+	}
+	for _, id := range ids {
+		if id == targetID {
+			return nil, fmt.Errorf("%w: a tag cannot be merged into itself", ErrInvalidInput)
+		}
+	}
+	sources, err := s.repo.FindTags(ctx, organizationID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) != len(ids) {
+		return nil, ErrTagNotFound
+	}
+
+	moved, err := s.repo.MergeTags(ctx, target, ids)
+	if err != nil {
+		return nil, err
+	}
+	return &MergeResult{Tag: target, Merged: len(ids), Moved: moved}, nil
+}
+
+func (s *service) EntityTags(ctx context.Context, viewer Viewer, ref EntityRef) ([]Tag, error) {
+	if err := s.checkEntity(ctx, viewer, ref); err != nil {
+		return nil, err
+	}
+	tags, err := s.repo.EntityTags(ctx, viewer.OrganizationID, []EntityRef{ref})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(tags[ref]), nil
+}
+
+func (s *service) SetEntityTags(ctx context.Context, viewer Viewer, ref EntityRef, tagIDs []uuid.UUID) ([]Tag, error) {
+	if err := s.checkEntity(ctx, viewer, ref); err != nil {
+		return nil, err
+	}
+	ids := uniqueIDs(tagIDs)
+	if len(ids) > MaxEntityTags {
+		return nil, fmt.Errorf("%w: an entity can carry at most %d tags", ErrInvalidInput, MaxEntityTags)
+	}
+	tags, err := s.repo.FindTags(ctx, viewer.OrganizationID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) != len(ids) {
+		return nil, ErrTagNotFound
+	}
+
+	if err := s.repo.SetEntityTags(ctx, viewer, ref, ids); err != nil {
+		return nil, err
+	}
+	return nonNil(tags), nil
+}
+
+// checkEntity returns ErrEntityNotFound for entities the viewer may not see, so their
+// existence is not revealed
+func (s *service) checkEntity(ctx context.Context, viewer Viewer, ref EntityRef) error {
+	if !ref.Type.IsValid() {
+		return ErrInvalidEntityType
+	}
+	visible, err := s.repo.EntityVisible(ctx, viewer, ref)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrEntityNotFound
+	}
+	return nil
+}
+
+func (s *service) Search(ctx context.Context, viewer Viewer, query SearchQuery) (*SearchResult, error) {
+	keys := make([]string, 0, len(query.Tags))
+	seen := make(map[string]bool, len(query.Tags))
+	for _, name := range query.Tags {
+		name, err := normalizeName(name)
+		if err != nil {
+			return nil, err
+		}
+		if key := nameKey(name); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 || len(keys) > MaxSearchTags {
+		return nil, fmt.Errorf("%w: search for between 1 and %d tags", ErrInvalidInput, MaxSearchTags)
+	}
+	types := make([]EntityType, 0, len(query.Types))
+	for _, t := range query.Types {
+		if !t.IsValid() {
+			return nil, ErrInvalidEntityType
+		}
+		if !containsType(types, t) {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		types = AllEntityTypes
+	}
+	query.Types = types
+	if query.Limit <= 0 {
+		query.Limit = DefaultLimit
+	}
+	if query.Limit > MaxLimit {
+		query.Limit = MaxLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	// Unknown tags match nothing, so every tag is required only when all are known
+	tags, err := s.repo.FindTagsByName(ctx, viewer.OrganizationID, keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 || (query.MatchAll && len(tags) != len(keys)) {
+		return &SearchResult{Entities: []TaggedEntity{}}, nil
+	}
+	tagIDs := make([]uuid.UUID, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+
+	entities, total, err := s.repo.Search(ctx, viewer, tagIDs, query)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]EntityRef, len(entities))
+	for i, entity := range entities {
+		refs[i] = EntityRef{Type: entity.Type, ID: entity.ID}
+	}
+	entityTags, err := s.repo.EntityTags(ctx, viewer.OrganizationID, refs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entities {
+		entities[i].Tags = nonNil(entityTags[refs[i]])
+	}
+	if entities == nil {
+		entities = []TaggedEntity{}
+	}
+	return &SearchResult{Entities: entities, Total: total}, nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func containsType(types []EntityType, t EntityType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNil(tags []Tag) []Tag {
+	if tags == nil {
+		return []Tag{}
+	}
+	return tags
+}
@@ -0,0 +1,43 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxCommentLength bounds the body of a task comment
+const MaxCommentLength = 10000
+
+var (
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrInvalidComment    = errors.New("comment body must be between 1 and 10000 characters")
+	ErrCommentNotAllowed = errors.New("only the author or the task creator can delete a comment")
+)
+
+// TaskComment is a message left on a task
+type TaskComment struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"type:uuid;not null;index:idx_task_comment_task_created,priority:1"`
+	AuthorID  uuid.UUID `json:"author_id" gorm:"type:uuid;not null;index"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index:idx_task_comment_task_created,priority:2"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the TaskComment model
+func (TaskComment) TableName() string {
+	return "task_comments"
+}
+
+// BeforeCreate is called before creating a new comment record
+func (c *TaskComment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = time.Now()
+	return nil
+}
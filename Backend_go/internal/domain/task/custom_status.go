@@ -0,0 +1,142 @@
+package task
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// MaxCustomStatuses caps the statuses a project can define
+const MaxCustomStatuses = 30
+
+// ErrInvalidWorkflow is returned for custom statuses that cannot be used
+var ErrInvalidWorkflow = errors.New("custom statuses need 1 to 30 distinct keys of lowercase letters, digits and underscores, a name, a todo, in_progress or done category with a built-in status of that category, and transitions between defined keys")
+
+// StatusCategory is the canonical stage of a status. Reports group statuses by it.
+type StatusCategory string
+
+const (
+	CategoryTodo       StatusCategory = "todo"
+	CategoryInProgress StatusCategory = "in_progress"
+	CategoryDone       StatusCategory = "done"
+)
+
+// CategoryOf returns the category of a built-in status
+func CategoryOf(status TaskStatus) StatusCategory {
+	switch status {
+	case TaskStatusUpcoming, TaskStatusDeferred:
+		return CategoryTodo
+	case TaskStatusCompleted, TaskStatusCancelled:
+		return CategoryDone
+	default:
+		return CategoryInProgress
+	}
+}
+
+// categoryStatus is the built-in status custom statuses of a category are stored as by default
+var categoryStatus = map[StatusCategory]TaskStatus{
+	CategoryTodo:       TaskStatusUpcoming,
+	CategoryInProgress: TaskStatusInProgress,
+	CategoryDone:       TaskStatusCompleted,
+}
+
+var customStatusKey = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+// CustomStatus is a status defined by a project. Tasks in it are stored with its
+// built-in Status, so analytics, reports and due date checks keep working.
+type CustomStatus struct {
+	Key      string         `json:"key"`
+	Name     string         `json:"name"`
+	Category StatusCategory `json:"category"`
+	// Status is the built-in status of the same category the custom status maps to.
+	// It defaults to Upcoming, In Progress or Completed.
+	Status TaskStatus `json:"status,omitempty"`
+}
+
+// StatusWorkflow is the set of custom statuses of a project and the moves between them
+type StatusWorkflow struct {
+	// Statuses are listed in board order; new tasks start in the first todo status
+	Statuses []CustomStatus `json:"statuses"`
+	// Transitions lists the statuses each status may move to. Statuses without an entry
+	// may move to any other.
+	Transitions map[string][]string `json:"transitions,omitempty"`
+}
+
+// Normalize validates the workflow and fills in the built-in status of each custom status
+func (w *StatusWorkflow) Normalize() error {
+	if len(w.Statuses) == 0 || len(w.Statuses) > MaxCustomStatuses {
+		return ErrInvalidWorkflow
+	}
+	keys := make(map[string]bool, len(w.Statuses))
+	for i := range w.Statuses {
+		cs := &w.Statuses[i]
+		cs.Name = strings.TrimSpace(cs.Name)
+		if !customStatusKey.MatchString(cs.Key) || keys[cs.Key] || cs.Name == "" || len(cs.Name) > 100 {
+			return ErrInvalidWorkflow
+		}
+		keys[cs.Key] = true
+
+		defaultStatus, ok := categoryStatus[cs.Category]
+		if !ok {
+			return ErrInvalidWorkflow
+		}
+		if cs.Status == "" {
+			cs.Status = defaultStatus
+		}
+		if !cs.Status.IsValid() || CategoryOf(cs.Status) != cs.Category {
+			return ErrInvalidWorkflow
+		}
+	}
+	for from, targets := range w.Transitions {
+		if !keys[from] {
+			return ErrInvalidWorkflow
+		}
+		for _, to := range targets {
+			if !keys[to] {
+				return ErrInvalidWorkflow
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns the custom status named by requested: its key, or else the first custom
+// status mapped to the built-in status of that name
+func (w *StatusWorkflow) Find(requested string) (CustomStatus, bool) {
+	for _, cs := range w.Statuses {
+		if cs.Key == requested {
+			return cs, true
+		}
+	}
+	for _, cs := range w.Statuses {
+		if string(cs.Status) == requested {
+			return cs, true
+		}
+	}
+	return CustomStatus{}, false
+}
+
+// Initial returns the status new tasks start in: the first todo status, or the first
+// status when there is none
+func (w *StatusWorkflow) Initial() CustomStatus {
+	for _, cs := range w.Statuses {
+		if cs.Category == CategoryTodo {
+			return cs
+		}
+	}
+	return w.Statuses[0]
+}
+
+// Allows reports whether a task may move from one custom status to another
+func (w *StatusWorkflow) Allows(from, to string) bool {
+	targets, ok := w.Transitions[from]
+	if !ok {
+		return true
+	}
+	for _, target := range targets {
+		if target == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package task
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrStatusDisabled is returned when a task is put in a status its project does not use
+var ErrStatusDisabled = errors.New("status is not enabled in this project")
+
+// ProjectDefaults are the task settings of a project, inherited from its organization
+// unless the project overrides them
+type ProjectDefaults struct {
+	// Priority is given to tasks created without one
+	Priority TaskPriority
+	// Statuses are the statuses tasks of the project may be in; empty enables all
+	Statuses []TaskStatus
+	// Workflow, when set, replaces the built-in statuses with the project's own
+	Workflow *StatusWorkflow
+}
+
+// Defaults resolves the task settings of projects
+type Defaults interface {
+	TaskDefaults(ctx context.Context, organizationID, projectID uuid.UUID) (ProjectDefaults, error)
+}
+
+// Allows reports whether the project uses the built-in status
+func (d ProjectDefaults) Allows(status TaskStatus) bool {
+	if len(d.Statuses) == 0 {
+		return true
+	}
+	for _, s := range d.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// InitialStatus returns the status of a new task and its custom status key. Tasks
+// created without a status start in the first todo status of the project's workflow,
+// or else in Upcoming, or the first enabled status when Upcoming is not enabled.
+func (d ProjectDefaults) InitialStatus(requested TaskStatus) (TaskStatus, string, error) {
+	if d.Workflow != nil {
+		if requested == "" {
+			initial := d.Workflow.Initial()
+			return initial.Status, initial.Key, nil
+		}
+		target, ok := d.Workflow.Find(string(requested))
+		if !ok {
+			return "", "", ErrStatusDisabled
+		}
+		return target.Status, target.Key, nil
+	}
+
+	if requested == "" {
+		if d.Allows(TaskStatusUpcoming) {
+			return TaskStatusUpcoming, "", nil
+		}
+		return d.Statuses[0], "", nil
+	}
+	if !requested.IsValid() {
+		return "", "", ErrInvalidInput
+	}
+	if !d.Allows(requested) {
+		return "", "", ErrStatusDisabled
+	}
+	return requested, "", nil
+}
+
+// projectDefaults returns the settings of the task's project, or the built-in ones
+// when no source is configured
+func (s *service) projectDefaults(ctx context.Context, organizationID, projectID uuid.UUID) (ProjectDefaults, error) {
+	if s.defaults == nil {
+		return ProjectDefaults{Priority: TaskPriorityMedium}, nil
+	}
+	return s.defaults.TaskDefaults(ctx, organizationID, projectID)
+}
+
+// resolveStatus checks that the task may move to the requested status and returns the
+// built-in status and custom status key to store. In projects with custom statuses the
+// request names one of them, by key or by its built-in status, and the project's
+// transitions apply. Otherwise the request is a built-in status, checked against the
+// built-in transitions when strict is set.
+func (s *service) resolveStatus(ctx context.Context, t *Task, requested TaskStatus, strict bool) (TaskStatus, string, error) {
+	defaults, err := s.projectDefaults(ctx, t.OrganizationID, t.ProjectID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if w := defaults.Workflow; w != nil {
+		target, ok := w.Find(string(requested))
+		if !ok {
+			return "", "", ErrStatusDisabled
+		}
+		current := t.CustomStatus
+		if current == "" {
+			if cs, ok := w.Find(string(t.Status)); ok {
+				current = cs.Key
+			}
+		}
+		if current != "" && current != target.Key && !w.Allows(current, target.Key) {
+			return "", "", ErrInvalidTransition
+		}
+		return target.Status, target.Key, nil
+	}
+
+	if !requested.IsValid() {
+		return "", "", ErrInvalidInput
+	}
+	if !defaults.Allows(requested) {
+		return "", "", ErrStatusDisabled
+	}
+	if strict && !isValidStatusTransition(t.Status, requested) {
+		return "", "", ErrInvalidTransition
+	}
+	return requested, "", nil
+}
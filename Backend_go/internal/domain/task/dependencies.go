@@ -0,0 +1,315 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDependencyCycle   = errors.New("dependencies would form a cycle")
+	ErrInvalidDependency = errors.New("dependencies must be other tasks in the same project")
+)
+
+// defaultTaskHours is the remaining work assumed for an open task without an estimate
+const defaultTaskHours = 8.0
+
+// DependencyLink is a task reached by following dependencies from another task
+type DependencyLink struct {
+	Task Task `json:"task"`
+	// Depth is 1 for direct dependencies and dependents and grows along the chain
+	Depth int `json:"depth"`
+}
+
+// DependencyGraph is a task with everything it waits on and everything waiting on it
+type DependencyGraph struct {
+	Task Task `json:"task"`
+	// Upstream are the tasks this task depends on, directly or through others
+	Upstream []DependencyLink `json:"upstream"`
+	// Downstream are the tasks that depend on this task, directly or through others
+	Downstream []DependencyLink `json:"downstream"`
+}
+
+// ScheduledTask is a task placed on the project schedule. Times are in hours of remaining
+// work from now.
+type ScheduledTask struct {
+	Task           Task    `json:"task"`
+	RemainingHours float64 `json:"remaining_hours"`
+	EarliestStart  float64 `json:"earliest_start"`
+	EarliestFinish float64 `json:"earliest_finish"`
+	LatestStart    float64 `json:"latest_start"`
+	LatestFinish   float64 `json:"latest_finish"`
+	// Slack is how long the task can slip without delaying the project
+	Slack    float64 `json:"slack"`
+	Critical bool    `json:"critical"`
+}
+
+// CriticalPath is the longest chain of dependent work in a project
+type CriticalPath struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	// TotalHours is the remaining work along the critical path
+	TotalHours float64 `json:"total_hours"`
+	// Path lists the critical chain, first task first
+	Path []ScheduledTask `json:"path"`
+	// Tasks lists every open or completed task of the project in dependency order
+	Tasks []ScheduledTask `json:"tasks"`
+}
+
+// projectTasks returns the tasks matching the filter, which names the project, by ID
+func (s *service) projectTasks(ctx context.Context, filter TaskFilter) ([]Task, map[uuid.UUID]*Task, error) {
+	filter.Page, filter.PageSize = 0, 0
+	tasks, _, err := s.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uuid.UUID]*Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+	return tasks, byID, nil
+}
+
+// validateDependencies checks that every dependency is another task of the project and
+// that depending on them would not make the task wait on itself. It returns the
+// dependencies without duplicates.
+func (s *service) validateDependencies(ctx context.Context, taskID, projectID uuid.UUID, dependencies []uuid.UUID) ([]uuid.UUID, error) {
+	if len(dependencies) == 0 {
+		return dependencies, nil
+	}
+	_, byID, err := s.projectTasks(ctx, TaskFilter{ProjectID: &projectID})
+	if err != nil {
+		return nil, err
+	}
+
+	unique := make([]uuid.UUID, 0, len(dependencies))
+	seen := make(map[uuid.UUID]bool, len(dependencies))
+	for _, id := range dependencies {
+		if id == taskID {
+			return nil, ErrDependencyCycle
+		}
+		if _, ok := byID[id]; !ok {
+			return nil, ErrInvalidDependency
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	// The task would wait on itself when it is upstream of one of its new dependencies
+	visited := make(map[uuid.UUID]bool)
+	for stack := append([]uuid.UUID{}, unique...); len(stack) > 0; {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == taskID {
+			return nil, ErrDependencyCycle
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		if t, ok := byID[id]; ok {
+			stack = append(stack, t.Dependencies...)
+		}
+	}
+	return unique, nil
+}
+
+// GetDependencies returns the task with its upstream and downstream dependency chains,
+// nearest first
+func (s *service) GetDependencies(ctx context.Context, id uuid.UUID) (*DependencyGraph, error) {
+	root, err := s.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tasks, byID, err := s.projectTasks(ctx, TaskFilter{ProjectID: &root.ProjectID})
+	if err != nil {
+		return nil, err
+	}
+	byID[root.ID] = root
+
+	dependents := make(map[uuid.UUID][]uuid.UUID)
+	for _, t := range tasks {
+		for _, depID := range t.Dependencies {
+			dependents[depID] = append(dependents[depID], t.ID)
+		}
+	}
+
+	return &DependencyGraph{
+		Task: *root,
+		Upstream: walkDependencies(root.ID, byID, func(t *Task) []uuid.UUID {
+			return t.Dependencies
+		}),
+		Downstream: walkDependencies(root.ID, byID, func(t *Task) []uuid.UUID {
+			return dependents[t.ID]
+		}),
+	}, nil
+}
+
+// walkDependencies follows next breadth first from the start task, so every task is
+// reported at its shortest depth
+func walkDependencies(start uuid.UUID, byID map[uuid.UUID]*Task, next func(*Task) []uuid.UUID) []DependencyLink {
+	links := []DependencyLink{}
+	seen := map[uuid.UUID]bool{start: true}
+	level := []uuid.UUID{start}
+	for depth := 1; len(level) > 0; depth++ {
+		var following []uuid.UUID
+		for _, id := range level {
+			for _, nextID := range next(byID[id]) {
+				t, ok := byID[nextID]
+				if !ok || seen[nextID] {
+					continue
+				}
+				seen[nextID] = true
+				links = append(links, DependencyLink{Task: *t, Depth: depth})
+				following = append(following, nextID)
+			}
+		}
+		level = following
+	}
+	return links
+}
+
+// GetCriticalPath schedules the project's remaining work by its dependencies and returns
+// the chain of tasks that decides when the project can finish. Cancelled tasks and
+// dependencies outside the project are ignored.
+func (s *service) GetCriticalPath(ctx context.Context, projectID uuid.UUID, filter TaskFilter) (*CriticalPath, error) {
+	filter.ProjectID = &projectID
+	tasks, _, err := s.projectTasks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []Task
+	for _, t := range tasks {
+		if t.Status != TaskStatusCancelled {
+			active = append(active, t)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool { return active[i].CreatedAt.Before(active[j].CreatedAt) })
+
+	index := make(map[uuid.UUID]int, len(active))
+	for i, t := range active {
+		index[t.ID] = i
+	}
+	predecessors := make([][]int, len(active))
+	successors := make([][]int, len(active))
+	waiting := make([]int, len(active))
+	for i, t := range active {
+		seen := make(map[int]bool)
+		for _, depID := range t.Dependencies {
+			dep, ok := index[depID]
+			if !ok || dep == i || seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			predecessors[i] = append(predecessors[i], dep)
+			successors[dep] = append(successors[dep], i)
+			waiting[i]++
+		}
+	}
+
+	// Topological order; tasks left over sit on a cycle saved before dependencies were validated
+	order := make([]int, 0, len(active))
+	for i := range active {
+		if waiting[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for n := 0; n < len(order); n++ {
+		for _, next := range successors[order[n]] {
+			if waiting[next]--; waiting[next] == 0 {
+				order = append(order, next)
+			}
+		}
+	}
+	if len(order) != len(active) {
+		return nil, ErrDependencyCycle
+	}
+
+	scheduled := make([]ScheduledTask, len(active))
+	end := 0.0
+	for _, i := range order {
+		st := ScheduledTask{Task: active[i], RemainingHours: remainingHours(&active[i])}
+		for _, p := range predecessors[i] {
+			st.EarliestStart = math.Max(st.EarliestStart, scheduled[p].EarliestFinish)
+		}
+		st.EarliestFinish = st.EarliestStart + st.RemainingHours
+		end = math.Max(end, st.EarliestFinish)
+		scheduled[i] = st
+	}
+	for n := len(order) - 1; n >= 0; n-- {
+		i := order[n]
+		st := &scheduled[i]
+		st.LatestFinish = end
+		for _, next := range successors[i] {
+			st.LatestFinish = math.Min(st.LatestFinish, scheduled[next].LatestStart)
+		}
+		st.LatestStart = st.LatestFinish - st.RemainingHours
+		st.Slack = st.LatestStart - st.EarliestStart
+		st.Critical = st.Slack < 1e-9
+	}
+
+	// Walk back from the task finishing last along predecessors with no slack
+	var path []int
+	current := -1
+	for _, i := range order {
+		if scheduled[i].Critical && (current < 0 || scheduled[i].EarliestFinish > scheduled[current].EarliestFinish) {
+			current = i
+		}
+	}
+	for current >= 0 {
+		path = append(path, current)
+		previous := -1
+		for _, p := range predecessors[current] {
+			if scheduled[p].Critical && math.Abs(scheduled[p].EarliestFinish-scheduled[current].EarliestStart) < 1e-9 {
+				previous = p
+				break
+			}
+		}
+		current = previous
+	}
+
+	result := &CriticalPath{
+		ProjectID:  projectID,
+		TotalHours: roundHours(end),
+		Path:       make([]ScheduledTask, 0, len(path)),
+		Tasks:      make([]ScheduledTask, 0, len(order)),
+	}
+	for n := len(path) - 1; n >= 0; n-- {
+		result.Path = append(result.Path, roundSchedule(scheduled[path[n]]))
+	}
+	for _, i := range order {
+		result.Tasks = append(result.Tasks, roundSchedule(scheduled[i]))
+	}
+	return result, nil
+}
+
+// remainingHours is the work left on a task: none once completed, otherwise what is left
+// of its estimate, or defaultTaskHours when it has none
+func remainingHours(t *Task) float64 {
+	switch {
+	case t.Status == TaskStatusCompleted:
+		return 0
+	case t.EstimatedHours > 0:
+		return math.Max(0, t.EstimatedHours-t.ActualHours)
+	}
+	return defaultTaskHours
+}
+
+func roundSchedule(st ScheduledTask) ScheduledTask {
+	st.RemainingHours = roundHours(st.RemainingHours)
+	st.EarliestStart = roundHours(st.EarliestStart)
+	st.EarliestFinish = roundHours(st.EarliestFinish)
+	st.LatestStart = roundHours(st.LatestStart)
+	st.LatestFinish = roundHours(st.LatestFinish)
+	st.Slack = roundHours(st.Slack)
+	return st
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -0,0 +1,498 @@
+package task
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryRepository keeps tasks, comments and analytics in memory, for tests and the demo
+// server. Records are stored by value so callers never share them with the store.
+type memoryRepository struct {
+	mu        sync.RWMutex
+	tasks     map[uuid.UUID]Task
+	deleted   map[uuid.UUID]Task
+	comments  map[uuid.UUID]TaskComment
+	analytics []TaskAnalytics
+}
+
+// NewMemoryRepository creates a TaskRepository that keeps everything in memory
+func NewMemoryRepository() TaskRepository {
+	return &memoryRepository{
+		tasks:    make(map[uuid.UUID]Task),
+		deleted:  make(map[uuid.UUID]Task),
+		comments: make(map[uuid.UUID]TaskComment),
+	}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, task *Task) error {
+	if err := task.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// New tasks go to the bottom of their column
+	if task.Position == 0 {
+		var last float64
+		for _, t := range r.tasks {
+			if t.ProjectID == task.ProjectID && t.Status == task.Status && t.Position > last {
+				last = t.Position
+			}
+		}
+		task.Position = last + positionGap
+	}
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+func (r *memoryRepository) FindAll(ctx context.Context, filter TaskFilter) ([]Task, int64, error) {
+	r.mu.RLock()
+	tasks := []Task{}
+	for _, t := range r.tasks {
+		switch {
+		case filter.OrganizationID != nil && t.OrganizationID != *filter.OrganizationID,
+			filter.ProjectID != nil && t.ProjectID != *filter.ProjectID,
+			filter.Status != nil && t.Status != *filter.Status,
+			filter.Priority != nil && t.Priority != *filter.Priority,
+			filter.AssigneeID != nil && (t.AssigneeID == nil || *t.AssigneeID != *filter.AssigneeID),
+			filter.CreatorID != nil && t.CreatorID != *filter.CreatorID,
+			filter.ReviewerID != nil && (t.ReviewerID == nil || *t.ReviewerID != *filter.ReviewerID),
+			filter.StartDate != nil && filter.EndDate != nil &&
+				(t.CreatedAt.Before(*filter.StartDate) || t.CreatedAt.After(*filter.EndDate)),
+			filter.DueDateStart != nil && (t.DueDate == nil || t.DueDate.Before(*filter.DueDateStart)),
+			filter.DueDateEnd != nil && (t.DueDate == nil || !t.DueDate.Before(*filter.DueDateEnd)),
+			!filter.IncludeArchived && t.ArchivedAt != nil:
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	r.mu.RUnlock()
+
+	if filter.Keyset != nil {
+		return pagination.Slice(filter.Keyset, tasks, (*Task).PageCursor), 0, nil
+	}
+
+	if filter.Sort == TaskSortPriorityScore && filter.Weights != nil {
+		rescore(tasks, *filter.Weights)
+	}
+	// Project tasks come back in board order
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if filter.Sort == TaskSortPriorityScore {
+			if a.PriorityScore != b.PriorityScore {
+				return a.PriorityScore > b.PriorityScore
+			}
+			if (a.DueDate == nil) != (b.DueDate == nil) || a.DueDate != nil && !a.DueDate.Equal(*b.DueDate) {
+				return dueBefore(&a, &b)
+			}
+		} else if filter.ProjectID != nil {
+			if a.Status != b.Status {
+				return a.Status < b.Status
+			}
+			if a.Position != b.Position {
+				return a.Position < b.Position
+			}
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	total := int64(len(tasks))
+	pageSize := filter.PageSize
+	if pageSize == 0 {
+		pageSize = 10000
+	}
+	start := filter.Page * pageSize
+	if start >= len(tasks) {
+		return []Task{}, total, nil
+	}
+	end := start + pageSize
+	if end > len(tasks) {
+		end = len(tasks)
+	}
+	return tasks[start:end], total, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, task *Task) error {
+	if err := task.BeforeUpdate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tasks[task.ID]; !ok {
+		return ErrTaskNotFound
+	}
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+// UpdateWithDescriptionVersion saves the task only if its stored description version still matches
+func (r *memoryRepository) UpdateWithDescriptionVersion(ctx context.Context, task *Task, expectedVersion int) error {
+	if err := task.BeforeUpdate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.tasks[task.ID]
+	if !ok || stored.DescriptionVersion != expectedVersion {
+		return ErrEditConflict
+	}
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	task.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	task.DeletedBy = deletedBy
+	r.deleted[id] = task
+	delete(r.tasks, id)
+	return nil
+}
+
+func (r *memoryRepository) FindDeleted(ctx context.Context, userID uuid.UUID, since time.Time) ([]Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tasks := []Task{}
+	for _, t := range r.deleted {
+		if t.DeletedAt.Time.Before(since) {
+			continue
+		}
+		if t.CreatorID == userID || (t.DeletedBy != nil && *t.DeletedBy == userID) {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].DeletedAt.Time.After(tasks[j].DeletedAt.Time) })
+	return tasks, nil
+}
+
+func (r *memoryRepository) FindDeletedByID(ctx context.Context, id uuid.UUID) (*Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	task, ok := r.deleted[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+func (r *memoryRepository) Restore(ctx context.Context, id uuid.UUID, parentTaskID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.deleted[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	task.DeletedAt = gorm.DeletedAt{}
+	task.DeletedBy = nil
+	task.ParentTaskID = parentTaskID
+	task.UpdatedAt = time.Now()
+	r.tasks[id] = task
+	delete(r.deleted, id)
+	return nil
+}
+
+func (r *memoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var purged int64
+	for id, t := range r.deleted {
+		if t.DeletedAt.Time.Before(before) {
+			delete(r.deleted, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *memoryRepository) FindDescendants(ctx context.Context, id uuid.UUID) ([]Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var tasks []Task
+	seen := map[uuid.UUID]bool{id: true}
+	for queue := []uuid.UUID{id}; len(queue) > 0; queue = queue[1:] {
+		for _, t := range r.tasks {
+			if t.ParentTaskID != nil && *t.ParentTaskID == queue[0] && !seen[t.ID] {
+				seen[t.ID] = true
+				tasks = append(tasks, t)
+				queue = append(queue, t.ID)
+			}
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) })
+	return tasks, nil
+}
+
+func (r *memoryRepository) AddActualHours(ctx context.Context, id uuid.UUID, hours float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	task.ActualHours = math.Max(0, task.ActualHours+hours)
+	task.UpdatedAt = time.Now()
+	r.tasks[id] = task
+	return nil
+}
+
+func (r *memoryRepository) UpdateProgressMetrics(ctx context.Context, id uuid.UUID, metrics map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	task.ProgressMetrics = metrics
+	r.tasks[id] = task
+	return nil
+}
+
+func (r *memoryRepository) Reparent(ctx context.Context, fromParentID uuid.UUID, toParentID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, t := range r.tasks {
+		if t.ParentTaskID != nil && *t.ParentTaskID == fromParentID {
+			t.ParentTaskID = toParentID
+			r.tasks[id] = t
+		}
+	}
+	return nil
+}
+
+// Move places the task the same way the database repository does, renumbering the column
+// when its neighbours are too close together
+func (r *memoryRepository) FindOpen(ctx context.Context) ([]Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var tasks []Task
+	for _, t := range r.tasks {
+		if t.Status != TaskStatusCompleted && t.Status != TaskStatusCancelled {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryRepository) UpdateRiskFactors(ctx context.Context, id uuid.UUID, factors map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	task.RiskFactors = factors
+	r.tasks[id] = task
+	return nil
+}
+
+func (r *memoryRepository) UpdatePriority(ctx context.Context, id uuid.UUID, score float64, factors PriorityFactors) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	task.PriorityScore = score
+	task.PriorityFactors = factors
+	r.tasks[id] = task
+	return nil
+}
+
+func (r *memoryRepository) LatestActivity(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	wanted := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	latest := make(map[uuid.UUID]time.Time)
+	for _, a := range r.analytics {
+		if wanted[a.TaskID] && a.Timestamp.After(latest[a.TaskID]) {
+			latest[a.TaskID] = a.Timestamp
+		}
+	}
+	return latest, nil
+}
+
+func (r *memoryRepository) Move(ctx context.Context, id uuid.UUID, status TaskStatus, customStatus string, index int) (*Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	moved, ok := r.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+
+	var column []Task
+	for _, t := range r.tasks {
+		if t.ProjectID == moved.ProjectID && t.Status == status && t.CustomStatus == customStatus && t.ID != id {
+			column = append(column, t)
+		}
+	}
+	sort.Slice(column, func(i, j int) bool {
+		if column[i].Position != column[j].Position {
+			return column[i].Position < column[j].Position
+		}
+		return column[i].CreatedAt.Before(column[j].CreatedAt)
+	})
+	if index < 0 || index > len(column) {
+		index = len(column)
+	}
+
+	position, ok := positionAt(column, index)
+	if !ok {
+		for i, t := range column {
+			slot := i
+			if i >= index {
+				slot++
+			}
+			t.Position = float64(slot+1) * positionGap
+			r.tasks[t.ID] = t
+		}
+		position = float64(index+1) * positionGap
+	}
+
+	moved.Status = status
+	moved.CustomStatus = customStatus
+	moved.Position = position
+	moved.UpdatedAt = time.Now()
+	r.tasks[id] = moved
+	return &moved, nil
+}
+
+func (r *memoryRepository) CreateComment(ctx context.Context, comment *TaskComment) error {
+	if err := comment.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.comments[comment.ID] = *comment
+	return nil
+}
+
+func (r *memoryRepository) FindCommentByID(ctx context.Context, id uuid.UUID) (*TaskComment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, ErrCommentNotFound
+	}
+	return &comment, nil
+}
+
+// ListComments returns the comments of a task, oldest first
+func (r *memoryRepository) ListComments(ctx context.Context, taskID uuid.UUID, page, pageSize int) ([]TaskComment, int64, error) {
+	r.mu.RLock()
+	comments := []TaskComment{}
+	for _, c := range r.comments {
+		if c.TaskID == taskID {
+			comments = append(comments, c)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt) })
+
+	total := int64(len(comments))
+	start := page * pageSize
+	if start >= len(comments) {
+		return []TaskComment{}, total, nil
+	}
+	end := start + pageSize
+	if pageSize <= 0 || end > len(comments) {
+		end = len(comments)
+	}
+	return comments[start:end], total, nil
+}
+
+func (r *memoryRepository) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.comments[id]; !ok {
+		return ErrCommentNotFound
+	}
+	delete(r.comments, id)
+	return nil
+}
+
+func (r *memoryRepository) RecordTaskActivity(ctx context.Context, analytics *TaskAnalytics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if analytics.ID == uuid.Nil {
+		analytics.ID = uuid.New()
+	}
+	if analytics.Timestamp.IsZero() {
+		analytics.Timestamp = time.Now()
+	}
+	r.analytics = append(r.analytics, *analytics)
+	return nil
+}
+
+func (r *memoryRepository) GetTaskAnalytics(ctx context.Context, filter AnalyticsFilter) ([]TaskAnalytics, int64, error) {
+	r.mu.RLock()
+	matches := []TaskAnalytics{}
+	for _, a := range r.analytics {
+		switch {
+		case filter.TaskID != nil && a.TaskID != *filter.TaskID,
+			filter.UserID != nil && a.UserID != *filter.UserID,
+			filter.Action != nil && a.Action != *filter.Action,
+			filter.StartTime != nil && a.Timestamp.Before(*filter.StartTime),
+			filter.EndTime != nil && a.Timestamp.After(*filter.EndTime):
+			continue
+		}
+		matches = append(matches, a)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Timestamp.After(matches[j].Timestamp) })
+	total := int64(len(matches))
+	start := filter.Page * filter.PageSize
+	if start >= len(matches) {
+		return []TaskAnalytics{}, total, nil
+	}
+	end := start + filter.PageSize
+	if filter.PageSize <= 0 || end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end], total, nil
+}
+
+func (r *memoryRepository) GetTaskActivitySummary(ctx context.Context, taskID uuid.UUID, startTime, endTime time.Time) (map[string]int, error) {
+	return r.activitySummary(func(a *TaskAnalytics) bool { return a.TaskID == taskID }, startTime, endTime), nil
+}
+
+func (r *memoryRepository) GetUserTaskActivitySummary(ctx context.Context, userID uuid.UUID, startTime, endTime time.Time) (map[string]int, error) {
+	return r.activitySummary(func(a *TaskAnalytics) bool { return a.UserID == userID }, startTime, endTime), nil
+}
+
+// activitySummary counts the matching activity between startTime and endTime by action
+func (r *memoryRepository) activitySummary(match func(*TaskAnalytics) bool, startTime, endTime time.Time) map[string]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	summary := make(map[string]int)
+	for _, a := range r.analytics {
+		if match(&a) && !a.Timestamp.Before(startTime) && !a.Timestamp.After(endTime) {
+			summary[a.Action]++
+		}
+	}
+	return summary
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -53,10 +54,13 @@ func (u *UUIDSlice) Scan(value interface{}) error {
 
 // Task represents a task in the system
 type Task struct {
-	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	Title          string       `json:"title" gorm:"not null"`
-	Description    string       `json:"description"`
-	Status         TaskStatus   `json:"status" gorm:"not null;default:'Upcoming';index:idx_task_status"`
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Title       string     `json:"title" gorm:"not null"`
+	Description string     `json:"description"`
+	Status      TaskStatus `json:"status" gorm:"not null;default:'Upcoming';index:idx_task_status;index:idx_task_board,priority:2"`
+	// CustomStatus is the key of the project's custom status the task is in, if the
+	// project defines its own statuses. Status then holds the built-in status it maps to.
+	CustomStatus   string       `json:"custom_status,omitempty" gorm:"type:varchar(50);not null;default:''"`
 	Priority       TaskPriority `json:"priority" gorm:"not null;default:'Medium';index:idx_task_priority"`
 	CreatedAt      time.Time    `json:"created_at" gorm:"not null;default:current_timestamp"`
 	UpdatedAt      time.Time    `json:"updated_at" gorm:"not null;default:current_timestamp"`
@@ -65,7 +69,7 @@ type Task struct {
 	ReviewerID     *uuid.UUID   `json:"reviewer_id,omitempty" gorm:"type:uuid;"`
 	CategoryID     *uuid.UUID   `json:"category_id,omitempty" gorm:"type:uuid"`
 	ParentTaskID   *uuid.UUID   `json:"parent_task_id,omitempty" gorm:"type:uuid"`
-	ProjectID      uuid.UUID    `json:"project_id" gorm:"type:uuid;not null;index:idx_task_project"`
+	ProjectID      uuid.UUID    `json:"project_id" gorm:"type:uuid;not null;index:idx_task_project;index:idx_task_board,priority:1"`
 	OrganizationID uuid.UUID    `json:"organization_id" gorm:"type:uuid;not null;index:idx_task_org"`
 
 	EstimatedHours float64    `json:"estimated_hours,omitempty"`
@@ -83,6 +87,26 @@ type Task struct {
 	ProgressMetrics map[string]interface{} `json:"progress_metrics,omitempty" gorm:"type:jsonb"`
 	Blockers        []string               `json:"blockers,omitempty" gorm:"type:jsonb"`
 	RiskFactors     map[string]interface{} `json:"risk_factors,omitempty" gorm:"type:jsonb"`
+
+	// PriorityScore ranks open tasks from 0 to 100 under the default weights. The
+	// scoring job refreshes it and the factors it was computed from.
+	PriorityScore   float64         `json:"priority_score" gorm:"not null;default:0;index:idx_task_priority_score"`
+	PriorityFactors PriorityFactors `json:"priority_factors" gorm:"embedded;embeddedPrefix:priority_"`
+
+	// Position orders the task within its status column on the project board. Positions are
+	// sparse so a move usually only rewrites the moved task.
+	Position float64 `json:"position" gorm:"not null;default:0;index:idx_task_board,priority:3"`
+
+	// DescriptionVersion is bumped on every description change so concurrent editors can detect conflicts
+	DescriptionVersion int `json:"description_version" gorm:"not null;default:1"`
+
+	// ArchivedAt is set when the retention policy of the organization archives the closed
+	// task. Archived tasks are left out of task lists unless asked for.
+	ArchivedAt *time.Time `json:"archived_at,omitempty" gorm:"index"`
+
+	// Deleted tasks stay in the trash until the purge job removes them
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	DeletedBy *uuid.UUID     `json:"deleted_by,omitempty" gorm:"type:uuid"`
 }
 
 // CreateTaskRequest represents the request body for creating a task
@@ -189,6 +213,9 @@ func (t *Task) BeforeCreate(tx *gorm.DB) error {
 	if t.Priority == "" {
 		t.Priority = TaskPriorityMedium
 	}
+	if t.DescriptionVersion == 0 {
+		t.DescriptionVersion = 1
+	}
 	t.CreatedAt = time.Now()
 	t.UpdatedAt = time.Now()
 	return t.Validate()
@@ -206,3 +233,8 @@ func (t *Task) DashboardUserID() uuid.UUID {
 	}
 	return t.CreatorID
 }
+
+// PageCursor is the position of the task in keyset pages, which follow creation time
+func (t *Task) PageCursor() pagination.Cursor {
+	return pagination.Cursor{Time: t.CreatedAt, ID: t.ID}
+}
@@ -0,0 +1,208 @@
+package task
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TaskSort orders task lists
+type TaskSort string
+
+const (
+	// TaskSortDefault keeps the board order of project tasks and creation order otherwise
+	TaskSortDefault TaskSort = ""
+	// TaskSortPriorityScore puts the tasks with the highest priority score first
+	TaskSortPriorityScore TaskSort = "priority_score"
+)
+
+func (s TaskSort) IsValid() bool {
+	return s == TaskSortDefault || s == TaskSortPriorityScore
+}
+
+const (
+	// PriorityDueHorizon is how far ahead a due date starts to raise the score. Like
+	// StaleAfter it is measured on the project's clock.
+	PriorityDueHorizon = 14 * 24 * time.Hour
+	// PriorityDependentsCap is the number of open dependent tasks that gives full
+	// dependency pressure
+	PriorityDependentsCap = 3
+	// MaxPriorityWeight bounds each weight a user can set
+	MaxPriorityWeight = 10.0
+)
+
+// PriorityFactors are the parts of a task's priority score, each from 0 to 1. They are
+// stored so lists can be ranked with any weights without recomputing them.
+type PriorityFactors struct {
+	// Due grows as the due date nears and is 1 once the task is overdue
+	Due float64 `json:"due"`
+	// Level is the task's own priority, from Low to Urgent
+	Level float64 `json:"level"`
+	// Dependencies grows with the open tasks waiting on this one
+	Dependencies float64 `json:"dependencies"`
+	// Staleness grows with the time since the task last saw activity
+	Staleness float64 `json:"staleness"`
+	// ScoredAt is when the factors were last computed, nil until then
+	ScoredAt *time.Time `json:"scored_at,omitempty"`
+}
+
+// PriorityWeights say how much each factor counts toward the score. Only their ratios
+// matter.
+type PriorityWeights struct {
+	Due          float64 `json:"due"`
+	Priority     float64 `json:"priority"`
+	Dependencies float64 `json:"dependencies"`
+	Staleness    float64 `json:"staleness"`
+}
+
+// DefaultPriorityWeights ranks mostly by due date and priority. They are the weights of
+// the stored PriorityScore.
+func DefaultPriorityWeights() PriorityWeights {
+	return PriorityWeights{Due: 4, Priority: 3, Dependencies: 2, Staleness: 1}
+}
+
+// PriorityWeightsFromPreferences reads the weights from resolved user preferences.
+// Weights that are missing fall back to their default; all of them at zero would rank
+// nothing, so the defaults are used instead.
+func PriorityWeightsFromPreferences(prefs map[string]interface{}) PriorityWeights {
+	weights := DefaultPriorityWeights()
+	raw, _ := prefs[user.PreferenceNamespaceTaskPriority].(map[string]interface{})
+	read := func(key string, weight *float64) {
+		if v, ok := raw[key].(float64); ok {
+			*weight = math.Max(0, math.Min(MaxPriorityWeight, v))
+		}
+	}
+	read("due", &weights.Due)
+	read("priority", &weights.Priority)
+	read("dependencies", &weights.Dependencies)
+	read("staleness", &weights.Staleness)
+	if weights.total() == 0 {
+		return DefaultPriorityWeights()
+	}
+	return weights
+}
+
+func (w PriorityWeights) total() float64 {
+	return w.Due + w.Priority + w.Dependencies + w.Staleness
+}
+
+// Score combines the factors into a score from 0 to 100
+func (w PriorityWeights) Score(f PriorityFactors) float64 {
+	total := w.total()
+	if total <= 0 {
+		return 0
+	}
+	sum := w.Due*f.Due + w.Priority*f.Level + w.Dependencies*f.Dependencies + w.Staleness*f.Staleness
+	return math.Round(sum/total*1000) / 10
+}
+
+var priorityLevels = map[TaskPriority]float64{
+	TaskPriorityLow:    0.25,
+	TaskPriorityMedium: 0.5,
+	TaskPriorityHigh:   0.75,
+	TaskPriorityUrgent: 1,
+}
+
+// ScorePriorities computes the priority factors and score of every open task. Closed
+// tasks keep their last score; they drop out of the ranking by status. It returns how
+// many tasks were scored.
+func (s *service) ScorePriorities(ctx context.Context) (int, error) {
+	now := time.Now()
+	open, err := s.repo.FindOpen(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]uuid.UUID, len(open))
+	dependents := make(map[uuid.UUID]int)
+	for i, t := range open {
+		ids[i] = t.ID
+		for _, depID := range uniqueDependencies(t.Dependencies) {
+			if depID != t.ID {
+				dependents[depID]++
+			}
+		}
+	}
+	activity, err := s.repo.LatestActivity(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	defer s.tasksChanged(ctx)
+	clocks := s.projectClocks()
+	weights := DefaultPriorityWeights()
+
+	scored := 0
+	for i := range open {
+		if err := ctx.Err(); err != nil {
+			return scored, err
+		}
+		t := &open[i]
+		lastActivity := t.UpdatedAt
+		if at, ok := activity[t.ID]; ok && at.After(lastActivity) {
+			lastActivity = at
+		}
+		factors := priorityFactors(t, dependents[t.ID], lastActivity, now, clocks.of(ctx, t))
+		if err := s.repo.UpdatePriority(ctx, t.ID, weights.Score(factors), factors); err != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to save task priority score", zap.String("task_id", t.ID.String()), zap.Error(err))
+			continue
+		}
+		scored++
+	}
+	return scored, nil
+}
+
+// priorityFactors grades one open task with the given number of open tasks depending on it
+func priorityFactors(t *Task, dependents int, lastActivity, now time.Time, clock sla.Clock) PriorityFactors {
+	factors := PriorityFactors{
+		Level:        priorityLevels[t.Priority],
+		Dependencies: math.Min(1, float64(dependents)/PriorityDependentsCap),
+		Staleness:    math.Min(1, float64(clock.Elapsed(lastActivity, now))/float64(2*StaleAfter)),
+		ScoredAt:     &now,
+	}
+	if t.DueDate != nil {
+		if t.DueDate.Before(now) {
+			factors.Due = 1
+		} else {
+			factors.Due = math.Max(0, 1-float64(clock.Elapsed(now, *t.DueDate))/float64(PriorityDueHorizon))
+		}
+	}
+	return factors
+}
+
+// uniqueDependencies drops repeated IDs so a task listed twice does not count twice
+func uniqueDependencies(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := ids[:0:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// rescore replaces the stored scores of the tasks with their scores under the weights
+func rescore(tasks []Task, weights PriorityWeights) {
+	for i := range tasks {
+		tasks[i].PriorityScore = weights.Score(tasks[i].PriorityFactors)
+	}
+}
+
+// sortByPriorityScore orders the highest score first, then by due date. Scores must
+// already be under the wanted weights.
+func sortByPriorityScore(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].PriorityScore != tasks[j].PriorityScore {
+			return tasks[i].PriorityScore > tasks[j].PriorityScore
+		}
+		return dueBefore(&tasks[i], &tasks[j])
+	})
+}
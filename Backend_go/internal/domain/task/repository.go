@@ -2,12 +2,15 @@ package task
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -28,8 +31,15 @@ type TaskFilter struct {
 	EndDate        *time.Time
 	DueDateStart   *time.Time
 	DueDateEnd     *time.Time
-	Page           int
-	PageSize       int
+	// IncludeArchived also returns tasks archived by a retention policy
+	IncludeArchived bool
+	Page            int
+	PageSize        int
+	// Keyset pages by creation time instead of Page and PageSize, without counting the total
+	Keyset *pagination.Keyset
+	Sort   TaskSort
+	// Weights rank tasks when sorting by priority score, the defaults when nil
+	Weights *PriorityWeights
 }
 
 // AnalyticsFilter defines filtering options for task analytics
@@ -49,13 +59,48 @@ type TaskRepository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*Task, error)
 	FindAll(ctx context.Context, filter TaskFilter) ([]Task, int64, error)
 	Update(ctx context.Context, task *Task) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	UpdateWithDescriptionVersion(ctx context.Context, task *Task, expectedVersion int) error
+	// Delete moves the task to the trash, recording who deleted it
+	Delete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error
+	// FindDeleted returns tasks in the trash that the user created or deleted, deleted since a time
+	FindDeleted(ctx context.Context, userID uuid.UUID, since time.Time) ([]Task, error)
+	// FindDeletedByID returns a task in the trash
+	FindDeletedByID(ctx context.Context, id uuid.UUID) (*Task, error)
+	// Restore takes a task out of the trash, moving it to the top level when its parent is given as nil
+	Restore(ctx context.Context, id uuid.UUID, parentTaskID *uuid.UUID) error
+	// PurgeDeleted permanently removes tasks deleted before a time
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// Move sets the task's status and custom status and places it at index within that
+	// column of its project board
+	Move(ctx context.Context, id uuid.UUID, status TaskStatus, customStatus string, index int) (*Task, error)
+
+	// Subtask methods
+	// FindDescendants returns every task nested below the task, at any depth
+	FindDescendants(ctx context.Context, id uuid.UUID) ([]Task, error)
+	UpdateProgressMetrics(ctx context.Context, id uuid.UUID, metrics map[string]interface{}) error
+	// AddActualHours adds to the hours logged on a task in one statement, so concurrent logs add
+	// up. Negative hours correct earlier logs; the total never goes below zero.
+	AddActualHours(ctx context.Context, id uuid.UUID, hours float64) error
+	// Reparent moves the direct subtasks of a task under another parent, or to the top level when nil
+	Reparent(ctx context.Context, fromParentID uuid.UUID, toParentID *uuid.UUID) error
+	// FindOpen returns every task that is not completed or cancelled
+	FindOpen(ctx context.Context) ([]Task, error)
+	UpdateRiskFactors(ctx context.Context, id uuid.UUID, factors map[string]interface{}) error
+	UpdatePriority(ctx context.Context, id uuid.UUID, score float64, factors PriorityFactors) error
+	// LatestActivity returns when activity was last recorded for each of the tasks that has any
+	LatestActivity(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]time.Time, error)
 
 	// Analytics methods
 	RecordTaskActivity(ctx context.Context, analytics *TaskAnalytics) error
 	GetTaskAnalytics(ctx context.Context, filter AnalyticsFilter) ([]TaskAnalytics, int64, error)
 	GetTaskActivitySummary(ctx context.Context, taskID uuid.UUID, startTime, endTime time.Time) (map[string]int, error)
 	GetUserTaskActivitySummary(ctx context.Context, userID uuid.UUID, startTime, endTime time.Time) (map[string]int, error)
+
+	// Comment methods
+	CreateComment(ctx context.Context, comment *TaskComment) error
+	FindCommentByID(ctx context.Context, id uuid.UUID) (*TaskComment, error)
+	ListComments(ctx context.Context, taskID uuid.UUID, page, pageSize int) ([]TaskComment, int64, error)
+	DeleteComment(ctx context.Context, id uuid.UUID) error
 }
 
 type taskRepository struct {
@@ -66,7 +111,26 @@ func NewRepository(db *connection.Database) TaskRepository {
 	return &taskRepository{db: db}
 }
 
+const (
+	// positionGap is the spacing between tasks placed at the end of a column or renumbered
+	positionGap = 1024.0
+	// minPositionGap is the closest two neighbours may get before their column is renumbered
+	minPositionGap = 1e-6
+)
+
 func (r *taskRepository) Create(ctx context.Context, task *Task) error {
+	// New tasks go to the bottom of their column
+	if task.Position == 0 {
+		var last float64
+		err := r.db.WithContext(ctx).Model(&Task{}).
+			Where("project_id = ? AND status = ?", task.ProjectID, task.Status).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		task.Position = last + positionGap
+	}
 	return r.db.WithContext(ctx).Create(task).Error
 }
 
@@ -119,6 +183,14 @@ func (r *taskRepository) FindAll(ctx context.Context, filter TaskFilter) ([]Task
 	if filter.DueDateEnd != nil {
 		query = query.Where("due_date < ?", *filter.DueDateEnd)
 	}
+	if !filter.IncludeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	if filter.Keyset != nil {
+		err := query.Scopes(filter.Keyset.Scope("created_at", "id")).Find(&tasks).Error
+		return tasks, 0, err
+	}
 
 	// Count total before pagination
 	err := query.Model(&Task{}).Count(&total).Error
@@ -126,6 +198,21 @@ func (r *taskRepository) FindAll(ctx context.Context, filter TaskFilter) ([]Task
 		return nil, 0, err
 	}
 
+	switch {
+	case filter.Sort == TaskSortPriorityScore && filter.Weights != nil:
+		w := filter.Weights
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "? * priority_due + ? * priority_level + ? * priority_dependencies + ? * priority_staleness DESC, due_date ASC NULLS LAST, created_at ASC",
+			Vars:               []interface{}{w.Due, w.Priority, w.Dependencies, w.Staleness},
+			WithoutParentheses: true,
+		}})
+	case filter.Sort == TaskSortPriorityScore:
+		query = query.Order("priority_score DESC, due_date ASC NULLS LAST, created_at ASC")
+	case filter.ProjectID != nil:
+		// Project tasks come back in board order
+		query = query.Order("status ASC, position ASC, created_at ASC")
+	}
+
 	// Set default PageSize if not set
 	if filter.PageSize == 0 {
 		filter.PageSize = 10000
@@ -153,8 +240,28 @@ func (r *taskRepository) Update(ctx context.Context, task *Task) error {
 	return nil
 }
 
-func (r *taskRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&Task{}, id)
+// UpdateWithDescriptionVersion saves the task only if its stored description version still matches
+func (r *taskRepository) UpdateWithDescriptionVersion(ctx context.Context, task *Task, expectedVersion int) error {
+	result := r.db.WithContext(ctx).Model(task).
+		Where("description_version = ?", expectedVersion).
+		Select("*").
+		Updates(task)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEditConflict
+	}
+	return nil
+}
+
+func (r *taskRepository) Delete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&Task{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"deleted_by": deletedBy,
+		})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -164,6 +271,271 @@ func (r *taskRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (r *taskRepository) FindDeleted(ctx context.Context, userID uuid.UUID, since time.Time) ([]Task, error) {
+	var tasks []Task
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at >= ? AND (creator_id = ? OR deleted_by = ?)", since, userID, userID).
+		Order("deleted_at DESC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) FindDeletedByID(ctx context.Context, id uuid.UUID) (*Task, error) {
+	var task Task
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&task).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *taskRepository) Restore(ctx context.Context, id uuid.UUID, parentTaskID *uuid.UUID) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&Task{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at":     nil,
+			"deleted_by":     nil,
+			"parent_task_id": parentTaskID,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func (r *taskRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", before).Delete(&Task{})
+	return result.RowsAffected, result.Error
+}
+
+// FindDescendants walks the subtask tree with a recursive query
+func (r *taskRepository) FindDescendants(ctx context.Context, id uuid.UUID) ([]Task, error) {
+	var tasks []Task
+	err := r.db.WithContext(ctx).Raw(`WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM tasks WHERE parent_task_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM tasks t JOIN subtree s ON t.parent_task_id = s.id WHERE t.deleted_at IS NULL
+		)
+		SELECT tasks.* FROM tasks JOIN subtree USING (id) ORDER BY tasks.created_at`, id).
+		Scan(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) UpdateProgressMetrics(ctx context.Context, id uuid.UUID, metrics map[string]interface{}) error {
+	encoded, err := json.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(&Task{}).Where("id = ?", id).
+		Update("progress_metrics", gorm.Expr("?::jsonb", string(encoded))).Error
+}
+
+func (r *taskRepository) AddActualHours(ctx context.Context, id uuid.UUID, hours float64) error {
+	result := r.db.WithContext(ctx).Model(&Task{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"actual_hours": gorm.Expr("GREATEST(actual_hours + ?, 0)", hours),
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func (r *taskRepository) Reparent(ctx context.Context, fromParentID uuid.UUID, toParentID *uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&Task{}).Where("parent_task_id = ?", fromParentID).
+		Update("parent_task_id", toParentID).Error
+}
+
+func (r *taskRepository) FindOpen(ctx context.Context) ([]Task, error) {
+	var tasks []Task
+	err := r.db.WithContext(ctx).
+		Where("status NOT IN ?", []TaskStatus{TaskStatusCompleted, TaskStatusCancelled}).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) UpdateRiskFactors(ctx context.Context, id uuid.UUID, factors map[string]interface{}) error {
+	encoded, err := json.Marshal(factors)
+	if err != nil {
+		return err
+	}
+	// UpdateColumn keeps updated_at untouched; the analysis is not activity on the task
+	return r.db.WithContext(ctx).Model(&Task{}).Where("id = ?", id).
+		UpdateColumn("risk_factors", gorm.Expr("?::jsonb", string(encoded))).Error
+}
+
+func (r *taskRepository) UpdatePriority(ctx context.Context, id uuid.UUID, score float64, factors PriorityFactors) error {
+	// Like the risk analysis, scoring is not activity on the task
+	return r.db.WithContext(ctx).Model(&Task{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"priority_score":        score,
+		"priority_due":          factors.Due,
+		"priority_level":        factors.Level,
+		"priority_dependencies": factors.Dependencies,
+		"priority_staleness":    factors.Staleness,
+		"priority_scored_at":    factors.ScoredAt,
+	}).Error
+}
+
+// activityBatchSize bounds the task IDs sent in one LatestActivity query
+const activityBatchSize = 1000
+
+func (r *taskRepository) LatestActivity(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	latest := make(map[uuid.UUID]time.Time, len(ids))
+	for start := 0; start < len(ids); start += activityBatchSize {
+		end := min(start+activityBatchSize, len(ids))
+		var rows []struct {
+			TaskID uuid.UUID
+			Last   time.Time
+		}
+		err := r.db.WithContext(ctx).Model(&TaskAnalytics{}).
+			Select("task_id, MAX(timestamp) AS last").
+			Where("task_id IN ?", ids[start:end]).
+			Group("task_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			latest[row.TaskID] = row.Last
+		}
+	}
+	return latest, nil
+}
+
+// Move locks the target column so concurrent moves see each other's positions. The task takes
+// the midpoint between its new neighbours; when they are too close together the column is
+// renumbered first.
+func (r *taskRepository) Move(ctx context.Context, id uuid.UUID, status TaskStatus, customStatus string, index int) (*Task, error) {
+	var moved Task
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&moved, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+
+		var column []Task
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "position").
+			Where("project_id = ? AND status = ? AND custom_status = ? AND id <> ?", moved.ProjectID, status, customStatus, id).
+			Order("position ASC, created_at ASC").
+			Find(&column).Error
+		if err != nil {
+			return err
+		}
+		if index < 0 || index > len(column) {
+			index = len(column)
+		}
+
+		position, ok := positionAt(column, index)
+		if !ok {
+			for i, t := range column {
+				slot := i
+				if i >= index {
+					slot++
+				}
+				if err := tx.Model(&Task{}).Where("id = ?", t.ID).Update("position", float64(slot+1)*positionGap).Error; err != nil {
+					return err
+				}
+			}
+			position = float64(index+1) * positionGap
+		}
+
+		moved.Status = status
+		moved.CustomStatus = customStatus
+		moved.Position = position
+		moved.UpdatedAt = time.Now()
+		return tx.Model(&moved).Updates(map[string]interface{}{
+			"status":        moved.Status,
+			"custom_status": moved.CustomStatus,
+			"position":      moved.Position,
+			"updated_at":    moved.UpdatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &moved, nil
+}
+
+// positionAt returns the position for a task inserted at index in an ordered column,
+// or false if its neighbours leave no room between them
+func positionAt(column []Task, index int) (float64, bool) {
+	switch {
+	case len(column) == 0:
+		return positionGap, true
+	case index == 0:
+		return column[0].Position - positionGap, true
+	case index == len(column):
+		return column[len(column)-1].Position + positionGap, true
+	}
+	prev, next := column[index-1].Position, column[index].Position
+	if next-prev < minPositionGap {
+		return 0, false
+	}
+	return (prev + next) / 2, true
+}
+
+// Comment implementation
+func (r *taskRepository) CreateComment(ctx context.Context, comment *TaskComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+func (r *taskRepository) FindCommentByID(ctx context.Context, id uuid.UUID) (*TaskComment, error) {
+	var comment TaskComment
+	if err := r.db.WithContext(ctx).First(&comment, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListComments returns the comments of a task, oldest first
+func (r *taskRepository) ListComments(ctx context.Context, taskID uuid.UUID, page, pageSize int) ([]TaskComment, int64, error) {
+	var comments []TaskComment
+	var total int64
+	query := r.db.WithContext(ctx).Model(&TaskComment{}).Where("task_id = ?", taskID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at ASC").
+		Offset(page * pageSize).
+		Limit(pageSize).
+		Find(&comments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+func (r *taskRepository) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&TaskComment{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
 // Analytics implementation
 func (r *taskRepository) RecordTaskActivity(ctx context.Context, analytics *TaskAnalytics) error {
 	return r.db.WithContext(ctx).Create(analytics).Error
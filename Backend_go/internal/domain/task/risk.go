@@ -0,0 +1,489 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RiskCode identifies why a task is at risk
+type RiskCode string
+
+const (
+	RiskOverdue            RiskCode = "overdue"
+	RiskNoActivity         RiskCode = "no_activity"
+	RiskDueSoonLowProgress RiskCode = "due_soon_low_progress"
+	RiskBlockedDependency  RiskCode = "blocked_dependency"
+)
+
+// RiskLevel grades how likely an at-risk task is to miss its date
+type RiskLevel string
+
+const (
+	RiskMedium RiskLevel = "medium"
+	RiskHigh   RiskLevel = "high"
+)
+
+const (
+	// StaleAfter is how long an open task may go without activity before it is at risk.
+	// Like DueSoonWindow it is measured on the project's clock, so on the business
+	// clock it means working days.
+	StaleAfter = 7 * 24 * time.Hour
+	// DueSoonWindow is how close the due date must be for low progress to put a task at risk
+	DueSoonWindow = 72 * time.Hour
+	// LowProgressPercent is the progress below which a task due soon is at risk
+	LowProgressPercent = 50.0
+
+	// riskAnalysisKey is the RiskFactors entry holding the latest risk analysis
+	riskAnalysisKey = "analysis"
+)
+
+// RiskReason is one finding of the risk analysis
+type RiskReason struct {
+	Code   RiskCode `json:"code"`
+	Detail string   `json:"detail"`
+	// TaskIDs is the dependency chain from the task to the blocked or overdue dependency
+	TaskIDs []uuid.UUID `json:"task_ids,omitempty"`
+}
+
+// RiskAnnotation is the result of the nightly risk analysis of an open task. It is stored
+// in the task's risk factors.
+type RiskAnnotation struct {
+	AtRisk     bool         `json:"at_risk"`
+	Level      RiskLevel    `json:"level,omitempty"`
+	Reasons    []RiskReason `json:"reasons"`
+	Progress   float64      `json:"progress"`
+	AnalyzedAt time.Time    `json:"analyzed_at"`
+}
+
+// RiskOf returns the latest risk analysis of the task, or nil when it has not been
+// analyzed or is no longer open
+func RiskOf(t *Task) *RiskAnnotation {
+	if t.Status == TaskStatusCompleted || t.Status == TaskStatusCancelled {
+		return nil
+	}
+	raw, ok := t.RiskFactors[riskAnalysisKey]
+	if !ok {
+		return nil
+	}
+	if annotation, ok := raw.(RiskAnnotation); ok {
+		return &annotation
+	}
+	// Loaded from the database the analysis is a decoded JSON object
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var annotation RiskAnnotation
+	if err := json.Unmarshal(encoded, &annotation); err != nil {
+		return nil
+	}
+	return &annotation
+}
+
+// AnalyzeRisks annotates every open task with its risk analysis and announces tasks that
+// became at risk, or are at risk for new reasons. It returns how many tasks are at risk.
+func (s *service) AnalyzeRisks(ctx context.Context) (int, error) {
+	now := time.Now()
+	open, err := s.repo.FindOpen(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	byID := make(map[uuid.UUID]*Task, len(open))
+	ids := make([]uuid.UUID, len(open))
+	for i := range open {
+		byID[open[i].ID] = &open[i]
+		ids[i] = open[i].ID
+	}
+	activity, err := s.repo.LatestActivity(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	defer s.tasksChanged(ctx)
+	clocks := s.projectClocks()
+	chains := &dependencyChains{tasks: byID, now: now, paths: make(map[uuid.UUID][]uuid.UUID), visiting: make(map[uuid.UUID]bool)}
+
+	atRisk := 0
+	for i := range open {
+		if err := ctx.Err(); err != nil {
+			return atRisk, err
+		}
+		t := &open[i]
+		lastActivity := t.UpdatedAt
+		if at, ok := activity[t.ID]; ok && at.After(lastActivity) {
+			lastActivity = at
+		}
+		annotation := assessRisk(t, lastActivity, chains.blockedBy(t.ID), now, clocks.of(ctx, t))
+
+		factors := make(map[string]interface{}, len(t.RiskFactors)+1)
+		for k, v := range t.RiskFactors {
+			factors[k] = v
+		}
+		factors[riskAnalysisKey] = annotation
+		if err := s.repo.UpdateRiskFactors(ctx, t.ID, factors); err != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to save task risk analysis", zap.String("task_id", t.ID.String()), zap.Error(err))
+			continue
+		}
+
+		if !annotation.AtRisk {
+			continue
+		}
+		atRisk++
+		if previous := RiskOf(t); previous == nil || !previous.AtRisk || hasNewReasons(previous, &annotation) {
+			s.publishAtRisk(ctx, t, &annotation)
+		}
+	}
+	return atRisk, nil
+}
+
+// assessRisk grades one open task. blockedChain is the path to a blocked or overdue
+// dependency, if any. A task is overdue once its due date passes; how long ago, how
+// long it has been idle and how soon it is due are measured on the clock.
+func assessRisk(t *Task, lastActivity time.Time, blockedChain []uuid.UUID, now time.Time, clock sla.Clock) RiskAnnotation {
+	annotation := RiskAnnotation{
+		Reasons:    []RiskReason{},
+		Progress:   taskProgress(t),
+		AnalyzedAt: now,
+	}
+
+	overdue := t.DueDate != nil && t.DueDate.Before(now)
+	if overdue {
+		annotation.Reasons = append(annotation.Reasons, RiskReason{
+			Code:   RiskOverdue,
+			Detail: fmt.Sprintf("Was due %s ago", clock.Describe(clock.Elapsed(*t.DueDate, now))),
+		})
+	}
+	if idle := clock.Elapsed(lastActivity, now); idle >= StaleAfter {
+		annotation.Reasons = append(annotation.Reasons, RiskReason{
+			Code:   RiskNoActivity,
+			Detail: fmt.Sprintf("No activity for %s", clock.Describe(idle)),
+		})
+	}
+	if !overdue && dueSoon(t, now, clock) && annotation.Progress < LowProgressPercent {
+		annotation.Reasons = append(annotation.Reasons, RiskReason{
+			Code:   RiskDueSoonLowProgress,
+			Detail: fmt.Sprintf("Due in %s with %.0f%% progress", clock.Describe(clock.Elapsed(now, *t.DueDate)), annotation.Progress),
+		})
+	}
+	if len(blockedChain) > 0 {
+		annotation.Reasons = append(annotation.Reasons, RiskReason{
+			Code:    RiskBlockedDependency,
+			Detail:  "Waiting on a dependency that is blocked or overdue",
+			TaskIDs: blockedChain,
+		})
+	}
+
+	switch {
+	case overdue || len(annotation.Reasons) >= 2:
+		annotation.AtRisk, annotation.Level = true, RiskHigh
+	case len(annotation.Reasons) == 1:
+		annotation.AtRisk, annotation.Level = true, RiskMedium
+	}
+	return annotation
+}
+
+// taskProgress estimates how far along a task is, in percent: from its subtask rollup
+// when it has subtasks, then from hours logged against the estimate, then from its status
+func taskProgress(t *Task) float64 {
+	switch rollup := t.ProgressMetrics[subtaskProgressKey].(type) {
+	case SubtaskRollup:
+		if rollup.Total > 0 {
+			return rollup.Percent
+		}
+	case map[string]interface{}:
+		if total, _ := rollup["total"].(float64); total > 0 {
+			percent, _ := rollup["percent"].(float64)
+			return percent
+		}
+	}
+	if t.EstimatedHours > 0 {
+		return math.Min(100, math.Round(t.ActualHours/t.EstimatedHours*100))
+	}
+	if t.Status == TaskStatusUnderReview {
+		return 90
+	}
+	return 0
+}
+
+// dueSoon reports whether the task is due within DueSoonWindow on the clock
+func dueSoon(t *Task, now time.Time, clock sla.Clock) bool {
+	return t.DueDate != nil && clock.Elapsed(now, *t.DueDate) <= DueSoonWindow
+}
+
+// projectClockCache resolves the clock of each project once per computation
+type projectClockCache struct {
+	resolver sla.Resolver
+	clocks   map[uuid.UUID]sla.Clock
+	logger   *zap.Logger
+}
+
+func (s *service) projectClocks() *projectClockCache {
+	return &projectClockCache{resolver: s.clocks, clocks: make(map[uuid.UUID]sla.Clock), logger: s.logger}
+}
+
+// of returns the clock of the task's project, falling back to the wall clock when the
+// project's clock cannot be loaded
+func (c *projectClockCache) of(ctx context.Context, t *Task) sla.Clock {
+	if c.resolver == nil {
+		return sla.WallClock
+	}
+	if clock, ok := c.clocks[t.ProjectID]; ok {
+		return clock
+	}
+	clock, err := c.resolver.ClockFor(ctx, t.OrganizationID, t.ProjectID)
+	if err != nil {
+		c.logger.Warn("Failed to load project clock, using the wall clock",
+			zap.String("project_id", t.ProjectID.String()), zap.Error(err))
+		clock = sla.WallClock
+	}
+	c.clocks[t.ProjectID] = clock
+	return clock
+}
+
+func hasNewReasons(previous, current *RiskAnnotation) bool {
+	for _, reason := range current.Reasons {
+		if !slices.ContainsFunc(previous.Reasons, func(r RiskReason) bool { return r.Code == reason.Code }) {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyChains finds, for each open task, a chain of open dependencies that ends in a
+// blocked or overdue task. Completed and cancelled dependencies never block.
+type dependencyChains struct {
+	tasks    map[uuid.UUID]*Task
+	now      time.Time
+	paths    map[uuid.UUID][]uuid.UUID
+	visiting map[uuid.UUID]bool
+}
+
+func (c *dependencyChains) blockedBy(id uuid.UUID) []uuid.UUID {
+	if path, ok := c.paths[id]; ok {
+		return path
+	}
+	// A dependency cycle cannot be resolved by waiting, but it is not a blocked task either
+	if c.visiting[id] {
+		return nil
+	}
+	c.visiting[id] = true
+	defer delete(c.visiting, id)
+
+	var path []uuid.UUID
+	for _, depID := range c.tasks[id].Dependencies {
+		dep, ok := c.tasks[depID]
+		if !ok || depID == id {
+			continue
+		}
+		if dep.Status == TaskStatusBlocked || (dep.DueDate != nil && dep.DueDate.Before(c.now)) {
+			path = []uuid.UUID{depID}
+			break
+		}
+		if rest := c.blockedBy(depID); len(rest) > 0 {
+			path = append([]uuid.UUID{depID}, rest...)
+			break
+		}
+	}
+	c.paths[id] = path
+	return path
+}
+
+// publishAtRisk announces that the task is at risk to its assignee, or its creator when unassigned
+func (s *service) publishAtRisk(ctx context.Context, task *Task, annotation *RiskAnnotation) {
+	if s.bus == nil {
+		return
+	}
+	owner := task.CreatorID
+	if task.AssigneeID != nil {
+		owner = *task.AssigneeID
+	}
+	reasons := make([]string, len(annotation.Reasons))
+	for i, reason := range annotation.Reasons {
+		reasons[i] = reason.Detail
+	}
+	s.bus.Publish(ctx, events.TaskAtRisk{
+		TaskID:         task.ID,
+		ProjectID:      task.ProjectID,
+		OrganizationID: task.OrganizationID,
+		Title:          task.Title,
+		DueDate:        task.DueDate,
+		OwnerID:        owner,
+		Level:          string(annotation.Level),
+		Reasons:        reasons,
+	})
+}
+
+// ProjectHealth summarizes the schedule risk of a project's tasks
+type ProjectHealth struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	// Score runs from 100, when no open task is at risk, down to 0
+	Score          float64 `json:"score"`
+	TotalTasks     int     `json:"total_tasks"`
+	OpenTasks      int     `json:"open_tasks"`
+	CompletedTasks int     `json:"completed_tasks"`
+	Completion     float64 `json:"completion"`
+	Overdue        int     `json:"overdue"`
+	AtRisk         int     `json:"at_risk"`
+	HighRisk       int     `json:"high_risk"`
+	Blocked        int     `json:"blocked"`
+	// DueSoon counts open tasks due within DueSoonWindow on the project's clock
+	DueSoon int           `json:"due_soon"`
+	Clock   sla.ClockMode `json:"clock"`
+	// AtRiskTasks lists the at-risk tasks, high risk and soonest due first
+	AtRiskTasks []Task `json:"at_risk_tasks"`
+	// AnalyzedAt is when the latest risk analysis ran over the project, if ever
+	AnalyzedAt *time.Time `json:"analyzed_at,omitempty"`
+}
+
+// GetProjectHealth summarizes the project from its tasks and their latest risk analysis
+func (s *service) GetProjectHealth(ctx context.Context, projectID uuid.UUID, filter TaskFilter) (*ProjectHealth, error) {
+	filter.ProjectID = &projectID
+	filter.Page, filter.PageSize = 0, 0
+	tasks, _, err := s.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	health := &ProjectHealth{ProjectID: projectID, Clock: sla.ClockWall, AtRiskTasks: []Task{}}
+	clock := sla.WallClock
+	if len(tasks) > 0 {
+		clock = s.projectClocks().of(ctx, &tasks[0])
+		health.Clock = clock.Mode()
+	}
+	penalty := 0.0
+	for _, t := range tasks {
+		switch t.Status {
+		case TaskStatusCancelled:
+			continue
+		case TaskStatusCompleted:
+			health.TotalTasks++
+			health.CompletedTasks++
+			continue
+		case TaskStatusBlocked:
+			health.Blocked++
+		}
+		health.TotalTasks++
+		health.OpenTasks++
+		if t.DueDate != nil && t.DueDate.Before(now) {
+			health.Overdue++
+		} else if dueSoon(&t, now, clock) {
+			health.DueSoon++
+		}
+
+		risk := RiskOf(&t)
+		if risk == nil {
+			continue
+		}
+		if health.AnalyzedAt == nil || risk.AnalyzedAt.After(*health.AnalyzedAt) {
+			health.AnalyzedAt = &risk.AnalyzedAt
+		}
+		if !risk.AtRisk {
+			continue
+		}
+		health.AtRisk++
+		health.AtRiskTasks = append(health.AtRiskTasks, t)
+		if risk.Level == RiskHigh {
+			health.HighRisk++
+			penalty++
+		} else {
+			penalty += 0.5
+		}
+	}
+
+	health.Completion = rollupPercent(health.CompletedTasks, health.TotalTasks)
+	health.Score = 100
+	if health.OpenTasks > 0 {
+		health.Score = math.Round(math.Max(0, 1-penalty/float64(health.OpenTasks))*1000) / 10
+	}
+	sortByRisk(health.AtRiskTasks)
+	return health, nil
+}
+
+// MyWork is a user's open assigned tasks, grouped by urgency. Each task appears in the
+// first group it qualifies for.
+type MyWork struct {
+	Overdue []Task `json:"overdue"`
+	AtRisk  []Task `json:"at_risk"`
+	// DueSoon holds tasks due within DueSoonWindow on their project's clock
+	DueSoon []Task `json:"due_soon"`
+	Other   []Task `json:"other"`
+}
+
+// GetMyWork returns the open tasks assigned to the user, grouped by urgency
+func (s *service) GetMyWork(ctx context.Context, userID uuid.UUID, weights *PriorityWeights) (*MyWork, error) {
+	tasks, _, err := s.repo.FindAll(ctx, TaskFilter{AssigneeID: &userID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	clocks := s.projectClocks()
+	work := &MyWork{Overdue: []Task{}, AtRisk: []Task{}, DueSoon: []Task{}, Other: []Task{}}
+	for _, t := range tasks {
+		if t.Status == TaskStatusCompleted || t.Status == TaskStatusCancelled {
+			continue
+		}
+		switch risk := RiskOf(&t); {
+		case t.DueDate != nil && t.DueDate.Before(now):
+			work.Overdue = append(work.Overdue, t)
+		case risk != nil && risk.AtRisk:
+			work.AtRisk = append(work.AtRisk, t)
+		case dueSoon(&t, now, clocks.of(ctx, &t)):
+			work.DueSoon = append(work.DueSoon, t)
+		default:
+			work.Other = append(work.Other, t)
+		}
+	}
+	if weights != nil {
+		for _, group := range [][]Task{work.Overdue, work.AtRisk, work.DueSoon, work.Other} {
+			rescore(group, *weights)
+			sortByPriorityScore(group)
+		}
+		return work, nil
+	}
+	sortByDueDate(work.Overdue)
+	sortByRisk(work.AtRisk)
+	sortByDueDate(work.DueSoon)
+	sortByDueDate(work.Other)
+	return work, nil
+}
+
+// sortByRisk orders high risk tasks first, then by due date
+func sortByRisk(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		hi, hj := RiskOf(&tasks[i]), RiskOf(&tasks[j])
+		highI := hi != nil && hi.Level == RiskHigh
+		highJ := hj != nil && hj.Level == RiskHigh
+		if highI != highJ {
+			return highI
+		}
+		return dueBefore(&tasks[i], &tasks[j])
+	})
+}
+
+func sortByDueDate(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool { return dueBefore(&tasks[i], &tasks[j]) })
+}
+
+// dueBefore orders tasks by due date, tasks without one last
+func dueBefore(a, b *Task) bool {
+	switch {
+	case a.DueDate == nil:
+		return false
+	case b.DueDate == nil:
+		return true
+	}
+	return a.DueDate.Before(*b.DueDate)
+}
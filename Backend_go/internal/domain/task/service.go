@@ -4,10 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/activity"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/plugins"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -15,6 +23,7 @@ import (
 var (
 	ErrInvalidTransition = errors.New("invalid status transition")
 	ErrDependencyFailed  = errors.New("dependencies not completed")
+	ErrEditConflict      = errors.New("task description was changed since it was loaded")
 )
 
 // Analytics types
@@ -48,10 +57,34 @@ type Service interface {
 	ListTasks(ctx context.Context, filter TaskFilter) ([]Task, int64, error)
 	UpdateTask(ctx context.Context, id uuid.UUID, input UpdateTaskInput) (*Task, error)
 	UpdateTaskStatus(ctx context.Context, id uuid.UUID, status TaskStatus) (*Task, error)
+	MoveTask(ctx context.Context, id uuid.UUID, input MoveTaskInput) (*Task, error)
 	DeleteTask(ctx context.Context, id uuid.UUID) error
 	GetTaskMetrics(ctx context.Context, id uuid.UUID) (*TaskMetrics, error)
 	GetProjectTasks(ctx context.Context, projectID uuid.UUID, filter TaskFilter) ([]Task, int64, error)
 	AssignTask(ctx context.Context, id uuid.UUID, assigneeID uuid.UUID) (*Task, error)
+	LogWork(ctx context.Context, id uuid.UUID, userID uuid.UUID, hours float64, note string) (*Task, error)
+	// AdjustLoggedWork corrects the hours logged on a task by a positive or negative
+	// amount, without recording activity. Logged hours never go below zero.
+	AdjustLoggedWork(ctx context.Context, id uuid.UUID, hours float64) (*Task, error)
+	GetSubtasks(ctx context.Context, id uuid.UUID) (*SubtaskNode, error)
+
+	// Trash methods
+	ListDeletedTasks(ctx context.Context, userID uuid.UUID, since time.Time) ([]Task, error)
+	RestoreTask(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Task, error)
+	PurgeDeletedTasks(ctx context.Context, before time.Time) (int64, error)
+
+	// Risk methods
+	AnalyzeRisks(ctx context.Context) (int, error)
+	GetProjectHealth(ctx context.Context, projectID uuid.UUID, filter TaskFilter) (*ProjectHealth, error)
+	// GetMyWork groups the user's open tasks by urgency. With weights, each group is
+	// ranked by priority score under them rather than by due date.
+	GetMyWork(ctx context.Context, userID uuid.UUID, weights *PriorityWeights) (*MyWork, error)
+	// ScorePriorities refreshes the priority score of every open task
+	ScorePriorities(ctx context.Context) (int, error)
+
+	// Dependency methods
+	GetDependencies(ctx context.Context, id uuid.UUID) (*DependencyGraph, error)
+	GetCriticalPath(ctx context.Context, projectID uuid.UUID, filter TaskFilter) (*CriticalPath, error)
 
 	// Analytics methods
 	RecordTaskActivity(ctx context.Context, input RecordTaskActivityInput) error
@@ -61,6 +94,12 @@ type Service interface {
 	GetUserTaskActivitySummary(ctx context.Context, userID uuid.UUID, startTime, endTime time.Time) (*UserTaskActivitySummary, error)
 	GetDashboardMetrics(userID uuid.UUID) (TasksDashboardMetrics, error)
 	GetTodayTasks(ctx context.Context, userID uuid.UUID) ([]Task, error)
+
+	// Comment methods
+	AddComment(ctx context.Context, taskID, authorID uuid.UUID, body string) (*TaskComment, error)
+	ListComments(ctx context.Context, taskID uuid.UUID, page, pageSize int) ([]TaskComment, int64, error)
+	GetComment(ctx context.Context, taskID, commentID uuid.UUID) (*TaskComment, error)
+	DeleteComment(ctx context.Context, taskID, commentID, userID uuid.UUID) error
 }
 
 type TaskMetrics struct {
@@ -98,11 +137,23 @@ type UpdateTaskInput struct {
 	AssigneeID     *uuid.UUID    `json:"assignee_id,omitempty"`
 	ReviewerID     *uuid.UUID    `json:"reviewer_id,omitempty"`
 	CategoryID     *uuid.UUID    `json:"category_id,omitempty"`
+	ParentTaskID   *uuid.UUID    `json:"parent_task_id,omitempty"`
 	EstimatedHours *float64      `json:"estimated_hours,omitempty"`
 	StartDate      *time.Time    `json:"start_date,omitempty"`
 	Duration       *float64      `json:"duration,omitempty"`
 	DueDate        *time.Time    `json:"due_date,omitempty"`
 	Dependencies   []uuid.UUID   `json:"dependencies,omitempty"`
+
+	// BaseDescriptionVersion is the description version the edit started from.
+	// When set, a description change is rejected if the task has moved on.
+	BaseDescriptionVersion *int `json:"base_description_version,omitempty"`
+}
+
+// MoveTaskInput places a task on the project board
+type MoveTaskInput struct {
+	Status TaskStatus `json:"status"`
+	// Position is the zero-based index within the target column; nil moves the task to the bottom
+	Position *int `json:"position,omitempty"`
 }
 
 // Define TasksDashboardMetrics struct for dashboard metrics aggregation
@@ -117,13 +168,49 @@ type TasksDashboardMetrics struct {
 // Repository interface
 
 type service struct {
-	repo   TaskRepository
-	redis  *cache.RedisClient // Injected for event publishing
-	logger *zap.Logger
+	repo     TaskRepository
+	redis    *cache.RedisClient   // Injected for event publishing
+	activity activity.Recorder    // Feeds project and organization activity timelines
+	webhooks webhooks.Publisher   // Delivers task events to subscribed endpoints
+	hooks    plugins.Hooks        // Runs plugin handlers around task creation
+	bus      events.Publisher     // Tells other domains about task completions
+	changes  cache.ChangeNotifier // Drops cached task responses after writes
+	clocks   sla.Resolver         // Picks the wall or business clock of each project
+	defaults Defaults             // Default priority and enabled statuses of each project
+	auditor  audit.Auditor        // Logs deletions to the organization's audit log
+	logger   *zap.Logger
+}
+
+func NewService(repo TaskRepository, redis *cache.RedisClient, activityRecorder activity.Recorder, webhookPublisher webhooks.Publisher, hooks plugins.Hooks, bus events.Publisher, changes cache.ChangeNotifier, clocks sla.Resolver, defaults Defaults, auditor audit.Auditor, logger *zap.Logger) Service {
+	return &service{repo: repo, redis: redis, activity: activityRecorder, webhooks: webhookPublisher, hooks: hooks, bus: bus, changes: changes, clocks: clocks, defaults: defaults, auditor: auditor, logger: logger}
 }
 
-func NewService(repo TaskRepository, redis *cache.RedisClient, logger *zap.Logger) Service {
-	return &service{repo: repo, redis: redis, logger: logger}
+// tasksChanged drops cached task responses after a write
+func (s *service) tasksChanged(ctx context.Context) {
+	if s.changes != nil {
+		s.changes.EntityChanged(ctx, cache.EntityTasks)
+	}
+}
+
+// taskActivityTypes maps task analytics actions to activity feed event types
+var taskActivityTypes = map[string]activity.EventType{
+	"task_created":    activity.EventTaskCreated,
+	"task_updated":    activity.EventTaskUpdated,
+	"status_changed":  activity.EventTaskStatusChanged,
+	"task_deleted":    activity.EventTaskDeleted,
+	"task_assigned":   activity.EventTaskAssigned,
+	"comment_added":   activity.EventCommentAdded,
+	"comment_deleted": activity.EventCommentDeleted,
+}
+
+// taskWebhookEvents maps task analytics actions to webhook event types
+var taskWebhookEvents = map[string]webhooks.EventType{
+	"task_created":   webhooks.EventTaskCreated,
+	"task_updated":   webhooks.EventTaskUpdated,
+	"status_changed": webhooks.EventTaskStatusChanged,
+	"task_deleted":   webhooks.EventTaskDeleted,
+	"task_assigned":  webhooks.EventTaskAssigned,
+	"task_moved":     webhooks.EventTaskUpdated,
 }
 
 func (s *service) CreateTask(ctx context.Context, input CreateTaskInput) (*Task, error) {
@@ -132,9 +219,17 @@ func (s *service) CreateTask(ctx context.Context, input CreateTaskInput) (*Task,
 		return nil, ErrInvalidInput
 	}
 
-	// Set default values
-	if input.Status == "" {
-		input.Status = TaskStatusUpcoming
+	// Set default values from the project's settings
+	defaults, err := s.projectDefaults(ctx, input.OrganizationID, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	status, customStatus, err := defaults.InitialStatus(input.Status)
+	if err != nil {
+		return nil, err
+	}
+	if input.Priority == "" {
+		input.Priority = defaults.Priority
 	}
 	if input.Priority == "" {
 		input.Priority = TaskPriorityMedium
@@ -144,7 +239,8 @@ func (s *service) CreateTask(ctx context.Context, input CreateTaskInput) (*Task,
 		ID:             uuid.New(),
 		Title:          input.Title,
 		Description:    input.Description,
-		Status:         input.Status,
+		Status:         status,
+		CustomStatus:   customStatus,
 		Priority:       input.Priority,
 		CreatorID:      input.CreatorID,
 		AssigneeID:     input.AssigneeID,
@@ -161,11 +257,29 @@ func (s *service) CreateTask(ctx context.Context, input CreateTaskInput) (*Task,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
+	if task.ParentTaskID != nil {
+		if err := s.validateParent(ctx, task.ID, task.ProjectID, *task.ParentTaskID); err != nil {
+			return nil, err
+		}
+	}
+	dependencies, err := s.validateDependencies(ctx, task.ID, task.ProjectID, task.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	task.Dependencies = dependencies
+
+	// Plugins may adjust the task or refuse it before it is stored
+	if s.hooks != nil {
+		if err := s.hooks.Before(ctx, plugins.BeforeTaskCreate, task); err != nil {
+			return nil, err
+		}
+	}
 
-	err := s.repo.Create(ctx, task)
+	err = s.repo.Create(ctx, task)
 	if err != nil {
 		return nil, err
 	}
+	s.tasksChanged(ctx)
 
 	s.recordTaskActivity(ctx, task, task.CreatorID, "task_created", map[string]interface{}{
 		"title":  task.Title,
@@ -173,7 +287,7 @@ func (s *service) CreateTask(ctx context.Context, input CreateTaskInput) (*Task,
 	})
 
 	// Record task creation activity with meaningful metadata
-	if callerID, ok := ctx.Value("user_id").(uuid.UUID); ok {
+	if callerID, ok := audit.ActorFrom(ctx); ok {
 		metadata := marshalTaskMetadata(map[string]interface{}{
 			"created_by":      callerID.String(),
 			"task_id":         task.ID.String(),
@@ -218,7 +332,25 @@ func (s *service) CreateTask(ctx context.Context, input CreateTaskInput) (*Task,
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
+	}
+
+	if s.bus != nil {
+		s.bus.Publish(ctx, events.TaskCreated{
+			TaskID:         task.ID,
+			ProjectID:      task.ProjectID,
+			OrganizationID: task.OrganizationID,
+			CreatorID:      task.CreatorID,
+			Status:         string(task.Status),
+			CreatedAt:      task.CreatedAt,
+		})
+	}
+	if task.AssigneeID != nil {
+		s.publishAssigned(ctx, task, task.CreatorID)
+	}
+	s.rollUpProgress(ctx, task.ParentTaskID)
+	if s.hooks != nil {
+		s.hooks.After(ctx, plugins.AfterTaskCreate, task)
 	}
 
 	return task, nil
@@ -236,7 +368,15 @@ func (s *service) GetTask(ctx context.Context, id uuid.UUID) (*Task, error) {
 }
 
 func (s *service) ListTasks(ctx context.Context, filter TaskFilter) ([]Task, int64, error) {
-	return s.repo.FindAll(ctx, filter)
+	tasks, total, err := s.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Stored scores are under the default weights; show the ones the list is ranked by
+	if filter.Sort == TaskSortPriorityScore && filter.Weights != nil {
+		rescore(tasks, *filter.Weights)
+	}
+	return tasks, total, nil
 }
 
 // Helper to marshal metadata
@@ -261,31 +401,39 @@ func (s *service) UpdateTask(ctx context.Context, id uuid.UUID, input UpdateTask
 	oldStatus := task.Status
 	oldAssignee := task.AssigneeID
 	oldDependencies := task.Dependencies
+	oldParent := task.ParentTaskID
 
 	changed := false
 	var analyticsEvents []*TaskAnalytics
-	var callerID uuid.UUID
-	if v, ok := ctx.Value("user_id").(uuid.UUID); ok {
-		callerID = v
-	} else {
-		callerID = task.CreatorID
-	}
+	callerID := actorOf(ctx, task)
 
 	// Update fields if provided
 	if input.Title != nil && *input.Title != task.Title {
 		task.Title = *input.Title
 		changed = true
 	}
+	baseDescriptionVersion := task.DescriptionVersion
+	descriptionChanged := false
 	if input.Description != nil && *input.Description != task.Description {
+		if input.BaseDescriptionVersion != nil && *input.BaseDescriptionVersion != task.DescriptionVersion {
+			return nil, ErrEditConflict
+		}
 		task.Description = *input.Description
+		task.DescriptionVersion++
+		descriptionChanged = true
 		changed = true
 	}
-	if input.Status != nil && *input.Status != oldStatus {
-		task.Status = *input.Status
+	if input.Status != nil && *input.Status != oldStatus && string(*input.Status) != task.CustomStatus {
+		status, customStatus, err := s.resolveStatus(ctx, task, *input.Status, false)
+		if err != nil {
+			return nil, err
+		}
+		task.Status = status
+		task.CustomStatus = customStatus
 		changed = true
 		metadata := marshalTaskMetadata(map[string]interface{}{
 			"old_status": string(oldStatus),
-			"new_status": string(*input.Status),
+			"new_status": string(status),
 			"updated_by": callerID.String(),
 			"task_id":    task.ID.String(),
 		})
@@ -327,11 +475,15 @@ func (s *service) UpdateTask(ctx context.Context, id uuid.UUID, input UpdateTask
 		})
 	}
 	if input.Dependencies != nil && !equalUUIDSlices(input.Dependencies, oldDependencies) {
-		task.Dependencies = input.Dependencies
+		dependencies, err := s.validateDependencies(ctx, task.ID, task.ProjectID, input.Dependencies)
+		if err != nil {
+			return nil, err
+		}
+		task.Dependencies = dependencies
 		changed = true
 		metadata := marshalTaskMetadata(map[string]interface{}{
 			"old_dependencies": oldDependencies,
-			"new_dependencies": input.Dependencies,
+			"new_dependencies": dependencies,
 			"updated_by":       callerID.String(),
 			"task_id":          task.ID.String(),
 		})
@@ -344,13 +496,31 @@ func (s *service) UpdateTask(ctx context.Context, id uuid.UUID, input UpdateTask
 			Metadata:  metadata,
 		})
 	}
+	// A nil parent ID moves the task back to the top level
+	if input.ParentTaskID != nil {
+		if *input.ParentTaskID == uuid.Nil {
+			task.ParentTaskID = nil
+		} else if oldParent == nil || *input.ParentTaskID != *oldParent {
+			if err := s.validateParent(ctx, task.ID, task.ProjectID, *input.ParentTaskID); err != nil {
+				return nil, err
+			}
+			task.ParentTaskID = input.ParentTaskID
+		}
+		changed = changed || !equalParents(oldParent, task.ParentTaskID)
+	}
 	// ... handle other fields as needed ...
 
 	task.UpdatedAt = time.Now()
-	err = s.repo.Update(ctx, task)
+	if descriptionChanged {
+		// Guard against a concurrent save landing between the read above and this write
+		err = s.repo.UpdateWithDescriptionVersion(ctx, task, baseDescriptionVersion)
+	} else {
+		err = s.repo.Update(ctx, task)
+	}
 	if err != nil {
 		return nil, err
 	}
+	s.tasksChanged(ctx)
 
 	if changed {
 		for _, event := range analyticsEvents {
@@ -373,14 +543,40 @@ func (s *service) UpdateTask(ctx context.Context, id uuid.UUID, input UpdateTask
 		_ = s.repo.RecordTaskActivity(ctx, analytics)
 	}
 
-	s.recordTaskActivity(ctx, task, task.CreatorID, "task_updated", map[string]interface{}{
+	s.recordTaskActivity(ctx, task, actorOf(ctx, task), "task_updated", map[string]interface{}{
 		"title":  task.Title,
 		"status": task.Status,
 	})
+	s.publishStatusChanged(ctx, task, oldStatus, callerID)
+	if input.AssigneeID != nil && (oldAssignee == nil || *input.AssigneeID != *oldAssignee) {
+		s.publishAssigned(ctx, task, callerID)
+	}
+	if !equalParents(oldParent, task.ParentTaskID) {
+		s.rollUpProgress(ctx, oldParent)
+		s.rollUpProgress(ctx, task.ParentTaskID)
+	} else if task.Status != oldStatus {
+		s.rollUpProgress(ctx, task.ParentTaskID)
+	}
 
 	return task, nil
 }
 
+// actorOf returns the user making the request, or the task's creator for changes made
+// outside one, such as by automations
+func actorOf(ctx context.Context, task *Task) uuid.UUID {
+	if actorID, ok := audit.ActorFrom(ctx); ok {
+		return actorID
+	}
+	return task.CreatorID
+}
+
+func equalParents(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 // Helper to compare slices of UUIDs
 func equalUUIDSlices(a, b []uuid.UUID) bool {
 	if len(a) != len(b) {
@@ -408,12 +604,10 @@ func (s *service) UpdateTaskStatus(ctx context.Context, id uuid.UUID, status Tas
 		return nil, ErrTaskNotFound
 	}
 
-	if !status.IsValid() {
-		return nil, ErrInvalidInput
-	}
-
-	if !isValidStatusTransition(task.Status, status) {
-		return nil, ErrInvalidTransition
+	// Validates the status against the project's custom statuses, if it has them
+	status, customStatus, err := s.resolveStatus(ctx, task, status, true)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check dependencies if moving to completed
@@ -429,15 +623,17 @@ func (s *service) UpdateTaskStatus(ctx context.Context, id uuid.UUID, status Tas
 
 	oldStatus := task.Status
 	task.Status = status
+	task.CustomStatus = customStatus
 	task.UpdatedAt = time.Now()
 
 	err = s.repo.Update(ctx, task)
 	if err != nil {
 		return nil, err
 	}
+	s.tasksChanged(ctx)
 
 	// Record status change activity
-	if callerID, ok := ctx.Value("user_id").(uuid.UUID); ok {
+	if callerID, ok := audit.ActorFrom(ctx); ok {
 		metadata := marshalTaskMetadata(map[string]interface{}{
 			"old_status": string(oldStatus),
 			"new_status": string(status),
@@ -467,13 +663,80 @@ func (s *service) UpdateTaskStatus(ctx context.Context, id uuid.UUID, status Tas
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
-	s.recordTaskActivity(ctx, task, task.CreatorID, "status_changed", map[string]interface{}{
+	s.recordTaskActivity(ctx, task, actorOf(ctx, task), "status_changed", map[string]interface{}{
 		"old_status": string(oldStatus),
 		"new_status": string(status),
 	})
+	s.publishStatusChanged(ctx, task, oldStatus, actorOf(ctx, task))
+	if status != oldStatus {
+		s.rollUpProgress(ctx, task.ParentTaskID)
+	}
+
+	return task, nil
+}
+
+// MoveTask reorders a task within its column or moves it to another status column.
+// Changing column follows the same transition and dependency rules as UpdateTaskStatus.
+func (s *service) MoveTask(ctx context.Context, id uuid.UUID, input MoveTaskInput) (*Task, error) {
+	if input.Status == "" {
+		return nil, ErrInvalidInput
+	}
+	if input.Position != nil && *input.Position < 0 {
+		return nil, ErrInvalidInput
+	}
+
+	current, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status, customStatus := current.Status, current.CustomStatus
+	if input.Status != current.Status && string(input.Status) != current.CustomStatus {
+		status, customStatus, err = s.resolveStatus(ctx, current, input.Status, true)
+		if err != nil {
+			return nil, err
+		}
+		if status == TaskStatusCompleted {
+			completed, err := s.checkDependenciesCompleted(ctx, current.Dependencies)
+			if err != nil {
+				return nil, err
+			}
+			if !completed {
+				return nil, ErrDependencyFailed
+			}
+		}
+	}
+
+	index := -1
+	if input.Position != nil {
+		index = *input.Position
+	}
+	task, err := s.repo.Move(ctx, id, status, customStatus, index)
+	if err != nil {
+		return nil, err
+	}
+	s.tasksChanged(ctx)
+
+	userID := actorOf(ctx, task)
+	if current.Status != task.Status {
+		s.recordTaskActivity(ctx, task, userID, "status_changed", map[string]interface{}{
+			"old_status": string(current.Status),
+			"new_status": string(task.Status),
+			"position":   task.Position,
+		})
+	} else {
+		s.recordTaskActivity(ctx, task, userID, "task_moved", map[string]interface{}{
+			"status":   string(task.Status),
+			"position": task.Position,
+		})
+	}
+	s.publishStatusChanged(ctx, task, current.Status, userID)
+	if current.Status != task.Status {
+		s.rollUpProgress(ctx, task.ParentTaskID)
+	}
 
 	return task, nil
 }
@@ -488,11 +751,13 @@ func (s *service) DeleteTask(ctx context.Context, id uuid.UUID) error {
 	}
 
 	// Record deletion activity before deleting
-	if callerID, ok := ctx.Value("user_id").(uuid.UUID); ok {
+	var deletedBy *uuid.UUID
+	if callerID, ok := audit.ActorFrom(ctx); ok {
 		s.recordTaskDeletion(ctx, task.ID, callerID)
+		deletedBy = &callerID
 	}
 
-	s.recordTaskActivity(ctx, task, task.CreatorID, "task_deleted", map[string]interface{}{
+	s.recordTaskActivity(ctx, task, actorOf(ctx, task), "task_deleted", map[string]interface{}{
 		"title":  task.Title,
 		"status": task.Status,
 	})
@@ -508,10 +773,38 @@ func (s *service) DeleteTask(ctx context.Context, id uuid.UUID) error {
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id, deletedBy); err != nil {
+		return err
+	}
+	if s.bus != nil {
+		s.bus.Publish(ctx, events.TaskDeleted{
+			TaskID:         task.ID,
+			ProjectID:      task.ProjectID,
+			OrganizationID: task.OrganizationID,
+			Status:         string(task.Status),
+			DeletedAt:      time.Now(),
+		})
+	}
+	if s.auditor != nil {
+		s.auditor.Audit(ctx, audit.Event{
+			OrganizationID: task.OrganizationID,
+			ActorID:        deletedBy,
+			Action:         audit.ActionTaskDeleted,
+			TargetType:     "task",
+			TargetID:       &task.ID,
+			Metadata:       map[string]interface{}{"title": task.Title, "project_id": task.ProjectID},
+		})
+	}
+	defer s.tasksChanged(ctx)
+	// Subtasks of a deleted task move up to its parent
+	if err := s.repo.Reparent(ctx, id, task.ParentTaskID); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to move subtasks of deleted task", zap.String("task_id", id.String()), zap.Error(err))
+	}
+	s.rollUpProgress(ctx, task.ParentTaskID)
+	return nil
 }
 
 func (s *service) recordTaskDeletion(ctx context.Context, taskID, userID uuid.UUID) {
@@ -682,9 +975,10 @@ func (s *service) AssignTask(ctx context.Context, id uuid.UUID, assigneeID uuid.
 	if err != nil {
 		return nil, err
 	}
+	s.tasksChanged(ctx)
 
 	// Record assignment activity
-	if callerID, ok := ctx.Value("user_id").(uuid.UUID); ok {
+	if callerID, ok := audit.ActorFrom(ctx); ok {
 		metadata := map[string]interface{}{
 			"new_assignee_id": assigneeID.String(),
 		}
@@ -698,13 +992,64 @@ func (s *service) AssignTask(ctx context.Context, id uuid.UUID, assigneeID uuid.
 		s.recordTaskAssignment(ctx, task.ID, callerID, metadata)
 	}
 
-	s.recordTaskActivity(ctx, task, task.CreatorID, "task_assigned", map[string]interface{}{
+	s.recordTaskActivity(ctx, task, actorOf(ctx, task), "task_assigned", map[string]interface{}{
 		"new_assignee_id": assigneeID.String(),
 	})
+	if !wasAssigned || oldAssignee != assigneeID {
+		s.publishAssigned(ctx, task, actorOf(ctx, task))
+	}
 
 	return task, nil
 }
 
+// LogWork adds hours to the time spent on a task and records who logged them
+func (s *service) LogWork(ctx context.Context, id uuid.UUID, userID uuid.UUID, hours float64, note string) (*Task, error) {
+	if hours <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if err := s.repo.AddActualHours(ctx, id, hours); err != nil {
+		return nil, err
+	}
+	s.tasksChanged(ctx)
+
+	task, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	metadata := map[string]interface{}{"hours": hours}
+	if note != "" {
+		metadata["note"] = note
+	}
+	if err := s.RecordTaskActivity(ctx, RecordTaskActivityInput{
+		TaskID:   id,
+		UserID:   userID,
+		Action:   "work_logged",
+		Metadata: metadata,
+	}); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to record logged work", zap.String("task_id", id.String()), zap.Error(err))
+	}
+	s.rollUpProgress(ctx, task.ParentTaskID)
+	return task, nil
+}
+
+func (s *service) AdjustLoggedWork(ctx context.Context, id uuid.UUID, hours float64) (*Task, error) {
+	if hours != 0 {
+		if err := s.repo.AddActualHours(ctx, id, hours); err != nil {
+			return nil, err
+		}
+		s.tasksChanged(ctx)
+	}
+
+	task, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hours != 0 {
+		s.rollUpProgress(ctx, task.ParentTaskID)
+	}
+	return task, nil
+}
+
 func (s *service) recordTaskAssignment(ctx context.Context, taskID, userID uuid.UUID, metadata map[string]interface{}) {
 	metadataJSON, _ := json.Marshal(metadata)
 
@@ -747,6 +1092,81 @@ func (s *service) RecordTaskActivity(ctx context.Context, input RecordTaskActivi
 	return s.repo.RecordTaskActivity(ctx, analytics)
 }
 
+// AddComment leaves a comment on a task and records it in the task's activity
+func (s *service) AddComment(ctx context.Context, taskID, authorID uuid.UUID, body string) (*TaskComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > MaxCommentLength {
+		return nil, ErrInvalidComment
+	}
+
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &TaskComment{
+		TaskID:   task.ID,
+		AuthorID: authorID,
+		Body:     body,
+	}
+	if err := s.repo.CreateComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	s.recordTaskActivity(ctx, task, authorID, "comment_added", map[string]interface{}{
+		"comment_id": comment.ID,
+	})
+	return comment, nil
+}
+
+// ListComments returns the comments of a task, oldest first
+func (s *service) ListComments(ctx context.Context, taskID uuid.UUID, page, pageSize int) ([]TaskComment, int64, error) {
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListComments(ctx, taskID, page, pageSize)
+}
+
+// GetComment returns a comment of a task
+func (s *service) GetComment(ctx context.Context, taskID, commentID uuid.UUID) (*TaskComment, error) {
+	comment, err := s.repo.FindCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.TaskID != taskID {
+		return nil, ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// DeleteComment removes a comment. Only its author and the task creator may delete it.
+func (s *service) DeleteComment(ctx context.Context, taskID, commentID, userID uuid.UUID) error {
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	comment, err := s.repo.FindCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.TaskID != task.ID {
+		return ErrCommentNotFound
+	}
+	if comment.AuthorID != userID && task.CreatorID != userID {
+		return ErrCommentNotAllowed
+	}
+
+	if err := s.repo.DeleteComment(ctx, commentID); err != nil {
+		return err
+	}
+
+	s.recordTaskActivity(ctx, task, userID, "comment_deleted", map[string]interface{}{
+		"comment_id": comment.ID,
+		"author_id":  comment.AuthorID,
+	})
+	return nil
+}
+
 func (s *service) GetTaskAnalytics(ctx context.Context, taskID uuid.UUID, startTime, endTime time.Time, page, pageSize int) ([]TaskAnalytics, int64, error) {
 	filter := AnalyticsFilter{
 		TaskID:    &taskID,
@@ -860,13 +1280,13 @@ func (s *service) GetTodayTasks(ctx context.Context, userID uuid.UUID) ([]Task,
 			nilDueDateCount++
 		}
 	}
-	s.logger.Info("GetTodayTasks results",
+	tracing.Logger(ctx, s.logger).Info("GetTodayTasks results",
 		zap.String("user_id", userID.String()),
 		zap.Int("total_found", len(tasks)),
 		zap.Int("nil_due_date_count", nilDueDateCount))
 
 	// Return all tasks, even those with nil DueDate
-	s.logger.Info("GetTodayTasks returning all active tasks",
+	tracing.Logger(ctx, s.logger).Info("GetTodayTasks returning all active tasks",
 		zap.Int("active_count", len(tasks)))
 
 	return tasks, nil
@@ -877,6 +1297,50 @@ func taskStatusPtr(status TaskStatus) *TaskStatus {
 	return &status
 }
 
+// publishStatusChanged announces a change of the task's status, and its completion when
+// the status just changed to completed
+func (s *service) publishStatusChanged(ctx context.Context, task *Task, oldStatus TaskStatus, userID uuid.UUID) {
+	if s.bus == nil || task.Status == oldStatus {
+		return
+	}
+	s.bus.Publish(ctx, events.TaskStatusChanged{
+		TaskID:         task.ID,
+		ProjectID:      task.ProjectID,
+		OrganizationID: task.OrganizationID,
+		PreviousStatus: string(oldStatus),
+		Status:         string(task.Status),
+		ChangedBy:      userID,
+		ChangedAt:      task.UpdatedAt,
+	})
+	if task.Status != TaskStatusCompleted {
+		return
+	}
+	s.bus.Publish(ctx, events.TaskCompleted{
+		TaskID:         task.ID,
+		ProjectID:      task.ProjectID,
+		OrganizationID: task.OrganizationID,
+		CompletedBy:    userID,
+		CompletedAt:    task.UpdatedAt,
+	})
+}
+
+// publishAssigned announces the task's current assignee
+func (s *service) publishAssigned(ctx context.Context, task *Task, assignedBy uuid.UUID) {
+	if s.bus == nil || task.AssigneeID == nil {
+		return
+	}
+	s.bus.Publish(ctx, events.TaskAssigned{
+		TaskID:         task.ID,
+		ProjectID:      task.ProjectID,
+		OrganizationID: task.OrganizationID,
+		Title:          task.Title,
+		Priority:       string(task.Priority),
+		DueDate:        task.DueDate,
+		AssigneeID:     *task.AssigneeID,
+		AssignedBy:     assignedBy,
+	})
+}
+
 func (s *service) recordTaskActivity(ctx context.Context, task *Task, userID uuid.UUID, action string, metadata map[string]interface{}) {
 	if metadata == nil {
 		metadata = make(map[string]interface{})
@@ -892,6 +1356,34 @@ func (s *service) recordTaskActivity(ctx context.Context, task *Task, userID uui
 		Details:   metadata,
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
+	}
+
+	// Append to the project activity feed
+	if eventType, ok := taskActivityTypes[action]; ok && s.activity != nil {
+		projectID := task.ProjectID
+		actorID := userID
+		s.activity.Record(ctx, activity.RecordInput{
+			OrganizationID: task.OrganizationID,
+			ProjectID:      &projectID,
+			ActorID:        &actorID,
+			Type:           eventType,
+			EntityType:     "task",
+			EntityID:       task.ID,
+			Summary:        task.Title,
+			Metadata:       metadata,
+		})
+	}
+
+	if eventType, ok := taskWebhookEvents[action]; ok && s.webhooks != nil {
+		s.webhooks.Publish(ctx, webhooks.Event{
+			Type:           eventType,
+			OrganizationID: task.OrganizationID,
+			UserID:         userID,
+			Data: map[string]interface{}{
+				"task":    task,
+				"details": metadata,
+			},
+		})
 	}
 }
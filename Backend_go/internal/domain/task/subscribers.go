@@ -0,0 +1,49 @@
+package task
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+)
+
+// SubscribeAssignmentNotifications notifies assignees, in the app and by email, when
+// someone else assigns them a task
+func SubscribeAssignmentNotifications(bus *events.Bus, notifier notification.DomainNotifier) {
+	events.Subscribe(bus, "task_assignment_notifications", events.Async, func(ctx context.Context, event events.TaskAssigned) error {
+		if event.AssigneeID == event.AssignedBy {
+			return nil
+		}
+		data := map[string]string{
+			"taskId":    event.TaskID.String(),
+			"taskTitle": event.Title,
+			"priority":  event.Priority,
+		}
+		if event.DueDate != nil {
+			data["dueDate"] = event.DueDate.Format("Mon Jan 2, 2006")
+		}
+		return notifier.NotifyUserWithDelivery(ctx, event.AssigneeID, notification.TaskAssigned,
+			"New task: "+event.Title, "You have been assigned a task: "+event.Title, data, "task", event.TaskID,
+			[]notification.DeliveryMethod{notification.InApp, notification.Email})
+	})
+}
+
+// SubscribeRiskNotifications notifies a task's owner in the app when the nightly analysis
+// finds the task at risk
+func SubscribeRiskNotifications(bus *events.Bus, notifier notification.DomainNotifier) {
+	events.Subscribe(bus, "task_risk_notifications", events.Async, func(ctx context.Context, event events.TaskAtRisk) error {
+		data := map[string]string{
+			"taskId":    event.TaskID.String(),
+			"taskTitle": event.Title,
+			"level":     event.Level,
+			"reasons":   strings.Join(event.Reasons, "; "),
+		}
+		if event.DueDate != nil {
+			data["dueDate"] = event.DueDate.Format("Mon Jan 2, 2006")
+		}
+		return notifier.NotifyUserWithDelivery(ctx, event.OwnerID, notification.TaskAtRisk,
+			"Task at risk: "+event.Title, strings.Join(event.Reasons, ". "), data, "task", event.TaskID,
+			[]notification.DeliveryMethod{notification.InApp})
+	})
+}
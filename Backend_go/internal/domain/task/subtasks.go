@@ -0,0 +1,146 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrTaskCycle     = errors.New("a task cannot be nested under itself or one of its subtasks")
+	ErrInvalidParent = errors.New("parent task must belong to the same project")
+)
+
+// subtaskProgressKey is the ProgressMetrics entry holding a parent task's subtask rollup
+const subtaskProgressKey = "subtasks"
+
+// SubtaskRollup summarizes every task nested below a task, at any depth
+type SubtaskRollup struct {
+	Total          int     `json:"total"`
+	Completed      int     `json:"completed"`
+	Percent        float64 `json:"percent"`
+	EstimatedHours float64 `json:"estimated_hours"`
+	ActualHours    float64 `json:"actual_hours"`
+}
+
+// SubtaskNode is a task with its subtask tree
+type SubtaskNode struct {
+	Task     Task          `json:"task"`
+	Rollup   SubtaskRollup `json:"rollup"`
+	Children []SubtaskNode `json:"children"`
+}
+
+// GetSubtasks returns the task with its full subtask tree, each level carrying the
+// completion rollup of everything below it
+func (s *service) GetSubtasks(ctx context.Context, id uuid.UUID) (*SubtaskNode, error) {
+	root, err := s.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := s.repo.FindDescendants(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uuid.UUID][]Task)
+	for _, t := range descendants {
+		children[*t.ParentTaskID] = append(children[*t.ParentTaskID], t)
+	}
+	node := buildSubtaskNode(*root, children)
+	return &node, nil
+}
+
+func buildSubtaskNode(t Task, children map[uuid.UUID][]Task) SubtaskNode {
+	node := SubtaskNode{Task: t, Children: []SubtaskNode{}}
+	for _, child := range children[t.ID] {
+		childNode := buildSubtaskNode(child, children)
+		node.Rollup.Total += 1 + childNode.Rollup.Total
+		node.Rollup.Completed += childNode.Rollup.Completed
+		if child.Status == TaskStatusCompleted {
+			node.Rollup.Completed++
+		}
+		node.Rollup.EstimatedHours += child.EstimatedHours + childNode.Rollup.EstimatedHours
+		node.Rollup.ActualHours += child.ActualHours + childNode.Rollup.ActualHours
+		node.Children = append(node.Children, childNode)
+	}
+	node.Rollup.Percent = rollupPercent(node.Rollup.Completed, node.Rollup.Total)
+	return node
+}
+
+func rollupPercent(completed, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(completed)/float64(total)*1000) / 10
+}
+
+// validateParent checks that the task can be nested under parentID: the parent exists in
+// the same project and is not the task itself or one of its subtasks
+func (s *service) validateParent(ctx context.Context, taskID, projectID, parentID uuid.UUID) error {
+	parent, err := s.repo.FindByID(ctx, parentID)
+	if errors.Is(err, ErrTaskNotFound) || (err == nil && parent == nil) {
+		return ErrInvalidParent
+	}
+	if err != nil {
+		return err
+	}
+	if parent.ProjectID != projectID {
+		return ErrInvalidParent
+	}
+
+	// Walk up from the new parent; meeting the task means it would become its own ancestor
+	seen := make(map[uuid.UUID]bool)
+	for current := parent; current != nil; {
+		if current.ID == taskID || seen[current.ID] {
+			return ErrTaskCycle
+		}
+		seen[current.ID] = true
+		if current.ParentTaskID == nil {
+			return nil
+		}
+		current, err = s.repo.FindByID(ctx, *current.ParentTaskID)
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollUpProgress refreshes the subtask rollup stored in the progress metrics of the
+// parent and every ancestor above it. Failures are logged; the change that triggered
+// the rollup has already been saved.
+func (s *service) rollUpProgress(ctx context.Context, parentID *uuid.UUID) {
+	if parentID == nil {
+		return
+	}
+	defer s.tasksChanged(ctx)
+	seen := make(map[uuid.UUID]bool)
+	for parentID != nil && !seen[*parentID] {
+		seen[*parentID] = true
+		node, err := s.GetSubtasks(ctx, *parentID)
+		if err != nil {
+			if !errors.Is(err, ErrTaskNotFound) {
+				tracing.Logger(ctx, s.logger).Error("Failed to roll up subtask progress", zap.String("task_id", parentID.String()), zap.Error(err))
+			}
+			return
+		}
+
+		metrics := make(map[string]interface{}, len(node.Task.ProgressMetrics)+1)
+		for k, v := range node.Task.ProgressMetrics {
+			metrics[k] = v
+		}
+		metrics[subtaskProgressKey] = node.Rollup
+		if err := s.repo.UpdateProgressMetrics(ctx, node.Task.ID, metrics); err != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to save subtask progress", zap.String("task_id", parentID.String()), zap.Error(err))
+			return
+		}
+		parentID = node.Task.ParentTaskID
+	}
+}
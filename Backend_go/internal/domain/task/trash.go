@@ -0,0 +1,57 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrRestoreNotAllowed = errors.New("only the creator of a task or whoever deleted it can restore it")
+
+// ListDeletedTasks returns the tasks the user created or deleted that went to the trash since a time
+func (s *service) ListDeletedTasks(ctx context.Context, userID uuid.UUID, since time.Time) ([]Task, error) {
+	return s.repo.FindDeleted(ctx, userID, since)
+}
+
+// RestoreTask takes a task out of the trash. Its subtasks were moved up when it was deleted
+// and stay where they are; a task whose parent is gone is restored at the top level.
+func (s *service) RestoreTask(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Task, error) {
+	task, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.CreatorID != userID && (task.DeletedBy == nil || *task.DeletedBy != userID) {
+		return nil, ErrRestoreNotAllowed
+	}
+
+	parentID := task.ParentTaskID
+	if parentID != nil {
+		if _, err := s.repo.FindByID(ctx, *parentID); err != nil {
+			if !errors.Is(err, ErrTaskNotFound) {
+				return nil, err
+			}
+			parentID = nil
+		}
+	}
+	if err := s.repo.Restore(ctx, id, parentID); err != nil {
+		return nil, err
+	}
+	defer s.tasksChanged(ctx)
+
+	restored, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.recordTaskActivity(ctx, restored, userID, "task_restored", map[string]interface{}{
+		"title": restored.Title,
+	})
+	s.rollUpProgress(ctx, restored.ParentTaskID)
+	return restored, nil
+}
+
+// PurgeDeletedTasks permanently removes tasks that went to the trash before a time
+func (s *service) PurgeDeletedTasks(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, before)
+}
@@ -0,0 +1,141 @@
+package timetracking
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxEntryDuration is the longest a single entry can be. Timers left running longer
+	// are stopped at this length.
+	MaxEntryDuration = 24 * time.Hour
+	// MaxTimesheetDays is the longest range a timesheet covers
+	MaxTimesheetDays = 366
+	// maxNoteLength is the longest note an entry can carry
+	maxNoteLength = 500
+)
+
+var (
+	ErrEntryNotFound  = errors.New("time entry not found")
+	ErrTimerRunning   = errors.New("a timer is already running")
+	ErrNoTimer        = errors.New("no timer is running on this task")
+	ErrInvalidEntry   = errors.New("time entries need a start, a positive duration of at most 24 hours and a note of at most 500 characters")
+	ErrInvalidRange   = errors.New("timesheet range must end after it starts and cover at most 366 days")
+	ErrNotEntryOwner  = errors.New("only the user who logged a time entry can change it")
+	ErrEntryIsRunning = errors.New("stop the timer before editing its entry")
+)
+
+// Source tells how a time entry was logged
+type Source string
+
+const (
+	SourceTimer  Source = "timer"
+	SourceManual Source = "manual"
+)
+
+// TimeEntry is time a user spent on a task. A timer is an entry without an end; its
+// duration is added to the task's actual hours when it stops.
+type TimeEntry struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	TaskID         uuid.UUID  `json:"task_id" gorm:"type:uuid;not null;index"`
+	ProjectID      uuid.UUID  `json:"project_id" gorm:"type:uuid;not null;index:idx_time_entry_project,priority:1"`
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null;index"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_time_entry_user,priority:1;uniqueIndex:idx_time_entry_running,where:ended_at is null"`
+	StartedAt      time.Time  `json:"started_at" gorm:"not null;index:idx_time_entry_user,priority:2;index:idx_time_entry_project,priority:2"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	// DurationSeconds is zero while the timer runs
+	DurationSeconds int64     `json:"duration_seconds" gorm:"not null;default:0"`
+	Note            string    `json:"note,omitempty" gorm:"size:500"`
+	Source          Source    `json:"source" gorm:"type:varchar(20);not null"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the TimeEntry model
+func (TimeEntry) TableName() string {
+	return "time_entries"
+}
+
+// BeforeCreate is called before creating a new time entry record
+func (e *TimeEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// Running reports whether the entry is a timer that has not stopped
+func (e *TimeEntry) Running() bool {
+	return e.EndedAt == nil
+}
+
+// Hours returns the logged duration in hours
+func (e *TimeEntry) Hours() float64 {
+	return float64(e.DurationSeconds) / 3600
+}
+
+// TimesheetFilter selects the finished entries of a timesheet. From and To are dates;
+// entries that started on either day are included.
+type TimesheetFilter struct {
+	OrganizationID uuid.UUID
+	UserID         *uuid.UUID
+	ProjectID      *uuid.UUID
+	From           time.Time
+	To             time.Time
+}
+
+// UserHours is the time a user logged
+type UserHours struct {
+	UserID uuid.UUID `json:"user_id"`
+	Hours  float64   `json:"hours"`
+}
+
+// TaskHours is the time logged on a task
+type TaskHours struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Hours     float64   `json:"hours"`
+}
+
+// DayHours is the time logged on a day
+type DayHours struct {
+	Date  string  `json:"date"`
+	Hours float64 `json:"hours"`
+}
+
+// Timesheet is the time logged in a date range, by day, task and user
+type Timesheet struct {
+	From       time.Time   `json:"from"`
+	To         time.Time   `json:"to"`
+	UserID     *uuid.UUID  `json:"user_id,omitempty"`
+	ProjectID  *uuid.UUID  `json:"project_id,omitempty"`
+	TotalHours float64     `json:"total_hours"`
+	Days       []DayHours  `json:"days"`
+	Tasks      []TaskHours `json:"tasks"`
+	Users      []UserHours `json:"users"`
+	Entries    []TimeEntry `json:"entries"`
+}
+
+// TaskSummary compares the time logged on a task with its estimate. ActualHours is the
+// task's total, which also counts work logged without time entries; TrackedHours is
+// the part logged as entries.
+type TaskSummary struct {
+	TaskID         uuid.UUID   `json:"task_id"`
+	EstimatedHours float64     `json:"estimated_hours"`
+	ActualHours    float64     `json:"actual_hours"`
+	TrackedHours   float64     `json:"tracked_hours"`
+	RemainingHours float64     `json:"remaining_hours"`
+	VarianceHours  float64     `json:"variance_hours"`
+	OverEstimate   bool        `json:"over_estimate"`
+	RunningTimers  int         `json:"running_timers"`
+	Users          []UserHours `json:"users"`
+}
+
+// roundHours rounds hours to two decimals for reports
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
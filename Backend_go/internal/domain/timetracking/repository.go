@@ -0,0 +1,127 @@
+package timetracking
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for time entry data access
+type Repository interface {
+	// Create stores an entry. Starting a second timer for a user returns ErrTimerRunning.
+	Create(ctx context.Context, entry *TimeEntry) error
+	FindByID(ctx context.Context, id uuid.UUID) (*TimeEntry, error)
+	// FindRunning returns the user's running timer, or nil when there is none
+	FindRunning(ctx context.Context, userID uuid.UUID) (*TimeEntry, error)
+	// Finish stops a running timer. It returns ErrNoTimer when the timer already stopped,
+	// so a timer is only counted once.
+	Finish(ctx context.Context, id uuid.UUID, endedAt time.Time, durationSeconds int64) error
+	Update(ctx context.Context, entry *TimeEntry) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListByTask returns the entries of a task, newest first
+	ListByTask(ctx context.Context, taskID uuid.UUID) ([]TimeEntry, error)
+	// ListFinished returns the stopped entries matching the filter, oldest first
+	ListFinished(ctx context.Context, filter TimesheetFilter) ([]TimeEntry, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new time entry repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+func (r *repository) Create(ctx context.Context, entry *TimeEntry) error {
+	err := r.db.WithContext(ctx).Create(entry).Error
+	if err != nil && (errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "SQLSTATE 23505")) {
+		return ErrTimerRunning
+	}
+	return err
+}
+
+func (r *repository) FindByID(ctx context.Context, id uuid.UUID) (*TimeEntry, error) {
+	var entry TimeEntry
+	if err := r.db.WithContext(ctx).First(&entry, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *repository) FindRunning(ctx context.Context, userID uuid.UUID) (*TimeEntry, error) {
+	var entry TimeEntry
+	err := r.db.WithContext(ctx).First(&entry, "user_id = ? AND ended_at IS NULL", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *repository) Finish(ctx context.Context, id uuid.UUID, endedAt time.Time, durationSeconds int64) error {
+	result := r.db.WithContext(ctx).Model(&TimeEntry{}).
+		Where("id = ? AND ended_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"ended_at":         endedAt,
+			"duration_seconds": durationSeconds,
+			"updated_at":       time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNoTimer
+	}
+	return nil
+}
+
+func (r *repository) Update(ctx context.Context, entry *TimeEntry) error {
+	return r.db.WithContext(ctx).Save(entry).Error
+}
+
+func (r *repository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&TimeEntry{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+func (r *repository) ListByTask(ctx context.Context, taskID uuid.UUID) ([]TimeEntry, error) {
+	var entries []TimeEntry
+	err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("started_at DESC").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *repository) ListFinished(ctx context.Context, filter TimesheetFilter) ([]TimeEntry, error) {
+	query := r.db.WithContext(ctx).Model(&TimeEntry{}).
+		Where("organization_id = ? AND ended_at IS NOT NULL", filter.OrganizationID).
+		Where("started_at >= ? AND started_at < ?", filter.From, filter.To.AddDate(0, 0, 1))
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+
+	var entries []TimeEntry
+	err := query.Order("started_at ASC").Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,378 @@
+package timetracking
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TaskStore reads tasks and keeps their actual hours in step with their time entries
+type TaskStore interface {
+	GetTask(ctx context.Context, id uuid.UUID) (*task.Task, error)
+	LogWork(ctx context.Context, id uuid.UUID, userID uuid.UUID, hours float64, note string) (*task.Task, error)
+	AdjustLoggedWork(ctx context.Context, id uuid.UUID, hours float64) (*task.Task, error)
+}
+
+// CreateEntryInput is time logged by hand
+type CreateEntryInput struct {
+	StartedAt time.Time
+	Hours     float64
+	Note      string
+}
+
+// UpdateEntryInput changes a stopped entry. Nil fields are left as they are.
+type UpdateEntryInput struct {
+	StartedAt *time.Time
+	Hours     *float64
+	Note      *string
+}
+
+// Service defines the interface for time tracking. Callers check access to the task or
+// project; the service checks that only an entry's user changes it.
+type Service interface {
+	// StartTimer starts a timer for the user on the task, stopping the user's timer on
+	// any other task
+	StartTimer(ctx context.Context, taskID, userID uuid.UUID, note string) (*TimeEntry, error)
+	// StopTimer stops the user's timer on the task and adds its time to the task
+	StopTimer(ctx context.Context, taskID, userID uuid.UUID) (*TimeEntry, error)
+	// RunningTimer returns the user's running timer, or nil when there is none
+	RunningTimer(ctx context.Context, userID uuid.UUID) (*TimeEntry, error)
+
+	CreateEntry(ctx context.Context, taskID, userID uuid.UUID, input CreateEntryInput) (*TimeEntry, error)
+	UpdateEntry(ctx context.Context, taskID, id, userID uuid.UUID, input UpdateEntryInput) (*TimeEntry, error)
+	// DeleteEntry removes an entry and its time from the task. Deleting a running timer
+	// discards it.
+	DeleteEntry(ctx context.Context, taskID, id, userID uuid.UUID) error
+	ListTaskEntries(ctx context.Context, taskID uuid.UUID) ([]TimeEntry, error)
+
+	// TaskSummary compares the time logged on the task with its estimate
+	TaskSummary(ctx context.Context, taskID uuid.UUID) (*TaskSummary, error)
+	// Timesheet reports the time logged in an organization, optionally for one user or
+	// project, between two dates
+	Timesheet(ctx context.Context, filter TimesheetFilter) (*Timesheet, error)
+}
+
+type service struct {
+	repo   Repository
+	tasks  TaskStore
+	logger *zap.Logger
+}
+
+// NewService creates a new time tracking service
+func NewService(repo Repository, tasks TaskStore, logger *zap.Logger) Service {
+	return &service{repo: repo, tasks: tasks, logger: logger}
+}
+
+func (s *service) StartTimer(ctx context.Context, taskID, userID uuid.UUID, note string) (*TimeEntry, error) {
+	note = strings.TrimSpace(note)
+	if len(note) > maxNoteLength {
+		return nil, ErrInvalidEntry
+	}
+	t, err := s.tasks.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := s.repo.FindRunning(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		if running.TaskID == taskID {
+			return nil, ErrTimerRunning
+		}
+		if _, err := s.finish(ctx, running); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	entry := &TimeEntry{
+		TaskID:         t.ID,
+		ProjectID:      t.ProjectID,
+		OrganizationID: t.OrganizationID,
+		UserID:         userID,
+		StartedAt:      now,
+		Note:           note,
+		Source:         SourceTimer,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *service) StopTimer(ctx context.Context, taskID, userID uuid.UUID) (*TimeEntry, error) {
+	running, err := s.repo.FindRunning(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if running == nil || running.TaskID != taskID {
+		return nil, ErrNoTimer
+	}
+	return s.finish(ctx, running)
+}
+
+// finish stops a running timer, capped at MaxEntryDuration, and logs its time on the task
+func (s *service) finish(ctx context.Context, entry *TimeEntry) (*TimeEntry, error) {
+	endedAt := time.Now()
+	if endedAt.Sub(entry.StartedAt) > MaxEntryDuration {
+		endedAt = entry.StartedAt.Add(MaxEntryDuration)
+	}
+	seconds := int64(endedAt.Sub(entry.StartedAt) / time.Second)
+	if err := s.repo.Finish(ctx, entry.ID, endedAt, seconds); err != nil {
+		return nil, err
+	}
+	entry.EndedAt = &endedAt
+	entry.DurationSeconds = seconds
+	entry.UpdatedAt = time.Now()
+
+	if seconds > 0 {
+		s.logWork(ctx, entry)
+	}
+	return entry, nil
+}
+
+func (s *service) RunningTimer(ctx context.Context, userID uuid.UUID) (*TimeEntry, error) {
+	return s.repo.FindRunning(ctx, userID)
+}
+
+func (s *service) CreateEntry(ctx context.Context, taskID, userID uuid.UUID, input CreateEntryInput) (*TimeEntry, error) {
+	note := strings.TrimSpace(input.Note)
+	seconds, err := validateEntry(input.StartedAt, input.Hours, note)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.tasks.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	endedAt := input.StartedAt.Add(time.Duration(seconds) * time.Second)
+	entry := &TimeEntry{
+		TaskID:          t.ID,
+		ProjectID:       t.ProjectID,
+		OrganizationID:  t.OrganizationID,
+		UserID:          userID,
+		StartedAt:       input.StartedAt,
+		EndedAt:         &endedAt,
+		DurationSeconds: seconds,
+		Note:            note,
+		Source:          SourceManual,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.repo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	s.logWork(ctx, entry)
+	return entry, nil
+}
+
+func (s *service) UpdateEntry(ctx context.Context, taskID, id, userID uuid.UUID, input UpdateEntryInput) (*TimeEntry, error) {
+	entry, err := s.ownedEntry(ctx, taskID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Running() {
+		return nil, ErrEntryIsRunning
+	}
+
+	startedAt, hours, note := entry.StartedAt, entry.Hours(), entry.Note
+	if input.StartedAt != nil {
+		startedAt = *input.StartedAt
+	}
+	if input.Hours != nil {
+		hours = *input.Hours
+	}
+	if input.Note != nil {
+		note = strings.TrimSpace(*input.Note)
+	}
+	seconds, err := validateEntry(startedAt, hours, note)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := seconds - entry.DurationSeconds
+	endedAt := startedAt.Add(time.Duration(seconds) * time.Second)
+	entry.StartedAt = startedAt
+	entry.EndedAt = &endedAt
+	entry.DurationSeconds = seconds
+	entry.Note = note
+	entry.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, entry); err != nil {
+		return nil, err
+	}
+	s.adjustWork(ctx, entry.TaskID, delta)
+	return entry, nil
+}
+
+func (s *service) DeleteEntry(ctx context.Context, taskID, id, userID uuid.UUID) error {
+	entry, err := s.ownedEntry(ctx, taskID, id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if !entry.Running() {
+		s.adjustWork(ctx, entry.TaskID, -entry.DurationSeconds)
+	}
+	return nil
+}
+
+func (s *service) ListTaskEntries(ctx context.Context, taskID uuid.UUID) ([]TimeEntry, error) {
+	return s.repo.ListByTask(ctx, taskID)
+}
+
+func (s *service) TaskSummary(ctx context.Context, taskID uuid.UUID) (*TaskSummary, error) {
+	t, err := s.tasks.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &TaskSummary{
+		TaskID:         t.ID,
+		EstimatedHours: t.EstimatedHours,
+		ActualHours:    roundHours(t.ActualHours),
+		RemainingHours: roundHours(math.Max(0, t.EstimatedHours-t.ActualHours)),
+		VarianceHours:  roundHours(t.ActualHours - t.EstimatedHours),
+		OverEstimate:   t.EstimatedHours > 0 && t.ActualHours > t.EstimatedHours,
+	}
+	byUser := make(map[uuid.UUID]float64)
+	var tracked float64
+	for i := range entries {
+		if entries[i].Running() {
+			summary.RunningTimers++
+			continue
+		}
+		tracked += entries[i].Hours()
+		byUser[entries[i].UserID] += entries[i].Hours()
+	}
+	summary.TrackedHours = roundHours(tracked)
+	summary.Users = userHours(byUser)
+	return summary, nil
+}
+
+func (s *service) Timesheet(ctx context.Context, filter TimesheetFilter) (*Timesheet, error) {
+	if filter.To.Before(filter.From) || filter.To.Sub(filter.From) >= MaxTimesheetDays*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+	entries, err := s.repo.ListFinished(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &Timesheet{
+		From:      filter.From,
+		To:        filter.To,
+		UserID:    filter.UserID,
+		ProjectID: filter.ProjectID,
+		Entries:   entries,
+	}
+
+	// Every day of the range is listed, with zero hours when nothing was logged
+	dayIndex := make(map[string]int)
+	for day := filter.From; !day.After(filter.To); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		dayIndex[date] = len(sheet.Days)
+		sheet.Days = append(sheet.Days, DayHours{Date: date})
+	}
+
+	byTask := make(map[uuid.UUID]*TaskHours)
+	byUser := make(map[uuid.UUID]float64)
+	var total float64
+	for i := range entries {
+		hours := entries[i].Hours()
+		total += hours
+		if index, ok := dayIndex[entries[i].StartedAt.In(filter.From.Location()).Format("2006-01-02")]; ok {
+			sheet.Days[index].Hours += hours
+		}
+		if th, ok := byTask[entries[i].TaskID]; ok {
+			th.Hours += hours
+		} else {
+			byTask[entries[i].TaskID] = &TaskHours{TaskID: entries[i].TaskID, ProjectID: entries[i].ProjectID, Hours: hours}
+		}
+		byUser[entries[i].UserID] += hours
+	}
+
+	sheet.TotalHours = roundHours(total)
+	for i := range sheet.Days {
+		sheet.Days[i].Hours = roundHours(sheet.Days[i].Hours)
+	}
+	sheet.Tasks = make([]TaskHours, 0, len(byTask))
+	for _, th := range byTask {
+		th.Hours = roundHours(th.Hours)
+		sheet.Tasks = append(sheet.Tasks, *th)
+	}
+	sort.Slice(sheet.Tasks, func(i, j int) bool { return sheet.Tasks[i].Hours > sheet.Tasks[j].Hours })
+	sheet.Users = userHours(byUser)
+	return sheet, nil
+}
+
+// ownedEntry returns an entry of the task that belongs to the user
+func (s *service) ownedEntry(ctx context.Context, taskID, id, userID uuid.UUID) (*TimeEntry, error) {
+	entry, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.TaskID != taskID {
+		return nil, ErrEntryNotFound
+	}
+	if entry.UserID != userID {
+		return nil, ErrNotEntryOwner
+	}
+	return entry, nil
+}
+
+// logWork adds a new entry's time to its task. The entry is already stored, so a failure
+// is logged rather than returned.
+func (s *service) logWork(ctx context.Context, entry *TimeEntry) {
+	if _, err := s.tasks.LogWork(ctx, entry.TaskID, entry.UserID, entry.Hours(), entry.Note); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to log tracked time on task",
+			zap.String("task_id", entry.TaskID.String()), zap.String("entry_id", entry.ID.String()), zap.Error(err))
+	}
+}
+
+// adjustWork corrects the task's time after an entry changed or was deleted
+func (s *service) adjustWork(ctx context.Context, taskID uuid.UUID, deltaSeconds int64) {
+	if deltaSeconds == 0 {
+		return
+	}
+	if _, err := s.tasks.AdjustLoggedWork(ctx, taskID, float64(deltaSeconds)/3600); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to adjust tracked time on task", zap.String("task_id", taskID.String()), zap.Error(err))
+	}
+}
+
+// validateEntry checks a manual entry and returns its duration in seconds
+func validateEntry(startedAt time.Time, hours float64, note string) (int64, error) {
+	duration := time.Duration(hours * float64(time.Hour))
+	if startedAt.IsZero() || hours <= 0 || duration > MaxEntryDuration || duration < time.Second || len(note) > maxNoteLength {
+		return 0, ErrInvalidEntry
+	}
+	return int64(duration / time.Second), nil
+}
+
+// userHours lists hours by user, most first
+func userHours(byUser map[uuid.UUID]float64) []UserHours {
+	list := make([]UserHours, 0, len(byUser))
+	for userID, hours := range byUser {
+		list = append(list, UserHours{UserID: userID, Hours: roundHours(hours)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Hours > list[j].Hours })
+	return list
+}
@@ -0,0 +1,87 @@
+package timezone
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrSameTimezone     = errors.New("timezone is unchanged")
+	ErrNothingToMigrate = errors.New("nothing selected to shift")
+)
+
+// Reasons an event is left at its current time
+const (
+	SkipReasonAllDay = "all_day"
+	SkipReasonShared = "shared"
+)
+
+// MigrationInput describes a timezone change and which of the user's data should
+// keep its local times
+type MigrationInput struct {
+	Timezone          string
+	ShiftEvents       bool
+	ShiftHabits       bool
+	ShiftWorkingHours bool
+}
+
+// Plan lists what a timezone change moves. The same plan is returned by a preview
+// and by the migration that applies it.
+type Plan struct {
+	FromTimezone  string              `json:"from_timezone"`
+	ToTimezone    string              `json:"to_timezone"`
+	Events        []EventShift        `json:"events"`
+	SkippedEvents []SkippedEvent      `json:"skipped_events"`
+	Habits        []HabitShift        `json:"habits"`
+	WorkingHours  *WorkingHoursChange `json:"working_hours,omitempty"`
+	Applied       bool                `json:"applied"`
+}
+
+// EventShift is a future event moved so its local start and end are unchanged.
+// Recurring events move as a whole series, unless the series has already started: then
+// it is split, and the occurrences from its next one on move to a new event. Old and
+// new times are those of the first occurrence that moves.
+type EventShift struct {
+	EventID   uuid.UUID `json:"event_id"`
+	Title     string    `json:"title"`
+	Recurring bool      `json:"recurring"`
+	// Split is set when past occurrences of the series stay where they are
+	Split       bool      `json:"split"`
+	OldStart    time.Time `json:"old_start"`
+	OldEnd      time.Time `json:"old_end"`
+	NewStart    time.Time `json:"new_start"`
+	NewEnd      time.Time `json:"new_end"`
+	Occurrences int       `json:"occurrences"`
+	Exceptions  int       `json:"exceptions"`
+}
+
+// SkippedEvent is a future event the migration leaves alone
+type SkippedEvent struct {
+	EventID uuid.UUID `json:"event_id"`
+	Title   string    `json:"title"`
+	Reason  string    `json:"reason"`
+}
+
+// HabitShift is a habit whose UTC reminder time changes
+type HabitShift struct {
+	HabitID         uuid.UUID `json:"habit_id"`
+	Title           string    `json:"title"`
+	OldReminderTime string    `json:"old_reminder_time"`
+	NewReminderTime string    `json:"new_reminder_time"`
+}
+
+// WorkingHours mirrors the working_hours preference, in UTC
+type WorkingHours struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	Days  []string `json:"days,omitempty"`
+}
+
+// WorkingHoursChange is the working_hours preference before and after the migration
+type WorkingHoursChange struct {
+	Old WorkingHours `json:"old"`
+	New WorkingHours `json:"new"`
+}
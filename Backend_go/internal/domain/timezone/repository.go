@@ -0,0 +1,291 @@
+package timezone
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Migration is the set of writes that applies a timezone change
+type Migration struct {
+	// User carries the new timezone and preferences
+	User *user.User
+	// EventIDs are the events whose times are shifted
+	EventIDs []uuid.UUID
+	// ReminderTimes maps habit IDs to their new UTC reminder time
+	ReminderTimes map[uuid.UUID]string
+	// Shift maps an instant to the instant with the same local time in the new timezone
+	Shift func(time.Time) time.Time
+	// Now is when the plan was made; series that started before it are split there
+	Now time.Time
+}
+
+// Repository defines the interface for timezone migration data access
+type Repository interface {
+	FindUser(ctx context.Context, id uuid.UUID) (*user.User, error)
+	ListFutureEvents(ctx context.Context, userID uuid.UUID, now time.Time) ([]calendar.CalendarEvent, error)
+	CountSeries(ctx context.Context, eventID uuid.UUID, from time.Time) (occurrences int64, exceptions int64, err error)
+	ListHabitsWithReminders(ctx context.Context, userID uuid.UUID) ([]habits.Habit, error)
+	Apply(ctx context.Context, migration *Migration) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new timezone migration repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// FindUser loads a user
+func (r *repository) FindUser(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	var u user.User
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ListFutureEvents returns the user's own events that end after now, or whose
+// recurrence continues past now, with their rules and collaborators
+func (r *repository) ListFutureEvents(ctx context.Context, userID uuid.UUID, now time.Time) ([]calendar.CalendarEvent, error) {
+	var events []calendar.CalendarEvent
+	err := r.db.WithContext(ctx).
+		Preload("RecurrenceRules").
+		Preload("Collaborators").
+		Where("user_id = ?", userID).
+		Where(`end_time >= ? OR EXISTS (
+			SELECT 1 FROM recurrence_rules rr
+			WHERE rr.event_id = calendar_events.id AND (rr.until IS NULL OR rr.until >= ?))`, now, now).
+		Order("start_time ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// CountSeries counts the stored occurrences and exceptions of an event from a time on
+func (r *repository) CountSeries(ctx context.Context, eventID uuid.UUID, from time.Time) (int64, int64, error) {
+	var occurrences, exceptions int64
+	if err := r.db.WithContext(ctx).Model(&calendar.EventOccurrence{}).
+		Where("event_id = ? AND occurrence_time >= ?", eventID, from).Count(&occurrences).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.db.WithContext(ctx).Model(&calendar.EventException{}).
+		Where("event_id = ? AND original_time >= ?", eventID, from).Count(&exceptions).Error; err != nil {
+		return 0, 0, err
+	}
+	return occurrences, exceptions, nil
+}
+
+// ListHabitsWithReminders returns the user's habits that have a reminder time
+func (r *repository) ListHabitsWithReminders(ctx context.Context, userID uuid.UUID) ([]habits.Habit, error) {
+	var list []habits.Habit
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND reminder_time IS NOT NULL AND reminder_time <> ''", userID).
+		Order("created_at ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// Apply writes the migration in a single transaction: either every event, habit
+// and the user's timezone move, or nothing does
+func (r *repository) Apply(ctx context.Context, migration *Migration) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, eventID := range migration.EventIDs {
+			if err := shiftEvent(tx, eventID, migration.User.Timezone, migration.Shift, migration.Now); err != nil {
+				return err
+			}
+		}
+
+		for habitID, reminderTime := range migration.ReminderTimes {
+			err := tx.Model(&habits.Habit{}).
+				Where("id = ? AND user_id = ?", habitID, migration.User.ID).
+				Update("reminder_time", reminderTime).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result := tx.Save(migration.User)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// shiftEvent moves an event together with its recurrence limits, stored occurrences and
+// exceptions, and moves its series to the new timezone so later occurrences follow it.
+// A series that started before now is split instead, so its past occurrences stay put.
+func shiftEvent(tx *gorm.DB, eventID uuid.UUID, timezone string, shift func(time.Time) time.Time, now time.Time) error {
+	var event calendar.CalendarEvent
+	if err := tx.Preload("RecurrenceRules").Preload("Exceptions").First(&event, "id = ?", eventID).Error; err != nil {
+		return err
+	}
+	if len(event.RecurrenceRules) > 0 && event.StartTime.Before(now) {
+		return splitEvent(tx, &event, timezone, shift, now)
+	}
+
+	updatedAt := time.Now()
+	err := tx.Model(&calendar.CalendarEvent{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+		"start_time": shift(event.StartTime),
+		"end_time":   shift(event.EndTime),
+		"timezone":   timezone,
+		"updated_at": updatedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range event.RecurrenceRules {
+		updates := map[string]interface{}{
+			"timezone":   timezone,
+			"updated_at": updatedAt,
+		}
+		if rule.Until != nil {
+			updates["until"] = shift(*rule.Until)
+		}
+		err := tx.Model(&calendar.RecurrenceRule{}).Where("id = ?", rule.ID).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var occurrences []calendar.EventOccurrence
+	if err := tx.Where("event_id = ?", event.ID).Find(&occurrences).Error; err != nil {
+		return err
+	}
+	for _, occ := range occurrences {
+		err := tx.Model(&calendar.EventOccurrence{}).Where("id = ?", occ.ID).Updates(map[string]interface{}{
+			"occurrence_time": shift(occ.OccurrenceTime),
+			"updated_at":      updatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, exception := range event.Exceptions {
+		updates := map[string]interface{}{
+			"original_time": shift(exception.OriginalTime),
+			"updated_at":    updatedAt,
+		}
+		if exception.OverrideStartTime != nil {
+			updates["override_start_time"] = shift(*exception.OverrideStartTime)
+		}
+		if exception.OverrideEndTime != nil {
+			updates["override_end_time"] = shift(*exception.OverrideEndTime)
+		}
+		if err := tx.Model(&calendar.EventException{}).Where("id = ?", exception.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitEvent ends the series of an event before now and moves the rest of it to a new
+// event in the new timezone. The new event takes the stored occurrences and exceptions
+// from now on, and copies of the reminders.
+func splitEvent(tx *gorm.DB, event *calendar.CalendarEvent, timezone string, shift func(time.Time) time.Time, now time.Time) error {
+	split, err := calendar.SplitSeries(event, now, shift)
+	if err != nil {
+		return err
+	}
+	if split == nil {
+		// Nothing of the series is left to move
+		return nil
+	}
+
+	updatedAt := time.Now()
+	rest := *event
+	rest.ID = uuid.New()
+	rest.StartTime = shift(split.Start)
+	rest.EndTime = shift(split.Start.Add(event.EndTime.Sub(event.StartTime)))
+	rest.Timezone = timezone
+	rest.CreatedAt = updatedAt
+	rest.UpdatedAt = updatedAt
+	if err := tx.Omit(clause.Associations).Create(&rest).Error; err != nil {
+		return err
+	}
+
+	split.Head.UpdatedAt = updatedAt
+	if err := tx.Save(split.Head).Error; err != nil {
+		return err
+	}
+	split.Tail.EventID = rest.ID
+	split.Tail.Timezone = timezone
+	split.Tail.CreatedAt = updatedAt
+	split.Tail.UpdatedAt = updatedAt
+	if err := tx.Create(split.Tail).Error; err != nil {
+		return err
+	}
+
+	var reminders []calendar.EventReminder
+	if err := tx.Where("event_id = ?", event.ID).Find(&reminders).Error; err != nil {
+		return err
+	}
+	for _, reminder := range reminders {
+		copied := calendar.EventReminder{
+			EventID:       rest.ID,
+			MinutesBefore: reminder.MinutesBefore,
+			Method:        reminder.Method,
+			WebhookURL:    reminder.WebhookURL,
+			CreatedAt:     updatedAt,
+			UpdatedAt:     updatedAt,
+		}
+		if err := tx.Create(&copied).Error; err != nil {
+			return err
+		}
+	}
+
+	var occurrences []calendar.EventOccurrence
+	if err := tx.Where("event_id = ? AND occurrence_time >= ?", event.ID, now).Find(&occurrences).Error; err != nil {
+		return err
+	}
+	for _, occ := range occurrences {
+		err := tx.Model(&calendar.EventOccurrence{}).Where("id = ?", occ.ID).Updates(map[string]interface{}{
+			"event_id":        rest.ID,
+			"occurrence_time": shift(occ.OccurrenceTime),
+			"updated_at":      updatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, exception := range event.Exceptions {
+		if exception.OriginalTime.Before(now) {
+			continue
+		}
+		updates := map[string]interface{}{
+			"event_id":      rest.ID,
+			"original_time": shift(exception.OriginalTime),
+			"updated_at":    updatedAt,
+		}
+		if exception.OverrideStartTime != nil {
+			updates["override_start_time"] = shift(*exception.OverrideStartTime)
+		}
+		if exception.OverrideEndTime != nil {
+			updates["override_end_time"] = shift(*exception.OverrideEndTime)
+		}
+		if err := tx.Model(&calendar.EventException{}).Where("id = ?", exception.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
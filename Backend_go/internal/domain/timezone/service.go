@@ -0,0 +1,347 @@
+package timezone
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Service helps users change timezone without their schedule drifting. Events,
+// habit reminders and working hours are stored in UTC, so after a move they would
+// land at different local times; the migration shifts them to keep local times.
+type Service interface {
+	// Preview returns what Migrate would change, without writing anything
+	Preview(ctx context.Context, userID uuid.UUID, input MigrationInput) (*Plan, error)
+	// Migrate changes the user's timezone and applies the plan in one transaction
+	Migrate(ctx context.Context, userID uuid.UUID, input MigrationInput) (*Plan, error)
+}
+
+type service struct {
+	repo   Repository
+	redis  *cache.RedisClient
+	logger *zap.Logger
+}
+
+// NewService creates a new timezone migration service
+func NewService(repo Repository, redis *cache.RedisClient, logger *zap.Logger) Service {
+	return &service{
+		repo:   repo,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// planState is a plan together with what is needed to apply it
+type planState struct {
+	plan      *Plan
+	user      *user.User
+	eventIDs  []uuid.UUID
+	reminders map[uuid.UUID]string
+	shift     func(time.Time) time.Time
+	prefs     map[string]interface{}
+	now       time.Time
+}
+
+func (s *service) Preview(ctx context.Context, userID uuid.UUID, input MigrationInput) (*Plan, error) {
+	state, err := s.buildPlan(ctx, userID, input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return state.plan, nil
+}
+
+func (s *service) Migrate(ctx context.Context, userID uuid.UUID, input MigrationInput) (*Plan, error) {
+	state, err := s.buildPlan(ctx, userID, input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	state.user.Timezone = input.Timezone
+	if state.prefs != nil {
+		state.user.Preferences = state.prefs
+	}
+	state.user.UpdatedAt = time.Now()
+
+	err = s.repo.Apply(ctx, &Migration{
+		User:          state.user,
+		EventIDs:      state.eventIDs,
+		ReminderTimes: state.reminders,
+		Shift:         state.shift,
+		Now:           state.now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	state.plan.Applied = true
+
+	tracing.Logger(ctx, s.logger).Info("Migrated user timezone",
+		zap.String("user_id", userID.String()),
+		zap.String("from", state.plan.FromTimezone),
+		zap.String("to", state.plan.ToTimezone),
+		zap.Int("events", len(state.plan.Events)),
+		zap.Int("habits", len(state.plan.Habits)))
+
+	event := &events.DashboardEvent{
+		EventType: events.DashboardEventCacheInvalidate,
+		UserID:    userID,
+		Timestamp: time.Now().UTC(),
+		Details: map[string]interface{}{
+			"action": "timezone_migrated",
+			"from":   state.plan.FromTimezone,
+			"to":     state.plan.ToTimezone,
+		},
+	}
+	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
+	}
+
+	return state.plan, nil
+}
+
+func (s *service) buildPlan(ctx context.Context, userID uuid.UUID, input MigrationInput, now time.Time) (*planState, error) {
+	to, err := user.ParseTimezone(input.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	if !input.ShiftEvents && !input.ShiftHabits && !input.ShiftWorkingHours {
+		return nil, ErrNothingToMigrate
+	}
+
+	u, err := s.repo.FindUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Timezone == input.Timezone {
+		return nil, ErrSameTimezone
+	}
+	// A stored timezone that no longer parses is treated as UTC
+	from, err := user.ParseTimezone(u.Timezone)
+	if err != nil {
+		from = time.UTC
+	}
+
+	state := &planState{
+		plan: &Plan{
+			FromTimezone:  u.Timezone,
+			ToTimezone:    input.Timezone,
+			Events:        []EventShift{},
+			SkippedEvents: []SkippedEvent{},
+			Habits:        []HabitShift{},
+		},
+		user:      u,
+		reminders: make(map[uuid.UUID]string),
+		now:       now,
+		shift: func(t time.Time) time.Time {
+			return keepLocalTime(t, from, to)
+		},
+	}
+
+	if input.ShiftEvents {
+		if err := s.planEvents(ctx, state, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.ShiftHabits {
+		list, err := s.repo.ListHabitsWithReminders(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range list {
+			newTime, _, ok := shiftTimeOfDay(*h.ReminderTime, from, to, now)
+			if !ok || newTime == *h.ReminderTime {
+				continue
+			}
+			state.reminders[h.ID] = newTime
+			state.plan.Habits = append(state.plan.Habits, HabitShift{
+				HabitID:         h.ID,
+				Title:           h.Title,
+				OldReminderTime: *h.ReminderTime,
+				NewReminderTime: newTime,
+			})
+		}
+	}
+
+	if input.ShiftWorkingHours {
+		if change, ok := shiftWorkingHours(u.Preferences, from, to, now); ok {
+			state.plan.WorkingHours = change
+			state.prefs = user.MergePreferences(u.Preferences, map[string]interface{}{
+				user.PreferenceNamespaceWorkingHours: workingHoursPreference(change.New),
+			})
+		}
+	}
+
+	return state, nil
+}
+
+// planEvents adds the user's future events to the plan. All-day events keep their
+// dates and events shared with other users keep their time for everyone else.
+func (s *service) planEvents(ctx context.Context, state *planState, now time.Time) error {
+	list, err := s.repo.ListFutureEvents(ctx, state.user.ID, now)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range list {
+		if event.IsAllDay {
+			state.plan.SkippedEvents = append(state.plan.SkippedEvents, SkippedEvent{
+				EventID: event.ID, Title: event.Title, Reason: SkipReasonAllDay,
+			})
+			continue
+		}
+		if isShared(event) {
+			state.plan.SkippedEvents = append(state.plan.SkippedEvents, SkippedEvent{
+				EventID: event.ID, Title: event.Title, Reason: SkipReasonShared,
+			})
+			continue
+		}
+
+		shift := EventShift{
+			EventID:   event.ID,
+			Title:     event.Title,
+			Recurring: len(event.RecurrenceRules) > 0,
+			OldStart:  event.StartTime,
+			OldEnd:    event.EndTime,
+		}
+		// A series already under way keeps its past occurrences; the rest moves from
+		// its next occurrence on
+		if shift.Recurring && event.StartTime.Before(now) {
+			split, err := calendar.SplitSeries(&event, now, state.shift)
+			if err != nil {
+				return err
+			}
+			if split == nil {
+				continue
+			}
+			shift.Split = true
+			shift.OldStart = split.Start
+			shift.OldEnd = split.Start.Add(event.EndTime.Sub(event.StartTime))
+		}
+		shift.NewStart = state.shift(shift.OldStart)
+		shift.NewEnd = state.shift(shift.OldEnd)
+		if shift.NewStart.Equal(shift.OldStart) && shift.NewEnd.Equal(shift.OldEnd) {
+			continue
+		}
+		if shift.Recurring {
+			occurrences, exceptions, err := s.repo.CountSeries(ctx, event.ID, now)
+			if err != nil {
+				return err
+			}
+			shift.Occurrences = int(occurrences)
+			shift.Exceptions = int(exceptions)
+		}
+
+		state.eventIDs = append(state.eventIDs, event.ID)
+		state.plan.Events = append(state.plan.Events, shift)
+	}
+	return nil
+}
+
+// isShared reports whether anyone other than the owner collaborates on the event
+func isShared(event calendar.CalendarEvent) bool {
+	for _, c := range event.Collaborators {
+		if c.UserID != event.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+// keepLocalTime returns the instant that shows the same wall-clock time in to as t shows in from
+func keepLocalTime(t time.Time, from, to *time.Location) time.Time {
+	local := t.In(from)
+	return time.Date(local.Year(), local.Month(), local.Day(),
+		local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), to).UTC()
+}
+
+// shiftTimeOfDay moves a UTC "HH:MM" time of day so it keeps its local time, using the
+// offsets in effect on the day of now. It also returns how many days the UTC time moved.
+func shiftTimeOfDay(value string, from, to *time.Location, now time.Time) (string, int, bool) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return "", 0, false
+	}
+	day := now.UTC()
+	before := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+	after := keepLocalTime(before, from, to)
+
+	beforeDay := time.Date(before.Year(), before.Month(), before.Day(), 0, 0, 0, 0, time.UTC)
+	afterDay := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, time.UTC)
+	return after.Format("15:04"), int(afterDay.Sub(beforeDay).Hours() / 24), true
+}
+
+// shiftWorkingHours shifts the working_hours preference. Days move with the start time
+// when it crosses midnight UTC.
+func shiftWorkingHours(prefs map[string]interface{}, from, to *time.Location, now time.Time) (*WorkingHoursChange, bool) {
+	raw, ok := prefs[user.PreferenceNamespaceWorkingHours].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	old := WorkingHours{}
+	old.Start, _ = raw["start"].(string)
+	old.End, _ = raw["end"].(string)
+	if days, ok := raw["days"].([]interface{}); ok {
+		for _, d := range days {
+			if day, ok := d.(string); ok {
+				old.Days = append(old.Days, day)
+			}
+		}
+	}
+	if old.Start == "" || old.End == "" {
+		return nil, false
+	}
+
+	start, dayShift, ok := shiftTimeOfDay(old.Start, from, to, now)
+	if !ok {
+		return nil, false
+	}
+	end, _, ok := shiftTimeOfDay(old.End, from, to, now)
+	if !ok {
+		return nil, false
+	}
+
+	next := WorkingHours{Start: start, End: end, Days: rotateWeekdays(old.Days, dayShift)}
+	if next.Start == old.Start && next.End == old.End && dayShift == 0 {
+		return nil, false
+	}
+	return &WorkingHoursChange{Old: old, New: next}, true
+}
+
+// rotateWeekdays moves each weekday by n days
+func rotateWeekdays(days []string, n int) []string {
+	if n == 0 || len(days) == 0 {
+		return days
+	}
+	rotated := make([]string, 0, len(days))
+	for _, d := range days {
+		for i, w := range user.Weekdays {
+			if w == d {
+				rotated = append(rotated, user.Weekdays[((i+n)%7+7)%7])
+				break
+			}
+		}
+	}
+	return rotated
+}
+
+func workingHoursPreference(hours WorkingHours) map[string]interface{} {
+	value := map[string]interface{}{
+		"start": hours.Start,
+		"end":   hours.End,
+	}
+	if hours.Days != nil {
+		days := make([]interface{}, len(hours.Days))
+		for i, d := range hours.Days {
+			days[i] = d
+		}
+		value["days"] = days
+	}
+	return value
+}
@@ -0,0 +1,294 @@
+package todos
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/devices"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GeofenceTrigger is the crossing of a geofence that fires its reminder
+type GeofenceTrigger string
+
+const (
+	GeofenceEnter GeofenceTrigger = "enter"
+	GeofenceExit  GeofenceTrigger = "exit"
+)
+
+const (
+	MinGeofenceRadius = 50.0
+	MaxGeofenceRadius = 10000.0
+	// MaxActiveGeofences matches the regions iOS lets one app monitor at a time
+	MaxActiveGeofences = 20
+	// GeofenceCooldown keeps a client moving back and forth across a fence from repeating
+	// its reminder
+	GeofenceCooldown = time.Hour
+	// maxTransitionAge drops transitions a client queued while offline for too long to
+	// still be useful
+	maxTransitionAge  = 24 * time.Hour
+	earthRadiusMeters = 6371000.0
+)
+
+var (
+	ErrGeofenceNotFound   = NewError("geofence not found")
+	ErrInvalidGeofence    = NewError("geofence needs a valid latitude and longitude, a radius between 50 and 10000 meters and a trigger of enter or exit")
+	ErrLocationNotEnabled = NewError("device is not registered with location enabled")
+)
+
+// Geofence is the location part of a todo's reminder. Clients monitor the area and report
+// when they cross its edge; crossing it in the trigger direction fires the reminder.
+type Geofence struct {
+	ID           uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey"`
+	TodoID       uuid.UUID       `json:"todo_id" gorm:"type:uuid;not null;uniqueIndex"`
+	UserID       uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index"`
+	Latitude     float64         `json:"latitude" gorm:"not null"`
+	Longitude    float64         `json:"longitude" gorm:"not null"`
+	RadiusMeters float64         `json:"radius_meters" gorm:"not null"`
+	Trigger      GeofenceTrigger `json:"trigger" gorm:"type:varchar(10);not null"`
+	// Label names the place, e.g. "Grocery store"
+	Label       string     `json:"label,omitempty" gorm:"size:255"`
+	LastFiredAt *time.Time `json:"last_fired_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Geofence model
+func (Geofence) TableName() string {
+	return "todo_geofences"
+}
+
+// GeofenceInput places a todo's geofence
+type GeofenceInput struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+	Trigger      GeofenceTrigger
+	Label        string
+}
+
+// ActiveGeofence is a geofence of an open todo for a client to monitor
+type ActiveGeofence struct {
+	Geofence  `gorm:"embedded"`
+	TodoTitle string `json:"todo_title"`
+	// DistanceMeters is how far the fence's center is from the client, when it sent its position
+	DistanceMeters *float64 `json:"distance_meters,omitempty" gorm:"-"`
+}
+
+// Position is a point on earth in degrees
+type Position struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// GeofenceTransition is a client's report of crossing a geofence
+type GeofenceTransition struct {
+	DeviceID   string
+	GeofenceID uuid.UUID
+	Transition GeofenceTrigger
+	OccurredAt time.Time
+}
+
+// GeofenceService manages the geofences of todo reminders and fires the reminders when
+// clients report crossing them
+type GeofenceService interface {
+	GetGeofence(ctx context.Context, userID, todoID uuid.UUID) (*Geofence, error)
+	SetGeofence(ctx context.Context, userID, todoID uuid.UUID, input GeofenceInput) (*Geofence, error)
+	DeleteGeofence(ctx context.Context, userID, todoID uuid.UUID) error
+	// ActiveGeofences returns the fences of the user's open todos, at most
+	// MaxActiveGeofences, nearest first when the client sent its position
+	ActiveGeofences(ctx context.Context, userID uuid.UUID, deviceID string, near *Position) ([]ActiveGeofence, error)
+	// ReportTransition fires the reminder of the crossed geofence and reports whether it did
+	ReportTransition(ctx context.Context, userID uuid.UUID, transition GeofenceTransition) (bool, error)
+}
+
+type geofenceService struct {
+	repo    GeofenceRepository
+	todos   TodoRepository
+	devices devices.Service
+	bus     events.Publisher
+	logger  *zap.Logger
+}
+
+// NewGeofenceService creates a new geofence service
+func NewGeofenceService(repo GeofenceRepository, todos TodoRepository, devices devices.Service, bus events.Publisher, logger *zap.Logger) GeofenceService {
+	return &geofenceService{
+		repo:    repo,
+		todos:   todos,
+		devices: devices,
+		bus:     bus,
+		logger:  logger,
+	}
+}
+
+// ownTodo returns the todo when it belongs to the user
+func (s *geofenceService) ownTodo(ctx context.Context, userID, todoID uuid.UUID) (*Todo, error) {
+	todo, err := s.todos.FindByID(ctx, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, ErrTodoNotFound
+	}
+	return todo, nil
+}
+
+// locationDevice returns the user's device when it registered with location enabled
+func (s *geofenceService) locationDevice(ctx context.Context, userID uuid.UUID, deviceID string) (*devices.Device, error) {
+	device, err := s.devices.GetDevice(ctx, userID, deviceID)
+	if errors.Is(err, devices.ErrDeviceNotFound) {
+		return nil, ErrLocationNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !device.LocationEnabled {
+		return nil, ErrLocationNotEnabled
+	}
+	if err := s.devices.Touch(ctx, device); err != nil {
+		tracing.Logger(ctx, s.logger).Warn("Failed to record device activity", zap.String("device_id", deviceID), zap.Error(err))
+	}
+	return device, nil
+}
+
+func (s *geofenceService) GetGeofence(ctx context.Context, userID, todoID uuid.UUID) (*Geofence, error) {
+	if _, err := s.ownTodo(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByTodoID(ctx, todoID)
+}
+
+// SetGeofence adds a geofence to the todo's reminder, or moves the one it has
+func (s *geofenceService) SetGeofence(ctx context.Context, userID, todoID uuid.UUID, input GeofenceInput) (*Geofence, error) {
+	if _, err := s.ownTodo(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+	label := strings.TrimSpace(input.Label)
+	if math.IsNaN(input.Latitude) || math.IsNaN(input.Longitude) ||
+		input.Latitude < -90 || input.Latitude > 90 || input.Longitude < -180 || input.Longitude > 180 ||
+		input.RadiusMeters < MinGeofenceRadius || input.RadiusMeters > MaxGeofenceRadius || len(label) > 255 {
+		return nil, ErrInvalidGeofence
+	}
+	if input.Trigger != GeofenceEnter && input.Trigger != GeofenceExit {
+		return nil, ErrInvalidGeofence
+	}
+
+	now := time.Now()
+	geofence := &Geofence{
+		ID:           uuid.New(),
+		TodoID:       todoID,
+		UserID:       userID,
+		Latitude:     input.Latitude,
+		Longitude:    input.Longitude,
+		RadiusMeters: input.RadiusMeters,
+		Trigger:      input.Trigger,
+		Label:        label,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.Save(ctx, geofence); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByTodoID(ctx, todoID)
+}
+
+func (s *geofenceService) DeleteGeofence(ctx context.Context, userID, todoID uuid.UUID) error {
+	if _, err := s.ownTodo(ctx, userID, todoID); err != nil {
+		return err
+	}
+	return s.repo.DeleteByTodoID(ctx, todoID)
+}
+
+func (s *geofenceService) ActiveGeofences(ctx context.Context, userID uuid.UUID, deviceID string, near *Position) ([]ActiveGeofence, error) {
+	if _, err := s.locationDevice(ctx, userID, deviceID); err != nil {
+		return nil, err
+	}
+	fences, err := s.repo.FindActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if near != nil {
+		for i := range fences {
+			distance := math.Round(distanceMeters(*near, Position{fences[i].Latitude, fences[i].Longitude}))
+			fences[i].DistanceMeters = &distance
+		}
+		sort.SliceStable(fences, func(i, j int) bool { return *fences[i].DistanceMeters < *fences[j].DistanceMeters })
+	}
+	if len(fences) > MaxActiveGeofences {
+		fences = fences[:MaxActiveGeofences]
+	}
+	return fences, nil
+}
+
+// ReportTransition fires the reminder when the crossing matches the fence's trigger, the
+// todo is still open and the fence did not fire within GeofenceCooldown. Other reports
+// are accepted and ignored, since clients cannot tell which crossings matter.
+func (s *geofenceService) ReportTransition(ctx context.Context, userID uuid.UUID, transition GeofenceTransition) (bool, error) {
+	if transition.Transition != GeofenceEnter && transition.Transition != GeofenceExit {
+		return false, ErrInvalidGeofence
+	}
+	if _, err := s.locationDevice(ctx, userID, transition.DeviceID); err != nil {
+		return false, err
+	}
+	geofence, err := s.repo.FindByID(ctx, transition.GeofenceID)
+	if err != nil {
+		return false, err
+	}
+	if geofence.UserID != userID {
+		return false, ErrGeofenceNotFound
+	}
+
+	now := time.Now()
+	occurredAt := transition.OccurredAt
+	if occurredAt.IsZero() || occurredAt.After(now) {
+		occurredAt = now
+	}
+	if geofence.Trigger != transition.Transition || now.Sub(occurredAt) > maxTransitionAge {
+		return false, nil
+	}
+	todo, err := s.todos.FindByID(ctx, geofence.TodoID)
+	if errors.Is(err, ErrTodoNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if todo.IsCompleted || todo.Status == StatusArchived {
+		return false, nil
+	}
+
+	fired, err := s.repo.ClaimFire(ctx, geofence.ID, now, now.Add(-GeofenceCooldown))
+	if err != nil || !fired {
+		return false, err
+	}
+	if s.bus != nil {
+		s.bus.Publish(ctx, events.TodoGeofenceTriggered{
+			TodoID:     todo.ID,
+			GeofenceID: geofence.ID,
+			UserID:     userID,
+			Title:      todo.Title,
+			Label:      geofence.Label,
+			Transition: string(transition.Transition),
+			DeviceID:   transition.DeviceID,
+			OccurredAt: occurredAt,
+		})
+	}
+	return true, nil
+}
+
+// distanceMeters is the great-circle distance between two positions
+func distanceMeters(a, b Position) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
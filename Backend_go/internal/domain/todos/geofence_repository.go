@@ -0,0 +1,99 @@
+package todos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GeofenceRepository defines the interface for todo geofence data access
+type GeofenceRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*Geofence, error)
+	FindByTodoID(ctx context.Context, todoID uuid.UUID) (*Geofence, error)
+	// Save creates the geofence or replaces the one the todo already has
+	Save(ctx context.Context, geofence *Geofence) error
+	DeleteByTodoID(ctx context.Context, todoID uuid.UUID) error
+	// FindActive returns the geofences of the user's open todos, most recently changed first
+	FindActive(ctx context.Context, userID uuid.UUID) ([]ActiveGeofence, error)
+	// ClaimFire records that the geofence fired at now unless it already fired after
+	// notBefore, and reports whether it did
+	ClaimFire(ctx context.Context, id uuid.UUID, now, notBefore time.Time) (bool, error)
+}
+
+type geofenceRepository struct {
+	db *connection.Database
+}
+
+func NewGeofenceRepository(db *connection.Database) GeofenceRepository {
+	return &geofenceRepository{db: db}
+}
+
+func (r *geofenceRepository) FindByID(ctx context.Context, id uuid.UUID) (*Geofence, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+func (r *geofenceRepository) FindByTodoID(ctx context.Context, todoID uuid.UUID) (*Geofence, error) {
+	return r.findOne(ctx, "todo_id = ?", todoID)
+}
+
+func (r *geofenceRepository) findOne(ctx context.Context, query string, args ...interface{}) (*Geofence, error) {
+	var geofence Geofence
+	err := r.db.WithContext(ctx).Where(query, args...).First(&geofence).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrGeofenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &geofence, nil
+}
+
+// Save upserts the geofence on its todo. Moving a fence lets it fire again right away.
+func (r *geofenceRepository) Save(ctx context.Context, geofence *Geofence) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "todo_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"latitude":      geofence.Latitude,
+			"longitude":     geofence.Longitude,
+			"radius_meters": geofence.RadiusMeters,
+			"trigger":       geofence.Trigger,
+			"label":         geofence.Label,
+			"last_fired_at": nil,
+			"updated_at":    geofence.UpdatedAt,
+		}),
+	}).Create(geofence).Error
+}
+
+func (r *geofenceRepository) DeleteByTodoID(ctx context.Context, todoID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&Geofence{}, "todo_id = ?", todoID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrGeofenceNotFound
+	}
+	return nil
+}
+
+func (r *geofenceRepository) FindActive(ctx context.Context, userID uuid.UUID) ([]ActiveGeofence, error) {
+	var fences []ActiveGeofence
+	err := r.db.WithContext(ctx).Table("todo_geofences").
+		Select("todo_geofences.*, todos.title AS todo_title").
+		Joins("JOIN todos ON todos.id = todo_geofences.todo_id").
+		Where("todo_geofences.user_id = ? AND todos.is_completed = ? AND todos.status <> ? AND todos.deleted_at IS NULL", userID, false, StatusArchived).
+		Order("todo_geofences.updated_at DESC").
+		Scan(&fences).Error
+	return fences, err
+}
+
+func (r *geofenceRepository) ClaimFire(ctx context.Context, id uuid.UUID, now, notBefore time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Geofence{}).
+		Where("id = ? AND (last_fired_at IS NULL OR last_fired_at < ?)", id, notBefore).
+		UpdateColumn("last_fired_at", now)
+	return result.RowsAffected > 0, result.Error
+}
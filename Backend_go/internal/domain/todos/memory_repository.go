@@ -0,0 +1,371 @@
+package todos
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryRepository keeps todos and lists in memory, for tests and the demo server.
+// Records are stored by value so callers never share them with the store.
+type memoryRepository struct {
+	mu      sync.RWMutex
+	todos   map[uuid.UUID]Todo
+	deleted map[uuid.UUID]Todo
+	lists   map[uuid.UUID]TodoList
+}
+
+// NewMemoryRepository creates a TodoRepository that keeps everything in memory
+func NewMemoryRepository() TodoRepository {
+	return &memoryRepository{
+		todos:   make(map[uuid.UUID]Todo),
+		deleted: make(map[uuid.UUID]Todo),
+		lists:   make(map[uuid.UUID]TodoList),
+	}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, todo *Todo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.insert(todo)
+	return nil
+}
+
+// insert stores a new todo, filling in what the database would. The caller holds the lock.
+func (r *memoryRepository) insert(todo *Todo) {
+	if todo.ID == uuid.Nil {
+		todo.ID = uuid.New()
+	}
+	now := time.Now()
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = now
+	}
+	todo.UpdatedAt = now
+	if todo.Status == "" {
+		todo.Status = StatusPending
+	}
+	if todo.Priority == "" {
+		todo.Priority = PriorityMedium
+	}
+	r.todos[todo.ID] = *todo
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	todo, ok := r.todos[id]
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	return &todo, nil
+}
+
+func (r *memoryRepository) FindAll(ctx context.Context, filter TodoFilter) ([]Todo, int64, error) {
+	matches := r.find(func(t *Todo) bool {
+		switch {
+		case filter.UserID != nil && t.UserID != *filter.UserID,
+			filter.Status != nil && t.Status != *filter.Status,
+			filter.Priority != nil && t.Priority != *filter.Priority,
+			filter.IsCompleted != nil && t.IsCompleted != *filter.IsCompleted,
+			filter.DueDateStart != nil && (t.DueDate == nil || t.DueDate.Before(*filter.DueDateStart)),
+			filter.DueDateEnd != nil && (t.DueDate == nil || !t.DueDate.Before(*filter.DueDateEnd)),
+			filter.DueDate != nil && (t.DueDate == nil || !t.DueDate.Equal(*filter.DueDate)),
+			filter.ReminderTime != nil && (t.ReminderTime == nil || !t.ReminderTime.Equal(*filter.ReminderTime)),
+			filter.IsRecurring != nil && t.IsRecurring != *filter.IsRecurring,
+			filter.LinkedTaskID != nil && (t.LinkedTaskID == nil || *t.LinkedTaskID != *filter.LinkedTaskID),
+			filter.LinkedCalendarEventID != nil && (t.LinkedCalendarEventID == nil || *t.LinkedCalendarEventID != *filter.LinkedCalendarEventID):
+			return false
+		}
+		return true
+	})
+	if filter.Keyset != nil {
+		return pagination.Slice(filter.Keyset, matches, (*Todo).PageCursor), 0, nil
+	}
+
+	total := int64(len(matches))
+	pageSize := filter.PageSize
+	if pageSize == 0 {
+		pageSize = 10000
+	}
+	start := filter.Page * pageSize
+	if start >= len(matches) {
+		return []Todo{}, total, nil
+	}
+	end := start + pageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end], total, nil
+}
+
+// find returns copies of the todos that match, oldest first
+func (r *memoryRepository) find(match func(*Todo) bool) []Todo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := []Todo{}
+	for _, todo := range r.todos {
+		if match(&todo) {
+			result = append(result, todo)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+func (r *memoryRepository) Update(ctx context.Context, todo *Todo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.todos[todo.ID]; !ok {
+		return ErrTodoNotFound
+	}
+	todo.UpdatedAt = time.Now()
+	r.todos[todo.ID] = *todo
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	todo, ok := r.todos[id]
+	if !ok {
+		return ErrTodoNotFound
+	}
+	todo.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.deleted[id] = todo
+	delete(r.todos, id)
+	return nil
+}
+
+func (r *memoryRepository) FindDeleted(ctx context.Context, userID uuid.UUID, since time.Time) ([]Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	todos := []Todo{}
+	for _, t := range r.deleted {
+		if t.UserID == userID && !t.DeletedAt.Time.Before(since) {
+			todos = append(todos, t)
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool { return todos[i].DeletedAt.Time.After(todos[j].DeletedAt.Time) })
+	return todos, nil
+}
+
+func (r *memoryRepository) FindDeletedByID(ctx context.Context, id uuid.UUID) (*Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	todo, ok := r.deleted[id]
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	return &todo, nil
+}
+
+func (r *memoryRepository) Restore(ctx context.Context, id uuid.UUID, listID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	todo, ok := r.deleted[id]
+	if !ok {
+		return ErrTodoNotFound
+	}
+	todo.DeletedAt = gorm.DeletedAt{}
+	todo.ListID = listID
+	todo.UpdatedAt = time.Now()
+	r.todos[id] = todo
+	delete(r.deleted, id)
+	return nil
+}
+
+func (r *memoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var purged int64
+	for id, t := range r.deleted {
+		if t.DeletedAt.Time.Before(before) {
+			delete(r.deleted, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *memoryRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]Todo, error) {
+	todos := r.find(func(t *Todo) bool { return t.UserID == userID })
+	for i := range todos {
+		if todos[i].RecurrencePattern == nil {
+			todos[i].RecurrencePattern = make(map[string]interface{})
+		}
+		if todos[i].Tags == nil {
+			todos[i].Tags = make(map[string]interface{})
+		}
+		if todos[i].Checklist == nil {
+			todos[i].Checklist = make(map[string]interface{})
+		}
+		if todos[i].AISuggestions == nil {
+			todos[i].AISuggestions = make(map[string]interface{})
+		}
+	}
+	return todos, nil
+}
+
+func (r *memoryRepository) FindByListID(ctx context.Context, listID uuid.UUID) ([]Todo, error) {
+	return r.find(func(t *Todo) bool { return t.ListID == listID }), nil
+}
+
+func (r *memoryRepository) FindByUserIDAndListID(ctx context.Context, userID uuid.UUID, listID uuid.UUID) ([]Todo, error) {
+	return r.find(func(t *Todo) bool { return t.UserID == userID && t.ListID == listID }), nil
+}
+
+func (r *memoryRepository) FindCompletedByUserID(ctx context.Context, userID uuid.UUID) ([]Todo, error) {
+	return r.find(func(t *Todo) bool { return t.UserID == userID && t.IsCompleted }), nil
+}
+
+func (r *memoryRepository) FindUncompletedByUserID(ctx context.Context, userID uuid.UUID) ([]Todo, error) {
+	return r.find(func(t *Todo) bool { return t.UserID == userID && !t.IsCompleted }), nil
+}
+
+func (r *memoryRepository) RecordOccurrence(ctx context.Context, sourceID uuid.UUID, next *Todo) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := false
+	if next != nil {
+		exists := false
+		for _, t := range r.todos {
+			if t.RecurrenceSourceID != nil && *t.RecurrenceSourceID == sourceID {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			next.RecurrenceSourceID = &sourceID
+			r.insert(next)
+			created = true
+		}
+	}
+	if source, ok := r.todos[sourceID]; ok && source.RecurrenceGeneratedAt == nil {
+		now := time.Now()
+		source.RecurrenceGeneratedAt = &now
+		r.todos[sourceID] = source
+	}
+	return created, nil
+}
+
+func (r *memoryRepository) FindPendingRecurrences(ctx context.Context, completedSince time.Time, limit int) ([]Todo, error) {
+	todos := r.find(func(t *Todo) bool {
+		return t.IsRecurring && t.IsCompleted && t.RecurrenceGeneratedAt == nil &&
+			t.CompletionDate != nil && !t.CompletionDate.Before(completedSince)
+	})
+	sort.Slice(todos, func(i, j int) bool { return todos[i].CompletionDate.Before(*todos[j].CompletionDate) })
+	if limit > 0 && len(todos) > limit {
+		todos = todos[:limit]
+	}
+	return todos, nil
+}
+
+func (r *memoryRepository) CreateTodoList(ctx context.Context, list *TodoList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if list.ID == uuid.Nil {
+		list.ID = uuid.New()
+	}
+	now := time.Now()
+	if list.CreatedAt.IsZero() {
+		list.CreatedAt = now
+	}
+	list.UpdatedAt = now
+	stored := *list
+	stored.Todos = nil
+	r.lists[list.ID] = stored
+	return nil
+}
+
+func (r *memoryRepository) GetOrCreateDefaultList(ctx context.Context, userID uuid.UUID) (*TodoList, error) {
+	if list, err := r.FindDefaultListByUserID(ctx, userID); err == nil {
+		return list, nil
+	}
+	list := &TodoList{
+		UserID:      userID,
+		Name:        "Default List",
+		Description: "Default todo list",
+		IsDefault:   true,
+	}
+	if err := r.CreateTodoList(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// FindDefaultListByUserID returns gorm.ErrRecordNotFound when the user has no default
+// list, as the database repository does
+func (r *memoryRepository) FindDefaultListByUserID(ctx context.Context, userID uuid.UUID) (*TodoList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, list := range r.lists {
+		if list.UserID == userID && list.IsDefault {
+			return &list, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryRepository) UpdateTodoList(ctx context.Context, list *TodoList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.lists[list.ID]; !ok {
+		return ErrTodoNotFound
+	}
+	list.UpdatedAt = time.Now()
+	stored := *list
+	stored.Todos = nil
+	r.lists[list.ID] = stored
+	return nil
+}
+
+func (r *memoryRepository) DeleteTodoList(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for todoID, t := range r.todos {
+		if t.ListID == id {
+			delete(r.todos, todoID)
+		}
+	}
+	for todoID, t := range r.deleted {
+		if t.ListID == id {
+			delete(r.deleted, todoID)
+		}
+	}
+	delete(r.lists, id)
+	return nil
+}
+
+func (r *memoryRepository) FindTodoListByID(ctx context.Context, id uuid.UUID) (*TodoList, error) {
+	r.mu.RLock()
+	list, ok := r.lists[id]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	list.Todos, _ = r.FindByListID(ctx, id)
+	return &list, nil
+}
+
+func (r *memoryRepository) FindAllTodoLists(ctx context.Context, userID uuid.UUID) ([]TodoList, error) {
+	r.mu.RLock()
+	lists := []TodoList{}
+	for _, list := range r.lists {
+		if list.UserID == userID {
+			lists = append(lists, list)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(lists, func(i, j int) bool { return lists[i].CreatedAt.Before(lists[j].CreatedAt) })
+	for i := range lists {
+		lists[i].Todos, _ = r.FindByListID(ctx, lists[i].ID)
+	}
+	return lists, nil
+}
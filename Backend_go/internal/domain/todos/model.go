@@ -3,6 +3,7 @@ package todos
 import (
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -56,10 +57,13 @@ type Todo struct {
 	Checklist             map[string]interface{} `gorm:"type:jsonb;default:'{}';serializer:json"`
 	LinkedTaskID          *uuid.UUID             `gorm:"type:uuid"`
 	LinkedCalendarEventID *uuid.UUID             `gorm:"type:uuid"`
+	RecurrenceSourceID    *uuid.UUID             `gorm:"type:uuid;uniqueIndex:idx_todo_recurrence_source"` // Occurrence this todo was generated from
+	RecurrenceGeneratedAt *time.Time             // When the next occurrence was generated, or the recurrence found to have ended
 	AIGenerated           bool                   `gorm:"default:false;not null"`
 	AISuggestions         map[string]interface{} `gorm:"type:jsonb;default:'{}';serializer:json"`
 	CreatedAt             time.Time              `gorm:"not null;default:current_timestamp;index"`
 	UpdatedAt             time.Time              `gorm:"not null;default:current_timestamp;autoUpdateTime"`
+	DeletedAt             gorm.DeletedAt         `gorm:"index"`             // Set while the todo is in the trash
 	List                  TodoList               `gorm:"foreignKey:ListID"` // Relationship to TodoList
 }
 
@@ -116,9 +120,10 @@ type TodoListsResponse struct {
 
 // Common errors
 var (
-	ErrInvalidStatus   = NewError("invalid todo status")
-	ErrInvalidPriority = NewError("invalid todo priority")
-	ErrInvalidInput    = NewError("invalid input")
+	ErrInvalidStatus     = NewError("invalid todo status")
+	ErrInvalidPriority   = NewError("invalid todo priority")
+	ErrInvalidInput      = NewError("invalid input")
+	ErrInvalidRecurrence = NewError("invalid recurrence pattern")
 )
 
 // Error represents a domain error
@@ -229,6 +234,11 @@ func (t *Todo) BeforeUpdate(tx *gorm.DB) error {
 	return t.Validate()
 }
 
+// PageCursor is the position of the todo in keyset pages, which follow creation time
+func (t *Todo) PageCursor() pagination.Cursor {
+	return pagination.Cursor{Time: t.CreatedAt, ID: t.ID}
+}
+
 type TodoFilter struct {
 	UserID                *uuid.UUID
 	Status                *TodoStatus
@@ -245,4 +255,6 @@ type TodoFilter struct {
 	LinkedCalendarEventID *uuid.UUID
 	Page                  int
 	PageSize              int
+	// Keyset pages by creation time instead of Page and PageSize, without counting the total
+	Keyset *pagination.Keyset
 }
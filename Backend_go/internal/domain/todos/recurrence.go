@@ -0,0 +1,162 @@
+package todos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecurrenceFreq is the unit a recurring todo repeats in
+type RecurrenceFreq string
+
+const (
+	FreqDaily   RecurrenceFreq = "daily"
+	FreqWeekly  RecurrenceFreq = "weekly"
+	FreqMonthly RecurrenceFreq = "monthly"
+	FreqYearly  RecurrenceFreq = "yearly"
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is the parsed recurrence pattern of a todo, e.g.
+//
+//	{"freq": "weekly", "interval": 2, "byday": ["MO", "TH"], "until": "2025-12-31"}
+type Recurrence struct {
+	Freq     RecurrenceFreq
+	Interval int
+	// ByDay restricts weekly recurrences to these days; weeks start on Monday
+	ByDay []time.Weekday
+	// Until is the last moment an occurrence may be due
+	Until *time.Time
+}
+
+// ParseRecurrence reads a recurrence pattern, returning ErrInvalidRecurrence
+// wrapped with the reason when it cannot be followed
+func ParseRecurrence(pattern map[string]interface{}) (*Recurrence, error) {
+	r := &Recurrence{Interval: 1}
+
+	freq, _ := pattern["freq"].(string)
+	if freq == "" {
+		freq, _ = pattern["frequency"].(string)
+	}
+	r.Freq = RecurrenceFreq(strings.ToLower(freq))
+	switch r.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+	default:
+		return nil, fmt.Errorf("%w: freq must be daily, weekly, monthly or yearly", ErrInvalidRecurrence)
+	}
+
+	if v, ok := pattern["interval"]; ok && v != nil {
+		interval, ok := v.(float64)
+		if !ok || interval < 1 || interval != float64(int(interval)) {
+			return nil, fmt.Errorf("%w: interval must be a positive whole number", ErrInvalidRecurrence)
+		}
+		r.Interval = int(interval)
+	}
+
+	if v, ok := pattern["byday"]; ok && v != nil {
+		days, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: byday must be a list of days", ErrInvalidRecurrence)
+		}
+		if len(days) > 0 && r.Freq != FreqWeekly {
+			return nil, fmt.Errorf("%w: byday is only supported for weekly recurrences", ErrInvalidRecurrence)
+		}
+		for _, d := range days {
+			code, _ := d.(string)
+			day, ok := weekdayCodes[strings.ToUpper(code)]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown day %v, use MO, TU, WE, TH, FR, SA or SU", ErrInvalidRecurrence, d)
+			}
+			r.ByDay = append(r.ByDay, day)
+		}
+	}
+
+	if v, ok := pattern["until"]; ok && v != nil {
+		s, _ := v.(string)
+		until, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			// A date alone includes the whole day
+			date, dateErr := time.Parse("2006-01-02", s)
+			if dateErr != nil {
+				return nil, fmt.Errorf("%w: until must be a date or RFC 3339 time", ErrInvalidRecurrence)
+			}
+			until = date.Add(24*time.Hour - time.Nanosecond)
+		}
+		r.Until = &until
+	}
+
+	return r, nil
+}
+
+// Next returns the occurrence that follows one due at the given time, and false
+// once the recurrence has ended
+func (r *Recurrence) Next(after time.Time) (time.Time, bool) {
+	var next time.Time
+	switch r.Freq {
+	case FreqDaily:
+		next = after.AddDate(0, 0, r.Interval)
+	case FreqWeekly:
+		next = r.nextWeekly(after)
+	case FreqMonthly:
+		next = addMonths(after, r.Interval)
+	case FreqYearly:
+		next = addMonths(after, 12*r.Interval)
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// nextWeekly picks the next listed day later in the same week, or the first listed
+// day of the week that is Interval weeks on
+func (r *Recurrence) nextWeekly(after time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return after.AddDate(0, 0, 7*r.Interval)
+	}
+
+	offset := weekOffset(after.Weekday())
+	first := 7
+	later := 7
+	for _, day := range r.ByDay {
+		o := weekOffset(day)
+		if o < first {
+			first = o
+		}
+		if o > offset && o < later {
+			later = o
+		}
+	}
+	if later < 7 {
+		return after.AddDate(0, 0, later-offset)
+	}
+	return after.AddDate(0, 0, 7*r.Interval-offset+first)
+}
+
+// weekOffset counts days from Monday
+func weekOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// addMonths moves a time by whole months, keeping the day of the month where that month has it
+// and using the month's last day otherwise, so that the 31st never spills into the month after
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
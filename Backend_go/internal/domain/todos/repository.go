@@ -8,6 +8,7 @@ import (
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -19,12 +20,26 @@ type TodoRepository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*Todo, error)
 	FindAll(ctx context.Context, filter TodoFilter) ([]Todo, int64, error)
 	Update(ctx context.Context, todo *Todo) error
+	// Delete moves the todo to the trash
 	Delete(ctx context.Context, id uuid.UUID) error
+	// FindDeleted returns the user's todos in the trash, deleted since a time
+	FindDeleted(ctx context.Context, userID uuid.UUID, since time.Time) ([]Todo, error)
+	// FindDeletedByID returns a todo in the trash
+	FindDeletedByID(ctx context.Context, id uuid.UUID) (*Todo, error)
+	// Restore takes a todo out of the trash into a list
+	Restore(ctx context.Context, id uuid.UUID, listID uuid.UUID) error
+	// PurgeDeleted permanently removes todos deleted before a time
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 	FindByUserID(ctx context.Context, userID uuid.UUID) ([]Todo, error)
 	FindByListID(ctx context.Context, listID uuid.UUID) ([]Todo, error)
 	FindByUserIDAndListID(ctx context.Context, userID uuid.UUID, listID uuid.UUID) ([]Todo, error)
 	FindCompletedByUserID(ctx context.Context, userID uuid.UUID) ([]Todo, error)
 	FindUncompletedByUserID(ctx context.Context, userID uuid.UUID) ([]Todo, error)
+	// RecordOccurrence creates the occurrence following a completed recurring todo and marks the
+	// source as handled. A nil next only marks it. It returns false if the source already had one.
+	RecordOccurrence(ctx context.Context, sourceID uuid.UUID, next *Todo) (bool, error)
+	// FindPendingRecurrences returns recurring todos completed since a time whose next occurrence was never generated
+	FindPendingRecurrences(ctx context.Context, completedSince time.Time, limit int) ([]Todo, error)
 	CreateTodoList(ctx context.Context, list *TodoList) error
 	GetOrCreateDefaultList(ctx context.Context, userID uuid.UUID) (*TodoList, error)
 	FindDefaultListByUserID(ctx context.Context, userID uuid.UUID) (*TodoList, error)
@@ -105,6 +120,11 @@ func (r *todoRepository) FindAll(ctx context.Context, filter TodoFilter) ([]Todo
 		query = query.Where("linked_calendar_event_id = ?", filter.LinkedCalendarEventID)
 	}
 
+	if filter.Keyset != nil {
+		err := query.Scopes(filter.Keyset.Scope("created_at", "id")).Find(&todos).Error
+		return todos, 0, err
+	}
+
 	// Count total before pagination
 	err := query.Model(&Todo{}).Count(&total).Error
 	if err != nil {
@@ -149,6 +169,49 @@ func (r *todoRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (r *todoRepository) FindDeleted(ctx context.Context, userID uuid.UUID, since time.Time) ([]Todo, error) {
+	var todos []Todo
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("user_id = ? AND deleted_at >= ?", userID, since).
+		Order("deleted_at DESC").
+		Find(&todos).Error
+	return todos, err
+}
+
+func (r *todoRepository) FindDeletedByID(ctx context.Context, id uuid.UUID) (*Todo, error) {
+	var todo Todo
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&todo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTodoNotFound
+		}
+		return nil, err
+	}
+	return &todo, nil
+}
+
+func (r *todoRepository) Restore(ctx context.Context, id uuid.UUID, listID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&Todo{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"list_id":    listID,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTodoNotFound
+	}
+	return nil
+}
+
+func (r *todoRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", before).Delete(&Todo{})
+	return result.RowsAffected, result.Error
+}
+
 func (r *todoRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]Todo, error) {
 	var todos []Todo
 	result := r.db.WithContext(ctx).
@@ -215,6 +278,37 @@ func (r *todoRepository) FindUncompletedByUserID(ctx context.Context, userID uui
 	return todos, nil
 }
 
+func (r *todoRepository) RecordOccurrence(ctx context.Context, sourceID uuid.UUID, next *Todo) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if next != nil {
+			next.RecurrenceSourceID = &sourceID
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "recurrence_source_id"}},
+				DoNothing: true,
+			}).Create(next)
+			if result.Error != nil {
+				return result.Error
+			}
+			created = result.RowsAffected > 0
+		}
+		return tx.Model(&Todo{}).
+			Where("id = ? AND recurrence_generated_at IS NULL", sourceID).
+			UpdateColumn("recurrence_generated_at", time.Now()).Error
+	})
+	return created, err
+}
+
+func (r *todoRepository) FindPendingRecurrences(ctx context.Context, completedSince time.Time, limit int) ([]Todo, error) {
+	var todos []Todo
+	err := r.db.WithContext(ctx).
+		Where("is_recurring = ? AND is_completed = ? AND recurrence_generated_at IS NULL AND completion_date >= ?", true, true, completedSince).
+		Order("completion_date").
+		Limit(limit).
+		Find(&todos).Error
+	return todos, err
+}
+
 func (r *todoRepository) CreateTodoList(ctx context.Context, list *TodoList) error {
 	return r.db.WithContext(ctx).Create(list).Error
 }
@@ -279,8 +373,9 @@ func (r *todoRepository) DeleteTodoList(ctx context.Context, id uuid.UUID) error
 		return tx.Error
 	}
 
-	// Delete all todos associated with this list first
-	if err := tx.Where("list_id = ?", id).Delete(&Todo{}).Error; err != nil {
+	// Delete all todos associated with this list first, including those in the trash,
+	// since they cannot outlive the list
+	if err := tx.Unscoped().Where("list_id = ?", id).Delete(&Todo{}).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
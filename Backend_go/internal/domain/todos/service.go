@@ -2,10 +2,13 @@ package todos
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -31,6 +34,14 @@ type Service interface {
 	GetAllTodoLists(ctx context.Context, userID uuid.UUID) ([]TodoList, error)
 	GetDashboardMetrics(userID uuid.UUID) (TodosDashboardMetrics, error)
 	GetTodayTodos(ctx context.Context, userID uuid.UUID) ([]Todo, error)
+	// GenerateMissedOccurrences creates the next occurrence of recently completed recurring
+	// todos that did not get one, e.g. because generation failed at completion time
+	GenerateMissedOccurrences(ctx context.Context) (int, error)
+
+	// Trash methods
+	ListDeletedTodos(ctx context.Context, userID uuid.UUID, since time.Time) ([]Todo, error)
+	RestoreTodo(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Todo, error)
+	PurgeDeletedTodos(ctx context.Context, before time.Time) (int64, error)
 }
 
 type CreateTodoInput struct {
@@ -90,13 +101,29 @@ type TodosDashboardMetrics struct {
 }
 
 type service struct {
-	repo   TodoRepository
-	redis  *cache.RedisClient
-	logger *zap.Logger
+	repo     TodoRepository
+	redis    *cache.RedisClient
+	webhooks webhooks.Publisher
+	bus      events.Publisher // Tells other domains about created and completed todos
+	changes  cache.ChangeNotifier
+	logger   *zap.Logger
 }
 
-func NewService(repo TodoRepository, redis *cache.RedisClient, logger *zap.Logger) Service {
-	return &service{repo: repo, redis: redis, logger: logger}
+const (
+	// recurrenceBackfillWindow is how far back completions are checked for missed occurrences
+	recurrenceBackfillWindow = 30 * 24 * time.Hour
+	recurrenceBackfillBatch  = 100
+)
+
+func NewService(repo TodoRepository, redis *cache.RedisClient, webhookPublisher webhooks.Publisher, bus events.Publisher, changes cache.ChangeNotifier, logger *zap.Logger) Service {
+	return &service{repo: repo, redis: redis, webhooks: webhookPublisher, bus: bus, changes: changes, logger: logger}
+}
+
+// todosChanged drops cached todo and todo list responses after a write
+func (s *service) todosChanged(ctx context.Context) {
+	if s.changes != nil {
+		s.changes.EntityChanged(ctx, cache.EntityTodos, cache.EntityTodoLists)
+	}
 }
 
 func (s *service) CreateTodo(ctx context.Context, input CreateTodoInput) (*Todo, error) {
@@ -112,6 +139,12 @@ func (s *service) CreateTodo(ctx context.Context, input CreateTodoInput) (*Todo,
 		input.Priority = PriorityMedium
 	}
 
+	if input.IsRecurring {
+		if _, err := ParseRecurrence(input.RecurrencePattern); err != nil {
+			return nil, err
+		}
+	}
+
 	todo := &Todo{
 		ID:                    uuid.New(),
 		Title:                 input.Title,
@@ -136,6 +169,7 @@ func (s *service) CreateTodo(ctx context.Context, input CreateTodoInput) (*Todo,
 	if err != nil {
 		return nil, err
 	}
+	s.todosChanged(ctx)
 
 	// Publish dashboard event
 	event := &events.DashboardEvent{
@@ -148,9 +182,12 @@ func (s *service) CreateTodo(ctx context.Context, input CreateTodoInput) (*Todo,
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
+	s.publishWebhook(ctx, webhooks.EventTodoCreated, todo)
+	s.publishEvent(ctx, events.TodoCreated{TodoID: todo.ID, UserID: todo.UserID, CreatedAt: todo.CreatedAt})
+
 	return todo, nil
 }
 
@@ -226,10 +263,17 @@ func (s *service) UpdateTodo(ctx context.Context, id uuid.UUID, input UpdateTodo
 		todo.LinkedCalendarEventID = input.LinkedCalendarEventID
 	}
 
+	if todo.IsRecurring {
+		if _, err := ParseRecurrence(todo.RecurrencePattern); err != nil {
+			return nil, err
+		}
+	}
+
 	err = s.repo.Update(ctx, todo)
 	if err != nil {
 		return nil, err
 	}
+	s.todosChanged(ctx)
 
 	return todo, nil
 }
@@ -242,7 +286,11 @@ func (s *service) DeleteTodo(ctx context.Context, id uuid.UUID) error {
 	if todo == nil {
 		return ErrTodoNotFound
 	}
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.todosChanged(ctx)
+	return nil
 }
 
 func (s *service) FindByUserID(ctx context.Context, userID uuid.UUID) ([]Todo, error) {
@@ -271,6 +319,7 @@ func (s *service) UpdateTodoStatus(ctx context.Context, id uuid.UUID, status Tod
 	if err != nil {
 		return nil, err
 	}
+	s.todosChanged(ctx)
 
 	return todo, nil
 }
@@ -289,6 +338,7 @@ func (s *service) UpdateTodoPriority(ctx context.Context, id uuid.UUID, priority
 	if err != nil {
 		return nil, err
 	}
+	s.todosChanged(ctx)
 
 	return todo, nil
 }
@@ -302,6 +352,7 @@ func (s *service) CompleteTodo(ctx context.Context, id uuid.UUID) (*Todo, error)
 		return nil, ErrTodoNotFound
 	}
 
+	wasCompleted := todo.IsCompleted
 	todo.IsCompleted = true
 	now := time.Now()
 	todo.CompletionDate = &now
@@ -310,6 +361,7 @@ func (s *service) CompleteTodo(ctx context.Context, id uuid.UUID) (*Todo, error)
 	if err != nil {
 		return nil, err
 	}
+	s.todosChanged(ctx)
 
 	// Publish dashboard event
 	event := &events.DashboardEvent{
@@ -322,12 +374,27 @@ func (s *service) CompleteTodo(ctx context.Context, id uuid.UUID) (*Todo, error)
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
 	// Invalidate dashboard cache for this user
 	s.recordTodoActivity(ctx, todo, todo.UserID, "todo_completed", nil)
 
+	s.publishWebhook(ctx, webhooks.EventTodoCompleted, todo)
+	if !wasCompleted {
+		s.publishEvent(ctx, events.TodoCompleted{TodoID: todo.ID, UserID: todo.UserID, CompletedAt: now})
+	}
+
+	if todo.IsRecurring {
+		// The completion stands even if the next occurrence cannot be created now;
+		// GenerateMissedOccurrences picks it up later
+		if _, err := s.generateNextOccurrence(ctx, todo); err != nil {
+			tracing.Logger(ctx, s.logger).Error("Failed to generate next todo occurrence",
+				zap.String("todo_id", todo.ID.String()),
+				zap.Error(err))
+		}
+	}
+
 	return todo, nil
 }
 
@@ -347,6 +414,7 @@ func (s *service) UncompleteTodo(ctx context.Context, id uuid.UUID) (*Todo, erro
 	if err != nil {
 		return nil, err
 	}
+	s.todosChanged(ctx)
 
 	// Publish dashboard event
 	event := &events.DashboardEvent{
@@ -359,7 +427,7 @@ func (s *service) UncompleteTodo(ctx context.Context, id uuid.UUID) (*Todo, erro
 		},
 	}
 	if err := s.redis.PublishDashboardEvent(ctx, event); err != nil {
-		s.logger.Error("Failed to publish dashboard event", zap.Error(err))
+		tracing.Logger(ctx, s.logger).Error("Failed to publish dashboard event", zap.Error(err))
 	}
 
 	// Invalidate dashboard cache for this user
@@ -368,6 +436,135 @@ func (s *service) UncompleteTodo(ctx context.Context, id uuid.UUID) (*Todo, erro
 	return todo, nil
 }
 
+func (s *service) GenerateMissedOccurrences(ctx context.Context) (int, error) {
+	since := time.Now().Add(-recurrenceBackfillWindow)
+	generated := 0
+	var errs []error
+	for {
+		pending, err := s.repo.FindPendingRecurrences(ctx, since, recurrenceBackfillBatch)
+		if err != nil {
+			return generated, err
+		}
+
+		failed := 0
+		for i := range pending {
+			next, err := s.generateNextOccurrence(ctx, &pending[i])
+			if err != nil {
+				failed++
+				errs = append(errs, err)
+				continue
+			}
+			if next != nil {
+				generated++
+			}
+		}
+
+		// Failed todos stay pending, so stop rather than fetch them again
+		if len(pending) < recurrenceBackfillBatch || failed > 0 {
+			break
+		}
+	}
+	return generated, errors.Join(errs...)
+}
+
+// generateNextOccurrence creates the occurrence following a completed recurring todo.
+// It returns nil when the recurrence has ended or the occurrence already exists.
+func (s *service) generateNextOccurrence(ctx context.Context, todo *Todo) (*Todo, error) {
+	rule, err := ParseRecurrence(todo.RecurrencePattern)
+	if err != nil {
+		// Patterns saved before they were validated can never produce an occurrence
+		tracing.Logger(ctx, s.logger).Warn("Recurring todo has an invalid pattern",
+			zap.String("todo_id", todo.ID.String()),
+			zap.Error(err))
+		_, err = s.repo.RecordOccurrence(ctx, todo.ID, nil)
+		return nil, err
+	}
+
+	next := nextOccurrence(todo, rule, time.Now())
+	created, err := s.repo.RecordOccurrence(ctx, todo.ID, next)
+	if err != nil || !created {
+		return nil, err
+	}
+	s.todosChanged(ctx)
+
+	s.recordTodoActivity(ctx, next, next.UserID, "todo_created", map[string]interface{}{
+		"recurrence_source_id": todo.ID,
+	})
+	s.publishWebhook(ctx, webhooks.EventTodoCreated, next)
+	s.publishEvent(ctx, events.TodoCreated{TodoID: next.ID, UserID: next.UserID, CreatedAt: time.Now()})
+	return next, nil
+}
+
+// nextOccurrence builds the todo following one in a recurrence. Occurrences that would
+// already be overdue are skipped, so completing late does not create a backlog.
+func nextOccurrence(todo *Todo, rule *Recurrence, now time.Time) *Todo {
+	anchor := now
+	if todo.DueDate != nil {
+		anchor = *todo.DueDate
+	} else if todo.CompletionDate != nil {
+		anchor = *todo.CompletionDate
+	}
+
+	due, ok := rule.Next(anchor)
+	for ok && due.Before(now) {
+		due, ok = rule.Next(due)
+	}
+	if !ok {
+		return nil
+	}
+
+	next := &Todo{
+		ID:                uuid.New(),
+		UserID:            todo.UserID,
+		ListID:            todo.ListID,
+		Title:             todo.Title,
+		Description:       todo.Description,
+		Status:            StatusPending,
+		Priority:          todo.Priority,
+		DueDate:           &due,
+		IsRecurring:       true,
+		RecurrencePattern: todo.RecurrencePattern,
+		Tags:              todo.Tags,
+		Checklist:         uncheckedChecklist(todo.Checklist),
+	}
+	if todo.ReminderTime != nil && todo.DueDate != nil {
+		reminder := todo.ReminderTime.Add(due.Sub(*todo.DueDate))
+		next.ReminderTime = &reminder
+	}
+	return next
+}
+
+// uncheckedChecklist copies a checklist with every item unchecked
+func uncheckedChecklist(checklist map[string]interface{}) map[string]interface{} {
+	if checklist == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(checklist))
+	for k, v := range checklist {
+		copied[k] = v
+	}
+	items, ok := checklist["items"].([]interface{})
+	if !ok {
+		return copied
+	}
+	unchecked := make([]interface{}, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			unchecked[i] = item
+			continue
+		}
+		reset := make(map[string]interface{}, len(fields))
+		for k, v := range fields {
+			reset[k] = v
+		}
+		reset["completed"] = false
+		unchecked[i] = reset
+	}
+	copied["items"] = unchecked
+	return copied
+}
+
 // Helper to record todo activity and trigger dashboard cache invalidation
 func (s *service) recordTodoActivity(ctx context.Context, todo *Todo, userID uuid.UUID, action string, metadata map[string]interface{}) {
 	if metadata == nil {
@@ -386,6 +583,25 @@ func (s *service) recordTodoActivity(ctx context.Context, todo *Todo, userID uui
 	}
 }
 
+// publishWebhook sends a todo event to the owner's personal webhooks
+func (s *service) publishWebhook(ctx context.Context, eventType webhooks.EventType, todo *Todo) {
+	if s.webhooks == nil {
+		return
+	}
+	s.webhooks.Publish(ctx, webhooks.Event{
+		Type:   eventType,
+		UserID: todo.UserID,
+		Data:   map[string]interface{}{"todo": todo},
+	})
+}
+
+// publishEvent hands a domain event to the bus, if the service has one
+func (s *service) publishEvent(ctx context.Context, event events.Event) {
+	if s.bus != nil {
+		s.bus.Publish(ctx, event)
+	}
+}
+
 func (s *service) CreateTodoList(ctx context.Context, list *TodoList) error {
 	if list.Name == "" {
 		return ErrInvalidInput
@@ -394,7 +610,11 @@ func (s *service) CreateTodoList(ctx context.Context, list *TodoList) error {
 		return ErrInvalidInput
 	}
 
-	return s.repo.CreateTodoList(ctx, list)
+	if err := s.repo.CreateTodoList(ctx, list); err != nil {
+		return err
+	}
+	s.todosChanged(ctx)
+	return nil
 }
 
 func (s *service) GetOrCreateDefaultList(ctx context.Context, userID uuid.UUID) (*TodoList, error) {
@@ -421,12 +641,17 @@ func (s *service) UpdateTodoList(ctx context.Context, id uuid.UUID, input Update
 	if err != nil {
 		return nil, err
 	}
+	s.todosChanged(ctx)
 
 	return list, nil
 }
 
 func (s *service) DeleteTodoList(ctx context.Context, id uuid.UUID) error {
-	return s.repo.DeleteTodoList(ctx, id)
+	if err := s.repo.DeleteTodoList(ctx, id); err != nil {
+		return err
+	}
+	s.todosChanged(ctx)
+	return nil
 }
 
 func (s *service) GetTodoList(ctx context.Context, id uuid.UUID) (*TodoList, error) {
@@ -482,13 +707,13 @@ func (s *service) GetTodayTodos(ctx context.Context, userID uuid.UUID) ([]Todo,
 			nilDueDateCount++
 		}
 	}
-	s.logger.Info("GetTodayTodos results",
+	tracing.Logger(ctx, s.logger).Info("GetTodayTodos results",
 		zap.String("user_id", userID.String()),
 		zap.Int("total_found", len(todos)),
 		zap.Int("nil_due_date_count", nilDueDateCount))
 
 	// Return all todos, even those with nil DueDate
-	s.logger.Info("GetTodayTodos returning all active todos",
+	tracing.Logger(ctx, s.logger).Info("GetTodayTodos returning all active todos",
 		zap.Int("active_count", len(todos)))
 
 	return todos, nil
@@ -0,0 +1,32 @@
+package todos
+
+import (
+	"context"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+)
+
+// SubscribeGeofenceNotifications sends the reminder of a todo, in the app and as a push,
+// when its geofence fires
+func SubscribeGeofenceNotifications(bus *events.Bus, notifier notification.DomainNotifier) {
+	events.Subscribe(bus, "todo_geofence_notifications", events.Async, func(ctx context.Context, event events.TodoGeofenceTriggered) error {
+		content := "You are near the place for: " + event.Title
+		if event.Transition == string(GeofenceExit) {
+			content = "You are leaving the place for: " + event.Title
+		}
+		if event.Label != "" {
+			content += " (" + event.Label + ")"
+		}
+		data := map[string]string{
+			"todoId":     event.TodoID.String(),
+			"todoTitle":  event.Title,
+			"geofenceId": event.GeofenceID.String(),
+			"transition": event.Transition,
+			"label":      event.Label,
+		}
+		return notifier.NotifyUserWithDelivery(ctx, event.UserID, notification.Reminder,
+			"Reminder: "+event.Title, content, data, "todo", event.TodoID,
+			[]notification.DeliveryMethod{notification.InApp, notification.Push})
+	})
+}
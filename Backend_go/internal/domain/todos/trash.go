@@ -0,0 +1,48 @@
+package todos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListDeletedTodos returns the user's todos that went to the trash since a time
+func (s *service) ListDeletedTodos(ctx context.Context, userID uuid.UUID, since time.Time) ([]Todo, error) {
+	return s.repo.FindDeleted(ctx, userID, since)
+}
+
+// RestoreTodo takes one of the user's todos out of the trash, back into its list or,
+// if that list was deleted meanwhile, into the user's default list
+func (s *service) RestoreTodo(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Todo, error) {
+	todo, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, ErrTodoNotFound
+	}
+
+	listID := todo.ListID
+	if _, err := s.repo.FindTodoListByID(ctx, listID); err != nil {
+		if !errors.Is(err, ErrTodoNotFound) {
+			return nil, err
+		}
+		list, err := s.repo.GetOrCreateDefaultList(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		listID = list.ID
+	}
+	if err := s.repo.Restore(ctx, id, listID); err != nil {
+		return nil, err
+	}
+	s.todosChanged(ctx)
+	return s.repo.FindByID(ctx, id)
+}
+
+// PurgeDeletedTodos permanently removes todos that went to the trash before a time
+func (s *service) PurgeDeletedTodos(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, before)
+}
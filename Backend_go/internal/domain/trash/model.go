@@ -0,0 +1,34 @@
+package trash
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ItemType is the kind of entity an item in the trash is
+type ItemType string
+
+const (
+	ItemTask ItemType = "task"
+	ItemTodo ItemType = "todo"
+)
+
+// DefaultRetention is how long deleted items stay in the trash before they are purged
+const DefaultRetention = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidItemType = errors.New("item type must be task or todo")
+	ErrItemNotFound    = errors.New("item not found in trash")
+)
+
+// Item is a deleted task or todo that can still be restored
+type Item struct {
+	Type      ItemType  `json:"type"`
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// ExpiresAt is when the purge job removes the item for good
+	ExpiresAt time.Time `json:"expires_at"`
+}
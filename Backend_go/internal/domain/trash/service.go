@@ -0,0 +1,91 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/google/uuid"
+)
+
+// Service defines the interface for trash operations
+type Service interface {
+	// List returns the items the user can still restore, most recently deleted first
+	List(ctx context.Context, userID uuid.UUID) ([]Item, error)
+	// Restore takes an item out of the trash and returns the restored task or todo
+	Restore(ctx context.Context, userID uuid.UUID, itemType ItemType, id uuid.UUID) (interface{}, error)
+}
+
+type service struct {
+	taskService task.Service
+	todoService todos.Service
+	retention   time.Duration
+}
+
+// NewService creates a new trash service. Items are listed until they are older than
+// retention, or DefaultRetention when it is not positive, which should match the purge job.
+func NewService(taskService task.Service, todoService todos.Service, retention time.Duration) Service {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &service{
+		taskService: taskService,
+		todoService: todoService,
+		retention:   retention,
+	}
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]Item, error) {
+	since := time.Now().Add(-s.retention)
+
+	deletedTasks, err := s.taskService.ListDeletedTasks(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	deletedTodos, err := s.todoService.ListDeletedTodos(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(deletedTasks)+len(deletedTodos))
+	for _, t := range deletedTasks {
+		items = append(items, s.item(ItemTask, t.ID, t.Title, t.DeletedAt.Time))
+	}
+	for _, t := range deletedTodos {
+		items = append(items, s.item(ItemTodo, t.ID, t.Title, t.DeletedAt.Time))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+func (s *service) item(itemType ItemType, id uuid.UUID, title string, deletedAt time.Time) Item {
+	return Item{
+		Type:      itemType,
+		ID:        id,
+		Title:     title,
+		DeletedAt: deletedAt,
+		ExpiresAt: deletedAt.Add(s.retention),
+	}
+}
+
+func (s *service) Restore(ctx context.Context, userID uuid.UUID, itemType ItemType, id uuid.UUID) (interface{}, error) {
+	switch itemType {
+	case ItemTask:
+		restored, err := s.taskService.RestoreTask(ctx, id, userID)
+		if errors.Is(err, task.ErrTaskNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return restored, err
+	case ItemTodo:
+		restored, err := s.todoService.RestoreTodo(ctx, id, userID)
+		if errors.Is(err, todos.ErrTodoNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return restored, err
+	default:
+		return nil, ErrInvalidItemType
+	}
+}
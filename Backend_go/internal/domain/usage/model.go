@@ -0,0 +1,89 @@
+package usage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultWindow is how far back the dashboard looks when no window is given
+	DefaultWindow = 24 * time.Hour
+	// MaxWindow is the furthest back the dashboard looks
+	MaxWindow = 7 * 24 * time.Hour
+)
+
+// ErrInvalidWindow is returned for a window outside one hour to seven days
+var ErrInvalidWindow = errors.New("window must be between 1 and 168 hours")
+
+// Dashboard is a user's recent API usage and the credentials and integrations behind it
+type Dashboard struct {
+	From          time.Time           `json:"from"`
+	To            time.Time           `json:"to"`
+	Calls         CallVolume          `json:"calls"`
+	RateLimits    []RateLimitBucket   `json:"rate_limits"`
+	Sessions      []Session           `json:"sessions"`
+	RefreshTokens []RefreshToken      `json:"refresh_tokens"`
+	APIKeys       []APIKey            `json:"api_keys"`
+	Webhooks      []webhooks.Activity `json:"webhooks"`
+}
+
+// CallVolume is the user's API calls in the window, in total and by hour. Calls
+// rejected by the rate limiter are counted apart from the calls that went through.
+type CallVolume struct {
+	Total       int64        `json:"total"`
+	Errors      int64        `json:"errors"`
+	RateLimited int64        `json:"rate_limited"`
+	ViaAPIKeys  int64        `json:"via_api_keys"`
+	ErrorRate   float64      `json:"error_rate"`
+	Hours       []HourVolume `json:"hours"`
+}
+
+// HourVolume is the user's API calls in one UTC hour
+type HourVolume struct {
+	Hour        time.Time `json:"hour"`
+	Calls       int64     `json:"calls"`
+	Errors      int64     `json:"errors"`
+	RateLimited int64     `json:"rate_limited"`
+	ViaAPIKeys  int64     `json:"via_api_keys"`
+}
+
+// RateLimitBucket is how much of one of the user's rate limits is left right now.
+// Bucket is "default" for the overall limit, or the route group of a route limit.
+type RateLimitBucket struct {
+	Bucket         string `json:"bucket"`
+	Limit          int64  `json:"limit"`
+	Remaining      int64  `json:"remaining"`
+	ResetInSeconds int    `json:"reset_in_seconds"`
+}
+
+// Session is one of the user's signed-in sessions
+type Session struct {
+	ID           string    `json:"id"`
+	DeviceInfo   string    `json:"device_info"`
+	IPAddress    string    `json:"ip_address"`
+	LastActivity time.Time `json:"last_activity"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// RefreshToken is a refresh token the user can still exchange
+type RefreshToken struct {
+	SessionID  string    `json:"session_id"`
+	DeviceInfo string    `json:"device_info"`
+	IPAddress  string    `json:"ip_address"`
+	IssuedAt   time.Time `json:"issued_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// APIKey is one of the user's API keys and when it was last used
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Active     bool       `json:"active"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,197 @@
+package usage
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/apikeys"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"github.com/google/uuid"
+)
+
+// Meters reads the user's metered usage
+type Meters interface {
+	UserUsage(ctx context.Context, userID uuid.UUID, from, to time.Time) (*metering.UserUsageReport, error)
+}
+
+// RateLimits reads the token buckets of a principal
+type RateLimits interface {
+	Inspect(ctx context.Context, principal string) ([]auth.BucketState, error)
+}
+
+// Sessions lists the user's signed-in sessions
+type Sessions interface {
+	GetUserSessions(userID uuid.UUID) []*auth.Session
+}
+
+// Tokens lists the user's refresh tokens
+type Tokens interface {
+	ActiveTokens(ctx context.Context, userID uuid.UUID) ([]auth.RefreshToken, error)
+}
+
+// APIKeys lists the user's API keys
+type APIKeys interface {
+	ListKeys(ctx context.Context, userID uuid.UUID) ([]apikeys.APIKey, error)
+}
+
+// Webhooks summarizes the deliveries of the user's webhooks
+type Webhooks interface {
+	Activity(ctx context.Context, userID uuid.UUID, since time.Time) ([]webhooks.Activity, error)
+}
+
+// Service builds the API usage dashboard of users, to help them debug their clients
+type Service interface {
+	// Dashboard returns the user's usage over the window ending now
+	Dashboard(ctx context.Context, userID uuid.UUID, window time.Duration) (*Dashboard, error)
+}
+
+type service struct {
+	meters     Meters
+	rateLimits RateLimits
+	sessions   Sessions
+	tokens     Tokens
+	keys       APIKeys
+	webhooks   Webhooks
+}
+
+// NewService creates a new usage dashboard service
+func NewService(meters Meters, rateLimits RateLimits, sessions Sessions, tokens Tokens, keys APIKeys, webhooks Webhooks) Service {
+	return &service{
+		meters:     meters,
+		rateLimits: rateLimits,
+		sessions:   sessions,
+		tokens:     tokens,
+		keys:       keys,
+		webhooks:   webhooks,
+	}
+}
+
+func (s *service) Dashboard(ctx context.Context, userID uuid.UUID, window time.Duration) (*Dashboard, error) {
+	if window < time.Hour || window > MaxWindow {
+		return nil, ErrInvalidWindow
+	}
+	now := time.Now()
+	// The current hour is included, so a window of n hours covers n hour buckets
+	from := metering.Hour(now.Add(-window + time.Hour))
+
+	report, err := s.meters.UserUsage(ctx, userID, from, now)
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := s.rateLimits.Inspect(ctx, "user:"+userID.String())
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.tokens.ActiveTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.keys.ListKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.webhooks.Activity(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &Dashboard{
+		From:          from,
+		To:            now,
+		Calls:         callVolume(report, from, now),
+		RateLimits:    make([]RateLimitBucket, 0, len(buckets)),
+		Sessions:      []Session{},
+		RefreshTokens: make([]RefreshToken, 0, len(tokens)),
+		APIKeys:       make([]APIKey, 0, len(keys)),
+		Webhooks:      activity,
+	}
+	for _, b := range buckets {
+		dashboard.RateLimits = append(dashboard.RateLimits, RateLimitBucket{
+			Bucket:         bucketName(b.Key),
+			Limit:          b.Limit,
+			Remaining:      b.Remaining,
+			ResetInSeconds: int(math.Ceil(b.ResetAfter.Seconds())),
+		})
+	}
+	for _, session := range s.sessions.GetUserSessions(userID) {
+		dashboard.Sessions = append(dashboard.Sessions, Session{
+			ID:           session.ID,
+			DeviceInfo:   session.DeviceInfo,
+			IPAddress:    session.IPAddress,
+			LastActivity: session.LastActivity,
+			ExpiresAt:    session.ExpiresAt,
+		})
+	}
+	for _, token := range tokens {
+		dashboard.RefreshTokens = append(dashboard.RefreshTokens, RefreshToken{
+			SessionID:  token.SessionID,
+			DeviceInfo: token.DeviceInfo,
+			IPAddress:  token.IPAddress,
+			IssuedAt:   token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+		})
+	}
+	for _, key := range keys {
+		dashboard.APIKeys = append(dashboard.APIKeys, APIKey{
+			ID:         key.ID,
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     key.Scopes,
+			Active:     key.Active(now),
+			LastUsedAt: key.LastUsedAt,
+			ExpiresAt:  key.ExpiresAt,
+		})
+	}
+	if dashboard.Webhooks == nil {
+		dashboard.Webhooks = []webhooks.Activity{}
+	}
+	return dashboard, nil
+}
+
+// callVolume lays the metered API calls out hour by hour, with zeros for quiet hours
+func callVolume(report *metering.UserUsageReport, from, to time.Time) CallVolume {
+	volume := CallVolume{
+		Total:       report.Totals[metering.MeterAPICalls],
+		Errors:      report.Totals[metering.MeterAPIErrors],
+		RateLimited: report.Totals[metering.MeterAPIRateLimited],
+		ViaAPIKeys:  report.Totals[metering.MeterAPIKeyCalls],
+	}
+	if volume.Total > 0 {
+		volume.ErrorRate = math.Round(float64(volume.Errors)/float64(volume.Total)*1000) / 1000
+	}
+
+	index := make(map[time.Time]int)
+	for hour := from; !hour.After(to); hour = hour.Add(time.Hour) {
+		index[hour] = len(volume.Hours)
+		volume.Hours = append(volume.Hours, HourVolume{Hour: hour})
+	}
+	for _, row := range report.Hours {
+		i, ok := index[row.Hour.UTC()]
+		if !ok {
+			continue
+		}
+		switch row.Meter {
+		case metering.MeterAPICalls:
+			volume.Hours[i].Calls += row.Quantity
+		case metering.MeterAPIErrors:
+			volume.Hours[i].Errors += row.Quantity
+		case metering.MeterAPIRateLimited:
+			volume.Hours[i].RateLimited += row.Quantity
+		case metering.MeterAPIKeyCalls:
+			volume.Hours[i].ViaAPIKeys += row.Quantity
+		}
+	}
+	return volume
+}
+
+// bucketName names a rate limit bucket by what follows the principal in its key
+func bucketName(key string) string {
+	if _, qualifier, ok := strings.Cut(key, "|"); ok && qualifier != "" {
+		return qualifier
+	}
+	return "default"
+}
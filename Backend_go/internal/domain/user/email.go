@@ -0,0 +1,46 @@
+package user
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/email"
+	"github.com/google/uuid"
+)
+
+// EmailRecipients resolves notification email addresses from user accounts, honouring
+// the notifications.email and notifications.email_types preferences
+type EmailRecipients struct {
+	repo Repository
+}
+
+// NewEmailRecipients creates an EmailRecipients backed by the user repository
+func NewEmailRecipients(repo Repository) *EmailRecipients {
+	return &EmailRecipients{repo: repo}
+}
+
+// EmailRecipient returns the user's address, and false when the account is inactive or
+// the user turned off email for the category
+func (r *EmailRecipients) EmailRecipient(ctx context.Context, userID uuid.UUID, category string) (email.Address, bool, error) {
+	user, err := r.repo.FindByID(ctx, userID)
+	if err != nil {
+		return email.Address{}, false, err
+	}
+	if user == nil || !user.IsActive || user.DeletedAt != nil || user.Email == "" {
+		return email.Address{}, false, nil
+	}
+
+	to := email.Address{
+		Name:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Email: user.Email,
+	}
+	notifications, _ := ResolvePreferences(user.Preferences)[PreferenceNamespaceNotifications].(map[string]interface{})
+	if enabled, _ := notifications["email"].(bool); !enabled {
+		return to, false, nil
+	}
+	types, _ := notifications["email_types"].(map[string]interface{})
+	if enabled, ok := types[category].(bool); ok && !enabled {
+		return to, false, nil
+	}
+	return to, true, nil
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -118,3 +119,8 @@ func (u *User) BeforeUpdate(tx *gorm.DB) error {
 	u.UpdatedAt = time.Now()
 	return nil
 }
+
+// PageCursor is the position of the user in keyset pages, which follow creation time
+func (u *User) PageCursor() pagination.Cursor {
+	return pagination.Cursor{Time: u.CreatedAt, ID: u.ID}
+}
@@ -0,0 +1,297 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Preference namespaces
+const (
+	PreferenceNamespaceTheme         = "theme"
+	PreferenceNamespaceDefaultViews  = "default_views"
+	PreferenceNamespaceNotifications = "notifications"
+	PreferenceNamespaceWorkingHours  = "working_hours"
+	PreferenceNamespaceTaskPriority  = "task_priority"
+)
+
+// Weekdays are the values accepted in working_hours.days
+var Weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+type preferenceKind int
+
+const (
+	preferenceObject preferenceKind = iota
+	preferenceString
+	preferenceBool
+	preferenceStringList
+	preferenceNumber
+)
+
+// preferenceField describes the allowed shape of a single preference value
+type preferenceField struct {
+	Kind    preferenceKind
+	Enum    []string
+	Pattern *regexp.Regexp
+	Fields  map[string]preferenceField
+	// Min and Max bound number values
+	Min, Max float64
+}
+
+var (
+	hexColorPattern  = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	timeOfDayPattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+)
+
+// preferenceSchema is the set of namespaced keys accepted in User.Preferences
+var preferenceSchema = preferenceField{
+	Kind: preferenceObject,
+	Fields: map[string]preferenceField{
+		PreferenceNamespaceTheme: {
+			Kind: preferenceObject,
+			Fields: map[string]preferenceField{
+				"mode":         {Kind: preferenceString, Enum: []string{"light", "dark", "system"}},
+				"accent_color": {Kind: preferenceString, Pattern: hexColorPattern},
+				"density":      {Kind: preferenceString, Enum: []string{"compact", "comfortable"}},
+			},
+		},
+		PreferenceNamespaceDefaultViews: {
+			Kind: preferenceObject,
+			Fields: map[string]preferenceField{
+				"tasks":    {Kind: preferenceString, Enum: []string{"list", "board", "calendar", "table"}},
+				"projects": {Kind: preferenceString, Enum: []string{"list", "board", "timeline"}},
+				"calendar": {Kind: preferenceString, Enum: []string{"day", "week", "month", "agenda"}},
+				"todos":    {Kind: preferenceString, Enum: []string{"list", "board"}},
+			},
+		},
+		PreferenceNamespaceNotifications: {
+			Kind: preferenceObject,
+			Fields: map[string]preferenceField{
+				"email":  {Kind: preferenceBool},
+				"push":   {Kind: preferenceBool},
+				"in_app": {Kind: preferenceBool},
+				"digest": {Kind: preferenceString, Enum: []string{"off", "daily", "weekly"}},
+				// Which kinds of notification are emailed, when email is on
+				"email_types": {
+					Kind: preferenceObject,
+					Fields: map[string]preferenceField{
+						"habits":    {Kind: preferenceBool},
+						"tasks":     {Kind: preferenceBool},
+						"workflows": {Kind: preferenceBool},
+						"calendar":  {Kind: preferenceBool},
+						"general":   {Kind: preferenceBool},
+					},
+				},
+				"quiet_hours": {
+					Kind: preferenceObject,
+					Fields: map[string]preferenceField{
+						"enabled": {Kind: preferenceBool},
+						"start":   {Kind: preferenceString, Pattern: timeOfDayPattern},
+						"end":     {Kind: preferenceString, Pattern: timeOfDayPattern},
+					},
+				},
+			},
+		},
+		// Working hours are kept in UTC so they can be compared across users;
+		// days are the UTC weekdays on which the working period starts
+		PreferenceNamespaceWorkingHours: {
+			Kind: preferenceObject,
+			Fields: map[string]preferenceField{
+				"start": {Kind: preferenceString, Pattern: timeOfDayPattern},
+				"end":   {Kind: preferenceString, Pattern: timeOfDayPattern},
+				"days":  {Kind: preferenceStringList, Enum: Weekdays},
+			},
+		},
+		// Weights of the factors that make up the priority score tasks are ranked by
+		PreferenceNamespaceTaskPriority: {
+			Kind: preferenceObject,
+			Fields: map[string]preferenceField{
+				"due":          {Kind: preferenceNumber, Min: 0, Max: 10},
+				"priority":     {Kind: preferenceNumber, Min: 0, Max: 10},
+				"dependencies": {Kind: preferenceNumber, Min: 0, Max: 10},
+				"staleness":    {Kind: preferenceNumber, Min: 0, Max: 10},
+			},
+		},
+	},
+}
+
+// DefaultPreferences returns the preferences applied when a user has not set a value
+func DefaultPreferences() map[string]interface{} {
+	return map[string]interface{}{
+		PreferenceNamespaceTheme: map[string]interface{}{
+			"mode":    "system",
+			"density": "comfortable",
+		},
+		PreferenceNamespaceDefaultViews: map[string]interface{}{
+			"tasks":    "list",
+			"projects": "list",
+			"calendar": "week",
+			"todos":    "list",
+		},
+		PreferenceNamespaceNotifications: map[string]interface{}{
+			"email":  true,
+			"push":   true,
+			"in_app": true,
+			"digest": "daily",
+			"email_types": map[string]interface{}{
+				"habits":    true,
+				"tasks":     true,
+				"workflows": true,
+				"calendar":  true,
+				"general":   true,
+			},
+			"quiet_hours": map[string]interface{}{
+				"enabled": false,
+				"start":   "22:00",
+				"end":     "07:00",
+			},
+		},
+		PreferenceNamespaceTaskPriority: map[string]interface{}{
+			"due":          4.0,
+			"priority":     3.0,
+			"dependencies": 2.0,
+			"staleness":    1.0,
+		},
+	}
+}
+
+// ValidatePreferencesPatch checks a partial preferences document against the schema.
+// A null value is allowed for any known key and resets it to its default.
+func ValidatePreferencesPatch(patch map[string]interface{}) error {
+	return validatePreferenceObject("", preferenceSchema, patch)
+}
+
+func validatePreferenceObject(path string, field preferenceField, values map[string]interface{}) error {
+	for key, value := range values {
+		child, ok := field.Fields[key]
+		keyPath := joinPreferencePath(path, key)
+		if !ok {
+			return fmt.Errorf("%w: unknown key %q", ErrInvalidPreferences, keyPath)
+		}
+		if value == nil {
+			continue
+		}
+		if err := validatePreferenceValue(keyPath, child, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePreferenceValue(path string, field preferenceField, value interface{}) error {
+	switch field.Kind {
+	case preferenceObject:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %s must be an object", ErrInvalidPreferences, path)
+		}
+		return validatePreferenceObject(path, field, obj)
+	case preferenceBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%w: %s must be a boolean", ErrInvalidPreferences, path)
+		}
+	case preferenceStringList:
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %s must be an array of strings", ErrInvalidPreferences, path)
+		}
+		for _, item := range list {
+			str, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("%w: %s must be an array of strings", ErrInvalidPreferences, path)
+			}
+			if len(field.Enum) > 0 && !containsString(field.Enum, str) {
+				return fmt.Errorf("%w: %s values must be one of %s", ErrInvalidPreferences, path, strings.Join(field.Enum, ", "))
+			}
+		}
+	case preferenceNumber:
+		num, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%w: %s must be a number", ErrInvalidPreferences, path)
+		}
+		if num < field.Min || num > field.Max {
+			return fmt.Errorf("%w: %s must be between %g and %g", ErrInvalidPreferences, path, field.Min, field.Max)
+		}
+	case preferenceString:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w: %s must be a string", ErrInvalidPreferences, path)
+		}
+		if len(field.Enum) > 0 && !containsString(field.Enum, str) {
+			return fmt.Errorf("%w: %s must be one of %s", ErrInvalidPreferences, path, strings.Join(field.Enum, ", "))
+		}
+		if field.Pattern != nil && !field.Pattern.MatchString(str) {
+			return fmt.Errorf("%w: %s has an invalid format", ErrInvalidPreferences, path)
+		}
+	}
+	return nil
+}
+
+// MergePreferences applies a partial preferences document to the current one using
+// JSON merge patch semantics: objects are merged recursively and null removes a key.
+// Neither argument is modified.
+func MergePreferences(current, patch map[string]interface{}) map[string]interface{} {
+	merged := copyPreferences(current)
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		patchObj, isObj := value.(map[string]interface{})
+		if !isObj {
+			merged[key] = value
+			continue
+		}
+		currentObj, _ := merged[key].(map[string]interface{})
+		result := MergePreferences(currentObj, patchObj)
+		if len(result) == 0 {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = result
+	}
+	return merged
+}
+
+// ResolvePreferences returns the stored preferences layered over the defaults,
+// dropping any stored keys that are no longer part of the schema
+func ResolvePreferences(stored map[string]interface{}) map[string]interface{} {
+	known := make(map[string]interface{}, len(stored))
+	for key, value := range stored {
+		if _, ok := preferenceSchema.Fields[key]; ok {
+			known[key] = value
+		}
+	}
+	return MergePreferences(DefaultPreferences(), known)
+}
+
+func copyPreferences(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for key, value := range src {
+		if obj, ok := value.(map[string]interface{}); ok {
+			dst[key] = copyPreferences(obj)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+func joinPreferencePath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -27,6 +28,8 @@ type UserFilter struct {
 	Locale      *string
 	Page        int
 	PageSize    int
+	// Keyset pages by creation time instead of Page and PageSize, without counting the total
+	Keyset *pagination.Keyset
 }
 
 // AnalyticsFilter defines filtering options for user analytics
@@ -113,6 +116,11 @@ func (r *repository) FindAll(ctx context.Context, filter UserFilter) ([]User, in
 		query = query.Where("locale = ?", *filter.Locale)
 	}
 
+	if filter.Keyset != nil {
+		err := query.Scopes(filter.Keyset.Scope("created_at", "id")).Find(&users).Error
+		return users, 0, err
+	}
+
 	err := query.Count(&total).Error
 	if err != nil {
 		return nil, 0, err
@@ -8,17 +8,18 @@ import (
 
 	"encoding/json"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/events"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/mfa"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var log = logrus.New()
+var log = logger.Logrus("users")
 
 // Input types
 type CreateUserInput struct {
@@ -114,6 +115,8 @@ type Service interface {
 	FindUserByProviderID(ctx context.Context, providerID, provider string) (*User, error)
 	ListUsers(ctx context.Context, filter UserFilter) ([]User, int64, error)
 	UpdateUser(ctx context.Context, id uuid.UUID, input UpdateUserInput) (*User, error)
+	GetPreferences(ctx context.Context, id uuid.UUID) (map[string]interface{}, error)
+	UpdatePreferences(ctx context.Context, id uuid.UUID, patch map[string]interface{}) (map[string]interface{}, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 	AuthenticateUser(ctx context.Context, email, password string) (*User, error)
 	UpdatePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error
@@ -139,19 +142,29 @@ type Service interface {
 	GetDashboardMetrics(userID uuid.UUID) (UserDashboardMetrics, error)
 }
 
+// CredentialRevoker revokes a user's sessions and refresh tokens.
+// It is called after a password change so stolen credentials stop working.
+type CredentialRevoker interface {
+	RevokeUserCredentials(ctx context.Context, userID uuid.UUID) error
+}
+
 type service struct {
 	repo         Repository
 	rolesService roles.Service
 	mfaService   mfa.Service
 	redis        *cache.RedisClient
+	revoker      CredentialRevoker
+	auditor      audit.Auditor
 }
 
-func NewService(repo Repository, rolesService roles.Service, redis *cache.RedisClient) Service {
+func NewService(repo Repository, rolesService roles.Service, redis *cache.RedisClient, revoker CredentialRevoker, auditor audit.Auditor) Service {
 	return &service{
 		repo:         repo,
 		rolesService: rolesService,
 		mfaService:   mfa.NewService("Compass"),
 		redis:        redis,
+		revoker:      revoker,
+		auditor:      auditor,
 	}
 }
 
@@ -377,6 +390,10 @@ func (s *service) UpdateUser(ctx context.Context, id uuid.UUID, input UpdateUser
 
 	// Record profile update analytics
 	s.recordProfileUpdate(ctx, user.ID)
+	if input.Password != nil {
+		s.recordPasswordChange(ctx, user.ID)
+		s.revokeCredentials(ctx, user.ID)
+	}
 
 	// Record email/username change analytics
 	if input.Email != nil && *input.Email != oldEmail {
@@ -414,6 +431,43 @@ func (s *service) UpdateUser(ctx context.Context, id uuid.UUID, input UpdateUser
 	return user, nil
 }
 
+// GetPreferences returns the user's UI preferences layered over the defaults
+func (s *service) GetPreferences(ctx context.Context, id uuid.UUID) (map[string]interface{}, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ResolvePreferences(user.Preferences), nil
+}
+
+// UpdatePreferences validates a partial preferences document and merges it into the stored preferences
+func (s *service) UpdatePreferences(ctx context.Context, id uuid.UUID, patch map[string]interface{}) (map[string]interface{}, error) {
+	if err := ValidatePreferencesPatch(patch); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Preferences = MergePreferences(user.Preferences, patch)
+	user.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(patch))
+	for namespace := range patch {
+		namespaces = append(namespaces, namespace)
+	}
+	s.recordUserActivity(ctx, user.ID, "preferences_updated", map[string]interface{}{
+		"namespaces": namespaces,
+	})
+
+	return ResolvePreferences(user.Preferences), nil
+}
+
 func (s *service) recordProfileUpdate(ctx context.Context, userID uuid.UUID) {
 	analytics := &UserAnalytics{
 		ID:        uuid.New(),
@@ -486,13 +540,29 @@ func (s *service) AuthenticateUser(ctx context.Context, email, password string)
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
 		s.recordUserActivity(ctx, user.ID, "login_failed", nil)
+		s.audit(ctx, user.ID, audit.ActionLoginFailed, nil)
 		return nil, ErrInvalidCredentials
 	}
 
 	s.recordUserActivity(ctx, user.ID, "login_success", nil)
+	s.audit(ctx, user.ID, audit.ActionLogin, map[string]interface{}{"mfa_required": user.MFAEnabled})
 	return user, nil
 }
 
+// audit logs a sign-in attempt of a user in each of the user's organizations
+func (s *service) audit(ctx context.Context, userID uuid.UUID, action audit.Action, metadata map[string]interface{}) {
+	if s.auditor == nil {
+		return
+	}
+	s.auditor.Audit(ctx, audit.Event{
+		ActorID:    &userID,
+		Action:     action,
+		TargetType: "user",
+		TargetID:   &userID,
+		Metadata:   metadata,
+	})
+}
+
 func (s *service) recordLoginAttempt(ctx context.Context, userID uuid.UUID, success bool) {
 	action := "login_success"
 	if !success {
@@ -550,10 +620,22 @@ func (s *service) UpdatePassword(ctx context.Context, id uuid.UUID, currentPassw
 
 	// Record password change
 	s.recordPasswordChange(ctx, user.ID)
+	s.revokeCredentials(ctx, user.ID)
 
 	return nil
 }
 
+// revokeCredentials signs the user out everywhere after a password change
+func (s *service) revokeCredentials(ctx context.Context, userID uuid.UUID) {
+	if s.revoker == nil {
+		return
+	}
+	if err := s.revoker.RevokeUserCredentials(ctx, userID); err != nil {
+		// Log error in a real application
+		fmt.Printf("Error revoking credentials after password change: %v\n", err)
+	}
+}
+
 func (s *service) recordPasswordChange(ctx context.Context, userID uuid.UUID) {
 	analytics := &UserAnalytics{
 		ID:        uuid.New(),
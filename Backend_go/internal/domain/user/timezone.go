@@ -0,0 +1,50 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// offsetTimezonePattern matches fixed offsets such as "GMT+2", "UTC-05:30" or "GMT+0530"
+var offsetTimezonePattern = regexp.MustCompile(`^(?:GMT|UTC)([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// ParseTimezone resolves a user timezone, given either as an IANA name such as
+// "Africa/Cairo" or as a fixed offset such as "GMT+2"
+func ParseTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: timezone is required", ErrInvalidTimezone)
+	}
+
+	if m := offsetTimezonePattern.FindStringSubmatch(strings.ToUpper(name)); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes := 0
+		if m[3] != "" {
+			minutes, _ = strconv.Atoi(m[3])
+		}
+		if hours > 14 || minutes > 59 {
+			return nil, fmt.Errorf("%w: offset out of range in %q", ErrInvalidTimezone, name)
+		}
+		offset := hours*3600 + minutes*60
+		if m[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(name, offset), nil
+	}
+
+	// "Local" would resolve to the server's zone rather than the user's
+	if name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	return loc, nil
+}
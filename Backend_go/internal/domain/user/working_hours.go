@@ -0,0 +1,79 @@
+package user
+
+import "time"
+
+const (
+	// defaultWorkStart and defaultWorkEnd are the local working hours of users who
+	// have not set theirs
+	defaultWorkStart = 9
+	defaultWorkEnd   = 17
+)
+
+// WorkingPeriod is a stretch of time a user works
+type WorkingPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// WorkingPeriods returns the periods between start and end the user works. Working hours
+// set in the preferences are in UTC and may run past midnight; without them the user
+// works 09:00 to 17:00 on weekdays in their timezone.
+func WorkingPeriods(u *User, start, end time.Time) []WorkingPeriod {
+	wh, _ := u.Preferences[PreferenceNamespaceWorkingHours].(map[string]interface{})
+	startClock, _ := wh["start"].(string)
+	endClock, _ := wh["end"].(string)
+	from, errFrom := time.Parse("15:04", startClock)
+	to, errTo := time.Parse("15:04", endClock)
+
+	var periods []WorkingPeriod
+	add := func(periodStart, periodEnd time.Time) {
+		if periodStart.Before(start) {
+			periodStart = start
+		}
+		if periodEnd.After(end) {
+			periodEnd = end
+		}
+		if periodEnd.After(periodStart) {
+			periods = append(periods, WorkingPeriod{Start: periodStart, End: periodEnd})
+		}
+	}
+
+	if errFrom != nil || errTo != nil {
+		loc, err := ParseTimezone(u.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		local := start.In(loc)
+		for day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+				continue
+			}
+			add(time.Date(day.Year(), day.Month(), day.Day(), defaultWorkStart, 0, 0, 0, loc),
+				time.Date(day.Year(), day.Month(), day.Day(), defaultWorkEnd, 0, 0, 0, loc))
+		}
+		return periods
+	}
+
+	days := make(map[string]bool)
+	if list, ok := wh["days"].([]interface{}); ok {
+		for _, d := range list {
+			if name, ok := d.(string); ok {
+				days[name] = true
+			}
+		}
+	}
+	startOffset := time.Duration(from.Hour())*time.Hour + time.Duration(from.Minute())*time.Minute
+	endOffset := time.Duration(to.Hour())*time.Hour + time.Duration(to.Minute())*time.Minute
+	if endOffset <= startOffset {
+		endOffset += 24 * time.Hour
+	}
+	// A period that runs past midnight started the day before, so that day is included
+	utc := start.UTC()
+	for day := time.Date(utc.Year(), utc.Month(), utc.Day()-1, 0, 0, 0, 0, time.UTC); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if len(days) > 0 && !days[Weekdays[day.Weekday()]] {
+			continue
+		}
+		add(day.Add(startOffset), day.Add(endOffset))
+	}
+	return periods
+}
@@ -0,0 +1,142 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+)
+
+// Client adapts a code platform API and its repository webhooks
+type Client interface {
+	Name() ProviderName
+	// GetItem fetches an issue or pull request of the connected repository
+	GetItem(ctx context.Context, conn *Connection, kind ItemKind, number int) (*Item, error)
+	// CheckAccess confirms the connection's token can read the repository
+	CheckAccess(ctx context.Context, conn *Connection) error
+	// PostComment adds a comment to an issue or pull request
+	PostComment(ctx context.Context, conn *Connection, kind ItemKind, number int, body string) error
+	// VerifyWebhook checks that a webhook delivery was signed with the connection's secret
+	VerifyWebhook(conn *Connection, header http.Header, body []byte) error
+	// ParseWebhook reads an issue or pull request change from a webhook delivery.
+	// It returns nil for deliveries that do not change the state of an item.
+	ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// Reference identifies an issue or pull request parsed from its web URL
+type Reference struct {
+	Provider   ProviderName
+	BaseURL    string
+	Repository string
+	Kind       ItemKind
+	Number     int
+}
+
+var (
+	githubItemURL = regexp.MustCompile(`^/([^/]+/[^/]+)/(issues|pull)/(\d+)/?$`)
+	gitlabItemURL = regexp.MustCompile(`^/(.+?)/-/(issues|merge_requests)/(\d+)/?$`)
+)
+
+// ParseReference reads the repository, kind and number of an issue or pull request URL.
+// GitHub URLs look like /owner/repo/pull/12 and GitLab URLs like /group/project/-/merge_requests/12.
+func ParseReference(rawURL string) (*Reference, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, ErrInvalidReference
+	}
+	base := u.Scheme + "://" + u.Host
+
+	if m := gitlabItemURL.FindStringSubmatch(u.Path); m != nil {
+		number, _ := strconv.Atoi(m[3])
+		kind := KindIssue
+		if m[2] == "merge_requests" {
+			kind = KindPullRequest
+		}
+		return &Reference{Provider: ProviderGitLab, BaseURL: base, Repository: m[1], Kind: kind, Number: number}, nil
+	}
+	if m := githubItemURL.FindStringSubmatch(u.Path); m != nil {
+		number, _ := strconv.Atoi(m[3])
+		kind := KindIssue
+		if m[2] == "pull" {
+			kind = KindPullRequest
+		}
+		return &Reference{Provider: ProviderGitHub, BaseURL: base, Repository: m[1], Kind: kind, Number: number}, nil
+	}
+	return nil, ErrInvalidReference
+}
+
+// requestTimeout bounds each call to a code platform
+const requestTimeout = 10 * time.Second
+
+// HTTPClient sends the requests of the platform clients. Base URLs come from users, so
+// requests go through a client that refuses internal addresses, except those to the
+// self-hosted instances the operator allowed, which usually live on the internal network.
+type HTTPClient struct {
+	guarded      *http.Client
+	trusted      *http.Client
+	allowedHosts map[string]bool
+}
+
+// NewHTTPClient creates the client shared by the platform clients. allowedHosts are the
+// hosts of the self-hosted instances connections may use, with the port unless it is 443.
+func NewHTTPClient(allowedHosts []string) *HTTPClient {
+	allowed := make(map[string]bool, len(allowedHosts))
+	for _, host := range allowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			allowed[host] = true
+		}
+	}
+	return &HTTPClient{
+		guarded: webhooks.NewClient(requestTimeout),
+		trusted: &http.Client{
+			Timeout: requestTimeout,
+			// Redirects to other hosts would skip the address check
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+					return http.ErrUseLastResponse
+				}
+				return nil
+			},
+		},
+		allowedHosts: allowed,
+	}
+}
+
+// Allows reports whether a host is an allowed self-hosted instance
+func (c *HTTPClient) Allows(host string) bool {
+	return c.allowedHosts[strings.ToLower(host)]
+}
+
+// doJSON sends a request and decodes a JSON response, mapping 404 to ErrItemNotFound.
+// Error responses are not echoed; they may carry whatever the host chose to send.
+func (c *HTTPClient) doJSON(req *http.Request, dst interface{}) error {
+	client := c.guarded
+	if c.Allows(req.URL.Host) {
+		client = c.trusted
+	}
+
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrItemNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with status %d", req.URL.Host, resp.StatusCode)
+	}
+	if dst == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
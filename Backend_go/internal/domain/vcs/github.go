@@ -0,0 +1,182 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	githubWebURL = "https://github.com"
+	githubAPIURL = "https://api.github.com"
+)
+
+type githubClient struct {
+	client *HTTPClient
+}
+
+// NewGitHubClient creates the GitHub adapter. It also serves GitHub Enterprise when a connection sets BaseURL.
+func NewGitHubClient(httpClient *HTTPClient) Client {
+	return &githubClient{client: httpClient}
+}
+
+func (g *githubClient) Name() ProviderName {
+	return ProviderGitHub
+}
+
+func (g *githubClient) apiURL(conn *Connection, path string) string {
+	base := githubAPIURL
+	if conn.BaseURL != "" {
+		base = strings.TrimRight(conn.BaseURL, "/") + "/api/v3"
+	}
+	return base + "/repos/" + conn.Repository + path
+}
+
+func (g *githubClient) newRequest(ctx context.Context, conn *Connection, method, path string, body interface{}) (*http.Request, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.apiURL(conn, path), reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+conn.AccessToken)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (g *githubClient) GetItem(ctx context.Context, conn *Connection, kind ItemKind, number int) (*Item, error) {
+	path := fmt.Sprintf("/issues/%d", number)
+	if kind == KindPullRequest {
+		path = fmt.Sprintf("/pulls/%d", number)
+	}
+	req, err := g.newRequest(ctx, conn, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		HTMLURL     string          `json:"html_url"`
+		Title       string          `json:"title"`
+		State       string          `json:"state"`
+		Merged      bool            `json:"merged"`
+		PullRequest json.RawMessage `json:"pull_request"`
+	}
+	if err := g.client.doJSON(req, &resp); err != nil {
+		return nil, err
+	}
+	// The issues API also returns pull requests; don't link a pull request as an issue
+	if kind == KindIssue && len(resp.PullRequest) > 0 {
+		return nil, ErrItemNotFound
+	}
+
+	return &Item{
+		Kind:   kind,
+		Number: number,
+		URL:    resp.HTMLURL,
+		Title:  resp.Title,
+		State:  githubState(resp.State, resp.Merged),
+	}, nil
+}
+
+func (g *githubClient) CheckAccess(ctx context.Context, conn *Connection) error {
+	req, err := g.newRequest(ctx, conn, http.MethodGet, "", nil)
+	if err != nil {
+		return err
+	}
+	return g.client.doJSON(req, nil)
+}
+
+func (g *githubClient) PostComment(ctx context.Context, conn *Connection, kind ItemKind, number int, body string) error {
+	// Pull request conversation comments are issue comments on GitHub
+	req, err := g.newRequest(ctx, conn, http.MethodPost, fmt.Sprintf("/issues/%d/comments", number), map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+	return g.client.doJSON(req, nil)
+}
+
+// VerifyWebhook checks the X-Hub-Signature-256 header, an HMAC-SHA256 of the body
+func (g *githubClient) VerifyWebhook(conn *Connection, header http.Header, body []byte) error {
+	mac := hmac.New(sha256.New, []byte(conn.WebhookSecret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Hub-Signature-256"))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (g *githubClient) ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	var payload struct {
+		Action     string `json:"action"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Issue *struct {
+			Number      int             `json:"number"`
+			State       string          `json:"state"`
+			PullRequest json.RawMessage `json:"pull_request"`
+		} `json:"issue"`
+		PullRequest *struct {
+			Number int    `json:"number"`
+			State  string `json:"state"`
+			Merged bool   `json:"merged"`
+		} `json:"pull_request"`
+	}
+
+	switch header.Get("X-GitHub-Event") {
+	case "issues", "pull_request":
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if payload.Action != "closed" && payload.Action != "reopened" {
+		return nil, nil
+	}
+
+	event := &WebhookEvent{Repository: payload.Repository.FullName}
+	switch {
+	case payload.PullRequest != nil:
+		event.Kind = KindPullRequest
+		event.Number = payload.PullRequest.Number
+		event.State = githubState(payload.PullRequest.State, payload.PullRequest.Merged)
+	case payload.Issue != nil && len(payload.Issue.PullRequest) == 0:
+		event.Kind = KindIssue
+		event.Number = payload.Issue.Number
+		event.State = githubState(payload.Issue.State, false)
+	default:
+		return nil, nil
+	}
+	return event, nil
+}
+
+func githubState(state string, merged bool) ItemState {
+	switch {
+	case merged:
+		return StateMerged
+	case state == "closed":
+		return StateClosed
+	default:
+		return StateOpen
+	}
+}
@@ -0,0 +1,147 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const gitlabWebURL = "https://gitlab.com"
+
+type gitlabClient struct {
+	client *HTTPClient
+}
+
+// NewGitLabClient creates the GitLab adapter. Self-hosted instances are reached through a connection's BaseURL.
+func NewGitLabClient(httpClient *HTTPClient) Client {
+	return &gitlabClient{client: httpClient}
+}
+
+func (g *gitlabClient) Name() ProviderName {
+	return ProviderGitLab
+}
+
+func (g *gitlabClient) apiURL(conn *Connection, path string) string {
+	base := gitlabWebURL
+	if conn.BaseURL != "" {
+		base = strings.TrimRight(conn.BaseURL, "/")
+	}
+	return base + "/api/v4/projects/" + url.PathEscape(conn.Repository) + path
+}
+
+func itemPath(kind ItemKind, number int) string {
+	if kind == KindPullRequest {
+		return fmt.Sprintf("/merge_requests/%d", number)
+	}
+	return fmt.Sprintf("/issues/%d", number)
+}
+
+func (g *gitlabClient) GetItem(ctx context.Context, conn *Connection, kind ItemKind, number int) (*Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.apiURL(conn, itemPath(kind, number)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", conn.AccessToken)
+
+	var resp struct {
+		WebURL string `json:"web_url"`
+		Title  string `json:"title"`
+		State  string `json:"state"`
+	}
+	if err := g.client.doJSON(req, &resp); err != nil {
+		return nil, err
+	}
+
+	return &Item{
+		Kind:   kind,
+		Number: number,
+		URL:    resp.WebURL,
+		Title:  resp.Title,
+		State:  gitlabState(resp.State),
+	}, nil
+}
+
+func (g *gitlabClient) CheckAccess(ctx context.Context, conn *Connection) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.apiURL(conn, ""), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", conn.AccessToken)
+	return g.client.doJSON(req, nil)
+}
+
+func (g *gitlabClient) PostComment(ctx context.Context, conn *Connection, kind ItemKind, number int, body string) error {
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.apiURL(conn, itemPath(kind, number)+"/notes"), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", conn.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+	return g.client.doJSON(req, nil)
+}
+
+// VerifyWebhook compares the X-Gitlab-Token header with the connection's secret
+func (g *gitlabClient) VerifyWebhook(conn *Connection, header http.Header, body []byte) error {
+	if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(conn.WebhookSecret)) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (g *gitlabClient) ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	var kind ItemKind
+	switch header.Get("X-Gitlab-Event") {
+	case "Issue Hook":
+		kind = KindIssue
+	case "Merge Request Hook":
+		kind = KindPullRequest
+	default:
+		return nil, nil
+	}
+
+	var payload struct {
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+		ObjectAttributes struct {
+			IID    int    `json:"iid"`
+			State  string `json:"state"`
+			Action string `json:"action"`
+		} `json:"object_attributes"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	switch payload.ObjectAttributes.Action {
+	case "close", "reopen", "merge":
+	default:
+		return nil, nil
+	}
+
+	return &WebhookEvent{
+		Repository: payload.Project.PathWithNamespace,
+		Kind:       kind,
+		Number:     payload.ObjectAttributes.IID,
+		State:      gitlabState(payload.ObjectAttributes.State),
+	}, nil
+}
+
+func gitlabState(state string) ItemState {
+	switch state {
+	case "merged":
+		return StateMerged
+	case "closed":
+		return StateClosed
+	default:
+		return StateOpen
+	}
+}
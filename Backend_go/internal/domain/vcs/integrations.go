@@ -0,0 +1,59 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/integrations"
+	"github.com/google/uuid"
+)
+
+// IntegrationSource reports an organization's repository connections on its integrations page
+type IntegrationSource struct {
+	repo    Repository
+	service Service
+}
+
+// NewIntegrationSource creates an integration source for repository connections
+func NewIntegrationSource(repo Repository, service Service) *IntegrationSource {
+	return &IntegrationSource{repo: repo, service: service}
+}
+
+// List returns the repositories connected to the organization's projects
+func (s *IntegrationSource) List(ctx context.Context, orgID uuid.UUID) ([]integrations.Integration, error) {
+	conns, err := s.repo.ListOrganizationConnections(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]integrations.Integration, 0, len(conns))
+	for _, conn := range conns {
+		projectID := conn.ProjectID
+		items = append(items, integrations.Integration{
+			ID:          conn.ID,
+			Provider:    string(conn.Provider),
+			Name:        conn.Repository,
+			ProjectID:   &projectID,
+			ConnectedAt: conn.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// Reconnect checks the connection against its platform, replacing the access token if one is given
+func (s *IntegrationSource) Reconnect(ctx context.Context, orgID, userID, id uuid.UUID, input integrations.ReconnectInput) (string, error) {
+	conn, err := s.repo.FindConnectionByID(ctx, id)
+	if errors.Is(err, ErrConnectionNotFound) || (err == nil && conn.OrganizationID != orgID) {
+		return "", integrations.ErrIntegrationNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var token *string
+	if t := strings.TrimSpace(input.AccessToken); t != "" {
+		token = &t
+	}
+	_, err = s.service.VerifyConnection(ctx, Caller{UserID: userID, OrganizationID: orgID}, id, token)
+	return "", err
+}
@@ -0,0 +1,156 @@
+package vcs
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProviderName identifies a code hosting platform
+type ProviderName string
+
+const (
+	ProviderGitHub ProviderName = "github"
+	ProviderGitLab ProviderName = "gitlab"
+)
+
+// IsValid checks if the provider is supported
+func (p ProviderName) IsValid() bool {
+	switch p {
+	case ProviderGitHub, ProviderGitLab:
+		return true
+	default:
+		return false
+	}
+}
+
+// ItemKind is the kind of external item a task is linked to
+type ItemKind string
+
+const (
+	KindIssue       ItemKind = "issue"
+	KindPullRequest ItemKind = "pull_request"
+)
+
+// IsValid checks if the item kind is valid
+func (k ItemKind) IsValid() bool {
+	return k == KindIssue || k == KindPullRequest
+}
+
+// ItemState is the state of an external issue or pull request
+type ItemState string
+
+const (
+	StateOpen   ItemState = "open"
+	StateClosed ItemState = "closed"
+	StateMerged ItemState = "merged"
+)
+
+var (
+	ErrConnectionNotFound = errors.New("repository connection not found")
+	ErrConnectionExists   = errors.New("repository is already connected to this project")
+	ErrLinkNotFound       = errors.New("link not found")
+	ErrLinkExists         = errors.New("task is already linked to this item")
+	ErrItemNotFound       = errors.New("issue or pull request not found")
+	ErrRepositoryMismatch = errors.New("item does not belong to the connected repository")
+	ErrInvalidReference   = errors.New("invalid issue or pull request reference")
+	ErrInvalidSignature   = errors.New("invalid webhook signature")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrNotAuthorized      = errors.New("not authorized")
+)
+
+// Connection connects a project to a GitHub or GitLab repository
+type Connection struct {
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID    `json:"organization_id" gorm:"type:uuid;not null;index:idx_vcs_connection_org"`
+	ProjectID      uuid.UUID    `json:"project_id" gorm:"type:uuid;not null;uniqueIndex:idx_vcs_connection_repo,priority:1"`
+	Provider       ProviderName `json:"provider" gorm:"type:varchar(20);not null;uniqueIndex:idx_vcs_connection_repo,priority:2"`
+	// Repository is "owner/name" on GitHub or the project path on GitLab
+	Repository string `json:"repository" gorm:"type:varchar(255);not null;uniqueIndex:idx_vcs_connection_repo,priority:3"`
+	// BaseURL points at a self-hosted GitLab or GitHub Enterprise instance; empty means the public service
+	BaseURL       string `json:"base_url,omitempty" gorm:"type:text"`
+	AccessToken   string `json:"-" gorm:"type:text;not null"`
+	WebhookSecret string `json:"-" gorm:"type:varchar(128);not null"`
+	// SyncStatus is the task status applied when a linked pull request merges or issue closes; empty disables sync
+	SyncStatus string    `json:"sync_status" gorm:"type:varchar(50)"`
+	CreatedBy  uuid.UUID `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Connection model
+func (Connection) TableName() string {
+	return "vcs_connections"
+}
+
+// BeforeCreate is called before creating a new connection record
+func (c *Connection) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating a connection record
+func (c *Connection) BeforeUpdate(tx *gorm.DB) error {
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// Link ties a task to an issue or pull request of a connected repository
+type Link struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	TaskID         uuid.UUID `json:"task_id" gorm:"type:uuid;not null;index:idx_vcs_link_task;uniqueIndex:idx_vcs_link_item,priority:4"`
+	ConnectionID   uuid.UUID `json:"connection_id" gorm:"type:uuid;not null;uniqueIndex:idx_vcs_link_item,priority:1"`
+	Kind           ItemKind  `json:"kind" gorm:"type:varchar(20);not null;uniqueIndex:idx_vcs_link_item,priority:2"`
+	Number         int       `json:"number" gorm:"not null;uniqueIndex:idx_vcs_link_item,priority:3"`
+	URL            string    `json:"url" gorm:"type:text;not null"`
+	Title          string    `json:"title" gorm:"type:text"`
+	State          ItemState `json:"state" gorm:"type:varchar(20);not null"`
+	BacklinkPosted bool      `json:"backlink_posted" gorm:"not null;default:false"`
+	CreatedBy      uuid.UUID `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Link model
+func (Link) TableName() string {
+	return "vcs_links"
+}
+
+// BeforeCreate is called before creating a new link record
+func (l *Link) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	l.CreatedAt = time.Now()
+	l.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating a link record
+func (l *Link) BeforeUpdate(tx *gorm.DB) error {
+	l.UpdatedAt = time.Now()
+	return nil
+}
+
+// Item is an issue or pull request as reported by the code platform
+type Item struct {
+	Kind   ItemKind
+	Number int
+	URL    string
+	Title  string
+	State  ItemState
+}
+
+// WebhookEvent is a change to an issue or pull request delivered by a repository webhook
+type WebhookEvent struct {
+	Repository string
+	Kind       ItemKind
+	Number     int
+	State      ItemState
+}
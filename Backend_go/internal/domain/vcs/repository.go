@@ -0,0 +1,168 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for repository connection and link data access
+type Repository interface {
+	CreateConnection(ctx context.Context, conn *Connection) error
+	UpdateConnection(ctx context.Context, conn *Connection) error
+	DeleteConnection(ctx context.Context, id uuid.UUID) error
+	FindConnectionByID(ctx context.Context, id uuid.UUID) (*Connection, error)
+	ListConnections(ctx context.Context, projectID uuid.UUID) ([]Connection, error)
+	ListOrganizationConnections(ctx context.Context, orgID uuid.UUID) ([]Connection, error)
+
+	CreateLink(ctx context.Context, link *Link) error
+	UpdateLink(ctx context.Context, link *Link) error
+	MarkBacklinkPosted(ctx context.Context, id uuid.UUID) error
+	DeleteLink(ctx context.Context, id uuid.UUID) error
+	FindLinkByID(ctx context.Context, id uuid.UUID) (*Link, error)
+	ListTaskLinks(ctx context.Context, taskID uuid.UUID) ([]Link, error)
+	FindLinksByItem(ctx context.Context, connectionID uuid.UUID, kind ItemKind, number int) ([]Link, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new repository connection repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// CreateConnection stores a new repository connection
+func (r *repository) CreateConnection(ctx context.Context, conn *Connection) error {
+	err := r.db.WithContext(ctx).Create(conn).Error
+	if isUniqueViolation(err) {
+		return ErrConnectionExists
+	}
+	return err
+}
+
+// UpdateConnection saves changes to a repository connection
+func (r *repository) UpdateConnection(ctx context.Context, conn *Connection) error {
+	return r.db.WithContext(ctx).Save(conn).Error
+}
+
+// DeleteConnection removes a repository connection together with its links
+func (r *repository) DeleteConnection(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("connection_id = ?", id).Delete(&Link{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Connection{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConnectionNotFound
+		}
+		return nil
+	})
+}
+
+// FindConnectionByID retrieves a repository connection by its ID
+func (r *repository) FindConnectionByID(ctx context.Context, id uuid.UUID) (*Connection, error) {
+	var conn Connection
+	if err := r.db.WithContext(ctx).First(&conn, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConnectionNotFound
+		}
+		return nil, err
+	}
+	return &conn, nil
+}
+
+// ListConnections returns the repositories connected to a project
+func (r *repository) ListConnections(ctx context.Context, projectID uuid.UUID) ([]Connection, error) {
+	var conns []Connection
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("created_at ASC").
+		Find(&conns).Error
+	return conns, err
+}
+
+// ListOrganizationConnections returns the repositories connected to any project of an organization
+func (r *repository) ListOrganizationConnections(ctx context.Context, orgID uuid.UUID) ([]Connection, error) {
+	var conns []Connection
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("created_at ASC").
+		Find(&conns).Error
+	return conns, err
+}
+
+// CreateLink stores a new task link
+func (r *repository) CreateLink(ctx context.Context, link *Link) error {
+	err := r.db.WithContext(ctx).Create(link).Error
+	if isUniqueViolation(err) {
+		return ErrLinkExists
+	}
+	return err
+}
+
+// UpdateLink saves changes to a task link
+func (r *repository) UpdateLink(ctx context.Context, link *Link) error {
+	return r.db.WithContext(ctx).Save(link).Error
+}
+
+// MarkBacklinkPosted records that the backlink comment of a link was posted, leaving the
+// rest of the link as it is
+func (r *repository) MarkBacklinkPosted(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&Link{}).Where("id = ?", id).Update("backlink_posted", true).Error
+}
+
+// DeleteLink removes a task link
+func (r *repository) DeleteLink(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&Link{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+// FindLinkByID retrieves a task link by its ID
+func (r *repository) FindLinkByID(ctx context.Context, id uuid.UUID) (*Link, error) {
+	var link Link
+	if err := r.db.WithContext(ctx).First(&link, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ListTaskLinks returns the issues and pull requests linked to a task
+func (r *repository) ListTaskLinks(ctx context.Context, taskID uuid.UUID) ([]Link, error) {
+	var links []Link
+	err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("created_at ASC").
+		Find(&links).Error
+	return links, err
+}
+
+// FindLinksByItem returns every task linked to an issue or pull request
+func (r *repository) FindLinksByItem(ctx context.Context, connectionID uuid.UUID, kind ItemKind, number int) ([]Link, error) {
+	var links []Link
+	err := r.db.WithContext(ctx).
+		Where("connection_id = ? AND kind = ? AND number = ?", connectionID, kind, number).
+		Find(&links).Error
+	return links, err
+}
+
+func isUniqueViolation(err error) bool {
+	return err != nil && (errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "SQLSTATE 23505"))
+}
@@ -0,0 +1,526 @@
+package vcs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/integrations"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// backlinkTimeout bounds posting the backlink comment after a task is linked
+const backlinkTimeout = 15 * time.Second
+
+// Caller identifies the user making a request and the organization of their token
+type Caller struct {
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+// CreateConnectionInput describes a repository to connect to a project
+type CreateConnectionInput struct {
+	ProjectID   uuid.UUID
+	Provider    ProviderName
+	Repository  string
+	BaseURL     string
+	AccessToken string
+	SyncStatus  *string
+}
+
+// UpdateConnectionInput holds the connection settings that can be changed
+type UpdateConnectionInput struct {
+	AccessToken  *string
+	SyncStatus   *string
+	RotateSecret bool
+}
+
+// LinkTaskInput identifies the issue or pull request to link, either by its URL or by
+// connection, kind and number
+type LinkTaskInput struct {
+	TaskID       uuid.UUID
+	URL          string
+	ConnectionID *uuid.UUID
+	Kind         ItemKind
+	Number       int
+}
+
+// Service defines the interface for code platform integration business logic. Connections
+// belong to the caller's organization; the caller checks the user's permission to manage them.
+type Service interface {
+	CreateConnection(ctx context.Context, caller Caller, input CreateConnectionInput) (*Connection, error)
+	ListConnections(ctx context.Context, caller Caller, projectID uuid.UUID) ([]Connection, error)
+	UpdateConnection(ctx context.Context, caller Caller, id uuid.UUID, input UpdateConnectionInput) (*Connection, error)
+	DeleteConnection(ctx context.Context, caller Caller, id uuid.UUID) error
+	VerifyConnection(ctx context.Context, caller Caller, id uuid.UUID, accessToken *string) (*Connection, error)
+
+	LinkTask(ctx context.Context, caller Caller, input LinkTaskInput) (*Link, error)
+	ListTaskLinks(ctx context.Context, caller Caller, taskID uuid.UUID) ([]Link, error)
+	UnlinkTask(ctx context.Context, caller Caller, linkID uuid.UUID) error
+
+	HandleWebhook(ctx context.Context, connectionID uuid.UUID, header http.Header, body []byte) (int, error)
+}
+
+type service struct {
+	repo           Repository
+	http           *HTTPClient
+	clients        map[ProviderName]Client
+	taskService    task.Service
+	projectService project.Service
+	appURL         string
+	health         integrations.Recorder
+	logger         *zap.Logger
+}
+
+// NewService creates a new code platform integration service.
+// appURL is the web app base used in backlink comments. allowedHosts are the self-hosted
+// instances connections may set as base URL. Calls to the platforms and webhook deliveries
+// are reported to health.
+func NewService(repo Repository, taskService task.Service, projectService project.Service,
+	appURL string, allowedHosts []string, health integrations.Recorder, logger *zap.Logger) Service {
+	httpClient := NewHTTPClient(allowedHosts)
+	return &service{
+		repo: repo,
+		http: httpClient,
+		clients: map[ProviderName]Client{
+			ProviderGitHub: NewGitHubClient(httpClient),
+			ProviderGitLab: NewGitLabClient(httpClient),
+		},
+		taskService:    taskService,
+		projectService: projectService,
+		appURL:         strings.TrimRight(appURL, "/"),
+		health:         health,
+		logger:         logger,
+	}
+}
+
+// CreateConnection connects a repository to a project. The returned connection carries the
+// webhook secret to configure on the repository; it is not shown again.
+func (s *service) CreateConnection(ctx context.Context, caller Caller, input CreateConnectionInput) (*Connection, error) {
+	proj, err := s.getProject(ctx, caller, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !input.Provider.IsValid() {
+		return nil, fmt.Errorf("%w: unknown provider %q", ErrInvalidInput, input.Provider)
+	}
+
+	input.Repository = strings.Trim(strings.TrimSpace(input.Repository), "/")
+	if err := validateRepository(input.Provider, input.Repository); err != nil {
+		return nil, err
+	}
+	baseURL, err := s.normalizeBaseURL(input.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(input.AccessToken) == "" {
+		return nil, fmt.Errorf("%w: access_token is required", ErrInvalidInput)
+	}
+
+	syncStatus := string(task.TaskStatusCompleted)
+	if input.SyncStatus != nil {
+		syncStatus = *input.SyncStatus
+	}
+	if err := validateSyncStatus(syncStatus); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &Connection{
+		OrganizationID: proj.OrganizationID,
+		ProjectID:      proj.ID,
+		Provider:       input.Provider,
+		Repository:     input.Repository,
+		BaseURL:        baseURL,
+		AccessToken:    strings.TrimSpace(input.AccessToken),
+		WebhookSecret:  secret,
+		SyncStatus:     syncStatus,
+		CreatedBy:      caller.UserID,
+	}
+	if err := s.repo.CreateConnection(ctx, conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// ListConnections returns the repositories connected to a project
+func (s *service) ListConnections(ctx context.Context, caller Caller, projectID uuid.UUID) ([]Connection, error) {
+	if _, err := s.getProject(ctx, caller, projectID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListConnections(ctx, projectID)
+}
+
+// UpdateConnection replaces the access token, changes the sync status or rotates the webhook secret
+func (s *service) UpdateConnection(ctx context.Context, caller Caller, id uuid.UUID, input UpdateConnectionInput) (*Connection, error) {
+	conn, err := s.getConnection(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.AccessToken != nil {
+		token := strings.TrimSpace(*input.AccessToken)
+		if token == "" {
+			return nil, fmt.Errorf("%w: access_token cannot be empty", ErrInvalidInput)
+		}
+		conn.AccessToken = token
+	}
+	if input.SyncStatus != nil {
+		if err := validateSyncStatus(*input.SyncStatus); err != nil {
+			return nil, err
+		}
+		conn.SyncStatus = *input.SyncStatus
+	}
+	if input.RotateSecret {
+		if conn.WebhookSecret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateConnection(ctx, conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// DeleteConnection disconnects a repository and removes its task links
+func (s *service) DeleteConnection(ctx context.Context, caller Caller, id uuid.UUID) error {
+	if _, err := s.getConnection(ctx, caller, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteConnection(ctx, id)
+}
+
+// VerifyConnection checks that the connection can reach its repository, first replacing the
+// access token when one is given. A failed check is returned as integrations.ErrReconnectFailed.
+func (s *service) VerifyConnection(ctx context.Context, caller Caller, id uuid.UUID, accessToken *string) (*Connection, error) {
+	var conn *Connection
+	var err error
+	if accessToken != nil {
+		conn, err = s.UpdateConnection(ctx, caller, id, UpdateConnectionInput{AccessToken: accessToken})
+	} else {
+		conn, err = s.getConnection(ctx, caller, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.clients[conn.Provider].CheckAccess(ctx, conn)
+	s.recordCall(ctx, conn, err)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", integrations.ErrReconnectFailed, err)
+	}
+	return conn, nil
+}
+
+// LinkTask links a task to an issue or pull request of a repository connected to the task's project
+// and posts a comment pointing back to the task
+func (s *service) LinkTask(ctx context.Context, caller Caller, input LinkTaskInput) (*Link, error) {
+	t, err := s.authorizeTask(ctx, caller, input.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, kind, number, err := s.resolveItem(ctx, t, input)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.clients[conn.Provider].GetItem(ctx, conn, kind, number)
+	s.recordCall(ctx, conn, err)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &Link{
+		TaskID:       t.ID,
+		ConnectionID: conn.ID,
+		Kind:         kind,
+		Number:       number,
+		URL:          item.URL,
+		Title:        item.Title,
+		State:        item.State,
+		CreatedBy:    caller.UserID,
+	}
+	if err := s.repo.CreateLink(ctx, link); err != nil {
+		return nil, err
+	}
+
+	// The comment is posted with a copy, since the caller gets the link itself
+	go s.postBacklink(conn, *link, t)
+	return link, nil
+}
+
+// ListTaskLinks returns the issues and pull requests linked to a task
+func (s *service) ListTaskLinks(ctx context.Context, caller Caller, taskID uuid.UUID) ([]Link, error) {
+	if _, err := s.authorizeTask(ctx, caller, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTaskLinks(ctx, taskID)
+}
+
+// UnlinkTask removes a link between a task and an external item
+func (s *service) UnlinkTask(ctx context.Context, caller Caller, linkID uuid.UUID) error {
+	link, err := s.repo.FindLinkByID(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.authorizeTask(ctx, caller, link.TaskID); err != nil {
+		return err
+	}
+	return s.repo.DeleteLink(ctx, linkID)
+}
+
+// HandleWebhook applies an issue or pull request change to the linked tasks. A merged pull
+// request or a closed issue moves its tasks to the connection's sync status.
+// It returns the number of tasks whose status changed.
+func (s *service) HandleWebhook(ctx context.Context, connectionID uuid.UUID, header http.Header, body []byte) (int, error) {
+	conn, err := s.repo.FindConnectionByID(ctx, connectionID)
+	if err != nil {
+		return 0, err
+	}
+	client := s.clients[conn.Provider]
+	if err := client.VerifyWebhook(conn, header, body); err != nil {
+		s.recordCall(ctx, conn, err)
+		return 0, err
+	}
+	s.recordCall(ctx, conn, nil)
+
+	event, err := client.ParseWebhook(header, body)
+	if err != nil || event == nil {
+		return 0, err
+	}
+	if !strings.EqualFold(event.Repository, conn.Repository) {
+		return 0, ErrRepositoryMismatch
+	}
+
+	links, err := s.repo.FindLinksByItem(ctx, conn.ID, event.Kind, event.Number)
+	if err != nil {
+		return 0, err
+	}
+
+	done := event.State == StateMerged || (event.Kind == KindIssue && event.State == StateClosed)
+	synced := 0
+	for i := range links {
+		link := &links[i]
+		link.State = event.State
+		if err := s.repo.UpdateLink(ctx, link); err != nil {
+			return synced, err
+		}
+
+		if !done || conn.SyncStatus == "" {
+			continue
+		}
+		if s.syncTaskStatus(ctx, conn, link) {
+			synced++
+		}
+	}
+	return synced, nil
+}
+
+// syncTaskStatus moves a linked task to the connection's sync status, reporting whether it changed
+func (s *service) syncTaskStatus(ctx context.Context, conn *Connection, link *Link) bool {
+	status := task.TaskStatus(conn.SyncStatus)
+	t, err := s.taskService.GetTask(ctx, link.TaskID)
+	if err != nil || t.Status == status {
+		return false
+	}
+
+	if _, err := s.taskService.UpdateTaskStatus(ctx, t.ID, status); err != nil {
+		tracing.Logger(ctx, s.logger).Warn("Failed to sync task status from code platform",
+			zap.String("task_id", t.ID.String()),
+			zap.String("link_id", link.ID.String()),
+			zap.String("status", conn.SyncStatus),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// resolveItem finds the connection, kind and number an input refers to
+func (s *service) resolveItem(ctx context.Context, t *task.Task, input LinkTaskInput) (*Connection, ItemKind, int, error) {
+	if input.URL == "" {
+		if input.ConnectionID == nil || !input.Kind.IsValid() || input.Number <= 0 {
+			return nil, "", 0, fmt.Errorf("%w: provide url, or connection_id with kind and number", ErrInvalidInput)
+		}
+		conn, err := s.repo.FindConnectionByID(ctx, *input.ConnectionID)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		if conn.ProjectID != t.ProjectID {
+			return nil, "", 0, ErrConnectionNotFound
+		}
+		return conn, input.Kind, input.Number, nil
+	}
+
+	ref, err := ParseReference(input.URL)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	conns, err := s.repo.ListConnections(ctx, t.ProjectID)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	for i := range conns {
+		conn := &conns[i]
+		if input.ConnectionID != nil && conn.ID != *input.ConnectionID {
+			continue
+		}
+		if conn.Provider == ref.Provider && strings.EqualFold(conn.Repository, ref.Repository) &&
+			strings.EqualFold(webURL(conn), ref.BaseURL) {
+			return conn, ref.Kind, ref.Number, nil
+		}
+	}
+	return nil, "", 0, ErrRepositoryMismatch
+}
+
+func (s *service) postBacklink(conn *Connection, link Link, t *task.Task) {
+	ctx, cancel := context.WithTimeout(context.Background(), backlinkTimeout)
+	defer cancel()
+
+	body := fmt.Sprintf("Linked to Compass task **%s**", t.Title)
+	if s.appURL != "" {
+		body = fmt.Sprintf("Linked to Compass task [%s](%s/projects/%s/tasks/%s)", t.Title, s.appURL, t.ProjectID, t.ID)
+	}
+	err := s.clients[conn.Provider].PostComment(ctx, conn, link.Kind, link.Number, body)
+	s.recordCall(ctx, conn, err)
+	if err != nil {
+		s.logger.Warn("Failed to post backlink comment",
+			zap.String("link_id", link.ID.String()),
+			zap.String("url", link.URL),
+			zap.Error(err))
+		return
+	}
+
+	if err := s.repo.MarkBacklinkPosted(ctx, link.ID); err != nil {
+		s.logger.Error("Failed to record backlink comment", zap.String("link_id", link.ID.String()), zap.Error(err))
+	}
+}
+
+// recordCall reports the outcome of a call to the connection's platform. A missing issue or
+// pull request says nothing about the connection, so it is not recorded.
+func (s *service) recordCall(ctx context.Context, conn *Connection, err error) {
+	if s.health == nil || errors.Is(err, ErrItemNotFound) {
+		return
+	}
+	ref := integrations.Ref{ID: conn.ID, OrganizationID: conn.OrganizationID, Provider: string(conn.Provider)}
+	if err != nil {
+		s.health.RecordFailure(ctx, ref, err)
+		return
+	}
+	s.health.RecordSuccess(ctx, ref)
+}
+
+// getProject loads a project of the caller's organization, treating others as missing
+func (s *service) getProject(ctx context.Context, caller Caller, projectID uuid.UUID) (*project.Project, error) {
+	proj, err := s.projectService.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if proj.OrganizationID != caller.OrganizationID {
+		return nil, project.ErrProjectNotFound
+	}
+	return proj, nil
+}
+
+// getConnection loads a connection of the caller's organization, treating others as missing
+func (s *service) getConnection(ctx context.Context, caller Caller, id uuid.UUID) (*Connection, error) {
+	conn, err := s.repo.FindConnectionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if conn.OrganizationID != caller.OrganizationID {
+		return nil, ErrConnectionNotFound
+	}
+	return conn, nil
+}
+
+// authorizeTask allows members of the task's organization and the people working on it
+func (s *service) authorizeTask(ctx context.Context, caller Caller, taskID uuid.UUID) (*task.Task, error) {
+	t, err := s.taskService.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if t.OrganizationID == caller.OrganizationID || t.CreatorID == caller.UserID ||
+		(t.AssigneeID != nil && *t.AssigneeID == caller.UserID) {
+		return t, nil
+	}
+	return nil, ErrNotAuthorized
+}
+
+func validateRepository(provider ProviderName, repository string) error {
+	parts := strings.Split(repository, "/")
+	for _, p := range parts {
+		if p == "" || strings.ContainsAny(p, " ?#") {
+			return fmt.Errorf("%w: invalid repository %q", ErrInvalidInput, repository)
+		}
+	}
+	if provider == ProviderGitHub && len(parts) != 2 {
+		return fmt.Errorf("%w: GitHub repositories look like owner/name", ErrInvalidInput)
+	}
+	if len(parts) < 2 {
+		return fmt.Errorf("%w: GitLab repositories look like group/project", ErrInvalidInput)
+	}
+	return nil
+}
+
+func validateSyncStatus(status string) error {
+	if status != "" && !task.TaskStatus(status).IsValid() {
+		return fmt.Errorf("%w: unknown task status %q", ErrInvalidInput, status)
+	}
+	return nil
+}
+
+// normalizeBaseURL reduces a base URL to its origin, which must be an allowed self-hosted instance
+func (s *service) normalizeBaseURL(raw string) (string, error) {
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	if raw == "" {
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return "", fmt.Errorf("%w: base_url must be an https origin such as https://gitlab.example.com", ErrInvalidInput)
+	}
+	if !s.http.Allows(u.Host) {
+		return "", fmt.Errorf("%w: %s is not an allowed self-hosted instance", ErrInvalidInput, u.Host)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// webURL returns the origin that issue and pull request URLs of the connection start with
+func webURL(conn *Connection) string {
+	if conn.BaseURL != "" {
+		return conn.BaseURL
+	}
+	if conn.Provider == ProviderGitLab {
+		return gitlabWebURL
+	}
+	return githubWebURL
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// IsNotFound reports whether err means a connection, link or external item does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrConnectionNotFound) || errors.Is(err, ErrLinkNotFound) || errors.Is(err, ErrItemNotFound)
+}
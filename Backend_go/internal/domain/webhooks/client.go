@@ -0,0 +1,87 @@
+package webhooks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// blockedNetworks are internal ranges the net.IP predicates do not cover
+var blockedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// NewClient returns an HTTP client for calling user supplied URLs. It refuses to
+// connect to loopback, private, link-local and metadata addresses. The check runs on
+// the address being dialed, after DNS resolution, so a public name that resolves to an
+// internal address or a redirect to one is blocked too.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: blockInternal}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would dial the target itself, out of reach of the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// blockInternal is a net.Dialer Control hook that rejects internal addresses
+func blockInternal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isInternal(ip) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// isInternal reports whether the address must not be called. 169.254.169.254, the
+// cloud metadata address, is link-local.
+func isInternal(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHost rejects a URL host that is, or resolves to, an internal address. Names that
+// do not resolve yet are let through; deliveries are checked again when dialed.
+func checkHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isInternal(ip) {
+			return ErrBlockedAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if isInternal(addr.IP) {
+			return ErrBlockedAddress
+		}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Compass-Event"
+	HeaderDelivery  = "X-Compass-Delivery"
+	HeaderTimestamp = "X-Compass-Timestamp"
+	HeaderSignature = "X-Compass-Signature"
+)
+
+// Publisher is implemented by anything that accepts domain events for webhook delivery.
+// Publishing is best effort and never fails the change that produced the event.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Publishers fans each event out to several publishers
+type Publishers []Publisher
+
+// Publish passes the event to every publisher in order
+func (p Publishers) Publish(ctx context.Context, event Event) {
+	for _, publisher := range p {
+		publisher.Publish(ctx, event)
+	}
+}
+
+// DispatcherConfig controls delivery concurrency and the retry schedule
+type DispatcherConfig struct {
+	Workers      int
+	BatchSize    int
+	PollInterval time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+// DefaultDispatcherConfig returns the configuration used in production
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Workers:      4,
+		BatchSize:    50,
+		PollInterval: 5 * time.Second,
+		Timeout:      10 * time.Second,
+		MaxAttempts:  8,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   6 * time.Hour,
+	}
+}
+
+// Dispatcher persists published events as deliveries and sends them to
+// subscribed endpoints, retrying failures with exponential backoff
+type Dispatcher struct {
+	repo   Repository
+	client *http.Client
+	config DispatcherConfig
+	logger *zap.Logger
+
+	wake chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(repo Repository, config DispatcherConfig, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		client: NewClient(config.Timeout),
+		config: config,
+		logger: logger,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Publish queues the event for every active webhook subscribed to it
+func (d *Dispatcher) Publish(ctx context.Context, event Event) {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	subscribers, err := d.repo.FindSubscribers(ctx, event.OrganizationID, event.UserID)
+	if err != nil {
+		d.logger.Error("Failed to find webhook subscribers", zap.String("event", string(event.Type)), zap.Error(err))
+		return
+	}
+
+	var deliveries []*Delivery
+	var payload []byte
+	for _, webhook := range subscribers {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				d.logger.Error("Failed to encode webhook event", zap.String("event", string(event.Type)), zap.Error(err))
+				return
+			}
+		}
+		deliveries = append(deliveries, &Delivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       datatypes.JSON(payload),
+			Status:        DeliveryStatusPending,
+			NextAttemptAt: event.OccurredAt,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err := d.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		d.logger.Error("Failed to queue webhook deliveries", zap.String("event", string(event.Type)), zap.Error(err))
+		return
+	}
+	d.notify()
+}
+
+// Start begins sending due deliveries in the background
+func (d *Dispatcher) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			d.processDue()
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Stop waits for in-flight deliveries to finish and stops the dispatcher
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// processDue claims due deliveries and sends them using the configured number of workers
+func (d *Dispatcher) processDue() {
+	ctx := context.Background()
+	// Keep claimed deliveries away from other dispatchers for longer than one attempt can take
+	lease := 2 * d.config.Timeout
+
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, time.Now(), lease, d.config.BatchSize)
+	if err != nil {
+		d.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return
+	}
+
+	sem := make(chan struct{}, d.config.Workers)
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver makes one attempt to send the delivery and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, delivery *Delivery) {
+	webhook, err := d.repo.FindByID(ctx, delivery.WebhookID)
+	if err != nil || !webhook.Active {
+		delivery.Status = DeliveryStatusFailed
+		delivery.LastError = "webhook was removed or disabled"
+		d.save(ctx, delivery)
+		return
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := d.send(ctx, webhook, delivery)
+	if statusCode != 0 {
+		delivery.ResponseStatus = &statusCode
+	}
+
+	if sendErr == nil {
+		now := time.Now()
+		delivery.Status = DeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		d.save(ctx, delivery)
+		return
+	}
+
+	delivery.LastError = sendErr.Error()
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = DeliveryStatusFailed
+	} else {
+		delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+	}
+	d.logger.Warn("Webhook delivery failed",
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.String("webhook_id", webhook.ID.String()),
+		zap.Int("attempt", delivery.Attempts),
+		zap.Error(sendErr))
+	d.save(ctx, delivery)
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook *Webhook, delivery *Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Compass-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) save(ctx context.Context, delivery *Delivery) {
+	if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+		d.logger.Error("Failed to record webhook delivery", zap.String("delivery_id", delivery.ID.String()), zap.Error(err))
+	}
+}
+
+// backoff returns the wait before the next attempt, doubling after every failure
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// Sign computes the signature sent in the X-Compass-Signature header.
+// Receivers recompute it as HMAC-SHA256 over "<timestamp>.<body>" with the webhook secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
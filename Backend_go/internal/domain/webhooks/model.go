@@ -0,0 +1,219 @@
+package webhooks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// EventType identifies a domain event that can be delivered to webhooks
+type EventType string
+
+const (
+	EventTaskCreated               EventType = "task.created"
+	EventTaskUpdated               EventType = "task.updated"
+	EventTaskStatusChanged         EventType = "task.status_changed"
+	EventTaskAssigned              EventType = "task.assigned"
+	EventTaskDeleted               EventType = "task.deleted"
+	EventHabitCreated              EventType = "habit.created"
+	EventHabitCompleted            EventType = "habit.completed"
+	EventTodoCreated               EventType = "todo.created"
+	EventTodoCompleted             EventType = "todo.completed"
+	EventWorkflowExecutionStarted  EventType = "workflow.execution.started"
+	EventWorkflowExecutionFinished EventType = "workflow.execution.finished"
+	EventWorkflowStepTransitioned  EventType = "workflow.step.transitioned"
+
+	// EventAll subscribes a webhook to every event
+	EventAll EventType = "*"
+
+	// EventBadgeCountsChanged tells a user their badge counts changed. It is only
+	// streamed to realtime clients; webhooks cannot subscribe to it.
+	EventBadgeCountsChanged EventType = "badges.counts_changed"
+)
+
+// SupportedEvents lists the events a webhook can subscribe to
+var SupportedEvents = []EventType{
+	EventTaskCreated,
+	EventTaskUpdated,
+	EventTaskStatusChanged,
+	EventTaskAssigned,
+	EventTaskDeleted,
+	EventHabitCreated,
+	EventHabitCompleted,
+	EventTodoCreated,
+	EventTodoCompleted,
+	EventWorkflowExecutionStarted,
+	EventWorkflowExecutionFinished,
+	EventWorkflowStepTransitioned,
+}
+
+// IsValid checks if the event type can be subscribed to
+func (t EventType) IsValid() bool {
+	if t == EventAll {
+		return true
+	}
+	for _, e := range SupportedEvents {
+		if t == e {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus is the state of a single webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// IsValid checks if the delivery status is valid
+func (s DeliveryStatus) IsValid() bool {
+	switch s {
+	case DeliveryStatusPending, DeliveryStatusSucceeded, DeliveryStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidURL      = errors.New("webhook URL must be an absolute http or https URL")
+	ErrBlockedAddress  = errors.New("webhook URL must not point to a loopback, private or link-local address")
+	ErrInvalidEvent    = errors.New("unknown webhook event")
+	ErrInvalidInput    = errors.New("invalid input")
+)
+
+// Webhook is an endpoint registered to receive domain events.
+// Organization webhooks receive the organization's events; personal webhooks
+// (without an organization) receive events caused by their owner.
+type Webhook struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	OrganizationID *uuid.UUID     `json:"organization_id,omitempty" gorm:"type:uuid;index:idx_webhook_org"`
+	OwnerID        uuid.UUID      `json:"owner_id" gorm:"type:uuid;not null;index:idx_webhook_owner"`
+	URL            string         `json:"url" gorm:"type:text;not null"`
+	Secret         string         `json:"-" gorm:"type:varchar(128);not null"`
+	Events         pq.StringArray `json:"events" gorm:"type:text[]"`
+	Description    string         `json:"description,omitempty" gorm:"type:text"`
+	Active         bool           `json:"active" gorm:"not null;default:true"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// BeforeCreate is called before creating a new webhook record
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	w.CreatedAt = time.Now()
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating a webhook record
+func (w *Webhook) BeforeUpdate(tx *gorm.DB) error {
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// Subscribes reports whether the webhook wants events of the given type
+func (w *Webhook) Subscribes(eventType EventType) bool {
+	for _, e := range w.Events {
+		if EventType(e) == EventAll || EventType(e) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery records an attempt to deliver one event to one webhook
+type Delivery struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	WebhookID      uuid.UUID      `json:"webhook_id" gorm:"type:uuid;not null;index:idx_webhook_delivery_webhook"`
+	EventID        uuid.UUID      `json:"event_id" gorm:"type:uuid;not null"`
+	EventType      EventType      `json:"event_type" gorm:"type:varchar(64);not null"`
+	Payload        datatypes.JSON `json:"payload" gorm:"type:jsonb"`
+	Status         DeliveryStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_webhook_delivery_due,priority:1"`
+	Attempts       int            `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus *int           `json:"response_status,omitempty"`
+	LastError      string         `json:"last_error,omitempty" gorm:"type:text"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at" gorm:"index:idx_webhook_delivery_due,priority:2"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"not null;default:current_timestamp;index"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the Delivery model
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeCreate is called before creating a new delivery record
+func (d *Delivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.Status == "" {
+		d.Status = DeliveryStatusPending
+	}
+	d.CreatedAt = time.Now()
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate is called before updating a delivery record
+func (d *Delivery) BeforeUpdate(tx *gorm.DB) error {
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// Event is a domain event published by a service.
+// OrganizationID is uuid.Nil for personal events such as habits and todos.
+type Event struct {
+	ID             uuid.UUID   `json:"id"`
+	Type           EventType   `json:"type"`
+	OrganizationID uuid.UUID   `json:"organization_id,omitempty"`
+	UserID         uuid.UUID   `json:"user_id,omitempty"`
+	OccurredAt     time.Time   `json:"occurred_at"`
+	Data           interface{} `json:"data"`
+}
+
+// DeliveryFilter defines filtering options for the delivery log
+type DeliveryFilter struct {
+	WebhookID uuid.UUID
+	Status    *DeliveryStatus
+	Page      int
+	PageSize  int
+}
+
+// Activity summarizes the deliveries of a webhook since a point in time
+type Activity struct {
+	WebhookID      uuid.UUID  `json:"webhook_id"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	URL            string     `json:"url"`
+	Active         bool       `json:"active"`
+	Succeeded      int64      `json:"succeeded"`
+	Failed         int64      `json:"failed"`
+	Pending        int64      `json:"pending"`
+	LastDeliveryAt *time.Time `json:"last_delivery_at,omitempty"`
+}
+
+// DeliveryCount is the number of deliveries of a webhook in one status
+type DeliveryCount struct {
+	WebhookID uuid.UUID
+	Status    DeliveryStatus
+	Count     int64
+	LastAt    time.Time
+}
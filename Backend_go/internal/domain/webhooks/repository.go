@@ -0,0 +1,222 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for webhook data access
+type Repository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Webhook, error)
+	ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]Webhook, error)
+	ListPersonal(ctx context.Context, ownerID uuid.UUID) ([]Webhook, error)
+	// ListByOwner returns the personal and organization webhooks a user created
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]Webhook, error)
+	FindSubscribers(ctx context.Context, orgID, userID uuid.UUID) ([]Webhook, error)
+
+	CreateDeliveries(ctx context.Context, deliveries []*Delivery) error
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+	FindDeliveryByID(ctx context.Context, id uuid.UUID) (*Delivery, error)
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, int64, error)
+	// CountDeliveries counts the deliveries of the webhooks created since a time, by status
+	CountDeliveries(ctx context.Context, webhookIDs []uuid.UUID, since time.Time) ([]DeliveryCount, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new webhook repository
+func NewRepository(db *connection.Database) Repository {
+	return &repository{db: db.DB}
+}
+
+// Create stores a new webhook
+func (r *repository) Create(ctx context.Context, webhook *Webhook) error {
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+// Update saves changes to a webhook
+func (r *repository) Update(ctx context.Context, webhook *Webhook) error {
+	return r.db.WithContext(ctx).Save(webhook).Error
+}
+
+// Delete removes a webhook together with its delivery log
+func (r *repository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&Delivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Webhook{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+}
+
+// FindByID retrieves a webhook by its ID
+func (r *repository) FindByID(ctx context.Context, id uuid.UUID) (*Webhook, error) {
+	var webhook Webhook
+	if err := r.db.WithContext(ctx).First(&webhook, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListByOrganization returns the webhooks registered for an organization
+func (r *repository) ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("created_at ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+// ListPersonal returns the personal webhooks of a user
+func (r *repository) ListPersonal(ctx context.Context, ownerID uuid.UUID) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.WithContext(ctx).
+		Where("organization_id IS NULL AND owner_id = ?", ownerID).
+		Order("created_at ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *repository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ?", ownerID).
+		Order("created_at ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+// FindSubscribers returns the active webhooks that may receive an event from the
+// given organization or user. Event type filtering is done by the caller.
+func (r *repository) FindSubscribers(ctx context.Context, orgID, userID uuid.UUID) ([]Webhook, error) {
+	var webhooks []Webhook
+	query := r.db.WithContext(ctx).Where("active = ?", true)
+
+	switch {
+	case orgID != uuid.Nil && userID != uuid.Nil:
+		query = query.Where("organization_id = ? OR (organization_id IS NULL AND owner_id = ?)", orgID, userID)
+	case orgID != uuid.Nil:
+		query = query.Where("organization_id = ?", orgID)
+	case userID != uuid.Nil:
+		query = query.Where("organization_id IS NULL AND owner_id = ?", userID)
+	default:
+		return nil, nil
+	}
+
+	err := query.Find(&webhooks).Error
+	return webhooks, err
+}
+
+// CreateDeliveries stores new pending deliveries
+func (r *repository) CreateDeliveries(ctx context.Context, deliveries []*Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&deliveries).Error
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt
+func (r *repository) UpdateDelivery(ctx context.Context, delivery *Delivery) error {
+	return r.db.WithContext(ctx).Save(delivery).Error
+}
+
+// FindDeliveryByID retrieves a delivery by its ID
+func (r *repository) FindDeliveryByID(ctx context.Context, id uuid.UUID) (*Delivery, error) {
+	var delivery Delivery
+	if err := r.db.WithContext(ctx).First(&delivery, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first
+func (r *repository) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, int64, error) {
+	var deliveries []Delivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Delivery{}).Where("webhook_id = ?", filter.WebhookID)
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Offset(filter.Page * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// ClaimDueDeliveries locks pending deliveries that are due and pushes their next
+// attempt out by the lease, so concurrent dispatchers do not send them twice
+func (r *repository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	var deliveries []Delivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", DeliveryStatusPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+		}
+		return tx.Model(&Delivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *repository) CountDeliveries(ctx context.Context, webhookIDs []uuid.UUID, since time.Time) ([]DeliveryCount, error) {
+	var counts []DeliveryCount
+	if len(webhookIDs) == 0 {
+		return counts, nil
+	}
+	err := r.db.WithContext(ctx).Model(&Delivery{}).
+		Select("webhook_id, status, COUNT(*) AS count, MAX(created_at) AS last_at").
+		Where("webhook_id IN ? AND created_at >= ?", webhookIDs, since).
+		Group("webhook_id, status").
+		Scan(&counts).Error
+	return counts, err
+}
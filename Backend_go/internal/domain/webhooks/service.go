@@ -0,0 +1,291 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateWebhookInput is the input for registering a webhook
+type CreateWebhookInput struct {
+	OrganizationID *uuid.UUID
+	OwnerID        uuid.UUID
+	URL            string
+	Events         []string
+	Description    string
+}
+
+// UpdateWebhookInput is the input for changing a webhook
+type UpdateWebhookInput struct {
+	URL          *string
+	Events       []string
+	Description  *string
+	Active       *bool
+	RotateSecret bool
+}
+
+// Service defines the interface for webhook management. An orgID selects the webhooks
+// of that organization; the caller checks the user's permission to manage them. A nil
+// orgID selects the user's personal webhooks.
+type Service interface {
+	CreateWebhook(ctx context.Context, input CreateWebhookInput) (*Webhook, error)
+	GetWebhook(ctx context.Context, id, userID uuid.UUID, orgID *uuid.UUID) (*Webhook, error)
+	ListWebhooks(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID) ([]Webhook, error)
+	UpdateWebhook(ctx context.Context, id, userID uuid.UUID, orgID *uuid.UUID, input UpdateWebhookInput) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, id, userID uuid.UUID, orgID *uuid.UUID) error
+	ListDeliveries(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID, filter DeliveryFilter) ([]Delivery, int64, error)
+	RetryDelivery(ctx context.Context, webhookID, deliveryID, userID uuid.UUID, orgID *uuid.UUID) (*Delivery, error)
+	// Activity summarizes the deliveries since a time of every webhook the user created
+	Activity(ctx context.Context, userID uuid.UUID, since time.Time) ([]Activity, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService creates a new webhook service instance
+func NewService(repo Repository) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// CreateWebhook registers a new endpoint and generates its signing secret
+func (s *service) CreateWebhook(ctx context.Context, input CreateWebhookInput) (*Webhook, error) {
+	if input.OwnerID == uuid.Nil {
+		return nil, ErrInvalidInput
+	}
+	if err := validateURL(ctx, input.URL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeEvents(input.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &Webhook{
+		OrganizationID: input.OrganizationID,
+		OwnerID:        input.OwnerID,
+		URL:            strings.TrimSpace(input.URL),
+		Secret:         secret,
+		Events:         events,
+		Description:    input.Description,
+		Active:         true,
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// GetWebhook returns a webhook of the organization, or a personal webhook of the user
+func (s *service) GetWebhook(ctx context.Context, id, userID uuid.UUID, orgID *uuid.UUID) (*Webhook, error) {
+	webhook, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !inScope(webhook, userID, orgID) {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// ListWebhooks returns the organization's webhooks, or the user's personal ones when no organization is given
+func (s *service) ListWebhooks(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID) ([]Webhook, error) {
+	if orgID == nil {
+		return s.repo.ListPersonal(ctx, userID)
+	}
+	return s.repo.ListByOrganization(ctx, *orgID)
+}
+
+// UpdateWebhook changes the endpoint, subscriptions or state of a webhook
+func (s *service) UpdateWebhook(ctx context.Context, id, userID uuid.UUID, orgID *uuid.UUID, input UpdateWebhookInput) (*Webhook, error) {
+	webhook, err := s.GetWebhook(ctx, id, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.URL != nil {
+		if err := validateURL(ctx, *input.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = strings.TrimSpace(*input.URL)
+	}
+	if input.Events != nil {
+		events, err := normalizeEvents(input.Events)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = events
+	}
+	if input.Description != nil {
+		webhook.Description = *input.Description
+	}
+	if input.Active != nil {
+		webhook.Active = *input.Active
+	}
+	if input.RotateSecret {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+	}
+
+	if err := s.repo.Update(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (s *service) DeleteWebhook(ctx context.Context, id, userID uuid.UUID, orgID *uuid.UUID) error {
+	if _, err := s.GetWebhook(ctx, id, userID, orgID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// ListDeliveries returns the delivery log of a webhook
+func (s *service) ListDeliveries(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID, filter DeliveryFilter) ([]Delivery, int64, error) {
+	if _, err := s.GetWebhook(ctx, filter.WebhookID, userID, orgID); err != nil {
+		return nil, 0, err
+	}
+	if filter.Status != nil && !filter.Status.IsValid() {
+		return nil, 0, ErrInvalidInput
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	if filter.Page < 0 {
+		filter.Page = 0
+	}
+	return s.repo.ListDeliveries(ctx, filter)
+}
+
+// RetryDelivery schedules a failed delivery to be sent again immediately
+func (s *service) RetryDelivery(ctx context.Context, webhookID, deliveryID, userID uuid.UUID, orgID *uuid.UUID) (*Delivery, error) {
+	if _, err := s.GetWebhook(ctx, webhookID, userID, orgID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.repo.FindDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.WebhookID != webhookID {
+		return nil, ErrWebhookNotFound
+	}
+	if delivery.Status == DeliveryStatusSucceeded {
+		return nil, fmt.Errorf("%w: delivery already succeeded", ErrInvalidInput)
+	}
+
+	delivery.Status = DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// inScope reports whether the webhook belongs to the organization, or with no
+// organization, whether it is a personal webhook of the user
+func inScope(webhook *Webhook, userID uuid.UUID, orgID *uuid.UUID) bool {
+	if orgID != nil {
+		return webhook.OrganizationID != nil && *webhook.OrganizationID == *orgID
+	}
+	return webhook.OrganizationID == nil && webhook.OwnerID == userID
+}
+
+// validateURL accepts absolute http and https URLs outside the internal network
+func validateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	return checkHost(ctx, u.Hostname())
+}
+
+// normalizeEvents validates the subscribed events and removes duplicates
+func normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: at least one event is required", ErrInvalidEvent)
+	}
+	seen := make(map[string]struct{}, len(events))
+	normalized := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if !EventType(e).IsValid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidEvent, e)
+		}
+		if _, ok := seen[e]; ok {
+			continue
+		}
+		seen[e] = struct{}{}
+		normalized = append(normalized, e)
+	}
+	return normalized, nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func (s *service) Activity(ctx context.Context, userID uuid.UUID, since time.Time) ([]Activity, error) {
+	webhooks, err := s.repo.ListByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	activity := make([]Activity, len(webhooks))
+	index := make(map[uuid.UUID]int, len(webhooks))
+	ids := make([]uuid.UUID, len(webhooks))
+	for i, webhook := range webhooks {
+		activity[i] = Activity{
+			WebhookID:      webhook.ID,
+			OrganizationID: webhook.OrganizationID,
+			URL:            webhook.URL,
+			Active:         webhook.Active,
+		}
+		index[webhook.ID] = i
+		ids[i] = webhook.ID
+	}
+
+	counts, err := s.repo.CountDeliveries(ctx, ids, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		a := &activity[index[count.WebhookID]]
+		switch count.Status {
+		case DeliveryStatusSucceeded:
+			a.Succeeded += count.Count
+		case DeliveryStatusFailed:
+			a.Failed += count.Count
+		default:
+			a.Pending += count.Count
+		}
+		if a.LastDeliveryAt == nil || count.LastAt.After(*a.LastDeliveryAt) {
+			lastAt := count.LastAt
+			a.LastDeliveryAt = &lastAt
+		}
+	}
+	return activity, nil
+}
@@ -0,0 +1,146 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// Work item actions an action step can run
+const (
+	ActionCreateTask  = "create_task"
+	ActionUpdateTask  = "update_task"
+	ActionCreateTodo  = "create_todo"
+	ActionUpdateTodo  = "update_todo"
+	ActionCreateEvent = "create_event"
+	ActionUpdateEvent = "update_event"
+)
+
+var (
+	ErrNoWorkItems   = errors.New("work item actions are not available")
+	ErrInvalidAction = errors.New("invalid work item action")
+)
+
+// WorkItemRequest asks for one task, todo or calendar event to be created or updated.
+// Fields holds the rendered item definition in the JSON shape of the item's create or
+// update input.
+type WorkItemRequest struct {
+	Action         string
+	ID             uuid.UUID
+	Fields         map[string]interface{}
+	OrganizationID uuid.UUID
+	// ActorID is the user the work is done for, the creator of the workflow
+	ActorID uuid.UUID
+}
+
+// WorkItems creates and updates the tasks, todos and events defined by action steps
+type WorkItems interface {
+	Apply(ctx context.Context, req WorkItemRequest) (uuid.UUID, error)
+}
+
+// ActionDefinition is one entry of an action step's "actions" config. ID and Fields may
+// reference the payload, e.g. "$.steps.Create ticket.task_id", and strings in Fields may
+// embed references as "{{$.input.customer}}".
+type ActionDefinition struct {
+	Type string `json:"type"`
+	// Name keys the item's ID in the step output. It defaults to the action type.
+	Name   string                 `json:"name,omitempty"`
+	ID     string                 `json:"id,omitempty"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// WithWorkItems sets the service action steps create and update work items with
+func (e *DefaultWorkflowExecutor) WithWorkItems(items WorkItems) *DefaultWorkflowExecutor {
+	e.workItems = items
+	return e
+}
+
+// executeActionStep runs the work item actions configured on the step in order. Its
+// output maps each action's name to the ID of the item it created or updated, so later
+// steps can refer to them.
+func (e *DefaultWorkflowExecutor) executeActionStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	e.traceLogger(ctx).Info("Executing action step", zap.String("step_id", step.ID.String()))
+
+	if e.workItems == nil {
+		return ErrNoWorkItems
+	}
+	var config struct {
+		Actions []ActionDefinition `json:"actions"`
+	}
+	if len(step.Config) > 0 {
+		if err := json.Unmarshal(step.Config, &config); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAction, err)
+		}
+	}
+	if len(config.Actions) == 0 {
+		return fmt.Errorf("%w: step has no actions", ErrInvalidAction)
+	}
+
+	workflow, err := e.workflowOf(ctx, execution.ExecutionID)
+	if err != nil {
+		return err
+	}
+	doc, err := payloadDocument(payload)
+	if err != nil {
+		return err
+	}
+
+	output := make(map[string]interface{}, len(config.Actions))
+	for i, action := range config.Actions {
+		req := WorkItemRequest{
+			Action:         action.Type,
+			OrganizationID: workflow.OrganizationID,
+			ActorID:        workflow.CreatedBy,
+		}
+		if fields, ok := render(action.Fields, doc).(map[string]interface{}); ok {
+			req.Fields = fields
+		}
+		if action.ID != "" {
+			id, err := uuid.Parse(fmt.Sprint(render(action.ID, doc)))
+			if err != nil {
+				return fmt.Errorf("%w: action %d has no valid id", ErrInvalidAction, i+1)
+			}
+			req.ID = id
+		}
+
+		id, err := e.workItems.Apply(ctx, req)
+		if err != nil {
+			return fmt.Errorf("action %d (%s) failed: %w", i+1, action.Type, err)
+		}
+		e.traceLogger(ctx).Info("Applied work item action",
+			zap.String("step_id", step.ID.String()),
+			zap.String("action", action.Type),
+			zap.String("item_id", id.String()))
+
+		name := action.Name
+		if name == "" {
+			name = action.Type
+		}
+		output[name] = id.String()
+	}
+
+	outputJSON, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to encode step output: %w", err)
+	}
+	execution.Output = datatypes.JSON(outputJSON)
+	return nil
+}
+
+// workflowOf returns the workflow an execution belongs to
+func (e *DefaultWorkflowExecutor) workflowOf(ctx context.Context, executionID uuid.UUID) (*Workflow, error) {
+	execution, err := e.repo.GetExecutionByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow execution: %w", err)
+	}
+	workflow, err := e.repo.GetByID(ctx, execution.WorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	return workflow, nil
+}
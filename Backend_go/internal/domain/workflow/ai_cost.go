@@ -0,0 +1,273 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// AIRunner makes the model call of an AI step
+type AIRunner interface {
+	// RunAIStep returns the step output and the usage of the call. A result returned
+	// along with an error still has its usage recorded.
+	RunAIStep(ctx context.Context, req AIStepRequest) (*AIStepResult, error)
+}
+
+// AIStepRequest is an AI step to run for an organization
+type AIStepRequest struct {
+	OrganizationID uuid.UUID
+	Step           *WorkflowStep
+	Payload        *StepPayload
+}
+
+// AIStepResult is the outcome of an AI step
+type AIStepResult struct {
+	Output interface{}
+	Usage  AIUsage
+}
+
+// WithAI sets the runner that makes the model calls of AI steps. Without one AI steps
+// are simulated and cost nothing.
+func (e *DefaultWorkflowExecutor) WithAI(runner AIRunner) *DefaultWorkflowExecutor {
+	e.ai = runner
+	return e
+}
+
+// runAIStep makes the model call of an AI step and records its usage, both for cost
+// reports and in the step execution result
+func (e *DefaultWorkflowExecutor) runAIStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	workflow, err := e.workflowOf(ctx, execution.ExecutionID)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	result, err := e.ai.RunAIStep(ctx, AIStepRequest{
+		OrganizationID: workflow.OrganizationID,
+		Step:           step,
+		Payload:        payload,
+	})
+	if result != nil {
+		usage := result.Usage
+		if usage.LatencyMs == 0 {
+			usage.LatencyMs = time.Since(started).Milliseconds()
+		}
+		usage = usage.priced()
+
+		record := &AIUsageRecord{
+			OrganizationID:  workflow.OrganizationID,
+			WorkflowID:      workflow.ID,
+			ExecutionID:     execution.ExecutionID,
+			StepID:          step.ID,
+			StepExecutionID: execution.ID,
+			Provider:        usage.Provider,
+			Model:           usage.Model,
+			InputTokens:     usage.InputTokens,
+			OutputTokens:    usage.OutputTokens,
+			LatencyMs:       usage.LatencyMs,
+			CostUSD:         usage.CostUSD,
+		}
+		if recordErr := e.repo.CreateAIUsage(ctx, record); recordErr != nil {
+			e.traceLogger(ctx).Error("Failed to record AI usage",
+				zap.Error(recordErr),
+				zap.String("step_id", step.ID.String()))
+		}
+		execution.Result = withAIUsage(execution.Result, usage)
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.Output != nil {
+		output, err := json.Marshal(result.Output)
+		if err != nil {
+			return fmt.Errorf("failed to encode step output: %w", err)
+		}
+		execution.Output = datatypes.JSON(output)
+	}
+	return nil
+}
+
+// withAIUsage adds usage to the "ai_usage" entry of a step execution result, so retried
+// steps report the usage of all their attempts
+func withAIUsage(result datatypes.JSON, usage AIUsage) datatypes.JSON {
+	doc := make(map[string]interface{})
+	if len(result) > 0 {
+		_ = json.Unmarshal(result, &doc)
+	}
+	var total AIUsage
+	if previous, ok := doc["ai_usage"]; ok {
+		raw, _ := json.Marshal(previous)
+		_ = json.Unmarshal(raw, &total)
+	}
+	doc["ai_usage"] = total.add(usage)
+	encoded, _ := json.Marshal(doc)
+	return datatypes.JSON(encoded)
+}
+
+// aiBudgetExceeded reports whether a budget covering the execution's workflow has been
+// spent this month
+func (e *DefaultWorkflowExecutor) aiBudgetExceeded(ctx context.Context, execution *WorkflowStepExecution) (bool, error) {
+	if e.ai == nil {
+		return false, nil
+	}
+	workflow, err := e.workflowOf(ctx, execution.ExecutionID)
+	if err != nil {
+		return false, err
+	}
+	statuses, err := aiBudgetStatuses(ctx, e.repo, workflow.OrganizationID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	for _, status := range statuses {
+		if status.Exceeded && (status.WorkflowID == nil || *status.WorkflowID == workflow.ID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// aiBudgetStatuses returns the organization's budgets with what was spent against them
+// in the calendar month of now
+func aiBudgetStatuses(ctx context.Context, repo Repository, orgID uuid.UUID, now time.Time) ([]AIBudgetStatus, error) {
+	budgets, err := repo.ListAIBudgets(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AI budgets: %w", err)
+	}
+	if len(budgets) == 0 {
+		return []AIBudgetStatus{}, nil
+	}
+
+	now = now.UTC()
+	totals, err := repo.SumAIUsage(ctx, AIUsageFilter{
+		OrganizationID: orgID,
+		From:           time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		To:             now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum AI usage: %w", err)
+	}
+
+	statuses := make([]AIBudgetStatus, len(budgets))
+	for i, budget := range budgets {
+		status := AIBudgetStatus{AIBudget: budget}
+		for _, total := range totals {
+			if budget.WorkflowID == nil || *budget.WorkflowID == total.WorkflowID {
+				status.SpentUSD += total.CostUSD
+			}
+		}
+		status.Exceeded = status.SpentUSD >= budget.MonthlyLimitUSD
+		statuses[i] = status
+	}
+	return statuses, nil
+}
+
+// GetAICostReport rolls up the AI usage of an organization, or of one of its workflows,
+// between from and to, along with the state of the organization's budgets
+func (s *service) GetAICostReport(ctx context.Context, orgID uuid.UUID, workflowID *uuid.UUID, from, to time.Time) (*AICostReport, error) {
+	totals, err := s.repo.SumAIUsage(ctx, AIUsageFilter{
+		OrganizationID: orgID,
+		WorkflowID:     workflowID,
+		From:           from,
+		To:             to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum AI usage: %w", err)
+	}
+	statuses, err := aiBudgetStatuses(ctx, s.repo, orgID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AICostReport{From: from, To: to, Usage: totals, Budgets: statuses}
+	if workflowID != nil {
+		report.Budgets = report.Budgets[:0]
+		for _, status := range statuses {
+			if status.WorkflowID == nil || *status.WorkflowID == *workflowID {
+				report.Budgets = append(report.Budgets, status)
+			}
+		}
+	}
+	for _, total := range totals {
+		report.Calls += total.Calls
+		report.InputTokens += total.InputTokens
+		report.OutputTokens += total.OutputTokens
+		report.CostUSD += total.CostUSD
+	}
+	return report, nil
+}
+
+// SetAIBudget sets or removes an AI budget of the organization. AI steps paused by a
+// budget are resumed in the background, and pause again if another budget is still spent.
+func (s *service) SetAIBudget(ctx context.Context, orgID uuid.UUID, req SetAIBudgetRequest) (*AIBudget, error) {
+	if req.WorkflowID != nil {
+		workflow, err := s.repo.GetByID(ctx, *req.WorkflowID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workflow: %w", err)
+		}
+		if workflow.OrganizationID != orgID {
+			return nil, fmt.Errorf("%w: workflow belongs to another organization", ErrInvalidAIBudget)
+		}
+	}
+
+	var budget *AIBudget
+	if req.MonthlyLimitUSD == nil {
+		if err := s.repo.DeleteAIBudget(ctx, orgID, req.WorkflowID); err != nil {
+			return nil, fmt.Errorf("failed to remove AI budget: %w", err)
+		}
+	} else {
+		if *req.MonthlyLimitUSD < 0 {
+			return nil, fmt.Errorf("%w: the monthly limit cannot be negative", ErrInvalidAIBudget)
+		}
+		budget = &AIBudget{
+			OrganizationID:  orgID,
+			WorkflowID:      req.WorkflowID,
+			MonthlyLimitUSD: *req.MonthlyLimitUSD,
+		}
+		if err := s.repo.SaveAIBudget(ctx, budget); err != nil {
+			return nil, fmt.Errorf("failed to save AI budget: %w", err)
+		}
+	}
+
+	spawn(s.background, ctx, "resume paused AI steps", func(ctx context.Context) {
+		s.resumePausedAISteps(ctx, orgID)
+	})
+	return budget, nil
+}
+
+// resumePausedAISteps runs the organization's paused AI steps again
+func (s *service) resumePausedAISteps(ctx context.Context, orgID uuid.UUID) {
+	if s.executor == nil {
+		return
+	}
+	paused, err := s.repo.ListPausedStepExecutions(ctx, orgID)
+	if err != nil {
+		s.traceLogger(ctx).Error("Failed to list paused AI steps",
+			zap.Error(err),
+			zap.String("organization_id", orgID.String()))
+		return
+	}
+	for i := range paused {
+		execution := &paused[i]
+		step, err := s.repo.GetStepByID(ctx, execution.StepID)
+		if err != nil {
+			s.traceLogger(ctx).Warn("Failed to get paused step",
+				zap.Error(err),
+				zap.String("step_id", execution.StepID.String()))
+			continue
+		}
+		execution.Status = StepStatusActive
+		execution.Error = nil
+		if err := s.executor.ExecuteStep(ctx, step, execution); err != nil {
+			s.traceLogger(ctx).Warn("Resumed AI step failed",
+				zap.Error(err),
+				zap.String("step_id", step.ID.String()),
+				zap.String("step_execution_id", execution.ID.String()))
+		}
+	}
+}
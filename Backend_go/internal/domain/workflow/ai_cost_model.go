@@ -0,0 +1,170 @@
+package workflow
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAIBudgetExceeded is recorded on AI steps paused because a budget is spent
+	ErrAIBudgetExceeded = errors.New("AI budget exceeded")
+	ErrInvalidAIBudget  = errors.New("invalid AI budget")
+)
+
+// AIUsage is what one model call of an AI step consumed
+type AIUsage struct {
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	LatencyMs    int64   `json:"latency_ms"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// AIPrices holds the prices of known models keyed by "provider/model". A key also prices
+// the dated versions of its model, such as "gpt-4o-2024-08-06". Calls to other models
+// keep the cost reported by the AI runner, which is zero for local models.
+var AIPrices = map[string]ModelPrice{
+	"openai/gpt-4o":                 {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+	"openai/gpt-4o-mini":            {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"anthropic/claude-3-5-sonnet":   {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"anthropic/claude-3-5-haiku":    {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+	"openai/text-embedding-3-small": {InputPerMillion: 0.02},
+}
+
+// priced fills in the cost of the usage from AIPrices when the model is listed
+func (u AIUsage) priced() AIUsage {
+	name := u.Provider + "/" + u.Model
+	var matched string
+	for key := range AIPrices {
+		if strings.HasPrefix(name, key) && len(key) > len(matched) {
+			matched = key
+		}
+	}
+	if matched != "" {
+		price := AIPrices[matched]
+		u.CostUSD = (float64(u.InputTokens)*price.InputPerMillion + float64(u.OutputTokens)*price.OutputPerMillion) / 1e6
+	}
+	return u
+}
+
+// add returns the combined usage of two calls, such as two attempts of one step
+func (u AIUsage) add(other AIUsage) AIUsage {
+	if u.Provider == "" {
+		u.Provider, u.Model = other.Provider, other.Model
+	}
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.LatencyMs += other.LatencyMs
+	u.CostUSD += other.CostUSD
+	return u
+}
+
+// AIUsageRecord stores the usage of one attempt of an AI step for cost reports and budgets
+type AIUsageRecord struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	OrganizationID  uuid.UUID `json:"organization_id" gorm:"type:uuid;not null;index:idx_workflow_ai_usage_org,priority:1"`
+	WorkflowID      uuid.UUID `json:"workflow_id" gorm:"type:uuid;not null;index"`
+	ExecutionID     uuid.UUID `json:"execution_id" gorm:"type:uuid;not null"`
+	StepID          uuid.UUID `json:"step_id" gorm:"type:uuid;not null"`
+	StepExecutionID uuid.UUID `json:"step_execution_id" gorm:"type:uuid;not null"`
+	Provider        string    `json:"provider" gorm:"type:varchar(100)"`
+	Model           string    `json:"model" gorm:"type:varchar(100)"`
+	InputTokens     int       `json:"input_tokens" gorm:"not null;default:0"`
+	OutputTokens    int       `json:"output_tokens" gorm:"not null;default:0"`
+	LatencyMs       int64     `json:"latency_ms" gorm:"not null;default:0"`
+	CostUSD         float64   `json:"cost_usd" gorm:"type:numeric(14,6);not null;default:0"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null;default:current_timestamp;index:idx_workflow_ai_usage_org,priority:2"`
+}
+
+// AIBudget caps the AI spend of an organization per calendar month in UTC. A budget with
+// a workflow only counts and pauses that workflow's AI steps.
+type AIBudget struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	OrganizationID  uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null;index"`
+	WorkflowID      *uuid.UUID `json:"workflow_id,omitempty" gorm:"type:uuid"`
+	MonthlyLimitUSD float64    `json:"monthly_limit_usd" gorm:"type:numeric(14,6);not null"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"not null;default:current_timestamp"`
+}
+
+// SetAIBudgetRequest sets or, with a null limit, removes the organization's budget or
+// the budget of one of its workflows
+type SetAIBudgetRequest struct {
+	WorkflowID      *uuid.UUID `json:"workflow_id,omitempty"`
+	MonthlyLimitUSD *float64   `json:"monthly_limit_usd"`
+}
+
+// AIUsageFilter selects AI usage of an organization between From and To
+type AIUsageFilter struct {
+	OrganizationID uuid.UUID
+	WorkflowID     *uuid.UUID
+	From           time.Time
+	To             time.Time
+}
+
+// AIUsageTotal is the AI usage of a workflow with one provider and model
+type AIUsageTotal struct {
+	WorkflowID   uuid.UUID `json:"workflow_id"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	Calls        int64     `json:"calls"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	LatencyMs    int64     `json:"latency_ms"`
+	CostUSD      float64   `json:"cost_usd"`
+}
+
+// AIBudgetStatus is a budget with what has been spent against it this month
+type AIBudgetStatus struct {
+	AIBudget
+	SpentUSD float64 `json:"spent_usd"`
+	Exceeded bool    `json:"exceeded"`
+}
+
+// AICostReport rolls up the AI usage of an organization, or of one workflow
+type AICostReport struct {
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Calls        int64            `json:"calls"`
+	InputTokens  int64            `json:"input_tokens"`
+	OutputTokens int64            `json:"output_tokens"`
+	CostUSD      float64          `json:"cost_usd"`
+	Usage        []AIUsageTotal   `json:"usage"`
+	Budgets      []AIBudgetStatus `json:"budgets"`
+}
+
+func (AIUsageRecord) TableName() string {
+	return "workflow_ai_usage"
+}
+
+func (AIBudget) TableName() string {
+	return "workflow_ai_budgets"
+}
+
+func (r *AIUsageRecord) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+func (b *AIBudget) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
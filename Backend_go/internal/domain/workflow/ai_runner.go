@@ -0,0 +1,111 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/providers"
+)
+
+// aiStepConfig is the config of an AI step. Prompt and System are templates rendered
+// against the step payload, e.g. "Summarize the ticket {{$.input.ticket}}". With
+// OutputFormat "json" the reply is parsed as JSON and becomes the step output.
+type aiStepConfig struct {
+	Prompt       interface{} `json:"prompt"`
+	System       string      `json:"system"`
+	Model        string      `json:"model"`
+	MaxTokens    int         `json:"max_tokens"`
+	Temperature  *float64    `json:"temperature"`
+	OutputFormat string      `json:"output_format"`
+}
+
+// llmRunner runs AI steps with the LLM provider of the workflow's organization
+type llmRunner struct {
+	providers *providers.Resolver
+}
+
+// NewLLMRunner creates an AI runner that sends AI steps to the organization's LLM provider
+func NewLLMRunner(resolver *providers.Resolver) AIRunner {
+	return &llmRunner{providers: resolver}
+}
+
+// RunAIStep sends the step's rendered prompt to the provider. Without a prompt the step
+// description is sent along with the payload.
+func (r *llmRunner) RunAIStep(ctx context.Context, req AIStepRequest) (*AIStepResult, error) {
+	var config aiStepConfig
+	if len(req.Step.Config) > 0 {
+		if err := json.Unmarshal(req.Step.Config, &config); err != nil {
+			return nil, fmt.Errorf("invalid AI step config: %w", err)
+		}
+	}
+	doc, err := payloadDocument(req.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := promptText(render(config.Prompt, doc))
+	if prompt == "" {
+		payload, _ := json.Marshal(doc)
+		prompt = strings.TrimSpace(req.Step.Description + "\n\n" + string(payload))
+	}
+	system, _ := render(config.System, doc).(string)
+
+	provider, err := r.providers.For(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	completion, err := provider.Complete(ctx, providers.CompletionRequest{
+		Model:       config.Model,
+		System:      system,
+		Messages:    []providers.Message{{Role: "user", Content: prompt}},
+		MaxTokens:   config.MaxTokens,
+		Temperature: config.Temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AIStepResult{
+		Output: map[string]interface{}{"text": completion.Text},
+		Usage: AIUsage{
+			Provider:     provider.Name(),
+			Model:        completion.Model,
+			InputTokens:  completion.Usage.InputTokens,
+			OutputTokens: completion.Usage.OutputTokens,
+		},
+	}
+	if config.OutputFormat == "json" {
+		var output interface{}
+		if err := json.Unmarshal([]byte(jsonBody(completion.Text)), &output); err != nil {
+			return result, fmt.Errorf("AI step reply is not valid JSON: %w", err)
+		}
+		result.Output = output
+	}
+	return result, nil
+}
+
+// promptText turns a rendered prompt template into text, encoding anything but a string as JSON
+func promptText(prompt interface{}) string {
+	switch p := prompt.(type) {
+	case nil:
+		return ""
+	case string:
+		return p
+	default:
+		encoded, _ := json.Marshal(p)
+		return string(encoded)
+	}
+}
+
+// jsonBody strips the Markdown code fence models often wrap JSON replies in
+func jsonBody(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimPrefix(text, "json")
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/background"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// spawn runs fn after the request that started it. In a group, shutdown waits for it;
+// without one it runs in a goroutine of its own. It reports false once shutdown began.
+func spawn(group *background.Group, ctx context.Context, name string, fn func(ctx context.Context)) bool {
+	if group == nil {
+		go fn(tracing.Detach(ctx))
+		return true
+	}
+	return group.Go(ctx, name, fn)
+}
+
+// interruptStep records that shutdown stopped a step execution before it finished, so
+// the next start runs it again. ctx may be cancelled already, so the write ignores that.
+func interruptStep(ctx context.Context, repo Repository, logger *zap.Logger, execution *WorkflowStepExecution) {
+	execution.Status = StepStatusInterrupted
+	errStr := background.ErrShutdown.Error()
+	execution.Error = &errStr
+	execution.NextRetryAt = nil
+	execution.UpdatedAt = time.Now()
+	if err := repo.UpdateStepExecution(context.WithoutCancel(ctx), execution); err != nil {
+		tracing.Logger(ctx, logger).Error("Failed to record interrupted step execution",
+			zap.Error(err),
+			zap.String("step_execution_id", execution.ID.String()))
+	}
+}
+
+// ResumeInterrupted runs the step executions the last shutdown interrupted again
+func (s *service) ResumeInterrupted(ctx context.Context) (int, error) {
+	if s.executor == nil {
+		return 0, nil
+	}
+	interrupted, err := s.repo.ListInterruptedStepExecutions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	resumed := 0
+	for i := range interrupted {
+		execution := &interrupted[i]
+		step, err := s.repo.GetStepByID(ctx, execution.StepID)
+		if err != nil {
+			s.traceLogger(ctx).Warn("Failed to get interrupted step",
+				zap.Error(err),
+				zap.String("step_id", execution.StepID.String()))
+			continue
+		}
+		execution.Status = StepStatusActive
+		execution.Error = nil
+		started := spawn(s.background, ctx, "resume workflow step", func(ctx context.Context) {
+			if err := s.executor.ExecuteStep(ctx, step, execution); err != nil {
+				s.traceLogger(ctx).Warn("Resumed step failed",
+					zap.Error(err),
+					zap.String("step_id", step.ID.String()),
+					zap.String("step_execution_id", execution.ID.String()))
+			}
+		})
+		if !started {
+			break
+		}
+		resumed++
+	}
+	return resumed, nil
+}
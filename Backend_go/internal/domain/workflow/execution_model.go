@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,7 +21,16 @@ type WorkflowStepExecution struct {
 	UpdatedAt         time.Time      `json:"updated_at" gorm:"not null;default:current_timestamp"`
 	CompletedAt       *time.Time     `json:"completed_at"`
 	Result            datatypes.JSON `json:"result" gorm:"type:jsonb"`
+	Output            datatypes.JSON `json:"output" gorm:"type:jsonb"`
 	Error             *string        `json:"error"`
+	Attempts          int            `json:"attempts" gorm:"not null;default:0"`
+	NextRetryAt       *time.Time     `json:"next_retry_at"`
+	AttemptHistory    datatypes.JSON `json:"attempt_history" gorm:"type:jsonb"`
+	// DecidedBy, DecidedAt and DecisionComment record who approved or rejected an
+	// approval step, when, and why
+	DecidedBy       *uuid.UUID `json:"decided_by,omitempty" gorm:"type:uuid"`
+	DecidedAt       *time.Time `json:"decided_at,omitempty"`
+	DecisionComment string     `json:"decision_comment,omitempty" gorm:"type:text"`
 }
 
 // WorkflowExecution represents the execution of a workflow
@@ -30,6 +40,7 @@ type WorkflowExecution struct {
 	Status            WorkflowStatus `json:"status" gorm:"type:varchar(50);not null;default:'pending'"`
 	ExecutionPriority int            `json:"execution_priority" gorm:"default:0"`
 	ExecutionMetadata datatypes.JSON `json:"execution_metadata" gorm:"type:jsonb"`
+	Input             datatypes.JSON `json:"input" gorm:"type:jsonb"`
 	StartedAt         time.Time      `json:"started_at" gorm:"not null;default:current_timestamp"`
 	UpdatedAt         time.Time      `json:"updated_at" gorm:"not null;default:current_timestamp"`
 	CompletedAt       *time.Time     `json:"completed_at"`
@@ -55,6 +66,11 @@ type CreateWorkflowExecutionRequest struct {
 	ExecutionMetadata datatypes.JSON `json:"execution_metadata,omitempty"`
 }
 
+// ExecuteWorkflowRequest represents the optional request body for starting a workflow
+type ExecuteWorkflowRequest struct {
+	Input json.RawMessage `json:"input,omitempty" swaggertype:"object"`
+}
+
 // UpdateWorkflowExecutionRequest represents the request body for updating a workflow execution
 type UpdateWorkflowExecutionRequest struct {
 	Status            *WorkflowStatus `json:"status,omitempty"`
@@ -64,6 +80,12 @@ type UpdateWorkflowExecutionRequest struct {
 	Error             *string         `json:"error,omitempty"`
 }
 
+// StepDecision is an approver's answer to an approval step. Rejections need a comment.
+type StepDecision struct {
+	Approved bool
+	Comment  string
+}
+
 // WorkflowExecutionResponse represents the response for execution operations
 type WorkflowExecutionResponse struct {
 	Execution      *WorkflowExecution      `json:"execution"`
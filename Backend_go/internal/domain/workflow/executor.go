@@ -3,69 +3,128 @@ package workflow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/plugins"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/background"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
 	"gorm.io/datatypes"
 )
 
 // DefaultWorkflowExecutor is the standard implementation of WorkflowExecutor
 type DefaultWorkflowExecutor struct {
 	repo         Repository
-	logger       *logrus.Logger
+	logger       *zap.Logger
 	notifier     notification.DomainNotifier
 	rolesService roles.Service
+	webhooks     webhooks.Publisher
+	hooks        plugins.Hooks
+	workItems    WorkItems
+	ai           AIRunner
+	background   *background.Group
+	recovery     RecoveryConfig
+}
+
+// StepHookPayload is passed to plugin handlers around the execution of a step. Err is the
+// outcome of the step and is only set for after hooks.
+type StepHookPayload struct {
+	Step      *WorkflowStep
+	Execution *WorkflowStepExecution
+	Err       error
 }
 
 // NewDefaultExecutor creates a new workflow executor
-func NewDefaultExecutor(repo Repository, logger *logrus.Logger, notifier notification.DomainNotifier, rolesService roles.Service) *DefaultWorkflowExecutor {
+func NewDefaultExecutor(repo Repository, logger *zap.Logger, notifier notification.DomainNotifier, rolesService roles.Service) *DefaultWorkflowExecutor {
 	return &DefaultWorkflowExecutor{
 		repo:         repo,
 		logger:       logger,
 		notifier:     notifier,
 		rolesService: rolesService,
+		recovery:     DefaultRecoveryConfig(),
 	}
 }
 
+// WithWebhooks sets the publisher that receives execution lifecycle events
+func (e *DefaultWorkflowExecutor) WithWebhooks(publisher webhooks.Publisher) *DefaultWorkflowExecutor {
+	e.webhooks = publisher
+	return e
+}
+
+// WithHooks sets the plugin hooks run before and after each step
+func (e *DefaultWorkflowExecutor) WithHooks(hooks plugins.Hooks) *DefaultWorkflowExecutor {
+	e.hooks = hooks
+	return e
+}
+
+// WithBackground runs the executor's async work in a group, so shutdown waits for it
+func (e *DefaultWorkflowExecutor) WithBackground(group *background.Group) *DefaultWorkflowExecutor {
+	e.background = group
+	return e
+}
+
+// WithRecovery sets how often running steps renew their heartbeat
+func (e *DefaultWorkflowExecutor) WithRecovery(config RecoveryConfig) *DefaultWorkflowExecutor {
+	e.recovery = config
+	return e
+}
+
+// traceLogger returns the logger tagged with the trace identifiers carried by ctx
+func (e *DefaultWorkflowExecutor) traceLogger(ctx context.Context) *zap.Logger {
+	return tracing.Logger(ctx, e.logger)
+}
+
 // ExecuteStep handles the execution of a workflow step
 func (e *DefaultWorkflowExecutor) ExecuteStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
-	e.logger.WithFields(logrus.Fields{
-		"step_id":      step.ID,
-		"execution_id": execution.ExecutionID,
-		"step_type":    step.StepType,
-	}).Info("Executing workflow step")
-
-	// For manual/approval steps, we just ensure they are pending. For others, we set them to active.
+	e.traceLogger(ctx).Info("Executing workflow step",
+		zap.String("step_id", step.ID.String()),
+		zap.String("execution_id", execution.ExecutionID.String()),
+		zap.String("step_type", string(step.StepType)))
+
+	// For manual/approval steps, we just ensure they are pending. For others, we set them
+	// to active and keep up a heartbeat while they run, so a process that stops without
+	// shutting down leaves them for the recovery worker.
 	if step.StepType != StepTypeApproval && step.StepType != StepTypeManual {
 		execution.Status = StepStatusActive
+		execution.NextRetryAt = nil
 		if err := e.repo.UpdateStepExecution(ctx, execution); err != nil {
 			return fmt.Errorf("failed to update step execution status: %w", err)
 		}
+		defer e.heartbeat(ctx, execution.ID)()
 	}
 
-	// Execute the appropriate logic based on step type
+	// Execute the appropriate logic based on step type. Steps that wait for a person
+	// are neither timed out nor retried. A plugin refusing the step fails it, and AI
+	// steps pause while the AI budget is spent.
 	var err error
-	switch step.StepType {
-	case StepTypeManual:
-		err = e.executeManualStep(ctx, step, execution)
-	case StepTypeAutomated:
-		err = e.executeAutomatedStep(ctx, step, execution)
-	case StepTypeApproval:
-		err = e.executeApprovalStep(ctx, step, execution)
-	case StepTypeNotification:
-		err = e.executeNotificationStep(ctx, step, execution)
-	case StepTypeIntegration:
-		err = e.executeIntegrationStep(ctx, step, execution)
-	case StepTypeDecision:
-		err = e.executeDecisionStep(ctx, step, execution)
-	case StepTypeAITask:
-		err = e.executeAIStep(ctx, step, execution)
-	default:
-		err = fmt.Errorf("unsupported step type: %s", step.StepType)
+	var paused bool
+	if e.hooks != nil {
+		err = e.hooks.Before(ctx, plugins.BeforeWorkflowStepExecute, &StepHookPayload{Step: step, Execution: execution})
+	}
+	if err == nil && step.StepType == StepTypeAITask {
+		paused, err = e.aiBudgetExceeded(ctx, execution)
+	}
+	if err == nil && !paused {
+		switch step.StepType {
+		case StepTypeManual:
+			err = e.executeManualStep(ctx, step, execution)
+		case StepTypeApproval:
+			err = e.executeApprovalStep(ctx, step, execution)
+		default:
+			err = e.runWithRetry(ctx, step, execution)
+		}
+	}
+
+	// Shutdown stopped waiting for the step, so it is left to run again on the next start
+	if err != nil && background.Interrupted(ctx) {
+		interruptStep(ctx, e.repo, e.logger, execution)
+		return err
 	}
 
 	// Update execution based on result
@@ -76,17 +135,27 @@ func (e *DefaultWorkflowExecutor) ExecuteStep(ctx context.Context, step *Workflo
 		execution.Status = StepStatusFailed
 		errStr := err.Error()
 		execution.Error = &errStr
-		e.logger.WithError(err).WithFields(logrus.Fields{
-			"step_id":      step.ID,
-			"execution_id": execution.ExecutionID,
-		}).Error("Step execution failed")
+		e.traceLogger(ctx).Error("Step execution failed",
+			zap.Error(err),
+			zap.String("step_id", step.ID.String()),
+			zap.String("execution_id", execution.ExecutionID.String()))
+	} else if paused {
+		execution.Status = StepStatusPaused
+		errStr := ErrAIBudgetExceeded.Error()
+		execution.Error = &errStr
+		e.traceLogger(ctx).Warn("AI step paused, AI budget exceeded",
+			zap.String("step_id", step.ID.String()),
+			zap.String("execution_id", execution.ExecutionID.String()))
 	} else if step.StepType != StepTypeApproval && step.StepType != StepTypeManual {
-		// Only auto-complete non-manual steps
+		// Only auto-complete non-manual steps. Handlers may have put details of the run
+		// in the result already.
 		execution.Status = StepStatusCompleted
-		result := map[string]interface{}{
-			"completed_at": completedTime,
-			"duration":     completedTime.Sub(execution.StartedAt).Seconds(),
+		result := make(map[string]interface{})
+		if len(execution.Result) > 0 {
+			_ = json.Unmarshal(execution.Result, &result)
 		}
+		result["completed_at"] = completedTime
+		result["duration"] = completedTime.Sub(execution.StartedAt).Seconds()
 		resultJSON, _ := json.Marshal(result)
 		execution.Result = datatypes.JSON(resultJSON)
 		execution.CompletedAt = &completedTime
@@ -94,27 +163,163 @@ func (e *DefaultWorkflowExecutor) ExecuteStep(ctx context.Context, step *Workflo
 
 	// Save step execution status
 	if err := e.repo.UpdateStepExecution(ctx, execution); err != nil {
-		e.logger.WithError(err).Error("Failed to update step execution")
+		e.traceLogger(ctx).Error("Failed to update step execution", zap.Error(err))
 		return fmt.Errorf("failed to update step execution: %w", err)
 	}
+	if pubErr := publishStepEvent(ctx, e.webhooks, e.repo, step, execution); pubErr != nil {
+		e.traceLogger(ctx).Warn("Failed to publish step transition", zap.Error(pubErr))
+	}
+	if e.hooks != nil {
+		e.hooks.After(ctx, plugins.AfterWorkflowStepExecute, &StepHookPayload{Step: step, Execution: execution, Err: err})
+	}
 
 	// If step was successfully and automatically completed, process next steps
 	if err == nil && execution.Status == StepStatusCompleted {
 		if err := e.processTransitions(ctx, step, execution, "on_approve"); err != nil {
-			e.logger.WithError(err).Error("Failed to process next steps")
+			e.traceLogger(ctx).Error("Failed to process next steps", zap.Error(err))
 			// Continue execution even if next steps processing fails
 		}
 	}
 
 	// Check if workflow is complete
 	if err := e.checkWorkflowCompletion(ctx, execution.ExecutionID); err != nil {
-		e.logger.WithError(err).Error("Failed to check workflow completion")
+		e.traceLogger(ctx).Error("Failed to check workflow completion", zap.Error(err))
 		// Continue execution even if completion check fails
 	}
 
 	return err
 }
 
+// runWithRetry runs an automated step until it succeeds, its retry policy gives up or ctx
+// is cancelled, recording each attempt on the execution. Attempts made before a restart
+// count towards the policy, though a resumed step is always attempted once more.
+func (e *DefaultWorkflowExecutor) runWithRetry(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
+	policy, err := stepRetryPolicy(step)
+	if err != nil {
+		return err
+	}
+	payload, err := e.buildPayload(ctx, execution.ExecutionID)
+	if err != nil {
+		return err
+	}
+
+	var history []StepAttempt
+	if len(execution.AttemptHistory) > 0 {
+		_ = json.Unmarshal(execution.AttemptHistory, &history)
+	}
+
+	for {
+		started := time.Now()
+		err = e.runAttempt(ctx, step, execution, payload)
+
+		execution.Attempts++
+		execution.NextRetryAt = nil
+		record := StepAttempt{
+			Attempt:   execution.Attempts,
+			StartedAt: started,
+			Duration:  time.Since(started).Seconds(),
+		}
+		if err != nil {
+			record.Error = err.Error()
+			record.TimedOut = errors.Is(err, ErrStepTimeout)
+		} else {
+			execution.Error = nil
+		}
+		history = append(history, record)
+		historyJSON, _ := json.Marshal(history)
+		execution.AttemptHistory = datatypes.JSON(historyJSON)
+
+		if err == nil {
+			output, outErr := stepOutput(step, payload)
+			if outErr != nil {
+				return outErr
+			}
+			// An output template takes precedence over the output a handler produced
+			if output != nil {
+				execution.Output = output
+			}
+			return nil
+		}
+		if execution.Attempts >= policy.MaxAttempts || !policy.shouldRetry(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := policy.delay(execution.Attempts)
+		nextRetry := time.Now().Add(delay)
+		execution.NextRetryAt = &nextRetry
+		errStr := err.Error()
+		execution.Error = &errStr
+		execution.UpdatedAt = time.Now()
+		if updateErr := e.repo.UpdateStepExecution(ctx, execution); updateErr != nil {
+			e.traceLogger(ctx).Warn("Failed to record step attempt", zap.Error(updateErr))
+		}
+		e.traceLogger(ctx).Warn("Step attempt failed, retrying",
+			zap.Error(err),
+			zap.String("step_id", step.ID.String()),
+			zap.String("execution_id", execution.ExecutionID.String()),
+			zap.Int("attempt", execution.Attempts),
+			zap.Duration("retry_in", delay))
+
+		if waitErr := wait(ctx, delay); waitErr != nil {
+			execution.NextRetryAt = nil
+			return err
+		}
+	}
+}
+
+// runAttempt runs one attempt of an automated step, cancelling it once the step's
+// timeout has passed. A timed out handler may keep running for a while; it works on its
+// own copy of the execution, so nothing it writes after the timeout reaches the retry
+// loop or the next attempt.
+func (e *DefaultWorkflowExecutor) runAttempt(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	if step.Timeout == nil || *step.Timeout <= 0 {
+		return e.runHandler(ctx, step, execution, payload)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, time.Duration(*step.Timeout)*time.Second)
+	defer cancel()
+
+	attempt := *execution
+	done := make(chan error, 1)
+	go func() {
+		done <- e.runHandler(attemptCtx, step, &attempt, payload)
+	}()
+
+	select {
+	case err := <-done:
+		*execution = attempt
+		if err != nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %ds", ErrStepTimeout, *step.Timeout)
+		}
+		return err
+	case <-attemptCtx.Done():
+		if errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %ds", ErrStepTimeout, *step.Timeout)
+		}
+		return attemptCtx.Err()
+	}
+}
+
+// runHandler dispatches an automated step to the handler for its type
+func (e *DefaultWorkflowExecutor) runHandler(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	switch step.StepType {
+	case StepTypeAutomated:
+		return e.executeAutomatedStep(ctx, step, execution, payload)
+	case StepTypeNotification:
+		return e.executeNotificationStep(ctx, step, execution, payload)
+	case StepTypeIntegration:
+		return e.executeIntegrationStep(ctx, step, execution, payload)
+	case StepTypeDecision:
+		return e.executeDecisionStep(ctx, step, execution, payload)
+	case StepTypeAITask:
+		return e.executeAIStep(ctx, step, execution, payload)
+	case StepTypeAction:
+		return e.executeActionStep(ctx, step, execution, payload)
+	default:
+		return fmt.Errorf("unsupported step type: %s", step.StepType)
+	}
+}
+
 // ValidateTransition checks if a transition from one step to another is valid
 func (e *DefaultWorkflowExecutor) ValidateTransition(ctx context.Context, fromStep, toStep *WorkflowStep) error {
 	// List transitions from the source step
@@ -138,25 +343,26 @@ func (e *DefaultWorkflowExecutor) ValidateTransition(ctx context.Context, fromSt
 
 // executeManualStep handles manual steps which require user interaction
 func (e *DefaultWorkflowExecutor) executeManualStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
-	e.logger.WithField("step_id", step.ID).Info("Executing manual step - setting to pending")
+	e.traceLogger(ctx).Info("Executing manual step - setting to pending", zap.String("step_id", step.ID.String()))
 	// Manual steps are also set to pending and wait for a user to mark them as complete.
 	execution.Status = StepStatusPending
 	if err := e.repo.UpdateStepExecution(ctx, execution); err != nil {
 		return err
 	}
 
-	// Notify assigned user or role
-	go e.notifyAssignees(context.Background(), step)
+	e.notifyAssigneesAsync(ctx, step)
 
 	return nil
 }
 
 // executeAutomatedStep handles automated steps
-func (e *DefaultWorkflowExecutor) executeAutomatedStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
-	e.logger.WithField("step_id", step.ID).Info("Executing automated step")
+func (e *DefaultWorkflowExecutor) executeAutomatedStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	e.traceLogger(ctx).Info("Executing automated step", zap.String("step_id", step.ID.String()))
 
 	// Simulate processing time
-	time.Sleep(time.Millisecond * 200)
+	if err := wait(ctx, time.Millisecond*200); err != nil {
+		return err
+	}
 
 	// For demonstration, we'll just mark it as successful
 	return nil
@@ -164,56 +370,74 @@ func (e *DefaultWorkflowExecutor) executeAutomatedStep(ctx context.Context, step
 
 // executeApprovalStep handles approval steps
 func (e *DefaultWorkflowExecutor) executeApprovalStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
-	e.logger.WithField("step_id", step.ID).Info("Executing approval step - setting to pending")
+	e.traceLogger(ctx).Info("Executing approval step - setting to pending", zap.String("step_id", step.ID.String()))
 	// For approval steps, we just set them to pending and wait for external approval.
 	execution.Status = StepStatusPending
 	if err := e.repo.UpdateStepExecution(ctx, execution); err != nil {
 		return err
 	}
 
-	// Notify assigned user or role
-	go e.notifyAssignees(context.Background(), step)
+	e.notifyAssigneesAsync(ctx, step)
 
 	return nil
 }
 
+// notifyAssigneesAsync notifies the user or role assigned to a step without waiting
+func (e *DefaultWorkflowExecutor) notifyAssigneesAsync(ctx context.Context, step *WorkflowStep) {
+	spawn(e.background, ctx, "workflow step notification", func(ctx context.Context) {
+		e.notifyAssignees(ctx, step)
+	})
+}
+
 // executeNotificationStep handles notification steps
-func (e *DefaultWorkflowExecutor) executeNotificationStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
-	e.logger.WithField("step_id", step.ID).Info("Sending notification")
+func (e *DefaultWorkflowExecutor) executeNotificationStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	e.traceLogger(ctx).Info("Sending notification", zap.String("step_id", step.ID.String()))
 
 	// Simulate sending a notification
-	time.Sleep(time.Millisecond * 50)
+	if err := wait(ctx, time.Millisecond*50); err != nil {
+		return err
+	}
 
 	return nil
 }
 
 // executeIntegrationStep handles integration with external systems
-func (e *DefaultWorkflowExecutor) executeIntegrationStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
-	e.logger.WithField("step_id", step.ID).Info("Executing integration step")
+func (e *DefaultWorkflowExecutor) executeIntegrationStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	e.traceLogger(ctx).Info("Executing integration step", zap.String("step_id", step.ID.String()))
 
 	// Simulate integration with external system
-	time.Sleep(time.Millisecond * 300)
+	if err := wait(ctx, time.Millisecond*300); err != nil {
+		return err
+	}
 
 	return nil
 }
 
 // executeDecisionStep handles decision branches
-func (e *DefaultWorkflowExecutor) executeDecisionStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
-	e.logger.WithField("step_id", step.ID).Info("Evaluating decision step")
+func (e *DefaultWorkflowExecutor) executeDecisionStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	e.traceLogger(ctx).Info("Evaluating decision step", zap.String("step_id", step.ID.String()))
 
 	// Simulate decision logic
-	time.Sleep(time.Millisecond * 100)
+	if err := wait(ctx, time.Millisecond*100); err != nil {
+		return err
+	}
 
 	// Decision outcome would determine next step via transitions
 	return nil
 }
 
 // executeAIStep handles AI-powered tasks
-func (e *DefaultWorkflowExecutor) executeAIStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error {
-	e.logger.WithField("step_id", step.ID).Info("Executing AI step")
+func (e *DefaultWorkflowExecutor) executeAIStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, payload *StepPayload) error {
+	e.traceLogger(ctx).Info("Executing AI step", zap.String("step_id", step.ID.String()))
+
+	if e.ai != nil {
+		return e.runAIStep(ctx, step, execution, payload)
+	}
 
 	// Simulate AI processing
-	time.Sleep(time.Millisecond * 400)
+	if err := wait(ctx, time.Millisecond*400); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -237,14 +461,14 @@ func (e *DefaultWorkflowExecutor) processTransitions(ctx context.Context, curren
 
 	// If no transitions for this event, the path might be complete
 	if len(transitions) == 0 {
-		e.logger.WithFields(logrus.Fields{
-			"workflow_execution_id": execution.ExecutionID,
-			"on_event":              onEvent,
-		}).Info("No more steps to process for this event.")
-
-		// Only mark as complete on an approval event, not on rejection.
-		if onEvent == "on_approve" {
-			go e.completeWorkflow(context.Background(), execution.ExecutionID, currentStep.WorkflowID)
+		e.traceLogger(ctx).Info("No more steps to process for this event.",
+			zap.String("workflow_execution_id", execution.ExecutionID.String()),
+			zap.String("on_event", onEvent))
+
+		// The execution finishes once nothing else is left to run. It completes if every
+		// required step did and fails otherwise.
+		if onEvent == "on_approve" || onEvent == "on_reject" {
+			return e.checkWorkflowCompletion(ctx, execution.ExecutionID)
 		}
 		return nil
 	}
@@ -259,7 +483,9 @@ func (e *DefaultWorkflowExecutor) processTransitions(ctx context.Context, curren
 		// Get the target step
 		toStep, err := e.repo.GetStepByID(ctx, transition.ToStepID)
 		if err != nil {
-			e.logger.WithError(err).WithField("to_step_id", transition.ToStepID).Error("Failed to get target step")
+			e.traceLogger(ctx).Error("Failed to get target step",
+				zap.Error(err),
+				zap.String("to_step_id", transition.ToStepID.String()))
 			continue
 		}
 
@@ -272,14 +498,13 @@ func (e *DefaultWorkflowExecutor) processTransitions(ctx context.Context, curren
 				// In a real system, this would be more complex
 				conditionsMet := e.evaluateConditions(conditions, execution)
 				if !conditionsMet {
-					e.logger.WithFields(logrus.Fields{
-						"from_step_id": currentStep.ID,
-						"to_step_id":   toStep.ID,
-					}).Info("Transition conditions not met, skipping")
+					e.traceLogger(ctx).Info("Transition conditions not met, skipping",
+						zap.String("from_step_id", currentStep.ID.String()),
+						zap.String("to_step_id", toStep.ID.String()))
 					continue
 				}
 			} else {
-				e.logger.WithError(err).Error("Failed to unmarshal transition conditions")
+				e.traceLogger(ctx).Error("Failed to unmarshal transition conditions", zap.Error(err))
 				continue
 			}
 		}
@@ -290,6 +515,10 @@ func (e *DefaultWorkflowExecutor) processTransitions(ctx context.Context, curren
 			"previous_step_id": currentStep.ID,
 			"transition_id":    transition.ID,
 		}
+		if tc := tracing.FromContext(ctx); tc != nil {
+			metadataMap["request_id"] = tc.RequestID
+			metadataMap["trace_id"] = tc.TraceID
+		}
 		metadataJSON, _ := json.Marshal(metadataMap)
 
 		nextStepExecution := &WorkflowStepExecution{
@@ -304,18 +533,23 @@ func (e *DefaultWorkflowExecutor) processTransitions(ctx context.Context, curren
 		}
 
 		if err := e.repo.CreateStepExecution(ctx, nextStepExecution); err != nil {
-			e.logger.WithError(err).Error("Failed to create next step execution")
+			e.traceLogger(ctx).Error("Failed to create next step execution", zap.Error(err))
 			continue
 		}
+		if err := publishStepEvent(ctx, e.webhooks, e.repo, toStep, nextStepExecution); err != nil {
+			e.traceLogger(ctx).Warn("Failed to publish step transition", zap.Error(err))
+		}
 
 		// If step is auto-advance, execute it immediately
 		if toStep.AutoAdvance {
-			go func(step *WorkflowStep, stepExec *WorkflowStepExecution) {
-				ctx := context.Background() // Use a new context for async execution
-				if err := e.ExecuteStep(ctx, step, stepExec); err != nil {
-					e.logger.WithError(err).Error("Failed to auto-execute next step")
+			started := spawn(e.background, ctx, "workflow step", func(ctx context.Context) {
+				if err := e.ExecuteStep(ctx, toStep, nextStepExecution); err != nil {
+					e.traceLogger(ctx).Error("Failed to auto-execute next step", zap.Error(err))
 				}
-			}(toStep, nextStepExecution)
+			})
+			if !started {
+				interruptStep(ctx, e.repo, e.logger, nextStepExecution)
+			}
 		}
 	}
 
@@ -349,9 +583,10 @@ func (e *DefaultWorkflowExecutor) checkWorkflowCompletion(ctx context.Context, e
 		return fmt.Errorf("no step executions found for execution %s", executionID)
 	}
 
-	// Check if any steps are still pending or active
+	// Check if any steps are still pending, active or paused
 	for _, execution := range stepExecutions {
-		if execution.Status == StepStatusPending || execution.Status == StepStatusActive {
+		if execution.Status == StepStatusPending || execution.Status == StepStatusActive ||
+			execution.Status == StepStatusPaused || execution.Status == StepStatusInterrupted {
 			// Workflow is still in progress
 			return nil
 		}
@@ -369,7 +604,9 @@ func (e *DefaultWorkflowExecutor) checkWorkflowCompletion(ctx context.Context, e
 		// Get the corresponding step to check if it's required
 		step, err := e.repo.GetStepByID(ctx, execution.StepID)
 		if err != nil {
-			e.logger.WithError(err).WithField("step_id", execution.StepID).Error("Failed to get step")
+			e.traceLogger(ctx).Error("Failed to get step",
+				zap.Error(err),
+				zap.String("step_id", execution.StepID.String()))
 			continue
 		}
 
@@ -386,28 +623,21 @@ func (e *DefaultWorkflowExecutor) checkWorkflowCompletion(ctx context.Context, e
 
 	if allRequiredStepsSucceeded {
 		workflowExecution.Status = WorkflowStatusCompleted
-		resultSuccess := map[string]interface{}{
-			"completed_at": now,
-			"duration":     now.Sub(workflowExecution.StartedAt).Seconds(),
-			"status":       "success",
-		}
-		resultJSON, _ := json.Marshal(resultSuccess)
-		workflowExecution.Result = datatypes.JSON(resultJSON)
+		workflowExecution.Result = e.executionResult(ctx, workflowExecution, "success")
 	} else {
 		workflowExecution.Status = WorkflowStatusFailed
-		resultFailed := map[string]interface{}{
-			"completed_at": now,
-			"duration":     now.Sub(workflowExecution.StartedAt).Seconds(),
-			"status":       "failed",
-		}
-		resultJSON, _ := json.Marshal(resultFailed)
-		workflowExecution.Result = datatypes.JSON(resultJSON)
+		workflowExecution.Result = e.executionResult(ctx, workflowExecution, "failed")
 	}
 
-	// Update the workflow execution
-	if err := e.repo.UpdateExecution(ctx, workflowExecution); err != nil {
+	// Only the first caller to finish the execution updates the workflow and reports it.
+	// Steps ending together, recovery and late decisions can all get here.
+	finished, err := e.repo.FinishExecution(ctx, workflowExecution)
+	if err != nil {
 		return fmt.Errorf("failed to update workflow execution: %w", err)
 	}
+	if !finished {
+		return nil
+	}
 
 	// Update the workflow status
 	workflow, err := e.repo.GetByID(ctx, workflowExecution.WorkflowID)
@@ -448,6 +678,11 @@ func (e *DefaultWorkflowExecutor) checkWorkflowCompletion(ctx context.Context, e
 		return fmt.Errorf("failed to update workflow: %w", err)
 	}
 
+	publishExecutionEvent(ctx, e.webhooks, webhooks.EventWorkflowExecutionFinished, workflow, workflowExecution)
+	if workflowExecution.Status == WorkflowStatusCompleted {
+		e.notifyCompleted(ctx, workflow, workflowExecution)
+	}
+
 	return nil
 }
 
@@ -458,20 +693,24 @@ func (e *DefaultWorkflowExecutor) notifyAssignees(ctx context.Context, step *Wor
 
 	workflow, err := e.repo.GetByID(ctx, step.WorkflowID)
 	if err != nil {
-		e.logger.WithError(err).Warn("Failed to get workflow for notification")
+		e.traceLogger(ctx).Warn("Failed to get workflow for notification", zap.Error(err))
 		return
 	}
 
 	title := fmt.Sprintf("Action Required: %s", step.Name)
 	content := fmt.Sprintf("Your action is required for step '%s' in workflow '%s'.", step.Name, workflow.Name)
 	data := map[string]string{
-		"workflowId": step.WorkflowID.String(),
-		"stepId":     step.ID.String(),
+		"workflowId":   step.WorkflowID.String(),
+		"stepId":       step.ID.String(),
+		"stepName":     step.Name,
+		"workflowName": workflow.Name,
 	}
+	// Pending actions are emailed too, as they hold up the workflow
+	methods := []notification.DeliveryMethod{notification.InApp, notification.Email}
 
 	// If assigned to a specific user
 	if step.AssignedTo != nil {
-		e.notifier.NotifyUser(ctx, *step.AssignedTo, notification.WorkflowActionRequired, title, content, data, "workflow", step.WorkflowID)
+		e.notifier.NotifyUserWithDelivery(ctx, *step.AssignedTo, notification.WorkflowActionRequired, title, content, data, "workflow", step.WorkflowID, methods)
 		return
 	}
 
@@ -479,44 +718,23 @@ func (e *DefaultWorkflowExecutor) notifyAssignees(ctx context.Context, step *Wor
 	if step.AssignedToRoleID != nil && e.rolesService != nil {
 		userIDs, err := e.rolesService.GetUserIDsByRole(ctx, *step.AssignedToRoleID)
 		if err != nil {
-			e.logger.WithError(err).WithField("roleId", *step.AssignedToRoleID).Error("Failed to get users by role for notification")
+			e.traceLogger(ctx).Error("Failed to get users by role for notification",
+				zap.Error(err),
+				zap.String("role_id", step.AssignedToRoleID.String()))
 			return
 		}
 		for _, userID := range userIDs {
-			e.notifier.NotifyUser(ctx, userID, notification.WorkflowActionRequired, title, content, data, "workflow", step.WorkflowID)
+			e.notifier.NotifyUserWithDelivery(ctx, userID, notification.WorkflowActionRequired, title, content, data, "workflow", step.WorkflowID, methods)
 		}
 	}
 }
 
-func (e *DefaultWorkflowExecutor) completeWorkflow(ctx context.Context, executionID, workflowID uuid.UUID) {
-	// Here you would check if all parallel branches are completed, etc.
-	// For now, we assume if we reach the end of a path, it's done.
-	execution, err := e.repo.GetExecutionByID(ctx, executionID)
-	if err != nil {
-		e.logger.WithError(err).Warn("Failed to get workflow execution for completion notification")
-		return
-	}
-
-	// Update execution status
-	execution.Status = "Completed"
-	now := time.Now()
-	execution.CompletedAt = &now
-	if err := e.repo.UpdateExecution(ctx, execution); err != nil {
-		e.logger.WithError(err).Error("Failed to mark workflow execution as completed")
-		return
-	}
-
+// notifyCompleted tells the workflow's creator that an execution completed
+func (e *DefaultWorkflowExecutor) notifyCompleted(ctx context.Context, workflow *Workflow, execution *WorkflowExecution) {
 	if e.notifier == nil {
 		return
 	}
 
-	// Notify initiator
-	workflow, err := e.repo.GetByID(ctx, workflowID)
-	if err != nil {
-		e.logger.WithError(err).Warn("Failed to get workflow for completion notification")
-		return
-	}
-
 	title := fmt.Sprintf("Workflow '%s' Completed", workflow.Name)
 	content := "The workflow has been successfully completed."
 	data := map[string]string{
@@ -525,3 +743,45 @@ func (e *DefaultWorkflowExecutor) completeWorkflow(ctx context.Context, executio
 	}
 	e.notifier.NotifyUser(ctx, workflow.CreatedBy, notification.WorkflowCompleted, title, content, data, "workflow", workflow.ID)
 }
+
+// publishExecutionEvent sends a workflow execution lifecycle event to the organization's webhooks
+func publishExecutionEvent(ctx context.Context, publisher webhooks.Publisher, eventType webhooks.EventType, workflow *Workflow, execution *WorkflowExecution) {
+	if publisher == nil {
+		return
+	}
+	publisher.Publish(ctx, webhooks.Event{
+		Type:           eventType,
+		OrganizationID: workflow.OrganizationID,
+		UserID:         workflow.CreatedBy,
+		Data: map[string]interface{}{
+			"workflow_id":   workflow.ID,
+			"workflow_name": workflow.Name,
+			"execution":     execution,
+		},
+	})
+}
+
+// publishStepEvent tells subscribers that a step execution moved to a new status
+func publishStepEvent(ctx context.Context, publisher webhooks.Publisher, repo Repository, step *WorkflowStep, execution *WorkflowStepExecution) error {
+	if publisher == nil {
+		return nil
+	}
+	workflow, err := repo.GetByID(ctx, step.WorkflowID)
+	if err != nil {
+		return err
+	}
+	publisher.Publish(ctx, webhooks.Event{
+		Type:           webhooks.EventWorkflowStepTransitioned,
+		OrganizationID: workflow.OrganizationID,
+		UserID:         workflow.CreatedBy,
+		Data: map[string]interface{}{
+			"workflow_id":       workflow.ID,
+			"execution_id":      execution.ExecutionID,
+			"step_id":           step.ID,
+			"step_name":         step.Name,
+			"step_execution_id": execution.ID,
+			"status":            execution.Status,
+		},
+	})
+	return nil
+}
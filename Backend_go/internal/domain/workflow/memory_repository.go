@@ -0,0 +1,672 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// memoryRepository keeps workflows and their executions in memory, for tests and the demo
+// server. Records are stored by value so callers never share them with the store. Lookups
+// of missing records return gorm.ErrRecordNotFound, as the database repository does.
+type memoryRepository struct {
+	mu             sync.RWMutex
+	workflows      map[uuid.UUID]Workflow
+	steps          map[uuid.UUID]WorkflowStep
+	transitions    map[uuid.UUID]WorkflowTransition
+	executions     map[uuid.UUID]WorkflowExecution
+	stepExecutions map[uuid.UUID]WorkflowStepExecution
+	agentLinks     map[uuid.UUID]WorkflowAgentLink
+	aiUsage        []AIUsageRecord
+	aiBudgets      map[uuid.UUID]AIBudget
+}
+
+// NewMemoryRepository creates a Repository that keeps everything in memory
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		workflows:      make(map[uuid.UUID]Workflow),
+		steps:          make(map[uuid.UUID]WorkflowStep),
+		transitions:    make(map[uuid.UUID]WorkflowTransition),
+		executions:     make(map[uuid.UUID]WorkflowExecution),
+		stepExecutions: make(map[uuid.UUID]WorkflowStepExecution),
+		agentLinks:     make(map[uuid.UUID]WorkflowAgentLink),
+		aiBudgets:      make(map[uuid.UUID]AIBudget),
+	}
+}
+
+// paginate returns the given page of items, counting pages from 1 as the database
+// repository does. A page size of zero returns everything.
+func paginate[T any](items []T, page, pageSize int) []T {
+	if pageSize <= 0 {
+		return items
+	}
+	start := (page - 1) * pageSize
+	if start < 0 {
+		start = 0
+	}
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// Workflow operations
+func (r *memoryRepository) Create(ctx context.Context, workflow *Workflow) error {
+	if err := workflow.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workflows[workflow.ID] = *workflow
+	return nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, workflow *Workflow) error {
+	if err := workflow.BeforeUpdate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workflows[workflow.ID] = *workflow
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.workflows, id)
+	return nil
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*Workflow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	workflow, ok := r.workflows[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &workflow, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, filter *WorkflowFilter) ([]Workflow, int64, error) {
+	if filter == nil {
+		filter = &WorkflowFilter{}
+	}
+	r.mu.RLock()
+	workflows := []Workflow{}
+	for _, w := range r.workflows {
+		switch {
+		case filter.OrganizationID != nil && w.OrganizationID != *filter.OrganizationID,
+			filter.CreatedBy != nil && w.CreatedBy != *filter.CreatedBy,
+			filter.Status != nil && w.Status != *filter.Status,
+			filter.WorkflowType != nil && w.WorkflowType != *filter.WorkflowType,
+			filter.StartDate != nil && w.CreatedAt.Before(*filter.StartDate),
+			filter.EndDate != nil && w.CreatedAt.After(*filter.EndDate),
+			len(filter.Tags) > 0 && !sharesTag(w.Tags, filter.Tags),
+			filter.IsTemplate != nil && w.IsTemplate != *filter.IsTemplate:
+			continue
+		}
+		workflows = append(workflows, w)
+	}
+	r.mu.RUnlock()
+
+	if filter.Keyset != nil {
+		return pagination.Slice(filter.Keyset, workflows, (*Workflow).PageCursor), 0, nil
+	}
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].CreatedAt.Before(workflows[j].CreatedAt) })
+	return paginate(workflows, filter.Page, filter.PageSize), int64(len(workflows)), nil
+}
+
+// sharesTag reports whether the two tag lists overlap, like the && array operator
+func sharesTag(tags pq.StringArray, wanted []string) bool {
+	for _, t := range tags {
+		for _, w := range wanted {
+			if t == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *memoryRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status WorkflowStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if workflow, ok := r.workflows[id]; ok {
+		workflow.Status = status
+		workflow.UpdatedAt = time.Now()
+		r.workflows[id] = workflow
+	}
+	return nil
+}
+
+func (r *memoryRepository) SetTemplate(ctx context.Context, id uuid.UUID, isTemplate bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if workflow, ok := r.workflows[id]; ok {
+		workflow.IsTemplate = isTemplate
+		workflow.UpdatedAt = time.Now()
+		r.workflows[id] = workflow
+	}
+	return nil
+}
+
+// CreateWorkflowGraph stores the workflow, steps and transitions; storing in memory
+// cannot fail halfway
+func (r *memoryRepository) CreateWorkflowGraph(ctx context.Context, workflow *Workflow, steps []WorkflowStep, transitions []WorkflowTransition) error {
+	if err := r.Create(ctx, workflow); err != nil {
+		return err
+	}
+	for i := range steps {
+		if err := r.CreateStep(ctx, &steps[i]); err != nil {
+			return err
+		}
+	}
+	for i := range transitions {
+		if err := r.CreateTransition(ctx, &transitions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step operations
+func (r *memoryRepository) CreateStep(ctx context.Context, step *WorkflowStep) error {
+	if step.ID == uuid.Nil {
+		step.ID = uuid.New()
+	}
+	now := time.Now()
+	if step.CreatedAt.IsZero() {
+		step.CreatedAt = now
+	}
+	step.UpdatedAt = now
+	if step.Status == "" {
+		step.Status = StepStatusPending
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps[step.ID] = *step
+	return nil
+}
+
+func (r *memoryRepository) UpdateStep(ctx context.Context, step *WorkflowStep) error {
+	return r.CreateStep(ctx, step)
+}
+
+func (r *memoryRepository) DeleteStep(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.steps, id)
+	return nil
+}
+
+func (r *memoryRepository) GetStepByID(ctx context.Context, id uuid.UUID) (*WorkflowStep, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	step, ok := r.steps[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &step, nil
+}
+
+func (r *memoryRepository) ListSteps(ctx context.Context, filter *WorkflowStepFilter) ([]WorkflowStep, int64, error) {
+	if filter == nil {
+		filter = &WorkflowStepFilter{}
+	}
+	r.mu.RLock()
+	steps := []WorkflowStep{}
+	for _, s := range r.steps {
+		switch {
+		case filter.WorkflowID != nil && s.WorkflowID != *filter.WorkflowID,
+			filter.StepType != nil && s.StepType != *filter.StepType,
+			filter.Status != nil && s.Status != *filter.Status,
+			filter.AssignedTo != nil && (s.AssignedTo == nil || *s.AssignedTo != *filter.AssignedTo),
+			filter.StartDate != nil && s.CreatedAt.Before(*filter.StartDate),
+			filter.EndDate != nil && s.CreatedAt.After(*filter.EndDate):
+			continue
+		}
+		steps = append(steps, s)
+	}
+	r.mu.RUnlock()
+
+	// Order by step_order for consistent retrieval
+	sort.SliceStable(steps, func(i, j int) bool {
+		if steps[i].StepOrder != steps[j].StepOrder {
+			return steps[i].StepOrder < steps[j].StepOrder
+		}
+		return steps[i].CreatedAt.Before(steps[j].CreatedAt)
+	})
+	return paginate(steps, filter.Page, filter.PageSize), int64(len(steps)), nil
+}
+
+func (r *memoryRepository) UpdateStepStatus(ctx context.Context, id uuid.UUID, status StepStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if step, ok := r.steps[id]; ok {
+		step.Status = status
+		step.UpdatedAt = time.Now()
+		r.steps[id] = step
+	}
+	return nil
+}
+
+// Transition operations
+func (r *memoryRepository) CreateTransition(ctx context.Context, transition *WorkflowTransition) error {
+	if transition.ID == uuid.Nil {
+		transition.ID = uuid.New()
+	}
+	if transition.CreatedAt.IsZero() {
+		transition.CreatedAt = time.Now()
+	}
+	if transition.OnEvent == "" {
+		transition.OnEvent = "on_approve"
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions[transition.ID] = *transition
+	return nil
+}
+
+func (r *memoryRepository) UpdateTransition(ctx context.Context, transition *WorkflowTransition) error {
+	return r.CreateTransition(ctx, transition)
+}
+
+func (r *memoryRepository) DeleteTransition(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.transitions, id)
+	return nil
+}
+
+func (r *memoryRepository) GetTransitionByID(ctx context.Context, id uuid.UUID) (*WorkflowTransition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	transition, ok := r.transitions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &transition, nil
+}
+
+func (r *memoryRepository) ListTransitions(ctx context.Context, filter *WorkflowTransitionFilter) ([]WorkflowTransition, int64, error) {
+	if filter == nil {
+		filter = &WorkflowTransitionFilter{}
+	}
+	r.mu.RLock()
+	transitions := []WorkflowTransition{}
+	for _, t := range r.transitions {
+		switch {
+		case filter.FromStepID != nil && t.FromStepID != *filter.FromStepID,
+			filter.ToStepID != nil && t.ToStepID != *filter.ToStepID,
+			filter.OnEvent != nil && t.OnEvent != *filter.OnEvent:
+			continue
+		}
+		transitions = append(transitions, t)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(transitions, func(i, j int) bool { return transitions[i].CreatedAt.Before(transitions[j].CreatedAt) })
+	return paginate(transitions, filter.Page, filter.PageSize), int64(len(transitions)), nil
+}
+
+// Execution operations
+func (r *memoryRepository) CreateExecution(ctx context.Context, execution *WorkflowExecution) error {
+	if err := execution.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions[execution.ID] = *execution
+	return nil
+}
+
+func (r *memoryRepository) UpdateExecution(ctx context.Context, execution *WorkflowExecution) error {
+	if err := execution.BeforeUpdate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions[execution.ID] = *execution
+	return nil
+}
+
+func (r *memoryRepository) GetExecutionByID(ctx context.Context, id uuid.UUID) (*WorkflowExecution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	execution, ok := r.executions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &execution, nil
+}
+
+func (r *memoryRepository) ListExecutions(ctx context.Context, filter *WorkflowExecutionFilter) ([]WorkflowExecution, int64, error) {
+	if filter == nil {
+		filter = &WorkflowExecutionFilter{}
+	}
+	r.mu.RLock()
+	executions := []WorkflowExecution{}
+	for _, e := range r.executions {
+		switch {
+		case filter.WorkflowID != nil && e.WorkflowID != *filter.WorkflowID,
+			filter.Status != nil && e.Status != *filter.Status,
+			filter.StartDate != nil && e.StartedAt.Before(*filter.StartDate),
+			filter.EndDate != nil && e.StartedAt.After(*filter.EndDate):
+			continue
+		}
+		executions = append(executions, e)
+	}
+	r.mu.RUnlock()
+
+	// Most recent executions first
+	sort.Slice(executions, func(i, j int) bool { return executions[i].StartedAt.After(executions[j].StartedAt) })
+	return paginate(executions, filter.Page, filter.PageSize), int64(len(executions)), nil
+}
+
+func (r *memoryRepository) CancelActiveExecutions(ctx context.Context, workflowID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, e := range r.executions {
+		if e.WorkflowID == workflowID && (e.Status == WorkflowStatusActive || e.Status == WorkflowStatusPending) {
+			e.Status = WorkflowStatusCancelled
+			e.UpdatedAt = time.Now()
+			r.executions[id] = e
+		}
+	}
+	return nil
+}
+
+// Step execution operations
+func (r *memoryRepository) CreateStepExecution(ctx context.Context, execution *WorkflowStepExecution) error {
+	if err := execution.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stepExecutions[execution.ID] = *execution
+	return nil
+}
+
+func (r *memoryRepository) UpdateStepExecution(ctx context.Context, execution *WorkflowStepExecution) error {
+	if err := execution.BeforeUpdate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stepExecutions[execution.ID] = *execution
+	return nil
+}
+
+func (r *memoryRepository) GetStepExecutionByID(ctx context.Context, id uuid.UUID) (*WorkflowStepExecution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	execution, ok := r.stepExecutions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &execution, nil
+}
+
+func (r *memoryRepository) ListStepExecutions(ctx context.Context, executionID uuid.UUID) ([]WorkflowStepExecution, error) {
+	r.mu.RLock()
+	executions := []WorkflowStepExecution{}
+	for _, e := range r.stepExecutions {
+		if e.ExecutionID == executionID {
+			executions = append(executions, e)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(executions, func(i, j int) bool { return executions[i].StartedAt.Before(executions[j].StartedAt) })
+	return executions, nil
+}
+
+// Agent link operations
+func (r *memoryRepository) CreateAgentLink(ctx context.Context, link *WorkflowAgentLink) error {
+	if err := link.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.agentLinks[link.ID] = *link
+	return nil
+}
+
+func (r *memoryRepository) GetAgentLinksByWorkflowID(ctx context.Context, workflowID uuid.UUID) ([]WorkflowAgentLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	links := []WorkflowAgentLink{}
+	for _, l := range r.agentLinks {
+		if l.WorkflowID == workflowID {
+			links = append(links, l)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].CreatedAt.Before(links[j].CreatedAt) })
+	return links, nil
+}
+
+// AI cost operations
+func (r *memoryRepository) CreateAIUsage(ctx context.Context, record *AIUsageRecord) error {
+	if err := record.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aiUsage = append(r.aiUsage, *record)
+	return nil
+}
+
+func (r *memoryRepository) SumAIUsage(ctx context.Context, filter AIUsageFilter) ([]AIUsageTotal, error) {
+	type key struct {
+		workflowID      uuid.UUID
+		provider, model string
+	}
+	r.mu.RLock()
+	byKey := make(map[key]*AIUsageTotal)
+	totals := []AIUsageTotal{}
+	for _, u := range r.aiUsage {
+		if u.OrganizationID != filter.OrganizationID || u.CreatedAt.Before(filter.From) || u.CreatedAt.After(filter.To) {
+			continue
+		}
+		if filter.WorkflowID != nil && u.WorkflowID != *filter.WorkflowID {
+			continue
+		}
+		k := key{u.WorkflowID, u.Provider, u.Model}
+		total, ok := byKey[k]
+		if !ok {
+			total = &AIUsageTotal{WorkflowID: u.WorkflowID, Provider: u.Provider, Model: u.Model}
+			byKey[k] = total
+		}
+		total.Calls++
+		total.InputTokens += int64(u.InputTokens)
+		total.OutputTokens += int64(u.OutputTokens)
+		total.LatencyMs += u.LatencyMs
+		total.CostUSD += u.CostUSD
+	}
+	r.mu.RUnlock()
+	for _, total := range byKey {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].CostUSD > totals[j].CostUSD })
+	return totals, nil
+}
+
+func (r *memoryRepository) ListAIBudgets(ctx context.Context, orgID uuid.UUID) ([]AIBudget, error) {
+	r.mu.RLock()
+	budgets := []AIBudget{}
+	for _, b := range r.aiBudgets {
+		if b.OrganizationID == orgID {
+			budgets = append(budgets, b)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].CreatedAt.Before(budgets[j].CreatedAt) })
+	return budgets, nil
+}
+
+func (r *memoryRepository) SaveAIBudget(ctx context.Context, budget *AIBudget) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	budget.ID, budget.CreatedAt = uuid.New(), now
+	for id, b := range r.aiBudgets {
+		if sameAIBudget(b, budget.OrganizationID, budget.WorkflowID) {
+			budget.ID, budget.CreatedAt = id, b.CreatedAt
+		}
+	}
+	budget.UpdatedAt = now
+	r.aiBudgets[budget.ID] = *budget
+	return nil
+}
+
+func (r *memoryRepository) DeleteAIBudget(ctx context.Context, orgID uuid.UUID, workflowID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, b := range r.aiBudgets {
+		if sameAIBudget(b, orgID, workflowID) {
+			delete(r.aiBudgets, id)
+		}
+	}
+	return nil
+}
+
+func sameAIBudget(b AIBudget, orgID uuid.UUID, workflowID *uuid.UUID) bool {
+	if b.OrganizationID != orgID || (b.WorkflowID == nil) != (workflowID == nil) {
+		return false
+	}
+	return workflowID == nil || *b.WorkflowID == *workflowID
+}
+
+func (r *memoryRepository) ListPausedStepExecutions(ctx context.Context, orgID uuid.UUID) ([]WorkflowStepExecution, error) {
+	r.mu.RLock()
+	executions := []WorkflowStepExecution{}
+	for _, se := range r.stepExecutions {
+		if se.Status != StepStatusPaused {
+			continue
+		}
+		if execution, ok := r.executions[se.ExecutionID]; ok && r.workflows[execution.WorkflowID].OrganizationID == orgID {
+			executions = append(executions, se)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(executions, func(i, j int) bool { return executions[i].StartedAt.Before(executions[j].StartedAt) })
+	return executions, nil
+}
+
+func (r *memoryRepository) ListInterruptedStepExecutions(ctx context.Context) ([]WorkflowStepExecution, error) {
+	r.mu.RLock()
+	executions := []WorkflowStepExecution{}
+	for _, se := range r.stepExecutions {
+		if se.Status == StepStatusInterrupted {
+			executions = append(executions, se)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(executions, func(i, j int) bool { return executions[i].StartedAt.Before(executions[j].StartedAt) })
+	return executions, nil
+}
+
+func (r *memoryRepository) TouchStepExecution(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if se, ok := r.stepExecutions[id]; ok {
+		se.UpdatedAt = at
+		r.stepExecutions[id] = se
+	}
+	return nil
+}
+
+func (r *memoryRepository) ListStaleStepExecutions(ctx context.Context, before time.Time) ([]WorkflowStepExecution, error) {
+	r.mu.RLock()
+	executions := []WorkflowStepExecution{}
+	for _, se := range r.stepExecutions {
+		if staleAt(se, before) {
+			executions = append(executions, se)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(executions, func(i, j int) bool { return executions[i].StartedAt.Before(executions[j].StartedAt) })
+	return executions, nil
+}
+
+func (r *memoryRepository) ClaimStaleStepExecution(ctx context.Context, id uuid.UUID, before, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	se, ok := r.stepExecutions[id]
+	if !ok || !staleAt(se, before) {
+		return false, nil
+	}
+	se.UpdatedAt = at
+	r.stepExecutions[id] = se
+	return true, nil
+}
+
+func (r *memoryRepository) DecideStepExecution(ctx context.Context, execution *WorkflowStepExecution) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	se, ok := r.stepExecutions[execution.ID]
+	if !ok || se.Status != StepStatusPending {
+		return false, nil
+	}
+	r.stepExecutions[execution.ID] = *execution
+	return true, nil
+}
+
+func (r *memoryRepository) FinishExecution(ctx context.Context, execution *WorkflowExecution) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.executions[execution.ID]
+	if !ok {
+		return false, nil
+	}
+	for _, status := range finishedStatuses {
+		if stored.Status == status {
+			return false, nil
+		}
+	}
+	stored.Status = execution.Status
+	stored.Result = execution.Result
+	stored.CompletedAt = execution.CompletedAt
+	stored.UpdatedAt = execution.UpdatedAt
+	r.executions[execution.ID] = stored
+	return true, nil
+}
+
+// staleAt mirrors the stale step execution condition of the database repository
+func staleAt(se WorkflowStepExecution, before time.Time) bool {
+	return se.Status == StepStatusActive && se.UpdatedAt.Before(before) &&
+		(se.NextRetryAt == nil || se.NextRetryAt.Before(before))
+}
+
+// CreateWorkflow creates a new workflow with the same defaults as the database repository
+func (r *memoryRepository) CreateWorkflow(ctx context.Context, workflow *Workflow) error {
+	if workflow.Config == nil {
+		workflow.Config = datatypes.JSON("{}")
+	}
+	if workflow.WorkflowMetadata == nil {
+		metadata := map[string]interface{}{
+			"version":    "1.0.0",
+			"created_at": time.Now().UTC(),
+			"creator_id": workflow.CreatedBy,
+		}
+		if jsonData, err := json.Marshal(metadata); err == nil {
+			workflow.WorkflowMetadata = datatypes.JSON(jsonData)
+		}
+	}
+	if workflow.Tags == nil {
+		workflow.Tags = pq.StringArray{}
+	}
+	return r.Create(ctx, workflow)
+}
@@ -3,6 +3,7 @@ package workflow
 import (
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"gorm.io/datatypes"
@@ -49,6 +50,11 @@ type Workflow struct {
 	Version          string         `json:"version" gorm:"type:varchar(50)"`
 	Tags             pq.StringArray `json:"tags" gorm:"type:text[]"`
 
+	// Templates are kept in the organization's library to be cloned; they never run
+	IsTemplate bool `json:"is_template" gorm:"not null;default:false;index"`
+	// SourceID is the workflow or template this workflow was cloned from
+	SourceID *uuid.UUID `json:"source_id,omitempty" gorm:"type:uuid"`
+
 	// AI Integration
 	AIEnabled             bool           `json:"ai_enabled" gorm:"default:false"`
 	AIConfidenceThreshold float64        `json:"ai_confidence_threshold" gorm:"default:0.8"`
@@ -117,7 +123,10 @@ type WorkflowResponse struct {
 // WorkflowListResponse represents the response for listing workflows
 type WorkflowListResponse struct {
 	Workflows []Workflow `json:"workflows"`
-	Total     int64      `json:"total"`
+	// Total is only counted for offset pages
+	Total int64 `json:"total"`
+	// NextCursor continues a keyset listing; it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // TableName specifies the table name for the Workflow model
@@ -147,6 +156,11 @@ func (w *Workflow) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
+// PageCursor is the position of the workflow in keyset pages, which follow creation time
+func (w *Workflow) PageCursor() pagination.Cursor {
+	return pagination.Cursor{Time: w.CreatedAt, ID: w.ID}
+}
+
 // WorkflowFilter represents the filter options for querying workflows
 type WorkflowFilter struct {
 	OrganizationID *uuid.UUID
@@ -156,8 +170,11 @@ type WorkflowFilter struct {
 	StartDate      *time.Time
 	EndDate        *time.Time
 	Tags           []string
+	IsTemplate     *bool
 	Page           int
 	PageSize       int
+	// Keyset pages by creation time instead of Page and PageSize, without counting the total
+	Keyset *pagination.Keyset
 }
 
 // IsValid checks if the workflow type is valid
@@ -0,0 +1,198 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// referencePrefix marks strings in a step's output template that are read from the payload
+const referencePrefix = "$."
+
+// StepPayload is the document a step runs with: the input the execution was started with
+// and the outputs of the steps completed before it, keyed by step name
+type StepPayload struct {
+	Input interface{}            `json:"input"`
+	Steps map[string]interface{} `json:"steps"`
+}
+
+// buildPayload collects the execution input and the outputs of the execution's completed steps
+func (e *DefaultWorkflowExecutor) buildPayload(ctx context.Context, executionID uuid.UUID) (*StepPayload, error) {
+	execution, err := e.repo.GetExecutionByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow execution: %w", err)
+	}
+
+	payload := &StepPayload{Steps: make(map[string]interface{})}
+	if len(execution.Input) > 0 {
+		if err := json.Unmarshal(execution.Input, &payload.Input); err != nil {
+			return nil, fmt.Errorf("failed to read execution input: %w", err)
+		}
+	}
+
+	outputs, _, err := e.stepOutputs(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	payload.Steps = outputs
+	return payload, nil
+}
+
+// stepOutputs returns the outputs of an execution's completed steps keyed by step name,
+// along with the output of the step that completed last
+func (e *DefaultWorkflowExecutor) stepOutputs(ctx context.Context, executionID uuid.UUID) (map[string]interface{}, interface{}, error) {
+	stepExecutions, err := e.repo.ListStepExecutions(ctx, executionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list step executions: %w", err)
+	}
+
+	outputs := make(map[string]interface{})
+	var final interface{}
+	var finalExecution *WorkflowStepExecution
+	for i := range stepExecutions {
+		se := &stepExecutions[i]
+		if se.Status != StepStatusCompleted || len(se.Output) == 0 {
+			continue
+		}
+		step, err := e.repo.GetStepByID(ctx, se.StepID)
+		if err != nil {
+			e.traceLogger(ctx).Warn("Failed to get step for its output",
+				zap.Error(err),
+				zap.String("step_id", se.StepID.String()))
+			continue
+		}
+		var output interface{}
+		if err := json.Unmarshal(se.Output, &output); err != nil {
+			continue
+		}
+		outputs[step.Name] = output
+
+		if finalExecution == nil || completedAfter(se, finalExecution) {
+			finalExecution = se
+			final = output
+		}
+	}
+	return outputs, final, nil
+}
+
+func completedAfter(a, b *WorkflowStepExecution) bool {
+	if a.CompletedAt == nil {
+		return false
+	}
+	return b.CompletedAt == nil || a.CompletedAt.After(*b.CompletedAt)
+}
+
+// stepOutput renders the "output" template in a step's config against the payload.
+// Strings such as "$.input.customer.email" or "$.steps.Fetch order.total" are replaced by
+// the value they point at, and references embedded in longer strings as "{{$.input.name}}"
+// are replaced by their text; everything else is copied as is. Steps without a template
+// have no output.
+func stepOutput(step *WorkflowStep, payload *StepPayload) (datatypes.JSON, error) {
+	if len(step.Config) == 0 {
+		return nil, nil
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(step.Config, &config); err != nil {
+		return nil, nil
+	}
+	template, ok := config["output"]
+	if !ok {
+		return nil, nil
+	}
+
+	doc, err := payloadDocument(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := json.Marshal(render(template, doc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode step output: %w", err)
+	}
+	return datatypes.JSON(output), nil
+}
+
+// payloadDocument converts the payload into the generic form templates are rendered against
+func payloadDocument(payload *StepPayload) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// embeddedReference matches references embedded in a longer string, e.g. "Follow up with {{$.input.name}}"
+var embeddedReference = regexp.MustCompile(`\{\{\s*\$\.([^}]+?)\s*\}\}`)
+
+func render(template interface{}, doc map[string]interface{}) interface{} {
+	switch t := template.(type) {
+	case string:
+		if strings.HasPrefix(t, referencePrefix) {
+			return resolve(doc, strings.TrimPrefix(t, referencePrefix))
+		}
+		return embeddedReference.ReplaceAllStringFunc(t, func(match string) string {
+			value := resolve(doc, embeddedReference.FindStringSubmatch(match)[1])
+			if value == nil {
+				return ""
+			}
+			return fmt.Sprint(value)
+		})
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			out[k] = render(v, doc)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, v := range t {
+			out[i] = render(v, doc)
+		}
+		return out
+	default:
+		return t
+	}
+}
+
+// resolve follows a dotted path through the payload, returning nil when it leads nowhere
+func resolve(doc map[string]interface{}, path string) interface{} {
+	var current interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+// executionResult builds the result stored on a finished execution: the outputs of every
+// completed step and the output of the last one
+func (e *DefaultWorkflowExecutor) executionResult(ctx context.Context, execution *WorkflowExecution, status string) datatypes.JSON {
+	now := execution.UpdatedAt
+	result := map[string]interface{}{
+		"completed_at": now,
+		"duration":     now.Sub(execution.StartedAt).Seconds(),
+		"status":       status,
+	}
+	outputs, final, err := e.stepOutputs(ctx, execution.ID)
+	if err != nil {
+		e.traceLogger(ctx).Warn("Failed to collect step outputs", zap.Error(err))
+	} else {
+		result["steps"] = outputs
+		result["output"] = final
+	}
+	resultJSON, _ := json.Marshal(result)
+	return datatypes.JSON(resultJSON)
+}
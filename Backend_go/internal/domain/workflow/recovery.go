@@ -0,0 +1,278 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// ErrStepAbandoned is recorded for the attempt a step was on when the process running it
+// stopped. It counts as a timeout for the step's retry conditions.
+var ErrStepAbandoned = fmt.Errorf("%w: the process running it stopped", ErrStepTimeout)
+
+// RecoveryConfig holds the settings that tell running step executions from ones a stopped
+// process left active
+type RecoveryConfig struct {
+	// HeartbeatInterval is how often a running step execution records that it is alive
+	HeartbeatInterval time.Duration
+	// StaleAfter is how long an active step execution may go without a heartbeat before
+	// another process takes it over. It must be several heartbeats long.
+	StaleAfter time.Duration
+	// SweepInterval is how often the recovery worker looks for stale step executions
+	SweepInterval time.Duration
+}
+
+// DefaultRecoveryConfig returns the default step recovery configuration
+func DefaultRecoveryConfig() RecoveryConfig {
+	return RecoveryConfig{
+		HeartbeatInterval: 30 * time.Second,
+		StaleAfter:        5 * time.Minute,
+		SweepInterval:     time.Minute,
+	}
+}
+
+// RecoveryResult counts the stale step executions a sweep took over
+type RecoveryResult struct {
+	Resumed int `json:"resumed"`
+	Failed  int `json:"failed"`
+}
+
+// heartbeat renews the heartbeat of a step execution until the returned function is
+// called
+func (e *DefaultWorkflowExecutor) heartbeat(ctx context.Context, id uuid.UUID) func() {
+	interval := e.recovery.HeartbeatInterval
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.repo.TouchStepExecution(ctx, id, time.Now()); err != nil && ctx.Err() == nil {
+					e.traceLogger(ctx).Warn("Failed to renew step execution heartbeat",
+						zap.Error(err),
+						zap.String("step_execution_id", id.String()))
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// RecoverStep takes over a step execution a stopped process left active. Unless the
+// process stopped while waiting for a retry, the attempt it was on counts as abandoned.
+// The step's retry policy then decides whether it runs again, after the policy's delay,
+// or fails. It reports whether the step was resumed.
+func (e *DefaultWorkflowExecutor) RecoverStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) (bool, error) {
+	policy, err := stepRetryPolicy(step)
+	if err != nil {
+		return false, e.failRecovered(ctx, step, execution, err)
+	}
+
+	// A retry due after the last heartbeat means no attempt had started yet
+	lastSeen := execution.UpdatedAt
+	abandoned := execution.NextRetryAt == nil || !execution.NextRetryAt.After(lastSeen)
+
+	var delay time.Duration
+	if abandoned {
+		var history []StepAttempt
+		if len(execution.AttemptHistory) > 0 {
+			_ = json.Unmarshal(execution.AttemptHistory, &history)
+		}
+		execution.Attempts++
+		history = append(history, StepAttempt{
+			Attempt:   execution.Attempts,
+			StartedAt: lastSeen,
+			Error:     ErrStepAbandoned.Error(),
+			TimedOut:  true,
+		})
+		historyJSON, _ := json.Marshal(history)
+		execution.AttemptHistory = datatypes.JSON(historyJSON)
+
+		if execution.Attempts >= policy.MaxAttempts || !policy.shouldRetry(ErrStepAbandoned) {
+			return false, e.failRecovered(ctx, step, execution, ErrStepAbandoned)
+		}
+		delay = policy.delay(execution.Attempts)
+		errStr := ErrStepAbandoned.Error()
+		execution.Error = &errStr
+	} else if until := time.Until(*execution.NextRetryAt); until > 0 {
+		delay = until
+	}
+
+	// The retry time keeps the execution from counting as stale while it waits
+	nextRetry := time.Now().Add(delay)
+	execution.NextRetryAt = &nextRetry
+	execution.UpdatedAt = time.Now()
+	if err := e.repo.UpdateStepExecution(ctx, execution); err != nil {
+		return false, fmt.Errorf("failed to update step execution: %w", err)
+	}
+
+	started := spawn(e.background, ctx, "resume workflow step", func(ctx context.Context) {
+		if err := wait(ctx, delay); err != nil {
+			interruptStep(ctx, e.repo, e.logger, execution)
+			return
+		}
+		if err := e.ExecuteStep(ctx, step, execution); err != nil {
+			e.traceLogger(ctx).Warn("Recovered step failed",
+				zap.Error(err),
+				zap.String("step_id", step.ID.String()),
+				zap.String("step_execution_id", execution.ID.String()))
+		}
+	})
+	if !started {
+		interruptStep(ctx, e.repo, e.logger, execution)
+		return false, nil
+	}
+	return true, nil
+}
+
+// failRecovered fails a recovered step execution the way ExecuteStep fails a step
+func (e *DefaultWorkflowExecutor) failRecovered(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution, cause error) error {
+	execution.Status = StepStatusFailed
+	errStr := cause.Error()
+	execution.Error = &errStr
+	execution.NextRetryAt = nil
+	execution.UpdatedAt = time.Now()
+	if err := e.repo.UpdateStepExecution(ctx, execution); err != nil {
+		return fmt.Errorf("failed to update step execution: %w", err)
+	}
+	e.traceLogger(ctx).Warn("Failed step a stopped process abandoned",
+		zap.Error(cause),
+		zap.String("step_id", step.ID.String()),
+		zap.String("step_execution_id", execution.ID.String()),
+		zap.Int("attempts", execution.Attempts))
+
+	if err := publishStepEvent(ctx, e.webhooks, e.repo, step, execution); err != nil {
+		e.traceLogger(ctx).Warn("Failed to publish step transition", zap.Error(err))
+	}
+	if err := e.checkWorkflowCompletion(ctx, execution.ExecutionID); err != nil {
+		e.traceLogger(ctx).Error("Failed to check workflow completion", zap.Error(err))
+	}
+	return nil
+}
+
+// RecoverStale takes over the active step executions without a heartbeat for staleAfter.
+// Each is claimed before it is recovered, so several instances can sweep at once.
+func (s *service) RecoverStale(ctx context.Context, staleAfter time.Duration) (*RecoveryResult, error) {
+	result := &RecoveryResult{}
+	if s.executor == nil {
+		return result, nil
+	}
+
+	before := time.Now().Add(-staleAfter)
+	stale, err := s.repo.ListStaleStepExecutions(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stale {
+		execution := &stale[i]
+		claimed, err := s.repo.ClaimStaleStepExecution(ctx, execution.ID, before, time.Now())
+		if err != nil {
+			return result, err
+		}
+		if !claimed {
+			continue
+		}
+		step, err := s.repo.GetStepByID(ctx, execution.StepID)
+		if err != nil {
+			s.traceLogger(ctx).Warn("Failed to get stale step",
+				zap.Error(err),
+				zap.String("step_id", execution.StepID.String()))
+			continue
+		}
+		resumed, err := s.executor.RecoverStep(ctx, step, execution)
+		if err != nil {
+			s.traceLogger(ctx).Warn("Failed to recover stale step execution",
+				zap.Error(err),
+				zap.String("step_execution_id", execution.ID.String()))
+			continue
+		}
+		if resumed {
+			result.Resumed++
+		} else if execution.Status == StepStatusFailed {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// RecoveryWorker periodically takes over the step executions of processes that stopped
+// without shutting down, such as ones that crashed or were killed
+type RecoveryWorker struct {
+	service Service
+	config  RecoveryConfig
+	logger  *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRecoveryWorker creates a new step recovery worker
+func NewRecoveryWorker(service Service, config RecoveryConfig, logger *zap.Logger) *RecoveryWorker {
+	return &RecoveryWorker{
+		service: service,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// Start sweeps for stale step executions now and then every SweepInterval
+func (w *RecoveryWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.config.SweepInterval)
+		defer ticker.Stop()
+		for {
+			w.sweep(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the current sweep and stops the worker
+func (w *RecoveryWorker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+func (w *RecoveryWorker) sweep(ctx context.Context) {
+	result, err := w.service.RecoverStale(ctx, w.config.StaleAfter)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("Failed to recover stale workflow steps", zap.Error(err))
+		}
+		return
+	}
+	if result.Resumed > 0 || result.Failed > 0 {
+		w.logger.Info("Recovered stale workflow steps",
+			zap.Int("resumed", result.Resumed),
+			zap.Int("failed", result.Failed))
+	}
+}
@@ -3,11 +3,12 @@ package workflow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
-	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -21,6 +22,10 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*Workflow, error)
 	List(ctx context.Context, filter *WorkflowFilter) ([]Workflow, int64, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status WorkflowStatus) error
+	SetTemplate(ctx context.Context, id uuid.UUID, isTemplate bool) error
+	// CreateWorkflowGraph creates a workflow with its steps and transitions in one
+	// transaction
+	CreateWorkflowGraph(ctx context.Context, workflow *Workflow, steps []WorkflowStep, transitions []WorkflowTransition) error
 
 	// Step operations
 	CreateStep(ctx context.Context, step *WorkflowStep) error
@@ -54,6 +59,34 @@ type Repository interface {
 	CreateAgentLink(ctx context.Context, link *WorkflowAgentLink) error
 	GetAgentLinksByWorkflowID(ctx context.Context, workflowID uuid.UUID) ([]WorkflowAgentLink, error)
 
+	// AI cost operations
+	CreateAIUsage(ctx context.Context, record *AIUsageRecord) error
+	// SumAIUsage totals AI usage per workflow, provider and model
+	SumAIUsage(ctx context.Context, filter AIUsageFilter) ([]AIUsageTotal, error)
+	ListAIBudgets(ctx context.Context, orgID uuid.UUID) ([]AIBudget, error)
+	// SaveAIBudget replaces the budget with the same organization and workflow
+	SaveAIBudget(ctx context.Context, budget *AIBudget) error
+	DeleteAIBudget(ctx context.Context, orgID uuid.UUID, workflowID *uuid.UUID) error
+	ListPausedStepExecutions(ctx context.Context, orgID uuid.UUID) ([]WorkflowStepExecution, error)
+	// ListInterruptedStepExecutions lists the step executions of every organization a
+	// shutdown interrupted, oldest first
+	ListInterruptedStepExecutions(ctx context.Context) ([]WorkflowStepExecution, error)
+	// TouchStepExecution renews the heartbeat of a step execution, its updated_at, without
+	// writing anything else
+	TouchStepExecution(ctx context.Context, id uuid.UUID, at time.Time) error
+	// ListStaleStepExecutions lists the active step executions of every organization with
+	// no heartbeat since before, leaving out those waiting for a retry due after before
+	ListStaleStepExecutions(ctx context.Context, before time.Time) ([]WorkflowStepExecution, error)
+	// ClaimStaleStepExecution renews the heartbeat of a step execution if it is still
+	// stale. It reports false when another instance claimed it first.
+	ClaimStaleStepExecution(ctx context.Context, id uuid.UUID, before, at time.Time) (bool, error)
+	// DecideStepExecution saves the decision on an approval step execution if it is still
+	// pending. It reports false when someone else decided first.
+	DecideStepExecution(ctx context.Context, execution *WorkflowStepExecution) (bool, error)
+	// FinishExecution saves the final status and result of an execution if it has not
+	// finished yet. It reports false when it was already completed, failed or cancelled.
+	FinishExecution(ctx context.Context, execution *WorkflowExecution) (bool, error)
+
 	// CreateWorkflow creates a new workflow
 	CreateWorkflow(ctx context.Context, workflow *Workflow) error
 }
@@ -61,11 +94,11 @@ type Repository interface {
 // repository implements the Repository interface
 type repository struct {
 	db     *gorm.DB
-	logger *logrus.Logger
+	logger *zap.Logger
 }
 
 // NewRepository creates a new workflow repository
-func NewRepository(db *gorm.DB, logger *logrus.Logger) Repository {
+func NewRepository(db *gorm.DB, logger *zap.Logger) Repository {
 	return &repository{db: db, logger: logger}
 }
 
@@ -119,6 +152,13 @@ func (r *repository) List(ctx context.Context, filter *WorkflowFilter) ([]Workfl
 		if len(filter.Tags) > 0 {
 			query = query.Where("tags && ?", filter.Tags)
 		}
+		if filter.IsTemplate != nil {
+			query = query.Where("is_template = ?", *filter.IsTemplate)
+		}
+		if filter.Keyset != nil {
+			err := query.Scopes(filter.Keyset.Scope("created_at", "id")).Find(&workflows).Error
+			return workflows, 0, err
+		}
 	}
 
 	err := query.Count(&total).Error
@@ -143,6 +183,30 @@ func (r *repository) UpdateStatus(ctx context.Context, id uuid.UUID, status Work
 	return r.db.WithContext(ctx).Model(&Workflow{}).Where("id = ?", id).Update("status", status).Error
 }
 
+func (r *repository) SetTemplate(ctx context.Context, id uuid.UUID, isTemplate bool) error {
+	return r.db.WithContext(ctx).Model(&Workflow{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"is_template": isTemplate, "updated_at": time.Now()}).Error
+}
+
+func (r *repository) CreateWorkflowGraph(ctx context.Context, workflow *Workflow, steps []WorkflowStep, transitions []WorkflowTransition) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workflow).Error; err != nil {
+			return err
+		}
+		if len(steps) > 0 {
+			if err := tx.Create(&steps).Error; err != nil {
+				return err
+			}
+		}
+		if len(transitions) > 0 {
+			if err := tx.Create(&transitions).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // Step operations
 func (r *repository) CreateStep(ctx context.Context, step *WorkflowStep) error {
 	return r.db.WithContext(ctx).Create(step).Error
@@ -372,6 +436,163 @@ func (r *repository) ListStepExecutions(ctx context.Context, executionID uuid.UU
 	return executions, nil
 }
 
+// AI cost operations
+func (r *repository) CreateAIUsage(ctx context.Context, record *AIUsageRecord) error {
+	return r.db.WithContext(ctx).Create(record).Error
+}
+
+func (r *repository) SumAIUsage(ctx context.Context, filter AIUsageFilter) ([]AIUsageTotal, error) {
+	query := r.db.WithContext(ctx).Model(&AIUsageRecord{}).
+		Select("workflow_id, provider, model, COUNT(*) AS calls, SUM(input_tokens) AS input_tokens, "+
+			"SUM(output_tokens) AS output_tokens, SUM(latency_ms) AS latency_ms, SUM(cost_usd) AS cost_usd").
+		Where("organization_id = ? AND created_at >= ? AND created_at <= ?", filter.OrganizationID, filter.From, filter.To)
+	if filter.WorkflowID != nil {
+		query = query.Where("workflow_id = ?", *filter.WorkflowID)
+	}
+
+	var totals []AIUsageTotal
+	err := query.Group("workflow_id, provider, model").Order("cost_usd desc").Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+func (r *repository) ListAIBudgets(ctx context.Context, orgID uuid.UUID) ([]AIBudget, error) {
+	var budgets []AIBudget
+	err := r.db.WithContext(ctx).Where("organization_id = ?", orgID).Order("created_at asc").Find(&budgets).Error
+	if err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+func (r *repository) SaveAIBudget(ctx context.Context, budget *AIBudget) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing AIBudget
+		err := aiBudgetScope(tx, budget.OrganizationID, budget.WorkflowID).First(&existing).Error
+		switch {
+		case err == nil:
+			budget.ID = existing.ID
+			budget.CreatedAt = existing.CreatedAt
+			budget.UpdatedAt = time.Now()
+			return tx.Save(budget).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(budget).Error
+		default:
+			return err
+		}
+	})
+}
+
+func (r *repository) DeleteAIBudget(ctx context.Context, orgID uuid.UUID, workflowID *uuid.UUID) error {
+	return aiBudgetScope(r.db.WithContext(ctx), orgID, workflowID).Delete(&AIBudget{}).Error
+}
+
+// aiBudgetScope selects the budget of an organization, or of one of its workflows
+func aiBudgetScope(db *gorm.DB, orgID uuid.UUID, workflowID *uuid.UUID) *gorm.DB {
+	db = db.Where("organization_id = ?", orgID)
+	if workflowID == nil {
+		return db.Where("workflow_id IS NULL")
+	}
+	return db.Where("workflow_id = ?", *workflowID)
+}
+
+func (r *repository) ListPausedStepExecutions(ctx context.Context, orgID uuid.UUID) ([]WorkflowStepExecution, error) {
+	var executions []WorkflowStepExecution
+	err := r.db.WithContext(ctx).
+		Joins("JOIN workflow_executions ON workflow_executions.id = workflow_step_executions.execution_id").
+		Joins("JOIN workflows ON workflows.id = workflow_executions.workflow_id").
+		Where("workflows.organization_id = ? AND workflow_step_executions.status = ?", orgID, StepStatusPaused).
+		Order("workflow_step_executions.started_at asc").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+func (r *repository) ListInterruptedStepExecutions(ctx context.Context) ([]WorkflowStepExecution, error) {
+	var executions []WorkflowStepExecution
+	err := r.db.WithContext(ctx).
+		Where("status = ?", StepStatusInterrupted).
+		Order("started_at asc").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+func (r *repository) TouchStepExecution(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&WorkflowStepExecution{}).
+		Where("id = ?", id).
+		UpdateColumn("updated_at", at).Error
+}
+
+// staleStepExecution matches the active step executions nothing has worked on since before
+const staleStepExecution = "status = ? AND updated_at < ? AND (next_retry_at IS NULL OR next_retry_at < ?)"
+
+func (r *repository) ListStaleStepExecutions(ctx context.Context, before time.Time) ([]WorkflowStepExecution, error) {
+	var executions []WorkflowStepExecution
+	err := r.db.WithContext(ctx).
+		Where(staleStepExecution, StepStatusActive, before, before).
+		Order("started_at asc").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+func (r *repository) ClaimStaleStepExecution(ctx context.Context, id uuid.UUID, before, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&WorkflowStepExecution{}).
+		Where("id = ?", id).
+		Where(staleStepExecution, StepStatusActive, before, before).
+		UpdateColumn("updated_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) DecideStepExecution(ctx context.Context, execution *WorkflowStepExecution) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&WorkflowStepExecution{}).
+		Where("id = ? AND status = ?", execution.ID, StepStatusPending).
+		Updates(map[string]interface{}{
+			"status":           execution.Status,
+			"result":           execution.Result,
+			"error":            execution.Error,
+			"completed_at":     execution.CompletedAt,
+			"decided_by":       execution.DecidedBy,
+			"decided_at":       execution.DecidedAt,
+			"decision_comment": execution.DecisionComment,
+			"updated_at":       execution.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) FinishExecution(ctx context.Context, execution *WorkflowExecution) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&WorkflowExecution{}).
+		Where("id = ? AND status NOT IN ?", execution.ID, finishedStatuses).
+		Updates(map[string]interface{}{
+			"status":       execution.Status,
+			"result":       execution.Result,
+			"completed_at": execution.CompletedAt,
+			"updated_at":   execution.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// finishedStatuses are the statuses an execution does not leave
+var finishedStatuses = []WorkflowStatus{WorkflowStatusCompleted, WorkflowStatusFailed, WorkflowStatusCancelled}
+
 // Agent link operations
 func (r *repository) CreateAgentLink(ctx context.Context, link *WorkflowAgentLink) error {
 	return r.db.WithContext(ctx).Create(link).Error
@@ -390,10 +611,9 @@ func (r *repository) GetAgentLinksByWorkflowID(ctx context.Context, workflowID u
 
 // CreateWorkflow creates a new workflow
 func (r *repository) CreateWorkflow(ctx context.Context, workflow *Workflow) error {
-	r.logger.WithFields(logrus.Fields{
-		"name":       workflow.Name,
-		"creator_id": workflow.CreatedBy,
-	}).Info("Creating new workflow")
+	r.logger.Info("Creating new workflow",
+		zap.String("name", workflow.Name),
+		zap.String("creator_id", workflow.CreatedBy.String()))
 
 	// Ensure proper initialization of maps
 	if workflow.Config == nil {
@@ -417,7 +637,7 @@ func (r *repository) CreateWorkflow(ctx context.Context, workflow *Workflow) err
 
 	result := r.db.WithContext(ctx).Create(workflow)
 	if result.Error != nil {
-		r.logger.WithError(result.Error).Error("Failed to create workflow")
+		r.logger.Error("Failed to create workflow", zap.Error(result.Error))
 		return result.Error
 	}
 
@@ -0,0 +1,140 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// ErrStepTimeout is returned when a step runs past its timeout
+var ErrStepTimeout = errors.New("step timed out")
+
+// ErrInvalidRetryPolicy is returned when a step's retry policy cannot be followed
+var ErrInvalidRetryPolicy = errors.New("invalid retry policy")
+
+// Backoff strategies between attempts of a step
+const (
+	BackoffFixed       = "fixed"
+	BackoffExponential = "exponential"
+)
+
+// Retry conditions besides matching a part of the error message
+const (
+	RetryOnTimeout = "timeout"
+	RetryOnError   = "error"
+)
+
+// RetryPolicy says how often a failed step is attempted again. It is read from the
+// "retry" key of the step config, e.g.
+//
+//	{"retry": {"max_attempts": 3, "backoff": "exponential", "initial_interval": 5, "retry_on": ["timeout"]}}
+//
+// Intervals are in seconds. Without RetryOn every failure is retried.
+type RetryPolicy struct {
+	MaxAttempts     int      `json:"max_attempts"`
+	Backoff         string   `json:"backoff"`
+	InitialInterval int      `json:"initial_interval"`
+	MaxInterval     int      `json:"max_interval"`
+	RetryOn         []string `json:"retry_on"`
+}
+
+// StepAttempt records one attempt at running a step
+type StepAttempt struct {
+	Attempt   int       `json:"attempt"`
+	StartedAt time.Time `json:"started_at"`
+	Duration  float64   `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+	TimedOut  bool      `json:"timed_out,omitempty"`
+}
+
+// stepRetryPolicy returns the retry policy of a step, preferring the step config over
+// the older retry_config column. Steps without one are attempted once.
+func stepRetryPolicy(step *WorkflowStep) (*RetryPolicy, error) {
+	policy := &RetryPolicy{MaxAttempts: 1, Backoff: BackoffFixed}
+
+	raw := step.RetryConfig
+	if len(step.Config) > 0 {
+		var config map[string]json.RawMessage
+		if err := json.Unmarshal(step.Config, &config); err == nil {
+			if r, ok := config["retry"]; ok {
+				raw = datatypes.JSON(r)
+			}
+		}
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return policy, nil
+	}
+
+	if err := json.Unmarshal(raw, policy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRetryPolicy, err)
+	}
+	if policy.MaxAttempts < 1 {
+		return nil, fmt.Errorf("%w: max_attempts must be at least 1", ErrInvalidRetryPolicy)
+	}
+	if policy.Backoff == "" {
+		policy.Backoff = BackoffFixed
+	}
+	if policy.Backoff != BackoffFixed && policy.Backoff != BackoffExponential {
+		return nil, fmt.Errorf("%w: backoff must be fixed or exponential", ErrInvalidRetryPolicy)
+	}
+	if policy.InitialInterval < 0 || policy.MaxInterval < 0 {
+		return nil, fmt.Errorf("%w: intervals cannot be negative", ErrInvalidRetryPolicy)
+	}
+	return policy, nil
+}
+
+// shouldRetry reports whether a failed attempt matches the policy's retry conditions
+func (p *RetryPolicy) shouldRetry(err error) bool {
+	if len(p.RetryOn) == 0 {
+		return true
+	}
+	timedOut := errors.Is(err, ErrStepTimeout)
+	for _, cond := range p.RetryOn {
+		switch strings.ToLower(cond) {
+		case RetryOnTimeout:
+			if timedOut {
+				return true
+			}
+		case RetryOnError:
+			if !timedOut {
+				return true
+			}
+		default:
+			if strings.Contains(err.Error(), cond) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait after the given failed attempt, counting from 1
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	interval := time.Duration(p.InitialInterval) * time.Second
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attempt && interval > 0 && interval < 24*time.Hour; i++ {
+			interval *= 2
+		}
+	}
+	if max := time.Duration(p.MaxInterval) * time.Second; max > 0 && interval > max {
+		interval = max
+	}
+	return interval
+}
+
+// wait blocks for d, returning early with the context's error when it is cancelled
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
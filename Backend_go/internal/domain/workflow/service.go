@@ -5,12 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/activity"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/background"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/pagination"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
 	"gorm.io/datatypes"
 )
 
@@ -19,6 +27,8 @@ var (
 	ErrStepNotApprovable       = errors.New("step is not of type approval or is not pending")
 	ErrNotAuthorized           = errors.New("not authorized")
 	ErrRejectionRequiresReason = errors.New("rejection requires a reason")
+	ErrInvalidExecutionInput   = errors.New("execution input must be valid JSON")
+	ErrExecutionNotRunning     = errors.New("workflow execution is no longer running")
 )
 
 // Service defines the interface for workflow business logic
@@ -30,6 +40,11 @@ type Service interface {
 	GetWorkflow(ctx context.Context, id uuid.UUID) (*WorkflowResponse, error)
 	ListWorkflows(ctx context.Context, filter *WorkflowFilter) (*WorkflowListResponse, error)
 
+	// Templates and cloning
+	SetWorkflowTemplate(ctx context.Context, id uuid.UUID, isTemplate bool) (*WorkflowResponse, error)
+	ListWorkflowTemplates(ctx context.Context, orgID uuid.UUID, page, pageSize int) (*WorkflowListResponse, error)
+	CloneWorkflow(ctx context.Context, id uuid.UUID, creatorID uuid.UUID, req CloneWorkflowRequest) (*WorkflowResponse, error)
+
 	// Step operations
 	AddWorkflowStep(ctx context.Context, workflowID uuid.UUID, req CreateWorkflowStepRequest) (*WorkflowStepResponse, error)
 	UpdateWorkflowStep(ctx context.Context, id uuid.UUID, req UpdateWorkflowStepRequest) (*WorkflowStepResponse, error)
@@ -38,28 +53,54 @@ type Service interface {
 	ListWorkflowSteps(ctx context.Context, filter *WorkflowStepFilter) (*WorkflowStepListResponse, error)
 
 	// Execution operations
-	ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID) (*WorkflowExecutionResponse, error)
+	ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID, input datatypes.JSON) (*WorkflowExecutionResponse, error)
 	ExecuteWorkflowStep(ctx context.Context, stepID uuid.UUID, executionID uuid.UUID) (*WorkflowStepExecution, error)
 	CancelWorkflowExecution(ctx context.Context, workflowID uuid.UUID) error
 	GetWorkflowExecution(ctx context.Context, executionID uuid.UUID) (*WorkflowExecutionResponse, error)
 	ListWorkflowExecutions(ctx context.Context, filter *WorkflowExecutionFilter) (*WorkflowExecutionListResponse, error)
 	ApproveStepExecution(ctx context.Context, executionID, userID uuid.UUID, reason string) error
 	RejectStepExecution(ctx context.Context, executionID, userID uuid.UUID, reason string) error
+	// DecideStep approves or rejects a pending approval step of a workflow execution and
+	// only then moves the execution on along the step's on_approve or on_reject transitions
+	DecideStep(ctx context.Context, executionID, stepExecutionID, userID uuid.UUID, decision StepDecision) (*WorkflowStepExecution, error)
 
 	// Analysis and optimization
 	AnalyzeWorkflow(ctx context.Context, workflowID uuid.UUID) (map[string]interface{}, error)
 	OptimizeWorkflow(ctx context.Context, workflowID uuid.UUID) (map[string]interface{}, error)
 
+	// AI cost reports and budgets
+	GetAICostReport(ctx context.Context, orgID uuid.UUID, workflowID *uuid.UUID, from, to time.Time) (*AICostReport, error)
+	SetAIBudget(ctx context.Context, orgID uuid.UUID, req SetAIBudgetRequest) (*AIBudget, error)
+
+	// ResumeInterrupted runs the steps a shutdown interrupted again and returns how many
+	ResumeInterrupted(ctx context.Context) (int, error)
+	// RecoverStale takes over the active steps without a heartbeat for staleAfter, left
+	// behind by a process that stopped without shutting down
+	RecoverStale(ctx context.Context, staleAfter time.Duration) (*RecoveryResult, error)
+
 	GetRepo() Repository
 	GetExecutor() WorkflowExecutor
 }
 
 type service struct {
 	repo         Repository
-	logger       *logrus.Logger
+	logger       *zap.Logger
 	executor     WorkflowExecutor
 	rolesService roles.Service
 	notifier     notification.DomainNotifier
+	activity     activity.Recorder
+	webhooks     webhooks.Publisher
+	usage        metering.Recorder
+	auditor      audit.Auditor
+	defaults     Defaults
+	background   *background.Group
+}
+
+// Defaults supplies the settings new workflows inherit from their organization
+type Defaults interface {
+	// WorkflowDeadline returns how long after creation workflows of the organization
+	// are due, zero when they have no default deadline
+	WorkflowDeadline(ctx context.Context, organizationID uuid.UUID) (time.Duration, error)
 }
 
 // WorkflowExecutor handles the actual execution of workflow steps
@@ -67,15 +108,29 @@ type WorkflowExecutor interface {
 	ExecuteStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) error
 	ValidateTransition(ctx context.Context, fromStep, toStep *WorkflowStep) error
 	ProcessTransitions(ctx context.Context, currentStep *WorkflowStep, execution *WorkflowStepExecution, onEvent string) error
+	// RecoverStep resumes or fails a step execution a stopped process left active and
+	// reports whether it was resumed
+	RecoverStep(ctx context.Context, step *WorkflowStep, execution *WorkflowStepExecution) (bool, error)
 }
 
 // ServiceConfig holds the configuration for the workflow service
 type ServiceConfig struct {
 	Repository   Repository
-	Logger       *logrus.Logger
+	Logger       *zap.Logger
 	Executor     WorkflowExecutor
 	RolesService roles.Service
 	Notifier     notification.DomainNotifier
+	Activity     activity.Recorder
+	Webhooks     webhooks.Publisher
+	// Usage meters workflow executions; optional
+	Usage metering.Recorder
+	// Audit logs executions to the organization's audit log; optional
+	Audit audit.Auditor
+	// Defaults gives new workflows the organization's default deadline; optional
+	Defaults Defaults
+	// Background runs step executions and notifications so that shutdown waits for them;
+	// optional
+	Background *background.Group
 }
 
 // NewService creates a new workflow service
@@ -86,15 +141,25 @@ func NewService(config ServiceConfig) Service {
 		executor:     config.Executor,
 		rolesService: config.RolesService,
 		notifier:     config.Notifier,
+		activity:     config.Activity,
+		webhooks:     config.Webhooks,
+		usage:        config.Usage,
+		auditor:      config.Audit,
+		defaults:     config.Defaults,
+		background:   config.Background,
 	}
 }
 
+// traceLogger returns the logger tagged with the trace identifiers carried by ctx
+func (s *service) traceLogger(ctx context.Context) *zap.Logger {
+	return tracing.Logger(ctx, s.logger)
+}
+
 // CreateWorkflow implements the workflow creation logic
 func (s *service) CreateWorkflow(ctx context.Context, req CreateWorkflowRequest, creatorID uuid.UUID) (*WorkflowResponse, error) {
-	s.logger.WithFields(logrus.Fields{
-		"creator_id": creatorID,
-		"name":       req.Name,
-	}).Info("Creating new workflow")
+	s.traceLogger(ctx).Info("Creating new workflow",
+		zap.String("creator_id", creatorID.String()),
+		zap.String("name", req.Name))
 
 	metadata := map[string]interface{}{
 		"created_at": time.Now().UTC(),
@@ -124,10 +189,19 @@ func (s *service) CreateWorkflow(ctx context.Context, req CreateWorkflowRequest,
 
 	if req.Deadline != nil {
 		workflow.Deadline = req.Deadline
+	} else if s.defaults != nil {
+		deadline, err := s.defaults.WorkflowDeadline(ctx, req.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve workflow defaults: %w", err)
+		}
+		if deadline > 0 {
+			due := time.Now().UTC().Add(deadline)
+			workflow.Deadline = &due
+		}
 	}
 
 	if err := s.repo.Create(ctx, workflow); err != nil {
-		s.logger.WithError(err).Error("Failed to create workflow")
+		s.traceLogger(ctx).Error("Failed to create workflow", zap.Error(err))
 		return nil, fmt.Errorf("failed to create workflow: %w", err)
 	}
 
@@ -136,13 +210,11 @@ func (s *service) CreateWorkflow(ctx context.Context, req CreateWorkflowRequest,
 
 // UpdateWorkflow implements the workflow update logic
 func (s *service) UpdateWorkflow(ctx context.Context, id uuid.UUID, req UpdateWorkflowRequest) (*WorkflowResponse, error) {
-	s.logger.WithFields(logrus.Fields{
-		"workflow_id": id,
-	}).Info("Updating workflow")
+	s.traceLogger(ctx).Info("Updating workflow", zap.String("workflow_id", id.String()))
 
 	workflow, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to get workflow for update")
+		s.traceLogger(ctx).Error("Failed to get workflow for update", zap.Error(err))
 		return nil, fmt.Errorf("failed to get workflow: %w", err)
 	}
 
@@ -184,7 +256,7 @@ func (s *service) UpdateWorkflow(ctx context.Context, id uuid.UUID, req UpdateWo
 	}
 
 	if err := s.repo.Update(ctx, workflow); err != nil {
-		s.logger.WithError(err).Error("Failed to update workflow")
+		s.traceLogger(ctx).Error("Failed to update workflow", zap.Error(err))
 		return nil, fmt.Errorf("failed to update workflow: %w", err)
 	}
 
@@ -193,9 +265,7 @@ func (s *service) UpdateWorkflow(ctx context.Context, id uuid.UUID, req UpdateWo
 
 // DeleteWorkflow implements the workflow deletion logic
 func (s *service) DeleteWorkflow(ctx context.Context, id uuid.UUID) error {
-	s.logger.WithFields(logrus.Fields{
-		"workflow_id": id,
-	}).Info("Deleting workflow")
+	s.traceLogger(ctx).Info("Deleting workflow", zap.String("workflow_id", id.String()))
 
 	// First check if workflow exists
 	_, err := s.repo.GetByID(ctx, id)
@@ -205,12 +275,12 @@ func (s *service) DeleteWorkflow(ctx context.Context, id uuid.UUID) error {
 
 	// Cancel any active executions
 	if err := s.repo.CancelActiveExecutions(ctx, id); err != nil {
-		s.logger.WithError(err).Error("Failed to cancel active executions")
+		s.traceLogger(ctx).Error("Failed to cancel active executions", zap.Error(err))
 		// Continue with deletion even if cancellation fails
 	}
 
 	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.WithError(err).Error("Failed to delete workflow")
+		s.traceLogger(ctx).Error("Failed to delete workflow", zap.Error(err))
 		return fmt.Errorf("failed to delete workflow: %w", err)
 	}
 
@@ -219,13 +289,11 @@ func (s *service) DeleteWorkflow(ctx context.Context, id uuid.UUID) error {
 
 // GetWorkflow implements the workflow retrieval logic
 func (s *service) GetWorkflow(ctx context.Context, id uuid.UUID) (*WorkflowResponse, error) {
-	s.logger.WithFields(logrus.Fields{
-		"workflow_id": id,
-	}).Info("Getting workflow details")
+	s.traceLogger(ctx).Info("Getting workflow details", zap.String("workflow_id", id.String()))
 
 	workflow, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to get workflow")
+		s.traceLogger(ctx).Error("Failed to get workflow", zap.Error(err))
 		return nil, fmt.Errorf("failed to get workflow: %w", err)
 	}
 
@@ -234,26 +302,29 @@ func (s *service) GetWorkflow(ctx context.Context, id uuid.UUID) (*WorkflowRespo
 
 // ListWorkflows implements the workflow listing logic
 func (s *service) ListWorkflows(ctx context.Context, filter *WorkflowFilter) (*WorkflowListResponse, error) {
-	s.logger.Info("Listing workflows")
+	s.traceLogger(ctx).Info("Listing workflows")
 
 	workflows, total, err := s.repo.List(ctx, filter)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list workflows")
+		s.traceLogger(ctx).Error("Failed to list workflows", zap.Error(err))
 		return nil, fmt.Errorf("failed to list workflows: %w", err)
 	}
 
-	return &WorkflowListResponse{
+	response := &WorkflowListResponse{
 		Workflows: workflows,
 		Total:     total,
-	}, nil
+	}
+	if filter != nil && filter.Keyset != nil {
+		response.Workflows, response.NextCursor = pagination.Trim(filter.Keyset, workflows, (*Workflow).PageCursor)
+	}
+	return response, nil
 }
 
 // AddWorkflowStep implements the step creation logic
 func (s *service) AddWorkflowStep(ctx context.Context, workflowID uuid.UUID, req CreateWorkflowStepRequest) (*WorkflowStepResponse, error) {
-	s.logger.WithFields(logrus.Fields{
-		"workflow_id": workflowID,
-		"step_name":   req.Name,
-	}).Info("Adding workflow step")
+	s.traceLogger(ctx).Info("Adding workflow step",
+		zap.String("workflow_id", workflowID.String()),
+		zap.String("step_name", req.Name))
 
 	// First check if workflow exists
 	workflow, err := s.repo.GetByID(ctx, workflowID)
@@ -273,6 +344,9 @@ func (s *service) AddWorkflowStep(ctx context.Context, workflowID uuid.UUID, req
 		Conditions:  req.Conditions,
 		IsRequired:  true, // Default to required
 	}
+	if _, err := stepRetryPolicy(step); err != nil {
+		return nil, err
+	}
 
 	if req.Timeout != nil {
 		step.Timeout = req.Timeout
@@ -291,14 +365,14 @@ func (s *service) AddWorkflowStep(ctx context.Context, workflowID uuid.UUID, req
 	}
 
 	if err := s.repo.CreateStep(ctx, step); err != nil {
-		s.logger.WithError(err).Error("Failed to create workflow step")
+		s.traceLogger(ctx).Error("Failed to create workflow step", zap.Error(err))
 		return nil, fmt.Errorf("failed to create workflow step: %w", err)
 	}
 
 	// Update workflow updated_at timestamp
 	workflow.UpdatedAt = time.Now()
 	if err := s.repo.Update(ctx, workflow); err != nil {
-		s.logger.WithError(err).Error("Failed to update workflow timestamp")
+		s.traceLogger(ctx).Error("Failed to update workflow timestamp", zap.Error(err))
 		// Continue even if timestamp update fails
 	}
 
@@ -307,13 +381,11 @@ func (s *service) AddWorkflowStep(ctx context.Context, workflowID uuid.UUID, req
 
 // UpdateWorkflowStep implements the step update logic
 func (s *service) UpdateWorkflowStep(ctx context.Context, id uuid.UUID, req UpdateWorkflowStepRequest) (*WorkflowStepResponse, error) {
-	s.logger.WithFields(logrus.Fields{
-		"step_id": id,
-	}).Info("Updating workflow step")
+	s.traceLogger(ctx).Info("Updating workflow step", zap.String("step_id", id.String()))
 
 	step, err := s.repo.GetStepByID(ctx, id)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to get workflow step for update")
+		s.traceLogger(ctx).Error("Failed to get workflow step for update", zap.Error(err))
 		return nil, fmt.Errorf("failed to get workflow step: %w", err)
 	}
 
@@ -351,9 +423,12 @@ func (s *service) UpdateWorkflowStep(ctx context.Context, id uuid.UUID, req Upda
 	if req.AssignedToRoleID != nil {
 		step.AssignedToRoleID = req.AssignedToRoleID
 	}
+	if _, err := stepRetryPolicy(step); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.UpdateStep(ctx, step); err != nil {
-		s.logger.WithError(err).Error("Failed to update workflow step")
+		s.traceLogger(ctx).Error("Failed to update workflow step", zap.Error(err))
 		return nil, fmt.Errorf("failed to update workflow step: %w", err)
 	}
 
@@ -369,9 +444,7 @@ func (s *service) UpdateWorkflowStep(ctx context.Context, id uuid.UUID, req Upda
 
 // DeleteWorkflowStep implements the step deletion logic
 func (s *service) DeleteWorkflowStep(ctx context.Context, id uuid.UUID) error {
-	s.logger.WithFields(logrus.Fields{
-		"step_id": id,
-	}).Info("Deleting workflow step")
+	s.traceLogger(ctx).Info("Deleting workflow step", zap.String("step_id", id.String()))
 
 	// First check if step exists and get its workflow ID
 	step, err := s.repo.GetStepByID(ctx, id)
@@ -384,14 +457,16 @@ func (s *service) DeleteWorkflowStep(ctx context.Context, id uuid.UUID) error {
 		FromStepID: &id,
 	})
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list transitions from step")
+		s.traceLogger(ctx).Error("Failed to list transitions from step", zap.Error(err))
 		// Continue with deletion even if transition check fails
 	}
 
 	// If there are transitions, delete them first
 	for _, transition := range transitions {
 		if err := s.repo.DeleteTransition(ctx, transition.ID); err != nil {
-			s.logger.WithError(err).WithField("transition_id", transition.ID).Error("Failed to delete transition")
+			s.traceLogger(ctx).Error("Failed to delete transition",
+				zap.Error(err),
+				zap.String("transition_id", transition.ID.String()))
 			// Continue with other transitions
 		}
 	}
@@ -401,20 +476,22 @@ func (s *service) DeleteWorkflowStep(ctx context.Context, id uuid.UUID) error {
 		ToStepID: &id,
 	})
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list transitions to step")
+		s.traceLogger(ctx).Error("Failed to list transitions to step", zap.Error(err))
 		// Continue with deletion even if transition check fails
 	}
 
 	// Delete transitions where this step is a target
 	for _, transition := range toTransitions {
 		if err := s.repo.DeleteTransition(ctx, transition.ID); err != nil {
-			s.logger.WithError(err).WithField("transition_id", transition.ID).Error("Failed to delete transition")
+			s.traceLogger(ctx).Error("Failed to delete transition",
+				zap.Error(err),
+				zap.String("transition_id", transition.ID.String()))
 			// Continue with other transitions
 		}
 	}
 
 	if err := s.repo.DeleteStep(ctx, id); err != nil {
-		s.logger.WithError(err).Error("Failed to delete workflow step")
+		s.traceLogger(ctx).Error("Failed to delete workflow step", zap.Error(err))
 		return fmt.Errorf("failed to delete workflow step: %w", err)
 	}
 
@@ -431,13 +508,11 @@ func (s *service) DeleteWorkflowStep(ctx context.Context, id uuid.UUID) error {
 
 // GetWorkflowStep implements the step retrieval logic
 func (s *service) GetWorkflowStep(ctx context.Context, id uuid.UUID) (*WorkflowStepResponse, error) {
-	s.logger.WithFields(logrus.Fields{
-		"step_id": id,
-	}).Info("Getting workflow step details")
+	s.traceLogger(ctx).Info("Getting workflow step details", zap.String("step_id", id.String()))
 
 	step, err := s.repo.GetStepByID(ctx, id)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to get workflow step")
+		s.traceLogger(ctx).Error("Failed to get workflow step", zap.Error(err))
 		return nil, fmt.Errorf("failed to get workflow step: %w", err)
 	}
 
@@ -446,11 +521,11 @@ func (s *service) GetWorkflowStep(ctx context.Context, id uuid.UUID) (*WorkflowS
 
 // ListWorkflowSteps implements the step listing logic
 func (s *service) ListWorkflowSteps(ctx context.Context, filter *WorkflowStepFilter) (*WorkflowStepListResponse, error) {
-	s.logger.Info("Listing workflow steps")
+	s.traceLogger(ctx).Info("Listing workflow steps")
 
 	steps, total, err := s.repo.ListSteps(ctx, filter)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list workflow steps")
+		s.traceLogger(ctx).Error("Failed to list workflow steps", zap.Error(err))
 		return nil, fmt.Errorf("failed to list workflow steps: %w", err)
 	}
 
@@ -461,22 +536,27 @@ func (s *service) ListWorkflowSteps(ctx context.Context, filter *WorkflowStepFil
 }
 
 // ExecuteWorkflow implements the workflow execution logic
-func (s *service) ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID) (*WorkflowExecutionResponse, error) {
-	s.logger.WithFields(logrus.Fields{
-		"workflow_id": workflowID,
-	}).Info("Executing workflow")
+func (s *service) ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID, input datatypes.JSON) (*WorkflowExecutionResponse, error) {
+	s.traceLogger(ctx).Info("Executing workflow", zap.String("workflow_id", workflowID.String()))
+
+	if len(input) > 0 && !json.Valid(input) {
+		return nil, ErrInvalidExecutionInput
+	}
 
 	// Check if workflow exists
 	workflow, err := s.repo.GetByID(ctx, workflowID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workflow: %w", err)
 	}
+	if workflow.IsTemplate {
+		return nil, ErrTemplateNotExecutable
+	}
 
 	// Update workflow status to active
 	if workflow.Status != WorkflowStatusActive {
 		err = s.repo.UpdateStatus(ctx, workflowID, WorkflowStatusActive)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to update workflow status to active")
+			s.traceLogger(ctx).Error("Failed to update workflow status to active", zap.Error(err))
 			return nil, fmt.Errorf("failed to update workflow status: %w", err)
 		}
 		workflow.Status = WorkflowStatusActive
@@ -488,6 +568,10 @@ func (s *service) ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID) (*W
 		"started_by": "system",
 		"version":    workflow.Version,
 	}
+	if tc := tracing.FromContext(ctx); tc != nil {
+		executionMetadata["request_id"] = tc.RequestID
+		executionMetadata["trace_id"] = tc.TraceID
+	}
 	metadataJSON, _ := json.Marshal(executionMetadata)
 
 	execution := &WorkflowExecution{
@@ -496,22 +580,57 @@ func (s *service) ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID) (*W
 		Status:            WorkflowStatusActive,
 		ExecutionPriority: 0, // Default priority
 		ExecutionMetadata: datatypes.JSON(metadataJSON),
+		Input:             input,
 		StartedAt:         now,
 		UpdatedAt:         now,
 	}
 
 	if err := s.repo.CreateExecution(ctx, execution); err != nil {
-		s.logger.WithError(err).Error("Failed to create workflow execution")
+		s.traceLogger(ctx).Error("Failed to create workflow execution", zap.Error(err))
 		return nil, fmt.Errorf("failed to create workflow execution: %w", err)
 	}
 
+	if s.auditor != nil {
+		s.auditor.Audit(ctx, audit.Event{
+			OrganizationID: workflow.OrganizationID,
+			Action:         audit.ActionWorkflowExecuted,
+			TargetType:     "workflow",
+			TargetID:       &workflow.ID,
+			Metadata: map[string]interface{}{
+				"execution_id": execution.ID,
+				"name":         workflow.Name,
+			},
+		})
+	}
+
+	if s.activity != nil {
+		s.activity.Record(ctx, activity.RecordInput{
+			OrganizationID: workflow.OrganizationID,
+			Type:           activity.EventWorkflowRunStarted,
+			EntityType:     "workflow_execution",
+			EntityID:       execution.ID,
+			Summary:        workflow.Name,
+			Metadata: map[string]interface{}{
+				"workflow_id": workflow.ID,
+			},
+		})
+	}
+	publishExecutionEvent(ctx, s.webhooks, webhooks.EventWorkflowExecutionStarted, workflow, execution)
+	if s.usage != nil {
+		s.usage.Record(metering.Event{
+			OrganizationID: workflow.OrganizationID,
+			Meter:          metering.MeterWorkflowExecutions,
+			Quantity:       1,
+		})
+	}
+
 	// Find first step (lowest step order)
 	stepFilter := &WorkflowStepFilter{
 		WorkflowID: &workflowID,
 	}
 	steps, _, err := s.repo.ListSteps(ctx, stepFilter)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list workflow steps")
+		s.traceLogger(ctx).Error("Failed to list workflow steps", zap.Error(err))
 		return nil, fmt.Errorf("failed to list workflow steps: %w", err)
 	}
 
@@ -521,15 +640,16 @@ func (s *service) ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID) (*W
 		completedTime := time.Now()
 		execution.CompletedAt = &completedTime
 		if err := s.repo.UpdateExecution(ctx, execution); err != nil {
-			s.logger.WithError(err).Error("Failed to update workflow execution")
+			s.traceLogger(ctx).Error("Failed to update workflow execution", zap.Error(err))
 			// Continue even if update fails
 		}
 
 		// Update workflow status
 		if err := s.repo.UpdateStatus(ctx, workflowID, WorkflowStatusCompleted); err != nil {
-			s.logger.WithError(err).Error("Failed to update workflow status to completed")
+			s.traceLogger(ctx).Error("Failed to update workflow status to completed", zap.Error(err))
 			// Continue even if update fails
 		}
+		publishExecutionEvent(ctx, s.webhooks, webhooks.EventWorkflowExecutionFinished, workflow, execution)
 
 		return &WorkflowExecutionResponse{Execution: execution}, nil
 	}
@@ -560,23 +680,28 @@ func (s *service) ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID) (*W
 	}
 
 	if err := s.repo.CreateStepExecution(ctx, stepExecution); err != nil {
-		s.logger.WithError(err).Error("Failed to create step execution")
+		s.traceLogger(ctx).Error("Failed to create step execution", zap.Error(err))
 		return nil, fmt.Errorf("failed to create step execution: %w", err)
 	}
 
 	// Start step execution asynchronously if executor is available
 	if s.executor != nil {
-		go func() {
-			ctx := context.Background() // Use a new context for async execution
+		started := spawn(s.background, ctx, "workflow step", func(ctx context.Context) {
 			if err := s.executor.ExecuteStep(ctx, &firstStep, stepExecution); err != nil {
-				s.logger.WithError(err).Error("Failed to execute workflow step")
+				s.traceLogger(ctx).Error("Failed to execute workflow step", zap.Error(err))
+				if background.Interrupted(ctx) {
+					return
+				}
 				// Update step execution with error
 				stepExecution.Status = StepStatusFailed
 				errorStr := err.Error()
 				stepExecution.Error = &errorStr
 				_ = s.repo.UpdateStepExecution(ctx, stepExecution)
 			}
-		}()
+		})
+		if !started {
+			interruptStep(ctx, s.repo, s.logger, stepExecution)
+		}
 	}
 
 	return &WorkflowExecutionResponse{Execution: execution}, nil
@@ -584,10 +709,9 @@ func (s *service) ExecuteWorkflow(ctx context.Context, workflowID uuid.UUID) (*W
 
 // ExecuteWorkflowStep implements the step execution logic
 func (s *service) ExecuteWorkflowStep(ctx context.Context, stepID uuid.UUID, executionID uuid.UUID) (*WorkflowStepExecution, error) {
-	s.logger.WithFields(logrus.Fields{
-		"step_id":      stepID,
-		"execution_id": executionID,
-	}).Info("Executing workflow step")
+	s.traceLogger(ctx).Info("Executing workflow step",
+		zap.String("step_id", stepID.String()),
+		zap.String("execution_id", executionID.String()))
 
 	// Verify the step exists
 	step, err := s.repo.GetStepByID(ctx, stepID)
@@ -625,20 +749,20 @@ func (s *service) ExecuteWorkflowStep(ctx context.Context, stepID uuid.UUID, exe
 	}
 
 	if err := s.repo.CreateStepExecution(ctx, stepExecution); err != nil {
-		s.logger.WithError(err).Error("Failed to create step execution")
+		s.traceLogger(ctx).Error("Failed to create step execution", zap.Error(err))
 		return nil, fmt.Errorf("failed to create step execution: %w", err)
 	}
 
 	// Execute step if executor is available
 	if s.executor != nil {
 		if err := s.executor.ExecuteStep(ctx, step, stepExecution); err != nil {
-			s.logger.WithError(err).Error("Failed to execute workflow step")
+			s.traceLogger(ctx).Error("Failed to execute workflow step", zap.Error(err))
 			// Update step execution with error
 			stepExecution.Status = StepStatusFailed
 			errorStr := err.Error()
 			stepExecution.Error = &errorStr
 			if err := s.repo.UpdateStepExecution(ctx, stepExecution); err != nil {
-				s.logger.WithError(err).Error("Failed to update step execution status")
+				s.traceLogger(ctx).Error("Failed to update step execution status", zap.Error(err))
 			}
 			return stepExecution, fmt.Errorf("failed to execute workflow step: %w", err)
 		}
@@ -649,9 +773,7 @@ func (s *service) ExecuteWorkflowStep(ctx context.Context, stepID uuid.UUID, exe
 
 // CancelWorkflowExecution implements the execution cancellation logic
 func (s *service) CancelWorkflowExecution(ctx context.Context, workflowID uuid.UUID) error {
-	s.logger.WithFields(logrus.Fields{
-		"workflow_id": workflowID,
-	}).Info("Cancelling workflow execution")
+	s.traceLogger(ctx).Info("Cancelling workflow execution", zap.String("workflow_id", workflowID.String()))
 
 	// Check if workflow exists
 	_, err := s.repo.GetByID(ctx, workflowID)
@@ -661,13 +783,13 @@ func (s *service) CancelWorkflowExecution(ctx context.Context, workflowID uuid.U
 
 	// Cancel active executions
 	if err := s.repo.CancelActiveExecutions(ctx, workflowID); err != nil {
-		s.logger.WithError(err).Error("Failed to cancel active executions")
+		s.traceLogger(ctx).Error("Failed to cancel active executions", zap.Error(err))
 		return fmt.Errorf("failed to cancel active executions: %w", err)
 	}
 
 	// Update workflow status
 	if err := s.repo.UpdateStatus(ctx, workflowID, WorkflowStatusCancelled); err != nil {
-		s.logger.WithError(err).Error("Failed to update workflow status to cancelled")
+		s.traceLogger(ctx).Error("Failed to update workflow status to cancelled", zap.Error(err))
 		return fmt.Errorf("failed to update workflow status: %w", err)
 	}
 
@@ -676,7 +798,7 @@ func (s *service) CancelWorkflowExecution(ctx context.Context, workflowID uuid.U
 
 // GetWorkflowExecution implements the execution retrieval logic
 func (s *service) GetWorkflowExecution(ctx context.Context, executionID uuid.UUID) (*WorkflowExecutionResponse, error) {
-	s.logger.WithField("execution_id", executionID).Info("Getting workflow execution details")
+	s.traceLogger(ctx).Info("Getting workflow execution details", zap.String("execution_id", executionID.String()))
 
 	// Get execution from repository
 	execution, err := s.repo.GetExecutionByID(ctx, executionID)
@@ -688,7 +810,9 @@ func (s *service) GetWorkflowExecution(ctx context.Context, executionID uuid.UUI
 	stepExecutions, err := s.repo.ListStepExecutions(ctx, executionID)
 	if err != nil {
 		// Log error but continue, so we can at least return the main execution info
-		s.logger.WithError(err).WithField("execution_id", executionID).Error("Failed to list step executions")
+		s.traceLogger(ctx).Error("Failed to list step executions",
+			zap.Error(err),
+			zap.String("execution_id", executionID.String()))
 	}
 
 	return &WorkflowExecutionResponse{
@@ -699,11 +823,11 @@ func (s *service) GetWorkflowExecution(ctx context.Context, executionID uuid.UUI
 
 // ListWorkflowExecutions retrieves a paginated list of workflow executions based on a filter
 func (s *service) ListWorkflowExecutions(ctx context.Context, filter *WorkflowExecutionFilter) (*WorkflowExecutionListResponse, error) {
-	s.logger.Info("Listing workflow executions")
+	s.traceLogger(ctx).Info("Listing workflow executions")
 
 	executions, total, err := s.repo.ListExecutions(ctx, filter)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list workflow executions")
+		s.traceLogger(ctx).Error("Failed to list workflow executions", zap.Error(err))
 		return nil, fmt.Errorf("failed to list workflow executions: %w", err)
 	}
 
@@ -715,7 +839,8 @@ func (s *service) ListWorkflowExecutions(ctx context.Context, filter *WorkflowEx
 
 // ApproveStepExecution approves a step execution and processes the next steps.
 func (s *service) ApproveStepExecution(ctx context.Context, executionID, userID uuid.UUID, reason string) error {
-	return s.handleStepApprovalAction(ctx, executionID, userID, reason, true)
+	_, err := s.handleStepApprovalAction(ctx, uuid.Nil, executionID, userID, reason, true)
+	return err
 }
 
 // RejectStepExecution rejects a step execution.
@@ -723,49 +848,82 @@ func (s *service) RejectStepExecution(ctx context.Context, executionID, userID u
 	if reason == "" {
 		return ErrRejectionRequiresReason
 	}
-	return s.handleStepApprovalAction(ctx, executionID, userID, reason, false)
+	_, err := s.handleStepApprovalAction(ctx, uuid.Nil, executionID, userID, reason, false)
+	return err
+}
+
+func (s *service) DecideStep(ctx context.Context, executionID, stepExecutionID, userID uuid.UUID, decision StepDecision) (*WorkflowStepExecution, error) {
+	comment := strings.TrimSpace(decision.Comment)
+	if !decision.Approved && comment == "" {
+		return nil, ErrRejectionRequiresReason
+	}
+	stepExecution, err := s.handleStepApprovalAction(ctx, executionID, stepExecutionID, userID, comment, decision.Approved)
+	if err != nil && stepExecution != nil {
+		// The decision stands even when the next steps could not be started
+		s.traceLogger(ctx).Error("Failed to process transitions after step decision", zap.Error(err))
+		return stepExecution, nil
+	}
+	return stepExecution, err
 }
 
-func (s *service) handleStepApprovalAction(ctx context.Context, executionID, userID uuid.UUID, reason string, approved bool) error {
-	s.logger.WithFields(logrus.Fields{
-		"execution_id": executionID,
-		"user_id":      userID,
-		"approved":     approved,
-	}).Info("Handling step approval action")
+// handleStepApprovalAction records a decision on an approval step execution. When
+// workflowExecutionID is set the step execution must belong to that workflow execution.
+func (s *service) handleStepApprovalAction(ctx context.Context, workflowExecutionID, executionID, userID uuid.UUID, reason string, approved bool) (*WorkflowStepExecution, error) {
+	s.traceLogger(ctx).Info("Handling step approval action",
+		zap.String("execution_id", executionID.String()),
+		zap.String("user_id", userID.String()),
+		zap.Bool("approved", approved))
 
 	// Get the step execution
 	stepExecution, err := s.repo.GetStepExecutionByID(ctx, executionID)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to get step execution")
-		return ErrNotFound
+		s.traceLogger(ctx).Error("Failed to get step execution", zap.Error(err))
+		return nil, ErrNotFound
+	}
+	if workflowExecutionID != uuid.Nil && stepExecution.ExecutionID != workflowExecutionID {
+		return nil, ErrNotFound
 	}
 
 	// Get the step to check type and assignment
 	step, err := s.repo.GetStepByID(ctx, stepExecution.StepID)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to get step")
-		return ErrNotFound
+		s.traceLogger(ctx).Error("Failed to get step", zap.Error(err))
+		return nil, ErrNotFound
 	}
 
 	// Validate step is an approval step and is pending
 	if step.StepType != StepTypeApproval || stepExecution.Status != StepStatusPending {
-		return ErrStepNotApprovable
+		return nil, ErrStepNotApprovable
+	}
+
+	// A cancelled or finished execution has nothing left to move on
+	workflowExecution, err := s.repo.GetExecutionByID(ctx, stepExecution.ExecutionID)
+	if err != nil {
+		s.traceLogger(ctx).Error("Failed to get workflow execution", zap.Error(err))
+		return nil, ErrNotFound
+	}
+	switch workflowExecution.Status {
+	case WorkflowStatusCancelled, WorkflowStatusCompleted, WorkflowStatusFailed:
+		return nil, ErrExecutionNotRunning
 	}
 
 	// Authorization check
 	authorized, err := s.isUserAuthorizedForStep(ctx, step, userID)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to check user authorization for step")
-		return fmt.Errorf("could not verify authorization: %w", err)
+		s.traceLogger(ctx).Error("Failed to check user authorization for step", zap.Error(err))
+		return nil, fmt.Errorf("could not verify authorization: %w", err)
 	}
 	if !authorized {
-		return ErrNotAuthorized
+		return nil, ErrNotAuthorized
 	}
 
 	// Update step execution
 	now := time.Now()
 	stepExecution.CompletedAt = &now
 	stepExecution.UpdatedAt = now
+	stepExecution.DecidedBy = &userID
+	stepExecution.DecidedAt = &now
+	stepExecution.DecisionComment = reason
 
 	result := map[string]interface{}{
 		"action_by":    userID,
@@ -786,17 +944,25 @@ func (s *service) handleStepApprovalAction(ctx context.Context, executionID, use
 	resultJSON, _ := json.Marshal(result)
 	stepExecution.Result = datatypes.JSON(resultJSON)
 
-	if err := s.repo.UpdateStepExecution(ctx, stepExecution); err != nil {
-		s.logger.WithError(err).Error("Failed to update step execution")
-		return err
+	// Only the first of two approvers deciding at once moves the execution on
+	decided, err := s.repo.DecideStepExecution(ctx, stepExecution)
+	if err != nil {
+		s.traceLogger(ctx).Error("Failed to update step execution", zap.Error(err))
+		return nil, err
+	}
+	if !decided {
+		return nil, ErrStepNotApprovable
+	}
+	if err := publishStepEvent(ctx, s.webhooks, s.repo, step, stepExecution); err != nil {
+		s.traceLogger(ctx).Warn("Failed to publish step transition", zap.Error(err))
 	}
 
 	if approved {
 		// Notify the workflow initiator that the step was approved
-		go func() {
-			workflow, err := s.repo.GetByID(context.Background(), step.WorkflowID)
+		spawn(s.background, ctx, "workflow approval notification", func(notifyCtx context.Context) {
+			workflow, err := s.repo.GetByID(notifyCtx, step.WorkflowID)
 			if err != nil {
-				s.logger.WithError(err).Warn("Failed to get workflow for notification")
+				s.traceLogger(ctx).Warn("Failed to get workflow for notification", zap.Error(err))
 				return
 			}
 			if s.notifier != nil {
@@ -807,18 +973,18 @@ func (s *service) handleStepApprovalAction(ctx context.Context, executionID, use
 					"workflowExecutionId": stepExecution.ExecutionID.String(),
 					"stepId":              step.ID.String(),
 				}
-				s.notifier.NotifyUser(context.Background(), workflow.CreatedBy, notification.WorkflowApproved, title, content, data, "workflow", workflow.ID)
+				s.notifier.NotifyUser(notifyCtx, workflow.CreatedBy, notification.WorkflowApproved, title, content, data, "workflow", workflow.ID)
 			}
-		}()
+		})
 
 		// On approval, process the "on_approve" transitions
-		return s.executor.ProcessTransitions(ctx, step, stepExecution, "on_approve")
+		return stepExecution, s.executor.ProcessTransitions(ctx, step, stepExecution, "on_approve")
 	} else {
 		// Notify the workflow initiator that the step was rejected
-		go func() {
-			workflow, err := s.repo.GetByID(context.Background(), step.WorkflowID)
+		spawn(s.background, ctx, "workflow rejection notification", func(notifyCtx context.Context) {
+			workflow, err := s.repo.GetByID(notifyCtx, step.WorkflowID)
 			if err != nil {
-				s.logger.WithError(err).Warn("Failed to get workflow for notification")
+				s.traceLogger(ctx).Warn("Failed to get workflow for notification", zap.Error(err))
 				return
 			}
 			if s.notifier != nil {
@@ -829,12 +995,12 @@ func (s *service) handleStepApprovalAction(ctx context.Context, executionID, use
 					"workflowExecutionId": stepExecution.ExecutionID.String(),
 					"stepId":              step.ID.String(),
 				}
-				s.notifier.NotifyUser(context.Background(), workflow.CreatedBy, notification.WorkflowRejected, title, content, data, "workflow", workflow.ID)
+				s.notifier.NotifyUser(notifyCtx, workflow.CreatedBy, notification.WorkflowRejected, title, content, data, "workflow", workflow.ID)
 			}
-		}()
+		})
 
 		// On rejection, process the "on_reject" transitions
-		return s.executor.ProcessTransitions(ctx, step, stepExecution, "on_reject")
+		return stepExecution, s.executor.ProcessTransitions(ctx, step, stepExecution, "on_reject")
 	}
 }
 
@@ -845,7 +1011,7 @@ func (s *service) isUserAuthorizedForStep(ctx context.Context, step *WorkflowSte
 	}
 
 	// 2. Role-based assignment
-	if step.AssignedToRoleID != nil {
+	if step.AssignedToRoleID != nil && s.rolesService != nil {
 		userRoles, err := s.rolesService.GetUserRoles(ctx, userID)
 		if err != nil {
 			return false, err
@@ -857,15 +1023,14 @@ func (s *service) isUserAuthorizedForStep(ctx context.Context, step *WorkflowSte
 		}
 	}
 
-	// 3. If neither is set, or no match, user is not authorized.
-	return false, nil
+	// 3. Unassigned steps can be decided by anyone allowed to execute the workflow,
+	// which the routes check. Otherwise no match means the user is not authorized.
+	return step.AssignedTo == nil && step.AssignedToRoleID == nil, nil
 }
 
 // AnalyzeWorkflow implements the workflow analysis logic
 func (s *service) AnalyzeWorkflow(ctx context.Context, workflowID uuid.UUID) (map[string]interface{}, error) {
-	s.logger.WithFields(logrus.Fields{
-		"workflow_id": workflowID,
-	}).Info("Analyzing workflow")
+	s.traceLogger(ctx).Info("Analyzing workflow", zap.String("workflow_id", workflowID.String()))
 
 	// Check if workflow exists
 	workflow, err := s.repo.GetByID(ctx, workflowID)
@@ -879,7 +1044,7 @@ func (s *service) AnalyzeWorkflow(ctx context.Context, workflowID uuid.UUID) (ma
 	}
 	steps, _, err := s.repo.ListSteps(ctx, stepFilter)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list workflow steps")
+		s.traceLogger(ctx).Error("Failed to list workflow steps", zap.Error(err))
 		return nil, fmt.Errorf("failed to list workflow steps: %w", err)
 	}
 
@@ -889,7 +1054,7 @@ func (s *service) AnalyzeWorkflow(ctx context.Context, workflowID uuid.UUID) (ma
 	}
 	executions, _, err := s.repo.ListExecutions(ctx, executionFilter)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list workflow executions")
+		s.traceLogger(ctx).Error("Failed to list workflow executions", zap.Error(err))
 		return nil, fmt.Errorf("failed to list workflow executions: %w", err)
 	}
 
@@ -931,9 +1096,7 @@ func (s *service) AnalyzeWorkflow(ctx context.Context, workflowID uuid.UUID) (ma
 
 // OptimizeWorkflow implements the workflow optimization logic
 func (s *service) OptimizeWorkflow(ctx context.Context, workflowID uuid.UUID) (map[string]interface{}, error) {
-	s.logger.WithFields(logrus.Fields{
-		"workflow_id": workflowID,
-	}).Info("Optimizing workflow")
+	s.traceLogger(ctx).Info("Optimizing workflow", zap.String("workflow_id", workflowID.String()))
 
 	// First analyze the workflow
 	analysis, err := s.AnalyzeWorkflow(ctx, workflowID)
@@ -950,7 +1113,7 @@ func (s *service) OptimizeWorkflow(ctx context.Context, workflowID uuid.UUID) (m
 	// Set workflow status to optimizing
 	previousStatus := workflow.Status
 	if err := s.repo.UpdateStatus(ctx, workflowID, WorkflowStatusOptimizing); err != nil {
-		s.logger.WithError(err).Error("Failed to update workflow status to optimizing")
+		s.traceLogger(ctx).Error("Failed to update workflow status to optimizing", zap.Error(err))
 		// Continue with optimization even if status update fails
 	}
 
@@ -960,7 +1123,7 @@ func (s *service) OptimizeWorkflow(ctx context.Context, workflowID uuid.UUID) (m
 	}
 	steps, _, err := s.repo.ListSteps(ctx, stepFilter)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to list workflow steps")
+		s.traceLogger(ctx).Error("Failed to list workflow steps", zap.Error(err))
 		return nil, fmt.Errorf("failed to list workflow steps: %w", err)
 	}
 
@@ -1006,13 +1169,13 @@ func (s *service) OptimizeWorkflow(ctx context.Context, workflowID uuid.UUID) (m
 	workflow.BottleneckAnalysis = datatypes.JSON(bottleneckData)
 
 	if err := s.repo.Update(ctx, workflow); err != nil {
-		s.logger.WithError(err).Error("Failed to update workflow with optimization data")
+		s.traceLogger(ctx).Error("Failed to update workflow with optimization data", zap.Error(err))
 		// Continue even if update fails
 	}
 
 	// Set workflow status back to previous status
 	if err := s.repo.UpdateStatus(ctx, workflowID, previousStatus); err != nil {
-		s.logger.WithError(err).Error("Failed to restore workflow status")
+		s.traceLogger(ctx).Error("Failed to restore workflow status", zap.Error(err))
 		// Continue even if status update fails
 	}
 
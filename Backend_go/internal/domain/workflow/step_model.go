@@ -16,6 +16,9 @@ const (
 	StepStatusCompleted StepStatus = "completed"
 	StepStatusSkipped   StepStatus = "skipped"
 	StepStatusFailed    StepStatus = "failed"
+	StepStatusPaused    StepStatus = "paused"
+	// StepStatusInterrupted steps were stopped by a shutdown and run again on the next start
+	StepStatusInterrupted StepStatus = "interrupted"
 )
 
 type StepType string
@@ -28,6 +31,7 @@ const (
 	StepTypeIntegration  StepType = "integration"
 	StepTypeDecision     StepType = "decision"
 	StepTypeAITask       StepType = "ai_task"
+	StepTypeAction       StepType = "action"
 )
 
 // WorkflowStep represents a step in a workflow
@@ -0,0 +1,195 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+var (
+	ErrTemplateNotExecutable = errors.New("workflow templates cannot be executed; clone the template first")
+	ErrInvalidCloneName      = errors.New("clone name must be at most 255 characters")
+)
+
+// CloneWorkflowRequest describes the copy of a workflow or template
+type CloneWorkflowRequest struct {
+	// Name defaults to the source name followed by "(copy)", or the template name when
+	// instantiating a template
+	Name string
+	// AsTemplate puts the copy in the template library instead of making it runnable
+	AsTemplate bool
+}
+
+// SetWorkflowTemplate adds the workflow to, or removes it from, its organization's
+// template library
+func (s *service) SetWorkflowTemplate(ctx context.Context, id uuid.UUID, isTemplate bool) (*WorkflowResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	if err := s.repo.SetTemplate(ctx, id, isTemplate); err != nil {
+		return nil, fmt.Errorf("failed to update workflow: %w", err)
+	}
+	return s.GetWorkflow(ctx, id)
+}
+
+// ListWorkflowTemplates lists the template library of an organization
+func (s *service) ListWorkflowTemplates(ctx context.Context, orgID uuid.UUID, page, pageSize int) (*WorkflowListResponse, error) {
+	isTemplate := true
+	return s.ListWorkflows(ctx, &WorkflowFilter{
+		OrganizationID: &orgID,
+		IsTemplate:     &isTemplate,
+		Page:           page,
+		PageSize:       pageSize,
+	})
+}
+
+// CloneWorkflow copies the definition of a workflow, its steps and their transitions,
+// under new IDs in one transaction. Run history, metrics and statuses are not copied.
+// References to the source steps in step dependencies, configs and conditions are
+// rewritten to the new steps.
+func (s *service) CloneWorkflow(ctx context.Context, id uuid.UUID, creatorID uuid.UUID, req CloneWorkflowRequest) (*WorkflowResponse, error) {
+	source, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case name == "" && source.IsTemplate && !req.AsTemplate:
+		name = source.Name
+	case name == "":
+		name = source.Name + " (copy)"
+	}
+	if len(name) > 255 {
+		return nil, ErrInvalidCloneName
+	}
+
+	steps, _, err := s.repo.ListSteps(ctx, &WorkflowStepFilter{WorkflowID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow steps: %w", err)
+	}
+	var transitions []WorkflowTransition
+	for _, step := range steps {
+		stepID := step.ID
+		outgoing, _, err := s.repo.ListTransitions(ctx, &WorkflowTransitionFilter{FromStepID: &stepID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workflow transitions: %w", err)
+		}
+		transitions = append(transitions, outgoing...)
+	}
+
+	now := time.Now()
+	clone := &Workflow{
+		ID:                    uuid.New(),
+		Name:                  name,
+		Description:           source.Description,
+		WorkflowType:          source.WorkflowType,
+		CreatedBy:             creatorID,
+		OrganizationID:        source.OrganizationID,
+		Status:                WorkflowStatusPending,
+		Config:                source.Config,
+		WorkflowMetadata:      source.WorkflowMetadata,
+		Version:               source.Version,
+		Tags:                  source.Tags,
+		IsTemplate:            req.AsTemplate,
+		SourceID:              &source.ID,
+		AIEnabled:             source.AIEnabled,
+		AIConfidenceThreshold: source.AIConfidenceThreshold,
+		AIOverrideRules:       source.AIOverrideRules,
+		EstimatedDuration:     source.EstimatedDuration,
+		ScheduleConstraints:   source.ScheduleConstraints,
+		ErrorHandlingConfig:   source.ErrorHandlingConfig,
+		RetryPolicy:           source.RetryPolicy,
+		FallbackSteps:         source.FallbackSteps,
+		ComplianceRules:       source.ComplianceRules,
+		AccessControl:         source.AccessControl,
+	}
+
+	newIDs := make(map[string]string, len(steps))
+	for _, step := range steps {
+		newIDs[step.ID.String()] = uuid.New().String()
+	}
+
+	clonedSteps := make([]WorkflowStep, 0, len(steps))
+	for _, step := range steps {
+		dependencies := make([]string, len(step.Dependencies))
+		for i, dep := range step.Dependencies {
+			if mapped, ok := newIDs[dep]; ok {
+				dep = mapped
+			}
+			dependencies[i] = dep
+		}
+		clonedSteps = append(clonedSteps, WorkflowStep{
+			ID:                 uuid.MustParse(newIDs[step.ID.String()]),
+			WorkflowID:         clone.ID,
+			Name:               step.Name,
+			Description:        step.Description,
+			StepType:           step.StepType,
+			StepOrder:          step.StepOrder,
+			Status:             StepStatusPending,
+			Config:             remapStepIDs(step.Config, newIDs),
+			Conditions:         remapStepIDs(step.Conditions, newIDs),
+			Timeout:            step.Timeout,
+			RetryConfig:        step.RetryConfig,
+			IsRequired:         step.IsRequired,
+			AutoAdvance:        step.AutoAdvance,
+			CanRevert:          step.CanRevert,
+			Dependencies:       dependencies,
+			Version:            step.Version,
+			AssignedTo:         step.AssignedTo,
+			AssignedToRoleID:   step.AssignedToRoleID,
+			NotificationConfig: step.NotificationConfig,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		})
+	}
+
+	clonedTransitions := make([]WorkflowTransition, 0, len(transitions))
+	for _, transition := range transitions {
+		from, okFrom := newIDs[transition.FromStepID.String()]
+		to, okTo := newIDs[transition.ToStepID.String()]
+		// Transitions into steps of other workflows are left behind
+		if !okFrom || !okTo {
+			continue
+		}
+		clonedTransitions = append(clonedTransitions, WorkflowTransition{
+			ID:         uuid.New(),
+			FromStepID: uuid.MustParse(from),
+			ToStepID:   uuid.MustParse(to),
+			Conditions: remapStepIDs(transition.Conditions, newIDs),
+			Triggers:   remapStepIDs(transition.Triggers, newIDs),
+			OnEvent:    transition.OnEvent,
+			CreatedAt:  now,
+		})
+	}
+
+	if err := s.repo.CreateWorkflowGraph(ctx, clone, clonedSteps, clonedTransitions); err != nil {
+		s.traceLogger(ctx).Error("Failed to clone workflow", zap.Error(err))
+		return nil, fmt.Errorf("failed to clone workflow: %w", err)
+	}
+
+	s.traceLogger(ctx).Info("Cloned workflow",
+		zap.String("workflow_id", id.String()),
+		zap.String("clone_id", clone.ID.String()),
+		zap.Int("steps", len(clonedSteps)),
+		zap.Int("transitions", len(clonedTransitions)))
+	return &WorkflowResponse{Workflow: clone}, nil
+}
+
+// remapStepIDs rewrites the source step IDs in a JSON document to the IDs of their clones
+func remapStepIDs(data datatypes.JSON, newIDs map[string]string) datatypes.JSON {
+	if len(data) == 0 {
+		return data
+	}
+	document := string(data)
+	for oldID, newID := range newIDs {
+		document = strings.ReplaceAll(document, oldID, newID)
+	}
+	return datatypes.JSON(document)
+}
@@ -0,0 +1,172 @@
+package workitems
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workflow"
+	"github.com/google/uuid"
+)
+
+// ErrForbidden is returned when an action targets an item outside the workflow's
+// organization or owned by someone other than the workflow's creator
+var ErrForbidden = errors.New("work item belongs to someone else")
+
+type service struct {
+	taskService     task.Service
+	todoService     todos.Service
+	calendarService calendar.Service
+}
+
+// NewService creates the work item service that runs workflow action steps against the
+// task, todo and calendar services
+func NewService(taskService task.Service, todoService todos.Service, calendarService calendar.Service) workflow.WorkItems {
+	return &service{
+		taskService:     taskService,
+		todoService:     todoService,
+		calendarService: calendarService,
+	}
+}
+
+// Apply creates or updates the item described by req and returns its ID
+func (s *service) Apply(ctx context.Context, req workflow.WorkItemRequest) (uuid.UUID, error) {
+	switch req.Action {
+	case workflow.ActionCreateTask:
+		return s.createTask(ctx, req)
+	case workflow.ActionUpdateTask:
+		return s.updateTask(ctx, req)
+	case workflow.ActionCreateTodo:
+		return s.createTodo(ctx, req)
+	case workflow.ActionUpdateTodo:
+		return s.updateTodo(ctx, req)
+	case workflow.ActionCreateEvent:
+		return s.createEvent(ctx, req)
+	case workflow.ActionUpdateEvent:
+		return s.updateEvent(ctx, req)
+	}
+	return uuid.Nil, fmt.Errorf("%w: unknown type %q", workflow.ErrInvalidAction, req.Action)
+}
+
+func (s *service) createTask(ctx context.Context, req workflow.WorkItemRequest) (uuid.UUID, error) {
+	var input task.CreateTaskInput
+	if err := decode(req.Fields, &input); err != nil {
+		return uuid.Nil, err
+	}
+	input.OrganizationID = req.OrganizationID
+	input.CreatorID = req.ActorID
+	if input.StartDate.IsZero() {
+		input.StartDate = time.Now()
+	}
+	created, err := s.taskService.CreateTask(ctx, input)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return created.ID, nil
+}
+
+func (s *service) updateTask(ctx context.Context, req workflow.WorkItemRequest) (uuid.UUID, error) {
+	existing, err := s.taskService.GetTask(ctx, req.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if existing.OrganizationID != req.OrganizationID {
+		return uuid.Nil, ErrForbidden
+	}
+	var input task.UpdateTaskInput
+	if err := decode(req.Fields, &input); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := s.taskService.UpdateTask(ctx, req.ID, input); err != nil {
+		return uuid.Nil, err
+	}
+	return req.ID, nil
+}
+
+func (s *service) createTodo(ctx context.Context, req workflow.WorkItemRequest) (uuid.UUID, error) {
+	var input todos.CreateTodoInput
+	if err := decode(req.Fields, &input); err != nil {
+		return uuid.Nil, err
+	}
+	input.UserID = req.ActorID
+	if input.ListID == uuid.Nil {
+		list, err := s.todoService.GetOrCreateDefaultList(ctx, req.ActorID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		input.ListID = list.ID
+	}
+	created, err := s.todoService.CreateTodo(ctx, input)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return created.ID, nil
+}
+
+func (s *service) updateTodo(ctx context.Context, req workflow.WorkItemRequest) (uuid.UUID, error) {
+	existing, err := s.todoService.GetTodo(ctx, req.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if existing.UserID != req.ActorID {
+		return uuid.Nil, ErrForbidden
+	}
+	var input todos.UpdateTodoInput
+	if err := decode(req.Fields, &input); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := s.todoService.UpdateTodo(ctx, req.ID, input); err != nil {
+		return uuid.Nil, err
+	}
+	return req.ID, nil
+}
+
+func (s *service) createEvent(ctx context.Context, req workflow.WorkItemRequest) (uuid.UUID, error) {
+	var input calendar.CreateCalendarEventRequest
+	if err := decode(req.Fields, &input); err != nil {
+		return uuid.Nil, err
+	}
+	if input.EventType == "" {
+		input.EventType = calendar.EventTypeTask
+	}
+	created, err := s.calendarService.CreateEvent(ctx, input, req.ActorID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return created.ID, nil
+}
+
+func (s *service) updateEvent(ctx context.Context, req workflow.WorkItemRequest) (uuid.UUID, error) {
+	existing, err := s.calendarService.GetEventByID(ctx, req.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if existing.UserID != req.ActorID {
+		return uuid.Nil, ErrForbidden
+	}
+	var input calendar.UpdateCalendarEventRequest
+	if err := decode(req.Fields, &input); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := s.calendarService.UpdateEvent(ctx, req.ID, input); err != nil {
+		return uuid.Nil, err
+	}
+	return req.ID, nil
+}
+
+// decode reads rendered action fields into a service input through its JSON form
+func decode(fields map[string]interface{}, input interface{}) error {
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, input); err != nil {
+		return fmt.Errorf("%w: %v", workflow.ErrInvalidAction, err)
+	}
+	return nil
+}
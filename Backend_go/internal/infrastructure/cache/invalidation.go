@@ -0,0 +1,18 @@
+package cache
+
+import "context"
+
+// Cached API resources, named like the path segment their responses are cached under
+const (
+	EntityTasks     = "tasks"
+	EntityTodos     = "todos"
+	EntityTodoLists = "todo-lists"
+	EntityProjects  = "projects"
+)
+
+// ChangeNotifier is told by the domain services whenever they write an entity, so cached
+// reads of it are dropped right away instead of when they expire. Writes that do not come
+// through an API route, such as scheduled jobs and integrations, are covered as well.
+type ChangeNotifier interface {
+	EntityChanged(ctx context.Context, entities ...string)
+}
@@ -510,6 +510,33 @@ func (r *RedisClient) ClearByPattern(ctx context.Context, pattern string) error
 	return nil
 }
 
+// ScanKeys returns up to limit cache keys matching the given pattern, without the key prefix
+func (r *RedisClient) ScanKeys(ctx context.Context, pattern string, limit int) ([]string, error) {
+	if !r.IsHealthy() {
+		return nil, ErrCacheConnection
+	}
+
+	ctx, cancel := r.withContext(ctx)
+	defer cancel()
+
+	prefixedPattern := r.prefixKey(pattern)
+	iter := r.client.Scan(ctx, 0, prefixedPattern, 100).Iterator()
+	keys := make([]string, 0)
+
+	for iter.Next(ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), r.config.KeyPrefix))
+		if limit > 0 && len(keys) >= limit {
+			break
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
 // GenerateCacheKey creates a unique cache key for the given entity
 func GenerateCacheKey(entityType string, entityID interface{}, action string) string {
 	if entityType == "dashboard" {
@@ -0,0 +1,235 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Providers accepted in Config.Provider
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSendGrid = "sendgrid"
+	ProviderPostmark = "postmark"
+)
+
+var ErrUnknownProvider = errors.New("unknown email provider")
+
+// Message is a single email with an HTML body and its plain text alternative
+type Message struct {
+	To      Address
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Address is an email address with an optional display name
+type Address struct {
+	Name  string
+	Email string
+}
+
+func (a Address) String() string {
+	return (&mail.Address{Name: a.Name, Address: a.Email}).String()
+}
+
+// Sender delivers email through a provider
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures the email provider. Email is only logged when Provider is empty.
+type Config struct {
+	Provider string
+	From     Address
+	// AppURL is the web app base used for links in emails
+	AppURL string
+	SMTP   SMTPConfig
+	// APIKey authenticates with the SendGrid and Postmark APIs
+	APIKey string
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// NewSender creates the sender for the configured provider
+func NewSender(config Config, logger *zap.Logger) (Sender, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch config.Provider {
+	case "":
+		return &logSender{logger: logger}, nil
+	case ProviderSMTP:
+		return &smtpSender{config: config.SMTP, from: config.From}, nil
+	case ProviderSendGrid:
+		return &sendGridSender{apiKey: config.APIKey, from: config.From, client: client}, nil
+	case ProviderPostmark:
+		return &postmarkSender{apiKey: config.APIKey, from: config.From, client: client}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, config.Provider)
+	}
+}
+
+// logSender stands in for a provider in development
+type logSender struct {
+	logger *zap.Logger
+}
+
+func (s *logSender) Send(ctx context.Context, msg Message) error {
+	s.logger.Info("Email not sent, no provider configured",
+		zap.String("to", msg.To.Email),
+		zap.String("subject", msg.Subject))
+	return nil
+}
+
+type smtpSender struct {
+	config SMTPConfig
+	from   Address
+}
+
+// Send delivers the message with STARTTLS when the server offers it
+func (s *smtpSender) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+	body, err := s.encode(msg)
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	if err := smtp.SendMail(addr, auth, s.from.Email, []string{msg.To.Email}, body); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return nil
+}
+
+// encode builds a multipart/alternative message with the text part first, as RFC 2046 asks
+func (s *smtpSender) encode(msg Message) ([]byte, error) {
+	boundaryBytes := make([]byte, 12)
+	if _, err := rand.Read(boundaryBytes); err != nil {
+		return nil, err
+	}
+	boundary := hex.EncodeToString(boundaryBytes)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		w := quotedprintable.NewWriter(&buf)
+		if _, err := w.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+type sendGridSender struct {
+	apiKey string
+	from   Address
+	client *http.Client
+}
+
+func (s *sendGridSender) Send(ctx context.Context, msg Message) error {
+	type address struct {
+		Email string `json:"email"`
+		Name  string `json:"name,omitempty"`
+	}
+	type content struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	payload := map[string]interface{}{
+		"personalizations": []map[string]interface{}{
+			{"to": []address{{Email: msg.To.Email, Name: msg.To.Name}}},
+		},
+		"from":    address{Email: s.from.Email, Name: s.from.Name},
+		"subject": msg.Subject,
+		"content": []content{{Type: "text/plain", Value: msg.Text}, {Type: "text/html", Value: msg.HTML}},
+	}
+	return post(ctx, s.client, "https://api.sendgrid.com/v3/mail/send", map[string]string{
+		"Authorization": "Bearer " + s.apiKey,
+	}, payload)
+}
+
+type postmarkSender struct {
+	apiKey string
+	from   Address
+	client *http.Client
+}
+
+func (s *postmarkSender) Send(ctx context.Context, msg Message) error {
+	return post(ctx, s.client, "https://api.postmarkapp.com/email", map[string]string{
+		"X-Postmark-Server-Token": s.apiKey,
+		"Accept":                  "application/json",
+	}, map[string]string{
+		"From":          s.from.String(),
+		"To":            msg.To.String(),
+		"Subject":       msg.Subject,
+		"HtmlBody":      msg.HTML,
+		"TextBody":      msg.Text,
+		"MessageStream": "outbound",
+	})
+}
+
+// post sends payload as JSON to a provider API and fails on any non-2xx response
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("email provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// Template names
+const (
+	TemplateNotification     = "notification"
+	TemplateHabitReminder    = "habit_reminder"
+	TemplateTaskAssigned     = "task_assigned"
+	TemplateWorkflowApproval = "workflow_approval"
+	TemplatePasswordReset    = "password_reset"
+	TemplateOrgInvitation    = "organization_invitation"
+)
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Data is passed to every template. Fields holds template specific values.
+type Data struct {
+	AppURL        string
+	RecipientName string
+	Title         string
+	Content       string
+	ActionURL     string
+	ActionLabel   string
+	Fields        map[string]string
+}
+
+// Mailer renders templated emails and hands them to a Sender
+type Mailer struct {
+	sender    Sender
+	from      Address
+	appURL    string
+	templates map[string]*template.Template
+}
+
+// NewMailer parses the embedded templates, each rendered inside the shared layout
+func NewMailer(sender Sender, config Config) (*Mailer, error) {
+	files, err := fs.Glob(templateFS, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	templates := make(map[string]*template.Template)
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".html")
+		if name == "layout" {
+			continue
+		}
+		t, err := template.ParseFS(templateFS, "templates/layout.html", file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse email template %s: %w", name, err)
+		}
+		templates[name] = t
+	}
+	return &Mailer{
+		sender:    sender,
+		from:      config.From,
+		appURL:    strings.TrimRight(config.AppURL, "/"),
+		templates: templates,
+	}, nil
+}
+
+// Send renders the named template with data and sends it to the recipient. text is the
+// plain text alternative of the HTML body.
+func (m *Mailer) Send(ctx context.Context, to Address, subject, name string, data Data, text string) error {
+	t, ok := m.templates[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+	data.AppURL = m.appURL
+	if data.RecipientName == "" {
+		data.RecipientName = to.Name
+	}
+	if data.Title == "" {
+		data.Title = subject
+	}
+
+	var html bytes.Buffer
+	if err := t.ExecuteTemplate(&html, "layout.html", data); err != nil {
+		return fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+	return m.sender.Send(ctx, Message{To: to, Subject: subject, HTML: html.String(), Text: text})
+}
+
+// URL returns the absolute web app URL of path
+func (m *Mailer) URL(path string) string {
+	return m.appURL + "/" + strings.TrimLeft(path, "/")
+}
+
+// SendPasswordReset emails a password reset link that stops working after expiresIn
+func (m *Mailer) SendPasswordReset(ctx context.Context, to Address, resetURL string, expiresIn time.Duration) error {
+	subject := "Reset your Compass password"
+	text := fmt.Sprintf("Someone asked to reset the password of your Compass account. "+
+		"Open this link within %s to choose a new password:\n\n%s\n\n"+
+		"If it was not you, ignore this email and your password stays the same.", expiresIn, resetURL)
+	return m.Send(ctx, to, subject, TemplatePasswordReset, Data{
+		ActionURL:   resetURL,
+		ActionLabel: "Choose a new password",
+		Fields:      map[string]string{"expiresIn": expiresIn.String()},
+	}, text)
+}
+
+// SendOrganizationInvitation emails an invitation to join an organization with the given role
+func (m *Mailer) SendOrganizationInvitation(ctx context.Context, to Address, organization, role, acceptURL string, expiresAt time.Time) error {
+	subject := fmt.Sprintf("You are invited to join %s on Compass", organization)
+	expires := expiresAt.UTC().Format("January 2, 2006")
+	text := fmt.Sprintf("You were invited to join %s on Compass as %s. "+
+		"Open this link and sign in with this email address to accept:\n\n%s\n\n"+
+		"The invitation expires on %s.", organization, role, acceptURL, expires)
+	return m.Send(ctx, to, subject, TemplateOrgInvitation, Data{
+		ActionURL:   acceptURL,
+		ActionLabel: "Accept invitation",
+		Fields: map[string]string{
+			"organization": organization,
+			"role":         role,
+			"expiresAt":    expires,
+		},
+	}, text)
+}
@@ -0,0 +1,207 @@
+// Package anonymize copies a database into another with personal data scrambled, so that
+// staging can run on production-shaped data. Row counts, IDs, timestamps and every column
+// without personal data are kept, which preserves references between tables and the
+// distributions performance tests depend on.
+package anonymize
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/migrations"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const defaultBatchSize = 1000
+
+// ErrSameDatabase is returned when the source and target are the same database
+var ErrSameDatabase = errors.New("source and target are the same database")
+
+// Options configures a copy
+type Options struct {
+	// Salt keys the replacements. Runs with the same salt replace each value the same way;
+	// a random salt is used when it is empty.
+	Salt string
+	// Password becomes the password of every account in the copy. When it is empty nobody
+	// can sign in with a password.
+	Password  string
+	BatchSize int
+}
+
+// TableResult reports how many rows of a table were copied
+type TableResult struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+}
+
+// Anonymizer copies the tables managed by the migrations from one database into another
+type Anonymizer struct {
+	source       *gorm.DB
+	target       *gorm.DB
+	salt         []byte
+	passwordHash string
+	batchSize    int
+	logger       *zap.Logger
+}
+
+// New creates an anonymizer. The target must already have the schema.
+func New(source, target *gorm.DB, opts Options, logger *zap.Logger) (*Anonymizer, error) {
+	a := &Anonymizer{
+		source:       source,
+		target:       target,
+		salt:         []byte(opts.Salt),
+		passwordHash: "!anonymized",
+		batchSize:    opts.BatchSize,
+		logger:       logger,
+	}
+	if a.batchSize <= 0 {
+		a.batchSize = defaultBatchSize
+	}
+	if len(a.salt) == 0 {
+		a.salt = make([]byte, 32)
+		if _, err := rand.Read(a.salt); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Password != "" {
+		hash, err := auth.HashPassword(opts.Password)
+		if err != nil {
+			return nil, err
+		}
+		a.passwordHash = hash
+	}
+	return a, nil
+}
+
+// Run replaces the contents of the target's tables with a scrambled copy of the source.
+// The source is read from a single snapshot and the target is written in one transaction,
+// so the copy is consistent and a failed run leaves the target as it was.
+func (a *Anonymizer) Run(ctx context.Context) ([]TableResult, error) {
+	if err := a.checkDistinct(ctx); err != nil {
+		return nil, err
+	}
+
+	schemas, err := a.parseModels()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []TableResult
+	err = a.source.WithContext(ctx).Transaction(func(src *gorm.DB) error {
+		return a.target.WithContext(ctx).Transaction(func(dst *gorm.DB) error {
+			// Skip foreign key checks while loading; the rows come from a consistent snapshot
+			if err := dst.Exec("SET LOCAL session_replication_role = replica").Error; err != nil {
+				return fmt.Errorf("failed to disable foreign key checks on target: %w", err)
+			}
+			if err := truncate(dst, schemas); err != nil {
+				return err
+			}
+			for _, s := range schemas {
+				rows, err := a.copyTable(src, dst, s)
+				if err != nil {
+					return fmt.Errorf("failed to copy %s: %w", s.Table, err)
+				}
+				a.logger.Info("Copied table", zap.String("table", s.Table), zap.Int64("rows", rows))
+				results = append(results, TableResult{Table: s.Table, Rows: rows})
+			}
+			return nil
+		})
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// checkDistinct refuses to run when both connections point at the same database,
+// since the target is truncated
+func (a *Anonymizer) checkDistinct(ctx context.Context) error {
+	const query = "SELECT current_database() || '@' || COALESCE(inet_server_addr()::text, 'local') || ':' || COALESCE(inet_server_port()::text, '')"
+	var source, target string
+	if err := a.source.WithContext(ctx).Raw(query).Scan(&source).Error; err != nil {
+		return err
+	}
+	if err := a.target.WithContext(ctx).Raw(query).Scan(&target).Error; err != nil {
+		return err
+	}
+	if source == target {
+		return ErrSameDatabase
+	}
+	return nil
+}
+
+// parseModels returns the schemas of the migrated models, in migration order
+func (a *Anonymizer) parseModels() ([]*schema.Schema, error) {
+	var schemas []*schema.Schema
+	for _, model := range migrations.Models() {
+		stmt := &gorm.Statement{DB: a.source}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse %T: %w", model, err)
+		}
+		schemas = append(schemas, stmt.Schema)
+	}
+	return schemas, nil
+}
+
+func truncate(dst *gorm.DB, schemas []*schema.Schema) error {
+	tables := make([]string, 0, len(schemas))
+	for _, s := range schemas {
+		tables = append(tables, dst.Statement.Quote(s.Table))
+	}
+	if err := dst.Exec("TRUNCATE " + strings.Join(tables, ", ") + " CASCADE").Error; err != nil {
+		return fmt.Errorf("failed to truncate target: %w", err)
+	}
+	return nil
+}
+
+// copyTable copies a table in primary key order, scrambling each batch before inserting it
+func (a *Anonymizer) copyTable(src, dst *gorm.DB, s *schema.Schema) (int64, error) {
+	columns := s.DBNames
+	order := make([]clause.OrderByColumn, 0, len(s.PrimaryFieldDBNames))
+	for _, name := range s.PrimaryFieldDBNames {
+		order = append(order, clause.OrderByColumn{Column: clause.Column{Name: name}})
+	}
+
+	var copied int64
+	for offset := 0; ; offset += a.batchSize {
+		var rows []map[string]interface{}
+		query := src.Table(s.Table).Select(columns)
+		if len(order) > 0 {
+			query = query.Order(clause.OrderBy{Columns: order})
+		}
+		if err := query.Limit(a.batchSize).Offset(offset).Find(&rows).Error; err != nil {
+			return copied, err
+		}
+		if len(rows) == 0 {
+			return copied, nil
+		}
+
+		for _, row := range rows {
+			a.scrub(row)
+		}
+		if err := dst.Table(s.Table).Create(&rows).Error; err != nil {
+			return copied, err
+		}
+		copied += int64(len(rows))
+
+		if len(rows) < a.batchSize {
+			return copied, nil
+		}
+	}
+}
+
+func (a *Anonymizer) scrub(row map[string]interface{}) {
+	for column, value := range row {
+		if r, ok := columnRules[column]; ok {
+			row[column] = r(a, value)
+		}
+	}
+}
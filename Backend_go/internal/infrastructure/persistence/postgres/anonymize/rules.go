@@ -0,0 +1,204 @@
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// rule replaces the value of a column. Values are replaced deterministically, so the same
+// email in users.email and inbound_messages.sender is replaced by the same address.
+type rule func(a *Anonymizer, value interface{}) interface{}
+
+// columnRules lists the columns holding personal data or credentials, by column name.
+// Columns not listed are copied as they are.
+var columnRules = map[string]rule{
+	// Contact details
+	"email":           stringRule(scrambleEmail),
+	"sender":          stringRule(scrambleEmail),
+	"invited_emails":  arrayRule(scrambleEmail),
+	"allowed_senders": arrayRule(scrambleEmail),
+	"phone_number":    stringRule(scramblePhone),
+	"ip_address":      stringRule(scrambleIP),
+
+	// Identity
+	"username":         stringRule(scrambleUsername),
+	"first_name":       stringRule(scrambleFirstName),
+	"given_name":       stringRule(scrambleFirstName),
+	"last_name":        stringRule(scrambleLastName),
+	"family_name":      stringRule(scrambleLastName),
+	"bio":              stringRule(scrambleText),
+	"avatar_url":       clearRule,
+	"provider_id":      stringRule(scrambleToken),
+	"provider_data":    clearRule,
+	"external_user_id": stringRule(scrambleToken),
+
+	// Credentials
+	"password_hash":    passwordRule,
+	"mfa_enabled":      clearRule,
+	"mfa_secret":       clearRule,
+	"mfa_backup_codes": clearRule,
+	"access_token":     stringRule(scrambleToken),
+	"refresh_token":    stringRule(scrambleToken),
+	"token":            stringRule(scrambleToken),
+	"token_hash":       stringRule(scrambleToken),
+	"key_hash":         stringRule(scrambleToken),
+	"secret":           stringRule(scrambleToken),
+	"signing_secret":   stringRule(scrambleToken),
+	"webhook_secret":   stringRule(scrambleToken),
+}
+
+var firstNames = []string{
+	"James", "Mary", "Ahmed", "Fatima", "Wei", "Mei", "Carlos", "Lucia", "Ivan", "Olga",
+	"Kenji", "Yuki", "Liam", "Emma", "Noah", "Olivia", "Omar", "Layla", "Raj", "Priya",
+	"Lucas", "Sofia", "Mateo", "Valentina", "Ethan", "Chloe", "Hassan", "Nour", "Daniel", "Sarah",
+	"Jonas", "Lena", "Kwame", "Amara", "Diego", "Camila", "Arjun", "Ananya", "Felix", "Hannah",
+}
+
+var lastNames = []string{
+	"Smith", "Johnson", "Hassan", "Ali", "Wang", "Li", "Garcia", "Martinez", "Ivanov", "Petrova",
+	"Tanaka", "Sato", "Brown", "Wilson", "Taylor", "Anderson", "Khan", "Mahmoud", "Patel", "Sharma",
+	"Silva", "Santos", "Rossi", "Bianchi", "Muller", "Schmidt", "Mensah", "Okafor", "Nguyen", "Tran",
+	"Kim", "Park", "Cohen", "Levi", "Dubois", "Moreau", "Novak", "Horvat", "Larsen", "Berg",
+}
+
+const fillerText = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. "
+
+// stringRule applies a scrambler to text columns, leaving empty values empty
+func stringRule(scramble func(a *Anonymizer, s string) string) rule {
+	return func(a *Anonymizer, value interface{}) interface{} {
+		s, ok := textValue(value)
+		if !ok || s == "" {
+			return value
+		}
+		return scramble(a, s)
+	}
+}
+
+// arrayRule applies a scrambler to each element of a text[] column
+func arrayRule(scramble func(a *Anonymizer, s string) string) rule {
+	return func(a *Anonymizer, value interface{}) interface{} {
+		if value == nil {
+			return value
+		}
+		var items pq.StringArray
+		if err := items.Scan(value); err != nil {
+			return value
+		}
+		for i, item := range items {
+			if item != "" {
+				items[i] = scramble(a, item)
+			}
+		}
+		return items
+	}
+}
+
+// clearRule empties a column, keeping NOT NULL text and boolean columns valid
+func clearRule(a *Anonymizer, value interface{}) interface{} {
+	switch value.(type) {
+	case string, []byte:
+		return ""
+	case bool:
+		return false
+	default:
+		return nil
+	}
+}
+
+// passwordRule gives every account the same password, so the copy can be signed into
+func passwordRule(a *Anonymizer, value interface{}) interface{} {
+	return a.passwordHash
+}
+
+func textValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	default:
+		return "", false
+	}
+}
+
+// digest keys a value by kind, so values of the same kind get the same replacement in every table
+func (a *Anonymizer) digest(kind, value string) []byte {
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func (a *Anonymizer) pick(kind, value string, options []string) string {
+	return options[binary.BigEndian.Uint32(a.digest(kind, value))%uint32(len(options))]
+}
+
+// scrambleEmail keeps addresses that shared a domain on a shared domain, so organizations
+// still group their members the same way
+func scrambleEmail(a *Anonymizer, email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	domain := "example.com"
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		domain = email[at+1:]
+	}
+	local := hex.EncodeToString(a.digest("email", email))[:12]
+	host := hex.EncodeToString(a.digest("domain", domain))[:8]
+	return fmt.Sprintf("user-%s@d%s.example.com", local, host)
+}
+
+func scrambleUsername(a *Anonymizer, username string) string {
+	return "user_" + hex.EncodeToString(a.digest("username", strings.ToLower(username)))[:12]
+}
+
+func scrambleFirstName(a *Anonymizer, name string) string {
+	return a.pick("first_name", name, firstNames)
+}
+
+func scrambleLastName(a *Anonymizer, name string) string {
+	return a.pick("last_name", name, lastNames)
+}
+
+// scramblePhone replaces the digits after the country prefix, keeping the length and formatting
+func scramblePhone(a *Anonymizer, phone string) string {
+	sum := a.digest("phone", phone)
+	out := []byte(phone)
+	kept := 0
+	for i, ch := range out {
+		if ch < '0' || ch > '9' {
+			continue
+		}
+		if kept < 2 {
+			kept++
+			continue
+		}
+		out[i] = '0' + sum[i%len(sum)]%10
+	}
+	return string(out)
+}
+
+// scrambleIP maps addresses into private ranges
+func scrambleIP(a *Anonymizer, ip string) string {
+	sum := a.digest("ip", ip)
+	if strings.Contains(ip, ":") {
+		return fmt.Sprintf("fd00::%x:%x", binary.BigEndian.Uint16(sum), binary.BigEndian.Uint16(sum[2:]))
+	}
+	return fmt.Sprintf("10.%d.%d.%d", sum[0], sum[1], sum[2])
+}
+
+// scrambleToken replaces a token with another of the same length, keeping columns unique
+func scrambleToken(a *Anonymizer, token string) string {
+	sum := hex.EncodeToString(a.digest("token", token))
+	return strings.Repeat(sum, len(token)/len(sum)+1)[:len(token)]
+}
+
+// scrambleText replaces free text with filler of the same length
+func scrambleText(a *Anonymizer, text string) string {
+	return strings.Repeat(fillerText, len(text)/len(fillerText)+1)[:len(text)]
+}
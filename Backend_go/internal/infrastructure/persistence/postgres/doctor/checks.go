@@ -0,0 +1,48 @@
+package doctor
+
+// checks are the consistency rules, in the order they run. Rows in the trash are left
+// alone: the trash purge removes them, and restoring them is the user's call.
+var checks = []Check{
+	{
+		Name:        "tasks_missing_project",
+		Description: "tasks whose project no longer exists",
+		Find: `SELECT t.id FROM tasks t
+			WHERE t.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM projects p WHERE p.id = t.project_id)`,
+		Repair:      "moved to the trash",
+		RepairQuery: `UPDATE tasks SET deleted_at = NOW() WHERE id IN (%s)`,
+	},
+	{
+		Name:        "tasks_missing_parent",
+		Description: "subtasks whose parent task no longer exists",
+		Find: `SELECT t.id FROM tasks t
+			WHERE t.deleted_at IS NULL AND t.parent_task_id IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM tasks p WHERE p.id = t.parent_task_id)`,
+		Repair:      "made top-level tasks",
+		RepairQuery: `UPDATE tasks SET parent_task_id = NULL WHERE id IN (%s)`,
+	},
+	{
+		Name:        "occurrences_missing_event",
+		Description: "calendar occurrences whose event no longer exists",
+		Find: `SELECT o.id FROM event_occurrences o
+			WHERE NOT EXISTS (SELECT 1 FROM calendar_events e WHERE e.id = o.event_id)`,
+		Repair:      "deleted",
+		RepairQuery: `DELETE FROM event_occurrences WHERE id IN (%s)`,
+	},
+	{
+		Name:        "exceptions_missing_event",
+		Description: "calendar exceptions whose event no longer exists",
+		Find: `SELECT x.id FROM event_exceptions x
+			WHERE NOT EXISTS (SELECT 1 FROM calendar_events e WHERE e.id = x.event_id)`,
+		Repair:      "deleted",
+		RepairQuery: `DELETE FROM event_exceptions WHERE id IN (%s)`,
+	},
+	{
+		Name:        "memberships_deleted_organization",
+		Description: "organization memberships of missing or deleted organizations",
+		Find: `SELECT m.id FROM organization_members m
+			WHERE NOT EXISTS (SELECT 1 FROM organizations o WHERE o.id = m.organization_id AND o.deleted_at IS NULL)`,
+		Repair:      "deleted",
+		RepairQuery: `DELETE FROM organization_members WHERE id IN (%s)`,
+	},
+}
@@ -0,0 +1,144 @@
+// Package doctor finds rows that reference records which no longer exist, such as tasks
+// of a deleted project, and can repair them. The schema has few foreign keys, so such
+// rows are left behind by partial failures and manual fixes rather than rejected.
+package doctor
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const defaultSampleSize = 10
+
+// Check is a consistency rule
+type Check struct {
+	Name        string
+	Description string
+	// Find selects the IDs of the inconsistent rows
+	Find string
+	// Repair says what repairing does to the rows
+	Repair string
+	// RepairQuery repairs the rows whose IDs the %s subquery selects
+	RepairQuery string
+}
+
+// Finding reports the inconsistent rows a check found
+type Finding struct {
+	Check       string   `json:"check"`
+	Description string   `json:"description"`
+	Count       int64    `json:"count"`
+	Sample      []string `json:"sample,omitempty"`
+	// Repaired is how many rows were repaired; Repair says how
+	Repaired int64  `json:"repaired"`
+	Repair   string `json:"repair,omitempty"`
+}
+
+// Options configures a run
+type Options struct {
+	// Fix repairs the rows the checks find
+	Fix bool
+	// Only limits the run to the named checks; all checks run when it is empty
+	Only []string
+	// SampleSize is how many IDs of inconsistent rows each finding lists
+	SampleSize int
+}
+
+// Checks returns the names of the available checks
+func Checks() []string {
+	names := make([]string, len(checks))
+	for i, check := range checks {
+		names[i] = check.Name
+	}
+	return names
+}
+
+// Doctor runs the consistency checks against a database
+type Doctor struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// New creates a doctor for the database
+func New(db *gorm.DB, logger *zap.Logger) *Doctor {
+	return &Doctor{db: db, logger: logger}
+}
+
+// Run runs the checks and returns one finding per check, repairing what they find when
+// opts.Fix is set. Each check is repaired in its own transaction.
+func (d *Doctor) Run(ctx context.Context, opts Options) ([]Finding, error) {
+	selected, err := selectChecks(opts.Only)
+	if err != nil {
+		return nil, err
+	}
+	sampleSize := opts.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultSampleSize
+	}
+
+	findings := make([]Finding, 0, len(selected))
+	for _, check := range selected {
+		finding, err := d.run(ctx, check, opts.Fix, sampleSize)
+		if err != nil {
+			return findings, fmt.Errorf("%s: %w", check.Name, err)
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+func (d *Doctor) run(ctx context.Context, check Check, fix bool, sampleSize int) (Finding, error) {
+	finding := Finding{Check: check.Name, Description: check.Description}
+	db := d.db.WithContext(ctx)
+
+	if err := db.Raw(fmt.Sprintf("SELECT COUNT(*) FROM (%s) found", check.Find)).Scan(&finding.Count).Error; err != nil {
+		return finding, err
+	}
+	if finding.Count == 0 {
+		return finding, nil
+	}
+	if err := db.Raw(fmt.Sprintf("%s ORDER BY 1 LIMIT ?", check.Find), sampleSize).Scan(&finding.Sample).Error; err != nil {
+		return finding, err
+	}
+	if !fix {
+		return finding, nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(fmt.Sprintf(check.RepairQuery, check.Find))
+		finding.Repaired = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return finding, err
+	}
+	finding.Repair = check.Repair
+	d.logger.Info("Repaired inconsistent rows",
+		zap.String("check", check.Name),
+		zap.Int64("rows", finding.Repaired),
+		zap.String("repair", check.Repair))
+	return finding, nil
+}
+
+func selectChecks(only []string) ([]Check, error) {
+	if len(only) == 0 {
+		return checks, nil
+	}
+	selected := make([]Check, 0, len(only))
+	for _, name := range only {
+		found := false
+		for _, check := range checks {
+			if check.Name == name {
+				selected = append(selected, check)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown check %q", name)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Migration modes controlling what happens at startup
+const (
+	// ModeAuto applies pending migrations at startup (default)
+	ModeAuto = "auto"
+	// ModeStrict refuses to start when migrations are pending or the schema has drifted
+	ModeStrict = "strict"
+)
+
+// Drift describes a difference between a model and the database schema
+type Drift struct {
+	Model  string `json:"model"`
+	Table  string `json:"table"`
+	Column string `json:"column,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Status reports the state of the database schema compared to the models
+type Status struct {
+	Pending []string `json:"pending"`
+	Drift   []Drift  `json:"drift"`
+}
+
+// UpToDate returns true if there are no pending migrations and no drift
+func (s *Status) UpToDate() bool {
+	return len(s.Pending) == 0 && len(s.Drift) == 0
+}
+
+// CheckStatus compares the migration history and live schema against the models
+// without changing anything in the database
+func CheckStatus(db *connection.Database) (*Status, error) {
+	status := &Status{
+		Pending: make([]string, 0),
+		Drift:   make([]Drift, 0),
+	}
+
+	migrator := db.Migrator()
+	if !migrator.HasTable(&MigrationRecord{}) {
+		// Nothing has ever been migrated
+		for _, model := range Models() {
+			status.Pending = append(status.Pending, fmt.Sprintf("%T", model))
+		}
+		return status, nil
+	}
+
+	applied := make(map[string]struct{})
+	records, err := GetMigrationHistory(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	for _, record := range records {
+		applied[record.Name] = struct{}{}
+	}
+
+	for _, model := range Models() {
+		modelName := fmt.Sprintf("%T", model)
+		if _, ok := applied[modelName]; !ok {
+			status.Pending = append(status.Pending, modelName)
+			continue
+		}
+
+		drift, err := detectDrift(db.DB, model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect schema for %s: %w", modelName, err)
+		}
+		status.Drift = append(status.Drift, drift...)
+	}
+
+	return status, nil
+}
+
+// detectDrift reports missing tables and columns for an applied model
+func detectDrift(db *gorm.DB, model interface{}) ([]Drift, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+
+	modelName := fmt.Sprintf("%T", model)
+	table := stmt.Schema.Table
+	migrator := db.Migrator()
+
+	if !migrator.HasTable(model) {
+		return []Drift{{Model: modelName, Table: table, Reason: "table missing"}}, nil
+	}
+
+	var drift []Drift
+	for _, dbName := range stmt.Schema.DBNames {
+		if field := stmt.Schema.FieldsByDBName[dbName]; field != nil && field.IgnoreMigration {
+			continue
+		}
+		if !migrator.HasColumn(model, dbName) {
+			drift = append(drift, Drift{
+				Model:  modelName,
+				Table:  table,
+				Column: dbName,
+				Reason: "column missing",
+			})
+		}
+	}
+	return drift, nil
+}
+
+// EnsureUpToDate returns an error if migrations are pending or the schema has drifted.
+// It is used in strict mode to refuse to start against an unmigrated database.
+func EnsureUpToDate(db *connection.Database, logger *zap.Logger) error {
+	status, err := CheckStatus(db)
+	if err != nil {
+		return err
+	}
+
+	if status.UpToDate() {
+		logger.Info("Database schema is up to date")
+		return nil
+	}
+
+	for _, name := range status.Pending {
+		logger.Error("Pending migration", zap.String("model", name))
+	}
+	for _, d := range status.Drift {
+		logger.Error("Schema drift detected",
+			zap.String("model", d.Model),
+			zap.String("table", d.Table),
+			zap.String("column", d.Column),
+			zap.String("reason", d.Reason),
+		)
+	}
+
+	return fmt.Errorf("database schema is not up to date: %d pending migrations, %d drift issues (run with --migrate to apply)",
+		len(status.Pending), len(status.Drift))
+}
@@ -2,21 +2,52 @@ package migrations
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"errors"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/apikeys"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/attachments"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/audit"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/baselines"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/calendar"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/focus"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/meetingnotes"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/memberimport"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/orgunits"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/project"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/projections"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/projectcopy"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/retention"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/roles"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/settings"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/sla"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/tags"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/timetracking"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/user"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/vcs"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/workflow"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/notification"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/onboarding"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/activity"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/announcements"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/inbound"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/chat"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/search"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/billing"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/metering"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/legal"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/integrations"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habitlinks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/devices"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/persistence/postgres/connection"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/security/auth"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -47,6 +78,100 @@ func tryEnablePgVector(db *gorm.DB, logger *zap.Logger) {
 	}
 }
 
+// Models returns the models managed by AutoMigrate, in the order they should be migrated.
+// This order matters due to foreign key relationships.
+func Models() []interface{} {
+	return []interface{}{
+		&notification.Notification{},
+		&roles.Role{},
+		&roles.Permission{},
+		&user.User{}, // Users should be first as they're referenced by other tables
+		&roles.UserRole{},
+		&roles.RolePermission{},
+		&organization.Organization{}, // Organizations depend on users
+		&organization.Member{},
+		&organization.Invitation{},
+		&memberimport.Import{},
+		&orgunits.Unit{},
+		&orgunits.Member{},
+		&project.Project{},           // Projects depend on organizations
+		&task.Task{},                 // Tasks depend on projects, users, and organizations
+		&baselines.Baseline{},
+		&baselines.BaselineTask{},
+		&projectcopy.Duplication{},
+		&sla.Schedule{},
+		&sla.Holiday{},
+		&sla.ProjectClock{},
+		&settings.OrganizationDefaults{},
+		&settings.ProjectOverrides{},
+		&habits.Habit{},
+		&habits.StreakHistory{},
+		&habits.HabitCompletionLog{},
+		&habitlinks.Link{},
+		&calendar.CalendarEvent{},
+		&calendar.RecurrenceRule{},
+		&calendar.EventOccurrence{},
+		&calendar.EventException{},
+		&calendar.EventReminder{},
+		&calendar.EventCollaborator{},
+		&calendar.EventAttendee{},
+		&calendar.ReminderDelivery{},
+		&meetingnotes.Note{},
+		&meetingnotes.ActionItem{},
+		&tags.Tag{},
+		&tags.Assignment{},
+		&workflow.Workflow{},
+		&workflow.WorkflowStep{},
+		&workflow.WorkflowExecution{},
+		&workflow.WorkflowStepExecution{},
+		&workflow.WorkflowAgentLink{},
+		&workflow.WorkflowTransition{},
+		&workflow.AIUsageRecord{},
+		&workflow.AIBudget{},
+		&todos.Todo{},
+		&todos.Geofence{},
+		&devices.Device{},
+		&apikeys.APIKey{},
+		&attachments.Attachment{},
+		&audit.Entry{},
+		&retention.Policy{},
+		&projections.UserDailyStats{},
+		&projections.ProjectTaskCount{},
+		&user.UserAnalytics{},
+		&user.SessionAnalytics{},
+		&task.TaskAnalytics{},
+		&task.TaskComment{},
+		&timetracking.TimeEntry{},
+		&focus.Session{},
+		&focus.Interruption{},
+		&calendar.EventAnalytics{},
+		&habits.HabitAnalytics{},
+		&onboarding.Progress{},
+		&activity.Event{},
+		&search.AuditEntry{},
+		&webhooks.Webhook{},
+		&webhooks.Delivery{},
+		&announcements.Announcement{},
+		&announcements.Recipient{},
+		&inbound.Address{},
+		&inbound.Message{},
+		&inbound.Attachment{},
+		&auth.RefreshToken{},
+		&chat.Installation{},
+		&chat.Channel{},
+		&chat.UserLink{},
+		&vcs.Connection{},
+		&vcs.Link{},
+		&billing.Subscription{},
+		&billing.ProcessedEvent{},
+		&metering.DailyUsage{},
+		&metering.UserHourlyUsage{},
+		&legal.Consent{},
+		&integrations.Health{},
+		&integrations.Failure{},
+	}
+}
+
 // AutoMigrate runs database migrations for all models
 func AutoMigrate(db *connection.Database, logger *zap.Logger) error {
 	logger.Info("Starting automatic database migration...")
@@ -77,40 +202,8 @@ func AutoMigrate(db *connection.Database, logger *zap.Logger) error {
 			return fmt.Errorf("failed to get last version: %v", err)
 		}
 
-		// Define the models in the order they should be migrated
-		// This order matters due to foreign key relationships
-		models := []interface{}{
-			&notification.Notification{},
-			&roles.Role{},
-			&roles.Permission{},
-			&user.User{}, // Users should be first as they're referenced by other tables
-			&roles.UserRole{},
-			&roles.RolePermission{},
-			&organization.Organization{}, // Organizations depend on users
-			&project.Project{},           // Projects depend on organizations
-			&task.Task{},                 // Tasks depend on projects, users, and organizations
-			&habits.Habit{},
-			&habits.StreakHistory{},
-			&habits.HabitCompletionLog{},
-			&calendar.CalendarEvent{},
-			&calendar.RecurrenceRule{},
-			&calendar.EventOccurrence{},
-			&calendar.EventException{},
-			&calendar.EventReminder{},
-			&calendar.EventCollaborator{},
-			&workflow.Workflow{},
-			&workflow.WorkflowStep{},
-			&workflow.WorkflowExecution{},
-			&workflow.WorkflowStepExecution{},
-			&workflow.WorkflowAgentLink{},
-			&workflow.WorkflowTransition{},
-			&todos.Todo{},
-			&user.UserAnalytics{},
-			&user.SessionAnalytics{},
-			&task.TaskAnalytics{},
-			&calendar.EventAnalytics{},
-			&habits.HabitAnalytics{},
-		}
+		// Migrate models in dependency order
+		models := Models()
 
 		// Migrate each model
 		for i, model := range models {
@@ -151,6 +244,14 @@ func AutoMigrate(db *connection.Database, logger *zap.Logger) error {
 			}
 		}
 
+		// Add full-text search columns and their indexes
+		if err := createSearchIndexes(tx); err != nil {
+			return err
+		}
+		if err := createEmbeddingTable(tx, logger); err != nil {
+			return err
+		}
+
 		// Create default roles and permissions
 		if err := createDefaultRolesAndPermissions(tx); err != nil {
 			return err
@@ -161,6 +262,61 @@ func AutoMigrate(db *connection.Database, logger *zap.Logger) error {
 	})
 }
 
+// createSearchIndexes adds a language column and a generated tsvector column with a GIN
+// index to every searchable table
+func createSearchIndexes(db *gorm.DB) error {
+	for _, source := range search.Sources {
+		language := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s regconfig NOT NULL DEFAULT '%s'`,
+			source.Table, search.LanguageColumn, search.DefaultLanguage)
+		if err := db.Exec(language).Error; err != nil {
+			return fmt.Errorf("failed to add search language column to %s: %w", source.Table, err)
+		}
+
+		// Vectors from before search languages were configurable have English built in and
+		// are rebuilt over the language column; dropping the column drops its index
+		var expression string
+		if err := db.Raw(`SELECT coalesce(generation_expression, '') FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`,
+			source.Table, search.VectorColumn).Scan(&expression).Error; err != nil {
+			return fmt.Errorf("failed to inspect search column of %s: %w", source.Table, err)
+		}
+		if expression != "" && !strings.Contains(expression, search.LanguageColumn) {
+			if err := db.Exec(fmt.Sprintf(`ALTER TABLE %s DROP COLUMN %s`, source.Table, search.VectorColumn)).Error; err != nil {
+				return fmt.Errorf("failed to drop search column of %s: %w", source.Table, err)
+			}
+		}
+
+		column := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s tsvector GENERATED ALWAYS AS (%s) STORED`,
+			source.Table, search.VectorColumn, source.VectorExpression())
+		if err := db.Exec(column).Error; err != nil {
+			return fmt.Errorf("failed to add search column to %s: %w", source.Table, err)
+		}
+
+		index := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (%s)`,
+			source.IndexName(), source.Table, search.VectorColumn)
+		if err := db.Exec(index).Error; err != nil {
+			return fmt.Errorf("failed to create search index on %s: %w", source.Table, err)
+		}
+	}
+	return nil
+}
+
+// createEmbeddingTable creates the semantic search embedding table when pgvector is enabled
+func createEmbeddingTable(db *gorm.DB, logger *zap.Logger) error {
+	var enabled bool
+	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector')").Scan(&enabled).Error; err != nil {
+		return fmt.Errorf("failed to check for pgvector: %w", err)
+	}
+	if !enabled {
+		logger.Warn("Skipping search embedding table because pgvector is not enabled")
+		return nil
+	}
+	if err := db.Exec(search.EmbeddingTableSQL).Error; err != nil {
+		return fmt.Errorf("failed to create search embedding table: %w", err)
+	}
+	return nil
+}
+
 // createDefaultRolesAndPermissions creates default roles and permissions
 func createDefaultRolesAndPermissions(db *gorm.DB) error {
 	// Create default permissions
@@ -180,11 +336,30 @@ func createDefaultRolesAndPermissions(db *gorm.DB) error {
 		{Name: "tasks:update", Description: "Update tasks"},
 		{Name: "tasks:delete", Description: "Delete tasks"},
 
+		{Name: "workflows:create", Description: "Create workflows"},
+		{Name: "workflows:read", Description: "Read workflows"},
+		{Name: "workflows:update", Description: "Update workflows"},
+		{Name: "workflows:delete", Description: "Delete workflows"},
+		{Name: "workflows:execute", Description: "Run workflows and act on their steps"},
+
 		{Name: "roles:create", Description: "Create roles"},
 		{Name: "roles:read", Description: "Read roles"},
 		{Name: "roles:update", Description: "Update roles"},
 		{Name: "roles:delete", Description: "Delete roles"},
 		{Name: "roles:assign", Description: "Assign roles to users"},
+
+		{Name: "billing:manage", Description: "Manage the organization's subscription"},
+
+		{Name: "tags:create", Description: "Create tags"},
+		{Name: "tags:manage", Description: "Rename, merge and delete tags"},
+
+		{Name: "webhooks:manage", Description: "Manage the organization's webhooks"},
+
+		{Name: "announcements:manage", Description: "Post and retract organization announcements"},
+
+		{Name: "chat:manage", Description: "Install the chat apps and choose the channels they post to"},
+
+		{Name: "vcs:manage", Description: "Connect repositories to the organization's projects"},
 	}
 
 	// Create permissions if they don't exist
@@ -208,7 +383,14 @@ func createDefaultRolesAndPermissions(db *gorm.DB) error {
 				"organizations:create", "organizations:read", "organizations:update", "organizations:delete",
 				"projects:create", "projects:read", "projects:update", "projects:delete",
 				"tasks:create", "tasks:read", "tasks:update", "tasks:delete",
+				"workflows:create", "workflows:read", "workflows:update", "workflows:delete", "workflows:execute",
 				"roles:create", "roles:read", "roles:update", "roles:delete", "roles:assign",
+				"billing:manage",
+				"tags:create", "tags:manage",
+				"webhooks:manage",
+				"announcements:manage",
+				"chat:manage",
+				"vcs:manage",
 			},
 		},
 		{
@@ -220,6 +402,8 @@ func createDefaultRolesAndPermissions(db *gorm.DB) error {
 				"organizations:read",
 				"projects:read",
 				"tasks:read", "tasks:create", "tasks:update",
+				"workflows:read", "workflows:execute",
+				"tags:create",
 			},
 		},
 	}
@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"context"
+	"strings"
+)
+
+// anthropicVersion is the Messages API version the adapter speaks
+const anthropicVersion = "2023-06-01"
+
+// anthropicProvider uses the Anthropic Messages API. Anthropic has no embeddings API.
+type anthropicProvider struct {
+	config Config
+}
+
+func (p *anthropicProvider) Name() string { return Anthropic }
+
+func (p *anthropicProvider) Complete(ctx context.Context, req CompletionRequest) (*Completion, error) {
+	model := req.Model
+	if model == "" {
+		model = p.config.Model
+	}
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+
+	payload := map[string]interface{}{
+		"model":      model,
+		"max_tokens": maxTokens,
+		"messages":   req.Messages,
+	}
+	if req.System != "" {
+		payload["system"] = req.System
+	}
+	if req.Temperature != nil {
+		payload["temperature"] = *req.Temperature
+	}
+
+	var resp struct {
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	headers := map[string]string{
+		"x-api-key":         p.config.APIKey,
+		"anthropic-version": anthropicVersion,
+	}
+	if err := postJSON(ctx, p.config.BaseURL+"/messages", headers, payload, &resp); err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return &Completion{
+		Text:  text.String(),
+		Model: resp.Model,
+		Usage: Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens},
+	}, nil
+}
+
+func (p *anthropicProvider) Embed(ctx context.Context, req EmbeddingRequest) (*Embeddings, error) {
+	return nil, ErrNotSupported
+}
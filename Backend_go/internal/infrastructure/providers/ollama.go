@@ -0,0 +1,83 @@
+package providers
+
+import (
+	"context"
+)
+
+// ollamaProvider uses the chat and embed APIs of an Ollama server. Local models need
+// no API key and cost nothing.
+type ollamaProvider struct {
+	config Config
+}
+
+func (p *ollamaProvider) Name() string { return Ollama }
+
+func (p *ollamaProvider) headers() map[string]string {
+	if p.config.APIKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + p.config.APIKey}
+}
+
+func (p *ollamaProvider) Complete(ctx context.Context, req CompletionRequest) (*Completion, error) {
+	model := req.Model
+	if model == "" {
+		model = p.config.Model
+	}
+	messages := make([]Message, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, Message{Role: "system", Content: req.System})
+	}
+	messages = append(messages, req.Messages...)
+
+	options := map[string]interface{}{}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	if req.Temperature != nil {
+		options["temperature"] = *req.Temperature
+	}
+	payload := map[string]interface{}{
+		"model":    model,
+		"messages": messages,
+		"stream":   false,
+		"options":  options,
+	}
+
+	var resp struct {
+		Model           string  `json:"model"`
+		Message         Message `json:"message"`
+		PromptEvalCount int     `json:"prompt_eval_count"`
+		EvalCount       int     `json:"eval_count"`
+	}
+	if err := postJSON(ctx, p.config.BaseURL+"/api/chat", p.headers(), payload, &resp); err != nil {
+		return nil, err
+	}
+	return &Completion{
+		Text:  resp.Message.Content,
+		Model: resp.Model,
+		Usage: Usage{InputTokens: resp.PromptEvalCount, OutputTokens: resp.EvalCount},
+	}, nil
+}
+
+func (p *ollamaProvider) Embed(ctx context.Context, req EmbeddingRequest) (*Embeddings, error) {
+	model := req.Model
+	if model == "" {
+		model = p.config.EmbeddingModel
+	}
+
+	var resp struct {
+		Model           string      `json:"model"`
+		Embeddings      [][]float32 `json:"embeddings"`
+		PromptEvalCount int         `json:"prompt_eval_count"`
+	}
+	payload := map[string]interface{}{"model": model, "input": req.Input}
+	if err := postJSON(ctx, p.config.BaseURL+"/api/embed", p.headers(), payload, &resp); err != nil {
+		return nil, err
+	}
+	return &Embeddings{
+		Vectors: resp.Embeddings,
+		Model:   resp.Model,
+		Usage:   Usage{InputTokens: resp.PromptEvalCount},
+	}, nil
+}
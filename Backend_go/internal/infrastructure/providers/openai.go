@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+)
+
+// openAIProvider uses the OpenAI chat completions and embeddings APIs. Servers that
+// implement the same API can be used by setting BaseURL.
+type openAIProvider struct {
+	config Config
+}
+
+func (p *openAIProvider) Name() string { return OpenAI }
+
+func (p *openAIProvider) headers() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + p.config.APIKey}
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, req CompletionRequest) (*Completion, error) {
+	model := req.Model
+	if model == "" {
+		model = p.config.Model
+	}
+	messages := make([]Message, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, Message{Role: "system", Content: req.System})
+	}
+	messages = append(messages, req.Messages...)
+
+	payload := map[string]interface{}{
+		"model":    model,
+		"messages": messages,
+	}
+	if req.MaxTokens > 0 {
+		payload["max_tokens"] = req.MaxTokens
+	}
+	if req.Temperature != nil {
+		payload["temperature"] = *req.Temperature
+	}
+
+	var resp struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message Message `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := postJSON(ctx, p.config.BaseURL+"/chat/completions", p.headers(), payload, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("openai returned no choices")
+	}
+	return &Completion{
+		Text:  resp.Choices[0].Message.Content,
+		Model: resp.Model,
+		Usage: Usage{InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens},
+	}, nil
+}
+
+func (p *openAIProvider) Embed(ctx context.Context, req EmbeddingRequest) (*Embeddings, error) {
+	model := req.Model
+	if model == "" {
+		model = p.config.EmbeddingModel
+	}
+
+	var resp struct {
+		Model string `json:"model"`
+		Data  []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			PromptTokens int `json:"prompt_tokens"`
+		} `json:"usage"`
+	}
+	payload := map[string]interface{}{"model": model, "input": req.Input}
+	if err := postJSON(ctx, p.config.BaseURL+"/embeddings", p.headers(), payload, &resp); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(req.Input))
+	for _, item := range resp.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = item.Embedding
+		}
+	}
+	return &Embeddings{
+		Vectors: vectors,
+		Model:   resp.Model,
+		Usage:   Usage{InputTokens: resp.Usage.PromptTokens},
+	}, nil
+}
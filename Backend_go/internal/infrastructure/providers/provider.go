@@ -0,0 +1,156 @@
+// Package providers talks to the large language model providers behind the AI features:
+// OpenAI, Anthropic and local Ollama servers, through one interface.
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Provider names
+const (
+	OpenAI    = "openai"
+	Anthropic = "anthropic"
+	Ollama    = "ollama"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown LLM provider")
+	ErrNotConfigured   = errors.New("LLM provider is not configured")
+	// ErrNotSupported is returned by providers that cannot do what was asked, such as
+	// Anthropic for embeddings
+	ErrNotSupported = errors.New("not supported by the LLM provider")
+)
+
+// Message is one turn of a conversation. Role is "user" or "assistant".
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// CompletionRequest asks a model to continue a conversation. An empty Model uses the
+// provider's default model.
+type CompletionRequest struct {
+	Model       string
+	System      string
+	Messages    []Message
+	MaxTokens   int
+	Temperature *float64
+}
+
+// Completion is the model's reply
+type Completion struct {
+	Text  string
+	Model string
+	Usage Usage
+}
+
+// EmbeddingRequest asks for a vector per input. An empty Model uses the provider's
+// default embedding model.
+type EmbeddingRequest struct {
+	Model string
+	Input []string
+}
+
+// Embeddings holds one vector per input, in input order
+type Embeddings struct {
+	Vectors [][]float32
+	Model   string
+	Usage   Usage
+}
+
+// Usage counts the tokens a call consumed
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// Provider is a large language model provider
+type Provider interface {
+	Name() string
+	Complete(ctx context.Context, req CompletionRequest) (*Completion, error)
+	Embed(ctx context.Context, req EmbeddingRequest) (*Embeddings, error)
+}
+
+// Config configures one provider. BaseURL defaults to the provider's public API, or to a
+// local Ollama server.
+type Config struct {
+	APIKey         string
+	BaseURL        string
+	Model          string
+	EmbeddingModel string
+}
+
+// defaultMaxTokens caps completions that do not ask for a limit. Anthropic requires one.
+const defaultMaxTokens = 1024
+
+var httpClient = &http.Client{Timeout: 2 * time.Minute}
+
+// New creates the named provider
+func New(name string, config Config) (Provider, error) {
+	switch name {
+	case OpenAI:
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("%w: %s needs an API key", ErrNotConfigured, name)
+		}
+		return &openAIProvider{config: withDefaults(config, "https://api.openai.com/v1", "gpt-4o-mini", "text-embedding-3-small")}, nil
+	case Anthropic:
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("%w: %s needs an API key", ErrNotConfigured, name)
+		}
+		return &anthropicProvider{config: withDefaults(config, "https://api.anthropic.com/v1", "claude-3-5-haiku-latest", "")}, nil
+	case Ollama:
+		return &ollamaProvider{config: withDefaults(config, "http://localhost:11434", "llama3.1", "nomic-embed-text")}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+}
+
+func withDefaults(config Config, baseURL, model, embeddingModel string) Config {
+	if config.BaseURL == "" {
+		config.BaseURL = baseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.Model == "" {
+		config.Model = model
+	}
+	if config.EmbeddingModel == "" {
+		config.EmbeddingModel = embeddingModel
+	}
+	return config
+}
+
+// postJSON sends payload to url and decodes the response into out, failing on any
+// non-2xx response
+func postJSON(ctx context.Context, url string, headers map[string]string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("LLM provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Settings is the provider and models an organization chose. Empty fields fall back to
+// the server's defaults.
+type Settings struct {
+	Provider       string `json:"provider,omitempty"`
+	Model          string `json:"model,omitempty"`
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+}
+
+// SettingsSource looks up the provider settings of an organization
+type SettingsSource interface {
+	ProviderSettings(ctx context.Context, orgID uuid.UUID) (Settings, error)
+}
+
+// Resolver picks the provider for an organization. API keys and endpoints are server
+// configuration; organizations only choose among the configured providers and their models.
+type Resolver struct {
+	defaultProvider string
+	configs         map[string]Config
+	settings        SettingsSource
+}
+
+// NewResolver creates a resolver over the configured providers keyed by name.
+// settings may be nil, in which case every organization uses the default provider.
+func NewResolver(defaultProvider string, configs map[string]Config, settings SettingsSource) *Resolver {
+	return &Resolver{
+		defaultProvider: defaultProvider,
+		configs:         configs,
+		settings:        settings,
+	}
+}
+
+// Enabled reports whether a default provider is configured
+func (r *Resolver) Enabled() bool {
+	return r != nil && r.defaultProvider != ""
+}
+
+// For returns the provider an organization uses, set up with the models it chose.
+// uuid.Nil returns the default provider.
+func (r *Resolver) For(ctx context.Context, orgID uuid.UUID) (Provider, error) {
+	var settings Settings
+	if r.settings != nil && orgID != uuid.Nil {
+		var err error
+		settings, err = r.settings.ProviderSettings(ctx, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provider settings: %w", err)
+		}
+	}
+
+	name := settings.Provider
+	if name == "" {
+		name = r.defaultProvider
+	}
+	if name == "" {
+		return nil, ErrNotConfigured
+	}
+	config, ok := r.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotConfigured, name)
+	}
+	if settings.Model != "" {
+		config.Model = settings.Model
+	}
+	if settings.EmbeddingModel != "" {
+		config.EmbeddingModel = settings.EmbeddingModel
+	}
+	return New(name, config)
+}
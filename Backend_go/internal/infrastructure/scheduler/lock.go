@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	lockKeyPrefix    = "scheduler:lock:"
+	lastRunKeyPrefix = "scheduler:last_run:"
+)
+
+// Locker makes sure a scheduled activation of a job runs on only one instance
+type Locker interface {
+	// Acquire claims the activation of job scheduled at slot, returning false if another instance already has
+	Acquire(ctx context.Context, job string, slot time.Time, ttl time.Duration) (bool, error)
+}
+
+// redisLocker claims activations with SET NX. The key is left to expire rather than released,
+// so an instance whose clock lags behind cannot run the same activation after the first finishes.
+type redisLocker struct {
+	client   *redis.Client
+	instance string
+}
+
+// NewRedisLocker creates a Locker backed by Redis
+func NewRedisLocker(client *redis.Client, instance string) Locker {
+	return &redisLocker{client: client, instance: instance}
+}
+
+func (l *redisLocker) Acquire(ctx context.Context, job string, slot time.Time, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%s:%d", lockKeyPrefix, job, slot.Unix())
+	return l.client.SetNX(ctx, key, l.instance, ttl).Result()
+}
+
+// localLocker is used when no Redis client is configured and only one instance runs jobs
+type localLocker struct{}
+
+func (localLocker) Acquire(context.Context, string, time.Time, time.Duration) (bool, error) {
+	return true, nil
+}
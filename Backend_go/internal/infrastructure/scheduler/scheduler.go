@@ -2,70 +2,431 @@ package scheduler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/habits"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/projections"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/retention"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/task"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/todos"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
 	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
 )
 
+// Job names recorded in the run history
+const (
+	JobHabitReset     = "habit_reset"
+	JobHabitReminders = "habit_reminders"
+	JobTodoRecurrence = "todo_recurrence"
+	JobTaskRisk       = "task_risk"
+	JobTaskPriority   = "task_priority"
+	JobTrashPurge     = "trash_purge"
+	JobRetention      = "retention"
+	JobProjections    = "projections"
+)
+
+// maxJobRuns is the number of runs kept in the in-memory history
+const maxJobRuns = 100
+
+// Config holds the cron schedules of the habit and todo maintenance jobs
+type Config struct {
+	HabitResetSchedule    string
+	HabitReminderSchedule string
+	// TodoRecurrenceSchedule runs the backfill of missed recurring todo occurrences
+	TodoRecurrenceSchedule string
+	// TaskRiskSchedule runs the analysis that flags overdue and at-risk tasks
+	TaskRiskSchedule string
+	// TaskPrioritySchedule runs the refresh of the priority scores open tasks are ranked by
+	TaskPrioritySchedule string
+	// TrashPurgeSchedule runs the purge of tasks and todos deleted longer than TrashRetention ago
+	TrashPurgeSchedule string
+	TrashRetention     time.Duration
+	// RetentionSchedule runs the retention policies of organizations
+	RetentionSchedule string
+	// ProjectionsSchedule runs the recount of the project task counters
+	ProjectionsSchedule string
+	// Location is the time zone schedules are evaluated in
+	Location *time.Location
+	// LockTTL is how long an activation stays claimed; it must cover clock skew between instances
+	LockTTL time.Duration
+}
+
+// DefaultConfig resets habits at midnight, sends reminders at 8AM, 12PM, 6PM and 9PM
+// backfills recurring todos every hour, rescores task priorities every hour at a quarter
+// past, analyzes task risk at 2AM, applies retention
+// policies at 3AM, empties the trash of items older than 30 days at 4AM and recounts
+// the project task counters at 4:30AM
+func DefaultConfig() Config {
+	return Config{
+		HabitResetSchedule:     "0 0 * * *",
+		HabitReminderSchedule:  "0 8,12,18,21 * * *",
+		TodoRecurrenceSchedule: "30 * * * *",
+		TaskRiskSchedule:       "0 2 * * *",
+		TaskPrioritySchedule:   "15 * * * *",
+		TrashPurgeSchedule:     "0 4 * * *",
+		TrashRetention:         30 * 24 * time.Hour,
+		RetentionSchedule:      "0 3 * * *",
+		ProjectionsSchedule:    "30 4 * * *",
+		Location:               time.Local,
+		LockTTL:                10 * time.Minute,
+	}
+}
+
+// JobRun describes a single execution of a scheduled job
+type JobRun struct {
+	Job        string        `json:"job"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Duration   time.Duration `json:"duration"`
+	Status     string        `json:"status"`
+	Error      string        `json:"error,omitempty"`
+	Instance   string        `json:"instance,omitempty"`
+	// RequestID tags the log lines of the run, and of the events it published
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// JobStatus reports the schedule of a job and its most recent run on any instance
+type JobStatus struct {
+	Job      string    `json:"job"`
+	Schedule string    `json:"schedule"`
+	NextRun  time.Time `json:"next_run"`
+	LastRun  *JobRun   `json:"last_run,omitempty"`
+	Overdue  bool      `json:"overdue"`
+}
+
+type job struct {
+	name     string
+	schedule *Schedule
+	run      func(ctx context.Context) error
+	// catchUp runs the job at startup when its last activation was missed
+	catchUp bool
+}
+
 type Scheduler struct {
-	habitService habits.Service
-	logger       *logger.Logger
+	habitService      habits.Service
+	todoService       todos.Service
+	taskService       task.Service
+	retentionService  retention.Service
+	projectionService projections.Service
+	redis             *redis.Client
+	locker            Locker
+	config            Config
+	instance          string
+	logger            *logger.Logger
+	jobs              []*job
+
+	runsMu sync.RWMutex
+	runs   []JobRun
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
-func NewScheduler(habitService habits.Service, logger *logger.Logger) *Scheduler {
-	return &Scheduler{
-		habitService: habitService,
-		logger:       logger,
+// NewScheduler creates the maintenance scheduler. Activations are claimed in Redis
+// so that only one of several API instances runs each of them.
+func NewScheduler(habitService habits.Service, todoService todos.Service, taskService task.Service, retentionService retention.Service, projectionService projections.Service, redisClient *cache.RedisClient, config Config, logger *logger.Logger) (*Scheduler, error) {
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = DefaultConfig().LockTTL
+	}
+	if config.TodoRecurrenceSchedule == "" {
+		config.TodoRecurrenceSchedule = DefaultConfig().TodoRecurrenceSchedule
+	}
+	if config.TaskRiskSchedule == "" {
+		config.TaskRiskSchedule = DefaultConfig().TaskRiskSchedule
+	}
+	if config.TaskPrioritySchedule == "" {
+		config.TaskPrioritySchedule = DefaultConfig().TaskPrioritySchedule
+	}
+	if config.TrashPurgeSchedule == "" {
+		config.TrashPurgeSchedule = DefaultConfig().TrashPurgeSchedule
+	}
+	if config.TrashRetention <= 0 {
+		config.TrashRetention = DefaultConfig().TrashRetention
+	}
+	if config.RetentionSchedule == "" {
+		config.RetentionSchedule = DefaultConfig().RetentionSchedule
+	}
+	if config.ProjectionsSchedule == "" {
+		config.ProjectionsSchedule = DefaultConfig().ProjectionsSchedule
+	}
+
+	instance, _ := os.Hostname()
+	instance = fmt.Sprintf("%s-%d", instance, os.Getpid())
+
+	s := &Scheduler{
+		habitService:      habitService,
+		todoService:       todoService,
+		taskService:       taskService,
+		retentionService:  retentionService,
+		projectionService: projectionService,
+		locker:            localLocker{},
+		config:            config,
+		instance:          instance,
+		logger:            logger,
+		runs:              make([]JobRun, 0, maxJobRuns),
+	}
+	if redisClient != nil {
+		s.redis = redisClient.GetClient()
+		s.locker = NewRedisLocker(s.redis, instance)
+	}
+
+	resetSchedule, err := ParseSchedule(config.HabitResetSchedule)
+	if err != nil {
+		return nil, err
+	}
+	reminderSchedule, err := ParseSchedule(config.HabitReminderSchedule)
+	if err != nil {
+		return nil, err
+	}
+	recurrenceSchedule, err := ParseSchedule(config.TodoRecurrenceSchedule)
+	if err != nil {
+		return nil, err
+	}
+	riskSchedule, err := ParseSchedule(config.TaskRiskSchedule)
+	if err != nil {
+		return nil, err
 	}
+	prioritySchedule, err := ParseSchedule(config.TaskPrioritySchedule)
+	if err != nil {
+		return nil, err
+	}
+	purgeSchedule, err := ParseSchedule(config.TrashPurgeSchedule)
+	if err != nil {
+		return nil, err
+	}
+	retentionSchedule, err := ParseSchedule(config.RetentionSchedule)
+	if err != nil {
+		return nil, err
+	}
+	projectionsSchedule, err := ParseSchedule(config.ProjectionsSchedule)
+	if err != nil {
+		return nil, err
+	}
+	s.jobs = []*job{
+		{name: JobHabitReset, schedule: resetSchedule, run: s.runResetTasks, catchUp: true},
+		{name: JobHabitReminders, schedule: reminderSchedule, run: s.sendReminderNotifications},
+		{name: JobTodoRecurrence, schedule: recurrenceSchedule, run: s.generateTodoOccurrences, catchUp: true},
+		{name: JobTaskRisk, schedule: riskSchedule, run: s.analyzeTaskRisks, catchUp: true},
+		{name: JobTaskPriority, schedule: prioritySchedule, run: s.scoreTaskPriorities, catchUp: true},
+		{name: JobTrashPurge, schedule: purgeSchedule, run: s.purgeTrash, catchUp: true},
+		{name: JobRetention, schedule: retentionSchedule, run: s.applyRetention, catchUp: true},
+		{name: JobProjections, schedule: projectionsSchedule, run: s.reconcileProjections, catchUp: true},
+	}
+	return s, nil
+}
+
+// RecentRuns returns the most recent job runs, newest first.
+// If job is empty, runs of all jobs are returned.
+func (s *Scheduler) RecentRuns(job string, limit int) []JobRun {
+	s.runsMu.RLock()
+	defer s.runsMu.RUnlock()
+
+	if limit <= 0 || limit > maxJobRuns {
+		limit = maxJobRuns
+	}
+
+	result := make([]JobRun, 0, limit)
+	for i := len(s.runs) - 1; i >= 0 && len(result) < limit; i-- {
+		if job == "" || s.runs[i].Job == job {
+			result = append(result, s.runs[i])
+		}
+	}
+	return result
+}
+
+// Status returns every job with its next activation and the last run recorded by any instance
+func (s *Scheduler) Status(ctx context.Context) []JobStatus {
+	now := time.Now().In(s.config.Location)
+	result := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		status := JobStatus{
+			Job:      j.name,
+			Schedule: j.schedule.String(),
+			NextRun:  j.schedule.Next(now),
+			LastRun:  s.lastRun(ctx, j.name),
+		}
+		if status.LastRun != nil {
+			due := j.schedule.Next(status.LastRun.StartedAt.In(s.config.Location))
+			status.Overdue = !due.IsZero() && now.Sub(due) > s.config.LockTTL
+		}
+		result = append(result, status)
+	}
+	return result
 }
 
+// Start runs every job on its schedule until Stop is called
 func (s *Scheduler) Start() {
-	// Run immediately at startup
-	s.runResetTasks()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
 
-	// Schedule reminder notifications to run every 6 hours
-	go s.scheduleReminderNotifications()
+	for _, j := range s.jobs {
+		s.logger.Info("Scheduled job",
+			zap.String("job", j.name),
+			zap.String("schedule", j.schedule.String()),
+			zap.Time("next_run", j.schedule.Next(time.Now().In(s.config.Location))),
+		)
 
-	// Calculate time until next midnight
-	now := time.Now()
-	nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-	timeUntilMidnight := nextMidnight.Sub(now)
+		s.wg.Add(1)
+		go func(j *job) {
+			defer s.wg.Done()
+			if j.catchUp {
+				s.catchUp(ctx, j)
+			}
+			s.loop(ctx, j)
+		}(j)
+	}
+}
 
-	s.logger.Info("Habit scheduler initialized",
-		zap.Time("current_time", now),
-		zap.Time("next_run", nextMidnight),
-		zap.Duration("time_until_next_run", timeUntilMidnight),
-	)
+// Stop stops scheduling new runs and waits for running jobs to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
 
-	// Start the scheduler
-	go func() {
-		// Wait until first midnight
-		time.Sleep(timeUntilMidnight)
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	for {
+		next := j.schedule.Next(time.Now().In(s.config.Location))
+		if next.IsZero() {
+			s.logger.Error("Job schedule has no upcoming activation", zap.String("job", j.name))
+			return
+		}
 
-		// Then run every 24 hours
-		ticker := time.NewTicker(24 * time.Hour)
-		for range ticker.C {
-			s.runResetTasks()
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
 		}
-	}()
+		s.execute(j, next)
+	}
 }
 
-func (s *Scheduler) runResetTasks() {
-	ctx := context.Background()
+// catchUp runs a job whose last activation was missed, e.g. because no instance was up at the time.
+// Instances starting together claim the same activation, so only one of them runs it.
+func (s *Scheduler) catchUp(ctx context.Context, j *job) {
+	slot := time.Unix(0, 0)
+	if last := s.lastRun(ctx, j.name); last != nil {
+		slot = j.schedule.Next(last.StartedAt.In(s.config.Location))
+		if slot.IsZero() || slot.After(time.Now()) {
+			return
+		}
+	}
+	s.execute(j, slot)
+}
+
+// execute claims the activation of the job at slot and runs it if no other instance has
+func (s *Scheduler) execute(j *job, slot time.Time) {
+	ctx := tracing.Start(context.Background())
+
+	acquired, err := s.locker.Acquire(ctx, j.name, slot, s.config.LockTTL)
+	if err != nil {
+		s.log(ctx).Error("Failed to claim scheduled job", zap.String("job", j.name), zap.Error(err))
+		return
+	}
+	if !acquired {
+		s.log(ctx).Info("Scheduled job already claimed by another instance",
+			zap.String("job", j.name),
+			zap.Time("slot", slot),
+		)
+		return
+	}
+
+	startTime := time.Now()
+	err = j.run(ctx)
+	s.recordRun(ctx, j.name, startTime, err)
+}
+
+// recordRun stores the outcome of a job run, evicting the oldest entry when full
+func (s *Scheduler) recordRun(ctx context.Context, job string, startTime time.Time, err error) {
+	finishedAt := time.Now()
+	run := JobRun{
+		Job:        job,
+		StartedAt:  startTime,
+		FinishedAt: finishedAt,
+		Duration:   finishedAt.Sub(startTime),
+		Status:     "succeeded",
+		Instance:   s.instance,
+	}
+	if tc := tracing.FromContext(ctx); tc != nil {
+		run.RequestID = tc.RequestID
+	}
+	if err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+	}
+
+	s.runsMu.Lock()
+	if len(s.runs) >= maxJobRuns {
+		s.runs = s.runs[1:]
+	}
+	s.runs = append(s.runs, run)
+	s.runsMu.Unlock()
+
+	if s.redis == nil {
+		return
+	}
+	if b, err := json.Marshal(run); err == nil {
+		if err := s.redis.Set(ctx, lastRunKeyPrefix+job, b, 0).Err(); err != nil {
+			s.log(ctx).Error("Failed to store job run", zap.String("job", job), zap.Error(err))
+		}
+	}
+}
+
+// log returns the logger tagged with the request ID of the job run in ctx
+func (s *Scheduler) log(ctx context.Context) *logger.Logger {
+	return s.logger.With(tracing.ZapFields(ctx)...)
+}
+
+// lastRun returns the latest run of a job on any instance, falling back to the local history
+func (s *Scheduler) lastRun(ctx context.Context, job string) *JobRun {
+	if s.redis != nil {
+		b, err := s.redis.Get(ctx, lastRunKeyPrefix+job).Bytes()
+		if err == nil {
+			var run JobRun
+			if json.Unmarshal(b, &run) == nil {
+				return &run
+			}
+		} else if !errors.Is(err, redis.Nil) {
+			s.logger.Error("Failed to load job run", zap.String("job", job), zap.Error(err))
+		}
+	}
+
+	if runs := s.RecentRuns(job, 1); len(runs) > 0 {
+		return &runs[0]
+	}
+	return nil
+}
+
+func (s *Scheduler) runResetTasks(ctx context.Context) error {
 	startTime := time.Now()
 
-	s.logger.Info("Starting daily habit reset tasks", zap.Time("start_time", startTime))
+	s.log(ctx).Info("Starting daily habit reset tasks", zap.Time("start_time", startTime))
+
+	var runErr error
 
 	// Reset daily completions for habits completed in past days
 	resetCount, err := s.habitService.ResetDailyCompletions(ctx)
 	if err != nil {
-		s.logger.Error("Failed to reset daily completions",
+		runErr = err
+		s.log(ctx).Error("Failed to reset daily completions",
 			zap.Error(err),
 		)
 	} else {
-		s.logger.Info("Successfully reset daily completions",
+		s.log(ctx).Info("Successfully reset daily completions",
 			zap.Int64("reset_count", resetCount),
 			zap.String("reset_criteria", "Habits completed before today"),
 		)
@@ -75,65 +436,134 @@ func (s *Scheduler) runResetTasks() {
 	// This will automatically log streak history before resetting
 	streakResetCount, err := s.habitService.CheckAndResetBrokenStreaks(ctx)
 	if err != nil {
-		s.logger.Error("Failed to reset broken streaks",
+		runErr = err
+		s.log(ctx).Error("Failed to reset broken streaks",
 			zap.Error(err),
 		)
 	} else {
-		s.logger.Info("Successfully processed broken streaks",
+		s.log(ctx).Info("Successfully processed broken streaks",
 			zap.Int64("streak_reset_count", streakResetCount),
 			zap.String("reset_criteria", "Habits not completed since yesterday"),
 		)
 	}
 
-	s.logger.Info("Completed daily habit reset tasks",
+	s.log(ctx).Info("Completed daily habit reset tasks",
 		zap.Time("end_time", time.Now()),
 		zap.Duration("duration", time.Since(startTime)),
 	)
-}
-
-// scheduleReminderNotifications sets up a schedule to send reminder notifications throughout the day
-func (s *Scheduler) scheduleReminderNotifications() {
-	// Calculate time to the next scheduled reminder (8AM, 12PM, 6PM, 9PM)
-	reminderHours := []int{8, 12, 18, 21}
-
-	// Run first and then schedule
-	s.sendReminderNotifications()
-
-	ticker := time.NewTicker(1 * time.Hour)
-	for range ticker.C {
-		now := time.Now()
-		currentHour := now.Hour()
-
-		// Check if current hour is a reminder hour
-		for _, reminderHour := range reminderHours {
-			if currentHour == reminderHour {
-				s.sendReminderNotifications()
-				break
-			}
-		}
-	}
+	return runErr
 }
 
 // sendReminderNotifications sends reminder notifications for habits due today
-func (s *Scheduler) sendReminderNotifications() {
-	ctx := context.Background()
+func (s *Scheduler) sendReminderNotifications(ctx context.Context) error {
 	startTime := time.Now()
 
-	s.logger.Info("Starting habit reminder notifications", zap.Time("start_time", startTime))
+	s.log(ctx).Info("Starting habit reminder notifications", zap.Time("start_time", startTime))
 
 	err := s.habitService.SendHabitReminders(ctx)
 	if err != nil {
-		s.logger.Error("Failed to send habit reminders",
+		s.log(ctx).Error("Failed to send habit reminders",
 			zap.Error(err),
 		)
 	} else {
-		s.logger.Info("Successfully sent habit reminders",
+		s.log(ctx).Info("Successfully sent habit reminders",
 			zap.Time("time", startTime),
 		)
 	}
 
-	s.logger.Info("Completed habit reminder notifications",
+	s.log(ctx).Info("Completed habit reminder notifications",
 		zap.Time("end_time", time.Now()),
 		zap.Duration("duration", time.Since(startTime)),
 	)
+	return err
+}
+
+// generateTodoOccurrences creates the occurrences recurring todos missed when they were completed
+func (s *Scheduler) generateTodoOccurrences(ctx context.Context) error {
+	generated, err := s.todoService.GenerateMissedOccurrences(ctx)
+	if err != nil {
+		s.log(ctx).Error("Failed to generate some recurring todo occurrences",
+			zap.Int("generated", generated),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.log(ctx).Info("Generated missed recurring todo occurrences", zap.Int("generated", generated))
+	return nil
+}
+
+func (s *Scheduler) analyzeTaskRisks(ctx context.Context) error {
+	atRisk, err := s.taskService.AnalyzeRisks(ctx)
+	if err != nil {
+		s.log(ctx).Error("Failed to analyze task risks", zap.Int("at_risk", atRisk), zap.Error(err))
+		return err
+	}
+
+	s.log(ctx).Info("Analyzed task risks", zap.Int("at_risk", atRisk))
+	return nil
+}
+
+func (s *Scheduler) scoreTaskPriorities(ctx context.Context) error {
+	scored, err := s.taskService.ScorePriorities(ctx)
+	if err != nil {
+		s.log(ctx).Error("Failed to score task priorities", zap.Int("scored", scored), zap.Error(err))
+		return err
+	}
+
+	s.log(ctx).Info("Scored task priorities", zap.Int("scored", scored))
+	return nil
+}
+
+func (s *Scheduler) purgeTrash(ctx context.Context) error {
+	before := time.Now().Add(-s.config.TrashRetention)
+
+	purgedTasks, err := s.taskService.PurgeDeletedTasks(ctx, before)
+	if err != nil {
+		s.log(ctx).Error("Failed to purge deleted tasks", zap.Error(err))
+		return err
+	}
+	purgedTodos, err := s.todoService.PurgeDeletedTodos(ctx, before)
+	if err != nil {
+		s.log(ctx).Error("Failed to purge deleted todos", zap.Error(err))
+		return err
+	}
+
+	s.log(ctx).Info("Purged trash", zap.Int64("tasks", purgedTasks), zap.Int64("todos", purgedTodos))
+	return nil
+}
+
+// applyRetention gives notice of expiring data, expires the data whose notice ran out and
+// purges organizations whose deletion is due
+func (s *Scheduler) applyRetention(ctx context.Context) error {
+	result, err := s.retentionService.Run(ctx)
+	if result == nil {
+		s.log(ctx).Error("Failed to load retention policies", zap.Error(err))
+		return err
+	}
+
+	fields := []zap.Field{
+		zap.Int("policies", result.Policies),
+		zap.Int("noticed", result.Noticed),
+		zap.Int64("deleted", result.Deleted),
+		zap.Int64("archived", result.Archived),
+		zap.Int64("purged", result.Purged),
+		zap.Int("organizations_purged", result.Organizations),
+	}
+	if err != nil {
+		s.log(ctx).Error("Failed to apply some retention policies", append(fields, zap.Int("failed", result.Failed), zap.Error(err))...)
+		return err
+	}
+	s.log(ctx).Info("Applied retention policies", fields...)
+	return nil
+}
+
+// reconcileProjections recounts the project task counters, which restoring from the trash,
+// copying projects and importing change without publishing events
+func (s *Scheduler) reconcileProjections(ctx context.Context) error {
+	if _, err := s.projectionService.Reconcile(ctx); err != nil {
+		s.log(ctx).Error("Failed to reconcile projections", zap.Error(err))
+		return err
+	}
+	return nil
 }
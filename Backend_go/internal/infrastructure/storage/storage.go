@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Drivers accepted in Config.Driver
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var (
+	ErrUnknownDriver = errors.New("unknown storage driver")
+	ErrNotFound      = errors.New("object not found")
+	ErrInvalidKey    = errors.New("invalid object key")
+)
+
+// Store keeps uploaded files under keys chosen by the caller. Keys are slash separated
+// paths of letters, digits, dashes, dots and underscores.
+type Store interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Config selects and configures the store. The local driver is used when Driver is empty.
+type Config struct {
+	Driver   string
+	LocalDir string
+	S3       S3Config
+}
+
+type S3Config struct {
+	// Endpoint defaults to AWS for the region
+	Endpoint     string
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	UsePathStyle bool
+}
+
+// NewStore creates the store for the configured driver
+func NewStore(config Config) (Store, error) {
+	switch config.Driver {
+	case "", DriverLocal:
+		dir := config.LocalDir
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStore(dir)
+	case DriverS3:
+		return NewS3Store(config.S3, &http.Client{Timeout: 5 * time.Minute})
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, config.Driver)
+	}
+}
+
+// validKey rejects keys that could escape the store's root or need escaping
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+		for _, r := range segment {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-._", r)) {
+				return ErrInvalidKey
+			}
+		}
+	}
+	return nil
+}
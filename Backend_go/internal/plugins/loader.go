@@ -0,0 +1,64 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+)
+
+// symbolName is the exported variable a shared object plugin must define, of type Plugin
+const symbolName = "Plugin"
+
+// loadDir opens every .so file in dir, in name order. Shared objects must be built with
+// `go build -buildmode=plugin` against the same Compass version as the server.
+func loadDir(dir string) ([]Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".so" {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	plugins := make([]Plugin, 0, len(files))
+	for _, file := range files {
+		p, err := open(file)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+func open(file string) (Plugin, error) {
+	so, err := plugin.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", file, err)
+	}
+	sym, err := so.Lookup(symbolName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s does not export %s", ErrInvalidPlugin, file, symbolName)
+	}
+
+	// Lookup returns a pointer to an exported variable
+	switch p := sym.(type) {
+	case *Plugin:
+		if *p != nil {
+			return *p, nil
+		}
+	case Plugin:
+		return p, nil
+	}
+	return nil, fmt.Errorf("%w: %s exports %s of type %T", ErrInvalidPlugin, file, symbolName, sym)
+}
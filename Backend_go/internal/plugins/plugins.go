@@ -0,0 +1,219 @@
+// Package plugins lets deployments add behavior to Compass without forking the core
+// services. A plugin subscribes to lifecycle events and may mount its own routes under
+// /api/plugins/<name>. Plugins are either compiled in, by a file behind a build tag that
+// calls Register from init, or loaded at startup from shared objects in the plugins
+// directory, each exporting a variable named Plugin.
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Event names a point in the lifecycle of a core entity that plugins can hook into
+type Event string
+
+// Lifecycle events. Task events carry the *task.Task being created; workflow step events
+// carry a *workflow.StepHookPayload.
+const (
+	BeforeTaskCreate          Event = "task.before_create"
+	AfterTaskCreate           Event = "task.after_create"
+	BeforeWorkflowStepExecute Event = "workflow.step.before_execute"
+	AfterWorkflowStepExecute  Event = "workflow.step.after_execute"
+)
+
+var (
+	// ErrRejected is matched by the error returned when a before hook vetoes an operation
+	ErrRejected        = errors.New("rejected by plugin")
+	ErrDuplicatePlugin = errors.New("plugin already registered")
+	ErrInvalidPlugin   = errors.New("invalid plugin")
+)
+
+// Handler is called with the payload of the event it subscribed to. Before handlers may
+// change the payload, and veto the operation by returning an error.
+type Handler func(ctx context.Context, payload interface{}) error
+
+// Plugin is an extension installed into the registry at startup
+type Plugin interface {
+	// Name identifies the plugin in logs and in the path of its routes
+	Name() string
+	// Register subscribes the plugin to events and declares its routes
+	Register(r *Registrar) error
+}
+
+// Hooks runs plugin handlers at the lifecycle events of the core services
+type Hooks interface {
+	// Before runs the handlers of event in registration order and returns the first error,
+	// which aborts the operation
+	Before(ctx context.Context, event Event, payload interface{}) error
+	// After runs the handlers of event. Their errors are logged and never fail the operation.
+	After(ctx context.Context, event Event, payload interface{})
+}
+
+// RejectedError is returned by Before when a plugin vetoes an operation
+type RejectedError struct {
+	Plugin string
+	Err    error
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Plugin, e.Err)
+}
+
+func (e *RejectedError) Unwrap() error { return e.Err }
+
+func (e *RejectedError) Is(target error) bool { return target == ErrRejected }
+
+var (
+	builtinMu sync.Mutex
+	builtin   []Plugin
+)
+
+// Register adds a compiled-in plugin. It is meant to be called from init in a file
+// guarded by a build tag, so the plugin is only part of builds that ask for it.
+func Register(p Plugin) {
+	builtinMu.Lock()
+	defer builtinMu.Unlock()
+	builtin = append(builtin, p)
+}
+
+type subscription struct {
+	plugin  string
+	handler Handler
+}
+
+type pluginRoutes struct {
+	plugin   string
+	register func(group *gin.RouterGroup)
+}
+
+// Registry holds the installed plugins, their event handlers and their routes
+type Registry struct {
+	mu       sync.RWMutex
+	names    map[string]bool
+	handlers map[Event][]subscription
+	routes   []pluginRoutes
+	logger   *zap.Logger
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry(logger *zap.Logger) *Registry {
+	return &Registry{
+		names:    make(map[string]bool),
+		handlers: make(map[Event][]subscription),
+		logger:   logger,
+	}
+}
+
+// Load installs the compiled-in plugins and then those found in dir. An empty dir only
+// installs the compiled-in plugins.
+func (r *Registry) Load(dir string) error {
+	builtinMu.Lock()
+	plugins := append([]Plugin(nil), builtin...)
+	builtinMu.Unlock()
+
+	if dir != "" {
+		loaded, err := loadDir(dir)
+		if err != nil {
+			return err
+		}
+		plugins = append(plugins, loaded...)
+	}
+
+	for _, p := range plugins {
+		if err := r.Install(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Install registers a single plugin
+func (r *Registry) Install(p Plugin) error {
+	name := p.Name()
+	if name == "" {
+		return fmt.Errorf("%w: plugin has no name", ErrInvalidPlugin)
+	}
+
+	r.mu.Lock()
+	if r.names[name] {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrDuplicatePlugin, name)
+	}
+	r.names[name] = true
+	r.mu.Unlock()
+
+	if err := p.Register(&Registrar{registry: r, plugin: name}); err != nil {
+		return fmt.Errorf("failed to register plugin %s: %w", name, err)
+	}
+	r.logger.Info("Plugin installed", zap.String("plugin", name))
+	return nil
+}
+
+// Before implements Hooks
+func (r *Registry) Before(ctx context.Context, event Event, payload interface{}) error {
+	for _, sub := range r.subscriptions(event) {
+		if err := sub.handler(ctx, payload); err != nil {
+			return &RejectedError{Plugin: sub.plugin, Err: err}
+		}
+	}
+	return nil
+}
+
+// After implements Hooks
+func (r *Registry) After(ctx context.Context, event Event, payload interface{}) {
+	for _, sub := range r.subscriptions(event) {
+		if err := sub.handler(ctx, payload); err != nil {
+			r.logger.Warn("Plugin hook failed",
+				zap.String("plugin", sub.plugin),
+				zap.String("event", string(event)),
+				zap.Error(err))
+		}
+	}
+}
+
+func (r *Registry) subscriptions(event Event) []subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.handlers[event]
+}
+
+// RegisterRoutes mounts the routes of every plugin on its own group under /api/plugins.
+// The groups carry the given middleware, so plugin endpoints are authenticated like the
+// core API.
+func (r *Registry) RegisterRoutes(router *gin.Engine, middleware ...gin.HandlerFunc) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, routes := range r.routes {
+		group := router.Group("/api/plugins/" + routes.plugin)
+		group.Use(middleware...)
+		routes.register(group)
+	}
+}
+
+// Registrar is handed to a plugin while it registers, and attributes its handlers and
+// routes to it
+type Registrar struct {
+	registry *Registry
+	plugin   string
+}
+
+// On subscribes handler to event
+func (r *Registrar) On(event Event, handler Handler) {
+	r.registry.mu.Lock()
+	defer r.registry.mu.Unlock()
+	r.registry.handlers[event] = append(r.registry.handlers[event], subscription{plugin: r.plugin, handler: handler})
+}
+
+// Routes declares the plugin's routes. register is called with the plugin's group once
+// the server mounts plugin routes.
+func (r *Registrar) Routes(register func(group *gin.RouterGroup)) {
+	r.registry.mu.Lock()
+	defer r.registry.mu.Unlock()
+	r.registry.routes = append(r.registry.routes, pluginRoutes{plugin: r.plugin, register: register})
+}
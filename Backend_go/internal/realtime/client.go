@@ -0,0 +1,313 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// sendBuffer is how many events may queue for a client before new ones are dropped
+	sendBuffer = 64
+	// readLimit caps the size of a command sent by a client
+	readLimit = 4 * 1024
+
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = 30 * time.Second
+
+	// accessTTL is how long a subscription trusts the membership it resolved before
+	// checking it again, so removed members and changed roles stop receiving events
+	accessTTL = time.Minute
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrInvalidEvent   = errors.New("invalid event type")
+	ErrNotMember      = errors.New("not a member of the organization")
+)
+
+// Command is a message sent by a client to change what it receives
+type Command struct {
+	// Command is subscribe, unsubscribe or filter
+	Command        string               `json:"command"`
+	OrganizationID uuid.UUID            `json:"organization_id,omitempty"`
+	Events         []webhooks.EventType `json:"events,omitempty"`
+}
+
+// Reply acknowledges a command
+type Reply struct {
+	Type           string               `json:"type"`
+	OrganizationID uuid.UUID            `json:"organization_id,omitempty"`
+	Events         []webhooks.EventType `json:"events,omitempty"`
+	Error          string               `json:"error,omitempty"`
+}
+
+// access is what a subscriber may see of an organization
+type access struct {
+	membership *organization.Membership
+	// projects lists the projects whose tasks the subscriber sees; nil when they see all
+	projects map[uuid.UUID]struct{}
+	expires  time.Time
+}
+
+// sees applies the search scoping to an event: tasks the user created, is assigned or
+// reviews are always visible, other tasks need read access and, unless the member can
+// update projects, a project they belong to. Workflow events need read access to workflows.
+func (a *access) sees(userID uuid.UUID, r routing) bool {
+	switch {
+	case strings.HasPrefix(string(r.Type), "task."):
+		task := r.Data.Task
+		if task == nil {
+			return false
+		}
+		if task.CreatorID == userID ||
+			(task.AssigneeID != nil && *task.AssigneeID == userID) ||
+			(task.ReviewerID != nil && *task.ReviewerID == userID) {
+			return true
+		}
+		if !a.membership.HasPermission("tasks:read") {
+			return false
+		}
+		if a.projects == nil {
+			return true
+		}
+		_, ok := a.projects[task.ProjectID]
+		return ok
+	case strings.HasPrefix(string(r.Type), "workflow."):
+		return a.membership.HasPermission("workflows:read")
+	}
+	return true
+}
+
+// Client is a single websocket connection. It always receives events about its own user
+// that do not belong to an organization, and the events of the organizations it subscribed
+// to that its role lets it see.
+type Client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	userID uuid.UUID
+
+	outbound chan []byte
+
+	mu            sync.RWMutex
+	organizations map[uuid.UUID]*access
+	// events limits delivery to these types; empty means every type
+	events map[webhooks.EventType]struct{}
+	closed bool
+}
+
+// Serve streams events to the connection until it closes
+func (h *Hub) Serve(ctx context.Context, conn *websocket.Conn, userID uuid.UUID) {
+	client := &Client{
+		hub:           h,
+		conn:          conn,
+		userID:        userID,
+		outbound:      make(chan []byte, sendBuffer),
+		organizations: make(map[uuid.UUID]*access),
+		events:        make(map[webhooks.EventType]struct{}),
+	}
+
+	h.register(client)
+	defer h.unregister(client)
+
+	go client.writePump()
+	client.readPump(ctx)
+}
+
+// wants reports whether the event should be delivered to the client. The membership of
+// an organization is resolved again once it expires; a client that is no longer a member
+// is unsubscribed.
+func (c *Client) wants(ctx context.Context, r routing) bool {
+	c.mu.RLock()
+	_, filtered := c.events[r.Type]
+	filtered = filtered || len(c.events) == 0
+	a, subscribed := c.organizations[r.OrganizationID]
+	c.mu.RUnlock()
+
+	if !filtered {
+		return false
+	}
+	if r.OrganizationID == uuid.Nil {
+		return r.UserID == c.userID
+	}
+	if !subscribed {
+		return false
+	}
+
+	if time.Now().After(a.expires) {
+		var err error
+		a, err = c.resolve(ctx, r.OrganizationID)
+		if errors.Is(err, organization.ErrNotMember) {
+			c.mu.Lock()
+			delete(c.organizations, r.OrganizationID)
+			c.mu.Unlock()
+			c.reply(Reply{Type: "unsubscribed", OrganizationID: r.OrganizationID, Error: ErrNotMember.Error()})
+			return false
+		}
+		if err != nil {
+			c.hub.logger.Warn("Failed to check realtime subscription", zap.String("user_id", c.userID.String()),
+				zap.String("organization_id", r.OrganizationID.String()), zap.Error(err))
+			return false
+		}
+		c.mu.Lock()
+		if _, ok := c.organizations[r.OrganizationID]; ok {
+			c.organizations[r.OrganizationID] = a
+		}
+		c.mu.Unlock()
+	}
+	return a.sees(c.userID, r)
+}
+
+// resolve looks up what the client's user may see of an organization
+func (c *Client) resolve(ctx context.Context, orgID uuid.UUID) (*access, error) {
+	membership, err := c.hub.members.ResolveMembership(ctx, orgID, c.userID)
+	if err != nil {
+		return nil, err
+	}
+	a := &access{membership: membership, expires: time.Now().Add(accessTTL)}
+	if !membership.HasPermission("projects:update") {
+		ids, err := c.hub.projects.MemberProjectIDs(ctx, orgID, c.userID)
+		if err != nil {
+			return nil, err
+		}
+		a.projects = make(map[uuid.UUID]struct{}, len(ids))
+		for _, id := range ids {
+			a.projects[id] = struct{}{}
+		}
+	}
+	return a, nil
+}
+
+// send queues a message, dropping it if the client is not keeping up
+func (c *Client) send(message []byte) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.outbound <- message:
+	default:
+		c.hub.logger.Warn("Dropping realtime event for slow client", zap.String("user_id", c.userID.String()))
+	}
+}
+
+func (c *Client) reply(reply Reply) {
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return
+	}
+	c.send(data)
+}
+
+// close stops the write pump, which then closes the connection
+func (c *Client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.outbound)
+	}
+}
+
+func (c *Client) readPump(ctx context.Context) {
+	c.conn.SetReadLimit(readLimit)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				c.hub.logger.Warn("Realtime connection read error", zap.String("user_id", c.userID.String()), zap.Error(err))
+			}
+			return
+		}
+
+		var cmd Command
+		if err := json.Unmarshal(message, &cmd); err != nil {
+			c.reply(Reply{Type: "error", Error: "invalid command"})
+			continue
+		}
+		if err := c.handle(ctx, cmd); err != nil {
+			c.reply(Reply{Type: "error", OrganizationID: cmd.OrganizationID, Error: err.Error()})
+		}
+	}
+}
+
+func (c *Client) handle(ctx context.Context, cmd Command) error {
+	switch cmd.Command {
+	case "subscribe":
+		a, err := c.resolve(ctx, cmd.OrganizationID)
+		if err != nil {
+			return ErrNotMember
+		}
+		c.mu.Lock()
+		c.organizations[cmd.OrganizationID] = a
+		c.mu.Unlock()
+		c.reply(Reply{Type: "subscribed", OrganizationID: cmd.OrganizationID})
+	case "unsubscribe":
+		c.mu.Lock()
+		delete(c.organizations, cmd.OrganizationID)
+		c.mu.Unlock()
+		c.reply(Reply{Type: "unsubscribed", OrganizationID: cmd.OrganizationID})
+	case "filter":
+		events := make(map[webhooks.EventType]struct{}, len(cmd.Events))
+		for _, e := range cmd.Events {
+			if e == webhooks.EventAll {
+				events = map[webhooks.EventType]struct{}{}
+				break
+			}
+			if !e.IsValid() && e != webhooks.EventBadgeCountsChanged {
+				return ErrInvalidEvent
+			}
+			events[e] = struct{}{}
+		}
+		c.mu.Lock()
+		c.events = events
+		c.mu.Unlock()
+		c.reply(Reply{Type: "filtered", Events: cmd.Events})
+	default:
+		return ErrUnknownCommand
+	}
+	return nil
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.outbound:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
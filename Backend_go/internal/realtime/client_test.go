@@ -0,0 +1,110 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeMembers struct {
+	memberships map[uuid.UUID]*organization.Membership
+	resolved    int
+}
+
+func (f *fakeMembers) ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*organization.Membership, error) {
+	f.resolved++
+	membership, ok := f.memberships[userID]
+	if !ok {
+		return nil, organization.ErrNotMember
+	}
+	return membership, nil
+}
+
+type fakeProjects struct {
+	ids []uuid.UUID
+}
+
+func (f *fakeProjects) MemberProjectIDs(ctx context.Context, organizationID, userID uuid.UUID) ([]uuid.UUID, error) {
+	return f.ids, nil
+}
+
+func newTestClient(members *fakeMembers, projects *fakeProjects, userID uuid.UUID) *Client {
+	hub := &Hub{members: members, projects: projects, logger: zap.NewNop(), clients: make(map[*Client]struct{})}
+	return &Client{
+		hub:           hub,
+		userID:        userID,
+		outbound:      make(chan []byte, sendBuffer),
+		organizations: make(map[uuid.UUID]*access),
+		events:        make(map[webhooks.EventType]struct{}),
+	}
+}
+
+func taskEvent(orgID, projectID, creatorID uuid.UUID) routing {
+	r := routing{Type: webhooks.EventTaskUpdated, OrganizationID: orgID}
+	r.Data.Task = &taskRouting{ProjectID: projectID, CreatorID: creatorID}
+	return r
+}
+
+func TestClientWantsAppliesProjectVisibility(t *testing.T) {
+	orgID, userID, memberProject, otherProject := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	reader := &organization.Membership{OrganizationID: orgID, UserID: userID, Permissions: []string{"tasks:read"}}
+	manager := &organization.Membership{OrganizationID: orgID, UserID: userID, Permissions: []string{"tasks:read", "projects:update"}}
+	guest := &organization.Membership{OrganizationID: orgID, UserID: userID}
+
+	tests := []struct {
+		name       string
+		membership *organization.Membership
+		event      routing
+		want       bool
+	}{
+		{"Task of a member project", reader, taskEvent(orgID, memberProject, uuid.New()), true},
+		{"Task of another project", reader, taskEvent(orgID, otherProject, uuid.New()), false},
+		{"Task of another project for a project manager", manager, taskEvent(orgID, otherProject, uuid.New()), true},
+		{"Own task without read access", guest, taskEvent(orgID, otherProject, userID), true},
+		{"Task without read access", guest, taskEvent(orgID, memberProject, uuid.New()), false},
+		{"Workflow event without read access", reader, routing{Type: webhooks.EventWorkflowExecutionStarted, OrganizationID: orgID}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := &fakeMembers{memberships: map[uuid.UUID]*organization.Membership{userID: tt.membership}}
+			client := newTestClient(members, &fakeProjects{ids: []uuid.UUID{memberProject}}, userID)
+
+			assert.NoError(t, client.handle(context.Background(), Command{Command: "subscribe", OrganizationID: orgID}))
+			assert.Equal(t, tt.want, client.wants(context.Background(), tt.event))
+		})
+	}
+}
+
+func TestClientWantsRechecksExpiredMembership(t *testing.T) {
+	orgID, userID, projectID := uuid.New(), uuid.New(), uuid.New()
+	members := &fakeMembers{memberships: map[uuid.UUID]*organization.Membership{
+		userID: {OrganizationID: orgID, UserID: userID, Permissions: []string{"tasks:read", "projects:update"}},
+	}}
+	client := newTestClient(members, &fakeProjects{}, userID)
+	event := taskEvent(orgID, projectID, uuid.New())
+
+	assert.NoError(t, client.handle(context.Background(), Command{Command: "subscribe", OrganizationID: orgID}))
+	<-client.outbound
+
+	assert.True(t, client.wants(context.Background(), event))
+	assert.Equal(t, 1, members.resolved, "a fresh membership is not resolved again")
+
+	delete(members.memberships, userID)
+	client.organizations[orgID].expires = time.Now().Add(-time.Second)
+
+	assert.False(t, client.wants(context.Background(), event))
+	assert.NotContains(t, client.organizations, orgID)
+
+	var reply Reply
+	assert.NoError(t, json.Unmarshal(<-client.outbound, &reply))
+	assert.Equal(t, "unsubscribed", reply.Type)
+	assert.Equal(t, orgID, reply.OrganizationID)
+}
@@ -0,0 +1,168 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/organization"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/domain/webhooks"
+	"github.com/ahmedelhadi17776/Compass/Backend_go/internal/infrastructure/cache"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Channel is the Redis channel entity change events are published on
+const Channel = "realtime_events"
+
+// MembershipResolver checks that a user belongs to an organization before they can
+// subscribe to its events
+type MembershipResolver interface {
+	ResolveMembership(ctx context.Context, orgID, userID uuid.UUID) (*organization.Membership, error)
+}
+
+// ProjectResolver lists the projects whose tasks a member without access to every
+// project may see
+type ProjectResolver interface {
+	MemberProjectIDs(ctx context.Context, organizationID, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// Hub streams entity change events to connected clients. It implements webhooks.Publisher
+// so it receives the same domain events as outbound webhooks. Events go through Redis
+// pub/sub, so a client connected to any API instance sees changes made on every other.
+type Hub struct {
+	redis    *redis.Client
+	members  MembershipResolver
+	projects ProjectResolver
+	logger   *zap.Logger
+
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHub creates a realtime hub backed by Redis
+func NewHub(redisClient *cache.RedisClient, members MembershipResolver, projects ProjectResolver, logger *zap.Logger) *Hub {
+	return &Hub{
+		redis:    redisClient.GetClient(),
+		members:  members,
+		projects: projects,
+		logger:   logger,
+		clients:  make(map[*Client]struct{}),
+	}
+}
+
+// Publish sends the event to every API instance
+func (h *Hub) Publish(ctx context.Context, event webhooks.Event) {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error("Failed to encode realtime event", zap.String("event", string(event.Type)), zap.Error(err))
+		return
+	}
+	if err := h.redis.Publish(ctx, Channel, data).Err(); err != nil {
+		h.logger.Error("Failed to publish realtime event", zap.String("event", string(event.Type)), zap.Error(err))
+	}
+}
+
+// Start subscribes to the event channel and fans events out to local clients
+func (h *Hub) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.done = make(chan struct{})
+
+	pubsub := h.redis.Subscribe(ctx, Channel)
+	go func() {
+		defer close(h.done)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				h.dispatch(ctx, []byte(msg.Payload))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the subscription and disconnects every client
+func (h *Hub) Stop() {
+	if h.cancel == nil {
+		return
+	}
+	h.cancel()
+	<-h.done
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		client.close()
+		delete(h.clients, client)
+	}
+}
+
+// routing holds the fields of a published event needed to pick its recipients
+type routing struct {
+	Type           webhooks.EventType `json:"type"`
+	OrganizationID uuid.UUID          `json:"organization_id"`
+	UserID         uuid.UUID          `json:"user_id"`
+	Data           struct {
+		Task *taskRouting `json:"task"`
+	} `json:"data"`
+}
+
+// taskRouting holds the fields of a task event that decide which members may see it
+type taskRouting struct {
+	ProjectID  uuid.UUID  `json:"project_id"`
+	CreatorID  uuid.UUID  `json:"creator_id"`
+	AssigneeID *uuid.UUID `json:"assignee_id"`
+	ReviewerID *uuid.UUID `json:"reviewer_id"`
+}
+
+// dispatch forwards the encoded event unchanged to every client that should see it
+func (h *Hub) dispatch(ctx context.Context, payload []byte) {
+	var r routing
+	if err := json.Unmarshal(payload, &r); err != nil {
+		h.logger.Warn("Discarding malformed realtime event", zap.Error(err))
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client.wants(ctx, r) {
+			client.send(payload)
+		}
+	}
+}
+
+func (h *Hub) register(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[client] = struct{}{}
+}
+
+func (h *Hub) unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[client]; ok {
+		client.close()
+		delete(h.clients, client)
+	}
+}
@@ -0,0 +1,107 @@
+// Package background runs work that outlives the request that started it. The work runs
+// in a Group tied to the server's lifecycle, so that shutting down waits for it to finish
+// instead of killing it in the middle of a write.
+package background
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// interruptGrace is how long Shutdown waits for cancelled work to record where it stopped
+const interruptGrace = 5 * time.Second
+
+// ErrShutdown is the cause of the context of work that shutdown stopped waiting for
+var ErrShutdown = errors.New("interrupted by shutdown")
+
+// Group tracks the background work of the process
+type Group struct {
+	logger *zap.Logger
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu       sync.Mutex
+	draining bool
+	running  sync.WaitGroup
+}
+
+// NewGroup creates a group that accepts work until Shutdown
+func NewGroup(logger *zap.Logger) *Group {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &Group{logger: logger, ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a goroutine. Its context keeps the trace of ctx but is not cancelled with
+// it, only when Shutdown stops waiting. Once Shutdown has begun Go runs nothing and
+// returns false. A panic in fn is logged rather than crashing the process.
+func (g *Group) Go(ctx context.Context, name string, fn func(ctx context.Context)) bool {
+	g.mu.Lock()
+	if g.draining {
+		g.mu.Unlock()
+		return false
+	}
+	g.running.Add(1)
+	g.mu.Unlock()
+
+	workCtx, cancel := context.WithCancelCause(tracing.Detach(ctx))
+	stop := context.AfterFunc(g.ctx, func() { cancel(context.Cause(g.ctx)) })
+	go func() {
+		defer g.running.Done()
+		defer func() {
+			stop()
+			cancel(nil)
+		}()
+		defer func() {
+			if r := recover(); r != nil {
+				tracing.Logger(workCtx, g.logger).Error("Background work panicked",
+					zap.String("work", name),
+					zap.Any("panic", r),
+					zap.Stack("stack"))
+			}
+		}()
+		fn(workCtx)
+	}()
+	return true
+}
+
+// Shutdown stops accepting work and waits for the running work to finish. If ctx ends
+// first, the work still running is cancelled with ErrShutdown and given a few seconds to
+// record where it stopped, and ctx's error is returned.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.draining = true
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		g.cancel(nil)
+		return nil
+	case <-ctx.Done():
+	}
+
+	g.logger.Warn("Shutdown timed out, interrupting background work")
+	g.cancel(ErrShutdown)
+	select {
+	case <-done:
+	case <-time.After(interruptGrace):
+		g.logger.Error("Background work did not stop after being interrupted")
+	}
+	return ctx.Err()
+}
+
+// Interrupted reports whether ctx was cancelled because shutdown stopped waiting for the
+// work it belongs to. Work can then leave what it did to be resumed on the next start.
+func Interrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrShutdown)
+}
@@ -0,0 +1,51 @@
+package background
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestShutdownDrainsRunningWork(t *testing.T) {
+	g := NewGroup(zap.NewNop())
+	finished := make(chan struct{})
+	g.Go(context.Background(), "slow", func(ctx context.Context) {
+		time.Sleep(50 * time.Millisecond)
+		close(finished)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := g.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Shutdown returned before the work finished")
+	}
+
+	if g.Go(context.Background(), "late", func(context.Context) {}) {
+		t.Error("Go accepted work after Shutdown")
+	}
+}
+
+func TestShutdownInterruptsWorkPastDeadline(t *testing.T) {
+	g := NewGroup(zap.NewNop())
+	interrupted := make(chan bool, 1)
+	g.Go(context.Background(), "stuck", func(ctx context.Context) {
+		<-ctx.Done()
+		interrupted <- Interrupted(ctx)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := g.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown error = %v, want deadline exceeded", err)
+	}
+	if !<-interrupted {
+		t.Error("work was cancelled without ErrShutdown as the cause")
+	}
+}
@@ -0,0 +1,192 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrDeadLetterNotFound is returned when a dead-letter message does not exist
+var ErrDeadLetterNotFound = errors.New("dead-letter message not found")
+
+// QueueStats summarizes the state of a single topic
+type QueueStats struct {
+	Topic       string `json:"topic"`
+	Pending     int    `json:"pending"`
+	Subscribers int    `json:"subscribers"`
+	DeadLetters int    `json:"dead_letters"`
+	Paused      bool   `json:"paused"`
+}
+
+// DeadLetter is a message whose processing failed
+type DeadLetter struct {
+	Message
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	FailedAt  time.Time `json:"failed_at"`
+
+	message *InMemoryMessage
+}
+
+// QueueAdmin exposes operational controls over a message broker
+type QueueAdmin interface {
+	// Stats returns the state of every known topic
+	Stats() []QueueStats
+
+	// PauseTopic stops dispatching new messages on a topic
+	PauseTopic(topic string) error
+
+	// ResumeTopic resumes dispatching and delivers messages queued while paused
+	ResumeTopic(ctx context.Context, topic string) error
+
+	// DeadLetters returns the failed messages of a topic
+	DeadLetters(topic string) ([]DeadLetter, error)
+
+	// RequeueDeadLetter removes a failed message from the dead-letter queue and dispatches it again
+	RequeueDeadLetter(ctx context.Context, topic, messageID string) error
+}
+
+// Stats returns the state of every known topic
+func (b *InMemoryBroker) Stats() []QueueStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := make([]QueueStats, 0, len(b.topics))
+	for topic, messages := range b.topics {
+		pending := 0
+		for _, msg := range messages {
+			if !msg.processed {
+				pending++
+			}
+		}
+		stats = append(stats, QueueStats{
+			Topic:       topic,
+			Pending:     pending,
+			Subscribers: len(b.subscriptions[topic]),
+			DeadLetters: len(b.deadLetters[topic]),
+			Paused:      b.paused[topic],
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Topic < stats[j].Topic })
+	return stats
+}
+
+// PauseTopic stops dispatching new messages on a topic
+func (b *InMemoryBroker) PauseTopic(topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errors.New("broker is closed")
+	}
+	if _, exists := b.topics[topic]; !exists {
+		return ErrQueueNotFound
+	}
+
+	b.paused[topic] = true
+	b.logger.WithField("topic", topic).Info("Topic paused")
+	return nil
+}
+
+// ResumeTopic resumes dispatching and delivers messages queued while paused
+func (b *InMemoryBroker) ResumeTopic(ctx context.Context, topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errors.New("broker is closed")
+	}
+	if _, exists := b.topics[topic]; !exists {
+		return ErrQueueNotFound
+	}
+	if !b.paused[topic] {
+		return nil
+	}
+
+	delete(b.paused, topic)
+
+	// Deliver everything that was held back, skipping messages already in the dead-letter queue
+	dead := make(map[string]struct{}, len(b.deadLetters[topic]))
+	for _, dl := range b.deadLetters[topic] {
+		dead[dl.ID] = struct{}{}
+	}
+	for _, msg := range b.topics[topic] {
+		if _, isDead := dead[msg.ID]; !msg.processed && msg.attempts == 0 && !isDead {
+			b.dispatch(ctx, msg)
+		}
+	}
+
+	b.logger.WithField("topic", topic).Info("Topic resumed")
+	return nil
+}
+
+// DeadLetters returns the failed messages of a topic
+func (b *InMemoryBroker) DeadLetters(topic string) ([]DeadLetter, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if _, exists := b.topics[topic]; !exists {
+		return nil, ErrQueueNotFound
+	}
+
+	result := make([]DeadLetter, 0, len(b.deadLetters[topic]))
+	for _, dl := range b.deadLetters[topic] {
+		result = append(result, *dl)
+	}
+	return result, nil
+}
+
+// RequeueDeadLetter removes a failed message from the dead-letter queue and dispatches it again
+func (b *InMemoryBroker) RequeueDeadLetter(ctx context.Context, topic, messageID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errors.New("broker is closed")
+	}
+
+	letters, exists := b.deadLetters[topic]
+	if !exists {
+		return ErrDeadLetterNotFound
+	}
+
+	for i, dl := range letters {
+		if dl.ID != messageID {
+			continue
+		}
+
+		b.deadLetters[topic] = append(letters[:i], letters[i+1:]...)
+		dl.message.processed = false
+		if !b.paused[topic] {
+			b.dispatch(ctx, dl.message)
+		}
+
+		b.logger.WithField("topic", topic).WithField("message_id", messageID).Info("Dead-letter message requeued")
+		return nil
+	}
+
+	return ErrDeadLetterNotFound
+}
+
+// addDeadLetter records a failed message. Callers must hold b.mu.
+func (b *InMemoryBroker) addDeadLetter(msg *InMemoryMessage, err error) {
+	// A message handled by several subscribers only needs one dead-letter entry
+	for _, dl := range b.deadLetters[msg.Topic] {
+		if dl.ID == msg.ID {
+			dl.Attempts = msg.attempts
+			dl.LastError = err.Error()
+			dl.FailedAt = time.Now()
+			return
+		}
+	}
+
+	b.deadLetters[msg.Topic] = append(b.deadLetters[msg.Topic], &DeadLetter{
+		Message:   msg.Message,
+		Attempts:  msg.attempts,
+		LastError: err.Error(),
+		FailedAt:  time.Now(),
+		message:   msg,
+	})
+}
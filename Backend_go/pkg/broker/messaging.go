@@ -76,6 +76,8 @@ type InMemoryMessage struct {
 type InMemoryBroker struct {
 	topics        map[string][]*InMemoryMessage
 	subscriptions map[string]map[string]MessageHandler
+	paused        map[string]bool
+	deadLetters   map[string][]*DeadLetter
 	mu            sync.RWMutex
 	logger        *logrus.Logger
 	queueSize     int
@@ -100,6 +102,8 @@ func NewInMemoryBroker(logger *logrus.Logger, queueSize int) *InMemoryBroker {
 	broker := &InMemoryBroker{
 		topics:        make(map[string][]*InMemoryMessage),
 		subscriptions: make(map[string]map[string]MessageHandler),
+		paused:        make(map[string]bool),
+		deadLetters:   make(map[string][]*DeadLetter),
 		logger:        logger,
 		queueSize:     queueSize,
 	}
@@ -139,6 +143,8 @@ func (b *InMemoryBroker) DeleteTopic(ctx context.Context, topic string) error {
 
 	delete(b.topics, topic)
 	delete(b.subscriptions, topic)
+	delete(b.paused, topic)
+	delete(b.deadLetters, topic)
 
 	return nil
 }
@@ -178,14 +184,24 @@ func (b *InMemoryBroker) Publish(ctx context.Context, topic string, payload []by
 
 	b.topics[topic] = append(b.topics[topic], msg)
 
+	// Paused topics keep the message queued until the topic is resumed
+	if b.paused[topic] {
+		return nil
+	}
+
 	// Notify subscribers asynchronously
-	if subs, ok := b.subscriptions[topic]; ok && len(subs) > 0 {
+	b.dispatch(ctx, msg)
+
+	return nil
+}
+
+// dispatch hands a message to every subscriber of its topic. Callers must hold b.mu.
+func (b *InMemoryBroker) dispatch(ctx context.Context, msg *InMemoryMessage) {
+	if subs, ok := b.subscriptions[msg.Topic]; ok && len(subs) > 0 {
 		for _, handler := range subs {
-			go b.processMessage(ctx, handler, &msg.Message)
+			go b.processMessage(ctx, handler, msg)
 		}
 	}
-
-	return nil
 }
 
 // Subscribe subscribes to a topic
@@ -218,14 +234,26 @@ func (b *InMemoryBroker) Subscribe(ctx context.Context, topic string, handler Me
 }
 
 // processMessage processes a message with a handler
-func (b *InMemoryBroker) processMessage(ctx context.Context, handler MessageHandler, msg *Message) {
+func (b *InMemoryBroker) processMessage(ctx context.Context, handler MessageHandler, msg *InMemoryMessage) {
 	// Create a new background context for async processing to prevent cancellation issues
 	processingCtx := context.Background()
 
-	err := handler(processingCtx, msg)
+	err := handler(processingCtx, &msg.Message)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	msg.attempts++
 	if err != nil {
 		b.logger.WithError(err).WithField("message_id", msg.ID).Error("Error processing message")
+		b.addDeadLetter(msg, err)
+		return
 	}
+	msg.processed = true
 }
 
 // Close closes the broker
@@ -242,6 +270,8 @@ func (b *InMemoryBroker) Close() error {
 	// Clear all topics and subscriptions
 	b.topics = nil
 	b.subscriptions = nil
+	b.paused = nil
+	b.deadLetters = nil
 
 	return nil
 }
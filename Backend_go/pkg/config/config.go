@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ahmedelhadi17776/Compass/Backend_go/pkg/logger"
 	"github.com/spf13/viper"
 )
 
@@ -20,12 +21,26 @@ type Config struct {
 	CORS     CORSConfig     `mapstructure:"cors"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
 	Swagger  SwaggerConfig  `mapstructure:"swagger"`
+	Inbound  InboundConfig  `mapstructure:"inbound"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Chat      ChatConfig      `mapstructure:"chat"`
+	VCS       VCSConfig       `mapstructure:"vcs"`
+	Billing   BillingConfig   `mapstructure:"billing"`
+	Legal     LegalConfig     `mapstructure:"legal"`
+	Plugins   PluginsConfig   `mapstructure:"plugins"`
+	Email     EmailConfig     `mapstructure:"email"`
+	AI        AIConfig        `mapstructure:"ai"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	SLO       SLOConfig       `mapstructure:"slo"`
+	Storage   StorageConfig   `mapstructure:"storage"`
 }
 
 type ServerConfig struct {
 	Port    int           `mapstructure:"port" default:"8000"`
 	Mode    string        `mapstructure:"mode"`
 	Timeout time.Duration `mapstructure:"timeout"`
+	// ShutdownTimeout is how long shutdown waits for requests and background work
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 	UseHTTPS bool          `mapstructure:"use_https"`
 	HTTPSCertFile string    `mapstructure:"https_cert_file"`
 	HTTPSKeyFile  string    `mapstructure:"https_key_file"`
@@ -46,6 +61,7 @@ type DatabaseConfig struct {
 	MinIdleConns    int           `mapstructure:"min_idle_conns"`
 	RetryAttempts   int           `mapstructure:"retry_attempts"`
 	RetryDelay      time.Duration `mapstructure:"retry_delay"`
+	MigrationMode   string        `mapstructure:"migration_mode"` // "auto" (default) or "strict"
 }
 
 type RedisConfig struct {
@@ -61,6 +77,27 @@ type AuthConfig struct {
 	JWTIssuer       string                    `mapstructure:"jwt_issuer"`
 	OAuth2          OAuth2Config              `mapstructure:"oauth2"`
 	OAuth2Providers map[string]ProviderConfig `mapstructure:"oauth2_providers"`
+
+	// AccessTokenMinutes is the lifetime of access tokens issued alongside refresh tokens
+	AccessTokenMinutes int `mapstructure:"access_token_minutes"`
+	// RefreshTokenDays is the lifetime of a refresh token; each refresh issues a new one
+	RefreshTokenDays int `mapstructure:"refresh_token_days"`
+}
+
+// AccessTokenTTL returns the access token lifetime, defaulting to 15 minutes
+func (a AuthConfig) AccessTokenTTL() time.Duration {
+	if a.AccessTokenMinutes <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(a.AccessTokenMinutes) * time.Minute
+}
+
+// RefreshTokenTTL returns the refresh token lifetime, defaulting to 30 days
+func (a AuthConfig) RefreshTokenTTL() time.Duration {
+	if a.RefreshTokenDays <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(a.RefreshTokenDays) * 24 * time.Hour
 }
 
 type OAuth2Config struct {
@@ -89,6 +126,8 @@ type CORSConfig struct {
 type LoggingConfig struct {
 	Level  string `mapstructure:"level"`
 	Format string `mapstructure:"format"`
+	// Modules overrides the level of single modules, e.g. workflow: debug
+	Modules map[string]string `mapstructure:"modules"`
 }
 
 type SwaggerConfig struct {
@@ -100,6 +139,191 @@ type SwaggerConfig struct {
 	BasePath    string `mapstructure:"base_path"`
 }
 
+// InboundConfig configures email-in. Email-in is disabled when Domain is empty.
+type InboundConfig struct {
+	Domain string `mapstructure:"domain"`
+	// Secret authenticates the mail provider posting raw emails to the ingestion endpoint
+	Secret string `mapstructure:"secret"`
+}
+
+// SchedulerConfig holds the cron schedules of background jobs. Empty fields use the scheduler defaults.
+type SchedulerConfig struct {
+	HabitReset     string `mapstructure:"habit_reset"`
+	HabitReminders string `mapstructure:"habit_reminders"`
+	TodoRecurrence string `mapstructure:"todo_recurrence"`
+	TaskRisk       string `mapstructure:"task_risk"`
+	TaskPriority   string `mapstructure:"task_priority"`
+	TrashPurge     string `mapstructure:"trash_purge"`
+	Retention      string `mapstructure:"retention"`
+	Projections    string `mapstructure:"projections"`
+	Timezone       string `mapstructure:"timezone"`
+	// TrashRetentionDays is how long deleted tasks and todos can be restored before they are purged
+	TrashRetentionDays int `mapstructure:"trash_retention_days"`
+}
+
+// ChatConfig configures the Slack and Teams apps. A provider is disabled until its client is configured.
+type ChatConfig struct {
+	// AppURL is the web app base used for links posted to chat
+	AppURL string             `mapstructure:"app_url"`
+	Slack  ChatProviderConfig `mapstructure:"slack"`
+	Teams  ChatProviderConfig `mapstructure:"teams"`
+}
+
+type ChatProviderConfig struct {
+	ClientID      string `mapstructure:"client_id"`
+	ClientSecret  string `mapstructure:"client_secret"`
+	RedirectURL   string `mapstructure:"redirect_url"`
+	SigningSecret string `mapstructure:"signing_secret"`
+}
+
+// VCSConfig configures the GitHub and GitLab integrations
+type VCSConfig struct {
+	// AllowedHosts lists the self-hosted GitLab and GitHub Enterprise hosts repository
+	// connections may use, with the port unless it is 443. Only these may resolve to
+	// internal addresses.
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+}
+
+// BillingConfig configures subscriptions. Billing is disabled until the Stripe secret key is set.
+type BillingConfig struct {
+	StripeSecretKey     string `mapstructure:"stripe_secret_key"`
+	StripeWebhookSecret string `mapstructure:"stripe_webhook_secret"`
+	// Prices maps paid plan names ("pro", "business") to Stripe price IDs
+	Prices map[string]string `mapstructure:"prices"`
+	// PortalReturnURL is where the Stripe customer portal sends users back to
+	PortalReturnURL string `mapstructure:"portal_return_url"`
+}
+
+// LegalConfig holds the current terms of service and privacy policy. Bumping a version
+// asks every user to accept the document again; a document without a version is not enforced.
+type LegalConfig struct {
+	Terms   LegalDocumentConfig `mapstructure:"terms"`
+	Privacy LegalDocumentConfig `mapstructure:"privacy"`
+}
+
+type LegalDocumentConfig struct {
+	Version string `mapstructure:"version"`
+	URL     string `mapstructure:"url"`
+	// EffectiveDate is the date the version applies from, as YYYY-MM-DD
+	EffectiveDate string `mapstructure:"effective_date"`
+}
+
+// PluginsConfig configures extensions. Shared object plugins are loaded from Dir when it is
+// set; compiled-in plugins are always installed.
+type PluginsConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// EmailConfig configures outgoing email. Provider is "smtp", "sendgrid" or "postmark";
+// emails are only logged while it is empty.
+type EmailConfig struct {
+	Provider    string `mapstructure:"provider"`
+	FromAddress string `mapstructure:"from_address"`
+	FromName    string `mapstructure:"from_name"`
+	// AppURL is the web app base used for links in emails
+	AppURL       string `mapstructure:"app_url"`
+	SMTPHost     string `mapstructure:"smtp_host"`
+	SMTPPort     int    `mapstructure:"smtp_port"`
+	SMTPUsername string `mapstructure:"smtp_username"`
+	SMTPPassword string `mapstructure:"smtp_password"`
+	// APIKey authenticates with the SendGrid and Postmark APIs
+	APIKey string `mapstructure:"api_key"`
+}
+
+// AIConfig configures the LLM providers behind AI features. Provider is the default,
+// "openai", "anthropic" or "ollama"; organizations may pick another configured one.
+// AI features are simulated while Provider is empty.
+type AIConfig struct {
+	Provider  string           `mapstructure:"provider"`
+	OpenAI    AIProviderConfig `mapstructure:"openai"`
+	Anthropic AIProviderConfig `mapstructure:"anthropic"`
+	Ollama    AIProviderConfig `mapstructure:"ollama"`
+}
+
+// AIProviderConfig configures one LLM provider. OpenAI and Anthropic are available once
+// their API key is set, Ollama once its URL is set.
+type AIProviderConfig struct {
+	APIKey         string `mapstructure:"api_key"`
+	BaseURL        string `mapstructure:"base_url"`
+	Model          string `mapstructure:"model"`
+	EmbeddingModel string `mapstructure:"embedding_model"`
+}
+
+// RateLimitConfig configures the API rate limits. Every request draws from its caller's
+// bucket, whose tier is the most generous one among the caller's roles, or Default.
+// Requests under a path in Routes also draw from a bucket of their own per caller, and
+// requests in an organization listed in Organizations from one the whole organization
+// shares. Organizations, roles and route prefixes are matched in lower case.
+type RateLimitConfig struct {
+	Default       RateLimitTier            `mapstructure:"default"`
+	Routes        map[string]RateLimitTier `mapstructure:"routes"`
+	Roles         map[string]RateLimitTier `mapstructure:"roles"`
+	Organizations map[string]RateLimitTier `mapstructure:"organizations"`
+}
+
+// RateLimitTier is a token bucket: up to Burst requests at once, refilled at
+// RequestsPerMinute. Burst defaults to RequestsPerMinute.
+type RateLimitTier struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	Burst             int `mapstructure:"burst"`
+}
+
+// SLOConfig sets the latency objectives of route groups, keyed by path prefix. A request
+// counts against the group of the longest prefix it matches. Without Routes the whole API
+// gets a default objective. AlertUsers, by ID, are notified through AlertMethods when a
+// group burns its error budget too fast.
+type SLOConfig struct {
+	Routes       map[string]LatencySLO `mapstructure:"routes"`
+	AlertUsers   []string              `mapstructure:"alert_users"`
+	AlertMethods []string              `mapstructure:"alert_methods"`
+}
+
+// LatencySLO asks that Objective, a share like 0.99, of requests be answered within
+// Threshold
+type LatencySLO struct {
+	Threshold time.Duration `mapstructure:"threshold"`
+	Objective float64       `mapstructure:"objective"`
+}
+
+// StorageConfig configures where uploaded files are kept. Driver is "local" (the default),
+// which writes under LocalDir, or "s3" for any S3-compatible object store.
+type StorageConfig struct {
+	Driver   string          `mapstructure:"driver"`
+	LocalDir string          `mapstructure:"local_dir"`
+	S3       S3StorageConfig `mapstructure:"s3"`
+	// MaxUploadMB caps the size of a single upload, 25 MB when unset
+	MaxUploadMB int `mapstructure:"max_upload_mb"`
+	// AllowedTypes lists the accepted MIME types; "image/*" allows a whole family.
+	// A built-in list of documents, images and archives applies when empty.
+	AllowedTypes []string `mapstructure:"allowed_types"`
+	// Scan configures malware scanning of uploads
+	Scan ScanConfig `mapstructure:"scan"`
+	// PDFToPPMPath is the pdftoppm binary rendering the first page of PDFs for their
+	// previews. PDFs get no preview when it is empty; images always do.
+	PDFToPPMPath string `mapstructure:"pdftoppm_path"`
+}
+
+// ScanConfig selects the malware scanner uploads are quarantined for. Driver is "clamav",
+// which streams files to the clamd daemon at ClamAVAddress, or "http", which posts them to
+// an external API at URL. Uploads are not scanned when it is empty.
+type ScanConfig struct {
+	Driver        string `mapstructure:"driver"`
+	ClamAVAddress string `mapstructure:"clamav_address"`
+	URL           string `mapstructure:"url"`
+	APIKey        string `mapstructure:"api_key"`
+}
+
+// S3StorageConfig configures an S3-compatible bucket. UsePathStyle addresses the bucket
+// in the path rather than the host name, as MinIO and most self-hosted stores expect.
+type S3StorageConfig struct {
+	Endpoint     string `mapstructure:"endpoint"`
+	Region       string `mapstructure:"region"`
+	Bucket       string `mapstructure:"bucket"`
+	AccessKey    string `mapstructure:"access_key"`
+	SecretKey    string `mapstructure:"secret_key"`
+	UsePathStyle bool   `mapstructure:"use_path_style"`
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -158,8 +382,11 @@ func LoadConfig(configPath string) (*Config, error) {
 		"database.password":                      "DB_PASSWORD",
 		"database.name":                          "DB_NAME",
 		"database.sslmode":                       "DB_SSLMODE",
+		"database.migration_mode":                "DB_MIGRATION_MODE",
+		"server.port":                            "SERVER_PORT",
 		"server.mode":                            "SERVER_MODE",
 		"server.timeout":                         "SERVER_TIMEOUT",
+		"server.shutdown_timeout":                "SERVER_SHUTDOWN_TIMEOUT",
 		"redis.host":                             "REDIS_HOST",
 		"redis.port":                             "REDIS_PORT",
 		"redis.password":                         "REDIS_PASSWORD",
@@ -167,6 +394,8 @@ func LoadConfig(configPath string) (*Config, error) {
 		"auth.jwt_secret":                        "JWT_SECRET",
 		"auth.jwt_issuer":                        "JWT_ISSUER",
 		"auth.jwt_expiry_hours":                  "JWT_EXPIRY_HOURS",
+		"auth.access_token_minutes":              "ACCESS_TOKEN_MINUTES",
+		"auth.refresh_token_days":                "REFRESH_TOKEN_DAYS",
 		"auth.oauth2.enabled":                    "OAUTH2_ENABLED",
 		"auth.oauth2.callback_url":               "OAUTH2_CALLBACK_URL",
 		"auth.oauth2.state_timeout":              "OAUTH2_STATE_TIMEOUT",
@@ -178,26 +407,102 @@ func LoadConfig(configPath string) (*Config, error) {
 		"auth.oauth2_providers.github.redirect_url":  "OAUTH2_GITHUB_REDIRECT_URL",
 		"logging.level":  "LOG_LEVEL",
 		"logging.format": "LOG_FORMAT",
+		"logging.modules": "LOG_MODULE_LEVELS",
+		"inbound.domain": "INBOUND_EMAIL_DOMAIN",
+		"inbound.secret": "INBOUND_EMAIL_SECRET",
+		"scheduler.habit_reset":     "SCHEDULER_HABIT_RESET",
+		"scheduler.habit_reminders": "SCHEDULER_HABIT_REMINDERS",
+		"scheduler.todo_recurrence": "SCHEDULER_TODO_RECURRENCE",
+		"scheduler.task_risk":       "SCHEDULER_TASK_RISK",
+		"scheduler.task_priority":   "SCHEDULER_TASK_PRIORITY",
+		"scheduler.trash_purge":     "SCHEDULER_TRASH_PURGE",
+		"scheduler.projections":     "SCHEDULER_PROJECTIONS",
+		"scheduler.trash_retention_days": "SCHEDULER_TRASH_RETENTION_DAYS",
+		"scheduler.timezone":        "SCHEDULER_TIMEZONE",
+		"chat.app_url":              "CHAT_APP_URL",
+		"chat.slack.client_id":      "SLACK_CLIENT_ID",
+		"chat.slack.client_secret":  "SLACK_CLIENT_SECRET",
+		"chat.slack.redirect_url":   "SLACK_REDIRECT_URL",
+		"chat.slack.signing_secret": "SLACK_SIGNING_SECRET",
+		"chat.teams.client_id":      "TEAMS_CLIENT_ID",
+		"chat.teams.client_secret":  "TEAMS_CLIENT_SECRET",
+		"chat.teams.redirect_url":   "TEAMS_REDIRECT_URL",
+		"vcs.allowed_hosts":         "VCS_ALLOWED_HOSTS",
+		"billing.stripe_secret_key":     "STRIPE_SECRET_KEY",
+		"billing.stripe_webhook_secret": "STRIPE_WEBHOOK_SECRET",
+		"billing.prices.pro":            "STRIPE_PRICE_PRO",
+		"billing.prices.business":       "STRIPE_PRICE_BUSINESS",
+		"billing.portal_return_url":     "BILLING_PORTAL_RETURN_URL",
+		"legal.terms.version":           "LEGAL_TERMS_VERSION",
+		"legal.terms.url":               "LEGAL_TERMS_URL",
+		"legal.privacy.version":         "LEGAL_PRIVACY_VERSION",
+		"legal.privacy.url":             "LEGAL_PRIVACY_URL",
+		"plugins.dir":                   "PLUGINS_DIR",
+		"email.provider":                "EMAIL_PROVIDER",
+		"email.from_address":            "EMAIL_FROM_ADDRESS",
+		"email.from_name":               "EMAIL_FROM_NAME",
+		"email.app_url":                 "EMAIL_APP_URL",
+		"email.smtp_host":               "SMTP_HOST",
+		"email.smtp_port":               "SMTP_PORT",
+		"email.smtp_username":           "SMTP_USERNAME",
+		"email.smtp_password":           "SMTP_PASSWORD",
+		"email.api_key":                 "EMAIL_API_KEY",
+		"ai.provider":                   "AI_PROVIDER",
+		"ai.openai.api_key":             "OPENAI_API_KEY",
+		"ai.openai.base_url":            "OPENAI_BASE_URL",
+		"ai.openai.model":               "OPENAI_MODEL",
+		"ai.openai.embedding_model":     "OPENAI_EMBEDDING_MODEL",
+		"ai.anthropic.api_key":          "ANTHROPIC_API_KEY",
+		"ai.anthropic.model":            "ANTHROPIC_MODEL",
+		"ai.ollama.base_url":            "OLLAMA_URL",
+		"ai.ollama.model":               "OLLAMA_MODEL",
+		"ai.ollama.embedding_model":     "OLLAMA_EMBEDDING_MODEL",
+		"rate_limit.default.requests_per_minute": "RATE_LIMIT_REQUESTS_PER_MINUTE",
+		"rate_limit.default.burst":               "RATE_LIMIT_BURST",
+		"storage.driver":                         "STORAGE_DRIVER",
+		"storage.local_dir":                      "STORAGE_LOCAL_DIR",
+		"storage.max_upload_mb":                  "STORAGE_MAX_UPLOAD_MB",
+		"storage.s3.endpoint":                    "S3_ENDPOINT",
+		"storage.s3.region":                      "S3_REGION",
+		"storage.s3.bucket":                      "S3_BUCKET",
+		"storage.s3.access_key":                  "S3_ACCESS_KEY",
+		"storage.s3.secret_key":                  "S3_SECRET_KEY",
+		"storage.s3.use_path_style":              "S3_USE_PATH_STYLE",
+		"storage.scan.driver":                    "SCAN_DRIVER",
+		"storage.scan.clamav_address":            "CLAMAV_ADDRESS",
+		"storage.scan.url":                       "SCAN_API_URL",
+		"storage.scan.api_key":                   "SCAN_API_KEY",
+		"storage.pdftoppm_path":                  "PDFTOPPM_PATH",
+		"slo.alert_users":                        "SLO_ALERT_USERS",
+		"slo.alert_methods":                      "SLO_ALERT_METHODS",
 	}
 
 	for configKey, envVar := range envVars {
 		if value := os.Getenv(envVar); value != "" {
 			// Handle special cases for type conversion
 			switch envVar {
-			case "DB_PORT", "REDIS_PORT", "JWT_EXPIRY_HOURS", "OAUTH2_STATE_TIMEOUT":
+			case "SERVER_PORT", "DB_PORT", "REDIS_PORT", "JWT_EXPIRY_HOURS", "OAUTH2_STATE_TIMEOUT",
+				"ACCESS_TOKEN_MINUTES", "REFRESH_TOKEN_DAYS", "SMTP_PORT",
+				"RATE_LIMIT_REQUESTS_PER_MINUTE", "RATE_LIMIT_BURST", "STORAGE_MAX_UPLOAD_MB":
 				if intVal, err := strconv.Atoi(value); err == nil {
 					v.Set(configKey, intVal)
 				}
-			case "SERVER_TIMEOUT":
+			case "SERVER_TIMEOUT", "SERVER_SHUTDOWN_TIMEOUT":
 				if d, err := time.ParseDuration(value); err == nil {
 					v.Set(configKey, d)
 				}
-			case "OAUTH2_ENABLED":
+			case "OAUTH2_ENABLED", "S3_USE_PATH_STYLE":
 				if value == "true" || value == "1" {
 					v.Set(configKey, true)
 				} else if value == "false" || value == "0" {
 					v.Set(configKey, false)
 				}
+			case "LOG_MODULE_LEVELS":
+				modules, err := logger.ParseModuleLevels(value)
+				if err != nil {
+					return nil, err
+				}
+				v.Set(configKey, modules)
 			default:
 				v.Set(configKey, value)
 			}
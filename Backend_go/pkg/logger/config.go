@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Formats a logger can write
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Config selects the format and levels of every logger
+type Config struct {
+	// Level is the default level: debug, info, warn or error. Empty means info.
+	Level string
+	// Format is json or console. Empty means json.
+	Format string
+	// Modules overrides the level of single modules, like "workflow" or "habits"
+	Modules map[string]string
+}
+
+// ParseModuleLevels parses module level overrides written as "workflow=debug,habits=warn"
+func ParseModuleLevels(value string) (map[string]string, error) {
+	modules := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		module, level, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(module) == "" {
+			return nil, fmt.Errorf("invalid module log level %q, want module=level", pair)
+		}
+		modules[strings.TrimSpace(module)] = strings.TrimSpace(level)
+	}
+	return modules, nil
+}
+
+var (
+	// root is the core every logger writes to
+	root atomic.Pointer[zapcore.Core]
+
+	levelsMu sync.Mutex
+	config   = Config{}
+	// levels holds the level of every module a logger was created for; "" is the default
+	levels = map[string]zap.AtomicLevel{}
+)
+
+func init() {
+	core := newCore(FormatJSON)
+	root.Store(&core)
+}
+
+// Setup applies the configuration to every logger, including those created before. It
+// also sends what the standard library's log package writes to the "stdlib" module.
+func Setup(cfg Config) error {
+	if cfg.Format == "" {
+		cfg.Format = FormatJSON
+	}
+	if cfg.Format != FormatJSON && cfg.Format != FormatConsole {
+		return fmt.Errorf("invalid log format %q, want json or console", cfg.Format)
+	}
+	if _, err := parseLevel(cfg.Level); err != nil {
+		return err
+	}
+	for module, level := range cfg.Modules {
+		if _, err := parseLevel(level); err != nil {
+			return fmt.Errorf("module %s: %w", module, err)
+		}
+	}
+
+	core := newCore(cfg.Format)
+	root.Store(&core)
+
+	levelsMu.Lock()
+	config = cfg
+	for module, level := range levels {
+		level.SetLevel(configuredLevel(module))
+	}
+	levelsMu.Unlock()
+
+	zap.RedirectStdLog(Named("stdlib").Logger)
+	return nil
+}
+
+// levelFor returns the level shared by the loggers of a module
+func levelFor(module string) zap.AtomicLevel {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	level, ok := levels[module]
+	if !ok {
+		level = zap.NewAtomicLevelAt(configuredLevel(module))
+		levels[module] = level
+	}
+	return level
+}
+
+// configuredLevel returns the level of a module under the current configuration. The
+// configuration was validated by Setup, so parsing cannot fail.
+func configuredLevel(module string) zapcore.Level {
+	if level, ok := config.Modules[module]; ok && module != "" {
+		l, _ := parseLevel(level)
+		return l
+	}
+	l, _ := parseLevel(config.Level)
+	return l
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	if level == "" {
+		return zapcore.InfoLevel, nil
+	}
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(strings.ToLower(level))); err != nil {
+		return l, fmt.Errorf("invalid log level %q, want debug, info, warn or error", level)
+	}
+	return l, nil
+}
+
+// newCore builds the core loggers write to. It lets every entry through: the level of
+// the module is checked before an entry gets here.
+func newCore(format string) zapcore.Core {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.StacktraceKey = "" // Disable stacktrace by default
+
+	var encoder zapcore.Encoder
+	if format == FormatConsole {
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+	return zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), zapcore.DebugLevel)
+}
+
+// switchCore checks entries against the level of a module and writes them to the current
+// root core, so Setup reaches loggers created before it ran
+type switchCore struct {
+	level  zap.AtomicLevel
+	fields []zapcore.Field
+}
+
+func (c *switchCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *switchCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(append(combined, c.fields...), fields...)
+	return &switchCore{level: c.level, fields: combined}
+}
+
+func (c *switchCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *switchCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if len(c.fields) > 0 {
+		fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	}
+	return (*root.Load()).Write(entry, fields)
+}
+
+func (c *switchCore) Sync() error {
+	return (*root.Load()).Sync()
+}
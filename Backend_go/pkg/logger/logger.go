@@ -1,8 +1,10 @@
+// Package logger is the application's single logger. Every logger writes through one
+// zap core, configured by Setup, and adapters route logrus and the standard library's
+// log package through it too, so all output shares a format and level configuration.
 package logger
 
 import (
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 // Logger wraps zap logger
@@ -10,38 +12,19 @@ type Logger struct {
 	*zap.Logger
 }
 
-// NewLogger creates a new logger instance
+// NewLogger creates a new logger instance at the default level
 func NewLogger() *Logger {
-	config := zap.NewProductionConfig()
-	config.EncoderConfig.TimeKey = "timestamp"
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.EncoderConfig.StacktraceKey = "" // Disable stacktrace by default
-
-	logger, err := config.Build(
-		zap.AddCallerSkip(1),
-	)
-	if err != nil {
-		panic(err)
-	}
-
-	return &Logger{
-		Logger: logger,
-	}
+	return Named("")
 }
 
-// Info logs a message at info level
-func (l *Logger) Info(msg string, fields ...zap.Field) {
-	l.Logger.Info(msg, fields...)
-}
-
-// Error logs a message at error level
-func (l *Logger) Error(msg string, fields ...zap.Field) {
-	l.Logger.Error(msg, fields...)
-}
-
-// Fatal logs a message at fatal level and then calls os.Exit(1)
-func (l *Logger) Fatal(msg string, fields ...zap.Field) {
-	l.Logger.Fatal(msg, fields...)
+// Named creates a logger for a module. Its lines carry the module in the "logger" field
+// and it logs at the level configured for the module, or the default level.
+func Named(module string) *Logger {
+	l := zap.New(&switchCore{level: levelFor(module)}, zap.AddCaller())
+	if module != "" {
+		l = l.Named(module)
+	}
+	return &Logger{Logger: l}
 }
 
 // With creates a child logger and adds structured context to it
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logrus returns a logrus logger that writes through the logger of a module, for the
+// packages that still take one. Its entries are checked against the module's level.
+func Logrus(module string) *logrus.Logger {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.SetFormatter(discardFormatter{})
+	l.SetLevel(logrus.TraceLevel)
+	// The caller of a hook is inside logrus, so it is left out
+	l.AddHook(&logrusHook{logger: Named(module).WithOptions(zap.WithCaller(false))})
+	return l
+}
+
+// logrusHook forwards logrus entries to zap
+type logrusHook struct {
+	logger *zap.Logger
+}
+
+func (h *logrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *logrusHook) Fire(entry *logrus.Entry) error {
+	level := zapLevel(entry.Level)
+	checked := h.logger.Check(level, entry.Message)
+	if checked == nil {
+		return nil
+	}
+	fields := make([]zap.Field, 0, len(entry.Data))
+	for key, value := range entry.Data {
+		if err, ok := value.(error); ok && key == logrus.ErrorKey {
+			fields = append(fields, zap.Error(err))
+			continue
+		}
+		fields = append(fields, zap.Any(key, value))
+	}
+	// Fatal and panic entries are only written: logrus exits or panics itself
+	checked.Should(checked.Entry, zapcore.WriteThenNoop).Write(fields...)
+	return nil
+}
+
+func zapLevel(level logrus.Level) zapcore.Level {
+	switch level {
+	case logrus.PanicLevel:
+		return zapcore.PanicLevel
+	case logrus.FatalLevel:
+		return zapcore.FatalLevel
+	case logrus.ErrorLevel:
+		return zapcore.ErrorLevel
+	case logrus.WarnLevel:
+		return zapcore.WarnLevel
+	case logrus.InfoLevel:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+// discardFormatter skips formatting entries logrus would only write to io.Discard
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
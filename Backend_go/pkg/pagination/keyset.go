@@ -0,0 +1,132 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultLimit is the page size of cursor requests that do not ask for one
+	DefaultLimit = 20
+	// MaxLimit caps the page size of cursor requests
+	MaxLimit = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("limit must be between 1 and 100")
+)
+
+// Cursor is a position in a list ordered by a timestamp and then by ID
+type Cursor struct {
+	Time time.Time
+	ID   uuid.UUID
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c Cursor) Encode() string {
+	raw := c.Time.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor reads a cursor produced by Encode
+func ParseCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{Time: t, ID: id}, nil
+}
+
+// before reports whether c comes before other in (time, id) order. IDs compare byte by
+// byte, as Postgres compares UUIDs.
+func (c Cursor) before(other Cursor) bool {
+	if !c.Time.Equal(other.Time) {
+		return c.Time.Before(other.Time)
+	}
+	return bytes.Compare(c.ID[:], other.ID[:]) < 0
+}
+
+// Keyset asks for the page of up to Limit items that follows After, or the first page
+// when After is nil. Unlike offset pages it stays as fast deep into a list and does not
+// skip or repeat items when earlier ones are added or removed.
+type Keyset struct {
+	After *Cursor
+	Limit int
+}
+
+// NewKeyset reads the cursor and limit of a request. An empty cursor starts at the first
+// item and a zero limit uses DefaultLimit.
+func NewKeyset(cursor string, limit int) (*Keyset, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 1 || limit > MaxLimit {
+		return nil, ErrInvalidLimit
+	}
+	keyset := &Keyset{Limit: limit}
+	if cursor != "" {
+		after, err := ParseCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		keyset.After = after
+	}
+	return keyset, nil
+}
+
+// Scope orders a query by timeColumn and idColumn and restricts it to the page. It reads
+// one item more than the limit, which Trim uses to tell whether another page follows.
+func (k *Keyset) Scope(timeColumn, idColumn string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if k.After != nil {
+			db = db.Where(fmt.Sprintf("(%s, %s) > (?, ?)", timeColumn, idColumn), k.After.Time, k.After.ID)
+		}
+		return db.Order(timeColumn + " ASC").Order(idColumn + " ASC").Limit(k.Limit + 1)
+	}
+}
+
+// Slice does what Scope does for items held in memory
+func Slice[T any](k *Keyset, items []T, key func(*T) Cursor) []T {
+	sorted := make([]T, 0, len(items))
+	for i := range items {
+		if k.After == nil || k.After.before(key(&items[i])) {
+			sorted = append(sorted, items[i])
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return key(&sorted[i]).before(key(&sorted[j])) })
+	if len(sorted) > k.Limit+1 {
+		sorted = sorted[:k.Limit+1]
+	}
+	return sorted
+}
+
+// Trim drops the extra item read by Scope or Slice and returns the cursor of the next
+// page, which is empty on the last page
+func Trim[T any](k *Keyset, items []T, key func(*T) Cursor) ([]T, string) {
+	if len(items) <= k.Limit {
+		return items, ""
+	}
+	items = items[:k.Limit]
+	return items, key(&items[len(items)-1]).Encode()
+}
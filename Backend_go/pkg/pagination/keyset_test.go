@@ -0,0 +1,89 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type item struct {
+	createdAt time.Time
+	id        uuid.UUID
+}
+
+func (i *item) cursor() Cursor {
+	return Cursor{Time: i.createdAt, ID: i.id}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{Time: time.Date(2024, 3, 15, 9, 30, 0, 123456789, time.UTC), ID: uuid.New()}
+	got, err := ParseCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("ParseCursor: %v", err)
+	}
+	if !got.Time.Equal(want.Time) || got.ID != want.ID {
+		t.Errorf("round trip = %+v, want %+v", *got, want)
+	}
+
+	for _, bad := range []string{"", "not base64!", Cursor{}.Encode()[:8]} {
+		if _, err := ParseCursor(bad); err != ErrInvalidCursor {
+			t.Errorf("ParseCursor(%q) error = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestNewKeysetLimits(t *testing.T) {
+	keyset, err := NewKeyset("", 0)
+	if err != nil || keyset.Limit != DefaultLimit || keyset.After != nil {
+		t.Errorf("NewKeyset(\"\", 0) = %+v, %v", keyset, err)
+	}
+	for _, limit := range []int{-1, MaxLimit + 1} {
+		if _, err := NewKeyset("", limit); err != ErrInvalidLimit {
+			t.Errorf("NewKeyset limit %d error = %v, want ErrInvalidLimit", limit, err)
+		}
+	}
+}
+
+// TestPagesVisitEveryItemOnce pages through items sharing timestamps, adding an item before
+// the current position between pages, which offset pages would repeat an item for
+func TestPagesVisitEveryItemOnce(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var items []item
+	for i := 0; i < 7; i++ {
+		items = append(items, item{createdAt: base.Add(time.Duration(i/2) * time.Hour), id: uuid.New()})
+	}
+
+	seen := map[uuid.UUID]int{}
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > len(items) {
+			t.Fatal("paging did not end")
+		}
+		keyset, err := NewKeyset(cursor, 3)
+		if err != nil {
+			t.Fatalf("NewKeyset: %v", err)
+		}
+		page, next := Trim(keyset, Slice(keyset, items, (*item).cursor), (*item).cursor)
+		if len(page) > 3 {
+			t.Fatalf("page has %d items, want at most 3", len(page))
+		}
+		for _, it := range page {
+			seen[it.id]++
+		}
+		if next == "" {
+			break
+		}
+		items = append(items, item{createdAt: base.Add(-time.Hour), id: uuid.New()})
+		cursor = next
+	}
+
+	for _, it := range items[:7] {
+		if seen[it.id] != 1 {
+			t.Errorf("item %s seen %d times, want once", it.id, seen[it.id])
+		}
+	}
+	if len(seen) != 7 {
+		t.Errorf("saw %d items, want the 7 that existed before paging started", len(seen))
+	}
+}
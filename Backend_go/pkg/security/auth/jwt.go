@@ -18,6 +18,9 @@ type Claims struct {
 	Roles       []string  `json:"roles"`
 	OrgID       uuid.UUID `json:"org_id"`
 	Permissions []string  `json:"permissions"`
+	// Scopes limit a token issued for an integration. Tokens without scopes carry the
+	// full permissions of their user.
+	Scopes []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -86,14 +89,28 @@ func NewJWTService(config *config.Config) *JWTService {
 
 // GenerateToken generates a new JWT token for a user
 func GenerateToken(userID uuid.UUID, email string, roles []string, orgID uuid.UUID, permissions []string, secret string, expiryHours int) (string, error) {
+	return GenerateTokenWithExpiry(userID, email, roles, orgID, permissions, secret, time.Duration(expiryHours)*time.Hour)
+}
+
+// GenerateTokenWithExpiry generates a new JWT token for a user that is valid for the given duration
+func GenerateTokenWithExpiry(userID uuid.UUID, email string, roles []string, orgID uuid.UUID, permissions []string, secret string, expiry time.Duration) (string, error) {
+	return GenerateScopedToken(userID, email, roles, orgID, permissions, nil, secret, expiry)
+}
+
+// GenerateScopedToken generates a new JWT token for a user that is limited to the given
+// scopes, or unlimited when there are none
+func GenerateScopedToken(userID uuid.UUID, email string, roles []string, orgID uuid.UUID, permissions, scopes []string, secret string, expiry time.Duration) (string, error) {
 	claims := Claims{
 		UserID:      userID,
 		Email:       email,
 		Roles:       roles,
 		OrgID:       orgID,
 		Permissions: permissions,
+		Scopes:      scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expiryHours) * time.Hour)),
+			// A unique ID keeps tokens issued within the same second distinct
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
@@ -145,14 +162,15 @@ func (s *JWTService) RefreshToken(tokenString string) (string, error) {
 	}
 
 	// Generate new token with same claims but new expiry
-	return GenerateToken(
+	return GenerateScopedToken(
 		claims.UserID,
 		claims.Email,
 		claims.Roles,
 		claims.OrgID,
 		claims.Permissions,
+		claims.Scopes,
 		string(s.secretKey),
-		int(s.tokenDuration.Hours()),
+		s.tokenDuration,
 	)
 }
 
@@ -0,0 +1,334 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+// RefreshToken is a persisted, single-use token that renews an access token.
+// Only a hash of the token is stored. Every rotation issues a new token in the
+// same family; presenting a rotated token again revokes the whole family.
+type RefreshToken struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_refresh_token_user"`
+	FamilyID   uuid.UUID  `json:"family_id" gorm:"type:uuid;not null;index:idx_refresh_token_family"`
+	SessionID  string     `json:"session_id" gorm:"type:varchar(64);not null"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	DeviceInfo string     `json:"device_info" gorm:"type:text"`
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(64)"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *uuid.UUID `json:"replaced_by,omitempty" gorm:"type:uuid"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null;default:current_timestamp"`
+}
+
+// TableName specifies the table name for the RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// BeforeCreate is called before creating a new refresh token record
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	t.CreatedAt = time.Now()
+	return nil
+}
+
+// RefreshTokenStore persists refresh tokens
+type RefreshTokenStore interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	// Rotate revokes current in favour of next. It fails with ErrRefreshTokenReused
+	// if current was revoked in the meantime.
+	Rotate(ctx context.Context, current, next *RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID string) error
+	RevokeUser(ctx context.Context, userID uuid.UUID) error
+	// ListActive returns the user's refresh tokens that are neither revoked nor expired,
+	// newest first
+	ListActive(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error)
+}
+
+type refreshTokenStore struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenStore creates a Postgres-backed refresh token store
+func NewRefreshTokenStore(db *gorm.DB) RefreshTokenStore {
+	return &refreshTokenStore{db: db}
+}
+
+func (s *refreshTokenStore) Create(ctx context.Context, token *RefreshToken) error {
+	return s.db.WithContext(ctx).Create(token).Error
+}
+
+func (s *refreshTokenStore) FindByHash(ctx context.Context, hash string) (*RefreshToken, error) {
+	var token RefreshToken
+	if err := s.db.WithContext(ctx).First(&token, "token_hash = ?", hash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *refreshTokenStore) Rotate(ctx context.Context, current, next *RefreshToken) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by": next.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+		return nil
+	})
+}
+
+func (s *refreshTokenStore) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	return s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (s *refreshTokenStore) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	return s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("user_id = ? AND session_id = ? AND revoked_at IS NULL", userID, sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (s *refreshTokenStore) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (s *refreshTokenStore) ListActive(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	var tokens []RefreshToken
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Identity is what an access token asserts about its user
+type Identity struct {
+	UserID      uuid.UUID
+	Email       string
+	Roles       []string
+	OrgID       uuid.UUID
+	Permissions []string
+	// Scopes limit the token; they are only set on tokens issued for integrations
+	Scopes []string
+}
+
+// TokenPair is a short-lived access token together with the refresh token that renews it
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	Session          *Session
+}
+
+// RefreshTokenService issues access and refresh tokens and rotates refresh tokens
+type RefreshTokenService struct {
+	store      RefreshTokenStore
+	secret     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewRefreshTokenService creates a new refresh token service
+func NewRefreshTokenService(store RefreshTokenStore, secret string, accessTTL, refreshTTL time.Duration) *RefreshTokenService {
+	return &RefreshTokenService{
+		store:      store,
+		secret:     secret,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// IssueTokenPair starts a new session for a user who just authenticated
+func (s *RefreshTokenService) IssueTokenPair(ctx context.Context, identity Identity, deviceInfo, ipAddress string) (*TokenPair, error) {
+	familyID := uuid.New()
+	return s.issue(ctx, identity, familyID.String(), familyID, deviceInfo, ipAddress, nil)
+}
+
+// IssueScopedToken signs an access token limited to the identity's scopes, for an
+// integration. It has no refresh token: it opens a session of its own that ends when the
+// token expires, and can be revoked like any other session.
+func (s *RefreshTokenService) IssueScopedToken(identity Identity, name, ipAddress string, ttl time.Duration) (string, *Session, error) {
+	scopes, err := NormalizeScopes(identity.Scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	accessToken, err := GenerateScopedToken(identity.UserID, identity.Email, identity.Roles, identity.OrgID,
+		identity.Permissions, scopes, s.secret, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+	session := GetSessionStore().CreateSession(identity.UserID, "Scoped token: "+name, ipAddress, accessToken, ttl)
+	return accessToken, session, nil
+}
+
+// Validate resolves a refresh token that may still be exchanged.
+// Presenting a token that was already rotated revokes every token of its session.
+func (s *RefreshTokenService) Validate(ctx context.Context, rawToken string) (*RefreshToken, error) {
+	if rawToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	token, err := s.store.FindByHash(ctx, hashRefreshToken(rawToken))
+	if err != nil {
+		return nil, err
+	}
+	if token.RevokedAt != nil {
+		if token.ReplacedBy != nil {
+			// A rotated token was replayed: assume it leaked and end the session
+			s.endSession(ctx, token)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	return token, nil
+}
+
+// Rotate exchanges a validated refresh token for a new token pair in the same session.
+// The identity is loaded fresh by the caller so role changes take effect on refresh.
+func (s *RefreshTokenService) Rotate(ctx context.Context, current *RefreshToken, identity Identity, deviceInfo, ipAddress string) (*TokenPair, error) {
+	if identity.UserID != current.UserID {
+		return nil, ErrInvalidRefreshToken
+	}
+	pair, err := s.issue(ctx, identity, current.SessionID, current.FamilyID, deviceInfo, ipAddress, current)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		s.endSession(ctx, current)
+	}
+	return pair, err
+}
+
+// Revoke ends the session a refresh token belongs to
+func (s *RefreshTokenService) Revoke(ctx context.Context, rawToken string) error {
+	token, err := s.store.FindByHash(ctx, hashRefreshToken(rawToken))
+	if err != nil {
+		return err
+	}
+	s.endSession(ctx, token)
+	return nil
+}
+
+// RevokeSession revokes the refresh tokens of one of the user's sessions
+func (s *RefreshTokenService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	return s.store.RevokeSession(ctx, userID, sessionID)
+}
+
+// ActiveTokens returns the refresh tokens the user can still exchange, one per signed-in
+// session
+func (s *RefreshTokenService) ActiveTokens(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	return s.store.ListActive(ctx, userID)
+}
+
+// RevokeUserCredentials revokes every refresh token and session of a user,
+// for example after a password change
+func (s *RefreshTokenService) RevokeUserCredentials(ctx context.Context, userID uuid.UUID) error {
+	if err := s.store.RevokeUser(ctx, userID); err != nil {
+		return err
+	}
+	sessions := GetSessionStore()
+	for _, session := range sessions.GetUserSessions(userID) {
+		sessions.InvalidateSession(session.Token)
+		GetTokenBlacklist().AddToBlacklist(session.Token, session.ExpiresAt)
+	}
+	return nil
+}
+
+// issue signs an access token, opens or renews its session and persists a new refresh token
+func (s *RefreshTokenService) issue(ctx context.Context, identity Identity, sessionID string, familyID uuid.UUID, deviceInfo, ipAddress string, replaces *RefreshToken) (*TokenPair, error) {
+	accessToken, err := GenerateScopedToken(identity.UserID, identity.Email, identity.Roles, identity.OrgID,
+		identity.Permissions, identity.Scopes, s.secret, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	rawRefresh, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &RefreshToken{
+		UserID:     identity.UserID,
+		FamilyID:   familyID,
+		SessionID:  sessionID,
+		TokenHash:  hashRefreshToken(rawRefresh),
+		DeviceInfo: deviceInfo,
+		IPAddress:  ipAddress,
+		ExpiresAt:  now.Add(s.refreshTTL),
+	}
+	if replaces == nil {
+		err = s.store.Create(ctx, record)
+	} else {
+		err = s.store.Rotate(ctx, replaces, record)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := GetSessionStore()
+	if previous := sessions.RemoveSessionByID(sessionID); previous != nil {
+		GetTokenBlacklist().AddToBlacklist(previous.Token, previous.ExpiresAt)
+	}
+	session := sessions.CreateSessionWithID(sessionID, identity.UserID, deviceInfo, ipAddress, accessToken, s.accessTTL)
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  session.ExpiresAt,
+		RefreshToken:     rawRefresh,
+		RefreshExpiresAt: record.ExpiresAt,
+		Session:          session,
+	}, nil
+}
+
+// endSession revokes a token family and invalidates its current access token
+func (s *RefreshTokenService) endSession(ctx context.Context, token *RefreshToken) {
+	_ = s.store.RevokeFamily(ctx, token.FamilyID)
+	if session := GetSessionStore().RemoveSessionByID(token.SessionID); session != nil {
+		GetTokenBlacklist().AddToBlacklist(session.Token, session.ExpiresAt)
+	}
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Scopes limit what an API key or a scoped access token may do, on top of the permissions
+// of its user. A scope is "read" or "write" for every domain, or "<domain>:<action>" for
+// one, where the domain is the first path segment after /api ("tasks" for
+// /api/tasks/123) and the resource of permissions such as "tasks:update". Write covers
+// execute, and both cover read.
+const (
+	// ScopeRead allows reading from every domain
+	ScopeRead = "read"
+	// ScopeWrite allows reading and changing every domain
+	ScopeWrite = "write"
+)
+
+// Actions a scope grants on a domain
+const (
+	ActionRead    = "read"
+	ActionExecute = "execute"
+	ActionWrite   = "write"
+)
+
+var ErrInvalidScope = errors.New("scopes must be read, write, <domain>:read, <domain>:write or workflows:execute")
+
+var domainScope = regexp.MustCompile(`^([a-z][a-z0-9-]*):(read|write|execute)$`)
+
+// executableDomains are the domains execute scopes can be granted on. Every route of
+// these domains checks the permission of its action, so an execute scope cannot be used
+// to change anything else in them.
+var executableDomains = map[string]bool{
+	"workflows": true,
+}
+
+// NormalizeScopes validates scopes and returns them lowercased and without duplicates.
+// At least one scope is required.
+func NormalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+	seen := make(map[string]bool, len(scopes))
+	valid := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope != ScopeRead && scope != ScopeWrite {
+			m := domainScope.FindStringSubmatch(scope)
+			if m == nil || (m[2] == ActionExecute && !executableDomains[m[1]]) {
+				return nil, ErrInvalidScope
+			}
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			valid = append(valid, scope)
+		}
+	}
+	return valid, nil
+}
+
+// ScopesAllow reports whether the scopes grant the action on the domain
+func ScopesAllow(scopes []string, domain, action string) bool {
+	for _, scope := range scopes {
+		switch scope {
+		case ScopeWrite, domain + ":" + ActionWrite:
+			return true
+		case domain + ":" + ActionExecute:
+			if action != ActionWrite {
+				return true
+			}
+		case ScopeRead, domain + ":" + ActionRead:
+			if action == ActionRead {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PermissionScope returns the domain and scope action a permission such as
+// "tasks:update" needs: reading needs read, executing needs execute and anything else
+// needs write
+func PermissionScope(permission string) (string, string) {
+	domain, action, _ := strings.Cut(permission, ":")
+	switch action {
+	case ActionRead, ActionExecute:
+		return domain, action
+	default:
+		return domain, ActionWrite
+	}
+}
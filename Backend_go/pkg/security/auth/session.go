@@ -42,11 +42,18 @@ func GetSessionStore() *SessionStore {
 
 // CreateSession creates a new session
 func (ss *SessionStore) CreateSession(userID uuid.UUID, deviceInfo, ipAddress string, token string, expiryDuration time.Duration) *Session {
+	return ss.CreateSessionWithID(uuid.New().String(), userID, deviceInfo, ipAddress, token, expiryDuration)
+}
+
+// CreateSessionWithID creates a session with a known ID, replacing any session
+// with the same ID. Refreshing an access token keeps its session ID.
+func (ss *SessionStore) CreateSessionWithID(id string, userID uuid.UUID, deviceInfo, ipAddress string, token string, expiryDuration time.Duration) *Session {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 
+	ss.removeByID(id)
 	session := &Session{
-		ID:           uuid.New().String(),
+		ID:           id,
 		UserID:       userID,
 		Token:        token,
 		DeviceInfo:   deviceInfo,
@@ -122,3 +129,21 @@ func (ss *SessionStore) UpdateSessionActivity(token string) {
 		session.LastActivity = time.Now()
 	}
 }
+
+// RemoveSessionByID invalidates the session with the given ID and returns it
+func (ss *SessionStore) RemoveSessionByID(id string) *Session {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.removeByID(id)
+}
+
+func (ss *SessionStore) removeByID(id string) *Session {
+	for token, session := range ss.sessions {
+		if session.ID == id {
+			session.IsValid = false
+			delete(ss.sessions, token)
+			return session
+		}
+	}
+	return nil
+}
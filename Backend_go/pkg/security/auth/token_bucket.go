@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// takeScript refills a bucket for the time since it was last used and takes a token when
+// one is left. Tokens are returned as a string because Redis truncates Lua numbers.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now, 'capacity', capacity, 'rate', tostring(rate))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// Bucket is the size of a token bucket and how fast it refills
+type Bucket struct {
+	Capacity int64
+	// PerSecond is the number of tokens added back every second
+	PerSecond float64
+}
+
+// BucketState is what is left in a token bucket
+type BucketState struct {
+	Key       string
+	Limit     int64
+	Remaining int64
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+	// RetryAfter is how long until the next token, zero while one is left
+	RetryAfter time.Duration
+}
+
+// TokenBucketLimiter rate limits with token buckets kept in Redis, which allow short
+// bursts on top of a steady rate. Keys start with the principal they limit, optionally
+// followed by "|" and a qualifier, so all of a principal's buckets can be found together.
+type TokenBucketLimiter struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewTokenBucketLimiter creates a new token bucket limiter using Redis
+func NewTokenBucketLimiter(client *redis.Client) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		client: client,
+		prefix: "ratelimit:bucket:",
+	}
+}
+
+// Take takes a token from the bucket of a key and reports whether there was one
+func (l *TokenBucketLimiter) Take(ctx context.Context, key string, bucket Bucket) (bool, BucketState, error) {
+	perMilli := bucket.PerSecond / 1000
+	result, err := takeScript.Run(ctx, l.client, []string{l.prefix + key},
+		bucket.Capacity, strconv.FormatFloat(perMilli, 'f', -1, 64), time.Now().UnixMilli()).Slice()
+	if err != nil {
+		return false, BucketState{}, fmt.Errorf("rate limiter error: %w", err)
+	}
+	if len(result) != 2 {
+		return false, BucketState{}, fmt.Errorf("rate limiter error: unexpected reply %v", result)
+	}
+	allowed, _ := result[0].(int64)
+	tokens, err := strconv.ParseFloat(fmt.Sprint(result[1]), 64)
+	if err != nil {
+		return false, BucketState{}, fmt.Errorf("rate limiter error: %w", err)
+	}
+	return allowed == 1, bucketState(key, float64(bucket.Capacity), perMilli, tokens), nil
+}
+
+// Inspect returns the buckets of a principal as they are now
+func (l *TokenBucketLimiter) Inspect(ctx context.Context, principal string) ([]BucketState, error) {
+	keys, err := l.keys(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	states := make([]BucketState, 0, len(keys))
+	for _, key := range keys {
+		fields, err := l.client.HGetAll(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			// Expired since the scan
+			continue
+		}
+		capacity, _ := strconv.ParseFloat(fields["capacity"], 64)
+		perMilli, _ := strconv.ParseFloat(fields["rate"], 64)
+		tokens, _ := strconv.ParseFloat(fields["tokens"], 64)
+		ts, _ := strconv.ParseInt(fields["ts"], 10, 64)
+		if elapsed := now - ts; elapsed > 0 {
+			tokens = math.Min(capacity, tokens+float64(elapsed)*perMilli)
+		}
+		states = append(states, bucketState(strings.TrimPrefix(key, l.prefix), capacity, perMilli, tokens))
+	}
+	return states, nil
+}
+
+// Reset refills all buckets of a principal and returns how many there were
+func (l *TokenBucketLimiter) Reset(ctx context.Context, principal string) (int64, error) {
+	keys, err := l.keys(ctx, principal)
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+	return l.client.Del(ctx, keys...).Result()
+}
+
+// keys lists the Redis keys of a principal's buckets
+func (l *TokenBucketLimiter) keys(ctx context.Context, principal string) ([]string, error) {
+	keys := []string{}
+	exists, err := l.client.Exists(ctx, l.prefix+principal).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 1 {
+		keys = append(keys, l.prefix+principal)
+	}
+
+	iter := l.client.Scan(ctx, 0, l.prefix+escapePattern(principal)+"|*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// escapePattern escapes the glob characters of a SCAN pattern
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func bucketState(key string, capacity, perMilli, tokens float64) BucketState {
+	state := BucketState{
+		Key:       key,
+		Limit:     int64(capacity),
+		Remaining: int64(math.Floor(tokens)),
+	}
+	if perMilli > 0 {
+		state.ResetAfter = time.Duration(math.Ceil((capacity-tokens)/perMilli)) * time.Millisecond
+		if tokens < 1 {
+			state.RetryAfter = time.Duration(math.Ceil((1-tokens)/perMilli)) * time.Millisecond
+		}
+	}
+	return state
+}
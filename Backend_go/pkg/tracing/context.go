@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+)
+
+// MaxIDLength bounds a request or trace ID accepted from a client
+const MaxIDLength = 128
+
+// Context carries the identifiers needed to follow a request across goroutines
+type Context struct {
+	RequestID    string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+}
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying the given trace context
+func WithContext(ctx context.Context, tc *Context) context.Context {
+	if tc == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, tc)
+}
+
+// FromContext returns the trace context stored in ctx, or nil if there is none
+func FromContext(ctx context.Context) *Context {
+	if ctx == nil {
+		return nil
+	}
+	tc, _ := ctx.Value(contextKey{}).(*Context)
+	return tc
+}
+
+// Start returns a copy of ctx carrying a new trace with a fresh request ID, for work that
+// no request started, like a scheduled job
+func Start(ctx context.Context) context.Context {
+	return WithContext(ctx, &Context{RequestID: NewID(), TraceID: NewID(), SpanID: NewID()})
+}
+
+// ChildSpan returns a new span in the same trace whose parent is tc
+func (tc *Context) ChildSpan() *Context {
+	return &Context{
+		RequestID:    tc.RequestID,
+		TraceID:      tc.TraceID,
+		SpanID:       NewID(),
+		ParentSpanID: tc.SpanID,
+	}
+}
+
+// Detach returns a background context for async work started from ctx.
+// The new context is not cancelled with the request but keeps its request
+// and trace IDs, with a fresh child span for the async continuation.
+func Detach(ctx context.Context) context.Context {
+	detached := context.Background()
+	if tc := FromContext(ctx); tc != nil {
+		detached = WithContext(detached, tc.ChildSpan())
+	}
+	return detached
+}
+
+// Fields returns the trace identifiers in ctx as structured log fields
+func Fields(ctx context.Context) map[string]interface{} {
+	tc := FromContext(ctx)
+	if tc == nil {
+		return map[string]interface{}{}
+	}
+	fields := map[string]interface{}{
+		"request_id": tc.RequestID,
+		"trace_id":   tc.TraceID,
+		"span_id":    tc.SpanID,
+	}
+	if tc.ParentSpanID != "" {
+		fields["parent_span_id"] = tc.ParentSpanID
+	}
+	return fields
+}
+
+// ZapFields returns the trace identifiers in ctx as zap fields
+func ZapFields(ctx context.Context) []zap.Field {
+	tc := FromContext(ctx)
+	if tc == nil {
+		return nil
+	}
+	fields := []zap.Field{
+		zap.String("request_id", tc.RequestID),
+		zap.String("trace_id", tc.TraceID),
+		zap.String("span_id", tc.SpanID),
+	}
+	if tc.ParentSpanID != "" {
+		fields = append(fields, zap.String("parent_span_id", tc.ParentSpanID))
+	}
+	return fields
+}
+
+// Logger returns logger tagged with the trace identifiers carried by ctx, so the lines a
+// service logs for a request, or a job, can be found by its request ID
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := ZapFields(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
+
+// ValidID reports whether an ID sent by a client can be used as a request or trace ID: it
+// is not empty, at most MaxIDLength long and only holds letters, digits and "-_.:", so it
+// is safe to log and to echo in a header
+func ValidID(id string) bool {
+	if id == "" || len(id) > MaxIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewID generates a random trace/span identifier
+func NewID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
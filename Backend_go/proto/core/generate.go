@@ -0,0 +1,5 @@
+// Package core holds the protobuf definitions of the core services exposed over gRPC by
+// cmd/grpc. The Go code is generated and not checked in.
+package core
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative tasks.proto todos.proto habits.proto calendar.proto
@@ -0,0 +1,402 @@
+//go:build contract
+
+package contract
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixture is a file of requests replayed in order
+type fixture struct {
+	// Setup runs the cases of testdata/setup.json first; defaults to true
+	Setup *bool      `json:"setup,omitempty"`
+	Cases []testCase `json:"cases"`
+}
+
+// testCase is one recorded request and the response it must get. Strings in the path,
+// headers and body may refer to captured values as {{name}}.
+type testCase struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Auth    bool              `json:"auth,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+	Status  int               `json:"status"`
+	// Capture stores values of the response for later cases, keyed by name, as dotted
+	// paths such as "data.id" or "data.0.id"
+	Capture map[string]string `json:"capture,omitempty"`
+}
+
+// route is a method and path pattern as registered with the router
+type route struct {
+	Method string
+	Path   string
+}
+
+func (r route) String() string {
+	return r.Method + " " + r.Path
+}
+
+var (
+	placeholder = regexp.MustCompile(`\{\{(\w+)\}\}`)
+	client      = &http.Client{Timeout: 30 * time.Second}
+	// exercised holds the method and resolved path of every request sent
+	exercised []route
+)
+
+func TestContracts(t *testing.T) {
+	setup := loadFixture(t, filepath.Join("testdata", "setup.json"))
+
+	files, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		f := loadFixture(t, file)
+		t.Run(name, func(t *testing.T) {
+			vars := map[string]string{
+				"run": strconv.FormatInt(time.Now().UnixNano(), 36),
+			}
+			if f.Setup == nil || *f.Setup {
+				for _, c := range setup.Cases {
+					if _, ok := replay(t, c, vars); !ok {
+						t.Fatalf("setup case %q failed", c.Name)
+					}
+				}
+			}
+			for _, c := range f.Cases {
+				if !t.Run(c.Name, func(t *testing.T) { check(t, name, c, vars) }) {
+					// Later cases usually depend on the values this one should have captured
+					t.FailNow()
+				}
+			}
+		})
+	}
+
+	t.Run("coverage", checkCoverage)
+}
+
+func loadFixture(t *testing.T, path string) fixture {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatalf("invalid fixture %s: %v", path, err)
+	}
+	return f
+}
+
+// check replays a case and compares its response with the golden schema
+func check(t *testing.T, fixtureName string, c testCase, vars map[string]string) {
+	body, ok := replay(t, c, vars)
+	if !ok || body == nil {
+		return
+	}
+
+	actual := schemaOf(body)
+	golden := filepath.Join("testdata", "golden", fixtureName, slug(c.Name)+".json")
+	data, err := os.ReadFile(golden)
+	if os.IsNotExist(err) || *update {
+		writeGolden(t, golden, actual)
+		if !*update {
+			t.Logf("recorded new golden %s", golden)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected interface{}
+	if err := json.Unmarshal(data, &expected); err != nil {
+		t.Fatalf("invalid golden %s: %v", golden, err)
+	}
+	breaking, added := schemaDiff(expected, actual, "")
+	for _, b := range breaking {
+		t.Errorf("breaking change: %s", b)
+	}
+	if len(added) > 0 {
+		t.Logf("new fields, run with -update to record them: %s", strings.Join(added, ", "))
+	}
+}
+
+// replay sends a case and checks its status, returning the decoded body and whether the
+// status matched. Captured values are added to vars.
+func replay(t *testing.T, c testCase, vars map[string]string) (interface{}, bool) {
+	t.Helper()
+
+	path := substitute(c.Path, vars)
+	var reqBody io.Reader
+	if c.Body != nil {
+		raw, err := json.Marshal(substituteValue(c.Body, vars))
+		if err != nil {
+			t.Fatal(err)
+		}
+		reqBody = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequest(c.Method, baseURL+path, reqBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Auth {
+		req.Header.Set("Authorization", "Bearer "+vars["token"])
+	}
+	for k, v := range c.Headers {
+		req.Header.Set(k, substitute(v, vars))
+	}
+
+	exercised = append(exercised, route{Method: c.Method, Path: strings.SplitN(path, "?", 2)[0]})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", c.Method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != c.Status {
+		t.Errorf("%s %s: status %d, want %d: %s", c.Method, path, resp.StatusCode, c.Status, truncate(raw))
+		return nil, false
+	}
+	if len(bytes.TrimSpace(raw)) == 0 || !strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return nil, true
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Errorf("%s %s: invalid JSON response: %v", c.Method, path, err)
+		return nil, false
+	}
+	for name, p := range c.Capture {
+		v, ok := lookup(body, p)
+		if !ok {
+			t.Errorf("%s %s: nothing to capture at %s", c.Method, path, p)
+			return nil, false
+		}
+		vars[name] = fmt.Sprint(v)
+	}
+	return body, true
+}
+
+func substitute(s string, vars map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := vars[m[2:len(m)-2]]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+func substituteValue(v interface{}, vars map[string]string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return substitute(v, vars)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = substituteValue(item, vars)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = substituteValue(item, vars)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// lookup follows a dotted path through a decoded JSON value
+func lookup(v interface{}, path string) (interface{}, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			next, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, v != nil
+}
+
+func writeGolden(t *testing.T, path string, schema interface{}) {
+	t.Helper()
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkCoverage fails for registered routes that no fixture exercised, unless they are
+// listed as uncovered, and for listed routes that are now covered or gone
+func checkCoverage(t *testing.T) {
+	routesMu.Lock()
+	routes := append([]route(nil), registered...)
+	routesMu.Unlock()
+	if len(routes) == 0 {
+		t.Skip("registered routes are only known when the tests start the server")
+	}
+
+	uncovered := loadUncovered(t)
+	covered := make(map[route]bool)
+	for _, e := range exercised {
+		if r, ok := match(routes, e); ok {
+			covered[r] = true
+		}
+	}
+
+	var missing, stale []string
+	known := make(map[route]bool, len(routes))
+	for _, r := range routes {
+		known[r] = true
+		if !covered[r] && !uncovered[r] {
+			missing = append(missing, r.String())
+		}
+		if covered[r] && uncovered[r] {
+			stale = append(stale, r.String())
+		}
+	}
+	for r := range uncovered {
+		if !known[r] {
+			stale = append(stale, r.String())
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+
+	if len(missing) > 0 {
+		t.Errorf("routes without a fixture:\n\t%s", strings.Join(missing, "\n\t"))
+	}
+	if len(stale) > 0 {
+		t.Errorf("remove these routes from testdata/uncovered_routes.txt, they are covered or no longer exist:\n\t%s", strings.Join(stale, "\n\t"))
+	}
+}
+
+func loadUncovered(t *testing.T) map[route]bool {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "uncovered_routes.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	uncovered := make(map[route]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		method, path, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Fatalf("invalid uncovered route %q", line)
+		}
+		uncovered[route{Method: method, Path: strings.TrimSpace(path)}] = true
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return uncovered
+}
+
+// match finds the route pattern a request path was served by, preferring static
+// segments over parameters the way the router does
+func match(routes []route, request route) (route, bool) {
+	parts := strings.Split(strings.Trim(request.Path, "/"), "/")
+	best, bestScore := route{}, -1
+	for _, r := range routes {
+		if r.Method != request.Method {
+			continue
+		}
+		if score, ok := matchPath(strings.Split(strings.Trim(r.Path, "/"), "/"), parts); ok && score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// matchPath reports whether a path matches a pattern, scoring it by the number of
+// static segments
+func matchPath(pattern, parts []string) (int, bool) {
+	score := 0
+	for i, seg := range pattern {
+		if strings.HasPrefix(seg, "*") {
+			return score, true
+		}
+		if i >= len(parts) {
+			return 0, false
+		}
+		switch {
+		case strings.HasPrefix(seg, ":"):
+		case seg == parts[i]:
+			score++
+		default:
+			return 0, false
+		}
+	}
+	return score, len(pattern) == len(parts)
+}
+
+func slug(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteByte('_')
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+func truncate(b []byte) string {
+	const max = 300
+	if len(b) > max {
+		return string(b[:max]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,25 @@
+// Package contract replays recorded requests against a running API and fails when a
+// response no longer matches its golden schema, so DTOs and handlers can be refactored
+// without silently breaking clients.
+//
+// The tests are behind the contract build tag. By default they start Postgres and Redis
+// with docker compose, build and start cmd/api against them, and tear everything down
+// afterwards:
+//
+//	go test -tags contract ./test/contract
+//
+// Requests live in testdata/fixtures, one file per area; each file runs in order with a
+// fresh user from testdata/setup.json. The shape of every JSON response (field names and
+// types, not values) is compared with testdata/golden. Removing a field or changing its
+// type fails the test; new fields are reported but allowed. Goldens missing for a new
+// case are recorded on the first run, and -update rewrites all of them after a deliberate
+// breaking change:
+//
+//	go test -tags contract ./test/contract -update
+//
+// Every route the server registers needs a fixture. Routes without one yet are listed in
+// testdata/uncovered_routes.txt; the list may only shrink.
+//
+// Set CONTRACT_BASE_URL to run against a server that is already up instead; the coverage
+// check is skipped then, since the registered routes are read from the server's log.
+package contract
@@ -0,0 +1,151 @@
+//go:build contract
+
+package contract
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Schemas mirror a decoded JSON document with every value replaced by its type name:
+// "string", "number", "boolean" or "null" for scalars, a map for objects and a slice
+// holding the merged shape of the elements for arrays.
+
+// schemaOf derives the schema of a decoded JSON value
+func schemaOf(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		s := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			s[k] = schemaOf(item)
+		}
+		return s
+	case []interface{}:
+		if len(v) == 0 {
+			return []interface{}{}
+		}
+		elem := schemaOf(v[0])
+		for _, item := range v[1:] {
+			elem = mergeSchema(elem, schemaOf(item))
+		}
+		return []interface{}{elem}
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// mergeSchema combines the shapes of two array elements, keeping every field either has
+func mergeSchema(a, b interface{}) interface{} {
+	if a == "null" {
+		return b
+	}
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		return a
+	}
+	for k, v := range bm {
+		if existing, ok := am[k]; ok {
+			am[k] = mergeSchema(existing, v)
+		} else {
+			am[k] = v
+		}
+	}
+	return am
+}
+
+// schemaDiff compares a response schema with its golden, returning the changes that break
+// clients of the golden and, separately, fields the response added
+func schemaDiff(golden, actual interface{}, path string) (breaking, added []string) {
+	if golden == "null" {
+		// The field was never seen with a value, so any type is compatible with it
+		return nil, nil
+	}
+
+	switch g := golden.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: was an object, now %s", at(path), describe(actual))}, nil
+		}
+		for _, k := range sortedKeys(g) {
+			v, ok := a[k]
+			if !ok {
+				breaking = append(breaking, fmt.Sprintf("%s: field removed", at(join(path, k))))
+				continue
+			}
+			b, ad := schemaDiff(g[k], v, join(path, k))
+			breaking = append(breaking, b...)
+			added = append(added, ad...)
+		}
+		for _, k := range sortedKeys(a) {
+			if _, ok := g[k]; !ok {
+				added = append(added, at(join(path, k)))
+			}
+		}
+		return breaking, added
+
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: was an array, now %s", at(path), describe(actual))}, nil
+		}
+		if len(g) == 0 {
+			return nil, nil
+		}
+		if len(a) == 0 {
+			return []string{fmt.Sprintf("%s: no longer returns any items", at(path))}, nil
+		}
+		return schemaDiff(g[0], a[0], path+"[]")
+
+	default:
+		if golden != actual {
+			return []string{fmt.Sprintf("%s: was %s, now %s", at(path), describe(golden), describe(actual))}, nil
+		}
+		return nil, nil
+	}
+}
+
+func describe(schema interface{}) string {
+	switch s := schema.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return s
+	default:
+		return fmt.Sprint(s)
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func at(path string) string {
+	if path == "" {
+		return "response"
+	}
+	return path
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,194 @@
+//go:build contract
+
+package contract
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden schemas from the current responses")
+
+// moduleRoot is the directory of go.mod, relative to this package
+const moduleRoot = "../.."
+
+const composeProject = "compass-contract"
+
+var (
+	baseURL string
+
+	routesMu sync.Mutex
+	// registered holds the routes the started server logged, empty against an external server
+	registered []route
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	if url := os.Getenv("CONTRACT_BASE_URL"); url != "" {
+		baseURL = url
+		return m.Run()
+	}
+
+	if err := compose("up", "-d", "--wait"); err != nil {
+		fmt.Fprintf(os.Stderr, "contract: failed to start databases: %v\n", err)
+		return 1
+	}
+	if os.Getenv("CONTRACT_KEEP") == "" {
+		defer compose("down", "-v")
+	}
+
+	stop, err := startServer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contract: %v\n", err)
+		return 1
+	}
+	defer stop()
+
+	return m.Run()
+}
+
+func compose(args ...string) error {
+	cmd := exec.Command("docker", append([]string{"compose", "-f", "docker-compose.yml", "-p", composeProject}, args...)...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// startServer builds cmd/api, starts it against the compose databases and waits until it
+// answers, returning a function that stops it
+func startServer() (func(), error) {
+	dir, err := os.MkdirTemp("", "compass-contract")
+	if err != nil {
+		return nil, err
+	}
+
+	binary := filepath.Join(dir, "api")
+	build := exec.Command("go", "build", "-o", binary, "./cmd/api")
+	build.Dir = moduleRoot
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return nil, fmt.Errorf("failed to build api: %w", err)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	baseURL = fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	logPath := filepath.Join(dir, "api.log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, err
+	}
+
+	server := exec.Command(binary)
+	server.Dir = moduleRoot
+	server.Env = append(os.Environ(),
+		fmt.Sprintf("SERVER_PORT=%d", port),
+		"SERVER_MODE=test",
+		"DB_HOST=127.0.0.1",
+		"DB_PORT=55432",
+		"DB_USER=compass",
+		"DB_PASSWORD=compass",
+		"DB_NAME=compass_contract",
+		"DB_SSLMODE=disable",
+		"DB_MIGRATION_MODE=auto",
+		"REDIS_HOST=127.0.0.1",
+		"REDIS_PORT=56379",
+		"REDIS_PASSWORD=",
+		"JWT_SECRET=contract-test-secret",
+		"OAUTH2_ENABLED=false",
+	)
+	server.Stdout = logFile
+	stderr, err := server.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := server.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start api: %w", err)
+	}
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		readLog(io.TeeReader(stderr, logFile))
+	}()
+
+	stop := func() {
+		_ = server.Process.Signal(os.Interrupt)
+		<-logged
+		_ = server.Wait()
+		logFile.Close()
+		os.RemoveAll(dir)
+	}
+
+	if err := waitHealthy(60 * time.Second); err != nil {
+		_ = server.Process.Kill()
+		<-logged
+		_ = server.Wait()
+		logFile.Close()
+		return nil, fmt.Errorf("%w; server log kept at %s", err, logPath)
+	}
+	return stop, nil
+}
+
+// readLog collects the routes the server logs while it registers them
+func readLog(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry struct {
+			Msg    string `json:"msg"`
+			Method string `json:"method"`
+			Path   string `json:"path"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || entry.Msg != "Route registered" {
+			continue
+		}
+		routesMu.Lock()
+		registered = append(registered, route{Method: entry.Method, Path: entry.Path})
+		routesMu.Unlock()
+	}
+}
+
+func waitHealthy(timeout time.Duration) error {
+	client := &http.Client{Timeout: 2 * time.Second}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		resp, err := client.Get(baseURL + "/health")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("api did not become healthy within %s", timeout)
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}